@@ -0,0 +1,71 @@
+// cmd/cli/accesses.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+)
+
+// accessRow is one line of gk item accesses: a session's reads of the item.
+type accessRow struct {
+	Session string    `json:"session,omitempty"` // empty: a token without a session
+	Label   string    `json:"label,omitempty"`
+	First   time.Time `json:"first"`
+	Last    time.Time `json:"last"`
+	Reads   int64     `json:"reads"`
+	Current bool      `json:"current,omitempty"`
+}
+
+// accessRows renders the log, most recent reader first as the server sends it.
+func accessRows(accesses []*pb.ItemAccess) []accessRow {
+	rows := make([]accessRow, 0, len(accesses))
+	for _, a := range accesses {
+		rows = append(rows, accessRow{
+			Session: a.GetSessionId(),
+			Label:   a.GetLabel(),
+			First:   a.GetFirstAt().AsTime().Local(),
+			Last:    a.GetLastAt().AsTime().Local(),
+			Reads:   a.GetReads(),
+			Current: a.GetCurrent(),
+		})
+	}
+	return rows
+}
+
+// cmdAccesses prints which sessions fetched an item and when, so a user can
+// tell whether a shared machine read a credential. Listing and syncing do
+// not count; show, field, open and the like do.
+func cmdAccesses(args []string, addr, caPath string, insecure bool) {
+	fs := flag.NewFlagSet("accesses", flag.ExitOnError)
+	id := fs.String("id", "", "item id")
+	parseFlags(fs, args)
+	if *id == "" {
+		fmt.Fprintln(os.Stderr, tr("need -id"))
+		os.Exit(exitUsage)
+	}
+
+	token, err := loadToken()
+	if err != nil {
+		fail(err)
+	}
+	ctx, cancel := withTimeout()
+	defer cancel()
+	conn, cli, err := dial(ctx, addr, caPath, insecure, token)
+	if err != nil {
+		fail(err)
+	}
+	defer conn.Close()
+
+	req := &pb.GetItemAccessLogRequest{}
+	req.SetId(*id)
+	resp, err := cli.GetItemAccessLog(ctx, req)
+	if err != nil {
+		fail(err)
+	}
+	rows := accessRows(resp.GetAccesses())
+	emit(rows, func() { printJSON(rows) })
+}
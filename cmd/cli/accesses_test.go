@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gofrs/uuid/v5"
+
+	"github.com/and161185/goph-keeper/pkg/gktest"
+)
+
+func Test_e2e_Accesses(t *testing.T) {
+	_ = withTmpConfig(t)
+	srv := gktest.Start(t)
+	extraDialOptions = srv.DialOptions()
+	t.Cleanup(func() { extraDialOptions = nil })
+	const addr = "gk.test:8443"
+
+	_ = stdoutOf(t, func() { cmdSignup([]string{"-u", "fay", "-p", "pw", "-label", "home"}, addr, "", false) })
+	id := uuid.Must(uuid.NewV7()).String()
+	_ = stdoutOf(t, func() {
+		cmdAddLogin([]string{"-id", id, "-title", "bank", "-username", "fay", "-password", "x"}, addr, "", false)
+		cmdList(nil, addr, "", false)
+	})
+
+	accesses := func() []accessRow {
+		t.Helper()
+		var rows []accessRow
+		out := stdoutOf(t, func() { cmdAccesses([]string{"-id", id}, addr, "", false) })
+		if err := json.Unmarshal([]byte(out), &rows); err != nil {
+			t.Fatalf("accesses %q: %v", out, err)
+		}
+		return rows
+	}
+	if rows := accesses(); len(rows) != 0 {
+		t.Fatalf("listing counted as a read: %+v", rows)
+	}
+
+	_ = stdoutOf(t, func() { cmdLogin([]string{"-u", "fay", "-p", "pw", "-label", "library"}, addr, "", false) })
+	_ = stdoutOf(t, func() { cmdField([]string{"-id", id, "-name", "password"}, addr, "", false) })
+	rows := accesses()
+	if len(rows) != 1 || rows[0].Label != "library" || rows[0].Reads != 1 || !rows[0].Current || rows[0].Session == "" {
+		t.Fatalf("accesses: %+v", rows)
+	}
+}
@@ -0,0 +1,398 @@
+// cmd/cli/admin.go
+package main
+
+import (
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+)
+
+// cmdAdminDiag toggles the server diagnostics endpoint (admin only).
+func cmdAdminDiag(args []string, addr, caPath string, insecure bool) {
+	fs := flag.NewFlagSet("admin-diag", flag.ExitOnError)
+	on := fs.Bool("on", false, "enable diagnostics")
+	off := fs.Bool("off", false, "disable diagnostics")
+	parseFlags(fs, args)
+	if *on == *off {
+		fmt.Fprintln(os.Stderr, tr("need exactly one of -on / -off"))
+		os.Exit(2)
+	}
+
+	token, err := loadToken()
+	if err != nil {
+		fail(err)
+	}
+	ctx, cancel := withTimeout()
+	defer cancel()
+	conn, _, err := dial(ctx, addr, caPath, insecure, token)
+	if err != nil {
+		fail(err)
+	}
+	defer conn.Close()
+
+	req := &pb.SetDiagnosticsRequest{}
+	req.SetEnabled(*on)
+	resp, err := pb.NewAdminServiceClient(conn).SetDiagnostics(ctx, req)
+	if err != nil {
+		fail(err)
+	}
+	fmt.Printf(tr("diagnostics enabled=%v\n"), resp.GetEnabled())
+}
+
+// parseRollbackTime accepts an RFC 3339 time or a duration meaning that long ago.
+func parseRollbackTime(s string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil && d > 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("%w: %s", errInvalidInput, tr("-to wants an RFC 3339 time or a duration such as 2h"))
+}
+
+// cmdAdminRollback rolls one user's vault back to a point in time (admin only).
+func cmdAdminRollback(args []string, addr, caPath string, insecure bool) {
+	fs := flag.NewFlagSet("admin-rollback", flag.ExitOnError)
+	user := fs.String("user", "", "user id (uuid)")
+	to := fs.String("to", "", "target time: RFC 3339 or a duration ago (2h)")
+	dry := fs.Bool("dry-run", false, "only report what would change")
+	parseFlags(fs, args)
+	if *user == "" || *to == "" {
+		fail(fmt.Errorf("%w: %s", errInvalidInput, tr("-user and -to are required")))
+	}
+	at, err := parseRollbackTime(*to, time.Now())
+	if err != nil {
+		fail(err)
+	}
+
+	token, err := loadToken()
+	if err != nil {
+		fail(err)
+	}
+	ctx, cancel := withTimeout()
+	defer cancel()
+	conn, _, err := dial(ctx, addr, caPath, insecure, token)
+	if err != nil {
+		fail(err)
+	}
+	defer conn.Close()
+
+	req := &pb.RollbackUserRequest{}
+	req.SetUserId(*user)
+	req.SetToTime(timestamppb.New(at))
+	req.SetDryRun(*dry)
+	resp, err := pb.NewAdminServiceClient(conn).RollbackUser(ctx, req)
+	if err != nil {
+		fail(err)
+	}
+	printJSON(map[string]any{
+		"rollback_id":        resp.GetRollbackId(),
+		"to_time":            at.UTC().Format(time.RFC3339),
+		"restored":           resp.GetRestored(),
+		"removed":            resp.GetRemoved(),
+		"discarded_versions": resp.GetDiscardedVersions(),
+		"skipped":            resp.GetSkipped(),
+		"dry_run":            resp.GetDryRun(),
+	})
+}
+
+// cmdAdminCheck checks one user's stored items for divergence and
+// optionally repairs what the server safely can (admin only).
+func cmdAdminCheck(args []string, addr, caPath string, insecure bool) {
+	fs := flag.NewFlagSet("admin-check", flag.ExitOnError)
+	user := fs.String("user", "", "user id (uuid)")
+	repair := fs.Bool("repair", false, "write missing history and journal records")
+	parseFlags(fs, args)
+	if *user == "" {
+		fail(fmt.Errorf("%w: %s", errInvalidInput, tr("-user is required")))
+	}
+
+	token, err := loadToken()
+	if err != nil {
+		fail(err)
+	}
+	ctx, cancel := withTimeout()
+	defer cancel()
+	conn, _, err := dial(ctx, addr, caPath, insecure, token)
+	if err != nil {
+		fail(err)
+	}
+	defer conn.Close()
+
+	req := &pb.CheckUserConsistencyRequest{}
+	req.SetUserId(*user)
+	req.SetRepair(*repair)
+	resp, err := pb.NewAdminServiceClient(conn).CheckUserConsistency(ctx, req)
+	if err != nil {
+		fail(err)
+	}
+	vs := make([]map[string]any, 0, len(resp.GetViolations()))
+	for _, v := range resp.GetViolations() {
+		vs = append(vs, map[string]any{
+			"item_id":  v.GetItemId(),
+			"check":    v.GetCheck(),
+			"detail":   v.GetDetail(),
+			"repaired": v.GetRepaired(),
+		})
+	}
+	printJSON(map[string]any{
+		"items_checked": resp.GetItemsChecked(),
+		"violations":    vs,
+	})
+}
+
+// cmdAdminSuspend suspends an account (admin only).
+func cmdAdminSuspend(args []string, addr, caPath string, insecure bool) {
+	adminSetDisabled("admin-suspend", args, addr, caPath, insecure, true)
+}
+
+// cmdAdminReactivate lifts an account's suspension (admin only).
+func cmdAdminReactivate(args []string, addr, caPath string, insecure bool) {
+	adminSetDisabled("admin-reactivate", args, addr, caPath, insecure, false)
+}
+
+func adminSetDisabled(name string, args []string, addr, caPath string, insecure, disabled bool) {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	user := fs.String("user", "", "user id (uuid)")
+	parseFlags(fs, args)
+	if *user == "" {
+		fail(fmt.Errorf("%w: %s", errInvalidInput, tr("-user is required")))
+	}
+
+	token, err := loadToken()
+	if err != nil {
+		fail(err)
+	}
+	ctx, cancel := withTimeout()
+	defer cancel()
+	conn, _, err := dial(ctx, addr, caPath, insecure, token)
+	if err != nil {
+		fail(err)
+	}
+	defer conn.Close()
+	admin := pb.NewAdminServiceClient(conn)
+
+	if disabled {
+		req := &pb.SuspendUserRequest{}
+		req.SetUserId(*user)
+		if _, err := admin.SuspendUser(ctx, req); err != nil {
+			fail(err)
+		}
+		fmt.Print(tr("suspended %s\n", *user))
+		return
+	}
+	req := &pb.ReactivateUserRequest{}
+	req.SetUserId(*user)
+	if _, err := admin.ReactivateUser(ctx, req); err != nil {
+		fail(err)
+	}
+	fmt.Print(tr("reactivated %s\n", *user))
+}
+
+// cmdAdminUsage prints the largest users of the server's latest usage
+// rollup, with their daily growth (admin only).
+func cmdAdminUsage(args []string, addr, caPath string, insecure bool) {
+	fs := flag.NewFlagSet("admin-usage", flag.ExitOnError)
+	tenantID := fs.String("tenant", "", "only this tenant's users (all if empty)")
+	limit := fs.Int("limit", 0, "at most this many users, most bytes first (0 = server default)")
+	days := fs.Int("days", 0, "daily samples for this many days")
+	parseFlags(fs, args)
+
+	token, err := loadToken()
+	if err != nil {
+		fail(err)
+	}
+	ctx, cancel := withTimeout()
+	defer cancel()
+	conn, _, err := dial(ctx, addr, caPath, insecure, token)
+	if err != nil {
+		fail(err)
+	}
+	defer conn.Close()
+
+	req := &pb.GetUsageReportRequest{}
+	req.SetTenant(*tenantID)
+	req.SetLimit(int32(*limit))
+	req.SetDays(int32(*days))
+	resp, err := pb.NewAdminServiceClient(conn).GetUsageReport(ctx, req)
+	if err != nil {
+		fail(err)
+	}
+	stamp := func(ts interface{ AsTime() time.Time }, ok bool) any {
+		if !ok {
+			return nil
+		}
+		return ts.AsTime().UTC().Format(time.RFC3339)
+	}
+	users := make([]map[string]any, 0, len(resp.GetUsers()))
+	for _, u := range resp.GetUsers() {
+		hist := make([]map[string]any, 0, len(u.GetHistory()))
+		for _, h := range u.GetHistory() {
+			hist = append(hist, map[string]any{
+				"day":           h.GetDay().AsTime().UTC().Format(time.DateOnly),
+				"items":         h.GetItems(),
+				"bytes":         h.GetBytes(),
+				"history_bytes": h.GetHistoryBytes(),
+			})
+		}
+		users = append(users, map[string]any{
+			"user_id":       u.GetUserId(),
+			"tenant":        u.GetTenant(),
+			"items":         u.GetItems(),
+			"tombstones":    u.GetTombstones(),
+			"bytes":         u.GetBytes(),
+			"history_bytes": u.GetHistoryBytes(),
+			"last_write":    stamp(u.GetLastWrite(), u.HasLastWrite()),
+			"last_login":    stamp(u.GetLastLogin(), u.HasLastLogin()),
+			"history":       hist,
+		})
+	}
+	printJSON(map[string]any{
+		"as_of": stamp(resp.GetAsOf(), resp.HasAsOf()),
+		"users": users,
+	})
+}
+
+// cmdAdminLocks lists login lockouts, or lifts one account's (admin only).
+func cmdAdminLocks(args []string, addr, caPath string, insecure bool) {
+	fs := flag.NewFlagSet("admin-locks", flag.ExitOnError)
+	lift := fs.Bool("clear", false, "lift the lockouts of -user")
+	user := fs.String("user", "", "username")
+	tenantID := fs.String("tenant", "", "tenant of -user (default tenant if empty)")
+	ipHash := fs.String("ip-hash", "", "only from this address, as listed (hex)")
+	parseFlags(fs, args)
+	if *lift && *user == "" {
+		fail(fmt.Errorf("%w: %s", errInvalidInput, tr("-clear needs -user")))
+	}
+	iph, err := hex.DecodeString(*ipHash)
+	if err != nil {
+		fail(fmt.Errorf("%w: %s", errInvalidInput, tr("-ip-hash is not hex")))
+	}
+
+	token, err := loadToken()
+	if err != nil {
+		fail(err)
+	}
+	ctx, cancel := withTimeout()
+	defer cancel()
+	conn, _, err := dial(ctx, addr, caPath, insecure, token)
+	if err != nil {
+		fail(err)
+	}
+	defer conn.Close()
+	admin := pb.NewAdminServiceClient(conn)
+
+	if *lift {
+		req := &pb.ClearLoginLocksRequest{}
+		req.SetTenant(*tenantID)
+		req.SetUsername(*user)
+		req.SetIpHash(iph)
+		resp, err := admin.ClearLoginLocks(ctx, req)
+		if err != nil {
+			fail(err)
+		}
+		fmt.Print(tr("cleared %d lock(s)\n", resp.GetCleared()))
+		return
+	}
+	resp, err := admin.ListLoginLocks(ctx, &pb.ListLoginLocksRequest{})
+	if err != nil {
+		fail(err)
+	}
+	out := make([]map[string]any, 0, len(resp.GetLocks()))
+	for _, l := range resp.GetLocks() {
+		out = append(out, map[string]any{
+			"tenant":          l.GetTenant(),
+			"username":        l.GetUsername(),
+			"ip_hash":         hex.EncodeToString(l.GetIpHash()),
+			"failed_attempts": l.GetFailedAttempts(),
+			"blocked_until":   l.GetBlockedUntil().AsTime().UTC().Format(time.RFC3339),
+		})
+	}
+	printJSON(out)
+}
+
+// cmdAdminIP lists a user's address rules, or adds or removes one (admin only).
+func cmdAdminIP(args []string, addr, caPath string, insecure bool) {
+	fs := flag.NewFlagSet("admin-ip", flag.ExitOnError)
+	user := fs.String("user", "", "username")
+	tenantID := fs.String("tenant", "", "tenant of -user (default tenant if empty)")
+	allow := fs.String("allow", "", "let the user call from this network (CIDR); then only from allowed ones")
+	deny := fs.String("deny", "", "refuse the user's calls from this network (CIDR)")
+	rm := fs.String("rm", "", "remove the rule for this network")
+	parseFlags(fs, args)
+	if *user == "" {
+		fail(fmt.Errorf("%w: %s", errInvalidInput, tr("-user is required")))
+	}
+	n := 0
+	for _, s := range []string{*allow, *deny, *rm} {
+		if s != "" {
+			n++
+		}
+	}
+	if n > 1 {
+		fail(fmt.Errorf("%w: %s", errInvalidInput, tr("give one of -allow, -deny and -rm")))
+	}
+
+	token, err := loadToken()
+	if err != nil {
+		fail(err)
+	}
+	ctx, cancel := withTimeout()
+	defer cancel()
+	conn, _, err := dial(ctx, addr, caPath, insecure, token)
+	if err != nil {
+		fail(err)
+	}
+	defer conn.Close()
+	admin := pb.NewAdminServiceClient(conn)
+
+	switch {
+	case *allow != "" || *deny != "":
+		req := &pb.AddUserIPRuleRequest{}
+		req.SetTenant(*tenantID)
+		req.SetUsername(*user)
+		req.SetCidr(*allow + *deny)
+		req.SetAllow(*allow != "")
+		resp, err := admin.AddUserIPRule(ctx, req)
+		if err != nil {
+			fail(err)
+		}
+		printJSON(ipRuleRow(resp.GetRule()))
+	case *rm != "":
+		req := &pb.RemoveUserIPRuleRequest{}
+		req.SetTenant(*tenantID)
+		req.SetUsername(*user)
+		req.SetCidr(*rm)
+		if _, err := admin.RemoveUserIPRule(ctx, req); err != nil {
+			fail(err)
+		}
+		fmt.Print(tr("removed\n"))
+	default:
+		req := &pb.ListUserIPRulesRequest{}
+		req.SetTenant(*tenantID)
+		req.SetUsername(*user)
+		resp, err := admin.ListUserIPRules(ctx, req)
+		if err != nil {
+			fail(err)
+		}
+		out := make([]map[string]any, 0, len(resp.GetRules()))
+		for _, r := range resp.GetRules() {
+			out = append(out, ipRuleRow(r))
+		}
+		printJSON(out)
+	}
+}
+
+func ipRuleRow(r *pb.IPRule) map[string]any {
+	return map[string]any{
+		"cidr":       r.GetCidr(),
+		"allow":      r.GetAllow(),
+		"created_at": r.GetCreatedAt().AsTime().UTC().Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func Test_parseRollbackTime(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	if got, err := parseRollbackTime("2026-02-28T08:30:00+01:00", now); err != nil || !got.Equal(time.Date(2026, 2, 28, 7, 30, 0, 0, time.UTC)) {
+		t.Fatalf("rfc3339: %v %v", got, err)
+	}
+	if got, err := parseRollbackTime("90m", now); err != nil || !got.Equal(now.Add(-90*time.Minute)) {
+		t.Fatalf("duration: %v %v", got, err)
+	}
+	for _, bad := range []string{"yesterday", "-2h", "2026-02-28"} {
+		if _, err := parseRollbackTime(bad, now); !errors.Is(err, errInvalidInput) {
+			t.Fatalf("%q accepted: %v", bad, err)
+		}
+	}
+}
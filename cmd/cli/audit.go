@@ -0,0 +1,201 @@
+// cmd/cli/audit.go
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+)
+
+// metaPasswordChangedAt is the login meta key holding the last password change (RFC 3339).
+const metaPasswordChangedAt = "password_changed_at"
+
+// stampPasswordChange sets meta[password_changed_at]: carried over from prev
+// when the password is unchanged, otherwise now. prev may be nil (new item).
+func stampPasswordChange(meta map[string]any, newPass string, prev *typedPayload, now time.Time) {
+	if prev != nil {
+		if lf, err := parseLogin(*prev); err == nil && lf.Password == newPass {
+			var pm map[string]any
+			if json.Unmarshal(prev.Meta, &pm) == nil {
+				if ts, ok := pm[metaPasswordChangedAt].(string); ok && ts != "" {
+					meta[metaPasswordChangedAt] = ts
+					return
+				}
+			}
+		}
+	}
+	meta[metaPasswordChangedAt] = now.UTC().Format(time.RFC3339)
+}
+
+// stampLoginPayload applies stampPasswordChange to the meta of a login
+// payload. Other payloads are returned as they are.
+func stampLoginPayload(p typedPayload, prev *typedPayload, now time.Time) (typedPayload, error) {
+	if p.Type != "login" {
+		return p, nil
+	}
+	lf, err := parseLogin(p)
+	if err != nil {
+		return p, err
+	}
+	meta := map[string]any{}
+	if err := json.Unmarshal(p.Meta, &meta); err != nil {
+		return p, err
+	}
+	stampPasswordChange(meta, lf.Password, prev, now)
+	if p.Meta, err = json.Marshal(meta); err != nil {
+		return p, err
+	}
+	return p, nil
+}
+
+// parseAge parses a duration that may also use d (days) and w (weeks) units, e.g. "180d".
+func parseAge(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	for unit, mul := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, unit); ok {
+			v, err := strconv.ParseFloat(n, 64)
+			if err != nil || v < 0 {
+				return 0, fmt.Errorf("bad age %q", s)
+			}
+			return time.Duration(v * float64(mul)), nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("bad age %q", s)
+	}
+	return d, nil
+}
+
+// staleLogin is a credential overdue for rotation.
+type staleLogin struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	Username  string    `json:"username"`
+	ChangedAt time.Time `json:"changed_at"`
+	AgeDays   int       `json:"age_days"`
+	// Estimated is set when the item has no password_changed_at and the
+	// last modification time is used instead.
+	Estimated bool `json:"estimated,omitempty"`
+}
+
+// findStale returns logins whose password is older than maxAge, oldest first.
+func findStale(items []vaultItem, maxAge time.Duration, now time.Time) []staleLogin {
+	var out []staleLogin
+	for _, it := range items {
+		lf, err := parseLogin(it.Payload)
+		if err != nil {
+			continue
+		}
+		var meta struct {
+			ChangedAt string `json:"password_changed_at"`
+		}
+		_ = json.Unmarshal(it.Payload.Meta, &meta)
+		sl := staleLogin{ID: it.ID, Title: lf.Title, Username: lf.Username}
+		if t, err := time.Parse(time.RFC3339, meta.ChangedAt); err == nil {
+			sl.ChangedAt = t
+		} else {
+			sl.ChangedAt, sl.Estimated = it.UpdatedAt, true
+		}
+		age := now.Sub(sl.ChangedAt)
+		if age <= maxAge {
+			continue
+		}
+		sl.AgeDays = int(age / (24 * time.Hour))
+		out = append(out, sl)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ChangedAt.Before(out[j].ChangedAt) })
+	return out
+}
+
+// printStale writes a human-readable stale list.
+func printStale(w io.Writer, stale []staleLogin) {
+	for _, s := range stale {
+		est := ""
+		if s.Estimated {
+			est = " (estimated from last edit)"
+		}
+		fmt.Fprintf(w, "  %4dd  %s  %s  %s%s\n", s.AgeDays, s.ID, s.Title, s.Username, est)
+	}
+}
+
+// auditReport is what gk audit found.
+type auditReport struct {
+	Stale    []staleLogin `json:"stale"`
+	WeakWifi []weakWifi   `json:"weak_wifi"`
+	// Due are expired licenses and API keys past their rotation date.
+	Due []dueItem `json:"due"`
+}
+
+// cmdAudit reports logins whose passwords are overdue for rotation, Wi-Fi
+// networks with weak keys and licenses and API keys past their date.
+func cmdAudit(args []string, addr, caPath string, insecure bool) {
+	fs := flag.NewFlagSet("audit", flag.ExitOnError)
+	staleFlag := fs.String("stale", "180d", "flag passwords older than this (e.g. 90d, 12w, 720h)")
+	parseFlags(fs, args)
+	maxAge, err := parseAge(*staleFlag)
+	if err != nil {
+		fail(fmt.Errorf("%w: %w", errInvalidInput, err))
+	}
+
+	token, err := loadToken()
+	if err != nil {
+		fail(err)
+	}
+	ctx, cancel := withTimeout()
+	defer cancel()
+	conn, cli, err := dial(ctx, addr, caPath, insecure, token)
+	if err != nil {
+		fail(err)
+	}
+	defer conn.Close()
+
+	items, err := listTyped(ctx, cli)
+	if err != nil {
+		fail(err)
+	}
+	now := time.Now()
+	rep := auditReport{Stale: findStale(items, maxAge, now), WeakWifi: findWeakWifi(items)}
+	rep.Due = findDue(items, -24*time.Hour, now) // dates before today
+	emit(rep, func() {
+		if len(rep.Stale) == 0 {
+			fmt.Printf(tr("no passwords older than %s\n"), *staleFlag)
+		} else {
+			fmt.Printf(tr("%d passwords older than %s:\n"), len(rep.Stale), *staleFlag)
+			printStale(os.Stdout, rep.Stale)
+		}
+		if len(rep.WeakWifi) > 0 {
+			fmt.Printf(tr("%d Wi-Fi networks with weak security:\n"), len(rep.WeakWifi))
+			for _, w := range rep.WeakWifi {
+				fmt.Printf("  %s  %s  %s\n", w.ID, w.Title, w.explain())
+			}
+		}
+		if len(rep.Due) > 0 {
+			fmt.Printf(tr("%d licenses or API keys past their date:\n"), len(rep.Due))
+			printDue(os.Stdout, rep.Due)
+		}
+	})
+}
+
+// nagStale prints a rotation reminder to stderr; failures never fail the caller.
+// now should be the server's time so a wrong local clock does not skew ages.
+func nagStale(ctx context.Context, cli pb.GophKeeperClient, maxAge time.Duration, now time.Time) {
+	items, err := listTyped(ctx, cli)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, tr("rotation reminder skipped: %v\n"), err)
+		return
+	}
+	if stale := findStale(items, maxAge, now); len(stale) > 0 {
+		fmt.Fprint(os.Stderr, colors(os.Stderr).warn(tr("%d credentials overdue for rotation:\n", len(stale))))
+		printStale(os.Stderr, stale)
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func Test_parseAge(t *testing.T) {
+	t.Parallel()
+	for in, want := range map[string]time.Duration{
+		"180d": 180 * 24 * time.Hour,
+		"2w":   14 * 24 * time.Hour,
+		"36h":  36 * time.Hour,
+		"1.5d": 36 * time.Hour,
+	} {
+		got, err := parseAge(in)
+		if err != nil || got != want {
+			t.Fatalf("%s: %v %v", in, got, err)
+		}
+	}
+	for _, in := range []string{"", "d", "-3d", "soon"} {
+		if _, err := parseAge(in); err == nil {
+			t.Fatalf("%q: want error", in)
+		}
+	}
+}
+
+func Test_stampPasswordChange(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	old := "2025-01-01T00:00:00Z"
+	prev := loginItem("a", "t", "", "me", "same", "", 0).Payload
+	prev.Meta, _ = json.Marshal(map[string]any{"title": "t", metaPasswordChangedAt: old})
+
+	meta := map[string]any{}
+	stampPasswordChange(meta, "same", &prev, now)
+	if meta[metaPasswordChangedAt] != old {
+		t.Fatalf("unchanged password must keep date: %v", meta)
+	}
+	stampPasswordChange(meta, "rotated", &prev, now)
+	if meta[metaPasswordChangedAt] != "2026-05-01T12:00:00Z" {
+		t.Fatalf("changed password must stamp now: %v", meta)
+	}
+	stampPasswordChange(meta, "new", nil, now)
+	if meta[metaPasswordChangedAt] != "2026-05-01T12:00:00Z" {
+		t.Fatalf("new item must stamp now: %v", meta)
+	}
+}
+
+func Test_findStale(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	withDate := func(it vaultItem, ts string) vaultItem {
+		m := map[string]any{}
+		_ = json.Unmarshal(it.Payload.Meta, &m)
+		m[metaPasswordChangedAt] = ts
+		it.Payload.Meta, _ = json.Marshal(m)
+		return it
+	}
+	fresh := withDate(loginItem("fresh", "f", "", "u", "p", "", 0), "2026-04-01T00:00:00Z")
+	old := withDate(loginItem("old", "o", "", "u", "p", "", 0), "2025-01-01T00:00:00Z")
+	undated := loginItem("undated", "x", "", "u", "p", "", 0)
+	undated.UpdatedAt = now.Add(-400 * 24 * time.Hour)
+	text := vaultItem{ID: "t", Payload: typedPayload{Type: "text", Meta: []byte(`{}`), Data: []byte(`{}`)}}
+
+	got := findStale([]vaultItem{fresh, old, undated, text}, 180*24*time.Hour, now)
+	if len(got) != 2 || got[0].ID != "old" || got[1].ID != "undated" {
+		t.Fatalf("stale: %+v", got)
+	}
+	if got[0].Estimated || !got[1].Estimated || got[1].AgeDays != 400 {
+		t.Fatalf("flags: %+v", got)
+	}
+}
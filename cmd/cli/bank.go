@@ -0,0 +1,150 @@
+// cmd/cli/bank.go
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"regexp"
+	"strings"
+)
+
+var (
+	reIBAN = regexp.MustCompile(`^[A-Z]{2}\d{2}[A-Z0-9]{11,30}$`)
+	// reBIC is ISO 9362: bank, country, location and an optional branch.
+	reBIC = regexp.MustCompile(`^[A-Z]{4}[A-Z]{2}[A-Z0-9]{2}([A-Z0-9]{3})?$`)
+)
+
+// normalizeIBAN drops the spaces IBANs are printed with and upper-cases it.
+func normalizeIBAN(s string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(s), ""))
+}
+
+// validIBAN checks the format and the ISO 13616 mod-97 checksum of a
+// normalized IBAN.
+func validIBAN(iban string) bool {
+	if !reIBAN.MatchString(iban) {
+		return false
+	}
+	var digits strings.Builder
+	for _, r := range iban[4:] + iban[:4] {
+		if r >= 'A' && r <= 'Z' {
+			fmt.Fprintf(&digits, "%d", r-'A'+10)
+		} else {
+			digits.WriteRune(r)
+		}
+	}
+	n, ok := new(big.Int).SetString(digits.String(), 10)
+	return ok && new(big.Int).Mod(n, big.NewInt(97)).Int64() == 1
+}
+
+func validBIC(bic string) bool { return reBIC.MatchString(bic) }
+
+func checkIBAN(s string) error {
+	if !validIBAN(normalizeIBAN(s)) {
+		return errors.New(tr("not a valid IBAN"))
+	}
+	return nil
+}
+
+func checkBIC(s string) error {
+	if !validBIC(strings.ToUpper(s)) {
+		return errors.New(tr("8 or 11 letters and digits, e.g. DEUTDEFF"))
+	}
+	return nil
+}
+
+// maskIBAN keeps the country, the check digits and the last four
+// characters of an IBAN, in groups of four as it is printed.
+func maskIBAN(iban string) string {
+	if len(iban) > 8 {
+		iban = iban[:4] + strings.Repeat("*", len(iban)-8) + iban[len(iban)-4:]
+	}
+	var groups []string
+	for len(iban) > 4 {
+		groups, iban = append(groups, iban[:4]), iban[4:]
+	}
+	return strings.Join(append(groups, iban), " ")
+}
+
+// shownIBAN is the IBAN gk show prints for a bank item: masked unless
+// reveal. ok is false for other items.
+func shownIBAN(obj typedPayload, reveal bool) (string, bool) {
+	if obj.Type != "bank" {
+		return "", false
+	}
+	var data struct {
+		IBAN string `json:"iban"`
+	}
+	if json.Unmarshal(obj.Data, &data) != nil || data.IBAN == "" {
+		return "", false
+	}
+	if reveal {
+		return data.IBAN, true
+	}
+	return maskIBAN(data.IBAN), true
+}
+
+// cmdAddBank creates or updates a bank account record.
+func cmdAddBank(args []string, addr, caPath string, insecure bool) {
+	fs := flag.NewFlagSet("add-bank", flag.ExitOnError)
+	id := fs.String("id", "", "item id (uuid, optional)")
+	title := fs.String("title", "", "title (default: the bank)")
+	holder := fs.String("holder", "", "account holder")
+	bank := fs.String("bank", "", "bank name")
+	iban := fs.String("iban", "", "IBAN, spaces allowed")
+	bic := fs.String("bic", "", "BIC (SWIFT code)")
+	note := fs.String("note", "", "note")
+	base := fs.Int64("base", 0, "base version (0 for create)")
+	dryRun := dryRunFlag(fs)
+	customFields := customFieldFlags(fs)
+	parseFlags(fs, args)
+	fields, err := customFields()
+	if err != nil {
+		fail(err)
+	}
+
+	autoUUID(id)
+	if *holder == "" || *iban == "" {
+		fmt.Fprintln(os.Stderr, tr("holder and iban required"))
+		os.Exit(exitUsage)
+	}
+	acct, code := normalizeIBAN(*iban), strings.ToUpper(*bic)
+	if !validIBAN(acct) {
+		fail(fmt.Errorf("%w: -iban: bad format or checksum", errInvalidInput))
+	}
+	if code != "" && !validBIC(code) {
+		fail(fmt.Errorf("%w: -bic: want 8 or 11 letters and digits", errInvalidInput))
+	}
+	meta := map[string]any{"title": choose(*title, *bank), "holder": *holder, "bank": *bank, "bic": code, "note": *note}
+	data := map[string]any{"iban": acct}
+	pt, err := buildTypedPayloadFields("bank", meta, data, fields)
+	if err != nil {
+		fail(err)
+	}
+
+	token, err := loadToken()
+	if err != nil {
+		fail(err)
+	}
+	uid, err := loadUserID()
+	if err != nil {
+		fail(err)
+	}
+	blob, err := encryptForItem(*id, uid, *base+1, pt)
+	if err != nil {
+		fail(err)
+	}
+	if *dryRun {
+		printDryRun(plannedUpserts([]pendingUpsert{{ID: *id, BaseVer: *base, Blob: blob}}))
+		return
+	}
+	resp, err := upsertOne(addr, caPath, insecure, token, *id, *base, blob)
+	if err != nil {
+		fail(err)
+	}
+	printJSON(resp.GetResults())
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func Test_validIBAN(t *testing.T) {
+	t.Parallel()
+
+	for _, ok := range []string{"DE89 3704 0044 0532 0130 00", "gb82west12345698765432", "NL91ABNA0417164300"} {
+		if !validIBAN(normalizeIBAN(ok)) {
+			t.Fatalf("%s: want valid", ok)
+		}
+	}
+	for _, bad := range []string{"DE88370400440532013000", "DE8937040044", "1289370400440532013000", "DE89-3704-0044-0532-0130-00", ""} {
+		if validIBAN(normalizeIBAN(bad)) {
+			t.Fatalf("%s: want invalid", bad)
+		}
+	}
+	for bic, ok := range map[string]bool{"DEUTDEFF": true, "DEUTDEFF500": true, "DEUTDEF": false, "DEUT1EFF": false, "DEUTDEFF5": false} {
+		if validBIC(bic) != ok {
+			t.Fatalf("%s: want %v", bic, ok)
+		}
+	}
+}
+
+func Test_shownIBAN(t *testing.T) {
+	t.Parallel()
+
+	if got := maskIBAN("DE89370400440532013000"); got != "DE89 **** **** **** **30 00" {
+		t.Fatalf("mask: %s", got)
+	}
+	obj := typedPayload{Type: "bank", Meta: json.RawMessage(`{"holder":"A User"}`), Data: json.RawMessage(`{"iban":"NL91ABNA0417164300"}`)}
+	if got, ok := shownIBAN(obj, false); !ok || got != "NL91 **** **** **43 00" {
+		t.Fatalf("masked: %q %v", got, ok)
+	}
+	if got, _ := shownIBAN(obj, true); got != "NL91ABNA0417164300" {
+		t.Fatalf("revealed: %q", got)
+	}
+	if _, ok := shownIBAN(typedPayload{Type: "card"}, true); ok {
+		t.Fatal("a card has no IBAN")
+	}
+
+	if err := validatePayload(obj); err != nil {
+		t.Fatal(err)
+	}
+	obj.Data = json.RawMessage(`{"iban":"NL91ABNA0417164301"}`)
+	if err := validatePayload(obj); !errors.Is(err, errInvalidInput) {
+		t.Fatalf("bad checksum: %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+// cmd/cli/batch.go
+package main
+
+import (
+	"context"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+)
+
+// Server-side receive limit is 1 MiB per RPC; keep batches comfortably below it.
+const (
+	batchMaxBytes = 768 << 10
+	batchMaxItems = 200
+)
+
+// pendingUpsert is an encrypted item ready to be sent.
+type pendingUpsert struct {
+	ID      string
+	BaseVer int64
+	Blob    []byte
+	Plain   []byte // payload, kept to re-encrypt on a conflict; nil if unknown
+}
+
+// chunkUpserts splits items into batches bounded by total blob size and count.
+// An item larger than maxBytes is sent alone (the server decides whether to accept it).
+func chunkUpserts(items []pendingUpsert, maxBytes, maxItems int) [][]pendingUpsert {
+	var (
+		out  [][]pendingUpsert
+		cur  []pendingUpsert
+		size int
+	)
+	for _, it := range items {
+		n := len(it.Blob) + len(it.ID) + 16
+		if len(cur) > 0 && (size+n > maxBytes || len(cur) >= maxItems) {
+			out = append(out, cur)
+			cur, size = nil, 0
+		}
+		cur = append(cur, it)
+		size += n
+	}
+	if len(cur) > 0 {
+		out = append(out, cur)
+	}
+	return out
+}
+
+// upsertRequest builds the UpsertItems request for one batch.
+func upsertRequest(batch []pendingUpsert) *pb.UpsertItemsRequest {
+	ups := make([]*pb.UpsertItem, 0, len(batch))
+	for _, it := range batch {
+		eb := &pb.EncryptedBlob{}
+		eb.SetCiphertext(it.Blob)
+		u := &pb.UpsertItem{}
+		u.SetId(it.ID)
+		u.SetBaseVer(it.BaseVer)
+		u.SetBlobEnc(eb)
+		u.SetHints(itemHints(it.Blob))
+		ups = append(ups, u)
+	}
+	req := &pb.UpsertItemsRequest{}
+	req.SetItems(ups)
+	return req
+}
+
+// sendUpserts uploads items in size-capped batches; progress (if non-nil) is called after each batch.
+func sendUpserts(ctx context.Context, cli pb.GophKeeperClient, items []pendingUpsert, progress func(done, total int)) ([]*pb.ItemVersion, error) {
+	var results []*pb.ItemVersion
+	done := 0
+	for _, batch := range chunkUpserts(items, batchMaxBytes, batchMaxItems) {
+		resp, err := cli.UpsertItems(ctx, upsertRequest(batch))
+		if err != nil {
+			return results, err
+		}
+		showWarnings(resp.GetWarnings())
+		results = append(results, resp.GetResults()...)
+		done += len(batch)
+		if progress != nil {
+			progress(done, len(items))
+		}
+	}
+	return results, nil
+}
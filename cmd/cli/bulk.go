@@ -0,0 +1,235 @@
+// cmd/cli/bulk.go
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	u "github.com/gofrs/uuid/v5"
+)
+
+// bulkItem is one element of the bulk-add input: a typed payload plus optional
+// id/base_ver for updating an existing item.
+type bulkItem struct {
+	ID      string          `json:"id,omitempty"`
+	BaseVer int64           `json:"base_ver,omitempty"`
+	Type    string          `json:"type"`
+	Meta    json.RawMessage `json:"meta"`
+	Data    json.RawMessage `json:"data"`
+	Fields  []customField   `json:"fields,omitempty"`
+	// Schema is the type's schema version, as gk export writes it.
+	Schema int `json:"schema,omitempty"`
+}
+
+// bulkResult reports the outcome for one input element.
+type bulkResult struct {
+	Index int    `json:"index"`
+	ID    string `json:"id"`
+	Title string `json:"title,omitempty"`
+	Ver   int64  `json:"ver,omitempty"`
+	Error string `json:"error,omitempty"`
+	// Conflict is the -on-conflict policy applied to this item; with
+	// keep-both, CopyID is the new item holding the input.
+	Conflict string `json:"conflict,omitempty"`
+	CopyID   string `json:"copy_id,omitempty"`
+}
+
+// parseBulkItems decodes and validates the input array.
+func parseBulkItems(b []byte) ([]bulkItem, error) {
+	var items []bulkItem
+	if err := json.Unmarshal(b, &items); err != nil {
+		return nil, fmt.Errorf("input must be a JSON array of {type, meta, data}: %w", err)
+	}
+	for i := range items {
+		it := &items[i]
+		if _, ok := payloadTypes[it.Type]; !ok {
+			return nil, fmt.Errorf("item[%d]: unknown type %q", i, it.Type)
+		}
+		if it.ID == "" {
+			if it.BaseVer != 0 {
+				return nil, fmt.Errorf("item[%d]: base_ver without id", i)
+			}
+			autoUUID(&it.ID)
+		} else if _, err := u.FromString(it.ID); err != nil {
+			return nil, fmt.Errorf("item[%d]: bad id: %w", i, err)
+		}
+		if it.BaseVer < 0 {
+			return nil, fmt.Errorf("item[%d]: negative base_ver", i)
+		}
+		if len(it.Meta) == 0 {
+			it.Meta = json.RawMessage("{}")
+		}
+		if len(it.Data) == 0 {
+			it.Data = json.RawMessage("{}")
+		}
+	}
+	return items, nil
+}
+
+// storedLogins fetches the stored payloads of the logins the input updates,
+// keyed by id. It is best effort: a login it cannot read counts as having a
+// new password.
+func storedLogins(addr, caPath string, insecure bool, items []bulkItem) map[string]*typedPayload {
+	var ids []string
+	for _, it := range items {
+		if it.Type == "login" && it.BaseVer > 0 {
+			ids = append(ids, it.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	token, err := loadToken()
+	if err != nil {
+		return nil
+	}
+	ctx, cancel := withTimeout()
+	defer cancel()
+	conn, cli, err := dial(ctx, addr, caPath, insecure, token)
+	if err != nil {
+		return nil
+	}
+	defer conn.Close()
+	out := make(map[string]*typedPayload, len(ids))
+	for _, id := range ids {
+		if _, obj, err := fetchTyped(ctx, cli, id); err == nil {
+			out[id] = &obj
+		}
+	}
+	return out
+}
+
+// stampBulkLogins sets password_changed_at on the login items. An update
+// keeps the stored date when the password is the one in prev; a new item
+// keeps the date it comes with (a restored export) or gets now.
+func stampBulkLogins(items []bulkItem, prev map[string]*typedPayload, now time.Time) error {
+	for i := range items {
+		it := &items[i]
+		if it.Type != "login" {
+			continue
+		}
+		in := typedPayload{Type: it.Type, Meta: it.Meta, Data: it.Data}
+		was := prev[it.ID]
+		if it.BaseVer == 0 {
+			was = &in
+		}
+		out, err := stampLoginPayload(in, was, now)
+		if err != nil {
+			return fmt.Errorf("item[%d]: %w", i, err)
+		}
+		it.Meta = out.Meta
+	}
+	return nil
+}
+
+// encryptBulk checks each item against its schema and encrypts it for its
+// next version (base_ver+1).
+func encryptBulk(items []bulkItem, userID string) ([]pendingUpsert, error) {
+	out := make([]pendingUpsert, 0, len(items))
+	for i, it := range items {
+		pt, err := encodePayload(typedPayload{Type: it.Type, Meta: it.Meta, Data: it.Data, Fields: it.Fields, Schema: it.Schema})
+		if err != nil {
+			return nil, fmt.Errorf("item[%d]: %w", i, err)
+		}
+		blob, err := encryptForItem(it.ID, userID, it.BaseVer+1, pt)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, pendingUpsert{ID: it.ID, BaseVer: it.BaseVer, Blob: blob, Plain: pt})
+	}
+	return out, nil
+}
+
+// bulkResults pairs inputs with upload outcomes; items past the failed batch get sendErr.
+func bulkResults(items []bulkItem, outs []upsertOutcome, sendErr error) []bulkResult {
+	out := make([]bulkResult, len(items))
+	for i, it := range items {
+		r := bulkResult{Index: i, ID: it.ID, Title: metaTitle(typedPayload{Type: it.Type, Meta: it.Meta})}
+		switch {
+		case i < len(outs):
+			r.Ver, r.Conflict, r.CopyID = outs[i].Ver, outs[i].Resolution, outs[i].CopyID
+		case sendErr != nil:
+			r.Error = sendErr.Error()
+		}
+		out[i] = r
+	}
+	return out
+}
+
+// cmdBulkAdd encrypts and uploads many typed items from a JSON array.
+func cmdBulkAdd(args []string, addr, caPath string, insecure bool) {
+	fs := flag.NewFlagSet("bulk-add", flag.ExitOnError)
+	file := fs.String("file", "-", "JSON array of {type, meta, data[, id, base_ver]} (- for stdin)")
+	onConflict := fs.String("on-conflict", conflictFail, "stale base_ver: fail | keep-both | server | local")
+	dryRun := dryRunFlag(fs)
+	parseFlags(fs, args)
+	policy, err := parseConflictPolicy(*onConflict)
+	if err != nil {
+		fail(err)
+	}
+
+	raw, err := readAll(*file)
+	if err != nil {
+		fail(err)
+	}
+	items, err := parseBulkItems(raw)
+	if err != nil {
+		fail(fmt.Errorf("%w: %w", errInvalidInput, err))
+	}
+	if len(items) == 0 {
+		fmt.Println(tr("nothing to add"))
+		return
+	}
+
+	uid, err := loadUserID()
+	if err != nil {
+		fail(err)
+	}
+	if err := stampBulkLogins(items, storedLogins(addr, caPath, insecure, items), time.Now()); err != nil {
+		fail(fmt.Errorf("%w: %w", errInvalidInput, err))
+	}
+	ups, err := encryptBulk(items, uid)
+	if err != nil {
+		fail(err)
+	}
+	if *dryRun {
+		printDryRun(plannedUpserts(ups))
+		return
+	}
+	token, err := loadToken()
+	if err != nil {
+		fail(err)
+	}
+
+	ctx, cancel := withTimeout()
+	defer cancel()
+	conn, cli, err := dial(ctx, addr, caPath, insecure, token)
+	if err != nil {
+		fail(err)
+	}
+	defer conn.Close()
+
+	outs, sendErr := sendResolving(ctx, cli, uid, ups, policy, func(done, total int) {
+		fmt.Fprintf(os.Stderr, tr("uploaded %d/%d\n"), done, total)
+	})
+	results := bulkResults(items, outs, sendErr)
+	emit(results, func() { printJSON(results) })
+	stored := 0
+	for _, o := range outs {
+		if o.Resolution != conflictServer {
+			stored++
+		}
+	}
+	c, summary := colors(os.Stderr), tr("%d/%d items stored\n", stored, len(items))
+	if stored == len(items) {
+		fmt.Fprint(os.Stderr, c.ok(summary))
+	} else {
+		fmt.Fprint(os.Stderr, c.warn(summary))
+	}
+	if sendErr != nil {
+		fail(sendErr)
+	}
+}
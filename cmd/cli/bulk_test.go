@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+
+	cc "github.com/and161185/goph-keeper/internal/crypto/clientcrypto"
+)
+
+func Test_parseBulkItems_Validation(t *testing.T) {
+	t.Parallel()
+
+	items, err := parseBulkItems([]byte(`[
+		{"type":"login","meta":{"title":"a"},"data":{"password":"p"}},
+		{"type":"text","id":"8f1e6a52-0f5b-4a39-9c55-7c0c1a0a2f11","base_ver":4,"data":{"text":"t"}}
+	]`))
+	if err != nil || len(items) != 2 {
+		t.Fatalf("parse: %+v %v", items, err)
+	}
+	if items[0].ID == "" || items[0].BaseVer != 0 {
+		t.Fatalf("auto id: %+v", items[0])
+	}
+	if string(items[1].Meta) != "{}" || items[1].BaseVer != 4 {
+		t.Fatalf("defaults: %+v", items[1])
+	}
+
+	for _, in := range []string{
+		`{"type":"login"}`,
+		`[{"type":"bogus"}]`,
+		`[{"type":"text","id":"not-a-uuid"}]`,
+		`[{"type":"text","base_ver":2}]`,
+	} {
+		if _, err := parseBulkItems([]byte(in)); err == nil {
+			t.Fatalf("want error for %s", in)
+		}
+	}
+}
+
+func Test_encryptBulk_UsesNextVersion(t *testing.T) {
+	_ = withTmpConfig(t)
+	dek, _ := cc.Rand(cc.DEKLen)
+	if err := saveDEK(dek); err != nil {
+		t.Fatal(err)
+	}
+	items, err := parseBulkItems([]byte(`[{"type":"text","id":"8f1e6a52-0f5b-4a39-9c55-7c0c1a0a2f11","base_ver":4,"meta":{"title":"n"},"data":{"text":"t"}}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ups, err := encryptBulk(items, "u1")
+	if err != nil || len(ups) != 1 || ups[0].BaseVer != 4 {
+		t.Fatalf("encrypt: %+v %v", ups, err)
+	}
+	pt, err := decryptForItem(ups[0].ID, "u1", 5, ups[0].Blob)
+	if err != nil {
+		t.Fatalf("decrypt at ver 5: %v", err)
+	}
+	var obj typedPayload
+	if json.Unmarshal(pt, &obj) != nil || obj.Type != "text" || metaTitle(obj) != "n" {
+		t.Fatalf("payload: %s", pt)
+	}
+}
+
+func Test_bulkResults_PartialFailure(t *testing.T) {
+	t.Parallel()
+	items := []bulkItem{
+		{ID: "a", Type: "text", Meta: []byte(`{"title":"A"}`)},
+		{ID: "b", Type: "text", Meta: []byte(`{}`)},
+		{ID: "c", Type: "text", Meta: []byte(`{}`)},
+	}
+	got := bulkResults(items, []upsertOutcome{{Ver: 1}}, errors.New("version conflict"))
+	if got[0].Ver != 1 || got[0].Title != "A" || got[0].Error != "" {
+		t.Fatalf("stored item: %+v", got[0])
+	}
+	if got[1].Error != "version conflict" || got[2].Error == "" || got[2].Index != 2 {
+		t.Fatalf("failed items: %+v", got[1:])
+	}
+}
+
+func Test_e2e_BulkAddStampsPasswordChange(t *testing.T) {
+	_ = startServer(t)
+	const addr = "gk.test:8443"
+	dir := t.TempDir()
+
+	id := uuid.Must(uuid.NewV7()).String()
+	in := filepath.Join(dir, "in.json")
+	seed := `[{"type":"login","id":"` + id + `","meta":{"title":"mail","username":"alice","password_changed_at":"2020-01-02T00:00:00Z"},"data":{"password":"pw"}}]`
+	if err := os.WriteFile(in, []byte(seed), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_ = stdoutOf(t, func() { cmdBulkAdd([]string{"-file", in}, addr, "", false) })
+
+	changedAt := func() string {
+		t.Helper()
+		token, err := loadToken()
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := previousPayload(addr, "", false, token, id)
+		if p == nil {
+			t.Fatal("login not stored")
+		}
+		var m struct {
+			ChangedAt string `json:"password_changed_at"`
+		}
+		_ = json.Unmarshal(p.Meta, &m)
+		return m.ChangedAt
+	}
+	// export and re-add: the date goes with the item, a same password keeps it
+	roundTrip := func(rotate func(*bulkItem)) {
+		t.Helper()
+		out := filepath.Join(t.TempDir(), "vault.json")
+		cmdExport([]string{"-out", out, "-ids"}, addr, "", false)
+		raw, err := os.ReadFile(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		items, err := parseBulkItems(raw)
+		if err != nil || len(items) != 1 {
+			t.Fatalf("export: %v\n%s", err, raw)
+		}
+		rotate(&items[0])
+		raw, _ = json.Marshal(items)
+		if err := os.WriteFile(in, raw, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		_ = stdoutOf(t, func() { cmdBulkAdd([]string{"-file", in}, addr, "", false) })
+	}
+
+	if got := changedAt(); got != "2020-01-02T00:00:00Z" {
+		t.Fatalf("new item: %s", got)
+	}
+	roundTrip(func(*bulkItem) {})
+	if got := changedAt(); got != "2020-01-02T00:00:00Z" {
+		t.Fatalf("same password: %s", got)
+	}
+	before := time.Now().Add(-time.Second)
+	roundTrip(func(it *bulkItem) { it.Data = json.RawMessage(`{"password":"rotated"}`) })
+	if got, err := time.Parse(time.RFC3339, changedAt()); err != nil || got.Before(before) {
+		t.Fatalf("rotated password: %v %v", got, err)
+	}
+}
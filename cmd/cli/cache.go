@@ -0,0 +1,127 @@
+// cmd/cli/cache.go
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"time"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// noCache forces reads to go to the server even when syncd keeps a warm cache.
+var noCache bool
+
+// cachedChange mirrors pb.Change; blobs stay encrypted on disk.
+type cachedChange struct {
+	ID        string    `json:"id"`
+	Ver       int64     `json:"ver"`
+	Deleted   bool      `json:"deleted"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Blob      []byte    `json:"blob,omitempty"`
+	// Filtered entries were excluded by the sync filter: only the blob's
+	// SHA-256 is kept, for gk verify.
+	Filtered bool   `json:"filtered,omitempty"`
+	BlobHash []byte `json:"blob_sha256,omitempty"`
+	// Newer entries were written in a payload schema this client cannot
+	// read, so their blob was not downloaded.
+	Newer bool `json:"newer_schema,omitempty"`
+}
+
+// vaultCache is the local snapshot maintained by syncd.
+type vaultCache struct {
+	UserID   string    `json:"user_id"`
+	SyncedAt time.Time `json:"synced_at"` // local clock, for freshness checks
+	// ServerTime and Watermark come from the server, so they stay correct
+	// when the local clock is wrong.
+	ServerTime time.Time      `json:"server_time,omitzero"`
+	Watermark  int64          `json:"watermark,omitempty"`
+	Changes    []cachedChange `json:"changes"`
+	Filter     *syncFilter    `json:"filter,omitempty"` // rules the snapshot was taken with
+}
+
+func cachePath() string { return filepath.Join(cfgDir(), "cache.json") }
+
+// saveCache writes the snapshot atomically so readers never see a partial file.
+func saveCache(c vaultCache) error {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return writeProfileFile(cachePath(), b)
+}
+
+func loadCache() (vaultCache, error) {
+	var c vaultCache
+	b, err := readProfileFile(cachePath())
+	if err != nil {
+		return c, err
+	}
+	return c, json.Unmarshal(b, &c)
+}
+
+// cacheFromChanges snapshots a full GetChanges response.
+func cacheFromChanges(userID string, cs []*pb.Change, at time.Time) vaultCache {
+	c := vaultCache{UserID: userID, SyncedAt: at, Changes: make([]cachedChange, 0, len(cs))}
+	for _, ch := range cs {
+		c.Changes = append(c.Changes, cachedChange{
+			ID:        ch.GetId(),
+			Ver:       ch.GetVer(),
+			Deleted:   ch.GetDeleted(),
+			UpdatedAt: ch.GetUpdatedAt().AsTime(),
+			Blob:      ch.GetBlobEnc().GetCiphertext(),
+			Newer:     ch.GetHints().GetSchemaVersion() > payloadSchema,
+		})
+	}
+	return c
+}
+
+// protoChanges converts cached entries back to wire messages for the shared
+// printers. Items excluded by the sync filter or written in a newer schema
+// are left out.
+func (c vaultCache) protoChanges() []*pb.Change {
+	out := make([]*pb.Change, 0, len(c.Changes))
+	for _, cc := range c.Changes {
+		if cc.Filtered || cc.Newer {
+			continue
+		}
+		ch := &pb.Change{}
+		ch.SetId(cc.ID)
+		ch.SetVer(cc.Ver)
+		ch.SetDeleted(cc.Deleted)
+		ch.SetUpdatedAt(timestamppb.New(cc.UpdatedAt))
+		if cc.Blob != nil {
+			eb := &pb.EncryptedBlob{}
+			eb.SetCiphertext(cc.Blob)
+			ch.SetBlobEnc(eb)
+		}
+		out = append(out, ch)
+	}
+	return out
+}
+
+// warmChanges returns the cached vault when a running syncd vouches for it:
+// the daemon answers on its socket, the cache belongs to the current user and
+// the last successful sync is no older than three poll intervals.
+func warmChanges() ([]*pb.Change, bool) {
+	if noCache {
+		return nil, false
+	}
+	st, err := querySyncd()
+	if err != nil || st.LastSync.IsZero() {
+		return nil, false
+	}
+	uid, err := loadUserID()
+	if err != nil || uid != st.UserID {
+		return nil, false
+	}
+	c, err := loadCache()
+	if err != nil || c.UserID != uid {
+		return nil, false
+	}
+	if time.Since(c.SyncedAt) > 3*st.Interval {
+		return nil, false
+	}
+	return c.protoChanges(), true
+}
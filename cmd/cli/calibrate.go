@@ -0,0 +1,159 @@
+// cmd/cli/calibrate.go
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"time"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/internal/crypto/clientcrypto"
+)
+
+// calibration is the gk calibrate report.
+type calibration struct {
+	Target      time.Duration           `json:"target"`
+	Recommended clientcrypto.KDFParams  `json:"recommended"`
+	Measured    time.Duration           `json:"measured"`
+	Current     *clientcrypto.KDFParams `json:"current,omitempty"` // with -apply
+	Weaker      bool                    `json:"weaker_than_default,omitempty"`
+	Applied     bool                    `json:"applied,omitempty"`
+}
+
+// measureKDF times one KEK derivation with p.
+func measureKDF(p clientcrypto.KDFParams) time.Duration {
+	start := time.Now()
+	_ = p.DeriveKEK([]byte("calibration password"), []byte("calibration salt"))
+	return time.Since(start)
+}
+
+// recommendKDF picks Argon2id parameters that take about target on this
+// machine: memory first (doubling from the default while one pass at twice
+// the memory would still take at most half the budget, up to maxMemKiB), then
+// as many passes as fit, at most 16. It returns the parameters and the
+// duration measured for them.
+func recommendKDF(target time.Duration, maxMemKiB uint32, threads uint8, measure func(clientcrypto.KDFParams) time.Duration) (clientcrypto.KDFParams, time.Duration) {
+	p := clientcrypto.KDFParams{Time: 1, MemoryKiB: min(clientcrypto.DefaultKDF.MemoryKiB, maxMemKiB), Threads: threads}
+	d := measure(p)
+	for p.MemoryKiB*2 <= maxMemKiB && 4*d <= target {
+		p.MemoryKiB *= 2
+		d = measure(p)
+	}
+	if d > 0 {
+		p.Time = uint32(max(1, min(int64(target/d), 16)))
+	}
+	if p.Time > 1 {
+		d = measure(p)
+	}
+	return p, d
+}
+
+// cmdCalibrate benchmarks Argon2id and, with -apply, re-wraps the DEK under
+// the recommended parameters. The DEK itself does not change, so no item
+// needs re-encrypting.
+func cmdCalibrate(args []string, addr, caPath string, insecure bool) {
+	fs := flag.NewFlagSet("calibrate", flag.ExitOnError)
+	target := fs.Duration("target", 500*time.Millisecond, "desired unlock time")
+	maxMem := fs.Uint("max-memory", 1024, "memory ceiling, MiB")
+	threads := fs.Uint("threads", uint(min(runtime.NumCPU(), 4)), "Argon2 lanes")
+	apply := fs.Bool("apply", false, "re-wrap the DEK with the recommended parameters (needs -u, -p)")
+	weaker := fs.Bool("allow-weaker", false, "with -apply, accept parameters cheaper than the current ones")
+	u := fs.String("u", "", "username (for -apply)")
+	pw := fs.String("p", "", "password (for -apply)")
+	parseFlags(fs, args)
+	if *target <= 0 || *maxMem < 1 || *maxMem > 4096 || *threads < 1 || *threads > 255 {
+		fmt.Fprintln(os.Stderr, tr("calibrate: need -target > 0, -max-memory 1..4096, -threads 1..255"))
+		os.Exit(exitUsage)
+	}
+	if *apply && (*u == "" || *pw == "") {
+		fmt.Fprintln(os.Stderr, tr("calibrate -apply: need -u and -p"))
+		os.Exit(exitUsage)
+	}
+
+	rec, d := recommendKDF(*target, uint32(*maxMem)*1024, uint8(*threads), measureKDF)
+	c := calibration{Target: *target, Recommended: rec, Measured: d, Weaker: rec.Cost() < clientcrypto.DefaultKDF.Cost()}
+	if *apply {
+		cur, err := rewrapDEK(addr, caPath, insecure, *u, *pw, rec, *weaker)
+		if err != nil {
+			fail(err)
+		}
+		c.Current, c.Applied = &cur, true
+	}
+	emit(c, func() { printCalibration(c) })
+}
+
+// rewrapDEK logs in, unwraps the DEK with its current parameters and stores it
+// wrapped under next. The swap is conditional on the wrapped DEK not having
+// changed since the login. It returns the parameters in use before.
+func rewrapDEK(addr, caPath string, insecure bool, user, password string, next clientcrypto.KDFParams, allowWeaker bool) (clientcrypto.KDFParams, error) {
+	ctx, cancel := withTimeout()
+	defer cancel()
+	conn, cli, err := dial(ctx, addr, caPath, insecure, "")
+	if err != nil {
+		return clientcrypto.KDFParams{}, err
+	}
+	defer conn.Close()
+
+	lr := &pb.LoginRequest{}
+	lr.SetUsername(user)
+	lr.SetPassword(password)
+	resp, err := withChallenge("", func(answer string) (*pb.LoginResponse, error) {
+		lr.SetChallengeResponse(answer)
+		return cli.Login(ctx, lr)
+	})
+	if err != nil {
+		return clientcrypto.KDFParams{}, err
+	}
+	prev := resp.GetWrappedDek()
+	if len(prev) == 0 {
+		return clientcrypto.KDFParams{}, fmt.Errorf("%w: no wrapped DEK yet; log in once first", errInvalidInput)
+	}
+	cur, err := clientcrypto.WrappedKDF(prev)
+	if err != nil {
+		return cur, fmt.Errorf("wrapped DEK: %w: %w", errCrypto, err)
+	}
+	if next.Cost() < cur.Cost() && !allowWeaker {
+		return cur, fmt.Errorf("%w: %s is cheaper than the current %s (use -allow-weaker)", errInvalidInput, next, cur)
+	}
+	dek, err := clientcrypto.UnwrapDEK(cur.DeriveKEK([]byte(password), resp.GetKekSalt()), prev)
+	if err != nil {
+		return cur, fmt.Errorf("unwrap DEK: %w: %w", errCrypto, err)
+	}
+	if local, err := loadDEK(); err == nil && !bytes.Equal(local, dek) {
+		return cur, fmt.Errorf("%w: server DEK differs from the local one", errCrypto)
+	}
+	wrapped, err := clientcrypto.WrapDEKWithParams(next.DeriveKEK([]byte(password), resp.GetKekSalt()), dek, next)
+	if err != nil {
+		return cur, err
+	}
+
+	conn2, cli2, err := dial(ctx, addr, caPath, insecure, resp.GetAccessToken())
+	if err != nil {
+		return cur, err
+	}
+	defer conn2.Close()
+	req := &pb.SetWrappedDEKRequest{}
+	req.SetWrappedDek(wrapped)
+	req.SetPreviousWrappedDek(prev)
+	if _, err := cli2.SetWrappedDEK(ctx, req); err != nil {
+		return cur, err
+	}
+	return cur, nil
+}
+
+func printCalibration(c calibration) {
+	fmt.Printf(tr("recommended: %s (%s measured, target %s)\n"), c.Recommended, c.Measured.Round(time.Millisecond), c.Target)
+	p := colors(os.Stdout)
+	if c.Weaker {
+		fmt.Print(p.warn(tr("warning: weaker than the default %s; this machine is slow for the target\n", clientcrypto.DefaultKDF)))
+	}
+	switch {
+	case c.Applied:
+		fmt.Print(p.ok(tr("applied: DEK re-wrapped (was %s)\n", *c.Current)))
+	case c.Current == nil:
+		fmt.Println(tr("run with -apply -u USER -p PASS to use these parameters"))
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/and161185/goph-keeper/internal/crypto/clientcrypto"
+)
+
+func Test_recommendKDF(t *testing.T) {
+	// 10ms per pass per 64 MiB
+	fake := func(p clientcrypto.KDFParams) time.Duration {
+		return time.Duration(p.Time) * time.Duration(p.MemoryKiB/(64*1024)) * 10 * time.Millisecond
+	}
+
+	p, d := recommendKDF(time.Second, 1024*1024, 2, fake)
+	// memory grows to 1 GiB (160ms/pass), then 6 passes fit in 1s
+	want := clientcrypto.KDFParams{Time: 6, MemoryKiB: 1024 * 1024, Threads: 2}
+	if p != want || d != 960*time.Millisecond {
+		t.Fatalf("got %v in %s, want %v", p, d, want)
+	}
+
+	p, _ = recommendKDF(time.Second, 256*1024, 1, fake)
+	if p.MemoryKiB != 256*1024 || p.Time != 16 {
+		t.Fatalf("memory ceiling / pass cap: %v", p)
+	}
+
+	p, _ = recommendKDF(5*time.Millisecond, 1024*1024, 1, fake)
+	if p.MemoryKiB != 64*1024 || p.Time != 1 {
+		t.Fatalf("slow machine: %v", p)
+	}
+	if p.Cost() >= clientcrypto.DefaultKDF.Cost() {
+		t.Fatalf("expected a weaker-than-default recommendation")
+	}
+}
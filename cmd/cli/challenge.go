@@ -0,0 +1,42 @@
+// cmd/cli/challenge.go
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"google.golang.org/grpc/status"
+
+	"github.com/and161185/goph-keeper/internal/challenge"
+	"github.com/and161185/goph-keeper/internal/errs"
+)
+
+// maxPoWBits bounds the work a server can make the client do (2^28 hashes
+// is tens of seconds).
+const maxPoWBits = 28
+
+// withChallenge runs call with captcha as the challenge answer and, if the
+// server asks for a proof of work instead, solves it and runs call once
+// more. A CAPTCHA cannot be solved here; the user is told to pass one.
+func withChallenge[T any](captcha string, call func(answer string) (T, error)) (T, error) {
+	resp, err := call(captcha)
+	if err == nil {
+		return resp, nil
+	}
+	s, _ := status.FromError(err)
+	info := errorInfo(s)
+	if info == nil || info.GetReason() != errs.ReasonChallengeRequired {
+		return resp, err
+	}
+	meta := info.GetMetadata()
+	if meta["kind"] != challenge.KindPoW {
+		fmt.Fprint(os.Stderr, tr("the server wants a %s CAPTCHA solved (site key %s); pass its response token with -captcha\n", meta["kind"], meta["site_key"]))
+		return resp, err
+	}
+	bits, perr := strconv.Atoi(meta["difficulty"])
+	if perr != nil || bits < 1 || bits > maxPoWBits || meta["token"] == "" {
+		return resp, err
+	}
+	return call(challenge.Solve(meta["token"], bits))
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/and161185/goph-keeper/internal/challenge"
+	"github.com/and161185/goph-keeper/internal/errs"
+)
+
+func challengeErr(meta map[string]string) error {
+	st, _ := status.New(codes.Unauthenticated, "challenge required").
+		WithDetails(&errdetails.ErrorInfo{Reason: errs.ReasonChallengeRequired, Domain: errs.Domain, Metadata: meta})
+	return st.Err()
+}
+
+func Test_withChallenge(t *testing.T) {
+	pow, err := challenge.NewPoW(8, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, _ := pow.Issue()
+	var answers []string
+	call := func(answer string) (string, error) {
+		answers = append(answers, answer)
+		if err := pow.Verify(context.Background(), answer, ""); err != nil {
+			return "", challengeErr(map[string]string{"kind": c.Kind, "token": c.Token, "difficulty": "8"})
+		}
+		return "ok", nil
+	}
+	if got, err := withChallenge("", call); err != nil || got != "ok" || len(answers) != 2 {
+		t.Fatalf("proof of work: %q %v after %d calls", got, err, len(answers))
+	}
+
+	// a CAPTCHA is left to the user, and so is an unreasonable difficulty
+	for _, meta := range []map[string]string{
+		{"kind": challenge.KindTurnstile, "site_key": "site"},
+		{"kind": challenge.KindPoW, "token": "t", "difficulty": "40"},
+	} {
+		answers = nil
+		_, err := withChallenge("", func(answer string) (string, error) {
+			answers = append(answers, answer)
+			return "", challengeErr(meta)
+		})
+		if classify(err).Reason != errs.ReasonChallengeRequired || len(answers) != 1 {
+			t.Fatalf("%v: %v after %d calls", meta, err, len(answers))
+		}
+	}
+
+	answers = nil
+	_, err = withChallenge("captcha-token", func(answer string) (string, error) {
+		answers = append(answers, answer)
+		return "", status.Error(codes.Unauthenticated, "bad credentials")
+	})
+	if status.Code(err) != codes.Unauthenticated || len(answers) != 1 || answers[0] != "captcha-token" {
+		t.Fatalf("other errors: %v %v", err, answers)
+	}
+}
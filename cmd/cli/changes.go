@@ -0,0 +1,57 @@
+// cmd/cli/changes.go
+package main
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+)
+
+// fetchChanges follows GetChanges from req through its last page and
+// returns the pages merged: every change, the first page's server time
+// (everything committed by then is included) and the last page's
+// watermarks.
+func fetchChanges(ctx context.Context, cli pb.GophKeeperClient, req *pb.GetChangesRequest) (*pb.GetChangesResponse, error) {
+	out, err := cli.GetChanges(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	next := proto.CloneOf(req)
+	for out.GetNextPageToken() != "" {
+		next.SetPageToken(out.GetNextPageToken())
+		page, err := cli.GetChanges(ctx, next)
+		if err != nil {
+			return nil, err
+		}
+		out.SetChanges(append(out.GetChanges(), page.GetChanges()...))
+		out.SetWatermark(page.GetWatermark())
+		if page.HasSeqWatermark() {
+			out.SetSeqWatermark(page.GetSeqWatermark())
+		}
+		out.SetNextPageToken(page.GetNextPageToken())
+	}
+	return out, nil
+}
+
+// fetchBaseline reads the whole vault as of one moment: a BeginSync session
+// paged to its end, so writes from other devices while it runs do not tear
+// it. The result's seq_watermark is where the journal continues. Servers
+// without BeginSync are read with GetChanges from version 0.
+func fetchBaseline(ctx context.Context, cli pb.GophKeeperClient) (*pb.GetChangesResponse, error) {
+	begun, err := cli.BeginSync(ctx, &pb.BeginSyncRequest{})
+	if status.Code(err) == codes.Unimplemented {
+		req := &pb.GetChangesRequest{}
+		req.SetSinceVer(0)
+		return fetchChanges(ctx, cli, req)
+	}
+	if err != nil {
+		return nil, err
+	}
+	req := &pb.GetChangesRequest{}
+	req.SetSyncSession(begun.GetSyncSession())
+	return fetchChanges(ctx, cli, req)
+}
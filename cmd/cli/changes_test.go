@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gofrs/uuid/v5"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/pkg/gktest"
+)
+
+func Test_fetchChanges_FollowsPages(t *testing.T) {
+	srv := gktest.Start(t, gktest.WithMaxChangesPage(2))
+	cli := srv.Client(t)
+	_, token := srv.NewUser(t, "alice", "secret")
+	ctx := gktest.WithToken(context.Background(), token)
+
+	for range 5 {
+		if _, err := sendOne(ctx, cli, uuid.Must(uuid.NewV7()).String(), 0, []byte("blob")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	out, err := fetchChanges(ctx, cli, &pb.GetChangesRequest{})
+	if err != nil || len(out.GetChanges()) != 5 || out.GetWatermark() != 1 || out.GetNextPageToken() != "" {
+		t.Fatalf("merged: %d changes, watermark %d, token %q, %v",
+			len(out.GetChanges()), out.GetWatermark(), out.GetNextPageToken(), err)
+	}
+}
+
+func Test_fetchChanges_SeqWatermark(t *testing.T) {
+	srv := gktest.Start(t, gktest.WithMaxChangesPage(2))
+	cli := srv.Client(t)
+	_, token := srv.NewUser(t, "alice", "secret")
+	ctx := gktest.WithToken(context.Background(), token)
+
+	for range 3 {
+		if _, err := sendOne(ctx, cli, uuid.Must(uuid.NewV7()).String(), 0, []byte("blob")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req := &pb.GetChangesRequest{}
+	req.SetSinceSeq(0)
+	out, err := fetchChanges(ctx, cli, req)
+	if err != nil || len(out.GetChanges()) != 3 || out.GetSeqWatermark() != 3 {
+		t.Fatalf("merged: %d changes, seq %d, %v", len(out.GetChanges()), out.GetSeqWatermark(), err)
+	}
+}
+
+func Test_fetchBaseline(t *testing.T) {
+	srv := gktest.Start(t, gktest.WithMaxChangesPage(2))
+	cli := srv.Client(t)
+	_, token := srv.NewUser(t, "alice", "secret")
+	ctx := gktest.WithToken(context.Background(), token)
+
+	for range 3 {
+		if _, err := sendOne(ctx, cli, uuid.Must(uuid.NewV7()).String(), 0, []byte("blob")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	out, err := fetchBaseline(ctx, cli)
+	if err != nil || len(out.GetChanges()) != 3 || out.GetSeqWatermark() != 3 || out.GetNextPageToken() != "" {
+		t.Fatalf("baseline: %d changes, seq %d, %v", len(out.GetChanges()), out.GetSeqWatermark(), err)
+	}
+}
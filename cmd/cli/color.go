@@ -0,0 +1,77 @@
+// cmd/cli/color.go
+package main
+
+import (
+	"io"
+	"os"
+	"strings"
+)
+
+// noColor is -no-color; the NO_COLOR environment variable has the same effect.
+var noColor bool
+
+// ANSI SGR codes used by palette.
+const (
+	sgrRed    = "31"
+	sgrGreen  = "32"
+	sgrYellow = "33"
+	sgrCyan   = "36"
+	sgrDim    = "2"
+	sgrBold   = "1"
+)
+
+// palette highlights statuses on one output stream; its zero value prints
+// plain text.
+type palette struct{ on bool }
+
+// colors returns the palette for w. Color is used only when w is a terminal
+// and neither -no-color, NO_COLOR nor TERM=dumb asks otherwise, so pipes,
+// files and test buffers always get plain text.
+func colors(w io.Writer) palette {
+	f, ok := w.(*os.File)
+	if !ok || noColor || os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return palette{}
+	}
+	return palette{on: ansiTerminal(f)}
+}
+
+// paint wraps s in an SGR sequence, keeping a trailing newline outside it.
+func (p palette) paint(sgr, s string) string {
+	if !p.on || s == "" {
+		return s
+	}
+	body, nl := strings.CutSuffix(s, "\n")
+	s = "\033[" + sgr + "m" + body + "\033[0m"
+	if nl {
+		s += "\n"
+	}
+	return s
+}
+
+func (p palette) ok(s string) string   { return p.paint(sgrGreen, s) }
+func (p palette) bad(s string) string  { return p.paint(sgrRed, s) }
+func (p palette) warn(s string) string { return p.paint(sgrYellow, s) }
+func (p palette) info(s string) string { return p.paint(sgrCyan, s) }
+func (p palette) dim(s string) string  { return p.paint(sgrDim, s) }
+
+// diff colors a unified diff line by line: removals red, additions green,
+// hunk headers cyan.
+func (p palette) diff(s string) string {
+	if !p.on {
+		return s
+	}
+	lines := strings.SplitAfter(s, "\n")
+	for i, l := range lines {
+		switch {
+		case strings.HasPrefix(l, "---"), strings.HasPrefix(l, "+++"):
+			lines[i] = p.paint(sgrBold, l)
+		case strings.HasPrefix(l, "@@"):
+			lines[i] = p.info(l)
+		case strings.HasPrefix(l, "-"):
+			lines[i] = p.bad(l)
+		case strings.HasPrefix(l, "+"):
+			lines[i] = p.ok(l)
+		}
+	}
+	return strings.Join(lines, "")
+}
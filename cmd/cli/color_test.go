@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func Test_palette(t *testing.T) {
+	if got := (palette{}).bad("conflict\n"); got != "conflict\n" {
+		t.Fatalf("plain palette colored: %q", got)
+	}
+	p := palette{on: true}
+	if got := p.bad("conflict\n"); got != "\033[31mconflict\033[0m\n" {
+		t.Fatalf("bad: %q", got)
+	}
+	if got := p.ok("ok"); got != "\033[32mok\033[0m" {
+		t.Fatalf("ok: %q", got)
+	}
+	if got := p.warn(""); got != "" {
+		t.Fatalf("empty: %q", got)
+	}
+
+	diff := "--- a\n+++ b\n@@ -1 +1 @@\n-old\n+new\n same\n"
+	want := "\033[1m--- a\033[0m\n\033[1m+++ b\033[0m\n\033[36m@@ -1 +1 @@\033[0m\n" +
+		"\033[31m-old\033[0m\n\033[32m+new\033[0m\n same\n"
+	if got := p.diff(diff); got != want {
+		t.Fatalf("diff:\n%q\nwant\n%q", got, want)
+	}
+}
+
+func Test_colorsDisabled(t *testing.T) {
+	if colors(&bytes.Buffer{}).on {
+		t.Fatal("color for a buffer")
+	}
+	f, err := os.CreateTemp(t.TempDir(), "out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if colors(f).on {
+		t.Fatal("color for a regular file")
+	}
+
+	t.Setenv("NO_COLOR", "1")
+	if colors(os.Stdout).on {
+		t.Fatal("NO_COLOR ignored")
+	}
+	t.Setenv("NO_COLOR", "")
+	defer func(v bool) { noColor = v }(noColor)
+	noColor = true
+	if colors(os.Stdout).on {
+		t.Fatal("-no-color ignored")
+	}
+}
@@ -0,0 +1,328 @@
+// cmd/cli/commands.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// serverFlags are the global flags commands dial the server with.
+type serverFlags struct {
+	addr, caPath string
+	insecure     bool
+}
+
+// command is a node of gk's command tree: a group of subcommands, a
+// command that runs, or both (item add runs itself unless its first
+// argument names a subcommand, as in item add login).
+type command struct {
+	name    string
+	aliases []string
+	flat    string // top-level name from before commands were grouped; still accepted
+	args    string // argument synopsis for help
+	summary string // one line for help; a tr key
+	run     func(args []string, sf serverFlags)
+	lock    lockMode // how run holds the profile lock; shared unless set
+	subs    []*command
+}
+
+// remote adapts a command that dials the server.
+func remote(f func(args []string, addr, caPath string, insecure bool)) func([]string, serverFlags) {
+	return func(args []string, sf serverFlags) { f(args, sf.addr, sf.caPath, sf.insecure) }
+}
+
+// local adapts a command that only touches local state.
+func local(f func(args []string)) func([]string, serverFlags) {
+	return func(args []string, _ serverFlags) { f(args) }
+}
+
+// commandTree is gk's command tree. Flag sets keep the flat names, so
+// GK_<COMMAND>_<FLAG> variables did not change with the grouping.
+func commandTree() *command {
+	return &command{name: "gk", subs: []*command{
+		{name: "version", summary: "print gk's version", lock: lockNone, run: func([]string, serverFlags) {
+			fmt.Printf("gk %s (%s)\n", version, buildDate)
+		}},
+		{name: "account", aliases: []string{"acct"}, summary: "create, log in to and look after your account", subs: []*command{
+			{name: "register", flat: "register", args: "-u <username> -p <password> [-captcha <token>]",
+				summary: "create an account and print its user id", run: remote(cmdRegister)},
+			{name: "signup", flat: "signup", args: "-u <username> -p <password> [-captcha <token>] [-label L]",
+				summary: "register, log in and set up the DEK in one step", run: remote(cmdSignup), lock: lockExclusive},
+			{name: "login", flat: "login", args: "-u <username> -p <password> [-captcha <token>] [-label L]",
+				summary: "saves token; -label names this device, default hostname", run: remote(cmdLogin), lock: lockExclusive},
+			{name: "logins", flat: "logins", args: "[-n 20]",
+				summary: "recent logins and wrong passwords on your account", run: remote(cmdLogins)},
+			{name: "sessions", flat: "sessions", args: "[-revoke ID]",
+				summary: "devices signed in to your account, with last use", run: remote(cmdSessions)},
+			{name: "duress", flat: "duress", args: "setup -u <username> -p <duress password> [-copy ID,ID] | remove",
+				summary: "a second password that opens a decoy vault", run: remote(cmdDuress)},
+			{name: "freeze", flat: "freeze", args: "-p <password>",
+				summary: "refuse every change to the vault until unfreeze", run: remote(cmdFreeze)},
+			{name: "unfreeze", flat: "unfreeze", args: "-p <password>",
+				summary: "accept changes to the vault again", run: remote(cmdUnfreeze)},
+			{name: "profile", flat: "profile", args: "encrypt | decrypt | status",
+				summary: "seal token, DEK, user id and cache under a key in the OS keychain", run: local(cmdProfile), lock: lockExclusive},
+			{name: "recovery", flat: "recovery", args: "keygen -key F -pub F | wrap -pub F -out F | restore -key F -in F [-force]",
+				summary: "offline DEK copy, X25519+ML-KEM-768", run: local(cmdRecovery), lock: lockExclusive},
+			{name: "calibrate", flat: "calibrate", args: "[-target 500ms] [-max-memory 1024] [-threads N] [-apply -u U -p P [-allow-weaker]]",
+				summary: "tune Argon2id for this machine", run: remote(cmdCalibrate)},
+		}},
+		{name: "item", aliases: []string{"items"}, summary: "read, add, change and delete vault items", subs: []*command{
+			{name: "list", aliases: []string{"ls"}, flat: "list",
+				summary: "GetChanges since 0", run: remote(cmdList)},
+			{name: "sync", flat: "sync", args: "[-since <ver>] [-nag-stale 180d] [-all]",
+				summary: "-nag-stale: remind about old passwords on stderr; -all: ignore sync-filter", run: remote(cmdSync)},
+			{name: "sync-filter", flat: "sync-filter", args: "[-include type:T|tag:T|folder:P] [-exclude ...] [-remove R] [-clear]",
+				summary: "which items sync lists and syncd mirrors", run: local(cmdSyncFilter), lock: lockExclusive},
+			{name: "pin-device", flat: "pin-device", args: "-id <uuid> [-device <id>]... [-unpin]",
+				summary: "keep an item offline on this device only (plus each -device)", run: remote(cmdPinDevice)},
+			{name: "get", flat: "get", args: "-id <uuid>",
+				summary: "print an item's type, meta and data size", run: remote(cmdGet)},
+			{name: "show", flat: "show", args: "-id <uuid> [-out F] [-reveal] [-qr]",
+				summary: "print a typed item; -out writes binary data to a file", run: remote(cmdShow)},
+			{name: "add", flat: "add", args: "-id <uuid> -file <blob> [-dry-run] | -i [-dry-run]",
+				summary: "base_ver=0; -i: wizard: pick a type, answer prompts; secrets are not echoed", run: remote(cmdAdd), subs: []*command{
+					{name: "login", flat: "add-login", args: "-title T [-url U] [-username U] [-password P] [-note N]",
+						summary: "add a login", run: remote(cmdAddLogin)},
+					{name: "text", flat: "add-text", args: "-title T -text T [-note N]",
+						summary: "add a text note", run: remote(cmdAddText)},
+					{name: "card", flat: "add-card", args: "-title T -name N -number N -exp MM/YY -cvc C [-note N]",
+						summary: "add a bank card", run: remote(cmdAddCard)},
+					{name: "binary", flat: "add-binary", args: "-title T -file F [-note N]",
+						summary: "add a file", run: remote(cmdAddBinary)},
+					{name: "otp", flat: "add-otp", args: "-title T -secret S [-issuer I] [-digits 6] [-period 30]",
+						summary: "add a TOTP secret", run: remote(cmdAddOTP)},
+					{name: "wifi", flat: "add-wifi", args: "-ssid S [-psk K] [-security WPA|WPA3|WEP|nopass] [-hidden] [-title T] [-note N]",
+						summary: "add a Wi-Fi network; show -qr prints it for a QR code", run: remote(cmdAddWifi)},
+					{name: "license", flat: "add-license", args: "-key K [-title T] [-product P] [-seats N] [-purchased YYYY-MM-DD] [-expires YYYY-MM-DD] [-note N]",
+						summary: "add a software license", run: remote(cmdAddLicense)},
+					{name: "bank", flat: "add-bank", args: "-holder H -iban I [-bic B] [-bank N] [-title T] [-note N]",
+						summary: "add a bank account; show masks the IBAN", run: remote(cmdAddBank)},
+					{name: "apikey", flat: "add-apikey", args: "-key K [-title T] [-env prod] [-rotate-by YYYY-MM-DD] [-note N]",
+						summary: "add an API key", run: remote(cmdAddAPIKey)},
+				}},
+			{name: "edit", flat: "edit", args: "-id <uuid> -base <ver> -file <blob> [-dry-run]",
+				summary: "replace an item's data with a file", run: remote(cmdEdit)},
+			{name: "rm", aliases: []string{"remove"}, flat: "rm", args: "[-dry-run] -id <uuid> -base <ver> | <uuid>:<ver>...",
+				summary: "several items: all deleted or none", run: remote(cmdRm)},
+			{name: "totp", flat: "totp", args: "-id <uuid> [-id <uuid>...] [-watch]",
+				summary: "current OTP codes; -watch refreshes with countdown", run: remote(cmdTOTP), lock: lockNone},
+			{name: "open", flat: "open", args: "-id <uuid> | -title <t> [-copy]",
+				summary: "open login URL in browser; -copy puts password on clipboard", run: remote(cmdOpen)},
+			{name: "field", flat: "field", args: "-id <uuid> -name <field> [-copy]",
+				summary: "print/copy one decrypted field, e.g. username|password|cvc|secret", run: remote(cmdField)},
+			{name: "bulk-add", flat: "bulk-add", args: "[-file items.json|-] [-on-conflict fail|keep-both|server|local] [-dry-run]",
+				summary: "encrypt+upload a JSON array of {type,meta,data[,id,base_ver]}", run: remote(cmdBulkAdd)},
+			{name: "export", flat: "export", args: "[-out F|-] [-ids]",
+				summary: "decrypt the whole vault into the JSON array bulk-add takes", run: remote(cmdExport)},
+			{name: "dedupe", flat: "dedupe", args: "[-by url|password|both] [-list]",
+				summary: "find duplicate logins; interactive merge/delete", run: remote(cmdDedupe)},
+			{name: "search", flat: "search", args: "[-type T] <text>",
+				summary: "search titles, meta and custom fields; never secrets", run: remote(cmdSearch)},
+			{name: "template", flat: "template", args: "save -name N [-field f[=default]] [-hidden-field f] [-replace] | apply -name N [-title T] [-set f=value] [-note N] | list",
+				summary: "apply prompts for missing values", run: remote(cmdTemplate)},
+			{name: "audit", flat: "audit", args: "[-stale 180d]",
+				summary: "stale passwords, weak Wi-Fi keys, expired licenses and overdue API keys", run: remote(cmdAudit)},
+			{name: "expiring", flat: "expiring", args: "[-within 30d]",
+				summary: "licenses that expire and API keys due for rotation soon", run: remote(cmdExpiring)},
+			{name: "stats", flat: "stats", args: "[-top 5]",
+				summary: "counts by type, sizes, recent items, cache vs server cursor", run: remote(cmdStats)},
+			{name: "history", flat: "history", args: "diff -id <uuid> -from 3 [-to 5] [-show-secrets] [-U 3]",
+				summary: "unified diff of two versions", run: remote(cmdHistory)},
+			{name: "accesses", flat: "accesses", args: "-id <uuid>",
+				summary: "which devices fetched an item, when and how often", run: remote(cmdAccesses)},
+			{name: "import", flat: "import", args: "-format pass [-dir ~/.password-store] [-gpg gpg] | -format chrome|firefox|edge|csv -file export.csv [-dry-run]",
+				summary: "import logins; folders kept in meta; skips existing url+username", run: remote(cmdImport)},
+			{name: "verify", flat: "verify",
+				summary: "compare a Merkle root of the local cache with the server's", run: remote(cmdVerify)},
+		}},
+		{name: "syncd", args: "[-interval 30s] [-ntfy URL] [-long-poll] | status",
+			summary: "keep a local cache warm; list/open -title/... read from it", run: remote(cmdSyncd), lock: lockNone},
+		{name: "webhook", args: "add -url https://... | list | rm -id <uuid>",
+			summary: "change notifications: id, version, deleted flag; never data", run: remote(cmdWebhook)},
+		{name: "device", args: "add -platform fcm|apns|ntfy [-token T] [-name N] | list | rm -id <uuid>",
+			summary: `push "vault changed" to a device`, run: remote(cmdDevice)},
+		{name: "server", summary: "what the server offers", subs: []*command{
+			{name: "info", flat: "server-info",
+				summary: "server version and features, and whether this gk suits it", run: func(_ []string, sf serverFlags) {
+					cmdServerInfo(sf.addr, sf.caPath, sf.insecure)
+				}},
+			{name: "keys", flat: "server-keys", args: "[-add KEY,KEY] [-rm KEY,KEY] [-fetch]",
+				summary: "public keys pinned for -addr; -fetch also shows the one it presents", run: remote(cmdServerKeys), lock: lockExclusive},
+		}},
+		{name: "admin", summary: "server administration; needs an admin token", subs: []*command{
+			{name: "diag", flat: "admin-diag", args: "-on | -off",
+				summary: "toggle server diagnostics", run: remote(cmdAdminDiag)},
+			{name: "rollback", flat: "admin-rollback", args: "-user <uuid> -to 2026-03-01T12:00:00Z|2h [-dry-run]",
+				summary: "roll a user's items back to that time", run: remote(cmdAdminRollback)},
+			{name: "check", flat: "admin-check", args: "-user <uuid> [-repair]",
+				summary: "check a user's stored items for divergence", run: remote(cmdAdminCheck)},
+			{name: "suspend", flat: "admin-suspend", args: "-user <uuid>",
+				summary: "suspend an account; its tokens stop working at once", run: remote(cmdAdminSuspend)},
+			{name: "reactivate", flat: "admin-reactivate", args: "-user <uuid>",
+				summary: "lift an account's suspension", run: remote(cmdAdminReactivate)},
+			{name: "usage", flat: "admin-usage", args: "[-tenant T] [-limit N] [-days N]",
+				summary: "largest users' storage and its growth", run: remote(cmdAdminUsage)},
+			{name: "locks", flat: "admin-locks", args: "[-clear -user U [-tenant T] [-ip-hash HEX]]",
+				summary: "list or lift login lockouts", run: remote(cmdAdminLocks)},
+			{name: "ip", flat: "admin-ip", args: "-user U [-tenant T] [-allow CIDR | -deny CIDR | -rm CIDR]",
+				summary: "a user's address rules", run: remote(cmdAdminIP)},
+		}},
+		{name: "telemetry", args: "on -url URL | off | status",
+			summary: "opt in to sending command names, durations and error classes; nothing else", run: local(cmdTelemetry), lock: lockNone},
+		{name: "help", args: "[command...]", summary: "help for a command or group", lock: lockNone, run: func(args []string, _ serverFlags) {
+			root := commandTree()
+			c, rest := root.lookup(args)
+			if len(rest) > 0 {
+				usage()
+			}
+			if c == root {
+				fmt.Print(rootHelp(root))
+				return
+			}
+			fmt.Print(c.help(root.pathTo(c)))
+		}},
+	}}
+}
+
+// find returns the subcommand called name, nil if there is none. The root
+// also answers to flat names.
+func (c *command) find(name string, root bool) *command {
+	for _, s := range c.subs {
+		if s.name == name || slices.Contains(s.aliases, name) {
+			return s
+		}
+	}
+	if root {
+		var found *command
+		c.walk(func(s *command) {
+			if s.flat == name {
+				found = s
+			}
+		})
+		return found
+	}
+	return nil
+}
+
+// pathTo is the command words leading from c to target, "" if target is
+// not below c.
+func (c *command) pathTo(target *command) string {
+	for _, s := range c.subs {
+		if s == target {
+			return s.name
+		}
+		if p := s.pathTo(target); p != "" {
+			return s.name + " " + p
+		}
+	}
+	return ""
+}
+
+// walk calls fn for every command below c.
+func (c *command) walk(fn func(*command)) {
+	for _, s := range c.subs {
+		fn(s)
+		s.walk(fn)
+	}
+}
+
+// resolve follows the command words at the start of args. Global flags may
+// follow a group's name (gk item -format table list); they are parsed into
+// flag.CommandLine as they come. It returns the command and its arguments.
+func (c *command) resolve(args []string) (*command, []string) {
+	for len(args) > 0 {
+		s := c.find(args[0], c.name == "gk")
+		if s == nil {
+			break
+		}
+		c, args = s, args[1:]
+		if c.run == nil {
+			_ = flag.CommandLine.Parse(args)
+			args = flag.CommandLine.Args()
+		}
+	}
+	return c, args
+}
+
+// lookup is resolve without flags, for help: it returns the command args
+// name and the words that name nothing.
+func (c *command) lookup(args []string) (*command, []string) {
+	for len(args) > 0 {
+		s := c.find(args[0], c.name == "gk")
+		if s == nil {
+			break
+		}
+		c, args = s, args[1:]
+	}
+	return c, args
+}
+
+// lines lists the commands that run under c, one synopsis each, with path
+// before their names.
+func (c *command) lines(path string) [][2]string {
+	var out [][2]string
+	for _, s := range c.subs {
+		p := strings.TrimSpace(path + " " + s.name)
+		if s.run != nil {
+			syn := s.args
+			if s.summary != "" {
+				syn = strings.TrimSpace(syn + "  (" + tr(s.summary) + ")")
+			}
+			out = append(out, [2]string{p, syn})
+		}
+		out = append(out, s.lines(p)...)
+	}
+	return out
+}
+
+// writeLines prints lines with their synopses aligned.
+func writeLines(w io.Writer, lines [][2]string) {
+	width := 0
+	for _, l := range lines {
+		width = max(width, len(l[0]))
+	}
+	for _, l := range lines {
+		fmt.Fprintf(w, "  %-*s %s\n", width, l[0], l[1])
+	}
+}
+
+// rootHelp is gk's full help: every command, with the global flags.
+func rootHelp(root *command) string {
+	var b strings.Builder
+	b.WriteString(tr(usageHead))
+	writeLines(&b, root.lines(""))
+	b.WriteString(tr(usageTail))
+	return b.String()
+}
+
+// help is the help of a group or command reached as path.
+func (c *command) help(path string) string {
+	var b strings.Builder
+	if c.run != nil {
+		fmt.Fprintf(&b, "%s\n", strings.TrimSpace(tr("Usage: gk %s", path)+" "+c.args))
+	}
+	if len(c.subs) > 0 {
+		b.WriteString(tr("Usage: gk %s <command> [args]\n", path))
+	}
+	if c.summary != "" {
+		fmt.Fprintf(&b, "  %s\n", tr(c.summary))
+	}
+	if len(c.aliases) > 0 {
+		b.WriteString(tr("Aliases: %s\n", strings.Join(c.aliases, ", ")))
+	}
+	if c.flat != "" && c.flat != path {
+		b.WriteString(tr("Also: gk %s\n", c.flat))
+	}
+	if len(c.subs) > 0 {
+		b.WriteString(tr("\nCommands:\n"))
+		writeLines(&b, c.lines(""))
+	}
+	if c.run != nil {
+		b.WriteString(tr("\nRun gk %s -h for its flags.\n", path))
+	}
+	return b.String()
+}
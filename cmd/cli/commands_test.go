@@ -0,0 +1,113 @@
+package main
+
+import (
+	"flag"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func Test_commandTree_flatNames(t *testing.T) {
+	root := commandTree()
+	// every top-level command gk had before the groups
+	for _, name := range []string{
+		"version", "register", "signup", "login", "list", "sync", "get", "add", "edit", "rm",
+		"add-login", "add-text", "add-card", "add-binary", "add-otp", "show", "totp", "open", "field",
+		"bulk-add", "dedupe", "search", "template", "audit", "stats", "logins", "sessions", "server-info",
+		"verify", "recovery", "profile", "calibrate", "history", "import", "sync-filter", "syncd",
+		"webhook", "device", "admin-diag", "admin-rollback", "admin-locks", "admin-ip",
+	} {
+		if c, rest := root.lookup([]string{name}); c == root || c.run == nil || len(rest) > 0 {
+			t.Errorf("%s does not resolve to a command", name)
+		}
+	}
+
+	var check func(c *command)
+	check = func(c *command) {
+		seen := map[string]bool{}
+		for _, s := range c.subs {
+			for _, n := range append([]string{s.name}, s.aliases...) {
+				if seen[n] {
+					t.Errorf("%s: %s named twice", c.name, n)
+				}
+				seen[n] = true
+			}
+			check(s)
+		}
+	}
+	check(root)
+	if c, _ := root.lookup([]string{"add-login"}); root.pathTo(c) != "item add login" {
+		t.Errorf("add-login is at %q", root.pathTo(c))
+	}
+	root.walk(func(c *command) {
+		if c.flat == "" {
+			return
+		}
+		if top := root.find(c.flat, false); top != nil && top != c {
+			t.Errorf("flat name %s is taken by the top-level %s", c.flat, top.name)
+		}
+	})
+}
+
+func Test_command_resolve(t *testing.T) {
+	old := flag.CommandLine
+	t.Cleanup(func() { flag.CommandLine = old })
+	flag.CommandLine = flag.NewFlagSet("gk", flag.ContinueOnError)
+	format := flag.CommandLine.String("format", "", "")
+
+	root := commandTree()
+	for _, c := range []struct {
+		args []string
+		want string
+		rest []string
+	}{
+		{[]string{"item", "add", "login", "-title", "t"}, "login", []string{"-title", "t"}},
+		{[]string{"items", "ls"}, "list", nil},
+		{[]string{"add-login", "-title", "t"}, "login", []string{"-title", "t"}},
+		{[]string{"item", "add", "-file", "f"}, "add", []string{"-file", "f"}},
+		{[]string{"item", "-format", "table", "list"}, "list", nil},
+		{[]string{"item", "frob"}, "item", []string{"frob"}},
+		{[]string{"frob"}, "gk", []string{"frob"}},
+	} {
+		cmd, rest := root.resolve(c.args)
+		if cmd.name != c.want || !slices.Equal(rest, c.rest) {
+			t.Errorf("%v: %s %v", c.args, cmd.name, rest)
+		}
+	}
+	if *format != "table" {
+		t.Fatalf("global flag after a group: %q", *format)
+	}
+}
+
+func Test_rootHelp_listsAllCommands(t *testing.T) {
+	defer func(l string) { lang = l }(lang)
+	root := commandTree()
+	paths := root.lines("")
+	for _, l := range []string{"en", "ru"} {
+		lang = l
+		help := rootHelp(root)
+		for _, p := range paths {
+			if !strings.Contains(help, "  "+p[0]+" ") {
+				t.Errorf("%s help lacks %s", l, p[0])
+			}
+		}
+	}
+	lang = "en"
+	help := root.find("item", false).help("item")
+	if !strings.Contains(help, "Aliases: items") || !strings.Contains(help, "  add login ") {
+		t.Fatalf("group help:\n%s", help)
+	}
+}
+
+func Test_commandTree_locks(t *testing.T) {
+	root := commandTree()
+	for name, want := range map[string]lockMode{
+		"login": lockExclusive, "signup": lockExclusive, "profile": lockExclusive, "recovery": lockExclusive,
+		"sync-filter": lockExclusive, "list": lockShared, "get": lockShared, "add-login": lockShared,
+		"syncd": lockNone, "totp": lockNone,
+	} {
+		if c, _ := root.lookup([]string{name}); c.lock != want {
+			t.Errorf("%s: lock %d, want %d", name, c.lock, want)
+		}
+	}
+}
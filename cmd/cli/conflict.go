@@ -0,0 +1,220 @@
+// cmd/cli/conflict.go
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/internal/errs"
+)
+
+// Conflict policies for -on-conflict. With fail a batch is applied
+// atomically, so one stale base_ver rejects every item in it. Other policies
+// send batches best-effort: fresh items are stored at once, and stale ones
+// are settled against the server's current version and resent.
+const (
+	conflictFail     = "fail"      // stop, as without a policy
+	conflictKeepBoth = "keep-both" // keep the server's item, upload ours as a new "Conflicted copy"
+	conflictServer   = "server"    // drop our change
+	conflictLocal    = "local"     // overwrite the server's version with ours
+)
+
+// maxConflictRounds bounds resends when the items keep changing underneath.
+const maxConflictRounds = 3
+
+func parseConflictPolicy(s string) (string, error) {
+	switch s {
+	case "", conflictFail:
+		return conflictFail, nil
+	case conflictKeepBoth, conflictServer, conflictLocal:
+		return s, nil
+	}
+	return "", fmt.Errorf("%w: -on-conflict must be fail, keep-both, server or local", errInvalidInput)
+}
+
+// upsertOutcome is what became of one pending item.
+type upsertOutcome struct {
+	Ver        int64  // stored version; for a dropped change, the server's
+	Resolution string // conflict policy applied, empty if there was no conflict
+	CopyID     string // keep-both: id of the uploaded copy
+}
+
+// isVersionConflict tells a stale base_ver apart from other failed preconditions.
+func isVersionConflict(err error) bool {
+	s, ok := status.FromError(err)
+	if !ok || s.Code() != codes.FailedPrecondition {
+		return false
+	}
+	info := errorInfo(s)
+	return info == nil || info.GetReason() == errs.ReasonVersionConflict
+}
+
+// serverVersions maps every item id, tombstones included, to its current version.
+func serverVersions(ctx context.Context, cli pb.GophKeeperClient) (map[string]int64, error) {
+	req := &pb.GetChangesRequest{}
+	req.SetSinceVer(0)
+	out, err := fetchChanges(ctx, cli, req)
+	if err != nil {
+		return nil, err
+	}
+	vers := make(map[string]int64, len(out.GetChanges()))
+	for _, c := range out.GetChanges() {
+		vers[c.GetId()] = c.GetVer()
+	}
+	return vers, nil
+}
+
+// conflictedCopy retitles a payload for upload as a new item next to origID.
+func conflictedCopy(plain []byte, origID string) ([]byte, error) {
+	var obj typedPayload
+	if err := json.Unmarshal(plain, &obj); err != nil {
+		return nil, err
+	}
+	meta := map[string]any{}
+	if len(obj.Meta) > 0 {
+		if err := json.Unmarshal(obj.Meta, &meta); err != nil {
+			return nil, err
+		}
+	}
+	title, _ := meta["title"].(string)
+	if title == "" {
+		meta["title"] = "Conflicted copy"
+	} else {
+		meta["title"] = title + " (Conflicted copy)"
+	}
+	meta["conflict_of"] = origID
+	m, err := json.Marshal(meta)
+	if err != nil {
+		return nil, err
+	}
+	obj.Meta = m
+	return json.Marshal(obj)
+}
+
+// resolve rewrites it, whose base_ver is stale against the server's cur,
+// according to policy. drop means nothing is left to send.
+func resolve(it *pendingUpsert, out *upsertOutcome, uid string, cur int64, policy string) (drop bool, err error) {
+	out.Resolution = policy
+	switch policy {
+	case conflictServer:
+		out.Ver = cur
+		return true, nil
+	case conflictLocal:
+		it.BaseVer = cur
+		it.Blob, err = encryptForItem(it.ID, uid, cur+1, it.Plain)
+		return false, err
+	case conflictKeepBoth:
+		pt, err := conflictedCopy(it.Plain, it.ID)
+		if err != nil {
+			return false, err
+		}
+		id := ""
+		autoUUID(&id)
+		it.ID, it.BaseVer, it.Plain, out.CopyID = id, 0, pt, id
+		it.Blob, err = encryptForItem(id, uid, 1, pt)
+		return false, err
+	}
+	return false, fmt.Errorf("unknown conflict policy %q", policy)
+}
+
+// sendResolving is sendUpserts with a conflict policy. outcomes lines up with
+// items; on error it holds the batches stored before the failing one.
+func sendResolving(ctx context.Context, cli pb.GophKeeperClient, uid string, items []pendingUpsert, policy string, progress func(done, total int)) ([]upsertOutcome, error) {
+	if policy == conflictFail {
+		res, err := sendUpserts(ctx, cli, items, progress)
+		outcomes := make([]upsertOutcome, len(res))
+		for i, r := range res {
+			outcomes[i].Ver = r.GetNewVer()
+		}
+		return outcomes, err
+	}
+	outcomes := make([]upsertOutcome, 0, len(items))
+	for _, batch := range chunkUpserts(items, batchMaxBytes, batchMaxItems) {
+		batch = slices.Clone(batch) // resolve rewrites entries
+		outs := make([]upsertOutcome, len(batch))
+		send := make([]int, len(batch)) // indexes into batch still to store
+		for i := range send {
+			send[i] = i
+		}
+		for round := 0; len(send) > 0; round++ {
+			if round > maxConflictRounds {
+				return outcomes, fmt.Errorf("%w: %s keeps changing", errStale, batch[send[0]].ID)
+			}
+			pending := make([]pendingUpsert, len(send))
+			for k, i := range send {
+				pending[k] = batch[i]
+			}
+			written, vers, err := upsertEach(ctx, cli, pending)
+			if err != nil {
+				return outcomes, err
+			}
+			keep := send[:0]
+			for k, i := range send {
+				it := &batch[i]
+				switch {
+				case written[k]:
+					outs[i].Ver = vers[k]
+				case vers[k] == it.BaseVer:
+					keep = append(keep, i) // rejected with the rest of an atomic batch
+				case it.Plain == nil:
+					return outcomes, fmt.Errorf("%w: %s is at version %d", errStale, it.ID, vers[k])
+				default:
+					drop, err := resolve(it, &outs[i], uid, vers[k], policy)
+					if err != nil {
+						return outcomes, err
+					}
+					if !drop {
+						keep = append(keep, i)
+					}
+				}
+			}
+			send = keep
+		}
+		outcomes = append(outcomes, outs...)
+		if progress != nil {
+			progress(len(outcomes), len(items))
+		}
+	}
+	return outcomes, nil
+}
+
+// upsertEach sends one batch best-effort. For each item it reports whether
+// it was stored and a version: the new one, or the server's current one for
+// a stale item. A server without best_effort rejects a stale batch whole;
+// then nothing is stored and the current versions come from serverVersions.
+func upsertEach(ctx context.Context, cli pb.GophKeeperClient, batch []pendingUpsert) (written []bool, vers []int64, err error) {
+	req := upsertRequest(batch)
+	req.SetBestEffort(true)
+	resp, err := cli.UpsertItems(ctx, req)
+	written, vers = make([]bool, len(batch)), make([]int64, len(batch))
+	switch {
+	case isVersionConflict(err):
+		cur, err := serverVersions(ctx, cli)
+		if err != nil {
+			return nil, nil, err
+		}
+		for k, it := range batch {
+			vers[k] = cur[it.ID]
+		}
+		return written, vers, nil
+	case err != nil:
+		return nil, nil, err
+	}
+	showWarnings(resp.GetWarnings())
+	if rs := resp.GetItemResults(); len(rs) == len(batch) {
+		for k, r := range rs {
+			written[k], vers[k] = r.GetStatus() == pb.ItemStatus_ITEM_STATUS_OK, r.GetVer()
+		}
+		return written, vers, nil
+	}
+	for k, r := range resp.GetResults() { // all stored by a server without best_effort
+		written[k], vers[k] = true, r.GetNewVer()
+	}
+	return written, vers, nil
+}
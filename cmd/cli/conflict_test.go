@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	cc "github.com/and161185/goph-keeper/internal/crypto/clientcrypto"
+	"github.com/and161185/goph-keeper/internal/errs"
+)
+
+// fakeVault stores versions and blobs like the real server: a batch is
+// rejected whole when any base_ver is stale, unless it is best-effort. A
+// legacy vault ignores best_effort, as servers before it did.
+type fakeVault struct {
+	pb.GophKeeperClient
+	vers    map[string]int64
+	blobs   map[string][]byte
+	legacy  bool
+	fetches int // GetChanges calls
+}
+
+func (f *fakeVault) UpsertItems(_ context.Context, req *pb.UpsertItemsRequest, _ ...grpc.CallOption) (*pb.UpsertItemsResponse, error) {
+	each := req.GetBestEffort() && !f.legacy
+	for _, it := range req.GetItems() {
+		if !each && f.vers[it.GetId()] != it.GetBaseVer() {
+			st, _ := status.New(codes.FailedPrecondition, "version conflict").
+				WithDetails(&errdetails.ErrorInfo{Reason: errs.ReasonVersionConflict, Domain: errs.Domain})
+			return nil, st.Err()
+		}
+	}
+	var res []*pb.ItemVersion
+	var all []*pb.ItemResult
+	for _, it := range req.GetItems() {
+		r := &pb.ItemResult{}
+		r.SetId(it.GetId())
+		all = append(all, r)
+		if f.vers[it.GetId()] != it.GetBaseVer() {
+			r.SetStatus(pb.ItemStatus_ITEM_STATUS_VERSION_CONFLICT)
+			r.SetVer(f.vers[it.GetId()])
+			continue
+		}
+		f.vers[it.GetId()]++
+		f.blobs[it.GetId()] = it.GetBlobEnc().GetCiphertext()
+		r.SetStatus(pb.ItemStatus_ITEM_STATUS_OK)
+		r.SetVer(f.vers[it.GetId()])
+		v := &pb.ItemVersion{}
+		v.SetId(it.GetId())
+		v.SetNewVer(f.vers[it.GetId()])
+		res = append(res, v)
+	}
+	out := &pb.UpsertItemsResponse{}
+	out.SetResults(res)
+	if each {
+		out.SetItemResults(all)
+	}
+	return out, nil
+}
+
+func (f *fakeVault) GetChanges(context.Context, *pb.GetChangesRequest, ...grpc.CallOption) (*pb.GetChangesResponse, error) {
+	f.fetches++
+	var cs []*pb.Change
+	for id, v := range f.vers {
+		c := &pb.Change{}
+		c.SetId(id)
+		c.SetVer(v)
+		cs = append(cs, c)
+	}
+	out := &pb.GetChangesResponse{}
+	out.SetChanges(cs)
+	return out, nil
+}
+
+func Test_sendResolving_Policies(t *testing.T) {
+	_ = withTmpConfig(t)
+	dek, _ := cc.Rand(cc.DEKLen)
+	if err := saveDEK(dek); err != nil {
+		t.Fatal(err)
+	}
+	for _, legacy := range []bool{false, true} {
+		t.Run(map[bool]string{false: "best-effort", true: "legacy"}[legacy], func(t *testing.T) {
+			testPolicies(t, legacy)
+		})
+	}
+}
+
+func testPolicies(t *testing.T, legacy bool) {
+	const (
+		fresh = "11111111-1111-1111-1111-111111111111"
+		stale = "22222222-2222-2222-2222-222222222222"
+	)
+	setup := func() (*fakeVault, []pendingUpsert) {
+		f := &fakeVault{vers: map[string]int64{fresh: 1, stale: 3}, blobs: map[string][]byte{}, legacy: legacy}
+		items, err := encryptBulk([]bulkItem{
+			{ID: fresh, BaseVer: 1, Type: "text", Meta: []byte(`{"title":"ok"}`), Data: []byte(`{"text":"a"}`)},
+			{ID: stale, BaseVer: 2, Type: "text", Meta: []byte(`{"title":"Notes"}`), Data: []byte(`{"text":"mine"}`)},
+		}, "u1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f, items
+	}
+	ctx := context.Background()
+
+	f, items := setup()
+	if _, err := sendResolving(ctx, f, "u1", items, conflictFail, nil); !isVersionConflict(err) || f.vers[fresh] != 1 {
+		t.Fatalf("fail policy: %v, fresh ver %d", err, f.vers[fresh])
+	}
+
+	f, items = setup()
+	outs, err := sendResolving(ctx, f, "u1", items, conflictServer, nil)
+	if err != nil || outs[0].Ver != 2 || outs[1].Resolution != conflictServer || outs[1].Ver != 3 || f.vers[stale] != 3 {
+		t.Fatalf("server policy: %+v %v", outs, err)
+	}
+	if !legacy && f.fetches != 0 {
+		t.Fatalf("read the change feed %d times for versions the conflict carried", f.fetches)
+	}
+
+	f, items = setup()
+	outs, err = sendResolving(ctx, f, "u1", items, conflictLocal, nil)
+	if err != nil || outs[1].Ver != 4 || outs[1].Resolution != conflictLocal {
+		t.Fatalf("local policy: %+v %v", outs, err)
+	}
+	if _, err := decryptForItem(stale, "u1", 4, f.blobs[stale]); err != nil {
+		t.Fatalf("overwrite not sealed for ver 4: %v", err)
+	}
+
+	f, items = setup()
+	outs, err = sendResolving(ctx, f, "u1", items, conflictKeepBoth, nil)
+	if err != nil || f.vers[stale] != 3 || outs[1].CopyID == "" || outs[1].Ver != 1 {
+		t.Fatalf("keep-both policy: %+v %v", outs, err)
+	}
+	pt, err := decryptForItem(outs[1].CopyID, "u1", 1, f.blobs[outs[1].CopyID])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var obj typedPayload
+	_ = json.Unmarshal(pt, &obj)
+	var meta map[string]string
+	_ = json.Unmarshal(obj.Meta, &meta)
+	if meta["title"] != "Notes (Conflicted copy)" || meta["conflict_of"] != stale {
+		t.Fatalf("copy meta: %s", obj.Meta)
+	}
+}
+
+func Test_isVersionConflict(t *testing.T) {
+	t.Parallel()
+	if isVersionConflict(errors.New("network down")) || isVersionConflict(status.Error(codes.NotFound, "x")) {
+		t.Fatal("not a conflict")
+	}
+	if !isVersionConflict(status.Error(codes.FailedPrecondition, "version conflict")) {
+		t.Fatal("conflict without ErrorInfo from an older server")
+	}
+	st, _ := status.New(codes.FailedPrecondition, "already initialized").
+		WithDetails(&errdetails.ErrorInfo{Reason: errs.ReasonAlreadyInitialized, Domain: errs.Domain})
+	if isVersionConflict(st.Err()) {
+		t.Fatal("DEK precondition taken for a version conflict")
+	}
+	if _, err := parseConflictPolicy("merge"); !errors.Is(err, errInvalidInput) {
+		t.Fatalf("bad policy accepted: %v", err)
+	}
+}
@@ -0,0 +1,357 @@
+// cmd/cli/dedupe.go
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+)
+
+// dupGroup is a set of logins considered duplicates of each other.
+type dupGroup struct {
+	Reason string      `json:"reason"` // "url+username" or "password"
+	Items  []vaultItem `json:"-"`
+}
+
+// MarshalJSON lists group members without secrets.
+func (g dupGroup) MarshalJSON() ([]byte, error) {
+	type member struct {
+		ID        string    `json:"id"`
+		Ver       int64     `json:"ver"`
+		UpdatedAt time.Time `json:"updated_at"`
+		Title     string    `json:"title"`
+		URL       string    `json:"url"`
+		Username  string    `json:"username"`
+	}
+	out := struct {
+		Reason string   `json:"reason"`
+		Items  []member `json:"items"`
+	}{Reason: g.Reason}
+	for _, it := range g.Items {
+		lf, _ := parseLogin(it.Payload)
+		out.Items = append(out.Items, member{it.ID, it.Ver, it.UpdatedAt, lf.Title, lf.URL, lf.Username})
+	}
+	return json.Marshal(out)
+}
+
+// findDuplicates groups logins by URL+username and, separately, by identical
+// password. A password group covering exactly the same items as a URL group is dropped.
+func findDuplicates(items []vaultItem, byURL, byPassword bool) []dupGroup {
+	urlGroups := map[string][]vaultItem{}
+	pwGroups := map[string][]vaultItem{}
+	for _, it := range items {
+		lf, err := parseLogin(it.Payload)
+		if err != nil {
+			continue
+		}
+		if byURL && lf.URL != "" {
+			k := loginKey(lf.URL, lf.Username)
+			urlGroups[k] = append(urlGroups[k], it)
+		}
+		if byPassword && lf.Password != "" {
+			pwGroups[lf.Password] = append(pwGroups[lf.Password], it)
+		}
+	}
+
+	memberKey := func(g []vaultItem) string {
+		ids := make([]string, len(g))
+		for i, it := range g {
+			ids[i] = it.ID
+		}
+		sort.Strings(ids)
+		return strings.Join(ids, ",")
+	}
+	var out []dupGroup
+	seen := map[string]bool{}
+	for _, g := range urlGroups {
+		if len(g) > 1 {
+			seen[memberKey(g)] = true
+			out = append(out, dupGroup{Reason: "url+username", Items: g})
+		}
+	}
+	for _, g := range pwGroups {
+		if len(g) > 1 && !seen[memberKey(g)] {
+			out = append(out, dupGroup{Reason: "password", Items: g})
+		}
+	}
+	for i := range out {
+		sort.Slice(out[i].Items, func(a, b int) bool {
+			return out[i].Items[a].UpdatedAt.After(out[i].Items[b].UpdatedAt)
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Reason != out[j].Reason {
+			return out[i].Reason > out[j].Reason // url+username first
+		}
+		return out[i].Items[0].ID < out[j].Items[0].ID
+	})
+	return out
+}
+
+// mergePayload fills fields that are empty in keep from the other items and
+// appends their distinct notes. The kept password is never replaced unless
+// empty; password_changed_at follows the item the password comes from.
+func mergePayload(keep vaultItem, others []vaultItem, now time.Time) (typedPayload, error) {
+	decode := func(raw json.RawMessage) map[string]any {
+		m := map[string]any{}
+		_ = json.Unmarshal(raw, &m)
+		return m
+	}
+	empty := func(v any) bool { return v == nil || v == "" }
+
+	meta, data := decode(keep.Payload.Meta), decode(keep.Payload.Data)
+	from := &keep.Payload
+	notes := []string{}
+	if n, _ := meta["note"].(string); n != "" {
+		notes = append(notes, n)
+	}
+	for _, o := range others {
+		om, od := decode(o.Payload.Meta), decode(o.Payload.Data)
+		for k, v := range om {
+			if k == "note" || k == metaPasswordChangedAt {
+				continue
+			}
+			if empty(meta[k]) && !empty(v) {
+				meta[k] = v
+			}
+		}
+		for k, v := range od {
+			if empty(data[k]) && !empty(v) {
+				data[k] = v
+				if k == "password" {
+					from = &o.Payload
+				}
+			}
+		}
+		if n, _ := om["note"].(string); n != "" && !slices.Contains(notes, n) {
+			notes = append(notes, n)
+		}
+	}
+	if len(notes) > 0 {
+		meta["note"] = strings.Join(notes, "\n---\n")
+	}
+	if pass, _ := data["password"].(string); pass != "" {
+		stampPasswordChange(meta, pass, from, now)
+	}
+
+	mb, err := json.Marshal(meta)
+	if err != nil {
+		return typedPayload{}, err
+	}
+	db, err := json.Marshal(data)
+	if err != nil {
+		return typedPayload{}, err
+	}
+	fields := slices.Clone(keep.Payload.Fields)
+	for _, o := range others {
+		for _, f := range o.Payload.Fields {
+			if !slices.ContainsFunc(fields, func(k customField) bool { return k.Name == f.Name }) {
+				fields = append(fields, f)
+			}
+		}
+	}
+	return typedPayload{Type: keep.Payload.Type, Meta: mb, Data: db, Fields: fields, Schema: keep.Payload.Schema}, nil
+}
+
+// printDupGroup shows group members side by side, numbered from 1.
+func printDupGroup(w io.Writer, n int, g dupGroup) {
+	fmt.Fprintf(w, tr("group %d (same %s):\n"), n, g.Reason)
+	tw := tabwriter.NewWriter(w, 0, 2, 2, ' ', 0)
+	fmt.Fprintln(tw, "  #\tID\tVER\tUPDATED\tTITLE\tURL\tUSERNAME")
+	for i, it := range g.Items {
+		lf, _ := parseLogin(it.Payload)
+		fmt.Fprintf(tw, "  %d\t%s\t%d\t%s\t%s\t%s\t%s\n",
+			i+1, it.ID, it.Ver, it.UpdatedAt.Local().Format(time.DateTime), lf.Title, lf.URL, lf.Username)
+	}
+	_ = tw.Flush()
+}
+
+// dedupeAction is a parsed answer to the per-group prompt.
+type dedupeAction struct {
+	Op   byte // 's' skip, 'q' quit, 'd' keep N delete rest, 'm' merge into N
+	Keep int  // 0-based index into the group
+}
+
+// parseDedupeAnswer parses "s", "q", "d<N>" or "m<N>" (N is 1-based).
+func parseDedupeAnswer(ans string, size int) (dedupeAction, error) {
+	ans = strings.ToLower(strings.TrimSpace(ans))
+	switch ans {
+	case "", "s":
+		return dedupeAction{Op: 's'}, nil
+	case "q":
+		return dedupeAction{Op: 'q'}, nil
+	}
+	if op := ans[0]; op == 'd' || op == 'm' {
+		n, err := strconv.Atoi(ans[1:])
+		if err == nil && n >= 1 && n <= size {
+			return dedupeAction{Op: op, Keep: n - 1}, nil
+		}
+	}
+	return dedupeAction{}, fmt.Errorf("expected s, q, d1..d%d or m1..m%d", size, size)
+}
+
+// applyDedupe merges (optionally) into the kept item and deletes the others
+// in one change set: a duplicate edited meanwhile leaves the whole group,
+// kept item included, as it was.
+// It returns the kept item as it is now stored.
+func applyDedupe(ctx context.Context, cli pb.GophKeeperClient, uid string, g dupGroup, act dedupeAction) (vaultItem, error) {
+	keep := g.Items[act.Keep]
+	others := make([]vaultItem, 0, len(g.Items)-1)
+	for i, it := range g.Items {
+		if i != act.Keep {
+			others = append(others, it)
+		}
+	}
+	req := &pb.ApplyChangeSetRequest{}
+	var merged typedPayload
+	if act.Op == 'm' {
+		var err error
+		if merged, err = mergePayload(keep, others, time.Now()); err != nil {
+			return keep, err
+		}
+		pt, err := json.Marshal(merged)
+		if err != nil {
+			return keep, err
+		}
+		blob, err := encryptForItem(keep.ID, uid, keep.Ver+1, pt)
+		if err != nil {
+			return keep, err
+		}
+		req.SetUpserts(upsertRequest([]pendingUpsert{{ID: keep.ID, BaseVer: keep.Ver, Blob: blob}}).GetItems())
+	}
+	refs := make([]*pb.ItemRef, 0, len(others))
+	for _, o := range others {
+		r := &pb.ItemRef{}
+		r.SetId(o.ID)
+		r.SetBaseVer(o.Ver)
+		refs = append(refs, r)
+	}
+	req.SetDeletes(refs)
+	out, err := cli.ApplyChangeSet(ctx, req)
+	if err != nil {
+		return keep, fmt.Errorf("merge duplicates: %w", err)
+	}
+	if !out.GetApplied() {
+		return keep, fmt.Errorf("%w: an item of the group changed meanwhile, none was changed", errBatchRejected)
+	}
+	showWarnings(out.GetWarnings())
+	if act.Op == 'm' {
+		keep.Ver++
+		keep.UpdatedAt = time.Now()
+		keep.Payload = merged
+	}
+	return keep, nil
+}
+
+// cmdDedupe finds duplicate logins and resolves them interactively.
+func cmdDedupe(args []string, addr, caPath string, insecure bool) {
+	fs := flag.NewFlagSet("dedupe", flag.ExitOnError)
+	by := fs.String("by", "both", "match on: url | password | both")
+	listOnly := fs.Bool("list", false, "only list duplicate groups, no prompts")
+	parseFlags(fs, args)
+	if *by != "url" && *by != "password" && *by != "both" {
+		fmt.Fprintln(os.Stderr, tr("-by must be url, password or both"))
+		os.Exit(exitUsage)
+	}
+	if !*listOnly {
+		if err := needInput("dedupe"); err != nil {
+			fail(err)
+		}
+	}
+
+	token, err := loadToken()
+	if err != nil {
+		fail(err)
+	}
+	uid, err := loadUserID()
+	if err != nil {
+		fail(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute) // interactive session
+	defer cancel()
+	conn, cli, err := dial(ctx, addr, caPath, insecure, token)
+	if err != nil {
+		fail(err)
+	}
+	defer conn.Close()
+
+	noCache = true // about to modify items: work from the server's state
+	items, err := listTyped(ctx, cli)
+	if err != nil {
+		fail(err)
+	}
+	groups := findDuplicates(items, *by != "password", *by != "url")
+	if *listOnly || outputFormat != "" {
+		emit(groups, func() {
+			for i, g := range groups {
+				printDupGroup(os.Stdout, i+1, g)
+			}
+			fmt.Printf(tr("%d duplicate groups\n"), len(groups))
+		})
+		return
+	}
+	if len(groups) == 0 {
+		fmt.Println(colors(os.Stdout).ok(tr("no duplicates found")))
+		return
+	}
+
+	// An item can sit in a url group and a password group; later groups
+	// must see earlier merges (new ver/payload) and deletions.
+	current := map[string]vaultItem{}
+	for _, it := range items {
+		current[it.ID] = it
+	}
+	in := bufio.NewScanner(os.Stdin)
+	for i, g := range groups {
+		live := g.Items[:0:0]
+		for _, it := range g.Items {
+			if cur, ok := current[it.ID]; ok {
+				live = append(live, cur)
+			}
+		}
+		if len(live) < 2 {
+			continue
+		}
+		g.Items = live
+		printDupGroup(os.Stdout, i+1, g)
+
+		var act dedupeAction
+		for {
+			fmt.Print(tr("[s]kip, d<N> keep N and delete others, m<N> merge into N, [q]uit: "))
+			if !in.Scan() {
+				return
+			}
+			if act, err = parseDedupeAnswer(in.Text(), len(g.Items)); err == nil {
+				break
+			}
+			fmt.Println(err)
+		}
+		switch act.Op {
+		case 'q':
+			return
+		case 's':
+			continue
+		}
+		kept, err := applyDedupe(ctx, cli, uid, g, act)
+		if err != nil {
+			fail(err)
+		}
+		for _, it := range g.Items {
+			delete(current, it.ID)
+		}
+		current[kept.ID] = kept
+		fmt.Printf(tr("kept %s, removed %d\n"), kept.ID, len(g.Items)-1)
+	}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/pkg/gktest"
+)
+
+func loginItem(id, title, url, user, pass, note string, age time.Duration) vaultItem {
+	meta, _ := json.Marshal(map[string]any{"title": title, "url": url, "username": user, "note": note})
+	data, _ := json.Marshal(map[string]any{"password": pass})
+	return vaultItem{ID: id, Ver: 1, UpdatedAt: time.Unix(1700000000, 0).Add(-age), Payload: typedPayload{Type: "login", Meta: meta, Data: data}}
+}
+
+func Test_findDuplicates(t *testing.T) {
+	t.Parallel()
+	items := []vaultItem{
+		loginItem("a", "GH", "https://github.com/", "me", "p1", "", time.Hour),
+		loginItem("b", "GitHub", "http://GITHUB.com", "me", "p2", "", 0),
+		loginItem("c", "mail", "https://mail.example", "me", "shared", "", 0),
+		loginItem("d", "bank", "https://bank.example", "me", "shared", "", 0),
+		loginItem("e", "x", "https://x.example", "u", "p1", "", 0),
+		{ID: "t", Payload: typedPayload{Type: "text", Meta: []byte(`{}`), Data: []byte(`{"text":"p1"}`)}},
+	}
+
+	groups := findDuplicates(items, true, true)
+	if len(groups) != 3 {
+		t.Fatalf("want 3 groups, got %d: %+v", len(groups), groups)
+	}
+	if groups[0].Reason != "url+username" || groups[0].Items[0].ID != "b" {
+		t.Fatalf("url group (newest first): %+v", groups[0])
+	}
+	for _, g := range groups[1:] {
+		if g.Reason != "password" || len(g.Items) != 2 {
+			t.Fatalf("password group: %+v", g)
+		}
+	}
+
+	if got := findDuplicates(items, true, false); len(got) != 1 {
+		t.Fatalf("-by url: %d groups", len(got))
+	}
+
+	b, _ := json.Marshal(groups)
+	if strings.Contains(string(b), "shared") || strings.Contains(string(b), "p1") {
+		t.Fatalf("group JSON leaks passwords: %s", b)
+	}
+}
+
+func Test_mergePayload(t *testing.T) {
+	t.Parallel()
+	keep := loginItem("a", "GH", "https://github.com", "", "keep-pass", "recovery codes", 0)
+	other := loginItem("b", "GitHub", "https://github.com", "me", "old-pass", "2fa on phone", 0)
+	dup := loginItem("c", "", "", "", "", "recovery codes", 0)
+
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	other.Payload.Meta, _ = json.Marshal(map[string]any{"title": "GitHub", "url": "https://github.com", "username": "me", "note": "2fa on phone", metaPasswordChangedAt: "2024-01-02T00:00:00Z"})
+	got, err := mergePayload(keep, []vaultItem{other, dup}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lf, err := parseLogin(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lf.Title != "GH" || lf.Username != "me" || lf.Password != "keep-pass" {
+		t.Fatalf("merged fields: %+v", lf)
+	}
+	if lf.Note != "recovery codes\n---\n2fa on phone" {
+		t.Fatalf("notes: %q", lf.Note)
+	}
+	changedAt := func(p typedPayload) any {
+		var m map[string]any
+		_ = json.Unmarshal(p.Meta, &m)
+		return m[metaPasswordChangedAt]
+	}
+	// the kept password has no date, so it is stamped; the other's date is not taken
+	if got := changedAt(got); got != "2026-05-01T12:00:00Z" {
+		t.Fatalf("kept password changed at %v", got)
+	}
+
+	// an empty kept password takes the other's password and its date
+	keep = loginItem("a", "GH", "https://github.com", "", "", "", 0)
+	if got, err = mergePayload(keep, []vaultItem{other}, now); err != nil {
+		t.Fatal(err)
+	}
+	if got := changedAt(got); got != "2024-01-02T00:00:00Z" {
+		t.Fatalf("taken password changed at %v", got)
+	}
+}
+
+func Test_parseDedupeAnswer(t *testing.T) {
+	t.Parallel()
+	for in, want := range map[string]dedupeAction{
+		"":    {Op: 's'},
+		"S":   {Op: 's'},
+		"q":   {Op: 'q'},
+		"d2":  {Op: 'd', Keep: 1},
+		" m1": {Op: 'm', Keep: 0},
+	} {
+		got, err := parseDedupeAnswer(in, 3)
+		if err != nil || got != want {
+			t.Fatalf("%q: %+v %v", in, got, err)
+		}
+	}
+	for _, in := range []string{"d0", "d4", "m", "x1", "delete"} {
+		if _, err := parseDedupeAnswer(in, 3); err == nil {
+			t.Fatalf("%q: want error", in)
+		}
+	}
+}
+
+func Test_applyDedupe(t *testing.T) {
+	_ = withTmpConfig(t)
+	if err := saveDEK(make([]byte, 32)); err != nil {
+		t.Fatal(err)
+	}
+	srv := gktest.Start(t)
+	cli := srv.Client(t)
+	uid, token := srv.NewUser(t, "alice", "secret")
+	ctx := gktest.WithToken(context.Background(), token)
+
+	var g dupGroup
+	for _, title := range []string{"a", "b", "c"} {
+		id := uuid.Must(uuid.NewV7()).String()
+		if _, err := sendOne(ctx, cli, id, 0, []byte("blob")); err != nil {
+			t.Fatal(err)
+		}
+		g.Items = append(g.Items, loginItem(id, title, "https://x.example", "me", "pw", "", 0))
+	}
+	// c changes after gk listed it
+	if _, err := sendOne(ctx, cli, g.Items[2].ID, 1, []byte("blob")); err != nil {
+		t.Fatal(err)
+	}
+	vers := func() map[string]int64 {
+		req := &pb.GetChangesRequest{}
+		out, err := cli.GetChanges(ctx, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := map[string]int64{}
+		for _, ch := range out.GetChanges() {
+			m[ch.GetId()] = ch.GetVer()
+		}
+		return m
+	}
+
+	if _, err := applyDedupe(ctx, cli, uid, g, dedupeAction{Op: 'm'}); !errors.Is(err, errBatchRejected) {
+		t.Fatalf("want errBatchRejected, got %v", err)
+	}
+	if v := vers(); v[g.Items[0].ID] != 1 || v[g.Items[1].ID] != 1 {
+		t.Fatalf("rejected merge changed items: %v", v)
+	}
+
+	g.Items[2].Ver = 2
+	kept, err := applyDedupe(ctx, cli, uid, g, dedupeAction{Op: 'm'})
+	if err != nil || kept.Ver != 2 {
+		t.Fatalf("merge: %+v %v", kept, err)
+	}
+	if v := vers(); v[g.Items[0].ID] != 2 || v[g.Items[1].ID] != 2 || v[g.Items[2].ID] != 3 {
+		t.Fatalf("versions after merge: %v", v)
+	}
+}
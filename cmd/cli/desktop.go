@@ -0,0 +1,69 @@
+// cmd/cli/desktop.go
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// browserCommand returns the platform command that opens u in the default browser.
+func browserCommand(goos, u string) (string, []string) {
+	switch goos {
+	case "darwin":
+		return "open", []string{u}
+	case "windows":
+		return "rundll32", []string{"url.dll,FileProtocolHandler", u}
+	default:
+		return "xdg-open", []string{u}
+	}
+}
+
+// clipboardCommands lists candidate clipboard writers (stdin-fed) in preference order.
+func clipboardCommands(goos string) [][]string {
+	switch goos {
+	case "darwin":
+		return [][]string{{"pbcopy"}}
+	case "windows":
+		return [][]string{{"clip"}}
+	default:
+		return [][]string{{"wl-copy"}, {"xclip", "-selection", "clipboard"}, {"xsel", "--clipboard", "--input"}}
+	}
+}
+
+// checkWebURL accepts only absolute http(s) URLs so item metadata can't launch arbitrary handlers.
+func checkWebURL(raw string) error {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("refusing to open non-web url %q", raw)
+	}
+	return nil
+}
+
+// openURL launches the default browser without waiting for it to exit.
+func openURL(u string) error {
+	if err := checkWebURL(u); err != nil {
+		return err
+	}
+	name, args := browserCommand(runtime.GOOS, u)
+	return exec.Command(name, args...).Start()
+}
+
+// copyToClipboard writes text to the first available system clipboard tool.
+func copyToClipboard(text string) error {
+	for _, c := range clipboardCommands(runtime.GOOS) {
+		if _, err := exec.LookPath(c[0]); err != nil {
+			continue
+		}
+		cmd := exec.Command(c[0], c[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		return cmd.Run()
+	}
+	return errors.New("no clipboard tool found (install wl-copy, xclip or xsel)")
+}
@@ -0,0 +1,173 @@
+// cmd/cli/device.go
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+)
+
+// deviceRow is one registered push device in list output.
+type deviceRow struct {
+	ID        string `json:"id"`
+	Platform  string `json:"platform"`
+	Name      string `json:"name,omitempty"`
+	CreatedAt string `json:"created_at"`
+}
+
+// newNtfyTopic returns an unguessable topic name: whoever knows it can
+// subscribe to the pushes.
+func newNtfyTopic() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return "gk-" + hex.EncodeToString(b)
+}
+
+// cmdDevice manages the caller's push notification devices.
+func cmdDevice(args []string, addr, caPath string, insecure bool) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, tr("usage: device add -platform fcm|apns|ntfy [-token T] [-name N] | list | rm -id ID"))
+		os.Exit(exitUsage)
+	}
+	sub, args := args[0], args[1:]
+	fs := flag.NewFlagSet("device "+sub, flag.ExitOnError)
+	var platform, token, name, id *string
+	switch sub {
+	case "add":
+		platform = fs.String("platform", "", "fcm, apns or ntfy")
+		token = fs.String("token", "", "push token; for ntfy, the topic (default: a new random one)")
+		name = fs.String("name", "", "label, e.g. laptop")
+	case "rm":
+		id = fs.String("id", "", "device id")
+	case "list":
+	default:
+		fmt.Fprintf(os.Stderr, tr("unknown device subcommand %q\n"), sub)
+		os.Exit(exitUsage)
+	}
+	parseFlags(fs, args)
+	if platform != nil && *platform == "ntfy" && *token == "" {
+		*token = newNtfyTopic()
+	}
+	if (platform != nil && (*platform == "" || *token == "")) || (id != nil && *id == "") {
+		fail(fmt.Errorf("%w: %s", errInvalidInput, tr("device add needs -platform and -token, device rm needs -id")))
+	}
+
+	tok, err := loadToken()
+	if err != nil {
+		fail(err)
+	}
+	ctx, cancel := withTimeout()
+	defer cancel()
+	conn, cli, err := dial(ctx, addr, caPath, insecure, tok)
+	if err != nil {
+		fail(err)
+	}
+	defer conn.Close()
+
+	switch sub {
+	case "add":
+		req := &pb.RegisterDeviceRequest{}
+		req.SetPlatform(*platform)
+		req.SetToken(*token)
+		req.SetName(*name)
+		resp, err := cli.RegisterDevice(ctx, req)
+		if err != nil {
+			fail(err)
+		}
+		out := map[string]any{"id": resp.GetDevice().GetId(), "platform": *platform}
+		if *platform == "ntfy" {
+			out["topic"] = *token
+		}
+		printJSON(out)
+		if *platform == "ntfy" {
+			fmt.Fprintln(os.Stderr, tr("subscribe to this topic, e.g. syncd -ntfy <server>/<topic>; keep it private"))
+		}
+	case "list":
+		resp, err := cli.ListDevices(ctx, &pb.ListDevicesRequest{})
+		if err != nil {
+			fail(err)
+		}
+		rows := []deviceRow{}
+		for _, d := range resp.GetDevices() {
+			rows = append(rows, deviceRow{ID: d.GetId(), Platform: d.GetPlatform(), Name: d.GetName(), CreatedAt: tsString(d.GetCreatedAt())})
+		}
+		emit(rows, func() {
+			printJSON(map[string]any{"devices": rows, "platforms": resp.GetPlatforms()})
+		})
+	case "rm":
+		req := &pb.UnregisterDeviceRequest{}
+		req.SetId(*id)
+		if _, err := cli.UnregisterDevice(ctx, req); err != nil {
+			fail(err)
+		}
+		fmt.Printf(tr("device %s removed\n"), *id)
+	}
+}
+
+// watchNtfy subscribes to an ntfy topic URL and signals wake for every
+// message, reconnecting with backoff until ctx is done. Wake-ups are
+// coalesced: a pending one is not queued twice.
+func watchNtfy(ctx context.Context, topicURL string, wake chan<- struct{}) {
+	backoff := time.Second
+	for ctx.Err() == nil {
+		start := time.Now()
+		err := streamNtfy(ctx, topicURL, wake)
+		if ctx.Err() != nil {
+			return
+		}
+		if time.Since(start) > time.Minute {
+			backoff = time.Second
+		}
+		fmt.Fprint(os.Stderr, colors(os.Stderr).warn(tr("ntfy subscription lost: %v; retrying in %s\n", err, backoff)))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, time.Minute)
+	}
+}
+
+// streamNtfy reads one subscription until it ends.
+func streamNtfy(ctx context.Context, topicURL string, wake chan<- struct{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(topicURL, "/")+"/json", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s", resp.Status)
+	}
+	sc := bufio.NewScanner(resp.Body)
+	for sc.Scan() {
+		var ev struct {
+			Event string `json:"event"`
+		}
+		if json.Unmarshal(sc.Bytes(), &ev) != nil || ev.Event != "message" {
+			continue // open and keepalive events
+		}
+		select {
+		case wake <- struct{}{}:
+		default:
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+	return errors.New("stream closed")
+}
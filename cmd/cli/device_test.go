@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func Test_streamNtfy_WakesOnMessages(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/gk-topic/json" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = io.WriteString(w, `{"event":"open"}`+"\n"+`{"event":"keepalive"}`+"\n"+`{"event":"message","message":"{}"}`+"\n")
+	}))
+	defer srv.Close()
+
+	wake := make(chan struct{}, 1)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := streamNtfy(ctx, srv.URL+"/gk-topic", wake); err == nil {
+		t.Fatal("closed stream reported no error")
+	}
+	select {
+	case <-wake:
+	default:
+		t.Fatal("message did not wake the daemon")
+	}
+
+	if err := streamNtfy(ctx, srv.URL+"/other", wake); err == nil {
+		t.Fatal("404 accepted")
+	}
+	if len(wake) != 0 {
+		t.Fatal("keepalive or error woke the daemon")
+	}
+}
+
+func Test_newNtfyTopic(t *testing.T) {
+	a, b := newNtfyTopic(), newNtfyTopic()
+	if a == b || len(a) != 35 {
+		t.Fatalf("topics %q %q", a, b)
+	}
+}
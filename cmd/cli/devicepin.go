@@ -0,0 +1,116 @@
+// cmd/cli/devicepin.go
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// An item pinned to devices lists their ids in meta.devices. It still syncs
+// through the server, but every other profile's sync filter leaves it out
+// of gk sync and of the syncd cache, so it is never kept offline there.
+
+func deviceIDPath() string { return filepath.Join(cfgDir(), "device_id") }
+
+// thisDevice returns the profile's device id, made on first use.
+func thisDevice() (string, error) {
+	b, err := readProfileFile(deviceIDPath())
+	if err == nil {
+		return strings.TrimSpace(string(b)), nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+	var id string
+	autoUUID(&id)
+	return id, writeProfileFile(deviceIDPath(), []byte(id))
+}
+
+// pinnedDevices returns the ids in p's meta.devices; none means p is not
+// pinned.
+func pinnedDevices(p typedPayload) []string {
+	var m struct {
+		Devices []string `json:"devices"`
+	}
+	_ = json.Unmarshal(p.Meta, &m)
+	return m.Devices
+}
+
+// pinnedAway reports whether p is pinned to devices other than dev.
+func pinnedAway(p typedPayload, dev string) bool {
+	ds := pinnedDevices(p)
+	return len(ds) > 0 && !slices.Contains(ds, dev)
+}
+
+// cmdPinDevice pins an item to this device, plus any -device, or unpins it.
+func cmdPinDevice(args []string, addr, caPath string, insecure bool) {
+	fs := flag.NewFlagSet("pin-device", flag.ExitOnError)
+	id := fs.String("id", "", "item id (uuid)")
+	var others stringList
+	fs.Var(&others, "device", "also let this device id keep it (gk sync-filter shows a profile's id); repeatable")
+	unpin := fs.Bool("unpin", false, "let every device keep the item again")
+	parseFlags(fs, args)
+	if *id == "" {
+		fmt.Fprintln(os.Stderr, tr("need -id"))
+		os.Exit(exitUsage)
+	}
+	dev, err := thisDevice()
+	if err != nil {
+		fail(err)
+	}
+	token, err := loadToken()
+	if err != nil {
+		fail(err)
+	}
+	uid, err := loadUserID()
+	if err != nil {
+		fail(err)
+	}
+	ctx, cancel := withTimeout()
+	defer cancel()
+	conn, cli, err := dial(ctx, addr, caPath, insecure, token)
+	if err != nil {
+		fail(err)
+	}
+	defer conn.Close()
+
+	cur, obj, err := fetchTyped(ctx, cli, *id)
+	if err != nil {
+		fail(err)
+	}
+	meta := map[string]any{}
+	if len(obj.Meta) > 0 {
+		if err := json.Unmarshal(obj.Meta, &meta); err != nil {
+			fail(fmt.Errorf("%w: meta is not an object", errInvalidInput))
+		}
+	}
+	if *unpin {
+		delete(meta, "devices")
+	} else {
+		devices := append([]string{dev}, others...)
+		slices.Sort(devices)
+		meta["devices"] = slices.Compact(devices)
+	}
+	if obj.Meta, err = json.Marshal(meta); err != nil {
+		fail(err)
+	}
+	pt, err := encodePayload(obj)
+	if err != nil {
+		fail(err)
+	}
+	blob, err := encryptForItem(*id, uid, cur.GetVer()+1, pt)
+	if err != nil {
+		fail(err)
+	}
+	resp, err := sendOne(ctx, cli, *id, cur.GetVer(), blob)
+	if err != nil {
+		fail(err)
+	}
+	printJSON(resp.GetResults())
+}
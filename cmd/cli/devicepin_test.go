@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+func Test_syncFilter_Pins(t *testing.T) {
+	t.Parallel()
+
+	pinned := typedPayload{Type: "text", Meta: json.RawMessage(`{"devices":["d1","d2"]}`)}
+	free := typedPayload{Type: "text", Meta: json.RawMessage(`{}`)}
+	for _, c := range []struct {
+		f    syncFilter
+		p    typedPayload
+		keep bool
+	}{
+		{syncFilter{Device: "d1"}, pinned, true},
+		{syncFilter{Device: "d3"}, pinned, false},
+		{syncFilter{Device: "d3"}, free, true},
+		// pins win over include rules
+		{syncFilter{Device: "d3", Include: []string{"type:text"}}, pinned, false},
+		{syncFilter{Device: "d1", Exclude: []string{"type:text"}}, pinned, false},
+	} {
+		if got := c.f.keep(c.p); got != c.keep {
+			t.Fatalf("%+v keeps %s: %v", c.f, c.p.Meta, got)
+		}
+	}
+}
+
+func Test_thisDevice(t *testing.T) {
+	_ = withTmpConfig(t)
+	a, err := thisDevice()
+	if err != nil || a == "" {
+		t.Fatalf("first: %q %v", a, err)
+	}
+	if b, err := thisDevice(); err != nil || b != a {
+		t.Fatalf("second: %q %v, want %q", b, err, a)
+	}
+	if f, err := loadSyncFilter(); err != nil || f.Device != a || !f.empty() {
+		t.Fatalf("filter: %+v %v", f, err)
+	}
+}
+
+func Test_e2e_PinDevice(t *testing.T) {
+	_ = startServer(t)
+	const addr = "gk.test:8443"
+	id := uuid.Must(uuid.NewV7()).String()
+	_ = stdoutOf(t, func() { cmdAddText([]string{"-id", id, "-title", "local", "-text", "x"}, addr, "", false) })
+	_ = stdoutOf(t, func() { cmdPinDevice([]string{"-id", id}, addr, "", false) })
+	sync := func() string {
+		return stdoutOf(t, func() { cmdSync([]string{"-since", "0"}, addr, "", false) })
+	}
+
+	if out := sync(); !strings.Contains(out, id) {
+		t.Fatalf("the pinned device lists it: %s", out)
+	}
+	// another device
+	if err := os.WriteFile(deviceIDPath(), []byte(uuid.Must(uuid.NewV7()).String()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if out := sync(); strings.Contains(out, id) {
+		t.Fatalf("another device lists it: %s", out)
+	}
+	_ = stdoutOf(t, func() { cmdPinDevice([]string{"-id", id, "-unpin"}, addr, "", false) })
+	if out := sync(); !strings.Contains(out, id) {
+		t.Fatalf("after -unpin: %s", out)
+	}
+}
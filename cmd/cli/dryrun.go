@@ -0,0 +1,49 @@
+// cmd/cli/dryrun.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+)
+
+// plannedWrite is a write -dry-run would have sent.
+type plannedWrite struct {
+	Op      string `json:"op"` // upsert | delete
+	ID      string `json:"id"`
+	BaseVer int64  `json:"base_ver"`
+	Bytes   int    `json:"bytes,omitempty"` // ciphertext size
+	Batch   int    `json:"batch,omitempty"` // UpsertItems call it would go in, from 1
+}
+
+// dryRunFlag adds -dry-run to a command that writes items.
+func dryRunFlag(fs *flag.FlagSet) *bool {
+	return fs.Bool("dry-run", false, "validate and encrypt, print what would be sent, send nothing")
+}
+
+// plannedUpserts lists items in the batches sendUpserts would use.
+func plannedUpserts(items []pendingUpsert) []plannedWrite {
+	out := make([]plannedWrite, 0, len(items))
+	for i, batch := range chunkUpserts(items, batchMaxBytes, batchMaxItems) {
+		for _, it := range batch {
+			out = append(out, plannedWrite{Op: "upsert", ID: it.ID, BaseVer: it.BaseVer, Bytes: len(it.Blob), Batch: i + 1})
+		}
+	}
+	return out
+}
+
+func plannedDeletes(refs []*pb.ItemRef) []plannedWrite {
+	out := make([]plannedWrite, 0, len(refs))
+	for _, r := range refs {
+		out = append(out, plannedWrite{Op: "delete", ID: r.GetId(), BaseVer: r.GetBaseVer()})
+	}
+	return out
+}
+
+// printDryRun shows the planned writes and says on stderr that none was made.
+func printDryRun(w []plannedWrite) {
+	emit(w, func() { printJSON(w) })
+	fmt.Fprint(os.Stderr, colors(os.Stderr).warn(tr("dry run: nothing was sent\n")))
+}
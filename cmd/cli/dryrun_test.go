@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func Test_plannedUpserts(t *testing.T) {
+	big := bytes.Repeat([]byte{1}, batchMaxBytes-20)
+	got := plannedUpserts([]pendingUpsert{
+		{ID: "a", BaseVer: 0, Blob: []byte("xyz")},
+		{ID: "b", BaseVer: 4, Blob: big},
+	})
+	if len(got) != 2 || got[0] != (plannedWrite{Op: "upsert", ID: "a", Bytes: 3, Batch: 1}) || got[1].Batch != 2 || got[1].BaseVer != 4 || got[1].Bytes != len(big) {
+		t.Fatalf("planned: %+v", got)
+	}
+}
+
+func Test_plannedDeletes(t *testing.T) {
+	refs, err := parseItemRefs([]string{"0190a3c1-0000-7000-8000-000000000001:3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := plannedDeletes(refs)
+	if len(got) != 1 || got[0].Op != "delete" || got[0].BaseVer != 3 {
+		t.Fatalf("planned: %+v", got)
+	}
+}
+
+func Test_e2e_DryRunSendsNothing(t *testing.T) {
+	_ = startServer(t)
+	const addr = "gk.test:8443"
+	id := "0190a3c1-0000-7000-8000-0000000000aa"
+
+	out := stdoutOf(t, func() {
+		cmdAddText([]string{"-id", id, "-title", "t", "-text", "hello", "-dry-run"}, addr, "", false)
+	})
+	var planned []plannedWrite
+	if err := json.Unmarshal([]byte(out), &planned); err != nil || len(planned) != 1 || planned[0].ID != id || planned[0].Bytes == 0 {
+		t.Fatalf("dry run: %q %v", out, err)
+	}
+	outputFormat = "json"
+	t.Cleanup(func() { outputFormat = "" })
+	out = stdoutOf(t, func() { cmdStats(nil, addr, "", false) })
+	var st vaultStats
+	if err := json.Unmarshal([]byte(out), &st); err != nil || st.Live != 0 || st.Server.MaxVer != 0 {
+		t.Fatalf("stats after a dry run %q: %+v %v", out, st, err)
+	}
+}
@@ -0,0 +1,150 @@
+// cmd/cli/duress.go
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	cc "github.com/and161185/goph-keeper/internal/crypto/clientcrypto"
+)
+
+// cmdDuress sets up or removes the account's duress password: logging in
+// with it opens a decoy vault with its own DEK and items.
+func cmdDuress(args []string, addr, caPath string, insecure bool) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, tr("usage: duress setup|remove ..."))
+		os.Exit(exitUsage)
+	}
+	sub, args := args[0], args[1:]
+	fs := flag.NewFlagSet("duress "+sub, flag.ExitOnError)
+	var u, p, copyIDs *string
+	switch sub {
+	case "setup":
+		u = fs.String("u", "", "your username")
+		p = fs.String("p", "", "duress password; must differ from your password")
+		copyIDs = fs.String("copy", "", "comma-separated ids of items to copy into the decoy vault")
+	case "remove":
+	default:
+		fmt.Fprintf(os.Stderr, tr("unknown duress subcommand %q\n"), sub)
+		os.Exit(exitUsage)
+	}
+	parseFlags(fs, args)
+	if sub == "setup" && (*u == "" || *p == "") {
+		fmt.Fprintln(os.Stderr, tr("need -u and -p"))
+		os.Exit(exitUsage)
+	}
+
+	token, err := loadToken()
+	if err != nil {
+		fail(err)
+	}
+	ctx, cancel := withTimeout()
+	defer cancel()
+	conn, cli, err := dial(ctx, addr, caPath, insecure, token)
+	if err != nil {
+		fail(err)
+	}
+	defer conn.Close()
+
+	if sub == "remove" {
+		if _, err := cli.RemoveDuressPassword(ctx, &pb.RemoveDuressPasswordRequest{}); err != nil {
+			fail(err)
+		}
+		fmt.Println(colors(os.Stdout).ok(tr("duress password removed, with its vault")))
+		return
+	}
+
+	// read the items first: nothing is created if one of them is missing
+	var plain []pendingUpsert
+	for _, id := range splitColumns(*copyIDs) {
+		pt, err := readPlain(ctx, cli, id)
+		if err != nil {
+			fail(fmt.Errorf("%s: %w", id, err))
+		}
+		plain = append(plain, pendingUpsert{Plain: pt})
+	}
+
+	req := &pb.SetDuressPasswordRequest{}
+	req.SetPassword(*p)
+	if _, err := cli.SetDuressPassword(ctx, req); err != nil {
+		fail(err)
+	}
+	if err := setupDecoy(ctx, addr, caPath, insecure, *u, *p, plain); err != nil {
+		fmt.Fprint(os.Stderr, colors(os.Stderr).warn(tr("the duress password was set but its vault is incomplete; run gk duress remove and try again\n")))
+		fail(err)
+	}
+	fmt.Println(colors(os.Stdout).ok(tr("duress password set; %d items copied into its vault", len(plain))))
+}
+
+// readPlain fetches and decrypts the current version of an item of the
+// logged-in vault.
+func readPlain(ctx context.Context, cli pb.GophKeeperClient, id string) ([]byte, error) {
+	req := &pb.GetItemRequest{}
+	req.SetId(id)
+	it, err := getItem(ctx, cli, req)
+	if err != nil {
+		return nil, err
+	}
+	if it.GetDeleted() {
+		return nil, errItemDeleted
+	}
+	uid, err := loadUserID()
+	if err != nil {
+		return nil, err
+	}
+	return decryptForItem(id, uid, it.GetVer(), it.GetBlobEnc().GetCiphertext())
+}
+
+// setupDecoy logs in to the decoy vault without saving anything, gives it
+// a DEK as a first login would and stores items in it under new ids. The
+// decoy's DEK never touches the disk: the next login with the duress
+// password unwraps it like any other.
+func setupDecoy(ctx context.Context, addr, caPath string, insecure bool, username, password string, items []pendingUpsert) error {
+	conn, cli, err := dial(ctx, addr, caPath, insecure, "")
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	lr := &pb.LoginRequest{}
+	lr.SetUsername(username)
+	lr.SetPassword(password)
+	host, _ := os.Hostname()
+	lr.SetDeviceLabel(host)
+	resp, err := withChallenge("", func(answer string) (*pb.LoginResponse, error) {
+		lr.SetChallengeResponse(answer)
+		return cli.Login(ctx, lr)
+	})
+	if err != nil {
+		return err
+	}
+	kek := cc.DefaultKDF.DeriveKEK([]byte(password), resp.GetKekSalt())
+	dek, err := initDEK(ctx, addr, caPath, insecure, resp.GetAccessToken(), kek)
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		return nil
+	}
+
+	uid := resp.GetUserId()
+	for i := range items {
+		autoUUID(&items[i].ID)
+		key, err := cc.DeriveItemKey(dek, []byte(items[i].ID))
+		if err != nil {
+			return err
+		}
+		if items[i].Blob, err = cc.EncryptBlobWith(blobCipher, key, []byte(uid), []byte(items[i].ID), 1, items[i].Plain); err != nil {
+			return err
+		}
+	}
+	dconn, dcli, err := dial(ctx, addr, caPath, insecure, resp.GetAccessToken())
+	if err != nil {
+		return err
+	}
+	defer dconn.Close()
+	_, err = sendUpserts(ctx, dcli, items, nil)
+	return err
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/gofrs/uuid/v5"
+
+	"github.com/and161185/goph-keeper/pkg/gktest"
+)
+
+func Test_e2e_Duress(t *testing.T) {
+	_ = withTmpConfig(t)
+	srv := gktest.Start(t)
+	extraDialOptions = srv.DialOptions()
+	t.Cleanup(func() { extraDialOptions = nil })
+	const addr = "gk.test:8443"
+
+	_ = stdoutOf(t, func() { cmdSignup([]string{"-u", "erin", "-p", "pw"}, addr, "", false) })
+	realID, _ := loadUserID()
+	decoy, secret := uuid.Must(uuid.NewV7()).String(), uuid.Must(uuid.NewV7()).String()
+	_ = stdoutOf(t, func() {
+		cmdAddLogin([]string{"-id", decoy, "-title", "shop", "-username", "erin", "-password", "x"}, addr, "", false)
+		cmdAddLogin([]string{"-id", secret, "-title", "bank", "-username", "erin", "-password", "y"}, addr, "", false)
+	})
+
+	out := stdoutOf(t, func() { cmdDuress([]string{"setup", "-u", "erin", "-p", "decoy", "-copy", decoy}, addr, "", false) })
+	if !strings.Contains(out, "1 items copied") {
+		t.Fatalf("setup: %s", out)
+	}
+	if id, _ := loadUserID(); id != realID {
+		t.Fatalf("setup replaced the saved login: %s", id)
+	}
+
+	list := func() []changeRow {
+		t.Helper()
+		var rows []changeRow
+		out := stdoutOf(t, func() { cmdList(nil, addr, "", false) })
+		if err := json.Unmarshal([]byte(out), &rows); err != nil {
+			t.Fatalf("list %q: %v", out, err)
+		}
+		return rows
+	}
+	_ = stdoutOf(t, func() { cmdLogin([]string{"-u", "erin", "-p", "decoy"}, addr, "", false) })
+	rows := list()
+	if id, _ := loadUserID(); id == realID || len(rows) != 1 || rows[0].ID == decoy {
+		t.Fatalf("decoy vault of %s: %+v", id, rows)
+	}
+	if out := stdoutOf(t, func() { cmdShow([]string{"-id", rows[0].ID}, addr, "", false) }); !strings.Contains(out, `"title": "shop"`) {
+		t.Fatalf("copied item: %s", out)
+	}
+
+	_ = stdoutOf(t, func() { cmdLogin([]string{"-u", "erin", "-p", "pw"}, addr, "", false) })
+	if rows := list(); len(rows) != 2 {
+		t.Fatalf("real vault: %+v", rows)
+	}
+	_ = stdoutOf(t, func() { cmdDuress([]string{"remove"}, addr, "", false) })
+}
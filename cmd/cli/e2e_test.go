@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+
+	cc "github.com/and161185/goph-keeper/internal/crypto/clientcrypto"
+	"github.com/and161185/goph-keeper/pkg/gktest"
+)
+
+// startServer points the CLI at a fresh in-process server, logged in as a
+// new user with a local DEK.
+func startServer(t *testing.T, opts ...gktest.Option) *gktest.Server {
+	t.Helper()
+	_ = withTmpConfig(t)
+	srv := gktest.Start(t, opts...)
+	extraDialOptions = srv.DialOptions()
+	t.Cleanup(func() { extraDialOptions = nil })
+
+	uid, token := srv.NewUser(t, "alice", "secret")
+	dek, _ := cc.Rand(cc.DEKLen)
+	if err := saveDEK(dek); err != nil {
+		t.Fatal(err)
+	}
+	if err := saveUserID(uid); err != nil {
+		t.Fatal(err)
+	}
+	if err := saveToken(token, "", time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	return srv
+}
+
+func stdoutOf(t *testing.T, fn func()) string {
+	t.Helper()
+	old := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	defer func() { os.Stdout = old }()
+	fn()
+	_ = w.Close()
+	out, _ := io.ReadAll(r)
+	return string(out)
+}
+
+func Test_e2e_AddShowStats(t *testing.T) {
+	_ = startServer(t)
+	const addr = "gk.test:8443"
+
+	id := uuid.Must(uuid.NewV7()).String()
+	_ = stdoutOf(t, func() {
+		cmdAddLogin([]string{"-id", id, "-title", "mail", "-username", "alice", "-password", "pw"}, addr, "", false)
+	})
+
+	out := stdoutOf(t, func() { cmdShow([]string{"-id", id}, addr, "", false) })
+	if !strings.Contains(out, `"title": "mail"`) {
+		t.Fatalf("show: %s", out)
+	}
+
+	outputFormat = "json"
+	t.Cleanup(func() { outputFormat = "" })
+	out = stdoutOf(t, func() { cmdStats(nil, addr, "", false) })
+	var st vaultStats
+	if err := json.Unmarshal([]byte(out), &st); err != nil || st.Live != 1 || st.ByType["login"] != 1 || st.Server.MaxVer != 1 {
+		t.Fatalf("stats %q: %+v %v", out, st, err)
+	}
+}
@@ -0,0 +1,149 @@
+// cmd/cli/errors.go
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/and161185/goph-keeper/internal/errs"
+)
+
+// Process exit codes; stable for scripts.
+const (
+	exitGeneric  = 1 // unclassified failure
+	exitUsage    = 2 // bad flags/arguments
+	exitAuth     = 3 // not logged in, bad credentials, permission denied, rate limited
+	exitConflict = 4 // version conflict / already exists
+	exitNotFound = 5 // item missing or deleted
+	exitNetwork  = 6 // server unreachable or timed out
+	exitCrypto   = 7 // missing DEK or decryption/authentication failure
+)
+
+// Client-side sentinels used for classification.
+var (
+	errNoToken      = errors.New("no valid token (login required)")
+	errNoDEK        = errors.New("no DEK; login first")
+	errCrypto       = errors.New("crypto failure")
+	errItemDeleted  = errors.New("item is deleted")
+	errItemNotFound = errors.New("item not found")
+	errInvalidInput = errors.New("invalid input")
+	// errMergeConflict: a three-way merge needs a choice and there is no terminal to ask on.
+	errMergeConflict = errors.New("merge conflict")
+	// errStale: an item could not be settled against the server's newer version.
+	errStale = errors.New("version conflict")
+	// errBatchRejected: an all-or-nothing batch was rolled back because of some of its items.
+	errBatchRejected = errors.New("batch rejected")
+	// errNoServerInfo: the server predates GetServerInfo.
+	errNoServerInfo = errors.New("the server does not report its version or features")
+)
+
+// errorJSON switches fail() to print a structured error object (-error-json).
+var errorJSON bool
+
+// cliError is the machine-readable error printed with -error-json.
+type cliError struct {
+	Class    string `json:"class"`
+	ExitCode int    `json:"exit_code"`
+	Message  string `json:"message"`
+	GRPCCode string `json:"grpc_code,omitempty"`
+	Reason   string `json:"reason,omitempty"`   // google.rpc.ErrorInfo reason from the server
+	Incident string `json:"incident,omitempty"` // server log reference for internal errors
+}
+
+// classify maps an error to its class name and exit code.
+func classify(err error) cliError {
+	ce := cliError{Class: "error", ExitCode: exitGeneric, Message: err.Error()}
+	if s, ok := status.FromError(err); ok {
+		ce.GRPCCode = s.Code().String()
+		ce.Message = s.Message()
+		switch s.Code() {
+		case codes.Unauthenticated, codes.PermissionDenied, codes.ResourceExhausted:
+			ce.Class, ce.ExitCode = "auth", exitAuth
+		case codes.FailedPrecondition, codes.Aborted, codes.AlreadyExists:
+			ce.Class, ce.ExitCode = "conflict", exitConflict
+		case codes.NotFound:
+			ce.Class, ce.ExitCode = "not_found", exitNotFound
+		case codes.Unavailable, codes.DeadlineExceeded, codes.Canceled:
+			ce.Class, ce.ExitCode = "network", exitNetwork
+		case codes.InvalidArgument:
+			ce.Class, ce.ExitCode = "invalid", exitUsage
+		}
+		if info := errorInfo(s); info != nil {
+			ce.Reason = info.GetReason()
+			ce.Incident = info.GetMetadata()["incident"]
+			// reasons split codes that cover unrelated situations
+			switch ce.Reason {
+			case errs.ReasonUserQuota, errs.ReasonStorageQuota, errs.ReasonItemLimit:
+				ce.Class, ce.ExitCode = "quota", exitGeneric
+			case errs.ReasonNotConfigured:
+				ce.Class, ce.ExitCode = "error", exitGeneric
+			case errs.ReasonThrottled:
+				ce.Class, ce.ExitCode = "throttled", exitGeneric
+			case errs.ReasonVaultFrozen:
+				ce.Class, ce.ExitCode = "frozen", exitGeneric
+			case errs.ReasonAccountDisabled:
+				ce.Class = "disabled" // still exitAuth: logging in again will not help
+			case errs.ReasonClientTooOld, errs.ReasonUnsupportedFeature:
+				ce.Class, ce.ExitCode = "incompatible", exitGeneric
+			}
+		}
+		return ce
+	}
+
+	var ne net.Error
+	switch {
+	case errors.Is(err, errNoToken):
+		ce.Class, ce.ExitCode = "auth", exitAuth
+	case errors.Is(err, errNoDEK), errors.Is(err, errCrypto):
+		ce.Class, ce.ExitCode = "crypto", exitCrypto
+	case errors.Is(err, errItemDeleted), errors.Is(err, errItemNotFound):
+		ce.Class, ce.ExitCode = "not_found", exitNotFound
+	case errors.Is(err, errMergeConflict), errors.Is(err, errBatchRejected), errors.Is(err, errStale):
+		ce.Class, ce.ExitCode = "conflict", exitConflict
+	case errors.Is(err, errInvalidInput):
+		ce.Class, ce.ExitCode = "invalid", exitUsage
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &ne):
+		ce.Class, ce.ExitCode = "network", exitNetwork
+	}
+	return ce
+}
+
+// errorInfo returns the server's ErrorInfo detail, if any.
+func errorInfo(s *status.Status) *errdetails.ErrorInfo {
+	for _, d := range s.Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok && info.GetDomain() == errs.Domain {
+			return info
+		}
+	}
+	return nil
+}
+
+// fail prints err (plain or JSON) to stderr and exits with its class code.
+func fail(err error) {
+	ce := classify(err)
+	finishTelemetry(ce.Class)
+	if errorJSON {
+		_ = json.NewEncoder(os.Stderr).Encode(map[string]cliError{"error": ce})
+		os.Exit(ce.ExitCode)
+	}
+	c := colors(os.Stderr)
+	switch {
+	case ce.Reason == errs.ReasonAccountDisabled:
+		fmt.Fprintln(os.Stderr, c.bad(tr("the account is suspended; ask the server's administrator to reactivate it")))
+	case ce.GRPCCode != "" && ce.Reason != "":
+		fmt.Fprintln(os.Stderr, c.bad(fmt.Sprintf("rpc error: code=%s reason=%s msg=%s", ce.GRPCCode, ce.Reason, ce.Message)))
+	case ce.GRPCCode != "":
+		fmt.Fprintln(os.Stderr, c.bad(fmt.Sprintf("rpc error: code=%s msg=%s", ce.GRPCCode, ce.Message)))
+	default:
+		fmt.Fprintln(os.Stderr, c.bad(localizeErr(err)))
+	}
+	os.Exit(ce.ExitCode)
+}
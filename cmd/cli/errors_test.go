@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/and161185/goph-keeper/internal/errs"
+)
+
+func Test_classify(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		err   error
+		class string
+		code  int
+	}{
+		{status.Error(codes.Unauthenticated, "no auth"), "auth", exitAuth},
+		{status.Error(codes.ResourceExhausted, "rate limited"), "auth", exitAuth},
+		{status.Error(codes.FailedPrecondition, "version conflict"), "conflict", exitConflict},
+		{status.Error(codes.AlreadyExists, "taken"), "conflict", exitConflict},
+		{status.Error(codes.NotFound, "not found"), "not_found", exitNotFound},
+		{status.Error(codes.Unavailable, "down"), "network", exitNetwork},
+		{status.Error(codes.InvalidArgument, "bad"), "invalid", exitUsage},
+		{status.Error(codes.Internal, "boom"), "error", exitGeneric},
+		{errNoToken, "auth", exitAuth},
+		{errNoDEK, "crypto", exitCrypto},
+		{fmt.Errorf("decrypt: %w: %w", errCrypto, errors.New("auth failed")), "crypto", exitCrypto},
+		{errItemDeleted, "not_found", exitNotFound},
+		{fmt.Errorf("%w: item[0]: unknown type", errInvalidInput), "invalid", exitUsage},
+		{fmt.Errorf("dial: %w", context.DeadlineExceeded), "network", exitNetwork},
+		{errors.New("other"), "error", exitGeneric},
+	}
+	for _, c := range cases {
+		got := classify(c.err)
+		if got.Class != c.class || got.ExitCode != c.code {
+			t.Fatalf("%v: got %s/%d want %s/%d", c.err, got.Class, got.ExitCode, c.class, c.code)
+		}
+	}
+
+	ce := classify(status.Error(codes.NotFound, "nope"))
+	if ce.GRPCCode != "NotFound" || ce.Message != "nope" {
+		t.Fatalf("grpc details: %+v", ce)
+	}
+
+	withInfo := func(c codes.Code, reason string, meta map[string]string) error {
+		st, _ := status.New(c, "msg").WithDetails(&errdetails.ErrorInfo{Reason: reason, Domain: errs.Domain, Metadata: meta})
+		return st.Err()
+	}
+	ce = classify(withInfo(codes.ResourceExhausted, errs.ReasonStorageQuota, nil))
+	if ce.Class != "quota" || ce.ExitCode != exitGeneric || ce.Reason != errs.ReasonStorageQuota {
+		t.Fatalf("quota: %+v", ce)
+	}
+	ce = classify(withInfo(codes.ResourceExhausted, errs.ReasonItemLimit, nil))
+	if ce.Class != "quota" || ce.ExitCode != exitGeneric {
+		t.Fatalf("item limit: %+v", ce)
+	}
+	ce = classify(withInfo(codes.ResourceExhausted, errs.ReasonRateLimited, nil))
+	if ce.Class != "auth" || ce.Reason != errs.ReasonRateLimited {
+		t.Fatalf("rate limited: %+v", ce)
+	}
+	ce = classify(withInfo(codes.ResourceExhausted, errs.ReasonThrottled, nil))
+	if ce.Class != "throttled" || ce.ExitCode != exitGeneric {
+		t.Fatalf("throttled: %+v", ce)
+	}
+	ce = classify(withInfo(codes.FailedPrecondition, errs.ReasonVaultFrozen, nil))
+	if ce.Class != "frozen" || ce.ExitCode != exitGeneric {
+		t.Fatalf("frozen: %+v", ce)
+	}
+	ce = classify(withInfo(codes.PermissionDenied, errs.ReasonAccountDisabled, nil))
+	if ce.Class != "disabled" || ce.ExitCode != exitAuth || ce.Reason != errs.ReasonAccountDisabled {
+		t.Fatalf("disabled: %+v", ce)
+	}
+	ce = classify(withInfo(codes.Internal, errs.ReasonInternal, map[string]string{"incident": "abc123"}))
+	if ce.Incident != "abc123" || ce.ExitCode != exitGeneric {
+		t.Fatalf("internal: %+v", ce)
+	}
+}
+
+func Test_loadToken_MissingIsAuthClass(t *testing.T) {
+	_ = withTmpConfig(t)
+	_, err := loadToken()
+	if !errors.Is(err, errNoToken) {
+		t.Fatalf("want errNoToken, got %v", err)
+	}
+}
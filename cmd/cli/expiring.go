@@ -0,0 +1,141 @@
+// cmd/cli/expiring.go
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+)
+
+// dueItem is a license that expires or an API key due for rotation.
+type dueItem struct {
+	ID    string `json:"id"`
+	Type  string `json:"type"`
+	Title string `json:"title"`
+	// What is the meta key the date comes from: expires or rotate_by.
+	What string `json:"what"`
+	Date string `json:"date"`
+	// DaysLeft is negative once the date has passed.
+	DaysLeft int `json:"days_left"`
+}
+
+// dueDate returns the date an item needs attention by: a license's expiry
+// or an API key's rotation date. ok is false for other items and for
+// those without the date.
+func dueDate(p typedPayload) (what string, date time.Time, ok bool) {
+	var m struct {
+		Expires  string `json:"expires"`
+		RotateBy string `json:"rotate_by"`
+	}
+	_ = json.Unmarshal(p.Meta, &m)
+	var v string
+	switch p.Type {
+	case "license":
+		what, v = "expires", m.Expires
+	case "apikey":
+		what, v = "rotate_by", m.RotateBy
+	default:
+		return "", time.Time{}, false
+	}
+	date, err := time.Parse(dateLayout, v)
+	return what, date, err == nil
+}
+
+// daysUntil counts whole days from now's date to date.
+func daysUntil(date, now time.Time) int {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return int(date.Sub(today).Hours() / 24)
+}
+
+// findDue returns the items due within the given time of now, overdue ones
+// included, soonest first.
+func findDue(items []vaultItem, within time.Duration, now time.Time) []dueItem {
+	var out []dueItem
+	for _, it := range items {
+		what, date, ok := dueDate(it.Payload)
+		if !ok {
+			continue
+		}
+		left := daysUntil(date, now)
+		if time.Duration(left)*24*time.Hour > within {
+			continue
+		}
+		out = append(out, dueItem{ID: it.ID, Type: it.Payload.Type, Title: metaTitle(it.Payload), What: what, Date: date.Format(dateLayout), DaysLeft: left})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Date != out[j].Date {
+			return out[i].Date < out[j].Date
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
+
+// dueText says what is due for people.
+func dueText(d dueItem) string {
+	switch {
+	case d.What == "expires" && d.DaysLeft < 0:
+		return tr("expired on %s", d.Date)
+	case d.What == "expires":
+		return tr("expires on %s", d.Date)
+	case d.DaysLeft < 0:
+		return tr("rotation overdue since %s", d.Date)
+	}
+	return tr("rotate by %s", d.Date)
+}
+
+// printDue writes a human-readable due list.
+func printDue(w io.Writer, due []dueItem) {
+	for _, d := range due {
+		fmt.Fprintf(w, "  %4dd  %s  %-7s  %s  %s\n", d.DaysLeft, d.ID, d.Type, d.Title, dueText(d))
+	}
+}
+
+// dueLine is the status line gk show prints for a license or an API key.
+func dueLine(p typedPayload, now time.Time) (string, bool) {
+	what, date, ok := dueDate(p)
+	if !ok {
+		return "", false
+	}
+	return dueText(dueItem{What: what, Date: date.Format(dateLayout), DaysLeft: daysUntil(date, now)}), true
+}
+
+// cmdExpiring lists licenses that expire and API keys due for rotation soon.
+func cmdExpiring(args []string, addr, caPath string, insecure bool) {
+	fs := flag.NewFlagSet("expiring", flag.ExitOnError)
+	withinFlag := fs.String("within", "30d", "how far ahead to look (e.g. 30d, 12w)")
+	parseFlags(fs, args)
+	within, err := parseAge(*withinFlag)
+	if err != nil {
+		fail(fmt.Errorf("%w: %w", errInvalidInput, err))
+	}
+
+	token, err := loadToken()
+	if err != nil {
+		fail(err)
+	}
+	ctx, cancel := withTimeout()
+	defer cancel()
+	conn, cli, err := dial(ctx, addr, caPath, insecure, token)
+	if err != nil {
+		fail(err)
+	}
+	defer conn.Close()
+
+	items, err := listTyped(ctx, cli)
+	if err != nil {
+		fail(err)
+	}
+	due := findDue(items, within, time.Now())
+	emit(due, func() {
+		if len(due) == 0 {
+			fmt.Printf(tr("nothing expires or is due for rotation within %s\n"), *withinFlag)
+			return
+		}
+		printDue(os.Stdout, due)
+	})
+}
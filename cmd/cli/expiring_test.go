@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_findDue(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+	item := func(id, typ string, meta map[string]any) vaultItem {
+		meta["title"] = id
+		m, _ := json.Marshal(meta)
+		return vaultItem{ID: id, Payload: typedPayload{Type: typ, Meta: m, Data: json.RawMessage(`{"key":"k"}`)}}
+	}
+	items := []vaultItem{
+		item("expired", "license", map[string]any{"expires": "2026-03-01"}),
+		item("today", "apikey", map[string]any{"rotate_by": "2026-03-10"}),
+		item("soon", "license", map[string]any{"expires": "2026-03-20"}),
+		item("later", "apikey", map[string]any{"rotate_by": "2026-06-01"}),
+		item("perpetual", "license", map[string]any{}),
+		item("login", "login", map[string]any{"expires": "2026-03-01"}),
+	}
+
+	due := findDue(items, 30*24*time.Hour, now)
+	if len(due) != 3 || due[0].ID != "expired" || due[1].ID != "today" || due[2].ID != "soon" {
+		t.Fatalf("within 30d: %+v", due)
+	}
+	if due[0].DaysLeft != -9 || due[0].What != "expires" || due[1].DaysLeft != 0 || due[1].What != "rotate_by" || due[2].DaysLeft != 10 {
+		t.Fatalf("days left: %+v", due)
+	}
+	// gk audit lists only what is past its date
+	if past := findDue(items, -24*time.Hour, now); len(past) != 1 || past[0].ID != "expired" {
+		t.Fatalf("past: %+v", past)
+	}
+
+	if line, ok := dueLine(items[0].Payload, now); !ok || line != "expired on 2026-03-01" {
+		t.Fatalf("show line: %q %v", line, ok)
+	}
+	if _, ok := dueLine(items[4].Payload, now); ok {
+		t.Fatal("a perpetual license has no show line")
+	}
+}
+
+func Test_licenseSchema(t *testing.T) {
+	t.Parallel()
+
+	for _, bad := range []typedPayload{
+		{Type: "license", Meta: json.RawMessage(`{"expires":"2026-13-01"}`), Data: json.RawMessage(`{"key":"k"}`)},
+		{Type: "license", Meta: json.RawMessage(`{"seats":0}`), Data: json.RawMessage(`{"key":"k"}`)},
+		{Type: "apikey", Meta: json.RawMessage(`{}`), Data: json.RawMessage(`{}`)},
+		{Type: "apikey", Meta: json.RawMessage(`{"rotate_by":"10.03.2026"}`), Data: json.RawMessage(`{"key":"k"}`)},
+	} {
+		if err := validatePayload(bad); !errors.Is(err, errInvalidInput) {
+			t.Fatalf("%s %s: %v", bad.Type, bad.Meta, err)
+		}
+	}
+	ok := typedPayload{Type: "license", Meta: json.RawMessage(`{"seats":5,"purchased":"2025-01-02","expires":"2027-01-02"}`), Data: json.RawMessage(`{"key":"AAAA-BBBB"}`)}
+	if err := validatePayload(ok); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_checkDates(t *testing.T) {
+	t.Parallel()
+
+	err := checkDates([2]string{"purchased", "2026-01-02"}, [2]string{"expires", "soon"}, [2]string{"rotate-by", "later"})
+	if !errors.Is(err, errInvalidInput) || !strings.Contains(err.Error(), "-expires:") {
+		t.Fatalf("want the first bad flag, got %v", err)
+	}
+	if err := checkDates([2]string{"expires", ""}); err != nil {
+		t.Fatalf("an unset flag: %v", err)
+	}
+}
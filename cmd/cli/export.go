@@ -0,0 +1,179 @@
+// cmd/cli/export.go
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+)
+
+// exportItems calls fn with every live item of the vault as ExportItems
+// streams them. Items the stream leaves without a blob, being too big for a
+// message, are fetched with GetItemStream at the exported version. Servers
+// without ExportItems are read through GetChanges instead, which is not one
+// snapshot.
+func exportItems(ctx context.Context, cli pb.GophKeeperClient, fn func(*pb.Change) error) error {
+	stream, err := cli.ExportItems(ctx, &pb.ExportItemsRequest{})
+	if err != nil {
+		return err
+	}
+	for started := false; ; started = true {
+		m, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if !started && status.Code(err) == codes.Unimplemented {
+			return exportChanges(ctx, cli, fn)
+		}
+		if err != nil {
+			return err
+		}
+		for _, c := range m.GetItems() {
+			if !c.GetDeleted() && !c.HasBlobEnc() {
+				req := &pb.GetItemRequest{}
+				req.SetId(c.GetId())
+				req.SetVer(c.GetVer())
+				it, err := getItemStream(ctx, cli, req)
+				if err != nil {
+					return fmt.Errorf("%s: %w", c.GetId(), err)
+				}
+				c.SetBlobEnc(it.GetBlobEnc())
+			}
+			if err := fn(c); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// exportChanges is exportItems for servers without ExportItems.
+func exportChanges(ctx context.Context, cli pb.GophKeeperClient, fn func(*pb.Change) error) error {
+	req := &pb.GetChangesRequest{}
+	req.SetSinceVer(0)
+	out, err := fetchChanges(ctx, cli, req)
+	if err != nil {
+		return err
+	}
+	for _, c := range out.GetChanges() {
+		if c.GetDeleted() {
+			continue
+		}
+		if err := fn(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeExport decrypts the vault's typed items into w as a JSON array that
+// bulk-add reads back. With withIDs each element keeps its id and version
+// as base_ver, so bulk-add updates those items instead of adding copies.
+// It returns how many items it wrote and how many it skipped for not being
+// typed payloads.
+func writeExport(ctx context.Context, cli pb.GophKeeperClient, uid string, w io.Writer, withIDs bool) (n, skipped int, err error) {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return 0, 0, err
+	}
+	err = exportItems(ctx, cli, func(c *pb.Change) error {
+		pt, err := decryptForItem(c.GetId(), uid, c.GetVer(), c.GetBlobEnc().GetCiphertext())
+		if err != nil {
+			return fmt.Errorf("%s: %w", c.GetId(), err)
+		}
+		var obj typedPayload
+		if json.Unmarshal(pt, &obj) != nil || obj.Type == "" {
+			skipped++
+			return nil
+		}
+		it := bulkItem{Type: obj.Type, Meta: obj.Meta, Data: obj.Data, Fields: obj.Fields, Schema: obj.Schema}
+		if withIDs {
+			it.ID, it.BaseVer = c.GetId(), c.GetVer()
+		}
+		b, err := json.Marshal(it)
+		if err != nil {
+			return err
+		}
+		sep := ",\n"
+		if n == 0 {
+			sep = "\n"
+		}
+		if _, err := io.WriteString(w, sep); err != nil {
+			return err
+		}
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+		n++
+		return nil
+	})
+	if err != nil {
+		return n, skipped, err
+	}
+	_, err = io.WriteString(w, "\n]\n")
+	return n, skipped, err
+}
+
+// cmdExport writes the decrypted vault to a file, or stdout, in the
+// bulk-add format.
+func cmdExport(args []string, addr, caPath string, insecure bool) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	out := fs.String("out", "-", "file to create, - for stdout; it holds your secrets in the clear")
+	withIDs := fs.Bool("ids", false, "keep item ids and versions, so bulk-add updates the items in place")
+	parseFlags(fs, args)
+
+	token, err := loadToken()
+	if err != nil {
+		fail(err)
+	}
+	uid, err := loadUserID()
+	if err != nil {
+		fail(err)
+	}
+	// a large vault takes as long as it takes; ^C stops it
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	conn, cli, err := dial(ctx, addr, caPath, insecure, token)
+	if err != nil {
+		fail(err)
+	}
+	defer conn.Close()
+
+	var f *os.File
+	dst := os.Stdout
+	if *out != "-" {
+		if f, err = os.OpenFile(*out, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600); err != nil {
+			fail(err)
+		}
+		dst = f
+	}
+	w := bufio.NewWriter(dst)
+	n, skipped, err := writeExport(ctx, cli, uid, w, *withIDs)
+	if err == nil {
+		err = w.Flush()
+	}
+	if f != nil {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			_ = os.Remove(*out)
+		}
+	}
+	if err != nil {
+		fail(err)
+	}
+	if skipped > 0 {
+		fmt.Fprintf(os.Stderr, tr("skipped %d items that are not typed payloads\n"), skipped)
+	}
+	fmt.Fprintf(os.Stderr, tr("exported %d items\n"), n)
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gofrs/uuid/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+)
+
+// exportStream replays msgs, then err or io.EOF.
+type exportStream struct {
+	grpc.ClientStream
+	msgs []*pb.ExportItemsResponse
+	err  error
+}
+
+func (s *exportStream) Recv() (*pb.ExportItemsResponse, error) {
+	if len(s.msgs) == 0 {
+		if s.err != nil {
+			return nil, s.err
+		}
+		return nil, io.EOF
+	}
+	m := s.msgs[0]
+	s.msgs = s.msgs[1:]
+	return m, nil
+}
+
+// exportServer streams msgs, or fails with exportErr; GetChanges and
+// GetItemStream serve the rest.
+type exportServer struct {
+	pb.GophKeeperClient
+	msgs      []*pb.ExportItemsResponse
+	exportErr error
+	changes   []*pb.Change
+	chunks    []*pb.GetItemChunk
+	streamed  *pb.GetItemRequest
+}
+
+func (f *exportServer) ExportItems(context.Context, *pb.ExportItemsRequest, ...grpc.CallOption) (grpc.ServerStreamingClient[pb.ExportItemsResponse], error) {
+	return &exportStream{msgs: f.msgs, err: f.exportErr}, nil
+}
+
+func (f *exportServer) GetChanges(context.Context, *pb.GetChangesRequest, ...grpc.CallOption) (*pb.GetChangesResponse, error) {
+	out := &pb.GetChangesResponse{}
+	out.SetChanges(f.changes)
+	return out, nil
+}
+
+func (f *exportServer) GetItemStream(_ context.Context, req *pb.GetItemRequest, _ ...grpc.CallOption) (grpc.ServerStreamingClient[pb.GetItemChunk], error) {
+	f.streamed = req
+	return &chunkStream{chunks: f.chunks}, nil
+}
+
+func change(id string, ver int64, deleted bool, blob []byte) *pb.Change {
+	c := &pb.Change{}
+	c.SetId(id)
+	c.SetVer(ver)
+	c.SetDeleted(deleted)
+	if blob != nil {
+		eb := &pb.EncryptedBlob{}
+		eb.SetCiphertext(blob)
+		c.SetBlobEnc(eb)
+	}
+	return c
+}
+
+func Test_exportItems(t *testing.T) {
+	collect := func(f *exportServer) []string {
+		t.Helper()
+		var got []string
+		err := exportItems(context.Background(), f, func(c *pb.Change) error {
+			got = append(got, c.GetId()+":"+string(c.GetBlobEnc().GetCiphertext()))
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+
+	msg := &pb.ExportItemsResponse{}
+	msg.SetItems([]*pb.Change{change("a", 1, false, []byte("x")), change("it-1", 3, false, nil)})
+	f := &exportServer{msgs: []*pb.ExportItemsResponse{msg}, chunks: chunksOf([]byte("big"), 2)}
+	if got := collect(f); len(got) != 2 || got[0] != "a:x" || got[1] != "it-1:big" {
+		t.Fatalf("export: %v", got)
+	}
+	if f.streamed.GetId() != "it-1" || f.streamed.GetVer() != 3 {
+		t.Fatalf("blobless item fetched as %v", f.streamed)
+	}
+
+	old := &exportServer{
+		exportErr: status.Error(codes.Unimplemented, "unknown method ExportItems"),
+		changes:   []*pb.Change{change("a", 1, false, []byte("x")), change("b", 2, true, nil)},
+	}
+	if got := collect(old); len(got) != 1 || got[0] != "a:x" {
+		t.Fatalf("fallback to GetChanges: %v", got)
+	}
+
+	broken := &exportServer{msgs: []*pb.ExportItemsResponse{msg}, exportErr: status.Error(codes.Unimplemented, "late")}
+	broken.chunks = chunksOf([]byte("big"), 2)
+	err := exportItems(context.Background(), broken, func(*pb.Change) error { return nil })
+	if status.Code(err) != codes.Unimplemented {
+		t.Fatalf("error after the first message must not restart the export: %v", err)
+	}
+}
+
+func Test_e2e_Export(t *testing.T) {
+	_ = startServer(t)
+	const addr = "gk.test:8443"
+
+	id := uuid.Must(uuid.NewV7()).String()
+	_ = stdoutOf(t, func() {
+		cmdAddLogin([]string{"-id", id, "-title", "mail", "-username", "alice", "-password", "pw"}, addr, "", false)
+		cmdAddText([]string{"-title", "note", "-text", "hello"}, addr, "", false)
+	})
+
+	path := filepath.Join(t.TempDir(), "vault.json")
+	cmdExport([]string{"-out", path, "-ids"}, addr, "", false)
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	items, err := parseBulkItems(raw)
+	if err != nil {
+		t.Fatalf("bulk-add cannot read the export: %v\n%s", err, raw)
+	}
+	byType := map[string]bulkItem{}
+	for _, it := range items {
+		byType[it.Type] = it
+	}
+	if len(items) != 2 || byType["login"].ID != id || byType["login"].BaseVer != 1 || metaTitle(typedPayload{Meta: byType["text"].Meta}) != "note" {
+		t.Fatalf("export:\n%s", raw)
+	}
+}
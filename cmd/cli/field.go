@@ -0,0 +1,100 @@
+// cmd/cli/field.go
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// payloadFields flattens custom fields, meta and data into one map; on name
+// clashes data wins over meta, and both win over custom fields.
+func payloadFields(obj typedPayload) map[string]any {
+	out := map[string]any{}
+	for _, f := range obj.Fields {
+		out[f.Name] = f.Value
+	}
+	for _, raw := range []json.RawMessage{obj.Meta, obj.Data} {
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.UseNumber()
+		var m map[string]any
+		if dec.Decode(&m) != nil {
+			continue // non-object meta/data (e.g. binary bytes)
+		}
+		for k, v := range m {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// extractField returns the string form of a single named field.
+func extractField(obj typedPayload, name string) (string, error) {
+	fields := payloadFields(obj)
+	v, ok := fields[name]
+	if !ok {
+		names := make([]string, 0, len(fields))
+		for k := range fields {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		return "", fmt.Errorf("%s item has no field %q (available: %s)", obj.Type, name, strings.Join(names, ", "))
+	}
+	switch x := v.(type) {
+	case string:
+		return x, nil
+	case json.Number:
+		return x.String(), nil
+	case nil:
+		return "", nil
+	default:
+		b, _ := json.Marshal(x)
+		return string(b), nil
+	}
+}
+
+// cmdField prints (or copies) a single decrypted field of an item.
+func cmdField(args []string, addr, caPath string, insecure bool) {
+	fs := flag.NewFlagSet("field", flag.ExitOnError)
+	id := fs.String("id", "", "item id (uuid)")
+	name := fs.String("name", "", "field name (username, password, cvc, secret, ...)")
+	cp := fs.Bool("copy", false, "copy to clipboard instead of printing")
+	parseFlags(fs, args)
+	if *id == "" || *name == "" {
+		fmt.Fprintln(os.Stderr, tr("need -id and -name"))
+		os.Exit(exitUsage)
+	}
+
+	token, err := loadToken()
+	if err != nil {
+		fail(err)
+	}
+	ctx, cancel := withTimeout()
+	defer cancel()
+	conn, cli, err := dial(ctx, addr, caPath, insecure, token)
+	if err != nil {
+		fail(err)
+	}
+	defer conn.Close()
+
+	_, obj, err := fetchTyped(ctx, cli, *id)
+	if err != nil {
+		fail(err)
+	}
+	v, err := extractField(obj, *name)
+	if err != nil {
+		fail(err)
+	}
+	if *cp {
+		if err := copyToClipboard(v); err != nil {
+			fail(err)
+		}
+		fmt.Fprintf(os.Stderr, tr("%s copied to clipboard\n"), *name)
+		return
+	}
+	fmt.Println(v)
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_extractField(t *testing.T) {
+	t.Parallel()
+
+	login := typedPayload{
+		Type: "login",
+		Meta: []byte(`{"title":"GitHub","username":"me","port":8080}`),
+		Data: []byte(`{"password":"p@ss"}`),
+	}
+	for name, want := range map[string]string{"username": "me", "password": "p@ss", "port": "8080"} {
+		got, err := extractField(login, name)
+		if err != nil || got != want {
+			t.Fatalf("%s: got %q err %v", name, got, err)
+		}
+	}
+
+	_, err := extractField(login, "cvc")
+	if err == nil || !strings.Contains(err.Error(), "password") {
+		t.Fatalf("want error listing available fields, got %v", err)
+	}
+
+	bin := typedPayload{Type: "binary", Meta: []byte(`{"filename":"a.png"}`), Data: []byte(`"AAEC"`)}
+	if got, err := extractField(bin, "filename"); err != nil || got != "a.png" {
+		t.Fatalf("binary meta: %q %v", got, err)
+	}
+}
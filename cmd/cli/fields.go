@@ -0,0 +1,75 @@
+// cmd/cli/fields.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// customField is a user-defined key/value stored next to the typed meta/data.
+// Hidden fields are secrets: masked by show and redacted in history diffs.
+type customField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Hidden bool   `json:"hidden,omitempty"`
+}
+
+// customFieldFlags registers the repeatable -field / -hidden-field flags on fs
+// and returns a collector to call after fs.Parse.
+func customFieldFlags(fs *flag.FlagSet) func() ([]customField, error) {
+	var plain, hidden stringList
+	fs.Var(&plain, "field", "custom field name=value (repeatable)")
+	fs.Var(&hidden, "hidden-field", "secret custom field name=value (repeatable)")
+	return func() ([]customField, error) {
+		var out []customField
+		seen := map[string]bool{}
+		add := func(spec string, hide bool) error {
+			name, value, ok := strings.Cut(spec, "=")
+			name = strings.TrimSpace(name)
+			if !ok || name == "" {
+				return fmt.Errorf("%w: custom field %q: want name=value", errInvalidInput, spec)
+			}
+			if seen[name] {
+				return fmt.Errorf("%w: custom field %q given twice", errInvalidInput, name)
+			}
+			seen[name] = true
+			out = append(out, customField{Name: name, Value: value, Hidden: hide})
+			return nil
+		}
+		for _, spec := range plain {
+			if err := add(spec, false); err != nil {
+				return nil, err
+			}
+		}
+		for _, spec := range hidden {
+			if err := add(spec, true); err != nil {
+				return nil, err
+			}
+		}
+		return out, nil
+	}
+}
+
+// hiddenFieldNames returns the names of hidden custom fields.
+func hiddenFieldNames(obj typedPayload) map[string]bool {
+	out := map[string]bool{}
+	for _, f := range obj.Fields {
+		if f.Hidden {
+			out[f.Name] = true
+		}
+	}
+	return out
+}
+
+// visibleFields returns custom fields with hidden values masked unless reveal is set.
+func visibleFields(fields []customField, reveal bool) []customField {
+	out := make([]customField, len(fields))
+	for i, f := range fields {
+		if f.Hidden && !reveal {
+			f.Value = "********"
+		}
+		out[i] = f
+	}
+	return out
+}
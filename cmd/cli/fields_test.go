@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"strings"
+	"testing"
+)
+
+func Test_customFieldFlags(t *testing.T) {
+	t.Parallel()
+	parse := func(args ...string) ([]customField, error) {
+		fs := flag.NewFlagSet("t", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		collect := customFieldFlags(fs)
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		return collect()
+	}
+
+	got, err := parse("-field", "ip=10.0.0.1", "-hidden-field", "pin=12=34", "-field", "floor=3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []customField{{"ip", "10.0.0.1", false}, {"floor", "3", false}, {"pin", "12=34", true}}
+	if len(got) != 3 || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Fatalf("fields: %+v", got)
+	}
+
+	for _, args := range [][]string{{"-field", "noequals"}, {"-field", "=v"}, {"-field", "a=1", "-hidden-field", "a=2"}} {
+		if _, err := parse(args...); err == nil {
+			t.Fatalf("%v: want error", args)
+		}
+	}
+}
+
+func Test_customFields_PayloadRoundTrip(t *testing.T) {
+	t.Parallel()
+	pt, err := buildTypedPayloadFields("text", map[string]any{"title": "Router"}, map[string]any{"text": "x"},
+		[]customField{{Name: "ip", Value: "10.0.0.1"}, {Name: "pin", Value: "4821", Hidden: true}, {Name: "title", Value: "shadowed"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var obj typedPayload
+	if err := json.Unmarshal(pt, &obj); err != nil || len(obj.Fields) != 3 {
+		t.Fatalf("decode: %+v %v", obj, err)
+	}
+	if v, _ := extractField(obj, "pin"); v != "4821" {
+		t.Fatalf("field pin: %q", v)
+	}
+	if v, _ := extractField(obj, "title"); v != "Router" {
+		t.Fatalf("meta must win over custom field: %q", v)
+	}
+
+	vis := visibleFields(obj.Fields, false)
+	if vis[1].Value == "4821" || obj.Fields[1].Value != "4821" {
+		t.Fatalf("masking: %+v / original %+v", vis, obj.Fields)
+	}
+	if visibleFields(obj.Fields, true)[1].Value != "4821" {
+		t.Fatalf("reveal")
+	}
+
+	plain, _ := buildTypedPayload("text", map[string]any{}, map[string]any{})
+	if strings.Contains(string(plain), "fields") {
+		t.Fatalf("no custom fields must not add a key: %s", plain)
+	}
+}
+
+func Test_searchItems(t *testing.T) {
+	t.Parallel()
+	router := vaultItem{ID: "r", Payload: typedPayload{Type: "text", Meta: []byte(`{"title":"Router","note":"basement"}`),
+		Fields: []customField{{Name: "ip", Value: "192.168.1.1"}, {Name: "pin", Value: "4821", Hidden: true}}}}
+	gh := loginItem("g", "GitHub", "https://github.com", "me", "4821", "", 0)
+
+	if got := searchItems([]vaultItem{router, gh}, "192.168", ""); len(got) != 1 || got[0].Match != "field:ip" {
+		t.Fatalf("custom value: %+v", got)
+	}
+	if got := searchItems([]vaultItem{router, gh}, "BASEMENT", ""); len(got) != 1 || got[0].Match != "note" {
+		t.Fatalf("meta: %+v", got)
+	}
+	if got := searchItems([]vaultItem{router, gh}, "4821", ""); len(got) != 0 {
+		t.Fatalf("secrets must not be searchable: %+v", got)
+	}
+	if got := searchItems([]vaultItem{router, gh}, "pin", ""); len(got) != 1 || got[0].ID != "r" {
+		t.Fatalf("field name: %+v", got)
+	}
+	if got := searchItems([]vaultItem{router, gh}, "git", "text"); len(got) != 0 {
+		t.Fatalf("type filter: %+v", got)
+	}
+}
+
+func Test_secretMasks_HiddenCustomField(t *testing.T) {
+	t.Parallel()
+	a := typedPayload{Type: "text", Meta: []byte(`{}`), Data: []byte(`{}`), Fields: []customField{{Name: "pin", Value: "1111", Hidden: true}}}
+	b := typedPayload{Type: "text", Meta: []byte(`{}`), Data: []byte(`{}`), Fields: []customField{{Name: "pin", Value: "2222", Hidden: true}}}
+	ma, mb := secretMasks(a, b)
+	got := unifiedDiff(lineDiff(historyLines(a, ma), historyLines(b, mb)), "a", "b", 3)
+	if strings.Contains(got, "1111") || strings.Contains(got, "2222") || !strings.Contains(got, "+pin: [redacted, changed]") {
+		t.Fatalf("diff:\n%s", got)
+	}
+}
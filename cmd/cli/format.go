@@ -0,0 +1,158 @@
+// cmd/cli/format.go
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Global output selection (-format / -columns); empty format keeps each command's native output.
+var (
+	outputFormat  string
+	outputColumns string
+)
+
+// emit renders v in the selected -format, or calls native when no format was requested.
+func emit(v any, native func()) {
+	if outputFormat == "" {
+		native()
+		return
+	}
+	if err := writeFormatted(os.Stdout, outputFormat, splitColumns(outputColumns), v); err != nil {
+		fail(err)
+	}
+}
+
+func splitColumns(s string) []string {
+	var out []string
+	for _, c := range strings.Split(s, ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// writeFormatted writes v as json, yaml, table or go-template=<tmpl>.
+// Values are normalized through JSON first, so field names are always the JSON keys.
+func writeFormatted(w io.Writer, format string, columns []string, v any) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var generic any
+	if err := json.Unmarshal(raw, &generic); err != nil {
+		return err
+	}
+
+	switch {
+	case format == "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(generic)
+	case format == "yaml":
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(generic); err != nil {
+			return err
+		}
+		return enc.Close()
+	case format == "table":
+		return writeTable(w, raw, columns)
+	case strings.HasPrefix(format, "go-template="):
+		tmpl, err := template.New("out").Option("missingkey=zero").Parse(strings.TrimPrefix(format, "go-template="))
+		if err != nil {
+			return fmt.Errorf("template: %w", err)
+		}
+		if err := tmpl.Execute(w, generic); err != nil {
+			return err
+		}
+		_, err = io.WriteString(w, "\n")
+		return err
+	default:
+		return fmt.Errorf("unknown format %q (json|yaml|table|go-template=...)", format)
+	}
+}
+
+// writeTable prints an object or an array of objects as aligned columns.
+func writeTable(w io.Writer, raw []byte, columns []string) error {
+	raw = bytes.TrimSpace(raw)
+	var elems []json.RawMessage
+	switch {
+	case bytes.HasPrefix(raw, []byte("[")):
+		if err := json.Unmarshal(raw, &elems); err != nil {
+			return err
+		}
+	case bytes.HasPrefix(raw, []byte("{")):
+		elems = []json.RawMessage{raw}
+	default:
+		return errors.New("table format needs an object or a list of objects")
+	}
+
+	if len(columns) == 0 && len(elems) > 0 {
+		cols, err := orderedKeys(elems[0])
+		if err != nil {
+			return err
+		}
+		columns = cols
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.ToUpper(strings.Join(columns, "\t")))
+	for _, e := range elems {
+		var row map[string]any
+		if err := json.Unmarshal(e, &row); err != nil {
+			return err
+		}
+		cells := make([]string, len(columns))
+		for i, c := range columns {
+			cells[i] = cell(row[c])
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+// orderedKeys returns top-level object keys in document order.
+func orderedKeys(obj json.RawMessage) ([]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(obj))
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	var keys []string
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, tok.(string))
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+func cell(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case map[string]any, []any:
+		b, _ := json.Marshal(x)
+		return string(b)
+	default:
+		return fmt.Sprint(x)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+type fmtRow struct {
+	ID  string `json:"id"`
+	Ver int64  `json:"ver"`
+	Tag string `json:"tag,omitempty"`
+}
+
+var fmtRows = []fmtRow{{ID: "a", Ver: 1, Tag: "x"}, {ID: "b", Ver: 22}}
+
+func Test_writeFormatted_JSON_YAML(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	if err := writeFormatted(&buf, "json", nil, fmtRows); err != nil {
+		t.Fatalf("json: %v", err)
+	}
+	var back []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &back); err != nil || len(back) != 2 {
+		t.Fatalf("json roundtrip: %v %s", err, buf.String())
+	}
+
+	buf.Reset()
+	if err := writeFormatted(&buf, "yaml", nil, fmtRows); err != nil {
+		t.Fatalf("yaml: %v", err)
+	}
+	if !strings.Contains(buf.String(), "- id: a") || !strings.Contains(buf.String(), "ver: 22") {
+		t.Fatalf("yaml output: %s", buf.String())
+	}
+}
+
+func Test_writeFormatted_Table(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	if err := writeFormatted(&buf, "table", nil, fmtRows); err != nil {
+		t.Fatalf("table: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "ID") || !strings.Contains(lines[0], "TAG") {
+		t.Fatalf("table output: %q", buf.String())
+	}
+
+	buf.Reset()
+	if err := writeFormatted(&buf, "table", []string{"ver", "id"}, fmtRows); err != nil {
+		t.Fatalf("table columns: %v", err)
+	}
+	lines = strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if !strings.HasPrefix(lines[0], "VER") || strings.Contains(lines[0], "TAG") || !strings.HasPrefix(lines[2], "22") {
+		t.Fatalf("column selection: %q", buf.String())
+	}
+
+	buf.Reset()
+	if err := writeFormatted(&buf, "table", nil, fmtRows[0]); err != nil || strings.Count(buf.String(), "\n") != 2 {
+		t.Fatalf("single object table: %v %q", err, buf.String())
+	}
+	if err := writeFormatted(&buf, "table", nil, "scalar"); err == nil {
+		t.Fatalf("want error for scalar table")
+	}
+}
+
+func Test_writeFormatted_Template(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	if err := writeFormatted(&buf, `go-template={{range .}}{{.id}}={{.ver}};{{end}}`, nil, fmtRows); err != nil {
+		t.Fatalf("template: %v", err)
+	}
+	if buf.String() != "a=1;b=22;\n" {
+		t.Fatalf("template output: %q", buf.String())
+	}
+	if err := writeFormatted(&buf, "go-template={{", nil, fmtRows); err == nil {
+		t.Fatalf("want parse error")
+	}
+	if err := writeFormatted(&buf, "xml", nil, fmtRows); err == nil {
+		t.Fatalf("want unknown format error")
+	}
+}
+
+func Test_splitColumns(t *testing.T) {
+	t.Parallel()
+	got := splitColumns(" id, ver ,,")
+	if len(got) != 2 || got[0] != "id" || got[1] != "ver" {
+		t.Fatalf("splitColumns: %v", got)
+	}
+	if splitColumns("") != nil {
+		t.Fatalf("empty should be nil")
+	}
+}
@@ -0,0 +1,63 @@
+// cmd/cli/freeze.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+)
+
+// cmdFreeze makes the vault read-only on the server: every change is
+// refused, from any device, until gk unfreeze. Both ask for the password
+// again, so a token left on a lost laptop can do neither.
+func cmdFreeze(args []string, addr, caPath string, insecure bool) {
+	setFrozen("freeze", true, args, addr, caPath, insecure)
+}
+
+// cmdUnfreeze makes the vault writable again.
+func cmdUnfreeze(args []string, addr, caPath string, insecure bool) {
+	setFrozen("unfreeze", false, args, addr, caPath, insecure)
+}
+
+func setFrozen(name string, freeze bool, args []string, addr, caPath string, insecure bool) {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	p := fs.String("p", "", "your password")
+	parseFlags(fs, args)
+	if *p == "" {
+		fmt.Fprintln(os.Stderr, tr("need -p"))
+		os.Exit(exitUsage)
+	}
+
+	token, err := loadToken()
+	if err != nil {
+		fail(err)
+	}
+	ctx, cancel := withTimeout()
+	defer cancel()
+	conn, cli, err := dial(ctx, addr, caPath, insecure, token)
+	if err != nil {
+		fail(err)
+	}
+	defer conn.Close()
+
+	if !freeze {
+		req := &pb.UnfreezeVaultRequest{}
+		req.SetPassword(*p)
+		if _, err := cli.UnfreezeVault(ctx, req); err != nil {
+			fail(err)
+		}
+		fmt.Println(colors(os.Stdout).ok(tr("vault unfrozen; changes are accepted again")))
+		return
+	}
+	req := &pb.FreezeVaultRequest{}
+	req.SetPassword(*p)
+	resp, err := cli.FreezeVault(ctx, req)
+	if err != nil {
+		fail(err)
+	}
+	at := resp.GetFrozenAt().AsTime().Local().Format(time.DateTime)
+	fmt.Println(colors(os.Stdout).ok(tr("vault frozen since %s; changes are refused until gk unfreeze", at)))
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/gofrs/uuid/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/pkg/gktest"
+)
+
+func Test_e2e_Freeze(t *testing.T) {
+	_ = withTmpConfig(t)
+	srv := gktest.Start(t)
+	extraDialOptions = srv.DialOptions()
+	t.Cleanup(func() { extraDialOptions = nil })
+	const addr = "gk.test:8443"
+
+	_ = stdoutOf(t, func() { cmdSignup([]string{"-u", "hal", "-p", "pw"}, addr, "", false) })
+	if out := stdoutOf(t, func() { cmdFreeze([]string{"-p", "pw"}, addr, "", false) }); !strings.Contains(out, "vault frozen since") {
+		t.Fatalf("freeze: %s", out)
+	}
+
+	token, err := loadToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref := &pb.ItemRef{}
+	ref.SetId(uuid.Must(uuid.NewV7()).String())
+	ref.SetBaseVer(1)
+	req := &pb.DeleteItemsRequest{}
+	req.SetItems([]*pb.ItemRef{ref})
+	cli := srv.Client(t)
+	_, err = cli.DeleteItems(gktest.WithToken(context.Background(), token), req)
+	if status.Code(err) != codes.FailedPrecondition || classify(err).Class != "frozen" {
+		t.Fatalf("delete in a frozen vault: %v", err)
+	}
+
+	_ = stdoutOf(t, func() { cmdUnfreeze([]string{"-p", "pw"}, addr, "", false) })
+	id := uuid.Must(uuid.NewV7()).String()
+	_ = stdoutOf(t, func() { cmdAddText([]string{"-id", id, "-title", "n", "-text", "t"}, addr, "", false) })
+	if out := stdoutOf(t, func() { cmdShow([]string{"-id", id}, addr, "", false) }); !strings.Contains(out, `"title": "n"`) {
+		t.Fatalf("add after unfreezing: %s", out)
+	}
+}
@@ -0,0 +1,279 @@
+// cmd/cli/history.go
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"sort"
+	"strings"
+)
+
+// secretFields are payload fields hidden from history output unless -show-secrets is set.
+// Hidden custom fields are treated the same way.
+var secretFields = map[string]bool{"password": true, "cvc": true, "number": true, "secret": true, "pin": true}
+
+// historyLines renders a payload as stable, diff-friendly lines ("key: value", sorted).
+// Multi-line strings are expanded as indented blocks. mask, if non-nil, replaces
+// the value of the keys it contains.
+func historyLines(obj typedPayload, mask map[string]string) []string {
+	lines := []string{"type: " + obj.Type}
+	fields := payloadFields(obj)
+	if len(fields) == 0 && len(obj.Data) > 0 && obj.Data[0] != '{' {
+		lines = append(lines, fmt.Sprintf("data: (%d bytes encoded)", len(obj.Data)))
+	}
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if m, ok := mask[k]; ok {
+			lines = append(lines, k+": "+m)
+			continue
+		}
+		v, _ := extractField(obj, k)
+		if !strings.Contains(v, "\n") {
+			lines = append(lines, k+": "+v)
+			continue
+		}
+		lines = append(lines, k+": |")
+		for _, l := range strings.Split(v, "\n") {
+			lines = append(lines, "  "+l)
+		}
+	}
+	return lines
+}
+
+// secretMasks builds redaction placeholders for two versions, flagging secrets
+// that changed between them without revealing either value.
+func secretMasks(from, to typedPayload) (map[string]string, map[string]string) {
+	a, b := payloadFields(from), payloadFields(to)
+	ma, mb := map[string]string{}, map[string]string{}
+	secret := maps.Clone(secretFields)
+	maps.Copy(secret, hiddenFieldNames(from))
+	maps.Copy(secret, hiddenFieldNames(to))
+	for k := range secret {
+		va, inA := a[k]
+		vb, inB := b[k]
+		if inA {
+			ma[k] = "[redacted]"
+		}
+		if inB {
+			mb[k] = "[redacted]"
+			if inA && fmt.Sprint(va) != fmt.Sprint(vb) {
+				mb[k] = "[redacted, changed]"
+			}
+		}
+	}
+	return ma, mb
+}
+
+// diffOp is one line of an edit script: ' ' keep, '-' delete, '+' insert.
+type diffOp struct {
+	Kind byte
+	Line string
+}
+
+// maxLCSCells bounds the LCS table; larger inputs fall back to a full replace.
+const maxLCSCells = 4 << 20
+
+// lineDiff computes a line edit script from a to b (LCS after trimming common prefix/suffix).
+func lineDiff(a, b []string) []diffOp {
+	var pre, suf []diffOp
+	for len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+		pre = append(pre, diffOp{' ', a[0]})
+		a, b = a[1:], b[1:]
+	}
+	for len(a) > 0 && len(b) > 0 && a[len(a)-1] == b[len(b)-1] {
+		suf = append([]diffOp{{' ', a[len(a)-1]}}, suf...)
+		a, b = a[:len(a)-1], b[:len(b)-1]
+	}
+
+	var mid []diffOp
+	if (len(a)+1)*(len(b)+1) > maxLCSCells {
+		for _, l := range a {
+			mid = append(mid, diffOp{'-', l})
+		}
+		for _, l := range b {
+			mid = append(mid, diffOp{'+', l})
+		}
+		return append(append(pre, mid...), suf...)
+	}
+
+	// lcs[i][j] = LCS length of a[i:], b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			mid = append(mid, diffOp{' ', a[i]})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			mid = append(mid, diffOp{'-', a[i]})
+			i++
+		default:
+			mid = append(mid, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	return append(append(pre, mid...), suf...)
+}
+
+// unifiedDiff formats an edit script as a unified diff with ctx lines of context.
+// It returns "" when both sides are equal.
+func unifiedDiff(ops []diffOp, fromName, toName string, ctx int) string {
+	var b strings.Builder
+	n := len(ops)
+	for start := 0; start < n; {
+		// find next change
+		c := start
+		for c < n && ops[c].Kind == ' ' {
+			c++
+		}
+		if c == n {
+			break
+		}
+		lo := max(c-ctx, start)
+		// extend the hunk while changes are within 2*ctx of each other
+		hi, gap := c, 0
+		for k := c; k < n; k++ {
+			if ops[k].Kind == ' ' {
+				gap++
+				if gap > 2*ctx {
+					break
+				}
+			} else {
+				gap = 0
+				hi = k
+			}
+		}
+		hi = min(hi+ctx, n-1)
+
+		// line numbers (1-based) of the hunk start on each side
+		aLine, bLine := 1, 1
+		for _, op := range ops[:lo] {
+			if op.Kind != '+' {
+				aLine++
+			}
+			if op.Kind != '-' {
+				bLine++
+			}
+		}
+		aCnt, bCnt := 0, 0
+		for _, op := range ops[lo : hi+1] {
+			if op.Kind != '+' {
+				aCnt++
+			}
+			if op.Kind != '-' {
+				bCnt++
+			}
+		}
+		if b.Len() == 0 {
+			fmt.Fprintf(&b, "--- %s\n+++ %s\n", fromName, toName)
+		}
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n", hunkRange(aLine, aCnt), hunkRange(bLine, bCnt))
+		for _, op := range ops[lo : hi+1] {
+			b.WriteByte(op.Kind)
+			b.WriteString(op.Line)
+			b.WriteByte('\n')
+		}
+		start = hi + 1
+	}
+	return b.String()
+}
+
+// hunkRange formats a "start,count" range the way diff(1) does.
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start-1)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// cmdHistory dispatches history subcommands.
+func cmdHistory(args []string, addr, caPath string, insecure bool) {
+	if len(args) == 0 || args[0] != "diff" {
+		fmt.Fprintln(os.Stderr, tr("usage: history diff -id <uuid> -from <ver> [-to <ver>] [-show-secrets]"))
+		os.Exit(exitUsage)
+	}
+	fs := flag.NewFlagSet("history diff", flag.ExitOnError)
+	id := fs.String("id", "", "item id (uuid)")
+	from := fs.Int64("from", 0, "older version")
+	to := fs.Int64("to", 0, "newer version (default: current)")
+	showSecrets := fs.Bool("show-secrets", false, "print secret fields in clear")
+	unified := fs.Int("U", 3, "lines of context")
+	parseFlags(fs, args[1:])
+	if *id == "" || *from <= 0 || *to < 0 {
+		fmt.Fprintln(os.Stderr, tr("need -id and -from >= 1"))
+		os.Exit(exitUsage)
+	}
+
+	token, err := loadToken()
+	if err != nil {
+		fail(err)
+	}
+	ctx, cancel := withTimeout()
+	defer cancel()
+	conn, cli, err := dial(ctx, addr, caPath, insecure, token)
+	if err != nil {
+		fail(err)
+	}
+	defer conn.Close()
+
+	// Tombstones carry no decryptable payload; they diff as an empty side.
+	load := func(ver int64) (int64, typedPayload, bool) {
+		it, obj, err := fetchTypedAt(ctx, cli, *id, ver)
+		if errors.Is(err, errItemDeleted) {
+			return ver, typedPayload{}, true
+		}
+		if err != nil {
+			fail(fmt.Errorf("version %d: %w", ver, err))
+		}
+		return it.GetVer(), obj, false
+	}
+	fromVer, a, aDel := load(*from)
+	toVer, b, bDel := load(*to)
+
+	var ma, mb map[string]string
+	if !*showSecrets {
+		ma, mb = secretMasks(a, b)
+	}
+	side := func(obj typedPayload, deleted bool, mask map[string]string) []string {
+		if deleted {
+			return nil
+		}
+		return historyLines(obj, mask)
+	}
+	label := func(ver int64, deleted bool) string {
+		s := fmt.Sprintf("%s@v%d", *id, ver)
+		if deleted {
+			s += " (deleted)"
+		}
+		return s
+	}
+	out := unifiedDiff(lineDiff(side(a, aDel, ma), side(b, bDel, mb)), label(fromVer, aDel), label(toVer, bDel), *unified)
+	if out == "" {
+		fmt.Fprintf(os.Stderr, tr("no differences between v%d and v%d\n"), fromVer, toVer)
+		return
+	}
+	fmt.Print(colors(os.Stdout).diff(out))
+}
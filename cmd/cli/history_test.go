@@ -0,0 +1,71 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_unifiedDiff_TextItem(t *testing.T) {
+	t.Parallel()
+
+	a := typedPayload{Type: "text", Meta: []byte(`{"title":"notes"}`), Data: []byte(`{"text":"one\ntwo\nthree"}`)}
+	b := typedPayload{Type: "text", Meta: []byte(`{"title":"notes"}`), Data: []byte(`{"text":"one\n2\nthree\nfour"}`)}
+	got := unifiedDiff(lineDiff(historyLines(a, nil), historyLines(b, nil)), "x@v3", "x@v5", 3)
+	want := `--- x@v3
++++ x@v5
+@@ -1,6 +1,7 @@
+ type: text
+ text: |
+   one
+-  two
++  2
+   three
++  four
+ title: notes
+`
+	if got != want {
+		t.Fatalf("diff:\n%s\nwant:\n%s", got, want)
+	}
+
+	if d := unifiedDiff(lineDiff(historyLines(a, nil), historyLines(a, nil)), "a", "b", 3); d != "" {
+		t.Fatalf("equal inputs must produce no diff, got %q", d)
+	}
+}
+
+func Test_unifiedDiff_SeparateHunks(t *testing.T) {
+	t.Parallel()
+	var a, b []string
+	for i := 0; i < 20; i++ {
+		a = append(a, string(rune('a'+i)))
+	}
+	b = append(b, a...)
+	b[1], b[18] = "B", "S"
+	got := unifiedDiff(lineDiff(a, b), "a", "b", 1)
+	if strings.Count(got, "@@ -") != 2 || !strings.Contains(got, "@@ -1,3 +1,3 @@") || !strings.Contains(got, "@@ -18,3 +18,3 @@") {
+		t.Fatalf("hunks:\n%s", got)
+	}
+
+	got = unifiedDiff(lineDiff(nil, []string{"x"}), "a", "b", 3)
+	if !strings.Contains(got, "@@ -0,0 +1 @@\n+x\n") {
+		t.Fatalf("insert into empty:\n%s", got)
+	}
+}
+
+func Test_secretMasks_RedactsAndFlagsChange(t *testing.T) {
+	t.Parallel()
+	a := typedPayload{Type: "login", Meta: []byte(`{"title":"gh","username":"me"}`), Data: []byte(`{"password":"old"}`)}
+	b := typedPayload{Type: "login", Meta: []byte(`{"title":"gh","username":"me"}`), Data: []byte(`{"password":"new"}`)}
+	ma, mb := secretMasks(a, b)
+	got := unifiedDiff(lineDiff(historyLines(a, ma), historyLines(b, mb)), "a", "b", 3)
+	if strings.Contains(got, "old") || strings.Contains(got, "new") {
+		t.Fatalf("secret leaked:\n%s", got)
+	}
+	if !strings.Contains(got, "-password: [redacted]\n+password: [redacted, changed]\n") {
+		t.Fatalf("want changed marker:\n%s", got)
+	}
+
+	ma, mb = secretMasks(a, a)
+	if d := unifiedDiff(lineDiff(historyLines(a, ma), historyLines(a, mb)), "a", "b", 3); d != "" {
+		t.Fatalf("unchanged secret must not diff:\n%s", d)
+	}
+}
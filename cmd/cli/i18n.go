@@ -0,0 +1,74 @@
+// cmd/cli/i18n.go
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// lang is the language of human-readable output; -lang overrides the locale
+// environment. JSON output, error classes and exit codes never change.
+var lang = "en"
+
+// catalogs maps a language to translations keyed by the English message.
+// English needs no catalog: the key is the message.
+var catalogs = map[string]map[string]string{
+	"ru": ruMessages,
+}
+
+// detectLang picks the language from the POSIX locale variables, in their
+// precedence order. Unknown languages fall back to English.
+func detectLang(getenv func(string) string) string {
+	for _, k := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := getenv(k); v != "" {
+			return parseLang(v)
+		}
+	}
+	return "en"
+}
+
+// parseLang reduces a locale such as ru_RU.UTF-8 to a supported language.
+func parseLang(v string) string {
+	v = strings.ToLower(v)
+	if i := strings.IndexAny(v, "_.@-"); i >= 0 {
+		v = v[:i]
+	}
+	if _, ok := catalogs[v]; ok {
+		return v
+	}
+	return "en"
+}
+
+// setLang is the -lang flag.
+func setLang(v string) error {
+	if l := parseLang(v); l != "en" || strings.HasPrefix(strings.ToLower(v), "en") {
+		lang = l
+		return nil
+	}
+	return fmt.Errorf("unsupported language %q (en, ru)", v)
+}
+
+// tr translates msg into the current language and formats it with args.
+// A message missing from the catalog is printed in English.
+func tr(msg string, args ...any) string {
+	if t, ok := catalogs[lang][msg]; ok {
+		msg = t
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// localizeErr is err's text with the client sentinel it wraps translated;
+// details from the server or the OS stay as they are.
+func localizeErr(err error) string {
+	msg := err.Error()
+	for _, s := range []error{errNoToken, errNoDEK, errCrypto, errItemDeleted, errItemNotFound, errInvalidInput, errMergeConflict, errBatchRejected, errStale, errNoServerInfo} {
+		if errors.Is(err, s) {
+			return strings.Replace(msg, s.Error(), tr(s.Error()), 1)
+		}
+	}
+	return msg
+}
@@ -0,0 +1,398 @@
+// cmd/cli/i18n_ru.go
+package main
+
+// ruMessages is the Russian catalog. Keep format verbs in the same order as
+// in the English key; i18n_test.go checks both.
+var ruMessages = map[string]string{
+	usageHead: `gk CLI
+Использование:
+  gk -addr HOST:PORT [-lang en|ru] [-no-color] [-cacert file | -insecure | -tofu] [-pin-sha256 KEY] [-format json|yaml|table|go-template='...'] [-columns a,b] [-error-json] [-no-cache] [-max-skew 2m] [-renew-before 5m] [-cipher aes-256-gcm] [-schema-check strict|warn|off] [-non-interactive] <команда> [аргументы]
+  Глобальные флаги можно указывать и после группы: gk item -format table list
+  gk help <команда> описывает команду или группу; прежние имена (add-login, server-info, admin-ip...) тоже работают.
+
+Команды:
+`,
+	usageTail: `
+Окружение:
+  GK_<ФЛАГ> задаёт глобальный флаг, GK_<КОМАНДА>_<ФЛАГ> — флаг команды (GK_ADDR, GK_LOGIN_P, GK_TEMPLATE_APPLY_NAME)
+
+Коды выхода:
+  0 успех, 1 ошибка, 2 использование/неверный ввод, 3 авторизация, 4 конфликт, 5 не найдено, 6 сеть, 7 криптография
+`,
+
+	// command help
+	"Usage: gk %s":                                                                "Использование: gk %s",
+	"Usage: gk %s <command> [args]\n":                                             "Использование: gk %s <команда> [аргументы]\n",
+	"Aliases: %s\n":                                                               "Другие имена: %s\n",
+	"Also: gk %s\n":                                                               "Также: gk %s\n",
+	"\nCommands:\n":                                                               "\nКоманды:\n",
+	"\nRun gk %s -h for its flags.\n":                                             "\nФлаги команды: gk %s -h\n",
+	"print gk's version":                                                          "вывести версию gk",
+	"create, log in to and look after your account":                               "создание учётной записи, вход и её обслуживание",
+	"create an account and print its user id":                                     "создать учётную запись и вывести её id",
+	"register, log in and set up the DEK in one step":                             "регистрация, вход и настройка DEK за один шаг",
+	"saves token; -label names this device, default hostname":                     "сохраняет токен; -label называет это устройство, по умолчанию имя хоста",
+	"recent logins and wrong passwords on your account":                           "недавние входы и неверные пароли для вашей учётной записи",
+	"devices signed in to your account, with last use":                            "устройства, вошедшие в вашу учётную запись, и когда они использовались",
+	"a second password that opens a decoy vault":                                  "второй пароль, открывающий подставное хранилище",
+	"public keys pinned for -addr; -fetch also shows the one it presents":         "закреплённые открытые ключи сервера -addr; -fetch показывает и тот, что он предъявляет",
+	"seal token, DEK, user id and cache under a key in the OS keychain":           "зашифровать токен, DEK, id пользователя и кэш ключом из связки ключей ОС",
+	"offline DEK copy, X25519+ML-KEM-768":                                         "офлайн-копия DEK, X25519+ML-KEM-768",
+	"tune Argon2id for this machine":                                              "подобрать Argon2id для этой машины",
+	"read, add, change and delete vault items":                                    "чтение, добавление, изменение и удаление записей хранилища",
+	"GetChanges since 0":                                                          "GetChanges с версии 0",
+	"-nag-stale: remind about old passwords on stderr; -all: ignore sync-filter":  "-nag-stale: напоминать о старых паролях в stderr; -all: без учёта sync-filter",
+	"which items sync lists and syncd mirrors":                                    "какие записи выводит sync и зеркалирует syncd",
+	"keep an item offline on this device only (plus each -device)":                "хранить запись офлайн только на этом устройстве (и на каждом -device)",
+	"print an item's type, meta and data size":                                    "вывести тип, meta и размер данных записи",
+	"print a typed item; -out writes binary data to a file":                       "вывести типизированную запись; -out пишет двоичные данные в файл",
+	"base_ver=0; -i: wizard: pick a type, answer prompts; secrets are not echoed": "base_ver=0; -i: мастер: выбрать тип и ответить на вопросы; секреты не отображаются",
+	"add a login":       "добавить логин",
+	"add a text note":   "добавить текстовую заметку",
+	"add a bank card":   "добавить банковскую карту",
+	"add a file":        "добавить файл",
+	"add a TOTP secret": "добавить секрет TOTP",
+	"add a Wi-Fi network; show -qr prints it for a QR code":                   "добавить сеть Wi-Fi; show -qr выводит её для QR-кода",
+	"add a software license":                                                  "добавить лицензию на ПО",
+	"add an API key":                                                          "добавить API-ключ",
+	"add a bank account; show masks the IBAN":                                 "добавить банковский счёт; show маскирует IBAN",
+	"replace an item's data with a file":                                      "заменить данные записи содержимым файла",
+	"several items: all deleted or none":                                      "несколько записей: удаляются все или ни одна",
+	"current OTP codes; -watch refreshes with countdown":                      "текущие OTP-коды; -watch обновляет их с обратным отсчётом",
+	"open login URL in browser; -copy puts password on clipboard":             "открыть URL логина в браузере; -copy копирует пароль в буфер обмена",
+	"print/copy one decrypted field, e.g. username|password|cvc|secret":       "вывести/скопировать одно расшифрованное поле, напр. username|password|cvc|secret",
+	"encrypt+upload a JSON array of {type,meta,data[,id,base_ver]}":           "зашифровать и загрузить JSON-массив {type,meta,data[,id,base_ver]}",
+	"decrypt the whole vault into the JSON array bulk-add takes":              "расшифровать всё хранилище в JSON-массив, который принимает bulk-add",
+	"find duplicate logins; interactive merge/delete":                         "найти дубликаты логинов; интерактивное слияние/удаление",
+	"search titles, meta and custom fields; never secrets":                    "поиск по заголовкам, meta и своим полям; секреты не ищутся",
+	"apply prompts for missing values":                                        "apply запрашивает недостающие значения",
+	"stale passwords, weak Wi-Fi keys, expired licenses and overdue API keys": "старые пароли, слабые ключи Wi-Fi, истёкшие лицензии и просроченные API-ключи",
+	"licenses that expire and API keys due for rotation soon":                 "лицензии, которые скоро истекают, и API-ключи, которые пора сменить",
+	"counts by type, sizes, recent items, cache vs server cursor":             "число записей по типам, размеры, недавние записи, курсор кэша и сервера",
+	"which devices fetched an item, when and how often":                       "какие устройства получали запись, когда и сколько раз",
+	"unified diff of two versions":                                            "unified diff двух версий",
+	"import logins; folders kept in meta; skips existing url+username":        "импорт логинов; папки сохраняются в meta; существующие url+username пропускаются",
+	"compare a Merkle root of the local cache with the server's":              "сравнить корень Меркла локального кэша с серверным",
+	"keep a local cache warm; list/open -title/... read from it":              "держать локальный кэш актуальным; list/open -title/... читают из него",
+	"change notifications: id, version, deleted flag; never data":             "уведомления об изменениях: id, версия, флаг удаления; без данных",
+	`push "vault changed" to a device`:                                        "push «хранилище изменилось» на устройство",
+	"what the server offers":                                                  "что предлагает сервер",
+	"server version and features, and whether this gk suits it":               "версия и возможности сервера и подходит ли ему этот gk",
+	"server administration; needs an admin token":                             "администрирование сервера; нужен токен администратора",
+	"toggle server diagnostics":                                               "включить/выключить диагностику сервера",
+	"roll a user's items back to that time":                                   "откатить записи пользователя к этому моменту",
+	"check a user's stored items for divergence":                              "проверить, не разошлись ли сохранённые записи пользователя",
+	"suspend an account; its tokens stop working at once":                     "приостановить учётную запись; её токены сразу перестают работать",
+	"lift an account's suspension":                                            "снять приостановку учётной записи",
+	"largest users' storage and its growth":                                   "объём данных крупнейших пользователей и его рост",
+	"list or lift login lockouts":                                             "показать или снять блокировки входа",
+	"a user's address rules":                                                  "правила адресов пользователя",
+	"help for a command or group":                                             "справка по команде или группе",
+
+	// errors.go sentinels
+	"no valid token (login required)": "нет действующего токена (нужен login)",
+	"no DEK; login first":             "нет DEK; сначала выполните login",
+	"crypto failure":                  "ошибка криптографии",
+	"item is deleted":                 "запись удалена",
+	"item not found":                  "запись не найдена",
+	"invalid input":                   "неверный ввод",
+	"merge conflict":                  "конфликт слияния",
+	"batch rejected":                  "пакет отклонён",
+	"version conflict":                "конфликт версий",
+	"the server does not report its version or features": "сервер не сообщает свою версию и возможности",
+
+	// common flag checks
+	"need -p": "нужен -p",
+	"vault frozen since %s; changes are refused until gk unfreeze": "хранилище заморожено с %s; изменения отклоняются до gk unfreeze",
+	"vault unfrozen; changes are accepted again":                   "хранилище разморожено; изменения снова принимаются",
+	"refuse every change to the vault until unfreeze":              "отклонять любые изменения хранилища до unfreeze",
+	"accept changes to the vault again":                            "снова принимать изменения хранилища",
+	"usage: telemetry on -url URL | off | status":                  "использование: telemetry on -url URL | off | status",
+	"need -url": "нужен -url",
+	"telemetry on: each command's name, duration and error class go to %s\n":     "телеметрия включена: имя каждой команды, её длительность и класс ошибки отправляются на %s\n",
+	"telemetry off; unsent events deleted\n":                                     "телеметрия выключена; неотправленные события удалены\n",
+	"opt in to sending command names, durations and error classes; nothing else": "согласиться на отправку имён команд, длительностей и классов ошибок; больше ничего",
+	"need -u and -p":                   "нужны -u и -p",
+	"need -id":                         "нужен -id",
+	"need -file":                       "нужен -file",
+	"need -name":                       "нужен -name",
+	"need -id -base or <uuid>:<ver>":   "нужны -id и -base или <uuid>:<ver>",
+	"need -id -base -file":             "нужны -id, -base и -file",
+	"need -id and -name":               "нужны -id и -name",
+	"need -id and -from >= 1":          "нужны -id и -from >= 1",
+	"need exactly one of -id / -title": "нужен ровно один из -id / -title",
+	"need exactly one of -on / -off":   "нужен ровно один из -on / -off",
+	"-user and -to are required":       "нужны -user и -to",
+	"-to wants an RFC 3339 time or a duration such as 2h":                               "-to: нужно время RFC 3339 или длительность, например 2h",
+	"usage: webhook add -url URL | list | rm -id ID":                                    "использование: webhook add -url URL | list | rm -id ID",
+	"unknown webhook subcommand %q\n":                                                   "неизвестная подкоманда webhook %q\n",
+	"webhook add needs -url, webhook rm needs -id":                                      "для webhook add нужен -url, для webhook rm нужен -id",
+	"webhook %s removed\n":                                                              "вебхук %s удалён\n",
+	"usage: device add -platform fcm|apns|ntfy [-token T] [-name N] | list | rm -id ID": "использование: device add -platform fcm|apns|ntfy [-token T] [-name N] | list | rm -id ID",
+	"unknown device subcommand %q\n":                                                    "неизвестная подкоманда device %q\n",
+	"device add needs -platform and -token, device rm needs -id":                        "для device add нужны -platform и -token, для device rm нужен -id",
+	"subscribe to this topic, e.g. syncd -ntfy <server>/<topic>; keep it private":       "подпишитесь на эту тему, например syncd -ntfy <сервер>/<тема>; держите её в секрете",
+	"device %s removed\n":                                                               "устройство %s удалено\n",
+	"%d items were saved by a newer client; upgrade to read them\n":                     "%d записей сохранены более новым клиентом; обновитесь, чтобы их прочитать\n",
+	"long poll failed: %v; retrying in %s\n":                                            "длинный опрос не удался: %v; повтор через %s\n",
+	"server does not support long polling; using -interval only":                        "сервер не поддерживает длинный опрос; используется только -interval",
+	"ntfy subscription lost: %v; retrying in %s\n":                                      "подписка ntfy потеряна: %v; повтор через %s\n",
+	"store the secret now: it verifies X-GophKeeper-Signature and is not shown again":   "сохраните секрет сейчас: он проверяет X-GophKeeper-Signature и больше не будет показан",
+	"username and password required":                                                    "нужны имя пользователя и пароль",
+	"ssid required":                                                                     "нужен -ssid",
+	"key required":                                                                      "нужен -key",
+	"holder and iban required":                                                          "нужны -holder и -iban",
+	"text required":                                                                     "нужен текст",
+	"name, number, exp, cvc required":                                                   "нужны name, number, exp и cvc",
+	"invalid card fields":                                                               "неверные поля карты",
+	"file required":                                                                     "нужен файл",
+	"invalid otp params":                                                                "неверные параметры OTP",
+	"-by must be url, password or both":                                                 "-by должен быть url, password или both",
+	"-interval must be at least 1s":                                                     "-interval должен быть не меньше 1s",
+
+	"usage: history diff -id <uuid> -from <ver> [-to <ver>] [-show-secrets]": "использование: history diff -id <uuid> -from <ver> [-to <ver>] [-show-secrets]",
+	"usage: recovery keygen|wrap|restore ...":                                "использование: recovery keygen|wrap|restore ...",
+	"usage: search [-type login] <text>":                                     "использование: search [-type login] <текст>",
+	"usage: template save|apply|list ...":                                    "использование: template save|apply|list ...",
+	"unknown import format %q\n":                                             "неизвестный формат импорта %q\n",
+	"unknown recovery subcommand %q\n":                                       "неизвестная подкоманда recovery %q\n",
+	"unknown template subcommand %q\n":                                       "неизвестная подкоманда template %q\n",
+
+	// admin, audit, clipboard
+	"diagnostics enabled=%v\n":                           "диагностика включена=%v\n",
+	"no passwords older than %s\n":                       "нет паролей старше %s\n",
+	"%d passwords older than %s:\n":                      "%d пароль(ей) старше %s:\n",
+	"rotation reminder skipped: %v\n":                    "напоминание о смене паролей пропущено: %v\n",
+	"%d credentials overdue for rotation:\n":             "просрочена смена паролей: %d\n",
+	"%d Wi-Fi networks with weak security:\n":            "сетей Wi-Fi со слабой защитой: %d\n",
+	"open network, no key":                               "открытая сеть без ключа",
+	"WEP can be cracked in minutes":                      "WEP взламывается за минуты",
+	"key shorter than 12 characters":                     "ключ короче 12 символов",
+	"key is only digits":                                 "ключ только из цифр",
+	"key contains the network name":                      "ключ содержит имя сети",
+	"%d licenses or API keys past their date:\n":         "лицензий и API-ключей с истёкшим сроком: %d\n",
+	"expired on %s":                                      "истекла %s",
+	"expires on %s":                                      "истекает %s",
+	"rotation overdue since %s":                          "смена просрочена с %s",
+	"rotate by %s":                                       "сменить до %s",
+	"nothing expires or is due for rotation within %s\n": "в ближайшие %s ничего не истекает и не требует смены\n",
+	"%s copied to clipboard\n":                           "%s скопировано в буфер обмена\n",
+	"password copied to clipboard":                       "пароль скопирован в буфер обмена",
+
+	// bulk-add, import
+	"nothing to add":                             "нечего добавлять",
+	"uploaded %d/%d\n":                           "загружено %d/%d\n",
+	"%d/%d items stored\n":                       "сохранено записей: %d/%d\n",
+	"nothing to import":                          "нечего импортировать",
+	"nothing to import (%d duplicates)\n":        "нечего импортировать (дубликатов: %d)\n",
+	"would create %d, skip %d duplicates\n":      "будет создано %d, пропущено дубликатов %d\n",
+	"imported %d items, skipped %d duplicates\n": "импортировано записей: %d, пропущено дубликатов: %d\n",
+
+	// export
+	"exported %d items\n":                            "экспортировано записей: %d\n",
+	"skipped %d items that are not typed payloads\n": "пропущено записей не типизированного формата: %d\n",
+
+	// calibrate
+	"calibrate: need -target > 0, -max-memory 1..4096, -threads 1..255":          "calibrate: нужны -target > 0, -max-memory 1..4096, -threads 1..255",
+	"calibrate -apply: need -u and -p":                                           "calibrate -apply: нужны -u и -p",
+	"recommended: %s (%s measured, target %s)\n":                                 "рекомендуется: %s (измерено %s, цель %s)\n",
+	"warning: weaker than the default %s; this machine is slow for the target\n": "внимание: слабее параметров по умолчанию %s; машина слишком медленная для этой цели\n",
+	"applied: DEK re-wrapped (was %s)\n":                                         "применено: DEK перешифрован (было %s)\n",
+	"run with -apply -u USER -p PASS to use these parameters":                    "запустите с -apply -u USER -p PASS, чтобы применить эти параметры",
+
+	// dedupe
+	"group %d (same %s):\n": "группа %d (совпадает %s):\n",
+	"%d duplicate groups\n": "групп дубликатов: %d\n",
+	"no duplicates found":   "дубликатов не найдено",
+	"kept %s, removed %d\n": "оставлена %s, удалено %d\n",
+	"[s]kip, d<N> keep N and delete others, m<N> merge into N, [q]uit: ": "[s] пропустить, d<N> оставить N и удалить остальные, m<N> слить в N, [q] выйти: ",
+
+	// history, search
+	"no differences between v%d and v%d\n": "версии v%d и v%d не различаются\n",
+	"no matches":                           "совпадений нет",
+
+	// recovery
+	"wrote %s (keep offline) and %s\n": "записаны %s (храните офлайн) и %s\n",
+	"wrote %s\n":                       "записан %s\n",
+	"DEK restored":                     "DEK восстановлен",
+
+	// skew
+	"warning: local clock is %s ahead of the server; TOTP codes and expiry checks may be wrong\n": "внимание: локальные часы спешат относительно сервера на %s; коды TOTP и проверки сроков могут быть неверны\n",
+	"warning: local clock is %s behind the server; TOTP codes and expiry checks may be wrong\n":   "внимание: локальные часы отстают от сервера на %s; коды TOTP и проверки сроков могут быть неверны\n",
+
+	// stats
+	"items: %d live, %d deleted, %d bytes encrypted\n": "записи: %d активных, %d удалённых, %d байт в зашифрованном виде\n",
+	"limit: %d of %d items used\n":                     "лимит: занято %d из %d записей\n",
+	"  %-8s %d (could not decrypt)\n":                  "  %-8s %d (не удалось расшифровать)\n",
+	"largest:":                                         "самые большие:",
+	"recently modified:":                               "недавно изменённые:",
+	"server: %d items, max ver %d\n":                   "сервер: записей %d, макс. версия %d\n",
+	"local:  no cache (run gk syncd)":                  "локально: кэша нет (запустите gk syncd)",
+	"local:  %d items, max ver %d, synced %s":          "локально: записей %d, макс. версия %d, синхронизировано %s",
+	" (differs from server)":                           " (расходится с сервером)",
+
+	// sync
+	"warning: no sync position saved yet; listing the whole vault\n":               "внимание: позиция синхронизации ещё не сохранена; выводится всё хранилище\n",
+	"warning: the saved sync position is unusable (%v); listing the whole vault\n": "внимание: сохранённая позиция синхронизации непригодна (%v); выводится всё хранилище\n",
+	"warning: could not save the sync position: %v\n":                              "внимание: не удалось сохранить позицию синхронизации: %v\n",
+
+	// schema
+	"warning: %v; writing it anyway\n": "внимание: %v; запись всё равно сохраняется\n",
+	"warning: this %s was written by a newer gk (schema %d); fields this one does not know are shown as is\n": "внимание: эта запись %s сохранена более новым gk (схема %d); незнакомые поля показаны как есть\n",
+
+	// syncd
+	"%s sync failed: %v\n": "%s синхронизация не удалась: %v\n",
+	"syncd is not running": "syncd не запущен",
+	"pid %d, user %s, %d items, last sync %s (every %s, %d syncs)\n":      "pid %d, пользователь %s, записей %d, последняя синхронизация %s (каждые %s, всего %d)\n",
+	"clock skew: %s (server minus local)\n":                               "расхождение часов: %s (сервер минус локальные)\n",
+	"%d items not mirrored (sync-filter)\n":                               "записей не зеркалируется (sync-filter): %d\n",
+	"no sync filter: every item not pinned to another device is mirrored": "фильтра синхронизации нет: зеркалируются все записи, не закреплённые за другими устройствами",
+	"this device: %s":                         "это устройство: %s",
+	"last error: %s\n":                        "последняя ошибка: %s\n",
+	"syncd: polling %s every %s, socket %s\n": "syncd: опрос %s каждые %s, сокет %s\n",
+
+	// typed export
+	"wrote %dB to %s\n": "записано %d Б в %s\n",
+
+	// verify
+	"no local cache for this user (run gk syncd); verifying a fresh download": "нет локального кэша для этого пользователя (запустите gk syncd); проверяется свежая загрузка",
+	"local:  %s (%d items, from %s)\n":                                        "локально: %s (записей %d, источник %s)\n",
+	"server: %s\n":                                                            "сервер: %s\n",
+	"OK: vault matches the server":                                            "OK: хранилище совпадает с сервером",
+	"MISMATCH: %d item(s) differ\n":                                           "РАСХОЖДЕНИЕ: различаются записи: %d\n",
+	"  %s  content differs at ver %d (possible tampering)\n":                  "  %s  содержимое версии %d различается (возможна подмена)\n",
+	"  %s  local ver %d, server ver %d\n":                                     "  %s  локальная версия %d, на сервере %d\n",
+	"  %s  only on server (ver %d)\n":                                         "  %s  только на сервере (версия %d)\n",
+	"  %s  only in local copy (ver %d)\n":                                     "  %s  только в локальной копии (версия %d)\n",
+
+	// three-way merge
+	"conflict in %s:\n":                  "конфликт в %s:\n",
+	"local":                              "локальная версия",
+	"server v%d\n":                       "сервер v%d\n",
+	"keep [l]ocal, [s]erver or [b]oth: ": "оставить [l] локальное, [s] серверное или [b] оба: ",
+	"keep [l]ocal or [s]erver: ":         "оставить [l] локальное или [s] серверное: ",
+	"no conflict choice":                 "конфликт не разрешён",
+	"merged with server v%d\n":           "объединено с версией сервера v%d\n",
+
+	// add -i wizard
+	"Type: ":                         "Тип: ",
+	"%s (optional): ":                "%s (необязательно): ",
+	"%s (again): ":                   "%s (ещё раз): ",
+	"  required":                     "  обязательное поле",
+	"  entries differ":               "  значения не совпадают",
+	"  pick a number or a type name": "  укажите номер или название типа",
+	"input ended":                    "ввод закончился",
+	"no item type chosen":            "тип записи не выбран",
+	"no valid %s after %d tries":     "нет корректного значения «%s» после %d попыток",
+	"Title":                          "Заголовок",
+	"URL":                            "URL",
+	"Username":                       "Имя пользователя",
+	"Password":                       "Пароль",
+	"Note":                           "Заметка",
+	"Cardholder":                     "Владелец карты",
+	"Card number":                    "Номер карты",
+	"Expiry (MM/YY)":                 "Срок действия (ММ/ГГ)",
+	"CVC":                            "CVC",
+	"Text":                           "Текст",
+	"Issuer":                         "Издатель",
+	"Base32 secret":                  "Секрет в Base32",
+	"Network name (SSID)":            "Имя сети (SSID)",
+	"Security (WPA/WPA3/WEP/nopass)": "Защита (WPA/WPA3/WEP/nopass)",
+	"Key":                            "Ключ",
+	"WPA, WPA3, WEP or nopass":       "WPA, WPA3, WEP или nopass",
+	"Product":                        "Продукт",
+	"License key":                    "Лицензионный ключ",
+	"Seats":                          "Мест",
+	"Purchase date (YYYY-MM-DD)":     "Дата покупки (ГГГГ-ММ-ДД)",
+	"Expiry date (YYYY-MM-DD)":       "Дата окончания (ГГГГ-ММ-ДД)",
+	"Environment":                    "Окружение",
+	"API key":                        "API-ключ",
+	"Rotate by (YYYY-MM-DD)":         "Сменить до (ГГГГ-ММ-ДД)",
+	"use YYYY-MM-DD":                 "формат ГГГГ-ММ-ДД",
+	"a positive number":              "положительное число",
+	"Bank":                           "Банк",
+	"Account holder":                 "Владелец счёта",
+	"IBAN":                           "IBAN",
+	"BIC":                            "BIC",
+	"not a valid IBAN":               "неверный IBAN",
+	"8 or 11 letters and digits, e.g. DEUTDEFF": "8 или 11 букв и цифр, например DEUTDEFF",
+	"Digits":                         "Число цифр",
+	"Period (seconds)":               "Период (секунды)",
+	"Algorithm (SHA1/SHA256/SHA512)": "Алгоритм (SHA1/SHA256/SHA512)",
+	"File path":                      "Путь к файлу",
+	"not a valid card number":        "неверный номер карты",
+	"use MM/YY":                      "формат ММ/ГГ",
+	"3 or 4 digits":                  "3 или 4 цифры",
+	"not base32":                     "не base32",
+	"6 or 8":                         "6 или 8",
+	"a positive number of seconds":   "положительное число секунд",
+	"SHA1, SHA256 or SHA512":         "SHA1, SHA256 или SHA512",
+	"is a directory":                 "это каталог",
+
+	// rm.go
+	"%s: deleted, ver %d\n":                  "%s: удалена, версия %d\n",
+	"%s: version conflict, current ver %d\n": "%s: конфликт версий, текущая версия %d\n",
+	"%s: not found\n":                        "%s: не найдена\n",
+	"%s: kept, the batch was rolled back\n":  "%s: не удалена, пакет откатан\n",
+
+	// login lockouts
+	"warning: %d failed login attempts since your last login\n": "внимание: неудачных попыток входа с прошлого входа: %d\n",
+	"-clear needs -user":   "для -clear нужен -user",
+	"-ip-hash is not hex":  "-ip-hash: нужна hex-строка",
+	"cleared %d lock(s)\n": "снято блокировок: %d\n",
+
+	// admin-suspend, admin-reactivate
+	"suspended %s\n":   "учётная запись %s приостановлена\n",
+	"reactivated %s\n": "учётная запись %s снова активна\n",
+	"the account is suspended; ask the server's administrator to reactivate it": "учётная запись приостановлена; попросите администратора сервера её восстановить",
+
+	// admin-ip
+	"-user is required":                 "нужен -user",
+	"give one of -allow, -deny and -rm": "укажите только одно из -allow, -deny и -rm",
+	"removed\n":                         "удалено\n",
+
+	// login anomalies
+	"warning: unusual login (%s); if it was not you, change your password\n": "внимание: необычный вход (%s); если это были не вы, смените пароль\n",
+	"the first login sets up the vault key; log in without -scope":           "первый вход создаёт ключ хранилища; войдите без -scope",
+
+	// challenges
+	"the server wants a %s CAPTCHA solved (site key %s); pass its response token with -captcha\n": "сервер требует решить CAPTCHA %s (ключ сайта %s); передайте её токен ответа в -captcha\n",
+
+	// sessions
+	"session %s signed out\n": "сеанс %s завершён\n",
+
+	// server keys
+	"first connection to %s: trusting its key %s from now on; check it against the server's\n":                                                                                                                      "первое подключение к %s: его ключ %s теперь доверенный; сверьте его с ключом сервера\n",
+	"the certificate of %s expires on %s; if its key changes then, add the new key with gk server keys -add\n":                                                                                                      "сертификат %s истекает %s; если ключ сменится, добавьте новый через gk server keys -add\n",
+	"WARNING: THE KEY OF %s HAS CHANGED.\nIt presented %s, which is not pinned. Someone may be intercepting the connection.\nIf the key was changed on purpose, check the new one and run gk server keys -add %s\n": "ВНИМАНИЕ: КЛЮЧ %s ИЗМЕНИЛСЯ.\nПредъявлен %s, он не закреплён. Возможно, соединение перехватывают.\nЕсли ключ сменили намеренно, проверьте новый и выполните gk server keys -add %s\n",
+
+	// duress
+	"usage: duress setup|remove ...":                                                                "использование: duress setup|remove ...",
+	"unknown duress subcommand %q\n":                                                                "неизвестная подкоманда duress %q\n",
+	"duress password removed, with its vault":                                                       "пароль под принуждением удалён вместе с его хранилищем",
+	"duress password set; %d items copied into its vault":                                           "пароль под принуждением задан; в его хранилище скопировано записей: %d",
+	"the duress password was set but its vault is incomplete; run gk duress remove and try again\n": "пароль под принуждением задан, но его хранилище не готово; выполните gk duress remove и повторите\n",
+
+	// token renewal
+	"could not renew the access token: %v\n": "не удалось обновить токен доступа: %v\n",
+	"your session has ended; log in again\n": "ваш сеанс завершён; войдите снова\n",
+	"could not save the renewed token: %v\n": "не удалось сохранить обновлённый токен: %v\n",
+
+	// profile
+	"usage: profile encrypt|decrypt|status":           "использование: profile encrypt|decrypt|status",
+	"profile encrypted; its key is in the keychain\n": "профиль зашифрован; его ключ в связке ключей\n",
+	"profile decrypted\n":                             "профиль расшифрован\n",
+
+	// non-interactive mode
+	"%s needs answers on the terminal; -non-interactive is set": "%s требует ответов в терминале, а задан -non-interactive",
+
+	// dry runs
+	"dry run: nothing was sent\n": "пробный запуск: ничего не отправлено\n",
+
+	// server warnings
+	"server warning: %s\n":             "предупреждение сервера: %s\n",
+	"server warning for item %s: %s\n": "предупреждение сервера о записи %s: %s\n",
+
+	// signup
+	"account %s was created; run gk login to finish setting it up\n": "учётная запись %s создана; выполните gk login, чтобы завершить настройку\n",
+}
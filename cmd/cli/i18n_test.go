@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// trKeys collects the literal messages passed to tr in the package sources,
+// plus the messages translated indirectly (wizard labels, sentinels, help).
+func trKeys(t *testing.T) map[string]bool {
+	t.Helper()
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := map[string]bool{usageHead: true, usageTail: true}
+	fset := token.NewFileSet()
+	for _, fn := range files {
+		if strings.HasSuffix(fn, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, fn, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) == 0 {
+				return true
+			}
+			if id, ok := call.Fun.(*ast.Ident); !ok || id.Name != "tr" {
+				return true
+			}
+			switch a := call.Args[0].(type) {
+			case *ast.BasicLit:
+				s, err := strconv.Unquote(a.Value)
+				if err != nil {
+					t.Fatal(err)
+				}
+				keys[s] = true
+			case *ast.SelectorExpr, *ast.CallExpr: // wizard labels, sentinels and summaries, below
+			default:
+				if id, ok := a.(*ast.Ident); ok && (id.Name == "usageHead" || id.Name == "usageTail") {
+					break
+				}
+				t.Errorf("%s: tr needs a literal message", fset.Position(call.Pos()))
+			}
+			return true
+		})
+	}
+	for _, wt := range wizardTypes {
+		for _, f := range wt.Fields {
+			keys[f.Label] = true
+		}
+	}
+	commandTree().walk(func(c *command) {
+		if c.summary != "" {
+			keys[c.summary] = true
+		}
+	})
+	for _, s := range []error{errNoToken, errNoDEK, errCrypto, errItemDeleted, errItemNotFound, errInvalidInput, errMergeConflict, errBatchRejected, errStale, errNoServerInfo} {
+		keys[s.Error()] = true
+	}
+	return keys
+}
+
+var verbRe = regexp.MustCompile(`%[-+# 0-9.*]*[a-zA-Z%]`)
+
+func Test_catalogsComplete(t *testing.T) {
+	keys := trKeys(t)
+	for l, cat := range catalogs {
+		for k := range keys {
+			v, ok := cat[k]
+			if !ok {
+				t.Errorf("%s: missing %q", l, k)
+				continue
+			}
+			if got, want := verbRe.FindAllString(v, -1), verbRe.FindAllString(k, -1); !slices.Equal(got, want) {
+				t.Errorf("%s: %q has verbs %v, want %v", l, k, got, want)
+			}
+		}
+		for k := range cat {
+			if !keys[k] {
+				t.Errorf("%s: unused message %q", l, k)
+			}
+		}
+	}
+}
+
+func Test_detectLang(t *testing.T) {
+	cases := []struct {
+		env  map[string]string
+		want string
+	}{
+		{nil, "en"},
+		{map[string]string{"LANG": "ru_RU.UTF-8"}, "ru"},
+		{map[string]string{"LANG": "C"}, "en"},
+		{map[string]string{"LANG": "de_DE.UTF-8"}, "en"},
+		{map[string]string{"LANG": "ru_RU.UTF-8", "LC_MESSAGES": "en_US.UTF-8"}, "en"},
+		{map[string]string{"LANG": "en_US.UTF-8", "LC_ALL": "ru_UA"}, "ru"},
+	}
+	for _, c := range cases {
+		if got := detectLang(func(k string) string { return c.env[k] }); got != c.want {
+			t.Errorf("%v: got %s want %s", c.env, got, c.want)
+		}
+	}
+}
+
+func Test_setLang(t *testing.T) {
+	defer func(l string) { lang = l }(lang)
+	if err := setLang("ru"); err != nil || lang != "ru" {
+		t.Fatalf("ru: %v %s", err, lang)
+	}
+	if err := setLang("en_GB"); err != nil || lang != "en" {
+		t.Fatalf("en_GB: %v %s", err, lang)
+	}
+	if err := setLang("fr"); err == nil || lang != "en" {
+		t.Fatalf("fr accepted: %s", lang)
+	}
+}
+
+func Test_tr(t *testing.T) {
+	defer func(l string) { lang = l }(lang)
+	lang = "ru"
+	if got := tr("uploaded %d/%d\n", 1, 2); got != "загружено 1/2\n" {
+		t.Fatalf("ru: %q", got)
+	}
+	if got := tr("not in any catalog %d", 7); got != "not in any catalog 7" {
+		t.Fatalf("fallback: %q", got)
+	}
+	err := fmt.Errorf("unwrap DEK: %w: %w", errCrypto, fmt.Errorf("message authentication failed"))
+	if got := localizeErr(err); got != "unwrap DEK: ошибка криптографии: message authentication failed" {
+		t.Fatalf("localizeErr: %q", got)
+	}
+	lang = "en"
+	if got := localizeErr(err); got != err.Error() {
+		t.Fatalf("en localizeErr: %q", got)
+	}
+}
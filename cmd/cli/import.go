@@ -0,0 +1,246 @@
+// cmd/cli/import.go
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// importEntry is a login record read from an external password manager.
+type importEntry struct {
+	Title    string
+	Folder   string
+	URL      string
+	Username string
+	Password string
+	Note     string
+}
+
+// parsePassEntry parses pass(1) plaintext: first line is the password,
+// "login:"/"url:" lines (or a bare http(s) URL) fill the login fields, the rest becomes the note.
+func parsePassEntry(plain string) importEntry {
+	var (
+		e     importEntry
+		notes []string
+	)
+	lines := strings.Split(strings.ReplaceAll(plain, "\r\n", "\n"), "\n")
+	e.Password = lines[0]
+	for _, line := range lines[1:] {
+		key, val, _ := strings.Cut(line, ":")
+		key, val = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(val)
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case e.Username == "" && (key == "login" || key == "user" || key == "username" || key == "email"):
+			e.Username = val
+		case e.URL == "" && (key == "url" || key == "website" || key == "site"):
+			e.URL = val
+		case e.URL == "" && (strings.HasPrefix(trimmed, "https://") || strings.HasPrefix(trimmed, "http://")):
+			e.URL = trimmed
+		default:
+			notes = append(notes, line)
+		}
+	}
+	e.Note = strings.TrimSpace(strings.Join(notes, "\n"))
+	return e
+}
+
+// gpgDecrypt decrypts a pass entry with the local gpg agent. With
+// -non-interactive the agent may not ask for a passphrase either.
+func gpgDecrypt(gpg string) func(path string) ([]byte, error) {
+	args := []string{"--quiet", "--batch"}
+	if nonInteractive {
+		args = append(args, "--pinentry-mode", "error")
+	}
+	return func(path string) ([]byte, error) {
+		out, err := exec.Command(gpg, slices.Concat(args, []string{"--decrypt", path})...).Output()
+		if err != nil {
+			var ee *exec.ExitError
+			if errors.As(err, &ee) {
+				return nil, fmt.Errorf("gpg %s: %s", path, strings.TrimSpace(string(ee.Stderr)))
+			}
+			return nil, err
+		}
+		return out, nil
+	}
+}
+
+// readPassStore walks a password-store tree and decrypts every *.gpg entry.
+// Subdirectories become the entry folder, the file name becomes the title.
+func readPassStore(dir string, decrypt func(path string) ([]byte, error)) ([]importEntry, error) {
+	var out []importEntry
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != dir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir // .git, .extensions
+			}
+			return nil
+		}
+		if filepath.Ext(p) != ".gpg" {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		plain, err := decrypt(p)
+		if err != nil {
+			return err
+		}
+		e := parsePassEntry(string(plain))
+		e.Title = strings.TrimSuffix(filepath.Base(rel), ".gpg")
+		if f := filepath.Dir(rel); f != "." {
+			e.Folder = filepath.ToSlash(f)
+		}
+		out = append(out, e)
+		return nil
+	})
+	return out, err
+}
+
+// loginPayload builds the typed login payload for an imported entry; the
+// password counts as changed at now.
+func (e importEntry) loginPayload(now time.Time) ([]byte, error) {
+	meta := map[string]any{"title": e.Title, "url": e.URL, "username": e.Username, "note": e.Note}
+	if e.Folder != "" {
+		meta["folder"] = e.Folder
+	}
+	stampPasswordChange(meta, e.Password, nil, now)
+	return buildTypedPayload("login", meta, map[string]any{"password": e.Password})
+}
+
+// encryptEntries turns import entries into new encrypted items (base_ver=0).
+func encryptEntries(entries []importEntry, userID string) ([]pendingUpsert, error) {
+	out := make([]pendingUpsert, 0, len(entries))
+	now := time.Now()
+	for _, e := range entries {
+		var id string
+		autoUUID(&id)
+		pt, err := e.loginPayload(now)
+		if err != nil {
+			return nil, err
+		}
+		blob, err := encryptForItem(id, userID, 1, pt)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, pendingUpsert{ID: id, BaseVer: 0, Blob: blob})
+	}
+	return out, nil
+}
+
+// cmdImport imports logins from another password manager.
+func cmdImport(args []string, addr, caPath string, insecure bool) {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	format := fs.String("format", "pass", "source format: pass | csv (chrome, firefox, edge are aliases)")
+	dir := fs.String("dir", "", "password-store directory (default ~/.password-store)")
+	gpg := fs.String("gpg", "gpg", "gpg binary")
+	file := fs.String("file", "", "CSV export file (- for stdin)")
+	dryRun := fs.Bool("dry-run", false, "encrypt and show what would be created, send nothing")
+	parseFlags(fs, args)
+
+	var (
+		entries []importEntry
+		err     error
+	)
+	switch *format {
+	case "pass":
+		if *dir == "" {
+			if v := os.Getenv("PASSWORD_STORE_DIR"); v != "" {
+				*dir = v
+			} else {
+				home, _ := os.UserHomeDir()
+				*dir = filepath.Join(home, ".password-store")
+			}
+		}
+		entries, err = readPassStore(*dir, gpgDecrypt(*gpg))
+	case "csv", "chrome", "firefox", "edge":
+		if *file == "" {
+			fmt.Fprintln(os.Stderr, tr("need -file"))
+			os.Exit(exitUsage)
+		}
+		entries, err = readCSVFile(*file)
+	default:
+		fmt.Fprintf(os.Stderr, tr("unknown import format %q\n"), *format)
+		os.Exit(exitUsage)
+	}
+	if err != nil {
+		fail(err)
+	}
+	if len(entries) == 0 {
+		fmt.Println(tr("nothing to import"))
+		return
+	}
+
+	token, err := loadToken()
+	if err != nil {
+		fail(err)
+	}
+	uid, err := loadUserID()
+	if err != nil {
+		fail(err)
+	}
+
+	ctx, cancel := withTimeout()
+	defer cancel()
+	conn, cli, err := dial(ctx, addr, caPath, insecure, token)
+	if err != nil {
+		fail(err)
+	}
+	defer conn.Close()
+
+	existing, err := listTyped(ctx, cli)
+	if err != nil {
+		fail(err)
+	}
+	create, plan := planImport(entries, existing)
+	items, err := encryptEntries(create, uid)
+	if err != nil {
+		fail(err)
+	}
+	if *dryRun {
+		planned(plan, plannedUpserts(items))
+		emit(plan, func() {
+			for _, r := range plan {
+				fmt.Printf("%-15s %s\t%s\t%s\t%s\n", r.Action, r.Title, r.URL, r.Username, r.ID)
+			}
+			fmt.Printf(tr("would create %d, skip %d duplicates\n"), len(create), len(entries)-len(create))
+		})
+		fmt.Fprint(os.Stderr, colors(os.Stderr).warn(tr("dry run: nothing was sent\n")))
+		return
+	}
+	if len(create) == 0 {
+		fmt.Printf(tr("nothing to import (%d duplicates)\n"), len(entries))
+		return
+	}
+	res, err := sendUpserts(ctx, cli, items, func(done, total int) {
+		fmt.Fprintf(os.Stderr, tr("uploaded %d/%d\n"), done, total)
+	})
+	if err != nil {
+		fail(fmt.Errorf("import stopped after %d items: %w", len(res), err))
+	}
+	fmt.Print(colors(os.Stdout).ok(tr("imported %d items, skipped %d duplicates\n", len(res), len(entries)-len(create))))
+}
+
+// readCSVFile reads a browser CSV export from path or stdin ("-").
+func readCSVFile(path string) ([]importEntry, error) {
+	if path == "-" {
+		return readBrowserCSV(os.Stdin)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readBrowserCSV(f)
+}
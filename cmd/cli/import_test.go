@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	cc "github.com/and161185/goph-keeper/internal/crypto/clientcrypto"
+)
+
+func Test_parsePassEntry(t *testing.T) {
+	t.Parallel()
+
+	e := parsePassEntry("hunter2\nlogin: alice\nurl: https://example.com\nrecovery codes below\n1234\n")
+	if e.Password != "hunter2" || e.Username != "alice" || e.URL != "https://example.com" {
+		t.Fatalf("fields: %+v", e)
+	}
+	if e.Note != "recovery codes below\n1234" {
+		t.Fatalf("note: %q", e.Note)
+	}
+
+	e = parsePassEntry("pw\r\nhttps://bare.example.org/login\r\nUser: bob")
+	if e.URL != "https://bare.example.org/login" || e.Username != "bob" || e.Note != "" {
+		t.Fatalf("bare url / crlf: %+v", e)
+	}
+
+	if e := parsePassEntry("only-password"); e.Password != "only-password" || e.Note != "" {
+		t.Fatalf("password only: %+v", e)
+	}
+}
+
+func Test_readPassStore_FoldersAndSkips(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	write := func(rel, content string) {
+		p := filepath.Join(dir, rel)
+		_ = os.MkdirAll(filepath.Dir(p), 0o700)
+		if err := os.WriteFile(p, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("github.com.gpg", "pw1\nlogin: me")
+	write("work/jira.gpg", "pw2")
+	write("work/infra/db.gpg", "pw3")
+	write(".gpg-id", "KEYID")
+	write(".git/objects/x.gpg", "ignored")
+
+	// "decrypt" is identity: test files hold plaintext.
+	entries, err := readPassStore(dir, os.ReadFile)
+	if err != nil {
+		t.Fatalf("readPassStore: %v", err)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Password < entries[j].Password })
+	if len(entries) != 3 {
+		t.Fatalf("want 3 entries, got %+v", entries)
+	}
+	if entries[0].Title != "github.com" || entries[0].Folder != "" || entries[0].Username != "me" {
+		t.Fatalf("root entry: %+v", entries[0])
+	}
+	if entries[1].Title != "jira" || entries[1].Folder != "work" {
+		t.Fatalf("folder entry: %+v", entries[1])
+	}
+	if entries[2].Folder != "work/infra" {
+		t.Fatalf("nested folder: %+v", entries[2])
+	}
+
+	boom := errors.New("gpg failed")
+	if _, err := readPassStore(dir, func(string) ([]byte, error) { return nil, boom }); !errors.Is(err, boom) {
+		t.Fatalf("want decrypt error, got %v", err)
+	}
+}
+
+func Test_encryptEntries_Decryptable(t *testing.T) {
+	_ = withTmpConfig(t)
+	dek, _ := cc.Rand(cc.DEKLen)
+	if err := saveDEK(dek); err != nil {
+		t.Fatal(err)
+	}
+	items, err := encryptEntries([]importEntry{{Title: "t", Folder: "f", Password: "p"}}, "user-1")
+	if err != nil || len(items) != 1 || items[0].BaseVer != 0 {
+		t.Fatalf("encryptEntries: %+v %v", items, err)
+	}
+	pt, err := decryptForItem(items[0].ID, "user-1", 1, items[0].Blob)
+	if err != nil {
+		t.Fatalf("decrypt: %v", err)
+	}
+	var obj typedPayload
+	_ = json.Unmarshal(pt, &obj)
+	f := payloadFields(obj)
+	if obj.Type != "login" || f["folder"] != "f" || f["password"] != "p" || f[metaPasswordChangedAt] == nil {
+		t.Fatalf("payload: %s", pt)
+	}
+}
+
+func Test_chunkUpserts(t *testing.T) {
+	t.Parallel()
+	mk := func(n int) pendingUpsert { return pendingUpsert{ID: "x", Blob: make([]byte, n)} }
+
+	got := chunkUpserts([]pendingUpsert{mk(10), mk(10), mk(10)}, 1000, 2)
+	if len(got) != 2 || len(got[0]) != 2 || len(got[1]) != 1 {
+		t.Fatalf("count cap: %d batches", len(got))
+	}
+
+	got = chunkUpserts([]pendingUpsert{mk(400), mk(400), mk(5000), mk(1)}, 1000, 100)
+	if len(got) != 3 || len(got[0]) != 2 || len(got[1]) != 1 || len(got[2]) != 1 {
+		t.Fatalf("size cap: %v", len(got))
+	}
+
+	if chunkUpserts(nil, 10, 10) != nil {
+		t.Fatalf("empty input")
+	}
+}
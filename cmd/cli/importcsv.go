@@ -0,0 +1,163 @@
+// cmd/cli/importcsv.go
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// csvColumns maps a field to the header names used by browser exports.
+// Chrome/Edge: name,url,username,password[,note]; Firefox: url,username,password,httpRealm,...
+var csvColumns = map[string][]string{
+	"title":    {"name", "title"},
+	"url":      {"url", "login_uri", "website"},
+	"username": {"username", "login_username", "login"},
+	"password": {"password", "login_password"},
+	"note":     {"note", "notes", "comment"},
+}
+
+// readBrowserCSV parses a password CSV exported by Chrome, Firefox or Edge.
+// Columns are matched by header name, so column order and extra columns do not matter.
+func readBrowserCSV(r io.Reader) ([]importEntry, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("csv header: %w", err)
+	}
+	idx := map[string]int{}
+	for i, h := range header {
+		h = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+		for field, names := range csvColumns {
+			for _, n := range names {
+				if _, seen := idx[field]; !seen && h == n {
+					idx[field] = i
+				}
+			}
+		}
+	}
+	if _, ok := idx["password"]; !ok {
+		return nil, fmt.Errorf("csv header has no password column: %v", header)
+	}
+	get := func(rec []string, field string) string {
+		i, ok := idx[field]
+		if !ok || i >= len(rec) {
+			return ""
+		}
+		return strings.TrimSpace(rec[i])
+	}
+
+	var out []importEntry
+	for line := 2; ; line++ {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("csv line %d: %w", line, err)
+		}
+		e := importEntry{
+			Title:    get(rec, "title"),
+			URL:      get(rec, "url"),
+			Username: get(rec, "username"),
+			Password: get(rec, "password"),
+			Note:     get(rec, "note"),
+		}
+		if e.Password == "" && e.URL == "" && e.Username == "" {
+			continue
+		}
+		if e.Title == "" {
+			e.Title = hostOf(e.URL)
+		}
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+// hostOf returns the host of u, or u itself when it does not parse as a URL.
+func hostOf(u string) string {
+	if p, err := url.Parse(u); err == nil && p.Host != "" {
+		return p.Hostname()
+	}
+	return u
+}
+
+// loginKey identifies a login for duplicate detection: normalized URL plus username.
+// Scheme, host case, default ports and a trailing slash are ignored.
+func loginKey(rawURL, username string) string {
+	u := strings.TrimSpace(rawURL)
+	if p, err := url.Parse(u); err == nil && p.Host != "" {
+		host := strings.ToLower(p.Hostname())
+		if port := p.Port(); port != "" && port != "80" && port != "443" {
+			host += ":" + port
+		}
+		u = host + strings.TrimSuffix(p.EscapedPath(), "/")
+	} else {
+		u = strings.ToLower(strings.TrimSuffix(u, "/"))
+	}
+	return u + "\x00" + username
+}
+
+// importPlanRow is one line of the import preview.
+type importPlanRow struct {
+	Action   string `json:"action"`
+	Title    string `json:"title"`
+	Folder   string `json:"folder,omitempty"`
+	URL      string `json:"url"`
+	Username string `json:"username"`
+	// For created items in a dry run: the new id, ciphertext size and batch.
+	ID    string `json:"id,omitempty"`
+	Bytes int    `json:"bytes,omitempty"`
+	Batch int    `json:"batch,omitempty"`
+}
+
+// planned fills the create rows of plan, in order, from the writes made for them.
+func planned(plan []importPlanRow, writes []plannedWrite) {
+	i := 0
+	for j := range plan {
+		if plan[j].Action != "create" || i == len(writes) {
+			continue
+		}
+		plan[j].ID, plan[j].Bytes, plan[j].Batch = writes[i].ID, writes[i].Bytes, writes[i].Batch
+		i++
+	}
+}
+
+// planImport drops entries whose URL+username already exist in the vault
+// (or earlier in the same import). Entries without a URL are never treated as duplicates.
+func planImport(entries []importEntry, existing []vaultItem) ([]importEntry, []importPlanRow) {
+	seen := map[string]bool{}
+	for _, it := range existing {
+		lf, err := parseLogin(it.Payload)
+		if err != nil || lf.URL == "" {
+			continue
+		}
+		seen[loginKey(lf.URL, lf.Username)] = true
+	}
+	var (
+		create []importEntry
+		rows   []importPlanRow
+	)
+	for _, e := range entries {
+		row := importPlanRow{Action: "create", Title: e.Title, Folder: e.Folder, URL: e.URL, Username: e.Username}
+		if e.URL != "" {
+			k := loginKey(e.URL, e.Username)
+			if seen[k] {
+				row.Action = "skip-duplicate"
+				rows = append(rows, row)
+				continue
+			}
+			seen[k] = true
+		}
+		create = append(create, e)
+		rows = append(rows, row)
+	}
+	return create, rows
+}
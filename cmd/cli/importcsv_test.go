@@ -0,0 +1,73 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_readBrowserCSV_Formats(t *testing.T) {
+	t.Parallel()
+
+	chrome := "\ufeffname,url,username,password,note\n" +
+		"GitHub,https://github.com/login,me,p1,\"multi\nline\"\n" +
+		",https://example.com/,bob,p2,\n"
+	got, err := readBrowserCSV(strings.NewReader(chrome))
+	if err != nil || len(got) != 2 {
+		t.Fatalf("chrome: %+v %v", got, err)
+	}
+	if got[0].Title != "GitHub" || got[0].Note != "multi\nline" || got[0].Password != "p1" {
+		t.Fatalf("chrome row: %+v", got[0])
+	}
+	if got[1].Title != "example.com" {
+		t.Fatalf("title from host: %+v", got[1])
+	}
+
+	firefox := `"url","username","password","httpRealm","formActionOrigin","guid","timeCreated","timeLastUsed","timePasswordChanged"
+"https://accounts.example.org","alice","s3cret",,"https://accounts.example.org","{abc}","1","2","3"
+`
+	got, err = readBrowserCSV(strings.NewReader(firefox))
+	if err != nil || len(got) != 1 || got[0].Username != "alice" || got[0].Title != "accounts.example.org" {
+		t.Fatalf("firefox: %+v %v", got, err)
+	}
+
+	if _, err := readBrowserCSV(strings.NewReader("a,b\n1,2\n")); err == nil {
+		t.Fatalf("want error for missing password column")
+	}
+}
+
+func Test_planImport_Duplicates(t *testing.T) {
+	t.Parallel()
+
+	existing := []vaultItem{{
+		ID: "1",
+		Payload: typedPayload{
+			Type: "login",
+			Meta: []byte(`{"title":"GH","url":"https://GitHub.com/login/","username":"me"}`),
+			Data: []byte(`{"password":"x"}`),
+		},
+	}, {
+		ID:      "2",
+		Payload: typedPayload{Type: "text", Meta: []byte(`{}`), Data: []byte(`{"text":"t"}`)},
+	}}
+	entries := []importEntry{
+		{Title: "a", URL: "http://github.com/login", Username: "me"},     // existing
+		{Title: "b", URL: "https://github.com/login", Username: "other"}, // different user
+		{Title: "c", URL: "https://new.example", Username: "u"},
+		{Title: "d", URL: "https://new.example/", Username: "u"}, // dup within import
+		{Title: "e"}, {Title: "f"}, // no url: always created
+	}
+	create, rows := planImport(entries, existing)
+	if len(rows) != len(entries) {
+		t.Fatalf("rows: %d", len(rows))
+	}
+	var titles []string
+	for _, e := range create {
+		titles = append(titles, e.Title)
+	}
+	if strings.Join(titles, ",") != "b,c,e,f" {
+		t.Fatalf("create: %v", titles)
+	}
+	if rows[0].Action != "skip-duplicate" || rows[3].Action != "skip-duplicate" || rows[1].Action != "create" {
+		t.Fatalf("actions: %+v", rows)
+	}
+}
@@ -0,0 +1,87 @@
+// cmd/cli/itemstream.go
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+)
+
+// maxPrealloc caps the buffer reserved from a stream's announced size; a
+// larger blob still arrives, the buffer just grows as it does.
+const maxPrealloc = 64 << 20
+
+// getItem fetches an item with GetItem and, when the reply exceeds the
+// client's message limit, again with GetItemStream. Big binaries stored
+// before the limit was hit stay retrievable that way.
+func getItem(ctx context.Context, cli pb.GophKeeperClient, req *pb.GetItemRequest) (*pb.GetItemResponse, error) {
+	out, err := cli.GetItem(ctx, req)
+	if !tooLarge(err) {
+		return out, err
+	}
+	return getItemStream(ctx, cli, req)
+}
+
+// tooLarge reports whether err is grpc's own "message larger than max"
+// refusal. Server-side ResourceExhausted errors (quotas, rate limits) carry
+// an ErrorInfo reason and do not count.
+func tooLarge(err error) bool {
+	st, ok := status.FromError(err)
+	return ok && st.Code() == codes.ResourceExhausted && errorInfo(st) == nil
+}
+
+// getItemStream reassembles a streamed item into the GetItem response it
+// stands for. The blob is one AEAD message, so nothing can be decrypted
+// before the last chunk is in; the chunks are appended in place instead of
+// being held twice.
+func getItemStream(ctx context.Context, cli pb.GophKeeperClient, req *pb.GetItemRequest) (*pb.GetItemResponse, error) {
+	stream, err := cli.GetItemStream(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	first, err := stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	total := first.GetTotalSize()
+	if total < 0 {
+		return nil, fmt.Errorf("item stream: bad size %d", total)
+	}
+	blob := make([]byte, 0, min(total, maxPrealloc))
+	blob = append(blob, first.GetData()...)
+	for int64(len(blob)) < total {
+		c, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("item stream: truncated at %d of %d bytes", len(blob), total)
+		}
+		if err != nil {
+			return nil, err
+		}
+		blob = append(blob, c.GetData()...)
+	}
+	if int64(len(blob)) != total {
+		return nil, fmt.Errorf("item stream: %d bytes, announced %d", len(blob), total)
+	}
+	if _, err := stream.Recv(); !errors.Is(err, io.EOF) {
+		if err == nil {
+			err = errors.New("item stream: data past the announced size")
+		}
+		return nil, err
+	}
+
+	out := &pb.GetItemResponse{}
+	out.SetId(first.GetId())
+	out.SetVer(first.GetVer())
+	out.SetDeleted(first.GetDeleted())
+	out.SetUpdatedAt(first.GetUpdatedAt())
+	eb := &pb.EncryptedBlob{}
+	eb.SetCiphertext(blob)
+	out.SetBlobEnc(eb)
+	return out, nil
+}
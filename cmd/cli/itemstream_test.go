@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/internal/errs"
+)
+
+// chunkStream replays chunks, then io.EOF.
+type chunkStream struct {
+	grpc.ClientStream
+	chunks []*pb.GetItemChunk
+}
+
+func (s *chunkStream) Recv() (*pb.GetItemChunk, error) {
+	if len(s.chunks) == 0 {
+		return nil, io.EOF
+	}
+	c := s.chunks[0]
+	s.chunks = s.chunks[1:]
+	return c, nil
+}
+
+// bigItemServer fails GetItem with getErr and streams chunks.
+type bigItemServer struct {
+	pb.GophKeeperClient
+	getErr   error
+	chunks   []*pb.GetItemChunk
+	streamed bool
+}
+
+func (f *bigItemServer) GetItem(context.Context, *pb.GetItemRequest, ...grpc.CallOption) (*pb.GetItemResponse, error) {
+	return nil, f.getErr
+}
+
+func (f *bigItemServer) GetItemStream(context.Context, *pb.GetItemRequest, ...grpc.CallOption) (grpc.ServerStreamingClient[pb.GetItemChunk], error) {
+	f.streamed = true
+	return &chunkStream{chunks: f.chunks}, nil
+}
+
+func chunksOf(blob []byte, size int) []*pb.GetItemChunk {
+	var out []*pb.GetItemChunk
+	for len(blob) > 0 || out == nil {
+		n := min(len(blob), size)
+		c := &pb.GetItemChunk{}
+		if out == nil {
+			c.SetId("it-1")
+			c.SetVer(3)
+			c.SetTotalSize(int64(len(blob)))
+		}
+		c.SetData(blob[:n])
+		out = append(out, c)
+		blob = blob[n:]
+	}
+	return out
+}
+
+func Test_getItem_FallsBackToStream(t *testing.T) {
+	blob := bytes.Repeat([]byte("ciphertext"), 1000)
+	f := &bigItemServer{
+		getErr: status.Error(codes.ResourceExhausted, "grpc: received message larger than max (5000000 vs. 4194304)"),
+		chunks: chunksOf(blob, 4096),
+	}
+	out, err := getItem(context.Background(), f, &pb.GetItemRequest{})
+	if err != nil || !f.streamed {
+		t.Fatalf("getItem: %v, streamed %v", err, f.streamed)
+	}
+	if out.GetId() != "it-1" || out.GetVer() != 3 || !bytes.Equal(out.GetBlobEnc().GetCiphertext(), blob) {
+		t.Fatalf("reassembled %s v%d, %d bytes", out.GetId(), out.GetVer(), len(out.GetBlobEnc().GetCiphertext()))
+	}
+
+	// a truncated stream is an error, not a short blob
+	f.chunks = chunksOf(blob, 4096)[:2]
+	if _, err := getItem(context.Background(), f, &pb.GetItemRequest{}); err == nil {
+		t.Fatal("truncated stream accepted")
+	}
+}
+
+func Test_getItem_QuotaIsNotTooLarge(t *testing.T) {
+	st, _ := status.New(codes.ResourceExhausted, "rate limited").
+		WithDetails(&errdetails.ErrorInfo{Reason: errs.ReasonRateLimited, Domain: errs.Domain})
+	f := &bigItemServer{getErr: st.Err()}
+	if _, err := getItem(context.Background(), f, &pb.GetItemRequest{}); status.Code(err) != codes.ResourceExhausted || f.streamed {
+		t.Fatalf("getItem: %v, streamed %v", err, f.streamed)
+	}
+}
@@ -0,0 +1,44 @@
+// cmd/cli/keychain_darwin.go
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// osKeychain keeps profile keys in the login keychain through security(1).
+type osKeychain struct{}
+
+func (osKeychain) get(account string) ([]byte, error) {
+	out, err := exec.Command("security", "find-generic-password", "-s", keychainService, "-a", account, "-w").Output()
+	var ee *exec.ExitError
+	if errors.As(err, &ee) && ee.ExitCode() == 44 { // errSecItemNotFound
+		return nil, errNoProfileKey
+	}
+	if err != nil {
+		return nil, fmt.Errorf("security find-generic-password: %w", err)
+	}
+	return hex.DecodeString(strings.TrimSpace(string(out)))
+}
+
+func (osKeychain) set(account string, key []byte) error {
+	// the key goes in on stdin; as an argument it would show in ps
+	cmd := exec.Command("security", "-i")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %q -w %s\n", keychainService, account, hex.EncodeToString(key)))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("security add-generic-password: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func (osKeychain) remove(account string) error {
+	err := exec.Command("security", "delete-generic-password", "-s", keychainService, "-a", account).Run()
+	var ee *exec.ExitError
+	if errors.As(err, &ee) && ee.ExitCode() == 44 {
+		return nil
+	}
+	return err
+}
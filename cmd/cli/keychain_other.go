@@ -0,0 +1,15 @@
+//go:build !unix && !windows
+
+// cmd/cli/keychain_other.go
+package main
+
+import "errors"
+
+// osKeychain reports that this platform has no keychain gk can use.
+type osKeychain struct{}
+
+var errNoKeychain = errors.New("no keychain on this platform; the profile cannot be encrypted")
+
+func (osKeychain) get(string) ([]byte, error) { return nil, errNoKeychain }
+func (osKeychain) set(string, []byte) error   { return errNoKeychain }
+func (osKeychain) remove(string) error        { return nil }
@@ -0,0 +1,45 @@
+//go:build unix && !darwin
+
+// cmd/cli/keychain_unix.go
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// osKeychain keeps profile keys in the desktop's Secret Service (GNOME
+// Keyring, KWallet, KeePassXC) through secret-tool(1) from libsecret.
+type osKeychain struct{}
+
+func secretTool(args ...string) *exec.Cmd {
+	return exec.Command("secret-tool", append(args, "service", keychainService)...)
+}
+
+func (osKeychain) get(account string) ([]byte, error) {
+	out, err := secretTool("lookup", "account", account).Output()
+	var ee *exec.ExitError
+	if errors.As(err, &ee) && len(out) == 0 {
+		return nil, errNoProfileKey
+	}
+	if err != nil {
+		return nil, fmt.Errorf("secret-tool lookup: %w", err)
+	}
+	return hex.DecodeString(strings.TrimSpace(string(out)))
+}
+
+func (osKeychain) set(account string, key []byte) error {
+	cmd := secretTool("store", "--label=GophKeeper profile key", "account", account)
+	cmd.Stdin = strings.NewReader(hex.EncodeToString(key))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("secret-tool store (install libsecret-tools): %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func (osKeychain) remove(account string) error {
+	return secretTool("clear", "account", account).Run()
+}
@@ -0,0 +1,55 @@
+// cmd/cli/keychain_windows.go
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// osKeychain keeps each profile key in profile.key inside the profile,
+// protected with DPAPI: only the same Windows user on the same machine can
+// unprotect it, so a copy of the directory is of no use elsewhere.
+type osKeychain struct{}
+
+func dpapiPath(account string) string { return filepath.Join(account, "profile.key") }
+
+func (osKeychain) get(account string) ([]byte, error) {
+	b, err := os.ReadFile(dpapiPath(account))
+	if errors.Is(err, os.ErrNotExist) || len(b) == 0 {
+		return nil, errNoProfileKey
+	}
+	if err != nil {
+		return nil, err
+	}
+	in := windows.DataBlob{Size: uint32(len(b)), Data: &b[0]}
+	var out windows.DataBlob
+	if err := windows.CryptUnprotectData(&in, nil, nil, 0, nil, windows.CRYPTPROTECT_UI_FORBIDDEN, &out); err != nil {
+		return nil, err
+	}
+	defer windows.LocalFree(windows.Handle(unsafe.Pointer(out.Data)))
+	return bytes.Clone(unsafe.Slice(out.Data, out.Size)), nil
+}
+
+func (osKeychain) set(account string, key []byte) error {
+	in := windows.DataBlob{Size: uint32(len(key)), Data: &key[0]}
+	var out windows.DataBlob
+	if err := windows.CryptProtectData(&in, nil, nil, 0, nil, windows.CRYPTPROTECT_UI_FORBIDDEN, &out); err != nil {
+		return err
+	}
+	defer windows.LocalFree(windows.Handle(unsafe.Pointer(out.Data)))
+	_ = os.MkdirAll(account, 0o700)
+	return os.WriteFile(dpapiPath(account), unsafe.Slice(out.Data, out.Size), 0o600)
+}
+
+func (osKeychain) remove(account string) error {
+	err := os.Remove(dpapiPath(account))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,164 @@
+// cmd/cli/license.go
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// dateLayout is how license and API key dates are written.
+const dateLayout = "2006-01-02"
+
+func validDate(s string) bool {
+	_, err := time.Parse(dateLayout, s)
+	return err == nil
+}
+
+func checkDate(s string) error {
+	if !validDate(s) {
+		return errors.New(tr("use YYYY-MM-DD"))
+	}
+	return nil
+}
+
+func checkSeats(s string) error {
+	if n, err := strconv.Atoi(s); err != nil || n <= 0 {
+		return errors.New(tr("a positive number"))
+	}
+	return nil
+}
+
+// checkDates fails with errInvalidInput on the first of the {name, value}
+// flags that is set but not a date.
+func checkDates(flags ...[2]string) error {
+	for _, f := range flags {
+		if f[1] != "" && !validDate(f[1]) {
+			return fmt.Errorf("%w: -%s: use YYYY-MM-DD", errInvalidInput, f[0])
+		}
+	}
+	return nil
+}
+
+// cmdAddLicense creates or updates a software license record.
+func cmdAddLicense(args []string, addr, caPath string, insecure bool) {
+	fs := flag.NewFlagSet("add-license", flag.ExitOnError)
+	id := fs.String("id", "", "item id (uuid, optional)")
+	title := fs.String("title", "", "title")
+	product := fs.String("product", "", "product the license is for")
+	key := fs.String("key", "", "license key")
+	seats := fs.Int("seats", 0, "number of seats (0 = not tracked)")
+	purchased := fs.String("purchased", "", "purchase date, YYYY-MM-DD")
+	expires := fs.String("expires", "", "expiry date, YYYY-MM-DD (none for a perpetual license)")
+	note := fs.String("note", "", "note")
+	base := fs.Int64("base", 0, "base version (0 for create)")
+	dryRun := dryRunFlag(fs)
+	customFields := customFieldFlags(fs)
+	parseFlags(fs, args)
+	fields, err := customFields()
+	if err != nil {
+		fail(err)
+	}
+
+	autoUUID(id)
+	if *key == "" {
+		fmt.Fprintln(os.Stderr, tr("key required"))
+		os.Exit(exitUsage)
+	}
+	if *seats < 0 {
+		fail(fmt.Errorf("%w: -seats must not be negative", errInvalidInput))
+	}
+	if err := checkDates([2]string{"purchased", *purchased}, [2]string{"expires", *expires}); err != nil {
+		fail(err)
+	}
+	meta := map[string]any{"title": choose(*title, *product), "product": *product, "purchased": *purchased, "expires": *expires, "note": *note}
+	if *seats > 0 {
+		meta["seats"] = *seats
+	}
+	data := map[string]any{"key": *key}
+	pt, err := buildTypedPayloadFields("license", meta, data, fields)
+	if err != nil {
+		fail(err)
+	}
+
+	token, err := loadToken()
+	if err != nil {
+		fail(err)
+	}
+	uid, err := loadUserID()
+	if err != nil {
+		fail(err)
+	}
+	blob, err := encryptForItem(*id, uid, *base+1, pt)
+	if err != nil {
+		fail(err)
+	}
+	if *dryRun {
+		printDryRun(plannedUpserts([]pendingUpsert{{ID: *id, BaseVer: *base, Blob: blob}}))
+		return
+	}
+	resp, err := upsertOne(addr, caPath, insecure, token, *id, *base, blob)
+	if err != nil {
+		fail(err)
+	}
+	printJSON(resp.GetResults())
+}
+
+// cmdAddAPIKey creates or updates an API key record.
+func cmdAddAPIKey(args []string, addr, caPath string, insecure bool) {
+	fs := flag.NewFlagSet("add-apikey", flag.ExitOnError)
+	id := fs.String("id", "", "item id (uuid, optional)")
+	title := fs.String("title", "", "title")
+	key := fs.String("key", "", "API key")
+	env := fs.String("env", "", "environment, e.g. prod or staging")
+	rotateBy := fs.String("rotate-by", "", "date to rotate the key by, YYYY-MM-DD")
+	note := fs.String("note", "", "note")
+	base := fs.Int64("base", 0, "base version (0 for create)")
+	dryRun := dryRunFlag(fs)
+	customFields := customFieldFlags(fs)
+	parseFlags(fs, args)
+	fields, err := customFields()
+	if err != nil {
+		fail(err)
+	}
+
+	autoUUID(id)
+	if *key == "" {
+		fmt.Fprintln(os.Stderr, tr("key required"))
+		os.Exit(exitUsage)
+	}
+	if err := checkDates([2]string{"rotate-by", *rotateBy}); err != nil {
+		fail(err)
+	}
+	meta := map[string]any{"title": *title, "environment": *env, "rotate_by": *rotateBy, "note": *note}
+	data := map[string]any{"key": *key}
+	pt, err := buildTypedPayloadFields("apikey", meta, data, fields)
+	if err != nil {
+		fail(err)
+	}
+
+	token, err := loadToken()
+	if err != nil {
+		fail(err)
+	}
+	uid, err := loadUserID()
+	if err != nil {
+		fail(err)
+	}
+	blob, err := encryptForItem(*id, uid, *base+1, pt)
+	if err != nil {
+		fail(err)
+	}
+	if *dryRun {
+		printDryRun(plannedUpserts([]pendingUpsert{{ID: *id, BaseVer: *base, Blob: blob}}))
+		return
+	}
+	resp, err := upsertOne(addr, caPath, insecure, token, *id, *base, blob)
+	if err != nil {
+		fail(err)
+	}
+	printJSON(resp.GetResults())
+}
@@ -0,0 +1,11 @@
+//go:build !unix && !windows
+
+// cmd/cli/lock_other.go
+package main
+
+// lockFile does not lock where the platform has no file locks; concurrent
+// renewals may then each spend the refresh token, and all but one fail.
+func lockFile(string) (unlock func(), err error) { return func() {}, nil }
+
+// rlockFile does not lock either; gk processes there are not kept apart.
+func rlockFile(string) (unlock func(), err error) { return func() {}, nil }
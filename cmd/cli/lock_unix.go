@@ -0,0 +1,33 @@
+//go:build unix
+
+// cmd/cli/lock_unix.go
+package main
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// lockFile waits for an exclusive lock on path, creating the file if
+// needed. The lock is held until unlock is called or the process exits.
+func lockFile(path string) (unlock func(), err error) { return flockPath(path, unix.LOCK_EX) }
+
+// rlockFile is lockFile for a shared lock: any number of holders, but none
+// while someone holds the exclusive one.
+func rlockFile(path string) (unlock func(), err error) { return flockPath(path, unix.LOCK_SH) }
+
+func flockPath(path string, how int) (unlock func(), err error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	if err := unix.Flock(int(f.Fd()), how); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return func() {
+		_ = unix.Flock(int(f.Fd()), unix.LOCK_UN)
+		_ = f.Close()
+	}, nil
+}
@@ -0,0 +1,44 @@
+//go:build unix
+
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func Test_rlockFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profile.lock")
+	r1, err := rlockFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r2, err := rlockFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(chan struct{})
+	go func() {
+		unlock, err := lockFile(path)
+		if err != nil {
+			t.Error(err)
+		} else {
+			unlock()
+		}
+		close(got)
+	}()
+	r1()
+	select {
+	case <-got:
+		t.Fatal("exclusive lock taken while a shared one is held")
+	case <-time.After(100 * time.Millisecond):
+	}
+	r2()
+	select {
+	case <-got:
+	case <-time.After(5 * time.Second):
+		t.Fatal("exclusive lock not taken once the shared ones were released")
+	}
+}
@@ -0,0 +1,35 @@
+// cmd/cli/lock_windows.go
+package main
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// lockFile waits for an exclusive lock on path, creating the file if
+// needed. The lock is held until unlock is called or the process exits.
+func lockFile(path string) (unlock func(), err error) {
+	return lockFileEx(path, windows.LOCKFILE_EXCLUSIVE_LOCK)
+}
+
+// rlockFile is lockFile for a shared lock: any number of holders, but none
+// while someone holds the exclusive one.
+func rlockFile(path string) (unlock func(), err error) { return lockFileEx(path, 0) }
+
+func lockFileEx(path string, flags uint32) (unlock func(), err error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	h := windows.Handle(f.Fd())
+	ol := new(windows.Overlapped)
+	if err := windows.LockFileEx(h, flags, 0, 1, 0, ol); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return func() {
+		_ = windows.UnlockFileEx(h, 0, 1, 0, ol)
+		_ = f.Close()
+	}, nil
+}
@@ -0,0 +1,60 @@
+// cmd/cli/logins.go
+package main
+
+import (
+	"encoding/hex"
+	"flag"
+	"time"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+)
+
+// loginRow is one line of gk logins.
+type loginRow struct {
+	Time    time.Time `json:"time"`
+	Address string    `json:"address"` // short address hash: equal means the same address
+	Success bool      `json:"success"`
+	Country string    `json:"country,omitempty"`
+}
+
+// loginRows renders attempts, newest first as the server sends them.
+func loginRows(attempts []*pb.LoginAttempt) []loginRow {
+	rows := make([]loginRow, 0, len(attempts))
+	for _, a := range attempts {
+		h := a.GetIpHash()
+		rows = append(rows, loginRow{
+			Time:    a.GetAt().AsTime().Local(),
+			Address: hex.EncodeToString(h[:min(len(h), 4)]),
+			Success: a.GetSuccess(),
+			Country: a.GetCountry(),
+		})
+	}
+	return rows
+}
+
+// cmdLogins prints recent logins and wrong passwords on the account.
+func cmdLogins(args []string, addr, caPath string, insecure bool) {
+	fs := flag.NewFlagSet("logins", flag.ExitOnError)
+	n := fs.Int("n", 20, "attempts to show (server keeps the last 100)")
+	parseFlags(fs, args)
+
+	token, err := loadToken()
+	if err != nil {
+		fail(err)
+	}
+	ctx, cancel := withTimeout()
+	defer cancel()
+	conn, cli, err := dial(ctx, addr, caPath, insecure, token)
+	if err != nil {
+		fail(err)
+	}
+	defer conn.Close()
+
+	req := &pb.GetLoginHistoryRequest{}
+	req.SetLimit(int32(max(*n, 0)))
+	resp, err := cli.GetLoginHistory(ctx, req)
+	if err != nil {
+		fail(err)
+	}
+	printJSON(loginRows(resp.GetAttempts()))
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+)
+
+func Test_loginRows(t *testing.T) {
+	t.Parallel()
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	a := &pb.LoginAttempt{}
+	a.SetAt(timestamppb.New(at))
+	a.SetIpHash([]byte{0xde, 0xad, 0xbe, 0xef, 0x01, 0x02})
+	a.SetSuccess(true)
+	a.SetCountry("SE")
+	b := &pb.LoginAttempt{}
+	b.SetIpHash([]byte{0x0a})
+
+	rows := loginRows([]*pb.LoginAttempt{a, b})
+	if len(rows) != 2 || !rows[0].Time.Equal(at) || rows[0].Address != "deadbeef" || !rows[0].Success || rows[0].Country != "SE" {
+		t.Fatalf("rows: %+v", rows)
+	}
+	if rows[1].Address != "0a" || rows[1].Success {
+		t.Fatalf("short hash: %+v", rows[1])
+	}
+}
@@ -17,19 +17,17 @@ import (
 
 	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
 	clientcrypto "github.com/and161185/goph-keeper/internal/crypto/clientcrypto"
-	u "github.com/gofrs/uuid/v5"
-	"github.com/golang-jwt/jwt/v5"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
-	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // ---- config/token store ----
 
 type tokenFile struct {
-	AccessToken string    `json:"access_token"`
-	ExpiresAt   time.Time `json:"expires_at"`
+	AccessToken  string    `json:"access_token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
 }
 
 func cfgDir() string {
@@ -42,29 +40,58 @@ func cfgDir() string {
 
 func tokenPath() string { return filepath.Join(cfgDir(), "token.json") }
 
-func saveToken(tok string, exp time.Time) error {
+// tokenLockPath is locked while token.json is renewed or replaced. It is a
+// file of its own because token.json is replaced by rename.
+func tokenLockPath() string { return filepath.Join(cfgDir(), "token.lock") }
+
+// saveToken stores the access token and, if the server issued one, the
+// refresh token. It waits for a renewal by another gk process to finish,
+// so that one cannot overwrite a fresh login.
+func saveToken(tok, refresh string, exp time.Time) error {
 	_ = os.MkdirAll(cfgDir(), 0o700)
-	f, err := os.Create(tokenPath())
+	unlock, err := lockFile(tokenLockPath())
 	if err != nil {
 		return err
 	}
-	defer f.Close()
-	enc := json.NewEncoder(f)
-	enc.SetIndent("", "  ")
-	return enc.Encode(tokenFile{AccessToken: tok, ExpiresAt: exp})
+	defer unlock()
+	return writeTokenFile(tokenFile{AccessToken: tok, ExpiresAt: exp, RefreshToken: refresh})
 }
 
-func loadToken() (string, error) {
-	b, err := os.ReadFile(tokenPath())
+// writeTokenFile replaces token.json; the file is private since the
+// refresh token outlives a login.
+func writeTokenFile(tf tokenFile) error {
+	b, err := json.MarshalIndent(tf, "", "  ")
 	if err != nil {
-		return "", err
+		return err
 	}
+	return writeProfileFile(tokenPath(), append(b, '\n'))
+}
+
+func readTokenFile() (tokenFile, error) {
 	var tf tokenFile
-	if err := json.Unmarshal(b, &tf); err != nil {
+	b, err := readProfileFile(tokenPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return tf, errNoToken
+	}
+	if err != nil {
+		return tf, err
+	}
+	err = json.Unmarshal(b, &tf)
+	return tf, err
+}
+
+// loadToken returns the saved access token, renewing it first when it
+// expires within renewBefore and a refresh token is saved.
+func loadToken() (string, error) {
+	tf, err := readTokenFile()
+	if err != nil {
 		return "", err
 	}
+	if tf.RefreshToken != "" && renewServer.addr != "" && time.Until(tf.ExpiresAt) < renewBefore {
+		tf = renewToken(tf)
+	}
 	if tf.AccessToken == "" || time.Now().After(tf.ExpiresAt) {
-		return "", errors.New("no valid token (login required)")
+		return "", errNoToken
 	}
 	return tf.AccessToken, nil
 }
@@ -72,11 +99,10 @@ func loadToken() (string, error) {
 func dekPath() string { return filepath.Join(cfgDir(), "dek.bin") }
 
 func saveDEK(dek []byte) error {
-	_ = os.MkdirAll(cfgDir(), 0o700)
-	return os.WriteFile(dekPath(), dek, 0o600)
+	return writeProfileFile(dekPath(), dek)
 }
 func loadDEK() ([]byte, error) {
-	return os.ReadFile(dekPath())
+	return readProfileFile(dekPath())
 }
 
 // ---- grpc dial ----
@@ -88,33 +114,46 @@ func (b bearerCreds) GetRequestMetadata(context.Context, ...string) (map[string]
 }
 func (b bearerCreds) RequireTransportSecurity() bool { return true }
 
-func loadTLS(caPath string, insecure bool) (credentials.TransportCredentials, error) {
-	if insecure {
-		return credentials.NewTLS(&tls.Config{InsecureSkipVerify: true}), nil
-	}
-	if caPath == "" {
-		return credentials.NewClientTLSFromCert(nil, ""), nil
-	}
-	pem, err := os.ReadFile(caPath)
-	if err != nil {
-		return nil, err
-	}
-	pool := x509.NewCertPool()
-	if !pool.AppendCertsFromPEM(pem) {
-		return nil, errors.New("bad CA cert")
+// loadTLS builds the transport credentials. verify, if set, runs on top of
+// the CA checks or, when insecure, instead of them.
+func loadTLS(caPath string, insecure bool, verify func(tls.ConnectionState) error) (credentials.TransportCredentials, error) {
+	cfg := &tls.Config{InsecureSkipVerify: insecure, VerifyConnection: verify}
+	if caPath != "" && !insecure {
+		pem, err := os.ReadFile(caPath)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("bad CA cert")
+		}
+		cfg.RootCAs = pool
 	}
-	return credentials.NewTLS(&tls.Config{RootCAs: pool}), nil
+	return credentials.NewTLS(cfg), nil
 }
 
+// extraDialOptions are applied after dial's own; tests point the CLI at an
+// in-process server (pkg/gktest) with them.
+var extraDialOptions []grpc.DialOption
+
 func dial(ctx context.Context, addr, caPath string, insecure bool, bearer string) (*grpc.ClientConn, pb.GophKeeperClient, error) {
-	creds, err := loadTLS(caPath, insecure)
+	insecure, verify, err := serverCheck(addr, insecure)
+	if err != nil {
+		return nil, nil, err
+	}
+	creds, err := loadTLS(caPath, insecure, verify)
 	if err != nil {
 		return nil, nil, err
 	}
-	opts := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithChainUnaryInterceptor(clientInfoUnary),
+		grpc.WithChainStreamInterceptor(clientInfoStream),
+	}
 	if bearer != "" {
 		opts = append(opts, grpc.WithPerRPCCredentials(bearerCreds{token: bearer}))
 	}
+	opts = append(opts, extraDialOptions...)
 	//nolint:staticcheck // DialContext is supported through 1.x; migrate when grpc.NewClient is stable
 	cc, err := grpc.DialContext(ctx, addr, opts...)
 	if err != nil {
@@ -139,33 +178,38 @@ func printJSON(v any) {
 }
 
 func saveUserID(uid string) error {
-	return os.WriteFile(filepath.Join(cfgDir(), "user_id"), []byte(strings.TrimSpace(uid)), 0o600)
+	return writeProfileFile(filepath.Join(cfgDir(), "user_id"), []byte(strings.TrimSpace(uid)))
 }
 func loadUserID() (string, error) {
-	b, err := os.ReadFile(filepath.Join(cfgDir(), "user_id"))
+	b, err := readProfileFile(filepath.Join(cfgDir(), "user_id"))
 	if err != nil {
 		return "", err
 	}
 	return strings.TrimSpace(string(b)), nil
 }
 
-func usage() {
-	fmt.Fprintf(os.Stderr, `gk CLI
+// usageHead and usageTail frame the command list in gk's help; their
+// translations live in the catalogs.
+const usageHead = `gk CLI
 Usage:
-  gk -addr HOST:PORT [-cacert file | -insecure] <cmd> [args]
+  gk -addr HOST:PORT [-lang en|ru] [-no-color] [-cacert file | -insecure | -tofu] [-pin-sha256 KEY] [-format json|yaml|table|go-template='...'] [-columns a,b] [-error-json] [-no-cache] [-max-skew 2m] [-renew-before 5m] [-cipher aes-256-gcm] [-schema-check strict|warn|off] [-non-interactive] <cmd> [args]
+  Global flags may also follow a group: gk item -format table list
+  gk help <cmd> explains a command or group; the names from before the groups (add-login, server-info, admin-ip...) still work.
 
 Commands:
-  version
-  register   -u <username> -p <password>
-  login      -u <username> -p <password>           (saves token)
-  list                                         (GetChanges since 0)
-  sync       -since <ver>
-  get        -id <uuid>
-  add        -id <uuid> -file <blob>               (base_ver=0)
-  edit       -id <uuid> -base <ver> -file <blob>
-  rm         -id <uuid> -base <ver>
-`)
-	os.Exit(2)
+`
+
+const usageTail = `
+Environment:
+  GK_<FLAG> sets a global flag, GK_<COMMAND>_<FLAG> a command's (GK_ADDR, GK_LOGIN_P, GK_TEMPLATE_APPLY_NAME)
+
+Exit codes:
+  0 ok, 1 error, 2 usage/invalid input, 3 auth, 4 conflict, 5 not found, 6 network, 7 crypto
+`
+
+func usage() {
+	fmt.Fprint(os.Stderr, rootHelp(commandTree()))
+	os.Exit(exitUsage)
 }
 
 // ---- main ----
@@ -175,254 +219,352 @@ var (
 	buildDate = "unknown"
 )
 
-// main dispatches subcommands and configures TLS/auth for RPC calls.
+// main parses the global flags and runs the command the rest names.
 func main() {
+	lang = detectLang(os.Getenv)
+
 	// global flags
 	addr := flag.String("addr", "localhost:8443", "server addr")
 	caPath := flag.String("cacert", "", "CA cert (PEM)")
 	insecure := flag.Bool("insecure", false, "skip cert verify (dev)")
+	flag.StringVar(&outputFormat, "format", "", "output for read commands: json|yaml|table|go-template='...'")
+	flag.StringVar(&outputColumns, "columns", "", "comma-separated columns for -format table")
+	flag.BoolVar(&errorJSON, "error-json", false, "print errors as JSON objects on stderr")
+	flag.BoolVar(&noCache, "no-cache", false, "always read from the server, ignoring the syncd cache")
+	flag.DurationVar(&maxSkew, "max-skew", maxSkew, "warn when the local clock differs from the server's by more (0 = never)")
+	flag.Func("cipher", "AEAD for new item blobs: xchacha20-poly1305 (default) | aes-256-gcm", func(v string) (err error) {
+		blobCipher, err = clientcrypto.ParseCipher(v)
+		return err
+	})
+	flag.Func("schema-check", "checks on items written: strict (refuse payloads that break their type's schema) | warn | off", setSchemaCheck)
+	flag.BoolVar(&noColor, "no-color", false, "never color output (also NO_COLOR=1); color is used only on terminals")
+	flag.DurationVar(&renewBefore, "renew-before", renewBefore, "renew the access token when less than this is left of it (0 = only once expired)")
+	flag.Func("lang", "output language: en | ru (default from LC_ALL/LC_MESSAGES/LANG)", setLang)
+	flag.BoolVar(&nonInteractive, "non-interactive", false, "never prompt: commands that would ask fail instead")
+	flag.BoolVar(&tofu, "tofu", false, "trust the key -addr presents on first connect and pin it, instead of checking it against a CA")
+	flag.Func("pin-sha256", "base64 SHA-256 of the server's public key (SPKI), comma-separated for several; saved for -addr", func(v string) (err error) {
+		pinFlag, err = parsePins(v)
+		return err
+	})
 	flag.Usage = usage
 	flag.Parse()
 
-	if flag.NArg() < 1 {
+	root := commandTree()
+	cmd, args := root.resolve(flag.Args())
+	envFlags(flag.CommandLine, "")
+	renewServer.addr, renewServer.caPath, renewServer.insecure = *addr, *caPath, *insecure
+
+	switch {
+	case cmd == root:
 		usage()
+	case cmd.run == nil:
+		fmt.Fprint(os.Stderr, cmd.help(root.pathTo(cmd)))
+		os.Exit(exitUsage)
+	}
+	startTelemetry(root.pathTo(cmd))
+	unlock, err := lockProfile(cmd.lock)
+	if err != nil {
+		fail(err)
 	}
-	cmd := flag.Arg(0)
+	defer unlock()
+	if len(pinFlag) > 0 {
+		if err := savePins(*addr, pinFlag); err != nil {
+			fail(err)
+		}
+	}
+	cmd.run(args, serverFlags{addr: *addr, caPath: *caPath, insecure: *insecure})
+	finishTelemetry("ok")
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+// cmdRegister creates an account and prints its user id.
+func cmdRegister(args []string, addr, caPath string, insecure bool) {
+	ctx, cancel := withTimeout()
 	defer cancel()
 
-	switch cmd {
+	fs := flag.NewFlagSet("register", flag.ExitOnError)
+	u := fs.String("u", "", "username")
+	p := fs.String("p", "", "password")
+	captcha := fs.String("captcha", "", "CAPTCHA response token, if the server asks for one")
+	parseFlags(fs, args)
+	if *u == "" || *p == "" {
+		fmt.Fprintln(os.Stderr, tr("need -u and -p"))
+		os.Exit(1)
+	}
 
-	case "version":
-		fmt.Printf("gk %s (%s)\n", version, buildDate)
+	cc, cli, err := dial(ctx, addr, caPath, insecure, "")
+	if err != nil {
+		fail(err)
+	}
+	defer cc.Close()
+
+	rr := &pb.RegisterRequest{}
+	rr.SetUsername(*u)
+	rr.SetPassword(*p)
+	resp, err := withChallenge(*captcha, func(answer string) (*pb.RegisterResponse, error) {
+		rr.SetChallengeResponse(answer)
+		return cli.Register(ctx, rr)
+	})
+	if err != nil {
+		fail(err)
+	}
+	fmt.Println(resp.GetUserId())
+}
 
-	case "register":
-		fs := flag.NewFlagSet("register", flag.ExitOnError)
-		u := fs.String("u", "", "username")
-		p := fs.String("p", "", "password")
-		_ = fs.Parse(flag.Args()[1:])
-		if *u == "" || *p == "" {
-			fmt.Fprintln(os.Stderr, "need -u and -p")
-			os.Exit(1)
-		}
+// cmdLogin saves a token and the DEK, creating the DEK on the first login.
+func cmdLogin(args []string, addr, caPath string, insecure bool) {
+	ctx, cancel := withTimeout()
+	defer cancel()
 
-		cc, cli, err := dial(ctx, *addr, *caPath, *insecure, "")
-		if err != nil {
-			fail(err)
-		}
-		defer cc.Close()
+	fs := flag.NewFlagSet("login", flag.ExitOnError)
+	u := fs.String("u", "", "username")
+	p := fs.String("p", "", "password")
+	captcha := fs.String("captcha", "", "CAPTCHA response token, if the server asks for one")
+	host, _ := os.Hostname()
+	label := fs.String("label", host, "name this device in gk sessions")
+	scope := fs.String("scope", "", "limit the token to these comma-separated scopes: items:read, items:write, account:manage")
+	parseFlags(fs, args)
+	if *u == "" || *p == "" {
+		fmt.Fprintln(os.Stderr, tr("need -u and -p"))
+		os.Exit(1)
+	}
 
-		rr := &pb.RegisterRequest{}
-		rr.SetUsername(*u)
-		rr.SetPassword(*p)
-		resp, err := cli.Register(ctx, rr)
+	cc, cli, err := dial(ctx, addr, caPath, insecure, "")
+	if err != nil {
+		fail(err)
+	}
+	defer cc.Close()
+
+	lr := &pb.LoginRequest{}
+	lr.SetUsername(*u)
+	lr.SetPassword(*p)
+	lr.SetDeviceLabel(*label)
+	lr.SetScopes(splitColumns(*scope))
+
+	resp, err := withChallenge(*captcha, func(answer string) (*pb.LoginResponse, error) {
+		lr.SetChallengeResponse(answer)
+		return cli.Login(ctx, lr)
+	})
+	if err != nil {
+		fail(err)
+	}
+
+	// derive KEK once, with the parameters recorded in the wrapped DEK
+	kdf, err := clientcrypto.WrappedKDF(resp.GetWrappedDek())
+	if err != nil {
+		fail(fmt.Errorf("wrapped DEK: %w: %w", errCrypto, err))
+	}
+	kek := kdf.DeriveKEK([]byte(*p), resp.GetKekSalt())
+
+	if len(resp.GetWrappedDek()) > 0 {
+		// unwrap and save DEK
+		dek, err := clientcrypto.UnwrapDEK(kek, resp.GetWrappedDek())
 		if err != nil {
+			fail(fmt.Errorf("unwrap DEK: %w: %w", errCrypto, err))
+		}
+		if err := saveDEK(dek); err != nil {
 			fail(err)
 		}
-		fmt.Println(resp.GetUserId())
-
-	case "login":
-		fs := flag.NewFlagSet("login", flag.ExitOnError)
-		u := fs.String("u", "", "username")
-		p := fs.String("p", "", "password")
-		_ = fs.Parse(flag.Args()[1:])
-		if *u == "" || *p == "" {
-			fmt.Fprintln(os.Stderr, "need -u and -p")
-			os.Exit(1)
+	} else {
+		if len(resp.GetScopes()) > 0 {
+			fail(fmt.Errorf("%w: %s", errInvalidInput, tr("the first login sets up the vault key; log in without -scope")))
 		}
-
-		cc, cli, err := dial(ctx, *addr, *caPath, *insecure, "")
+		// first login → generate DEK, wrap, push to server, save locally
+		dek, err := initDEK(ctx, addr, caPath, insecure, resp.GetAccessToken(), kek)
 		if err != nil {
 			fail(err)
 		}
-		defer cc.Close()
-
-		lr := &pb.LoginRequest{}
-		lr.SetUsername(*u)
-		lr.SetPassword(*p)
-
-		resp, err := cli.Login(ctx, lr)
-		if err != nil {
+		if err := saveDEK(dek); err != nil {
 			fail(err)
 		}
+	}
 
-		// derive KEK once
-		kek := clientcrypto.DeriveKEK([]byte(*p), resp.GetKekSalt())
-
-		if len(resp.GetWrappedDek()) > 0 {
-			// unwrap and save DEK
-			dek, err := clientcrypto.UnwrapDEK(kek, resp.GetWrappedDek())
-			if err != nil {
-				fail(fmt.Errorf("unwrap DEK: %w", err))
-			}
-			if err := saveDEK(dek); err != nil {
-				fail(err)
-			}
-		} else {
-			// first login → generate DEK, wrap, push to server, save locally
-			dek, err := clientcrypto.Rand(clientcrypto.DEKLen)
-			if err != nil {
-				fail(err)
-			}
-			wrapped, err := clientcrypto.WrapDEK(kek, dek)
-			if err != nil {
-				fail(err)
-			}
-
-			cc2, cli2, err := dial(ctx, *addr, *caPath, *insecure, resp.GetAccessToken())
-			if err != nil {
-				fail(err)
-			}
-
-			swDEKr := &pb.SetWrappedDEKRequest{}
-			swDEKr.SetWrappedDek(wrapped)
-			_, err = cli2.SetWrappedDEK(ctx, swDEKr)
-			_ = cc2.Close()
-			if err != nil {
-				fail(err)
-			}
-
-			if err := saveDEK(dek); err != nil {
-				fail(err)
-			}
-		}
+	if err := saveLogin(resp); err != nil {
+		fail(err)
+	}
 
-		// save user id for AAD
-		_ = saveUserID(resp.GetUserId())
-
-		// parse exp from JWT
-		var claims jwt.RegisteredClaims
-		_, _ = jwt.ParseWithClaims(resp.GetAccessToken(), &claims, func(*jwt.Token) (any, error) { return nil, nil },
-			jwt.WithoutClaimsValidation(),
-		)
-		exp := time.Now().Add(15 * time.Minute)
-		if claims.ExpiresAt != nil {
-			exp = claims.ExpiresAt.Time
-		}
-		if err := saveToken(resp.GetAccessToken(), exp); err != nil {
-			fail(err)
-		}
+	if n := resp.GetFailedAttempts(); n > 0 {
+		fmt.Fprint(os.Stderr, colors(os.Stderr).warn(tr("warning: %d failed login attempts since your last login\n", n)))
+	}
+	if a := resp.GetAnomalies(); len(a) > 0 {
+		fmt.Fprint(os.Stderr, colors(os.Stderr).warn(tr("warning: unusual login (%s); if it was not you, change your password\n", strings.Join(a, ", "))))
+	}
+	fmt.Println(colors(os.Stdout).ok("ok"))
+}
 
-		fmt.Println("ok")
+// cmdList prints every item of the vault.
+func cmdList(_ []string, addr, caPath string, insecure bool) {
+	ctx, cancel := withTimeout()
+	defer cancel()
 
-	case "list":
+	changes, warm := warmChanges()
+	if !warm {
 		token, err := loadToken()
 		if err != nil {
 			fail(err)
 		}
-		cc, cli, err := dial(ctx, *addr, *caPath, *insecure, token)
+		cc, cli, err := dial(ctx, addr, caPath, insecure, token)
 		if err != nil {
 			fail(err)
 		}
 		defer cc.Close()
 
-		gcr := &pb.GetChangesRequest{}
-		gcr.SetSinceVer(0)
-		out, err := cli.GetChanges(ctx, gcr)
+		out, err := fetchBaseline(ctx, cli)
 		if err != nil {
 			fail(err)
 		}
-		// печатаем коротко
-		type row struct{ ID, Ver, Deleted, UpdatedAt string }
-		rows := []row{}
-		for _, c := range out.GetChanges() {
-			rows = append(rows, row{
-				ID:        c.GetId(),
-				Ver:       fmt.Sprint(c.GetVer()),
-				Deleted:   fmt.Sprint(c.GetDeleted()),
-				UpdatedAt: tsString(c.GetUpdatedAt()),
-			})
-		}
-		printJSON(rows)
+		changes = out.GetChanges()
+	}
+	// печатаем коротко
+	rows := changeRows(changes)
+	emit(rows, func() { printJSON(rows) })
+}
 
-	case "sync":
-		fs := flag.NewFlagSet("sync", flag.ExitOnError)
-		since := fs.Int64("since", 0, "since version")
-		_ = fs.Parse(flag.Args()[1:])
+// cmdSync prints the changes since a version, or since the profile's last
+// sync, narrowed by the sync filter.
+func cmdSync(args []string, addr, caPath string, insecure bool) {
+	ctx, cancel := withTimeout()
+	defer cancel()
 
-		token, err := loadToken()
+	fs := flag.NewFlagSet("sync", flag.ExitOnError)
+	since := fs.Int64("since", 0, "since version, 0 for the whole vault; the default continues from the last sync")
+	nag := fs.String("nag-stale", "", "after syncing, list passwords older than this on stderr (e.g. 180d)")
+	all := fs.Bool("all", false, "ignore the sync filter")
+	parseFlags(fs, args)
+	var nagAge time.Duration
+	if *nag != "" {
+		d, err := parseAge(*nag)
 		if err != nil {
-			fail(err)
+			fail(fmt.Errorf("%w: %w", errInvalidInput, err))
 		}
-		cc, cli, err := dial(ctx, *addr, *caPath, *insecure, token)
-		if err != nil {
-			fail(err)
-		}
-		defer cc.Close()
+		nagAge = d
+	}
 
+	token, err := loadToken()
+	if err != nil {
+		fail(err)
+	}
+	cc, cli, err := dial(ctx, addr, caPath, insecure, token)
+	if err != nil {
+		fail(err)
+	}
+	defer cc.Close()
+
+	// without -since, continue from where the last sync stopped
+	explicit := false
+	fs.Visit(func(f *flag.Flag) { explicit = explicit || f.Name == "since" })
+	uid, _ := loadUserID()
+	cur := syncCursor{UserID: uid, Addr: addr}
+
+	sent := time.Now()
+	var out *pb.GetChangesResponse
+	switch {
+	case explicit && *since > 0:
 		gcr := &pb.GetChangesRequest{}
 		gcr.SetSinceVer(*since)
-		out, err := cli.GetChanges(ctx, gcr)
-		if err != nil {
-			fail(err)
+		out, err = fetchChanges(ctx, cli, gcr)
+	case explicit:
+		out, err = fetchBaseline(ctx, cli)
+	default:
+		var saved syncCursor
+		if saved, err = loadSyncCursor(uid, addr); err == nil {
+			cur = saved
+			out, err = fetchChanges(ctx, cli, cur.request())
+			break
 		}
-		printJSON(out.GetChanges())
-
-	case "get":
-		fs := flag.NewFlagSet("get", flag.ExitOnError)
-		id := fs.String("id", "", "item id (uuid)")
-		_ = fs.Parse(flag.Args()[1:])
-		if *id == "" {
-			fmt.Fprintln(os.Stderr, "need -id")
-			os.Exit(1)
+		if errors.Is(err, os.ErrNotExist) {
+			fmt.Fprint(os.Stderr, colors(os.Stderr).warn(tr("warning: no sync position saved yet; listing the whole vault\n")))
+		} else {
+			fmt.Fprint(os.Stderr, colors(os.Stderr).warn(tr("warning: the saved sync position is unusable (%v); listing the whole vault\n", err)))
 		}
-
-		token, err := loadToken()
-		if err != nil {
-			fail(err)
+		out, err = fetchBaseline(ctx, cli)
+	}
+	if err != nil {
+		fail(err)
+	}
+	if !explicit {
+		if err := saveSyncCursor(cur.advance(out)); err != nil {
+			fmt.Fprint(os.Stderr, colors(os.Stderr).warn(tr("warning: could not save the sync position: %v\n", err)))
 		}
-		ccConn, cli, err := dial(ctx, *addr, *caPath, *insecure, token)
+	}
+	skew, _ := responseSkew(sent, time.Now(), out)
+	warnSkew(os.Stderr, skew)
+	changes := out.GetChanges()
+	if !*all {
+		filter, err := loadSyncFilter()
 		if err != nil {
 			fail(err)
 		}
-		defer ccConn.Close()
+		changes = filterChanges(filter, uid, changes)
+	}
+	rows := changeRows(changes)
+	emit(rows, func() { printJSON(rows) })
+	if nagAge > 0 {
+		nagStale(ctx, cli, nagAge, time.Now().Add(skew))
+	}
+}
 
-		gir := &pb.GetItemRequest{}
-		gir.SetId(*id)
-		out, err := cli.GetItem(ctx, gir)
-		if err != nil {
-			fail(err)
-		}
-		if out.GetDeleted() {
-			fmt.Fprintln(os.Stderr, "item is deleted")
-			os.Exit(1)
-		}
+// cmdGet decrypts and prints one item.
+func cmdGet(args []string, addr, caPath string, insecure bool) {
+	ctx, cancel := withTimeout()
+	defer cancel()
 
-		// decrypt: key = HKDF(DEK, itemID); AAD = userID||itemID||ver
-		dek, err := loadDEK()
-		if err != nil {
-			fail(errors.New("no DEK; login first"))
-		}
-		userID, err := loadUserID()
-		if err != nil {
-			fail(err)
-		}
-		ver := out.GetVer()
-		blob := out.GetBlobEnc().GetCiphertext()
+	fs := flag.NewFlagSet("get", flag.ExitOnError)
+	id := fs.String("id", "", "item id (uuid)")
+	parseFlags(fs, args)
+	if *id == "" {
+		fmt.Fprintln(os.Stderr, tr("need -id"))
+		os.Exit(1)
+	}
 
-		key, err := clientcrypto.DeriveItemKey(dek, []byte(*id))
-		if err != nil {
-			fail(err)
-		}
-		pt, err := clientcrypto.DecryptBlob(key, []byte(userID), []byte(*id), ver, blob)
-		if err != nil {
-			fail(fmt.Errorf("decrypt: %w", err))
-		}
+	token, err := loadToken()
+	if err != nil {
+		fail(err)
+	}
+	ccConn, cli, err := dial(ctx, addr, caPath, insecure, token)
+	if err != nil {
+		fail(err)
+	}
+	defer ccConn.Close()
 
-		// payload формат: {type, meta, data}; печатаем красиво
-		var payload struct {
-			Type string      `json:"type"`
-			Meta interface{} `json:"meta"`
-			Data []byte      `json:"data"`
-		}
-		if err := json.Unmarshal(pt, &payload); err != nil {
-			// если не JSON — выведем как есть (hex+size)
-			fmt.Printf("id=%s ver=%d at=%s\nraw=%x (%dB)\n",
-				out.GetId(), ver, tsString(out.GetUpdatedAt()), pt, len(pt))
-			break
-		}
+	gir := &pb.GetItemRequest{}
+	gir.SetId(*id)
+	out, err := getItem(ctx, cli, gir)
+	if err != nil {
+		fail(err)
+	}
+	if out.GetDeleted() {
+		fail(errItemDeleted)
+	}
+
+	// decrypt: key = HKDF(DEK, itemID); AAD = userID||itemID||ver
+	userID, err := loadUserID()
+	if err != nil {
+		fail(err)
+	}
+	ver := out.GetVer()
+	pt, err := decryptForItem(*id, userID, ver, out.GetBlobEnc().GetCiphertext())
+	if err != nil {
+		fail(err)
+	}
+
+	// payload формат: {type, meta, data}; печатаем красиво
+	var payload struct {
+		Type string      `json:"type"`
+		Meta interface{} `json:"meta"`
+		Data []byte      `json:"data"`
+	}
+	if err := json.Unmarshal(pt, &payload); err != nil {
+		// если не JSON — выведем как есть (hex+size)
+		fmt.Printf("id=%s ver=%d at=%s\nraw=%x (%dB)\n",
+			out.GetId(), ver, tsString(out.GetUpdatedAt()), pt, len(pt))
+		return
+	}
 
+	view := itemView{
+		ID: out.GetId(), Ver: ver, UpdatedAt: tsString(out.GetUpdatedAt()),
+		Type: payload.Type, Meta: payload.Meta, DataSize: len(payload.Data),
+	}
+	emit(view, func() {
 		fmt.Printf("id=%s ver=%d at=%s type=%s data=%dB\n",
 			out.GetId(), ver, tsString(out.GetUpdatedAt()), payload.Type, len(payload.Data))
 
@@ -430,212 +572,272 @@ func main() {
 			m, _ := json.MarshalIndent(payload.Meta, "", "  ")
 			fmt.Printf("meta=%s\n", m)
 		}
+	})
+}
 
-	case "add":
-		fs := flag.NewFlagSet("add", flag.ExitOnError)
-		id := fs.String("id", "", "item id (uuid, optional)")
-		typ := fs.String("type", "text", "item type")
-		meta := fs.String("meta", "", "meta JSON/string")
-		dataFile := fs.String("file", "", "data file ('-'=stdin)")
-		_ = fs.Parse(flag.Args()[1:])
-
-		if *id == "" {
-			uid, _ := u.NewV4()
-			*id = uid.String()
-		}
-		if *dataFile == "" {
-			fmt.Fprintln(os.Stderr, "need -file")
-			os.Exit(1)
-		}
+// cmdAdd encrypts a file as a new item.
+func cmdAdd(args []string, addr, caPath string, insecure bool) {
+	ctx, cancel := withTimeout()
+	defer cancel()
 
-		token, err := loadToken()
-		if err != nil {
-			fail(err)
-		}
-		ccConn, cli, err := dial(ctx, *addr, *caPath, *insecure, token)
-		if err != nil {
-			fail(err)
-		}
-		defer ccConn.Close()
+	fs := flag.NewFlagSet("add", flag.ExitOnError)
+	id := fs.String("id", "", "item id (uuid, optional)")
+	typ := fs.String("type", "text", "item type")
+	meta := fs.String("meta", "", "meta JSON/string")
+	dataFile := fs.String("file", "", "data file ('-'=stdin)")
+	interactive := fs.Bool("i", false, "interactive: choose a type and answer prompts")
+	dryRun := dryRunFlag(fs)
+	parseFlags(fs, args)
+	if *interactive {
+		cmdAddInteractive(addr, caPath, insecure, *dryRun)
+		return
+	}
 
-		// payload: {type, meta, data}
-		data, err := readAll(*dataFile)
-		if err != nil {
-			fail(err)
-		}
-		payload := map[string]any{"type": *typ, "meta": *meta, "data": data}
-		plain, _ := json.Marshal(payload)
+	autoUUID(id)
+	if *dataFile == "" {
+		fmt.Fprintln(os.Stderr, tr("need -file"))
+		os.Exit(1)
+	}
 
-		dek, err := loadDEK()
-		if err != nil {
-			fail(errors.New("no DEK (login first with wrapped_dek)"))
-		}
-		userID, err := loadUserID()
-		if err != nil {
-			fail(err)
-		}
+	// payload: {type, meta, data}
+	data, err := readAll(*dataFile)
+	if err != nil {
+		fail(err)
+	}
+	payload := map[string]any{"type": *typ, "meta": *meta, "data": data}
+	plain, _ := json.Marshal(payload)
 
-		// AAD = user_id || item_id || ver(=base+1). base=0 → ver=1
-		aadUser := []byte(userID)
-		aadItem := []byte(*id)
-		ver := int64(1)
+	dek, err := loadDEK()
+	if err != nil {
+		fail(errNoDEK)
+	}
+	userID, err := loadUserID()
+	if err != nil {
+		fail(err)
+	}
 
-		key, err := clientcrypto.DeriveItemKey(dek, aadItem)
-		if err != nil {
-			fail(err)
-		}
-		blob, err := clientcrypto.EncryptBlob(key, aadUser, aadItem, ver, plain)
-		if err != nil {
-			fail(err)
-		}
+	// AAD = user_id || item_id || ver(=base+1). base=0 → ver=1
+	aadUser := []byte(userID)
+	aadItem := []byte(*id)
+	ver := int64(1)
 
-		eb := &pb.EncryptedBlob{}
-		eb.SetCiphertext(blob)
+	key, err := clientcrypto.DeriveItemKey(dek, aadItem)
+	if err != nil {
+		fail(err)
+	}
+	blob, err := clientcrypto.EncryptBlobWith(blobCipher, key, aadUser, aadItem, ver, plain)
+	if err != nil {
+		fail(err)
+	}
 
-		ui := &pb.UpsertItem{}
-		ui.SetId(*id)
-		ui.SetBaseVer(0)
-		ui.SetBlobEnc(eb)
-		req := &pb.UpsertItemsRequest{}
-		req.SetItems([]*pb.UpsertItem{
-			ui,
-		})
-		out, err := cli.UpsertItems(ctx, req)
-		if err != nil {
-			fail(err)
-		}
-		printJSON(out.GetResults())
-
-	case "edit":
-		fs := flag.NewFlagSet("edit", flag.ExitOnError)
-		id := fs.String("id", "", "item id (uuid)")
-		base := fs.Int64("base", -1, "base version")
-		typ := fs.String("type", "text", "item type")
-		meta := fs.String("meta", "", "meta JSON/string")
-		dataFile := fs.String("file", "", "data file ('-'=stdin)")
-		_ = fs.Parse(flag.Args()[1:])
-		if *id == "" || *base < 0 || *dataFile == "" {
-			fmt.Fprintln(os.Stderr, "need -id -base -file")
-			os.Exit(1)
-		}
+	if *dryRun {
+		printDryRun(plannedUpserts([]pendingUpsert{{ID: *id, BaseVer: 0, Blob: blob}}))
+		return
+	}
 
-		token, err := loadToken()
-		if err != nil {
-			fail(err)
-		}
-		ccConn, cli, err := dial(ctx, *addr, *caPath, *insecure, token)
-		if err != nil {
-			fail(err)
-		}
-		defer ccConn.Close()
+	token, err := loadToken()
+	if err != nil {
+		fail(err)
+	}
+	ccConn, cli, err := dial(ctx, addr, caPath, insecure, token)
+	if err != nil {
+		fail(err)
+	}
+	defer ccConn.Close()
+
+	eb := &pb.EncryptedBlob{}
+	eb.SetCiphertext(blob)
+
+	ui := &pb.UpsertItem{}
+	ui.SetId(*id)
+	ui.SetBaseVer(0)
+	ui.SetBlobEnc(eb)
+	ui.SetHints(itemHints(blob))
+	req := &pb.UpsertItemsRequest{}
+	req.SetItems([]*pb.UpsertItem{
+		ui,
+	})
+	out, err := cli.UpsertItems(ctx, req)
+	if err != nil {
+		fail(err)
+	}
+	showWarnings(out.GetWarnings())
+	printJSON(out.GetResults())
+}
 
-		data, err := readAll(*dataFile)
-		if err != nil {
-			fail(err)
-		}
-		payload := map[string]any{"type": *typ, "meta": *meta, "data": data}
-		plain, _ := json.Marshal(payload)
+// cmdEdit replaces an item's data with a file.
+func cmdEdit(args []string, addr, caPath string, insecure bool) {
+	ctx, cancel := withTimeout()
+	defer cancel()
 
-		dek, err := loadDEK()
-		if err != nil {
-			fail(errors.New("no DEK"))
-		}
-		userID, err := loadUserID()
-		if err != nil {
-			fail(err)
-		}
+	fs := flag.NewFlagSet("edit", flag.ExitOnError)
+	id := fs.String("id", "", "item id (uuid)")
+	base := fs.Int64("base", -1, "base version")
+	typ := fs.String("type", "text", "item type")
+	meta := fs.String("meta", "", "meta JSON/string")
+	dataFile := fs.String("file", "", "data file ('-'=stdin)")
+	dryRun := dryRunFlag(fs)
+	parseFlags(fs, args)
+	if *id == "" || *base < 0 || *dataFile == "" {
+		fmt.Fprintln(os.Stderr, tr("need -id -base -file"))
+		os.Exit(1)
+	}
 
-		aadUser := []byte(userID)
-		aadItem := []byte(*id)
-		ver := *base + 1
+	data, err := readAll(*dataFile)
+	if err != nil {
+		fail(err)
+	}
+	payload := map[string]any{"type": *typ, "meta": *meta, "data": data}
+	plain, _ := json.Marshal(payload)
 
-		key, err := clientcrypto.DeriveItemKey(dek, aadItem)
-		if err != nil {
-			fail(err)
-		}
-		blob, err := clientcrypto.EncryptBlob(key, aadUser, aadItem, ver, plain)
-		if err != nil {
-			fail(err)
-		}
+	dek, err := loadDEK()
+	if err != nil {
+		fail(errNoDEK)
+	}
+	userID, err := loadUserID()
+	if err != nil {
+		fail(err)
+	}
 
-		eb := &pb.EncryptedBlob{}
-		eb.SetCiphertext(blob)
+	aadUser := []byte(userID)
+	aadItem := []byte(*id)
+	ver := *base + 1
 
-		ui := &pb.UpsertItem{}
-		ui.SetId(*id)
-		ui.SetBaseVer(*base)
-		ui.SetBlobEnc(eb)
-		req := &pb.UpsertItemsRequest{}
-		req.SetItems([]*pb.UpsertItem{
-			ui,
-		})
+	key, err := clientcrypto.DeriveItemKey(dek, aadItem)
+	if err != nil {
+		fail(err)
+	}
+	blob, err := clientcrypto.EncryptBlobWith(blobCipher, key, aadUser, aadItem, ver, plain)
+	if err != nil {
+		fail(err)
+	}
 
-		out, err := cli.UpsertItems(ctx, req)
-		if err != nil {
-			fail(err)
-		}
-		printJSON(out.GetResults())
-
-	case "rm":
-		fs := flag.NewFlagSet("rm", flag.ExitOnError)
-		id := fs.String("id", "", "item id (uuid)")
-		base := fs.Int64("base", -1, "base version")
-		_ = fs.Parse(flag.Args()[1:])
-		if *id == "" || *base < 0 {
-			fmt.Fprintln(os.Stderr, "need -id and -base")
-			os.Exit(1)
-		}
+	if *dryRun {
+		printDryRun(plannedUpserts([]pendingUpsert{{ID: *id, BaseVer: *base, Blob: blob}}))
+		return
+	}
 
-		token, err := loadToken()
-		if err != nil {
-			fail(err)
-		}
-		cc, cli, err := dial(ctx, *addr, *caPath, *insecure, token)
-		if err != nil {
-			fail(err)
+	token, err := loadToken()
+	if err != nil {
+		fail(err)
+	}
+	ccConn, cli, err := dial(ctx, addr, caPath, insecure, token)
+	if err != nil {
+		fail(err)
+	}
+	defer ccConn.Close()
+
+	eb := &pb.EncryptedBlob{}
+	eb.SetCiphertext(blob)
+
+	ui := &pb.UpsertItem{}
+	ui.SetId(*id)
+	ui.SetBaseVer(*base)
+	ui.SetBlobEnc(eb)
+	ui.SetHints(itemHints(blob))
+	req := &pb.UpsertItemsRequest{}
+	req.SetItems([]*pb.UpsertItem{
+		ui,
+	})
+
+	out, err := cli.UpsertItems(ctx, req)
+	if err != nil {
+		fail(err)
+	}
+	showWarnings(out.GetWarnings())
+	printJSON(out.GetResults())
+}
+
+// cmdRm deletes one item, or several all-or-nothing.
+func cmdRm(args []string, addr, caPath string, insecure bool) {
+	ctx, cancel := withTimeout()
+	defer cancel()
+
+	fs := flag.NewFlagSet("rm", flag.ExitOnError)
+	id := fs.String("id", "", "item id (uuid)")
+	base := fs.Int64("base", -1, "base version")
+	dryRun := fs.Bool("dry-run", false, "check the arguments, print what would be deleted, delete nothing")
+	parseFlags(fs, args)
+	batch := fs.NArg() > 0
+	if batch == (*id != "") || (!batch && *base < 0) {
+		fmt.Fprintln(os.Stderr, tr("need -id -base or <uuid>:<ver>"))
+		os.Exit(1)
+	}
+	refs, err := parseItemRefs(fs.Args())
+	if err != nil {
+		fail(err)
+	}
+	if *dryRun {
+		if !batch {
+			refs, err = parseItemRefs([]string{fmt.Sprintf("%s:%d", *id, *base)})
+			if err != nil {
+				fail(err)
+			}
 		}
-		defer cc.Close()
+		printDryRun(plannedDeletes(refs))
+		return
+	}
 
-		dir := &pb.DeleteItemRequest{}
-		dir.SetId(*id)
-		dir.SetBaseVer(*base)
-		out, err := cli.DeleteItem(ctx, dir)
-		if err != nil {
+	token, err := loadToken()
+	if err != nil {
+		fail(err)
+	}
+	cc, cli, err := dial(ctx, addr, caPath, insecure, token)
+	if err != nil {
+		fail(err)
+	}
+	defer cc.Close()
+
+	if batch {
+		if err := deleteItems(ctx, cli, refs, os.Stdout); err != nil {
 			fail(err)
 		}
-		printJSON(out.GetResult())
-
-	case "add-login":
-		cmdAddLogin(flag.Args()[1:], *addr, *caPath, *insecure)
-	case "add-text":
-		cmdAddText(flag.Args()[1:], *addr, *caPath, *insecure)
-	case "add-card":
-		cmdAddCard(flag.Args()[1:], *addr, *caPath, *insecure)
-	case "add-binary":
-		cmdAddBinary(flag.Args()[1:], *addr, *caPath, *insecure)
-	case "add-otp":
-		cmdAddOTP(flag.Args()[1:], *addr, *caPath, *insecure)
-	case "show":
-		cmdShow(flag.Args()[1:], *addr, *caPath, *insecure)
-	default:
-		usage()
+		return
 	}
+	dir := &pb.DeleteItemRequest{}
+	dir.SetId(*id)
+	dir.SetBaseVer(*base)
+	out, err := cli.DeleteItem(ctx, dir)
+	if err != nil {
+		fail(err)
+	}
+	printJSON(out.GetResult())
 }
 
 // ---- helpers ----
 
+// changeRow is the compact listing of a single change used by list/sync.
+type changeRow struct{ ID, Ver, Deleted, UpdatedAt string }
+
+func changeRows(cs []*pb.Change) []changeRow {
+	rows := []changeRow{}
+	for _, c := range cs {
+		rows = append(rows, changeRow{
+			ID:        c.GetId(),
+			Ver:       fmt.Sprint(c.GetVer()),
+			Deleted:   fmt.Sprint(c.GetDeleted()),
+			UpdatedAt: tsString(c.GetUpdatedAt()),
+		})
+	}
+	return rows
+}
+
+// itemView is the structured form of a decrypted item for -format output.
+type itemView struct {
+	ID        string        `json:"id"`
+	Ver       int64         `json:"ver"`
+	UpdatedAt string        `json:"updated_at"`
+	Type      string        `json:"type"`
+	Meta      any           `json:"meta"`
+	DataSize  int           `json:"data_size"`
+	Fields    []customField `json:"fields,omitempty"`
+	// IBAN of a bank item, masked unless -reveal.
+	IBAN string `json:"iban,omitempty"`
+}
+
 func tsString(ts *timestamppb.Timestamp) string {
 	if ts == nil {
 		return ""
 	}
 	return ts.AsTime().UTC().Format(time.RFC3339)
 }
-
-func fail(err error) {
-	if s, ok := status.FromError(err); ok {
-		fmt.Fprintf(os.Stderr, "rpc error: code=%s msg=%s\n", s.Code(), s.Message())
-		os.Exit(1)
-	}
-	fmt.Fprintln(os.Stderr, err)
-	os.Exit(1)
-}
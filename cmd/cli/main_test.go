@@ -44,14 +44,14 @@ func Test_token_SaveLoad(t *testing.T) {
 		t.Fatalf("expected error when token file missing")
 	}
 	now := time.Now().Add(1 * time.Minute)
-	if err := saveToken("tok", now); err != nil {
+	if err := saveToken("tok", "", now); err != nil {
 		t.Fatalf("saveToken: %v", err)
 	}
 	tok, err := loadToken()
 	if err != nil || tok != "tok" {
 		t.Fatalf("loadToken: tok=%q err=%v", tok, err)
 	}
-	if err := saveToken("tok2", time.Now().Add(-time.Minute)); err != nil {
+	if err := saveToken("tok2", "", time.Now().Add(-time.Minute)); err != nil {
 		t.Fatalf("saveToken expired: %v", err)
 	}
 	if _, err := loadToken(); err == nil {
@@ -172,13 +172,13 @@ func Test_loadTLS_Variants(t *testing.T) {
 	t.Parallel()
 
 	// insecure
-	creds, err := loadTLS("", true)
+	creds, err := loadTLS("", true, nil)
 	if err != nil || creds == nil {
 		t.Fatalf("insecure: %v %v", creds, err)
 	}
 
 	// system default (no caPath)
-	creds, err = loadTLS("", false)
+	creds, err = loadTLS("", false, nil)
 	if err != nil || creds == nil {
 		t.Fatalf("default tls: %v %v", creds, err)
 	}
@@ -186,7 +186,7 @@ func Test_loadTLS_Variants(t *testing.T) {
 	// bad CA file
 	tmp := filepath.Join(t.TempDir(), "bad.pem")
 	_ = os.WriteFile(tmp, []byte("not pem"), 0o600)
-	creds, err = loadTLS(tmp, false)
+	creds, err = loadTLS(tmp, false, nil)
 	if err == nil || creds != nil {
 		t.Fatalf("bad CA should error, got creds=%v err=%v", creds, err)
 	}
@@ -0,0 +1,329 @@
+// cmd/cli/merge.go
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+)
+
+// mergeChunk is a stretch of a three-way merge: either settled lines or a
+// conflict where both sides changed the same base lines differently.
+type mergeChunk struct {
+	Lines    []string // settled result
+	Conflict bool
+	Base     []string
+	Ours     []string
+	Theirs   []string
+	Whole    bool // a single value: one side or the other, never both
+}
+
+// baseMatches maps each base line to its index in other, or -1 if the edit
+// script from base to other deletes it.
+func baseMatches(base, other []string) []int {
+	m := make([]int, len(base))
+	i, j := 0, 0
+	for _, op := range lineDiff(base, other) {
+		switch op.Kind {
+		case ' ':
+			m[i] = j
+			i++
+			j++
+		case '-':
+			m[i] = -1
+			i++
+		case '+':
+			j++
+		}
+	}
+	return m
+}
+
+// merge3 merges two line-based edits of base (diff3). Regions changed on one
+// side only, or identically on both, merge cleanly; the rest are conflicts.
+func merge3(base, ours, theirs []string) []mergeChunk {
+	mo, mt := baseMatches(base, ours), baseMatches(base, theirs)
+	var out []mergeChunk
+	settle := func(lines []string) {
+		if len(lines) == 0 {
+			return
+		}
+		if n := len(out); n > 0 && !out[n-1].Conflict {
+			out[n-1].Lines = append(out[n-1].Lines, lines...)
+			return
+		}
+		out = append(out, mergeChunk{Lines: slices.Clone(lines)})
+	}
+
+	i, o, t := 0, 0, 0
+	for {
+		// lines unchanged on both sides
+		for i < len(base) && mo[i] == o && mt[i] == t {
+			settle(base[i : i+1])
+			i, o, t = i+1, o+1, t+1
+		}
+		if i == len(base) && o == len(ours) && t == len(theirs) {
+			return out
+		}
+		// the next base line both sides kept ends the changed region
+		k := i
+		for k < len(base) && (mo[k] < 0 || mt[k] < 0) {
+			k++
+		}
+		ko, kt := len(ours), len(theirs)
+		if k < len(base) {
+			ko, kt = mo[k], mt[k]
+		}
+		b, x, y := base[i:k], ours[o:ko], theirs[t:kt]
+		switch {
+		case slices.Equal(x, b):
+			settle(y)
+		case slices.Equal(y, b), slices.Equal(x, y):
+			settle(x)
+		default:
+			out = append(out, mergeChunk{Conflict: true, Base: b, Ours: x, Theirs: y})
+		}
+		i, o, t = k, ko, kt
+	}
+}
+
+// conflictResolver picks the lines for one conflicting chunk of field.
+type conflictResolver func(field string, c mergeChunk) ([]string, error)
+
+// mergeText three-way merges multi-line text, calling resolve for conflicts.
+func mergeText(field, base, ours, theirs string, resolve conflictResolver) (string, error) {
+	if ours == theirs || theirs == base {
+		return ours, nil
+	}
+	if ours == base {
+		return theirs, nil
+	}
+	var lines []string
+	for _, c := range merge3(strings.Split(base, "\n"), strings.Split(ours, "\n"), strings.Split(theirs, "\n")) {
+		if !c.Conflict {
+			lines = append(lines, c.Lines...)
+			continue
+		}
+		picked, err := resolve(field, c)
+		if err != nil {
+			return "", err
+		}
+		lines = append(lines, picked...)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// mergeValue three-way merges a single value; a conflict offers both values whole.
+func mergeValue(field string, base, ours, theirs any, resolve conflictResolver) (any, error) {
+	eq := func(a, b any) bool {
+		ja, _ := json.Marshal(a)
+		jb, _ := json.Marshal(b)
+		return string(ja) == string(jb)
+	}
+	switch {
+	case eq(ours, theirs), eq(theirs, base):
+		return ours, nil
+	case eq(ours, base):
+		return theirs, nil
+	}
+	show := func(v any) []string {
+		if s, ok := v.(string); ok {
+			return []string{s}
+		}
+		b, _ := json.Marshal(v)
+		return []string{string(b)}
+	}
+	picked, err := resolve(field, mergeChunk{Conflict: true, Whole: true, Base: show(base), Ours: show(ours), Theirs: show(theirs)})
+	if err != nil {
+		return nil, err
+	}
+	if slices.Equal(picked, show(theirs)) {
+		return theirs, nil
+	}
+	return ours, nil
+}
+
+// textMergeFields are merged line by line; other meta keys as whole values.
+var textMergeFields = map[string]bool{"text": true, "note": true}
+
+// mergeTyped merges a text item edited locally (ours) from base while the
+// server moved on to theirs.
+func mergeTyped(base, ours, theirs typedPayload, resolve conflictResolver) (typedPayload, error) {
+	merged := ours
+	var err error
+	if merged.Meta, err = mergeObject("meta", base.Meta, ours.Meta, theirs.Meta, resolve); err != nil {
+		return typedPayload{}, err
+	}
+	if merged.Data, err = mergeObject("data", base.Data, ours.Data, theirs.Data, resolve); err != nil {
+		return typedPayload{}, err
+	}
+	fields, err := mergeValue("fields", base.Fields, ours.Fields, theirs.Fields, resolve)
+	if err != nil {
+		return typedPayload{}, err
+	}
+	if b, err := json.Marshal(fields); err == nil {
+		merged.Fields = nil
+		_ = json.Unmarshal(b, &merged.Fields)
+	}
+	return merged, nil
+}
+
+// mergeObject merges two edits of a JSON object key by key.
+func mergeObject(name string, base, ours, theirs json.RawMessage, resolve conflictResolver) (json.RawMessage, error) {
+	var b, o, t map[string]any
+	_ = json.Unmarshal(base, &b)
+	_ = json.Unmarshal(ours, &o)
+	_ = json.Unmarshal(theirs, &t)
+	keys := map[string]bool{}
+	for _, m := range []map[string]any{b, o, t} {
+		for k := range m {
+			keys[k] = true
+		}
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	slices.Sort(sorted)
+
+	out := map[string]any{}
+	for _, k := range sorted {
+		field := name + "." + k
+		bv, bok := b[k].(string)
+		ov, ook := o[k].(string)
+		tv, tok := t[k].(string)
+		var v any
+		var err error
+		if textMergeFields[k] && (bok || b[k] == nil) && (ook || o[k] == nil) && (tok || t[k] == nil) {
+			v, err = mergeText(field, bv, ov, tv, resolve)
+		} else {
+			v, err = mergeValue(field, b[k], o[k], t[k], resolve)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if v != nil {
+			out[k] = v
+		}
+	}
+	return json.Marshal(out)
+}
+
+// promptResolver asks on out/in which side of each conflict to keep.
+func promptResolver(in *bufio.Reader, out io.Writer, theirVer int64) conflictResolver {
+	return func(field string, c mergeChunk) ([]string, error) {
+		fmt.Fprintf(out, tr("conflict in %s:\n"), field)
+		fmt.Fprintln(out, "<<<<<<< "+tr("local"))
+		for _, l := range c.Ours {
+			fmt.Fprintln(out, l)
+		}
+		fmt.Fprintln(out, "=======")
+		for _, l := range c.Theirs {
+			fmt.Fprintln(out, l)
+		}
+		fmt.Fprintf(out, ">>>>>>> "+tr("server v%d\n"), theirVer)
+		for try := 0; try < wizardMaxTries; try++ {
+			if c.Whole {
+				fmt.Fprint(out, tr("keep [l]ocal or [s]erver: "))
+			} else {
+				fmt.Fprint(out, tr("keep [l]ocal, [s]erver or [b]oth: "))
+			}
+			s, err := in.ReadString('\n')
+			if err != nil && s == "" {
+				return nil, fmt.Errorf("%w: %s", errInvalidInput, tr("input ended"))
+			}
+			switch strings.TrimSpace(s) {
+			case "l":
+				return c.Ours, nil
+			case "s":
+				return c.Theirs, nil
+			case "b":
+				if !c.Whole {
+					return slices.Concat(c.Ours, c.Theirs), nil
+				}
+			}
+		}
+		return nil, fmt.Errorf("%w: %s", errInvalidInput, tr("no conflict choice"))
+	}
+}
+
+// errUnresolved stops a merge that would need a prompt it cannot show.
+func errUnresolved(field string, _ mergeChunk) ([]string, error) {
+	return nil, fmt.Errorf("%w: %s", errMergeConflict, field)
+}
+
+// mergeBase returns the payload at ver, preferring the syncd cache (the copy
+// the edit started from) and falling back to the server's history.
+func mergeBase(ctx context.Context, cli pb.GophKeeperClient, uid, id string, ver int64) (typedPayload, error) {
+	if c, err := loadCache(); err == nil && c.UserID == uid {
+		for _, ch := range c.Changes {
+			if ch.ID != id || ch.Ver != ver || ch.Blob == nil {
+				continue
+			}
+			pt, err := decryptForItem(id, uid, ver, ch.Blob)
+			if err != nil {
+				break
+			}
+			var obj typedPayload
+			if json.Unmarshal(pt, &obj) == nil {
+				return obj, nil
+			}
+		}
+	}
+	_, obj, err := fetchTypedAt(ctx, cli, id, ver)
+	return obj, err
+}
+
+// mergeAndRetry handles a version conflict on an edit of a text item: it
+// merges our payload with the server's current one against base and uploads
+// the result on top of the server's version.
+func mergeAndRetry(addr, caPath string, insecure bool, token, uid, id string, base int64, ours []byte) (*pb.UpsertItemsResponse, error) {
+	ctx, cancel := withTimeout()
+	defer cancel()
+	conn, cli, err := dial(ctx, addr, caPath, insecure, token)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var mine typedPayload
+	if err := json.Unmarshal(ours, &mine); err != nil {
+		return nil, err
+	}
+	orig, err := mergeBase(ctx, cli, uid, id, base)
+	if err != nil {
+		return nil, fmt.Errorf("merge base v%d: %w", base, err)
+	}
+	cur, theirs, err := fetchTyped(ctx, cli, id)
+	if err != nil {
+		return nil, err
+	}
+	if theirs.Type != mine.Type {
+		return nil, fmt.Errorf("%w: item is now a %s", errMergeConflict, theirs.Type)
+	}
+	resolve := conflictResolver(errUnresolved)
+	if isTerminal(os.Stdin) && !nonInteractive {
+		resolve = promptResolver(bufio.NewReader(os.Stdin), os.Stderr, cur.GetVer())
+	}
+	merged, err := mergeTyped(orig, mine, theirs, resolve)
+	if err != nil {
+		return nil, err
+	}
+	pt, err := json.Marshal(merged)
+	if err != nil {
+		return nil, err
+	}
+	blob, err := encryptForItem(id, uid, cur.GetVer()+1, pt)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(os.Stderr, tr("merged with server v%d\n"), cur.GetVer())
+	return sendOne(ctx, cli, id, cur.GetVer(), blob)
+}
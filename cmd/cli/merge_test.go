@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func Test_mergeText_Clean(t *testing.T) {
+	t.Parallel()
+	base := "a\nb\nc\nd\ne"
+	cases := []struct {
+		name, ours, theirs, want string
+	}{
+		{"disjoint edits", "A\nb\nc\nd\ne", "a\nb\nc\nd\nE", "A\nb\nc\nd\nE"},
+		{"same edit", "a\nB\nc\nd\ne", "a\nB\nc\nd\ne", "a\nB\nc\nd\ne"},
+		{"insert and delete", "a\nb\nx\nc\nd\ne", "a\nb\nc\ne", "a\nb\nx\nc\ne"},
+		{"only theirs", base, "a\nb\nC\nd\ne", "a\nb\nC\nd\ne"},
+		{"appends at both ends", "top\n" + base, base + "\nend", "top\n" + base + "\nend"},
+	}
+	for _, c := range cases {
+		got, err := mergeText("data.text", base, c.ours, c.theirs, errUnresolved)
+		if err != nil || got != c.want {
+			t.Errorf("%s: got %q, %v", c.name, got, err)
+		}
+	}
+}
+
+func Test_mergeText_Conflict(t *testing.T) {
+	t.Parallel()
+	base, ours, theirs := "a\nb\nc", "a\nmine\nc", "a\ntheirs\nc"
+	if _, err := mergeText("data.text", base, ours, theirs, errUnresolved); !errors.Is(err, errMergeConflict) {
+		t.Fatalf("overlapping edits merged: %v", err)
+	}
+	var seen mergeChunk
+	got, err := mergeText("data.text", base, ours, theirs, func(_ string, c mergeChunk) ([]string, error) {
+		seen = c
+		return c.Theirs, nil
+	})
+	if err != nil || got != theirs {
+		t.Fatalf("resolved: %q %v", got, err)
+	}
+	if strings.Join(seen.Base, "") != "b" || strings.Join(seen.Ours, "") != "mine" {
+		t.Fatalf("chunk: %+v", seen)
+	}
+
+	var out strings.Builder
+	got, err = mergeText("data.text", base, ours, theirs, promptResolver(bufio.NewReader(strings.NewReader("x\nb\n")), &out, 7))
+	if err != nil || got != "a\nmine\ntheirs\nc" {
+		t.Fatalf("prompt both: %q %v", got, err)
+	}
+	if !strings.Contains(out.String(), ">>>>>>> server v7") {
+		t.Fatalf("prompt: %s", out.String())
+	}
+}
+
+func Test_mergeTyped(t *testing.T) {
+	t.Parallel()
+	payload := func(meta, data string) typedPayload {
+		return typedPayload{Type: "text", Meta: json.RawMessage(meta), Data: json.RawMessage(data)}
+	}
+	base := payload(`{"title":"Todo","note":"one\ntwo"}`, `{"text":"x\ny\nz"}`)
+	ours := payload(`{"title":"Todo","note":"one\ntwo\nthree"}`, `{"text":"X\ny\nz"}`)
+	theirs := payload(`{"title":"TODO","note":"zero\none\ntwo"}`, `{"text":"x\ny\nZ"}`)
+
+	got, err := mergeTyped(base, ours, theirs, errUnresolved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var meta map[string]string
+	var data map[string]string
+	_ = json.Unmarshal(got.Meta, &meta)
+	_ = json.Unmarshal(got.Data, &data)
+	if meta["title"] != "TODO" || meta["note"] != "zero\none\ntwo\nthree" || data["text"] != "X\ny\nZ" {
+		t.Fatalf("merged: %s %s", got.Meta, got.Data)
+	}
+
+	// a title changed on both sides is one value, not lines to concatenate
+	ours = payload(`{"title":"Mine"}`, `{"text":"x"}`)
+	theirs = payload(`{"title":"Theirs"}`, `{"text":"x"}`)
+	if _, err := mergeTyped(base, ours, theirs, errUnresolved); !errors.Is(err, errMergeConflict) {
+		t.Fatalf("title conflict: %v", err)
+	}
+	got, err = mergeTyped(base, ours, theirs, promptResolver(bufio.NewReader(strings.NewReader("b\ns\n")), io.Discard, 2))
+	_ = json.Unmarshal(got.Meta, &meta)
+	if err != nil || meta["title"] != "Theirs" {
+		t.Fatalf("title pick: %s %v", got.Meta, err)
+	}
+}
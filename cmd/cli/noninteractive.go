@@ -0,0 +1,58 @@
+// cmd/cli/noninteractive.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// nonInteractive (-non-interactive) makes commands that would ask on the
+// terminal fail instead, so cron jobs and CI get an error and an exit code
+// rather than a hung prompt.
+var nonInteractive bool
+
+// needInput fails fast when what needs an answer that cannot be asked for.
+func needInput(what string) error {
+	if !nonInteractive {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", errInvalidInput, tr("%s needs answers on the terminal; -non-interactive is set", what))
+}
+
+// envName is the variable that stands in for a flag: GK_, then the command
+// and flag names upper-cased with spaces and dashes as underscores.
+func envName(cmd, name string) string {
+	s := strings.TrimPrefix(cmd+"_"+name, "_")
+	return "GK_" + strings.ToUpper(strings.NewReplacer("-", "_", " ", "_").Replace(s))
+}
+
+// envFlags sets each flag of fs not given on the command line from its
+// environment variable, if set: -cacert from GK_CACERT, and for commands
+// login -p from GK_LOGIN_P. cmd is "" for the global flags.
+func envFlags(fs *flag.FlagSet, cmd string) {
+	given := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { given[f.Name] = true })
+	fs.VisitAll(func(f *flag.Flag) {
+		if given[f.Name] {
+			return
+		}
+		env := envName(cmd, f.Name)
+		v, ok := os.LookupEnv(env)
+		if !ok {
+			return
+		}
+		if err := fs.Set(f.Name, v); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", env, err)
+			os.Exit(exitUsage)
+		}
+	})
+}
+
+// parseFlags parses a command's flags, then fills the rest from the
+// environment.
+func parseFlags(fs *flag.FlagSet, args []string) {
+	_ = fs.Parse(args)
+	envFlags(fs, fs.Name())
+}
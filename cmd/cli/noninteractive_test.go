@@ -0,0 +1,48 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"testing"
+)
+
+func Test_envName(t *testing.T) {
+	for _, c := range []struct{ cmd, name, want string }{
+		{"", "cacert", "GK_CACERT"},
+		{"", "non-interactive", "GK_NON_INTERACTIVE"},
+		{"login", "p", "GK_LOGIN_P"},
+		{"template apply", "name", "GK_TEMPLATE_APPLY_NAME"},
+		{"admin-ip", "ip-hash", "GK_ADMIN_IP_IP_HASH"},
+	} {
+		if got := envName(c.cmd, c.name); got != c.want {
+			t.Errorf("envName(%q, %q) = %q, want %q", c.cmd, c.name, got, c.want)
+		}
+	}
+}
+
+func Test_parseFlags_env(t *testing.T) {
+	t.Setenv("GK_LOGIN_U", "env-user")
+	t.Setenv("GK_LOGIN_P", "env-pass")
+	t.Setenv("GK_LOGIN_LABEL", "ci")
+	fs := flag.NewFlagSet("login", flag.ContinueOnError)
+	u := fs.String("u", "", "")
+	p := fs.String("p", "", "")
+	label := fs.String("label", "host", "")
+	other := fs.Bool("x", false, "")
+	parseFlags(fs, []string{"-u", "cli-user"})
+	if *u != "cli-user" || *p != "env-pass" || *label != "ci" || *other {
+		t.Fatalf("u=%q p=%q label=%q x=%v", *u, *p, *label, *other)
+	}
+}
+
+func Test_needInput(t *testing.T) {
+	defer func(v bool) { nonInteractive = v }(nonInteractive)
+	nonInteractive = false
+	if err := needInput("dedupe"); err != nil {
+		t.Fatal(err)
+	}
+	nonInteractive = true
+	if err := needInput("dedupe"); !errors.Is(err, errInvalidInput) {
+		t.Fatalf("non-interactive: %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+// cmd/cli/open.go
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// loginFields is the decoded content of a login item.
+type loginFields struct {
+	Title    string `json:"title"`
+	URL      string `json:"url"`
+	Username string `json:"username"`
+	Note     string `json:"note"`
+	Password string `json:"-"`
+}
+
+// parseLogin decodes meta/data of a login payload.
+func parseLogin(obj typedPayload) (loginFields, error) {
+	if obj.Type != "login" {
+		return loginFields{}, fmt.Errorf("not a login item (type=%s)", obj.Type)
+	}
+	var lf loginFields
+	if err := json.Unmarshal(obj.Meta, &lf); err != nil {
+		return loginFields{}, err
+	}
+	var data struct {
+		Password string `json:"password"`
+	}
+	if err := json.Unmarshal(obj.Data, &data); err != nil {
+		return loginFields{}, err
+	}
+	lf.Password = data.Password
+	return lf, nil
+}
+
+// cmdOpen opens a login item's URL in the default browser, optionally copying its password.
+func cmdOpen(args []string, addr, caPath string, insecure bool) {
+	fs := flag.NewFlagSet("open", flag.ExitOnError)
+	id := fs.String("id", "", "item id (uuid)")
+	title := fs.String("title", "", "login title (alternative to -id)")
+	cp := fs.Bool("copy", false, "copy the password to the clipboard")
+	parseFlags(fs, args)
+	if (*id == "") == (*title == "") {
+		fmt.Fprintln(os.Stderr, tr("need exactly one of -id / -title"))
+		os.Exit(exitUsage)
+	}
+
+	token, err := loadToken()
+	if err != nil {
+		fail(err)
+	}
+	ctx, cancel := withTimeout()
+	defer cancel()
+	conn, cli, err := dial(ctx, addr, caPath, insecure, token)
+	if err != nil {
+		fail(err)
+	}
+	defer conn.Close()
+
+	if *id == "" {
+		if *id, err = findByTitle(ctx, cli, *title, "login"); err != nil {
+			fail(err)
+		}
+	}
+	_, obj, err := fetchTyped(ctx, cli, *id)
+	if err != nil {
+		fail(err)
+	}
+	lf, err := parseLogin(obj)
+	if err != nil {
+		fail(err)
+	}
+	if lf.URL == "" {
+		fail(fmt.Errorf("login %s has no url", *id))
+	}
+	if err := openURL(lf.URL); err != nil {
+		fail(err)
+	}
+	if *cp {
+		if err := copyToClipboard(lf.Password); err != nil {
+			fail(err)
+		}
+		fmt.Fprintln(os.Stderr, tr("password copied to clipboard"))
+	}
+	fmt.Println(lf.URL)
+}
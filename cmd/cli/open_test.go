@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func Test_parseLogin(t *testing.T) {
+	t.Parallel()
+	meta, _ := json.Marshal(map[string]any{"title": "GitHub", "url": "https://github.com", "username": "me"})
+	data, _ := json.Marshal(map[string]any{"password": "s3cret"})
+
+	lf, err := parseLogin(typedPayload{Type: "login", Meta: meta, Data: data})
+	if err != nil {
+		t.Fatalf("parseLogin: %v", err)
+	}
+	if lf.URL != "https://github.com" || lf.Username != "me" || lf.Password != "s3cret" {
+		t.Fatalf("unexpected: %+v", lf)
+	}
+	if _, err := parseLogin(typedPayload{Type: "card"}); err == nil {
+		t.Fatalf("want error on non-login")
+	}
+}
+
+func Test_checkWebURL(t *testing.T) {
+	t.Parallel()
+	for _, ok := range []string{"https://github.com", "http://localhost:8080/x"} {
+		if err := checkWebURL(ok); err != nil {
+			t.Fatalf("%s: %v", ok, err)
+		}
+	}
+	for _, bad := range []string{"file:///etc/passwd", "javascript:alert(1)", "github.com", "https://"} {
+		if err := checkWebURL(bad); err == nil {
+			t.Fatalf("%s: want error", bad)
+		}
+	}
+}
+
+func Test_browserAndClipboardCommands(t *testing.T) {
+	t.Parallel()
+	if name, args := browserCommand("linux", "https://x"); name != "xdg-open" || args[0] != "https://x" {
+		t.Fatalf("linux: %s %v", name, args)
+	}
+	if name, _ := browserCommand("darwin", "https://x"); name != "open" {
+		t.Fatalf("darwin: %s", name)
+	}
+	if name, args := browserCommand("windows", "https://x"); name != "rundll32" || len(args) != 2 {
+		t.Fatalf("windows: %s %v", name, args)
+	}
+	if c := clipboardCommands("darwin"); c[0][0] != "pbcopy" {
+		t.Fatalf("darwin clipboard: %v", c)
+	}
+	if c := clipboardCommands("linux"); len(c) < 2 {
+		t.Fatalf("linux clipboard candidates: %v", c)
+	}
+}
+
+func Test_metaTitle(t *testing.T) {
+	t.Parallel()
+	if metaTitle(typedPayload{Meta: json.RawMessage(`{"title":"X"}`)}) != "X" {
+		t.Fatalf("title")
+	}
+	if metaTitle(typedPayload{Meta: json.RawMessage(`"str"`)}) != "" {
+		t.Fatalf("non-object meta should yield empty title")
+	}
+}
@@ -0,0 +1,257 @@
+// cmd/cli/pin.go
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// errPinMismatch: the server's key is none of those pinned for it.
+var errPinMismatch = errors.New("server key does not match the pinned key")
+
+// pinFlag holds the -pin-sha256 keys; when set they replace the ones saved
+// for the server.
+var pinFlag []string
+
+func pinsPath() string { return filepath.Join(cfgDir(), "pins.json") }
+
+// parsePins checks a comma-separated -pin-sha256 value: each entry is the
+// base64 SHA-256 of a DER SubjectPublicKeyInfo.
+func parsePins(v string) ([]string, error) {
+	pins := splitColumns(v)
+	for _, p := range pins {
+		if b, err := base64.StdEncoding.DecodeString(p); err != nil || len(b) != sha256.Size {
+			return nil, fmt.Errorf("%q is not a base64 SHA-256", p)
+		}
+	}
+	return pins, nil
+}
+
+// spkiPin is the pin of a certificate's public key.
+func spkiPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// loadPins reads the profile's pins by server address; a missing file is
+// no pins.
+func loadPins() (map[string][]string, error) {
+	pins := map[string][]string{}
+	b, err := readProfileFile(pinsPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return pins, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &pins); err != nil {
+		return nil, fmt.Errorf("%s: %w", pinsPath(), err)
+	}
+	return pins, nil
+}
+
+// savePins makes pins the keys pinned for addr.
+func savePins(addr string, pins []string) error {
+	all, err := loadPins()
+	if err != nil {
+		return err
+	}
+	all[addr] = pins
+	b, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeProfileFile(pinsPath(), b)
+}
+
+// pinsFor returns the keys a connection to addr must present: -pin-sha256
+// if given, else those saved for addr.
+func pinsFor(addr string) ([]string, error) {
+	if len(pinFlag) > 0 {
+		return pinFlag, nil
+	}
+	all, err := loadPins()
+	if err != nil {
+		return nil, err
+	}
+	return all[addr], nil
+}
+
+// tofu (-tofu) trusts the key a server presents on the first connection
+// to its address and pins it; CA checks are skipped, the pins replace them.
+var tofu bool
+
+// expiryWarning is how long before its certificate expires a pinned server
+// is reported, so the user can pin the next key in time.
+const expiryWarning = 30 * 24 * time.Hour
+
+// warnOnce keeps one process (syncd runs for days) from repeating a warning.
+var warnOnce = map[string]*sync.Once{"mismatch": {}, "expiry": {}, "tofu": {}}
+
+func warnOnceTo(w io.Writer, kind, msg string) {
+	warnOnce[kind].Do(func() { fmt.Fprint(w, colors(w).warn(msg)) })
+}
+
+// serverCheck returns how dial verifies addr: whether CA checks are
+// skipped and what else the connection must pass, nil for nothing.
+func serverCheck(addr string, insecure bool) (bool, func(tls.ConnectionState) error, error) {
+	pins, err := pinsFor(addr)
+	if err != nil {
+		return false, nil, err
+	}
+	switch {
+	case tofu && len(pins) == 0:
+		return true, trustOnFirstUse(addr), nil
+	case len(pins) > 0:
+		return insecure || tofu, verifyPins(addr, pins), nil
+	}
+	return insecure, nil, nil
+}
+
+// trustOnFirstUse pins the key the server presents.
+func trustOnFirstUse(addr string) func(tls.ConnectionState) error {
+	return func(cs tls.ConnectionState) error {
+		if len(cs.PeerCertificates) == 0 {
+			return errors.New("the server sent no certificate")
+		}
+		pin := spkiPin(cs.PeerCertificates[0])
+		if err := savePins(addr, []string{pin}); err != nil {
+			return err
+		}
+		warnOnceTo(os.Stderr, "tofu", tr("first connection to %s: trusting its key %s from now on; check it against the server's\n", addr, pin))
+		return nil
+	}
+}
+
+// verifyPins accepts a connection when a pinned key is in it. A verified
+// chain counts as a whole, so pinning the CA's or an intermediate's key
+// works; without one (-insecure, -tofu) only the server's own key proves
+// anything, since the rest of what it sends is not signed for.
+func verifyPins(addr string, pins []string) func(tls.ConnectionState) error {
+	return func(cs tls.ConnectionState) error {
+		if len(cs.PeerCertificates) == 0 {
+			return errors.New("the server sent no certificate")
+		}
+		leaf := cs.PeerCertificates[0]
+		certs := []*x509.Certificate{leaf}
+		for _, chain := range cs.VerifiedChains {
+			certs = append(certs, chain...)
+		}
+		for _, c := range certs {
+			if slices.Contains(pins, spkiPin(c)) {
+				if left := time.Until(leaf.NotAfter); left < expiryWarning {
+					warnOnceTo(os.Stderr, "expiry", tr("the certificate of %s expires on %s; if its key changes then, add the new key with gk server keys -add\n",
+						addr, leaf.NotAfter.Local().Format(time.DateOnly)))
+				}
+				return nil
+			}
+		}
+		got := spkiPin(leaf)
+		warnOnceTo(os.Stderr, "mismatch", tr("WARNING: THE KEY OF %s HAS CHANGED.\nIt presented %s, which is not pinned. Someone may be intercepting the connection.\nIf the key was changed on purpose, check the new one and run gk server keys -add %s\n", addr, got, got))
+		return fmt.Errorf("%w: %s presented %s, pinned: %s", errPinMismatch, addr, got, strings.Join(pins, ", "))
+	}
+}
+
+// presentedKey connects to addr and returns the certificate it presents,
+// trusting nothing: it is for comparing with a key learned elsewhere.
+func presentedKey(ctx context.Context, addr string) (*x509.Certificate, error) {
+	var leaf *x509.Certificate
+	creds, err := loadTLS("", true, func(cs tls.ConnectionState) error {
+		if len(cs.PeerCertificates) == 0 {
+			return errors.New("the server sent no certificate")
+		}
+		leaf = cs.PeerCertificates[0]
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	raw, err := (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	conn, _, err := creds.ClientHandshake(ctx, addr, raw)
+	if err != nil {
+		raw.Close()
+		return nil, err
+	}
+	conn.Close()
+	return leaf, nil
+}
+
+// serverKeyRow is one line of gk server keys.
+type serverKeyRow struct {
+	Addr      string    `json:"addr"`
+	Pin       string    `json:"pin"`
+	Presented bool      `json:"presented,omitempty"` // the key the server has now (-fetch)
+	Pinned    bool      `json:"pinned"`
+	Expires   time.Time `json:"expires,omitzero"` // of the presented certificate
+}
+
+// cmdServerKeys lists, adds and removes the keys pinned for -addr, and with
+// -fetch shows the one the server presents now. Adding the next key before
+// a planned rotation lets both work until the old one is removed.
+func cmdServerKeys(args []string, addr, _ string, _ bool) {
+	fs := flag.NewFlagSet("server-keys", flag.ExitOnError)
+	add := fs.String("add", "", "comma-separated keys to pin as well")
+	rm := fs.String("rm", "", "comma-separated keys to unpin")
+	fetch := fs.Bool("fetch", false, "also show the key the server presents now")
+	parseFlags(fs, args)
+
+	all, err := loadPins()
+	if err != nil {
+		fail(err)
+	}
+	pins := all[addr]
+	if *add != "" || *rm != "" {
+		more, err := parsePins(*add)
+		if err != nil {
+			fail(fmt.Errorf("%w: %w", errInvalidInput, err))
+		}
+		for _, p := range more {
+			if !slices.Contains(pins, p) {
+				pins = append(pins, p)
+			}
+		}
+		drop := splitColumns(*rm)
+		pins = slices.DeleteFunc(pins, func(p string) bool { return slices.Contains(drop, p) })
+		if err := savePins(addr, pins); err != nil {
+			fail(err)
+		}
+	}
+
+	rows := make([]serverKeyRow, 0, len(pins)+1)
+	for _, p := range pins {
+		rows = append(rows, serverKeyRow{Addr: addr, Pin: p, Pinned: true})
+	}
+	if *fetch {
+		ctx, cancel := withTimeout()
+		defer cancel()
+		leaf, err := presentedKey(ctx, addr)
+		if err != nil {
+			fail(err)
+		}
+		pin := spkiPin(leaf)
+		if i := slices.Index(pins, pin); i >= 0 {
+			rows[i].Presented, rows[i].Expires = true, leaf.NotAfter
+		} else {
+			rows = append(rows, serverKeyRow{Addr: addr, Pin: pin, Presented: true, Expires: leaf.NotAfter})
+		}
+	}
+	emit(rows, func() { printJSON(rows) })
+}
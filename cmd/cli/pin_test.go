@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testCert is a self-signed certificate for localhost.
+func testCert(t *testing.T) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+// handshake runs a TLS handshake with a server presenting cert.
+func handshake(t *testing.T, cert tls.Certificate, insecure bool, verify func(tls.ConnectionState) error) error {
+	t.Helper()
+	creds, err := loadTLS("", insecure, verify)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, server := net.Pipe()
+	defer client.Close()
+	go func() {
+		defer server.Close()
+		_ = tls.Server(server, &tls.Config{Certificates: []tls.Certificate{cert}, NextProtos: []string{"h2"}}).Handshake()
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, _, err := creds.ClientHandshake(ctx, "localhost", client)
+	if err == nil {
+		conn.Close()
+	}
+	return err
+}
+
+func Test_loadTLS_Pins(t *testing.T) {
+	t.Parallel()
+	cert, other := testCert(t), testCert(t)
+
+	if err := handshake(t, cert, true, verifyPins("localhost", []string{spkiPin(other.Leaf), spkiPin(cert.Leaf)})); err != nil {
+		t.Fatalf("pinned key: %v", err)
+	}
+	if err := handshake(t, cert, true, verifyPins("localhost", []string{spkiPin(other.Leaf)})); !errors.Is(err, errPinMismatch) {
+		t.Fatalf("other key: %v", err)
+	}
+	if err := handshake(t, cert, false, verifyPins("localhost", []string{spkiPin(cert.Leaf)})); err == nil {
+		t.Fatal("a pin let an untrusted certificate through the CA check")
+	}
+}
+
+func Test_serverCheck_TOFU(t *testing.T) {
+	_ = withTmpConfig(t)
+	t.Cleanup(func() { tofu = false })
+	cert, other := testCert(t), testCert(t)
+	const addr = "home.lan:8443"
+
+	connect := func(c tls.Certificate) error {
+		insecure, verify, err := serverCheck(addr, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return handshake(t, c, insecure, verify)
+	}
+	if err := connect(cert); err == nil {
+		t.Fatal("self-signed certificate accepted without -tofu")
+	}
+	tofu = true
+	if err := connect(cert); err != nil {
+		t.Fatalf("first use: %v", err)
+	}
+	if pins, _ := pinsFor(addr); !slices.Equal(pins, []string{spkiPin(cert.Leaf)}) {
+		t.Fatalf("pinned %v", pins)
+	}
+	if err := connect(cert); err != nil {
+		t.Fatalf("second use: %v", err)
+	}
+	if err := connect(other); !errors.Is(err, errPinMismatch) {
+		t.Fatalf("changed key: %v", err)
+	}
+	// a planned rotation: pin the next key, then the server switches
+	if err := savePins(addr, []string{spkiPin(cert.Leaf), spkiPin(other.Leaf)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := connect(other); err != nil {
+		t.Fatalf("rotated key: %v", err)
+	}
+}
+
+func Test_cmdServerKeys(t *testing.T) {
+	_ = withTmpConfig(t)
+	cert := testCert(t)
+	lis, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}, NextProtos: []string{"h2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { lis.Close() })
+	go func() {
+		for {
+			c, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			_ = c.(*tls.Conn).Handshake()
+			c.Close()
+		}
+	}()
+	addr := lis.Addr().String()
+	next := spkiPin(testCert(t).Leaf)
+
+	var rows []serverKeyRow
+	out := stdoutOf(t, func() { cmdServerKeys([]string{"-fetch", "-add", next}, addr, "", false) })
+	if err := json.Unmarshal([]byte(out), &rows); err != nil {
+		t.Fatalf("%q: %v", out, err)
+	}
+	if len(rows) != 2 || !rows[0].Pinned || rows[0].Pin != next || rows[1].Pinned || !rows[1].Presented || rows[1].Pin != spkiPin(cert.Leaf) {
+		t.Fatalf("keys: %+v", rows)
+	}
+	out = stdoutOf(t, func() { cmdServerKeys([]string{"-rm", next}, addr, "", false) })
+	if strings.TrimSpace(out) != "[]" {
+		t.Fatalf("after -rm: %s", out)
+	}
+}
+
+func Test_pins_Profile(t *testing.T) {
+	_ = withTmpConfig(t)
+	t.Cleanup(func() { pinFlag = nil })
+
+	if _, err := parsePins("abc"); err == nil {
+		t.Fatal("short pin accepted")
+	}
+	a, b := spkiPin(testCert(t).Leaf), spkiPin(testCert(t).Leaf)
+	pins, err := parsePins(a + ", " + b)
+	if err != nil || len(pins) != 2 {
+		t.Fatalf("parse: %v %v", pins, err)
+	}
+	if got, err := pinsFor("gk.test:8443"); err != nil || got != nil {
+		t.Fatalf("nothing saved: %v %v", got, err)
+	}
+	if err := savePins("gk.test:8443", pins); err != nil {
+		t.Fatal(err)
+	}
+	if err := savePins("other:8443", []string{b}); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := pinsFor("gk.test:8443"); !slices.Equal(got, pins) {
+		t.Fatalf("saved pins: %v", got)
+	}
+	pinFlag = []string{b}
+	if got, _ := pinsFor("gk.test:8443"); !slices.Equal(got, pinFlag) {
+		t.Fatalf("flag does not win: %v", got)
+	}
+}
@@ -0,0 +1,319 @@
+// cmd/cli/profile.go
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/crypto/chacha20poly1305"
+
+	"github.com/and161185/goph-keeper/internal/crypto/clientcrypto"
+)
+
+// profileFiles are the files in cfgDir that hold account data; an encrypted
+// profile keeps them sealed. Locks, temp files and the syncd socket hold
+// nothing worth sealing.
+var profileFiles = []string{"token.json", "dek.bin", "user_id", "cache.json", "sync_filter.json", "pins.json", "sync_cursor.json", "device_id"}
+
+// sealedMagic starts a sealed profile file: magic || nonce || ciphertext,
+// with the magic and the file's name as AAD so files cannot be swapped.
+var sealedMagic = []byte("GKP1")
+
+// keychainService names the profile key's entry in the OS keychain; the
+// account is the profile directory, so profiles do not share keys.
+const keychainService = "gophkeeper"
+
+// errNoProfileKey: the keychain has no key for this profile.
+var errNoProfileKey = errors.New("no profile key in the keychain")
+
+// keychain keeps profile keys outside the profile directory. osKeychain is
+// the platform's; tests swap in their own.
+type keychain interface {
+	get(account string) ([]byte, error)
+	set(account string, key []byte) error
+	remove(account string) error
+}
+
+var profileKeychain keychain = osKeychain{}
+
+// profileKey is the key of the profile in dir, fetched on first use so that
+// a keychain that asks to be unlocked does so once per command.
+var profileKey struct {
+	sync.Mutex
+	dir string
+	key []byte
+}
+
+// profileLockPath is locked for the length of a command: shared by those
+// that read the profile, exclusive by those that rewrite several of its
+// files (login, profile encrypt), so no command sees a token from one login
+// next to a DEK from another.
+func profileLockPath() string { return filepath.Join(cfgDir(), "profile.lock") }
+
+// lockMode is how a command holds profileLockPath.
+type lockMode int
+
+const (
+	lockShared lockMode = iota
+	lockExclusive
+	lockNone // long-running commands, which would keep logins waiting
+)
+
+// lockProfile takes the profile lock in mode m.
+func lockProfile(m lockMode) (unlock func(), err error) {
+	switch m {
+	case lockShared:
+		_ = os.MkdirAll(cfgDir(), 0o700)
+		return rlockFile(profileLockPath())
+	case lockExclusive:
+		_ = os.MkdirAll(cfgDir(), 0o700)
+		return lockFile(profileLockPath())
+	}
+	return func() {}, nil
+}
+
+// profileMarkerPath exists while the profile is encrypted.
+func profileMarkerPath() string { return filepath.Join(cfgDir(), "profile.json") }
+
+type profileMarker struct {
+	Encrypted bool `json:"encrypted"`
+}
+
+func profileEncrypted() bool {
+	b, err := os.ReadFile(profileMarkerPath())
+	if err != nil {
+		return false
+	}
+	var m profileMarker
+	return json.Unmarshal(b, &m) == nil && m.Encrypted
+}
+
+func loadProfileKey() ([]byte, error) {
+	profileKey.Lock()
+	defer profileKey.Unlock()
+	dir := cfgDir()
+	if profileKey.key != nil && profileKey.dir == dir {
+		return profileKey.key, nil
+	}
+	key, err := profileKeychain.get(dir)
+	if errors.Is(err, errNoProfileKey) {
+		return nil, fmt.Errorf("%w: the profile in %s is encrypted but the keychain has no key for it", errCrypto, dir)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("profile key: %w", err)
+	}
+	if len(key) != chacha20poly1305.KeySize {
+		return nil, fmt.Errorf("%w: profile key in the keychain has %d bytes", errCrypto, len(key))
+	}
+	profileKey.dir, profileKey.key = dir, key
+	return key, nil
+}
+
+func sealProfile(key []byte, name string, plain []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := clientcrypto.Rand(aead.NonceSize())
+	if err != nil {
+		return nil, err
+	}
+	out := append(bytes.Clone(sealedMagic), nonce...)
+	return aead.Seal(out, nonce, plain, append(bytes.Clone(sealedMagic), name...)), nil
+}
+
+func openProfile(key []byte, name string, sealed []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, err
+	}
+	rest := sealed[len(sealedMagic):]
+	if len(rest) < aead.NonceSize() {
+		return nil, fmt.Errorf("%w: %s: truncated", errCrypto, name)
+	}
+	plain, err := aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], append(bytes.Clone(sealedMagic), name...))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", errCrypto, name, err)
+	}
+	return plain, nil
+}
+
+// readProfileFile reads a file of the profile, opening it if it is sealed.
+func readProfileFile(path string) ([]byte, error) {
+	b, err := os.ReadFile(path)
+	if err != nil || !bytes.HasPrefix(b, sealedMagic) || !profileEncrypted() {
+		return b, err
+	}
+	key, err := loadProfileKey()
+	if err != nil {
+		return nil, err
+	}
+	return openProfile(key, filepath.Base(path), b)
+}
+
+// writeProfileFile replaces a file of the profile in one step, so readers
+// never see it half written, sealing it if the profile is encrypted.
+func writeProfileFile(path string, data []byte) error {
+	_ = os.MkdirAll(cfgDir(), 0o700)
+	if profileEncrypted() {
+		key, err := loadProfileKey()
+		if err != nil {
+			return err
+		}
+		if data, err = sealProfile(key, filepath.Base(path), data); err != nil {
+			return err
+		}
+	}
+	return replaceFile(path, data)
+}
+
+// replaceFile writes data to a temp file beside path and renames it over
+// path.
+func replaceFile(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// readProfile returns the profile's files that exist, by name.
+func readProfile() (map[string][]byte, error) {
+	files := map[string][]byte{}
+	for _, name := range profileFiles {
+		b, err := readProfileFile(filepath.Join(cfgDir(), name))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		files[name] = b
+	}
+	return files, nil
+}
+
+func writeProfile(files map[string][]byte) error {
+	for name, b := range files {
+		if err := writeProfileFile(filepath.Join(cfgDir(), name), b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encryptProfile puts a new key in the keychain and seals the profile's
+// files with it. The key is stored first: a run cut short leaves some files
+// plain, which read as before, and running it again finishes the job.
+func encryptProfile() error {
+	files, err := readProfile()
+	if err != nil {
+		return err
+	}
+	if profileEncrypted() {
+		return writeProfile(files)
+	}
+	key, err := clientcrypto.Rand(chacha20poly1305.KeySize)
+	if err != nil {
+		return err
+	}
+	dir := cfgDir()
+	if err := profileKeychain.set(dir, key); err != nil {
+		return fmt.Errorf("store the profile key: %w", err)
+	}
+	// some keychain tools report success without storing anything
+	if got, err := profileKeychain.get(dir); err != nil || !bytes.Equal(got, key) {
+		return fmt.Errorf("store the profile key: the keychain did not keep it (%v)", err)
+	}
+	_ = os.MkdirAll(dir, 0o700)
+	b, _ := json.Marshal(profileMarker{Encrypted: true})
+	if err := replaceFile(profileMarkerPath(), b); err != nil {
+		return err
+	}
+	return writeProfile(files)
+}
+
+// decryptProfile writes the profile's files back in plain text and drops
+// the key from the keychain.
+func decryptProfile() error {
+	if !profileEncrypted() {
+		return nil
+	}
+	files, err := readProfile()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(profileMarkerPath()); err != nil {
+		return err
+	}
+	if err := writeProfile(files); err != nil {
+		return err
+	}
+	profileKey.Lock()
+	profileKey.key = nil
+	profileKey.Unlock()
+	return profileKeychain.remove(cfgDir())
+}
+
+// profileStatus is printed by gk profile status.
+type profileStatus struct {
+	Dir       string            `json:"dir"`
+	Encrypted bool              `json:"encrypted"`
+	Files     map[string]string `json:"files"` // name → sealed | plain
+}
+
+func currentProfileStatus() profileStatus {
+	st := profileStatus{Dir: cfgDir(), Encrypted: profileEncrypted(), Files: map[string]string{}}
+	for _, name := range profileFiles {
+		b, err := os.ReadFile(filepath.Join(st.Dir, name))
+		if err != nil {
+			continue
+		}
+		st.Files[name] = "plain"
+		if st.Encrypted && bytes.HasPrefix(b, sealedMagic) {
+			st.Files[name] = "sealed"
+		}
+	}
+	return st
+}
+
+// cmdProfile encrypts or decrypts the profile directory, or shows which.
+func cmdProfile(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, tr("usage: profile encrypt|decrypt|status"))
+		os.Exit(exitUsage)
+	}
+	fs := flag.NewFlagSet("profile "+args[0], flag.ExitOnError)
+	parseFlags(fs, args[1:])
+	switch args[0] {
+	case "encrypt":
+		if err := encryptProfile(); err != nil {
+			fail(err)
+		}
+		fmt.Print(tr("profile encrypted; its key is in the keychain\n"))
+	case "decrypt":
+		if err := decryptProfile(); err != nil {
+			fail(err)
+		}
+		fmt.Print(tr("profile decrypted\n"))
+	case "status":
+		printJSON(currentProfileStatus())
+	default:
+		fmt.Fprintln(os.Stderr, tr("usage: profile encrypt|decrypt|status"))
+		os.Exit(exitUsage)
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// memKeychain stands in for the OS keychain.
+type memKeychain map[string][]byte
+
+func (m memKeychain) get(account string) ([]byte, error) {
+	if k, ok := m[account]; ok {
+		return k, nil
+	}
+	return nil, errNoProfileKey
+}
+func (m memKeychain) set(account string, key []byte) error { m[account] = key; return nil }
+func (m memKeychain) remove(account string) error          { delete(m, account); return nil }
+
+func withKeychain(t *testing.T) memKeychain {
+	t.Helper()
+	kc := memKeychain{}
+	old := profileKeychain
+	profileKeychain = kc
+	t.Cleanup(func() { profileKeychain = old })
+	return kc
+}
+
+func Test_profile_EncryptDecrypt(t *testing.T) {
+	dir := withTmpConfig(t)
+	kc := withKeychain(t)
+
+	dek := bytes.Repeat([]byte{7}, 32)
+	if err := saveDEK(dek); err != nil {
+		t.Fatal(err)
+	}
+	if err := saveUserID("u-1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := saveToken("tok", "refresh", time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := encryptProfile(); err != nil {
+		t.Fatal(err)
+	}
+	if len(kc[dir]) != 32 {
+		t.Fatalf("keychain: %v", kc)
+	}
+	for _, name := range []string{"dek.bin", "user_id", "token.json"} {
+		b, _ := os.ReadFile(filepath.Join(dir, name))
+		if !bytes.HasPrefix(b, sealedMagic) || bytes.Contains(b, []byte("u-1")) || bytes.Contains(b, []byte("refresh")) {
+			t.Fatalf("%s not sealed: %q", name, b)
+		}
+	}
+	if st := currentProfileStatus(); !st.Encrypted || st.Files["dek.bin"] != "sealed" {
+		t.Fatalf("status: %+v", st)
+	}
+
+	// reads and new writes go through the keychain's key
+	if got, err := loadDEK(); err != nil || !bytes.Equal(got, dek) {
+		t.Fatalf("loadDEK: %v", err)
+	}
+	if tok, err := loadToken(); err != nil || tok != "tok" {
+		t.Fatalf("loadToken: %q %v", tok, err)
+	}
+	if err := saveUserID("u-2"); err != nil {
+		t.Fatal(err)
+	}
+	if uid, err := loadUserID(); err != nil || uid != "u-2" {
+		t.Fatalf("loadUserID: %q %v", uid, err)
+	}
+
+	// files are bound to their names
+	sealedDEK, _ := os.ReadFile(filepath.Join(dir, "dek.bin"))
+	if err := os.WriteFile(filepath.Join(dir, "user_id"), sealedDEK, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadUserID(); !errors.Is(err, errCrypto) {
+		t.Fatalf("swapped file: %v", err)
+	}
+	if err := saveUserID("u-2"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := decryptProfile(); err != nil {
+		t.Fatal(err)
+	}
+	if len(kc) != 0 || currentProfileStatus().Encrypted {
+		t.Fatalf("key left behind: %v", kc)
+	}
+	if b, _ := os.ReadFile(filepath.Join(dir, "user_id")); string(b) != "u-2" {
+		t.Fatalf("user_id after decrypt: %q", b)
+	}
+	if got, err := loadDEK(); err != nil || !bytes.Equal(got, dek) {
+		t.Fatalf("plain loadDEK: %v", err)
+	}
+}
+
+func Test_profile_keyMissing(t *testing.T) {
+	_ = withTmpConfig(t)
+	kc := withKeychain(t)
+	if err := saveUserID("u-1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := encryptProfile(); err != nil {
+		t.Fatal(err)
+	}
+	clear(kc)
+	profileKey.Lock()
+	profileKey.key = nil
+	profileKey.Unlock()
+	if _, err := loadUserID(); !errors.Is(err, errCrypto) {
+		t.Fatalf("without the key: %v", err)
+	}
+}
@@ -0,0 +1,175 @@
+// cmd/cli/recovery.go
+package main
+
+import (
+	"bytes"
+	"encoding/pem"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/and161185/goph-keeper/internal/crypto/clientcrypto"
+)
+
+// PEM block types of the recovery files.
+const (
+	pemHybridPrivate = "GOPHKEEPER HYBRID PRIVATE KEY"
+	pemHybridPublic  = "GOPHKEEPER HYBRID PUBLIC KEY"
+	pemWrappedDEK    = "GOPHKEEPER WRAPPED DEK"
+)
+
+// readPEM reads the first block of the given type from path.
+func readPEM(path, typ string) (*pem.Block, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		var blk *pem.Block
+		blk, b = pem.Decode(b)
+		if blk == nil {
+			return nil, fmt.Errorf("%w: %s: no %s block", errInvalidInput, path, typ)
+		}
+		if blk.Type == typ {
+			return blk, nil
+		}
+	}
+}
+
+// writePEM creates path (failing if it exists) and writes one block.
+func writePEM(path string, perm os.FileMode, blk *pem.Block) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	if err := pem.Encode(f, blk); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// cmdRecovery manages offline recovery copies of the DEK, wrapped for a
+// hybrid X25519 + ML-KEM-768 key. Nothing here talks to the server.
+func cmdRecovery(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, tr("usage: recovery keygen|wrap|restore ..."))
+		os.Exit(exitUsage)
+	}
+	sub, args := args[0], args[1:]
+	fs := flag.NewFlagSet("recovery "+sub, flag.ExitOnError)
+	var keyPath, pubPath, in, out *string
+	var force *bool
+	switch sub {
+	case "keygen":
+		keyPath = fs.String("key", "", "private key file to create")
+		pubPath = fs.String("pub", "", "public key file to create")
+	case "wrap":
+		pubPath = fs.String("pub", "", "recipient public key file")
+		out = fs.String("out", "", "wrapped DEK file to create")
+	case "restore":
+		keyPath = fs.String("key", "", "recipient private key file")
+		in = fs.String("in", "", "wrapped DEK file")
+		force = fs.Bool("force", false, "replace a different local DEK")
+	default:
+		fmt.Fprintf(os.Stderr, tr("unknown recovery subcommand %q\n"), sub)
+		os.Exit(exitUsage)
+	}
+	parseFlags(fs, args)
+
+	var err error
+	switch sub {
+	case "keygen":
+		err = recoveryKeygen(*keyPath, *pubPath)
+	case "wrap":
+		err = recoveryWrap(*pubPath, *out)
+	case "restore":
+		err = recoveryRestore(*keyPath, *in, *force)
+	}
+	if err != nil {
+		fail(err)
+	}
+}
+
+func recoveryKeygen(keyPath, pubPath string) error {
+	if keyPath == "" || pubPath == "" {
+		return fmt.Errorf("%w: need -key and -pub", errInvalidInput)
+	}
+	k, err := clientcrypto.GenerateHybridKey()
+	if err != nil {
+		return err
+	}
+	if err := writePEM(keyPath, 0o600, &pem.Block{Type: pemHybridPrivate, Bytes: k.Bytes()}); err != nil {
+		return err
+	}
+	if err := writePEM(pubPath, 0o644, &pem.Block{Type: pemHybridPublic, Bytes: k.Public()}); err != nil {
+		return err
+	}
+	fmt.Printf(tr("wrote %s (keep offline) and %s\n"), keyPath, pubPath)
+	return nil
+}
+
+func recoveryWrap(pubPath, out string) error {
+	if pubPath == "" || out == "" {
+		return fmt.Errorf("%w: need -pub and -out", errInvalidInput)
+	}
+	pub, err := readPEM(pubPath, pemHybridPublic)
+	if err != nil {
+		return err
+	}
+	dek, err := loadDEK()
+	if err != nil {
+		return errNoDEK
+	}
+	uid, err := loadUserID()
+	if err != nil {
+		return err
+	}
+	w, err := clientcrypto.WrapDEKForRecipient(pub.Bytes, dek)
+	if err != nil {
+		return err
+	}
+	blk := &pem.Block{Type: pemWrappedDEK, Headers: map[string]string{"User-Id": uid}, Bytes: w}
+	if err := writePEM(out, 0o600, blk); err != nil {
+		return err
+	}
+	fmt.Printf(tr("wrote %s\n"), out)
+	return nil
+}
+
+func recoveryRestore(keyPath, in string, force bool) error {
+	if keyPath == "" || in == "" {
+		return fmt.Errorf("%w: need -key and -in", errInvalidInput)
+	}
+	kb, err := readPEM(keyPath, pemHybridPrivate)
+	if err != nil {
+		return err
+	}
+	k, err := clientcrypto.ParseHybridKey(kb.Bytes)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errCrypto, err)
+	}
+	wb, err := readPEM(in, pemWrappedDEK)
+	if err != nil {
+		return err
+	}
+	dek, err := k.UnwrapDEK(wb.Bytes)
+	if err != nil {
+		return fmt.Errorf("unwrap DEK: %w: %w", errCrypto, err)
+	}
+	uid := wb.Headers["User-Id"]
+	if cur, err := loadUserID(); err == nil && uid != "" && cur != uid && !force {
+		return fmt.Errorf("%w: file is for user %s, logged in as %s (use -force)", errInvalidInput, uid, cur)
+	}
+	if cur, err := loadDEK(); err == nil && !bytes.Equal(cur, dek) && !force {
+		return fmt.Errorf("%w: a different DEK is stored locally (use -force)", errInvalidInput)
+	}
+	if err := saveDEK(dek); err != nil {
+		return err
+	}
+	if uid != "" {
+		_ = saveUserID(uid)
+	}
+	fmt.Println(tr("DEK restored"))
+	return nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	cc "github.com/and161185/goph-keeper/internal/crypto/clientcrypto"
+)
+
+func Test_recovery_RoundTrip(t *testing.T) {
+	_ = withTmpConfig(t)
+	dir := t.TempDir()
+	key, pub, wrapped := filepath.Join(dir, "r.key"), filepath.Join(dir, "r.pub"), filepath.Join(dir, "dek.pem")
+
+	dek, _ := cc.Rand(cc.DEKLen)
+	if err := saveDEK(dek); err != nil {
+		t.Fatal(err)
+	}
+	_ = saveUserID("u1")
+
+	if err := recoveryKeygen(key, pub); err != nil {
+		t.Fatalf("keygen: %v", err)
+	}
+	if err := recoveryKeygen(key, pub); err == nil {
+		t.Fatalf("keygen must not overwrite an existing key")
+	}
+	if err := recoveryWrap(pub, wrapped); err != nil {
+		t.Fatalf("wrap: %v", err)
+	}
+
+	other, _ := cc.Rand(cc.DEKLen)
+	_ = saveDEK(other)
+	if err := recoveryRestore(key, wrapped, false); err == nil {
+		t.Fatalf("restore must not replace a different DEK without -force")
+	}
+	if err := recoveryRestore(key, wrapped, true); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if got, _ := loadDEK(); !bytes.Equal(got, dek) {
+		t.Fatalf("DEK not restored")
+	}
+
+	// the private key is needed: the public file is not enough
+	if err := recoveryRestore(pub, wrapped, true); err == nil {
+		t.Fatalf("restore with a public key must fail")
+	}
+}
@@ -0,0 +1,94 @@
+// cmd/cli/renew.go
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+)
+
+// renewBefore is how much of the access token must be left for commands to
+// use it as is; with less, loadToken renews it first so that long batches
+// do not fail half way.
+var renewBefore = 5 * time.Minute
+
+// renewServer is where loadToken renews tokens; main sets it from the
+// global flags. With addr empty tokens are never renewed.
+var renewServer struct {
+	addr, caPath string
+	insecure     bool
+}
+
+// tokenExpiry reads the expiry of an access token without verifying it,
+// assuming the server's default lifetime if the token has none.
+func tokenExpiry(tok string) time.Time {
+	var claims jwt.RegisteredClaims
+	_, _ = jwt.ParseWithClaims(tok, &claims, func(*jwt.Token) (any, error) { return nil, nil },
+		jwt.WithoutClaimsValidation(),
+	)
+	if claims.ExpiresAt != nil {
+		return claims.ExpiresAt.Time
+	}
+	return time.Now().Add(15 * time.Minute)
+}
+
+// renewToken trades tf's refresh token for new tokens and saves them. gk
+// processes renew one at a time under the token lock, and one that waited
+// uses the tokens the other saved, since its refresh token is spent. If
+// renewal fails tf is returned, and the caller makes do with what is left
+// of its access token.
+func renewToken(tf tokenFile) tokenFile {
+	warn := func(msg string) { fmt.Fprint(os.Stderr, colors(os.Stderr).warn(msg)) }
+	unlock, err := lockFile(tokenLockPath())
+	if err != nil {
+		warn(tr("could not renew the access token: %v\n", err))
+		return tf
+	}
+	defer unlock()
+	if cur, err := readTokenFile(); err == nil && cur.RefreshToken != tf.RefreshToken {
+		return cur
+	}
+
+	ctx, cancel := withTimeout()
+	defer cancel()
+	conn, cli, err := dial(ctx, renewServer.addr, renewServer.caPath, renewServer.insecure, "")
+	if err != nil {
+		warn(tr("could not renew the access token: %v\n", err))
+		return tf
+	}
+	defer conn.Close()
+	req := &pb.RefreshRequest{}
+	req.SetRefreshToken(tf.RefreshToken)
+	resp, err := cli.Refresh(ctx, req)
+	switch status.Code(err) {
+	case codes.OK:
+	case codes.Unauthenticated, codes.FailedPrecondition:
+		// the session was revoked or has expired, or the server no longer
+		// keeps sessions: stop asking until the next login
+		if status.Code(err) == codes.Unauthenticated {
+			warn(tr("your session has ended; log in again\n"))
+		}
+		tf.RefreshToken = ""
+		_ = writeTokenFile(tf)
+		return tf
+	default:
+		warn(tr("could not renew the access token: %v\n", err))
+		return tf
+	}
+
+	next := tokenFile{
+		AccessToken:  resp.GetAccessToken(),
+		ExpiresAt:    tokenExpiry(resp.GetAccessToken()),
+		RefreshToken: resp.GetRefreshToken(),
+	}
+	if err := writeTokenFile(next); err != nil {
+		warn(tr("could not save the renewed token: %v\n", err))
+	}
+	return next
+}
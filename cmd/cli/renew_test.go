@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/pkg/gktest"
+)
+
+// loginForRenewal saves tokens from a fresh login that are due for renewal.
+func loginForRenewal(t *testing.T, srv *gktest.Server) (pb.GophKeeperClient, *pb.LoginResponse) {
+	t.Helper()
+	renewServer.addr = "gk.test:8443"
+	t.Cleanup(func() { renewServer.addr = "" })
+	cli := srv.Client(t)
+	lr := &pb.LoginRequest{}
+	lr.SetUsername("alice")
+	lr.SetPassword("secret")
+	resp, err := cli.Login(context.Background(), lr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := saveToken(resp.GetAccessToken(), resp.GetRefreshToken(), time.Now().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	return cli, resp
+}
+
+func Test_loadToken_renews(t *testing.T) {
+	srv := startServer(t)
+	cli, login := loginForRenewal(t, srv)
+
+	// several gk processes find the token due at once; one renews and the
+	// others pick up its tokens, since the refresh token is good only once
+	const n = 8
+	toks := make([]string, n)
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tok, err := loadToken()
+			if err != nil {
+				t.Error(err)
+			}
+			toks[i] = tok
+		}()
+	}
+	wg.Wait()
+	tf, err := readTokenFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tf.RefreshToken == "" || tf.RefreshToken == login.GetRefreshToken() || time.Until(tf.ExpiresAt) < renewBefore {
+		t.Fatalf("after renewal: %+v", tf)
+	}
+	for i, tok := range toks {
+		if tok != tf.AccessToken {
+			t.Fatalf("process %d got %q, saved %q", i, tok, tf.AccessToken)
+		}
+	}
+	if tok, err := loadToken(); err != nil || tok != tf.AccessToken {
+		t.Fatalf("fresh token renewed again: %v", err)
+	}
+
+	// a revoked session is not retried; its access token serves until it expires
+	sessions, err := cli.ListSessions(gktest.WithToken(context.Background(), tf.AccessToken), &pb.ListSessionsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range sessions.GetSessions() {
+		if s.GetCurrent() {
+			req := &pb.RevokeSessionRequest{}
+			req.SetId(s.GetId())
+			if _, err := cli.RevokeSession(gktest.WithToken(context.Background(), tf.AccessToken), req); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := saveToken(tf.AccessToken, tf.RefreshToken, time.Now().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if tok, err := loadToken(); err != nil || tok != tf.AccessToken {
+		t.Fatalf("revoked session: %q %v", tok, err)
+	}
+	if after, _ := readTokenFile(); after.RefreshToken != "" {
+		t.Fatalf("spent refresh token kept: %+v", after)
+	}
+	req := &pb.RefreshRequest{}
+	req.SetRefreshToken(tf.RefreshToken)
+	if _, err := cli.Refresh(context.Background(), req); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("revoked refresh token: %v", err)
+	}
+}
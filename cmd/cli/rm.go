@@ -0,0 +1,62 @@
+// cmd/cli/rm.go
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/gofrs/uuid/v5"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+)
+
+// parseItemRefs parses rm's "<uuid>:<ver>" arguments.
+func parseItemRefs(args []string) ([]*pb.ItemRef, error) {
+	refs := make([]*pb.ItemRef, 0, len(args))
+	for _, a := range args {
+		id, ver, ok := strings.Cut(a, ":")
+		base, err := strconv.ParseInt(ver, 10, 64)
+		if !ok || err != nil || base < 0 {
+			return nil, fmt.Errorf("%w: %q is not <uuid>:<ver>", errInvalidInput, a)
+		}
+		if _, err := uuid.FromString(id); err != nil {
+			return nil, fmt.Errorf("%w: %q is not <uuid>:<ver>", errInvalidInput, a)
+		}
+		r := &pb.ItemRef{}
+		r.SetId(id)
+		r.SetBaseVer(base)
+		refs = append(refs, r)
+	}
+	return refs, nil
+}
+
+// deleteItems deletes refs with one DeleteItems call, which applies all of
+// them or none, and writes a line per item to w. A batch the server rolled
+// back is errBatchRejected.
+func deleteItems(ctx context.Context, cli pb.GophKeeperClient, refs []*pb.ItemRef, w io.Writer) error {
+	req := &pb.DeleteItemsRequest{}
+	req.SetItems(refs)
+	out, err := cli.DeleteItems(ctx, req)
+	if err != nil {
+		return err
+	}
+	for _, r := range out.GetResults() {
+		switch r.GetStatus() {
+		case pb.ItemStatus_ITEM_STATUS_OK:
+			fmt.Fprint(w, tr("%s: deleted, ver %d\n", r.GetId(), r.GetVer()))
+		case pb.ItemStatus_ITEM_STATUS_VERSION_CONFLICT:
+			fmt.Fprint(w, tr("%s: version conflict, current ver %d\n", r.GetId(), r.GetVer()))
+		case pb.ItemStatus_ITEM_STATUS_NOT_FOUND:
+			fmt.Fprint(w, tr("%s: not found\n", r.GetId()))
+		default:
+			fmt.Fprint(w, tr("%s: kept, the batch was rolled back\n", r.GetId()))
+		}
+	}
+	if !out.GetApplied() {
+		return fmt.Errorf("%w: no item was deleted", errBatchRejected)
+	}
+	return nil
+}
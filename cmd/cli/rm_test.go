@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gofrs/uuid/v5"
+
+	"github.com/and161185/goph-keeper/pkg/gktest"
+)
+
+func Test_parseItemRefs(t *testing.T) {
+	id := uuid.Must(uuid.NewV7()).String()
+	refs, err := parseItemRefs([]string{id + ":3"})
+	if err != nil || len(refs) != 1 || refs[0].GetId() != id || refs[0].GetBaseVer() != 3 {
+		t.Fatalf("parse: %v %v", refs, err)
+	}
+	for _, bad := range []string{id, id + ":", id + ":-1", "x:1"} {
+		if _, err := parseItemRefs([]string{bad}); !errors.Is(err, errInvalidInput) {
+			t.Fatalf("%q: want errInvalidInput, got %v", bad, err)
+		}
+	}
+}
+
+func Test_deleteItems(t *testing.T) {
+	srv := gktest.Start(t)
+	cli := srv.Client(t)
+	_, token := srv.NewUser(t, "alice", "secret")
+	ctx := gktest.WithToken(context.Background(), token)
+
+	a, b := uuid.Must(uuid.NewV7()).String(), uuid.Must(uuid.NewV7()).String()
+	for _, id := range []string{a, b} {
+		if _, err := sendOne(ctx, cli, id, 0, []byte("blob")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	refs, _ := parseItemRefs([]string{a + ":1", b + ":2"})
+	var out bytes.Buffer
+	if err := deleteItems(ctx, cli, refs, &out); !errors.Is(err, errBatchRejected) {
+		t.Fatalf("want errBatchRejected, got %v", err)
+	}
+	if !strings.Contains(out.String(), b+": version conflict, current ver 1") || !strings.Contains(out.String(), a+": kept") {
+		t.Fatalf("output:\n%s", out.String())
+	}
+
+	out.Reset()
+	refs, _ = parseItemRefs([]string{a + ":1", b + ":1"})
+	if err := deleteItems(ctx, cli, refs, &out); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(out.String(), "deleted, ver 2") != 2 {
+		t.Fatalf("output:\n%s", out.String())
+	}
+}
@@ -0,0 +1,242 @@
+// cmd/cli/schema.go
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+)
+
+// fieldRule is one known key of a payload's meta or data.
+type fieldRule struct {
+	name     string
+	required bool
+	// valid checks a present value as decoded from JSON; nil takes any
+	// string. want says what valid expects.
+	valid func(any) bool
+	want  string
+}
+
+// payloadType is the schema of one payload type. meta is open: it also
+// holds tags, folders and whatever bulk-add brings, so only its known keys
+// are checked. data is closed unless rawData: a key the schema does not
+// name is refused, since a typo there would lose a secret.
+type payloadType struct {
+	// version is the newest schema of the type this client writes and
+	// fully reads; payloads carry it as "schema".
+	version int
+	meta    []fieldRule
+	data    []fieldRule
+	// rawData: data is not an object of fields (file bytes, a template
+	// layout) and is left to the commands that use it.
+	rawData bool
+}
+
+var reCVC = regexp.MustCompile(`^\d{3,4}$`)
+
+func isBool(v any) bool { _, ok := v.(bool); return ok }
+
+func isString(v any) bool { _, ok := v.(string); return ok }
+
+func strList(v any) bool {
+	l, ok := v.([]any)
+	return ok && !slices.ContainsFunc(l, func(e any) bool { return !isString(e) })
+}
+
+// str is a string rule that also passes check.
+func str(check func(string) bool) func(any) bool {
+	return func(v any) bool { s, ok := v.(string); return ok && check(s) }
+}
+
+// num is a whole-number rule that also passes check.
+func num(check func(int) bool) func(any) bool {
+	return func(v any) bool {
+		f, ok := v.(float64)
+		return ok && f == float64(int(f)) && check(int(f))
+	}
+}
+
+// commonMeta are the meta keys every type may carry.
+var commonMeta = []fieldRule{
+	{name: "devices", valid: strList, want: "a list of device ids"},
+}
+
+// payloadTypes is the schema registry: every payload type gk writes. A new
+// field bumps its type's version, so older clients know to read it
+// tolerantly instead of refusing it.
+var payloadTypes = map[string]payloadType{
+	"login": {
+		version: 1,
+		meta:    []fieldRule{{name: "title"}, {name: "url"}, {name: "username"}, {name: "note"}, {name: "password_changed_at"}},
+		data:    []fieldRule{{name: "password", required: true}},
+	},
+	"text": {
+		version: 1,
+		meta:    []fieldRule{{name: "title"}, {name: "note"}},
+		data:    []fieldRule{{name: "text", required: true}},
+	},
+	"card": {
+		version: 1,
+		meta: []fieldRule{
+			{name: "title"}, {name: "note"},
+			{name: "name", required: true},
+			{name: "number", required: true, valid: str(luhn), want: "digits passing the Luhn check"},
+			{name: "exp", required: true, valid: str(validExp), want: "MM/YY"},
+			{name: "cvc", required: true, valid: str(reCVC.MatchString), want: "3 or 4 digits"},
+		},
+	},
+	"binary": {
+		version: 1,
+		meta:    []fieldRule{{name: "title"}, {name: "note"}, {name: "filename"}, {name: "mime"}},
+		rawData: true,
+	},
+	"otp": {
+		version: 1,
+		meta: []fieldRule{
+			{name: "title"}, {name: "note"}, {name: "issuer"},
+			{name: "digits", valid: num(func(n int) bool { return n == 6 || n == 8 }), want: "6 or 8"},
+			{name: "period", valid: num(func(n int) bool { return n > 0 }), want: "a positive number of seconds"},
+			{name: "algo", valid: str(func(s string) bool { return slices.Contains([]string{"SHA1", "SHA256", "SHA512"}, s) }), want: "SHA1, SHA256 or SHA512"},
+		},
+		data: []fieldRule{{name: "secret", required: true, valid: str(isBase32), want: "base32"}},
+	},
+	"wifi": {
+		version: 1,
+		meta: []fieldRule{
+			{name: "title"}, {name: "note"},
+			{name: "ssid", required: true},
+			{name: "security", valid: str(func(s string) bool { return slices.Contains([]string{wifiWPA, wifiSAE, wifiWEP, wifiNoPass}, s) }), want: "WPA, SAE, WEP or nopass"},
+			{name: "hidden", valid: isBool, want: "true or false"},
+		},
+		data: []fieldRule{{name: "psk"}},
+	},
+	"license": {
+		version: 1,
+		meta: []fieldRule{
+			{name: "title"}, {name: "note"}, {name: "product"},
+			{name: "seats", valid: num(func(n int) bool { return n > 0 }), want: "a positive number"},
+			{name: "purchased", valid: str(validDate), want: "YYYY-MM-DD"},
+			{name: "expires", valid: str(validDate), want: "YYYY-MM-DD"},
+		},
+		data: []fieldRule{{name: "key", required: true}},
+	},
+	"apikey": {
+		version: 1,
+		meta: []fieldRule{
+			{name: "title"}, {name: "note"}, {name: "environment"},
+			{name: "rotate_by", valid: str(validDate), want: "YYYY-MM-DD"},
+		},
+		data: []fieldRule{{name: "key", required: true}},
+	},
+	"bank": {
+		version: 1,
+		meta: []fieldRule{
+			{name: "title"}, {name: "note"}, {name: "bank"},
+			{name: "holder", required: true},
+			{name: "bic", valid: str(validBIC), want: "a BIC of 8 or 11 letters and digits"},
+		},
+		data: []fieldRule{{name: "iban", required: true, valid: str(validIBAN), want: "an IBAN without spaces with a valid checksum"}},
+	},
+	typeCustom: {
+		version: 1,
+		meta:    []fieldRule{{name: "title"}, {name: "note"}},
+	},
+	typeTemplate: {
+		version: 1,
+		meta:    []fieldRule{{name: "title", required: true}},
+		rawData: true,
+	},
+}
+
+// newer reports whether p was written with a schema of its type newer than
+// this client's. Such payloads are read as far as their known fields go.
+func (p typedPayload) newer() bool {
+	t, ok := payloadTypes[p.Type]
+	return ok && p.Schema > t.version
+}
+
+// Schema checks on write (-schema-check).
+const (
+	checkStrict = "strict" // refuse payloads that break their schema
+	checkWarn   = "warn"   // write them, with a warning on stderr
+	checkOff    = "off"
+)
+
+var schemaCheck = checkStrict
+
+func setSchemaCheck(v string) error {
+	if v != checkStrict && v != checkWarn && v != checkOff {
+		return fmt.Errorf("want %s, %s or %s", checkStrict, checkWarn, checkOff)
+	}
+	schemaCheck = v
+	return nil
+}
+
+// validatePayload checks p against its type's schema. A payload of a newer
+// schema than this client's has only its known fields checked: the rest
+// is what a newer gk added, and is kept as is.
+func validatePayload(p typedPayload) error {
+	t, ok := payloadTypes[p.Type]
+	if !ok {
+		return fmt.Errorf("%w: unknown type %q", errInvalidInput, p.Type)
+	}
+	if err := checkFields("meta", p.Meta, slices.Concat(commonMeta, t.meta), false); err != nil {
+		return err
+	}
+	if t.rawData {
+		return nil
+	}
+	return checkFields("data", p.Data, t.data, !p.newer())
+}
+
+func checkFields(part string, raw json.RawMessage, rules []fieldRule, closed bool) error {
+	var obj map[string]any
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &obj); err != nil {
+			return fmt.Errorf("%w: %s must be an object", errInvalidInput, part)
+		}
+	}
+	for _, r := range rules {
+		v, ok := obj[r.name]
+		if !ok || v == nil || v == "" {
+			if r.required {
+				return fmt.Errorf("%w: %s.%s is required", errInvalidInput, part, r.name)
+			}
+			continue
+		}
+		valid, want := r.valid, r.want
+		if valid == nil {
+			valid, want = isString, "a string"
+		}
+		if !valid(v) {
+			return fmt.Errorf("%w: %s.%s must be %s", errInvalidInput, part, r.name, want)
+		}
+	}
+	if closed {
+		for k := range obj {
+			if !slices.ContainsFunc(rules, func(r fieldRule) bool { return r.name == k }) {
+				return fmt.Errorf("%w: %s.%s is not a field of this type", errInvalidInput, part, k)
+			}
+		}
+	}
+	return nil
+}
+
+// encodePayload stamps p with its type's schema version, unless it already
+// carries a newer one, checks it as -schema-check says and marshals it.
+func encodePayload(p typedPayload) ([]byte, error) {
+	if t, ok := payloadTypes[p.Type]; ok && p.Schema < t.version {
+		p.Schema = t.version
+	}
+	if schemaCheck != checkOff {
+		if err := validatePayload(p); err != nil {
+			if schemaCheck == checkStrict {
+				return nil, err
+			}
+			fmt.Fprint(os.Stderr, colors(os.Stderr).warn(tr("warning: %v; writing it anyway\n", err)))
+		}
+	}
+	return json.Marshal(p)
+}
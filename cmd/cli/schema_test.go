@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func Test_validatePayload(t *testing.T) {
+	t.Parallel()
+
+	p := func(typ, meta, data string, schema int) typedPayload {
+		return typedPayload{Type: typ, Meta: json.RawMessage(meta), Data: json.RawMessage(data), Schema: schema}
+	}
+	for _, ok := range []typedPayload{
+		p("login", `{"title":"a","tags":["x"]}`, `{"password":"p"}`, 1),
+		p("card", `{"name":"N","number":"4242424242424242","exp":"12/30","cvc":"123"}`, `{}`, 1),
+		p("otp", `{"digits":6,"period":30,"algo":"SHA1"}`, `{"secret":"JBSWY3DPEHPK3PXP"}`, 1),
+		p("binary", `{"filename":"f"}`, `"AAEC"`, 1),
+		// a newer gk may add data fields this one does not know
+		p("login", `{}`, `{"password":"p","passkey":"k"}`, 2),
+	} {
+		if err := validatePayload(ok); err != nil {
+			t.Fatalf("%s %s: %v", ok.Meta, ok.Data, err)
+		}
+	}
+	for _, bad := range []typedPayload{
+		p("bogus", `{}`, `{}`, 1),
+		p("login", `{}`, `{}`, 1),
+		p("login", `{}`, `{"password":"p","pasword":"q"}`, 1),
+		p("login", `{"url":7}`, `{"password":"p"}`, 1),
+		p("card", `{"name":"N","number":"4242424242424241","exp":"12/30","cvc":"123"}`, `{}`, 1),
+		p("otp", `{"digits":7}`, `{"secret":"JBSWY3DPEHPK3PXP"}`, 1),
+		p("text", `[]`, `{"text":"t"}`, 1),
+		// newer payloads still have their known fields checked
+		p("login", `{}`, `{"passkey":"k"}`, 2),
+	} {
+		if err := validatePayload(bad); !errors.Is(err, errInvalidInput) {
+			t.Fatalf("%s %s %s: want invalid input, got %v", bad.Type, bad.Meta, bad.Data, err)
+		}
+	}
+}
+
+func Test_encodePayload(t *testing.T) {
+	bad := typedPayload{Type: "text", Meta: json.RawMessage(`{}`), Data: json.RawMessage(`{"txt":"t"}`)}
+	if _, err := encodePayload(bad); !errors.Is(err, errInvalidInput) {
+		t.Fatalf("strict: %v", err)
+	}
+
+	t.Cleanup(func() { schemaCheck = checkStrict })
+	for _, mode := range []string{checkWarn, checkOff} {
+		if err := setSchemaCheck(mode); err != nil {
+			t.Fatal(err)
+		}
+		b, err := encodePayload(bad)
+		var got typedPayload
+		if err != nil || json.Unmarshal(b, &got) != nil || got.Schema != 1 {
+			t.Fatalf("%s: %s %v", mode, b, err)
+		}
+	}
+	if setSchemaCheck("lax") == nil {
+		t.Fatal("want an error for an unknown mode")
+	}
+
+	// a newer schema is kept, not stamped down
+	schemaCheck = checkStrict
+	b, err := encodePayload(typedPayload{Type: "text", Meta: json.RawMessage(`{}`), Data: json.RawMessage(`{"text":"t","lang":"en"}`), Schema: 3})
+	var got typedPayload
+	if err != nil || json.Unmarshal(b, &got) != nil || got.Schema != 3 || !got.newer() {
+		t.Fatalf("newer: %s %v", b, err)
+	}
+}
@@ -0,0 +1,106 @@
+// cmd/cli/search.go
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// searchHit is one matching item; Match names where the query was found.
+type searchHit struct {
+	ID    string `json:"id"`
+	Type  string `json:"type"`
+	Title string `json:"title"`
+	Match string `json:"match"`
+}
+
+// matchItem reports where q (lower-cased) occurs in the item's searchable text:
+// meta string values, custom field names and non-hidden custom field values.
+// Secret data and hidden values are never searched.
+func matchItem(obj typedPayload, q string) (string, bool) {
+	var meta map[string]any
+	_ = json.Unmarshal(obj.Meta, &meta)
+	keys := make([]string, 0, len(meta))
+	for k := range meta {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if v, ok := meta[k].(string); ok && strings.Contains(strings.ToLower(v), q) {
+			return k, true
+		}
+	}
+	for _, f := range obj.Fields {
+		if strings.Contains(strings.ToLower(f.Name), q) {
+			return "field:" + f.Name, true
+		}
+		if !f.Hidden && strings.Contains(strings.ToLower(f.Value), q) {
+			return "field:" + f.Name, true
+		}
+	}
+	return "", false
+}
+
+// searchItems filters items by query and optional type.
+func searchItems(items []vaultItem, query, typ string) []searchHit {
+	q := strings.ToLower(strings.TrimSpace(query))
+	var out []searchHit
+	for _, it := range items {
+		if typ != "" && it.Payload.Type != typ {
+			continue
+		}
+		where, ok := matchItem(it.Payload, q)
+		if !ok {
+			continue
+		}
+		out = append(out, searchHit{ID: it.ID, Type: it.Payload.Type, Title: metaTitle(it.Payload), Match: where})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Title != out[j].Title {
+			return out[i].Title < out[j].Title
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
+
+// cmdSearch finds items by text in their non-secret fields.
+func cmdSearch(args []string, addr, caPath string, insecure bool) {
+	fs := flag.NewFlagSet("search", flag.ExitOnError)
+	typ := fs.String("type", "", "only items of this type")
+	parseFlags(fs, args)
+	if fs.NArg() != 1 || strings.TrimSpace(fs.Arg(0)) == "" {
+		fmt.Fprintln(os.Stderr, tr("usage: search [-type login] <text>"))
+		os.Exit(exitUsage)
+	}
+
+	token, err := loadToken()
+	if err != nil {
+		fail(err)
+	}
+	ctx, cancel := withTimeout()
+	defer cancel()
+	conn, cli, err := dial(ctx, addr, caPath, insecure, token)
+	if err != nil {
+		fail(err)
+	}
+	defer conn.Close()
+
+	items, err := listTyped(ctx, cli)
+	if err != nil {
+		fail(err)
+	}
+	hits := searchItems(items, fs.Arg(0), *typ)
+	emit(hits, func() {
+		for _, h := range hits {
+			fmt.Printf("%s  %-6s  %s  (%s)\n", h.ID, h.Type, h.Title, h.Match)
+		}
+		if len(hits) == 0 {
+			fmt.Fprintln(os.Stderr, tr("no matches"))
+		}
+	})
+}
@@ -0,0 +1,74 @@
+// cmd/cli/serverinfo.go
+package main
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/internal/compat"
+)
+
+// clientFeatures are the protocol features gk cannot do without; a server
+// lacking one refuses its calls instead of answering in a way gk misreads.
+// Long polling and best_effort batches are left out: gk falls back when a
+// server has neither.
+var clientFeatures = []string{compat.FeaturePageToken, compat.FeatureDeleteItems, compat.FeatureItemStream, compat.FeatureChangeSet}
+
+// clientMD adds gk's version and features to outgoing metadata.
+func clientMD(ctx context.Context) context.Context {
+	return metadata.AppendToOutgoingContext(ctx,
+		compat.VersionKey, version,
+		compat.FeaturesKey, strings.Join(clientFeatures, ","))
+}
+
+func clientInfoUnary(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	return invoker(clientMD(ctx), method, req, reply, cc, opts...)
+}
+
+func clientInfoStream(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return streamer(clientMD(ctx), desc, cc, method, opts...)
+}
+
+// serverInfo is the output of gk server-info.
+type serverInfo struct {
+	ServerVersion    string   `json:"server_version"`
+	MinClientVersion string   `json:"min_client_version,omitempty"`
+	Features         []string `json:"features"`
+	ClientVersion    string   `json:"client_version"`
+	Compatible       bool     `json:"compatible"`
+	Missing          []string `json:"missing,omitempty"` // features gk needs that the server lacks
+}
+
+// cmdServerInfo prints what the server offers and whether this gk suits it.
+func cmdServerInfo(addr, caPath string, insecure bool) {
+	ctx, cancel := withTimeout()
+	defer cancel()
+	conn, cli, err := dial(ctx, addr, caPath, insecure, "")
+	if err != nil {
+		fail(err)
+	}
+	defer conn.Close()
+
+	resp, err := cli.GetServerInfo(ctx, &pb.GetServerInfoRequest{})
+	if status.Code(err) == codes.Unimplemented {
+		fail(errNoServerInfo)
+	}
+	if err != nil {
+		fail(err)
+	}
+	si := serverInfo{
+		ServerVersion:    resp.GetServerVersion(),
+		MinClientVersion: resp.GetMinClientVersion(),
+		Features:         resp.GetFeatures(),
+		ClientVersion:    version,
+		Missing:          compat.Missing(clientFeatures, resp.GetFeatures()),
+	}
+	si.Compatible = len(si.Missing) == 0 && !compat.Older(version, si.MinClientVersion)
+	printJSON(si)
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/internal/compat"
+	"github.com/and161185/goph-keeper/pkg/gktest"
+)
+
+func Test_e2e_ServerInfo(t *testing.T) {
+	_ = startServer(t, gktest.WithMinClientVersion("v1.0.0"))
+	const addr = "gk.test:8443"
+	old := version
+	t.Cleanup(func() { version = old })
+
+	info := func() serverInfo {
+		t.Helper()
+		var si serverInfo
+		out := stdoutOf(t, func() { cmdServerInfo(addr, "", false) })
+		if err := json.Unmarshal([]byte(out), &si); err != nil {
+			t.Fatalf("%q: %v", out, err)
+		}
+		return si
+	}
+	si := info()
+	if !si.Compatible || si.MinClientVersion != "v1.0.0" || !slices.Contains(si.Features, compat.FeaturePageToken) {
+		t.Fatalf("dev build: %+v", si)
+	}
+
+	version = "v0.9.0"
+	if si := info(); si.Compatible || si.ClientVersion != "v0.9.0" {
+		t.Fatalf("old build: %+v", si)
+	}
+	token, _ := loadToken()
+	ctx, cancel := withTimeout()
+	defer cancel()
+	conn, cli, err := dial(ctx, addr, "", false, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_, err = cli.GetChanges(ctx, &pb.GetChangesRequest{})
+	if ce := classify(err); ce.Class != "incompatible" || ce.ExitCode != exitGeneric {
+		t.Fatalf("old build's call: %+v", ce)
+	}
+}
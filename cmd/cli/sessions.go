@@ -0,0 +1,72 @@
+// cmd/cli/sessions.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+)
+
+// sessionRow is one line of gk sessions.
+type sessionRow struct {
+	ID       string    `json:"id"`
+	Label    string    `json:"label,omitempty"`
+	Created  time.Time `json:"created"`
+	LastUsed time.Time `json:"last_used"`
+	Expires  time.Time `json:"expires"`
+	Current  bool      `json:"current,omitempty"`
+	Scopes   []string  `json:"scopes,omitempty"` // empty: not limited
+}
+
+// sessionRows renders sessions, most recently used first as the server sends them.
+func sessionRows(sessions []*pb.Session) []sessionRow {
+	rows := make([]sessionRow, 0, len(sessions))
+	for _, s := range sessions {
+		rows = append(rows, sessionRow{
+			ID:       s.GetId(),
+			Label:    s.GetLabel(),
+			Created:  s.GetCreatedAt().AsTime().Local(),
+			LastUsed: s.GetLastUsedAt().AsTime().Local(),
+			Expires:  s.GetExpiresAt().AsTime().Local(),
+			Current:  s.GetCurrent(),
+			Scopes:   s.GetScopes(),
+		})
+	}
+	return rows
+}
+
+// cmdSessions lists the devices signed in to the account or signs one out.
+func cmdSessions(args []string, addr, caPath string, insecure bool) {
+	fs := flag.NewFlagSet("sessions", flag.ExitOnError)
+	revoke := fs.String("revoke", "", "session id to sign out; its refresh token stops working")
+	parseFlags(fs, args)
+
+	token, err := loadToken()
+	if err != nil {
+		fail(err)
+	}
+	ctx, cancel := withTimeout()
+	defer cancel()
+	conn, cli, err := dial(ctx, addr, caPath, insecure, token)
+	if err != nil {
+		fail(err)
+	}
+	defer conn.Close()
+
+	if *revoke != "" {
+		req := &pb.RevokeSessionRequest{}
+		req.SetId(*revoke)
+		if _, err := cli.RevokeSession(ctx, req); err != nil {
+			fail(err)
+		}
+		fmt.Printf(tr("session %s signed out\n"), *revoke)
+		return
+	}
+	resp, err := cli.ListSessions(ctx, &pb.ListSessionsRequest{})
+	if err != nil {
+		fail(err)
+	}
+	printJSON(sessionRows(resp.GetSessions()))
+}
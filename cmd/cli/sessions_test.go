@@ -0,0 +1,33 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+)
+
+func Test_sessionRows(t *testing.T) {
+	t.Parallel()
+	used := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	a := &pb.Session{}
+	a.SetId("s1")
+	a.SetLabel("laptop")
+	a.SetLastUsedAt(timestamppb.New(used))
+	a.SetExpiresAt(timestamppb.New(used.Add(30 * 24 * time.Hour)))
+	a.SetCurrent(true)
+	a.SetScopes([]string{"items:read"})
+	b := &pb.Session{}
+	b.SetId("s2")
+
+	rows := sessionRows([]*pb.Session{a, b})
+	if len(rows) != 2 || rows[0].ID != "s1" || rows[0].Label != "laptop" || !rows[0].LastUsed.Equal(used) || !rows[0].Current ||
+		len(rows[0].Scopes) != 1 {
+		t.Fatalf("rows: %+v", rows)
+	}
+	if rows[1].ID != "s2" || rows[1].Label != "" || rows[1].Current {
+		t.Fatalf("unlabelled: %+v", rows[1])
+	}
+}
@@ -0,0 +1,116 @@
+// cmd/cli/signup.go
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	clientcrypto "github.com/and161185/goph-keeper/internal/crypto/clientcrypto"
+)
+
+// cmdSignup creates an account and leaves this machine logged in to it
+// with a fresh DEK: register, login, then the first-login DEK setup, in one
+// command.
+func cmdSignup(args []string, addr, caPath string, insecure bool) {
+	fs := flag.NewFlagSet("signup", flag.ExitOnError)
+	u := fs.String("u", "", "username")
+	p := fs.String("p", "", "password")
+	captcha := fs.String("captcha", "", "CAPTCHA response token, if the server asks for one")
+	host, _ := os.Hostname()
+	label := fs.String("label", host, "name this device in gk sessions")
+	parseFlags(fs, args)
+	if *u == "" || *p == "" {
+		fmt.Fprintln(os.Stderr, tr("need -u and -p"))
+		os.Exit(exitUsage)
+	}
+
+	ctx, cancel := withTimeout()
+	defer cancel()
+	conn, cli, err := dial(ctx, addr, caPath, insecure, "")
+	if err != nil {
+		fail(err)
+	}
+	defer conn.Close()
+
+	rr := &pb.RegisterRequest{}
+	rr.SetUsername(*u)
+	rr.SetPassword(*p)
+	rr.SetReturnKekSalt(true)
+	reg, err := withChallenge(*captcha, func(answer string) (*pb.RegisterResponse, error) {
+		rr.SetChallengeResponse(answer)
+		return cli.Register(ctx, rr)
+	})
+	if err != nil {
+		fail(err)
+	}
+	// from here on the account exists; a plain login finishes what failed
+	unfinished := func(err error) {
+		fmt.Fprint(os.Stderr, colors(os.Stderr).warn(tr("account %s was created; run gk login to finish setting it up\n", reg.GetUserId())))
+		fail(err)
+	}
+
+	lr := &pb.LoginRequest{}
+	lr.SetUsername(*u)
+	lr.SetPassword(*p)
+	lr.SetDeviceLabel(*label)
+	resp, err := withChallenge("", func(answer string) (*pb.LoginResponse, error) {
+		lr.SetChallengeResponse(answer)
+		return cli.Login(ctx, lr)
+	})
+	if err != nil {
+		unfinished(err)
+	}
+
+	salt := reg.GetKekSalt()
+	if len(salt) == 0 { // a server without return_kek_salt
+		salt = resp.GetKekSalt()
+	}
+	kek := clientcrypto.DefaultKDF.DeriveKEK([]byte(*p), salt)
+	dek, err := initDEK(ctx, addr, caPath, insecure, resp.GetAccessToken(), kek)
+	if err != nil {
+		unfinished(err)
+	}
+	if err := saveDEK(dek); err != nil {
+		unfinished(err)
+	}
+	if err := saveLogin(resp); err != nil {
+		unfinished(err)
+	}
+	fmt.Println(reg.GetUserId())
+}
+
+// initDEK makes a new DEK, stores it on the server wrapped under kek and
+// returns it. The server refuses if the account has a DEK already.
+func initDEK(ctx context.Context, addr, caPath string, insecure bool, token string, kek []byte) ([]byte, error) {
+	dek, err := clientcrypto.Rand(clientcrypto.DEKLen)
+	if err != nil {
+		return nil, err
+	}
+	wrapped, err := clientcrypto.WrapDEK(kek, dek)
+	if err != nil {
+		return nil, err
+	}
+	conn, cli, err := dial(ctx, addr, caPath, insecure, token)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	req := &pb.SetWrappedDEKRequest{}
+	req.SetWrappedDek(wrapped)
+	if _, err := cli.SetWrappedDEK(ctx, req); err != nil {
+		return nil, err
+	}
+	return dek, nil
+}
+
+// saveLogin keeps what later commands need from a login: the user id (part
+// of every item's AAD) and the tokens.
+func saveLogin(resp *pb.LoginResponse) error {
+	if err := saveUserID(resp.GetUserId()); err != nil {
+		return err
+	}
+	return saveToken(resp.GetAccessToken(), resp.GetRefreshToken(), tokenExpiry(resp.GetAccessToken()))
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/gofrs/uuid/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	cc "github.com/and161185/goph-keeper/internal/crypto/clientcrypto"
+	"github.com/and161185/goph-keeper/pkg/gktest"
+)
+
+func Test_e2e_Signup(t *testing.T) {
+	_ = withTmpConfig(t)
+	srv := gktest.Start(t)
+	extraDialOptions = srv.DialOptions()
+	t.Cleanup(func() { extraDialOptions = nil })
+	const addr = "gk.test:8443"
+
+	uid := strings.TrimSpace(stdoutOf(t, func() { cmdSignup([]string{"-u", "carol", "-p", "pw", "-label", "test"}, addr, "", false) }))
+	if saved, err := loadUserID(); err != nil || saved != uid || uid == "" {
+		t.Fatalf("user id %q, saved %q: %v", uid, saved, err)
+	}
+	if _, err := loadToken(); err != nil {
+		t.Fatalf("token: %v", err)
+	}
+	dek, err := loadDEK()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lr := &pb.LoginRequest{}
+	lr.SetUsername("carol")
+	lr.SetPassword("pw")
+	resp, err := srv.Client(t).Login(context.Background(), lr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kdf, err := cc.WrappedKDF(resp.GetWrappedDek())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := cc.UnwrapDEK(kdf.DeriveKEK([]byte("pw"), resp.GetKekSalt()), resp.GetWrappedDek())
+	if err != nil || !bytes.Equal(got, dek) {
+		t.Fatalf("server's wrapped DEK does not hold the local one: %v", err)
+	}
+}
+
+func Test_e2e_LoginScope(t *testing.T) {
+	_ = withTmpConfig(t)
+	srv := gktest.Start(t)
+	extraDialOptions = srv.DialOptions()
+	t.Cleanup(func() { extraDialOptions = nil })
+	const addr = "gk.test:8443"
+
+	_ = stdoutOf(t, func() { cmdSignup([]string{"-u", "dan", "-p", "pw"}, addr, "", false) })
+	_ = stdoutOf(t, func() { cmdLogin([]string{"-u", "dan", "-p", "pw", "-scope", "items:read"}, addr, "", false) })
+	token, err := loadToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := gktest.WithToken(context.Background(), token)
+	cli := srv.Client(t)
+	if _, err := cli.GetChanges(ctx, &pb.GetChangesRequest{}); err != nil {
+		t.Fatalf("read with a read-only token: %v", err)
+	}
+	ref := &pb.ItemRef{}
+	ref.SetId(uuid.Must(uuid.NewV7()).String())
+	ref.SetBaseVer(1)
+	req := &pb.DeleteItemsRequest{}
+	req.SetItems([]*pb.ItemRef{ref})
+	if _, err := cli.DeleteItems(ctx, req); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("delete with a read-only token: %v", err)
+	}
+}
@@ -0,0 +1,51 @@
+// cmd/cli/skew.go
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+)
+
+// maxSkew is the clock difference to the server beyond which sync warns; 0 disables.
+var maxSkew = 2 * time.Minute
+
+// clockSkew estimates how far the server clock is ahead of ours (negative:
+// behind) from a call sent at sent and answered at recv. The server read its
+// clock somewhere in between; the midpoint halves the round-trip error.
+func clockSkew(sent, recv, server time.Time) time.Duration {
+	mid := sent.Add(recv.Sub(sent) / 2)
+	return server.Sub(mid).Round(time.Second)
+}
+
+// responseSkew is clockSkew for a GetChanges answer; ok is false when the
+// server is too old to report its time.
+func responseSkew(sent, recv time.Time, out *pb.GetChangesResponse) (time.Duration, bool) {
+	if !out.HasServerTime() {
+		return 0, false
+	}
+	return clockSkew(sent, recv, out.GetServerTime().AsTime()), true
+}
+
+// skewed reports whether skew exceeds maxSkew in either direction.
+func skewed(skew time.Duration) bool {
+	return maxSkew > 0 && (skew >= maxSkew || -skew >= maxSkew)
+}
+
+// warnSkew tells the user when their clock is off by more than maxSkew.
+// Sync cursors do not depend on the local clock, but TOTP codes, token
+// expiry and password-age reminders do.
+func warnSkew(w io.Writer, skew time.Duration) bool {
+	if !skewed(skew) {
+		return false
+	}
+	c := colors(w)
+	if skew < 0 {
+		fmt.Fprint(w, c.warn(tr("warning: local clock is %s ahead of the server; TOTP codes and expiry checks may be wrong\n", -skew)))
+	} else {
+		fmt.Fprint(w, c.warn(tr("warning: local clock is %s behind the server; TOTP codes and expiry checks may be wrong\n", skew)))
+	}
+	return true
+}
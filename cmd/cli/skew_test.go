@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func Test_clockSkew(t *testing.T) {
+	sent := time.Unix(1000, 0)
+	recv := sent.Add(2 * time.Second)
+	// server answered mid-flight with a clock 5 minutes fast
+	if got := clockSkew(sent, recv, sent.Add(time.Second+5*time.Minute)); got != 5*time.Minute {
+		t.Fatalf("fast server: %s", got)
+	}
+	if got := clockSkew(sent, recv, sent.Add(-time.Hour)); got != -time.Hour-time.Second {
+		t.Fatalf("slow server: %s", got)
+	}
+
+	out := &pb.GetChangesResponse{}
+	if _, ok := responseSkew(sent, recv, out); ok {
+		t.Fatal("old server without server_time must not report skew")
+	}
+	out.SetServerTime(timestamppb.New(sent.Add(time.Second)))
+	if skew, ok := responseSkew(sent, recv, out); !ok || skew != 0 {
+		t.Fatalf("in sync: %s %v", skew, ok)
+	}
+}
+
+func Test_warnSkew(t *testing.T) {
+	var buf bytes.Buffer
+	if warnSkew(&buf, time.Minute) || warnSkew(&buf, -time.Minute) || buf.Len() != 0 {
+		t.Fatalf("warned below threshold: %q", buf.String())
+	}
+	if !warnSkew(&buf, 3*time.Minute) || !strings.Contains(buf.String(), "3m0s behind") {
+		t.Fatalf("behind: %q", buf.String())
+	}
+	buf.Reset()
+	if !warnSkew(&buf, -time.Hour) || !strings.Contains(buf.String(), "1h0m0s ahead of") {
+		t.Fatalf("ahead: %q", buf.String())
+	}
+}
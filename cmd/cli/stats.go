@@ -0,0 +1,191 @@
+// cmd/cli/stats.go
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+)
+
+// statItem describes one item in the largest/recent lists.
+type statItem struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Title     string    `json:"title"`
+	Bytes     int       `json:"bytes"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// cursorInfo is a sync position: how many items (tombstones included) and the highest version.
+type cursorInfo struct {
+	Items       int64     `json:"items"`
+	MaxVer      int64     `json:"max_ver"`
+	SyncedAt    time.Time `json:"synced_at,omitzero"`    // local cache only
+	LastUpdated time.Time `json:"last_updated,omitzero"` // server only
+}
+
+// vaultStats is the gk stats report.
+type vaultStats struct {
+	Live           int            `json:"live"`
+	Deleted        int            `json:"deleted"`
+	ByType         map[string]int `json:"by_type"`
+	Undecryptable  int            `json:"undecryptable,omitempty"`
+	EncryptedBytes int64          `json:"encrypted_bytes"`
+	Largest        []statItem     `json:"largest"`
+	Recent         []statItem     `json:"recent"`
+	MaxItems       int64          `json:"max_items,omitempty"` // server cap on live items
+	Local          *cursorInfo    `json:"local,omitempty"`
+	Server         cursorInfo     `json:"server"`
+}
+
+// computeStats summarises a full change set. Items that fail to decrypt are
+// counted but kept out of the per-type breakdown.
+func computeStats(changes []*pb.Change, userID string, top int) vaultStats {
+	st := vaultStats{ByType: map[string]int{}}
+	var live []statItem
+	for _, c := range changes {
+		if c.GetDeleted() {
+			st.Deleted++
+			continue
+		}
+		st.Live++
+		blob := c.GetBlobEnc().GetCiphertext()
+		st.EncryptedBytes += int64(len(blob))
+		si := statItem{ID: c.GetId(), Bytes: len(blob), UpdatedAt: c.GetUpdatedAt().AsTime()}
+		var obj typedPayload
+		pt, err := decryptForItem(c.GetId(), userID, c.GetVer(), blob)
+		if err != nil || json.Unmarshal(pt, &obj) != nil || obj.Type == "" {
+			st.Undecryptable++
+			si.Type = "?"
+		} else {
+			st.ByType[obj.Type]++
+			si.Type, si.Title = obj.Type, metaTitle(obj)
+		}
+		live = append(live, si)
+	}
+
+	st.Largest = topItems(live, top, func(a, b statItem) bool { return a.Bytes > b.Bytes })
+	st.Recent = topItems(live, top, func(a, b statItem) bool { return a.UpdatedAt.After(b.UpdatedAt) })
+	return st
+}
+
+// topItems returns the first n items of a copy of in sorted by less (ties by id).
+func topItems(in []statItem, n int, less func(a, b statItem) bool) []statItem {
+	out := append([]statItem(nil), in...)
+	sort.SliceStable(out, func(i, j int) bool {
+		if less(out[i], out[j]) {
+			return true
+		}
+		if less(out[j], out[i]) {
+			return false
+		}
+		return out[i].ID < out[j].ID
+	})
+	if len(out) > n {
+		out = out[:n]
+	}
+	return out
+}
+
+// localCursor reports the syncd cache position, if a cache exists.
+func localCursor() *cursorInfo {
+	c, err := loadCache()
+	if err != nil {
+		return nil
+	}
+	ci := &cursorInfo{Items: int64(len(c.Changes)), SyncedAt: c.SyncedAt}
+	for _, ch := range c.Changes {
+		ci.MaxVer = max(ci.MaxVer, ch.Ver)
+	}
+	return ci
+}
+
+// cmdStats prints vault statistics.
+func cmdStats(args []string, addr, caPath string, insecure bool) {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	top := fs.Int("top", 5, "entries in largest/recent lists")
+	parseFlags(fs, args)
+
+	token, err := loadToken()
+	if err != nil {
+		fail(err)
+	}
+	uid, err := loadUserID()
+	if err != nil {
+		fail(err)
+	}
+	ctx, cancel := withTimeout()
+	defer cancel()
+	conn, cli, err := dial(ctx, addr, caPath, insecure, token)
+	if err != nil {
+		fail(err)
+	}
+	defer conn.Close()
+
+	srv, err := cli.GetStats(ctx, &pb.GetStatsRequest{})
+	if err != nil {
+		fail(err)
+	}
+	changes, warm := warmChanges()
+	if !warm {
+		req := &pb.GetChangesRequest{}
+		req.SetSinceVer(0)
+		out, err := fetchChanges(ctx, cli, req)
+		if err != nil {
+			fail(err)
+		}
+		changes = out.GetChanges()
+	}
+
+	st := computeStats(changes, uid, *top)
+	st.Local = localCursor()
+	st.Server = cursorInfo{Items: srv.GetItems(), MaxVer: srv.GetMaxVer()}
+	st.MaxItems = srv.GetMaxItems()
+	if srv.HasLastUpdated() {
+		st.Server.LastUpdated = srv.GetLastUpdated().AsTime()
+	}
+
+	emit(st, func() { printStats(st) })
+}
+
+func printStats(st vaultStats) {
+	fmt.Printf(tr("items: %d live, %d deleted, %d bytes encrypted\n"), st.Live, st.Deleted, st.EncryptedBytes)
+	if st.MaxItems > 0 {
+		fmt.Printf(tr("limit: %d of %d items used\n"), st.Live, st.MaxItems)
+	}
+	types := make([]string, 0, len(st.ByType))
+	for t := range st.ByType {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	for _, t := range types {
+		fmt.Printf("  %-8s %d\n", t, st.ByType[t])
+	}
+	if st.Undecryptable > 0 {
+		fmt.Print(colors(os.Stdout).bad(tr("  %-8s %d (could not decrypt)\n", "?", st.Undecryptable)))
+	}
+	fmt.Println(tr("largest:"))
+	for _, it := range st.Largest {
+		fmt.Printf("  %8d  %s  %s %s\n", it.Bytes, it.ID, it.Type, it.Title)
+	}
+	fmt.Println(tr("recently modified:"))
+	for _, it := range st.Recent {
+		fmt.Printf("  %s  %s  %s %s\n", it.UpdatedAt.Local().Format(time.DateTime), it.ID, it.Type, it.Title)
+	}
+	fmt.Printf(tr("server: %d items, max ver %d\n"), st.Server.Items, st.Server.MaxVer)
+	c := colors(os.Stdout)
+	if st.Local == nil {
+		fmt.Println(c.dim(tr("local:  no cache (run gk syncd)")))
+		return
+	}
+	fmt.Printf(tr("local:  %d items, max ver %d, synced %s"), st.Local.Items, st.Local.MaxVer, st.Local.SyncedAt.Local().Format(time.DateTime))
+	if st.Local.MaxVer != st.Server.MaxVer || st.Local.Items != st.Server.Items {
+		fmt.Print(c.warn(tr(" (differs from server)")))
+	}
+	fmt.Println()
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	cc "github.com/and161185/goph-keeper/internal/crypto/clientcrypto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func Test_computeStats(t *testing.T) {
+	_ = withTmpConfig(t)
+	dek, _ := cc.Rand(cc.DEKLen)
+	if err := saveDEK(dek); err != nil {
+		t.Fatal(err)
+	}
+	base := time.Unix(1700000000, 0)
+	mk := func(id, typ, title string, pad int, age time.Duration) *pb.Change {
+		key := map[string]string{"login": "password", "text": "text"}[typ]
+		pt, _ := buildTypedPayload(typ, map[string]any{"title": title}, map[string]any{key: "x" + string(make([]byte, pad))})
+		blob, err := encryptForItem(id, "u1", 1, pt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		eb := &pb.EncryptedBlob{}
+		eb.SetCiphertext(blob)
+		c := &pb.Change{}
+		c.SetId(id)
+		c.SetVer(1)
+		c.SetUpdatedAt(timestamppb.New(base.Add(-age)))
+		c.SetBlobEnc(eb)
+		return c
+	}
+	garbage := &pb.Change{}
+	garbage.SetId("g")
+	garbage.SetVer(1)
+	geb := &pb.EncryptedBlob{}
+	geb.SetCiphertext([]byte("not a ciphertext"))
+	garbage.SetBlobEnc(geb)
+	tomb := &pb.Change{}
+	tomb.SetId("t")
+	tomb.SetDeleted(true)
+
+	st := computeStats([]*pb.Change{
+		mk("a", "login", "small", 0, time.Hour),
+		mk("b", "login", "big", 500, 3*time.Hour),
+		mk("c", "text", "newest", 10, 0),
+		garbage, tomb,
+	}, "u1", 2)
+
+	if st.Live != 4 || st.Deleted != 1 || st.Undecryptable != 1 {
+		t.Fatalf("counts: %+v", st)
+	}
+	if st.ByType["login"] != 2 || st.ByType["text"] != 1 {
+		t.Fatalf("by type: %v", st.ByType)
+	}
+	if len(st.Largest) != 2 || st.Largest[0].Title != "big" {
+		t.Fatalf("largest: %+v", st.Largest)
+	}
+	if len(st.Recent) != 2 || st.Recent[0].Title != "newest" || st.Recent[1].Title != "small" {
+		t.Fatalf("recent: %+v", st.Recent)
+	}
+	if st.EncryptedBytes <= 500 {
+		t.Fatalf("bytes: %d", st.EncryptedBytes)
+	}
+}
+
+func Test_localCursor(t *testing.T) {
+	_ = withTmpConfig(t)
+	if localCursor() != nil {
+		t.Fatalf("no cache yet")
+	}
+	c := vaultCache{UserID: "u", SyncedAt: time.Now(), Changes: []cachedChange{{ID: "a", Ver: 3}, {ID: "b", Ver: 7, Deleted: true}}}
+	if err := saveCache(c); err != nil {
+		t.Fatal(err)
+	}
+	got := localCursor()
+	if got == nil || got.Items != 2 || got.MaxVer != 7 {
+		t.Fatalf("cursor: %+v", got)
+	}
+}
@@ -0,0 +1,77 @@
+// cmd/cli/synccursor.go
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+)
+
+// syncCursor is where the profile's last gk sync stopped, so the next one
+// without -since lists only what changed after it. It is bound to the
+// account and server it was read from.
+type syncCursor struct {
+	UserID string `json:"user_id"`
+	Addr   string `json:"addr"`
+	// Seq is the change journal position; Journal tells a server that
+	// reported one (even 0) from one without a journal.
+	Seq     int64 `json:"seq,omitempty"`
+	Journal bool  `json:"journal,omitempty"`
+	// Ver is the version watermark, used without a journal.
+	Ver int64 `json:"ver"`
+}
+
+// errStaleCursor: the saved cursor belongs to another account or server.
+var errStaleCursor = errors.New("it was saved for another account or server")
+
+func syncCursorPath() string { return filepath.Join(cfgDir(), "sync_cursor.json") }
+
+// loadSyncCursor reads the cursor saved for uid at addr; a missing file
+// fails with os.ErrNotExist, another account's or server's with
+// errStaleCursor.
+func loadSyncCursor(uid, addr string) (syncCursor, error) {
+	var c syncCursor
+	b, err := readProfileFile(syncCursorPath())
+	if err != nil {
+		return c, err
+	}
+	if err := json.Unmarshal(b, &c); err != nil {
+		return c, fmt.Errorf("%s: %w", syncCursorPath(), err)
+	}
+	if c.UserID != uid || c.Addr != addr {
+		return c, errStaleCursor
+	}
+	return c, nil
+}
+
+func saveSyncCursor(c syncCursor) error {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return writeProfileFile(syncCursorPath(), b)
+}
+
+// request asks for the changes after the cursor: by journal position when
+// the server keeps one, else by version.
+func (c syncCursor) request() *pb.GetChangesRequest {
+	req := &pb.GetChangesRequest{}
+	if c.Journal {
+		req.SetSinceSeq(c.Seq)
+	} else {
+		req.SetSinceVer(c.Ver)
+	}
+	return req
+}
+
+// advance returns the cursor after the changes in out.
+func (c syncCursor) advance(out *pb.GetChangesResponse) syncCursor {
+	c.Ver = max(c.Ver, out.GetWatermark())
+	if out.HasSeqWatermark() {
+		c.Seq, c.Journal = out.GetSeqWatermark(), true
+	}
+	return c
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+func Test_loadSyncCursor(t *testing.T) {
+	_ = withTmpConfig(t)
+	if err := saveSyncCursor(syncCursor{UserID: "u1", Addr: "a:1", Seq: 7, Journal: true}); err != nil {
+		t.Fatal(err)
+	}
+	c, err := loadSyncCursor("u1", "a:1")
+	if err != nil || c.Seq != 7 || !c.Journal || c.request().GetSinceSeq() != 7 {
+		t.Fatalf("cursor: %+v %v", c, err)
+	}
+	if _, err := loadSyncCursor("u2", "a:1"); !errors.Is(err, errStaleCursor) {
+		t.Fatalf("another account: %v", err)
+	}
+	if _, err := loadSyncCursor("u1", "b:1"); !errors.Is(err, errStaleCursor) {
+		t.Fatalf("another server: %v", err)
+	}
+}
+
+func Test_e2e_SyncContinues(t *testing.T) {
+	_ = startServer(t)
+	const addr = "gk.test:8443"
+	add := func() string {
+		id := uuid.Must(uuid.NewV7()).String()
+		_ = stdoutOf(t, func() { cmdAddText([]string{"-id", id, "-title", "note", "-text", "x"}, addr, "", false) })
+		return id
+	}
+	sync := func(args ...string) string {
+		return stdoutOf(t, func() { cmdSync(args, addr, "", false) })
+	}
+
+	first, second := add(), add()
+	if out := sync(); !strings.Contains(out, first) || !strings.Contains(out, second) {
+		t.Fatalf("first sync lists the whole vault: %s", out)
+	}
+	third := add()
+	if out := sync(); strings.Contains(out, first) || !strings.Contains(out, third) {
+		t.Fatalf("second sync lists only what changed: %s", out)
+	}
+	if out := sync("-since", "0"); !strings.Contains(out, first) || !strings.Contains(out, third) {
+		t.Fatalf("-since 0: %s", out)
+	}
+	// an explicit -since leaves the saved position alone
+	if out := sync(); strings.Contains(out, first) || strings.Contains(out, third) {
+		t.Fatalf("after -since 0: %s", out)
+	}
+}
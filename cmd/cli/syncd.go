@@ -0,0 +1,340 @@
+// cmd/cli/syncd.go
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+)
+
+// syncStatus is what syncd reports on its socket.
+type syncStatus struct {
+	PID       int           `json:"pid"`
+	UserID    string        `json:"user_id"`
+	Addr      string        `json:"addr"`
+	Interval  time.Duration `json:"interval"`
+	Items     int           `json:"items"`
+	Syncs     int64         `json:"syncs"`
+	LastSync  time.Time     `json:"last_sync"`
+	Watermark int64         `json:"watermark"`              // server cursor of the last sync
+	Seq       int64         `json:"seq,omitempty"`          // change journal position, on servers that keep one
+	Filtered  int           `json:"filtered,omitempty"`     // items kept out of the cache by the sync filter
+	Newer     int           `json:"newer_schema,omitempty"` // items only a newer client can read
+	ClockSkew time.Duration `json:"clock_skew,omitempty"`   // server clock minus ours
+	LastError string        `json:"last_error,omitempty"`
+	StartedAt time.Time     `json:"started_at"`
+}
+
+func syncdSocketPath() string { return filepath.Join(cfgDir(), "syncd.sock") }
+
+// querySyncd asks a running daemon for its status. It fails fast when none is listening.
+func querySyncd() (syncStatus, error) {
+	var st syncStatus
+	conn, err := net.DialTimeout("unix", syncdSocketPath(), 200*time.Millisecond)
+	if err != nil {
+		return st, err
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(time.Second))
+	err = json.NewDecoder(conn).Decode(&st)
+	return st, err
+}
+
+// longPollSeconds is how long syncd asks the server to hold a long poll.
+const longPollSeconds = 55
+
+// syncDaemon polls the server and keeps the on-disk cache current.
+type syncDaemon struct {
+	addr, caPath string
+	insecure     bool
+	interval     time.Duration
+	wake         chan struct{} // push notifications trigger an early refresh
+
+	mu         sync.Mutex
+	status     syncStatus
+	journal    bool // the server answered with a seq watermark
+	skewWarned bool // warn once per excursion, not every poll
+}
+
+// refresh pulls the full change set, since the cache is a whole snapshot.
+// Asking with since_seq gets the journal position back from servers that
+// keep one, which pollOnce then waits on.
+func (d *syncDaemon) refresh(ctx context.Context) error {
+	token, err := loadToken()
+	if err != nil {
+		return err
+	}
+	uid, err := loadUserID()
+	if err != nil {
+		return err
+	}
+	filter, err := loadSyncFilter()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	conn, cli, err := dial(ctx, d.addr, d.caPath, d.insecure, token)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	req := &pb.GetChangesRequest{}
+	req.SetSinceSeq(0)
+	req.SetMaxSchemaVersion(payloadSchema)
+	sent := time.Now()
+	out, err := fetchChanges(ctx, cli, req)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	skew, _ := responseSkew(sent, now, out)
+	c := cacheFromChanges(uid, out.GetChanges(), now)
+	c.Watermark = out.GetWatermark()
+	if out.HasServerTime() {
+		c.ServerTime = out.GetServerTime().AsTime()
+	}
+	applySyncFilter(&c, filter, out.GetChanges())
+	// syncd does not hold the profile lock between refreshes, only while
+	// it writes, so gk profile encrypt cannot seal an older cache over it
+	unlock, err := lockProfile(lockShared)
+	if err != nil {
+		return err
+	}
+	err = saveCache(c)
+	unlock()
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.status.UserID = uid
+	d.status.Items = len(out.GetChanges())
+	d.status.Filtered, d.status.Newer = 0, 0
+	for _, ch := range c.Changes {
+		if ch.Filtered {
+			d.status.Filtered++
+		}
+		if ch.Newer {
+			d.status.Newer++
+		}
+	}
+	d.status.LastSync = now
+	d.status.Watermark = out.GetWatermark()
+	d.status.Seq = out.GetSeqWatermark()
+	d.journal = out.HasSeqWatermark()
+	d.status.ClockSkew = skew
+	d.status.Syncs++
+	if !d.skewWarned {
+		d.skewWarned = warnSkew(os.Stderr, skew)
+	} else {
+		d.skewWarned = skewed(skew)
+	}
+	return nil
+}
+
+func (d *syncDaemon) snapshot() syncStatus {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.status
+}
+
+func (d *syncDaemon) setError(err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.status.LastError = ""
+	if err != nil {
+		d.status.LastError = err.Error()
+	}
+}
+
+// serve answers every connection on ln with the current status.
+func (d *syncDaemon) serve(ln net.Listener) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		_ = conn.SetDeadline(time.Now().Add(time.Second))
+		_ = json.NewEncoder(conn).Encode(d.snapshot())
+		_ = conn.Close()
+	}
+}
+
+// run polls until ctx is done, refreshing immediately on start and
+// whenever a push arrives on wake.
+func (d *syncDaemon) run(ctx context.Context) {
+	t := time.NewTicker(d.interval)
+	defer t.Stop()
+	for {
+		err := d.refresh(ctx)
+		d.setError(err)
+		if err != nil {
+			fmt.Fprint(os.Stderr, colors(os.Stderr).bad(tr("%s sync failed: %v\n", time.Now().Format(time.RFC3339), err)))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		case <-d.wake:
+		}
+	}
+}
+
+// longPoll holds a GetChangesLongPoll call open and signals wake whenever it
+// reports a change, for networks where the ntfy stream is not an option.
+func (d *syncDaemon) longPoll(ctx context.Context) {
+	backoff := time.Second
+	for ctx.Err() == nil {
+		changed, err := d.pollOnce(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if status.Code(err) == codes.Unimplemented {
+			fmt.Fprintln(os.Stderr, colors(os.Stderr).warn(tr("server does not support long polling; using -interval only")))
+			return
+		}
+		if err == nil {
+			backoff = time.Second
+			if changed {
+				select {
+				case d.wake <- struct{}{}:
+				default:
+				}
+			}
+			continue
+		}
+		fmt.Fprint(os.Stderr, colors(os.Stderr).warn(tr("long poll failed: %v; retrying in %s\n", err, backoff)))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, time.Minute)
+	}
+}
+
+// pollOnce makes one long-poll call from the last synced journal position,
+// or from the version watermark on servers without a journal. Per-item
+// versions miss edits to items below the max version; seqs do not.
+func (d *syncDaemon) pollOnce(ctx context.Context) (bool, error) {
+	token, err := loadToken()
+	if err != nil {
+		return false, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, longPollSeconds*time.Second+10*time.Second)
+	defer cancel()
+	conn, cli, err := dial(ctx, d.addr, d.caPath, d.insecure, token)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	req := &pb.GetChangesLongPollRequest{}
+	d.mu.Lock()
+	if d.journal {
+		req.SetSinceSeq(d.status.Seq)
+	} else {
+		req.SetSinceVer(d.status.Watermark)
+	}
+	d.mu.Unlock()
+	req.SetWaitSeconds(longPollSeconds)
+	out, err := cli.GetChangesLongPoll(ctx, req)
+	if err != nil {
+		return false, err
+	}
+	return out.GetChanged(), nil
+}
+
+// listenSyncd claims the daemon socket, clearing a stale one left by a crashed daemon.
+func listenSyncd() (net.Listener, error) {
+	if _, err := querySyncd(); err == nil {
+		return nil, errors.New("syncd is already running")
+	}
+	_ = os.MkdirAll(cfgDir(), 0o700)
+	_ = os.Remove(syncdSocketPath())
+	ln, err := net.Listen("unix", syncdSocketPath())
+	if err != nil {
+		return nil, err
+	}
+	_ = os.Chmod(syncdSocketPath(), 0o600)
+	return ln, nil
+}
+
+// cmdSyncd runs the background sync daemon, or prints its status.
+func cmdSyncd(args []string, addr, caPath string, insecure bool) {
+	if len(args) > 0 && args[0] == "status" {
+		st, err := querySyncd()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, colors(os.Stderr).warn(tr("syncd is not running")))
+			os.Exit(exitGeneric)
+		}
+		emit(st, func() {
+			fmt.Printf(tr("pid %d, user %s, %d items, last sync %s (every %s, %d syncs)\n"),
+				st.PID, st.UserID, st.Items, st.LastSync.Format(time.RFC3339), st.Interval, st.Syncs)
+			c := colors(os.Stdout)
+			if st.Filtered > 0 {
+				fmt.Printf(tr("%d items not mirrored (sync-filter)\n"), st.Filtered)
+			}
+			if st.Newer > 0 {
+				fmt.Print(c.warn(tr("%d items were saved by a newer client; upgrade to read them\n", st.Newer)))
+			}
+			if skewed(st.ClockSkew) {
+				fmt.Print(c.warn(tr("clock skew: %s (server minus local)\n", st.ClockSkew)))
+			}
+			if st.LastError != "" {
+				fmt.Print(c.bad(tr("last error: %s\n", st.LastError)))
+			}
+		})
+		return
+	}
+
+	fs := flag.NewFlagSet("syncd", flag.ExitOnError)
+	interval := fs.Duration("interval", 30*time.Second, "poll interval")
+	ntfy := fs.String("ntfy", "", "also sync on pushes to this ntfy topic URL (see device add -platform ntfy)")
+	longPoll := fs.Bool("long-poll", false, "also sync as soon as a long-polling call sees a change")
+	parseFlags(fs, args)
+	if *interval < time.Second {
+		fmt.Fprintln(os.Stderr, tr("-interval must be at least 1s"))
+		os.Exit(exitUsage)
+	}
+
+	ln, err := listenSyncd()
+	if err != nil {
+		fail(err)
+	}
+	defer func() {
+		_ = ln.Close()
+		_ = os.Remove(syncdSocketPath())
+	}()
+
+	d := &syncDaemon{addr: addr, caPath: caPath, insecure: insecure, interval: *interval, wake: make(chan struct{}, 1)}
+	d.status = syncStatus{PID: os.Getpid(), Addr: addr, Interval: *interval, StartedAt: time.Now()}
+	go d.serve(ln)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	fmt.Fprintf(os.Stderr, tr("syncd: polling %s every %s, socket %s\n"), addr, *interval, syncdSocketPath())
+	if *ntfy != "" {
+		go watchNtfy(ctx, *ntfy, d.wake)
+	}
+	if *longPoll {
+		go d.longPoll(ctx)
+	}
+	d.run(ctx)
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func Test_vaultCache_RoundTrip(t *testing.T) {
+	_ = withTmpConfig(t)
+
+	eb := &pb.EncryptedBlob{}
+	eb.SetCiphertext([]byte{1, 2, 3})
+	live := &pb.Change{}
+	live.SetId("a")
+	live.SetVer(2)
+	live.SetUpdatedAt(timestamppb.New(time.Unix(1700000000, 0)))
+	live.SetBlobEnc(eb)
+	gone := &pb.Change{}
+	gone.SetId("b")
+	gone.SetVer(3)
+	gone.SetDeleted(true)
+
+	if err := saveCache(cacheFromChanges("u1", []*pb.Change{live, gone}, time.Now())); err != nil {
+		t.Fatalf("saveCache: %v", err)
+	}
+	c, err := loadCache()
+	if err != nil || c.UserID != "u1" || len(c.Changes) != 2 {
+		t.Fatalf("loadCache: %+v %v", c, err)
+	}
+	got := c.protoChanges()
+	if got[0].GetId() != "a" || string(got[0].GetBlobEnc().GetCiphertext()) != "\x01\x02\x03" ||
+		!got[0].GetUpdatedAt().AsTime().Equal(time.Unix(1700000000, 0)) {
+		t.Fatalf("live change: %v", got[0])
+	}
+	if !got[1].GetDeleted() || got[1].HasBlobEnc() {
+		t.Fatalf("tombstone: %v", got[1])
+	}
+}
+
+func Test_cacheFromChanges_NewerSchema(t *testing.T) {
+	h := &pb.ItemHints{}
+	h.SetSchemaVersion(payloadSchema + 1)
+	newer := &pb.Change{}
+	newer.SetId("n")
+	newer.SetVer(1)
+	newer.SetHints(h)
+	c := cacheFromChanges("u1", []*pb.Change{newer}, time.Now())
+	if !c.Changes[0].Newer || len(c.protoChanges()) != 0 {
+		t.Fatalf("item of a newer schema: %+v", c.Changes)
+	}
+}
+
+func Test_itemHints(t *testing.T) {
+	if h := itemHints(make([]byte, smallBlobMax)); h.GetSizeClass() != pb.SizeClass_SIZE_CLASS_SMALL || h.GetSchemaVersion() != payloadSchema {
+		t.Fatalf("small: %v", h)
+	}
+	if h := itemHints(make([]byte, smallBlobMax+1)); h.GetSizeClass() != pb.SizeClass_SIZE_CLASS_LARGE {
+		t.Fatalf("large: %v", h)
+	}
+}
+
+func Test_warmChanges_RequiresLiveFreshDaemon(t *testing.T) {
+	_ = withTmpConfig(t)
+	if err := saveDEK([]byte("x")); err != nil { // creates cfg dir
+		t.Fatal(err)
+	}
+	if err := saveUserID("u1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := saveCache(cacheFromChanges("u1", nil, time.Now())); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := warmChanges(); ok {
+		t.Fatalf("cache must not be used without a running daemon")
+	}
+
+	ln, err := listenSyncd()
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	d := &syncDaemon{interval: time.Minute}
+	d.status = syncStatus{UserID: "u1", Interval: time.Minute, LastSync: time.Now()}
+	go d.serve(ln)
+
+	if st, err := querySyncd(); err != nil || st.UserID != "u1" {
+		t.Fatalf("querySyncd: %+v %v", st, err)
+	}
+	if _, err := listenSyncd(); err == nil {
+		t.Fatalf("second daemon must be refused")
+	}
+	if _, ok := warmChanges(); !ok {
+		t.Fatalf("want warm cache")
+	}
+
+	noCache = true
+	_, ok := warmChanges()
+	noCache = false
+	if ok {
+		t.Fatalf("-no-cache must bypass the cache")
+	}
+
+	if err := saveCache(cacheFromChanges("u1", nil, time.Now().Add(-time.Hour))); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := warmChanges(); ok {
+		t.Fatalf("stale cache must not be used")
+	}
+
+	if err := saveCache(cacheFromChanges("someone-else", nil, time.Now())); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := warmChanges(); ok {
+		t.Fatalf("cache of another user must not be used")
+	}
+}
@@ -0,0 +1,253 @@
+// cmd/cli/syncfilter.go
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/internal/merkle"
+)
+
+// syncFilter restricts which items gk sync lists and syncd mirrors. Rules
+// are type:NAME, tag:NAME or folder:PATH (PATH matches its subfolders too).
+// An item is kept when it matches some include rule (or there are none) and
+// no exclude rule. Rules are checked after decryption, so the server never
+// learns them. Items pinned to other devices are left out whatever the
+// rules say.
+type syncFilter struct {
+	Include []string `json:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+	// Device is the profile's device id, set on load; "" skips pins.
+	Device string `json:"-"`
+}
+
+func syncFilterPath() string { return filepath.Join(cfgDir(), "sync_filter.json") }
+
+func (f syncFilter) empty() bool { return len(f.Include) == 0 && len(f.Exclude) == 0 }
+
+// active reports whether f may drop anything: it has rules or pins apply.
+func (f syncFilter) active() bool { return !f.empty() || f.Device != "" }
+
+// loadSyncFilter reads the profile's filter; a missing file is no rules.
+func loadSyncFilter() (syncFilter, error) {
+	var f syncFilter
+	dev, err := thisDevice()
+	if err != nil {
+		return f, err
+	}
+	b, err := readProfileFile(syncFilterPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return syncFilter{Device: dev}, nil
+	}
+	if err != nil {
+		return f, err
+	}
+	if err := json.Unmarshal(b, &f); err != nil {
+		return f, fmt.Errorf("%s: %w", syncFilterPath(), err)
+	}
+	f.Device = dev
+	return f, f.validate()
+}
+
+func saveSyncFilter(f syncFilter) error {
+	if f.empty() {
+		err := os.Remove(syncFilterPath())
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	b, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeProfileFile(syncFilterPath(), b)
+}
+
+// parseFilterRule splits kind:value and checks the kind.
+func parseFilterRule(r string) (kind, value string, err error) {
+	kind, value, ok := strings.Cut(r, ":")
+	if !ok || value == "" {
+		return "", "", fmt.Errorf("%w: filter rule %q: want type:NAME, tag:NAME or folder:PATH", errInvalidInput, r)
+	}
+	switch kind {
+	case "type", "tag", "folder":
+		return kind, value, nil
+	}
+	return "", "", fmt.Errorf("%w: filter rule %q: unknown kind %q", errInvalidInput, r, kind)
+}
+
+func (f syncFilter) validate() error {
+	for _, r := range slices.Concat(f.Include, f.Exclude) {
+		if _, _, err := parseFilterRule(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// filterMeta is the part of an item's meta that rules look at. Tags may be
+// a list or a comma-separated string.
+type filterMeta struct {
+	Folder string          `json:"folder"`
+	Tags   json.RawMessage `json:"tags"`
+}
+
+func (m filterMeta) tags() []string {
+	var list []string
+	if json.Unmarshal(m.Tags, &list) == nil {
+		return list
+	}
+	var s string
+	if json.Unmarshal(m.Tags, &s) == nil {
+		for _, t := range strings.Split(s, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				list = append(list, t)
+			}
+		}
+	}
+	return list
+}
+
+func matchRule(rule string, p typedPayload, m filterMeta) bool {
+	kind, value, _ := parseFilterRule(rule)
+	switch kind {
+	case "type":
+		return strings.EqualFold(p.Type, value)
+	case "tag":
+		return slices.ContainsFunc(m.tags(), func(t string) bool { return strings.EqualFold(t, value) })
+	case "folder":
+		folder, value := strings.Trim(m.Folder, "/"), strings.Trim(value, "/")
+		return folder == value || strings.HasPrefix(folder, value+"/")
+	}
+	return false
+}
+
+// keep reports whether the filter lets p through.
+func (f syncFilter) keep(p typedPayload) bool {
+	if f.Device != "" && pinnedAway(p, f.Device) {
+		return false
+	}
+	var m filterMeta
+	_ = json.Unmarshal(p.Meta, &m)
+	match := func(r string) bool { return matchRule(r, p, m) }
+	if len(f.Include) > 0 && !slices.ContainsFunc(f.Include, match) {
+		return false
+	}
+	return !slices.ContainsFunc(f.Exclude, match)
+}
+
+// keepChange applies f to a wire change. Tombstones and items that cannot be
+// decrypted or decoded are kept: dropping them would hide problems.
+func (f syncFilter) keepChange(uid string, c *pb.Change) bool {
+	if !f.active() || c.GetDeleted() {
+		return true
+	}
+	pt, err := decryptForItem(c.GetId(), uid, c.GetVer(), c.GetBlobEnc().GetCiphertext())
+	if err != nil {
+		return true
+	}
+	var obj typedPayload
+	if json.Unmarshal(pt, &obj) != nil {
+		return true
+	}
+	return f.keep(obj)
+}
+
+// filterChanges returns the changes f keeps.
+func filterChanges(f syncFilter, uid string, cs []*pb.Change) []*pb.Change {
+	if !f.active() {
+		return cs
+	}
+	out := make([]*pb.Change, 0, len(cs))
+	for _, c := range cs {
+		if f.keepChange(uid, c) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// applySyncFilter drops the blobs of items f excludes from a snapshot. The
+// entries stay, with the blob's hash, so gk verify still covers them.
+func applySyncFilter(c *vaultCache, f syncFilter, cs []*pb.Change) {
+	if !f.active() {
+		return
+	}
+	if !f.empty() {
+		c.Filter = &f
+	}
+	for i, ch := range cs {
+		if f.keepChange(c.UserID, ch) {
+			continue
+		}
+		h := merkle.BlobHash(c.Changes[i].Blob)
+		c.Changes[i].Blob, c.Changes[i].BlobHash, c.Changes[i].Filtered = nil, h[:], true
+	}
+}
+
+// cmdSyncFilter shows or changes the profile's sync filter.
+func cmdSyncFilter(args []string) {
+	fs := flag.NewFlagSet("sync-filter", flag.ExitOnError)
+	var include, exclude, remove stringList
+	fs.Var(&include, "include", "add an include rule (type:T, tag:T, folder:PATH); repeatable")
+	fs.Var(&exclude, "exclude", "add an exclude rule; repeatable")
+	fs.Var(&remove, "remove", "remove a rule from either list; repeatable")
+	clearAll := fs.Bool("clear", false, "remove all rules")
+	parseFlags(fs, args)
+
+	f, err := loadSyncFilter()
+	if err != nil {
+		fail(err)
+	}
+	changed := *clearAll || len(include)+len(exclude)+len(remove) > 0
+	if *clearAll {
+		f = syncFilter{Device: f.Device}
+	}
+	for _, r := range remove {
+		f.Include = slices.DeleteFunc(f.Include, func(s string) bool { return s == r })
+		f.Exclude = slices.DeleteFunc(f.Exclude, func(s string) bool { return s == r })
+	}
+	for _, r := range include {
+		if !slices.Contains(f.Include, r) {
+			f.Include = append(f.Include, r)
+		}
+	}
+	for _, r := range exclude {
+		if !slices.Contains(f.Exclude, r) {
+			f.Exclude = append(f.Exclude, r)
+		}
+	}
+	if changed {
+		if err := f.validate(); err != nil {
+			fail(err)
+		}
+		if err := saveSyncFilter(f); err != nil {
+			fail(err)
+		}
+	}
+	view := struct {
+		syncFilter
+		Device string `json:"device"`
+	}{f, f.Device}
+	emit(view, func() {
+		fmt.Println(tr("this device: %s", f.Device))
+		if f.empty() {
+			fmt.Println(tr("no sync filter: every item not pinned to another device is mirrored"))
+			return
+		}
+		for _, r := range f.Include {
+			fmt.Println("include", r)
+		}
+		for _, r := range f.Exclude {
+			fmt.Println("exclude", r)
+		}
+	})
+}
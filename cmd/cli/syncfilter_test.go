@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	cc "github.com/and161185/goph-keeper/internal/crypto/clientcrypto"
+	"github.com/and161185/goph-keeper/internal/merkle"
+)
+
+func Test_syncFilter_keep(t *testing.T) {
+	t.Parallel()
+	item := func(typ, meta string) typedPayload {
+		return typedPayload{Type: typ, Meta: []byte(meta)}
+	}
+	bin := item("binary", `{"title":"iso","tags":["big","media"]}`)
+	work := item("login", `{"folder":"Work/Infra","tags":"vpn, work"}`)
+	home := item("login", `{"folder":"Home"}`)
+
+	cases := []struct {
+		f    syncFilter
+		p    typedPayload
+		want bool
+	}{
+		{syncFilter{}, bin, true},
+		{syncFilter{Exclude: []string{"type:binary"}}, bin, false},
+		{syncFilter{Exclude: []string{"type:binary"}}, work, true},
+		{syncFilter{Exclude: []string{"tag:MEDIA"}}, bin, false},
+		{syncFilter{Include: []string{"tag:work"}}, work, true},
+		{syncFilter{Include: []string{"tag:work"}}, home, false},
+		{syncFilter{Include: []string{"folder:Work"}}, work, true},
+		{syncFilter{Include: []string{"folder:Wor"}}, work, false},
+		{syncFilter{Include: []string{"folder:Work"}, Exclude: []string{"tag:vpn"}}, work, false},
+	}
+	for i, c := range cases {
+		if got := c.f.keep(c.p); got != c.want {
+			t.Errorf("case %d %+v: got %v", i, c.f, got)
+		}
+	}
+
+	if err := (syncFilter{Include: []string{"title:x"}}).validate(); !errors.Is(err, errInvalidInput) {
+		t.Fatalf("unknown kind accepted: %v", err)
+	}
+	if err := (syncFilter{Exclude: []string{"type:"}}).validate(); !errors.Is(err, errInvalidInput) {
+		t.Fatalf("empty value accepted: %v", err)
+	}
+}
+
+func Test_syncFilter_SaveLoad(t *testing.T) {
+	_ = withTmpConfig(t)
+	if f, err := loadSyncFilter(); err != nil || !f.empty() {
+		t.Fatalf("missing file: %+v %v", f, err)
+	}
+	want := syncFilter{Include: []string{"folder:Work"}, Exclude: []string{"type:binary"}}
+	if err := saveSyncFilter(want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := loadSyncFilter()
+	if err != nil || len(got.Include) != 1 || got.Exclude[0] != "type:binary" {
+		t.Fatalf("load: %+v %v", got, err)
+	}
+	if err := saveSyncFilter(syncFilter{}); err != nil {
+		t.Fatal(err)
+	}
+	if f, err := loadSyncFilter(); err != nil || !f.empty() {
+		t.Fatalf("after clear: %+v %v", f, err)
+	}
+}
+
+func Test_applySyncFilter_KeepsVerifyRoot(t *testing.T) {
+	_ = withTmpConfig(t)
+	dek, _ := cc.Rand(cc.DEKLen)
+	if err := saveDEK(dek); err != nil {
+		t.Fatal(err)
+	}
+	change := func(id, typ string) *pb.Change {
+		pt, err := buildTypedPayloadFields(typ, map[string]any{"title": id}, map[string]any{"text": "y"}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		blob, err := encryptForItem(id, "u1", 1, pt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ch := &pb.Change{}
+		ch.SetId(id)
+		ch.SetVer(1)
+		eb := &pb.EncryptedBlob{}
+		eb.SetCiphertext(blob)
+		ch.SetBlobEnc(eb)
+		return ch
+	}
+	gone := &pb.Change{}
+	gone.SetId("c")
+	gone.SetVer(2)
+	gone.SetDeleted(true)
+	cs := []*pb.Change{change("a", "binary"), change("b", "text"), gone}
+
+	f := syncFilter{Exclude: []string{"type:binary"}}
+	if got := filterChanges(f, "u1", cs); len(got) != 2 || got[0].GetId() != "b" || got[1].GetId() != "c" {
+		t.Fatalf("filterChanges kept %d", len(got))
+	}
+
+	full := cacheFromChanges("u1", cs, time.Now())
+	c := cacheFromChanges("u1", cs, time.Now())
+	applySyncFilter(&c, f, cs)
+	if !c.Changes[0].Filtered || c.Changes[0].Blob != nil || c.Changes[1].Filtered || c.Filter == nil {
+		t.Fatalf("filtered cache: %+v", c.Changes)
+	}
+	if a, b := merkle.Root(cacheLeaves(full)), merkle.Root(cacheLeaves(c)); !bytes.Equal(a[:], b[:]) {
+		t.Fatal("filtering changed the verify root")
+	}
+	if got := c.protoChanges(); len(got) != 2 || got[0].GetId() != "b" {
+		t.Fatalf("protoChanges served a filtered item: %d", len(got))
+	}
+}
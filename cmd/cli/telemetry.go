@@ -0,0 +1,264 @@
+// cmd/cli/telemetry.go
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// telemetrySettings is telemetry.json. Telemetry is off until the user
+// turns it on with gk telemetry on.
+type telemetrySettings struct {
+	Enabled bool   `json:"enabled"`
+	URL     string `json:"url,omitempty"`
+}
+
+// telemetryEvent is everything gk reports about one command: no arguments,
+// server address, account or machine, so events cannot be tied to anyone.
+type telemetryEvent struct {
+	Command    string `json:"command"`
+	DurationMS int64  `json:"duration_ms"`
+	Class      string `json:"class"` // "ok", or the error class -error-json prints
+}
+
+const (
+	telemetryBatch    = 20   // events are sent once this many are queued
+	telemetryMaxQueue = 1000 // while the endpoint is unreachable, older events are dropped
+	telemetryTimeout  = 3 * time.Second
+)
+
+func telemetryPath() string      { return filepath.Join(cfgDir(), "telemetry.json") }
+func telemetryQueuePath() string { return filepath.Join(cfgDir(), "telemetry_queue.jsonl") }
+
+// timedCommand is the command main is timing.
+type timedCommand struct {
+	name  string
+	start time.Time
+}
+
+// running is nil when main times no command.
+var running *timedCommand
+
+func loadTelemetry() (telemetrySettings, error) {
+	var st telemetrySettings
+	b, err := os.ReadFile(telemetryPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return st, nil
+	}
+	if err != nil {
+		return st, err
+	}
+	if err := json.Unmarshal(b, &st); err != nil {
+		return st, fmt.Errorf("%s: %w", telemetryPath(), err)
+	}
+	return st, nil
+}
+
+func saveTelemetry(st telemetrySettings) error {
+	b, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	_ = os.MkdirAll(cfgDir(), 0o700)
+	return os.WriteFile(telemetryPath(), b, 0o600)
+}
+
+// startTelemetry starts timing the command main runs.
+func startTelemetry(name string) {
+	running = &timedCommand{name, time.Now()}
+}
+
+// finishTelemetry ends the timed command with class and, if telemetry is
+// on, queues its event and sends the queue once it holds a batch. It never
+// fails the command: a broken queue or endpoint only loses events.
+func finishTelemetry(class string) {
+	r := running
+	running = nil
+	if r == nil {
+		return
+	}
+	st, err := loadTelemetry()
+	if err != nil || !st.Enabled {
+		return
+	}
+	n, err := queueTelemetry(telemetryEvent{Command: r.name, DurationMS: time.Since(r.start).Milliseconds(), Class: class})
+	if err == nil && n >= telemetryBatch {
+		_ = flushTelemetry(st.URL)
+	}
+}
+
+// queueTelemetry appends ev to the queue and returns how many it holds.
+// Appends of one line do not interleave, so concurrent gk runs may share it.
+func queueTelemetry(ev telemetryEvent) (int, error) {
+	line, err := json.Marshal(ev)
+	if err != nil {
+		return 0, err
+	}
+	_ = os.MkdirAll(cfgDir(), 0o700)
+	f, err := os.OpenFile(telemetryQueuePath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return 0, err
+	}
+	_, err = f.Write(append(line, '\n'))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return 0, err
+	}
+	events, err := readTelemetryQueue(telemetryQueuePath())
+	return len(events), err
+}
+
+func readTelemetryQueue(path string) ([]telemetryEvent, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var events []telemetryEvent
+	for _, line := range bytes.Split(b, []byte("\n")) {
+		var ev telemetryEvent
+		if json.Unmarshal(line, &ev) == nil && ev.Command != "" {
+			events = append(events, ev) // a torn line is skipped
+		}
+	}
+	return events, nil
+}
+
+// flushTelemetry sends the queue to endpoint. The queue is renamed first,
+// so of several gk runs only one sends it; if sending fails its events go
+// back to the queue, the newest telemetryMaxQueue of them.
+func flushTelemetry(endpoint string) error {
+	sending := telemetryQueuePath() + "." + strconv.Itoa(os.Getpid())
+	if err := os.Rename(telemetryQueuePath(), sending); err != nil {
+		return err
+	}
+	defer os.Remove(sending)
+	events, err := readTelemetryQueue(sending)
+	if err != nil || len(events) == 0 {
+		return err
+	}
+	if err = postTelemetry(endpoint, events); err == nil {
+		return nil
+	}
+	if len(events) > telemetryMaxQueue {
+		events = events[len(events)-telemetryMaxQueue:]
+	}
+	var buf bytes.Buffer
+	for _, ev := range events {
+		line, _ := json.Marshal(ev)
+		buf.Write(append(line, '\n'))
+	}
+	f, ferr := os.OpenFile(telemetryQueuePath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if ferr != nil {
+		return errors.Join(err, ferr)
+	}
+	defer f.Close()
+	_, ferr = f.Write(buf.Bytes())
+	return errors.Join(err, ferr)
+}
+
+// postTelemetry sends events as {"events": [...]}.
+func postTelemetry(endpoint string, events []telemetryEvent) error {
+	body, err := json.Marshal(map[string][]telemetryEvent{"events": events})
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), telemetryTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("telemetry endpoint: %s", resp.Status)
+	}
+	return nil
+}
+
+// telemetryStatus is what gk telemetry status prints.
+type telemetryStatus struct {
+	Enabled bool             `json:"enabled"`
+	URL     string           `json:"url,omitempty"`
+	Queued  []telemetryEvent `json:"queued"` // exactly what the next send holds
+}
+
+// cmdTelemetry turns the anonymous usage report on or off, or shows it.
+func cmdTelemetry(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, tr("usage: telemetry on -url URL | off | status"))
+		os.Exit(exitUsage)
+	}
+	fs := flag.NewFlagSet("telemetry "+args[0], flag.ExitOnError)
+	var endpoint *string
+	if args[0] == "on" {
+		endpoint = fs.String("url", "", "where to send events, http(s)://; default: the last one given")
+	}
+	parseFlags(fs, args[1:])
+
+	st, err := loadTelemetry()
+	if err != nil {
+		fail(err)
+	}
+	switch args[0] {
+	case "on":
+		if *endpoint != "" {
+			u, err := url.Parse(*endpoint)
+			if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+				fail(fmt.Errorf("%w: -url must be an http(s) URL", errInvalidInput))
+			}
+			st.URL = *endpoint
+		}
+		if st.URL == "" {
+			fmt.Fprintln(os.Stderr, tr("need -url"))
+			os.Exit(exitUsage)
+		}
+		st.Enabled = true
+		if err := saveTelemetry(st); err != nil {
+			fail(err)
+		}
+		fmt.Print(tr("telemetry on: each command's name, duration and error class go to %s\n", st.URL))
+	case "off":
+		st.Enabled = false
+		if err := saveTelemetry(st); err != nil {
+			fail(err)
+		}
+		// unsent events go too: off means nothing more leaves this machine
+		if err := os.Remove(telemetryQueuePath()); err != nil && !errors.Is(err, os.ErrNotExist) {
+			fail(err)
+		}
+		fmt.Print(tr("telemetry off; unsent events deleted\n"))
+	case "status":
+		queued, err := readTelemetryQueue(telemetryQueuePath())
+		if err != nil {
+			fail(err)
+		}
+		out := telemetryStatus{Enabled: st.Enabled, URL: st.URL, Queued: queued}
+		if out.Queued == nil {
+			out.Queued = []telemetryEvent{}
+		}
+		printJSON(out)
+	default:
+		fmt.Fprintln(os.Stderr, tr("usage: telemetry on -url URL | off | status"))
+		os.Exit(exitUsage)
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+)
+
+func Test_telemetry(t *testing.T) {
+	_ = withTmpConfig(t)
+	var (
+		mu   sync.Mutex
+		got  []telemetryEvent
+		down = true
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if down {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var body struct{ Events []telemetryEvent }
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		got = append(got, body.Events...)
+	}))
+	t.Cleanup(srv.Close)
+	run := func(n int, class string) {
+		for range n {
+			startTelemetry("item show")
+			finishTelemetry(class)
+		}
+	}
+
+	run(1, "ok")
+	if _, err := os.Stat(telemetryQueuePath()); !os.IsNotExist(err) {
+		t.Fatalf("queued while off: %v", err)
+	}
+
+	_ = stdoutOf(t, func() { cmdTelemetry([]string{"on", "-url", srv.URL}) })
+	run(telemetryBatch-1, "ok")
+	run(1, "network") // a full batch, but the endpoint is down
+	var st telemetryStatus
+	if err := json.Unmarshal([]byte(stdoutOf(t, func() { cmdTelemetry([]string{"status"}) })), &st); err != nil {
+		t.Fatal(err)
+	}
+	if !st.Enabled || len(st.Queued) != telemetryBatch || st.Queued[telemetryBatch-1] != (telemetryEvent{Command: "item show", Class: "network"}) {
+		t.Fatalf("status after a failed send: %+v", st)
+	}
+
+	mu.Lock()
+	down = false
+	mu.Unlock()
+	run(1, "ok")
+	mu.Lock()
+	n := len(got)
+	mu.Unlock()
+	if n != telemetryBatch+1 {
+		t.Fatalf("sent %d events", n)
+	}
+	if events, _ := readTelemetryQueue(telemetryQueuePath()); len(events) != 0 {
+		t.Fatalf("queue after sending: %+v", events)
+	}
+
+	run(2, "ok")
+	_ = stdoutOf(t, func() { cmdTelemetry([]string{"off"}) })
+	run(1, "ok")
+	if _, err := os.Stat(telemetryQueuePath()); !os.IsNotExist(err) {
+		t.Fatalf("queue kept after off: %v", err)
+	}
+}
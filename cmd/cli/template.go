@@ -0,0 +1,289 @@
+// cmd/cli/template.go
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+)
+
+// Item types added on top of the five add-* types: a saved field layout and
+// the generic record created from it.
+const (
+	typeTemplate = "template"
+	typeCustom   = "custom"
+)
+
+// templateField is one slot of a template layout.
+type templateField struct {
+	Name    string `json:"name"`
+	Hidden  bool   `json:"hidden,omitempty"`
+	Default string `json:"default,omitempty"`
+}
+
+// itemTemplate is the data of a template item.
+type itemTemplate struct {
+	Fields []templateField `json:"fields"`
+}
+
+// parseTemplateFields builds a layout from -field name[=default] and -hidden-field name specs.
+func parseTemplateFields(plain, hidden []string) ([]templateField, error) {
+	var out []templateField
+	seen := map[string]bool{}
+	add := func(spec string, hide bool) error {
+		name, def, _ := strings.Cut(spec, "=")
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return fmt.Errorf("%w: empty template field name in %q", errInvalidInput, spec)
+		}
+		if seen[name] {
+			return fmt.Errorf("%w: template field %q given twice", errInvalidInput, name)
+		}
+		seen[name] = true
+		out = append(out, templateField{Name: name, Hidden: hide, Default: def})
+		return nil
+	}
+	for _, s := range plain {
+		if err := add(s, false); err != nil {
+			return nil, err
+		}
+	}
+	for _, s := range hidden {
+		if err := add(s, true); err != nil {
+			return nil, err
+		}
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("%w: template needs at least one -field or -hidden-field", errInvalidInput)
+	}
+	return out, nil
+}
+
+// fillTemplate resolves a value for every template field: -set values first,
+// then defaults, then ask() for the rest. Unknown -set names are rejected.
+func fillTemplate(tpl itemTemplate, set map[string]string, ask func(f templateField) (string, error)) ([]customField, error) {
+	known := map[string]bool{}
+	for _, f := range tpl.Fields {
+		known[f.Name] = true
+	}
+	for name := range set {
+		if !known[name] {
+			return nil, fmt.Errorf("%w: template has no field %q", errInvalidInput, name)
+		}
+	}
+	out := make([]customField, 0, len(tpl.Fields))
+	for _, f := range tpl.Fields {
+		v, ok := set[f.Name]
+		if !ok {
+			v = f.Default
+		}
+		if v == "" && ask != nil {
+			var err error
+			if v, err = ask(f); err != nil {
+				return nil, err
+			}
+		}
+		out = append(out, customField{Name: f.Name, Value: v, Hidden: f.Hidden})
+	}
+	return out, nil
+}
+
+// promptField asks for a value on stdin (input is echoed).
+func promptField(in *bufio.Reader, out io.Writer) func(templateField) (string, error) {
+	return func(f templateField) (string, error) {
+		hint := ""
+		if f.Hidden {
+			hint = " (secret, input is visible)"
+		}
+		fmt.Fprintf(out, "%s%s: ", f.Name, hint)
+		line, err := in.ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("%w: no value for %q", errInvalidInput, f.Name)
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+}
+
+// namedTemplate is a stored template with its item identity.
+type namedTemplate struct {
+	ID   string
+	Ver  int64
+	Name string
+	Tpl  itemTemplate
+}
+
+// listTemplates returns all stored templates.
+func listTemplates(ctx context.Context, cli pb.GophKeeperClient) ([]namedTemplate, error) {
+	items, err := listTyped(ctx, cli)
+	if err != nil {
+		return nil, err
+	}
+	var out []namedTemplate
+	for _, it := range items {
+		if it.Payload.Type != typeTemplate {
+			continue
+		}
+		var tpl itemTemplate
+		if json.Unmarshal(it.Payload.Data, &tpl) != nil {
+			continue
+		}
+		out = append(out, namedTemplate{ID: it.ID, Ver: it.Ver, Name: metaTitle(it.Payload), Tpl: tpl})
+	}
+	return out, nil
+}
+
+// findTemplate looks a template up by case-insensitive name; nil when absent.
+func findTemplate(list []namedTemplate, name string) *namedTemplate {
+	for i := range list {
+		if strings.EqualFold(list[i].Name, name) {
+			return &list[i]
+		}
+	}
+	return nil
+}
+
+// cmdTemplate dispatches template subcommands.
+func cmdTemplate(args []string, addr, caPath string, insecure bool) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, tr("usage: template save|apply|list ..."))
+		os.Exit(exitUsage)
+	}
+	sub, args := args[0], args[1:]
+
+	fs := flag.NewFlagSet("template "+sub, flag.ExitOnError)
+	name := fs.String("name", "", "template name")
+	var plain, hidden, set stringList
+	var replace *bool
+	var title, note *string
+	switch sub {
+	case "save":
+		fs.Var(&plain, "field", "field name[=default] (repeatable)")
+		fs.Var(&hidden, "hidden-field", "secret field name (repeatable)")
+		replace = fs.Bool("replace", false, "overwrite an existing template with this name")
+	case "apply":
+		title = fs.String("title", "", "title of the new item")
+		note = fs.String("note", "", "note")
+		fs.Var(&set, "set", "field value name=value (repeatable); missing values are prompted")
+	case "list":
+	default:
+		fmt.Fprintf(os.Stderr, tr("unknown template subcommand %q\n"), sub)
+		os.Exit(exitUsage)
+	}
+	parseFlags(fs, args)
+	if sub != "list" && *name == "" {
+		fmt.Fprintln(os.Stderr, tr("need -name"))
+		os.Exit(exitUsage)
+	}
+
+	token, err := loadToken()
+	if err != nil {
+		fail(err)
+	}
+	uid, err := loadUserID()
+	if err != nil {
+		fail(err)
+	}
+	ctx, cancel := withTimeout()
+	defer cancel()
+	conn, cli, err := dial(ctx, addr, caPath, insecure, token)
+	if err != nil {
+		fail(err)
+	}
+	defer conn.Close()
+
+	noCache = true // a template saved a moment ago must be visible
+	tpls, err := listTemplates(ctx, cli)
+	if err != nil {
+		fail(err)
+	}
+
+	switch sub {
+	case "list":
+		emit(tpls, func() {
+			for _, t := range tpls {
+				names := make([]string, len(t.Tpl.Fields))
+				for i, f := range t.Tpl.Fields {
+					names[i] = f.Name
+					if f.Hidden {
+						names[i] += "*"
+					}
+				}
+				fmt.Printf("%-20s %s\n", t.Name, strings.Join(names, ", "))
+			}
+		})
+
+	case "save":
+		fields, err := parseTemplateFields(plain, hidden)
+		if err != nil {
+			fail(err)
+		}
+		id, base := "", int64(0)
+		if old := findTemplate(tpls, *name); old != nil {
+			if !*replace {
+				fail(fmt.Errorf("%w: template %q exists (use -replace)", errInvalidInput, *name))
+			}
+			id, base = old.ID, old.Ver
+		}
+		autoUUID(&id)
+		pt, err := buildTypedPayload(typeTemplate, map[string]any{"title": *name}, itemTemplate{Fields: fields})
+		if err != nil {
+			fail(err)
+		}
+		blob, err := encryptForItem(id, uid, base+1, pt)
+		if err != nil {
+			fail(err)
+		}
+		res, err := sendUpserts(ctx, cli, []pendingUpsert{{ID: id, BaseVer: base, Blob: blob}}, nil)
+		if err != nil {
+			fail(err)
+		}
+		printJSON(res)
+
+	case "apply":
+		t := findTemplate(tpls, *name)
+		if t == nil {
+			fail(fmt.Errorf("template %q: %w", *name, errItemNotFound))
+		}
+		values := map[string]string{}
+		for _, spec := range set {
+			k, v, ok := strings.Cut(spec, "=")
+			if !ok {
+				fail(fmt.Errorf("%w: -set %q: want name=value", errInvalidInput, spec))
+			}
+			values[strings.TrimSpace(k)] = v
+		}
+		ask := promptField(bufio.NewReader(os.Stdin), os.Stderr)
+		if nonInteractive {
+			ask = func(f templateField) (string, error) {
+				return "", fmt.Errorf("%w: no value for %q; give it with -set", errInvalidInput, f.Name)
+			}
+		}
+		fields, err := fillTemplate(t.Tpl, values, ask)
+		if err != nil {
+			fail(err)
+		}
+		var id string
+		autoUUID(&id)
+		meta := map[string]any{"title": choose(*title, t.Name), "template": t.Name, "note": *note}
+		pt, err := buildTypedPayloadFields(typeCustom, meta, map[string]any{}, fields)
+		if err != nil {
+			fail(err)
+		}
+		blob, err := encryptForItem(id, uid, 1, pt)
+		if err != nil {
+			fail(err)
+		}
+		res, err := sendUpserts(ctx, cli, []pendingUpsert{{ID: id, Blob: blob}}, nil)
+		if err != nil {
+			fail(err)
+		}
+		printJSON(res)
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func Test_parseTemplateFields(t *testing.T) {
+	t.Parallel()
+	got, err := parseTemplateFields([]string{"ssid", "security=WPA2"}, []string{"password"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []templateField{{"ssid", false, ""}, {"security", false, "WPA2"}, {"password", true, ""}}
+	if len(got) != 3 || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Fatalf("fields: %+v", got)
+	}
+	for _, c := range [][2][]string{{nil, nil}, {{"=x"}, nil}, {{"a"}, {"a"}}} {
+		if _, err := parseTemplateFields(c[0], c[1]); !errors.Is(err, errInvalidInput) {
+			t.Fatalf("%v: want errInvalidInput, got %v", c, err)
+		}
+	}
+}
+
+func Test_fillTemplate(t *testing.T) {
+	t.Parallel()
+	tpl := itemTemplate{Fields: []templateField{{Name: "ssid"}, {Name: "security", Default: "WPA2"}, {Name: "password", Hidden: true}}}
+
+	var asked []string
+	ask := func(f templateField) (string, error) {
+		asked = append(asked, f.Name)
+		return "typed-" + f.Name, nil
+	}
+	got, err := fillTemplate(tpl, map[string]string{"ssid": "home"}, ask)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []customField{{"ssid", "home", false}, {"security", "WPA2", false}, {"password", "typed-password", true}}
+	if len(got) != 3 || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Fatalf("fields: %+v", got)
+	}
+	if len(asked) != 1 || asked[0] != "password" {
+		t.Fatalf("asked: %v", asked)
+	}
+
+	if _, err := fillTemplate(tpl, map[string]string{"bssid": "x"}, ask); !errors.Is(err, errInvalidInput) {
+		t.Fatalf("unknown -set name: %v", err)
+	}
+}
+
+func Test_promptField(t *testing.T) {
+	t.Parallel()
+	ask := promptField(bufio.NewReader(strings.NewReader("home\r\n")), io.Discard)
+	if v, err := ask(templateField{Name: "ssid"}); err != nil || v != "home" {
+		t.Fatalf("got %q %v", v, err)
+	}
+	if _, err := ask(templateField{Name: "password"}); !errors.Is(err, errInvalidInput) {
+		t.Fatalf("EOF: %v", err)
+	}
+}
+
+func Test_findTemplate(t *testing.T) {
+	t.Parallel()
+	list := []namedTemplate{{ID: "1", Name: "Wifi"}, {ID: "2", Name: "bank account"}}
+	if got := findTemplate(list, "wifi"); got == nil || got.ID != "1" {
+		t.Fatalf("case-insensitive lookup: %+v", got)
+	}
+	if findTemplate(list, "license") != nil {
+		t.Fatal("want nil")
+	}
+}
@@ -0,0 +1,11 @@
+//go:build darwin || freebsd || netbsd || openbsd
+
+// cmd/cli/term_bsd.go
+package main
+
+import "golang.org/x/sys/unix"
+
+const (
+	ioctlGetTermios = unix.TIOCGETA
+	ioctlSetTermios = unix.TIOCSETA
+)
@@ -0,0 +1,9 @@
+// cmd/cli/term_linux.go
+package main
+
+import "golang.org/x/sys/unix"
+
+const (
+	ioctlGetTermios = unix.TCGETS
+	ioctlSetTermios = unix.TCSETS
+)
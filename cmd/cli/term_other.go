@@ -0,0 +1,15 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd && !windows
+
+// cmd/cli/term_other.go
+package main
+
+import "os"
+
+// echoOff is unsupported here; secrets are read with echo on.
+func echoOff(*os.File) (restore func(), ok bool) { return func() {}, false }
+
+// isTerminal is always false here: prompts that need one are skipped.
+func isTerminal(*os.File) bool { return false }
+
+// ansiTerminal is always false here, so output stays plain.
+func ansiTerminal(*os.File) bool { return false }
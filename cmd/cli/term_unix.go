@@ -0,0 +1,37 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd
+
+// cmd/cli/term_unix.go
+package main
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// echoOff stops the terminal on f from echoing input. ok is false when f is
+// not a terminal; restore must be called otherwise.
+func echoOff(f *os.File) (restore func(), ok bool) {
+	fd := int(f.Fd())
+	old, err := unix.IoctlGetTermios(fd, ioctlGetTermios)
+	if err != nil {
+		return func() {}, false
+	}
+	t := *old
+	t.Lflag &^= unix.ECHO
+	t.Lflag |= unix.ICANON | unix.ISIG
+	if err := unix.IoctlSetTermios(fd, ioctlSetTermios, &t); err != nil {
+		return func() {}, false
+	}
+	return func() { _ = unix.IoctlSetTermios(fd, ioctlSetTermios, old) }, true
+}
+
+// isTerminal reports whether f is a terminal.
+func isTerminal(f *os.File) bool {
+	_, err := unix.IoctlGetTermios(int(f.Fd()), ioctlGetTermios)
+	return err == nil
+}
+
+// ansiTerminal reports whether f is a terminal; Unix terminals render ANSI
+// colors as is.
+func ansiTerminal(f *os.File) bool { return isTerminal(f) }
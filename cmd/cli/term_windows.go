@@ -0,0 +1,42 @@
+// cmd/cli/term_windows.go
+package main
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// echoOff stops the console on f from echoing input. ok is false when f is
+// not a console; restore must be called otherwise.
+func echoOff(f *os.File) (restore func(), ok bool) {
+	h := windows.Handle(f.Fd())
+	var mode uint32
+	if err := windows.GetConsoleMode(h, &mode); err != nil {
+		return func() {}, false
+	}
+	if err := windows.SetConsoleMode(h, mode&^windows.ENABLE_ECHO_INPUT); err != nil {
+		return func() {}, false
+	}
+	return func() { _ = windows.SetConsoleMode(h, mode) }, true
+}
+
+// isTerminal reports whether f is a console.
+func isTerminal(f *os.File) bool {
+	var mode uint32
+	return windows.GetConsoleMode(windows.Handle(f.Fd()), &mode) == nil
+}
+
+// ansiTerminal reports whether f is a console that renders ANSI colors,
+// turning on virtual terminal processing if needed (Windows 10 and later).
+func ansiTerminal(f *os.File) bool {
+	h := windows.Handle(f.Fd())
+	var mode uint32
+	if err := windows.GetConsoleMode(h, &mode); err != nil {
+		return false
+	}
+	if mode&windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING != 0 {
+		return true
+	}
+	return windows.SetConsoleMode(h, mode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING) == nil
+}
@@ -0,0 +1,205 @@
+// cmd/cli/totp.go
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/and161185/goph-keeper/internal/totp"
+)
+
+// stringList is a repeatable string flag (-id a -id b).
+type stringList []string
+
+func (s *stringList) String() string { return strings.Join(*s, ",") }
+func (s *stringList) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
+
+// otpEntry is a decoded otp item ready for code generation.
+type otpEntry struct {
+	Label  string
+	Params totp.Params
+}
+
+// parseOTP extracts generator parameters and a display label from an otp payload.
+func parseOTP(obj typedPayload) (otpEntry, error) {
+	if obj.Type != "otp" {
+		return otpEntry{}, fmt.Errorf("not an otp item (type=%s)", obj.Type)
+	}
+	var meta struct {
+		Title  string `json:"title"`
+		Issuer string `json:"issuer"`
+		Digits int    `json:"digits"`
+		Period int    `json:"period"`
+		Algo   string `json:"algo"`
+	}
+	var data struct {
+		Secret string `json:"secret"`
+	}
+	if err := json.Unmarshal(obj.Meta, &meta); err != nil {
+		return otpEntry{}, err
+	}
+	if err := json.Unmarshal(obj.Data, &data); err != nil {
+		return otpEntry{}, err
+	}
+	secret, err := totp.DecodeSecret(data.Secret)
+	if err != nil {
+		return otpEntry{}, fmt.Errorf("bad secret: %w", err)
+	}
+	if meta.Digits == 0 {
+		meta.Digits = 6
+	}
+	if meta.Period == 0 {
+		meta.Period = 30
+	}
+	label := meta.Title
+	if meta.Issuer != "" {
+		label = strings.TrimSpace(label + " (" + meta.Issuer + ")")
+	}
+	return otpEntry{
+		Label:  label,
+		Params: totp.Params{Secret: secret, Digits: meta.Digits, Period: meta.Period, Algo: meta.Algo},
+	}, nil
+}
+
+// countdownBar draws a fixed-width bar proportional to the remaining validity.
+func countdownBar(remain time.Duration, period, width int) string {
+	if period <= 0 || width <= 0 {
+		return ""
+	}
+	filled := int(remain/time.Second) * width / period
+	if filled > width {
+		filled = width
+	}
+	return "[" + strings.Repeat("#", filled) + strings.Repeat(".", width-filled) + "]"
+}
+
+// renderOTPLine formats one watch/print line for an otp entry at time now.
+func renderOTPLine(e otpEntry, now time.Time) string {
+	code, err := totp.Code(e.Params, now)
+	if err != nil {
+		return fmt.Sprintf("%-8s %s", "error", e.Label)
+	}
+	remain := totp.Remaining(e.Params.Period, now)
+	return fmt.Sprintf("%s %2ds  %s  %s",
+		countdownBar(remain, e.Params.Period, 10), int(remain/time.Second), code, e.Label)
+}
+
+// otpRow is the structured form of a generated code for -format output.
+type otpRow struct {
+	ID        string `json:"id"`
+	Label     string `json:"label"`
+	Code      string `json:"code"`
+	Remaining int    `json:"remaining"`
+}
+
+func otpRows(ids []string, entries []otpEntry, now time.Time) []otpRow {
+	rows := make([]otpRow, 0, len(entries))
+	for i, e := range entries {
+		code, _ := totp.Code(e.Params, now)
+		rows = append(rows, otpRow{
+			ID: ids[i], Label: e.Label, Code: code,
+			Remaining: int(totp.Remaining(e.Params.Period, now) / time.Second),
+		})
+	}
+	return rows
+}
+
+// cmdTOTP prints current TOTP codes for one or more otp items; -watch keeps refreshing.
+func cmdTOTP(args []string, addr, caPath string, insecure bool) {
+	fs := flag.NewFlagSet("totp", flag.ExitOnError)
+	var ids stringList
+	fs.Var(&ids, "id", "otp item id (uuid, repeatable)")
+	watch := fs.Bool("watch", false, "keep refreshing codes with a countdown until interrupted")
+	parseFlags(fs, args)
+	if len(ids) == 0 {
+		fmt.Fprintln(os.Stderr, tr("need -id"))
+		os.Exit(2)
+	}
+
+	token, err := loadToken()
+	if err != nil {
+		fail(err)
+	}
+	entries, err := loadOTPEntries(addr, caPath, insecure, token, ids)
+	if err != nil {
+		fail(err)
+	}
+
+	if !*watch {
+		now := time.Now()
+		emit(otpRows(ids, entries, now), func() {
+			for _, e := range entries {
+				fmt.Println(renderOTPLine(e, now))
+			}
+		})
+		return
+	}
+	if err := watchOTP(entries); err != nil && !errors.Is(err, context.Canceled) {
+		fail(err)
+	}
+}
+
+// loadOTPEntries fetches and decodes the given otp items.
+func loadOTPEntries(addr, caPath string, insecure bool, token string, ids []string) ([]otpEntry, error) {
+	ctx, cancel := withTimeout()
+	defer cancel()
+	conn, cli, err := dial(ctx, addr, caPath, insecure, token)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	entries := make([]otpEntry, 0, len(ids))
+	for _, id := range ids {
+		_, obj, err := fetchTyped(ctx, cli, id)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", id, err)
+		}
+		e, err := parseOTP(obj)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", id, err)
+		}
+		if e.Label == "" {
+			e.Label = id
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// watchOTP redraws all entries in place every second until SIGINT.
+func watchOTP(entries []otpEntry) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	draw := func(first bool) {
+		if !first {
+			fmt.Printf("\033[%dA", len(entries))
+		}
+		now := time.Now()
+		for _, e := range entries {
+			fmt.Printf("\033[2K%s\n", renderOTPLine(e, now))
+		}
+	}
+	draw(true)
+	t := time.NewTicker(time.Second)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+			draw(false)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func otpPayload(t *testing.T, meta, data map[string]any) typedPayload {
+	t.Helper()
+	m, _ := json.Marshal(meta)
+	d, _ := json.Marshal(data)
+	return typedPayload{Type: "otp", Meta: m, Data: d}
+}
+
+func Test_parseOTP(t *testing.T) {
+	t.Parallel()
+
+	e, err := parseOTP(otpPayload(t,
+		map[string]any{"title": "Google", "issuer": "ACME", "digits": 8, "period": 60, "algo": "SHA256"},
+		map[string]any{"secret": "JBSWY3DPEHPK3PXP"}))
+	if err != nil {
+		t.Fatalf("parseOTP: %v", err)
+	}
+	if e.Label != "Google (ACME)" || e.Params.Digits != 8 || e.Params.Period != 60 || e.Params.Algo != "SHA256" {
+		t.Fatalf("unexpected entry: %+v", e)
+	}
+
+	e, err = parseOTP(otpPayload(t, map[string]any{}, map[string]any{"secret": "JBSWY3DPEHPK3PXP"}))
+	if err != nil || e.Params.Digits != 6 || e.Params.Period != 30 {
+		t.Fatalf("defaults: %+v %v", e, err)
+	}
+
+	if _, err := parseOTP(typedPayload{Type: "login"}); err == nil {
+		t.Fatalf("want error on non-otp item")
+	}
+	if _, err := parseOTP(otpPayload(t, map[string]any{}, map[string]any{"secret": "!!"})); err == nil {
+		t.Fatalf("want error on bad secret")
+	}
+}
+
+func Test_countdownBar(t *testing.T) {
+	t.Parallel()
+	if got := countdownBar(15*time.Second, 30, 10); got != "[#####.....]" {
+		t.Fatalf("half: %q", got)
+	}
+	if got := countdownBar(30*time.Second, 30, 4); got != "[####]" {
+		t.Fatalf("full: %q", got)
+	}
+	if countdownBar(time.Second, 0, 10) != "" {
+		t.Fatalf("zero period should render nothing")
+	}
+}
+
+func Test_renderOTPLine(t *testing.T) {
+	t.Parallel()
+	e, err := parseOTP(otpPayload(t, map[string]any{"title": "X"}, map[string]any{"secret": "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"}))
+	if err != nil {
+		t.Fatalf("parseOTP: %v", err)
+	}
+	line := renderOTPLine(e, time.Unix(59, 0))
+	if !strings.Contains(line, "287082") || !strings.Contains(line, " 1s") || !strings.HasSuffix(line, "X") {
+		t.Fatalf("unexpected line: %q", line)
+	}
+}
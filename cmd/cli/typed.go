@@ -5,7 +5,6 @@ import (
 	"context"
 	"encoding/base32"
 	"encoding/json"
-	"errors"
 	"flag"
 	"fmt"
 	"io"
@@ -26,21 +25,192 @@ import (
 
 // buildTypedPayload packs {type, meta, data} as JSON bytes.
 func buildTypedPayload(typ string, meta any, data any) ([]byte, error) {
-	w := map[string]any{"type": typ, "meta": meta, "data": data}
-	return json.Marshal(w)
+	return buildTypedPayloadFields(typ, meta, data, nil)
 }
 
+// buildTypedPayloadFields is buildTypedPayload with custom fields. The
+// payload is checked against its type's schema and stamped with its
+// version (see encodePayload).
+func buildTypedPayloadFields(typ string, meta any, data any, fields []customField) ([]byte, error) {
+	m, err := json.Marshal(meta)
+	if err != nil {
+		return nil, err
+	}
+	d, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return encodePayload(typedPayload{Type: typ, Meta: m, Data: d, Fields: fields})
+}
+
+// blobCipher seals new item blobs (-cipher). Decryption reads the cipher from
+// each blob, so vaults may mix both.
+var blobCipher = cc.XChaCha20Poly1305
+
 // encryptForItem encrypts plaintext as blob_enc using HKDF(itemID) and AAD(userID||itemID||ver).
 func encryptForItem(itemID, userID string, ver int64, plaintext []byte) ([]byte, error) {
 	dek, err := loadDEK()
 	if err != nil {
-		return nil, errors.New("no DEK; login first")
+		return nil, errNoDEK
+	}
+	key, err := cc.DeriveItemKey(dek, []byte(itemID))
+	if err != nil {
+		return nil, err
+	}
+	return cc.EncryptBlobWith(blobCipher, key, []byte(userID), []byte(itemID), ver, plaintext)
+}
+
+// decryptForItem reverses encryptForItem for the given item version.
+func decryptForItem(itemID, userID string, ver int64, blob []byte) ([]byte, error) {
+	dek, err := loadDEK()
+	if err != nil {
+		return nil, errNoDEK
 	}
 	key, err := cc.DeriveItemKey(dek, []byte(itemID))
 	if err != nil {
 		return nil, err
 	}
-	return cc.EncryptBlob(key, []byte(userID), []byte(itemID), ver, plaintext)
+	pt, err := cc.DecryptBlob(key, []byte(userID), []byte(itemID), ver, blob)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt: %w: %w", errCrypto, err)
+	}
+	return pt, nil
+}
+
+// typedPayload is the decrypted {type, meta, data} envelope. Decoding is
+// tolerant: keys this client does not know are ignored, so payloads of
+// newer clients still read as far as their known fields go.
+type typedPayload struct {
+	Type   string          `json:"type"`
+	Meta   json.RawMessage `json:"meta"`
+	Data   json.RawMessage `json:"data"`
+	Fields []customField   `json:"fields,omitempty"`
+	// Schema is the version of the type's schema the payload was written
+	// with (payloadTypes); 0 in payloads older than the registry, which
+	// are version 1.
+	Schema int `json:"schema,omitempty"`
+}
+
+// fetchTyped loads a live item by id, decrypts it and decodes the typed envelope.
+func fetchTyped(ctx context.Context, cli pb.GophKeeperClient, id string) (*pb.GetItemResponse, typedPayload, error) {
+	return fetchTypedAt(ctx, cli, id, 0)
+}
+
+// fetchTypedAt is fetchTyped for a historical version (0 = current).
+func fetchTypedAt(ctx context.Context, cli pb.GophKeeperClient, id string, ver int64) (*pb.GetItemResponse, typedPayload, error) {
+	req := &pb.GetItemRequest{}
+	req.SetId(id)
+	req.SetVer(ver)
+	it, err := getItem(ctx, cli, req)
+	if err != nil {
+		return nil, typedPayload{}, err
+	}
+	if it.GetDeleted() {
+		return nil, typedPayload{}, errItemDeleted
+	}
+	uid, err := loadUserID()
+	if err != nil {
+		return nil, typedPayload{}, err
+	}
+	pt, err := decryptForItem(id, uid, it.GetVer(), it.GetBlobEnc().GetCiphertext())
+	if err != nil {
+		return nil, typedPayload{}, err
+	}
+	var obj typedPayload
+	if err := json.Unmarshal(pt, &obj); err != nil {
+		return nil, typedPayload{}, err
+	}
+	return it, obj, nil
+}
+
+// vaultItem is a decrypted live item from a full listing.
+type vaultItem struct {
+	ID        string
+	Ver       int64
+	UpdatedAt time.Time
+	Payload   typedPayload
+}
+
+// listTyped fetches all live items and decrypts those that decode as typed payloads.
+func listTyped(ctx context.Context, cli pb.GophKeeperClient) ([]vaultItem, error) {
+	changes, warm := warmChanges()
+	if !warm {
+		req := &pb.GetChangesRequest{}
+		req.SetSinceVer(0)
+		out, err := fetchChanges(ctx, cli, req)
+		if err != nil {
+			return nil, err
+		}
+		changes = out.GetChanges()
+	}
+	uid, err := loadUserID()
+	if err != nil {
+		return nil, err
+	}
+	var items []vaultItem
+	for _, c := range changes {
+		if c.GetDeleted() {
+			continue
+		}
+		pt, err := decryptForItem(c.GetId(), uid, c.GetVer(), c.GetBlobEnc().GetCiphertext())
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", c.GetId(), err)
+		}
+		var obj typedPayload
+		if json.Unmarshal(pt, &obj) != nil {
+			continue
+		}
+		items = append(items, vaultItem{ID: c.GetId(), Ver: c.GetVer(), UpdatedAt: c.GetUpdatedAt().AsTime(), Payload: obj})
+	}
+	return items, nil
+}
+
+// metaTitle returns meta.title of a typed payload (empty if absent).
+func metaTitle(p typedPayload) string {
+	var m struct {
+		Title string `json:"title"`
+	}
+	_ = json.Unmarshal(p.Meta, &m)
+	return m.Title
+}
+
+// findByTitle resolves a unique live item id by case-insensitive title (optionally of a given type).
+func findByTitle(ctx context.Context, cli pb.GophKeeperClient, title, typ string) (string, error) {
+	items, err := listTyped(ctx, cli)
+	if err != nil {
+		return "", err
+	}
+	var ids []string
+	for _, it := range items {
+		if (typ == "" || it.Payload.Type == typ) && strings.EqualFold(metaTitle(it.Payload), title) {
+			ids = append(ids, it.ID)
+		}
+	}
+	switch len(ids) {
+	case 0:
+		return "", fmt.Errorf("no item titled %q: %w", title, errItemNotFound)
+	case 1:
+		return ids[0], nil
+	default:
+		return "", fmt.Errorf("title %q is ambiguous (%s); use -id", title, strings.Join(ids, ", "))
+	}
+}
+
+// previousPayload returns the current decrypted payload of an item being edited,
+// or nil when it cannot be read (the edit itself still proceeds).
+func previousPayload(addr, caPath string, insecure bool, token, id string) *typedPayload {
+	ctx, cancel := withTimeout()
+	defer cancel()
+	conn, cli, err := dial(ctx, addr, caPath, insecure, token)
+	if err != nil {
+		return nil
+	}
+	defer conn.Close()
+	_, obj, err := fetchTyped(ctx, cli, id)
+	if err != nil {
+		return nil
+	}
+	return &obj
 }
 
 // upsertOne composes UpsertItems request for single item.
@@ -53,7 +223,29 @@ func upsertOne(addr, caPath string, insecure bool, token, itemID string, baseVer
 		return nil, err
 	}
 	defer ccConn.Close()
+	return sendOne(ctx, cli, itemID, baseVer, blob)
+}
+
+// payloadSchema is the version of the {type, meta, data} payload this
+// client writes and can read; it goes out in ItemHints.schema_version.
+const payloadSchema = 1
+
+// smallBlobMax is the longest ciphertext of SIZE_CLASS_SMALL.
+const smallBlobMax = 64 << 10
 
+// itemHints declares blob's size class and the payload schema.
+func itemHints(blob []byte) *pb.ItemHints {
+	h := &pb.ItemHints{}
+	h.SetSizeClass(pb.SizeClass_SIZE_CLASS_LARGE)
+	if len(blob) <= smallBlobMax {
+		h.SetSizeClass(pb.SizeClass_SIZE_CLASS_SMALL)
+	}
+	h.SetSchemaVersion(payloadSchema)
+	return h
+}
+
+// sendOne upserts a single item over an open connection.
+func sendOne(ctx context.Context, cli pb.GophKeeperClient, itemID string, baseVer int64, blob []byte) (*pb.UpsertItemsResponse, error) {
 	eb := &pb.EncryptedBlob{}
 	eb.SetCiphertext(blob)
 
@@ -61,11 +253,16 @@ func upsertOne(addr, caPath string, insecure bool, token, itemID string, baseVer
 	it.SetId(itemID)
 	it.SetBaseVer(baseVer)
 	it.SetBlobEnc(eb)
+	it.SetHints(itemHints(blob))
 
 	req := &pb.UpsertItemsRequest{}
 	req.SetItems([]*pb.UpsertItem{it})
 
-	return cli.UpsertItems(ctx, req)
+	resp, err := cli.UpsertItems(ctx, req)
+	if err == nil {
+		showWarnings(resp.GetWarnings())
+	}
+	return resp, err
 }
 
 func pretty(b []byte) string {
@@ -79,9 +276,11 @@ func pretty(b []byte) string {
 
 // ------- validators -------
 
+// autoUUID fills an empty id with a UUIDv7, so item ids sort by creation
+// time in the server's index and in logs.
 func autoUUID(id *string) {
 	if *id == "" {
-		v, _ := u.NewV4()
+		v, _ := u.NewV7()
 		*id = v.String()
 	}
 }
@@ -126,21 +325,36 @@ func cmdAddLogin(args []string, addr, caPath string, insecure bool) {
 	pass := fs.String("password", "", "password")
 	note := fs.String("note", "", "note")
 	base := fs.Int64("base", 0, "base version (0 for create)")
-	_ = fs.Parse(args)
+	dryRun := dryRunFlag(fs)
+	customFields := customFieldFlags(fs)
+	parseFlags(fs, args)
+	fields, err := customFields()
+	if err != nil {
+		fail(err)
+	}
 
 	autoUUID(id)
 	if *user == "" || *pass == "" {
-		fmt.Fprintln(os.Stderr, "username and password required")
+		fmt.Fprintln(os.Stderr, tr("username and password required"))
 		os.Exit(2)
 	}
 	meta := map[string]any{"title": *title, "url": *url, "username": *user, "note": *note}
 	data := map[string]any{"password": *pass}
-	pt, _ := buildTypedPayload("login", meta, data)
 
 	token, err := loadToken()
 	if err != nil {
 		fail(err)
 	}
+	var prev *typedPayload
+	if *base > 0 {
+		prev = previousPayload(addr, caPath, insecure, token, *id)
+	}
+	stampPasswordChange(meta, *pass, prev, time.Now())
+	pt, err := buildTypedPayloadFields("login", meta, data, fields)
+	if err != nil {
+		fail(err)
+	}
+
 	uid, err := loadUserID()
 	if err != nil {
 		fail(err)
@@ -149,6 +363,10 @@ func cmdAddLogin(args []string, addr, caPath string, insecure bool) {
 	if err != nil {
 		fail(err)
 	}
+	if *dryRun {
+		printDryRun(plannedUpserts([]pendingUpsert{{ID: *id, BaseVer: *base, Blob: blob}}))
+		return
+	}
 	resp, err := upsertOne(addr, caPath, insecure, token, *id, *base, blob)
 	if err != nil {
 		fail(err)
@@ -164,16 +382,25 @@ func cmdAddText(args []string, addr, caPath string, insecure bool) {
 	text := fs.String("text", "", "text")
 	note := fs.String("note", "", "note")
 	base := fs.Int64("base", 0, "base version (0 for create)")
-	_ = fs.Parse(args)
+	dryRun := dryRunFlag(fs)
+	customFields := customFieldFlags(fs)
+	parseFlags(fs, args)
+	fields, err := customFields()
+	if err != nil {
+		fail(err)
+	}
 
 	autoUUID(id)
 	if *text == "" {
-		fmt.Fprintln(os.Stderr, "text required")
+		fmt.Fprintln(os.Stderr, tr("text required"))
 		os.Exit(2)
 	}
 	meta := map[string]any{"title": *title, "note": *note}
 	data := map[string]any{"text": *text}
-	pt, _ := buildTypedPayload("text", meta, data)
+	pt, err := buildTypedPayloadFields("text", meta, data, fields)
+	if err != nil {
+		fail(err)
+	}
 
 	token, err := loadToken()
 	if err != nil {
@@ -187,7 +414,15 @@ func cmdAddText(args []string, addr, caPath string, insecure bool) {
 	if err != nil {
 		fail(err)
 	}
+	if *dryRun {
+		printDryRun(plannedUpserts([]pendingUpsert{{ID: *id, BaseVer: *base, Blob: blob}}))
+		return
+	}
 	resp, err := upsertOne(addr, caPath, insecure, token, *id, *base, blob)
+	if err != nil && *base > 0 && isVersionConflict(err) {
+		// edited from a stale copy: merge with the server's version instead of failing
+		resp, err = mergeAndRetry(addr, caPath, insecure, token, uid, *id, *base, pt)
+	}
 	if err != nil {
 		fail(err)
 	}
@@ -205,20 +440,29 @@ func cmdAddCard(args []string, addr, caPath string, insecure bool) {
 	cvc := fs.String("cvc", "", "CVC")
 	note := fs.String("note", "", "note")
 	base := fs.Int64("base", 0, "base version (0 for create)")
-	_ = fs.Parse(args)
+	dryRun := dryRunFlag(fs)
+	customFields := customFieldFlags(fs)
+	parseFlags(fs, args)
+	fields, err := customFields()
+	if err != nil {
+		fail(err)
+	}
 
 	autoUUID(id)
 	if *name == "" || *number == "" || *exp == "" || *cvc == "" {
-		fmt.Fprintln(os.Stderr, "name, number, exp, cvc required")
+		fmt.Fprintln(os.Stderr, tr("name, number, exp, cvc required"))
 		os.Exit(2)
 	}
 	if !luhn(*number) || !validExp(*exp) || len(*cvc) < 3 || len(*cvc) > 4 {
-		fmt.Fprintln(os.Stderr, "invalid card fields")
+		fmt.Fprintln(os.Stderr, tr("invalid card fields"))
 		os.Exit(2)
 	}
 	meta := map[string]any{"title": *title, "name": *name, "number": *number, "exp": *exp, "cvc": *cvc, "note": *note}
 	data := map[string]any{}
-	pt, _ := buildTypedPayload("card", meta, data)
+	pt, err := buildTypedPayloadFields("card", meta, data, fields)
+	if err != nil {
+		fail(err)
+	}
 
 	token, err := loadToken()
 	if err != nil {
@@ -232,6 +476,10 @@ func cmdAddCard(args []string, addr, caPath string, insecure bool) {
 	if err != nil {
 		fail(err)
 	}
+	if *dryRun {
+		printDryRun(plannedUpserts([]pendingUpsert{{ID: *id, BaseVer: *base, Blob: blob}}))
+		return
+	}
 	resp, err := upsertOne(addr, caPath, insecure, token, *id, *base, blob)
 	if err != nil {
 		fail(err)
@@ -247,11 +495,17 @@ func cmdAddBinary(args []string, addr, caPath string, insecure bool) {
 	file := fs.String("file", "", "path to file")
 	note := fs.String("note", "", "note")
 	base := fs.Int64("base", 0, "base version (0 for create)")
-	_ = fs.Parse(args)
+	dryRun := dryRunFlag(fs)
+	customFields := customFieldFlags(fs)
+	parseFlags(fs, args)
+	fields, err := customFields()
+	if err != nil {
+		fail(err)
+	}
 
 	autoUUID(id)
 	if *file == "" {
-		fmt.Fprintln(os.Stderr, "file required")
+		fmt.Fprintln(os.Stderr, tr("file required"))
 		os.Exit(2)
 	}
 	b, err := os.ReadFile(*file)
@@ -261,7 +515,10 @@ func cmdAddBinary(args []string, addr, caPath string, insecure bool) {
 	fn := filepath.Base(*file)
 	mt := mime.TypeByExtension(strings.ToLower(filepath.Ext(fn)))
 	meta := map[string]any{"title": *title, "filename": fn, "mime": mt, "note": *note}
-	pt, _ := buildTypedPayload("binary", meta, b)
+	pt, err := buildTypedPayloadFields("binary", meta, b, fields)
+	if err != nil {
+		fail(err)
+	}
 
 	token, err := loadToken()
 	if err != nil {
@@ -275,6 +532,10 @@ func cmdAddBinary(args []string, addr, caPath string, insecure bool) {
 	if err != nil {
 		fail(err)
 	}
+	if *dryRun {
+		printDryRun(plannedUpserts([]pendingUpsert{{ID: *id, BaseVer: *base, Blob: blob}}))
+		return
+	}
 	resp, err := upsertOne(addr, caPath, insecure, token, *id, *base, blob)
 	if err != nil {
 		fail(err)
@@ -294,16 +555,25 @@ func cmdAddOTP(args []string, addr, caPath string, insecure bool) {
 	algo := fs.String("algo", "SHA1", "algo (SHA1/SHA256/SHA512)")
 	note := fs.String("note", "", "note")
 	base := fs.Int64("base", 0, "base version (0 for create)")
-	_ = fs.Parse(args)
+	dryRun := dryRunFlag(fs)
+	customFields := customFieldFlags(fs)
+	parseFlags(fs, args)
+	fields, err := customFields()
+	if err != nil {
+		fail(err)
+	}
 
 	autoUUID(id)
 	if *secret == "" || !isBase32(*secret) || (*digits != 6 && *digits != 8) || *period <= 0 {
-		fmt.Fprintln(os.Stderr, "invalid otp params")
+		fmt.Fprintln(os.Stderr, tr("invalid otp params"))
 		os.Exit(2)
 	}
 	meta := map[string]any{"title": *title, "issuer": *issuer, "digits": *digits, "period": *period, "algo": strings.ToUpper(*algo), "note": *note}
 	data := map[string]any{"secret": strings.ToUpper(*secret)}
-	pt, _ := buildTypedPayload("otp", meta, data)
+	pt, err := buildTypedPayloadFields("otp", meta, data, fields)
+	if err != nil {
+		fail(err)
+	}
 
 	token, err := loadToken()
 	if err != nil {
@@ -317,6 +587,10 @@ func cmdAddOTP(args []string, addr, caPath string, insecure bool) {
 	if err != nil {
 		fail(err)
 	}
+	if *dryRun {
+		printDryRun(plannedUpserts([]pendingUpsert{{ID: *id, BaseVer: *base, Blob: blob}}))
+		return
+	}
 	resp, err := upsertOne(addr, caPath, insecure, token, *id, *base, blob)
 	if err != nil {
 		fail(err)
@@ -329,9 +603,11 @@ func cmdShow(args []string, addr, caPath string, insecure bool) {
 	fs := flag.NewFlagSet("show", flag.ExitOnError)
 	id := fs.String("id", "", "item id (uuid)")
 	out := fs.String("out", "", "write binary data to file ('-'=stdout)")
-	_ = fs.Parse(args)
+	reveal := fs.Bool("reveal", false, "show hidden custom field values and whole IBANs")
+	qr := fs.Bool("qr", false, "print a wifi item as the WIFI: string QR encoders take; it holds the key")
+	parseFlags(fs, args)
 	if *id == "" {
-		fmt.Fprintln(os.Stderr, "need -id")
+		fmt.Fprintln(os.Stderr, tr("need -id"))
 		os.Exit(2)
 	}
 
@@ -347,43 +623,20 @@ func cmdShow(args []string, addr, caPath string, insecure bool) {
 	}
 	defer ccConn.Close()
 
-	req := &pb.GetItemRequest{}
-	req.SetId(*id)
-	it, err := cli.GetItem(ctx, req)
+	it, obj, err := fetchTyped(ctx, cli, *id)
 	if err != nil {
 		fail(err)
 	}
-	if it.GetDeleted() {
-		fmt.Fprintln(os.Stderr, "item is deleted")
-		os.Exit(1)
+	if obj.newer() {
+		fmt.Fprint(os.Stderr, colors(os.Stderr).warn(tr("warning: this %s was written by a newer gk (schema %d); fields this one does not know are shown as is\n", obj.Type, obj.Schema)))
 	}
-
-	// decrypt
-	dek, err := loadDEK()
-	if err != nil {
-		fail(errors.New("no DEK; login first"))
-	}
-	uid, err := loadUserID()
-	if err != nil {
-		fail(err)
-	}
-	key, err := cc.DeriveItemKey(dek, []byte(*id))
-	if err != nil {
-		fail(err)
-	}
-	pt, err := cc.DecryptBlob(key, []byte(uid), []byte(*id), it.GetVer(), it.GetBlobEnc().GetCiphertext())
-	if err != nil {
-		fail(fmt.Errorf("decrypt: %w", err))
-	}
-
-	// parse type
-	var obj struct {
-		Type string          `json:"type"`
-		Meta json.RawMessage `json:"meta"`
-		Data json.RawMessage `json:"data"`
-	}
-	if err := json.Unmarshal(pt, &obj); err != nil {
-		fail(err)
+	if *qr {
+		wf, err := parseWifi(obj)
+		if err != nil {
+			fail(err)
+		}
+		fmt.Println(wifiQR(wf))
+		return
 	}
 
 	switch obj.Type {
@@ -405,12 +658,31 @@ func cmdShow(args []string, addr, caPath string, insecure bool) {
 			fail(err)
 		}
 		if *out != "-" {
-			fmt.Printf("wrote %dB to %s\n", len(data), choose(*out, m.Filename))
+			fmt.Printf(tr("wrote %dB to %s\n"), len(data), choose(*out, m.Filename))
 		}
 	default:
-		fmt.Println(pretty(obj.Meta))
+		view := itemView{
+			ID: it.GetId(), Ver: it.GetVer(), UpdatedAt: tsString(it.GetUpdatedAt()),
+			Type: obj.Type, Meta: obj.Meta, DataSize: len(obj.Data),
+			Fields: visibleFields(obj.Fields, *reveal),
+		}
+		if iban, ok := shownIBAN(obj, *reveal); ok {
+			view.IBAN = iban
+		}
+		emit(view, func() {
+			fmt.Println(pretty(obj.Meta))
+			if line, ok := dueLine(obj, time.Now()); ok {
+				fmt.Println(line)
+			}
+			if view.IBAN != "" {
+				fmt.Printf("iban: %s\n", view.IBAN)
+			}
+			for _, f := range view.Fields {
+				fmt.Printf("%s: %s\n", f.Name, f.Value)
+			}
 
-		fmt.Printf("data=%sB (use type-specific export if needed)\n", strconv.Itoa(len(obj.Data)))
+			fmt.Printf("data=%sB (use type-specific export if needed)\n", strconv.Itoa(len(obj.Data)))
+		})
 	}
 }
 
@@ -52,8 +52,8 @@ func Test_autoUUID(t *testing.T) {
 	if id == "" {
 		t.Fatalf("should set uuid when empty")
 	}
-	if _, err := u.FromString(id); err != nil {
-		t.Fatalf("not a uuid: %v", err)
+	if v, err := u.FromString(id); err != nil || v.Version() != u.V7 {
+		t.Fatalf("not a uuidv7: %q %v", id, err)
 	}
 
 	keep := "00000000-0000-0000-0000-000000000000"
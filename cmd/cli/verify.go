@@ -0,0 +1,175 @@
+// cmd/cli/verify.go
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"os"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/internal/merkle"
+)
+
+// verifyDiff is one item on which the local copy and the server disagree.
+type verifyDiff struct {
+	ID        string `json:"id"`
+	Kind      string `json:"kind"` // content, stale, server_only, local_only
+	LocalVer  int64  `json:"local_ver,omitempty"`
+	ServerVer int64  `json:"server_ver,omitempty"`
+}
+
+// verifyReport is the gk verify result.
+type verifyReport struct {
+	Source     string       `json:"source"` // cache or server
+	LocalRoot  string       `json:"local_root"`
+	ServerRoot string       `json:"server_root"`
+	Items      int          `json:"items"`
+	OK         bool         `json:"ok"`
+	Diffs      []verifyDiff `json:"diffs,omitempty"`
+}
+
+// cacheLeaves builds Merkle leaves from the local snapshot.
+func cacheLeaves(c vaultCache) []merkle.Leaf {
+	out := make([]merkle.Leaf, 0, len(c.Changes))
+	for _, ch := range c.Changes {
+		l := merkle.NewLeaf(ch.ID, ch.Ver, ch.Deleted, ch.Blob)
+		if ch.Filtered {
+			copy(l.BlobHash[:], ch.BlobHash)
+		}
+		out = append(out, l)
+	}
+	merkle.Sort(out)
+	return out
+}
+
+// diffLeaves compares local leaves with the server's. Both must be sorted by id.
+func diffLeaves(local []merkle.Leaf, server []*pb.VaultLeaf) []verifyDiff {
+	var out []verifyDiff
+	i, j := 0, 0
+	for i < len(local) || j < len(server) {
+		switch {
+		case j == len(server) || (i < len(local) && local[i].ID < server[j].GetId()):
+			out = append(out, verifyDiff{ID: local[i].ID, Kind: "local_only", LocalVer: local[i].Ver})
+			i++
+		case i == len(local) || server[j].GetId() < local[i].ID:
+			out = append(out, verifyDiff{ID: server[j].GetId(), Kind: "server_only", ServerVer: server[j].GetVer()})
+			j++
+		default:
+			l, s := local[i], server[j]
+			h := l.Hash()
+			switch {
+			case l.Ver != s.GetVer():
+				out = append(out, verifyDiff{ID: l.ID, Kind: "stale", LocalVer: l.Ver, ServerVer: s.GetVer()})
+			case !bytes.Equal(h[:], s.GetHash()):
+				// same version, different bytes: one side was altered outside the protocol
+				out = append(out, verifyDiff{ID: l.ID, Kind: "content", LocalVer: l.Ver, ServerVer: s.GetVer()})
+			}
+			i++
+			j++
+		}
+	}
+	return out
+}
+
+// cmdVerify recomputes the vault's Merkle root from local data and compares it
+// with the server's. Exit code 7 means an item differs at the same version
+// (tampering or corruption); 4 means the local copy is merely out of date.
+func cmdVerify(args []string, addr, caPath string, insecure bool) {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	parseFlags(fs, args)
+
+	token, err := loadToken()
+	if err != nil {
+		fail(err)
+	}
+	uid, err := loadUserID()
+	if err != nil {
+		fail(err)
+	}
+	ctx, cancel := withTimeout()
+	defer cancel()
+	conn, cli, err := dial(ctx, addr, caPath, insecure, token)
+	if err != nil {
+		fail(err)
+	}
+	defer conn.Close()
+
+	rep := verifyReport{Source: "cache"}
+	var local []merkle.Leaf
+	if c, err := loadCache(); err == nil && c.UserID == uid {
+		local = cacheLeaves(c)
+	} else {
+		// no local snapshot: this still checks what arrives over the wire
+		fmt.Fprintln(os.Stderr, tr("no local cache for this user (run gk syncd); verifying a fresh download"))
+		rep.Source = "server"
+		req := &pb.GetChangesRequest{}
+		req.SetSinceVer(0)
+		out, err := fetchChanges(ctx, cli, req)
+		if err != nil {
+			fail(err)
+		}
+		c := cacheFromChanges(uid, out.GetChanges(), out.GetServerTime().AsTime())
+		local = cacheLeaves(c)
+	}
+	root := merkle.Root(local)
+	rep.LocalRoot, rep.Items = hex.EncodeToString(root[:]), len(local)
+
+	vv, err := cli.VerifyVault(ctx, &pb.VerifyVaultRequest{})
+	if err != nil {
+		fail(err)
+	}
+	rep.ServerRoot = hex.EncodeToString(vv.GetRoot())
+	rep.OK = bytes.Equal(root[:], vv.GetRoot())
+	if !rep.OK {
+		req := &pb.VerifyVaultRequest{}
+		req.SetIncludeLeaves(true)
+		if vv, err = cli.VerifyVault(ctx, req); err != nil {
+			fail(err)
+		}
+		rep.Diffs = diffLeaves(local, vv.GetLeaves())
+	}
+
+	emit(rep, func() { printVerify(rep) })
+	if code := verifyExitCode(rep); code != 0 {
+		os.Exit(code)
+	}
+}
+
+// verifyExitCode is 0 when the roots match, exitCrypto if any item differs
+// at equal versions, and exitConflict otherwise.
+func verifyExitCode(rep verifyReport) int {
+	if rep.OK {
+		return 0
+	}
+	for _, d := range rep.Diffs {
+		if d.Kind == "content" {
+			return exitCrypto
+		}
+	}
+	return exitConflict
+}
+
+func printVerify(rep verifyReport) {
+	fmt.Printf(tr("local:  %s (%d items, from %s)\n"), rep.LocalRoot, rep.Items, rep.Source)
+	fmt.Printf(tr("server: %s\n"), rep.ServerRoot)
+	c := colors(os.Stdout)
+	if rep.OK {
+		fmt.Println(c.ok(tr("OK: vault matches the server")))
+		return
+	}
+	fmt.Print(c.bad(tr("MISMATCH: %d item(s) differ\n", len(rep.Diffs))))
+	for _, d := range rep.Diffs {
+		switch d.Kind {
+		case "content":
+			fmt.Print(c.bad(tr("  %s  content differs at ver %d (possible tampering)\n", d.ID, d.LocalVer)))
+		case "stale":
+			fmt.Print(c.warn(tr("  %s  local ver %d, server ver %d\n", d.ID, d.LocalVer, d.ServerVer)))
+		case "server_only":
+			fmt.Print(c.warn(tr("  %s  only on server (ver %d)\n", d.ID, d.ServerVer)))
+		case "local_only":
+			fmt.Print(c.warn(tr("  %s  only in local copy (ver %d)\n", d.ID, d.LocalVer)))
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"testing"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/internal/merkle"
+)
+
+func Test_diffLeaves(t *testing.T) {
+	c := vaultCache{Changes: []cachedChange{
+		{ID: "d", Ver: 1, Blob: []byte("local-only")},
+		{ID: "a", Ver: 2, Blob: []byte("same")},
+		{ID: "b", Ver: 3, Blob: []byte("old")},
+		{ID: "c", Ver: 4, Blob: []byte("tampered")},
+	}}
+	local := cacheLeaves(c)
+	if local[0].ID != "a" || local[3].ID != "d" {
+		t.Fatalf("leaves not sorted: %+v", local)
+	}
+
+	leaf := func(id string, ver int64, blob string) *pb.VaultLeaf {
+		h := merkle.NewLeaf(id, ver, false, []byte(blob)).Hash()
+		l := &pb.VaultLeaf{}
+		l.SetId(id)
+		l.SetVer(ver)
+		l.SetHash(h[:])
+		return l
+	}
+	server := []*pb.VaultLeaf{
+		leaf("a", 2, "same"),
+		leaf("b", 5, "new"),
+		leaf("c", 4, "original"),
+		leaf("e", 1, "server-only"),
+	}
+
+	got := diffLeaves(local, server)
+	want := []verifyDiff{
+		{ID: "b", Kind: "stale", LocalVer: 3, ServerVer: 5},
+		{ID: "c", Kind: "content", LocalVer: 4, ServerVer: 4},
+		{ID: "d", Kind: "local_only", LocalVer: 1},
+		{ID: "e", Kind: "server_only", ServerVer: 1},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("diffs: %+v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("diff %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if code := verifyExitCode(verifyReport{Diffs: got}); code != exitCrypto {
+		t.Fatalf("exit code %d, want %d", code, exitCrypto)
+	}
+	if code := verifyExitCode(verifyReport{Diffs: got[:1]}); code != exitConflict {
+		t.Fatalf("exit code %d, want %d", code, exitConflict)
+	}
+	if code := verifyExitCode(verifyReport{OK: true}); code != 0 {
+		t.Fatalf("exit code %d for a match", code)
+	}
+}
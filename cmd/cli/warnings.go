@@ -0,0 +1,22 @@
+// cmd/cli/warnings.go
+package main
+
+import (
+	"fmt"
+	"os"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+)
+
+// showWarnings prints the server's advisory warnings to stderr. They do
+// not change the outcome, so output on stdout stays parseable.
+func showWarnings(ws []*pb.Warning) {
+	c := colors(os.Stderr)
+	for _, w := range ws {
+		msg := tr("server warning: %s\n", w.GetMessage())
+		if id := w.GetItemId(); id != "" {
+			msg = tr("server warning for item %s: %s\n", id, w.GetMessage())
+		}
+		fmt.Fprint(os.Stderr, c.warn(msg))
+	}
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"io"
+	"os"
+	"testing"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+)
+
+func Test_showWarnings(t *testing.T) {
+	old := os.Stderr
+	r, w, _ := os.Pipe()
+	os.Stderr = w
+	defer func() { os.Stderr = old }()
+
+	large, near := &pb.Warning{}, &pb.Warning{}
+	large.SetCode("ITEM_LARGE")
+	large.SetMessage("item is 300000 bytes")
+	large.SetItemId("0198c6f2-0000-7000-8000-000000000001")
+	near.SetCode("ITEM_LIMIT_NEARLY_REACHED")
+	near.SetMessage("9 of 10 items used")
+	showWarnings([]*pb.Warning{large, near})
+	_ = w.Close()
+	out, _ := io.ReadAll(r)
+
+	want := "server warning for item 0198c6f2-0000-7000-8000-000000000001: item is 300000 bytes\n" +
+		"server warning: 9 of 10 items used\n"
+	if string(out) != want {
+		t.Fatalf("got %q", out)
+	}
+}
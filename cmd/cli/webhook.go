@@ -0,0 +1,87 @@
+// cmd/cli/webhook.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+)
+
+// webhookRow is one registered webhook in list output.
+type webhookRow struct {
+	ID        string `json:"id"`
+	URL       string `json:"url"`
+	CreatedAt string `json:"created_at"`
+}
+
+// cmdWebhook manages the caller's change notification webhooks.
+func cmdWebhook(args []string, addr, caPath string, insecure bool) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, tr("usage: webhook add -url URL | list | rm -id ID"))
+		os.Exit(exitUsage)
+	}
+	sub, args := args[0], args[1:]
+	fs := flag.NewFlagSet("webhook "+sub, flag.ExitOnError)
+	var url, id *string
+	switch sub {
+	case "add":
+		url = fs.String("url", "", "https:// endpoint to notify")
+	case "rm":
+		id = fs.String("id", "", "webhook id")
+	case "list":
+	default:
+		fmt.Fprintf(os.Stderr, tr("unknown webhook subcommand %q\n"), sub)
+		os.Exit(exitUsage)
+	}
+	parseFlags(fs, args)
+	if (url != nil && *url == "") || (id != nil && *id == "") {
+		fail(fmt.Errorf("%w: %s", errInvalidInput, tr("webhook add needs -url, webhook rm needs -id")))
+	}
+
+	token, err := loadToken()
+	if err != nil {
+		fail(err)
+	}
+	ctx, cancel := withTimeout()
+	defer cancel()
+	conn, cli, err := dial(ctx, addr, caPath, insecure, token)
+	if err != nil {
+		fail(err)
+	}
+	defer conn.Close()
+
+	switch sub {
+	case "add":
+		req := &pb.CreateWebhookRequest{}
+		req.SetUrl(*url)
+		resp, err := cli.CreateWebhook(ctx, req)
+		if err != nil {
+			fail(err)
+		}
+		printJSON(map[string]any{
+			"id":     resp.GetWebhook().GetId(),
+			"url":    resp.GetWebhook().GetUrl(),
+			"secret": resp.GetSecret(),
+		})
+		fmt.Fprintln(os.Stderr, tr("store the secret now: it verifies X-GophKeeper-Signature and is not shown again"))
+	case "list":
+		resp, err := cli.ListWebhooks(ctx, &pb.ListWebhooksRequest{})
+		if err != nil {
+			fail(err)
+		}
+		rows := []webhookRow{}
+		for _, h := range resp.GetWebhooks() {
+			rows = append(rows, webhookRow{ID: h.GetId(), URL: h.GetUrl(), CreatedAt: tsString(h.GetCreatedAt())})
+		}
+		emit(rows, func() { printJSON(rows) })
+	case "rm":
+		req := &pb.DeleteWebhookRequest{}
+		req.SetId(*id)
+		if _, err := cli.DeleteWebhook(ctx, req); err != nil {
+			fail(err)
+		}
+		fmt.Printf(tr("webhook %s removed\n"), *id)
+	}
+}
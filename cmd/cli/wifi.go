@@ -0,0 +1,238 @@
+// cmd/cli/wifi.go
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Wi-Fi security as the WIFI: QR format names it; SAE is WPA3-Personal.
+const (
+	wifiWPA    = "WPA"
+	wifiSAE    = "SAE"
+	wifiWEP    = "WEP"
+	wifiNoPass = "nopass"
+)
+
+// wifiFields is the decoded content of a wifi item.
+type wifiFields struct {
+	Title    string `json:"title"`
+	SSID     string `json:"ssid"`
+	Security string `json:"security"`
+	Hidden   bool   `json:"hidden"`
+	PSK      string `json:"-"`
+}
+
+// parseWifi decodes meta/data of a wifi payload.
+func parseWifi(obj typedPayload) (wifiFields, error) {
+	if obj.Type != "wifi" {
+		return wifiFields{}, fmt.Errorf("%w: not a wifi item (type=%s)", errInvalidInput, obj.Type)
+	}
+	var wf wifiFields
+	if err := json.Unmarshal(obj.Meta, &wf); err != nil {
+		return wifiFields{}, err
+	}
+	var data struct {
+		PSK string `json:"psk"`
+	}
+	if len(obj.Data) > 0 {
+		if err := json.Unmarshal(obj.Data, &data); err != nil {
+			return wifiFields{}, err
+		}
+	}
+	wf.PSK = data.PSK
+	return wf, nil
+}
+
+// wifiSecurity normalizes a -security value; WPA2 is WPA, WPA3 is SAE.
+func wifiSecurity(s string) (string, error) {
+	switch strings.ToUpper(s) {
+	case "WPA", "WPA2", "":
+		return wifiWPA, nil
+	case "SAE", "WPA3":
+		return wifiSAE, nil
+	case "WEP":
+		return wifiWEP, nil
+	case "NOPASS", "OPEN", "NONE":
+		return wifiNoPass, nil
+	}
+	return "", fmt.Errorf("%w: security %q: want WPA, WPA3, WEP or nopass", errInvalidInput, s)
+}
+
+func isHex(s string) bool {
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+// checkWifiKey checks psk against what the security mode takes: 8-63
+// characters (or 64 hex digits) for WPA, a 40 or 104 bit key for WEP and
+// nothing for an open network.
+func checkWifiKey(security, psk string) error {
+	switch security {
+	case wifiNoPass:
+		if psk != "" {
+			return fmt.Errorf("%w: an open network has no key", errInvalidInput)
+		}
+	case wifiWEP:
+		if n := len(psk); n != 5 && n != 13 && !((n == 10 || n == 26) && isHex(psk)) {
+			return fmt.Errorf("%w: a WEP key is 5 or 13 characters, or 10 or 26 hex digits", errInvalidInput)
+		}
+	default:
+		if n := len(psk); (n < 8 || n > 63) && !(n == 64 && isHex(psk)) {
+			return fmt.Errorf("%w: a WPA key is 8 to 63 characters, or 64 hex digits", errInvalidInput)
+		}
+	}
+	return nil
+}
+
+// wifiEscape escapes the characters the WIFI: format reserves.
+var wifiEscape = strings.NewReplacer(`\`, `\\`, `;`, `\;`, `,`, `\,`, `"`, `\"`, `:`, `\:`)
+
+// wifiQR renders a network as the WIFI: string QR encoders and phone
+// cameras understand.
+func wifiQR(wf wifiFields) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "WIFI:T:%s;S:%s;", choose(wf.Security, wifiWPA), wifiEscape.Replace(wf.SSID))
+	if wf.Security != wifiNoPass {
+		fmt.Fprintf(&b, "P:%s;", wifiEscape.Replace(wf.PSK))
+	}
+	if wf.Hidden {
+		b.WriteString("H:true;")
+	}
+	b.WriteString(";")
+	return b.String()
+}
+
+// weakWifi is a network whose security is worth fixing.
+type weakWifi struct {
+	ID     string `json:"id"`
+	Title  string `json:"title"`
+	SSID   string `json:"ssid"`
+	Reason string `json:"reason"`
+}
+
+// explain describes w.Reason for people.
+func (w weakWifi) explain() string {
+	switch w.Reason {
+	case "open":
+		return tr("open network, no key")
+	case "wep":
+		return tr("WEP can be cracked in minutes")
+	case "short":
+		return tr("key shorter than 12 characters")
+	case "digits":
+		return tr("key is only digits")
+	case "ssid":
+		return tr("key contains the network name")
+	}
+	return w.Reason
+}
+
+// findWeakWifi returns the wifi items with a weak or no key.
+func findWeakWifi(items []vaultItem) []weakWifi {
+	var out []weakWifi
+	for _, it := range items {
+		wf, err := parseWifi(it.Payload)
+		if err != nil {
+			continue
+		}
+		reason := ""
+		switch {
+		case wf.Security == wifiNoPass:
+			reason = "open"
+		case wf.Security == wifiWEP:
+			reason = "wep"
+		case len(wf.PSK) == 64 && isHex(wf.PSK):
+			// a raw 256-bit key
+		case len(wf.PSK) < 12:
+			reason = "short"
+		case strings.Trim(wf.PSK, "0123456789") == "":
+			reason = "digits"
+		case wf.SSID != "" && strings.Contains(strings.ToLower(wf.PSK), strings.ToLower(wf.SSID)):
+			reason = "ssid"
+		}
+		if reason != "" {
+			out = append(out, weakWifi{ID: it.ID, Title: wf.Title, SSID: wf.SSID, Reason: reason})
+		}
+	}
+	return out
+}
+
+// cmdAddWifi creates or updates a Wi-Fi network record.
+func cmdAddWifi(args []string, addr, caPath string, insecure bool) {
+	fs := flag.NewFlagSet("add-wifi", flag.ExitOnError)
+	id := fs.String("id", "", "item id (uuid, optional)")
+	title := fs.String("title", "", "title (default: the SSID)")
+	ssid := fs.String("ssid", "", "network name")
+	psk := fs.String("psk", "", "key (passphrase)")
+	security := fs.String("security", wifiWPA, "WPA (WPA/WPA2) | WPA3 | WEP | nopass")
+	hidden := fs.Bool("hidden", false, "the network does not broadcast its SSID")
+	note := fs.String("note", "", "note")
+	base := fs.Int64("base", 0, "base version (0 for create)")
+	dryRun := dryRunFlag(fs)
+	customFields := customFieldFlags(fs)
+	parseFlags(fs, args)
+	fields, err := customFields()
+	if err != nil {
+		fail(err)
+	}
+
+	autoUUID(id)
+	if *ssid == "" {
+		fmt.Fprintln(os.Stderr, tr("ssid required"))
+		os.Exit(exitUsage)
+	}
+	sec, err := wifiSecurity(*security)
+	if err != nil {
+		fail(err)
+	}
+	if err := checkWifiKey(sec, *psk); err != nil {
+		fail(err)
+	}
+	meta := map[string]any{"title": choose(*title, *ssid), "ssid": *ssid, "security": sec, "note": *note}
+	if *hidden {
+		meta["hidden"] = true
+	}
+	data := map[string]any{}
+	if sec != wifiNoPass {
+		data["psk"] = *psk
+	}
+	pt, err := buildTypedPayloadFields("wifi", meta, data, fields)
+	if err != nil {
+		fail(err)
+	}
+
+	token, err := loadToken()
+	if err != nil {
+		fail(err)
+	}
+	uid, err := loadUserID()
+	if err != nil {
+		fail(err)
+	}
+	blob, err := encryptForItem(*id, uid, *base+1, pt)
+	if err != nil {
+		fail(err)
+	}
+	if *dryRun {
+		printDryRun(plannedUpserts([]pendingUpsert{{ID: *id, BaseVer: *base, Blob: blob}}))
+		return
+	}
+	resp, err := upsertOne(addr, caPath, insecure, token, *id, *base, blob)
+	if err != nil {
+		fail(err)
+	}
+	printJSON(resp.GetResults())
+}
+
+func checkWifiSecurity(s string) error {
+	if _, err := wifiSecurity(s); err != nil {
+		return errors.New(tr("WPA, WPA3, WEP or nopass"))
+	}
+	return nil
+}
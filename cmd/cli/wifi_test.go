@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func Test_wifiQR(t *testing.T) {
+	t.Parallel()
+
+	for _, c := range []struct {
+		wf   wifiFields
+		want string
+	}{
+		{wifiFields{SSID: "home", Security: wifiWPA, PSK: "correct horse"}, "WIFI:T:WPA;S:home;P:correct horse;;"},
+		{wifiFields{SSID: `a;b,c"d:e\f`, Security: wifiSAE, PSK: "p;w", Hidden: true}, `WIFI:T:SAE;S:a\;b\,c\"d\:e\\f;P:p\;w;H:true;;`},
+		{wifiFields{SSID: "cafe", Security: wifiNoPass}, "WIFI:T:nopass;S:cafe;;"},
+	} {
+		if got := wifiQR(c.wf); got != c.want {
+			t.Fatalf("%+v: %s, want %s", c.wf, got, c.want)
+		}
+	}
+}
+
+func Test_checkWifiKey(t *testing.T) {
+	t.Parallel()
+
+	for _, c := range []struct {
+		sec, psk string
+		ok       bool
+	}{
+		{wifiWPA, "12345678", true},
+		{wifiWPA, "1234567", false},
+		{wifiWPA, strings.Repeat("ab", 32), true},
+		{wifiWPA, strings.Repeat("x", 64), false},
+		{wifiWEP, "abcde", true},
+		{wifiWEP, "0123456789", true},
+		{wifiWEP, "abcdef", false},
+		{wifiNoPass, "", true},
+		{wifiNoPass, "secret12", false},
+	} {
+		if err := checkWifiKey(c.sec, c.psk); (err == nil) != c.ok || (err != nil && !errors.Is(err, errInvalidInput)) {
+			t.Fatalf("%s %q: %v", c.sec, c.psk, err)
+		}
+	}
+	if s, err := wifiSecurity("wpa3"); err != nil || s != wifiSAE {
+		t.Fatalf("wpa3: %s %v", s, err)
+	}
+	if _, err := wifiSecurity("WPA-EAP"); !errors.Is(err, errInvalidInput) {
+		t.Fatalf("WPA-EAP: %v", err)
+	}
+}
+
+func Test_findWeakWifi(t *testing.T) {
+	t.Parallel()
+
+	wifi := func(id, ssid, sec, psk string) vaultItem {
+		meta, _ := json.Marshal(map[string]any{"title": id, "ssid": ssid, "security": sec})
+		data, _ := json.Marshal(map[string]any{"psk": psk})
+		return vaultItem{ID: id, Payload: typedPayload{Type: "wifi", Meta: meta, Data: data}}
+	}
+	items := []vaultItem{
+		wifi("good", "home", wifiWPA, "violet-tractor-88-harbor"),
+		wifi("open", "cafe", wifiNoPass, ""),
+		wifi("wep", "old", wifiWEP, "abcde"),
+		wifi("short", "home", wifiWPA, "hunter22"),
+		wifi("digits", "home", wifiWPA, "0123456789012"),
+		wifi("ssid", "Home", wifiSAE, "home-sweet-home!"),
+		wifi("raw", "home", wifiWPA, strings.Repeat("0f", 32)),
+		{ID: "login", Payload: typedPayload{Type: "login", Meta: json.RawMessage(`{}`), Data: json.RawMessage(`{"password":"x"}`)}},
+	}
+	got := map[string]string{}
+	for _, w := range findWeakWifi(items) {
+		got[w.ID] = w.Reason
+	}
+	want := map[string]string{"open": "open", "wep": "wep", "short": "short", "digits": "digits", "ssid": "ssid"}
+	if len(got) != len(want) {
+		t.Fatalf("weak: %v", got)
+	}
+	for id, r := range want {
+		if got[id] != r {
+			t.Fatalf("%s: %q, want %q", id, got[id], r)
+		}
+	}
+
+	// the SSID is searchable, the key never is
+	if hits := searchItems(items, "harbor", ""); len(hits) != 0 {
+		t.Fatalf("search found the key: %+v", hits)
+	}
+	if hits := searchItems(items, "cafe", "wifi"); len(hits) != 1 || hits[0].Match != "ssid" {
+		t.Fatalf("search by ssid: %+v", hits)
+	}
+}
@@ -0,0 +1,361 @@
+// cmd/cli/wizard.go
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// wizardField is one question of the add wizard.
+type wizardField struct {
+	Key      string // payload key
+	Label    string
+	Data     bool // stored under data rather than meta
+	Secret   bool // read without echo
+	Optional bool
+	Default  string
+	Check    func(string) error
+}
+
+// wizardTypes lists the questions per item type, producing the same
+// payloads as the add-* commands.
+var wizardTypes = []struct {
+	Type   string
+	Fields []wizardField
+}{
+	{"login", []wizardField{
+		{Key: "title", Label: "Title", Optional: true},
+		{Key: "url", Label: "URL", Optional: true},
+		{Key: "username", Label: "Username"},
+		{Key: "password", Label: "Password", Data: true, Secret: true},
+		{Key: "note", Label: "Note", Optional: true},
+	}},
+	{"card", []wizardField{
+		{Key: "title", Label: "Title", Optional: true},
+		{Key: "name", Label: "Cardholder"},
+		{Key: "number", Label: "Card number", Secret: true, Check: checkCardNumber},
+		{Key: "exp", Label: "Expiry (MM/YY)", Check: checkExp},
+		{Key: "cvc", Label: "CVC", Secret: true, Check: checkCVC},
+		{Key: "note", Label: "Note", Optional: true},
+	}},
+	{"text", []wizardField{
+		{Key: "title", Label: "Title", Optional: true},
+		{Key: "text", Label: "Text", Data: true, Secret: true},
+		{Key: "note", Label: "Note", Optional: true},
+	}},
+	{"otp", []wizardField{
+		{Key: "title", Label: "Title", Optional: true},
+		{Key: "issuer", Label: "Issuer", Optional: true},
+		{Key: "secret", Label: "Base32 secret", Data: true, Secret: true, Check: checkBase32},
+		{Key: "digits", Label: "Digits", Default: "6", Check: checkDigits},
+		{Key: "period", Label: "Period (seconds)", Default: "30", Check: checkPeriod},
+		{Key: "algo", Label: "Algorithm (SHA1/SHA256/SHA512)", Default: "SHA1", Check: checkAlgo},
+		{Key: "note", Label: "Note", Optional: true},
+	}},
+	{"binary", []wizardField{
+		{Key: "title", Label: "Title", Optional: true},
+		{Key: "file", Label: "File path", Check: checkFile},
+		{Key: "note", Label: "Note", Optional: true},
+	}},
+	{"wifi", []wizardField{
+		{Key: "title", Label: "Title", Optional: true},
+		{Key: "ssid", Label: "Network name (SSID)"},
+		{Key: "security", Label: "Security (WPA/WPA3/WEP/nopass)", Default: wifiWPA, Check: checkWifiSecurity},
+		{Key: "psk", Label: "Key", Data: true, Secret: true, Optional: true},
+		{Key: "note", Label: "Note", Optional: true},
+	}},
+	{"license", []wizardField{
+		{Key: "title", Label: "Title", Optional: true},
+		{Key: "product", Label: "Product", Optional: true},
+		{Key: "key", Label: "License key", Data: true, Secret: true},
+		{Key: "seats", Label: "Seats", Optional: true, Check: checkSeats},
+		{Key: "purchased", Label: "Purchase date (YYYY-MM-DD)", Optional: true, Check: checkDate},
+		{Key: "expires", Label: "Expiry date (YYYY-MM-DD)", Optional: true, Check: checkDate},
+		{Key: "note", Label: "Note", Optional: true},
+	}},
+	{"bank", []wizardField{
+		{Key: "title", Label: "Title", Optional: true},
+		{Key: "bank", Label: "Bank", Optional: true},
+		{Key: "holder", Label: "Account holder"},
+		{Key: "iban", Label: "IBAN", Data: true, Check: checkIBAN},
+		{Key: "bic", Label: "BIC", Optional: true, Check: checkBIC},
+		{Key: "note", Label: "Note", Optional: true},
+	}},
+	{"apikey", []wizardField{
+		{Key: "title", Label: "Title", Optional: true},
+		{Key: "environment", Label: "Environment", Optional: true},
+		{Key: "key", Label: "API key", Data: true, Secret: true},
+		{Key: "rotate_by", Label: "Rotate by (YYYY-MM-DD)", Optional: true, Check: checkDate},
+		{Key: "note", Label: "Note", Optional: true},
+	}},
+}
+
+func checkCardNumber(s string) error {
+	if !luhn(s) {
+		return errors.New(tr("not a valid card number"))
+	}
+	return nil
+}
+
+func checkExp(s string) error {
+	if !validExp(s) {
+		return errors.New(tr("use MM/YY"))
+	}
+	return nil
+}
+
+func checkCVC(s string) error {
+	if _, err := strconv.Atoi(s); err != nil || len(s) < 3 || len(s) > 4 {
+		return errors.New(tr("3 or 4 digits"))
+	}
+	return nil
+}
+
+func checkBase32(s string) error {
+	if !isBase32(s) {
+		return errors.New(tr("not base32"))
+	}
+	return nil
+}
+
+func checkDigits(s string) error {
+	if s != "6" && s != "8" {
+		return errors.New(tr("6 or 8"))
+	}
+	return nil
+}
+
+func checkPeriod(s string) error {
+	if n, err := strconv.Atoi(s); err != nil || n <= 0 {
+		return errors.New(tr("a positive number of seconds"))
+	}
+	return nil
+}
+
+func checkAlgo(s string) error {
+	switch strings.ToUpper(s) {
+	case "SHA1", "SHA256", "SHA512":
+		return nil
+	}
+	return errors.New(tr("SHA1, SHA256 or SHA512"))
+}
+
+func checkFile(s string) error {
+	st, err := os.Stat(s)
+	if err != nil {
+		return err
+	}
+	if st.IsDir() {
+		return errors.New(tr("is a directory"))
+	}
+	return nil
+}
+
+// wizardMaxTries bounds re-prompts for an invalid answer.
+const wizardMaxTries = 3
+
+// wizard asks questions on in/out. hide, if set, turns echo off for secrets.
+type wizard struct {
+	in   *bufio.Reader
+	out  io.Writer
+	hide func() (restore func(), ok bool)
+}
+
+// line reads one answer; io.EOF with no input ends the wizard.
+func (w wizard) line(secret bool) (string, error) {
+	if secret && w.hide != nil {
+		restore, ok := w.hide()
+		defer func() {
+			restore()
+			if ok {
+				fmt.Fprintln(w.out) // the user's Enter was not echoed
+			}
+		}()
+	}
+	s, err := w.in.ReadString('\n')
+	if err != nil && s == "" {
+		return "", fmt.Errorf("%w: %s", errInvalidInput, tr("input ended"))
+	}
+	return strings.TrimRight(s, "\r\n"), nil
+}
+
+// ask prompts for f until the answer passes its checks.
+func (w wizard) ask(f wizardField) (string, error) {
+	for try := 0; try < wizardMaxTries; try++ {
+		switch {
+		case f.Default != "":
+			fmt.Fprintf(w.out, "%s [%s]: ", tr(f.Label), f.Default)
+		case f.Optional:
+			fmt.Fprintf(w.out, tr("%s (optional): "), tr(f.Label))
+		default:
+			fmt.Fprintf(w.out, "%s: ", tr(f.Label))
+		}
+		v, err := w.line(f.Secret)
+		if err != nil {
+			return "", err
+		}
+		v = strings.TrimSpace(v)
+		if v == "" {
+			v = f.Default
+		}
+		if v == "" && !f.Optional {
+			fmt.Fprintln(w.out, tr("  required"))
+			continue
+		}
+		if v != "" && f.Check != nil {
+			if err := f.Check(v); err != nil {
+				fmt.Fprintf(w.out, "  %v\n", err)
+				continue
+			}
+		}
+		if f.Secret && f.Data {
+			fmt.Fprintf(w.out, tr("%s (again): "), tr(f.Label))
+			again, err := w.line(true)
+			if err != nil {
+				return "", err
+			}
+			if strings.TrimSpace(again) != v {
+				fmt.Fprintln(w.out, tr("  entries differ"))
+				continue
+			}
+		}
+		return v, nil
+	}
+	return "", fmt.Errorf("%w: %s", errInvalidInput, tr("no valid %s after %d tries", strings.ToLower(tr(f.Label)), wizardMaxTries))
+}
+
+// chooseType asks for the item type by number or name.
+func (w wizard) chooseType() (int, error) {
+	for i, t := range wizardTypes {
+		fmt.Fprintf(w.out, "  %d) %s\n", i+1, t.Type)
+	}
+	for try := 0; try < wizardMaxTries; try++ {
+		fmt.Fprint(w.out, tr("Type: "))
+		v, err := w.line(false)
+		if err != nil {
+			return 0, err
+		}
+		v = strings.TrimSpace(v)
+		if n, err := strconv.Atoi(v); err == nil && n >= 1 && n <= len(wizardTypes) {
+			return n - 1, nil
+		}
+		for i, t := range wizardTypes {
+			if strings.EqualFold(v, t.Type) {
+				return i, nil
+			}
+		}
+		fmt.Fprintln(w.out, tr("  pick a number or a type name"))
+	}
+	return 0, fmt.Errorf("%w: %s", errInvalidInput, tr("no item type chosen"))
+}
+
+// run walks through the questions and returns the typed payload.
+func (w wizard) run(now time.Time) ([]byte, error) {
+	ti, err := w.chooseType()
+	if err != nil {
+		return nil, err
+	}
+	typ := wizardTypes[ti].Type
+	answers := map[string]string{}
+	for _, f := range wizardTypes[ti].Fields {
+		v, err := w.ask(f)
+		if err != nil {
+			return nil, err
+		}
+		answers[f.Key] = v
+	}
+
+	meta, data := map[string]any{}, map[string]any{}
+	for _, f := range wizardTypes[ti].Fields {
+		if f.Data {
+			data[f.Key] = answers[f.Key]
+		} else {
+			meta[f.Key] = answers[f.Key]
+		}
+	}
+	switch typ {
+	case "login":
+		stampPasswordChange(meta, answers["password"], nil, now)
+	case "otp":
+		digits, _ := strconv.Atoi(answers["digits"])
+		period, _ := strconv.Atoi(answers["period"])
+		meta["digits"], meta["period"] = digits, period
+		meta["algo"] = strings.ToUpper(answers["algo"])
+		data["secret"] = strings.ToUpper(answers["secret"])
+	case "wifi":
+		sec, _ := wifiSecurity(answers["security"])
+		if err := checkWifiKey(sec, answers["psk"]); err != nil {
+			return nil, err
+		}
+		meta["security"], meta["title"] = sec, choose(answers["title"], answers["ssid"])
+		if sec == wifiNoPass {
+			delete(data, "psk")
+		}
+	case "license":
+		meta["title"] = choose(answers["title"], answers["product"])
+		if seats, err := strconv.Atoi(answers["seats"]); err == nil {
+			meta["seats"] = seats
+		} else {
+			delete(meta, "seats")
+		}
+	case "bank":
+		meta["title"] = choose(answers["title"], answers["bank"])
+		meta["bic"] = strings.ToUpper(answers["bic"])
+		data["iban"] = normalizeIBAN(answers["iban"])
+	case "binary":
+		b, err := os.ReadFile(answers["file"])
+		if err != nil {
+			return nil, err
+		}
+		delete(meta, "file")
+		fn := filepath.Base(answers["file"])
+		meta["filename"], meta["mime"] = fn, mime.TypeByExtension(strings.ToLower(filepath.Ext(fn)))
+		return buildTypedPayloadFields(typ, meta, b, nil)
+	}
+	return buildTypedPayloadFields(typ, meta, data, nil)
+}
+
+// cmdAddInteractive is gk add -i: it builds the item from answers on the
+// terminal instead of flags.
+func cmdAddInteractive(addr, caPath string, insecure, dryRun bool) {
+	if err := needInput("add -i"); err != nil {
+		fail(err)
+	}
+	token, err := loadToken()
+	if err != nil {
+		fail(err)
+	}
+	uid, err := loadUserID()
+	if err != nil {
+		fail(err)
+	}
+	w := wizard{in: bufio.NewReader(os.Stdin), out: os.Stderr, hide: func() (func(), bool) { return echoOff(os.Stdin) }}
+	pt, err := w.run(time.Now())
+	if err != nil {
+		fail(err)
+	}
+
+	id := ""
+	autoUUID(&id)
+	blob, err := encryptForItem(id, uid, 1, pt)
+	if err != nil {
+		fail(err)
+	}
+	if dryRun {
+		printDryRun(plannedUpserts([]pendingUpsert{{ID: id, BaseVer: 0, Blob: blob}}))
+		return
+	}
+	resp, err := upsertOne(addr, caPath, insecure, token, id, 0, blob)
+	if err != nil {
+		fail(err)
+	}
+	printJSON(resp.GetResults())
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func runWizard(t *testing.T, input string) (typedPayload, string, error) {
+	t.Helper()
+	var out strings.Builder
+	hidden := 0
+	w := wizard{
+		in:   bufio.NewReader(strings.NewReader(input)),
+		out:  &out,
+		hide: func() (func(), bool) { hidden++; return func() {}, true },
+	}
+	pt, err := w.run(time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC))
+	var obj typedPayload
+	if err == nil {
+		if jerr := json.Unmarshal(pt, &obj); jerr != nil {
+			t.Fatal(jerr)
+		}
+	}
+	return obj, out.String(), err
+}
+
+func Test_wizard_Login(t *testing.T) {
+	// type by name, optional fields skipped, a mistyped confirmation retried
+	obj, out, err := runWizard(t, "login\nGitHub\n\nme\ns3cret\ntypo\ns3cret\ns3cret\n\n")
+	if err != nil {
+		t.Fatalf("run: %v\n%s", err, out)
+	}
+	if obj.Type != "login" || !strings.Contains(out, "entries differ") {
+		t.Fatalf("type %q, output:\n%s", obj.Type, out)
+	}
+	var meta map[string]any
+	var data map[string]string
+	_ = json.Unmarshal(obj.Meta, &meta)
+	_ = json.Unmarshal(obj.Data, &data)
+	if meta["title"] != "GitHub" || meta["username"] != "me" || data["password"] != "s3cret" || meta[metaPasswordChangedAt] == nil {
+		t.Fatalf("payload: meta=%v data=%v", meta, data)
+	}
+	if strings.Contains(out, "s3cret") {
+		t.Fatalf("secret echoed to output")
+	}
+}
+
+func Test_wizard_CardValidationAndOTPDefaults(t *testing.T) {
+	obj, out, err := runWizard(t, "2\nVisa\nA User\n4111111111111112\n4111111111111111\n13-30\n12/30\n12\n123\n\n")
+	if err != nil {
+		t.Fatalf("card: %v\n%s", err, out)
+	}
+	if obj.Type != "card" || !strings.Contains(out, "not a valid card number") || !strings.Contains(out, "use MM/YY") {
+		t.Fatalf("card output:\n%s", out)
+	}
+
+	obj, _, err = runWizard(t, "otp\n\nACME\njbswy3dpehpk3pxp\njbswy3dpehpk3pxp\n\n\nsha256\n\n")
+	if err != nil {
+		t.Fatalf("otp: %v", err)
+	}
+	var meta map[string]any
+	_ = json.Unmarshal(obj.Meta, &meta)
+	if meta["digits"] != float64(6) || meta["period"] != float64(30) || meta["algo"] != "SHA256" {
+		t.Fatalf("otp meta: %v", meta)
+	}
+	if !strings.Contains(string(obj.Data), "JBSWY3DPEHPK3PXP") {
+		t.Fatalf("otp data: %s", obj.Data)
+	}
+}
+
+func Test_wizard_GivesUp(t *testing.T) {
+	if _, _, err := runWizard(t, "9\nnope\n0\n"); !errors.Is(err, errInvalidInput) {
+		t.Fatalf("want errInvalidInput after bad type choices, got %v", err)
+	}
+	if _, _, err := runWizard(t, "login\n\n\n"); !errors.Is(err, errInvalidInput) {
+		t.Fatalf("want errInvalidInput at end of input, got %v", err)
+	}
+}
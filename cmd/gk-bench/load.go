@@ -0,0 +1,227 @@
+// cmd/gk-bench/load.go
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	mrand "math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/internal/errs"
+)
+
+// ops are the operations -mix can weigh.
+var ops = []string{"get", "changes", "upsert"}
+
+// parseMix reads "op=weight,..."; operations left out are not run.
+func parseMix(s string) (map[string]int, error) {
+	mix := map[string]int{}
+	total := 0
+	for _, part := range strings.Split(s, ",") {
+		op, w, ok := strings.Cut(strings.TrimSpace(part), "=")
+		n, err := strconv.Atoi(w)
+		if !ok || err != nil || n < 0 {
+			return nil, fmt.Errorf("-mix: %q is not op=weight", part)
+		}
+		if !slices.Contains(ops, op) {
+			return nil, fmt.Errorf("-mix: unknown operation %q, want one of %s", op, strings.Join(ops, ", "))
+		}
+		mix[op] = n
+		total += n
+	}
+	if total == 0 {
+		return nil, errors.New("-mix: all weights are zero")
+	}
+	return mix, nil
+}
+
+// pickOp draws an operation by its weight.
+func pickOp(r *mrand.Rand, mix map[string]int) string {
+	total := 0
+	for _, op := range ops {
+		total += mix[op]
+	}
+	n := r.IntN(total)
+	for _, op := range ops {
+		if n < mix[op] {
+			return op
+		}
+		n -= mix[op]
+	}
+	return ops[0]
+}
+
+// samples are the latencies and failures of one operation seen by one
+// caller; they are merged for the report.
+type samples struct {
+	lat       []time.Duration
+	errors    int
+	conflicts int
+}
+
+// run sets up the users and their vaults, then drives the mix for
+// c.duration and reports on it.
+func run(ctx context.Context, c config, cli pb.GophKeeperClient) (*report, error) {
+	start := time.Now()
+	users := make([]*user, c.users)
+	total := 0
+	for i := range users {
+		u, err := newUser(ctx, cli, fmt.Sprintf("%s%d", c.prefix, i))
+		if err != nil {
+			return nil, err
+		}
+		// vault sizes vary around the average, as real ones do
+		r := mrand.New(mrand.NewPCG(uint64(i), uint64(start.UnixNano())))
+		n := c.items/2 + r.IntN(c.items+1)
+		if err := u.seed(ctx, cli, r, max(n, 1), c.largeShare); err != nil {
+			return nil, err
+		}
+		users[i] = u
+		total += len(u.items)
+	}
+	setup := time.Since(start)
+
+	runCtx, cancel := context.WithTimeout(ctx, c.duration)
+	defer cancel()
+	results := make([]map[string]*samples, c.concurrency)
+	var wg sync.WaitGroup
+	began := time.Now()
+	for w := range c.concurrency {
+		results[w] = map[string]*samples{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r := mrand.New(mrand.NewPCG(uint64(w), uint64(began.UnixNano())))
+			for runCtx.Err() == nil {
+				op := pickOp(r, c.mix)
+				s := results[w][op]
+				if s == nil {
+					s = &samples{}
+					results[w][op] = s
+				}
+				u := users[r.IntN(len(users))]
+				switch op {
+				case "get":
+					callGet(runCtx, cli, r, u, s)
+				case "changes":
+					callChanges(runCtx, cli, u, c.page, s)
+				case "upsert":
+					callUpsert(runCtx, cli, r, u, c.batch, s)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return newReport(c, total, setup, time.Since(began), results), nil
+}
+
+// timed runs call and records its latency, or counts its failure; calls
+// cut short by the end of the run count as neither.
+func timed(ctx context.Context, s *samples, call func() error) error {
+	t := time.Now()
+	err := call()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err != nil {
+		s.errors++
+		return err
+	}
+	s.lat = append(s.lat, time.Since(t))
+	return nil
+}
+
+func callGet(ctx context.Context, cli pb.GophKeeperClient, r *mrand.Rand, u *user, s *samples) {
+	u.mu.Lock()
+	id := u.items[r.IntN(len(u.items))].id
+	u.mu.Unlock()
+	req := &pb.GetItemRequest{}
+	req.SetId(id)
+	_ = timed(ctx, s, func() error {
+		_, err := cli.GetItem(u.ctx(ctx), req)
+		return err
+	})
+}
+
+// callChanges syncs the whole vault from scratch, a page per call, as a
+// new device does; every page is a sample.
+func callChanges(ctx context.Context, cli pb.GophKeeperClient, u *user, page int, s *samples) {
+	req := &pb.GetChangesRequest{}
+	req.SetPageSize(int32(page)) //nolint:gosec // -page is small
+	for {
+		var resp *pb.GetChangesResponse
+		err := timed(ctx, s, func() error {
+			var err error
+			resp, err = cli.GetChanges(u.ctx(ctx), req)
+			return err
+		})
+		if err != nil || resp.GetNextPageToken() == "" {
+			return
+		}
+		req.SetPageToken(resp.GetNextPageToken())
+	}
+}
+
+// callUpsert rewrites batch random items of u's vault. Conflicts, which
+// callers sharing a vault cause, are counted apart from errors and the
+// stale version is corrected from the server's answer.
+func callUpsert(ctx context.Context, cli pb.GophKeeperClient, r *mrand.Rand, u *user, batch int, s *samples) {
+	u.mu.Lock()
+	picked := r.Perm(len(u.items))[:min(batch, len(u.items))]
+	ups := make([]*pb.UpsertItem, len(picked))
+	for j, i := range picked {
+		ups[j] = upsertItem(u.items[i], u.items[i].kind.blob(r))
+	}
+	u.mu.Unlock()
+
+	req := &pb.UpsertItemsRequest{}
+	req.SetItems(ups)
+	var resp *pb.UpsertItemsResponse
+	err := timed(ctx, s, func() error {
+		var err error
+		resp, err = cli.UpsertItems(u.ctx(ctx), req)
+		return err
+	})
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if err == nil {
+		for j, v := range resp.GetResults() {
+			u.items[picked[j]].ver = v.GetNewVer()
+		}
+		return
+	}
+	if idx, ver, ok := conflictAt(err); ok && idx < len(picked) {
+		s.errors--
+		s.conflicts++
+		u.items[picked[idx]].ver = ver
+	}
+}
+
+// conflictAt reads the batch index and current version of the item a
+// VERSION_CONFLICT error is about.
+func conflictAt(err error) (int, int64, bool) {
+	st, ok := status.FromError(err)
+	if !ok {
+		return 0, 0, false
+	}
+	for _, d := range st.Details() {
+		info, ok := d.(*errdetails.ErrorInfo)
+		if !ok || info.GetReason() != errs.ReasonVersionConflict {
+			continue
+		}
+		idx, err1 := strconv.Atoi(info.GetMetadata()["item"])
+		ver, err2 := strconv.ParseInt(info.GetMetadata()["current_ver"], 10, 64)
+		return idx, ver, err1 == nil && err2 == nil
+	}
+	return 0, 0, false
+}
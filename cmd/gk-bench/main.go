@@ -0,0 +1,134 @@
+// Command gk-bench loads a GophKeeper server with synthetic users and
+// reports call latencies. It registers the users, fills their vaults with
+// random ciphertexts shaped like real ones and then runs a weighted mix of
+// UpsertItems, GetChanges and GetItem for a while.
+//
+// Point it at a disposable server: the users it creates stay behind.
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+)
+
+// config holds the parsed command-line flags.
+type config struct {
+	addr        string
+	caPath      string
+	insecure    bool
+	users       int
+	items       int
+	largeShare  float64
+	duration    time.Duration
+	concurrency int
+	mix         map[string]int
+	batch       int
+	page        int
+	prefix      string
+	jsonOut     bool
+}
+
+func parseFlags(args []string) (config, error) {
+	var c config
+	fs := flag.NewFlagSet("gk-bench", flag.ContinueOnError)
+	fs.StringVar(&c.addr, "addr", "localhost:8443", "server address")
+	fs.StringVar(&c.caPath, "cacert", "", "CA certificate (PEM) to trust")
+	fs.BoolVar(&c.insecure, "insecure", false, "skip TLS verification")
+	fs.IntVar(&c.users, "users", 10, "synthetic users to register")
+	fs.IntVar(&c.items, "items", 200, "average items per vault")
+	fs.Float64Var(&c.largeShare, "large", 0.02, "share of items that are files (16 KiB to 512 KiB)")
+	fs.DurationVar(&c.duration, "duration", 30*time.Second, "how long to run the mix")
+	fs.IntVar(&c.concurrency, "concurrency", 8, "concurrent callers")
+	mix := fs.String("mix", "get=60,changes=25,upsert=15", "weights of the operations: get, changes, upsert")
+	fs.IntVar(&c.batch, "batch", 1, "items per UpsertItems call")
+	fs.IntVar(&c.page, "page", 0, "GetChanges page size; 0 is the server's limit")
+	fs.StringVar(&c.prefix, "prefix", "", "username prefix; random when empty")
+	fs.BoolVar(&c.jsonOut, "json", false, "print the report as JSON")
+	if err := fs.Parse(args); err != nil {
+		return c, err
+	}
+
+	var err error
+	if c.mix, err = parseMix(*mix); err != nil {
+		return c, err
+	}
+	switch {
+	case c.users < 1, c.items < 1, c.concurrency < 1, c.batch < 1:
+		return c, errors.New("-users, -items, -concurrency and -batch must be positive")
+	case c.page < 0, c.duration <= 0:
+		return c, errors.New("-page must not be negative and -duration must be positive")
+	case c.largeShare < 0 || c.largeShare > 1:
+		return c, errors.New("-large must be between 0 and 1")
+	}
+	if c.prefix == "" {
+		var b [3]byte
+		_, _ = rand.Read(b[:])
+		c.prefix = "bench-" + hex.EncodeToString(b[:]) + "-"
+	}
+	return c, nil
+}
+
+func dial(ctx context.Context, c config) (*grpc.ClientConn, error) {
+	cfg := &tls.Config{InsecureSkipVerify: c.insecure} //nolint:gosec // -insecure is for test servers
+	if c.caPath != "" && !c.insecure {
+		pem, err := os.ReadFile(c.caPath)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("bad CA cert")
+		}
+		cfg.RootCAs = pool
+	}
+	//nolint:staticcheck // DialContext is supported through 1.x; migrate when grpc.NewClient is stable
+	return grpc.DialContext(ctx, c.addr, grpc.WithTransportCredentials(credentials.NewTLS(cfg)))
+}
+
+func main() {
+	c, err := parseFlags(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "gk-bench:", err)
+		os.Exit(2)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	conn, err := dial(ctx, c)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "gk-bench:", err)
+		os.Exit(1)
+	}
+	defer conn.Close()
+
+	rep, err := run(ctx, c, pb.NewGophKeeperClient(conn))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "gk-bench:", err)
+		os.Exit(1)
+	}
+	if c.jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(rep)
+		return
+	}
+	rep.print(os.Stdout)
+}
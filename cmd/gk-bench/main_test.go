@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/and161185/goph-keeper/pkg/gktest"
+)
+
+func Test_parseMix(t *testing.T) {
+	mix, err := parseMix("get=3, upsert=1")
+	if err != nil || mix["get"] != 3 || mix["upsert"] != 1 || mix["changes"] != 0 {
+		t.Fatalf("%v %v", mix, err)
+	}
+	for _, bad := range []string{"", "get", "get=x", "put=1", "get=0", "get=-1,upsert=2"} {
+		if _, err := parseMix(bad); err == nil {
+			t.Errorf("%q accepted", bad)
+		}
+	}
+}
+
+func Test_percentile(t *testing.T) {
+	lat := make([]time.Duration, 100)
+	for i := range lat {
+		lat[i] = time.Duration(i+1) * time.Millisecond
+	}
+	for p, want := range map[float64]time.Duration{0.5: 50 * time.Millisecond, 0.99: 99 * time.Millisecond, 1: 100 * time.Millisecond} {
+		if got := percentile(lat, p); got != want {
+			t.Errorf("p%v = %v, want %v", p*100, got, want)
+		}
+	}
+	if percentile(nil, 0.5) != 0 {
+		t.Error("no samples")
+	}
+}
+
+func Test_run(t *testing.T) {
+	srv := gktest.Start(t)
+	c, err := parseFlags([]string{"-users", "3", "-items", "20", "-large", "0.1", "-concurrency", "4",
+		"-duration", "500ms", "-batch", "3", "-page", "7", "-mix", "get=1,changes=1,upsert=1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rep, err := run(context.Background(), c, srv.Client(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Users != 3 || rep.Items < 3*10 {
+		t.Fatalf("set up %d users with %d items", rep.Users, rep.Items)
+	}
+	if len(rep.Ops) != len(ops) {
+		t.Fatalf("ops: %+v", rep.Ops)
+	}
+	for _, o := range rep.Ops {
+		if o.Calls == 0 || o.Errors != 0 || o.P50MS <= 0 || o.P50MS > o.MaxMS {
+			t.Errorf("%+v", o)
+		}
+	}
+}
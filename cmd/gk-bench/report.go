@@ -0,0 +1,87 @@
+// cmd/gk-bench/report.go
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"text/tabwriter"
+	"time"
+)
+
+// report is what a run measured.
+type report struct {
+	Users       int        `json:"users"`
+	Items       int        `json:"items"`
+	SetupMS     float64    `json:"setup_ms"`
+	DurationMS  float64    `json:"duration_ms"`
+	Concurrency int        `json:"concurrency"`
+	Ops         []opReport `json:"ops"`
+}
+
+// opReport sums up one operation; latencies are of successful calls.
+type opReport struct {
+	Op        string  `json:"op"`
+	Calls     int     `json:"calls"`
+	Errors    int     `json:"errors"`
+	Conflicts int     `json:"conflicts,omitempty"`
+	PerSecond float64 `json:"per_second"`
+	P50MS     float64 `json:"p50_ms"`
+	P90MS     float64 `json:"p90_ms"`
+	P99MS     float64 `json:"p99_ms"`
+	MaxMS     float64 `json:"max_ms"`
+}
+
+func ms(d time.Duration) float64 { return float64(d.Microseconds()) / 1000 }
+
+// percentile returns the latency below which p of the sorted lat fall.
+func percentile(lat []time.Duration, p float64) time.Duration {
+	if len(lat) == 0 {
+		return 0
+	}
+	i := int(p*float64(len(lat))+0.5) - 1
+	return lat[min(max(i, 0), len(lat)-1)]
+}
+
+func newReport(c config, items int, setup, took time.Duration, results []map[string]*samples) *report {
+	rep := &report{Users: c.users, Items: items, SetupMS: ms(setup), DurationMS: ms(took), Concurrency: c.concurrency}
+	for _, op := range ops {
+		var all samples
+		for _, res := range results {
+			if s := res[op]; s != nil {
+				all.lat = append(all.lat, s.lat...)
+				all.errors += s.errors
+				all.conflicts += s.conflicts
+			}
+		}
+		calls := len(all.lat) + all.errors + all.conflicts
+		if calls == 0 {
+			continue
+		}
+		slices.Sort(all.lat)
+		rep.Ops = append(rep.Ops, opReport{
+			Op:        op,
+			Calls:     calls,
+			Errors:    all.errors,
+			Conflicts: all.conflicts,
+			PerSecond: float64(len(all.lat)) / took.Seconds(),
+			P50MS:     ms(percentile(all.lat, 0.50)),
+			P90MS:     ms(percentile(all.lat, 0.90)),
+			P99MS:     ms(percentile(all.lat, 0.99)),
+			MaxMS:     ms(percentile(all.lat, 1)),
+		})
+	}
+	return rep
+}
+
+func (r *report) print(w io.Writer) {
+	fmt.Fprintf(w, "%d users, %d items, set up in %.1fs; %d callers for %.1fs\n\n",
+		r.Users, r.Items, r.SetupMS/1000, r.Concurrency, r.DurationMS/1000)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "op\tcalls\terrors\tconflicts\tok/s\tp50 ms\tp90 ms\tp99 ms\tmax ms\t")
+	for _, o := range r.Ops {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%.1f\t%.2f\t%.2f\t%.2f\t%.2f\t\n",
+			o.Op, o.Calls, o.Errors, o.Conflicts, o.PerSecond, o.P50MS, o.P90MS, o.P99MS, o.MaxMS)
+	}
+	_ = tw.Flush()
+}
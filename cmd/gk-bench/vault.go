@@ -0,0 +1,166 @@
+// cmd/gk-bench/vault.go
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	mrand "math/rand/v2"
+	"sync"
+
+	"github.com/gofrs/uuid/v5"
+	"google.golang.org/grpc/metadata"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+)
+
+// sealOverhead is what encryption adds to a payload: the blob header,
+// nonce and tag written by clientcrypto.
+const sealOverhead = 45
+
+// seedMaxBytes caps one seeding UpsertItems call below the server's 1 MiB
+// message limit.
+const seedMaxBytes = 768 << 10
+
+// kind is a sort of vault item and the plaintext sizes it comes in.
+type kind struct {
+	name     string
+	min, max int
+	large    bool
+}
+
+// Records make up most of a vault; files are drawn with -large.
+var (
+	records = []struct {
+		kind
+		weight int
+	}{
+		{kind{"login", 200, 900, false}, 70},
+		{kind{"card", 250, 500, false}, 10},
+		{kind{"note", 100, 8 << 10, false}, 20},
+	}
+	fileKind = kind{"file", 16 << 10, 512 << 10, true}
+)
+
+func pickKind(r *mrand.Rand, largeShare float64) kind {
+	if r.Float64() < largeShare {
+		return fileKind
+	}
+	n := r.IntN(100)
+	for _, k := range records {
+		if n < k.weight {
+			return k.kind
+		}
+		n -= k.weight
+	}
+	return records[0].kind
+}
+
+// blob returns a random ciphertext as long as an encrypted payload of k.
+func (k kind) blob(r *mrand.Rand) []byte {
+	b := make([]byte, k.min+r.IntN(k.max-k.min+1)+sealOverhead)
+	_, _ = rand.Read(b)
+	return b
+}
+
+func (k kind) hints() *pb.ItemHints {
+	h := &pb.ItemHints{}
+	h.SetSizeClass(pb.SizeClass_SIZE_CLASS_SMALL)
+	if k.large {
+		h.SetSizeClass(pb.SizeClass_SIZE_CLASS_LARGE)
+	}
+	h.SetSchemaVersion(1)
+	return h
+}
+
+// item is the bench's view of a stored item.
+type item struct {
+	id   string
+	kind kind
+	ver  int64
+}
+
+// user is a synthetic account and its vault. mu guards the versions, which
+// concurrent upserts of the same vault move on.
+type user struct {
+	name  string
+	token string
+	mu    sync.Mutex
+	items []item
+}
+
+// ctx returns a context whose calls carry u's token.
+func (u *user) ctx(ctx context.Context) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+u.token)
+}
+
+// newUser registers and logs in name with a random password.
+func newUser(ctx context.Context, cli pb.GophKeeperClient, name string) (*user, error) {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	password := hex.EncodeToString(b[:])
+
+	rr := &pb.RegisterRequest{}
+	rr.SetUsername(name)
+	rr.SetPassword(password)
+	if _, err := cli.Register(ctx, rr); err != nil {
+		return nil, fmt.Errorf("register %s: %w", name, err)
+	}
+	lr := &pb.LoginRequest{}
+	lr.SetUsername(name)
+	lr.SetPassword(password)
+	lr.SetDeviceLabel("gk-bench")
+	resp, err := cli.Login(ctx, lr)
+	if err != nil {
+		return nil, fmt.Errorf("login %s: %w", name, err)
+	}
+	return &user{name: name, token: resp.GetAccessToken()}, nil
+}
+
+// seed fills u's vault with n items, several per call.
+func (u *user) seed(ctx context.Context, cli pb.GophKeeperClient, r *mrand.Rand, n int, largeShare float64) error {
+	var batch []*pb.UpsertItem
+	var size int
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		req := &pb.UpsertItemsRequest{}
+		req.SetItems(batch)
+		resp, err := cli.UpsertItems(u.ctx(ctx), req)
+		if err != nil {
+			return fmt.Errorf("seed %s: %w", u.name, err)
+		}
+		for i, v := range resp.GetResults() {
+			u.items[len(u.items)-len(batch)+i].ver = v.GetNewVer()
+		}
+		batch, size = nil, 0
+		return nil
+	}
+	for range n {
+		k := pickKind(r, largeShare)
+		it := item{id: uuid.Must(uuid.NewV7()).String(), kind: k}
+		up := upsertItem(it, k.blob(r))
+		if size+len(up.GetBlobEnc().GetCiphertext()) > seedMaxBytes || len(batch) == 200 {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		u.items = append(u.items, it)
+		batch = append(batch, up)
+		size += len(up.GetBlobEnc().GetCiphertext())
+	}
+	return flush()
+}
+
+func upsertItem(it item, blob []byte) *pb.UpsertItem {
+	eb := &pb.EncryptedBlob{}
+	eb.SetCiphertext(blob)
+	up := &pb.UpsertItem{}
+	up.SetId(it.id)
+	up.SetBaseVer(it.ver)
+	up.SetBlobEnc(eb)
+	up.SetHints(it.kind.hints())
+	return up
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	"github.com/and161185/goph-keeper/internal/auditlog"
+)
+
+// auditConfig holds the -audit-* flags.
+type auditConfig struct {
+	enabled    bool
+	forward    string
+	forwardFmt string
+	queue      int
+}
+
+func registerAuditFlags(fs *flag.FlagSet, c *auditConfig) {
+	fs.BoolVar(&c.enabled, "audit", true, "record security events in the audit_events table")
+	fs.StringVar(&c.forward, "audit-forward", "", "also send events to udp://, tcp:// or tls:// syslog, or an http(s):// endpoint")
+	fs.StringVar(&c.forwardFmt, "audit-forward-format", "", "json, cef or syslog (default: syslog for syslog URLs, json for HTTP)")
+	fs.IntVar(&c.queue, "audit-forward-queue", 1000, "events buffered while the forward target is slow; overflow is dropped")
+}
+
+// newAuditLog returns the audit trail and its forwarder, which the caller
+// runs; both are nil when auditing is off.
+func newAuditLog(c auditConfig, pool *pgxpool.Pool, logger *zap.Logger) (*auditlog.Log, *auditlog.Forwarder, error) {
+	if !c.enabled && c.forward == "" {
+		return nil, nil, nil
+	}
+	l := &auditlog.Log{Logger: logger}
+	if c.enabled {
+		l.DB = pool
+	}
+	if c.forward == "" {
+		return l, nil, nil
+	}
+	name := c.forwardFmt
+	if name == "" {
+		name = "syslog"
+		if strings.HasPrefix(c.forward, "http") {
+			name = "json"
+		}
+	}
+	host, _ := os.Hostname()
+	format, err := auditlog.NewFormatter(name, host, version)
+	if err != nil {
+		return nil, nil, err
+	}
+	if l.Forward, err = auditlog.NewForwarder(c.forward, format, c.queue, logger); err != nil {
+		return nil, nil, err
+	}
+	return l, l.Forward, nil
+}
+
+const auditUsage = `usage: gk-server audit export|prune [server flags]
+  export [-format json|cef|syslog] [-since T] [-until T] [-o FILE]
+                        write stored events, oldest first (T: RFC 3339 or a duration ago, e.g. 24h)
+  prune -before T       delete events older than T`
+
+// auditCommand handles "gk-server audit ...". It returns false when args
+// are not an audit command.
+func auditCommand(args []string) bool {
+	if len(args) == 0 || args[0] != "audit" {
+		return false
+	}
+	if len(args) < 2 {
+		fmt.Fprintln(os.Stderr, auditUsage)
+		os.Exit(2)
+	}
+	var c config
+	fs := flag.NewFlagSet("audit "+args[1], flag.ExitOnError)
+	registerFlags(fs, &c)
+	format := fs.String("format", "json", "export: json, cef or syslog")
+	since := fs.String("since", "", "export: first event time")
+	until := fs.String("until", "", "export: stop before this time")
+	out := fs.String("o", "", "export: output file (default stdout)")
+	before := fs.String("before", "", "prune: delete events older than this")
+	_ = fs.Parse(args[2:])
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	var err error
+	switch args[1] {
+	case "export":
+		err = auditExport(ctx, c, *format, *since, *until, *out)
+	case "prune":
+		err = auditPrune(ctx, c, *before)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown audit command %q\n%s\n", args[1], auditUsage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	return true
+}
+
+// parseAuditTime reads an RFC 3339 time or a duration before now; "" is the
+// zero time.
+func parseAuditTime(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return time.Time{}, fmt.Errorf("time %q: want RFC 3339 or a positive duration", s)
+	}
+	return now.Add(-d), nil
+}
+
+func auditExport(ctx context.Context, c config, format, since, until, out string) error {
+	host, _ := os.Hostname()
+	f, err := auditlog.NewFormatter(format, host, version)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	from, err := parseAuditTime(since, now)
+	if err != nil {
+		return err
+	}
+	to, err := parseAuditTime(until, now)
+	if err != nil {
+		return err
+	}
+
+	var dst io.Writer = os.Stdout
+	if out != "" {
+		file, err := os.OpenFile(out, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		dst = file
+	}
+	pool, err := openPool(ctx, c)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	w := bufio.NewWriter(dst)
+	n := 0
+	err = auditlog.Export(ctx, pool, from, to, func(e auditlog.Event) error {
+		n++
+		_, err := w.Write(append(f(e), '\n'))
+		return err
+	})
+	if err == nil {
+		err = w.Flush()
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "exported %d events\n", n)
+	return nil
+}
+
+func auditPrune(ctx context.Context, c config, before string) error {
+	if before == "" {
+		return errors.New("prune: -before is required")
+	}
+	t, err := parseAuditTime(before, time.Now())
+	if err != nil {
+		return err
+	}
+	pool, err := openPool(ctx, c)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+	n, err := auditlog.Prune(ctx, pool, t)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("deleted %d events older than %s\n", n, t.Format(time.RFC3339))
+	return nil
+}
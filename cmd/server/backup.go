@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	"github.com/and161185/goph-keeper/internal/backup"
+	"github.com/and161185/goph-keeper/internal/migrate"
+	"github.com/and161185/goph-keeper/internal/secrets"
+)
+
+// backupConfig holds the -backup-* flags.
+type backupConfig struct {
+	url      string
+	endpoint string
+	interval time.Duration
+	scope    string
+	keep     int
+	maxAge   time.Duration
+	keyFile  string
+	keyRef   string
+}
+
+func registerBackupFlags(fs *flag.FlagSet, c *backupConfig) {
+	fs.StringVar(&c.url, "backup-url", "", "snapshot store: s3://bucket/prefix or file:///dir")
+	fs.StringVar(&c.endpoint, "backup-s3-endpoint", "", "S3-compatible endpoint URL (path-style), empty = AWS")
+	fs.DurationVar(&c.interval, "backup-interval", 0, "take snapshots this often (0 = no scheduled backups)")
+	fs.StringVar(&c.scope, "backup-scope", "full", "full (one snapshot of the database) or per-user")
+	fs.IntVar(&c.keep, "backup-keep", 7, "snapshots kept per scope (0 = no limit)")
+	fs.DurationVar(&c.maxAge, "backup-max-age", 0, "delete snapshots older than this, except the newest (0 = off)")
+	fs.StringVar(&c.keyFile, "backup-key-file", "", "read the 32-byte backup key (hex or base64) from this file")
+	fs.StringVar(&c.keyRef, "backup-key-ref", "", "backup key from a secret provider (env:, file:, vault:, awssm:)")
+}
+
+// store opens the snapshot store.
+func (c backupConfig) store() (backup.Store, error) {
+	if c.url == "" {
+		return nil, errors.New("-backup-url is required")
+	}
+	return backup.Open(c.url, c.endpoint)
+}
+
+// key resolves the backup key.
+func (c backupConfig) key(ctx context.Context) ([]byte, error) {
+	raw, err := secrets.Default().Pick(ctx, "backup key", "", c.keyFile, c.keyRef)
+	if err != nil {
+		return nil, err
+	}
+	if raw == "" {
+		return nil, errors.New("backup key: set -backup-key-file or -backup-key-ref")
+	}
+	return backup.ParseKey(raw)
+}
+
+func newScheduler(ctx context.Context, c backupConfig, pool *pgxpool.Pool, logger *zap.Logger) (*backup.Scheduler, error) {
+	if c.scope != "full" && c.scope != "per-user" {
+		return nil, fmt.Errorf("-backup-scope %q: want full or per-user", c.scope)
+	}
+	store, err := c.store()
+	if err != nil {
+		return nil, err
+	}
+	key, err := c.key(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &backup.Scheduler{
+		DB:       pool,
+		Store:    store,
+		Key:      key,
+		Interval: c.interval,
+		PerUser:  c.scope == "per-user",
+		Keep:     c.keep,
+		MaxAge:   c.maxAge,
+		Logger:   logger,
+	}, nil
+}
+
+const backupUsage = `usage: gk-server backup now|list|restore [server and -backup-* flags]
+  now                                  take snapshots (-backup-scope) and apply retention
+  list                                 list stored snapshots
+  restore -object KEY | -file PATH     load a snapshot; -replace empties its scope first`
+
+// backupCommand handles "gk-server backup ...". It returns false when args
+// are not a backup command.
+func backupCommand(args []string) bool {
+	if len(args) == 0 || args[0] != "backup" {
+		return false
+	}
+	if len(args) < 2 {
+		fmt.Fprintln(os.Stderr, backupUsage)
+		os.Exit(2)
+	}
+	var c config
+	fs := flag.NewFlagSet("backup "+args[1], flag.ExitOnError)
+	registerFlags(fs, &c)
+	object := fs.String("object", "", "restore: snapshot key in the store (see backup list)")
+	file := fs.String("file", "", "restore: sealed snapshot file")
+	replace := fs.Bool("replace", false, "restore: delete the snapshot's users (all users for a full one) first")
+	_ = fs.Parse(args[2:])
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	var err error
+	switch args[1] {
+	case "now":
+		err = backupNow(ctx, c)
+	case "list":
+		err = backupList(ctx, c.backup)
+	case "restore":
+		err = backupRestore(ctx, c, *object, *file, *replace)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown backup command %q\n%s\n", args[1], backupUsage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	return true
+}
+
+// openPool connects to the database configured by c.
+func openPool(ctx context.Context, c config) (*pgxpool.Pool, error) {
+	dsn, err := secrets.Default().Pick(ctx, "dsn", c.dsn, c.dsnFile, c.dsnRef)
+	if err != nil {
+		return nil, err
+	}
+	if dsn == "" {
+		dsn = defaultDSN
+	}
+	poolCfg, err := c.db.PoolConfig(dsn)
+	if err != nil {
+		return nil, err
+	}
+	if dsn, err = c.db.ApplyDSN(dsn); err != nil {
+		return nil, err
+	}
+	if err := migrate.Up(ctx, dsn); err != nil { // a restore target may be empty
+		return nil, fmt.Errorf("migrate up: %w", err)
+	}
+	return pgxpool.NewWithConfig(ctx, poolCfg)
+}
+
+func backupNow(ctx context.Context, c config) error {
+	pool, err := openPool(ctx, c)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+	logger, _ := zap.NewProduction()
+	defer func() { _ = logger.Sync() }()
+	sched, err := newScheduler(ctx, c.backup, pool, logger)
+	if err != nil {
+		return err
+	}
+	return sched.RunOnce(ctx)
+}
+
+func backupList(ctx context.Context, c backupConfig) error {
+	store, err := c.store()
+	if err != nil {
+		return err
+	}
+	objs, err := store.List(ctx, "")
+	if err != nil {
+		return err
+	}
+	for _, o := range objs {
+		fmt.Printf("%-60s %12d\n", o.Key, o.Size)
+	}
+	return nil
+}
+
+func backupRestore(ctx context.Context, c config, object, file string, replace bool) error {
+	if (object == "") == (file == "") {
+		return errors.New("restore: give exactly one of -object and -file")
+	}
+	key, err := c.backup.key(ctx)
+	if err != nil {
+		return err
+	}
+	var src io.ReadCloser
+	if file != "" {
+		src, err = os.Open(file)
+	} else {
+		var store backup.Store
+		if store, err = c.backup.store(); err == nil {
+			src, err = store.Get(ctx, object)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	plain, err := backup.OpenSnapshot(src, key)
+	if err != nil {
+		return err
+	}
+
+	pool, err := openPool(ctx, c)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+	h, st, err := backup.Restore(ctx, pool, plain, replace, c.legalHold)
+	if errors.Is(err, backup.ErrExists) {
+		return fmt.Errorf("%w; use -replace to overwrite", err)
+	}
+	if err != nil {
+		return err
+	}
+	scope := "full"
+	if h.UserID != nil {
+		scope = "user " + h.UserID.String()
+	}
+	fmt.Printf("restored %s snapshot of %s: %d users, %d items, %d history rows\n",
+		scope, h.CreatedAt.Format(time.RFC3339), st.Users, st.Items, st.History)
+	return nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/and161185/goph-keeper/internal/challenge"
+	"github.com/and161185/goph-keeper/internal/secrets"
+)
+
+// challengeConfig holds the -challenge-* flags.
+type challengeConfig struct {
+	kind       string
+	powBits    int
+	siteKey    string
+	secretRef  string
+	loginAfter int
+}
+
+func registerChallengeFlags(fs *flag.FlagSet, c *challengeConfig) {
+	fs.StringVar(&c.kind, "challenge", "", "make Register, and Login after repeated wrong passwords, answer a challenge: pow, hcaptcha or turnstile (empty = off)")
+	fs.IntVar(&c.powBits, "challenge-pow-bits", 20, "proof-of-work difficulty in leading zero bits; each more doubles the client's work")
+	fs.StringVar(&c.siteKey, "challenge-site-key", "", "hCaptcha or Turnstile site key")
+	fs.StringVar(&c.secretRef, "challenge-secret-ref", "", "hCaptcha or Turnstile secret key from a secret provider (env:, file:, vault:, awssm:)")
+	fs.IntVar(&c.loginAfter, "challenge-login-after", 3, "wrong passwords since an account's last login before its logins are challenged")
+}
+
+// newChallenge builds the verifier -challenge selects, nil if none.
+func newChallenge(ctx context.Context, c challengeConfig, res *secrets.Resolver) (challenge.Verifier, error) {
+	switch c.kind {
+	case "":
+		return nil, nil
+	case challenge.KindPoW:
+		return challenge.NewPoW(c.powBits, 5*time.Minute)
+	case challenge.KindHCaptcha, challenge.KindTurnstile:
+		if c.siteKey == "" || c.secretRef == "" {
+			return nil, fmt.Errorf("-challenge %s needs -challenge-site-key and -challenge-secret-ref", c.kind)
+		}
+		secret, err := res.Pick(ctx, "challenge secret", "", "", c.secretRef)
+		if err != nil {
+			return nil, err
+		}
+		cli := &http.Client{Timeout: 10 * time.Second}
+		if c.kind == challenge.KindHCaptcha {
+			return challenge.NewHCaptcha(c.siteKey, secret, cli), nil
+		}
+		return challenge.NewTurnstile(c.siteKey, secret, cli), nil
+	}
+	return nil, fmt.Errorf("-challenge %q: want pow, hcaptcha or turnstile", c.kind)
+}
@@ -0,0 +1,37 @@
+//go:build chaos
+
+package main
+
+import (
+	"flag"
+
+	"go.uber.org/zap"
+
+	"github.com/and161185/goph-keeper/internal/chaos"
+	"github.com/and161185/goph-keeper/internal/repository"
+)
+
+// chaosConfig holds -chaos, which only builds with the chaos tag have.
+type chaosConfig struct {
+	faults string
+}
+
+func registerChaosFlags(fs *flag.FlagSet, c *chaosConfig) {
+	fs.StringVar(&c.faults, "chaos", "", "inject faults into database calls, e.g. latency=50ms,serialization=0.05,drop=0.01,seed=1 (empty = none)")
+}
+
+// withChaos puts the fault injector between the repositories and the
+// breaker, so the breaker sees the injected failures as it would real ones.
+func withChaos(c chaosConfig, logger *zap.Logger, users repository.UserRepository, items repository.ItemRepository) (repository.UserRepository, repository.ItemRepository) {
+	if c.faults == "" {
+		return users, items
+	}
+	f, err := chaos.Parse(c.faults)
+	if err != nil {
+		logger.Fatal("chaos", zap.Error(err))
+	}
+	logger.Warn("injecting database faults; never run this build in production",
+		zap.Duration("latency", f.Latency), zap.Float64("serialization", f.Serialization), zap.Float64("drop", f.Drop))
+	in := chaos.New(f)
+	return chaos.NewUserRepo(in, users), chaos.NewItemRepo(in, items)
+}
@@ -0,0 +1,21 @@
+//go:build !chaos
+
+package main
+
+import (
+	"flag"
+
+	"go.uber.org/zap"
+
+	"github.com/and161185/goph-keeper/internal/repository"
+)
+
+// chaosConfig is empty: fault injection is left out of regular builds.
+type chaosConfig struct{}
+
+func registerChaosFlags(*flag.FlagSet, *chaosConfig) {}
+
+// withChaos returns the repositories unchanged.
+func withChaos(_ chaosConfig, _ *zap.Logger, users repository.UserRepository, items repository.ItemRepository) (repository.UserRepository, repository.ItemRepository) {
+	return users, items
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/and161185/goph-keeper/internal/partition"
+)
+
+const partitionUsage = `usage: gk-server partition status|items [server flags]
+  status                 show whether items is hash partitioned by user
+  items -parts N         rebuild items as N partitions; stop the servers first`
+
+// partitionCommand handles "gk-server partition ...". It returns false when
+// args are not a partition command.
+func partitionCommand(args []string) bool {
+	if len(args) == 0 || args[0] != "partition" {
+		return false
+	}
+	if len(args) < 2 {
+		fmt.Fprintln(os.Stderr, partitionUsage)
+		os.Exit(2)
+	}
+	var c config
+	fs := flag.NewFlagSet("partition "+args[1], flag.ExitOnError)
+	registerFlags(fs, &c)
+	parts := fs.Int("parts", 16, "items: number of hash partitions")
+	_ = fs.Parse(args[2:])
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	var err error
+	switch args[1] {
+	case "status", "items":
+		err = partitionRun(ctx, c, args[1] == "items", *parts)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown partition command %q\n%s\n", args[1], partitionUsage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	return true
+}
+
+func partitionRun(ctx context.Context, c config, convert bool, parts int) error {
+	pool, err := openPool(ctx, c)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+	if convert {
+		if err := partition.Items(ctx, pool, parts); err != nil {
+			return err
+		}
+	}
+	n, err := partition.Parts(ctx, pool)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		fmt.Println("items: not partitioned")
+		return nil
+	}
+	fmt.Printf("items: %d hash partitions by user_id\n", n)
+	return nil
+}
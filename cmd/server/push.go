@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"net/http"
+	"os"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/and161185/goph-keeper/internal/push"
+	"github.com/and161185/goph-keeper/internal/secrets"
+	"github.com/and161185/goph-keeper/internal/tokensign"
+)
+
+// pushConfig holds the -push-* flags. Each platform is enabled by setting
+// its flags; with none set the device RPCs are disabled.
+type pushConfig struct {
+	fcmProject   string
+	apnsKeyFile  string
+	apnsKeyID    string
+	apnsTeam     string
+	apnsTopic    string
+	apnsSandbox  bool
+	ntfyURL      string
+	ntfyTokenRef string
+	maxDevices   int
+}
+
+func registerPushFlags(fs *flag.FlagSet, c *pushConfig) {
+	fs.StringVar(&c.fcmProject, "push-fcm-project", "", "Firebase project id; enables FCM pushes (credentials: GOOGLE_OAUTH_ACCESS_TOKEN or the GCP metadata server)")
+	fs.StringVar(&c.apnsKeyFile, "push-apns-key-file", "", "APNs .p8 signing key; enables APNs pushes with -push-apns-key-id, -push-apns-team and -push-apns-topic")
+	fs.StringVar(&c.apnsKeyID, "push-apns-key-id", "", "APNs key id")
+	fs.StringVar(&c.apnsTeam, "push-apns-team", "", "Apple developer team id")
+	fs.StringVar(&c.apnsTopic, "push-apns-topic", "", "app bundle id")
+	fs.BoolVar(&c.apnsSandbox, "push-apns-sandbox", false, "use the APNs development environment")
+	fs.StringVar(&c.ntfyURL, "push-ntfy-url", "", "ntfy server, e.g. https://ntfy.sh; enables ntfy pushes")
+	fs.StringVar(&c.ntfyTokenRef, "push-ntfy-token-ref", "", "ntfy access token from a secret provider (env:, file:, vault:, awssm:)")
+	fs.IntVar(&c.maxDevices, "push-max-devices", 10, "push devices per user")
+}
+
+// newPushProviders builds the providers the flags enable, keyed by platform.
+func newPushProviders(ctx context.Context, c pushConfig, res *secrets.Resolver) (map[string]push.Provider, error) {
+	cli := &http.Client{Timeout: 10 * time.Second}
+	ps := make(map[string]push.Provider)
+	if c.fcmProject != "" {
+		ps[push.FCM] = &push.FCMProvider{Project: c.fcmProject, HTTP: cli, Token: tokensign.GCPDefaultToken(cli)}
+	}
+	if c.apnsKeyFile != "" {
+		if c.apnsKeyID == "" || c.apnsTeam == "" || c.apnsTopic == "" {
+			return nil, errors.New("-push-apns-key-file needs -push-apns-key-id, -push-apns-team and -push-apns-topic")
+		}
+		raw, err := os.ReadFile(c.apnsKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		key, err := push.ParseAPNsKey(raw)
+		if err != nil {
+			return nil, err
+		}
+		endpoint := push.APNsProduction
+		if c.apnsSandbox {
+			endpoint = push.APNsSandbox
+		}
+		ps[push.APNs] = &push.APNsProvider{
+			KeyID: c.apnsKeyID, TeamID: c.apnsTeam, Topic: c.apnsTopic,
+			Key: key, Endpoint: endpoint, HTTP: cli,
+		}
+	}
+	if c.ntfyURL != "" {
+		tok, err := res.Pick(ctx, "ntfy token", "", "", c.ntfyTokenRef)
+		if err != nil {
+			return nil, err
+		}
+		ps[push.Ntfy] = &push.NtfyProvider{BaseURL: c.ntfyURL, AccessToken: tok, HTTP: cli}
+	}
+	return ps, nil
+}
+
+// newPushDispatcher returns the push dispatcher, or nil when no platform is
+// configured.
+func newPushDispatcher(ctx context.Context, c pushConfig, res *secrets.Resolver, devices push.Devices, logger *zap.Logger) (*push.Dispatcher, error) {
+	ps, err := newPushProviders(ctx, c, res)
+	if err != nil || len(ps) == 0 {
+		return nil, err
+	}
+	return push.NewDispatcher(devices, ps, logger), nil
+}
@@ -0,0 +1,25 @@
+//go:build !windows
+
+package main
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+)
+
+// serviceCommand handles "gk-server service ..."; Windows only.
+func serviceCommand([]string) bool { return false }
+
+// isService reports whether the process was started by a service manager
+// that needs the Windows service protocol.
+func isService() bool { return false }
+
+// runService is never reached outside Windows.
+func runService(func(ctx context.Context)) error {
+	return errors.New("windows services are not supported on this platform")
+}
+
+// serviceLogger returns logger unchanged.
+func serviceLogger(logger *zap.Logger) *zap.Logger { return logger }
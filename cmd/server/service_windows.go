@@ -0,0 +1,237 @@
+//go:build windows
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/eventlog"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// serviceName is both the SCM service name and the event log source.
+const serviceName = "gk-server"
+
+// serviceCommand handles "gk-server service install|uninstall|start|stop".
+// It returns false when args are not a service command.
+func serviceCommand(args []string) bool {
+	if len(args) == 0 || args[0] != "service" {
+		return false
+	}
+	if len(args) < 2 {
+		fmt.Fprintln(os.Stderr, "usage: gk-server service install [server flags...] | uninstall | start | stop")
+		os.Exit(2)
+	}
+	var err error
+	switch args[1] {
+	case "install":
+		err = installService(args[2:])
+	case "uninstall":
+		err = uninstallService()
+	case "start":
+		err = withService(func(s *mgr.Service) error { return s.Start() })
+	case "stop":
+		err = withService(func(s *mgr.Service) error {
+			_, err := s.Control(svc.Stop)
+			return err
+		})
+	default:
+		fmt.Fprintf(os.Stderr, "unknown service command %q\n", args[1])
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	return true
+}
+
+// installService registers the current executable as an auto-start service
+// that is run with flags, plus an event log source for its output.
+func installService(flags []string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if exe, err = filepath.Abs(exe); err != nil {
+		return err
+	}
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("connect to service manager: %w", err)
+	}
+	defer m.Disconnect()
+	if s, err := m.OpenService(serviceName); err == nil {
+		s.Close()
+		return fmt.Errorf("service %s already exists", serviceName)
+	}
+	s, err := m.CreateService(serviceName, exe, mgr.Config{
+		DisplayName: "GophKeeper server",
+		Description: "GophKeeper encrypted vault gRPC server",
+		StartType:   mgr.StartAutomatic,
+	}, flags...)
+	if err != nil {
+		return fmt.Errorf("create service: %w", err)
+	}
+	defer s.Close()
+	if err := eventlog.InstallAsEventCreate(serviceName, eventlog.Error|eventlog.Warning|eventlog.Info); err != nil {
+		_ = s.Delete()
+		return fmt.Errorf("install event log source: %w", err)
+	}
+	// restart after crashes (Fatal logs, lost DB at startup); reset the failure count daily
+	_ = s.SetRecoveryActions([]mgr.RecoveryAction{{Type: mgr.ServiceRestart, Delay: 5 * time.Second}}, 86400)
+	fmt.Printf("service %s installed: %s %v\n", serviceName, exe, flags)
+	return nil
+}
+
+// uninstallService removes the service and its event log source.
+func uninstallService() error {
+	if err := withService(func(s *mgr.Service) error { return s.Delete() }); err != nil {
+		return err
+	}
+	if err := eventlog.Remove(serviceName); err != nil {
+		return fmt.Errorf("remove event log source: %w", err)
+	}
+	fmt.Printf("service %s removed\n", serviceName)
+	return nil
+}
+
+// withService opens the installed service and calls fn with it.
+func withService(fn func(*mgr.Service) error) error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("connect to service manager: %w", err)
+	}
+	defer m.Disconnect()
+	s, err := m.OpenService(serviceName)
+	if err != nil {
+		return fmt.Errorf("service %s: %w", serviceName, err)
+	}
+	defer s.Close()
+	return fn(s)
+}
+
+// isService reports whether the process was started by the service control manager.
+func isService() bool {
+	ok, err := svc.IsWindowsService()
+	return err == nil && ok
+}
+
+// runService hands the process over to the service control manager; run
+// executes until its context is cancelled by a stop or shutdown request.
+func runService(run func(ctx context.Context)) error {
+	return svc.Run(serviceName, &gkService{run: run})
+}
+
+// gkService adapts the server to svc.Handler.
+type gkService struct {
+	run func(ctx context.Context)
+}
+
+// Execute implements svc.Handler.
+func (g *gkService) Execute(_ []string, req <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	status <- svc.Status{State: svc.StartPending}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		g.run(ctx)
+	}()
+	status <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+
+	for {
+		select {
+		case <-done:
+			return false, 0
+		case c := <-req:
+			switch c.Cmd {
+			case svc.Interrogate:
+				status <- c.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				status <- svc.Status{State: svc.StopPending}
+				cancel()
+				<-done
+				return false, 0
+			}
+		}
+	}
+}
+
+// serviceLogger additionally sends log entries to the Windows event log when
+// running as a service, where stderr goes nowhere.
+func serviceLogger(logger *zap.Logger) *zap.Logger {
+	if !isService() {
+		return logger
+	}
+	el, err := eventlog.Open(serviceName)
+	if err != nil {
+		logger.Warn("event log unavailable", zap.Error(err))
+		return logger
+	}
+	core := &eventlogCore{
+		LevelEnabler: zapcore.InfoLevel,
+		enc:          zapcore.NewConsoleEncoder(zap.NewProductionEncoderConfig()),
+		log:          el,
+	}
+	return logger.WithOptions(zap.WrapCore(func(c zapcore.Core) zapcore.Core { return zapcore.NewTee(c, core) }))
+}
+
+// eventWriter is the part of *eventlog.Log eventlogCore writes through.
+type eventWriter interface {
+	Error(eid uint32, msg string) error
+	Warning(eid uint32, msg string) error
+	Info(eid uint32, msg string) error
+}
+
+// eventlogCore is a zapcore.Core writing to the Windows event log.
+type eventlogCore struct {
+	zapcore.LevelEnabler
+	enc zapcore.Encoder
+	log eventWriter
+}
+
+// With implements zapcore.Core.
+func (c *eventlogCore) With(fields []zapcore.Field) zapcore.Core {
+	enc := c.enc.Clone()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	return &eventlogCore{LevelEnabler: c.LevelEnabler, enc: enc, log: c.log}
+}
+
+// Check implements zapcore.Core.
+func (c *eventlogCore) Check(e zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(e.Level) {
+		return ce.AddCore(e, c)
+	}
+	return ce
+}
+
+// Write implements zapcore.Core, mapping zap levels onto event types.
+func (c *eventlogCore) Write(e zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(e, fields)
+	if err != nil {
+		return err
+	}
+	msg := buf.String()
+	buf.Free()
+	switch {
+	case e.Level >= zapcore.ErrorLevel:
+		return c.log.Error(1, msg)
+	case e.Level == zapcore.WarnLevel:
+		return c.log.Warning(1, msg)
+	default:
+		return c.log.Info(1, msg)
+	}
+}
+
+// Sync implements zapcore.Core.
+func (c *eventlogCore) Sync() error { return nil }
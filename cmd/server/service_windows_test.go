@@ -0,0 +1,106 @@
+//go:build windows
+
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"golang.org/x/sys/windows/svc"
+)
+
+func TestGkService_Stop(t *testing.T) {
+	stopped := make(chan struct{})
+	g := &gkService{run: func(ctx context.Context) {
+		<-ctx.Done()
+		close(stopped)
+	}}
+	req := make(chan svc.ChangeRequest)
+	status := make(chan svc.Status, 4)
+	type result struct {
+		ssec bool
+		code uint32
+	}
+	done := make(chan result, 1)
+	go func() {
+		ssec, code := g.Execute(nil, req, status)
+		done <- result{ssec, code}
+	}()
+
+	if s := <-status; s.State != svc.StartPending {
+		t.Fatalf("first status %v", s.State)
+	}
+	if s := <-status; s.State != svc.Running || s.Accepts != svc.AcceptStop|svc.AcceptShutdown {
+		t.Fatalf("running status %+v", s)
+	}
+	cur := svc.Status{State: svc.Running}
+	req <- svc.ChangeRequest{Cmd: svc.Interrogate, CurrentStatus: cur}
+	if s := <-status; s != cur {
+		t.Fatalf("interrogate answered %+v", s)
+	}
+
+	req <- svc.ChangeRequest{Cmd: svc.Stop}
+	if s := <-status; s.State != svc.StopPending {
+		t.Fatalf("stop status %v", s.State)
+	}
+	select {
+	case r := <-done:
+		if r.ssec || r.code != 0 {
+			t.Fatalf("exit code %v %d", r.ssec, r.code)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Execute did not return after Stop")
+	}
+	select {
+	case <-stopped:
+	default:
+		t.Fatal("server context not cancelled")
+	}
+}
+
+func TestGkService_RunEnds(t *testing.T) {
+	g := &gkService{run: func(context.Context) {}}
+	status := make(chan svc.Status, 4)
+	if ssec, code := g.Execute(nil, make(chan svc.ChangeRequest), status); ssec || code != 0 {
+		t.Fatalf("exit code %v %d", ssec, code)
+	}
+}
+
+// events records what eventlogCore writes, by event type.
+type events map[string][]string
+
+func (e events) Error(_ uint32, msg string) error { e["error"] = append(e["error"], msg); return nil }
+func (e events) Warning(_ uint32, msg string) error {
+	e["warning"] = append(e["warning"], msg)
+	return nil
+}
+func (e events) Info(_ uint32, msg string) error { e["info"] = append(e["info"], msg); return nil }
+
+func TestEventlogCore_Levels(t *testing.T) {
+	got := events{}
+	core := &eventlogCore{
+		LevelEnabler: zapcore.InfoLevel,
+		enc:          zapcore.NewConsoleEncoder(zap.NewProductionEncoderConfig()),
+		log:          got,
+	}
+	logger := zap.New(core).With(zap.String("addr", ":8443"))
+	logger.Debug("not written")
+	logger.Info("listening")
+	logger.Warn("slow query")
+	logger.Error("db down")
+
+	if len(got["info"]) != 1 || len(got["warning"]) != 1 || len(got["error"]) != 1 {
+		t.Fatalf("events: %v", got)
+	}
+	for _, msgs := range got {
+		for _, m := range msgs {
+			if !strings.Contains(m, `"addr": ":8443"`) {
+				t.Fatalf("fields dropped: %q", m)
+			}
+		}
+	}
+}
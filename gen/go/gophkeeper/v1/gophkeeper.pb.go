@@ -22,15 +22,119 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// Ceiling a client declares for an item's ciphertext length.
+type SizeClass int32
+
+const (
+	// No declaration: only the server's overall 1 MiB limit applies.
+	SizeClass_SIZE_CLASS_UNSPECIFIED SizeClass = 0
+	// Records such as logins, cards and short notes: at most 64 KiB.
+	SizeClass_SIZE_CLASS_SMALL SizeClass = 1
+	// Files and long texts: at most 1 MiB.
+	SizeClass_SIZE_CLASS_LARGE SizeClass = 2
+)
+
+// Enum value maps for SizeClass.
+var (
+	SizeClass_name = map[int32]string{
+		0: "SIZE_CLASS_UNSPECIFIED",
+		1: "SIZE_CLASS_SMALL",
+		2: "SIZE_CLASS_LARGE",
+	}
+	SizeClass_value = map[string]int32{
+		"SIZE_CLASS_UNSPECIFIED": 0,
+		"SIZE_CLASS_SMALL":       1,
+		"SIZE_CLASS_LARGE":       2,
+	}
+)
+
+func (x SizeClass) Enum() *SizeClass {
+	p := new(SizeClass)
+	*p = x
+	return p
+}
+
+func (x SizeClass) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SizeClass) Descriptor() protoreflect.EnumDescriptor {
+	return file_gophkeeper_v1_gophkeeper_proto_enumTypes[0].Descriptor()
+}
+
+func (SizeClass) Type() protoreflect.EnumType {
+	return &file_gophkeeper_v1_gophkeeper_proto_enumTypes[0]
+}
+
+func (x SizeClass) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Outcome of one item of a batch.
+type ItemStatus int32
+
+const (
+	ItemStatus_ITEM_STATUS_UNSPECIFIED ItemStatus = 0
+	ItemStatus_ITEM_STATUS_OK          ItemStatus = 1
+	// base_ver is not the item's current version.
+	ItemStatus_ITEM_STATUS_VERSION_CONFLICT ItemStatus = 2
+	ItemStatus_ITEM_STATUS_NOT_FOUND        ItemStatus = 3
+	// The item itself was fine, but another one failed and the batch was
+	// rolled back.
+	ItemStatus_ITEM_STATUS_ABORTED ItemStatus = 4
+)
+
+// Enum value maps for ItemStatus.
+var (
+	ItemStatus_name = map[int32]string{
+		0: "ITEM_STATUS_UNSPECIFIED",
+		1: "ITEM_STATUS_OK",
+		2: "ITEM_STATUS_VERSION_CONFLICT",
+		3: "ITEM_STATUS_NOT_FOUND",
+		4: "ITEM_STATUS_ABORTED",
+	}
+	ItemStatus_value = map[string]int32{
+		"ITEM_STATUS_UNSPECIFIED":      0,
+		"ITEM_STATUS_OK":               1,
+		"ITEM_STATUS_VERSION_CONFLICT": 2,
+		"ITEM_STATUS_NOT_FOUND":        3,
+		"ITEM_STATUS_ABORTED":          4,
+	}
+)
+
+func (x ItemStatus) Enum() *ItemStatus {
+	p := new(ItemStatus)
+	*p = x
+	return p
+}
+
+func (x ItemStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ItemStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_gophkeeper_v1_gophkeeper_proto_enumTypes[1].Descriptor()
+}
+
+func (ItemStatus) Type() protoreflect.EnumType {
+	return &file_gophkeeper_v1_gophkeeper_proto_enumTypes[1]
+}
+
+func (x ItemStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
 // User registration.
 type RegisterRequest struct {
-	state                  protoimpl.MessageState `protogen:"opaque.v1"`
-	xxx_hidden_Username    *string                `protobuf:"bytes,1,opt,name=username"`
-	xxx_hidden_Password    *string                `protobuf:"bytes,2,opt,name=password"`
-	XXX_raceDetectHookData protoimpl.RaceDetectHookData
-	XXX_presence           [1]uint32
-	unknownFields          protoimpl.UnknownFields
-	sizeCache              protoimpl.SizeCache
+	state                        protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Username          *string                `protobuf:"bytes,1,opt,name=username"`
+	xxx_hidden_Password          *string                `protobuf:"bytes,2,opt,name=password"`
+	xxx_hidden_ChallengeResponse *string                `protobuf:"bytes,3,opt,name=challenge_response,json=challengeResponse"`
+	xxx_hidden_ReturnKekSalt     bool                   `protobuf:"varint,4,opt,name=return_kek_salt,json=returnKekSalt"`
+	XXX_raceDetectHookData       protoimpl.RaceDetectHookData
+	XXX_presence                 [1]uint32
+	unknownFields                protoimpl.UnknownFields
+	sizeCache                    protoimpl.SizeCache
 }
 
 func (x *RegisterRequest) Reset() {
@@ -78,14 +182,41 @@ func (x *RegisterRequest) GetPassword() string {
 	return ""
 }
 
+func (x *RegisterRequest) GetChallengeResponse() string {
+	if x != nil {
+		if x.xxx_hidden_ChallengeResponse != nil {
+			return *x.xxx_hidden_ChallengeResponse
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *RegisterRequest) GetReturnKekSalt() bool {
+	if x != nil {
+		return x.xxx_hidden_ReturnKekSalt
+	}
+	return false
+}
+
 func (x *RegisterRequest) SetUsername(v string) {
 	x.xxx_hidden_Username = &v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 2)
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 4)
 }
 
 func (x *RegisterRequest) SetPassword(v string) {
 	x.xxx_hidden_Password = &v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 2)
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 4)
+}
+
+func (x *RegisterRequest) SetChallengeResponse(v string) {
+	x.xxx_hidden_ChallengeResponse = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 4)
+}
+
+func (x *RegisterRequest) SetReturnKekSalt(v bool) {
+	x.xxx_hidden_ReturnKekSalt = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 3, 4)
 }
 
 func (x *RegisterRequest) HasUsername() bool {
@@ -102,6 +233,20 @@ func (x *RegisterRequest) HasPassword() bool {
 	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
 }
 
+func (x *RegisterRequest) HasChallengeResponse() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 2)
+}
+
+func (x *RegisterRequest) HasReturnKekSalt() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 3)
+}
+
 func (x *RegisterRequest) ClearUsername() {
 	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
 	x.xxx_hidden_Username = nil
@@ -112,6 +257,16 @@ func (x *RegisterRequest) ClearPassword() {
 	x.xxx_hidden_Password = nil
 }
 
+func (x *RegisterRequest) ClearChallengeResponse() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 2)
+	x.xxx_hidden_ChallengeResponse = nil
+}
+
+func (x *RegisterRequest) ClearReturnKekSalt() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 3)
+	x.xxx_hidden_ReturnKekSalt = false
+}
+
 type RegisterRequest_builder struct {
 	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
 
@@ -119,6 +274,11 @@ type RegisterRequest_builder struct {
 	Username *string
 	// Plain password sent over TLS; server hashes with Argon2id + per-user salt_auth.
 	Password *string
+	// Answer to the challenge a CHALLENGE_REQUIRED error asked for.
+	ChallengeResponse *string
+	// Return the new user's kek_salt, so the client can derive its KEK and
+	// call SetWrappedDEK right after its first Login.
+	ReturnKekSalt *bool
 }
 
 func (b0 RegisterRequest_builder) Build() *RegisterRequest {
@@ -126,19 +286,29 @@ func (b0 RegisterRequest_builder) Build() *RegisterRequest {
 	b, x := &b0, m0
 	_, _ = b, x
 	if b.Username != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 2)
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 4)
 		x.xxx_hidden_Username = b.Username
 	}
 	if b.Password != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 2)
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 4)
 		x.xxx_hidden_Password = b.Password
 	}
+	if b.ChallengeResponse != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 4)
+		x.xxx_hidden_ChallengeResponse = b.ChallengeResponse
+	}
+	if b.ReturnKekSalt != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 3, 4)
+		x.xxx_hidden_ReturnKekSalt = *b.ReturnKekSalt
+	}
 	return m0
 }
 
+// A taken username fails with ALREADY_EXISTS.
 type RegisterResponse struct {
 	state                  protoimpl.MessageState `protogen:"opaque.v1"`
 	xxx_hidden_UserId      *string                `protobuf:"bytes,1,opt,name=user_id,json=userId"`
+	xxx_hidden_KekSalt     []byte                 `protobuf:"bytes,2,opt,name=kek_salt,json=kekSalt"`
 	XXX_raceDetectHookData protoimpl.RaceDetectHookData
 	XXX_presence           [1]uint32
 	unknownFields          protoimpl.UnknownFields
@@ -180,9 +350,24 @@ func (x *RegisterResponse) GetUserId() string {
 	return ""
 }
 
+func (x *RegisterResponse) GetKekSalt() []byte {
+	if x != nil {
+		return x.xxx_hidden_KekSalt
+	}
+	return nil
+}
+
 func (x *RegisterResponse) SetUserId(v string) {
 	x.xxx_hidden_UserId = &v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 1)
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 2)
+}
+
+func (x *RegisterResponse) SetKekSalt(v []byte) {
+	if v == nil {
+		v = []byte{}
+	}
+	x.xxx_hidden_KekSalt = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 2)
 }
 
 func (x *RegisterResponse) HasUserId() bool {
@@ -192,16 +377,31 @@ func (x *RegisterResponse) HasUserId() bool {
 	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
 }
 
+func (x *RegisterResponse) HasKekSalt() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
 func (x *RegisterResponse) ClearUserId() {
 	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
 	x.xxx_hidden_UserId = nil
 }
 
+func (x *RegisterResponse) ClearKekSalt() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_KekSalt = nil
+}
+
 type RegisterResponse_builder struct {
 	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
 
-	// Empty on success. Consider returning user_id if needed by clients.
+	// Server-side user id (UUID).
 	UserId *string
+	// KDF salt for the KEK (Argon2id), as Login returns it; set only when
+	// return_kek_salt was.
+	KekSalt []byte
 }
 
 func (b0 RegisterResponse_builder) Build() *RegisterResponse {
@@ -209,21 +409,28 @@ func (b0 RegisterResponse_builder) Build() *RegisterResponse {
 	b, x := &b0, m0
 	_, _ = b, x
 	if b.UserId != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 1)
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 2)
 		x.xxx_hidden_UserId = b.UserId
 	}
+	if b.KekSalt != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 2)
+		x.xxx_hidden_KekSalt = b.KekSalt
+	}
 	return m0
 }
 
 // User login / session bootstrap.
 type LoginRequest struct {
-	state                  protoimpl.MessageState `protogen:"opaque.v1"`
-	xxx_hidden_Username    *string                `protobuf:"bytes,1,opt,name=username"`
-	xxx_hidden_Password    *string                `protobuf:"bytes,2,opt,name=password"`
-	XXX_raceDetectHookData protoimpl.RaceDetectHookData
-	XXX_presence           [1]uint32
-	unknownFields          protoimpl.UnknownFields
-	sizeCache              protoimpl.SizeCache
+	state                        protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Username          *string                `protobuf:"bytes,1,opt,name=username"`
+	xxx_hidden_Password          *string                `protobuf:"bytes,2,opt,name=password"`
+	xxx_hidden_ChallengeResponse *string                `protobuf:"bytes,3,opt,name=challenge_response,json=challengeResponse"`
+	xxx_hidden_DeviceLabel       *string                `protobuf:"bytes,4,opt,name=device_label,json=deviceLabel"`
+	xxx_hidden_Scopes            []string               `protobuf:"bytes,5,rep,name=scopes"`
+	XXX_raceDetectHookData       protoimpl.RaceDetectHookData
+	XXX_presence                 [1]uint32
+	unknownFields                protoimpl.UnknownFields
+	sizeCache                    protoimpl.SizeCache
 }
 
 func (x *LoginRequest) Reset() {
@@ -271,14 +478,55 @@ func (x *LoginRequest) GetPassword() string {
 	return ""
 }
 
+func (x *LoginRequest) GetChallengeResponse() string {
+	if x != nil {
+		if x.xxx_hidden_ChallengeResponse != nil {
+			return *x.xxx_hidden_ChallengeResponse
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *LoginRequest) GetDeviceLabel() string {
+	if x != nil {
+		if x.xxx_hidden_DeviceLabel != nil {
+			return *x.xxx_hidden_DeviceLabel
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *LoginRequest) GetScopes() []string {
+	if x != nil {
+		return x.xxx_hidden_Scopes
+	}
+	return nil
+}
+
 func (x *LoginRequest) SetUsername(v string) {
 	x.xxx_hidden_Username = &v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 2)
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 5)
 }
 
 func (x *LoginRequest) SetPassword(v string) {
 	x.xxx_hidden_Password = &v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 2)
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 5)
+}
+
+func (x *LoginRequest) SetChallengeResponse(v string) {
+	x.xxx_hidden_ChallengeResponse = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 5)
+}
+
+func (x *LoginRequest) SetDeviceLabel(v string) {
+	x.xxx_hidden_DeviceLabel = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 3, 5)
+}
+
+func (x *LoginRequest) SetScopes(v []string) {
+	x.xxx_hidden_Scopes = v
 }
 
 func (x *LoginRequest) HasUsername() bool {
@@ -295,6 +543,20 @@ func (x *LoginRequest) HasPassword() bool {
 	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
 }
 
+func (x *LoginRequest) HasChallengeResponse() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 2)
+}
+
+func (x *LoginRequest) HasDeviceLabel() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 3)
+}
+
 func (x *LoginRequest) ClearUsername() {
 	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
 	x.xxx_hidden_Username = nil
@@ -305,11 +567,31 @@ func (x *LoginRequest) ClearPassword() {
 	x.xxx_hidden_Password = nil
 }
 
+func (x *LoginRequest) ClearChallengeResponse() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 2)
+	x.xxx_hidden_ChallengeResponse = nil
+}
+
+func (x *LoginRequest) ClearDeviceLabel() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 3)
+	x.xxx_hidden_DeviceLabel = nil
+}
+
 type LoginRequest_builder struct {
 	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
 
 	Username *string
 	Password *string
+	// Answer to the challenge a CHALLENGE_REQUIRED error asked for.
+	ChallengeResponse *string
+	// Name of this device in ListSessions, e.g. "work laptop"; at most 100
+	// characters.
+	DeviceLabel *string
+	// Limit the tokens to these scopes: "items:read" (the change feed,
+	// items, stats), "items:write" (upserts and deletes) and "account:manage"
+	// (DEK, sessions, webhooks, devices, admin calls). Empty for all of them.
+	// Refreshed tokens keep the same scopes.
+	Scopes []string
 }
 
 func (b0 LoginRequest_builder) Build() *LoginRequest {
@@ -317,27 +599,39 @@ func (b0 LoginRequest_builder) Build() *LoginRequest {
 	b, x := &b0, m0
 	_, _ = b, x
 	if b.Username != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 2)
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 5)
 		x.xxx_hidden_Username = b.Username
 	}
 	if b.Password != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 2)
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 5)
 		x.xxx_hidden_Password = b.Password
 	}
+	if b.ChallengeResponse != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 5)
+		x.xxx_hidden_ChallengeResponse = b.ChallengeResponse
+	}
+	if b.DeviceLabel != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 3, 5)
+		x.xxx_hidden_DeviceLabel = b.DeviceLabel
+	}
+	x.xxx_hidden_Scopes = b.Scopes
 	return m0
 }
 
 type LoginResponse struct {
-	state                   protoimpl.MessageState `protogen:"opaque.v1"`
-	xxx_hidden_AccessToken  *string                `protobuf:"bytes,1,opt,name=access_token,json=accessToken"`
-	xxx_hidden_RefreshToken *string                `protobuf:"bytes,2,opt,name=refresh_token,json=refreshToken"`
-	xxx_hidden_KekSalt      []byte                 `protobuf:"bytes,3,opt,name=kek_salt,json=kekSalt"`
-	xxx_hidden_WrappedDek   []byte                 `protobuf:"bytes,4,opt,name=wrapped_dek,json=wrappedDek"`
-	xxx_hidden_UserId       *string                `protobuf:"bytes,5,opt,name=user_id,json=userId"`
-	XXX_raceDetectHookData  protoimpl.RaceDetectHookData
-	XXX_presence            [1]uint32
-	unknownFields           protoimpl.UnknownFields
-	sizeCache               protoimpl.SizeCache
+	state                     protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_AccessToken    *string                `protobuf:"bytes,1,opt,name=access_token,json=accessToken"`
+	xxx_hidden_RefreshToken   *string                `protobuf:"bytes,2,opt,name=refresh_token,json=refreshToken"`
+	xxx_hidden_KekSalt        []byte                 `protobuf:"bytes,3,opt,name=kek_salt,json=kekSalt"`
+	xxx_hidden_WrappedDek     []byte                 `protobuf:"bytes,4,opt,name=wrapped_dek,json=wrappedDek"`
+	xxx_hidden_UserId         *string                `protobuf:"bytes,5,opt,name=user_id,json=userId"`
+	xxx_hidden_FailedAttempts int32                  `protobuf:"varint,6,opt,name=failed_attempts,json=failedAttempts"`
+	xxx_hidden_Anomalies      []string               `protobuf:"bytes,7,rep,name=anomalies"`
+	xxx_hidden_Scopes         []string               `protobuf:"bytes,8,rep,name=scopes"`
+	XXX_raceDetectHookData    protoimpl.RaceDetectHookData
+	XXX_presence              [1]uint32
+	unknownFields             protoimpl.UnknownFields
+	sizeCache                 protoimpl.SizeCache
 }
 
 func (x *LoginResponse) Reset() {
@@ -409,14 +703,35 @@ func (x *LoginResponse) GetUserId() string {
 	return ""
 }
 
+func (x *LoginResponse) GetFailedAttempts() int32 {
+	if x != nil {
+		return x.xxx_hidden_FailedAttempts
+	}
+	return 0
+}
+
+func (x *LoginResponse) GetAnomalies() []string {
+	if x != nil {
+		return x.xxx_hidden_Anomalies
+	}
+	return nil
+}
+
+func (x *LoginResponse) GetScopes() []string {
+	if x != nil {
+		return x.xxx_hidden_Scopes
+	}
+	return nil
+}
+
 func (x *LoginResponse) SetAccessToken(v string) {
 	x.xxx_hidden_AccessToken = &v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 5)
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 8)
 }
 
 func (x *LoginResponse) SetRefreshToken(v string) {
 	x.xxx_hidden_RefreshToken = &v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 5)
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 8)
 }
 
 func (x *LoginResponse) SetKekSalt(v []byte) {
@@ -424,7 +739,7 @@ func (x *LoginResponse) SetKekSalt(v []byte) {
 		v = []byte{}
 	}
 	x.xxx_hidden_KekSalt = v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 5)
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 8)
 }
 
 func (x *LoginResponse) SetWrappedDek(v []byte) {
@@ -432,12 +747,25 @@ func (x *LoginResponse) SetWrappedDek(v []byte) {
 		v = []byte{}
 	}
 	x.xxx_hidden_WrappedDek = v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 3, 5)
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 3, 8)
 }
 
 func (x *LoginResponse) SetUserId(v string) {
 	x.xxx_hidden_UserId = &v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 4, 5)
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 4, 8)
+}
+
+func (x *LoginResponse) SetFailedAttempts(v int32) {
+	x.xxx_hidden_FailedAttempts = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 5, 8)
+}
+
+func (x *LoginResponse) SetAnomalies(v []string) {
+	x.xxx_hidden_Anomalies = v
+}
+
+func (x *LoginResponse) SetScopes(v []string) {
+	x.xxx_hidden_Scopes = v
 }
 
 func (x *LoginResponse) HasAccessToken() bool {
@@ -475,6 +803,13 @@ func (x *LoginResponse) HasUserId() bool {
 	return protoimpl.X.Present(&(x.XXX_presence[0]), 4)
 }
 
+func (x *LoginResponse) HasFailedAttempts() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 5)
+}
+
 func (x *LoginResponse) ClearAccessToken() {
 	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
 	x.xxx_hidden_AccessToken = nil
@@ -500,12 +835,17 @@ func (x *LoginResponse) ClearUserId() {
 	x.xxx_hidden_UserId = nil
 }
 
+func (x *LoginResponse) ClearFailedAttempts() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 5)
+	x.xxx_hidden_FailedAttempts = 0
+}
+
 type LoginResponse_builder struct {
 	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
 
 	// Short-lived token to authorize API calls (metadata "authorization: Bearer ...").
 	AccessToken *string
-	// Optional refresh token if you implement refresh flows.
+	// Token for Refresh; empty when the server has sessions turned off.
 	RefreshToken *string
 	// KDF salt used to derive KEK on client (Argon2id).
 	KekSalt []byte
@@ -513,6 +853,14 @@ type LoginResponse_builder struct {
 	WrappedDek []byte
 	// Server-side user id (UUID).
 	UserId *string
+	// Wrong passwords given for this account, from any address, since its
+	// previous successful login. Clients should warn the user when it is not 0.
+	FailedAttempts *int32
+	// Why this login looks unusual, when the server has a GeoIP database:
+	// "new_country" and/or "impossible_travel". Clients should tell the user.
+	Anomalies []string
+	// Scopes the tokens are limited to; empty when they are not.
+	Scopes []string
 }
 
 func (b0 LoginResponse_builder) Build() *LoginResponse {
@@ -520,25 +868,31 @@ func (b0 LoginResponse_builder) Build() *LoginResponse {
 	b, x := &b0, m0
 	_, _ = b, x
 	if b.AccessToken != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 5)
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 8)
 		x.xxx_hidden_AccessToken = b.AccessToken
 	}
 	if b.RefreshToken != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 5)
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 8)
 		x.xxx_hidden_RefreshToken = b.RefreshToken
 	}
 	if b.KekSalt != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 5)
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 8)
 		x.xxx_hidden_KekSalt = b.KekSalt
 	}
 	if b.WrappedDek != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 3, 5)
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 3, 8)
 		x.xxx_hidden_WrappedDek = b.WrappedDek
 	}
 	if b.UserId != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 4, 5)
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 4, 8)
 		x.xxx_hidden_UserId = b.UserId
 	}
+	if b.FailedAttempts != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 5, 8)
+		x.xxx_hidden_FailedAttempts = *b.FailedAttempts
+	}
+	x.xxx_hidden_Anomalies = b.Anomalies
+	x.xxx_hidden_Scopes = b.Scopes
 	return m0
 }
 
@@ -621,32 +975,33 @@ func (b0 EncryptedBlob_builder) Build() *EncryptedBlob {
 	return m0
 }
 
-// Upsert payload for optimistic concurrency.
-type UpsertItem struct {
-	state                  protoimpl.MessageState `protogen:"opaque.v1"`
-	xxx_hidden_Id          *string                `protobuf:"bytes,1,opt,name=id"`
-	xxx_hidden_BaseVer     int64                  `protobuf:"varint,2,opt,name=base_ver,json=baseVer"`
-	xxx_hidden_BlobEnc     *EncryptedBlob         `protobuf:"bytes,3,opt,name=blob_enc,json=blobEnc"`
-	XXX_raceDetectHookData protoimpl.RaceDetectHookData
-	XXX_presence           [1]uint32
-	unknownFields          protoimpl.UnknownFields
-	sizeCache              protoimpl.SizeCache
+// Hints a client attaches to an item. They say nothing about the plaintext
+// beyond what the ciphertext's length already does, and the server stores
+// them next to the blob and returns them with it.
+type ItemHints struct {
+	state                    protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_SizeClass     SizeClass              `protobuf:"varint,1,opt,name=size_class,json=sizeClass,enum=gophkeeper.v1.SizeClass"`
+	xxx_hidden_SchemaVersion int32                  `protobuf:"varint,2,opt,name=schema_version,json=schemaVersion"`
+	XXX_raceDetectHookData   protoimpl.RaceDetectHookData
+	XXX_presence             [1]uint32
+	unknownFields            protoimpl.UnknownFields
+	sizeCache                protoimpl.SizeCache
 }
 
-func (x *UpsertItem) Reset() {
-	*x = UpsertItem{}
+func (x *ItemHints) Reset() {
+	*x = ItemHints{}
 	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UpsertItem) String() string {
+func (x *ItemHints) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UpsertItem) ProtoMessage() {}
+func (*ItemHints) ProtoMessage() {}
 
-func (x *UpsertItem) ProtoReflect() protoreflect.Message {
+func (x *ItemHints) ProtoReflect() protoreflect.Message {
 	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -658,44 +1013,168 @@ func (x *UpsertItem) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-func (x *UpsertItem) GetId() string {
+func (x *ItemHints) GetSizeClass() SizeClass {
 	if x != nil {
-		if x.xxx_hidden_Id != nil {
-			return *x.xxx_hidden_Id
+		if protoimpl.X.Present(&(x.XXX_presence[0]), 0) {
+			return x.xxx_hidden_SizeClass
 		}
-		return ""
 	}
-	return ""
+	return SizeClass_SIZE_CLASS_UNSPECIFIED
 }
 
-func (x *UpsertItem) GetBaseVer() int64 {
+func (x *ItemHints) GetSchemaVersion() int32 {
 	if x != nil {
-		return x.xxx_hidden_BaseVer
+		return x.xxx_hidden_SchemaVersion
 	}
 	return 0
 }
 
-func (x *UpsertItem) GetBlobEnc() *EncryptedBlob {
-	if x != nil {
-		return x.xxx_hidden_BlobEnc
-	}
-	return nil
+func (x *ItemHints) SetSizeClass(v SizeClass) {
+	x.xxx_hidden_SizeClass = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 2)
 }
 
-func (x *UpsertItem) SetId(v string) {
-	x.xxx_hidden_Id = &v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 3)
+func (x *ItemHints) SetSchemaVersion(v int32) {
+	x.xxx_hidden_SchemaVersion = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 2)
 }
 
-func (x *UpsertItem) SetBaseVer(v int64) {
-	x.xxx_hidden_BaseVer = v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 3)
+func (x *ItemHints) HasSizeClass() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *ItemHints) HasSchemaVersion() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *ItemHints) ClearSizeClass() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_SizeClass = SizeClass_SIZE_CLASS_UNSPECIFIED
+}
+
+func (x *ItemHints) ClearSchemaVersion() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_SchemaVersion = 0
+}
+
+type ItemHints_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	// The server rejects blobs longer than the class allows.
+	SizeClass *SizeClass
+	// Version of the client's payload format; 0 when unknown. Clients that
+	// cannot parse a version may skip its blobs (see max_schema_version).
+	SchemaVersion *int32
+}
+
+func (b0 ItemHints_builder) Build() *ItemHints {
+	m0 := &ItemHints{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.SizeClass != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 2)
+		x.xxx_hidden_SizeClass = *b.SizeClass
+	}
+	if b.SchemaVersion != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 2)
+		x.xxx_hidden_SchemaVersion = *b.SchemaVersion
+	}
+	return m0
+}
+
+// Upsert payload for optimistic concurrency.
+type UpsertItem struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Id          *string                `protobuf:"bytes,1,opt,name=id"`
+	xxx_hidden_BaseVer     int64                  `protobuf:"varint,2,opt,name=base_ver,json=baseVer"`
+	xxx_hidden_BlobEnc     *EncryptedBlob         `protobuf:"bytes,3,opt,name=blob_enc,json=blobEnc"`
+	xxx_hidden_Hints       *ItemHints             `protobuf:"bytes,4,opt,name=hints"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *UpsertItem) Reset() {
+	*x = UpsertItem{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpsertItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpsertItem) ProtoMessage() {}
+
+func (x *UpsertItem) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *UpsertItem) GetId() string {
+	if x != nil {
+		if x.xxx_hidden_Id != nil {
+			return *x.xxx_hidden_Id
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *UpsertItem) GetBaseVer() int64 {
+	if x != nil {
+		return x.xxx_hidden_BaseVer
+	}
+	return 0
+}
+
+func (x *UpsertItem) GetBlobEnc() *EncryptedBlob {
+	if x != nil {
+		return x.xxx_hidden_BlobEnc
+	}
+	return nil
+}
+
+func (x *UpsertItem) GetHints() *ItemHints {
+	if x != nil {
+		return x.xxx_hidden_Hints
+	}
+	return nil
+}
+
+func (x *UpsertItem) SetId(v string) {
+	x.xxx_hidden_Id = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 4)
+}
+
+func (x *UpsertItem) SetBaseVer(v int64) {
+	x.xxx_hidden_BaseVer = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 4)
 }
 
 func (x *UpsertItem) SetBlobEnc(v *EncryptedBlob) {
 	x.xxx_hidden_BlobEnc = v
 }
 
+func (x *UpsertItem) SetHints(v *ItemHints) {
+	x.xxx_hidden_Hints = v
+}
+
 func (x *UpsertItem) HasId() bool {
 	if x == nil {
 		return false
@@ -717,6 +1196,13 @@ func (x *UpsertItem) HasBlobEnc() bool {
 	return x.xxx_hidden_BlobEnc != nil
 }
 
+func (x *UpsertItem) HasHints() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_Hints != nil
+}
+
 func (x *UpsertItem) ClearId() {
 	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
 	x.xxx_hidden_Id = nil
@@ -731,6 +1217,10 @@ func (x *UpsertItem) ClearBlobEnc() {
 	x.xxx_hidden_BlobEnc = nil
 }
 
+func (x *UpsertItem) ClearHints() {
+	x.xxx_hidden_Hints = nil
+}
+
 type UpsertItem_builder struct {
 	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
 
@@ -740,6 +1230,8 @@ type UpsertItem_builder struct {
 	BaseVer *int64
 	// New encrypted blob to store.
 	BlobEnc *EncryptedBlob
+	// Optional; they replace the item's previous hints.
+	Hints *ItemHints
 }
 
 func (b0 UpsertItem_builder) Build() *UpsertItem {
@@ -747,14 +1239,15 @@ func (b0 UpsertItem_builder) Build() *UpsertItem {
 	b, x := &b0, m0
 	_, _ = b, x
 	if b.Id != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 3)
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 4)
 		x.xxx_hidden_Id = b.Id
 	}
 	if b.BaseVer != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 3)
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 4)
 		x.xxx_hidden_BaseVer = *b.BaseVer
 	}
 	x.xxx_hidden_BlobEnc = b.BlobEnc
+	x.xxx_hidden_Hints = b.Hints
 	return m0
 }
 
@@ -772,7 +1265,7 @@ type ItemVersion struct {
 
 func (x *ItemVersion) Reset() {
 	*x = ItemVersion{}
-	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[6]
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -784,7 +1277,7 @@ func (x *ItemVersion) String() string {
 func (*ItemVersion) ProtoMessage() {}
 
 func (x *ItemVersion) ProtoReflect() protoreflect.Message {
-	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[6]
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -900,6 +1393,8 @@ type Change struct {
 	xxx_hidden_Deleted     bool                   `protobuf:"varint,3,opt,name=deleted"`
 	xxx_hidden_UpdatedAt   *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=updated_at,json=updatedAt"`
 	xxx_hidden_BlobEnc     *EncryptedBlob         `protobuf:"bytes,5,opt,name=blob_enc,json=blobEnc"`
+	xxx_hidden_Hints       *ItemHints             `protobuf:"bytes,6,opt,name=hints"`
+	xxx_hidden_Seq         int64                  `protobuf:"varint,7,opt,name=seq"`
 	XXX_raceDetectHookData protoimpl.RaceDetectHookData
 	XXX_presence           [1]uint32
 	unknownFields          protoimpl.UnknownFields
@@ -908,7 +1403,7 @@ type Change struct {
 
 func (x *Change) Reset() {
 	*x = Change{}
-	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[7]
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -920,7 +1415,7 @@ func (x *Change) String() string {
 func (*Change) ProtoMessage() {}
 
 func (x *Change) ProtoReflect() protoreflect.Message {
-	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[7]
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -969,19 +1464,33 @@ func (x *Change) GetBlobEnc() *EncryptedBlob {
 	return nil
 }
 
+func (x *Change) GetHints() *ItemHints {
+	if x != nil {
+		return x.xxx_hidden_Hints
+	}
+	return nil
+}
+
+func (x *Change) GetSeq() int64 {
+	if x != nil {
+		return x.xxx_hidden_Seq
+	}
+	return 0
+}
+
 func (x *Change) SetId(v string) {
 	x.xxx_hidden_Id = &v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 5)
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 7)
 }
 
 func (x *Change) SetVer(v int64) {
 	x.xxx_hidden_Ver = v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 5)
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 7)
 }
 
 func (x *Change) SetDeleted(v bool) {
 	x.xxx_hidden_Deleted = v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 5)
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 7)
 }
 
 func (x *Change) SetUpdatedAt(v *timestamppb.Timestamp) {
@@ -992,6 +1501,15 @@ func (x *Change) SetBlobEnc(v *EncryptedBlob) {
 	x.xxx_hidden_BlobEnc = v
 }
 
+func (x *Change) SetHints(v *ItemHints) {
+	x.xxx_hidden_Hints = v
+}
+
+func (x *Change) SetSeq(v int64) {
+	x.xxx_hidden_Seq = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 6, 7)
+}
+
 func (x *Change) HasId() bool {
 	if x == nil {
 		return false
@@ -1027,6 +1545,20 @@ func (x *Change) HasBlobEnc() bool {
 	return x.xxx_hidden_BlobEnc != nil
 }
 
+func (x *Change) HasHints() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_Hints != nil
+}
+
+func (x *Change) HasSeq() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 6)
+}
+
 func (x *Change) ClearId() {
 	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
 	x.xxx_hidden_Id = nil
@@ -1050,6 +1582,15 @@ func (x *Change) ClearBlobEnc() {
 	x.xxx_hidden_BlobEnc = nil
 }
 
+func (x *Change) ClearHints() {
+	x.xxx_hidden_Hints = nil
+}
+
+func (x *Change) ClearSeq() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 6)
+	x.xxx_hidden_Seq = 0
+}
+
 type Change_builder struct {
 	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
 
@@ -1058,7 +1599,14 @@ type Change_builder struct {
 	Deleted   *bool
 	UpdatedAt *timestamppb.Timestamp
 	// Present only if not deleted (server can always send it; client ignores when deleted=true).
+	// Also left out when the item's schema_version is above the request's
+	// max_schema_version.
 	BlobEnc *EncryptedBlob
+	// As stored with the blob.
+	Hints *ItemHints
+	// Position of the change in the change journal; set on journal reads
+	// (since_seq) only.
+	Seq *int64
 }
 
 func (b0 Change_builder) Build() *Change {
@@ -1066,32 +1614,40 @@ func (b0 Change_builder) Build() *Change {
 	b, x := &b0, m0
 	_, _ = b, x
 	if b.Id != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 5)
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 7)
 		x.xxx_hidden_Id = b.Id
 	}
 	if b.Ver != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 5)
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 7)
 		x.xxx_hidden_Ver = *b.Ver
 	}
 	if b.Deleted != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 5)
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 7)
 		x.xxx_hidden_Deleted = *b.Deleted
 	}
 	x.xxx_hidden_UpdatedAt = b.UpdatedAt
 	x.xxx_hidden_BlobEnc = b.BlobEnc
+	x.xxx_hidden_Hints = b.Hints
+	if b.Seq != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 6, 7)
+		x.xxx_hidden_Seq = *b.Seq
+	}
 	return m0
 }
 
 type UpsertItemsRequest struct {
-	state            protoimpl.MessageState `protogen:"opaque.v1"`
-	xxx_hidden_Items *[]*UpsertItem         `protobuf:"bytes,1,rep,name=items"`
-	unknownFields    protoimpl.UnknownFields
-	sizeCache        protoimpl.SizeCache
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Items       *[]*UpsertItem         `protobuf:"bytes,1,rep,name=items"`
+	xxx_hidden_BestEffort  bool                   `protobuf:"varint,2,opt,name=best_effort,json=bestEffort"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
 }
 
 func (x *UpsertItemsRequest) Reset() {
 	*x = UpsertItemsRequest{}
-	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[8]
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1103,7 +1659,7 @@ func (x *UpsertItemsRequest) String() string {
 func (*UpsertItemsRequest) ProtoMessage() {}
 
 func (x *UpsertItemsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[8]
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1123,14 +1679,43 @@ func (x *UpsertItemsRequest) GetItems() []*UpsertItem {
 	return nil
 }
 
+func (x *UpsertItemsRequest) GetBestEffort() bool {
+	if x != nil {
+		return x.xxx_hidden_BestEffort
+	}
+	return false
+}
+
 func (x *UpsertItemsRequest) SetItems(v []*UpsertItem) {
 	x.xxx_hidden_Items = &v
 }
 
+func (x *UpsertItemsRequest) SetBestEffort(v bool) {
+	x.xxx_hidden_BestEffort = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 2)
+}
+
+func (x *UpsertItemsRequest) HasBestEffort() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *UpsertItemsRequest) ClearBestEffort() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_BestEffort = false
+}
+
 type UpsertItemsRequest_builder struct {
 	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
 
 	Items []*UpsertItem
+	// Write each item on its own instead of all or none: a stale base_ver
+	// fails only its item, and item_results says which were written.
+	// Malformed items, the item cap and the storage quota still fail the
+	// whole call.
+	BestEffort *bool
 }
 
 func (b0 UpsertItemsRequest_builder) Build() *UpsertItemsRequest {
@@ -1138,19 +1723,25 @@ func (b0 UpsertItemsRequest_builder) Build() *UpsertItemsRequest {
 	b, x := &b0, m0
 	_, _ = b, x
 	x.xxx_hidden_Items = &b.Items
+	if b.BestEffort != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 2)
+		x.xxx_hidden_BestEffort = *b.BestEffort
+	}
 	return m0
 }
 
 type UpsertItemsResponse struct {
-	state              protoimpl.MessageState `protogen:"opaque.v1"`
-	xxx_hidden_Results *[]*ItemVersion        `protobuf:"bytes,1,rep,name=results"`
-	unknownFields      protoimpl.UnknownFields
-	sizeCache          protoimpl.SizeCache
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Results     *[]*ItemVersion        `protobuf:"bytes,1,rep,name=results"`
+	xxx_hidden_ItemResults *[]*ItemResult         `protobuf:"bytes,2,rep,name=item_results,json=itemResults"`
+	xxx_hidden_Warnings    *[]*Warning            `protobuf:"bytes,3,rep,name=warnings"`
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
 }
 
 func (x *UpsertItemsResponse) Reset() {
 	*x = UpsertItemsResponse{}
-	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[9]
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1162,7 +1753,7 @@ func (x *UpsertItemsResponse) String() string {
 func (*UpsertItemsResponse) ProtoMessage() {}
 
 func (x *UpsertItemsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[9]
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1182,14 +1773,48 @@ func (x *UpsertItemsResponse) GetResults() []*ItemVersion {
 	return nil
 }
 
+func (x *UpsertItemsResponse) GetItemResults() []*ItemResult {
+	if x != nil {
+		if x.xxx_hidden_ItemResults != nil {
+			return *x.xxx_hidden_ItemResults
+		}
+	}
+	return nil
+}
+
+func (x *UpsertItemsResponse) GetWarnings() []*Warning {
+	if x != nil {
+		if x.xxx_hidden_Warnings != nil {
+			return *x.xxx_hidden_Warnings
+		}
+	}
+	return nil
+}
+
 func (x *UpsertItemsResponse) SetResults(v []*ItemVersion) {
 	x.xxx_hidden_Results = &v
 }
 
+func (x *UpsertItemsResponse) SetItemResults(v []*ItemResult) {
+	x.xxx_hidden_ItemResults = &v
+}
+
+func (x *UpsertItemsResponse) SetWarnings(v []*Warning) {
+	x.xxx_hidden_Warnings = &v
+}
+
 type UpsertItemsResponse_builder struct {
 	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
 
+	// The items written, in request order.
 	Results []*ItemVersion
+	// best_effort only: one per requested item, in request order, OK or
+	// VERSION_CONFLICT.
+	ItemResults []*ItemResult
+	// Advisory notes about a call that succeeded: the item cap is nearly
+	// reached, an item is unusually large, the request used something
+	// deprecated. Clients show them to the user and carry on.
+	Warnings []*Warning
 }
 
 func (b0 UpsertItemsResponse_builder) Build() *UpsertItemsResponse {
@@ -1197,34 +1822,38 @@ func (b0 UpsertItemsResponse_builder) Build() *UpsertItemsResponse {
 	b, x := &b0, m0
 	_, _ = b, x
 	x.xxx_hidden_Results = &b.Results
+	x.xxx_hidden_ItemResults = &b.ItemResults
+	x.xxx_hidden_Warnings = &b.Warnings
 	return m0
 }
 
-// Get all changes since a given version (LWW conflict policy on server).
-type GetChangesRequest struct {
+// Warning is an advisory message; it never means the call failed.
+type Warning struct {
 	state                  protoimpl.MessageState `protogen:"opaque.v1"`
-	xxx_hidden_SinceVer    int64                  `protobuf:"varint,1,opt,name=since_ver,json=sinceVer"`
+	xxx_hidden_Code        *string                `protobuf:"bytes,1,opt,name=code"`
+	xxx_hidden_Message     *string                `protobuf:"bytes,2,opt,name=message"`
+	xxx_hidden_ItemId      *string                `protobuf:"bytes,3,opt,name=item_id,json=itemId"`
 	XXX_raceDetectHookData protoimpl.RaceDetectHookData
 	XXX_presence           [1]uint32
 	unknownFields          protoimpl.UnknownFields
 	sizeCache              protoimpl.SizeCache
 }
 
-func (x *GetChangesRequest) Reset() {
-	*x = GetChangesRequest{}
-	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[10]
+func (x *Warning) Reset() {
+	*x = Warning{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetChangesRequest) String() string {
+func (x *Warning) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetChangesRequest) ProtoMessage() {}
+func (*Warning) ProtoMessage() {}
 
-func (x *GetChangesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[10]
+func (x *Warning) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1235,35 +1864,337 @@ func (x *GetChangesRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-func (x *GetChangesRequest) GetSinceVer() int64 {
+func (x *Warning) GetCode() string {
 	if x != nil {
-		return x.xxx_hidden_SinceVer
+		if x.xxx_hidden_Code != nil {
+			return *x.xxx_hidden_Code
+		}
+		return ""
 	}
-	return 0
+	return ""
 }
 
-func (x *GetChangesRequest) SetSinceVer(v int64) {
-	x.xxx_hidden_SinceVer = v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 1)
+func (x *Warning) GetMessage() string {
+	if x != nil {
+		if x.xxx_hidden_Message != nil {
+			return *x.xxx_hidden_Message
+		}
+		return ""
+	}
+	return ""
 }
 
-func (x *GetChangesRequest) HasSinceVer() bool {
-	if x == nil {
-		return false
+func (x *Warning) GetItemId() string {
+	if x != nil {
+		if x.xxx_hidden_ItemId != nil {
+			return *x.xxx_hidden_ItemId
+		}
+		return ""
 	}
-	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+	return ""
 }
 
-func (x *GetChangesRequest) ClearSinceVer() {
-	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
-	x.xxx_hidden_SinceVer = 0
+func (x *Warning) SetCode(v string) {
+	x.xxx_hidden_Code = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 3)
 }
 
-type GetChangesRequest_builder struct {
-	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+func (x *Warning) SetMessage(v string) {
+	x.xxx_hidden_Message = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 3)
+}
+
+func (x *Warning) SetItemId(v string) {
+	x.xxx_hidden_ItemId = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 3)
+}
+
+func (x *Warning) HasCode() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *Warning) HasMessage() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *Warning) HasItemId() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 2)
+}
+
+func (x *Warning) ClearCode() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_Code = nil
+}
+
+func (x *Warning) ClearMessage() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_Message = nil
+}
+
+func (x *Warning) ClearItemId() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 2)
+	x.xxx_hidden_ItemId = nil
+}
+
+type Warning_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	// Stable code clients may branch on, e.g. ITEM_LIMIT_NEARLY_REACHED,
+	// ITEM_LARGE, DEPRECATED_ID. New codes may appear at any time.
+	Code *string
+	// Human-readable text in English.
+	Message *string
+	// The item the warning is about, empty if it concerns the whole call.
+	ItemId *string
+}
+
+func (b0 Warning_builder) Build() *Warning {
+	m0 := &Warning{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Code != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 3)
+		x.xxx_hidden_Code = b.Code
+	}
+	if b.Message != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 3)
+		x.xxx_hidden_Message = b.Message
+	}
+	if b.ItemId != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 3)
+		x.xxx_hidden_ItemId = b.ItemId
+	}
+	return m0
+}
+
+// Get all changes since a given version (LWW conflict policy on server).
+type GetChangesRequest struct {
+	state                       protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_SinceVer         int64                  `protobuf:"varint,1,opt,name=since_ver,json=sinceVer"`
+	xxx_hidden_MaxSchemaVersion int32                  `protobuf:"varint,2,opt,name=max_schema_version,json=maxSchemaVersion"`
+	xxx_hidden_PageSize         int32                  `protobuf:"varint,3,opt,name=page_size,json=pageSize"`
+	xxx_hidden_PageToken        *string                `protobuf:"bytes,4,opt,name=page_token,json=pageToken"`
+	xxx_hidden_SinceSeq         int64                  `protobuf:"varint,5,opt,name=since_seq,json=sinceSeq"`
+	xxx_hidden_SyncSession      *string                `protobuf:"bytes,6,opt,name=sync_session,json=syncSession"`
+	XXX_raceDetectHookData      protoimpl.RaceDetectHookData
+	XXX_presence                [1]uint32
+	unknownFields               protoimpl.UnknownFields
+	sizeCache                   protoimpl.SizeCache
+}
+
+func (x *GetChangesRequest) Reset() {
+	*x = GetChangesRequest{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetChangesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetChangesRequest) ProtoMessage() {}
+
+func (x *GetChangesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *GetChangesRequest) GetSinceVer() int64 {
+	if x != nil {
+		return x.xxx_hidden_SinceVer
+	}
+	return 0
+}
+
+func (x *GetChangesRequest) GetMaxSchemaVersion() int32 {
+	if x != nil {
+		return x.xxx_hidden_MaxSchemaVersion
+	}
+	return 0
+}
+
+func (x *GetChangesRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.xxx_hidden_PageSize
+	}
+	return 0
+}
+
+func (x *GetChangesRequest) GetPageToken() string {
+	if x != nil {
+		if x.xxx_hidden_PageToken != nil {
+			return *x.xxx_hidden_PageToken
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *GetChangesRequest) GetSinceSeq() int64 {
+	if x != nil {
+		return x.xxx_hidden_SinceSeq
+	}
+	return 0
+}
+
+func (x *GetChangesRequest) GetSyncSession() string {
+	if x != nil {
+		if x.xxx_hidden_SyncSession != nil {
+			return *x.xxx_hidden_SyncSession
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *GetChangesRequest) SetSinceVer(v int64) {
+	x.xxx_hidden_SinceVer = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 6)
+}
+
+func (x *GetChangesRequest) SetMaxSchemaVersion(v int32) {
+	x.xxx_hidden_MaxSchemaVersion = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 6)
+}
+
+func (x *GetChangesRequest) SetPageSize(v int32) {
+	x.xxx_hidden_PageSize = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 6)
+}
+
+func (x *GetChangesRequest) SetPageToken(v string) {
+	x.xxx_hidden_PageToken = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 3, 6)
+}
+
+func (x *GetChangesRequest) SetSinceSeq(v int64) {
+	x.xxx_hidden_SinceSeq = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 4, 6)
+}
+
+func (x *GetChangesRequest) SetSyncSession(v string) {
+	x.xxx_hidden_SyncSession = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 5, 6)
+}
+
+func (x *GetChangesRequest) HasSinceVer() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *GetChangesRequest) HasMaxSchemaVersion() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *GetChangesRequest) HasPageSize() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 2)
+}
+
+func (x *GetChangesRequest) HasPageToken() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 3)
+}
+
+func (x *GetChangesRequest) HasSinceSeq() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 4)
+}
+
+func (x *GetChangesRequest) HasSyncSession() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 5)
+}
+
+func (x *GetChangesRequest) ClearSinceVer() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_SinceVer = 0
+}
+
+func (x *GetChangesRequest) ClearMaxSchemaVersion() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_MaxSchemaVersion = 0
+}
+
+func (x *GetChangesRequest) ClearPageSize() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 2)
+	x.xxx_hidden_PageSize = 0
+}
+
+func (x *GetChangesRequest) ClearPageToken() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 3)
+	x.xxx_hidden_PageToken = nil
+}
+
+func (x *GetChangesRequest) ClearSinceSeq() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 4)
+	x.xxx_hidden_SinceSeq = 0
+}
+
+func (x *GetChangesRequest) ClearSyncSession() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 5)
+	x.xxx_hidden_SyncSession = nil
+}
+
+type GetChangesRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
 
 	// Inclusive lower bound: return items with ver > since_ver.
 	SinceVer *int64
+	// Newest payload format the client can parse; items written with a later
+	// schema_version come without blob_enc. 0 sends every blob.
+	MaxSchemaVersion *int32
+	// Most changes to return; 0 or anything above the server's limit (1000
+	// by default) means the limit. Changes come ordered by (ver, id).
+	PageSize *int32
+	// next_page_token of the previous page. It carries the position, so
+	// since_ver and since_seq are ignored when it is set.
+	PageToken *string
+	// Read the change journal after this position instead of items by
+	// version; 0 starts from the beginning. The journal lists changes in the
+	// order they were committed, so an edit is never missed because its
+	// item's version is below the cursor. Each changed item comes once, as
+	// it is now. since_ver is ignored when this is set. Servers without the
+	// change_journal feature ignore it and leave seq_watermark unset.
+	SinceSeq *int64
+	// sync_session of BeginSync: read the vault as it stood when the session
+	// began, every item at the version it had then, tombstones included, in
+	// id order. Writes since, from this or other devices, do not show. Pages
+	// follow with page_token as usual; since_ver and since_seq are ignored.
+	// Errors:
+	// - FAILED_PRECONDITION (SYNC_SESSION_EXPIRED): a version the session
+	//   needs is gone, after an admin rollback; call BeginSync again
+	SyncSession *string
 }
 
 func (b0 GetChangesRequest_builder) Build() *GetChangesRequest {
@@ -1271,34 +2202,8485 @@ func (b0 GetChangesRequest_builder) Build() *GetChangesRequest {
 	b, x := &b0, m0
 	_, _ = b, x
 	if b.SinceVer != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 1)
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 6)
 		x.xxx_hidden_SinceVer = *b.SinceVer
 	}
+	if b.MaxSchemaVersion != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 6)
+		x.xxx_hidden_MaxSchemaVersion = *b.MaxSchemaVersion
+	}
+	if b.PageSize != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 6)
+		x.xxx_hidden_PageSize = *b.PageSize
+	}
+	if b.PageToken != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 3, 6)
+		x.xxx_hidden_PageToken = b.PageToken
+	}
+	if b.SinceSeq != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 4, 6)
+		x.xxx_hidden_SinceSeq = *b.SinceSeq
+	}
+	if b.SyncSession != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 5, 6)
+		x.xxx_hidden_SyncSession = b.SyncSession
+	}
 	return m0
 }
 
 type GetChangesResponse struct {
-	state              protoimpl.MessageState `protogen:"opaque.v1"`
-	xxx_hidden_Changes *[]*Change             `protobuf:"bytes,1,rep,name=changes"`
-	unknownFields      protoimpl.UnknownFields
-	sizeCache          protoimpl.SizeCache
+	state                    protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Changes       *[]*Change             `protobuf:"bytes,1,rep,name=changes"`
+	xxx_hidden_ServerTime    *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=server_time,json=serverTime"`
+	xxx_hidden_Watermark     int64                  `protobuf:"varint,3,opt,name=watermark"`
+	xxx_hidden_NextPageToken *string                `protobuf:"bytes,4,opt,name=next_page_token,json=nextPageToken"`
+	xxx_hidden_SeqWatermark  int64                  `protobuf:"varint,5,opt,name=seq_watermark,json=seqWatermark"`
+	XXX_raceDetectHookData   protoimpl.RaceDetectHookData
+	XXX_presence             [1]uint32
+	unknownFields            protoimpl.UnknownFields
+	sizeCache                protoimpl.SizeCache
+}
+
+func (x *GetChangesResponse) Reset() {
+	*x = GetChangesResponse{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetChangesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetChangesResponse) ProtoMessage() {}
+
+func (x *GetChangesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *GetChangesResponse) GetChanges() []*Change {
+	if x != nil {
+		if x.xxx_hidden_Changes != nil {
+			return *x.xxx_hidden_Changes
+		}
+	}
+	return nil
+}
+
+func (x *GetChangesResponse) GetServerTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.xxx_hidden_ServerTime
+	}
+	return nil
+}
+
+func (x *GetChangesResponse) GetWatermark() int64 {
+	if x != nil {
+		return x.xxx_hidden_Watermark
+	}
+	return 0
+}
+
+func (x *GetChangesResponse) GetNextPageToken() string {
+	if x != nil {
+		if x.xxx_hidden_NextPageToken != nil {
+			return *x.xxx_hidden_NextPageToken
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *GetChangesResponse) GetSeqWatermark() int64 {
+	if x != nil {
+		return x.xxx_hidden_SeqWatermark
+	}
+	return 0
+}
+
+func (x *GetChangesResponse) SetChanges(v []*Change) {
+	x.xxx_hidden_Changes = &v
+}
+
+func (x *GetChangesResponse) SetServerTime(v *timestamppb.Timestamp) {
+	x.xxx_hidden_ServerTime = v
+}
+
+func (x *GetChangesResponse) SetWatermark(v int64) {
+	x.xxx_hidden_Watermark = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 5)
+}
+
+func (x *GetChangesResponse) SetNextPageToken(v string) {
+	x.xxx_hidden_NextPageToken = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 3, 5)
+}
+
+func (x *GetChangesResponse) SetSeqWatermark(v int64) {
+	x.xxx_hidden_SeqWatermark = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 4, 5)
+}
+
+func (x *GetChangesResponse) HasServerTime() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_ServerTime != nil
+}
+
+func (x *GetChangesResponse) HasWatermark() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 2)
+}
+
+func (x *GetChangesResponse) HasNextPageToken() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 3)
+}
+
+func (x *GetChangesResponse) HasSeqWatermark() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 4)
+}
+
+func (x *GetChangesResponse) ClearServerTime() {
+	x.xxx_hidden_ServerTime = nil
+}
+
+func (x *GetChangesResponse) ClearWatermark() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 2)
+	x.xxx_hidden_Watermark = 0
+}
+
+func (x *GetChangesResponse) ClearNextPageToken() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 3)
+	x.xxx_hidden_NextPageToken = nil
+}
+
+func (x *GetChangesResponse) ClearSeqWatermark() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 4)
+	x.xxx_hidden_SeqWatermark = 0
+}
+
+type GetChangesResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Changes []*Change
+	// Server clock when the changes were read. Clients compare it with their own
+	// clock to detect skew instead of trusting local time for sync bookkeeping.
+	ServerTime *timestamppb.Timestamp
+	// Cursor to send as since_ver next time: the highest version in this
+	// response, or the request's since_ver when nothing changed. Many items
+	// share a version, so it is only complete on the last page.
+	Watermark *int64
+	// Set when more changes follow: pass it as page_token for the next page.
+	NextPageToken *string
+	// Journal reads only: the position to send as since_seq next time, the
+	// seq of the last change or the request's since_seq when nothing
+	// changed. Complete on every page.
+	SeqWatermark *int64
+}
+
+func (b0 GetChangesResponse_builder) Build() *GetChangesResponse {
+	m0 := &GetChangesResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_Changes = &b.Changes
+	x.xxx_hidden_ServerTime = b.ServerTime
+	if b.Watermark != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 5)
+		x.xxx_hidden_Watermark = *b.Watermark
+	}
+	if b.NextPageToken != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 3, 5)
+		x.xxx_hidden_NextPageToken = b.NextPageToken
+	}
+	if b.SeqWatermark != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 4, 5)
+		x.xxx_hidden_SeqWatermark = *b.SeqWatermark
+	}
+	return m0
+}
+
+// GetChanges that waits for something to report.
+type GetChangesLongPollRequest struct {
+	state                       protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_SinceVer         int64                  `protobuf:"varint,1,opt,name=since_ver,json=sinceVer"`
+	xxx_hidden_WaitSeconds      int32                  `protobuf:"varint,2,opt,name=wait_seconds,json=waitSeconds"`
+	xxx_hidden_MaxSchemaVersion int32                  `protobuf:"varint,3,opt,name=max_schema_version,json=maxSchemaVersion"`
+	xxx_hidden_SinceSeq         int64                  `protobuf:"varint,4,opt,name=since_seq,json=sinceSeq"`
+	XXX_raceDetectHookData      protoimpl.RaceDetectHookData
+	XXX_presence                [1]uint32
+	unknownFields               protoimpl.UnknownFields
+	sizeCache                   protoimpl.SizeCache
+}
+
+func (x *GetChangesLongPollRequest) Reset() {
+	*x = GetChangesLongPollRequest{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetChangesLongPollRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetChangesLongPollRequest) ProtoMessage() {}
+
+func (x *GetChangesLongPollRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *GetChangesLongPollRequest) GetSinceVer() int64 {
+	if x != nil {
+		return x.xxx_hidden_SinceVer
+	}
+	return 0
+}
+
+func (x *GetChangesLongPollRequest) GetWaitSeconds() int32 {
+	if x != nil {
+		return x.xxx_hidden_WaitSeconds
+	}
+	return 0
+}
+
+func (x *GetChangesLongPollRequest) GetMaxSchemaVersion() int32 {
+	if x != nil {
+		return x.xxx_hidden_MaxSchemaVersion
+	}
+	return 0
+}
+
+func (x *GetChangesLongPollRequest) GetSinceSeq() int64 {
+	if x != nil {
+		return x.xxx_hidden_SinceSeq
+	}
+	return 0
+}
+
+func (x *GetChangesLongPollRequest) SetSinceVer(v int64) {
+	x.xxx_hidden_SinceVer = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 4)
+}
+
+func (x *GetChangesLongPollRequest) SetWaitSeconds(v int32) {
+	x.xxx_hidden_WaitSeconds = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 4)
+}
+
+func (x *GetChangesLongPollRequest) SetMaxSchemaVersion(v int32) {
+	x.xxx_hidden_MaxSchemaVersion = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 4)
+}
+
+func (x *GetChangesLongPollRequest) SetSinceSeq(v int64) {
+	x.xxx_hidden_SinceSeq = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 3, 4)
+}
+
+func (x *GetChangesLongPollRequest) HasSinceVer() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *GetChangesLongPollRequest) HasWaitSeconds() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *GetChangesLongPollRequest) HasMaxSchemaVersion() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 2)
+}
+
+func (x *GetChangesLongPollRequest) HasSinceSeq() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 3)
+}
+
+func (x *GetChangesLongPollRequest) ClearSinceVer() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_SinceVer = 0
+}
+
+func (x *GetChangesLongPollRequest) ClearWaitSeconds() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_WaitSeconds = 0
+}
+
+func (x *GetChangesLongPollRequest) ClearMaxSchemaVersion() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 2)
+	x.xxx_hidden_MaxSchemaVersion = 0
+}
+
+func (x *GetChangesLongPollRequest) ClearSinceSeq() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 3)
+	x.xxx_hidden_SinceSeq = 0
+}
+
+type GetChangesLongPollRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	// As in GetChangesRequest.
+	SinceVer *int64
+	// How long to hold the call when nothing has changed: 0 means 30 s, and
+	// at most 60 s is honoured. The server also answers about a second before
+	// the call's deadline.
+	WaitSeconds *int32
+	// As in GetChangesRequest.
+	MaxSchemaVersion *int32
+	// As in GetChangesRequest; since_ver is ignored when this is set.
+	SinceSeq *int64
+}
+
+func (b0 GetChangesLongPollRequest_builder) Build() *GetChangesLongPollRequest {
+	m0 := &GetChangesLongPollRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.SinceVer != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 4)
+		x.xxx_hidden_SinceVer = *b.SinceVer
+	}
+	if b.WaitSeconds != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 4)
+		x.xxx_hidden_WaitSeconds = *b.WaitSeconds
+	}
+	if b.MaxSchemaVersion != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 4)
+		x.xxx_hidden_MaxSchemaVersion = *b.MaxSchemaVersion
+	}
+	if b.SinceSeq != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 3, 4)
+		x.xxx_hidden_SinceSeq = *b.SinceSeq
+	}
+	return m0
+}
+
+type GetChangesLongPollResponse struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Result      *GetChangesResponse    `protobuf:"bytes,1,opt,name=result"`
+	xxx_hidden_Changed     bool                   `protobuf:"varint,2,opt,name=changed"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *GetChangesLongPollResponse) Reset() {
+	*x = GetChangesLongPollResponse{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetChangesLongPollResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetChangesLongPollResponse) ProtoMessage() {}
+
+func (x *GetChangesLongPollResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *GetChangesLongPollResponse) GetResult() *GetChangesResponse {
+	if x != nil {
+		return x.xxx_hidden_Result
+	}
+	return nil
+}
+
+func (x *GetChangesLongPollResponse) GetChanged() bool {
+	if x != nil {
+		return x.xxx_hidden_Changed
+	}
+	return false
+}
+
+func (x *GetChangesLongPollResponse) SetResult(v *GetChangesResponse) {
+	x.xxx_hidden_Result = v
+}
+
+func (x *GetChangesLongPollResponse) SetChanged(v bool) {
+	x.xxx_hidden_Changed = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 2)
+}
+
+func (x *GetChangesLongPollResponse) HasResult() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_Result != nil
+}
+
+func (x *GetChangesLongPollResponse) HasChanged() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *GetChangesLongPollResponse) ClearResult() {
+	x.xxx_hidden_Result = nil
+}
+
+func (x *GetChangesLongPollResponse) ClearChanged() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_Changed = false
+}
+
+type GetChangesLongPollResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	// The first page GetChanges returns for since_ver at the time of the
+	// reply; fetch the rest with GetChanges and its next_page_token.
+	Result *GetChangesResponse
+	// The vault changed: there are changes above since_ver, or a write landed
+	// while the call waited. Edits keep each item's own version, so an edit of
+	// an older item is reported here without appearing in result; clients then
+	// pull in full. False when the wait ran out.
+	Changed *bool
+}
+
+func (b0 GetChangesLongPollResponse_builder) Build() *GetChangesLongPollResponse {
+	m0 := &GetChangesLongPollResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_Result = b.Result
+	if b.Changed != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 2)
+		x.xxx_hidden_Changed = *b.Changed
+	}
+	return m0
+}
+
+type GetItemRequest struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Id          *string                `protobuf:"bytes,1,opt,name=id"`
+	xxx_hidden_Ver         int64                  `protobuf:"varint,2,opt,name=ver"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *GetItemRequest) Reset() {
+	*x = GetItemRequest{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetItemRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetItemRequest) ProtoMessage() {}
+
+func (x *GetItemRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *GetItemRequest) GetId() string {
+	if x != nil {
+		if x.xxx_hidden_Id != nil {
+			return *x.xxx_hidden_Id
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *GetItemRequest) GetVer() int64 {
+	if x != nil {
+		return x.xxx_hidden_Ver
+	}
+	return 0
+}
+
+func (x *GetItemRequest) SetId(v string) {
+	x.xxx_hidden_Id = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 2)
+}
+
+func (x *GetItemRequest) SetVer(v int64) {
+	x.xxx_hidden_Ver = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 2)
+}
+
+func (x *GetItemRequest) HasId() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *GetItemRequest) HasVer() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *GetItemRequest) ClearId() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_Id = nil
+}
+
+func (x *GetItemRequest) ClearVer() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_Ver = 0
+}
+
+type GetItemRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Id *string
+	// Historical version to fetch; 0 returns the current state.
+	Ver *int64
+}
+
+func (b0 GetItemRequest_builder) Build() *GetItemRequest {
+	m0 := &GetItemRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Id != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 2)
+		x.xxx_hidden_Id = b.Id
+	}
+	if b.Ver != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 2)
+		x.xxx_hidden_Ver = *b.Ver
+	}
+	return m0
+}
+
+type GetItemResponse struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Id          *string                `protobuf:"bytes,1,opt,name=id"`
+	xxx_hidden_Ver         int64                  `protobuf:"varint,2,opt,name=ver"`
+	xxx_hidden_Deleted     bool                   `protobuf:"varint,3,opt,name=deleted"`
+	xxx_hidden_UpdatedAt   *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=updated_at,json=updatedAt"`
+	xxx_hidden_BlobEnc     *EncryptedBlob         `protobuf:"bytes,5,opt,name=blob_enc,json=blobEnc"`
+	xxx_hidden_Hints       *ItemHints             `protobuf:"bytes,6,opt,name=hints"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *GetItemResponse) Reset() {
+	*x = GetItemResponse{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetItemResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetItemResponse) ProtoMessage() {}
+
+func (x *GetItemResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *GetItemResponse) GetId() string {
+	if x != nil {
+		if x.xxx_hidden_Id != nil {
+			return *x.xxx_hidden_Id
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *GetItemResponse) GetVer() int64 {
+	if x != nil {
+		return x.xxx_hidden_Ver
+	}
+	return 0
+}
+
+func (x *GetItemResponse) GetDeleted() bool {
+	if x != nil {
+		return x.xxx_hidden_Deleted
+	}
+	return false
+}
+
+func (x *GetItemResponse) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.xxx_hidden_UpdatedAt
+	}
+	return nil
+}
+
+func (x *GetItemResponse) GetBlobEnc() *EncryptedBlob {
+	if x != nil {
+		return x.xxx_hidden_BlobEnc
+	}
+	return nil
+}
+
+func (x *GetItemResponse) GetHints() *ItemHints {
+	if x != nil {
+		return x.xxx_hidden_Hints
+	}
+	return nil
+}
+
+func (x *GetItemResponse) SetId(v string) {
+	x.xxx_hidden_Id = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 6)
+}
+
+func (x *GetItemResponse) SetVer(v int64) {
+	x.xxx_hidden_Ver = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 6)
+}
+
+func (x *GetItemResponse) SetDeleted(v bool) {
+	x.xxx_hidden_Deleted = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 6)
+}
+
+func (x *GetItemResponse) SetUpdatedAt(v *timestamppb.Timestamp) {
+	x.xxx_hidden_UpdatedAt = v
+}
+
+func (x *GetItemResponse) SetBlobEnc(v *EncryptedBlob) {
+	x.xxx_hidden_BlobEnc = v
+}
+
+func (x *GetItemResponse) SetHints(v *ItemHints) {
+	x.xxx_hidden_Hints = v
+}
+
+func (x *GetItemResponse) HasId() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *GetItemResponse) HasVer() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *GetItemResponse) HasDeleted() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 2)
+}
+
+func (x *GetItemResponse) HasUpdatedAt() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_UpdatedAt != nil
+}
+
+func (x *GetItemResponse) HasBlobEnc() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_BlobEnc != nil
+}
+
+func (x *GetItemResponse) HasHints() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_Hints != nil
+}
+
+func (x *GetItemResponse) ClearId() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_Id = nil
+}
+
+func (x *GetItemResponse) ClearVer() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_Ver = 0
+}
+
+func (x *GetItemResponse) ClearDeleted() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 2)
+	x.xxx_hidden_Deleted = false
+}
+
+func (x *GetItemResponse) ClearUpdatedAt() {
+	x.xxx_hidden_UpdatedAt = nil
+}
+
+func (x *GetItemResponse) ClearBlobEnc() {
+	x.xxx_hidden_BlobEnc = nil
+}
+
+func (x *GetItemResponse) ClearHints() {
+	x.xxx_hidden_Hints = nil
+}
+
+type GetItemResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Id        *string
+	Ver       *int64
+	Deleted   *bool
+	UpdatedAt *timestamppb.Timestamp
+	BlobEnc   *EncryptedBlob
+	Hints     *ItemHints
+}
+
+func (b0 GetItemResponse_builder) Build() *GetItemResponse {
+	m0 := &GetItemResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Id != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 6)
+		x.xxx_hidden_Id = b.Id
+	}
+	if b.Ver != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 6)
+		x.xxx_hidden_Ver = *b.Ver
+	}
+	if b.Deleted != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 6)
+		x.xxx_hidden_Deleted = *b.Deleted
+	}
+	x.xxx_hidden_UpdatedAt = b.UpdatedAt
+	x.xxx_hidden_BlobEnc = b.BlobEnc
+	x.xxx_hidden_Hints = b.Hints
+	return m0
+}
+
+// One message of GetItemStream. The first carries the item's metadata and
+// total_size; every message carries the next bytes of blob_enc.ciphertext,
+// in order. A tombstone is a single message without data.
+type GetItemChunk struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Id          *string                `protobuf:"bytes,1,opt,name=id"`
+	xxx_hidden_Ver         int64                  `protobuf:"varint,2,opt,name=ver"`
+	xxx_hidden_Deleted     bool                   `protobuf:"varint,3,opt,name=deleted"`
+	xxx_hidden_UpdatedAt   *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=updated_at,json=updatedAt"`
+	xxx_hidden_TotalSize   int64                  `protobuf:"varint,5,opt,name=total_size,json=totalSize"`
+	xxx_hidden_Data        []byte                 `protobuf:"bytes,6,opt,name=data"`
+	xxx_hidden_Hints       *ItemHints             `protobuf:"bytes,7,opt,name=hints"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *GetItemChunk) Reset() {
+	*x = GetItemChunk{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetItemChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetItemChunk) ProtoMessage() {}
+
+func (x *GetItemChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *GetItemChunk) GetId() string {
+	if x != nil {
+		if x.xxx_hidden_Id != nil {
+			return *x.xxx_hidden_Id
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *GetItemChunk) GetVer() int64 {
+	if x != nil {
+		return x.xxx_hidden_Ver
+	}
+	return 0
+}
+
+func (x *GetItemChunk) GetDeleted() bool {
+	if x != nil {
+		return x.xxx_hidden_Deleted
+	}
+	return false
+}
+
+func (x *GetItemChunk) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.xxx_hidden_UpdatedAt
+	}
+	return nil
+}
+
+func (x *GetItemChunk) GetTotalSize() int64 {
+	if x != nil {
+		return x.xxx_hidden_TotalSize
+	}
+	return 0
+}
+
+func (x *GetItemChunk) GetData() []byte {
+	if x != nil {
+		return x.xxx_hidden_Data
+	}
+	return nil
+}
+
+func (x *GetItemChunk) GetHints() *ItemHints {
+	if x != nil {
+		return x.xxx_hidden_Hints
+	}
+	return nil
+}
+
+func (x *GetItemChunk) SetId(v string) {
+	x.xxx_hidden_Id = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 7)
+}
+
+func (x *GetItemChunk) SetVer(v int64) {
+	x.xxx_hidden_Ver = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 7)
+}
+
+func (x *GetItemChunk) SetDeleted(v bool) {
+	x.xxx_hidden_Deleted = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 7)
+}
+
+func (x *GetItemChunk) SetUpdatedAt(v *timestamppb.Timestamp) {
+	x.xxx_hidden_UpdatedAt = v
+}
+
+func (x *GetItemChunk) SetTotalSize(v int64) {
+	x.xxx_hidden_TotalSize = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 4, 7)
+}
+
+func (x *GetItemChunk) SetData(v []byte) {
+	if v == nil {
+		v = []byte{}
+	}
+	x.xxx_hidden_Data = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 5, 7)
+}
+
+func (x *GetItemChunk) SetHints(v *ItemHints) {
+	x.xxx_hidden_Hints = v
+}
+
+func (x *GetItemChunk) HasId() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *GetItemChunk) HasVer() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *GetItemChunk) HasDeleted() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 2)
+}
+
+func (x *GetItemChunk) HasUpdatedAt() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_UpdatedAt != nil
+}
+
+func (x *GetItemChunk) HasTotalSize() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 4)
+}
+
+func (x *GetItemChunk) HasData() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 5)
+}
+
+func (x *GetItemChunk) HasHints() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_Hints != nil
+}
+
+func (x *GetItemChunk) ClearId() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_Id = nil
+}
+
+func (x *GetItemChunk) ClearVer() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_Ver = 0
+}
+
+func (x *GetItemChunk) ClearDeleted() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 2)
+	x.xxx_hidden_Deleted = false
+}
+
+func (x *GetItemChunk) ClearUpdatedAt() {
+	x.xxx_hidden_UpdatedAt = nil
+}
+
+func (x *GetItemChunk) ClearTotalSize() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 4)
+	x.xxx_hidden_TotalSize = 0
+}
+
+func (x *GetItemChunk) ClearData() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 5)
+	x.xxx_hidden_Data = nil
+}
+
+func (x *GetItemChunk) ClearHints() {
+	x.xxx_hidden_Hints = nil
+}
+
+type GetItemChunk_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Id        *string
+	Ver       *int64
+	Deleted   *bool
+	UpdatedAt *timestamppb.Timestamp
+	// Length of the whole ciphertext.
+	TotalSize *int64
+	Data      []byte
+	// First message only.
+	Hints *ItemHints
+}
+
+func (b0 GetItemChunk_builder) Build() *GetItemChunk {
+	m0 := &GetItemChunk{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Id != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 7)
+		x.xxx_hidden_Id = b.Id
+	}
+	if b.Ver != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 7)
+		x.xxx_hidden_Ver = *b.Ver
+	}
+	if b.Deleted != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 7)
+		x.xxx_hidden_Deleted = *b.Deleted
+	}
+	x.xxx_hidden_UpdatedAt = b.UpdatedAt
+	if b.TotalSize != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 4, 7)
+		x.xxx_hidden_TotalSize = *b.TotalSize
+	}
+	if b.Data != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 5, 7)
+		x.xxx_hidden_Data = b.Data
+	}
+	x.xxx_hidden_Hints = b.Hints
+	return m0
+}
+
+type BeginSyncRequest struct {
+	state         protoimpl.MessageState `protogen:"opaque.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BeginSyncRequest) Reset() {
+	*x = BeginSyncRequest{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BeginSyncRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BeginSyncRequest) ProtoMessage() {}
+
+func (x *BeginSyncRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+type BeginSyncRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+}
+
+func (b0 BeginSyncRequest_builder) Build() *BeginSyncRequest {
+	m0 := &BeginSyncRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	return m0
+}
+
+type BeginSyncResponse struct {
+	state                   protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_SyncSession  *string                `protobuf:"bytes,1,opt,name=sync_session,json=syncSession"`
+	xxx_hidden_SeqWatermark int64                  `protobuf:"varint,2,opt,name=seq_watermark,json=seqWatermark"`
+	xxx_hidden_ServerTime   *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=server_time,json=serverTime"`
+	XXX_raceDetectHookData  protoimpl.RaceDetectHookData
+	XXX_presence            [1]uint32
+	unknownFields           protoimpl.UnknownFields
+	sizeCache               protoimpl.SizeCache
+}
+
+func (x *BeginSyncResponse) Reset() {
+	*x = BeginSyncResponse{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BeginSyncResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BeginSyncResponse) ProtoMessage() {}
+
+func (x *BeginSyncResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *BeginSyncResponse) GetSyncSession() string {
+	if x != nil {
+		if x.xxx_hidden_SyncSession != nil {
+			return *x.xxx_hidden_SyncSession
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *BeginSyncResponse) GetSeqWatermark() int64 {
+	if x != nil {
+		return x.xxx_hidden_SeqWatermark
+	}
+	return 0
+}
+
+func (x *BeginSyncResponse) GetServerTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.xxx_hidden_ServerTime
+	}
+	return nil
+}
+
+func (x *BeginSyncResponse) SetSyncSession(v string) {
+	x.xxx_hidden_SyncSession = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 3)
+}
+
+func (x *BeginSyncResponse) SetSeqWatermark(v int64) {
+	x.xxx_hidden_SeqWatermark = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 3)
+}
+
+func (x *BeginSyncResponse) SetServerTime(v *timestamppb.Timestamp) {
+	x.xxx_hidden_ServerTime = v
+}
+
+func (x *BeginSyncResponse) HasSyncSession() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *BeginSyncResponse) HasSeqWatermark() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *BeginSyncResponse) HasServerTime() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_ServerTime != nil
+}
+
+func (x *BeginSyncResponse) ClearSyncSession() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_SyncSession = nil
+}
+
+func (x *BeginSyncResponse) ClearSeqWatermark() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_SeqWatermark = 0
+}
+
+func (x *BeginSyncResponse) ClearServerTime() {
+	x.xxx_hidden_ServerTime = nil
+}
+
+type BeginSyncResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	// Pass as GetChangesRequest.sync_session to page through the vault as of
+	// this call. It does not expire on its own.
+	SyncSession *string
+	// The change journal position the session stands at: once the last page
+	// is read, continue with since_seq set to it to get the writes since.
+	SeqWatermark *int64
+	// Server clock when the session began.
+	ServerTime *timestamppb.Timestamp
+}
+
+func (b0 BeginSyncResponse_builder) Build() *BeginSyncResponse {
+	m0 := &BeginSyncResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.SyncSession != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 3)
+		x.xxx_hidden_SyncSession = b.SyncSession
+	}
+	if b.SeqWatermark != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 3)
+		x.xxx_hidden_SeqWatermark = *b.SeqWatermark
+	}
+	x.xxx_hidden_ServerTime = b.ServerTime
+	return m0
+}
+
+type ExportItemsRequest struct {
+	state                     protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_IncludeDeleted bool                   `protobuf:"varint,1,opt,name=include_deleted,json=includeDeleted"`
+	XXX_raceDetectHookData    protoimpl.RaceDetectHookData
+	XXX_presence              [1]uint32
+	unknownFields             protoimpl.UnknownFields
+	sizeCache                 protoimpl.SizeCache
+}
+
+func (x *ExportItemsRequest) Reset() {
+	*x = ExportItemsRequest{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportItemsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportItemsRequest) ProtoMessage() {}
+
+func (x *ExportItemsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *ExportItemsRequest) GetIncludeDeleted() bool {
+	if x != nil {
+		return x.xxx_hidden_IncludeDeleted
+	}
+	return false
+}
+
+func (x *ExportItemsRequest) SetIncludeDeleted(v bool) {
+	x.xxx_hidden_IncludeDeleted = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 1)
+}
+
+func (x *ExportItemsRequest) HasIncludeDeleted() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *ExportItemsRequest) ClearIncludeDeleted() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_IncludeDeleted = false
+}
+
+type ExportItemsRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	// Send tombstones too; they come without blobs.
+	IncludeDeleted *bool
+}
+
+func (b0 ExportItemsRequest_builder) Build() *ExportItemsRequest {
+	m0 := &ExportItemsRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.IncludeDeleted != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 1)
+		x.xxx_hidden_IncludeDeleted = *b.IncludeDeleted
+	}
+	return m0
+}
+
+// The next items of an export. Several small items share a message; an item
+// whose blob is over 3 MiB comes alone and without blob_enc, for the client
+// to fetch with GetItemStream.
+type ExportItemsResponse struct {
+	state            protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Items *[]*Change             `protobuf:"bytes,1,rep,name=items"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ExportItemsResponse) Reset() {
+	*x = ExportItemsResponse{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportItemsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportItemsResponse) ProtoMessage() {}
+
+func (x *ExportItemsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *ExportItemsResponse) GetItems() []*Change {
+	if x != nil {
+		if x.xxx_hidden_Items != nil {
+			return *x.xxx_hidden_Items
+		}
+	}
+	return nil
+}
+
+func (x *ExportItemsResponse) SetItems(v []*Change) {
+	x.xxx_hidden_Items = &v
+}
+
+type ExportItemsResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Items []*Change
+}
+
+func (b0 ExportItemsResponse_builder) Build() *ExportItemsResponse {
+	m0 := &ExportItemsResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_Items = &b.Items
+	return m0
+}
+
+type DeleteItemRequest struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Id          *string                `protobuf:"bytes,1,opt,name=id"`
+	xxx_hidden_BaseVer     int64                  `protobuf:"varint,2,opt,name=base_ver,json=baseVer"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *DeleteItemRequest) Reset() {
+	*x = DeleteItemRequest{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteItemRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteItemRequest) ProtoMessage() {}
+
+func (x *DeleteItemRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *DeleteItemRequest) GetId() string {
+	if x != nil {
+		if x.xxx_hidden_Id != nil {
+			return *x.xxx_hidden_Id
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *DeleteItemRequest) GetBaseVer() int64 {
+	if x != nil {
+		return x.xxx_hidden_BaseVer
+	}
+	return 0
+}
+
+func (x *DeleteItemRequest) SetId(v string) {
+	x.xxx_hidden_Id = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 2)
+}
+
+func (x *DeleteItemRequest) SetBaseVer(v int64) {
+	x.xxx_hidden_BaseVer = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 2)
+}
+
+func (x *DeleteItemRequest) HasId() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *DeleteItemRequest) HasBaseVer() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *DeleteItemRequest) ClearId() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_Id = nil
+}
+
+func (x *DeleteItemRequest) ClearBaseVer() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_BaseVer = 0
+}
+
+type DeleteItemRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Id      *string
+	BaseVer *int64
+}
+
+func (b0 DeleteItemRequest_builder) Build() *DeleteItemRequest {
+	m0 := &DeleteItemRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Id != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 2)
+		x.xxx_hidden_Id = b.Id
+	}
+	if b.BaseVer != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 2)
+		x.xxx_hidden_BaseVer = *b.BaseVer
+	}
+	return m0
+}
+
+type DeleteItemResponse struct {
+	state             protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Result *ItemVersion           `protobuf:"bytes,1,opt,name=result"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *DeleteItemResponse) Reset() {
+	*x = DeleteItemResponse{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteItemResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteItemResponse) ProtoMessage() {}
+
+func (x *DeleteItemResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *DeleteItemResponse) GetResult() *ItemVersion {
+	if x != nil {
+		return x.xxx_hidden_Result
+	}
+	return nil
+}
+
+func (x *DeleteItemResponse) SetResult(v *ItemVersion) {
+	x.xxx_hidden_Result = v
+}
+
+func (x *DeleteItemResponse) HasResult() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_Result != nil
+}
+
+func (x *DeleteItemResponse) ClearResult() {
+	x.xxx_hidden_Result = nil
+}
+
+type DeleteItemResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Result *ItemVersion
+}
+
+func (b0 DeleteItemResponse_builder) Build() *DeleteItemResponse {
+	m0 := &DeleteItemResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_Result = b.Result
+	return m0
+}
+
+// An item and the version the client last saw.
+type ItemRef struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Id          *string                `protobuf:"bytes,1,opt,name=id"`
+	xxx_hidden_BaseVer     int64                  `protobuf:"varint,2,opt,name=base_ver,json=baseVer"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *ItemRef) Reset() {
+	*x = ItemRef{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ItemRef) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ItemRef) ProtoMessage() {}
+
+func (x *ItemRef) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *ItemRef) GetId() string {
+	if x != nil {
+		if x.xxx_hidden_Id != nil {
+			return *x.xxx_hidden_Id
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *ItemRef) GetBaseVer() int64 {
+	if x != nil {
+		return x.xxx_hidden_BaseVer
+	}
+	return 0
+}
+
+func (x *ItemRef) SetId(v string) {
+	x.xxx_hidden_Id = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 2)
+}
+
+func (x *ItemRef) SetBaseVer(v int64) {
+	x.xxx_hidden_BaseVer = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 2)
+}
+
+func (x *ItemRef) HasId() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *ItemRef) HasBaseVer() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *ItemRef) ClearId() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_Id = nil
+}
+
+func (x *ItemRef) ClearBaseVer() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_BaseVer = 0
+}
+
+type ItemRef_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Id      *string
+	BaseVer *int64
+}
+
+func (b0 ItemRef_builder) Build() *ItemRef {
+	m0 := &ItemRef{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Id != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 2)
+		x.xxx_hidden_Id = b.Id
+	}
+	if b.BaseVer != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 2)
+		x.xxx_hidden_BaseVer = *b.BaseVer
+	}
+	return m0
+}
+
+type ItemResult struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Id          *string                `protobuf:"bytes,1,opt,name=id"`
+	xxx_hidden_Status      ItemStatus             `protobuf:"varint,2,opt,name=status,enum=gophkeeper.v1.ItemStatus"`
+	xxx_hidden_Ver         int64                  `protobuf:"varint,3,opt,name=ver"`
+	xxx_hidden_UpdatedAt   *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=updated_at,json=updatedAt"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *ItemResult) Reset() {
+	*x = ItemResult{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ItemResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ItemResult) ProtoMessage() {}
+
+func (x *ItemResult) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *ItemResult) GetId() string {
+	if x != nil {
+		if x.xxx_hidden_Id != nil {
+			return *x.xxx_hidden_Id
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *ItemResult) GetStatus() ItemStatus {
+	if x != nil {
+		if protoimpl.X.Present(&(x.XXX_presence[0]), 1) {
+			return x.xxx_hidden_Status
+		}
+	}
+	return ItemStatus_ITEM_STATUS_UNSPECIFIED
+}
+
+func (x *ItemResult) GetVer() int64 {
+	if x != nil {
+		return x.xxx_hidden_Ver
+	}
+	return 0
+}
+
+func (x *ItemResult) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.xxx_hidden_UpdatedAt
+	}
+	return nil
+}
+
+func (x *ItemResult) SetId(v string) {
+	x.xxx_hidden_Id = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 4)
+}
+
+func (x *ItemResult) SetStatus(v ItemStatus) {
+	x.xxx_hidden_Status = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 4)
+}
+
+func (x *ItemResult) SetVer(v int64) {
+	x.xxx_hidden_Ver = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 4)
+}
+
+func (x *ItemResult) SetUpdatedAt(v *timestamppb.Timestamp) {
+	x.xxx_hidden_UpdatedAt = v
+}
+
+func (x *ItemResult) HasId() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *ItemResult) HasStatus() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *ItemResult) HasVer() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 2)
+}
+
+func (x *ItemResult) HasUpdatedAt() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_UpdatedAt != nil
+}
+
+func (x *ItemResult) ClearId() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_Id = nil
+}
+
+func (x *ItemResult) ClearStatus() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_Status = ItemStatus_ITEM_STATUS_UNSPECIFIED
+}
+
+func (x *ItemResult) ClearVer() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 2)
+	x.xxx_hidden_Ver = 0
+}
+
+func (x *ItemResult) ClearUpdatedAt() {
+	x.xxx_hidden_UpdatedAt = nil
+}
+
+type ItemResult_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Id     *string
+	Status *ItemStatus
+	// OK: the new version. VERSION_CONFLICT: the item's current version, 0
+	// if there is no such item.
+	Ver *int64
+	// VERSION_CONFLICT: when the current version was written. May be unset
+	// for OK.
+	UpdatedAt *timestamppb.Timestamp
+}
+
+func (b0 ItemResult_builder) Build() *ItemResult {
+	m0 := &ItemResult{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Id != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 4)
+		x.xxx_hidden_Id = b.Id
+	}
+	if b.Status != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 4)
+		x.xxx_hidden_Status = *b.Status
+	}
+	if b.Ver != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 4)
+		x.xxx_hidden_Ver = *b.Ver
+	}
+	x.xxx_hidden_UpdatedAt = b.UpdatedAt
+	return m0
+}
+
+type DeleteItemsRequest struct {
+	state            protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Items *[]*ItemRef            `protobuf:"bytes,1,rep,name=items"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *DeleteItemsRequest) Reset() {
+	*x = DeleteItemsRequest{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteItemsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteItemsRequest) ProtoMessage() {}
+
+func (x *DeleteItemsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *DeleteItemsRequest) GetItems() []*ItemRef {
+	if x != nil {
+		if x.xxx_hidden_Items != nil {
+			return *x.xxx_hidden_Items
+		}
+	}
+	return nil
+}
+
+func (x *DeleteItemsRequest) SetItems(v []*ItemRef) {
+	x.xxx_hidden_Items = &v
+}
+
+type DeleteItemsRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Items []*ItemRef
+}
+
+func (b0 DeleteItemsRequest_builder) Build() *DeleteItemsRequest {
+	m0 := &DeleteItemsRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_Items = &b.Items
+	return m0
+}
+
+type DeleteItemsResponse struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Results     *[]*ItemResult         `protobuf:"bytes,1,rep,name=results"`
+	xxx_hidden_Applied     bool                   `protobuf:"varint,2,opt,name=applied"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *DeleteItemsResponse) Reset() {
+	*x = DeleteItemsResponse{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteItemsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteItemsResponse) ProtoMessage() {}
+
+func (x *DeleteItemsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *DeleteItemsResponse) GetResults() []*ItemResult {
+	if x != nil {
+		if x.xxx_hidden_Results != nil {
+			return *x.xxx_hidden_Results
+		}
+	}
+	return nil
+}
+
+func (x *DeleteItemsResponse) GetApplied() bool {
+	if x != nil {
+		return x.xxx_hidden_Applied
+	}
+	return false
+}
+
+func (x *DeleteItemsResponse) SetResults(v []*ItemResult) {
+	x.xxx_hidden_Results = &v
+}
+
+func (x *DeleteItemsResponse) SetApplied(v bool) {
+	x.xxx_hidden_Applied = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 2)
+}
+
+func (x *DeleteItemsResponse) HasApplied() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *DeleteItemsResponse) ClearApplied() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_Applied = false
+}
+
+type DeleteItemsResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	// One per requested item, in request order.
+	Results []*ItemResult
+	// Every item was deleted. When false nothing was.
+	Applied *bool
+}
+
+func (b0 DeleteItemsResponse_builder) Build() *DeleteItemsResponse {
+	m0 := &DeleteItemsResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_Results = &b.Results
+	if b.Applied != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 2)
+		x.xxx_hidden_Applied = *b.Applied
+	}
+	return m0
+}
+
+type ApplyChangeSetRequest struct {
+	state              protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Upserts *[]*UpsertItem         `protobuf:"bytes,1,rep,name=upserts"`
+	xxx_hidden_Deletes *[]*ItemRef            `protobuf:"bytes,2,rep,name=deletes"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *ApplyChangeSetRequest) Reset() {
+	*x = ApplyChangeSetRequest{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApplyChangeSetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApplyChangeSetRequest) ProtoMessage() {}
+
+func (x *ApplyChangeSetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *ApplyChangeSetRequest) GetUpserts() []*UpsertItem {
+	if x != nil {
+		if x.xxx_hidden_Upserts != nil {
+			return *x.xxx_hidden_Upserts
+		}
+	}
+	return nil
+}
+
+func (x *ApplyChangeSetRequest) GetDeletes() []*ItemRef {
+	if x != nil {
+		if x.xxx_hidden_Deletes != nil {
+			return *x.xxx_hidden_Deletes
+		}
+	}
+	return nil
+}
+
+func (x *ApplyChangeSetRequest) SetUpserts(v []*UpsertItem) {
+	x.xxx_hidden_Upserts = &v
+}
+
+func (x *ApplyChangeSetRequest) SetDeletes(v []*ItemRef) {
+	x.xxx_hidden_Deletes = &v
+}
+
+type ApplyChangeSetRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	// Written as by UpsertItems.
+	Upserts []*UpsertItem
+	// Tombstoned as by DeleteItems. An id may not be both upserted and
+	// deleted.
+	Deletes []*ItemRef
+}
+
+func (b0 ApplyChangeSetRequest_builder) Build() *ApplyChangeSetRequest {
+	m0 := &ApplyChangeSetRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_Upserts = &b.Upserts
+	x.xxx_hidden_Deletes = &b.Deletes
+	return m0
+}
+
+type ApplyChangeSetResponse struct {
+	state                    protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_UpsertResults *[]*ItemResult         `protobuf:"bytes,1,rep,name=upsert_results,json=upsertResults"`
+	xxx_hidden_DeleteResults *[]*ItemResult         `protobuf:"bytes,2,rep,name=delete_results,json=deleteResults"`
+	xxx_hidden_Applied       bool                   `protobuf:"varint,3,opt,name=applied"`
+	xxx_hidden_Warnings      *[]*Warning            `protobuf:"bytes,4,rep,name=warnings"`
+	XXX_raceDetectHookData   protoimpl.RaceDetectHookData
+	XXX_presence             [1]uint32
+	unknownFields            protoimpl.UnknownFields
+	sizeCache                protoimpl.SizeCache
+}
+
+func (x *ApplyChangeSetResponse) Reset() {
+	*x = ApplyChangeSetResponse{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApplyChangeSetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApplyChangeSetResponse) ProtoMessage() {}
+
+func (x *ApplyChangeSetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *ApplyChangeSetResponse) GetUpsertResults() []*ItemResult {
+	if x != nil {
+		if x.xxx_hidden_UpsertResults != nil {
+			return *x.xxx_hidden_UpsertResults
+		}
+	}
+	return nil
+}
+
+func (x *ApplyChangeSetResponse) GetDeleteResults() []*ItemResult {
+	if x != nil {
+		if x.xxx_hidden_DeleteResults != nil {
+			return *x.xxx_hidden_DeleteResults
+		}
+	}
+	return nil
+}
+
+func (x *ApplyChangeSetResponse) GetApplied() bool {
+	if x != nil {
+		return x.xxx_hidden_Applied
+	}
+	return false
+}
+
+func (x *ApplyChangeSetResponse) GetWarnings() []*Warning {
+	if x != nil {
+		if x.xxx_hidden_Warnings != nil {
+			return *x.xxx_hidden_Warnings
+		}
+	}
+	return nil
+}
+
+func (x *ApplyChangeSetResponse) SetUpsertResults(v []*ItemResult) {
+	x.xxx_hidden_UpsertResults = &v
+}
+
+func (x *ApplyChangeSetResponse) SetDeleteResults(v []*ItemResult) {
+	x.xxx_hidden_DeleteResults = &v
+}
+
+func (x *ApplyChangeSetResponse) SetApplied(v bool) {
+	x.xxx_hidden_Applied = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 4)
+}
+
+func (x *ApplyChangeSetResponse) SetWarnings(v []*Warning) {
+	x.xxx_hidden_Warnings = &v
+}
+
+func (x *ApplyChangeSetResponse) HasApplied() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 2)
+}
+
+func (x *ApplyChangeSetResponse) ClearApplied() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 2)
+	x.xxx_hidden_Applied = false
+}
+
+type ApplyChangeSetResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	// One per upsert, in request order. An id another user holds is a
+	// VERSION_CONFLICT with ver 0.
+	UpsertResults []*ItemResult
+	// One per delete, in request order.
+	DeleteResults []*ItemResult
+	// Every upsert and delete was applied. When false none was.
+	Applied *bool
+	// As in UpsertItemsResponse; only when applied.
+	Warnings []*Warning
+}
+
+func (b0 ApplyChangeSetResponse_builder) Build() *ApplyChangeSetResponse {
+	m0 := &ApplyChangeSetResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_UpsertResults = &b.UpsertResults
+	x.xxx_hidden_DeleteResults = &b.DeleteResults
+	if b.Applied != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 4)
+		x.xxx_hidden_Applied = *b.Applied
+	}
+	x.xxx_hidden_Warnings = &b.Warnings
+	return m0
+}
+
+// Aggregate counters over the caller's items (no plaintext involved).
+type GetStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"opaque.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatsRequest) Reset() {
+	*x = GetStatsRequest{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatsRequest) ProtoMessage() {}
+
+func (x *GetStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+type GetStatsRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+}
+
+func (b0 GetStatsRequest_builder) Build() *GetStatsRequest {
+	m0 := &GetStatsRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	return m0
+}
+
+type GetStatsResponse struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Items       int64                  `protobuf:"varint,1,opt,name=items"`
+	xxx_hidden_Deleted     int64                  `protobuf:"varint,2,opt,name=deleted"`
+	xxx_hidden_MaxVer      int64                  `protobuf:"varint,3,opt,name=max_ver,json=maxVer"`
+	xxx_hidden_TotalBytes  int64                  `protobuf:"varint,4,opt,name=total_bytes,json=totalBytes"`
+	xxx_hidden_LastUpdated *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=last_updated,json=lastUpdated"`
+	xxx_hidden_MaxItems    int64                  `protobuf:"varint,6,opt,name=max_items,json=maxItems"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *GetStatsResponse) Reset() {
+	*x = GetStatsResponse{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatsResponse) ProtoMessage() {}
+
+func (x *GetStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *GetStatsResponse) GetItems() int64 {
+	if x != nil {
+		return x.xxx_hidden_Items
+	}
+	return 0
+}
+
+func (x *GetStatsResponse) GetDeleted() int64 {
+	if x != nil {
+		return x.xxx_hidden_Deleted
+	}
+	return 0
+}
+
+func (x *GetStatsResponse) GetMaxVer() int64 {
+	if x != nil {
+		return x.xxx_hidden_MaxVer
+	}
+	return 0
+}
+
+func (x *GetStatsResponse) GetTotalBytes() int64 {
+	if x != nil {
+		return x.xxx_hidden_TotalBytes
+	}
+	return 0
+}
+
+func (x *GetStatsResponse) GetLastUpdated() *timestamppb.Timestamp {
+	if x != nil {
+		return x.xxx_hidden_LastUpdated
+	}
+	return nil
+}
+
+func (x *GetStatsResponse) GetMaxItems() int64 {
+	if x != nil {
+		return x.xxx_hidden_MaxItems
+	}
+	return 0
+}
+
+func (x *GetStatsResponse) SetItems(v int64) {
+	x.xxx_hidden_Items = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 6)
+}
+
+func (x *GetStatsResponse) SetDeleted(v int64) {
+	x.xxx_hidden_Deleted = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 6)
+}
+
+func (x *GetStatsResponse) SetMaxVer(v int64) {
+	x.xxx_hidden_MaxVer = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 6)
+}
+
+func (x *GetStatsResponse) SetTotalBytes(v int64) {
+	x.xxx_hidden_TotalBytes = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 3, 6)
+}
+
+func (x *GetStatsResponse) SetLastUpdated(v *timestamppb.Timestamp) {
+	x.xxx_hidden_LastUpdated = v
+}
+
+func (x *GetStatsResponse) SetMaxItems(v int64) {
+	x.xxx_hidden_MaxItems = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 5, 6)
+}
+
+func (x *GetStatsResponse) HasItems() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *GetStatsResponse) HasDeleted() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *GetStatsResponse) HasMaxVer() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 2)
+}
+
+func (x *GetStatsResponse) HasTotalBytes() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 3)
+}
+
+func (x *GetStatsResponse) HasLastUpdated() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_LastUpdated != nil
+}
+
+func (x *GetStatsResponse) HasMaxItems() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 5)
+}
+
+func (x *GetStatsResponse) ClearItems() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_Items = 0
+}
+
+func (x *GetStatsResponse) ClearDeleted() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_Deleted = 0
+}
+
+func (x *GetStatsResponse) ClearMaxVer() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 2)
+	x.xxx_hidden_MaxVer = 0
+}
+
+func (x *GetStatsResponse) ClearTotalBytes() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 3)
+	x.xxx_hidden_TotalBytes = 0
+}
+
+func (x *GetStatsResponse) ClearLastUpdated() {
+	x.xxx_hidden_LastUpdated = nil
+}
+
+func (x *GetStatsResponse) ClearMaxItems() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 5)
+	x.xxx_hidden_MaxItems = 0
+}
+
+type GetStatsResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Items       *int64
+	Deleted     *int64
+	MaxVer      *int64
+	TotalBytes  *int64
+	LastUpdated *timestamppb.Timestamp
+	MaxItems    *int64
+}
+
+func (b0 GetStatsResponse_builder) Build() *GetStatsResponse {
+	m0 := &GetStatsResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Items != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 6)
+		x.xxx_hidden_Items = *b.Items
+	}
+	if b.Deleted != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 6)
+		x.xxx_hidden_Deleted = *b.Deleted
+	}
+	if b.MaxVer != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 6)
+		x.xxx_hidden_MaxVer = *b.MaxVer
+	}
+	if b.TotalBytes != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 3, 6)
+		x.xxx_hidden_TotalBytes = *b.TotalBytes
+	}
+	x.xxx_hidden_LastUpdated = b.LastUpdated
+	if b.MaxItems != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 5, 6)
+		x.xxx_hidden_MaxItems = *b.MaxItems
+	}
+	return m0
+}
+
+// Vault checksum: a Merkle tree with one leaf per item (tombstones included),
+// ordered by id. See internal/merkle for the exact hashing.
+type VerifyVaultRequest struct {
+	state                    protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_IncludeLeaves bool                   `protobuf:"varint,1,opt,name=include_leaves,json=includeLeaves"`
+	XXX_raceDetectHookData   protoimpl.RaceDetectHookData
+	XXX_presence             [1]uint32
+	unknownFields            protoimpl.UnknownFields
+	sizeCache                protoimpl.SizeCache
+}
+
+func (x *VerifyVaultRequest) Reset() {
+	*x = VerifyVaultRequest{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyVaultRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyVaultRequest) ProtoMessage() {}
+
+func (x *VerifyVaultRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *VerifyVaultRequest) GetIncludeLeaves() bool {
+	if x != nil {
+		return x.xxx_hidden_IncludeLeaves
+	}
+	return false
+}
+
+func (x *VerifyVaultRequest) SetIncludeLeaves(v bool) {
+	x.xxx_hidden_IncludeLeaves = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 1)
+}
+
+func (x *VerifyVaultRequest) HasIncludeLeaves() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *VerifyVaultRequest) ClearIncludeLeaves() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_IncludeLeaves = false
+}
+
+type VerifyVaultRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	// Also return every leaf, so a client whose root differs can find the items.
+	IncludeLeaves *bool
+}
+
+func (b0 VerifyVaultRequest_builder) Build() *VerifyVaultRequest {
+	m0 := &VerifyVaultRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.IncludeLeaves != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 1)
+		x.xxx_hidden_IncludeLeaves = *b.IncludeLeaves
+	}
+	return m0
+}
+
+type VaultLeaf struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Id          *string                `protobuf:"bytes,1,opt,name=id"`
+	xxx_hidden_Ver         int64                  `protobuf:"varint,2,opt,name=ver"`
+	xxx_hidden_Deleted     bool                   `protobuf:"varint,3,opt,name=deleted"`
+	xxx_hidden_Hash        []byte                 `protobuf:"bytes,4,opt,name=hash"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *VaultLeaf) Reset() {
+	*x = VaultLeaf{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VaultLeaf) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VaultLeaf) ProtoMessage() {}
+
+func (x *VaultLeaf) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *VaultLeaf) GetId() string {
+	if x != nil {
+		if x.xxx_hidden_Id != nil {
+			return *x.xxx_hidden_Id
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *VaultLeaf) GetVer() int64 {
+	if x != nil {
+		return x.xxx_hidden_Ver
+	}
+	return 0
+}
+
+func (x *VaultLeaf) GetDeleted() bool {
+	if x != nil {
+		return x.xxx_hidden_Deleted
+	}
+	return false
+}
+
+func (x *VaultLeaf) GetHash() []byte {
+	if x != nil {
+		return x.xxx_hidden_Hash
+	}
+	return nil
+}
+
+func (x *VaultLeaf) SetId(v string) {
+	x.xxx_hidden_Id = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 4)
+}
+
+func (x *VaultLeaf) SetVer(v int64) {
+	x.xxx_hidden_Ver = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 4)
+}
+
+func (x *VaultLeaf) SetDeleted(v bool) {
+	x.xxx_hidden_Deleted = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 4)
+}
+
+func (x *VaultLeaf) SetHash(v []byte) {
+	if v == nil {
+		v = []byte{}
+	}
+	x.xxx_hidden_Hash = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 3, 4)
+}
+
+func (x *VaultLeaf) HasId() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *VaultLeaf) HasVer() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *VaultLeaf) HasDeleted() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 2)
+}
+
+func (x *VaultLeaf) HasHash() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 3)
+}
+
+func (x *VaultLeaf) ClearId() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_Id = nil
+}
+
+func (x *VaultLeaf) ClearVer() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_Ver = 0
+}
+
+func (x *VaultLeaf) ClearDeleted() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 2)
+	x.xxx_hidden_Deleted = false
+}
+
+func (x *VaultLeaf) ClearHash() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 3)
+	x.xxx_hidden_Hash = nil
+}
+
+type VaultLeaf_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Id      *string
+	Ver     *int64
+	Deleted *bool
+	Hash    []byte
+}
+
+func (b0 VaultLeaf_builder) Build() *VaultLeaf {
+	m0 := &VaultLeaf{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Id != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 4)
+		x.xxx_hidden_Id = b.Id
+	}
+	if b.Ver != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 4)
+		x.xxx_hidden_Ver = *b.Ver
+	}
+	if b.Deleted != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 4)
+		x.xxx_hidden_Deleted = *b.Deleted
+	}
+	if b.Hash != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 3, 4)
+		x.xxx_hidden_Hash = b.Hash
+	}
+	return m0
+}
+
+type VerifyVaultResponse struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Root        []byte                 `protobuf:"bytes,1,opt,name=root"`
+	xxx_hidden_Items       int64                  `protobuf:"varint,2,opt,name=items"`
+	xxx_hidden_Leaves      *[]*VaultLeaf          `protobuf:"bytes,3,rep,name=leaves"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *VerifyVaultResponse) Reset() {
+	*x = VerifyVaultResponse{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyVaultResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyVaultResponse) ProtoMessage() {}
+
+func (x *VerifyVaultResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *VerifyVaultResponse) GetRoot() []byte {
+	if x != nil {
+		return x.xxx_hidden_Root
+	}
+	return nil
+}
+
+func (x *VerifyVaultResponse) GetItems() int64 {
+	if x != nil {
+		return x.xxx_hidden_Items
+	}
+	return 0
+}
+
+func (x *VerifyVaultResponse) GetLeaves() []*VaultLeaf {
+	if x != nil {
+		if x.xxx_hidden_Leaves != nil {
+			return *x.xxx_hidden_Leaves
+		}
+	}
+	return nil
+}
+
+func (x *VerifyVaultResponse) SetRoot(v []byte) {
+	if v == nil {
+		v = []byte{}
+	}
+	x.xxx_hidden_Root = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 3)
+}
+
+func (x *VerifyVaultResponse) SetItems(v int64) {
+	x.xxx_hidden_Items = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 3)
+}
+
+func (x *VerifyVaultResponse) SetLeaves(v []*VaultLeaf) {
+	x.xxx_hidden_Leaves = &v
+}
+
+func (x *VerifyVaultResponse) HasRoot() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *VerifyVaultResponse) HasItems() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *VerifyVaultResponse) ClearRoot() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_Root = nil
+}
+
+func (x *VerifyVaultResponse) ClearItems() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_Items = 0
+}
+
+type VerifyVaultResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Root   []byte
+	Items  *int64
+	Leaves []*VaultLeaf
+}
+
+func (b0 VerifyVaultResponse_builder) Build() *VerifyVaultResponse {
+	m0 := &VerifyVaultResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Root != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 3)
+		x.xxx_hidden_Root = b.Root
+	}
+	if b.Items != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 3)
+		x.xxx_hidden_Items = *b.Items
+	}
+	x.xxx_hidden_Leaves = &b.Leaves
+	return m0
+}
+
+type SetWrappedDEKRequest struct {
+	state                         protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_WrappedDek         []byte                 `protobuf:"bytes,1,opt,name=wrapped_dek,json=wrappedDek"`
+	xxx_hidden_PreviousWrappedDek []byte                 `protobuf:"bytes,2,opt,name=previous_wrapped_dek,json=previousWrappedDek"`
+	XXX_raceDetectHookData        protoimpl.RaceDetectHookData
+	XXX_presence                  [1]uint32
+	unknownFields                 protoimpl.UnknownFields
+	sizeCache                     protoimpl.SizeCache
+}
+
+func (x *SetWrappedDEKRequest) Reset() {
+	*x = SetWrappedDEKRequest{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetWrappedDEKRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetWrappedDEKRequest) ProtoMessage() {}
+
+func (x *SetWrappedDEKRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *SetWrappedDEKRequest) GetWrappedDek() []byte {
+	if x != nil {
+		return x.xxx_hidden_WrappedDek
+	}
+	return nil
+}
+
+func (x *SetWrappedDEKRequest) GetPreviousWrappedDek() []byte {
+	if x != nil {
+		return x.xxx_hidden_PreviousWrappedDek
+	}
+	return nil
+}
+
+func (x *SetWrappedDEKRequest) SetWrappedDek(v []byte) {
+	if v == nil {
+		v = []byte{}
+	}
+	x.xxx_hidden_WrappedDek = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 2)
+}
+
+func (x *SetWrappedDEKRequest) SetPreviousWrappedDek(v []byte) {
+	if v == nil {
+		v = []byte{}
+	}
+	x.xxx_hidden_PreviousWrappedDek = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 2)
+}
+
+func (x *SetWrappedDEKRequest) HasWrappedDek() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *SetWrappedDEKRequest) HasPreviousWrappedDek() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *SetWrappedDEKRequest) ClearWrappedDek() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_WrappedDek = nil
+}
+
+func (x *SetWrappedDEKRequest) ClearPreviousWrappedDek() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_PreviousWrappedDek = nil
+}
+
+type SetWrappedDEKRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	WrappedDek []byte
+	// When set, replaces the stored wrapped DEK only if it still equals this
+	// value (re-wrapping after a password or KDF change). When unset, the DEK
+	// can only be set once.
+	PreviousWrappedDek []byte
+}
+
+func (b0 SetWrappedDEKRequest_builder) Build() *SetWrappedDEKRequest {
+	m0 := &SetWrappedDEKRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.WrappedDek != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 2)
+		x.xxx_hidden_WrappedDek = b.WrappedDek
+	}
+	if b.PreviousWrappedDek != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 2)
+		x.xxx_hidden_PreviousWrappedDek = b.PreviousWrappedDek
+	}
+	return m0
+}
+
+type SetWrappedDEKResponse struct {
+	state         protoimpl.MessageState `protogen:"opaque.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetWrappedDEKResponse) Reset() {
+	*x = SetWrappedDEKResponse{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetWrappedDEKResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetWrappedDEKResponse) ProtoMessage() {}
+
+func (x *SetWrappedDEKResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+type SetWrappedDEKResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+}
+
+func (b0 SetWrappedDEKResponse_builder) Build() *SetWrappedDEKResponse {
+	m0 := &SetWrappedDEKResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	return m0
+}
+
+// A URL notified when the owner's vault changes.
+type Webhook struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Id          *string                `protobuf:"bytes,1,opt,name=id"`
+	xxx_hidden_Url         *string                `protobuf:"bytes,2,opt,name=url"`
+	xxx_hidden_CreatedAt   *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_at,json=createdAt"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *Webhook) Reset() {
+	*x = Webhook{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Webhook) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Webhook) ProtoMessage() {}
+
+func (x *Webhook) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *Webhook) GetId() string {
+	if x != nil {
+		if x.xxx_hidden_Id != nil {
+			return *x.xxx_hidden_Id
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *Webhook) GetUrl() string {
+	if x != nil {
+		if x.xxx_hidden_Url != nil {
+			return *x.xxx_hidden_Url
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *Webhook) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.xxx_hidden_CreatedAt
+	}
+	return nil
+}
+
+func (x *Webhook) SetId(v string) {
+	x.xxx_hidden_Id = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 3)
+}
+
+func (x *Webhook) SetUrl(v string) {
+	x.xxx_hidden_Url = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 3)
+}
+
+func (x *Webhook) SetCreatedAt(v *timestamppb.Timestamp) {
+	x.xxx_hidden_CreatedAt = v
+}
+
+func (x *Webhook) HasId() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *Webhook) HasUrl() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *Webhook) HasCreatedAt() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_CreatedAt != nil
+}
+
+func (x *Webhook) ClearId() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_Id = nil
+}
+
+func (x *Webhook) ClearUrl() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_Url = nil
+}
+
+func (x *Webhook) ClearCreatedAt() {
+	x.xxx_hidden_CreatedAt = nil
+}
+
+type Webhook_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Id        *string
+	Url       *string
+	CreatedAt *timestamppb.Timestamp
+}
+
+func (b0 Webhook_builder) Build() *Webhook {
+	m0 := &Webhook{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Id != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 3)
+		x.xxx_hidden_Id = b.Id
+	}
+	if b.Url != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 3)
+		x.xxx_hidden_Url = b.Url
+	}
+	x.xxx_hidden_CreatedAt = b.CreatedAt
+	return m0
+}
+
+type CreateWebhookRequest struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Url         *string                `protobuf:"bytes,1,opt,name=url"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *CreateWebhookRequest) Reset() {
+	*x = CreateWebhookRequest{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateWebhookRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateWebhookRequest) ProtoMessage() {}
+
+func (x *CreateWebhookRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *CreateWebhookRequest) GetUrl() string {
+	if x != nil {
+		if x.xxx_hidden_Url != nil {
+			return *x.xxx_hidden_Url
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *CreateWebhookRequest) SetUrl(v string) {
+	x.xxx_hidden_Url = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 1)
+}
+
+func (x *CreateWebhookRequest) HasUrl() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *CreateWebhookRequest) ClearUrl() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_Url = nil
+}
+
+type CreateWebhookRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	// https:// URL that receives POSTed change notifications.
+	Url *string
+}
+
+func (b0 CreateWebhookRequest_builder) Build() *CreateWebhookRequest {
+	m0 := &CreateWebhookRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Url != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 1)
+		x.xxx_hidden_Url = b.Url
+	}
+	return m0
+}
+
+type CreateWebhookResponse struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Webhook     *Webhook               `protobuf:"bytes,1,opt,name=webhook"`
+	xxx_hidden_Secret      *string                `protobuf:"bytes,2,opt,name=secret"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *CreateWebhookResponse) Reset() {
+	*x = CreateWebhookResponse{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateWebhookResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateWebhookResponse) ProtoMessage() {}
+
+func (x *CreateWebhookResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *CreateWebhookResponse) GetWebhook() *Webhook {
+	if x != nil {
+		return x.xxx_hidden_Webhook
+	}
+	return nil
+}
+
+func (x *CreateWebhookResponse) GetSecret() string {
+	if x != nil {
+		if x.xxx_hidden_Secret != nil {
+			return *x.xxx_hidden_Secret
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *CreateWebhookResponse) SetWebhook(v *Webhook) {
+	x.xxx_hidden_Webhook = v
+}
+
+func (x *CreateWebhookResponse) SetSecret(v string) {
+	x.xxx_hidden_Secret = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 2)
+}
+
+func (x *CreateWebhookResponse) HasWebhook() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_Webhook != nil
+}
+
+func (x *CreateWebhookResponse) HasSecret() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *CreateWebhookResponse) ClearWebhook() {
+	x.xxx_hidden_Webhook = nil
+}
+
+func (x *CreateWebhookResponse) ClearSecret() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_Secret = nil
+}
+
+type CreateWebhookResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Webhook *Webhook
+	// HMAC-SHA256 key for the X-GophKeeper-Signature header. Returned only here.
+	Secret *string
+}
+
+func (b0 CreateWebhookResponse_builder) Build() *CreateWebhookResponse {
+	m0 := &CreateWebhookResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_Webhook = b.Webhook
+	if b.Secret != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 2)
+		x.xxx_hidden_Secret = b.Secret
+	}
+	return m0
+}
+
+type ListWebhooksRequest struct {
+	state         protoimpl.MessageState `protogen:"opaque.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListWebhooksRequest) Reset() {
+	*x = ListWebhooksRequest{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListWebhooksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListWebhooksRequest) ProtoMessage() {}
+
+func (x *ListWebhooksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+type ListWebhooksRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+}
+
+func (b0 ListWebhooksRequest_builder) Build() *ListWebhooksRequest {
+	m0 := &ListWebhooksRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	return m0
+}
+
+type ListWebhooksResponse struct {
+	state               protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Webhooks *[]*Webhook            `protobuf:"bytes,1,rep,name=webhooks"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *ListWebhooksResponse) Reset() {
+	*x = ListWebhooksResponse{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListWebhooksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListWebhooksResponse) ProtoMessage() {}
+
+func (x *ListWebhooksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *ListWebhooksResponse) GetWebhooks() []*Webhook {
+	if x != nil {
+		if x.xxx_hidden_Webhooks != nil {
+			return *x.xxx_hidden_Webhooks
+		}
+	}
+	return nil
+}
+
+func (x *ListWebhooksResponse) SetWebhooks(v []*Webhook) {
+	x.xxx_hidden_Webhooks = &v
+}
+
+type ListWebhooksResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Webhooks []*Webhook
+}
+
+func (b0 ListWebhooksResponse_builder) Build() *ListWebhooksResponse {
+	m0 := &ListWebhooksResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_Webhooks = &b.Webhooks
+	return m0
+}
+
+type DeleteWebhookRequest struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Id          *string                `protobuf:"bytes,1,opt,name=id"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *DeleteWebhookRequest) Reset() {
+	*x = DeleteWebhookRequest{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteWebhookRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteWebhookRequest) ProtoMessage() {}
+
+func (x *DeleteWebhookRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *DeleteWebhookRequest) GetId() string {
+	if x != nil {
+		if x.xxx_hidden_Id != nil {
+			return *x.xxx_hidden_Id
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *DeleteWebhookRequest) SetId(v string) {
+	x.xxx_hidden_Id = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 1)
+}
+
+func (x *DeleteWebhookRequest) HasId() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *DeleteWebhookRequest) ClearId() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_Id = nil
+}
+
+type DeleteWebhookRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Id *string
+}
+
+func (b0 DeleteWebhookRequest_builder) Build() *DeleteWebhookRequest {
+	m0 := &DeleteWebhookRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Id != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 1)
+		x.xxx_hidden_Id = b.Id
+	}
+	return m0
+}
+
+type DeleteWebhookResponse struct {
+	state         protoimpl.MessageState `protogen:"opaque.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteWebhookResponse) Reset() {
+	*x = DeleteWebhookResponse{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteWebhookResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteWebhookResponse) ProtoMessage() {}
+
+func (x *DeleteWebhookResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+type DeleteWebhookResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+}
+
+func (b0 DeleteWebhookResponse_builder) Build() *DeleteWebhookResponse {
+	m0 := &DeleteWebhookResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	return m0
+}
+
+// A client device woken by a push notification when the vault changes.
+type Device struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Id          *string                `protobuf:"bytes,1,opt,name=id"`
+	xxx_hidden_Platform    *string                `protobuf:"bytes,2,opt,name=platform"`
+	xxx_hidden_Name        *string                `protobuf:"bytes,3,opt,name=name"`
+	xxx_hidden_CreatedAt   *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *Device) Reset() {
+	*x = Device{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Device) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Device) ProtoMessage() {}
+
+func (x *Device) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *Device) GetId() string {
+	if x != nil {
+		if x.xxx_hidden_Id != nil {
+			return *x.xxx_hidden_Id
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *Device) GetPlatform() string {
+	if x != nil {
+		if x.xxx_hidden_Platform != nil {
+			return *x.xxx_hidden_Platform
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *Device) GetName() string {
+	if x != nil {
+		if x.xxx_hidden_Name != nil {
+			return *x.xxx_hidden_Name
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *Device) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.xxx_hidden_CreatedAt
+	}
+	return nil
+}
+
+func (x *Device) SetId(v string) {
+	x.xxx_hidden_Id = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 4)
+}
+
+func (x *Device) SetPlatform(v string) {
+	x.xxx_hidden_Platform = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 4)
+}
+
+func (x *Device) SetName(v string) {
+	x.xxx_hidden_Name = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 4)
+}
+
+func (x *Device) SetCreatedAt(v *timestamppb.Timestamp) {
+	x.xxx_hidden_CreatedAt = v
+}
+
+func (x *Device) HasId() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *Device) HasPlatform() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *Device) HasName() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 2)
+}
+
+func (x *Device) HasCreatedAt() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_CreatedAt != nil
+}
+
+func (x *Device) ClearId() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_Id = nil
+}
+
+func (x *Device) ClearPlatform() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_Platform = nil
+}
+
+func (x *Device) ClearName() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 2)
+	x.xxx_hidden_Name = nil
+}
+
+func (x *Device) ClearCreatedAt() {
+	x.xxx_hidden_CreatedAt = nil
+}
+
+type Device_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Id *string
+	// fcm, apns or ntfy.
+	Platform *string
+	// Human-readable label, e.g. "work laptop".
+	Name      *string
+	CreatedAt *timestamppb.Timestamp
+}
+
+func (b0 Device_builder) Build() *Device {
+	m0 := &Device{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Id != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 4)
+		x.xxx_hidden_Id = b.Id
+	}
+	if b.Platform != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 4)
+		x.xxx_hidden_Platform = b.Platform
+	}
+	if b.Name != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 4)
+		x.xxx_hidden_Name = b.Name
+	}
+	x.xxx_hidden_CreatedAt = b.CreatedAt
+	return m0
+}
+
+type RegisterDeviceRequest struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Platform    *string                `protobuf:"bytes,1,opt,name=platform"`
+	xxx_hidden_Token       *string                `protobuf:"bytes,2,opt,name=token"`
+	xxx_hidden_Name        *string                `protobuf:"bytes,3,opt,name=name"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *RegisterDeviceRequest) Reset() {
+	*x = RegisterDeviceRequest{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterDeviceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterDeviceRequest) ProtoMessage() {}
+
+func (x *RegisterDeviceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *RegisterDeviceRequest) GetPlatform() string {
+	if x != nil {
+		if x.xxx_hidden_Platform != nil {
+			return *x.xxx_hidden_Platform
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *RegisterDeviceRequest) GetToken() string {
+	if x != nil {
+		if x.xxx_hidden_Token != nil {
+			return *x.xxx_hidden_Token
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *RegisterDeviceRequest) GetName() string {
+	if x != nil {
+		if x.xxx_hidden_Name != nil {
+			return *x.xxx_hidden_Name
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *RegisterDeviceRequest) SetPlatform(v string) {
+	x.xxx_hidden_Platform = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 3)
+}
+
+func (x *RegisterDeviceRequest) SetToken(v string) {
+	x.xxx_hidden_Token = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 3)
+}
+
+func (x *RegisterDeviceRequest) SetName(v string) {
+	x.xxx_hidden_Name = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 3)
+}
+
+func (x *RegisterDeviceRequest) HasPlatform() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *RegisterDeviceRequest) HasToken() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *RegisterDeviceRequest) HasName() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 2)
+}
+
+func (x *RegisterDeviceRequest) ClearPlatform() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_Platform = nil
+}
+
+func (x *RegisterDeviceRequest) ClearToken() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_Token = nil
+}
+
+func (x *RegisterDeviceRequest) ClearName() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 2)
+	x.xxx_hidden_Name = nil
+}
+
+type RegisterDeviceRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	// fcm, apns or ntfy; the server must have the platform configured.
+	Platform *string
+	// FCM registration token, APNs device token (hex) or ntfy topic name.
+	Token *string
+	Name  *string
+}
+
+func (b0 RegisterDeviceRequest_builder) Build() *RegisterDeviceRequest {
+	m0 := &RegisterDeviceRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Platform != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 3)
+		x.xxx_hidden_Platform = b.Platform
+	}
+	if b.Token != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 3)
+		x.xxx_hidden_Token = b.Token
+	}
+	if b.Name != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 3)
+		x.xxx_hidden_Name = b.Name
+	}
+	return m0
+}
+
+type RegisterDeviceResponse struct {
+	state             protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Device *Device                `protobuf:"bytes,1,opt,name=device"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *RegisterDeviceResponse) Reset() {
+	*x = RegisterDeviceResponse{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterDeviceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterDeviceResponse) ProtoMessage() {}
+
+func (x *RegisterDeviceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *RegisterDeviceResponse) GetDevice() *Device {
+	if x != nil {
+		return x.xxx_hidden_Device
+	}
+	return nil
+}
+
+func (x *RegisterDeviceResponse) SetDevice(v *Device) {
+	x.xxx_hidden_Device = v
+}
+
+func (x *RegisterDeviceResponse) HasDevice() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_Device != nil
+}
+
+func (x *RegisterDeviceResponse) ClearDevice() {
+	x.xxx_hidden_Device = nil
+}
+
+type RegisterDeviceResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	// Registering the same platform and token again returns the same device.
+	Device *Device
+}
+
+func (b0 RegisterDeviceResponse_builder) Build() *RegisterDeviceResponse {
+	m0 := &RegisterDeviceResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_Device = b.Device
+	return m0
+}
+
+type ListDevicesRequest struct {
+	state         protoimpl.MessageState `protogen:"opaque.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDevicesRequest) Reset() {
+	*x = ListDevicesRequest{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDevicesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDevicesRequest) ProtoMessage() {}
+
+func (x *ListDevicesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+type ListDevicesRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+}
+
+func (b0 ListDevicesRequest_builder) Build() *ListDevicesRequest {
+	m0 := &ListDevicesRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	return m0
+}
+
+type ListDevicesResponse struct {
+	state                protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Devices   *[]*Device             `protobuf:"bytes,1,rep,name=devices"`
+	xxx_hidden_Platforms []string               `protobuf:"bytes,2,rep,name=platforms"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *ListDevicesResponse) Reset() {
+	*x = ListDevicesResponse{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDevicesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDevicesResponse) ProtoMessage() {}
+
+func (x *ListDevicesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *ListDevicesResponse) GetDevices() []*Device {
+	if x != nil {
+		if x.xxx_hidden_Devices != nil {
+			return *x.xxx_hidden_Devices
+		}
+	}
+	return nil
+}
+
+func (x *ListDevicesResponse) GetPlatforms() []string {
+	if x != nil {
+		return x.xxx_hidden_Platforms
+	}
+	return nil
+}
+
+func (x *ListDevicesResponse) SetDevices(v []*Device) {
+	x.xxx_hidden_Devices = &v
+}
+
+func (x *ListDevicesResponse) SetPlatforms(v []string) {
+	x.xxx_hidden_Platforms = v
+}
+
+type ListDevicesResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Devices []*Device
+	// Platforms this server can push to.
+	Platforms []string
+}
+
+func (b0 ListDevicesResponse_builder) Build() *ListDevicesResponse {
+	m0 := &ListDevicesResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_Devices = &b.Devices
+	x.xxx_hidden_Platforms = b.Platforms
+	return m0
+}
+
+type UnregisterDeviceRequest struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Id          *string                `protobuf:"bytes,1,opt,name=id"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *UnregisterDeviceRequest) Reset() {
+	*x = UnregisterDeviceRequest{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnregisterDeviceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnregisterDeviceRequest) ProtoMessage() {}
+
+func (x *UnregisterDeviceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *UnregisterDeviceRequest) GetId() string {
+	if x != nil {
+		if x.xxx_hidden_Id != nil {
+			return *x.xxx_hidden_Id
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *UnregisterDeviceRequest) SetId(v string) {
+	x.xxx_hidden_Id = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 1)
+}
+
+func (x *UnregisterDeviceRequest) HasId() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *UnregisterDeviceRequest) ClearId() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_Id = nil
+}
+
+type UnregisterDeviceRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Id *string
+}
+
+func (b0 UnregisterDeviceRequest_builder) Build() *UnregisterDeviceRequest {
+	m0 := &UnregisterDeviceRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Id != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 1)
+		x.xxx_hidden_Id = b.Id
+	}
+	return m0
+}
+
+type UnregisterDeviceResponse struct {
+	state         protoimpl.MessageState `protogen:"opaque.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnregisterDeviceResponse) Reset() {
+	*x = UnregisterDeviceResponse{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnregisterDeviceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnregisterDeviceResponse) ProtoMessage() {}
+
+func (x *UnregisterDeviceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+type UnregisterDeviceResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+}
+
+func (b0 UnregisterDeviceResponse_builder) Build() *UnregisterDeviceResponse {
+	m0 := &UnregisterDeviceResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	return m0
+}
+
+type GetLoginHistoryRequest struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Limit       int32                  `protobuf:"varint,1,opt,name=limit"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *GetLoginHistoryRequest) Reset() {
+	*x = GetLoginHistoryRequest{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLoginHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLoginHistoryRequest) ProtoMessage() {}
+
+func (x *GetLoginHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *GetLoginHistoryRequest) GetLimit() int32 {
+	if x != nil {
+		return x.xxx_hidden_Limit
+	}
+	return 0
+}
+
+func (x *GetLoginHistoryRequest) SetLimit(v int32) {
+	x.xxx_hidden_Limit = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 1)
+}
+
+func (x *GetLoginHistoryRequest) HasLimit() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *GetLoginHistoryRequest) ClearLimit() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_Limit = 0
+}
+
+type GetLoginHistoryRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	// Newest first, at most this many; 0 means 20. The server keeps the last 100.
+	Limit *int32
+}
+
+func (b0 GetLoginHistoryRequest_builder) Build() *GetLoginHistoryRequest {
+	m0 := &GetLoginHistoryRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Limit != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 1)
+		x.xxx_hidden_Limit = *b.Limit
+	}
+	return m0
+}
+
+// One password check against the caller's account.
+type LoginAttempt struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_At          *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=at"`
+	xxx_hidden_IpHash      []byte                 `protobuf:"bytes,2,opt,name=ip_hash,json=ipHash"`
+	xxx_hidden_Success     bool                   `protobuf:"varint,3,opt,name=success"`
+	xxx_hidden_Country     *string                `protobuf:"bytes,4,opt,name=country"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *LoginAttempt) Reset() {
+	*x = LoginAttempt{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[53]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LoginAttempt) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoginAttempt) ProtoMessage() {}
+
+func (x *LoginAttempt) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[53]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *LoginAttempt) GetAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.xxx_hidden_At
+	}
+	return nil
+}
+
+func (x *LoginAttempt) GetIpHash() []byte {
+	if x != nil {
+		return x.xxx_hidden_IpHash
+	}
+	return nil
+}
+
+func (x *LoginAttempt) GetSuccess() bool {
+	if x != nil {
+		return x.xxx_hidden_Success
+	}
+	return false
+}
+
+func (x *LoginAttempt) GetCountry() string {
+	if x != nil {
+		if x.xxx_hidden_Country != nil {
+			return *x.xxx_hidden_Country
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *LoginAttempt) SetAt(v *timestamppb.Timestamp) {
+	x.xxx_hidden_At = v
+}
+
+func (x *LoginAttempt) SetIpHash(v []byte) {
+	if v == nil {
+		v = []byte{}
+	}
+	x.xxx_hidden_IpHash = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 4)
+}
+
+func (x *LoginAttempt) SetSuccess(v bool) {
+	x.xxx_hidden_Success = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 4)
+}
+
+func (x *LoginAttempt) SetCountry(v string) {
+	x.xxx_hidden_Country = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 3, 4)
+}
+
+func (x *LoginAttempt) HasAt() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_At != nil
+}
+
+func (x *LoginAttempt) HasIpHash() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *LoginAttempt) HasSuccess() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 2)
+}
+
+func (x *LoginAttempt) HasCountry() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 3)
+}
+
+func (x *LoginAttempt) ClearAt() {
+	x.xxx_hidden_At = nil
+}
+
+func (x *LoginAttempt) ClearIpHash() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_IpHash = nil
+}
+
+func (x *LoginAttempt) ClearSuccess() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 2)
+	x.xxx_hidden_Success = false
+}
+
+func (x *LoginAttempt) ClearCountry() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 3)
+	x.xxx_hidden_Country = nil
+}
+
+type LoginAttempt_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	At *timestamppb.Timestamp
+	// SHA-256 of the client address: equal hashes mean the same address.
+	IpHash  []byte
+	Success *bool
+	// ISO 3166-1 country of the address; empty without a GeoIP database.
+	Country *string
+}
+
+func (b0 LoginAttempt_builder) Build() *LoginAttempt {
+	m0 := &LoginAttempt{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_At = b.At
+	if b.IpHash != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 4)
+		x.xxx_hidden_IpHash = b.IpHash
+	}
+	if b.Success != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 4)
+		x.xxx_hidden_Success = *b.Success
+	}
+	if b.Country != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 3, 4)
+		x.xxx_hidden_Country = b.Country
+	}
+	return m0
+}
+
+type GetLoginHistoryResponse struct {
+	state               protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Attempts *[]*LoginAttempt       `protobuf:"bytes,1,rep,name=attempts"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *GetLoginHistoryResponse) Reset() {
+	*x = GetLoginHistoryResponse{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[54]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLoginHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLoginHistoryResponse) ProtoMessage() {}
+
+func (x *GetLoginHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[54]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *GetLoginHistoryResponse) GetAttempts() []*LoginAttempt {
+	if x != nil {
+		if x.xxx_hidden_Attempts != nil {
+			return *x.xxx_hidden_Attempts
+		}
+	}
+	return nil
+}
+
+func (x *GetLoginHistoryResponse) SetAttempts(v []*LoginAttempt) {
+	x.xxx_hidden_Attempts = &v
+}
+
+type GetLoginHistoryResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Attempts []*LoginAttempt
+}
+
+func (b0 GetLoginHistoryResponse_builder) Build() *GetLoginHistoryResponse {
+	m0 := &GetLoginHistoryResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_Attempts = &b.Attempts
+	return m0
+}
+
+type RefreshRequest struct {
+	state                   protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_RefreshToken *string                `protobuf:"bytes,1,opt,name=refresh_token,json=refreshToken"`
+	XXX_raceDetectHookData  protoimpl.RaceDetectHookData
+	XXX_presence            [1]uint32
+	unknownFields           protoimpl.UnknownFields
+	sizeCache               protoimpl.SizeCache
+}
+
+func (x *RefreshRequest) Reset() {
+	*x = RefreshRequest{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[55]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RefreshRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RefreshRequest) ProtoMessage() {}
+
+func (x *RefreshRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[55]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *RefreshRequest) GetRefreshToken() string {
+	if x != nil {
+		if x.xxx_hidden_RefreshToken != nil {
+			return *x.xxx_hidden_RefreshToken
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *RefreshRequest) SetRefreshToken(v string) {
+	x.xxx_hidden_RefreshToken = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 1)
+}
+
+func (x *RefreshRequest) HasRefreshToken() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *RefreshRequest) ClearRefreshToken() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_RefreshToken = nil
+}
+
+type RefreshRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	RefreshToken *string
+}
+
+func (b0 RefreshRequest_builder) Build() *RefreshRequest {
+	m0 := &RefreshRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.RefreshToken != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 1)
+		x.xxx_hidden_RefreshToken = b.RefreshToken
+	}
+	return m0
+}
+
+type RefreshResponse struct {
+	state                   protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_AccessToken  *string                `protobuf:"bytes,1,opt,name=access_token,json=accessToken"`
+	xxx_hidden_RefreshToken *string                `protobuf:"bytes,2,opt,name=refresh_token,json=refreshToken"`
+	XXX_raceDetectHookData  protoimpl.RaceDetectHookData
+	XXX_presence            [1]uint32
+	unknownFields           protoimpl.UnknownFields
+	sizeCache               protoimpl.SizeCache
+}
+
+func (x *RefreshResponse) Reset() {
+	*x = RefreshResponse{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[56]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RefreshResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RefreshResponse) ProtoMessage() {}
+
+func (x *RefreshResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[56]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *RefreshResponse) GetAccessToken() string {
+	if x != nil {
+		if x.xxx_hidden_AccessToken != nil {
+			return *x.xxx_hidden_AccessToken
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *RefreshResponse) GetRefreshToken() string {
+	if x != nil {
+		if x.xxx_hidden_RefreshToken != nil {
+			return *x.xxx_hidden_RefreshToken
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *RefreshResponse) SetAccessToken(v string) {
+	x.xxx_hidden_AccessToken = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 2)
+}
+
+func (x *RefreshResponse) SetRefreshToken(v string) {
+	x.xxx_hidden_RefreshToken = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 2)
+}
+
+func (x *RefreshResponse) HasAccessToken() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *RefreshResponse) HasRefreshToken() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *RefreshResponse) ClearAccessToken() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_AccessToken = nil
+}
+
+func (x *RefreshResponse) ClearRefreshToken() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_RefreshToken = nil
+}
+
+type RefreshResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	AccessToken *string
+	// Replaces the refresh token sent, which no longer works.
+	RefreshToken *string
+}
+
+func (b0 RefreshResponse_builder) Build() *RefreshResponse {
+	m0 := &RefreshResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.AccessToken != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 2)
+		x.xxx_hidden_AccessToken = b.AccessToken
+	}
+	if b.RefreshToken != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 2)
+		x.xxx_hidden_RefreshToken = b.RefreshToken
+	}
+	return m0
+}
+
+// A device signed in with a refresh token.
+type Session struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Id          *string                `protobuf:"bytes,1,opt,name=id"`
+	xxx_hidden_Label       *string                `protobuf:"bytes,2,opt,name=label"`
+	xxx_hidden_CreatedAt   *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_at,json=createdAt"`
+	xxx_hidden_LastUsedAt  *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=last_used_at,json=lastUsedAt"`
+	xxx_hidden_ExpiresAt   *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=expires_at,json=expiresAt"`
+	xxx_hidden_Current     bool                   `protobuf:"varint,6,opt,name=current"`
+	xxx_hidden_Scopes      []string               `protobuf:"bytes,7,rep,name=scopes"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *Session) Reset() {
+	*x = Session{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[57]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Session) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Session) ProtoMessage() {}
+
+func (x *Session) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[57]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *Session) GetId() string {
+	if x != nil {
+		if x.xxx_hidden_Id != nil {
+			return *x.xxx_hidden_Id
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *Session) GetLabel() string {
+	if x != nil {
+		if x.xxx_hidden_Label != nil {
+			return *x.xxx_hidden_Label
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *Session) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.xxx_hidden_CreatedAt
+	}
+	return nil
+}
+
+func (x *Session) GetLastUsedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.xxx_hidden_LastUsedAt
+	}
+	return nil
+}
+
+func (x *Session) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.xxx_hidden_ExpiresAt
+	}
+	return nil
+}
+
+func (x *Session) GetCurrent() bool {
+	if x != nil {
+		return x.xxx_hidden_Current
+	}
+	return false
+}
+
+func (x *Session) GetScopes() []string {
+	if x != nil {
+		return x.xxx_hidden_Scopes
+	}
+	return nil
+}
+
+func (x *Session) SetId(v string) {
+	x.xxx_hidden_Id = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 7)
+}
+
+func (x *Session) SetLabel(v string) {
+	x.xxx_hidden_Label = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 7)
+}
+
+func (x *Session) SetCreatedAt(v *timestamppb.Timestamp) {
+	x.xxx_hidden_CreatedAt = v
+}
+
+func (x *Session) SetLastUsedAt(v *timestamppb.Timestamp) {
+	x.xxx_hidden_LastUsedAt = v
+}
+
+func (x *Session) SetExpiresAt(v *timestamppb.Timestamp) {
+	x.xxx_hidden_ExpiresAt = v
+}
+
+func (x *Session) SetCurrent(v bool) {
+	x.xxx_hidden_Current = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 5, 7)
+}
+
+func (x *Session) SetScopes(v []string) {
+	x.xxx_hidden_Scopes = v
+}
+
+func (x *Session) HasId() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *Session) HasLabel() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *Session) HasCreatedAt() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_CreatedAt != nil
+}
+
+func (x *Session) HasLastUsedAt() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_LastUsedAt != nil
+}
+
+func (x *Session) HasExpiresAt() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_ExpiresAt != nil
+}
+
+func (x *Session) HasCurrent() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 5)
+}
+
+func (x *Session) ClearId() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_Id = nil
+}
+
+func (x *Session) ClearLabel() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_Label = nil
+}
+
+func (x *Session) ClearCreatedAt() {
+	x.xxx_hidden_CreatedAt = nil
+}
+
+func (x *Session) ClearLastUsedAt() {
+	x.xxx_hidden_LastUsedAt = nil
+}
+
+func (x *Session) ClearExpiresAt() {
+	x.xxx_hidden_ExpiresAt = nil
+}
+
+func (x *Session) ClearCurrent() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 5)
+	x.xxx_hidden_Current = false
+}
+
+type Session_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Id *string
+	// device_label given at login.
+	Label     *string
+	CreatedAt *timestamppb.Timestamp
+	// Last login or refresh.
+	LastUsedAt *timestamppb.Timestamp
+	// Unless refreshed before then.
+	ExpiresAt *timestamppb.Timestamp
+	// The session of the token this call was made with.
+	Current *bool
+	// Scopes its tokens are limited to; empty when they are not.
+	Scopes []string
+}
+
+func (b0 Session_builder) Build() *Session {
+	m0 := &Session{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Id != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 7)
+		x.xxx_hidden_Id = b.Id
+	}
+	if b.Label != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 7)
+		x.xxx_hidden_Label = b.Label
+	}
+	x.xxx_hidden_CreatedAt = b.CreatedAt
+	x.xxx_hidden_LastUsedAt = b.LastUsedAt
+	x.xxx_hidden_ExpiresAt = b.ExpiresAt
+	if b.Current != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 5, 7)
+		x.xxx_hidden_Current = *b.Current
+	}
+	x.xxx_hidden_Scopes = b.Scopes
+	return m0
+}
+
+type ListSessionsRequest struct {
+	state         protoimpl.MessageState `protogen:"opaque.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSessionsRequest) Reset() {
+	*x = ListSessionsRequest{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[58]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSessionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSessionsRequest) ProtoMessage() {}
+
+func (x *ListSessionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[58]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+type ListSessionsRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+}
+
+func (b0 ListSessionsRequest_builder) Build() *ListSessionsRequest {
+	m0 := &ListSessionsRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	return m0
+}
+
+type ListSessionsResponse struct {
+	state               protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Sessions *[]*Session            `protobuf:"bytes,1,rep,name=sessions"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *ListSessionsResponse) Reset() {
+	*x = ListSessionsResponse{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[59]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSessionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSessionsResponse) ProtoMessage() {}
+
+func (x *ListSessionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[59]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *ListSessionsResponse) GetSessions() []*Session {
+	if x != nil {
+		if x.xxx_hidden_Sessions != nil {
+			return *x.xxx_hidden_Sessions
+		}
+	}
+	return nil
+}
+
+func (x *ListSessionsResponse) SetSessions(v []*Session) {
+	x.xxx_hidden_Sessions = &v
+}
+
+type ListSessionsResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	// Most recently used first.
+	Sessions []*Session
+}
+
+func (b0 ListSessionsResponse_builder) Build() *ListSessionsResponse {
+	m0 := &ListSessionsResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_Sessions = &b.Sessions
+	return m0
+}
+
+type RevokeSessionRequest struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Id          *string                `protobuf:"bytes,1,opt,name=id"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *RevokeSessionRequest) Reset() {
+	*x = RevokeSessionRequest{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[60]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeSessionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeSessionRequest) ProtoMessage() {}
+
+func (x *RevokeSessionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[60]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *RevokeSessionRequest) GetId() string {
+	if x != nil {
+		if x.xxx_hidden_Id != nil {
+			return *x.xxx_hidden_Id
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *RevokeSessionRequest) SetId(v string) {
+	x.xxx_hidden_Id = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 1)
+}
+
+func (x *RevokeSessionRequest) HasId() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *RevokeSessionRequest) ClearId() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_Id = nil
+}
+
+type RevokeSessionRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Id *string
+}
+
+func (b0 RevokeSessionRequest_builder) Build() *RevokeSessionRequest {
+	m0 := &RevokeSessionRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Id != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 1)
+		x.xxx_hidden_Id = b.Id
+	}
+	return m0
+}
+
+type RevokeSessionResponse struct {
+	state         protoimpl.MessageState `protogen:"opaque.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeSessionResponse) Reset() {
+	*x = RevokeSessionResponse{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[61]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeSessionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeSessionResponse) ProtoMessage() {}
+
+func (x *RevokeSessionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[61]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+type RevokeSessionResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+}
+
+func (b0 RevokeSessionResponse_builder) Build() *RevokeSessionResponse {
+	m0 := &RevokeSessionResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	return m0
+}
+
+// One session's reads of an item.
+type ItemAccess struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_SessionId   *string                `protobuf:"bytes,1,opt,name=session_id,json=sessionId"`
+	xxx_hidden_Label       *string                `protobuf:"bytes,2,opt,name=label"`
+	xxx_hidden_FirstAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=first_at,json=firstAt"`
+	xxx_hidden_LastAt      *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=last_at,json=lastAt"`
+	xxx_hidden_Reads       int64                  `protobuf:"varint,5,opt,name=reads"`
+	xxx_hidden_Current     bool                   `protobuf:"varint,6,opt,name=current"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *ItemAccess) Reset() {
+	*x = ItemAccess{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[62]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ItemAccess) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ItemAccess) ProtoMessage() {}
+
+func (x *ItemAccess) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[62]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *ItemAccess) GetSessionId() string {
+	if x != nil {
+		if x.xxx_hidden_SessionId != nil {
+			return *x.xxx_hidden_SessionId
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *ItemAccess) GetLabel() string {
+	if x != nil {
+		if x.xxx_hidden_Label != nil {
+			return *x.xxx_hidden_Label
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *ItemAccess) GetFirstAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.xxx_hidden_FirstAt
+	}
+	return nil
+}
+
+func (x *ItemAccess) GetLastAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.xxx_hidden_LastAt
+	}
+	return nil
+}
+
+func (x *ItemAccess) GetReads() int64 {
+	if x != nil {
+		return x.xxx_hidden_Reads
+	}
+	return 0
+}
+
+func (x *ItemAccess) GetCurrent() bool {
+	if x != nil {
+		return x.xxx_hidden_Current
+	}
+	return false
+}
+
+func (x *ItemAccess) SetSessionId(v string) {
+	x.xxx_hidden_SessionId = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 6)
+}
+
+func (x *ItemAccess) SetLabel(v string) {
+	x.xxx_hidden_Label = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 6)
+}
+
+func (x *ItemAccess) SetFirstAt(v *timestamppb.Timestamp) {
+	x.xxx_hidden_FirstAt = v
+}
+
+func (x *ItemAccess) SetLastAt(v *timestamppb.Timestamp) {
+	x.xxx_hidden_LastAt = v
+}
+
+func (x *ItemAccess) SetReads(v int64) {
+	x.xxx_hidden_Reads = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 4, 6)
+}
+
+func (x *ItemAccess) SetCurrent(v bool) {
+	x.xxx_hidden_Current = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 5, 6)
+}
+
+func (x *ItemAccess) HasSessionId() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *ItemAccess) HasLabel() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *ItemAccess) HasFirstAt() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_FirstAt != nil
+}
+
+func (x *ItemAccess) HasLastAt() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_LastAt != nil
+}
+
+func (x *ItemAccess) HasReads() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 4)
+}
+
+func (x *ItemAccess) HasCurrent() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 5)
+}
+
+func (x *ItemAccess) ClearSessionId() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_SessionId = nil
+}
+
+func (x *ItemAccess) ClearLabel() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_Label = nil
+}
+
+func (x *ItemAccess) ClearFirstAt() {
+	x.xxx_hidden_FirstAt = nil
+}
+
+func (x *ItemAccess) ClearLastAt() {
+	x.xxx_hidden_LastAt = nil
+}
+
+func (x *ItemAccess) ClearReads() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 4)
+	x.xxx_hidden_Reads = 0
+}
+
+func (x *ItemAccess) ClearCurrent() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 5)
+	x.xxx_hidden_Current = false
+}
+
+type ItemAccess_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	// Empty for tokens issued without a session.
+	SessionId *string
+	// The session's device_label when it first read the item; kept after
+	// the session ends.
+	Label   *string
+	FirstAt *timestamppb.Timestamp
+	LastAt  *timestamppb.Timestamp
+	Reads   *int64
+	// The session of the token this call was made with.
+	Current *bool
+}
+
+func (b0 ItemAccess_builder) Build() *ItemAccess {
+	m0 := &ItemAccess{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.SessionId != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 6)
+		x.xxx_hidden_SessionId = b.SessionId
+	}
+	if b.Label != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 6)
+		x.xxx_hidden_Label = b.Label
+	}
+	x.xxx_hidden_FirstAt = b.FirstAt
+	x.xxx_hidden_LastAt = b.LastAt
+	if b.Reads != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 4, 6)
+		x.xxx_hidden_Reads = *b.Reads
+	}
+	if b.Current != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 5, 6)
+		x.xxx_hidden_Current = *b.Current
+	}
+	return m0
+}
+
+type GetItemAccessLogRequest struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Id          *string                `protobuf:"bytes,1,opt,name=id"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *GetItemAccessLogRequest) Reset() {
+	*x = GetItemAccessLogRequest{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[63]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetItemAccessLogRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetItemAccessLogRequest) ProtoMessage() {}
+
+func (x *GetItemAccessLogRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[63]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *GetItemAccessLogRequest) GetId() string {
+	if x != nil {
+		if x.xxx_hidden_Id != nil {
+			return *x.xxx_hidden_Id
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *GetItemAccessLogRequest) SetId(v string) {
+	x.xxx_hidden_Id = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 1)
+}
+
+func (x *GetItemAccessLogRequest) HasId() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *GetItemAccessLogRequest) ClearId() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_Id = nil
+}
+
+type GetItemAccessLogRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Id *string
+}
+
+func (b0 GetItemAccessLogRequest_builder) Build() *GetItemAccessLogRequest {
+	m0 := &GetItemAccessLogRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Id != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 1)
+		x.xxx_hidden_Id = b.Id
+	}
+	return m0
+}
+
+type GetItemAccessLogResponse struct {
+	state               protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Accesses *[]*ItemAccess         `protobuf:"bytes,1,rep,name=accesses"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *GetItemAccessLogResponse) Reset() {
+	*x = GetItemAccessLogResponse{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[64]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetItemAccessLogResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetItemAccessLogResponse) ProtoMessage() {}
+
+func (x *GetItemAccessLogResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[64]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *GetItemAccessLogResponse) GetAccesses() []*ItemAccess {
+	if x != nil {
+		if x.xxx_hidden_Accesses != nil {
+			return *x.xxx_hidden_Accesses
+		}
+	}
+	return nil
+}
+
+func (x *GetItemAccessLogResponse) SetAccesses(v []*ItemAccess) {
+	x.xxx_hidden_Accesses = &v
+}
+
+type GetItemAccessLogResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	// Most recent reader first.
+	Accesses []*ItemAccess
+}
+
+func (b0 GetItemAccessLogResponse_builder) Build() *GetItemAccessLogResponse {
+	m0 := &GetItemAccessLogResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_Accesses = &b.Accesses
+	return m0
+}
+
+type FreezeVaultRequest struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Password    *string                `protobuf:"bytes,1,opt,name=password"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *FreezeVaultRequest) Reset() {
+	*x = FreezeVaultRequest{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[65]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FreezeVaultRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FreezeVaultRequest) ProtoMessage() {}
+
+func (x *FreezeVaultRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[65]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *FreezeVaultRequest) GetPassword() string {
+	if x != nil {
+		if x.xxx_hidden_Password != nil {
+			return *x.xxx_hidden_Password
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *FreezeVaultRequest) SetPassword(v string) {
+	x.xxx_hidden_Password = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 1)
+}
+
+func (x *FreezeVaultRequest) HasPassword() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *FreezeVaultRequest) ClearPassword() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_Password = nil
+}
+
+type FreezeVaultRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	// The account password, again: a token alone cannot freeze or unfreeze.
+	Password *string
+}
+
+func (b0 FreezeVaultRequest_builder) Build() *FreezeVaultRequest {
+	m0 := &FreezeVaultRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Password != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 1)
+		x.xxx_hidden_Password = b.Password
+	}
+	return m0
+}
+
+type FreezeVaultResponse struct {
+	state               protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_FrozenAt *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=frozen_at,json=frozenAt"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *FreezeVaultResponse) Reset() {
+	*x = FreezeVaultResponse{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[66]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FreezeVaultResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FreezeVaultResponse) ProtoMessage() {}
+
+func (x *FreezeVaultResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[66]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *FreezeVaultResponse) GetFrozenAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.xxx_hidden_FrozenAt
+	}
+	return nil
+}
+
+func (x *FreezeVaultResponse) SetFrozenAt(v *timestamppb.Timestamp) {
+	x.xxx_hidden_FrozenAt = v
+}
+
+func (x *FreezeVaultResponse) HasFrozenAt() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_FrozenAt != nil
+}
+
+func (x *FreezeVaultResponse) ClearFrozenAt() {
+	x.xxx_hidden_FrozenAt = nil
+}
+
+type FreezeVaultResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	// When the vault was frozen; earlier than now if it already was.
+	FrozenAt *timestamppb.Timestamp
+}
+
+func (b0 FreezeVaultResponse_builder) Build() *FreezeVaultResponse {
+	m0 := &FreezeVaultResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_FrozenAt = b.FrozenAt
+	return m0
+}
+
+type UnfreezeVaultRequest struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Password    *string                `protobuf:"bytes,1,opt,name=password"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *UnfreezeVaultRequest) Reset() {
+	*x = UnfreezeVaultRequest{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[67]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnfreezeVaultRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnfreezeVaultRequest) ProtoMessage() {}
+
+func (x *UnfreezeVaultRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[67]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *UnfreezeVaultRequest) GetPassword() string {
+	if x != nil {
+		if x.xxx_hidden_Password != nil {
+			return *x.xxx_hidden_Password
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *UnfreezeVaultRequest) SetPassword(v string) {
+	x.xxx_hidden_Password = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 1)
+}
+
+func (x *UnfreezeVaultRequest) HasPassword() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *UnfreezeVaultRequest) ClearPassword() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_Password = nil
+}
+
+type UnfreezeVaultRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Password *string
+}
+
+func (b0 UnfreezeVaultRequest_builder) Build() *UnfreezeVaultRequest {
+	m0 := &UnfreezeVaultRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Password != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 1)
+		x.xxx_hidden_Password = b.Password
+	}
+	return m0
+}
+
+type UnfreezeVaultResponse struct {
+	state         protoimpl.MessageState `protogen:"opaque.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnfreezeVaultResponse) Reset() {
+	*x = UnfreezeVaultResponse{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[68]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnfreezeVaultResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnfreezeVaultResponse) ProtoMessage() {}
+
+func (x *UnfreezeVaultResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[68]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+type UnfreezeVaultResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+}
+
+func (b0 UnfreezeVaultResponse_builder) Build() *UnfreezeVaultResponse {
+	m0 := &UnfreezeVaultResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	return m0
+}
+
+type SetDuressPasswordRequest struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Password    *string                `protobuf:"bytes,1,opt,name=password"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *SetDuressPasswordRequest) Reset() {
+	*x = SetDuressPasswordRequest{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[69]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetDuressPasswordRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetDuressPasswordRequest) ProtoMessage() {}
+
+func (x *SetDuressPasswordRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[69]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *SetDuressPasswordRequest) GetPassword() string {
+	if x != nil {
+		if x.xxx_hidden_Password != nil {
+			return *x.xxx_hidden_Password
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *SetDuressPasswordRequest) SetPassword(v string) {
+	x.xxx_hidden_Password = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 1)
+}
+
+func (x *SetDuressPasswordRequest) HasPassword() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *SetDuressPasswordRequest) ClearPassword() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_Password = nil
+}
+
+type SetDuressPasswordRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	// Logging in to the caller's username with this password opens the
+	// duress vault instead. Must differ from the account password.
+	Password *string
+}
+
+func (b0 SetDuressPasswordRequest_builder) Build() *SetDuressPasswordRequest {
+	m0 := &SetDuressPasswordRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Password != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 1)
+		x.xxx_hidden_Password = b.Password
+	}
+	return m0
+}
+
+type SetDuressPasswordResponse struct {
+	state         protoimpl.MessageState `protogen:"opaque.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetDuressPasswordResponse) Reset() {
+	*x = SetDuressPasswordResponse{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[70]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetDuressPasswordResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetDuressPasswordResponse) ProtoMessage() {}
+
+func (x *SetDuressPasswordResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[70]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+type SetDuressPasswordResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+}
+
+func (b0 SetDuressPasswordResponse_builder) Build() *SetDuressPasswordResponse {
+	m0 := &SetDuressPasswordResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	return m0
+}
+
+type RemoveDuressPasswordRequest struct {
+	state         protoimpl.MessageState `protogen:"opaque.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveDuressPasswordRequest) Reset() {
+	*x = RemoveDuressPasswordRequest{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[71]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveDuressPasswordRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveDuressPasswordRequest) ProtoMessage() {}
+
+func (x *RemoveDuressPasswordRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[71]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+type RemoveDuressPasswordRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+}
+
+func (b0 RemoveDuressPasswordRequest_builder) Build() *RemoveDuressPasswordRequest {
+	m0 := &RemoveDuressPasswordRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	return m0
+}
+
+type RemoveDuressPasswordResponse struct {
+	state         protoimpl.MessageState `protogen:"opaque.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveDuressPasswordResponse) Reset() {
+	*x = RemoveDuressPasswordResponse{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[72]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveDuressPasswordResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveDuressPasswordResponse) ProtoMessage() {}
+
+func (x *RemoveDuressPasswordResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[72]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+type RemoveDuressPasswordResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+}
+
+func (b0 RemoveDuressPasswordResponse_builder) Build() *RemoveDuressPasswordResponse {
+	m0 := &RemoveDuressPasswordResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	return m0
+}
+
+// Toggle runtime diagnostics (pprof, expvar, GC stats) exposure.
+type SetDiagnosticsRequest struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Enabled     bool                   `protobuf:"varint,1,opt,name=enabled"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *SetDiagnosticsRequest) Reset() {
+	*x = SetDiagnosticsRequest{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[73]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetDiagnosticsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetDiagnosticsRequest) ProtoMessage() {}
+
+func (x *SetDiagnosticsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[73]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *SetDiagnosticsRequest) GetEnabled() bool {
+	if x != nil {
+		return x.xxx_hidden_Enabled
+	}
+	return false
+}
+
+func (x *SetDiagnosticsRequest) SetEnabled(v bool) {
+	x.xxx_hidden_Enabled = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 1)
+}
+
+func (x *SetDiagnosticsRequest) HasEnabled() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *SetDiagnosticsRequest) ClearEnabled() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_Enabled = false
+}
+
+type SetDiagnosticsRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Enabled *bool
+}
+
+func (b0 SetDiagnosticsRequest_builder) Build() *SetDiagnosticsRequest {
+	m0 := &SetDiagnosticsRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Enabled != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 1)
+		x.xxx_hidden_Enabled = *b.Enabled
+	}
+	return m0
+}
+
+type SetDiagnosticsResponse struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Enabled     bool                   `protobuf:"varint,1,opt,name=enabled"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *SetDiagnosticsResponse) Reset() {
+	*x = SetDiagnosticsResponse{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[74]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetDiagnosticsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetDiagnosticsResponse) ProtoMessage() {}
+
+func (x *SetDiagnosticsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[74]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *SetDiagnosticsResponse) GetEnabled() bool {
+	if x != nil {
+		return x.xxx_hidden_Enabled
+	}
+	return false
+}
+
+func (x *SetDiagnosticsResponse) SetEnabled(v bool) {
+	x.xxx_hidden_Enabled = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 1)
+}
+
+func (x *SetDiagnosticsResponse) HasEnabled() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *SetDiagnosticsResponse) ClearEnabled() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_Enabled = false
+}
+
+type SetDiagnosticsResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	// Diagnostics state after the change.
+	Enabled *bool
+}
+
+func (b0 SetDiagnosticsResponse_builder) Build() *SetDiagnosticsResponse {
+	m0 := &SetDiagnosticsResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Enabled != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 1)
+		x.xxx_hidden_Enabled = *b.Enabled
+	}
+	return m0
+}
+
+// Roll one user's vault back to its state at a point in time.
+type RollbackUserRequest struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_UserId      *string                `protobuf:"bytes,1,opt,name=user_id,json=userId"`
+	xxx_hidden_ToTime      *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=to_time,json=toTime"`
+	xxx_hidden_DryRun      bool                   `protobuf:"varint,3,opt,name=dry_run,json=dryRun"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *RollbackUserRequest) Reset() {
+	*x = RollbackUserRequest{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[75]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RollbackUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RollbackUserRequest) ProtoMessage() {}
+
+func (x *RollbackUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[75]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *RollbackUserRequest) GetUserId() string {
+	if x != nil {
+		if x.xxx_hidden_UserId != nil {
+			return *x.xxx_hidden_UserId
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *RollbackUserRequest) GetToTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.xxx_hidden_ToTime
+	}
+	return nil
+}
+
+func (x *RollbackUserRequest) GetDryRun() bool {
+	if x != nil {
+		return x.xxx_hidden_DryRun
+	}
+	return false
+}
+
+func (x *RollbackUserRequest) SetUserId(v string) {
+	x.xxx_hidden_UserId = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 3)
+}
+
+func (x *RollbackUserRequest) SetToTime(v *timestamppb.Timestamp) {
+	x.xxx_hidden_ToTime = v
+}
+
+func (x *RollbackUserRequest) SetDryRun(v bool) {
+	x.xxx_hidden_DryRun = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 3)
+}
+
+func (x *RollbackUserRequest) HasUserId() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *RollbackUserRequest) HasToTime() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_ToTime != nil
+}
+
+func (x *RollbackUserRequest) HasDryRun() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 2)
+}
+
+func (x *RollbackUserRequest) ClearUserId() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_UserId = nil
+}
+
+func (x *RollbackUserRequest) ClearToTime() {
+	x.xxx_hidden_ToTime = nil
+}
+
+func (x *RollbackUserRequest) ClearDryRun() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 2)
+	x.xxx_hidden_DryRun = false
+}
+
+type RollbackUserRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	UserId *string
+	ToTime *timestamppb.Timestamp
+	// Report what would change without changing anything.
+	DryRun *bool
+}
+
+func (b0 RollbackUserRequest_builder) Build() *RollbackUserRequest {
+	m0 := &RollbackUserRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.UserId != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 3)
+		x.xxx_hidden_UserId = b.UserId
+	}
+	x.xxx_hidden_ToTime = b.ToTime
+	if b.DryRun != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 3)
+		x.xxx_hidden_DryRun = *b.DryRun
+	}
+	return m0
+}
+
+type RollbackUserResponse struct {
+	state                        protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_RollbackId        int64                  `protobuf:"varint,1,opt,name=rollback_id,json=rollbackId"`
+	xxx_hidden_Restored          int64                  `protobuf:"varint,2,opt,name=restored"`
+	xxx_hidden_Removed           int64                  `protobuf:"varint,3,opt,name=removed"`
+	xxx_hidden_DiscardedVersions int64                  `protobuf:"varint,4,opt,name=discarded_versions,json=discardedVersions"`
+	xxx_hidden_Skipped           int64                  `protobuf:"varint,5,opt,name=skipped"`
+	xxx_hidden_DryRun            bool                   `protobuf:"varint,6,opt,name=dry_run,json=dryRun"`
+	XXX_raceDetectHookData       protoimpl.RaceDetectHookData
+	XXX_presence                 [1]uint32
+	unknownFields                protoimpl.UnknownFields
+	sizeCache                    protoimpl.SizeCache
+}
+
+func (x *RollbackUserResponse) Reset() {
+	*x = RollbackUserResponse{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[76]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RollbackUserResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RollbackUserResponse) ProtoMessage() {}
+
+func (x *RollbackUserResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[76]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *RollbackUserResponse) GetRollbackId() int64 {
+	if x != nil {
+		return x.xxx_hidden_RollbackId
+	}
+	return 0
+}
+
+func (x *RollbackUserResponse) GetRestored() int64 {
+	if x != nil {
+		return x.xxx_hidden_Restored
+	}
+	return 0
+}
+
+func (x *RollbackUserResponse) GetRemoved() int64 {
+	if x != nil {
+		return x.xxx_hidden_Removed
+	}
+	return 0
+}
+
+func (x *RollbackUserResponse) GetDiscardedVersions() int64 {
+	if x != nil {
+		return x.xxx_hidden_DiscardedVersions
+	}
+	return 0
+}
+
+func (x *RollbackUserResponse) GetSkipped() int64 {
+	if x != nil {
+		return x.xxx_hidden_Skipped
+	}
+	return 0
+}
+
+func (x *RollbackUserResponse) GetDryRun() bool {
+	if x != nil {
+		return x.xxx_hidden_DryRun
+	}
+	return false
+}
+
+func (x *RollbackUserResponse) SetRollbackId(v int64) {
+	x.xxx_hidden_RollbackId = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 6)
+}
+
+func (x *RollbackUserResponse) SetRestored(v int64) {
+	x.xxx_hidden_Restored = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 6)
+}
+
+func (x *RollbackUserResponse) SetRemoved(v int64) {
+	x.xxx_hidden_Removed = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 6)
+}
+
+func (x *RollbackUserResponse) SetDiscardedVersions(v int64) {
+	x.xxx_hidden_DiscardedVersions = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 3, 6)
+}
+
+func (x *RollbackUserResponse) SetSkipped(v int64) {
+	x.xxx_hidden_Skipped = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 4, 6)
+}
+
+func (x *RollbackUserResponse) SetDryRun(v bool) {
+	x.xxx_hidden_DryRun = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 5, 6)
+}
+
+func (x *RollbackUserResponse) HasRollbackId() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *RollbackUserResponse) HasRestored() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *RollbackUserResponse) HasRemoved() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 2)
+}
+
+func (x *RollbackUserResponse) HasDiscardedVersions() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 3)
+}
+
+func (x *RollbackUserResponse) HasSkipped() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 4)
+}
+
+func (x *RollbackUserResponse) HasDryRun() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 5)
+}
+
+func (x *RollbackUserResponse) ClearRollbackId() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_RollbackId = 0
+}
+
+func (x *RollbackUserResponse) ClearRestored() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_Restored = 0
+}
+
+func (x *RollbackUserResponse) ClearRemoved() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 2)
+	x.xxx_hidden_Removed = 0
+}
+
+func (x *RollbackUserResponse) ClearDiscardedVersions() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 3)
+	x.xxx_hidden_DiscardedVersions = 0
+}
+
+func (x *RollbackUserResponse) ClearSkipped() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 4)
+	x.xxx_hidden_Skipped = 0
+}
+
+func (x *RollbackUserResponse) ClearDryRun() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 5)
+	x.xxx_hidden_DryRun = false
+}
+
+type RollbackUserResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	// Audit record of the rollback; 0 for a dry run.
+	RollbackId *int64
+	// Items reset to the version they had at to_time.
+	Restored *int64
+	// Items created after to_time, removed.
+	Removed *int64
+	// History versions after to_time, moved out of item_history.
+	DiscardedVersions *int64
+	// Items whose state at to_time predates their recorded history; left as they are.
+	Skipped *int64
+	DryRun  *bool
+}
+
+func (b0 RollbackUserResponse_builder) Build() *RollbackUserResponse {
+	m0 := &RollbackUserResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.RollbackId != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 6)
+		x.xxx_hidden_RollbackId = *b.RollbackId
+	}
+	if b.Restored != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 6)
+		x.xxx_hidden_Restored = *b.Restored
+	}
+	if b.Removed != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 6)
+		x.xxx_hidden_Removed = *b.Removed
+	}
+	if b.DiscardedVersions != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 3, 6)
+		x.xxx_hidden_DiscardedVersions = *b.DiscardedVersions
+	}
+	if b.Skipped != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 4, 6)
+		x.xxx_hidden_Skipped = *b.Skipped
+	}
+	if b.DryRun != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 5, 6)
+		x.xxx_hidden_DryRun = *b.DryRun
+	}
+	return m0
+}
+
+// Check one user's stored items against the invariants sync relies on.
+type CheckUserConsistencyRequest struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_UserId      *string                `protobuf:"bytes,1,opt,name=user_id,json=userId"`
+	xxx_hidden_Repair      bool                   `protobuf:"varint,2,opt,name=repair"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *CheckUserConsistencyRequest) Reset() {
+	*x = CheckUserConsistencyRequest{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[77]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckUserConsistencyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckUserConsistencyRequest) ProtoMessage() {}
+
+func (x *CheckUserConsistencyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[77]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *CheckUserConsistencyRequest) GetUserId() string {
+	if x != nil {
+		if x.xxx_hidden_UserId != nil {
+			return *x.xxx_hidden_UserId
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *CheckUserConsistencyRequest) GetRepair() bool {
+	if x != nil {
+		return x.xxx_hidden_Repair
+	}
+	return false
+}
+
+func (x *CheckUserConsistencyRequest) SetUserId(v string) {
+	x.xxx_hidden_UserId = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 2)
+}
+
+func (x *CheckUserConsistencyRequest) SetRepair(v bool) {
+	x.xxx_hidden_Repair = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 2)
+}
+
+func (x *CheckUserConsistencyRequest) HasUserId() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *CheckUserConsistencyRequest) HasRepair() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *CheckUserConsistencyRequest) ClearUserId() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_UserId = nil
+}
+
+func (x *CheckUserConsistencyRequest) ClearRepair() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_Repair = false
+}
+
+type CheckUserConsistencyRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	UserId *string
+	// Fix what can be fixed safely; the rest is only reported.
+	Repair *bool
+}
+
+func (b0 CheckUserConsistencyRequest_builder) Build() *CheckUserConsistencyRequest {
+	m0 := &CheckUserConsistencyRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.UserId != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 2)
+		x.xxx_hidden_UserId = b.UserId
+	}
+	if b.Repair != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 2)
+		x.xxx_hidden_Repair = *b.Repair
+	}
+	return m0
+}
+
+// One broken invariant of one item.
+type ConsistencyViolation struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_ItemId      *string                `protobuf:"bytes,1,opt,name=item_id,json=itemId"`
+	xxx_hidden_Check       *string                `protobuf:"bytes,2,opt,name=check"`
+	xxx_hidden_Detail      *string                `protobuf:"bytes,3,opt,name=detail"`
+	xxx_hidden_Repaired    bool                   `protobuf:"varint,4,opt,name=repaired"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *ConsistencyViolation) Reset() {
+	*x = ConsistencyViolation{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[78]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConsistencyViolation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConsistencyViolation) ProtoMessage() {}
+
+func (x *ConsistencyViolation) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[78]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *ConsistencyViolation) GetItemId() string {
+	if x != nil {
+		if x.xxx_hidden_ItemId != nil {
+			return *x.xxx_hidden_ItemId
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *ConsistencyViolation) GetCheck() string {
+	if x != nil {
+		if x.xxx_hidden_Check != nil {
+			return *x.xxx_hidden_Check
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *ConsistencyViolation) GetDetail() string {
+	if x != nil {
+		if x.xxx_hidden_Detail != nil {
+			return *x.xxx_hidden_Detail
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *ConsistencyViolation) GetRepaired() bool {
+	if x != nil {
+		return x.xxx_hidden_Repaired
+	}
+	return false
+}
+
+func (x *ConsistencyViolation) SetItemId(v string) {
+	x.xxx_hidden_ItemId = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 4)
+}
+
+func (x *ConsistencyViolation) SetCheck(v string) {
+	x.xxx_hidden_Check = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 4)
+}
+
+func (x *ConsistencyViolation) SetDetail(v string) {
+	x.xxx_hidden_Detail = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 4)
+}
+
+func (x *ConsistencyViolation) SetRepaired(v bool) {
+	x.xxx_hidden_Repaired = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 3, 4)
+}
+
+func (x *ConsistencyViolation) HasItemId() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *ConsistencyViolation) HasCheck() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *ConsistencyViolation) HasDetail() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 2)
+}
+
+func (x *ConsistencyViolation) HasRepaired() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 3)
+}
+
+func (x *ConsistencyViolation) ClearItemId() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_ItemId = nil
+}
+
+func (x *ConsistencyViolation) ClearCheck() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_Check = nil
+}
+
+func (x *ConsistencyViolation) ClearDetail() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 2)
+	x.xxx_hidden_Detail = nil
+}
+
+func (x *ConsistencyViolation) ClearRepaired() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 3)
+	x.xxx_hidden_Repaired = false
+}
+
+type ConsistencyViolation_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	ItemId *string
+	// Invariant name, e.g. "journal_order".
+	Check    *string
+	Detail   *string
+	Repaired *bool
+}
+
+func (b0 ConsistencyViolation_builder) Build() *ConsistencyViolation {
+	m0 := &ConsistencyViolation{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.ItemId != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 4)
+		x.xxx_hidden_ItemId = b.ItemId
+	}
+	if b.Check != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 4)
+		x.xxx_hidden_Check = b.Check
+	}
+	if b.Detail != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 4)
+		x.xxx_hidden_Detail = b.Detail
+	}
+	if b.Repaired != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 3, 4)
+		x.xxx_hidden_Repaired = *b.Repaired
+	}
+	return m0
+}
+
+type CheckUserConsistencyResponse struct {
+	state                   protoimpl.MessageState   `protogen:"opaque.v1"`
+	xxx_hidden_ItemsChecked int64                    `protobuf:"varint,1,opt,name=items_checked,json=itemsChecked"`
+	xxx_hidden_Violations   *[]*ConsistencyViolation `protobuf:"bytes,2,rep,name=violations"`
+	XXX_raceDetectHookData  protoimpl.RaceDetectHookData
+	XXX_presence            [1]uint32
+	unknownFields           protoimpl.UnknownFields
+	sizeCache               protoimpl.SizeCache
+}
+
+func (x *CheckUserConsistencyResponse) Reset() {
+	*x = CheckUserConsistencyResponse{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[79]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckUserConsistencyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckUserConsistencyResponse) ProtoMessage() {}
+
+func (x *CheckUserConsistencyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[79]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *CheckUserConsistencyResponse) GetItemsChecked() int64 {
+	if x != nil {
+		return x.xxx_hidden_ItemsChecked
+	}
+	return 0
+}
+
+func (x *CheckUserConsistencyResponse) GetViolations() []*ConsistencyViolation {
+	if x != nil {
+		if x.xxx_hidden_Violations != nil {
+			return *x.xxx_hidden_Violations
+		}
+	}
+	return nil
+}
+
+func (x *CheckUserConsistencyResponse) SetItemsChecked(v int64) {
+	x.xxx_hidden_ItemsChecked = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 2)
+}
+
+func (x *CheckUserConsistencyResponse) SetViolations(v []*ConsistencyViolation) {
+	x.xxx_hidden_Violations = &v
+}
+
+func (x *CheckUserConsistencyResponse) HasItemsChecked() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *CheckUserConsistencyResponse) ClearItemsChecked() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_ItemsChecked = 0
+}
+
+type CheckUserConsistencyResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	ItemsChecked *int64
+	// Ordered by item id.
+	Violations []*ConsistencyViolation
+}
+
+func (b0 CheckUserConsistencyResponse_builder) Build() *CheckUserConsistencyResponse {
+	m0 := &CheckUserConsistencyResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.ItemsChecked != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 2)
+		x.xxx_hidden_ItemsChecked = *b.ItemsChecked
+	}
+	x.xxx_hidden_Violations = &b.Violations
+	return m0
+}
+
+type SuspendUserRequest struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_UserId      *string                `protobuf:"bytes,1,opt,name=user_id,json=userId"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *SuspendUserRequest) Reset() {
+	*x = SuspendUserRequest{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[80]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SuspendUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SuspendUserRequest) ProtoMessage() {}
+
+func (x *SuspendUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[80]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *SuspendUserRequest) GetUserId() string {
+	if x != nil {
+		if x.xxx_hidden_UserId != nil {
+			return *x.xxx_hidden_UserId
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *SuspendUserRequest) SetUserId(v string) {
+	x.xxx_hidden_UserId = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 1)
+}
+
+func (x *SuspendUserRequest) HasUserId() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *SuspendUserRequest) ClearUserId() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_UserId = nil
+}
+
+type SuspendUserRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	UserId *string
+}
+
+func (b0 SuspendUserRequest_builder) Build() *SuspendUserRequest {
+	m0 := &SuspendUserRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.UserId != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 1)
+		x.xxx_hidden_UserId = b.UserId
+	}
+	return m0
+}
+
+type SuspendUserResponse struct {
+	state         protoimpl.MessageState `protogen:"opaque.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SuspendUserResponse) Reset() {
+	*x = SuspendUserResponse{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[81]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SuspendUserResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SuspendUserResponse) ProtoMessage() {}
+
+func (x *SuspendUserResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[81]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+type SuspendUserResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+}
+
+func (b0 SuspendUserResponse_builder) Build() *SuspendUserResponse {
+	m0 := &SuspendUserResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	return m0
+}
+
+type ReactivateUserRequest struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_UserId      *string                `protobuf:"bytes,1,opt,name=user_id,json=userId"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *ReactivateUserRequest) Reset() {
+	*x = ReactivateUserRequest{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[82]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReactivateUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReactivateUserRequest) ProtoMessage() {}
+
+func (x *ReactivateUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[82]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *ReactivateUserRequest) GetUserId() string {
+	if x != nil {
+		if x.xxx_hidden_UserId != nil {
+			return *x.xxx_hidden_UserId
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *ReactivateUserRequest) SetUserId(v string) {
+	x.xxx_hidden_UserId = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 1)
+}
+
+func (x *ReactivateUserRequest) HasUserId() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *ReactivateUserRequest) ClearUserId() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_UserId = nil
+}
+
+type ReactivateUserRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	UserId *string
+}
+
+func (b0 ReactivateUserRequest_builder) Build() *ReactivateUserRequest {
+	m0 := &ReactivateUserRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.UserId != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 1)
+		x.xxx_hidden_UserId = b.UserId
+	}
+	return m0
+}
+
+type ReactivateUserResponse struct {
+	state         protoimpl.MessageState `protogen:"opaque.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReactivateUserResponse) Reset() {
+	*x = ReactivateUserResponse{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[83]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReactivateUserResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReactivateUserResponse) ProtoMessage() {}
+
+func (x *ReactivateUserResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[83]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+type ReactivateUserResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+}
+
+func (b0 ReactivateUserResponse_builder) Build() *ReactivateUserResponse {
+	m0 := &ReactivateUserResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	return m0
+}
+
+type GetUsageReportRequest struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Tenant      *string                `protobuf:"bytes,1,opt,name=tenant"`
+	xxx_hidden_Limit       int32                  `protobuf:"varint,2,opt,name=limit"`
+	xxx_hidden_Days        int32                  `protobuf:"varint,3,opt,name=days"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *GetUsageReportRequest) Reset() {
+	*x = GetUsageReportRequest{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[84]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUsageReportRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUsageReportRequest) ProtoMessage() {}
+
+func (x *GetUsageReportRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[84]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *GetUsageReportRequest) GetTenant() string {
+	if x != nil {
+		if x.xxx_hidden_Tenant != nil {
+			return *x.xxx_hidden_Tenant
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *GetUsageReportRequest) GetLimit() int32 {
+	if x != nil {
+		return x.xxx_hidden_Limit
+	}
+	return 0
+}
+
+func (x *GetUsageReportRequest) GetDays() int32 {
+	if x != nil {
+		return x.xxx_hidden_Days
+	}
+	return 0
+}
+
+func (x *GetUsageReportRequest) SetTenant(v string) {
+	x.xxx_hidden_Tenant = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 3)
+}
+
+func (x *GetUsageReportRequest) SetLimit(v int32) {
+	x.xxx_hidden_Limit = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 3)
+}
+
+func (x *GetUsageReportRequest) SetDays(v int32) {
+	x.xxx_hidden_Days = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 3)
+}
+
+func (x *GetUsageReportRequest) HasTenant() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *GetUsageReportRequest) HasLimit() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *GetUsageReportRequest) HasDays() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 2)
+}
+
+func (x *GetUsageReportRequest) ClearTenant() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_Tenant = nil
+}
+
+func (x *GetUsageReportRequest) ClearLimit() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_Limit = 0
+}
+
+func (x *GetUsageReportRequest) ClearDays() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 2)
+	x.xxx_hidden_Days = 0
+}
+
+type GetUsageReportRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	// Only this tenant's users; empty = every tenant.
+	Tenant *string
+	// Users with the most bytes first, at most this many; 0 = 100, at most 1000.
+	Limit *int32
+	// Daily samples per user for this many days up to the report's; 0 = none,
+	// at most 366.
+	Days *int32
+}
+
+func (b0 GetUsageReportRequest_builder) Build() *GetUsageReportRequest {
+	m0 := &GetUsageReportRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Tenant != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 3)
+		x.xxx_hidden_Tenant = b.Tenant
+	}
+	if b.Limit != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 3)
+		x.xxx_hidden_Limit = *b.Limit
+	}
+	if b.Days != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 3)
+		x.xxx_hidden_Days = *b.Days
+	}
+	return m0
+}
+
+// A user's storage as of the last rollup of a day.
+type UsageSample struct {
+	state                   protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Day          *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=day"`
+	xxx_hidden_Items        int64                  `protobuf:"varint,2,opt,name=items"`
+	xxx_hidden_Bytes        int64                  `protobuf:"varint,3,opt,name=bytes"`
+	xxx_hidden_HistoryBytes int64                  `protobuf:"varint,4,opt,name=history_bytes,json=historyBytes"`
+	XXX_raceDetectHookData  protoimpl.RaceDetectHookData
+	XXX_presence            [1]uint32
+	unknownFields           protoimpl.UnknownFields
+	sizeCache               protoimpl.SizeCache
+}
+
+func (x *UsageSample) Reset() {
+	*x = UsageSample{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[85]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UsageSample) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UsageSample) ProtoMessage() {}
+
+func (x *UsageSample) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[85]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *UsageSample) GetDay() *timestamppb.Timestamp {
+	if x != nil {
+		return x.xxx_hidden_Day
+	}
+	return nil
+}
+
+func (x *UsageSample) GetItems() int64 {
+	if x != nil {
+		return x.xxx_hidden_Items
+	}
+	return 0
+}
+
+func (x *UsageSample) GetBytes() int64 {
+	if x != nil {
+		return x.xxx_hidden_Bytes
+	}
+	return 0
+}
+
+func (x *UsageSample) GetHistoryBytes() int64 {
+	if x != nil {
+		return x.xxx_hidden_HistoryBytes
+	}
+	return 0
+}
+
+func (x *UsageSample) SetDay(v *timestamppb.Timestamp) {
+	x.xxx_hidden_Day = v
+}
+
+func (x *UsageSample) SetItems(v int64) {
+	x.xxx_hidden_Items = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 4)
+}
+
+func (x *UsageSample) SetBytes(v int64) {
+	x.xxx_hidden_Bytes = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 4)
+}
+
+func (x *UsageSample) SetHistoryBytes(v int64) {
+	x.xxx_hidden_HistoryBytes = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 3, 4)
+}
+
+func (x *UsageSample) HasDay() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_Day != nil
+}
+
+func (x *UsageSample) HasItems() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *UsageSample) HasBytes() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 2)
+}
+
+func (x *UsageSample) HasHistoryBytes() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 3)
+}
+
+func (x *UsageSample) ClearDay() {
+	x.xxx_hidden_Day = nil
+}
+
+func (x *UsageSample) ClearItems() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_Items = 0
+}
+
+func (x *UsageSample) ClearBytes() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 2)
+	x.xxx_hidden_Bytes = 0
+}
+
+func (x *UsageSample) ClearHistoryBytes() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 3)
+	x.xxx_hidden_HistoryBytes = 0
+}
+
+type UsageSample_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	// Midnight UTC of the day.
+	Day          *timestamppb.Timestamp
+	Items        *int64
+	Bytes        *int64
+	HistoryBytes *int64
+}
+
+func (b0 UsageSample_builder) Build() *UsageSample {
+	m0 := &UsageSample{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_Day = b.Day
+	if b.Items != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 4)
+		x.xxx_hidden_Items = *b.Items
+	}
+	if b.Bytes != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 4)
+		x.xxx_hidden_Bytes = *b.Bytes
+	}
+	if b.HistoryBytes != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 3, 4)
+		x.xxx_hidden_HistoryBytes = *b.HistoryBytes
+	}
+	return m0
+}
+
+// One user's storage. Sizes are of ciphertext as stored.
+type UserUsage struct {
+	state                   protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_UserId       *string                `protobuf:"bytes,1,opt,name=user_id,json=userId"`
+	xxx_hidden_Tenant       *string                `protobuf:"bytes,2,opt,name=tenant"`
+	xxx_hidden_Items        int64                  `protobuf:"varint,3,opt,name=items"`
+	xxx_hidden_Tombstones   int64                  `protobuf:"varint,4,opt,name=tombstones"`
+	xxx_hidden_Bytes        int64                  `protobuf:"varint,5,opt,name=bytes"`
+	xxx_hidden_HistoryBytes int64                  `protobuf:"varint,6,opt,name=history_bytes,json=historyBytes"`
+	xxx_hidden_LastWrite    *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=last_write,json=lastWrite"`
+	xxx_hidden_LastLogin    *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=last_login,json=lastLogin"`
+	xxx_hidden_History      *[]*UsageSample        `protobuf:"bytes,9,rep,name=history"`
+	XXX_raceDetectHookData  protoimpl.RaceDetectHookData
+	XXX_presence            [1]uint32
+	unknownFields           protoimpl.UnknownFields
+	sizeCache               protoimpl.SizeCache
+}
+
+func (x *UserUsage) Reset() {
+	*x = UserUsage{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[86]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserUsage) ProtoMessage() {}
+
+func (x *UserUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[86]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *UserUsage) GetUserId() string {
+	if x != nil {
+		if x.xxx_hidden_UserId != nil {
+			return *x.xxx_hidden_UserId
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *UserUsage) GetTenant() string {
+	if x != nil {
+		if x.xxx_hidden_Tenant != nil {
+			return *x.xxx_hidden_Tenant
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *UserUsage) GetItems() int64 {
+	if x != nil {
+		return x.xxx_hidden_Items
+	}
+	return 0
+}
+
+func (x *UserUsage) GetTombstones() int64 {
+	if x != nil {
+		return x.xxx_hidden_Tombstones
+	}
+	return 0
+}
+
+func (x *UserUsage) GetBytes() int64 {
+	if x != nil {
+		return x.xxx_hidden_Bytes
+	}
+	return 0
+}
+
+func (x *UserUsage) GetHistoryBytes() int64 {
+	if x != nil {
+		return x.xxx_hidden_HistoryBytes
+	}
+	return 0
+}
+
+func (x *UserUsage) GetLastWrite() *timestamppb.Timestamp {
+	if x != nil {
+		return x.xxx_hidden_LastWrite
+	}
+	return nil
+}
+
+func (x *UserUsage) GetLastLogin() *timestamppb.Timestamp {
+	if x != nil {
+		return x.xxx_hidden_LastLogin
+	}
+	return nil
+}
+
+func (x *UserUsage) GetHistory() []*UsageSample {
+	if x != nil {
+		if x.xxx_hidden_History != nil {
+			return *x.xxx_hidden_History
+		}
+	}
+	return nil
+}
+
+func (x *UserUsage) SetUserId(v string) {
+	x.xxx_hidden_UserId = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 9)
+}
+
+func (x *UserUsage) SetTenant(v string) {
+	x.xxx_hidden_Tenant = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 9)
+}
+
+func (x *UserUsage) SetItems(v int64) {
+	x.xxx_hidden_Items = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 9)
+}
+
+func (x *UserUsage) SetTombstones(v int64) {
+	x.xxx_hidden_Tombstones = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 3, 9)
+}
+
+func (x *UserUsage) SetBytes(v int64) {
+	x.xxx_hidden_Bytes = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 4, 9)
+}
+
+func (x *UserUsage) SetHistoryBytes(v int64) {
+	x.xxx_hidden_HistoryBytes = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 5, 9)
+}
+
+func (x *UserUsage) SetLastWrite(v *timestamppb.Timestamp) {
+	x.xxx_hidden_LastWrite = v
+}
+
+func (x *UserUsage) SetLastLogin(v *timestamppb.Timestamp) {
+	x.xxx_hidden_LastLogin = v
+}
+
+func (x *UserUsage) SetHistory(v []*UsageSample) {
+	x.xxx_hidden_History = &v
+}
+
+func (x *UserUsage) HasUserId() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *UserUsage) HasTenant() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *UserUsage) HasItems() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 2)
+}
+
+func (x *UserUsage) HasTombstones() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 3)
+}
+
+func (x *UserUsage) HasBytes() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 4)
+}
+
+func (x *UserUsage) HasHistoryBytes() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 5)
+}
+
+func (x *UserUsage) HasLastWrite() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_LastWrite != nil
+}
+
+func (x *UserUsage) HasLastLogin() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_LastLogin != nil
+}
+
+func (x *UserUsage) ClearUserId() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_UserId = nil
+}
+
+func (x *UserUsage) ClearTenant() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_Tenant = nil
+}
+
+func (x *UserUsage) ClearItems() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 2)
+	x.xxx_hidden_Items = 0
+}
+
+func (x *UserUsage) ClearTombstones() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 3)
+	x.xxx_hidden_Tombstones = 0
+}
+
+func (x *UserUsage) ClearBytes() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 4)
+	x.xxx_hidden_Bytes = 0
+}
+
+func (x *UserUsage) ClearHistoryBytes() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 5)
+	x.xxx_hidden_HistoryBytes = 0
+}
+
+func (x *UserUsage) ClearLastWrite() {
+	x.xxx_hidden_LastWrite = nil
+}
+
+func (x *UserUsage) ClearLastLogin() {
+	x.xxx_hidden_LastLogin = nil
+}
+
+type UserUsage_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	UserId *string
+	Tenant *string
+	// Live items.
+	Items      *int64
+	Tombstones *int64
+	// Current item blobs, tombstones included.
+	Bytes *int64
+	// Kept earlier versions.
+	HistoryBytes *int64
+	// Unset if the user never stored an item.
+	LastWrite *timestamppb.Timestamp
+	// Unset if no login is on record.
+	LastLogin *timestamppb.Timestamp
+	// Oldest first; days without a rollup are missing.
+	History []*UsageSample
+}
+
+func (b0 UserUsage_builder) Build() *UserUsage {
+	m0 := &UserUsage{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.UserId != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 9)
+		x.xxx_hidden_UserId = b.UserId
+	}
+	if b.Tenant != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 9)
+		x.xxx_hidden_Tenant = b.Tenant
+	}
+	if b.Items != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 9)
+		x.xxx_hidden_Items = *b.Items
+	}
+	if b.Tombstones != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 3, 9)
+		x.xxx_hidden_Tombstones = *b.Tombstones
+	}
+	if b.Bytes != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 4, 9)
+		x.xxx_hidden_Bytes = *b.Bytes
+	}
+	if b.HistoryBytes != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 5, 9)
+		x.xxx_hidden_HistoryBytes = *b.HistoryBytes
+	}
+	x.xxx_hidden_LastWrite = b.LastWrite
+	x.xxx_hidden_LastLogin = b.LastLogin
+	x.xxx_hidden_History = &b.History
+	return m0
+}
+
+type GetUsageReportResponse struct {
+	state            protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_AsOf  *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=as_of,json=asOf"`
+	xxx_hidden_Users *[]*UserUsage          `protobuf:"bytes,2,rep,name=users"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *GetUsageReportResponse) Reset() {
+	*x = GetUsageReportResponse{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[87]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUsageReportResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUsageReportResponse) ProtoMessage() {}
+
+func (x *GetUsageReportResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[87]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *GetUsageReportResponse) GetAsOf() *timestamppb.Timestamp {
+	if x != nil {
+		return x.xxx_hidden_AsOf
+	}
+	return nil
+}
+
+func (x *GetUsageReportResponse) GetUsers() []*UserUsage {
+	if x != nil {
+		if x.xxx_hidden_Users != nil {
+			return *x.xxx_hidden_Users
+		}
+	}
+	return nil
+}
+
+func (x *GetUsageReportResponse) SetAsOf(v *timestamppb.Timestamp) {
+	x.xxx_hidden_AsOf = v
+}
+
+func (x *GetUsageReportResponse) SetUsers(v []*UserUsage) {
+	x.xxx_hidden_Users = &v
+}
+
+func (x *GetUsageReportResponse) HasAsOf() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_AsOf != nil
+}
+
+func (x *GetUsageReportResponse) ClearAsOf() {
+	x.xxx_hidden_AsOf = nil
+}
+
+type GetUsageReportResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	// When the figures were rolled up; unset if they never were.
+	AsOf  *timestamppb.Timestamp
+	Users []*UserUsage
+}
+
+func (b0 GetUsageReportResponse_builder) Build() *GetUsageReportResponse {
+	m0 := &GetUsageReportResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_AsOf = b.AsOf
+	x.xxx_hidden_Users = &b.Users
+	return m0
+}
+
+// A (username, address) pair barred from logging in after repeated
+// wrong passwords.
+type LoginLock struct {
+	state                     protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Tenant         *string                `protobuf:"bytes,1,opt,name=tenant"`
+	xxx_hidden_Username       *string                `protobuf:"bytes,2,opt,name=username"`
+	xxx_hidden_IpHash         []byte                 `protobuf:"bytes,3,opt,name=ip_hash,json=ipHash"`
+	xxx_hidden_FailedAttempts int32                  `protobuf:"varint,4,opt,name=failed_attempts,json=failedAttempts"`
+	xxx_hidden_BlockedUntil   *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=blocked_until,json=blockedUntil"`
+	XXX_raceDetectHookData    protoimpl.RaceDetectHookData
+	XXX_presence              [1]uint32
+	unknownFields             protoimpl.UnknownFields
+	sizeCache                 protoimpl.SizeCache
+}
+
+func (x *LoginLock) Reset() {
+	*x = LoginLock{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[88]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LoginLock) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoginLock) ProtoMessage() {}
+
+func (x *LoginLock) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[88]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *LoginLock) GetTenant() string {
+	if x != nil {
+		if x.xxx_hidden_Tenant != nil {
+			return *x.xxx_hidden_Tenant
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *LoginLock) GetUsername() string {
+	if x != nil {
+		if x.xxx_hidden_Username != nil {
+			return *x.xxx_hidden_Username
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *LoginLock) GetIpHash() []byte {
+	if x != nil {
+		return x.xxx_hidden_IpHash
+	}
+	return nil
+}
+
+func (x *LoginLock) GetFailedAttempts() int32 {
+	if x != nil {
+		return x.xxx_hidden_FailedAttempts
+	}
+	return 0
+}
+
+func (x *LoginLock) GetBlockedUntil() *timestamppb.Timestamp {
+	if x != nil {
+		return x.xxx_hidden_BlockedUntil
+	}
+	return nil
+}
+
+func (x *LoginLock) SetTenant(v string) {
+	x.xxx_hidden_Tenant = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 5)
+}
+
+func (x *LoginLock) SetUsername(v string) {
+	x.xxx_hidden_Username = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 5)
+}
+
+func (x *LoginLock) SetIpHash(v []byte) {
+	if v == nil {
+		v = []byte{}
+	}
+	x.xxx_hidden_IpHash = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 5)
+}
+
+func (x *LoginLock) SetFailedAttempts(v int32) {
+	x.xxx_hidden_FailedAttempts = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 3, 5)
+}
+
+func (x *LoginLock) SetBlockedUntil(v *timestamppb.Timestamp) {
+	x.xxx_hidden_BlockedUntil = v
+}
+
+func (x *LoginLock) HasTenant() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *LoginLock) HasUsername() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *LoginLock) HasIpHash() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 2)
+}
+
+func (x *LoginLock) HasFailedAttempts() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 3)
+}
+
+func (x *LoginLock) HasBlockedUntil() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_BlockedUntil != nil
+}
+
+func (x *LoginLock) ClearTenant() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_Tenant = nil
+}
+
+func (x *LoginLock) ClearUsername() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_Username = nil
+}
+
+func (x *LoginLock) ClearIpHash() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 2)
+	x.xxx_hidden_IpHash = nil
+}
+
+func (x *LoginLock) ClearFailedAttempts() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 3)
+	x.xxx_hidden_FailedAttempts = 0
+}
+
+func (x *LoginLock) ClearBlockedUntil() {
+	x.xxx_hidden_BlockedUntil = nil
+}
+
+type LoginLock_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Tenant   *string
+	Username *string
+	// SHA-256 of the client address; the server keeps no addresses.
+	IpHash []byte
+	// Wrong passwords that led to the lock.
+	FailedAttempts *int32
+	BlockedUntil   *timestamppb.Timestamp
+}
+
+func (b0 LoginLock_builder) Build() *LoginLock {
+	m0 := &LoginLock{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Tenant != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 5)
+		x.xxx_hidden_Tenant = b.Tenant
+	}
+	if b.Username != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 5)
+		x.xxx_hidden_Username = b.Username
+	}
+	if b.IpHash != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 5)
+		x.xxx_hidden_IpHash = b.IpHash
+	}
+	if b.FailedAttempts != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 3, 5)
+		x.xxx_hidden_FailedAttempts = *b.FailedAttempts
+	}
+	x.xxx_hidden_BlockedUntil = b.BlockedUntil
+	return m0
+}
+
+type ListLoginLocksRequest struct {
+	state         protoimpl.MessageState `protogen:"opaque.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListLoginLocksRequest) Reset() {
+	*x = ListLoginLocksRequest{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[89]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListLoginLocksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListLoginLocksRequest) ProtoMessage() {}
+
+func (x *ListLoginLocksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[89]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+type ListLoginLocksRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+}
+
+func (b0 ListLoginLocksRequest_builder) Build() *ListLoginLocksRequest {
+	m0 := &ListLoginLocksRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	return m0
+}
+
+type ListLoginLocksResponse struct {
+	state            protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Locks *[]*LoginLock          `protobuf:"bytes,1,rep,name=locks"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ListLoginLocksResponse) Reset() {
+	*x = ListLoginLocksResponse{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[90]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListLoginLocksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListLoginLocksResponse) ProtoMessage() {}
+
+func (x *ListLoginLocksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[90]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *ListLoginLocksResponse) GetLocks() []*LoginLock {
+	if x != nil {
+		if x.xxx_hidden_Locks != nil {
+			return *x.xxx_hidden_Locks
+		}
+	}
+	return nil
+}
+
+func (x *ListLoginLocksResponse) SetLocks(v []*LoginLock) {
+	x.xxx_hidden_Locks = &v
+}
+
+type ListLoginLocksResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	// Latest to expire first, at most 1000.
+	Locks []*LoginLock
+}
+
+func (b0 ListLoginLocksResponse_builder) Build() *ListLoginLocksResponse {
+	m0 := &ListLoginLocksResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_Locks = &b.Locks
+	return m0
+}
+
+// Lift the locks of one account, from one address or from all of them.
+type ClearLoginLocksRequest struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Tenant      *string                `protobuf:"bytes,1,opt,name=tenant"`
+	xxx_hidden_Username    *string                `protobuf:"bytes,2,opt,name=username"`
+	xxx_hidden_IpHash      []byte                 `protobuf:"bytes,3,opt,name=ip_hash,json=ipHash"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *ClearLoginLocksRequest) Reset() {
+	*x = ClearLoginLocksRequest{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[91]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClearLoginLocksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClearLoginLocksRequest) ProtoMessage() {}
+
+func (x *ClearLoginLocksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[91]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *ClearLoginLocksRequest) GetTenant() string {
+	if x != nil {
+		if x.xxx_hidden_Tenant != nil {
+			return *x.xxx_hidden_Tenant
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *ClearLoginLocksRequest) GetUsername() string {
+	if x != nil {
+		if x.xxx_hidden_Username != nil {
+			return *x.xxx_hidden_Username
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *ClearLoginLocksRequest) GetIpHash() []byte {
+	if x != nil {
+		return x.xxx_hidden_IpHash
+	}
+	return nil
+}
+
+func (x *ClearLoginLocksRequest) SetTenant(v string) {
+	x.xxx_hidden_Tenant = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 3)
+}
+
+func (x *ClearLoginLocksRequest) SetUsername(v string) {
+	x.xxx_hidden_Username = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 3)
+}
+
+func (x *ClearLoginLocksRequest) SetIpHash(v []byte) {
+	if v == nil {
+		v = []byte{}
+	}
+	x.xxx_hidden_IpHash = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 3)
+}
+
+func (x *ClearLoginLocksRequest) HasTenant() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *ClearLoginLocksRequest) HasUsername() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *ClearLoginLocksRequest) HasIpHash() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 2)
+}
+
+func (x *ClearLoginLocksRequest) ClearTenant() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_Tenant = nil
+}
+
+func (x *ClearLoginLocksRequest) ClearUsername() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_Username = nil
+}
+
+func (x *ClearLoginLocksRequest) ClearIpHash() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 2)
+	x.xxx_hidden_IpHash = nil
+}
+
+type ClearLoginLocksRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	// Empty for the default tenant.
+	Tenant   *string
+	Username *string
+	// As listed; empty clears every address.
+	IpHash []byte
+}
+
+func (b0 ClearLoginLocksRequest_builder) Build() *ClearLoginLocksRequest {
+	m0 := &ClearLoginLocksRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Tenant != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 3)
+		x.xxx_hidden_Tenant = b.Tenant
+	}
+	if b.Username != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 3)
+		x.xxx_hidden_Username = b.Username
+	}
+	if b.IpHash != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 3)
+		x.xxx_hidden_IpHash = b.IpHash
+	}
+	return m0
+}
+
+type ClearLoginLocksResponse struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Cleared     int32                  `protobuf:"varint,1,opt,name=cleared"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *ClearLoginLocksResponse) Reset() {
+	*x = ClearLoginLocksResponse{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[92]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClearLoginLocksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClearLoginLocksResponse) ProtoMessage() {}
+
+func (x *ClearLoginLocksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[92]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *ClearLoginLocksResponse) GetCleared() int32 {
+	if x != nil {
+		return x.xxx_hidden_Cleared
+	}
+	return 0
+}
+
+func (x *ClearLoginLocksResponse) SetCleared(v int32) {
+	x.xxx_hidden_Cleared = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 1)
+}
+
+func (x *ClearLoginLocksResponse) HasCleared() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *ClearLoginLocksResponse) ClearCleared() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_Cleared = 0
+}
+
+type ClearLoginLocksResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	// Locks lifted.
+	Cleared *int32
+}
+
+func (b0 ClearLoginLocksResponse_builder) Build() *ClearLoginLocksResponse {
+	m0 := &ClearLoginLocksResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Cleared != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 1)
+		x.xxx_hidden_Cleared = *b.Cleared
+	}
+	return m0
+}
+
+// A network a user's calls may (allow) or may not come from. Once a user
+// has an allow rule, calls from networks no allow rule covers are refused.
+type IPRule struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Cidr        *string                `protobuf:"bytes,1,opt,name=cidr"`
+	xxx_hidden_Allow       bool                   `protobuf:"varint,2,opt,name=allow"`
+	xxx_hidden_CreatedAt   *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_at,json=createdAt"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *IPRule) Reset() {
+	*x = IPRule{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[93]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IPRule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IPRule) ProtoMessage() {}
+
+func (x *IPRule) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[93]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *IPRule) GetCidr() string {
+	if x != nil {
+		if x.xxx_hidden_Cidr != nil {
+			return *x.xxx_hidden_Cidr
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *IPRule) GetAllow() bool {
+	if x != nil {
+		return x.xxx_hidden_Allow
+	}
+	return false
+}
+
+func (x *IPRule) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.xxx_hidden_CreatedAt
+	}
+	return nil
+}
+
+func (x *IPRule) SetCidr(v string) {
+	x.xxx_hidden_Cidr = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 3)
+}
+
+func (x *IPRule) SetAllow(v bool) {
+	x.xxx_hidden_Allow = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 3)
+}
+
+func (x *IPRule) SetCreatedAt(v *timestamppb.Timestamp) {
+	x.xxx_hidden_CreatedAt = v
+}
+
+func (x *IPRule) HasCidr() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *IPRule) HasAllow() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *IPRule) HasCreatedAt() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_CreatedAt != nil
+}
+
+func (x *IPRule) ClearCidr() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_Cidr = nil
+}
+
+func (x *IPRule) ClearAllow() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_Allow = false
+}
+
+func (x *IPRule) ClearCreatedAt() {
+	x.xxx_hidden_CreatedAt = nil
+}
+
+type IPRule_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	// Network in CIDR notation, e.g. "10.8.0.0/16".
+	Cidr      *string
+	Allow     *bool
+	CreatedAt *timestamppb.Timestamp
+}
+
+func (b0 IPRule_builder) Build() *IPRule {
+	m0 := &IPRule{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Cidr != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 3)
+		x.xxx_hidden_Cidr = b.Cidr
+	}
+	if b.Allow != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 3)
+		x.xxx_hidden_Allow = *b.Allow
+	}
+	x.xxx_hidden_CreatedAt = b.CreatedAt
+	return m0
 }
 
-func (x *GetChangesResponse) Reset() {
-	*x = GetChangesResponse{}
-	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[11]
+type ListUserIPRulesRequest struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Tenant      *string                `protobuf:"bytes,1,opt,name=tenant"`
+	xxx_hidden_Username    *string                `protobuf:"bytes,2,opt,name=username"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *ListUserIPRulesRequest) Reset() {
+	*x = ListUserIPRulesRequest{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[94]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetChangesResponse) String() string {
+func (x *ListUserIPRulesRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetChangesResponse) ProtoMessage() {}
+func (*ListUserIPRulesRequest) ProtoMessage() {}
 
-func (x *GetChangesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[11]
+func (x *ListUserIPRulesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[94]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1309,57 +10691,105 @@ func (x *GetChangesResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-func (x *GetChangesResponse) GetChanges() []*Change {
+func (x *ListUserIPRulesRequest) GetTenant() string {
 	if x != nil {
-		if x.xxx_hidden_Changes != nil {
-			return *x.xxx_hidden_Changes
+		if x.xxx_hidden_Tenant != nil {
+			return *x.xxx_hidden_Tenant
 		}
+		return ""
 	}
-	return nil
+	return ""
 }
 
-func (x *GetChangesResponse) SetChanges(v []*Change) {
-	x.xxx_hidden_Changes = &v
+func (x *ListUserIPRulesRequest) GetUsername() string {
+	if x != nil {
+		if x.xxx_hidden_Username != nil {
+			return *x.xxx_hidden_Username
+		}
+		return ""
+	}
+	return ""
 }
 
-type GetChangesResponse_builder struct {
+func (x *ListUserIPRulesRequest) SetTenant(v string) {
+	x.xxx_hidden_Tenant = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 2)
+}
+
+func (x *ListUserIPRulesRequest) SetUsername(v string) {
+	x.xxx_hidden_Username = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 2)
+}
+
+func (x *ListUserIPRulesRequest) HasTenant() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *ListUserIPRulesRequest) HasUsername() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *ListUserIPRulesRequest) ClearTenant() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_Tenant = nil
+}
+
+func (x *ListUserIPRulesRequest) ClearUsername() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_Username = nil
+}
+
+type ListUserIPRulesRequest_builder struct {
 	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
 
-	Changes []*Change
+	// Empty for the default tenant.
+	Tenant   *string
+	Username *string
 }
 
-func (b0 GetChangesResponse_builder) Build() *GetChangesResponse {
-	m0 := &GetChangesResponse{}
+func (b0 ListUserIPRulesRequest_builder) Build() *ListUserIPRulesRequest {
+	m0 := &ListUserIPRulesRequest{}
 	b, x := &b0, m0
 	_, _ = b, x
-	x.xxx_hidden_Changes = &b.Changes
+	if b.Tenant != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 2)
+		x.xxx_hidden_Tenant = b.Tenant
+	}
+	if b.Username != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 2)
+		x.xxx_hidden_Username = b.Username
+	}
 	return m0
 }
 
-type GetItemRequest struct {
-	state                  protoimpl.MessageState `protogen:"opaque.v1"`
-	xxx_hidden_Id          *string                `protobuf:"bytes,1,opt,name=id"`
-	XXX_raceDetectHookData protoimpl.RaceDetectHookData
-	XXX_presence           [1]uint32
-	unknownFields          protoimpl.UnknownFields
-	sizeCache              protoimpl.SizeCache
+type ListUserIPRulesResponse struct {
+	state            protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Rules *[]*IPRule             `protobuf:"bytes,1,rep,name=rules"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
-func (x *GetItemRequest) Reset() {
-	*x = GetItemRequest{}
-	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[12]
+func (x *ListUserIPRulesResponse) Reset() {
+	*x = ListUserIPRulesResponse{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[95]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetItemRequest) String() string {
+func (x *ListUserIPRulesResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetItemRequest) ProtoMessage() {}
+func (*ListUserIPRulesResponse) ProtoMessage() {}
 
-func (x *GetItemRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[12]
+func (x *ListUserIPRulesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[95]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1370,78 +10800,61 @@ func (x *GetItemRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-func (x *GetItemRequest) GetId() string {
+func (x *ListUserIPRulesResponse) GetRules() []*IPRule {
 	if x != nil {
-		if x.xxx_hidden_Id != nil {
-			return *x.xxx_hidden_Id
+		if x.xxx_hidden_Rules != nil {
+			return *x.xxx_hidden_Rules
 		}
-		return ""
-	}
-	return ""
-}
-
-func (x *GetItemRequest) SetId(v string) {
-	x.xxx_hidden_Id = &v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 1)
-}
-
-func (x *GetItemRequest) HasId() bool {
-	if x == nil {
-		return false
 	}
-	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+	return nil
 }
 
-func (x *GetItemRequest) ClearId() {
-	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
-	x.xxx_hidden_Id = nil
+func (x *ListUserIPRulesResponse) SetRules(v []*IPRule) {
+	x.xxx_hidden_Rules = &v
 }
 
-type GetItemRequest_builder struct {
+type ListUserIPRulesResponse_builder struct {
 	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
 
-	Id *string
+	Rules []*IPRule
 }
 
-func (b0 GetItemRequest_builder) Build() *GetItemRequest {
-	m0 := &GetItemRequest{}
+func (b0 ListUserIPRulesResponse_builder) Build() *ListUserIPRulesResponse {
+	m0 := &ListUserIPRulesResponse{}
 	b, x := &b0, m0
 	_, _ = b, x
-	if b.Id != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 1)
-		x.xxx_hidden_Id = b.Id
-	}
+	x.xxx_hidden_Rules = &b.Rules
 	return m0
 }
 
-type GetItemResponse struct {
+// Add a rule, or change whether an existing rule for the network allows.
+type AddUserIPRuleRequest struct {
 	state                  protoimpl.MessageState `protogen:"opaque.v1"`
-	xxx_hidden_Id          *string                `protobuf:"bytes,1,opt,name=id"`
-	xxx_hidden_Ver         int64                  `protobuf:"varint,2,opt,name=ver"`
-	xxx_hidden_Deleted     bool                   `protobuf:"varint,3,opt,name=deleted"`
-	xxx_hidden_UpdatedAt   *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=updated_at,json=updatedAt"`
-	xxx_hidden_BlobEnc     *EncryptedBlob         `protobuf:"bytes,5,opt,name=blob_enc,json=blobEnc"`
+	xxx_hidden_Tenant      *string                `protobuf:"bytes,1,opt,name=tenant"`
+	xxx_hidden_Username    *string                `protobuf:"bytes,2,opt,name=username"`
+	xxx_hidden_Cidr        *string                `protobuf:"bytes,3,opt,name=cidr"`
+	xxx_hidden_Allow       bool                   `protobuf:"varint,4,opt,name=allow"`
 	XXX_raceDetectHookData protoimpl.RaceDetectHookData
 	XXX_presence           [1]uint32
 	unknownFields          protoimpl.UnknownFields
 	sizeCache              protoimpl.SizeCache
 }
 
-func (x *GetItemResponse) Reset() {
-	*x = GetItemResponse{}
-	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[13]
+func (x *AddUserIPRuleRequest) Reset() {
+	*x = AddUserIPRuleRequest{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[96]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetItemResponse) String() string {
+func (x *AddUserIPRuleRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetItemResponse) ProtoMessage() {}
+func (*AddUserIPRuleRequest) ProtoMessage() {}
 
-func (x *GetItemResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[13]
+func (x *AddUserIPRuleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[96]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1452,181 +10865,239 @@ func (x *GetItemResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-func (x *GetItemResponse) GetId() string {
+func (x *AddUserIPRuleRequest) GetTenant() string {
 	if x != nil {
-		if x.xxx_hidden_Id != nil {
-			return *x.xxx_hidden_Id
+		if x.xxx_hidden_Tenant != nil {
+			return *x.xxx_hidden_Tenant
 		}
 		return ""
 	}
 	return ""
 }
 
-func (x *GetItemResponse) GetVer() int64 {
-	if x != nil {
-		return x.xxx_hidden_Ver
-	}
-	return 0
-}
-
-func (x *GetItemResponse) GetDeleted() bool {
+func (x *AddUserIPRuleRequest) GetUsername() string {
 	if x != nil {
-		return x.xxx_hidden_Deleted
+		if x.xxx_hidden_Username != nil {
+			return *x.xxx_hidden_Username
+		}
+		return ""
 	}
-	return false
+	return ""
 }
 
-func (x *GetItemResponse) GetUpdatedAt() *timestamppb.Timestamp {
+func (x *AddUserIPRuleRequest) GetCidr() string {
 	if x != nil {
-		return x.xxx_hidden_UpdatedAt
+		if x.xxx_hidden_Cidr != nil {
+			return *x.xxx_hidden_Cidr
+		}
+		return ""
 	}
-	return nil
+	return ""
 }
 
-func (x *GetItemResponse) GetBlobEnc() *EncryptedBlob {
+func (x *AddUserIPRuleRequest) GetAllow() bool {
 	if x != nil {
-		return x.xxx_hidden_BlobEnc
+		return x.xxx_hidden_Allow
 	}
-	return nil
-}
-
-func (x *GetItemResponse) SetId(v string) {
-	x.xxx_hidden_Id = &v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 5)
+	return false
 }
 
-func (x *GetItemResponse) SetVer(v int64) {
-	x.xxx_hidden_Ver = v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 5)
+func (x *AddUserIPRuleRequest) SetTenant(v string) {
+	x.xxx_hidden_Tenant = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 4)
 }
 
-func (x *GetItemResponse) SetDeleted(v bool) {
-	x.xxx_hidden_Deleted = v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 5)
+func (x *AddUserIPRuleRequest) SetUsername(v string) {
+	x.xxx_hidden_Username = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 4)
 }
 
-func (x *GetItemResponse) SetUpdatedAt(v *timestamppb.Timestamp) {
-	x.xxx_hidden_UpdatedAt = v
+func (x *AddUserIPRuleRequest) SetCidr(v string) {
+	x.xxx_hidden_Cidr = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 4)
 }
 
-func (x *GetItemResponse) SetBlobEnc(v *EncryptedBlob) {
-	x.xxx_hidden_BlobEnc = v
+func (x *AddUserIPRuleRequest) SetAllow(v bool) {
+	x.xxx_hidden_Allow = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 3, 4)
 }
 
-func (x *GetItemResponse) HasId() bool {
+func (x *AddUserIPRuleRequest) HasTenant() bool {
 	if x == nil {
 		return false
 	}
 	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
 }
 
-func (x *GetItemResponse) HasVer() bool {
+func (x *AddUserIPRuleRequest) HasUsername() bool {
 	if x == nil {
 		return false
 	}
 	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
 }
 
-func (x *GetItemResponse) HasDeleted() bool {
+func (x *AddUserIPRuleRequest) HasCidr() bool {
 	if x == nil {
 		return false
 	}
 	return protoimpl.X.Present(&(x.XXX_presence[0]), 2)
 }
 
-func (x *GetItemResponse) HasUpdatedAt() bool {
-	if x == nil {
-		return false
-	}
-	return x.xxx_hidden_UpdatedAt != nil
-}
-
-func (x *GetItemResponse) HasBlobEnc() bool {
+func (x *AddUserIPRuleRequest) HasAllow() bool {
 	if x == nil {
 		return false
 	}
-	return x.xxx_hidden_BlobEnc != nil
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 3)
 }
 
-func (x *GetItemResponse) ClearId() {
+func (x *AddUserIPRuleRequest) ClearTenant() {
 	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
-	x.xxx_hidden_Id = nil
+	x.xxx_hidden_Tenant = nil
 }
 
-func (x *GetItemResponse) ClearVer() {
+func (x *AddUserIPRuleRequest) ClearUsername() {
 	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
-	x.xxx_hidden_Ver = 0
+	x.xxx_hidden_Username = nil
 }
 
-func (x *GetItemResponse) ClearDeleted() {
+func (x *AddUserIPRuleRequest) ClearCidr() {
 	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 2)
-	x.xxx_hidden_Deleted = false
-}
-
-func (x *GetItemResponse) ClearUpdatedAt() {
-	x.xxx_hidden_UpdatedAt = nil
+	x.xxx_hidden_Cidr = nil
 }
 
-func (x *GetItemResponse) ClearBlobEnc() {
-	x.xxx_hidden_BlobEnc = nil
+func (x *AddUserIPRuleRequest) ClearAllow() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 3)
+	x.xxx_hidden_Allow = false
 }
 
-type GetItemResponse_builder struct {
+type AddUserIPRuleRequest_builder struct {
 	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
 
-	Id        *string
-	Ver       *int64
-	Deleted   *bool
-	UpdatedAt *timestamppb.Timestamp
-	BlobEnc   *EncryptedBlob
+	// Empty for the default tenant.
+	Tenant   *string
+	Username *string
+	// CIDR or a single address.
+	Cidr  *string
+	Allow *bool
 }
 
-func (b0 GetItemResponse_builder) Build() *GetItemResponse {
-	m0 := &GetItemResponse{}
+func (b0 AddUserIPRuleRequest_builder) Build() *AddUserIPRuleRequest {
+	m0 := &AddUserIPRuleRequest{}
 	b, x := &b0, m0
 	_, _ = b, x
-	if b.Id != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 5)
-		x.xxx_hidden_Id = b.Id
+	if b.Tenant != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 4)
+		x.xxx_hidden_Tenant = b.Tenant
 	}
-	if b.Ver != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 5)
-		x.xxx_hidden_Ver = *b.Ver
+	if b.Username != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 4)
+		x.xxx_hidden_Username = b.Username
 	}
-	if b.Deleted != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 5)
-		x.xxx_hidden_Deleted = *b.Deleted
+	if b.Cidr != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 4)
+		x.xxx_hidden_Cidr = b.Cidr
 	}
-	x.xxx_hidden_UpdatedAt = b.UpdatedAt
-	x.xxx_hidden_BlobEnc = b.BlobEnc
+	if b.Allow != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 3, 4)
+		x.xxx_hidden_Allow = *b.Allow
+	}
+	return m0
+}
+
+type AddUserIPRuleResponse struct {
+	state           protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Rule *IPRule                `protobuf:"bytes,1,opt,name=rule"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *AddUserIPRuleResponse) Reset() {
+	*x = AddUserIPRuleResponse{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[97]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddUserIPRuleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddUserIPRuleResponse) ProtoMessage() {}
+
+func (x *AddUserIPRuleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[97]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *AddUserIPRuleResponse) GetRule() *IPRule {
+	if x != nil {
+		return x.xxx_hidden_Rule
+	}
+	return nil
+}
+
+func (x *AddUserIPRuleResponse) SetRule(v *IPRule) {
+	x.xxx_hidden_Rule = v
+}
+
+func (x *AddUserIPRuleResponse) HasRule() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_Rule != nil
+}
+
+func (x *AddUserIPRuleResponse) ClearRule() {
+	x.xxx_hidden_Rule = nil
+}
+
+type AddUserIPRuleResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Rule *IPRule
+}
+
+func (b0 AddUserIPRuleResponse_builder) Build() *AddUserIPRuleResponse {
+	m0 := &AddUserIPRuleResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_Rule = b.Rule
 	return m0
 }
 
-type DeleteItemRequest struct {
+type RemoveUserIPRuleRequest struct {
 	state                  protoimpl.MessageState `protogen:"opaque.v1"`
-	xxx_hidden_Id          *string                `protobuf:"bytes,1,opt,name=id"`
-	xxx_hidden_BaseVer     int64                  `protobuf:"varint,2,opt,name=base_ver,json=baseVer"`
+	xxx_hidden_Tenant      *string                `protobuf:"bytes,1,opt,name=tenant"`
+	xxx_hidden_Username    *string                `protobuf:"bytes,2,opt,name=username"`
+	xxx_hidden_Cidr        *string                `protobuf:"bytes,3,opt,name=cidr"`
 	XXX_raceDetectHookData protoimpl.RaceDetectHookData
 	XXX_presence           [1]uint32
 	unknownFields          protoimpl.UnknownFields
 	sizeCache              protoimpl.SizeCache
 }
 
-func (x *DeleteItemRequest) Reset() {
-	*x = DeleteItemRequest{}
-	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[14]
+func (x *RemoveUserIPRuleRequest) Reset() {
+	*x = RemoveUserIPRuleRequest{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[98]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeleteItemRequest) String() string {
+func (x *RemoveUserIPRuleRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteItemRequest) ProtoMessage() {}
+func (*RemoveUserIPRuleRequest) ProtoMessage() {}
 
-func (x *DeleteItemRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[14]
+func (x *RemoveUserIPRuleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[98]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1637,101 +11108,137 @@ func (x *DeleteItemRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-func (x *DeleteItemRequest) GetId() string {
+func (x *RemoveUserIPRuleRequest) GetTenant() string {
 	if x != nil {
-		if x.xxx_hidden_Id != nil {
-			return *x.xxx_hidden_Id
+		if x.xxx_hidden_Tenant != nil {
+			return *x.xxx_hidden_Tenant
 		}
 		return ""
 	}
 	return ""
 }
 
-func (x *DeleteItemRequest) GetBaseVer() int64 {
+func (x *RemoveUserIPRuleRequest) GetUsername() string {
 	if x != nil {
-		return x.xxx_hidden_BaseVer
+		if x.xxx_hidden_Username != nil {
+			return *x.xxx_hidden_Username
+		}
+		return ""
 	}
-	return 0
+	return ""
 }
 
-func (x *DeleteItemRequest) SetId(v string) {
-	x.xxx_hidden_Id = &v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 2)
+func (x *RemoveUserIPRuleRequest) GetCidr() string {
+	if x != nil {
+		if x.xxx_hidden_Cidr != nil {
+			return *x.xxx_hidden_Cidr
+		}
+		return ""
+	}
+	return ""
 }
 
-func (x *DeleteItemRequest) SetBaseVer(v int64) {
-	x.xxx_hidden_BaseVer = v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 2)
+func (x *RemoveUserIPRuleRequest) SetTenant(v string) {
+	x.xxx_hidden_Tenant = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 3)
 }
 
-func (x *DeleteItemRequest) HasId() bool {
+func (x *RemoveUserIPRuleRequest) SetUsername(v string) {
+	x.xxx_hidden_Username = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 3)
+}
+
+func (x *RemoveUserIPRuleRequest) SetCidr(v string) {
+	x.xxx_hidden_Cidr = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 3)
+}
+
+func (x *RemoveUserIPRuleRequest) HasTenant() bool {
 	if x == nil {
 		return false
 	}
 	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
 }
 
-func (x *DeleteItemRequest) HasBaseVer() bool {
+func (x *RemoveUserIPRuleRequest) HasUsername() bool {
 	if x == nil {
 		return false
 	}
 	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
 }
 
-func (x *DeleteItemRequest) ClearId() {
+func (x *RemoveUserIPRuleRequest) HasCidr() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 2)
+}
+
+func (x *RemoveUserIPRuleRequest) ClearTenant() {
 	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
-	x.xxx_hidden_Id = nil
+	x.xxx_hidden_Tenant = nil
 }
 
-func (x *DeleteItemRequest) ClearBaseVer() {
+func (x *RemoveUserIPRuleRequest) ClearUsername() {
 	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
-	x.xxx_hidden_BaseVer = 0
+	x.xxx_hidden_Username = nil
 }
 
-type DeleteItemRequest_builder struct {
+func (x *RemoveUserIPRuleRequest) ClearCidr() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 2)
+	x.xxx_hidden_Cidr = nil
+}
+
+type RemoveUserIPRuleRequest_builder struct {
 	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
 
-	Id      *string
-	BaseVer *int64
+	// Empty for the default tenant.
+	Tenant   *string
+	Username *string
+	// As listed.
+	Cidr *string
 }
 
-func (b0 DeleteItemRequest_builder) Build() *DeleteItemRequest {
-	m0 := &DeleteItemRequest{}
+func (b0 RemoveUserIPRuleRequest_builder) Build() *RemoveUserIPRuleRequest {
+	m0 := &RemoveUserIPRuleRequest{}
 	b, x := &b0, m0
 	_, _ = b, x
-	if b.Id != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 2)
-		x.xxx_hidden_Id = b.Id
+	if b.Tenant != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 3)
+		x.xxx_hidden_Tenant = b.Tenant
 	}
-	if b.BaseVer != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 2)
-		x.xxx_hidden_BaseVer = *b.BaseVer
+	if b.Username != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 3)
+		x.xxx_hidden_Username = b.Username
+	}
+	if b.Cidr != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 3)
+		x.xxx_hidden_Cidr = b.Cidr
 	}
 	return m0
 }
 
-type DeleteItemResponse struct {
-	state             protoimpl.MessageState `protogen:"opaque.v1"`
-	xxx_hidden_Result *ItemVersion           `protobuf:"bytes,1,opt,name=result"`
-	unknownFields     protoimpl.UnknownFields
-	sizeCache         protoimpl.SizeCache
+type RemoveUserIPRuleResponse struct {
+	state         protoimpl.MessageState `protogen:"opaque.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DeleteItemResponse) Reset() {
-	*x = DeleteItemResponse{}
-	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[15]
+func (x *RemoveUserIPRuleResponse) Reset() {
+	*x = RemoveUserIPRuleResponse{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[99]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeleteItemResponse) String() string {
+func (x *RemoveUserIPRuleResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteItemResponse) ProtoMessage() {}
+func (*RemoveUserIPRuleResponse) ProtoMessage() {}
 
-func (x *DeleteItemResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[15]
+func (x *RemoveUserIPRuleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[99]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1742,66 +11249,87 @@ func (x *DeleteItemResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-func (x *DeleteItemResponse) GetResult() *ItemVersion {
-	if x != nil {
-		return x.xxx_hidden_Result
-	}
-	return nil
+type RemoveUserIPRuleResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
 }
 
-func (x *DeleteItemResponse) SetResult(v *ItemVersion) {
-	x.xxx_hidden_Result = v
+func (b0 RemoveUserIPRuleResponse_builder) Build() *RemoveUserIPRuleResponse {
+	m0 := &RemoveUserIPRuleResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	return m0
 }
 
-func (x *DeleteItemResponse) HasResult() bool {
-	if x == nil {
-		return false
-	}
-	return x.xxx_hidden_Result != nil
+type GetServerInfoRequest struct {
+	state         protoimpl.MessageState `protogen:"opaque.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DeleteItemResponse) ClearResult() {
-	x.xxx_hidden_Result = nil
+func (x *GetServerInfoRequest) Reset() {
+	*x = GetServerInfoRequest{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[100]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-type DeleteItemResponse_builder struct {
+func (x *GetServerInfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetServerInfoRequest) ProtoMessage() {}
+
+func (x *GetServerInfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[100]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+type GetServerInfoRequest_builder struct {
 	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
 
-	Result *ItemVersion
 }
 
-func (b0 DeleteItemResponse_builder) Build() *DeleteItemResponse {
-	m0 := &DeleteItemResponse{}
+func (b0 GetServerInfoRequest_builder) Build() *GetServerInfoRequest {
+	m0 := &GetServerInfoRequest{}
 	b, x := &b0, m0
 	_, _ = b, x
-	x.xxx_hidden_Result = b.Result
 	return m0
 }
 
-type SetWrappedDEKRequest struct {
-	state                  protoimpl.MessageState `protogen:"opaque.v1"`
-	xxx_hidden_WrappedDek  []byte                 `protobuf:"bytes,1,opt,name=wrapped_dek,json=wrappedDek"`
-	XXX_raceDetectHookData protoimpl.RaceDetectHookData
-	XXX_presence           [1]uint32
-	unknownFields          protoimpl.UnknownFields
-	sizeCache              protoimpl.SizeCache
+type GetServerInfoResponse struct {
+	state                       protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_ServerVersion    *string                `protobuf:"bytes,1,opt,name=server_version,json=serverVersion"`
+	xxx_hidden_Features         []string               `protobuf:"bytes,2,rep,name=features"`
+	xxx_hidden_MinClientVersion *string                `protobuf:"bytes,3,opt,name=min_client_version,json=minClientVersion"`
+	XXX_raceDetectHookData      protoimpl.RaceDetectHookData
+	XXX_presence                [1]uint32
+	unknownFields               protoimpl.UnknownFields
+	sizeCache                   protoimpl.SizeCache
 }
 
-func (x *SetWrappedDEKRequest) Reset() {
-	*x = SetWrappedDEKRequest{}
-	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[16]
+func (x *GetServerInfoResponse) Reset() {
+	*x = GetServerInfoResponse{}
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[101]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SetWrappedDEKRequest) String() string {
+func (x *GetServerInfoResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SetWrappedDEKRequest) ProtoMessage() {}
+func (*GetServerInfoResponse) ProtoMessage() {}
 
-func (x *SetWrappedDEKRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[16]
+func (x *GetServerInfoResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[101]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1812,90 +11340,98 @@ func (x *SetWrappedDEKRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-func (x *SetWrappedDEKRequest) GetWrappedDek() []byte {
+func (x *GetServerInfoResponse) GetServerVersion() string {
 	if x != nil {
-		return x.xxx_hidden_WrappedDek
+		if x.xxx_hidden_ServerVersion != nil {
+			return *x.xxx_hidden_ServerVersion
+		}
+		return ""
 	}
-	return nil
+	return ""
 }
 
-func (x *SetWrappedDEKRequest) SetWrappedDek(v []byte) {
-	if v == nil {
-		v = []byte{}
+func (x *GetServerInfoResponse) GetFeatures() []string {
+	if x != nil {
+		return x.xxx_hidden_Features
 	}
-	x.xxx_hidden_WrappedDek = v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 1)
+	return nil
 }
 
-func (x *SetWrappedDEKRequest) HasWrappedDek() bool {
-	if x == nil {
-		return false
+func (x *GetServerInfoResponse) GetMinClientVersion() string {
+	if x != nil {
+		if x.xxx_hidden_MinClientVersion != nil {
+			return *x.xxx_hidden_MinClientVersion
+		}
+		return ""
 	}
-	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+	return ""
 }
 
-func (x *SetWrappedDEKRequest) ClearWrappedDek() {
-	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
-	x.xxx_hidden_WrappedDek = nil
+func (x *GetServerInfoResponse) SetServerVersion(v string) {
+	x.xxx_hidden_ServerVersion = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 3)
 }
 
-type SetWrappedDEKRequest_builder struct {
-	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
-
-	WrappedDek []byte
+func (x *GetServerInfoResponse) SetFeatures(v []string) {
+	x.xxx_hidden_Features = v
 }
 
-func (b0 SetWrappedDEKRequest_builder) Build() *SetWrappedDEKRequest {
-	m0 := &SetWrappedDEKRequest{}
-	b, x := &b0, m0
-	_, _ = b, x
-	if b.WrappedDek != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 1)
-		x.xxx_hidden_WrappedDek = b.WrappedDek
-	}
-	return m0
+func (x *GetServerInfoResponse) SetMinClientVersion(v string) {
+	x.xxx_hidden_MinClientVersion = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 3)
 }
 
-type SetWrappedDEKResponse struct {
-	state         protoimpl.MessageState `protogen:"opaque.v1"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *GetServerInfoResponse) HasServerVersion() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
 }
 
-func (x *SetWrappedDEKResponse) Reset() {
-	*x = SetWrappedDEKResponse{}
-	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[17]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *GetServerInfoResponse) HasMinClientVersion() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 2)
 }
 
-func (x *SetWrappedDEKResponse) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *GetServerInfoResponse) ClearServerVersion() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_ServerVersion = nil
 }
 
-func (*SetWrappedDEKResponse) ProtoMessage() {}
-
-func (x *SetWrappedDEKResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_gophkeeper_v1_gophkeeper_proto_msgTypes[17]
-	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
-	}
-	return mi.MessageOf(x)
+func (x *GetServerInfoResponse) ClearMinClientVersion() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 2)
+	x.xxx_hidden_MinClientVersion = nil
 }
 
-type SetWrappedDEKResponse_builder struct {
+type GetServerInfoResponse_builder struct {
 	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
 
+	// The server build, e.g. v1.4.0, or "dev".
+	ServerVersion *string
+	// Features this server offers: the protocol ones every server of this
+	// version has (best_effort, page_token, delete_items, item_stream,
+	// long_poll, sessions, warnings) and those its configuration turns on
+	// (webhooks, push, challenge).
+	Features []string
+	// Calls from clients naming an older version fail; empty if any will do.
+	MinClientVersion *string
 }
 
-func (b0 SetWrappedDEKResponse_builder) Build() *SetWrappedDEKResponse {
-	m0 := &SetWrappedDEKResponse{}
+func (b0 GetServerInfoResponse_builder) Build() *GetServerInfoResponse {
+	m0 := &GetServerInfoResponse{}
 	b, x := &b0, m0
 	_, _ = b, x
+	if b.ServerVersion != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 3)
+		x.xxx_hidden_ServerVersion = b.ServerVersion
+	}
+	x.xxx_hidden_Features = b.Features
+	if b.MinClientVersion != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 3)
+		x.xxx_hidden_MinClientVersion = b.MinClientVersion
+	}
 	return m0
 }
 
@@ -1903,133 +11439,692 @@ var File_gophkeeper_v1_gophkeeper_proto protoreflect.FileDescriptor
 
 const file_gophkeeper_v1_gophkeeper_proto_rawDesc = "" +
 	"\n" +
-	"\x1egophkeeper/v1/gophkeeper.proto\x12\rgophkeeper.v1\x1a\x1fgoogle/protobuf/timestamp.proto\x1a!google/protobuf/go_features.proto\"I\n" +
+	"\x1egophkeeper/v1/gophkeeper.proto\x12\rgophkeeper.v1\x1a\x1fgoogle/protobuf/timestamp.proto\x1a!google/protobuf/go_features.proto\"\xa0\x01\n" +
 	"\x0fRegisterRequest\x12\x1a\n" +
 	"\busername\x18\x01 \x01(\tR\busername\x12\x1a\n" +
-	"\bpassword\x18\x02 \x01(\tR\bpassword\"+\n" +
+	"\bpassword\x18\x02 \x01(\tR\bpassword\x12-\n" +
+	"\x12challenge_response\x18\x03 \x01(\tR\x11challengeResponse\x12&\n" +
+	"\x0freturn_kek_salt\x18\x04 \x01(\bR\rreturnKekSalt\"F\n" +
 	"\x10RegisterResponse\x12\x17\n" +
-	"\auser_id\x18\x01 \x01(\tR\x06userId\"F\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x19\n" +
+	"\bkek_salt\x18\x02 \x01(\fR\akekSalt\"\xb0\x01\n" +
 	"\fLoginRequest\x12\x1a\n" +
 	"\busername\x18\x01 \x01(\tR\busername\x12\x1a\n" +
-	"\bpassword\x18\x02 \x01(\tR\bpassword\"\xac\x01\n" +
+	"\bpassword\x18\x02 \x01(\tR\bpassword\x12-\n" +
+	"\x12challenge_response\x18\x03 \x01(\tR\x11challengeResponse\x12!\n" +
+	"\fdevice_label\x18\x04 \x01(\tR\vdeviceLabel\x12\x16\n" +
+	"\x06scopes\x18\x05 \x03(\tR\x06scopes\"\x8b\x02\n" +
 	"\rLoginResponse\x12!\n" +
 	"\faccess_token\x18\x01 \x01(\tR\vaccessToken\x12#\n" +
 	"\rrefresh_token\x18\x02 \x01(\tR\frefreshToken\x12\x19\n" +
 	"\bkek_salt\x18\x03 \x01(\fR\akekSalt\x12\x1f\n" +
 	"\vwrapped_dek\x18\x04 \x01(\fR\n" +
 	"wrappedDek\x12\x17\n" +
-	"\auser_id\x18\x05 \x01(\tR\x06userId\"/\n" +
+	"\auser_id\x18\x05 \x01(\tR\x06userId\x12'\n" +
+	"\x0ffailed_attempts\x18\x06 \x01(\x05R\x0efailedAttempts\x12\x1c\n" +
+	"\tanomalies\x18\a \x03(\tR\tanomalies\x12\x16\n" +
+	"\x06scopes\x18\b \x03(\tR\x06scopes\"/\n" +
 	"\rEncryptedBlob\x12\x1e\n" +
 	"\n" +
 	"ciphertext\x18\x01 \x01(\fR\n" +
-	"ciphertext\"p\n" +
+	"ciphertext\"k\n" +
+	"\tItemHints\x127\n" +
+	"\n" +
+	"size_class\x18\x01 \x01(\x0e2\x18.gophkeeper.v1.SizeClassR\tsizeClass\x12%\n" +
+	"\x0eschema_version\x18\x02 \x01(\x05R\rschemaVersion\"\xa0\x01\n" +
 	"\n" +
 	"UpsertItem\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x19\n" +
 	"\bbase_ver\x18\x02 \x01(\x03R\abaseVer\x127\n" +
-	"\bblob_enc\x18\x03 \x01(\v2\x1c.gophkeeper.v1.EncryptedBlobR\ablobEnc\"q\n" +
+	"\bblob_enc\x18\x03 \x01(\v2\x1c.gophkeeper.v1.EncryptedBlobR\ablobEnc\x12.\n" +
+	"\x05hints\x18\x04 \x01(\v2\x18.gophkeeper.v1.ItemHintsR\x05hints\"q\n" +
 	"\vItemVersion\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
 	"\anew_ver\x18\x02 \x01(\x03R\x06newVer\x129\n" +
 	"\n" +
-	"updated_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"\xb8\x01\n" +
+	"updated_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"\xfa\x01\n" +
 	"\x06Change\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x10\n" +
 	"\x03ver\x18\x02 \x01(\x03R\x03ver\x12\x18\n" +
 	"\adeleted\x18\x03 \x01(\bR\adeleted\x129\n" +
 	"\n" +
 	"updated_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x127\n" +
-	"\bblob_enc\x18\x05 \x01(\v2\x1c.gophkeeper.v1.EncryptedBlobR\ablobEnc\"E\n" +
+	"\bblob_enc\x18\x05 \x01(\v2\x1c.gophkeeper.v1.EncryptedBlobR\ablobEnc\x12.\n" +
+	"\x05hints\x18\x06 \x01(\v2\x18.gophkeeper.v1.ItemHintsR\x05hints\x12\x10\n" +
+	"\x03seq\x18\a \x01(\x03R\x03seq\"f\n" +
 	"\x12UpsertItemsRequest\x12/\n" +
-	"\x05items\x18\x01 \x03(\v2\x19.gophkeeper.v1.UpsertItemR\x05items\"K\n" +
+	"\x05items\x18\x01 \x03(\v2\x19.gophkeeper.v1.UpsertItemR\x05items\x12\x1f\n" +
+	"\vbest_effort\x18\x02 \x01(\bR\n" +
+	"bestEffort\"\xbd\x01\n" +
 	"\x13UpsertItemsResponse\x124\n" +
-	"\aresults\x18\x01 \x03(\v2\x1a.gophkeeper.v1.ItemVersionR\aresults\"0\n" +
+	"\aresults\x18\x01 \x03(\v2\x1a.gophkeeper.v1.ItemVersionR\aresults\x12<\n" +
+	"\fitem_results\x18\x02 \x03(\v2\x19.gophkeeper.v1.ItemResultR\vitemResults\x122\n" +
+	"\bwarnings\x18\x03 \x03(\v2\x16.gophkeeper.v1.WarningR\bwarnings\"P\n" +
+	"\aWarning\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x17\n" +
+	"\aitem_id\x18\x03 \x01(\tR\x06itemId\"\xda\x01\n" +
 	"\x11GetChangesRequest\x12\x1b\n" +
-	"\tsince_ver\x18\x01 \x01(\x03R\bsinceVer\"E\n" +
+	"\tsince_ver\x18\x01 \x01(\x03R\bsinceVer\x12,\n" +
+	"\x12max_schema_version\x18\x02 \x01(\x05R\x10maxSchemaVersion\x12\x1b\n" +
+	"\tpage_size\x18\x03 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x04 \x01(\tR\tpageToken\x12\x1b\n" +
+	"\tsince_seq\x18\x05 \x01(\x03R\bsinceSeq\x12!\n" +
+	"\fsync_session\x18\x06 \x01(\tR\vsyncSession\"\xed\x01\n" +
 	"\x12GetChangesResponse\x12/\n" +
-	"\achanges\x18\x01 \x03(\v2\x15.gophkeeper.v1.ChangeR\achanges\" \n" +
+	"\achanges\x18\x01 \x03(\v2\x15.gophkeeper.v1.ChangeR\achanges\x12;\n" +
+	"\vserver_time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"serverTime\x12\x1c\n" +
+	"\twatermark\x18\x03 \x01(\x03R\twatermark\x12&\n" +
+	"\x0fnext_page_token\x18\x04 \x01(\tR\rnextPageToken\x12#\n" +
+	"\rseq_watermark\x18\x05 \x01(\x03R\fseqWatermark\"\xa6\x01\n" +
+	"\x19GetChangesLongPollRequest\x12\x1b\n" +
+	"\tsince_ver\x18\x01 \x01(\x03R\bsinceVer\x12!\n" +
+	"\fwait_seconds\x18\x02 \x01(\x05R\vwaitSeconds\x12,\n" +
+	"\x12max_schema_version\x18\x03 \x01(\x05R\x10maxSchemaVersion\x12\x1b\n" +
+	"\tsince_seq\x18\x04 \x01(\x03R\bsinceSeq\"q\n" +
+	"\x1aGetChangesLongPollResponse\x129\n" +
+	"\x06result\x18\x01 \x01(\v2!.gophkeeper.v1.GetChangesResponseR\x06result\x12\x18\n" +
+	"\achanged\x18\x02 \x01(\bR\achanged\"2\n" +
 	"\x0eGetItemRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\"\xc1\x01\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x10\n" +
+	"\x03ver\x18\x02 \x01(\x03R\x03ver\"\xf1\x01\n" +
 	"\x0fGetItemResponse\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x10\n" +
 	"\x03ver\x18\x02 \x01(\x03R\x03ver\x12\x18\n" +
 	"\adeleted\x18\x03 \x01(\bR\adeleted\x129\n" +
 	"\n" +
 	"updated_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x127\n" +
-	"\bblob_enc\x18\x05 \x01(\v2\x1c.gophkeeper.v1.EncryptedBlobR\ablobEnc\">\n" +
+	"\bblob_enc\x18\x05 \x01(\v2\x1c.gophkeeper.v1.EncryptedBlobR\ablobEnc\x12.\n" +
+	"\x05hints\x18\x06 \x01(\v2\x18.gophkeeper.v1.ItemHintsR\x05hints\"\xe8\x01\n" +
+	"\fGetItemChunk\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x10\n" +
+	"\x03ver\x18\x02 \x01(\x03R\x03ver\x12\x18\n" +
+	"\adeleted\x18\x03 \x01(\bR\adeleted\x129\n" +
+	"\n" +
+	"updated_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12\x1d\n" +
+	"\n" +
+	"total_size\x18\x05 \x01(\x03R\ttotalSize\x12\x12\n" +
+	"\x04data\x18\x06 \x01(\fR\x04data\x12.\n" +
+	"\x05hints\x18\a \x01(\v2\x18.gophkeeper.v1.ItemHintsR\x05hints\"\x12\n" +
+	"\x10BeginSyncRequest\"\x98\x01\n" +
+	"\x11BeginSyncResponse\x12!\n" +
+	"\fsync_session\x18\x01 \x01(\tR\vsyncSession\x12#\n" +
+	"\rseq_watermark\x18\x02 \x01(\x03R\fseqWatermark\x12;\n" +
+	"\vserver_time\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"serverTime\"=\n" +
+	"\x12ExportItemsRequest\x12'\n" +
+	"\x0finclude_deleted\x18\x01 \x01(\bR\x0eincludeDeleted\"B\n" +
+	"\x13ExportItemsResponse\x12+\n" +
+	"\x05items\x18\x01 \x03(\v2\x15.gophkeeper.v1.ChangeR\x05items\">\n" +
 	"\x11DeleteItemRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x19\n" +
 	"\bbase_ver\x18\x02 \x01(\x03R\abaseVer\"H\n" +
 	"\x12DeleteItemResponse\x122\n" +
-	"\x06result\x18\x01 \x01(\v2\x1a.gophkeeper.v1.ItemVersionR\x06result\"7\n" +
+	"\x06result\x18\x01 \x01(\v2\x1a.gophkeeper.v1.ItemVersionR\x06result\"4\n" +
+	"\aItemRef\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x19\n" +
+	"\bbase_ver\x18\x02 \x01(\x03R\abaseVer\"\x9c\x01\n" +
+	"\n" +
+	"ItemResult\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x121\n" +
+	"\x06status\x18\x02 \x01(\x0e2\x19.gophkeeper.v1.ItemStatusR\x06status\x12\x10\n" +
+	"\x03ver\x18\x03 \x01(\x03R\x03ver\x129\n" +
+	"\n" +
+	"updated_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"B\n" +
+	"\x12DeleteItemsRequest\x12,\n" +
+	"\x05items\x18\x01 \x03(\v2\x16.gophkeeper.v1.ItemRefR\x05items\"d\n" +
+	"\x13DeleteItemsResponse\x123\n" +
+	"\aresults\x18\x01 \x03(\v2\x19.gophkeeper.v1.ItemResultR\aresults\x12\x18\n" +
+	"\aapplied\x18\x02 \x01(\bR\aapplied\"~\n" +
+	"\x15ApplyChangeSetRequest\x123\n" +
+	"\aupserts\x18\x01 \x03(\v2\x19.gophkeeper.v1.UpsertItemR\aupserts\x120\n" +
+	"\adeletes\x18\x02 \x03(\v2\x16.gophkeeper.v1.ItemRefR\adeletes\"\xea\x01\n" +
+	"\x16ApplyChangeSetResponse\x12@\n" +
+	"\x0eupsert_results\x18\x01 \x03(\v2\x19.gophkeeper.v1.ItemResultR\rupsertResults\x12@\n" +
+	"\x0edelete_results\x18\x02 \x03(\v2\x19.gophkeeper.v1.ItemResultR\rdeleteResults\x12\x18\n" +
+	"\aapplied\x18\x03 \x01(\bR\aapplied\x122\n" +
+	"\bwarnings\x18\x04 \x03(\v2\x16.gophkeeper.v1.WarningR\bwarnings\"\x11\n" +
+	"\x0fGetStatsRequest\"\xd8\x01\n" +
+	"\x10GetStatsResponse\x12\x14\n" +
+	"\x05items\x18\x01 \x01(\x03R\x05items\x12\x18\n" +
+	"\adeleted\x18\x02 \x01(\x03R\adeleted\x12\x17\n" +
+	"\amax_ver\x18\x03 \x01(\x03R\x06maxVer\x12\x1f\n" +
+	"\vtotal_bytes\x18\x04 \x01(\x03R\n" +
+	"totalBytes\x12=\n" +
+	"\flast_updated\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\vlastUpdated\x12\x1b\n" +
+	"\tmax_items\x18\x06 \x01(\x03R\bmaxItems\";\n" +
+	"\x12VerifyVaultRequest\x12%\n" +
+	"\x0einclude_leaves\x18\x01 \x01(\bR\rincludeLeaves\"[\n" +
+	"\tVaultLeaf\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x10\n" +
+	"\x03ver\x18\x02 \x01(\x03R\x03ver\x12\x18\n" +
+	"\adeleted\x18\x03 \x01(\bR\adeleted\x12\x12\n" +
+	"\x04hash\x18\x04 \x01(\fR\x04hash\"q\n" +
+	"\x13VerifyVaultResponse\x12\x12\n" +
+	"\x04root\x18\x01 \x01(\fR\x04root\x12\x14\n" +
+	"\x05items\x18\x02 \x01(\x03R\x05items\x120\n" +
+	"\x06leaves\x18\x03 \x03(\v2\x18.gophkeeper.v1.VaultLeafR\x06leaves\"i\n" +
 	"\x14SetWrappedDEKRequest\x12\x1f\n" +
 	"\vwrapped_dek\x18\x01 \x01(\fR\n" +
-	"wrappedDek\"\x17\n" +
-	"\x15SetWrappedDEKResponse2\xbf\x04\n" +
+	"wrappedDek\x120\n" +
+	"\x14previous_wrapped_dek\x18\x02 \x01(\fR\x12previousWrappedDek\"\x17\n" +
+	"\x15SetWrappedDEKResponse\"f\n" +
+	"\aWebhook\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x10\n" +
+	"\x03url\x18\x02 \x01(\tR\x03url\x129\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"(\n" +
+	"\x14CreateWebhookRequest\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\"a\n" +
+	"\x15CreateWebhookResponse\x120\n" +
+	"\awebhook\x18\x01 \x01(\v2\x16.gophkeeper.v1.WebhookR\awebhook\x12\x16\n" +
+	"\x06secret\x18\x02 \x01(\tR\x06secret\"\x15\n" +
+	"\x13ListWebhooksRequest\"J\n" +
+	"\x14ListWebhooksResponse\x122\n" +
+	"\bwebhooks\x18\x01 \x03(\v2\x16.gophkeeper.v1.WebhookR\bwebhooks\"&\n" +
+	"\x14DeleteWebhookRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x17\n" +
+	"\x15DeleteWebhookResponse\"\x83\x01\n" +
+	"\x06Device\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1a\n" +
+	"\bplatform\x18\x02 \x01(\tR\bplatform\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x129\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"]\n" +
+	"\x15RegisterDeviceRequest\x12\x1a\n" +
+	"\bplatform\x18\x01 \x01(\tR\bplatform\x12\x14\n" +
+	"\x05token\x18\x02 \x01(\tR\x05token\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\"G\n" +
+	"\x16RegisterDeviceResponse\x12-\n" +
+	"\x06device\x18\x01 \x01(\v2\x15.gophkeeper.v1.DeviceR\x06device\"\x14\n" +
+	"\x12ListDevicesRequest\"d\n" +
+	"\x13ListDevicesResponse\x12/\n" +
+	"\adevices\x18\x01 \x03(\v2\x15.gophkeeper.v1.DeviceR\adevices\x12\x1c\n" +
+	"\tplatforms\x18\x02 \x03(\tR\tplatforms\")\n" +
+	"\x17UnregisterDeviceRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x1a\n" +
+	"\x18UnregisterDeviceResponse\".\n" +
+	"\x16GetLoginHistoryRequest\x12\x14\n" +
+	"\x05limit\x18\x01 \x01(\x05R\x05limit\"\x87\x01\n" +
+	"\fLoginAttempt\x12*\n" +
+	"\x02at\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x02at\x12\x17\n" +
+	"\aip_hash\x18\x02 \x01(\fR\x06ipHash\x12\x18\n" +
+	"\asuccess\x18\x03 \x01(\bR\asuccess\x12\x18\n" +
+	"\acountry\x18\x04 \x01(\tR\acountry\"R\n" +
+	"\x17GetLoginHistoryResponse\x127\n" +
+	"\battempts\x18\x01 \x03(\v2\x1b.gophkeeper.v1.LoginAttemptR\battempts\"5\n" +
+	"\x0eRefreshRequest\x12#\n" +
+	"\rrefresh_token\x18\x01 \x01(\tR\frefreshToken\"Y\n" +
+	"\x0fRefreshResponse\x12!\n" +
+	"\faccess_token\x18\x01 \x01(\tR\vaccessToken\x12#\n" +
+	"\rrefresh_token\x18\x02 \x01(\tR\frefreshToken\"\x95\x02\n" +
+	"\aSession\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05label\x18\x02 \x01(\tR\x05label\x129\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12<\n" +
+	"\flast_used_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"lastUsedAt\x129\n" +
+	"\n" +
+	"expires_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x12\x18\n" +
+	"\acurrent\x18\x06 \x01(\bR\acurrent\x12\x16\n" +
+	"\x06scopes\x18\a \x03(\tR\x06scopes\"\x15\n" +
+	"\x13ListSessionsRequest\"J\n" +
+	"\x14ListSessionsResponse\x122\n" +
+	"\bsessions\x18\x01 \x03(\v2\x16.gophkeeper.v1.SessionR\bsessions\"&\n" +
+	"\x14RevokeSessionRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x17\n" +
+	"\x15RevokeSessionResponse\"\xdd\x01\n" +
+	"\n" +
+	"ItemAccess\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x14\n" +
+	"\x05label\x18\x02 \x01(\tR\x05label\x125\n" +
+	"\bfirst_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\afirstAt\x123\n" +
+	"\alast_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x06lastAt\x12\x14\n" +
+	"\x05reads\x18\x05 \x01(\x03R\x05reads\x12\x18\n" +
+	"\acurrent\x18\x06 \x01(\bR\acurrent\")\n" +
+	"\x17GetItemAccessLogRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"Q\n" +
+	"\x18GetItemAccessLogResponse\x125\n" +
+	"\baccesses\x18\x01 \x03(\v2\x19.gophkeeper.v1.ItemAccessR\baccesses\"0\n" +
+	"\x12FreezeVaultRequest\x12\x1a\n" +
+	"\bpassword\x18\x01 \x01(\tR\bpassword\"N\n" +
+	"\x13FreezeVaultResponse\x127\n" +
+	"\tfrozen_at\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\bfrozenAt\"2\n" +
+	"\x14UnfreezeVaultRequest\x12\x1a\n" +
+	"\bpassword\x18\x01 \x01(\tR\bpassword\"\x17\n" +
+	"\x15UnfreezeVaultResponse\"6\n" +
+	"\x18SetDuressPasswordRequest\x12\x1a\n" +
+	"\bpassword\x18\x01 \x01(\tR\bpassword\"\x1b\n" +
+	"\x19SetDuressPasswordResponse\"\x1d\n" +
+	"\x1bRemoveDuressPasswordRequest\"\x1e\n" +
+	"\x1cRemoveDuressPasswordResponse\"1\n" +
+	"\x15SetDiagnosticsRequest\x12\x18\n" +
+	"\aenabled\x18\x01 \x01(\bR\aenabled\"2\n" +
+	"\x16SetDiagnosticsResponse\x12\x18\n" +
+	"\aenabled\x18\x01 \x01(\bR\aenabled\"|\n" +
+	"\x13RollbackUserRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x123\n" +
+	"\ato_time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x06toTime\x12\x17\n" +
+	"\adry_run\x18\x03 \x01(\bR\x06dryRun\"\xcf\x01\n" +
+	"\x14RollbackUserResponse\x12\x1f\n" +
+	"\vrollback_id\x18\x01 \x01(\x03R\n" +
+	"rollbackId\x12\x1a\n" +
+	"\brestored\x18\x02 \x01(\x03R\brestored\x12\x18\n" +
+	"\aremoved\x18\x03 \x01(\x03R\aremoved\x12-\n" +
+	"\x12discarded_versions\x18\x04 \x01(\x03R\x11discardedVersions\x12\x18\n" +
+	"\askipped\x18\x05 \x01(\x03R\askipped\x12\x17\n" +
+	"\adry_run\x18\x06 \x01(\bR\x06dryRun\"N\n" +
+	"\x1bCheckUserConsistencyRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x16\n" +
+	"\x06repair\x18\x02 \x01(\bR\x06repair\"y\n" +
+	"\x14ConsistencyViolation\x12\x17\n" +
+	"\aitem_id\x18\x01 \x01(\tR\x06itemId\x12\x14\n" +
+	"\x05check\x18\x02 \x01(\tR\x05check\x12\x16\n" +
+	"\x06detail\x18\x03 \x01(\tR\x06detail\x12\x1a\n" +
+	"\brepaired\x18\x04 \x01(\bR\brepaired\"\x88\x01\n" +
+	"\x1cCheckUserConsistencyResponse\x12#\n" +
+	"\ritems_checked\x18\x01 \x01(\x03R\fitemsChecked\x12C\n" +
+	"\n" +
+	"violations\x18\x02 \x03(\v2#.gophkeeper.v1.ConsistencyViolationR\n" +
+	"violations\"-\n" +
+	"\x12SuspendUserRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\"\x15\n" +
+	"\x13SuspendUserResponse\"0\n" +
+	"\x15ReactivateUserRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\"\x18\n" +
+	"\x16ReactivateUserResponse\"Y\n" +
+	"\x15GetUsageReportRequest\x12\x16\n" +
+	"\x06tenant\x18\x01 \x01(\tR\x06tenant\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12\x12\n" +
+	"\x04days\x18\x03 \x01(\x05R\x04days\"\x8c\x01\n" +
+	"\vUsageSample\x12,\n" +
+	"\x03day\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x03day\x12\x14\n" +
+	"\x05items\x18\x02 \x01(\x03R\x05items\x12\x14\n" +
+	"\x05bytes\x18\x03 \x01(\x03R\x05bytes\x12#\n" +
+	"\rhistory_bytes\x18\x04 \x01(\x03R\fhistoryBytes\"\xd9\x02\n" +
+	"\tUserUsage\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x16\n" +
+	"\x06tenant\x18\x02 \x01(\tR\x06tenant\x12\x14\n" +
+	"\x05items\x18\x03 \x01(\x03R\x05items\x12\x1e\n" +
+	"\n" +
+	"tombstones\x18\x04 \x01(\x03R\n" +
+	"tombstones\x12\x14\n" +
+	"\x05bytes\x18\x05 \x01(\x03R\x05bytes\x12#\n" +
+	"\rhistory_bytes\x18\x06 \x01(\x03R\fhistoryBytes\x129\n" +
+	"\n" +
+	"last_write\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tlastWrite\x129\n" +
+	"\n" +
+	"last_login\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tlastLogin\x124\n" +
+	"\ahistory\x18\t \x03(\v2\x1a.gophkeeper.v1.UsageSampleR\ahistory\"y\n" +
+	"\x16GetUsageReportResponse\x12/\n" +
+	"\x05as_of\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04asOf\x12.\n" +
+	"\x05users\x18\x02 \x03(\v2\x18.gophkeeper.v1.UserUsageR\x05users\"\xc2\x01\n" +
+	"\tLoginLock\x12\x16\n" +
+	"\x06tenant\x18\x01 \x01(\tR\x06tenant\x12\x1a\n" +
+	"\busername\x18\x02 \x01(\tR\busername\x12\x17\n" +
+	"\aip_hash\x18\x03 \x01(\fR\x06ipHash\x12'\n" +
+	"\x0ffailed_attempts\x18\x04 \x01(\x05R\x0efailedAttempts\x12?\n" +
+	"\rblocked_until\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\fblockedUntil\"\x17\n" +
+	"\x15ListLoginLocksRequest\"H\n" +
+	"\x16ListLoginLocksResponse\x12.\n" +
+	"\x05locks\x18\x01 \x03(\v2\x18.gophkeeper.v1.LoginLockR\x05locks\"e\n" +
+	"\x16ClearLoginLocksRequest\x12\x16\n" +
+	"\x06tenant\x18\x01 \x01(\tR\x06tenant\x12\x1a\n" +
+	"\busername\x18\x02 \x01(\tR\busername\x12\x17\n" +
+	"\aip_hash\x18\x03 \x01(\fR\x06ipHash\"3\n" +
+	"\x17ClearLoginLocksResponse\x12\x18\n" +
+	"\acleared\x18\x01 \x01(\x05R\acleared\"m\n" +
+	"\x06IPRule\x12\x12\n" +
+	"\x04cidr\x18\x01 \x01(\tR\x04cidr\x12\x14\n" +
+	"\x05allow\x18\x02 \x01(\bR\x05allow\x129\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"L\n" +
+	"\x16ListUserIPRulesRequest\x12\x16\n" +
+	"\x06tenant\x18\x01 \x01(\tR\x06tenant\x12\x1a\n" +
+	"\busername\x18\x02 \x01(\tR\busername\"F\n" +
+	"\x17ListUserIPRulesResponse\x12+\n" +
+	"\x05rules\x18\x01 \x03(\v2\x15.gophkeeper.v1.IPRuleR\x05rules\"t\n" +
+	"\x14AddUserIPRuleRequest\x12\x16\n" +
+	"\x06tenant\x18\x01 \x01(\tR\x06tenant\x12\x1a\n" +
+	"\busername\x18\x02 \x01(\tR\busername\x12\x12\n" +
+	"\x04cidr\x18\x03 \x01(\tR\x04cidr\x12\x14\n" +
+	"\x05allow\x18\x04 \x01(\bR\x05allow\"B\n" +
+	"\x15AddUserIPRuleResponse\x12)\n" +
+	"\x04rule\x18\x01 \x01(\v2\x15.gophkeeper.v1.IPRuleR\x04rule\"a\n" +
+	"\x17RemoveUserIPRuleRequest\x12\x16\n" +
+	"\x06tenant\x18\x01 \x01(\tR\x06tenant\x12\x1a\n" +
+	"\busername\x18\x02 \x01(\tR\busername\x12\x12\n" +
+	"\x04cidr\x18\x03 \x01(\tR\x04cidr\"\x1a\n" +
+	"\x18RemoveUserIPRuleResponse\"\x16\n" +
+	"\x14GetServerInfoRequest\"\x88\x01\n" +
+	"\x15GetServerInfoResponse\x12%\n" +
+	"\x0eserver_version\x18\x01 \x01(\tR\rserverVersion\x12\x1a\n" +
+	"\bfeatures\x18\x02 \x03(\tR\bfeatures\x12,\n" +
+	"\x12min_client_version\x18\x03 \x01(\tR\x10minClientVersion*S\n" +
+	"\tSizeClass\x12\x1a\n" +
+	"\x16SIZE_CLASS_UNSPECIFIED\x10\x00\x12\x14\n" +
+	"\x10SIZE_CLASS_SMALL\x10\x01\x12\x14\n" +
+	"\x10SIZE_CLASS_LARGE\x10\x02*\x93\x01\n" +
+	"\n" +
+	"ItemStatus\x12\x1b\n" +
+	"\x17ITEM_STATUS_UNSPECIFIED\x10\x00\x12\x12\n" +
+	"\x0eITEM_STATUS_OK\x10\x01\x12 \n" +
+	"\x1cITEM_STATUS_VERSION_CONFLICT\x10\x02\x12\x19\n" +
+	"\x15ITEM_STATUS_NOT_FOUND\x10\x03\x12\x17\n" +
+	"\x13ITEM_STATUS_ABORTED\x10\x042\xd1\x15\n" +
 	"\n" +
 	"GophKeeper\x12K\n" +
 	"\bRegister\x12\x1e.gophkeeper.v1.RegisterRequest\x1a\x1f.gophkeeper.v1.RegisterResponse\x12B\n" +
 	"\x05Login\x12\x1b.gophkeeper.v1.LoginRequest\x1a\x1c.gophkeeper.v1.LoginResponse\x12T\n" +
 	"\vUpsertItems\x12!.gophkeeper.v1.UpsertItemsRequest\x1a\".gophkeeper.v1.UpsertItemsResponse\x12Q\n" +
 	"\n" +
-	"GetChanges\x12 .gophkeeper.v1.GetChangesRequest\x1a!.gophkeeper.v1.GetChangesResponse\x12H\n" +
-	"\aGetItem\x12\x1d.gophkeeper.v1.GetItemRequest\x1a\x1e.gophkeeper.v1.GetItemResponse\x12Q\n" +
+	"GetChanges\x12 .gophkeeper.v1.GetChangesRequest\x1a!.gophkeeper.v1.GetChangesResponse\x12i\n" +
+	"\x12GetChangesLongPoll\x12(.gophkeeper.v1.GetChangesLongPollRequest\x1a).gophkeeper.v1.GetChangesLongPollResponse\x12H\n" +
+	"\aGetItem\x12\x1d.gophkeeper.v1.GetItemRequest\x1a\x1e.gophkeeper.v1.GetItemResponse\x12M\n" +
+	"\rGetItemStream\x12\x1d.gophkeeper.v1.GetItemRequest\x1a\x1b.gophkeeper.v1.GetItemChunk0\x01\x12V\n" +
+	"\vExportItems\x12!.gophkeeper.v1.ExportItemsRequest\x1a\".gophkeeper.v1.ExportItemsResponse0\x01\x12N\n" +
+	"\tBeginSync\x12\x1f.gophkeeper.v1.BeginSyncRequest\x1a .gophkeeper.v1.BeginSyncResponse\x12Q\n" +
 	"\n" +
-	"DeleteItem\x12 .gophkeeper.v1.DeleteItemRequest\x1a!.gophkeeper.v1.DeleteItemResponse\x12Z\n" +
-	"\rSetWrappedDEK\x12#.gophkeeper.v1.SetWrappedDEKRequest\x1a$.gophkeeper.v1.SetWrappedDEKResponseBLZBgithub.com/and161185/goph-keeper/gen/go/gophkeeper/v1;gophkeeperv1\x92\x03\x05\xd2>\x02\x10\x03b\beditionsp\xe8\a"
-
-var file_gophkeeper_v1_gophkeeper_proto_msgTypes = make([]protoimpl.MessageInfo, 18)
+	"DeleteItem\x12 .gophkeeper.v1.DeleteItemRequest\x1a!.gophkeeper.v1.DeleteItemResponse\x12T\n" +
+	"\vDeleteItems\x12!.gophkeeper.v1.DeleteItemsRequest\x1a\".gophkeeper.v1.DeleteItemsResponse\x12]\n" +
+	"\x0eApplyChangeSet\x12$.gophkeeper.v1.ApplyChangeSetRequest\x1a%.gophkeeper.v1.ApplyChangeSetResponse\x12Z\n" +
+	"\rSetWrappedDEK\x12#.gophkeeper.v1.SetWrappedDEKRequest\x1a$.gophkeeper.v1.SetWrappedDEKResponse\x12K\n" +
+	"\bGetStats\x12\x1e.gophkeeper.v1.GetStatsRequest\x1a\x1f.gophkeeper.v1.GetStatsResponse\x12T\n" +
+	"\vVerifyVault\x12!.gophkeeper.v1.VerifyVaultRequest\x1a\".gophkeeper.v1.VerifyVaultResponse\x12Z\n" +
+	"\rCreateWebhook\x12#.gophkeeper.v1.CreateWebhookRequest\x1a$.gophkeeper.v1.CreateWebhookResponse\x12W\n" +
+	"\fListWebhooks\x12\".gophkeeper.v1.ListWebhooksRequest\x1a#.gophkeeper.v1.ListWebhooksResponse\x12Z\n" +
+	"\rDeleteWebhook\x12#.gophkeeper.v1.DeleteWebhookRequest\x1a$.gophkeeper.v1.DeleteWebhookResponse\x12]\n" +
+	"\x0eRegisterDevice\x12$.gophkeeper.v1.RegisterDeviceRequest\x1a%.gophkeeper.v1.RegisterDeviceResponse\x12T\n" +
+	"\vListDevices\x12!.gophkeeper.v1.ListDevicesRequest\x1a\".gophkeeper.v1.ListDevicesResponse\x12c\n" +
+	"\x10UnregisterDevice\x12&.gophkeeper.v1.UnregisterDeviceRequest\x1a'.gophkeeper.v1.UnregisterDeviceResponse\x12`\n" +
+	"\x0fGetLoginHistory\x12%.gophkeeper.v1.GetLoginHistoryRequest\x1a&.gophkeeper.v1.GetLoginHistoryResponse\x12H\n" +
+	"\aRefresh\x12\x1d.gophkeeper.v1.RefreshRequest\x1a\x1e.gophkeeper.v1.RefreshResponse\x12W\n" +
+	"\fListSessions\x12\".gophkeeper.v1.ListSessionsRequest\x1a#.gophkeeper.v1.ListSessionsResponse\x12Z\n" +
+	"\rRevokeSession\x12#.gophkeeper.v1.RevokeSessionRequest\x1a$.gophkeeper.v1.RevokeSessionResponse\x12c\n" +
+	"\x10GetItemAccessLog\x12&.gophkeeper.v1.GetItemAccessLogRequest\x1a'.gophkeeper.v1.GetItemAccessLogResponse\x12T\n" +
+	"\vFreezeVault\x12!.gophkeeper.v1.FreezeVaultRequest\x1a\".gophkeeper.v1.FreezeVaultResponse\x12Z\n" +
+	"\rUnfreezeVault\x12#.gophkeeper.v1.UnfreezeVaultRequest\x1a$.gophkeeper.v1.UnfreezeVaultResponse\x12f\n" +
+	"\x11SetDuressPassword\x12'.gophkeeper.v1.SetDuressPasswordRequest\x1a(.gophkeeper.v1.SetDuressPasswordResponse\x12o\n" +
+	"\x14RemoveDuressPassword\x12*.gophkeeper.v1.RemoveDuressPasswordRequest\x1a+.gophkeeper.v1.RemoveDuressPasswordResponse\x12Z\n" +
+	"\rGetServerInfo\x12#.gophkeeper.v1.GetServerInfoRequest\x1a$.gophkeeper.v1.GetServerInfoResponse2\xaf\b\n" +
+	"\fAdminService\x12]\n" +
+	"\x0eSetDiagnostics\x12$.gophkeeper.v1.SetDiagnosticsRequest\x1a%.gophkeeper.v1.SetDiagnosticsResponse\x12W\n" +
+	"\fRollbackUser\x12\".gophkeeper.v1.RollbackUserRequest\x1a#.gophkeeper.v1.RollbackUserResponse\x12o\n" +
+	"\x14CheckUserConsistency\x12*.gophkeeper.v1.CheckUserConsistencyRequest\x1a+.gophkeeper.v1.CheckUserConsistencyResponse\x12T\n" +
+	"\vSuspendUser\x12!.gophkeeper.v1.SuspendUserRequest\x1a\".gophkeeper.v1.SuspendUserResponse\x12]\n" +
+	"\x0eReactivateUser\x12$.gophkeeper.v1.ReactivateUserRequest\x1a%.gophkeeper.v1.ReactivateUserResponse\x12]\n" +
+	"\x0eGetUsageReport\x12$.gophkeeper.v1.GetUsageReportRequest\x1a%.gophkeeper.v1.GetUsageReportResponse\x12]\n" +
+	"\x0eListLoginLocks\x12$.gophkeeper.v1.ListLoginLocksRequest\x1a%.gophkeeper.v1.ListLoginLocksResponse\x12`\n" +
+	"\x0fClearLoginLocks\x12%.gophkeeper.v1.ClearLoginLocksRequest\x1a&.gophkeeper.v1.ClearLoginLocksResponse\x12`\n" +
+	"\x0fListUserIPRules\x12%.gophkeeper.v1.ListUserIPRulesRequest\x1a&.gophkeeper.v1.ListUserIPRulesResponse\x12Z\n" +
+	"\rAddUserIPRule\x12#.gophkeeper.v1.AddUserIPRuleRequest\x1a$.gophkeeper.v1.AddUserIPRuleResponse\x12c\n" +
+	"\x10RemoveUserIPRule\x12&.gophkeeper.v1.RemoveUserIPRuleRequest\x1a'.gophkeeper.v1.RemoveUserIPRuleResponseBLZBgithub.com/and161185/goph-keeper/gen/go/gophkeeper/v1;gophkeeperv1\x92\x03\x05\xd2>\x02\x10\x03b\beditionsp\xe8\a"
+
+var file_gophkeeper_v1_gophkeeper_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_gophkeeper_v1_gophkeeper_proto_msgTypes = make([]protoimpl.MessageInfo, 102)
 var file_gophkeeper_v1_gophkeeper_proto_goTypes = []any{
-	(*RegisterRequest)(nil),       // 0: gophkeeper.v1.RegisterRequest
-	(*RegisterResponse)(nil),      // 1: gophkeeper.v1.RegisterResponse
-	(*LoginRequest)(nil),          // 2: gophkeeper.v1.LoginRequest
-	(*LoginResponse)(nil),         // 3: gophkeeper.v1.LoginResponse
-	(*EncryptedBlob)(nil),         // 4: gophkeeper.v1.EncryptedBlob
-	(*UpsertItem)(nil),            // 5: gophkeeper.v1.UpsertItem
-	(*ItemVersion)(nil),           // 6: gophkeeper.v1.ItemVersion
-	(*Change)(nil),                // 7: gophkeeper.v1.Change
-	(*UpsertItemsRequest)(nil),    // 8: gophkeeper.v1.UpsertItemsRequest
-	(*UpsertItemsResponse)(nil),   // 9: gophkeeper.v1.UpsertItemsResponse
-	(*GetChangesRequest)(nil),     // 10: gophkeeper.v1.GetChangesRequest
-	(*GetChangesResponse)(nil),    // 11: gophkeeper.v1.GetChangesResponse
-	(*GetItemRequest)(nil),        // 12: gophkeeper.v1.GetItemRequest
-	(*GetItemResponse)(nil),       // 13: gophkeeper.v1.GetItemResponse
-	(*DeleteItemRequest)(nil),     // 14: gophkeeper.v1.DeleteItemRequest
-	(*DeleteItemResponse)(nil),    // 15: gophkeeper.v1.DeleteItemResponse
-	(*SetWrappedDEKRequest)(nil),  // 16: gophkeeper.v1.SetWrappedDEKRequest
-	(*SetWrappedDEKResponse)(nil), // 17: gophkeeper.v1.SetWrappedDEKResponse
-	(*timestamppb.Timestamp)(nil), // 18: google.protobuf.Timestamp
+	(SizeClass)(0),                       // 0: gophkeeper.v1.SizeClass
+	(ItemStatus)(0),                      // 1: gophkeeper.v1.ItemStatus
+	(*RegisterRequest)(nil),              // 2: gophkeeper.v1.RegisterRequest
+	(*RegisterResponse)(nil),             // 3: gophkeeper.v1.RegisterResponse
+	(*LoginRequest)(nil),                 // 4: gophkeeper.v1.LoginRequest
+	(*LoginResponse)(nil),                // 5: gophkeeper.v1.LoginResponse
+	(*EncryptedBlob)(nil),                // 6: gophkeeper.v1.EncryptedBlob
+	(*ItemHints)(nil),                    // 7: gophkeeper.v1.ItemHints
+	(*UpsertItem)(nil),                   // 8: gophkeeper.v1.UpsertItem
+	(*ItemVersion)(nil),                  // 9: gophkeeper.v1.ItemVersion
+	(*Change)(nil),                       // 10: gophkeeper.v1.Change
+	(*UpsertItemsRequest)(nil),           // 11: gophkeeper.v1.UpsertItemsRequest
+	(*UpsertItemsResponse)(nil),          // 12: gophkeeper.v1.UpsertItemsResponse
+	(*Warning)(nil),                      // 13: gophkeeper.v1.Warning
+	(*GetChangesRequest)(nil),            // 14: gophkeeper.v1.GetChangesRequest
+	(*GetChangesResponse)(nil),           // 15: gophkeeper.v1.GetChangesResponse
+	(*GetChangesLongPollRequest)(nil),    // 16: gophkeeper.v1.GetChangesLongPollRequest
+	(*GetChangesLongPollResponse)(nil),   // 17: gophkeeper.v1.GetChangesLongPollResponse
+	(*GetItemRequest)(nil),               // 18: gophkeeper.v1.GetItemRequest
+	(*GetItemResponse)(nil),              // 19: gophkeeper.v1.GetItemResponse
+	(*GetItemChunk)(nil),                 // 20: gophkeeper.v1.GetItemChunk
+	(*BeginSyncRequest)(nil),             // 21: gophkeeper.v1.BeginSyncRequest
+	(*BeginSyncResponse)(nil),            // 22: gophkeeper.v1.BeginSyncResponse
+	(*ExportItemsRequest)(nil),           // 23: gophkeeper.v1.ExportItemsRequest
+	(*ExportItemsResponse)(nil),          // 24: gophkeeper.v1.ExportItemsResponse
+	(*DeleteItemRequest)(nil),            // 25: gophkeeper.v1.DeleteItemRequest
+	(*DeleteItemResponse)(nil),           // 26: gophkeeper.v1.DeleteItemResponse
+	(*ItemRef)(nil),                      // 27: gophkeeper.v1.ItemRef
+	(*ItemResult)(nil),                   // 28: gophkeeper.v1.ItemResult
+	(*DeleteItemsRequest)(nil),           // 29: gophkeeper.v1.DeleteItemsRequest
+	(*DeleteItemsResponse)(nil),          // 30: gophkeeper.v1.DeleteItemsResponse
+	(*ApplyChangeSetRequest)(nil),        // 31: gophkeeper.v1.ApplyChangeSetRequest
+	(*ApplyChangeSetResponse)(nil),       // 32: gophkeeper.v1.ApplyChangeSetResponse
+	(*GetStatsRequest)(nil),              // 33: gophkeeper.v1.GetStatsRequest
+	(*GetStatsResponse)(nil),             // 34: gophkeeper.v1.GetStatsResponse
+	(*VerifyVaultRequest)(nil),           // 35: gophkeeper.v1.VerifyVaultRequest
+	(*VaultLeaf)(nil),                    // 36: gophkeeper.v1.VaultLeaf
+	(*VerifyVaultResponse)(nil),          // 37: gophkeeper.v1.VerifyVaultResponse
+	(*SetWrappedDEKRequest)(nil),         // 38: gophkeeper.v1.SetWrappedDEKRequest
+	(*SetWrappedDEKResponse)(nil),        // 39: gophkeeper.v1.SetWrappedDEKResponse
+	(*Webhook)(nil),                      // 40: gophkeeper.v1.Webhook
+	(*CreateWebhookRequest)(nil),         // 41: gophkeeper.v1.CreateWebhookRequest
+	(*CreateWebhookResponse)(nil),        // 42: gophkeeper.v1.CreateWebhookResponse
+	(*ListWebhooksRequest)(nil),          // 43: gophkeeper.v1.ListWebhooksRequest
+	(*ListWebhooksResponse)(nil),         // 44: gophkeeper.v1.ListWebhooksResponse
+	(*DeleteWebhookRequest)(nil),         // 45: gophkeeper.v1.DeleteWebhookRequest
+	(*DeleteWebhookResponse)(nil),        // 46: gophkeeper.v1.DeleteWebhookResponse
+	(*Device)(nil),                       // 47: gophkeeper.v1.Device
+	(*RegisterDeviceRequest)(nil),        // 48: gophkeeper.v1.RegisterDeviceRequest
+	(*RegisterDeviceResponse)(nil),       // 49: gophkeeper.v1.RegisterDeviceResponse
+	(*ListDevicesRequest)(nil),           // 50: gophkeeper.v1.ListDevicesRequest
+	(*ListDevicesResponse)(nil),          // 51: gophkeeper.v1.ListDevicesResponse
+	(*UnregisterDeviceRequest)(nil),      // 52: gophkeeper.v1.UnregisterDeviceRequest
+	(*UnregisterDeviceResponse)(nil),     // 53: gophkeeper.v1.UnregisterDeviceResponse
+	(*GetLoginHistoryRequest)(nil),       // 54: gophkeeper.v1.GetLoginHistoryRequest
+	(*LoginAttempt)(nil),                 // 55: gophkeeper.v1.LoginAttempt
+	(*GetLoginHistoryResponse)(nil),      // 56: gophkeeper.v1.GetLoginHistoryResponse
+	(*RefreshRequest)(nil),               // 57: gophkeeper.v1.RefreshRequest
+	(*RefreshResponse)(nil),              // 58: gophkeeper.v1.RefreshResponse
+	(*Session)(nil),                      // 59: gophkeeper.v1.Session
+	(*ListSessionsRequest)(nil),          // 60: gophkeeper.v1.ListSessionsRequest
+	(*ListSessionsResponse)(nil),         // 61: gophkeeper.v1.ListSessionsResponse
+	(*RevokeSessionRequest)(nil),         // 62: gophkeeper.v1.RevokeSessionRequest
+	(*RevokeSessionResponse)(nil),        // 63: gophkeeper.v1.RevokeSessionResponse
+	(*ItemAccess)(nil),                   // 64: gophkeeper.v1.ItemAccess
+	(*GetItemAccessLogRequest)(nil),      // 65: gophkeeper.v1.GetItemAccessLogRequest
+	(*GetItemAccessLogResponse)(nil),     // 66: gophkeeper.v1.GetItemAccessLogResponse
+	(*FreezeVaultRequest)(nil),           // 67: gophkeeper.v1.FreezeVaultRequest
+	(*FreezeVaultResponse)(nil),          // 68: gophkeeper.v1.FreezeVaultResponse
+	(*UnfreezeVaultRequest)(nil),         // 69: gophkeeper.v1.UnfreezeVaultRequest
+	(*UnfreezeVaultResponse)(nil),        // 70: gophkeeper.v1.UnfreezeVaultResponse
+	(*SetDuressPasswordRequest)(nil),     // 71: gophkeeper.v1.SetDuressPasswordRequest
+	(*SetDuressPasswordResponse)(nil),    // 72: gophkeeper.v1.SetDuressPasswordResponse
+	(*RemoveDuressPasswordRequest)(nil),  // 73: gophkeeper.v1.RemoveDuressPasswordRequest
+	(*RemoveDuressPasswordResponse)(nil), // 74: gophkeeper.v1.RemoveDuressPasswordResponse
+	(*SetDiagnosticsRequest)(nil),        // 75: gophkeeper.v1.SetDiagnosticsRequest
+	(*SetDiagnosticsResponse)(nil),       // 76: gophkeeper.v1.SetDiagnosticsResponse
+	(*RollbackUserRequest)(nil),          // 77: gophkeeper.v1.RollbackUserRequest
+	(*RollbackUserResponse)(nil),         // 78: gophkeeper.v1.RollbackUserResponse
+	(*CheckUserConsistencyRequest)(nil),  // 79: gophkeeper.v1.CheckUserConsistencyRequest
+	(*ConsistencyViolation)(nil),         // 80: gophkeeper.v1.ConsistencyViolation
+	(*CheckUserConsistencyResponse)(nil), // 81: gophkeeper.v1.CheckUserConsistencyResponse
+	(*SuspendUserRequest)(nil),           // 82: gophkeeper.v1.SuspendUserRequest
+	(*SuspendUserResponse)(nil),          // 83: gophkeeper.v1.SuspendUserResponse
+	(*ReactivateUserRequest)(nil),        // 84: gophkeeper.v1.ReactivateUserRequest
+	(*ReactivateUserResponse)(nil),       // 85: gophkeeper.v1.ReactivateUserResponse
+	(*GetUsageReportRequest)(nil),        // 86: gophkeeper.v1.GetUsageReportRequest
+	(*UsageSample)(nil),                  // 87: gophkeeper.v1.UsageSample
+	(*UserUsage)(nil),                    // 88: gophkeeper.v1.UserUsage
+	(*GetUsageReportResponse)(nil),       // 89: gophkeeper.v1.GetUsageReportResponse
+	(*LoginLock)(nil),                    // 90: gophkeeper.v1.LoginLock
+	(*ListLoginLocksRequest)(nil),        // 91: gophkeeper.v1.ListLoginLocksRequest
+	(*ListLoginLocksResponse)(nil),       // 92: gophkeeper.v1.ListLoginLocksResponse
+	(*ClearLoginLocksRequest)(nil),       // 93: gophkeeper.v1.ClearLoginLocksRequest
+	(*ClearLoginLocksResponse)(nil),      // 94: gophkeeper.v1.ClearLoginLocksResponse
+	(*IPRule)(nil),                       // 95: gophkeeper.v1.IPRule
+	(*ListUserIPRulesRequest)(nil),       // 96: gophkeeper.v1.ListUserIPRulesRequest
+	(*ListUserIPRulesResponse)(nil),      // 97: gophkeeper.v1.ListUserIPRulesResponse
+	(*AddUserIPRuleRequest)(nil),         // 98: gophkeeper.v1.AddUserIPRuleRequest
+	(*AddUserIPRuleResponse)(nil),        // 99: gophkeeper.v1.AddUserIPRuleResponse
+	(*RemoveUserIPRuleRequest)(nil),      // 100: gophkeeper.v1.RemoveUserIPRuleRequest
+	(*RemoveUserIPRuleResponse)(nil),     // 101: gophkeeper.v1.RemoveUserIPRuleResponse
+	(*GetServerInfoRequest)(nil),         // 102: gophkeeper.v1.GetServerInfoRequest
+	(*GetServerInfoResponse)(nil),        // 103: gophkeeper.v1.GetServerInfoResponse
+	(*timestamppb.Timestamp)(nil),        // 104: google.protobuf.Timestamp
 }
 var file_gophkeeper_v1_gophkeeper_proto_depIdxs = []int32{
-	4,  // 0: gophkeeper.v1.UpsertItem.blob_enc:type_name -> gophkeeper.v1.EncryptedBlob
-	18, // 1: gophkeeper.v1.ItemVersion.updated_at:type_name -> google.protobuf.Timestamp
-	18, // 2: gophkeeper.v1.Change.updated_at:type_name -> google.protobuf.Timestamp
-	4,  // 3: gophkeeper.v1.Change.blob_enc:type_name -> gophkeeper.v1.EncryptedBlob
-	5,  // 4: gophkeeper.v1.UpsertItemsRequest.items:type_name -> gophkeeper.v1.UpsertItem
-	6,  // 5: gophkeeper.v1.UpsertItemsResponse.results:type_name -> gophkeeper.v1.ItemVersion
-	7,  // 6: gophkeeper.v1.GetChangesResponse.changes:type_name -> gophkeeper.v1.Change
-	18, // 7: gophkeeper.v1.GetItemResponse.updated_at:type_name -> google.protobuf.Timestamp
-	4,  // 8: gophkeeper.v1.GetItemResponse.blob_enc:type_name -> gophkeeper.v1.EncryptedBlob
-	6,  // 9: gophkeeper.v1.DeleteItemResponse.result:type_name -> gophkeeper.v1.ItemVersion
-	0,  // 10: gophkeeper.v1.GophKeeper.Register:input_type -> gophkeeper.v1.RegisterRequest
-	2,  // 11: gophkeeper.v1.GophKeeper.Login:input_type -> gophkeeper.v1.LoginRequest
-	8,  // 12: gophkeeper.v1.GophKeeper.UpsertItems:input_type -> gophkeeper.v1.UpsertItemsRequest
-	10, // 13: gophkeeper.v1.GophKeeper.GetChanges:input_type -> gophkeeper.v1.GetChangesRequest
-	12, // 14: gophkeeper.v1.GophKeeper.GetItem:input_type -> gophkeeper.v1.GetItemRequest
-	14, // 15: gophkeeper.v1.GophKeeper.DeleteItem:input_type -> gophkeeper.v1.DeleteItemRequest
-	16, // 16: gophkeeper.v1.GophKeeper.SetWrappedDEK:input_type -> gophkeeper.v1.SetWrappedDEKRequest
-	1,  // 17: gophkeeper.v1.GophKeeper.Register:output_type -> gophkeeper.v1.RegisterResponse
-	3,  // 18: gophkeeper.v1.GophKeeper.Login:output_type -> gophkeeper.v1.LoginResponse
-	9,  // 19: gophkeeper.v1.GophKeeper.UpsertItems:output_type -> gophkeeper.v1.UpsertItemsResponse
-	11, // 20: gophkeeper.v1.GophKeeper.GetChanges:output_type -> gophkeeper.v1.GetChangesResponse
-	13, // 21: gophkeeper.v1.GophKeeper.GetItem:output_type -> gophkeeper.v1.GetItemResponse
-	15, // 22: gophkeeper.v1.GophKeeper.DeleteItem:output_type -> gophkeeper.v1.DeleteItemResponse
-	17, // 23: gophkeeper.v1.GophKeeper.SetWrappedDEK:output_type -> gophkeeper.v1.SetWrappedDEKResponse
-	17, // [17:24] is the sub-list for method output_type
-	10, // [10:17] is the sub-list for method input_type
-	10, // [10:10] is the sub-list for extension type_name
-	10, // [10:10] is the sub-list for extension extendee
-	0,  // [0:10] is the sub-list for field type_name
+	0,   // 0: gophkeeper.v1.ItemHints.size_class:type_name -> gophkeeper.v1.SizeClass
+	6,   // 1: gophkeeper.v1.UpsertItem.blob_enc:type_name -> gophkeeper.v1.EncryptedBlob
+	7,   // 2: gophkeeper.v1.UpsertItem.hints:type_name -> gophkeeper.v1.ItemHints
+	104, // 3: gophkeeper.v1.ItemVersion.updated_at:type_name -> google.protobuf.Timestamp
+	104, // 4: gophkeeper.v1.Change.updated_at:type_name -> google.protobuf.Timestamp
+	6,   // 5: gophkeeper.v1.Change.blob_enc:type_name -> gophkeeper.v1.EncryptedBlob
+	7,   // 6: gophkeeper.v1.Change.hints:type_name -> gophkeeper.v1.ItemHints
+	8,   // 7: gophkeeper.v1.UpsertItemsRequest.items:type_name -> gophkeeper.v1.UpsertItem
+	9,   // 8: gophkeeper.v1.UpsertItemsResponse.results:type_name -> gophkeeper.v1.ItemVersion
+	28,  // 9: gophkeeper.v1.UpsertItemsResponse.item_results:type_name -> gophkeeper.v1.ItemResult
+	13,  // 10: gophkeeper.v1.UpsertItemsResponse.warnings:type_name -> gophkeeper.v1.Warning
+	10,  // 11: gophkeeper.v1.GetChangesResponse.changes:type_name -> gophkeeper.v1.Change
+	104, // 12: gophkeeper.v1.GetChangesResponse.server_time:type_name -> google.protobuf.Timestamp
+	15,  // 13: gophkeeper.v1.GetChangesLongPollResponse.result:type_name -> gophkeeper.v1.GetChangesResponse
+	104, // 14: gophkeeper.v1.GetItemResponse.updated_at:type_name -> google.protobuf.Timestamp
+	6,   // 15: gophkeeper.v1.GetItemResponse.blob_enc:type_name -> gophkeeper.v1.EncryptedBlob
+	7,   // 16: gophkeeper.v1.GetItemResponse.hints:type_name -> gophkeeper.v1.ItemHints
+	104, // 17: gophkeeper.v1.GetItemChunk.updated_at:type_name -> google.protobuf.Timestamp
+	7,   // 18: gophkeeper.v1.GetItemChunk.hints:type_name -> gophkeeper.v1.ItemHints
+	104, // 19: gophkeeper.v1.BeginSyncResponse.server_time:type_name -> google.protobuf.Timestamp
+	10,  // 20: gophkeeper.v1.ExportItemsResponse.items:type_name -> gophkeeper.v1.Change
+	9,   // 21: gophkeeper.v1.DeleteItemResponse.result:type_name -> gophkeeper.v1.ItemVersion
+	1,   // 22: gophkeeper.v1.ItemResult.status:type_name -> gophkeeper.v1.ItemStatus
+	104, // 23: gophkeeper.v1.ItemResult.updated_at:type_name -> google.protobuf.Timestamp
+	27,  // 24: gophkeeper.v1.DeleteItemsRequest.items:type_name -> gophkeeper.v1.ItemRef
+	28,  // 25: gophkeeper.v1.DeleteItemsResponse.results:type_name -> gophkeeper.v1.ItemResult
+	8,   // 26: gophkeeper.v1.ApplyChangeSetRequest.upserts:type_name -> gophkeeper.v1.UpsertItem
+	27,  // 27: gophkeeper.v1.ApplyChangeSetRequest.deletes:type_name -> gophkeeper.v1.ItemRef
+	28,  // 28: gophkeeper.v1.ApplyChangeSetResponse.upsert_results:type_name -> gophkeeper.v1.ItemResult
+	28,  // 29: gophkeeper.v1.ApplyChangeSetResponse.delete_results:type_name -> gophkeeper.v1.ItemResult
+	13,  // 30: gophkeeper.v1.ApplyChangeSetResponse.warnings:type_name -> gophkeeper.v1.Warning
+	104, // 31: gophkeeper.v1.GetStatsResponse.last_updated:type_name -> google.protobuf.Timestamp
+	36,  // 32: gophkeeper.v1.VerifyVaultResponse.leaves:type_name -> gophkeeper.v1.VaultLeaf
+	104, // 33: gophkeeper.v1.Webhook.created_at:type_name -> google.protobuf.Timestamp
+	40,  // 34: gophkeeper.v1.CreateWebhookResponse.webhook:type_name -> gophkeeper.v1.Webhook
+	40,  // 35: gophkeeper.v1.ListWebhooksResponse.webhooks:type_name -> gophkeeper.v1.Webhook
+	104, // 36: gophkeeper.v1.Device.created_at:type_name -> google.protobuf.Timestamp
+	47,  // 37: gophkeeper.v1.RegisterDeviceResponse.device:type_name -> gophkeeper.v1.Device
+	47,  // 38: gophkeeper.v1.ListDevicesResponse.devices:type_name -> gophkeeper.v1.Device
+	104, // 39: gophkeeper.v1.LoginAttempt.at:type_name -> google.protobuf.Timestamp
+	55,  // 40: gophkeeper.v1.GetLoginHistoryResponse.attempts:type_name -> gophkeeper.v1.LoginAttempt
+	104, // 41: gophkeeper.v1.Session.created_at:type_name -> google.protobuf.Timestamp
+	104, // 42: gophkeeper.v1.Session.last_used_at:type_name -> google.protobuf.Timestamp
+	104, // 43: gophkeeper.v1.Session.expires_at:type_name -> google.protobuf.Timestamp
+	59,  // 44: gophkeeper.v1.ListSessionsResponse.sessions:type_name -> gophkeeper.v1.Session
+	104, // 45: gophkeeper.v1.ItemAccess.first_at:type_name -> google.protobuf.Timestamp
+	104, // 46: gophkeeper.v1.ItemAccess.last_at:type_name -> google.protobuf.Timestamp
+	64,  // 47: gophkeeper.v1.GetItemAccessLogResponse.accesses:type_name -> gophkeeper.v1.ItemAccess
+	104, // 48: gophkeeper.v1.FreezeVaultResponse.frozen_at:type_name -> google.protobuf.Timestamp
+	104, // 49: gophkeeper.v1.RollbackUserRequest.to_time:type_name -> google.protobuf.Timestamp
+	80,  // 50: gophkeeper.v1.CheckUserConsistencyResponse.violations:type_name -> gophkeeper.v1.ConsistencyViolation
+	104, // 51: gophkeeper.v1.UsageSample.day:type_name -> google.protobuf.Timestamp
+	104, // 52: gophkeeper.v1.UserUsage.last_write:type_name -> google.protobuf.Timestamp
+	104, // 53: gophkeeper.v1.UserUsage.last_login:type_name -> google.protobuf.Timestamp
+	87,  // 54: gophkeeper.v1.UserUsage.history:type_name -> gophkeeper.v1.UsageSample
+	104, // 55: gophkeeper.v1.GetUsageReportResponse.as_of:type_name -> google.protobuf.Timestamp
+	88,  // 56: gophkeeper.v1.GetUsageReportResponse.users:type_name -> gophkeeper.v1.UserUsage
+	104, // 57: gophkeeper.v1.LoginLock.blocked_until:type_name -> google.protobuf.Timestamp
+	90,  // 58: gophkeeper.v1.ListLoginLocksResponse.locks:type_name -> gophkeeper.v1.LoginLock
+	104, // 59: gophkeeper.v1.IPRule.created_at:type_name -> google.protobuf.Timestamp
+	95,  // 60: gophkeeper.v1.ListUserIPRulesResponse.rules:type_name -> gophkeeper.v1.IPRule
+	95,  // 61: gophkeeper.v1.AddUserIPRuleResponse.rule:type_name -> gophkeeper.v1.IPRule
+	2,   // 62: gophkeeper.v1.GophKeeper.Register:input_type -> gophkeeper.v1.RegisterRequest
+	4,   // 63: gophkeeper.v1.GophKeeper.Login:input_type -> gophkeeper.v1.LoginRequest
+	11,  // 64: gophkeeper.v1.GophKeeper.UpsertItems:input_type -> gophkeeper.v1.UpsertItemsRequest
+	14,  // 65: gophkeeper.v1.GophKeeper.GetChanges:input_type -> gophkeeper.v1.GetChangesRequest
+	16,  // 66: gophkeeper.v1.GophKeeper.GetChangesLongPoll:input_type -> gophkeeper.v1.GetChangesLongPollRequest
+	18,  // 67: gophkeeper.v1.GophKeeper.GetItem:input_type -> gophkeeper.v1.GetItemRequest
+	18,  // 68: gophkeeper.v1.GophKeeper.GetItemStream:input_type -> gophkeeper.v1.GetItemRequest
+	23,  // 69: gophkeeper.v1.GophKeeper.ExportItems:input_type -> gophkeeper.v1.ExportItemsRequest
+	21,  // 70: gophkeeper.v1.GophKeeper.BeginSync:input_type -> gophkeeper.v1.BeginSyncRequest
+	25,  // 71: gophkeeper.v1.GophKeeper.DeleteItem:input_type -> gophkeeper.v1.DeleteItemRequest
+	29,  // 72: gophkeeper.v1.GophKeeper.DeleteItems:input_type -> gophkeeper.v1.DeleteItemsRequest
+	31,  // 73: gophkeeper.v1.GophKeeper.ApplyChangeSet:input_type -> gophkeeper.v1.ApplyChangeSetRequest
+	38,  // 74: gophkeeper.v1.GophKeeper.SetWrappedDEK:input_type -> gophkeeper.v1.SetWrappedDEKRequest
+	33,  // 75: gophkeeper.v1.GophKeeper.GetStats:input_type -> gophkeeper.v1.GetStatsRequest
+	35,  // 76: gophkeeper.v1.GophKeeper.VerifyVault:input_type -> gophkeeper.v1.VerifyVaultRequest
+	41,  // 77: gophkeeper.v1.GophKeeper.CreateWebhook:input_type -> gophkeeper.v1.CreateWebhookRequest
+	43,  // 78: gophkeeper.v1.GophKeeper.ListWebhooks:input_type -> gophkeeper.v1.ListWebhooksRequest
+	45,  // 79: gophkeeper.v1.GophKeeper.DeleteWebhook:input_type -> gophkeeper.v1.DeleteWebhookRequest
+	48,  // 80: gophkeeper.v1.GophKeeper.RegisterDevice:input_type -> gophkeeper.v1.RegisterDeviceRequest
+	50,  // 81: gophkeeper.v1.GophKeeper.ListDevices:input_type -> gophkeeper.v1.ListDevicesRequest
+	52,  // 82: gophkeeper.v1.GophKeeper.UnregisterDevice:input_type -> gophkeeper.v1.UnregisterDeviceRequest
+	54,  // 83: gophkeeper.v1.GophKeeper.GetLoginHistory:input_type -> gophkeeper.v1.GetLoginHistoryRequest
+	57,  // 84: gophkeeper.v1.GophKeeper.Refresh:input_type -> gophkeeper.v1.RefreshRequest
+	60,  // 85: gophkeeper.v1.GophKeeper.ListSessions:input_type -> gophkeeper.v1.ListSessionsRequest
+	62,  // 86: gophkeeper.v1.GophKeeper.RevokeSession:input_type -> gophkeeper.v1.RevokeSessionRequest
+	65,  // 87: gophkeeper.v1.GophKeeper.GetItemAccessLog:input_type -> gophkeeper.v1.GetItemAccessLogRequest
+	67,  // 88: gophkeeper.v1.GophKeeper.FreezeVault:input_type -> gophkeeper.v1.FreezeVaultRequest
+	69,  // 89: gophkeeper.v1.GophKeeper.UnfreezeVault:input_type -> gophkeeper.v1.UnfreezeVaultRequest
+	71,  // 90: gophkeeper.v1.GophKeeper.SetDuressPassword:input_type -> gophkeeper.v1.SetDuressPasswordRequest
+	73,  // 91: gophkeeper.v1.GophKeeper.RemoveDuressPassword:input_type -> gophkeeper.v1.RemoveDuressPasswordRequest
+	102, // 92: gophkeeper.v1.GophKeeper.GetServerInfo:input_type -> gophkeeper.v1.GetServerInfoRequest
+	75,  // 93: gophkeeper.v1.AdminService.SetDiagnostics:input_type -> gophkeeper.v1.SetDiagnosticsRequest
+	77,  // 94: gophkeeper.v1.AdminService.RollbackUser:input_type -> gophkeeper.v1.RollbackUserRequest
+	79,  // 95: gophkeeper.v1.AdminService.CheckUserConsistency:input_type -> gophkeeper.v1.CheckUserConsistencyRequest
+	82,  // 96: gophkeeper.v1.AdminService.SuspendUser:input_type -> gophkeeper.v1.SuspendUserRequest
+	84,  // 97: gophkeeper.v1.AdminService.ReactivateUser:input_type -> gophkeeper.v1.ReactivateUserRequest
+	86,  // 98: gophkeeper.v1.AdminService.GetUsageReport:input_type -> gophkeeper.v1.GetUsageReportRequest
+	91,  // 99: gophkeeper.v1.AdminService.ListLoginLocks:input_type -> gophkeeper.v1.ListLoginLocksRequest
+	93,  // 100: gophkeeper.v1.AdminService.ClearLoginLocks:input_type -> gophkeeper.v1.ClearLoginLocksRequest
+	96,  // 101: gophkeeper.v1.AdminService.ListUserIPRules:input_type -> gophkeeper.v1.ListUserIPRulesRequest
+	98,  // 102: gophkeeper.v1.AdminService.AddUserIPRule:input_type -> gophkeeper.v1.AddUserIPRuleRequest
+	100, // 103: gophkeeper.v1.AdminService.RemoveUserIPRule:input_type -> gophkeeper.v1.RemoveUserIPRuleRequest
+	3,   // 104: gophkeeper.v1.GophKeeper.Register:output_type -> gophkeeper.v1.RegisterResponse
+	5,   // 105: gophkeeper.v1.GophKeeper.Login:output_type -> gophkeeper.v1.LoginResponse
+	12,  // 106: gophkeeper.v1.GophKeeper.UpsertItems:output_type -> gophkeeper.v1.UpsertItemsResponse
+	15,  // 107: gophkeeper.v1.GophKeeper.GetChanges:output_type -> gophkeeper.v1.GetChangesResponse
+	17,  // 108: gophkeeper.v1.GophKeeper.GetChangesLongPoll:output_type -> gophkeeper.v1.GetChangesLongPollResponse
+	19,  // 109: gophkeeper.v1.GophKeeper.GetItem:output_type -> gophkeeper.v1.GetItemResponse
+	20,  // 110: gophkeeper.v1.GophKeeper.GetItemStream:output_type -> gophkeeper.v1.GetItemChunk
+	24,  // 111: gophkeeper.v1.GophKeeper.ExportItems:output_type -> gophkeeper.v1.ExportItemsResponse
+	22,  // 112: gophkeeper.v1.GophKeeper.BeginSync:output_type -> gophkeeper.v1.BeginSyncResponse
+	26,  // 113: gophkeeper.v1.GophKeeper.DeleteItem:output_type -> gophkeeper.v1.DeleteItemResponse
+	30,  // 114: gophkeeper.v1.GophKeeper.DeleteItems:output_type -> gophkeeper.v1.DeleteItemsResponse
+	32,  // 115: gophkeeper.v1.GophKeeper.ApplyChangeSet:output_type -> gophkeeper.v1.ApplyChangeSetResponse
+	39,  // 116: gophkeeper.v1.GophKeeper.SetWrappedDEK:output_type -> gophkeeper.v1.SetWrappedDEKResponse
+	34,  // 117: gophkeeper.v1.GophKeeper.GetStats:output_type -> gophkeeper.v1.GetStatsResponse
+	37,  // 118: gophkeeper.v1.GophKeeper.VerifyVault:output_type -> gophkeeper.v1.VerifyVaultResponse
+	42,  // 119: gophkeeper.v1.GophKeeper.CreateWebhook:output_type -> gophkeeper.v1.CreateWebhookResponse
+	44,  // 120: gophkeeper.v1.GophKeeper.ListWebhooks:output_type -> gophkeeper.v1.ListWebhooksResponse
+	46,  // 121: gophkeeper.v1.GophKeeper.DeleteWebhook:output_type -> gophkeeper.v1.DeleteWebhookResponse
+	49,  // 122: gophkeeper.v1.GophKeeper.RegisterDevice:output_type -> gophkeeper.v1.RegisterDeviceResponse
+	51,  // 123: gophkeeper.v1.GophKeeper.ListDevices:output_type -> gophkeeper.v1.ListDevicesResponse
+	53,  // 124: gophkeeper.v1.GophKeeper.UnregisterDevice:output_type -> gophkeeper.v1.UnregisterDeviceResponse
+	56,  // 125: gophkeeper.v1.GophKeeper.GetLoginHistory:output_type -> gophkeeper.v1.GetLoginHistoryResponse
+	58,  // 126: gophkeeper.v1.GophKeeper.Refresh:output_type -> gophkeeper.v1.RefreshResponse
+	61,  // 127: gophkeeper.v1.GophKeeper.ListSessions:output_type -> gophkeeper.v1.ListSessionsResponse
+	63,  // 128: gophkeeper.v1.GophKeeper.RevokeSession:output_type -> gophkeeper.v1.RevokeSessionResponse
+	66,  // 129: gophkeeper.v1.GophKeeper.GetItemAccessLog:output_type -> gophkeeper.v1.GetItemAccessLogResponse
+	68,  // 130: gophkeeper.v1.GophKeeper.FreezeVault:output_type -> gophkeeper.v1.FreezeVaultResponse
+	70,  // 131: gophkeeper.v1.GophKeeper.UnfreezeVault:output_type -> gophkeeper.v1.UnfreezeVaultResponse
+	72,  // 132: gophkeeper.v1.GophKeeper.SetDuressPassword:output_type -> gophkeeper.v1.SetDuressPasswordResponse
+	74,  // 133: gophkeeper.v1.GophKeeper.RemoveDuressPassword:output_type -> gophkeeper.v1.RemoveDuressPasswordResponse
+	103, // 134: gophkeeper.v1.GophKeeper.GetServerInfo:output_type -> gophkeeper.v1.GetServerInfoResponse
+	76,  // 135: gophkeeper.v1.AdminService.SetDiagnostics:output_type -> gophkeeper.v1.SetDiagnosticsResponse
+	78,  // 136: gophkeeper.v1.AdminService.RollbackUser:output_type -> gophkeeper.v1.RollbackUserResponse
+	81,  // 137: gophkeeper.v1.AdminService.CheckUserConsistency:output_type -> gophkeeper.v1.CheckUserConsistencyResponse
+	83,  // 138: gophkeeper.v1.AdminService.SuspendUser:output_type -> gophkeeper.v1.SuspendUserResponse
+	85,  // 139: gophkeeper.v1.AdminService.ReactivateUser:output_type -> gophkeeper.v1.ReactivateUserResponse
+	89,  // 140: gophkeeper.v1.AdminService.GetUsageReport:output_type -> gophkeeper.v1.GetUsageReportResponse
+	92,  // 141: gophkeeper.v1.AdminService.ListLoginLocks:output_type -> gophkeeper.v1.ListLoginLocksResponse
+	94,  // 142: gophkeeper.v1.AdminService.ClearLoginLocks:output_type -> gophkeeper.v1.ClearLoginLocksResponse
+	97,  // 143: gophkeeper.v1.AdminService.ListUserIPRules:output_type -> gophkeeper.v1.ListUserIPRulesResponse
+	99,  // 144: gophkeeper.v1.AdminService.AddUserIPRule:output_type -> gophkeeper.v1.AddUserIPRuleResponse
+	101, // 145: gophkeeper.v1.AdminService.RemoveUserIPRule:output_type -> gophkeeper.v1.RemoveUserIPRuleResponse
+	104, // [104:146] is the sub-list for method output_type
+	62,  // [62:104] is the sub-list for method input_type
+	62,  // [62:62] is the sub-list for extension type_name
+	62,  // [62:62] is the sub-list for extension extendee
+	0,   // [0:62] is the sub-list for field type_name
 }
 
 func init() { file_gophkeeper_v1_gophkeeper_proto_init() }
@@ -2042,13 +12137,14 @@ func file_gophkeeper_v1_gophkeeper_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_gophkeeper_v1_gophkeeper_proto_rawDesc), len(file_gophkeeper_v1_gophkeeper_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   18,
+			NumEnums:      2,
+			NumMessages:   102,
 			NumExtensions: 0,
-			NumServices:   1,
+			NumServices:   2,
 		},
 		GoTypes:           file_gophkeeper_v1_gophkeeper_proto_goTypes,
 		DependencyIndexes: file_gophkeeper_v1_gophkeeper_proto_depIdxs,
+		EnumInfos:         file_gophkeeper_v1_gophkeeper_proto_enumTypes,
 		MessageInfos:      file_gophkeeper_v1_gophkeeper_proto_msgTypes,
 	}.Build()
 	File_gophkeeper_v1_gophkeeper_proto = out.File
@@ -19,13 +19,37 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	GophKeeper_Register_FullMethodName      = "/gophkeeper.v1.GophKeeper/Register"
-	GophKeeper_Login_FullMethodName         = "/gophkeeper.v1.GophKeeper/Login"
-	GophKeeper_UpsertItems_FullMethodName   = "/gophkeeper.v1.GophKeeper/UpsertItems"
-	GophKeeper_GetChanges_FullMethodName    = "/gophkeeper.v1.GophKeeper/GetChanges"
-	GophKeeper_GetItem_FullMethodName       = "/gophkeeper.v1.GophKeeper/GetItem"
-	GophKeeper_DeleteItem_FullMethodName    = "/gophkeeper.v1.GophKeeper/DeleteItem"
-	GophKeeper_SetWrappedDEK_FullMethodName = "/gophkeeper.v1.GophKeeper/SetWrappedDEK"
+	GophKeeper_Register_FullMethodName             = "/gophkeeper.v1.GophKeeper/Register"
+	GophKeeper_Login_FullMethodName                = "/gophkeeper.v1.GophKeeper/Login"
+	GophKeeper_UpsertItems_FullMethodName          = "/gophkeeper.v1.GophKeeper/UpsertItems"
+	GophKeeper_GetChanges_FullMethodName           = "/gophkeeper.v1.GophKeeper/GetChanges"
+	GophKeeper_GetChangesLongPoll_FullMethodName   = "/gophkeeper.v1.GophKeeper/GetChangesLongPoll"
+	GophKeeper_GetItem_FullMethodName              = "/gophkeeper.v1.GophKeeper/GetItem"
+	GophKeeper_GetItemStream_FullMethodName        = "/gophkeeper.v1.GophKeeper/GetItemStream"
+	GophKeeper_ExportItems_FullMethodName          = "/gophkeeper.v1.GophKeeper/ExportItems"
+	GophKeeper_BeginSync_FullMethodName            = "/gophkeeper.v1.GophKeeper/BeginSync"
+	GophKeeper_DeleteItem_FullMethodName           = "/gophkeeper.v1.GophKeeper/DeleteItem"
+	GophKeeper_DeleteItems_FullMethodName          = "/gophkeeper.v1.GophKeeper/DeleteItems"
+	GophKeeper_ApplyChangeSet_FullMethodName       = "/gophkeeper.v1.GophKeeper/ApplyChangeSet"
+	GophKeeper_SetWrappedDEK_FullMethodName        = "/gophkeeper.v1.GophKeeper/SetWrappedDEK"
+	GophKeeper_GetStats_FullMethodName             = "/gophkeeper.v1.GophKeeper/GetStats"
+	GophKeeper_VerifyVault_FullMethodName          = "/gophkeeper.v1.GophKeeper/VerifyVault"
+	GophKeeper_CreateWebhook_FullMethodName        = "/gophkeeper.v1.GophKeeper/CreateWebhook"
+	GophKeeper_ListWebhooks_FullMethodName         = "/gophkeeper.v1.GophKeeper/ListWebhooks"
+	GophKeeper_DeleteWebhook_FullMethodName        = "/gophkeeper.v1.GophKeeper/DeleteWebhook"
+	GophKeeper_RegisterDevice_FullMethodName       = "/gophkeeper.v1.GophKeeper/RegisterDevice"
+	GophKeeper_ListDevices_FullMethodName          = "/gophkeeper.v1.GophKeeper/ListDevices"
+	GophKeeper_UnregisterDevice_FullMethodName     = "/gophkeeper.v1.GophKeeper/UnregisterDevice"
+	GophKeeper_GetLoginHistory_FullMethodName      = "/gophkeeper.v1.GophKeeper/GetLoginHistory"
+	GophKeeper_Refresh_FullMethodName              = "/gophkeeper.v1.GophKeeper/Refresh"
+	GophKeeper_ListSessions_FullMethodName         = "/gophkeeper.v1.GophKeeper/ListSessions"
+	GophKeeper_RevokeSession_FullMethodName        = "/gophkeeper.v1.GophKeeper/RevokeSession"
+	GophKeeper_GetItemAccessLog_FullMethodName     = "/gophkeeper.v1.GophKeeper/GetItemAccessLog"
+	GophKeeper_FreezeVault_FullMethodName          = "/gophkeeper.v1.GophKeeper/FreezeVault"
+	GophKeeper_UnfreezeVault_FullMethodName        = "/gophkeeper.v1.GophKeeper/UnfreezeVault"
+	GophKeeper_SetDuressPassword_FullMethodName    = "/gophkeeper.v1.GophKeeper/SetDuressPassword"
+	GophKeeper_RemoveDuressPassword_FullMethodName = "/gophkeeper.v1.GophKeeper/RemoveDuressPassword"
+	GophKeeper_GetServerInfo_FullMethodName        = "/gophkeeper.v1.GophKeeper/GetServerInfo"
 )
 
 // GophKeeperClient is the client API for GophKeeper service.
@@ -35,28 +59,157 @@ type GophKeeperClient interface {
 	// Create user. Errors:
 	// - ALREADY_EXISTS: username taken
 	// - INVALID_ARGUMENT: bad input
+	// - UNAUTHENTICATED: the server wants a challenge answered first
+	//   (CHALLENGE_REQUIRED). The ErrorInfo metadata has "kind" (pow,
+	//   hcaptcha or turnstile) and, for pow, "token" and "difficulty": send
+	//   token + ":" + any suffix whose SHA-256 starts with difficulty zero
+	//   bits; for a CAPTCHA, "site_key" and send the provider's response
+	//   token. Retry with the answer in challenge_response; a wrong answer
+	//   gets a fresh challenge.
 	Register(ctx context.Context, in *RegisterRequest, opts ...grpc.CallOption) (*RegisterResponse, error)
 	// Authenticate user and bootstrap client-side crypto. Errors:
-	// - UNAUTHENTICATED: wrong credentials
+	// - INVALID_ARGUMENT: device_label too long
+	// - UNAUTHENTICATED: wrong credentials, or CHALLENGE_REQUIRED as for
+	//   Register once the account has had several wrong passwords since its
+	//   last login
 	// - RESOURCE_EXHAUSTED: rate limit / lockout
+	// - PERMISSION_DENIED: the client's address is outside the server's or
+	//   the user's address rules (ADDRESS_DENIED); any call may fail this way
 	Login(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*LoginResponse, error)
 	// Upsert items with optimistic concurrency (base_ver must match).
 	// Errors:
-	// - FAILED_PRECONDITION: version conflict
+	// - FAILED_PRECONDITION: version conflict, unless best_effort is set. The
+	//   ErrorInfo metadata names the first stale item: "item" (its index),
+	//   "id", "current_ver" (0 if there is no such item) and "updated_at"
+	//   (RFC 3339, existing items only).
 	// - INVALID_ARGUMENT: malformed payload
 	UpsertItems(ctx context.Context, in *UpsertItemsRequest, opts ...grpc.CallOption) (*UpsertItemsResponse, error)
 	// Incremental sync by version cursor.
 	GetChanges(ctx context.Context, in *GetChangesRequest, opts ...grpc.CallOption) (*GetChangesResponse, error)
-	// Fetch a single item by id.
+	// GetChanges for clients that cannot keep a stream open: the reply comes
+	// as soon as the vault changes, or unchanged once the wait is over. Call
+	// it again with the returned watermark.
+	GetChangesLongPoll(ctx context.Context, in *GetChangesLongPollRequest, opts ...grpc.CallOption) (*GetChangesLongPollResponse, error)
+	// Fetch a single item by id, optionally at a past version.
 	// Errors:
-	// - NOT_FOUND
+	// - NOT_FOUND: unknown item or version
 	GetItem(ctx context.Context, in *GetItemRequest, opts ...grpc.CallOption) (*GetItemResponse, error)
+	// GetItem for blobs too large for one message, sent in chunks of at most
+	// 256 KiB. Same errors as GetItem.
+	GetItemStream(ctx context.Context, in *GetItemRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[GetItemChunk], error)
+	// Every item of the caller as of one moment, in id order: writes that
+	// commit while the stream runs are not in it. The server reads ahead only
+	// as fast as the client takes messages in.
+	ExportItems(ctx context.Context, in *ExportItemsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExportItemsResponse], error)
+	// Pin the vault as it is now for a full download: GetChanges with the
+	// returned sync_session pages through that state however long it takes,
+	// while other devices keep writing.
+	BeginSync(ctx context.Context, in *BeginSyncRequest, opts ...grpc.CallOption) (*BeginSyncResponse, error)
 	// Logical delete (tombstone), ver++.
 	// Errors:
-	// - FAILED_PRECONDITION: version conflict
+	// - FAILED_PRECONDITION: version conflict, with ErrorInfo metadata as for
+	//   UpsertItems
 	// - NOT_FOUND
 	DeleteItem(ctx context.Context, in *DeleteItemRequest, opts ...grpc.CallOption) (*DeleteItemResponse, error)
+	// DeleteItem for many items in one transaction: either all of them are
+	// deleted or none is, and results say which items stood in the way.
+	// Conflicts and missing items are reported in results, not as errors.
+	// Errors:
+	// - INVALID_ARGUMENT: bad id, or more items than the server's batch limit
+	DeleteItems(ctx context.Context, in *DeleteItemsRequest, opts ...grpc.CallOption) (*DeleteItemsResponse, error)
+	// UpsertItems and DeleteItems in one transaction: every upsert and delete
+	// is applied or none is, so a client can merge duplicates into one item
+	// and delete the rest without leaving half of the merge behind.
+	// Conflicts and missing items are reported in the results, not as errors.
+	// Errors:
+	// - INVALID_ARGUMENT: malformed payload, an id both upserted and deleted,
+	//   or more entries in all than the server's batch limit
+	// - RESOURCE_EXHAUSTED: the item cap or the storage quota
+	ApplyChangeSet(ctx context.Context, in *ApplyChangeSetRequest, opts ...grpc.CallOption) (*ApplyChangeSetResponse, error)
+	// Errors:
+	// - FAILED_PRECONDITION: already initialized, or previous_wrapped_dek is stale
 	SetWrappedDEK(ctx context.Context, in *SetWrappedDEKRequest, opts ...grpc.CallOption) (*SetWrappedDEKResponse, error)
+	// Vault counters for the authenticated user.
+	GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*GetStatsResponse, error)
+	// Merkle root over (id, ver, blob hash) of every item, for comparing the
+	// server's copy of the vault with a client's.
+	VerifyVault(ctx context.Context, in *VerifyVaultRequest, opts ...grpc.CallOption) (*VerifyVaultResponse, error)
+	// Register a URL to be notified of item changes (id, version, deleted
+	// flag; never item data). Errors:
+	// - INVALID_ARGUMENT: not an absolute https URL
+	// - RESOURCE_EXHAUSTED: too many webhooks
+	// - FAILED_PRECONDITION: webhooks disabled on this server
+	CreateWebhook(ctx context.Context, in *CreateWebhookRequest, opts ...grpc.CallOption) (*CreateWebhookResponse, error)
+	// The caller's webhooks, oldest first.
+	ListWebhooks(ctx context.Context, in *ListWebhooksRequest, opts ...grpc.CallOption) (*ListWebhooksResponse, error)
+	// Errors:
+	// - NOT_FOUND: no such webhook of the caller
+	DeleteWebhook(ctx context.Context, in *DeleteWebhookRequest, opts ...grpc.CallOption) (*DeleteWebhookResponse, error)
+	// Register a device for "vault changed" pushes, so it can sync at once
+	// instead of polling. Pushes carry no item data. Errors:
+	// - INVALID_ARGUMENT: unknown or unconfigured platform, bad token
+	// - RESOURCE_EXHAUSTED: too many devices
+	// - FAILED_PRECONDITION: push disabled on this server
+	RegisterDevice(ctx context.Context, in *RegisterDeviceRequest, opts ...grpc.CallOption) (*RegisterDeviceResponse, error)
+	// The caller's devices, oldest first.
+	ListDevices(ctx context.Context, in *ListDevicesRequest, opts ...grpc.CallOption) (*ListDevicesResponse, error)
+	// Errors:
+	// - NOT_FOUND: no such device of the caller
+	UnregisterDevice(ctx context.Context, in *UnregisterDeviceRequest, opts ...grpc.CallOption) (*UnregisterDeviceResponse, error)
+	// Recent logins and wrong passwords on the caller's account, so the user
+	// can spot attempts that were not theirs. Attempts refused by the login
+	// rate limiter are not recorded. Errors:
+	// - INVALID_ARGUMENT: negative limit
+	GetLoginHistory(ctx context.Context, in *GetLoginHistoryRequest, opts ...grpc.CallOption) (*GetLoginHistoryResponse, error)
+	// Trade a refresh token for a new access token and a new refresh token,
+	// without the password. Each refresh token works once. Errors:
+	// - UNAUTHENTICATED: unknown, already used, expired or revoked token
+	// - FAILED_PRECONDITION: sessions are off on this server (NOT_CONFIGURED)
+	Refresh(ctx context.Context, in *RefreshRequest, opts ...grpc.CallOption) (*RefreshResponse, error)
+	// The caller's signed-in devices. Errors:
+	// - FAILED_PRECONDITION: sessions are off on this server (NOT_CONFIGURED)
+	ListSessions(ctx context.Context, in *ListSessionsRequest, opts ...grpc.CallOption) (*ListSessionsResponse, error)
+	// End one of the caller's sessions: its refresh token stops working.
+	// Access tokens already issued from it last until they expire. Errors:
+	// - NOT_FOUND: no such session of the caller
+	// - FAILED_PRECONDITION: sessions are off on this server (NOT_CONFIGURED)
+	RevokeSession(ctx context.Context, in *RevokeSessionRequest, opts ...grpc.CallOption) (*RevokeSessionResponse, error)
+	// Which sessions read an item with GetItem or GetItemStream, and when.
+	// Reads through GetChanges are not counted: every sync would be one.
+	// Errors:
+	// - INVALID_ARGUMENT: bad id
+	// - FAILED_PRECONDITION: the server keeps no access log (NOT_CONFIGURED)
+	GetItemAccessLog(ctx context.Context, in *GetItemAccessLogRequest, opts ...grpc.CallOption) (*GetItemAccessLogResponse, error)
+	// Make the caller's vault read-only, e.g. for a trip or while a device
+	// may be compromised. Until UnfreezeVault, calls that change items, the
+	// DEK, webhooks, push devices or the duress password fail with
+	// FAILED_PRECONDITION (VAULT_FROZEN); reads, logins and RevokeSession
+	// still work. Freezing a frozen vault changes nothing. Errors:
+	// - UNAUTHENTICATED: wrong password (BAD_CREDENTIALS)
+	// - RESOURCE_EXHAUSTED: too many wrong passwords; shares the login lockout
+	FreezeVault(ctx context.Context, in *FreezeVaultRequest, opts ...grpc.CallOption) (*FreezeVaultResponse, error)
+	// Make the caller's vault writable again. Errors as FreezeVault.
+	UnfreezeVault(ctx context.Context, in *UnfreezeVaultRequest, opts ...grpc.CallOption) (*UnfreezeVaultResponse, error)
+	// Give the caller's account a duress password, for a user made to unlock
+	// their vault. Logging in with it succeeds like a normal login but opens
+	// a second, initially empty vault: its own user id, kek_salt, wrapped
+	// DEK and items. Nothing in its responses tells it apart from the real
+	// one. The client sets it up like a new account's. Errors:
+	// - INVALID_ARGUMENT: the password is the account password
+	// - ALREADY_EXISTS: the account has a duress password
+	SetDuressPassword(ctx context.Context, in *SetDuressPasswordRequest, opts ...grpc.CallOption) (*SetDuressPasswordResponse, error)
+	// Delete the duress vault with everything in it. Errors:
+	// - NOT_FOUND: the account has no duress password
+	RemoveDuressPassword(ctx context.Context, in *RemoveDuressPasswordRequest, opts ...grpc.CallOption) (*RemoveDuressPasswordResponse, error)
+	// What this server offers and which clients it accepts. Needs no token
+	// and is never refused for the client's version or features, so a
+	// client can always learn why other calls are. Every other call may
+	// fail with:
+	// - FAILED_PRECONDITION: the client is older than min_client_version
+	//   (CLIENT_TOO_OLD; ErrorInfo metadata "min_client_version"), or relies
+	//   on features the server does not offer (UNSUPPORTED_FEATURE;
+	//   metadata "missing", comma-separated)
+	GetServerInfo(ctx context.Context, in *GetServerInfoRequest, opts ...grpc.CallOption) (*GetServerInfoResponse, error)
 }
 
 type gophKeeperClient struct {
@@ -107,6 +260,16 @@ func (c *gophKeeperClient) GetChanges(ctx context.Context, in *GetChangesRequest
 	return out, nil
 }
 
+func (c *gophKeeperClient) GetChangesLongPoll(ctx context.Context, in *GetChangesLongPollRequest, opts ...grpc.CallOption) (*GetChangesLongPollResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetChangesLongPollResponse)
+	err := c.cc.Invoke(ctx, GophKeeper_GetChangesLongPoll_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *gophKeeperClient) GetItem(ctx context.Context, in *GetItemRequest, opts ...grpc.CallOption) (*GetItemResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(GetItemResponse)
@@ -117,6 +280,54 @@ func (c *gophKeeperClient) GetItem(ctx context.Context, in *GetItemRequest, opts
 	return out, nil
 }
 
+func (c *gophKeeperClient) GetItemStream(ctx context.Context, in *GetItemRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[GetItemChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &GophKeeper_ServiceDesc.Streams[0], GophKeeper_GetItemStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[GetItemRequest, GetItemChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GophKeeper_GetItemStreamClient = grpc.ServerStreamingClient[GetItemChunk]
+
+func (c *gophKeeperClient) ExportItems(ctx context.Context, in *ExportItemsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExportItemsResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &GophKeeper_ServiceDesc.Streams[1], GophKeeper_ExportItems_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ExportItemsRequest, ExportItemsResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GophKeeper_ExportItemsClient = grpc.ServerStreamingClient[ExportItemsResponse]
+
+func (c *gophKeeperClient) BeginSync(ctx context.Context, in *BeginSyncRequest, opts ...grpc.CallOption) (*BeginSyncResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BeginSyncResponse)
+	err := c.cc.Invoke(ctx, GophKeeper_BeginSync_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *gophKeeperClient) DeleteItem(ctx context.Context, in *DeleteItemRequest, opts ...grpc.CallOption) (*DeleteItemResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(DeleteItemResponse)
@@ -127,6 +338,26 @@ func (c *gophKeeperClient) DeleteItem(ctx context.Context, in *DeleteItemRequest
 	return out, nil
 }
 
+func (c *gophKeeperClient) DeleteItems(ctx context.Context, in *DeleteItemsRequest, opts ...grpc.CallOption) (*DeleteItemsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteItemsResponse)
+	err := c.cc.Invoke(ctx, GophKeeper_DeleteItems_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) ApplyChangeSet(ctx context.Context, in *ApplyChangeSetRequest, opts ...grpc.CallOption) (*ApplyChangeSetResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ApplyChangeSetResponse)
+	err := c.cc.Invoke(ctx, GophKeeper_ApplyChangeSet_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *gophKeeperClient) SetWrappedDEK(ctx context.Context, in *SetWrappedDEKRequest, opts ...grpc.CallOption) (*SetWrappedDEKResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(SetWrappedDEKResponse)
@@ -137,6 +368,186 @@ func (c *gophKeeperClient) SetWrappedDEK(ctx context.Context, in *SetWrappedDEKR
 	return out, nil
 }
 
+func (c *gophKeeperClient) GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*GetStatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetStatsResponse)
+	err := c.cc.Invoke(ctx, GophKeeper_GetStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) VerifyVault(ctx context.Context, in *VerifyVaultRequest, opts ...grpc.CallOption) (*VerifyVaultResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VerifyVaultResponse)
+	err := c.cc.Invoke(ctx, GophKeeper_VerifyVault_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) CreateWebhook(ctx context.Context, in *CreateWebhookRequest, opts ...grpc.CallOption) (*CreateWebhookResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateWebhookResponse)
+	err := c.cc.Invoke(ctx, GophKeeper_CreateWebhook_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) ListWebhooks(ctx context.Context, in *ListWebhooksRequest, opts ...grpc.CallOption) (*ListWebhooksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListWebhooksResponse)
+	err := c.cc.Invoke(ctx, GophKeeper_ListWebhooks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) DeleteWebhook(ctx context.Context, in *DeleteWebhookRequest, opts ...grpc.CallOption) (*DeleteWebhookResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteWebhookResponse)
+	err := c.cc.Invoke(ctx, GophKeeper_DeleteWebhook_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) RegisterDevice(ctx context.Context, in *RegisterDeviceRequest, opts ...grpc.CallOption) (*RegisterDeviceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RegisterDeviceResponse)
+	err := c.cc.Invoke(ctx, GophKeeper_RegisterDevice_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) ListDevices(ctx context.Context, in *ListDevicesRequest, opts ...grpc.CallOption) (*ListDevicesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListDevicesResponse)
+	err := c.cc.Invoke(ctx, GophKeeper_ListDevices_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) UnregisterDevice(ctx context.Context, in *UnregisterDeviceRequest, opts ...grpc.CallOption) (*UnregisterDeviceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UnregisterDeviceResponse)
+	err := c.cc.Invoke(ctx, GophKeeper_UnregisterDevice_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) GetLoginHistory(ctx context.Context, in *GetLoginHistoryRequest, opts ...grpc.CallOption) (*GetLoginHistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetLoginHistoryResponse)
+	err := c.cc.Invoke(ctx, GophKeeper_GetLoginHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) Refresh(ctx context.Context, in *RefreshRequest, opts ...grpc.CallOption) (*RefreshResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RefreshResponse)
+	err := c.cc.Invoke(ctx, GophKeeper_Refresh_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) ListSessions(ctx context.Context, in *ListSessionsRequest, opts ...grpc.CallOption) (*ListSessionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSessionsResponse)
+	err := c.cc.Invoke(ctx, GophKeeper_ListSessions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) RevokeSession(ctx context.Context, in *RevokeSessionRequest, opts ...grpc.CallOption) (*RevokeSessionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RevokeSessionResponse)
+	err := c.cc.Invoke(ctx, GophKeeper_RevokeSession_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) GetItemAccessLog(ctx context.Context, in *GetItemAccessLogRequest, opts ...grpc.CallOption) (*GetItemAccessLogResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetItemAccessLogResponse)
+	err := c.cc.Invoke(ctx, GophKeeper_GetItemAccessLog_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) FreezeVault(ctx context.Context, in *FreezeVaultRequest, opts ...grpc.CallOption) (*FreezeVaultResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FreezeVaultResponse)
+	err := c.cc.Invoke(ctx, GophKeeper_FreezeVault_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) UnfreezeVault(ctx context.Context, in *UnfreezeVaultRequest, opts ...grpc.CallOption) (*UnfreezeVaultResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UnfreezeVaultResponse)
+	err := c.cc.Invoke(ctx, GophKeeper_UnfreezeVault_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) SetDuressPassword(ctx context.Context, in *SetDuressPasswordRequest, opts ...grpc.CallOption) (*SetDuressPasswordResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetDuressPasswordResponse)
+	err := c.cc.Invoke(ctx, GophKeeper_SetDuressPassword_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) RemoveDuressPassword(ctx context.Context, in *RemoveDuressPasswordRequest, opts ...grpc.CallOption) (*RemoveDuressPasswordResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RemoveDuressPasswordResponse)
+	err := c.cc.Invoke(ctx, GophKeeper_RemoveDuressPassword_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gophKeeperClient) GetServerInfo(ctx context.Context, in *GetServerInfoRequest, opts ...grpc.CallOption) (*GetServerInfoResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetServerInfoResponse)
+	err := c.cc.Invoke(ctx, GophKeeper_GetServerInfo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // GophKeeperServer is the server API for GophKeeper service.
 // All implementations must embed UnimplementedGophKeeperServer
 // for forward compatibility.
@@ -144,28 +555,157 @@ type GophKeeperServer interface {
 	// Create user. Errors:
 	// - ALREADY_EXISTS: username taken
 	// - INVALID_ARGUMENT: bad input
+	// - UNAUTHENTICATED: the server wants a challenge answered first
+	//   (CHALLENGE_REQUIRED). The ErrorInfo metadata has "kind" (pow,
+	//   hcaptcha or turnstile) and, for pow, "token" and "difficulty": send
+	//   token + ":" + any suffix whose SHA-256 starts with difficulty zero
+	//   bits; for a CAPTCHA, "site_key" and send the provider's response
+	//   token. Retry with the answer in challenge_response; a wrong answer
+	//   gets a fresh challenge.
 	Register(context.Context, *RegisterRequest) (*RegisterResponse, error)
 	// Authenticate user and bootstrap client-side crypto. Errors:
-	// - UNAUTHENTICATED: wrong credentials
+	// - INVALID_ARGUMENT: device_label too long
+	// - UNAUTHENTICATED: wrong credentials, or CHALLENGE_REQUIRED as for
+	//   Register once the account has had several wrong passwords since its
+	//   last login
 	// - RESOURCE_EXHAUSTED: rate limit / lockout
+	// - PERMISSION_DENIED: the client's address is outside the server's or
+	//   the user's address rules (ADDRESS_DENIED); any call may fail this way
 	Login(context.Context, *LoginRequest) (*LoginResponse, error)
 	// Upsert items with optimistic concurrency (base_ver must match).
 	// Errors:
-	// - FAILED_PRECONDITION: version conflict
+	// - FAILED_PRECONDITION: version conflict, unless best_effort is set. The
+	//   ErrorInfo metadata names the first stale item: "item" (its index),
+	//   "id", "current_ver" (0 if there is no such item) and "updated_at"
+	//   (RFC 3339, existing items only).
 	// - INVALID_ARGUMENT: malformed payload
 	UpsertItems(context.Context, *UpsertItemsRequest) (*UpsertItemsResponse, error)
 	// Incremental sync by version cursor.
 	GetChanges(context.Context, *GetChangesRequest) (*GetChangesResponse, error)
-	// Fetch a single item by id.
+	// GetChanges for clients that cannot keep a stream open: the reply comes
+	// as soon as the vault changes, or unchanged once the wait is over. Call
+	// it again with the returned watermark.
+	GetChangesLongPoll(context.Context, *GetChangesLongPollRequest) (*GetChangesLongPollResponse, error)
+	// Fetch a single item by id, optionally at a past version.
 	// Errors:
-	// - NOT_FOUND
+	// - NOT_FOUND: unknown item or version
 	GetItem(context.Context, *GetItemRequest) (*GetItemResponse, error)
+	// GetItem for blobs too large for one message, sent in chunks of at most
+	// 256 KiB. Same errors as GetItem.
+	GetItemStream(*GetItemRequest, grpc.ServerStreamingServer[GetItemChunk]) error
+	// Every item of the caller as of one moment, in id order: writes that
+	// commit while the stream runs are not in it. The server reads ahead only
+	// as fast as the client takes messages in.
+	ExportItems(*ExportItemsRequest, grpc.ServerStreamingServer[ExportItemsResponse]) error
+	// Pin the vault as it is now for a full download: GetChanges with the
+	// returned sync_session pages through that state however long it takes,
+	// while other devices keep writing.
+	BeginSync(context.Context, *BeginSyncRequest) (*BeginSyncResponse, error)
 	// Logical delete (tombstone), ver++.
 	// Errors:
-	// - FAILED_PRECONDITION: version conflict
+	// - FAILED_PRECONDITION: version conflict, with ErrorInfo metadata as for
+	//   UpsertItems
 	// - NOT_FOUND
 	DeleteItem(context.Context, *DeleteItemRequest) (*DeleteItemResponse, error)
+	// DeleteItem for many items in one transaction: either all of them are
+	// deleted or none is, and results say which items stood in the way.
+	// Conflicts and missing items are reported in results, not as errors.
+	// Errors:
+	// - INVALID_ARGUMENT: bad id, or more items than the server's batch limit
+	DeleteItems(context.Context, *DeleteItemsRequest) (*DeleteItemsResponse, error)
+	// UpsertItems and DeleteItems in one transaction: every upsert and delete
+	// is applied or none is, so a client can merge duplicates into one item
+	// and delete the rest without leaving half of the merge behind.
+	// Conflicts and missing items are reported in the results, not as errors.
+	// Errors:
+	// - INVALID_ARGUMENT: malformed payload, an id both upserted and deleted,
+	//   or more entries in all than the server's batch limit
+	// - RESOURCE_EXHAUSTED: the item cap or the storage quota
+	ApplyChangeSet(context.Context, *ApplyChangeSetRequest) (*ApplyChangeSetResponse, error)
+	// Errors:
+	// - FAILED_PRECONDITION: already initialized, or previous_wrapped_dek is stale
 	SetWrappedDEK(context.Context, *SetWrappedDEKRequest) (*SetWrappedDEKResponse, error)
+	// Vault counters for the authenticated user.
+	GetStats(context.Context, *GetStatsRequest) (*GetStatsResponse, error)
+	// Merkle root over (id, ver, blob hash) of every item, for comparing the
+	// server's copy of the vault with a client's.
+	VerifyVault(context.Context, *VerifyVaultRequest) (*VerifyVaultResponse, error)
+	// Register a URL to be notified of item changes (id, version, deleted
+	// flag; never item data). Errors:
+	// - INVALID_ARGUMENT: not an absolute https URL
+	// - RESOURCE_EXHAUSTED: too many webhooks
+	// - FAILED_PRECONDITION: webhooks disabled on this server
+	CreateWebhook(context.Context, *CreateWebhookRequest) (*CreateWebhookResponse, error)
+	// The caller's webhooks, oldest first.
+	ListWebhooks(context.Context, *ListWebhooksRequest) (*ListWebhooksResponse, error)
+	// Errors:
+	// - NOT_FOUND: no such webhook of the caller
+	DeleteWebhook(context.Context, *DeleteWebhookRequest) (*DeleteWebhookResponse, error)
+	// Register a device for "vault changed" pushes, so it can sync at once
+	// instead of polling. Pushes carry no item data. Errors:
+	// - INVALID_ARGUMENT: unknown or unconfigured platform, bad token
+	// - RESOURCE_EXHAUSTED: too many devices
+	// - FAILED_PRECONDITION: push disabled on this server
+	RegisterDevice(context.Context, *RegisterDeviceRequest) (*RegisterDeviceResponse, error)
+	// The caller's devices, oldest first.
+	ListDevices(context.Context, *ListDevicesRequest) (*ListDevicesResponse, error)
+	// Errors:
+	// - NOT_FOUND: no such device of the caller
+	UnregisterDevice(context.Context, *UnregisterDeviceRequest) (*UnregisterDeviceResponse, error)
+	// Recent logins and wrong passwords on the caller's account, so the user
+	// can spot attempts that were not theirs. Attempts refused by the login
+	// rate limiter are not recorded. Errors:
+	// - INVALID_ARGUMENT: negative limit
+	GetLoginHistory(context.Context, *GetLoginHistoryRequest) (*GetLoginHistoryResponse, error)
+	// Trade a refresh token for a new access token and a new refresh token,
+	// without the password. Each refresh token works once. Errors:
+	// - UNAUTHENTICATED: unknown, already used, expired or revoked token
+	// - FAILED_PRECONDITION: sessions are off on this server (NOT_CONFIGURED)
+	Refresh(context.Context, *RefreshRequest) (*RefreshResponse, error)
+	// The caller's signed-in devices. Errors:
+	// - FAILED_PRECONDITION: sessions are off on this server (NOT_CONFIGURED)
+	ListSessions(context.Context, *ListSessionsRequest) (*ListSessionsResponse, error)
+	// End one of the caller's sessions: its refresh token stops working.
+	// Access tokens already issued from it last until they expire. Errors:
+	// - NOT_FOUND: no such session of the caller
+	// - FAILED_PRECONDITION: sessions are off on this server (NOT_CONFIGURED)
+	RevokeSession(context.Context, *RevokeSessionRequest) (*RevokeSessionResponse, error)
+	// Which sessions read an item with GetItem or GetItemStream, and when.
+	// Reads through GetChanges are not counted: every sync would be one.
+	// Errors:
+	// - INVALID_ARGUMENT: bad id
+	// - FAILED_PRECONDITION: the server keeps no access log (NOT_CONFIGURED)
+	GetItemAccessLog(context.Context, *GetItemAccessLogRequest) (*GetItemAccessLogResponse, error)
+	// Make the caller's vault read-only, e.g. for a trip or while a device
+	// may be compromised. Until UnfreezeVault, calls that change items, the
+	// DEK, webhooks, push devices or the duress password fail with
+	// FAILED_PRECONDITION (VAULT_FROZEN); reads, logins and RevokeSession
+	// still work. Freezing a frozen vault changes nothing. Errors:
+	// - UNAUTHENTICATED: wrong password (BAD_CREDENTIALS)
+	// - RESOURCE_EXHAUSTED: too many wrong passwords; shares the login lockout
+	FreezeVault(context.Context, *FreezeVaultRequest) (*FreezeVaultResponse, error)
+	// Make the caller's vault writable again. Errors as FreezeVault.
+	UnfreezeVault(context.Context, *UnfreezeVaultRequest) (*UnfreezeVaultResponse, error)
+	// Give the caller's account a duress password, for a user made to unlock
+	// their vault. Logging in with it succeeds like a normal login but opens
+	// a second, initially empty vault: its own user id, kek_salt, wrapped
+	// DEK and items. Nothing in its responses tells it apart from the real
+	// one. The client sets it up like a new account's. Errors:
+	// - INVALID_ARGUMENT: the password is the account password
+	// - ALREADY_EXISTS: the account has a duress password
+	SetDuressPassword(context.Context, *SetDuressPasswordRequest) (*SetDuressPasswordResponse, error)
+	// Delete the duress vault with everything in it. Errors:
+	// - NOT_FOUND: the account has no duress password
+	RemoveDuressPassword(context.Context, *RemoveDuressPasswordRequest) (*RemoveDuressPasswordResponse, error)
+	// What this server offers and which clients it accepts. Needs no token
+	// and is never refused for the client's version or features, so a
+	// client can always learn why other calls are. Every other call may
+	// fail with:
+	// - FAILED_PRECONDITION: the client is older than min_client_version
+	//   (CLIENT_TOO_OLD; ErrorInfo metadata "min_client_version"), or relies
+	//   on features the server does not offer (UNSUPPORTED_FEATURE;
+	//   metadata "missing", comma-separated)
+	GetServerInfo(context.Context, *GetServerInfoRequest) (*GetServerInfoResponse, error)
 	mustEmbedUnimplementedGophKeeperServer()
 }
 
@@ -188,15 +728,87 @@ func (UnimplementedGophKeeperServer) UpsertItems(context.Context, *UpsertItemsRe
 func (UnimplementedGophKeeperServer) GetChanges(context.Context, *GetChangesRequest) (*GetChangesResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetChanges not implemented")
 }
+func (UnimplementedGophKeeperServer) GetChangesLongPoll(context.Context, *GetChangesLongPollRequest) (*GetChangesLongPollResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetChangesLongPoll not implemented")
+}
 func (UnimplementedGophKeeperServer) GetItem(context.Context, *GetItemRequest) (*GetItemResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetItem not implemented")
 }
+func (UnimplementedGophKeeperServer) GetItemStream(*GetItemRequest, grpc.ServerStreamingServer[GetItemChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method GetItemStream not implemented")
+}
+func (UnimplementedGophKeeperServer) ExportItems(*ExportItemsRequest, grpc.ServerStreamingServer[ExportItemsResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method ExportItems not implemented")
+}
+func (UnimplementedGophKeeperServer) BeginSync(context.Context, *BeginSyncRequest) (*BeginSyncResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BeginSync not implemented")
+}
 func (UnimplementedGophKeeperServer) DeleteItem(context.Context, *DeleteItemRequest) (*DeleteItemResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteItem not implemented")
 }
+func (UnimplementedGophKeeperServer) DeleteItems(context.Context, *DeleteItemsRequest) (*DeleteItemsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteItems not implemented")
+}
+func (UnimplementedGophKeeperServer) ApplyChangeSet(context.Context, *ApplyChangeSetRequest) (*ApplyChangeSetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ApplyChangeSet not implemented")
+}
 func (UnimplementedGophKeeperServer) SetWrappedDEK(context.Context, *SetWrappedDEKRequest) (*SetWrappedDEKResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SetWrappedDEK not implemented")
 }
+func (UnimplementedGophKeeperServer) GetStats(context.Context, *GetStatsRequest) (*GetStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStats not implemented")
+}
+func (UnimplementedGophKeeperServer) VerifyVault(context.Context, *VerifyVaultRequest) (*VerifyVaultResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifyVault not implemented")
+}
+func (UnimplementedGophKeeperServer) CreateWebhook(context.Context, *CreateWebhookRequest) (*CreateWebhookResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateWebhook not implemented")
+}
+func (UnimplementedGophKeeperServer) ListWebhooks(context.Context, *ListWebhooksRequest) (*ListWebhooksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListWebhooks not implemented")
+}
+func (UnimplementedGophKeeperServer) DeleteWebhook(context.Context, *DeleteWebhookRequest) (*DeleteWebhookResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteWebhook not implemented")
+}
+func (UnimplementedGophKeeperServer) RegisterDevice(context.Context, *RegisterDeviceRequest) (*RegisterDeviceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RegisterDevice not implemented")
+}
+func (UnimplementedGophKeeperServer) ListDevices(context.Context, *ListDevicesRequest) (*ListDevicesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDevices not implemented")
+}
+func (UnimplementedGophKeeperServer) UnregisterDevice(context.Context, *UnregisterDeviceRequest) (*UnregisterDeviceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnregisterDevice not implemented")
+}
+func (UnimplementedGophKeeperServer) GetLoginHistory(context.Context, *GetLoginHistoryRequest) (*GetLoginHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLoginHistory not implemented")
+}
+func (UnimplementedGophKeeperServer) Refresh(context.Context, *RefreshRequest) (*RefreshResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Refresh not implemented")
+}
+func (UnimplementedGophKeeperServer) ListSessions(context.Context, *ListSessionsRequest) (*ListSessionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSessions not implemented")
+}
+func (UnimplementedGophKeeperServer) RevokeSession(context.Context, *RevokeSessionRequest) (*RevokeSessionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeSession not implemented")
+}
+func (UnimplementedGophKeeperServer) GetItemAccessLog(context.Context, *GetItemAccessLogRequest) (*GetItemAccessLogResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetItemAccessLog not implemented")
+}
+func (UnimplementedGophKeeperServer) FreezeVault(context.Context, *FreezeVaultRequest) (*FreezeVaultResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FreezeVault not implemented")
+}
+func (UnimplementedGophKeeperServer) UnfreezeVault(context.Context, *UnfreezeVaultRequest) (*UnfreezeVaultResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnfreezeVault not implemented")
+}
+func (UnimplementedGophKeeperServer) SetDuressPassword(context.Context, *SetDuressPasswordRequest) (*SetDuressPasswordResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetDuressPassword not implemented")
+}
+func (UnimplementedGophKeeperServer) RemoveDuressPassword(context.Context, *RemoveDuressPasswordRequest) (*RemoveDuressPasswordResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveDuressPassword not implemented")
+}
+func (UnimplementedGophKeeperServer) GetServerInfo(context.Context, *GetServerInfoRequest) (*GetServerInfoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetServerInfo not implemented")
+}
 func (UnimplementedGophKeeperServer) mustEmbedUnimplementedGophKeeperServer() {}
 func (UnimplementedGophKeeperServer) testEmbeddedByValue()                    {}
 
@@ -290,6 +902,24 @@ func _GophKeeper_GetChanges_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _GophKeeper_GetChangesLongPoll_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetChangesLongPollRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).GetChangesLongPoll(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeper_GetChangesLongPoll_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).GetChangesLongPoll(ctx, req.(*GetChangesLongPollRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _GophKeeper_GetItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetItemRequest)
 	if err := dec(in); err != nil {
@@ -308,6 +938,46 @@ func _GophKeeper_GetItem_Handler(srv interface{}, ctx context.Context, dec func(
 	return interceptor(ctx, in, info, handler)
 }
 
+func _GophKeeper_GetItemStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetItemRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GophKeeperServer).GetItemStream(m, &grpc.GenericServerStream[GetItemRequest, GetItemChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GophKeeper_GetItemStreamServer = grpc.ServerStreamingServer[GetItemChunk]
+
+func _GophKeeper_ExportItems_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportItemsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GophKeeperServer).ExportItems(m, &grpc.GenericServerStream[ExportItemsRequest, ExportItemsResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GophKeeper_ExportItemsServer = grpc.ServerStreamingServer[ExportItemsResponse]
+
+func _GophKeeper_BeginSync_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BeginSyncRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).BeginSync(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeper_BeginSync_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).BeginSync(ctx, req.(*BeginSyncRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _GophKeeper_DeleteItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(DeleteItemRequest)
 	if err := dec(in); err != nil {
@@ -326,58 +996,1125 @@ func _GophKeeper_DeleteItem_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
-func _GophKeeper_SetWrappedDEK_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(SetWrappedDEKRequest)
+func _GophKeeper_DeleteItems_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteItemsRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(GophKeeperServer).SetWrappedDEK(ctx, in)
+		return srv.(GophKeeperServer).DeleteItems(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: GophKeeper_SetWrappedDEK_FullMethodName,
+		FullMethod: GophKeeper_DeleteItems_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(GophKeeperServer).SetWrappedDEK(ctx, req.(*SetWrappedDEKRequest))
+		return srv.(GophKeeperServer).DeleteItems(ctx, req.(*DeleteItemsRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-// GophKeeper_ServiceDesc is the grpc.ServiceDesc for GophKeeper service.
-// It's only intended for direct use with grpc.RegisterService,
-// and not to be introspected or modified (even as a copy)
+func _GophKeeper_ApplyChangeSet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ApplyChangeSetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).ApplyChangeSet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeper_ApplyChangeSet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).ApplyChangeSet(ctx, req.(*ApplyChangeSetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_SetWrappedDEK_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetWrappedDEKRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).SetWrappedDEK(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeper_SetWrappedDEK_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).SetWrappedDEK(ctx, req.(*SetWrappedDEKRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_GetStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).GetStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeper_GetStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).GetStats(ctx, req.(*GetStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_VerifyVault_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyVaultRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).VerifyVault(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeper_VerifyVault_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).VerifyVault(ctx, req.(*VerifyVaultRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_CreateWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateWebhookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).CreateWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeper_CreateWebhook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).CreateWebhook(ctx, req.(*CreateWebhookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_ListWebhooks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListWebhooksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).ListWebhooks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeper_ListWebhooks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).ListWebhooks(ctx, req.(*ListWebhooksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_DeleteWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteWebhookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).DeleteWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeper_DeleteWebhook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).DeleteWebhook(ctx, req.(*DeleteWebhookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_RegisterDevice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterDeviceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).RegisterDevice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeper_RegisterDevice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).RegisterDevice(ctx, req.(*RegisterDeviceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_ListDevices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDevicesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).ListDevices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeper_ListDevices_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).ListDevices(ctx, req.(*ListDevicesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_UnregisterDevice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnregisterDeviceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).UnregisterDevice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeper_UnregisterDevice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).UnregisterDevice(ctx, req.(*UnregisterDeviceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_GetLoginHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLoginHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).GetLoginHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeper_GetLoginHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).GetLoginHistory(ctx, req.(*GetLoginHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_Refresh_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RefreshRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).Refresh(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeper_Refresh_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).Refresh(ctx, req.(*RefreshRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_ListSessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSessionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).ListSessions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeper_ListSessions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).ListSessions(ctx, req.(*ListSessionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_RevokeSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).RevokeSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeper_RevokeSession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).RevokeSession(ctx, req.(*RevokeSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_GetItemAccessLog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetItemAccessLogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).GetItemAccessLog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeper_GetItemAccessLog_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).GetItemAccessLog(ctx, req.(*GetItemAccessLogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_FreezeVault_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FreezeVaultRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).FreezeVault(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeper_FreezeVault_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).FreezeVault(ctx, req.(*FreezeVaultRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_UnfreezeVault_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnfreezeVaultRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).UnfreezeVault(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeper_UnfreezeVault_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).UnfreezeVault(ctx, req.(*UnfreezeVaultRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_SetDuressPassword_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetDuressPasswordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).SetDuressPassword(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeper_SetDuressPassword_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).SetDuressPassword(ctx, req.(*SetDuressPasswordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_RemoveDuressPassword_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveDuressPasswordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).RemoveDuressPassword(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeper_RemoveDuressPassword_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).RemoveDuressPassword(ctx, req.(*RemoveDuressPasswordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GophKeeper_GetServerInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetServerInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GophKeeperServer).GetServerInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GophKeeper_GetServerInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GophKeeperServer).GetServerInfo(ctx, req.(*GetServerInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// GophKeeper_ServiceDesc is the grpc.ServiceDesc for GophKeeper service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
 var GophKeeper_ServiceDesc = grpc.ServiceDesc{
 	ServiceName: "gophkeeper.v1.GophKeeper",
 	HandlerType: (*GophKeeperServer)(nil),
 	Methods: []grpc.MethodDesc{
 		{
-			MethodName: "Register",
-			Handler:    _GophKeeper_Register_Handler,
+			MethodName: "Register",
+			Handler:    _GophKeeper_Register_Handler,
+		},
+		{
+			MethodName: "Login",
+			Handler:    _GophKeeper_Login_Handler,
+		},
+		{
+			MethodName: "UpsertItems",
+			Handler:    _GophKeeper_UpsertItems_Handler,
+		},
+		{
+			MethodName: "GetChanges",
+			Handler:    _GophKeeper_GetChanges_Handler,
+		},
+		{
+			MethodName: "GetChangesLongPoll",
+			Handler:    _GophKeeper_GetChangesLongPoll_Handler,
+		},
+		{
+			MethodName: "GetItem",
+			Handler:    _GophKeeper_GetItem_Handler,
+		},
+		{
+			MethodName: "BeginSync",
+			Handler:    _GophKeeper_BeginSync_Handler,
+		},
+		{
+			MethodName: "DeleteItem",
+			Handler:    _GophKeeper_DeleteItem_Handler,
+		},
+		{
+			MethodName: "DeleteItems",
+			Handler:    _GophKeeper_DeleteItems_Handler,
+		},
+		{
+			MethodName: "ApplyChangeSet",
+			Handler:    _GophKeeper_ApplyChangeSet_Handler,
+		},
+		{
+			MethodName: "SetWrappedDEK",
+			Handler:    _GophKeeper_SetWrappedDEK_Handler,
+		},
+		{
+			MethodName: "GetStats",
+			Handler:    _GophKeeper_GetStats_Handler,
+		},
+		{
+			MethodName: "VerifyVault",
+			Handler:    _GophKeeper_VerifyVault_Handler,
+		},
+		{
+			MethodName: "CreateWebhook",
+			Handler:    _GophKeeper_CreateWebhook_Handler,
+		},
+		{
+			MethodName: "ListWebhooks",
+			Handler:    _GophKeeper_ListWebhooks_Handler,
+		},
+		{
+			MethodName: "DeleteWebhook",
+			Handler:    _GophKeeper_DeleteWebhook_Handler,
+		},
+		{
+			MethodName: "RegisterDevice",
+			Handler:    _GophKeeper_RegisterDevice_Handler,
+		},
+		{
+			MethodName: "ListDevices",
+			Handler:    _GophKeeper_ListDevices_Handler,
+		},
+		{
+			MethodName: "UnregisterDevice",
+			Handler:    _GophKeeper_UnregisterDevice_Handler,
+		},
+		{
+			MethodName: "GetLoginHistory",
+			Handler:    _GophKeeper_GetLoginHistory_Handler,
+		},
+		{
+			MethodName: "Refresh",
+			Handler:    _GophKeeper_Refresh_Handler,
+		},
+		{
+			MethodName: "ListSessions",
+			Handler:    _GophKeeper_ListSessions_Handler,
+		},
+		{
+			MethodName: "RevokeSession",
+			Handler:    _GophKeeper_RevokeSession_Handler,
+		},
+		{
+			MethodName: "GetItemAccessLog",
+			Handler:    _GophKeeper_GetItemAccessLog_Handler,
+		},
+		{
+			MethodName: "FreezeVault",
+			Handler:    _GophKeeper_FreezeVault_Handler,
+		},
+		{
+			MethodName: "UnfreezeVault",
+			Handler:    _GophKeeper_UnfreezeVault_Handler,
+		},
+		{
+			MethodName: "SetDuressPassword",
+			Handler:    _GophKeeper_SetDuressPassword_Handler,
+		},
+		{
+			MethodName: "RemoveDuressPassword",
+			Handler:    _GophKeeper_RemoveDuressPassword_Handler,
+		},
+		{
+			MethodName: "GetServerInfo",
+			Handler:    _GophKeeper_GetServerInfo_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "GetItemStream",
+			Handler:       _GophKeeper_GetItemStream_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ExportItems",
+			Handler:       _GophKeeper_ExportItems_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "gophkeeper/v1/gophkeeper.proto",
+}
+
+const (
+	AdminService_SetDiagnostics_FullMethodName       = "/gophkeeper.v1.AdminService/SetDiagnostics"
+	AdminService_RollbackUser_FullMethodName         = "/gophkeeper.v1.AdminService/RollbackUser"
+	AdminService_CheckUserConsistency_FullMethodName = "/gophkeeper.v1.AdminService/CheckUserConsistency"
+	AdminService_SuspendUser_FullMethodName          = "/gophkeeper.v1.AdminService/SuspendUser"
+	AdminService_ReactivateUser_FullMethodName       = "/gophkeeper.v1.AdminService/ReactivateUser"
+	AdminService_GetUsageReport_FullMethodName       = "/gophkeeper.v1.AdminService/GetUsageReport"
+	AdminService_ListLoginLocks_FullMethodName       = "/gophkeeper.v1.AdminService/ListLoginLocks"
+	AdminService_ClearLoginLocks_FullMethodName      = "/gophkeeper.v1.AdminService/ClearLoginLocks"
+	AdminService_ListUserIPRules_FullMethodName      = "/gophkeeper.v1.AdminService/ListUserIPRules"
+	AdminService_AddUserIPRule_FullMethodName        = "/gophkeeper.v1.AdminService/AddUserIPRule"
+	AdminService_RemoveUserIPRule_FullMethodName     = "/gophkeeper.v1.AdminService/RemoveUserIPRule"
+)
+
+// AdminServiceClient is the client API for AdminService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Operator-only endpoints. Callers must be listed in the server's admin set.
+type AdminServiceClient interface {
+	// Enable or disable the diagnostics HTTP endpoint at runtime. Errors:
+	// - UNAUTHENTICATED: missing/invalid token
+	// - PERMISSION_DENIED: caller is not an admin
+	// - FAILED_PRECONDITION: diagnostics listener not configured
+	SetDiagnostics(ctx context.Context, in *SetDiagnosticsRequest, opts ...grpc.CallOption) (*SetDiagnosticsResponse, error)
+	// Roll a single user's items back to a timestamp using item history, e.g.
+	// after a client bug corrupted them. Item blobs are bound to their version,
+	// so each item returns to the version it had then; later versions are kept
+	// in an audit table, not served. Other users are not touched. Errors:
+	// - UNAUTHENTICATED, PERMISSION_DENIED: as above
+	// - INVALID_ARGUMENT: bad user id, missing or future to_time
+	// - NOT_FOUND: unknown user
+	// - FAILED_PRECONDITION: rollback not available on this server
+	// - FAILED_PRECONDITION (LEGAL_HOLD): the rollback would discard history
+	//   inside the server's legal hold window
+	RollbackUser(ctx context.Context, in *RollbackUserRequest, opts ...grpc.CallOption) (*RollbackUserResponse, error)
+	// Check a user's items, their history and the change journal for
+	// divergence: duplicate or out-of-order journal versions, live items
+	// without a blob, history missing the current version. With repair, the
+	// missing history and journal records are written from the items table;
+	// other findings need an operator. Errors:
+	// - UNAUTHENTICATED, PERMISSION_DENIED: as above
+	// - INVALID_ARGUMENT: bad user id
+	// - FAILED_PRECONDITION: checks not available on this server
+	CheckUserConsistency(ctx context.Context, in *CheckUserConsistencyRequest, opts ...grpc.CallOption) (*CheckUserConsistencyResponse, error)
+	// Suspend an account: its logins and refreshes fail, its sessions end,
+	// and calls with tokens it already holds fail at once, all with
+	// PERMISSION_DENIED and reason ACCOUNT_DISABLED. A login learns this only
+	// with the right password. The user's data is kept. Errors:
+	// - UNAUTHENTICATED, PERMISSION_DENIED: as above
+	// - INVALID_ARGUMENT: bad user id
+	// - NOT_FOUND: unknown user
+	// - FAILED_PRECONDITION: suspension not available on this server
+	SuspendUser(ctx context.Context, in *SuspendUserRequest, opts ...grpc.CallOption) (*SuspendUserResponse, error)
+	// Lift a suspension; the user logs in again to get new sessions.
+	// Errors: as for SuspendUser
+	ReactivateUser(ctx context.Context, in *ReactivateUserRequest, opts ...grpc.CallOption) (*ReactivateUserResponse, error)
+	// Per-user item counts, stored bytes and last activity from the latest
+	// usage rollup, with their daily growth, for capacity planning. The
+	// figures are as old as that rollup. Errors:
+	// - UNAUTHENTICATED, PERMISSION_DENIED: as above
+	// - INVALID_ARGUMENT: limit or days out of range
+	// - FAILED_PRECONDITION: usage reports not available on this server
+	GetUsageReport(ctx context.Context, in *GetUsageReportRequest, opts ...grpc.CallOption) (*GetUsageReportResponse, error)
+	// Pairs currently locked out of Login. Errors:
+	// - UNAUTHENTICATED, PERMISSION_DENIED: as above
+	// - FAILED_PRECONDITION: lockouts not manageable on this server
+	ListLoginLocks(ctx context.Context, in *ListLoginLocksRequest, opts ...grpc.CallOption) (*ListLoginLocksResponse, error)
+	// Lift lockouts early, e.g. for a user who has found their password. The
+	// failed attempts are still reported at the user's next login. Errors:
+	// - UNAUTHENTICATED, PERMISSION_DENIED: as above
+	// - INVALID_ARGUMENT: no username
+	// - FAILED_PRECONDITION: as for ListLoginLocks
+	ClearLoginLocks(ctx context.Context, in *ClearLoginLocksRequest, opts ...grpc.CallOption) (*ClearLoginLocksResponse, error)
+	// A user's address rules. Errors:
+	// - UNAUTHENTICATED, PERMISSION_DENIED: as above
+	// - INVALID_ARGUMENT: no username
+	// - FAILED_PRECONDITION: per-user address rules are off on this server
+	ListUserIPRules(ctx context.Context, in *ListUserIPRulesRequest, opts ...grpc.CallOption) (*ListUserIPRulesResponse, error)
+	// Add or change one of a user's address rules. It applies from the user's
+	// next call, Login included. Errors:
+	// - UNAUTHENTICATED, PERMISSION_DENIED, FAILED_PRECONDITION: as above
+	// - INVALID_ARGUMENT: no username, bad cidr
+	// - NOT_FOUND: unknown user
+	// - RESOURCE_EXHAUSTED: the user has the most rules allowed
+	//   (IP_RULE_LIMIT_EXCEEDED)
+	AddUserIPRule(ctx context.Context, in *AddUserIPRuleRequest, opts ...grpc.CallOption) (*AddUserIPRuleResponse, error)
+	// Remove one of a user's address rules. Errors:
+	// - UNAUTHENTICATED, PERMISSION_DENIED, FAILED_PRECONDITION: as above
+	// - INVALID_ARGUMENT: no username, bad cidr
+	// - NOT_FOUND: no such rule
+	RemoveUserIPRule(ctx context.Context, in *RemoveUserIPRuleRequest, opts ...grpc.CallOption) (*RemoveUserIPRuleResponse, error)
+}
+
+type adminServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAdminServiceClient(cc grpc.ClientConnInterface) AdminServiceClient {
+	return &adminServiceClient{cc}
+}
+
+func (c *adminServiceClient) SetDiagnostics(ctx context.Context, in *SetDiagnosticsRequest, opts ...grpc.CallOption) (*SetDiagnosticsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetDiagnosticsResponse)
+	err := c.cc.Invoke(ctx, AdminService_SetDiagnostics_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) RollbackUser(ctx context.Context, in *RollbackUserRequest, opts ...grpc.CallOption) (*RollbackUserResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RollbackUserResponse)
+	err := c.cc.Invoke(ctx, AdminService_RollbackUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) CheckUserConsistency(ctx context.Context, in *CheckUserConsistencyRequest, opts ...grpc.CallOption) (*CheckUserConsistencyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CheckUserConsistencyResponse)
+	err := c.cc.Invoke(ctx, AdminService_CheckUserConsistency_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) SuspendUser(ctx context.Context, in *SuspendUserRequest, opts ...grpc.CallOption) (*SuspendUserResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SuspendUserResponse)
+	err := c.cc.Invoke(ctx, AdminService_SuspendUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) ReactivateUser(ctx context.Context, in *ReactivateUserRequest, opts ...grpc.CallOption) (*ReactivateUserResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReactivateUserResponse)
+	err := c.cc.Invoke(ctx, AdminService_ReactivateUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) GetUsageReport(ctx context.Context, in *GetUsageReportRequest, opts ...grpc.CallOption) (*GetUsageReportResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetUsageReportResponse)
+	err := c.cc.Invoke(ctx, AdminService_GetUsageReport_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) ListLoginLocks(ctx context.Context, in *ListLoginLocksRequest, opts ...grpc.CallOption) (*ListLoginLocksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListLoginLocksResponse)
+	err := c.cc.Invoke(ctx, AdminService_ListLoginLocks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) ClearLoginLocks(ctx context.Context, in *ClearLoginLocksRequest, opts ...grpc.CallOption) (*ClearLoginLocksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ClearLoginLocksResponse)
+	err := c.cc.Invoke(ctx, AdminService_ClearLoginLocks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) ListUserIPRules(ctx context.Context, in *ListUserIPRulesRequest, opts ...grpc.CallOption) (*ListUserIPRulesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListUserIPRulesResponse)
+	err := c.cc.Invoke(ctx, AdminService_ListUserIPRules_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) AddUserIPRule(ctx context.Context, in *AddUserIPRuleRequest, opts ...grpc.CallOption) (*AddUserIPRuleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AddUserIPRuleResponse)
+	err := c.cc.Invoke(ctx, AdminService_AddUserIPRule_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) RemoveUserIPRule(ctx context.Context, in *RemoveUserIPRuleRequest, opts ...grpc.CallOption) (*RemoveUserIPRuleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RemoveUserIPRuleResponse)
+	err := c.cc.Invoke(ctx, AdminService_RemoveUserIPRule_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AdminServiceServer is the server API for AdminService service.
+// All implementations must embed UnimplementedAdminServiceServer
+// for forward compatibility.
+//
+// Operator-only endpoints. Callers must be listed in the server's admin set.
+type AdminServiceServer interface {
+	// Enable or disable the diagnostics HTTP endpoint at runtime. Errors:
+	// - UNAUTHENTICATED: missing/invalid token
+	// - PERMISSION_DENIED: caller is not an admin
+	// - FAILED_PRECONDITION: diagnostics listener not configured
+	SetDiagnostics(context.Context, *SetDiagnosticsRequest) (*SetDiagnosticsResponse, error)
+	// Roll a single user's items back to a timestamp using item history, e.g.
+	// after a client bug corrupted them. Item blobs are bound to their version,
+	// so each item returns to the version it had then; later versions are kept
+	// in an audit table, not served. Other users are not touched. Errors:
+	// - UNAUTHENTICATED, PERMISSION_DENIED: as above
+	// - INVALID_ARGUMENT: bad user id, missing or future to_time
+	// - NOT_FOUND: unknown user
+	// - FAILED_PRECONDITION: rollback not available on this server
+	// - FAILED_PRECONDITION (LEGAL_HOLD): the rollback would discard history
+	//   inside the server's legal hold window
+	RollbackUser(context.Context, *RollbackUserRequest) (*RollbackUserResponse, error)
+	// Check a user's items, their history and the change journal for
+	// divergence: duplicate or out-of-order journal versions, live items
+	// without a blob, history missing the current version. With repair, the
+	// missing history and journal records are written from the items table;
+	// other findings need an operator. Errors:
+	// - UNAUTHENTICATED, PERMISSION_DENIED: as above
+	// - INVALID_ARGUMENT: bad user id
+	// - FAILED_PRECONDITION: checks not available on this server
+	CheckUserConsistency(context.Context, *CheckUserConsistencyRequest) (*CheckUserConsistencyResponse, error)
+	// Suspend an account: its logins and refreshes fail, its sessions end,
+	// and calls with tokens it already holds fail at once, all with
+	// PERMISSION_DENIED and reason ACCOUNT_DISABLED. A login learns this only
+	// with the right password. The user's data is kept. Errors:
+	// - UNAUTHENTICATED, PERMISSION_DENIED: as above
+	// - INVALID_ARGUMENT: bad user id
+	// - NOT_FOUND: unknown user
+	// - FAILED_PRECONDITION: suspension not available on this server
+	SuspendUser(context.Context, *SuspendUserRequest) (*SuspendUserResponse, error)
+	// Lift a suspension; the user logs in again to get new sessions.
+	// Errors: as for SuspendUser
+	ReactivateUser(context.Context, *ReactivateUserRequest) (*ReactivateUserResponse, error)
+	// Per-user item counts, stored bytes and last activity from the latest
+	// usage rollup, with their daily growth, for capacity planning. The
+	// figures are as old as that rollup. Errors:
+	// - UNAUTHENTICATED, PERMISSION_DENIED: as above
+	// - INVALID_ARGUMENT: limit or days out of range
+	// - FAILED_PRECONDITION: usage reports not available on this server
+	GetUsageReport(context.Context, *GetUsageReportRequest) (*GetUsageReportResponse, error)
+	// Pairs currently locked out of Login. Errors:
+	// - UNAUTHENTICATED, PERMISSION_DENIED: as above
+	// - FAILED_PRECONDITION: lockouts not manageable on this server
+	ListLoginLocks(context.Context, *ListLoginLocksRequest) (*ListLoginLocksResponse, error)
+	// Lift lockouts early, e.g. for a user who has found their password. The
+	// failed attempts are still reported at the user's next login. Errors:
+	// - UNAUTHENTICATED, PERMISSION_DENIED: as above
+	// - INVALID_ARGUMENT: no username
+	// - FAILED_PRECONDITION: as for ListLoginLocks
+	ClearLoginLocks(context.Context, *ClearLoginLocksRequest) (*ClearLoginLocksResponse, error)
+	// A user's address rules. Errors:
+	// - UNAUTHENTICATED, PERMISSION_DENIED: as above
+	// - INVALID_ARGUMENT: no username
+	// - FAILED_PRECONDITION: per-user address rules are off on this server
+	ListUserIPRules(context.Context, *ListUserIPRulesRequest) (*ListUserIPRulesResponse, error)
+	// Add or change one of a user's address rules. It applies from the user's
+	// next call, Login included. Errors:
+	// - UNAUTHENTICATED, PERMISSION_DENIED, FAILED_PRECONDITION: as above
+	// - INVALID_ARGUMENT: no username, bad cidr
+	// - NOT_FOUND: unknown user
+	// - RESOURCE_EXHAUSTED: the user has the most rules allowed
+	//   (IP_RULE_LIMIT_EXCEEDED)
+	AddUserIPRule(context.Context, *AddUserIPRuleRequest) (*AddUserIPRuleResponse, error)
+	// Remove one of a user's address rules. Errors:
+	// - UNAUTHENTICATED, PERMISSION_DENIED, FAILED_PRECONDITION: as above
+	// - INVALID_ARGUMENT: no username, bad cidr
+	// - NOT_FOUND: no such rule
+	RemoveUserIPRule(context.Context, *RemoveUserIPRuleRequest) (*RemoveUserIPRuleResponse, error)
+	mustEmbedUnimplementedAdminServiceServer()
+}
+
+// UnimplementedAdminServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedAdminServiceServer struct{}
+
+func (UnimplementedAdminServiceServer) SetDiagnostics(context.Context, *SetDiagnosticsRequest) (*SetDiagnosticsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetDiagnostics not implemented")
+}
+func (UnimplementedAdminServiceServer) RollbackUser(context.Context, *RollbackUserRequest) (*RollbackUserResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RollbackUser not implemented")
+}
+func (UnimplementedAdminServiceServer) CheckUserConsistency(context.Context, *CheckUserConsistencyRequest) (*CheckUserConsistencyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckUserConsistency not implemented")
+}
+func (UnimplementedAdminServiceServer) SuspendUser(context.Context, *SuspendUserRequest) (*SuspendUserResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SuspendUser not implemented")
+}
+func (UnimplementedAdminServiceServer) ReactivateUser(context.Context, *ReactivateUserRequest) (*ReactivateUserResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReactivateUser not implemented")
+}
+func (UnimplementedAdminServiceServer) GetUsageReport(context.Context, *GetUsageReportRequest) (*GetUsageReportResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUsageReport not implemented")
+}
+func (UnimplementedAdminServiceServer) ListLoginLocks(context.Context, *ListLoginLocksRequest) (*ListLoginLocksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListLoginLocks not implemented")
+}
+func (UnimplementedAdminServiceServer) ClearLoginLocks(context.Context, *ClearLoginLocksRequest) (*ClearLoginLocksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ClearLoginLocks not implemented")
+}
+func (UnimplementedAdminServiceServer) ListUserIPRules(context.Context, *ListUserIPRulesRequest) (*ListUserIPRulesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListUserIPRules not implemented")
+}
+func (UnimplementedAdminServiceServer) AddUserIPRule(context.Context, *AddUserIPRuleRequest) (*AddUserIPRuleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddUserIPRule not implemented")
+}
+func (UnimplementedAdminServiceServer) RemoveUserIPRule(context.Context, *RemoveUserIPRuleRequest) (*RemoveUserIPRuleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveUserIPRule not implemented")
+}
+func (UnimplementedAdminServiceServer) mustEmbedUnimplementedAdminServiceServer() {}
+func (UnimplementedAdminServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeAdminServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AdminServiceServer will
+// result in compilation errors.
+type UnsafeAdminServiceServer interface {
+	mustEmbedUnimplementedAdminServiceServer()
+}
+
+func RegisterAdminServiceServer(s grpc.ServiceRegistrar, srv AdminServiceServer) {
+	// If the following call pancis, it indicates UnimplementedAdminServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&AdminService_ServiceDesc, srv)
+}
+
+func _AdminService_SetDiagnostics_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetDiagnosticsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).SetDiagnostics(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_SetDiagnostics_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).SetDiagnostics(ctx, req.(*SetDiagnosticsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_RollbackUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RollbackUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).RollbackUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_RollbackUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).RollbackUser(ctx, req.(*RollbackUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_CheckUserConsistency_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckUserConsistencyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).CheckUserConsistency(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_CheckUserConsistency_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).CheckUserConsistency(ctx, req.(*CheckUserConsistencyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_SuspendUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SuspendUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).SuspendUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_SuspendUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).SuspendUser(ctx, req.(*SuspendUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_ReactivateUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReactivateUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).ReactivateUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_ReactivateUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).ReactivateUser(ctx, req.(*ReactivateUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_GetUsageReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUsageReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).GetUsageReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_GetUsageReport_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).GetUsageReport(ctx, req.(*GetUsageReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_ListLoginLocks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListLoginLocksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).ListLoginLocks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_ListLoginLocks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).ListLoginLocks(ctx, req.(*ListLoginLocksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_ClearLoginLocks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClearLoginLocksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).ClearLoginLocks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_ClearLoginLocks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).ClearLoginLocks(ctx, req.(*ClearLoginLocksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_ListUserIPRules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListUserIPRulesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).ListUserIPRules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_ListUserIPRules_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).ListUserIPRules(ctx, req.(*ListUserIPRulesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_AddUserIPRule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddUserIPRuleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).AddUserIPRule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_AddUserIPRule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).AddUserIPRule(ctx, req.(*AddUserIPRuleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_RemoveUserIPRule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveUserIPRuleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).RemoveUserIPRule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_RemoveUserIPRule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).RemoveUserIPRule(ctx, req.(*RemoveUserIPRuleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// AdminService_ServiceDesc is the grpc.ServiceDesc for AdminService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AdminService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gophkeeper.v1.AdminService",
+	HandlerType: (*AdminServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SetDiagnostics",
+			Handler:    _AdminService_SetDiagnostics_Handler,
 		},
 		{
-			MethodName: "Login",
-			Handler:    _GophKeeper_Login_Handler,
+			MethodName: "RollbackUser",
+			Handler:    _AdminService_RollbackUser_Handler,
 		},
 		{
-			MethodName: "UpsertItems",
-			Handler:    _GophKeeper_UpsertItems_Handler,
+			MethodName: "CheckUserConsistency",
+			Handler:    _AdminService_CheckUserConsistency_Handler,
 		},
 		{
-			MethodName: "GetChanges",
-			Handler:    _GophKeeper_GetChanges_Handler,
+			MethodName: "SuspendUser",
+			Handler:    _AdminService_SuspendUser_Handler,
 		},
 		{
-			MethodName: "GetItem",
-			Handler:    _GophKeeper_GetItem_Handler,
+			MethodName: "ReactivateUser",
+			Handler:    _AdminService_ReactivateUser_Handler,
 		},
 		{
-			MethodName: "DeleteItem",
-			Handler:    _GophKeeper_DeleteItem_Handler,
+			MethodName: "GetUsageReport",
+			Handler:    _AdminService_GetUsageReport_Handler,
 		},
 		{
-			MethodName: "SetWrappedDEK",
-			Handler:    _GophKeeper_SetWrappedDEK_Handler,
+			MethodName: "ListLoginLocks",
+			Handler:    _AdminService_ListLoginLocks_Handler,
+		},
+		{
+			MethodName: "ClearLoginLocks",
+			Handler:    _AdminService_ClearLoginLocks_Handler,
+		},
+		{
+			MethodName: "ListUserIPRules",
+			Handler:    _AdminService_ListUserIPRules_Handler,
+		},
+		{
+			MethodName: "AddUserIPRule",
+			Handler:    _AdminService_AddUserIPRule_Handler,
+		},
+		{
+			MethodName: "RemoveUserIPRule",
+			Handler:    _AdminService_RemoveUserIPRule_Handler,
 		},
 	},
 	Streams:  []grpc.StreamDesc{},
@@ -0,0 +1,164 @@
+// Package auditlog records security-relevant server events and renders them
+// for SIEMs.
+//
+// Events are metadata only: who called what, from where and how it ended.
+// They are stored in the audit_events table, can be exported as JSON lines,
+// ArcSight CEF or RFC 5424 syslog, and optionally forwarded as they happen
+// to a syslog collector or an HTTP endpoint.
+package auditlog
+
+import (
+	"context"
+	"time"
+
+	"github.com/and161185/goph-keeper/internal/geoip"
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+)
+
+// Outcomes of an event.
+const (
+	Success = "success"
+	Failure = "failure"
+)
+
+// Event is one audited call.
+type Event struct {
+	ID     int64     `json:"id,omitempty"`
+	Time   time.Time `json:"time"`
+	Tenant string    `json:"tenant"`
+	// Action names the operation, e.g. "login" or "admin.rollback_user".
+	Action string `json:"action"`
+	// UserID is the acting user, uuid.Nil when unknown (a failed login).
+	UserID uuid.UUID `json:"user_id"`
+	// Subject is the username given to Register or Login.
+	Subject string `json:"subject,omitempty"`
+	// Target is the item or user the call acted on, if it names one.
+	Target  string `json:"target,omitempty"`
+	Peer    string `json:"peer,omitempty"`
+	Outcome string `json:"outcome"`
+	// Code is the gRPC status code the call ended with.
+	Code string `json:"code"`
+	// Country is the peer's ISO country code when a GeoIP database placed it.
+	Country string `json:"country,omitempty"`
+	// Flags marks an unusual call, e.g. model.LoginNewCountry for a login.
+	Flags []string `json:"flags,omitempty"`
+}
+
+// DB runs queries; *pgxpool.Pool and pgxmock pools implement it.
+type DB interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// nullUUID stores uuid.Nil as NULL.
+func nullUUID(id uuid.UUID) any {
+	if id == uuid.Nil {
+		return nil
+	}
+	return id
+}
+
+// Append stores e.
+func Append(ctx context.Context, db DB, e Event) error {
+	const q = `
+INSERT INTO audit_events (at, tenant_id, action, user_id, subject, target, peer, outcome, code, country, flags)
+VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)`
+	flags := e.Flags
+	if flags == nil {
+		flags = []string{} // nil would be NULL
+	}
+	_, err := db.Exec(ctx, q, e.Time, e.Tenant, e.Action, nullUUID(e.UserID), e.Subject, e.Target, e.Peer, e.Outcome, e.Code, e.Country, flags)
+	return err
+}
+
+// Export calls fn for every stored event in [since, until), oldest first.
+// A zero bound is open.
+func Export(ctx context.Context, db DB, since, until time.Time, fn func(Event) error) error {
+	const q = `
+SELECT id, at, tenant_id, action, user_id, subject, target, peer, outcome, code, country, flags
+FROM audit_events
+WHERE ($1::timestamptz IS NULL OR at >= $1) AND ($2::timestamptz IS NULL OR at < $2)
+ORDER BY at, id`
+	rows, err := db.Query(ctx, q, nullTime(since), nullTime(until))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			e   Event
+			uid *uuid.UUID
+		)
+		if err := rows.Scan(&e.ID, &e.Time, &e.Tenant, &e.Action, &uid, &e.Subject, &e.Target, &e.Peer, &e.Outcome, &e.Code, &e.Country, &e.Flags); err != nil {
+			return err
+		}
+		if uid != nil {
+			e.UserID = *uid
+		}
+		if len(e.Flags) == 0 {
+			e.Flags = nil
+		}
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// Prune deletes events older than before and returns how many went.
+func Prune(ctx context.Context, db DB, before time.Time) (int64, error) {
+	tag, err := db.Exec(ctx, `DELETE FROM audit_events WHERE at < $1`, before)
+	return tag.RowsAffected(), err
+}
+
+func nullTime(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return t
+}
+
+// Log is the server's audit trail: events go to the database and, when a
+// forwarder is set, to the SIEM. Failures to record are logged, never
+// returned to the caller being audited.
+type Log struct {
+	DB      DB
+	Forward *Forwarder
+	Logger  *zap.Logger
+	// Timeout bounds the insert; zero means 2s.
+	Timeout time.Duration
+	// Geo, when set, fills in the country of events from a peer.
+	Geo interface {
+		Locate(addr string) geoip.Location
+	}
+}
+
+// Record stores and forwards e.
+func (l *Log) Record(ctx context.Context, e Event) {
+	metrics.Add("recorded", 1)
+	if l.Geo != nil && e.Country == "" && e.Peer != "" {
+		e.Country = l.Geo.Locate(e.Peer).Country
+	}
+	if l.Forward != nil {
+		l.Forward.Send(e)
+	}
+	if l.DB == nil {
+		return
+	}
+	timeout := l.Timeout
+	if timeout == 0 {
+		timeout = 2 * time.Second
+	}
+	// the call's own deadline may be nearly spent; the record must not be
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+	defer cancel()
+	if err := Append(ctx, l.DB, e); err != nil {
+		metrics.Add("store_failures", 1)
+		if l.Logger != nil {
+			l.Logger.Warn("audit event not stored", zap.String("action", e.Action), zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package auditlog
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/and161185/goph-keeper/internal/geoip"
+	"github.com/gofrs/uuid/v5"
+	pgxmock "github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLog_RecordAndExport(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+	ctx := context.Background()
+	uid := uuid.Must(uuid.NewV4())
+	e := sample
+	e.UserID = uid
+
+	mock.ExpectExec(`INSERT INTO audit_events`).
+		WithArgs(e.Time, "acme", "login", uid, e.Subject, "", e.Peer, Failure, "Unauthenticated", "", []string{}).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	// a failed insert is logged, not returned
+	mock.ExpectExec(`INSERT INTO audit_events`).
+		WithArgs(e.Time, "acme", "login", nil, e.Subject, "", e.Peer, Failure, "Unauthenticated", "", []string{}).
+		WillReturnError(errors.New("down"))
+	l := &Log{DB: mock}
+	l.Record(ctx, e)
+	e.UserID = uuid.Nil
+	l.Record(ctx, e)
+
+	since := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	cols := []string{"id", "at", "tenant_id", "action", "user_id", "subject", "target", "peer", "outcome", "code", "country", "flags"}
+	mock.ExpectQuery(`FROM audit_events`).WithArgs(since, nil).
+		WillReturnRows(pgxmock.NewRows(cols).
+			AddRow(int64(1), sample.Time, "acme", "login", &uid, "eve", "", "", Success, "OK", "JP", []string{"new_country"}).
+			AddRow(int64(2), sample.Time, "acme", "login", nil, "eve", "", "", Failure, "Unauthenticated", "", []string{}))
+	var got []Event
+	require.NoError(t, Export(ctx, mock, since, time.Time{}, func(e Event) error {
+		got = append(got, e)
+		return nil
+	}))
+	require.Len(t, got, 2)
+	require.Equal(t, uid, got[0].UserID)
+	require.Equal(t, uuid.Nil, got[1].UserID)
+	require.Equal(t, []string{"new_country"}, got[0].Flags)
+	require.Nil(t, got[1].Flags)
+
+	mock.ExpectExec(`DELETE FROM audit_events WHERE at < \$1`).WithArgs(since).
+		WillReturnResult(pgxmock.NewResult("DELETE", 5))
+	n, err := Prune(ctx, mock, since)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), n)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+type fixedGeo string
+
+func (g fixedGeo) Locate(string) geoip.Location { return geoip.Location{Country: string(g)} }
+
+func TestLog_RecordLocatesPeer(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+	e := sample
+	e.Flags = []string{"impossible_travel"}
+
+	mock.ExpectExec(`INSERT INTO audit_events`).
+		WithArgs(e.Time, "acme", "login", nil, e.Subject, "", e.Peer, Failure, "Unauthenticated", "GB", e.Flags).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	l := &Log{DB: mock, Geo: fixedGeo("GB")}
+	l.Record(context.Background(), e)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,81 @@
+package auditlog
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+var sample = Event{
+	Time:    time.Date(2026, 4, 1, 10, 20, 30, 123e6, time.UTC),
+	Tenant:  "acme",
+	Action:  "login",
+	Subject: `eve|x=1 "]`,
+	Peer:    "203.0.113.7:51000",
+	Outcome: Failure,
+	Code:    "Unauthenticated",
+}
+
+func TestCEF(t *testing.T) {
+	t.Parallel()
+	got := string(CEF(sample, "1.4|beta"))
+	want := `CEF:0|GophKeeper|gk-server|1.4\|beta|login|login failure|8|` +
+		`rt=1775038830123 act=login outcome=failure src=203.0.113.7 spt=51000 suser=eve|x\=1 "] ` +
+		`cs1Label=tenant cs1=acme cs2Label=grpcCode cs2=Unauthenticated`
+	if got != want {
+		t.Fatalf("cef:\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestCEF_Flagged(t *testing.T) {
+	t.Parallel()
+	e := sample
+	e.Outcome, e.Code = Success, "OK"
+	e.Country, e.Flags = "JP", []string{"new_country", "impossible_travel"}
+	got := string(CEF(e, "1"))
+	if !strings.Contains(got, "|login success|7|") ||
+		!strings.HasSuffix(got, " cs4Label=country cs4=JP cs5Label=flags cs5=new_country,impossible_travel") {
+		t.Fatalf("flagged login: %s", got)
+	}
+	if got := string(Syslog(e, "h")); !strings.HasPrefix(got, "<84>1 ") ||
+		!strings.Contains(got, ` country="JP" flags="new_country,impossible_travel"]`) {
+		t.Fatalf("flagged login: %s", got)
+	}
+}
+
+func TestSyslog(t *testing.T) {
+	t.Parallel()
+	e := sample
+	e.UserID = uuid.Must(uuid.FromString("6ba7b810-9dad-11d1-80b4-00c04fd430c8"))
+	got := string(Syslog(e, "gk 1"))
+	want := `<84>1 2026-04-01T10:20:30.123Z gk_1 gk-server - login [gk@32473 tenant="acme" ` +
+		`user="6ba7b810-9dad-11d1-80b4-00c04fd430c8" subject="eve|x=1 \"\]" peer="203.0.113.7:51000" ` +
+		`outcome="failure" code="Unauthenticated"] login failure`
+	if got != want {
+		t.Fatalf("syslog:\n got %s\nwant %s", got, want)
+	}
+
+	e.Outcome, e.Code = Success, "OK"
+	if got := string(Syslog(e, "")); !strings.HasPrefix(got, "<86>1 ") || !strings.Contains(got, " - gk-server ") {
+		t.Fatalf("success: %s", got)
+	}
+}
+
+func TestNewFormatter(t *testing.T) {
+	t.Parallel()
+	f, err := NewFormatter("json", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back Event
+	if err := json.Unmarshal(f(sample), &back); err != nil || !reflect.DeepEqual(back, sample) {
+		t.Fatalf("json round trip: %+v %v", back, err)
+	}
+	if _, err := NewFormatter("leef", "", ""); err == nil {
+		t.Fatal("unknown format accepted")
+	}
+}
@@ -0,0 +1,232 @@
+package auditlog
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"expvar"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// metrics are published under the "audit" expvar (see /debug/vars).
+var metrics = expvar.NewMap("audit")
+
+// Forwarder ships events to a SIEM in the background. Send never blocks the
+// audited call: when the queue is full the event is dropped and counted,
+// it is still in the database.
+type Forwarder struct {
+	sink   sink
+	format Formatter
+	queue  chan Event
+	logger *zap.Logger
+}
+
+// sink delivers a batch of rendered events.
+type sink interface {
+	write(ctx context.Context, lines [][]byte) error
+	close() error
+}
+
+// maxBatch caps the events delivered in one write.
+const maxBatch = 100
+
+// NewForwarder returns a forwarder to rawURL:
+//
+//	udp://host:514          syslog, one datagram per event
+//	tcp://host:514          syslog over TCP, octet-counted (RFC 6587)
+//	tls://host:6514         syslog over TLS (RFC 5425)
+//	https://host/path       HTTP POST of newline-separated events
+//
+// Events are rendered by format. queue is the number of events buffered
+// while the destination is slow or down.
+func NewForwarder(rawURL string, format Formatter, queue int, logger *zap.Logger) (*Forwarder, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("audit forward url: %w", err)
+	}
+	var s sink
+	switch u.Scheme {
+	case "udp", "tcp", "tls":
+		if u.Host == "" {
+			return nil, fmt.Errorf("audit forward url %q: missing host:port", rawURL)
+		}
+		s = &syslogSink{network: u.Scheme, addr: u.Host}
+	case "http", "https":
+		s = &httpSink{url: u.String(), client: &http.Client{Timeout: 10 * time.Second}}
+	default:
+		return nil, fmt.Errorf("audit forward url %q: want udp://, tcp://, tls://, http:// or https://", rawURL)
+	}
+	if queue <= 0 {
+		queue = 1000
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Forwarder{sink: s, format: format, queue: make(chan Event, queue), logger: logger}, nil
+}
+
+// Send queues e for delivery.
+func (f *Forwarder) Send(e Event) {
+	select {
+	case f.queue <- e:
+	default:
+		metrics.Add("dropped", 1)
+	}
+}
+
+// Run delivers queued events until ctx is done, then makes one last
+// attempt, bounded to a few seconds, to flush what is left.
+func (f *Forwarder) Run(ctx context.Context) {
+	defer func() { _ = f.sink.close() }()
+	// a batch already taken off the queue is delivered even if ctx ends
+	// meanwhile; the sinks bound each delivery themselves
+	live := context.WithoutCancel(ctx)
+	for {
+		select {
+		case e := <-f.queue:
+			f.deliver(live, f.batch(e))
+		case <-ctx.Done():
+			fctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+			defer cancel()
+			for len(f.queue) > 0 && fctx.Err() == nil {
+				f.deliver(fctx, f.batch(<-f.queue))
+			}
+			return
+		}
+	}
+}
+
+// batch renders first and whatever else is already queued, up to maxBatch.
+func (f *Forwarder) batch(first Event) [][]byte {
+	lines := [][]byte{f.format(first)}
+	for len(lines) < maxBatch {
+		select {
+		case e := <-f.queue:
+			lines = append(lines, f.format(e))
+		default:
+			return lines
+		}
+	}
+	return lines
+}
+
+func (f *Forwarder) deliver(ctx context.Context, lines [][]byte) {
+	if err := f.sink.write(ctx, lines); err != nil {
+		metrics.Add("forward_failures", 1)
+		metrics.Add("dropped", int64(len(lines)))
+		f.logger.Warn("audit forward", zap.Int("events", len(lines)), zap.Error(err))
+		return
+	}
+	metrics.Add("forwarded", int64(len(lines)))
+}
+
+// syslogSink writes to a syslog collector, reconnecting once per batch
+// after a failed stream write.
+type syslogSink struct {
+	network, addr string
+	conn          net.Conn
+}
+
+func (s *syslogSink) dial(ctx context.Context) error {
+	d := &net.Dialer{Timeout: 5 * time.Second}
+	var err error
+	switch s.network {
+	case "tls":
+		td := &tls.Dialer{NetDialer: d, Config: &tls.Config{MinVersion: tls.VersionTLS12}}
+		s.conn, err = td.DialContext(ctx, "tcp", s.addr)
+	default:
+		s.conn, err = d.DialContext(ctx, s.network, s.addr)
+	}
+	return err
+}
+
+func (s *syslogSink) write(ctx context.Context, lines [][]byte) error {
+	var buf bytes.Buffer
+	for _, l := range lines {
+		if s.network != "udp" {
+			buf.WriteString(strconv.Itoa(len(l)) + " ")
+		}
+		buf.Write(l)
+		if s.network == "udp" {
+			if err := s.send(ctx, buf.Bytes()); err != nil {
+				return err
+			}
+			buf.Reset()
+		}
+	}
+	if s.network == "udp" {
+		return nil
+	}
+	err := s.send(ctx, buf.Bytes())
+	if err != nil && s.conn == nil {
+		err = s.send(ctx, buf.Bytes())
+	}
+	return err
+}
+
+// send writes p, dropping the connection on error so the next send redials.
+func (s *syslogSink) send(ctx context.Context, p []byte) error {
+	if s.conn == nil {
+		if err := s.dial(ctx); err != nil {
+			return err
+		}
+	}
+	if dl, ok := ctx.Deadline(); ok {
+		_ = s.conn.SetWriteDeadline(dl)
+	} else {
+		_ = s.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	}
+	if _, err := s.conn.Write(p); err != nil {
+		_ = s.conn.Close()
+		s.conn = nil
+		return err
+	}
+	return nil
+}
+
+func (s *syslogSink) close() error {
+	if s.conn == nil {
+		return nil
+	}
+	return s.conn.Close()
+}
+
+// httpSink posts each batch as one newline-separated body.
+type httpSink struct {
+	url    string
+	client *http.Client
+}
+
+func (h *httpSink) write(ctx context.Context, lines [][]byte) error {
+	body := append(bytes.Join(lines, []byte("\n")), '\n')
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	ct := "text/plain; charset=utf-8"
+	if len(lines) > 0 && bytes.HasPrefix(lines[0], []byte("{")) {
+		ct = "application/x-ndjson"
+	}
+	req.Header.Set("Content-Type", ct)
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4<<10))
+	if resp.StatusCode/100 != 2 {
+		return errors.New("audit forward: " + resp.Status)
+	}
+	return nil
+}
+
+func (h *httpSink) close() error { return nil }
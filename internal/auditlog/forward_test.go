@@ -0,0 +1,106 @@
+package auditlog
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// forwardAll sends events through a forwarder to rawURL and stops it.
+func forwardAll(t *testing.T, rawURL, format string, events ...Event) {
+	t.Helper()
+	f, err := NewFormatter(format, "h", "v")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fw, err := NewForwarder(rawURL, f, 10, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range events {
+		fw.Send(e)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel() // Run flushes the queue on the way out
+	fw.Run(ctx)
+}
+
+func TestForwarder_TCPSyslog(t *testing.T) {
+	t.Parallel()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	got := make(chan string, 1)
+	go func() {
+		c, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		b, _ := io.ReadAll(c)
+		got <- string(b)
+	}()
+
+	e2 := sample
+	e2.Action = "register"
+	forwardAll(t, "tcp://"+ln.Addr().String(), "syslog", sample, e2)
+
+	select {
+	case s := <-got:
+		// octet counting: "<len> <msg>" frames back to back
+		var want string
+		for _, e := range []Event{sample, e2} {
+			l := string(Syslog(e, "h"))
+			want += strconv.Itoa(len(l)) + " " + l
+		}
+		if s != want {
+			t.Fatalf("stream:\n got %q\nwant %q", s, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("nothing received")
+	}
+}
+
+func TestForwarder_HTTP(t *testing.T) {
+	t.Parallel()
+	var (
+		mu    sync.Mutex
+		lines []string
+		ct    string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		ct = r.Header.Get("Content-Type")
+		sc := bufio.NewScanner(r.Body)
+		for sc.Scan() {
+			lines = append(lines, sc.Text())
+		}
+	}))
+	defer srv.Close()
+
+	forwardAll(t, srv.URL+"/ingest", "json", sample, sample, sample)
+	mu.Lock()
+	defer mu.Unlock()
+	if len(lines) != 3 || ct != "application/x-ndjson" || lines[0] != string(JSON(sample)) {
+		t.Fatalf("posted %q as %q", lines, ct)
+	}
+}
+
+func TestNewForwarder_BadURL(t *testing.T) {
+	t.Parallel()
+	for _, u := range []string{"ftp://x", "udp://", "::"} {
+		if _, err := NewForwarder(u, JSON, 0, nil); err == nil {
+			t.Fatalf("%q accepted", u)
+		}
+	}
+}
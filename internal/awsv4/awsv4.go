@@ -0,0 +1,228 @@
+// Package awsv4 signs HTTP requests to AWS APIs with Signature Version 4,
+// enough for the few JSON calls the server makes without pulling in the SDK.
+package awsv4
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Credentials are static AWS credentials.
+type Credentials struct {
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+}
+
+// FromEnv reads credentials from AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN.
+func FromEnv() Credentials {
+	return Credentials{
+		AccessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+	}
+}
+
+// RegionFromEnv returns AWS_REGION, falling back to AWS_DEFAULT_REGION.
+func RegionFromEnv() string {
+	if r := os.Getenv("AWS_REGION"); r != "" {
+		return r
+	}
+	return os.Getenv("AWS_DEFAULT_REGION")
+}
+
+// Sign adds AWS Signature Version 4 headers to req, signing every header
+// already set plus host and x-amz-date. body must be the exact request body.
+func Sign(req *http.Request, body []byte, c Credentials, region, service string, t time.Time) {
+	SignHash(req, hexSHA256(body), c, region, service, t)
+}
+
+// UnsignedPayload is the payload hash S3 accepts over TLS for bodies that
+// are streamed rather than hashed up front.
+const UnsignedPayload = "UNSIGNED-PAYLOAD"
+
+// SignHash is Sign with the payload hash given instead of the body: the hex
+// SHA-256 of the body, or UnsignedPayload. S3 also wants it in the
+// X-Amz-Content-Sha256 header, which the caller sets before signing.
+func SignHash(req *http.Request, payloadHash string, c Credentials, region, service string, t time.Time) {
+	if c.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", c.SessionToken)
+	}
+	t = t.UTC()
+	amzDate := t.Format("20060102T150405Z")
+	day := t.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		headers[strings.ToLower(k)] = strings.TrimSpace(strings.Join(v, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var canonHeaders strings.Builder
+	for _, k := range names {
+		canonHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signed := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonical := strings.Join([]string{
+		req.Method,
+		path,
+		canonicalQuery(req),
+		canonHeaders.String(),
+		signed,
+		payloadHash,
+	}, "\n")
+
+	scope := day + "/" + region + "/" + service + "/aws4_request"
+	toSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hexSHA256([]byte(canonical))
+
+	key := hmacSHA256([]byte("AWS4"+c.SecretKey), day)
+	for _, part := range []string{region, service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	sig := hex.EncodeToString(hmacSHA256(key, toSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		c.AccessKey, scope, signed, sig))
+}
+
+// canonicalQuery sorts query parameters by key, then value.
+func canonicalQuery(req *http.Request) string {
+	q := req.URL.Query()
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		vs := q[k]
+		sort.Strings(vs)
+		for _, v := range vs {
+			parts = append(parts, awsEscape(k)+"="+awsEscape(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// awsEscape percent-encodes everything except RFC 3986 unreserved characters.
+func awsEscape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') || strings.IndexByte("-_.~", c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func hexSHA256(b []byte) string {
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	m := hmac.New(sha256.New, key)
+	m.Write([]byte(data))
+	return m.Sum(nil)
+}
+
+// APIError is a non-2xx answer from an AWS JSON API.
+type APIError struct {
+	Status  int
+	Type    string // e.g. "ResourceNotFoundException"
+	Message string
+}
+
+// Error implements error.
+func (e *APIError) Error() string {
+	return fmt.Sprintf("aws: %d %s: %s", e.Status, e.Type, e.Message)
+}
+
+// Client calls AWS JSON 1.1 APIs (Secrets Manager, KMS, ...).
+type Client struct {
+	Region   string
+	Creds    Credentials
+	Endpoint string // default https://<service>.<region>.amazonaws.com
+	HTTP     *http.Client
+	Now      func() time.Time
+}
+
+// FromEnvClient returns a client configured from the AWS_* environment.
+func FromEnvClient() *Client {
+	return &Client{Region: RegionFromEnv(), Creds: FromEnv()}
+}
+
+// Do posts in as JSON with X-Amz-Target set to target and decodes the answer into out.
+func (c *Client) Do(ctx context.Context, service, target string, in, out any) error {
+	if c.Region == "" || c.Creds.AccessKey == "" || c.Creds.SecretKey == "" {
+		return errors.New("AWS_REGION, AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set")
+	}
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	endpoint := c.Endpoint
+	if endpoint == "" {
+		endpoint = "https://" + service + "." + c.Region + ".amazonaws.com"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(endpoint, "/")+"/", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", target)
+	now := time.Now
+	if c.Now != nil {
+		now = c.Now
+	}
+	Sign(req, body, c.Creds, c.Region, service, now())
+
+	cli := c.HTTP
+	if cli == nil {
+		cli = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := cli.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode/100 != 2 {
+		var e struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(raw, &e)
+		if i := strings.LastIndexByte(e.Type, '#'); i >= 0 {
+			e.Type = e.Type[i+1:]
+		}
+		return &APIError{Status: resp.StatusCode, Type: e.Type, Message: e.Message}
+	}
+	return json.Unmarshal(raw, out)
+}
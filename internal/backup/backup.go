@@ -0,0 +1,276 @@
+// Package backup takes consistent snapshots of the server database, seals
+// them with a server-held key and keeps them in object storage.
+//
+// A snapshot is a gzip-compressed stream of JSON lines: a header, then the
+// users, items and item history of its scope (the whole database or one
+// user), read in a single repeatable-read transaction. Item blobs are stored
+// as they are in the database, still sealed by their owners' keys; the
+// backup key additionally hides usernames, password hashes and wrapped DEKs.
+package backup
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Format is the snapshot layout version written in every header.
+const Format = 1
+
+// Beginner opens transactions; *pgxpool.Pool and pgxmock pools implement it.
+type Beginner interface {
+	BeginTx(ctx context.Context, opts pgx.TxOptions) (pgx.Tx, error)
+}
+
+// Header opens a snapshot.
+type Header struct {
+	Format    int       `json:"format"`
+	CreatedAt time.Time `json:"created_at"`
+	// UserID is set for a one-user snapshot; nil means the whole database.
+	UserID *uuid.UUID `json:"user_id,omitempty"`
+}
+
+// User is a users row.
+type User struct {
+	ID         uuid.UUID `json:"id"`
+	TenantID   string    `json:"tenant_id"`
+	Username   string    `json:"username"`
+	PwdHash    []byte    `json:"pwd_hash"`
+	SaltAuth   []byte    `json:"salt_auth"`
+	KEKSalt    []byte    `json:"kek_salt"`
+	WrappedDEK []byte    `json:"wrapped_dek"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Item is an items or item_history row.
+type Item struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"user_id"`
+	TenantID  string    `json:"tenant_id"`
+	Ver       int64     `json:"ver"`
+	BlobEnc   []byte    `json:"blob_enc"`
+	Deleted   bool      `json:"deleted"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// record is one line of a snapshot; exactly one field is set.
+type record struct {
+	Header  *Header `json:"header,omitempty"`
+	User    *User   `json:"user,omitempty"`
+	Item    *Item   `json:"item,omitempty"`
+	History *Item   `json:"history,omitempty"`
+}
+
+// Stats counts the rows of a snapshot.
+type Stats struct {
+	Users   int `json:"users"`
+	Items   int `json:"items"`
+	History int `json:"history"`
+}
+
+// ErrFormat is returned for a stream that is not a snapshot this version can read.
+var ErrFormat = errors.New("backup: not a snapshot")
+
+// Dump writes a snapshot of userID's data, or of everything when userID is
+// uuid.Nil, to w. All rows come from one read-only repeatable-read
+// transaction, so the snapshot is consistent while writes go on.
+func Dump(ctx context.Context, db Beginner, userID uuid.UUID, now time.Time, w io.Writer) (Stats, error) {
+	var st Stats
+	tx, err := db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return st, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	enc := json.NewEncoder(w)
+	h := Header{Format: Format, CreatedAt: now.UTC()}
+	where, args := "", []any(nil)
+	if userID != uuid.Nil {
+		h.UserID = &userID
+		args = []any{userID}
+	}
+	if err := enc.Encode(record{Header: &h}); err != nil {
+		return st, err
+	}
+
+	if args != nil {
+		where = " WHERE id=$1"
+	}
+	err = each(ctx, tx, `SELECT id, tenant_id, username, pwd_hash, salt_auth, kek_salt, wrapped_dek, created_at FROM users`+where+` ORDER BY id`, args,
+		func(rows pgx.Rows) error {
+			var u User
+			if err := rows.Scan(&u.ID, &u.TenantID, &u.Username, &u.PwdHash, &u.SaltAuth, &u.KEKSalt, &u.WrappedDEK, &u.CreatedAt); err != nil {
+				return err
+			}
+			st.Users++
+			return enc.Encode(record{User: &u})
+		})
+	if err != nil {
+		return st, fmt.Errorf("dump users: %w", err)
+	}
+
+	if args != nil {
+		where = " WHERE user_id=$1"
+	}
+	err = each(ctx, tx, `SELECT id, user_id, tenant_id, ver, blob_enc, deleted, updated_at FROM items`+where+` ORDER BY id`, args,
+		func(rows pgx.Rows) error {
+			var it Item
+			if err := rows.Scan(&it.ID, &it.UserID, &it.TenantID, &it.Ver, &it.BlobEnc, &it.Deleted, &it.UpdatedAt); err != nil {
+				return err
+			}
+			st.Items++
+			return enc.Encode(record{Item: &it})
+		})
+	if err != nil {
+		return st, fmt.Errorf("dump items: %w", err)
+	}
+
+	err = each(ctx, tx, `SELECT item_id, user_id, tenant_id, ver, blob_enc, deleted, updated_at FROM item_history`+where+` ORDER BY item_id, ver`, args,
+		func(rows pgx.Rows) error {
+			var it Item
+			if err := rows.Scan(&it.ID, &it.UserID, &it.TenantID, &it.Ver, &it.BlobEnc, &it.Deleted, &it.UpdatedAt); err != nil {
+				return err
+			}
+			st.History++
+			return enc.Encode(record{History: &it})
+		})
+	if err != nil {
+		return st, fmt.Errorf("dump history: %w", err)
+	}
+	return st, tx.Commit(ctx)
+}
+
+// each runs query and calls fn for every row.
+func each(ctx context.Context, tx pgx.Tx, query string, args []any, fn func(pgx.Rows) error) error {
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := fn(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// ReadHeader reads the header line of a snapshot from br.
+func ReadHeader(br *bufio.Reader) (Header, error) {
+	line, err := br.ReadBytes('\n')
+	if err != nil && len(line) == 0 {
+		return Header{}, fmt.Errorf("%w: %v", ErrFormat, err)
+	}
+	var rec record
+	if json.Unmarshal(line, &rec) != nil || rec.Header == nil {
+		return Header{}, ErrFormat
+	}
+	if rec.Header.Format != Format {
+		return Header{}, fmt.Errorf("%w: format %d, want %d", ErrFormat, rec.Header.Format, Format)
+	}
+	return *rec.Header, nil
+}
+
+// ErrExists is returned by Restore without replace when the target already
+// holds a user or item from the snapshot.
+var ErrExists = errors.New("backup: target already has rows from the snapshot")
+
+// Restore loads a snapshot in one transaction. With replace, the scope is
+// emptied first: every user for a full snapshot, the one user otherwise
+// (their items and history go with them by cascade). Without it the target
+// must not hold any of the snapshot's rows yet. A hold above 0 is the legal
+// hold window: replace fails with errs.ErrLegalHold while the scope has
+// item history younger than that.
+func Restore(ctx context.Context, db Beginner, r io.Reader, replace bool, hold time.Duration) (Header, Stats, error) {
+	var st Stats
+	br := bufio.NewReader(r)
+	h, err := ReadHeader(br)
+	if err != nil {
+		return h, st, err
+	}
+	tx, err := db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return h, st, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if replace && hold > 0 {
+		var found bool
+		if h.UserID != nil {
+			err = tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM item_history WHERE user_id=$1 AND updated_at>now()-make_interval(secs => $2))`,
+				*h.UserID, hold.Seconds()).Scan(&found)
+		} else {
+			err = tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM item_history WHERE updated_at>now()-make_interval(secs => $1))`,
+				hold.Seconds()).Scan(&found)
+		}
+		if err != nil {
+			return h, st, fmt.Errorf("check legal hold: %w", err)
+		}
+		if found {
+			return h, st, fmt.Errorf("%w: the scope has history newer than %s", errs.ErrLegalHold, hold)
+		}
+	}
+	if replace {
+		if h.UserID != nil {
+			_, err = tx.Exec(ctx, `DELETE FROM users WHERE id=$1`, *h.UserID)
+		} else {
+			_, err = tx.Exec(ctx, `DELETE FROM users`)
+		}
+		if err != nil {
+			return h, st, fmt.Errorf("clear scope: %w", err)
+		}
+	}
+
+	dec := json.NewDecoder(br)
+	for {
+		var rec record
+		if err := dec.Decode(&rec); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return h, st, fmt.Errorf("%w: %v", ErrFormat, err)
+		}
+		switch {
+		case rec.User != nil:
+			u := rec.User
+			_, err = tx.Exec(ctx, `INSERT INTO users (id, tenant_id, username, pwd_hash, salt_auth, kek_salt, wrapped_dek, created_at) VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`,
+				u.ID, u.TenantID, u.Username, u.PwdHash, u.SaltAuth, u.KEKSalt, u.WrappedDEK, u.CreatedAt)
+			st.Users++
+		case rec.Item != nil:
+			it := rec.Item
+			// the history trigger records this version too; the history
+			// lines that follow skip it on conflict
+			_, err = tx.Exec(ctx, `INSERT INTO items (id, user_id, tenant_id, ver, blob_enc, deleted, updated_at) VALUES ($1,$2,$3,$4,$5,$6,$7)`,
+				it.ID, it.UserID, it.TenantID, it.Ver, it.BlobEnc, it.Deleted, it.UpdatedAt)
+			st.Items++
+		case rec.History != nil:
+			it := rec.History
+			_, err = tx.Exec(ctx, `INSERT INTO item_history (item_id, user_id, tenant_id, ver, blob_enc, deleted, updated_at) VALUES ($1,$2,$3,$4,$5,$6,$7) ON CONFLICT (item_id, ver) DO NOTHING`,
+				it.ID, it.UserID, it.TenantID, it.Ver, it.BlobEnc, it.Deleted, it.UpdatedAt)
+			st.History++
+		default:
+			return h, st, fmt.Errorf("%w: unexpected line", ErrFormat)
+		}
+		if isUniqueViolation(err) {
+			return h, st, ErrExists
+		}
+		if err != nil {
+			return h, st, err
+		}
+	}
+	return h, st, tx.Commit(ctx)
+}
+
+// isUniqueViolation reports whether the error is a unique constraint violation.
+func isUniqueViolation(err error) bool {
+	var pg *pgconn.PgError
+	return errors.As(err, &pg) && pg.Code == "23505"
+}
@@ -0,0 +1,160 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	pgxmock "github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	userCols = []string{"id", "tenant_id", "username", "pwd_hash", "salt_auth", "kek_salt", "wrapped_dek", "created_at"}
+	itemCols = []string{"id", "user_id", "tenant_id", "ver", "blob_enc", "deleted", "updated_at"}
+)
+
+func expectDump(mock pgxmock.PgxPoolIface, uid, iid uuid.UUID, ts time.Time, args ...any) {
+	mock.ExpectBeginTx(pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	mock.ExpectQuery(`SELECT id, tenant_id, username, .* FROM users`).WithArgs(args...).
+		WillReturnRows(pgxmock.NewRows(userCols).AddRow(uid, "default", "alice", []byte("h"), []byte("s"), []byte("k"), []byte("w"), ts))
+	mock.ExpectQuery(`SELECT id, user_id, .* FROM items`).WithArgs(args...).
+		WillReturnRows(pgxmock.NewRows(itemCols).AddRow(iid, uid, "default", int64(2), []byte("v2"), false, ts))
+	mock.ExpectQuery(`SELECT item_id, user_id, .* FROM item_history`).WithArgs(args...).
+		WillReturnRows(pgxmock.NewRows(itemCols).
+			AddRow(iid, uid, "default", int64(1), []byte("v1"), false, ts).
+			AddRow(iid, uid, "default", int64(2), []byte("v2"), false, ts))
+	mock.ExpectCommit()
+}
+
+func TestTake_RestoreRoundTrip(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+	ctx := context.Background()
+	uid, iid := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	key := bytes.Repeat([]byte{1}, KeyLen)
+	store := Dir(t.TempDir())
+
+	expectDump(mock, uid, iid, ts, uid)
+	obj, st, err := Take(ctx, mock, store, key, uid, ts)
+	require.NoError(t, err)
+	require.Equal(t, "user-"+uid.String()+"/20260102T030405Z.gkbk", obj.Key)
+	require.Equal(t, Stats{Users: 1, Items: 1, History: 2}, st)
+
+	r, err := store.Get(ctx, obj.Key)
+	require.NoError(t, err)
+	defer r.Close()
+	raw, _ := io.ReadAll(r)
+	require.NotContains(t, string(raw), "alice", "snapshot stored in the clear")
+	plain, err := OpenSnapshot(bytes.NewReader(raw), key)
+	require.NoError(t, err)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM users WHERE id=\$1`).WithArgs(uid).WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	mock.ExpectExec(`INSERT INTO users`).
+		WithArgs(uid, "default", "alice", []byte("h"), []byte("s"), []byte("k"), []byte("w"), ts).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectExec(`INSERT INTO items`).
+		WithArgs(iid, uid, "default", int64(2), []byte("v2"), false, ts).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	for _, v := range []string{"v1", "v2"} {
+		mock.ExpectExec(`INSERT INTO item_history .* ON CONFLICT \(item_id, ver\) DO NOTHING`).
+			WithArgs(iid, uid, "default", pgxmock.AnyArg(), []byte(v), false, ts).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	}
+	mock.ExpectCommit()
+	h, st, err := Restore(ctx, mock, plain, true, 0)
+	require.NoError(t, err)
+	require.Equal(t, uid, *h.UserID)
+	require.Equal(t, Stats{Users: 1, Items: 1, History: 2}, st)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRestore_LegalHold(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+	ctx := context.Background()
+	uid, iid := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	ts := time.Now().UTC()
+	hold := 30 * 24 * time.Hour
+
+	expectDump(mock, uid, iid, ts, uid)
+	var buf bytes.Buffer
+	_, err = Dump(ctx, mock, uid, ts, &buf)
+	require.NoError(t, err)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM item_history WHERE user_id=\$1`).WithArgs(uid, hold.Seconds()).
+		WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectRollback()
+	_, _, err = Restore(ctx, mock, &buf, true, hold)
+	require.ErrorIs(t, err, errs.ErrLegalHold)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRestore_ExistingRows(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+	ctx := context.Background()
+	uid, iid := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	ts := time.Now().UTC()
+
+	expectDump(mock, uid, iid, ts)
+	var buf bytes.Buffer
+	_, err = Dump(ctx, mock, uuid.Nil, ts, &buf)
+	require.NoError(t, err)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO users`).
+		WithArgs(pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnError(&pgconn.PgError{Code: "23505"})
+	mock.ExpectRollback()
+	_, _, err = Restore(ctx, mock, &buf, false, 0)
+	require.ErrorIs(t, err, ErrExists)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	_, _, err = Restore(ctx, mock, bytes.NewReader([]byte(`{"user":{}}`+"\n")), false, 0)
+	require.ErrorIs(t, err, ErrFormat)
+}
+
+func TestPrune(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := Dir(t.TempDir())
+	now := time.Date(2026, 5, 10, 0, 0, 0, 0, time.UTC)
+	uid := uuid.Must(uuid.NewV4())
+	for d := 0; d < 5; d++ {
+		for _, id := range []uuid.UUID{uuid.Nil, uid} {
+			require.NoError(t, store.Put(ctx, ObjectKey(id, now.AddDate(0, 0, -d)), bytes.NewReader(nil), 0))
+		}
+	}
+	// a lone old snapshot survives max-age as the newest of its scope
+	old := ObjectKey(uuid.Must(uuid.NewV4()), now.AddDate(0, -1, 0))
+	require.NoError(t, store.Put(ctx, old, bytes.NewReader(nil), 0))
+	require.NoError(t, store.Put(ctx, "notes.txt", bytes.NewReader(nil), 0))
+
+	deleted, err := Prune(ctx, store, 3, 0, now)
+	require.NoError(t, err)
+	require.Len(t, deleted, 4)
+	require.Contains(t, deleted, ObjectKey(uuid.Nil, now.AddDate(0, 0, -4)))
+
+	deleted, err = Prune(ctx, store, 0, 36*time.Hour, now)
+	require.NoError(t, err)
+	require.Equal(t, []string{ObjectKey(uuid.Nil, now.AddDate(0, 0, -2)), ObjectKey(uid, now.AddDate(0, 0, -2))}, deleted)
+
+	latest, err := Latest(ctx, store)
+	require.NoError(t, err)
+	require.True(t, latest.Equal(now))
+	objs, _ := store.List(ctx, "")
+	require.Len(t, objs, 6) // 2 scopes x 2, the lone snapshot and notes.txt
+}
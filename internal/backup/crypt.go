@@ -0,0 +1,193 @@
+package backup
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Sealed snapshots are AES-256-GCM in the STREAM construction: the
+// plaintext is cut into chunkSize pieces, each sealed under nonce
+// prefix || counter || last-flag with the file header as additional data,
+// so chunks cannot be reordered, dropped or truncated unnoticed.
+const (
+	magic     = "GKBK"
+	version   = 1
+	prefixLen = 7
+	headerLen = len(magic) + 1 + prefixLen
+	chunkSize = 64 << 10
+	tagSize   = 16
+)
+
+// KeyLen is the backup key size in bytes.
+const KeyLen = 32
+
+// ErrCorrupt is returned when a sealed stream fails authentication.
+var ErrCorrupt = errors.New("backup: corrupt or sealed with another key")
+
+// ParseKey decodes a backup key given as hex or standard base64.
+func ParseKey(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	if k, err := hex.DecodeString(s); err == nil && len(k) == KeyLen {
+		return k, nil
+	}
+	if k, err := base64.StdEncoding.DecodeString(s); err == nil && len(k) == KeyLen {
+		return k, nil
+	}
+	return nil, fmt.Errorf("backup key: want %d bytes as hex or base64", KeyLen)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeyLen {
+		return nil, fmt.Errorf("backup key: want %d bytes, got %d", KeyLen, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func chunkNonce(prefix []byte, n uint32, last bool) []byte {
+	nonce := make([]byte, 12)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[prefixLen:], n)
+	if last {
+		nonce[11] = 1
+	}
+	return nonce
+}
+
+// sealWriter encrypts into w. A full chunk is sealed only once more data
+// arrives, so that Close can mark the final chunk, which may be empty.
+type sealWriter struct {
+	w      io.Writer
+	aead   cipher.AEAD
+	header []byte
+	n      uint32
+	buf    []byte
+	out    []byte
+}
+
+// NewWriter returns a writer that seals everything written to it into w.
+// Close must be called to write the final chunk; it does not close w.
+func NewWriter(w io.Writer, key []byte) (io.WriteCloser, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	header := make([]byte, headerLen)
+	copy(header, magic)
+	header[len(magic)] = version
+	if _, err := rand.Read(header[len(magic)+1:]); err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+	return &sealWriter{w: w, aead: aead, header: header, buf: make([]byte, 0, chunkSize)}, nil
+}
+
+func (s *sealWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		if len(s.buf) == chunkSize {
+			if err := s.seal(false); err != nil {
+				return written, err
+			}
+		}
+		k := copy(s.buf[len(s.buf):chunkSize], p)
+		s.buf = s.buf[:len(s.buf)+k]
+		p = p[k:]
+		written += k
+	}
+	return written, nil
+}
+
+func (s *sealWriter) seal(last bool) error {
+	if s.n == ^uint32(0) {
+		return errors.New("backup: stream too long")
+	}
+	nonce := chunkNonce(s.header[len(magic)+1:], s.n, last)
+	s.out = s.aead.Seal(s.out[:0], nonce, s.buf, s.header)
+	s.n++
+	s.buf = s.buf[:0]
+	_, err := s.w.Write(s.out)
+	return err
+}
+
+// Close seals the final chunk.
+func (s *sealWriter) Close() error { return s.seal(true) }
+
+// openReader decrypts a sealed stream chunk by chunk.
+type openReader struct {
+	r      *bufio.Reader
+	aead   cipher.AEAD
+	header []byte
+	n      uint32
+	in     []byte
+	plain  []byte
+	done   bool
+}
+
+// NewReader returns a reader of the plaintext sealed in r. It fails with
+// ErrCorrupt on any tampering, including a stream cut short.
+func NewReader(r io.Reader, key []byte) (io.Reader, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	header := make([]byte, headerLen)
+	if _, err := io.ReadFull(r, header); err != nil || string(header[:len(magic)]) != magic {
+		return nil, ErrFormat
+	}
+	if header[len(magic)] != version {
+		return nil, fmt.Errorf("%w: sealed format %d", ErrFormat, header[len(magic)])
+	}
+	return &openReader{r: bufio.NewReaderSize(r, chunkSize+tagSize+1), aead: aead, header: header,
+		in: make([]byte, chunkSize+tagSize)}, nil
+}
+
+func (o *openReader) Read(p []byte) (int, error) {
+	for len(o.plain) == 0 {
+		if o.done {
+			return 0, io.EOF
+		}
+		if err := o.next(); err != nil {
+			return 0, err
+		}
+	}
+	k := copy(p, o.plain)
+	o.plain = o.plain[k:]
+	return k, nil
+}
+
+func (o *openReader) next() error {
+	k, err := io.ReadFull(o.r, o.in)
+	switch {
+	case errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, io.EOF):
+		o.done = true // a short chunk is the last one
+	case err != nil:
+		return err
+	default:
+		if _, perr := o.r.Peek(1); errors.Is(perr, io.EOF) {
+			o.done = true
+		}
+	}
+	nonce := chunkNonce(o.header[len(magic)+1:], o.n, o.done)
+	plain, err := o.aead.Open(o.in[:0:0], nonce, o.in[:k], o.header)
+	if err != nil {
+		return ErrCorrupt
+	}
+	o.n++
+	o.plain = plain
+	return nil
+}
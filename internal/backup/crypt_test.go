@@ -0,0 +1,93 @@
+package backup
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"testing"
+)
+
+func sealAll(t *testing.T, key, plain []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// odd write sizes exercise chunk boundaries
+	for p := plain; len(p) > 0; {
+		n := min(len(p), 10007)
+		if _, err := w.Write(p[:n]); err != nil {
+			t.Fatal(err)
+		}
+		p = p[n:]
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func openAll(key, sealed []byte) ([]byte, error) {
+	r, err := NewReader(bytes.NewReader(sealed), key)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func TestSeal_RoundTrip(t *testing.T) {
+	t.Parallel()
+	key := make([]byte, KeyLen)
+	_, _ = rand.Read(key)
+	for _, n := range []int{0, 1, chunkSize, 2*chunkSize + 5} {
+		plain := make([]byte, n)
+		_, _ = rand.Read(plain)
+		got, err := openAll(key, sealAll(t, key, plain))
+		if err != nil || !bytes.Equal(got, plain) {
+			t.Fatalf("size %d: %v", n, err)
+		}
+	}
+}
+
+func TestSeal_DetectsTampering(t *testing.T) {
+	t.Parallel()
+	key := make([]byte, KeyLen)
+	_, _ = rand.Read(key)
+	plain := make([]byte, 2*chunkSize+5)
+	sealed := sealAll(t, key, plain)
+
+	other := make([]byte, KeyLen)
+	if _, err := openAll(other, sealed); !errors.Is(err, ErrCorrupt) {
+		t.Fatalf("wrong key: %v", err)
+	}
+	flipped := bytes.Clone(sealed)
+	flipped[headerLen+100] ^= 1
+	if _, err := openAll(key, flipped); !errors.Is(err, ErrCorrupt) {
+		t.Fatalf("flipped bit: %v", err)
+	}
+	// cut after the second full chunk: it was not sealed as the last one
+	cut := sealed[:headerLen+2*(chunkSize+tagSize)]
+	if _, err := openAll(key, cut); !errors.Is(err, ErrCorrupt) {
+		t.Fatalf("truncated: %v", err)
+	}
+	if _, err := openAll(key, []byte("not a backup")); !errors.Is(err, ErrFormat) {
+		t.Fatalf("garbage: %v", err)
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	t.Parallel()
+	key := bytes.Repeat([]byte{7}, KeyLen)
+	for _, s := range []string{hex.EncodeToString(key), base64.StdEncoding.EncodeToString(key) + "\n"} {
+		if got, err := ParseKey(s); err != nil || !bytes.Equal(got, key) {
+			t.Fatalf("%q: %v", s, err)
+		}
+	}
+	if _, err := ParseKey("abcd"); err == nil {
+		t.Fatal("short key accepted")
+	}
+}
@@ -0,0 +1,176 @@
+package backup
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/and161185/goph-keeper/internal/awsv4"
+)
+
+// S3 is a Store in an S3 bucket, or an S3-compatible service (MinIO, Ceph,
+// R2, ...) when Endpoint is set. Bodies are sent as UNSIGNED-PAYLOAD, so the
+// endpoint should be HTTPS.
+type S3 struct {
+	Bucket   string
+	Prefix   string // key prefix inside the bucket, without slashes at the ends
+	Endpoint string // e.g. https://minio.internal:9000; empty = AWS
+	API      *awsv4.Client
+}
+
+// objectURL addresses key (or the bucket itself when key is empty).
+func (s *S3) objectURL(key string) (*url.URL, error) {
+	if s.Prefix != "" && key != "" {
+		key = s.Prefix + "/" + key
+	}
+	var u *url.URL
+	segs := []string{}
+	if s.Endpoint != "" {
+		var err error
+		if u, err = url.Parse(strings.TrimRight(s.Endpoint, "/")); err != nil {
+			return nil, fmt.Errorf("s3 endpoint: %w", err)
+		}
+		segs = append(segs, s.Bucket)
+	} else {
+		u = &url.URL{Scheme: "https", Host: s.Bucket + ".s3." + s.API.Region + ".amazonaws.com"}
+	}
+	if key != "" {
+		segs = append(segs, strings.Split(key, "/")...)
+	}
+	esc := make([]string, len(segs))
+	for i, p := range segs {
+		esc[i] = url.PathEscape(p)
+	}
+	u.Path = "/" + strings.Join(segs, "/")
+	u.RawPath = "/" + strings.Join(esc, "/")
+	return u, nil
+}
+
+// do sends one signed request and returns the response for a 2xx status.
+func (s *S3) do(ctx context.Context, method, key string, q url.Values, body io.Reader, size int64) (*http.Response, error) {
+	if s.API.Region == "" || s.API.Creds.AccessKey == "" || s.API.Creds.SecretKey == "" {
+		return nil, errors.New("AWS_REGION, AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set")
+	}
+	u, err := s.objectURL(key)
+	if err != nil {
+		return nil, err
+	}
+	u.RawQuery = q.Encode()
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	req.Header.Set("X-Amz-Content-Sha256", awsv4.UnsignedPayload)
+	now := time.Now
+	if s.API.Now != nil {
+		now = s.API.Now
+	}
+	awsv4.SignHash(req, awsv4.UnsignedPayload, s.API.Creds, s.API.Region, "s3", now())
+
+	cli := s.API.HTTP
+	if cli == nil {
+		cli = &http.Client{} // uploads can be long; ctx bounds them
+	}
+	resp, err := cli.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 == 2 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	var e struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	_ = xml.Unmarshal(raw, &e)
+	if e.Code == "" {
+		e.Code = http.StatusText(resp.StatusCode)
+	}
+	return nil, &awsv4.APIError{Status: resp.StatusCode, Type: e.Code, Message: e.Message}
+}
+
+// Put implements Store.
+func (s *S3) Put(ctx context.Context, key string, body io.ReadSeeker, size int64) error {
+	resp, err := s.do(ctx, http.MethodPut, key, nil, body, size)
+	if err != nil {
+		return fmt.Errorf("s3 put %s: %w", key, err)
+	}
+	return resp.Body.Close()
+}
+
+// Get implements Store.
+func (s *S3) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil, nil, 0)
+	var apiErr *awsv4.APIError
+	if errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound {
+		return nil, fmt.Errorf("%s: %w", key, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("s3 get %s: %w", key, err)
+	}
+	return resp.Body, nil
+}
+
+// List implements Store with ListObjectsV2, following continuation tokens.
+func (s *S3) List(ctx context.Context, prefix string) ([]Object, error) {
+	full := prefix
+	if s.Prefix != "" {
+		full = s.Prefix + "/" + prefix
+	}
+	var out []Object
+	token := ""
+	for {
+		q := url.Values{"list-type": {"2"}, "prefix": {full}}
+		if token != "" {
+			q.Set("continuation-token", token)
+		}
+		resp, err := s.do(ctx, http.MethodGet, "", q, nil, 0)
+		if err != nil {
+			return nil, fmt.Errorf("s3 list: %w", err)
+		}
+		var page struct {
+			Contents []struct {
+				Key  string `xml:"Key"`
+				Size int64  `xml:"Size"`
+			} `xml:"Contents"`
+			IsTruncated           bool   `xml:"IsTruncated"`
+			NextContinuationToken string `xml:"NextContinuationToken"`
+		}
+		err = xml.NewDecoder(resp.Body).Decode(&page)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("s3 list: %w", err)
+		}
+		for _, c := range page.Contents {
+			key := c.Key
+			if s.Prefix != "" {
+				key = strings.TrimPrefix(key, s.Prefix+"/")
+			}
+			out = append(out, Object{Key: key, Size: c.Size})
+		}
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			return out, nil
+		}
+		token = page.NextContinuationToken
+	}
+}
+
+// Delete implements Store.
+func (s *S3) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, nil, 0)
+	if err != nil {
+		return fmt.Errorf("s3 delete %s: %w", key, err)
+	}
+	return resp.Body.Close()
+}
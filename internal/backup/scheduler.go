@@ -0,0 +1,291 @@
+package backup
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// Snapshots are stored as <scope>/<UTC time>.gkbk, scope being "full" or
+// "user-<uuid>", so names sort by time within a scope.
+const (
+	scopeFull  = "full"
+	userScope  = "user-"
+	timeLayout = "20060102T150405Z"
+	ext        = ".gkbk"
+)
+
+// ObjectKey names the snapshot of userID (uuid.Nil = full) taken at t.
+func ObjectKey(userID uuid.UUID, t time.Time) string {
+	scope := scopeFull
+	if userID != uuid.Nil {
+		scope = userScope + userID.String()
+	}
+	return scope + "/" + t.UTC().Format(timeLayout) + ext
+}
+
+// parseObjectKey splits a snapshot key into its scope and time.
+func parseObjectKey(key string) (scope string, t time.Time, ok bool) {
+	scope, name := path.Split(key)
+	scope = strings.TrimSuffix(scope, "/")
+	if scope == "" || strings.Contains(scope, "/") || !strings.HasSuffix(name, ext) {
+		return "", time.Time{}, false
+	}
+	t, err := time.Parse(timeLayout, strings.TrimSuffix(name, ext))
+	return scope, t, err == nil
+}
+
+// metrics are published under the "backup" expvar (see /debug/vars).
+var (
+	metrics     = expvar.NewMap("backup")
+	lastSuccess atomic.Int64 // unix seconds of the newest stored snapshot
+)
+
+func init() {
+	metrics.Set("age_seconds", expvar.Func(func() any {
+		t := lastSuccess.Load()
+		if t == 0 {
+			return -1
+		}
+		return time.Now().Unix() - t
+	}))
+}
+
+func markSuccess(t time.Time) {
+	if u := t.Unix(); u > lastSuccess.Load() {
+		lastSuccess.Store(u)
+		v := new(expvar.Int)
+		v.Set(u)
+		metrics.Set("last_success_unix", v)
+	}
+}
+
+// Take dumps one scope, seals it with key and stores it. The sealed
+// snapshot is spooled to a temporary file first because object stores want
+// the length up front.
+func Take(ctx context.Context, db Beginner, store Store, key []byte, userID uuid.UUID, now time.Time) (Object, Stats, error) {
+	obj := Object{Key: ObjectKey(userID, now)}
+	f, err := os.CreateTemp("", "gk-backup-*")
+	if err != nil {
+		return obj, Stats{}, err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	sealed, err := NewWriter(f, key)
+	if err != nil {
+		return obj, Stats{}, err
+	}
+	gz := gzip.NewWriter(sealed)
+	st, err := Dump(ctx, db, userID, now, gz)
+	if err != nil {
+		return obj, st, err
+	}
+	if err := gz.Close(); err != nil {
+		return obj, st, err
+	}
+	if err := sealed.Close(); err != nil {
+		return obj, st, err
+	}
+	if obj.Size, err = f.Seek(0, io.SeekEnd); err != nil {
+		return obj, st, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return obj, st, err
+	}
+	return obj, st, store.Put(ctx, obj.Key, f, obj.Size)
+}
+
+// OpenSnapshot returns the plaintext snapshot stream of a sealed object.
+func OpenSnapshot(r io.Reader, key []byte) (io.Reader, error) {
+	plain, err := NewReader(r, key)
+	if err != nil {
+		return nil, err
+	}
+	gz, err := gzip.NewReader(plain)
+	if errors.Is(err, ErrCorrupt) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFormat, err)
+	}
+	return gz, nil
+}
+
+// Prune applies retention to every scope in store: snapshots beyond the
+// keep newest, or older than maxAge, are deleted. Zero disables either
+// rule; the newest snapshot of a scope is never deleted.
+func Prune(ctx context.Context, store Store, keep int, maxAge time.Duration, now time.Time) ([]string, error) {
+	objs, err := store.List(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	type snap struct {
+		key string
+		t   time.Time
+	}
+	scopes := map[string][]snap{}
+	for _, o := range objs {
+		if scope, t, ok := parseObjectKey(o.Key); ok {
+			scopes[scope] = append(scopes[scope], snap{o.Key, t})
+		}
+	}
+	var deleted []string
+	var errs []error
+	for _, snaps := range scopes {
+		sort.Slice(snaps, func(i, j int) bool { return snaps[i].t.After(snaps[j].t) })
+		for i, s := range snaps[1:] {
+			if (keep > 0 && i+1 >= keep) || (maxAge > 0 && now.Sub(s.t) > maxAge) {
+				if err := store.Delete(ctx, s.key); err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				deleted = append(deleted, s.key)
+			}
+		}
+	}
+	sort.Strings(deleted)
+	return deleted, errors.Join(errs...)
+}
+
+// Latest returns the time of the newest snapshot in store, zero if none.
+func Latest(ctx context.Context, store Store) (time.Time, error) {
+	objs, err := store.List(ctx, "")
+	if err != nil {
+		return time.Time{}, err
+	}
+	var latest time.Time
+	for _, o := range objs {
+		if _, t, ok := parseObjectKey(o.Key); ok && t.After(latest) {
+			latest = t
+		}
+	}
+	return latest, nil
+}
+
+// userIDs lists every user.
+func userIDs(ctx context.Context, db Beginner) ([]uuid.UUID, error) {
+	tx, err := db.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	var ids []uuid.UUID
+	err = each(ctx, tx, `SELECT id FROM users ORDER BY id`, nil, func(rows pgx.Rows) error {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+		ids = append(ids, id)
+		return nil
+	})
+	return ids, err
+}
+
+// Scheduler takes snapshots every Interval and applies retention.
+type Scheduler struct {
+	DB       Beginner
+	Store    Store
+	Key      []byte
+	Interval time.Duration
+	PerUser  bool // one snapshot per user instead of one of the whole database
+	Keep     int
+	MaxAge   time.Duration
+	Logger   *zap.Logger
+	Now      func() time.Time
+}
+
+func (s *Scheduler) now() time.Time {
+	if s.Now != nil {
+		return s.Now()
+	}
+	return time.Now()
+}
+
+// RunOnce takes one round of snapshots and prunes old ones.
+func (s *Scheduler) RunOnce(ctx context.Context) error {
+	now := s.now()
+	ids := []uuid.UUID{uuid.Nil}
+	if s.PerUser {
+		var err error
+		if ids, err = userIDs(ctx, s.DB); err != nil {
+			s.fail(err)
+			return fmt.Errorf("list users: %w", err)
+		}
+	}
+	var errs []error
+	var total int64
+	for _, id := range ids {
+		obj, st, err := Take(ctx, s.DB, s.Store, s.Key, id, now)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", obj.Key, err))
+			continue
+		}
+		total += obj.Size
+		s.Logger.Info("backup stored", zap.String("key", obj.Key), zap.Int64("bytes", obj.Size),
+			zap.Int("users", st.Users), zap.Int("items", st.Items), zap.Int("history", st.History))
+	}
+	if err := errors.Join(errs...); err != nil {
+		s.fail(err)
+		return err
+	}
+	metrics.Add("runs", 1)
+	lastBytes := new(expvar.Int)
+	lastBytes.Set(total)
+	metrics.Set("last_bytes", lastBytes)
+	markSuccess(now)
+
+	deleted, err := Prune(ctx, s.Store, s.Keep, s.MaxAge, now)
+	if len(deleted) > 0 {
+		s.Logger.Info("backup retention", zap.Strings("deleted", deleted))
+	}
+	if err != nil {
+		s.Logger.Warn("backup retention", zap.Error(err))
+	}
+	return nil
+}
+
+func (s *Scheduler) fail(err error) {
+	metrics.Add("failures", 1)
+	msg := new(expvar.String)
+	msg.Set(err.Error())
+	metrics.Set("last_error", msg)
+	s.Logger.Error("backup failed", zap.Error(err))
+}
+
+// Run takes snapshots until ctx is done. The first round is due one
+// Interval after the newest snapshot already in the store, so restarts do
+// not pile up extra backups.
+func (s *Scheduler) Run(ctx context.Context) {
+	next := s.now()
+	if latest, err := Latest(ctx, s.Store); err != nil {
+		s.Logger.Warn("backup store", zap.Error(err))
+	} else if !latest.IsZero() {
+		markSuccess(latest)
+		next = latest.Add(s.Interval)
+	}
+	timer := time.NewTimer(time.Until(next))
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+		_ = s.RunOnce(ctx) // logged and counted
+		timer.Reset(s.Interval)
+	}
+}
@@ -0,0 +1,151 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/and161185/goph-keeper/internal/awsv4"
+)
+
+// ErrNotFound is returned by Store.Get for a missing object.
+var ErrNotFound = errors.New("backup: object not found")
+
+// Object is a stored snapshot.
+type Object struct {
+	Key  string `json:"key"`
+	Size int64  `json:"size"`
+}
+
+// Store keeps snapshots under slash-separated keys.
+type Store interface {
+	// Put stores size bytes from body under key.
+	Put(ctx context.Context, key string, body io.ReadSeeker, size int64) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// List returns the objects whose key starts with prefix, sorted by key.
+	List(ctx context.Context, prefix string) ([]Object, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// Open returns the store for a URL: s3://bucket/prefix (AWS_* environment;
+// endpoint, if set, selects an S3-compatible service with path-style
+// requests) or file:///dir, or a plain directory path.
+func Open(rawURL, endpoint string) (Store, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("backup url: %w", err)
+	}
+	switch u.Scheme {
+	case "s3":
+		if u.Host == "" {
+			return nil, fmt.Errorf("backup url %q: no bucket", rawURL)
+		}
+		return &S3{
+			Bucket:   u.Host,
+			Prefix:   strings.Trim(u.Path, "/"),
+			Endpoint: endpoint,
+			API:      awsv4.FromEnvClient(),
+		}, nil
+	case "file":
+		return Dir(u.Path), nil
+	case "":
+		return Dir(rawURL), nil
+	}
+	return nil, fmt.Errorf("backup url %q: want s3:// or file://", rawURL)
+}
+
+// Dir is a Store on the local filesystem, for tests, NFS mounts and
+// storage gateways.
+type Dir string
+
+func (d Dir) path(key string) (string, error) {
+	if !fs.ValidPath(key) {
+		return "", fmt.Errorf("backup: bad key %q", key)
+	}
+	return filepath.Join(string(d), filepath.FromSlash(key)), nil
+}
+
+// Put implements Store. The object appears under its name only once complete.
+func (d Dir) Put(_ context.Context, key string, body io.ReadSeeker, _ int64) error {
+	p, err := d.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o700); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(p), ".put-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := io.Copy(f, body); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), p)
+}
+
+// Get implements Store.
+func (d Dir) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	p, err := d.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%s: %w", key, ErrNotFound)
+	}
+	return f, err
+}
+
+// List implements Store.
+func (d Dir) List(_ context.Context, prefix string) ([]Object, error) {
+	var out []Object
+	err := filepath.WalkDir(string(d), func(p string, e fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) && p == string(d) {
+			return fs.SkipAll
+		}
+		if err != nil || e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			return err
+		}
+		rel, err := filepath.Rel(string(d), p)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		info, err := e.Info()
+		if err != nil {
+			return err
+		}
+		out = append(out, Object{Key: key, Size: info.Size()})
+		return nil
+	})
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out, err
+}
+
+// Delete implements Store.
+func (d Dir) Delete(_ context.Context, key string) error {
+	p, err := d.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/and161185/goph-keeper/internal/awsv4"
+)
+
+// exerciseStore runs the Store contract against s.
+func exerciseStore(t *testing.T, s Store) {
+	t.Helper()
+	ctx := context.Background()
+	for _, k := range []string{"full/b.gkbk", "full/a.gkbk", "user-1/a.gkbk"} {
+		if err := s.Put(ctx, k, strings.NewReader("data of "+k), int64(len("data of "+k))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	objs, err := s.List(ctx, "full/")
+	if err != nil || len(objs) != 2 || objs[0].Key != "full/a.gkbk" || objs[0].Size != int64(len("data of full/a.gkbk")) {
+		t.Fatalf("list: %+v %v", objs, err)
+	}
+	r, err := s.Get(ctx, "user-1/a.gkbk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := io.ReadAll(r)
+	_ = r.Close()
+	if string(b) != "data of user-1/a.gkbk" {
+		t.Fatalf("get: %q", b)
+	}
+	if err := s.Delete(ctx, "full/a.gkbk"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get(ctx, "full/a.gkbk"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("deleted object: %v", err)
+	}
+	if objs, _ := s.List(ctx, ""); len(objs) != 2 {
+		t.Fatalf("after delete: %+v", objs)
+	}
+}
+
+func TestDir(t *testing.T) {
+	t.Parallel()
+	exerciseStore(t, Dir(t.TempDir()))
+	if objs, err := Dir(t.TempDir()+"/missing").List(context.Background(), ""); err != nil || len(objs) != 0 {
+		t.Fatalf("missing dir: %v %v", objs, err)
+	}
+	if err := Dir(t.TempDir()).Put(context.Background(), "../x", strings.NewReader(""), 0); err == nil {
+		t.Fatal("key escaped the directory")
+	}
+}
+
+// fakeS3 is a path-style S3 endpoint with one bucket, paging lists by two.
+type fakeS3 struct {
+	mu   sync.Mutex
+	objs map[string]string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AK/") ||
+		r.Header.Get("X-Amz-Content-Sha256") != awsv4.UnsignedPayload {
+		http.Error(w, "<Error><Code>AccessDenied</Code></Error>", http.StatusForbidden)
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key, ok := strings.CutPrefix(r.URL.Path, "/bkt/")
+	switch {
+	case r.Method == http.MethodPut && ok:
+		b, _ := io.ReadAll(r.Body)
+		f.objs[key] = string(b)
+	case r.Method == http.MethodGet && ok:
+		v, found := f.objs[key]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "<Error><Code>NoSuchKey</Code><Message>gone</Message></Error>")
+			return
+		}
+		fmt.Fprint(w, v)
+	case r.Method == http.MethodDelete && ok:
+		delete(f.objs, key)
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodGet && r.URL.Path == "/bkt":
+		var keys []string
+		for k := range f.objs {
+			if strings.HasPrefix(k, r.URL.Query().Get("prefix")) {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		start := 0
+		if tok := r.URL.Query().Get("continuation-token"); tok != "" {
+			fmt.Sscan(tok, &start)
+		}
+		end := min(start+2, len(keys))
+		fmt.Fprint(w, "<ListBucketResult>")
+		for _, k := range keys[start:end] {
+			fmt.Fprintf(w, "<Contents><Key>%s</Key><Size>%d</Size></Contents>", k, len(f.objs[k]))
+		}
+		if end < len(keys) {
+			fmt.Fprintf(w, "<IsTruncated>true</IsTruncated><NextContinuationToken>%d</NextContinuationToken>", end)
+		}
+		fmt.Fprint(w, "</ListBucketResult>")
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func TestS3(t *testing.T) {
+	t.Parallel()
+	fake := &fakeS3{objs: map[string]string{}}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+	api := &awsv4.Client{Region: "us-east-1", Creds: awsv4.Credentials{AccessKey: "AK", SecretKey: "SK"},
+		Now: func() time.Time { return time.Unix(0, 0) }}
+
+	exerciseStore(t, &S3{Bucket: "bkt", Prefix: "gk/prod", Endpoint: srv.URL, API: api})
+	if _, ok := fake.objs["gk/prod/user-1/a.gkbk"]; !ok {
+		t.Fatalf("prefix not applied: %v", fake.objs)
+	}
+
+	noCreds := &S3{Bucket: "bkt", Endpoint: srv.URL, API: &awsv4.Client{}}
+	if _, err := noCreds.List(context.Background(), ""); err == nil {
+		t.Fatal("missing credentials accepted")
+	}
+	bad := &S3{Bucket: "bkt", Endpoint: srv.URL, API: &awsv4.Client{Region: "r", Creds: awsv4.Credentials{AccessKey: "X", SecretKey: "Y"}}}
+	var apiErr *awsv4.APIError
+	if err := bad.Delete(context.Background(), "k"); !errors.As(err, &apiErr) || apiErr.Type != "AccessDenied" {
+		t.Fatalf("denied: %v", err)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	t.Parallel()
+	s, err := Open("s3://bkt/gk/prod/", "")
+	if s3, ok := s.(*S3); err != nil || !ok || s3.Bucket != "bkt" || s3.Prefix != "gk/prod" {
+		t.Fatalf("s3: %#v %v", s, err)
+	}
+	if s, err := Open("file:///var/backups/gk", ""); err != nil || s != Dir("/var/backups/gk") {
+		t.Fatalf("file: %#v %v", s, err)
+	}
+	if _, err := Open("ftp://x/y", ""); err == nil {
+		t.Fatal("ftp accepted")
+	}
+}
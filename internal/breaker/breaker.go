@@ -0,0 +1,225 @@
+// Package breaker implements a circuit breaker for database access. After
+// Threshold consecutive infrastructure failures it opens and fails calls
+// immediately with errs.ErrUnavailable; after Cooldown one probe call is let
+// through (half-open) and its outcome closes or reopens the circuit.
+package breaker
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/and161185/goph-keeper/internal/errs"
+)
+
+// State is the circuit state.
+type State int
+
+const (
+	Closed State = iota
+	Open
+	HalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("State(%d)", int(s))
+}
+
+// OpenError is returned while the circuit is open.
+type OpenError struct {
+	RetryAfter time.Duration
+}
+
+func (e *OpenError) Error() string {
+	return fmt.Sprintf("database unavailable, retry in %s", e.RetryAfter.Round(time.Second))
+}
+
+// Is makes errors.Is(err, errs.ErrUnavailable) hold.
+func (e *OpenError) Is(target error) bool { return target == errs.ErrUnavailable }
+
+// metrics are published under the "db_breaker" expvar (see /debug/vars).
+var metrics = expvar.NewMap("db_breaker")
+
+// Breaker is safe for concurrent use.
+type Breaker struct {
+	threshold int
+	cooldown  time.Duration
+	onChange  func(from, to State)
+	now       func() time.Time
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+	probing  bool
+	pending  [][2]State // transitions not yet reported to onChange
+
+	notifyMu sync.Mutex // serialises onChange calls
+}
+
+// New returns a closed breaker. onChange, if set, is called on every state
+// transition, in order and outside the breaker's lock.
+func New(threshold int, cooldown time.Duration, onChange func(from, to State)) *Breaker {
+	if threshold < 1 {
+		threshold = 1
+	}
+	metrics.Set("state", stateVar(Closed))
+	return &Breaker{threshold: threshold, cooldown: cooldown, onChange: onChange, now: time.Now}
+}
+
+// State returns the current state.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// allow decides whether a call may proceed; probe is set for the single half-open trial.
+func (b *Breaker) allow() (probe bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case Closed:
+		return false, nil
+	case Open:
+		wait := b.cooldown - b.now().Sub(b.openedAt)
+		if wait > 0 {
+			metrics.Add("rejected", 1)
+			return false, &OpenError{RetryAfter: wait}
+		}
+		b.setLocked(HalfOpen)
+		fallthrough
+	default: // HalfOpen
+		if b.probing {
+			metrics.Add("rejected", 1)
+			return false, &OpenError{RetryAfter: b.cooldown}
+		}
+		b.probing = true
+		return true, nil
+	}
+}
+
+// record accounts the outcome of an allowed call.
+func (b *Breaker) record(probe bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if probe {
+		b.probing = false
+		if errors.Is(err, context.Canceled) {
+			return // the caller gave up; the probe proved nothing
+		}
+	}
+	if !IsFailure(err) {
+		b.failures = 0
+		if b.state != Closed {
+			b.setLocked(Closed)
+		}
+		return
+	}
+	b.failures++
+	if b.state == HalfOpen || b.failures >= b.threshold {
+		if b.state != Open {
+			metrics.Add("trips", 1)
+		}
+		b.openedAt = b.now()
+		b.setLocked(Open)
+	}
+}
+
+// setLocked changes state and queues the transition for notify; b.mu must be held.
+func (b *Breaker) setLocked(to State) {
+	from := b.state
+	if from == to {
+		return
+	}
+	b.state = to
+	metrics.Set("state", stateVar(to))
+	if b.onChange != nil {
+		b.pending = append(b.pending, [2]State{from, to})
+	}
+}
+
+// notify reports queued transitions to onChange.
+func (b *Breaker) notify() {
+	if b.onChange == nil {
+		return
+	}
+	b.notifyMu.Lock()
+	defer b.notifyMu.Unlock()
+	b.mu.Lock()
+	pending := b.pending
+	b.pending = nil
+	b.mu.Unlock()
+	for _, t := range pending {
+		b.onChange(t[0], t[1])
+	}
+}
+
+// Do runs fn unless the circuit is open.
+func (b *Breaker) Do(fn func() error) error {
+	probe, err := b.allow()
+	if probe {
+		b.notify()
+	}
+	if err != nil {
+		return err
+	}
+	err = fn()
+	b.record(probe, err)
+	b.notify()
+	return err
+}
+
+// Call is Do for functions returning a value.
+func Call[T any](b *Breaker, fn func() (T, error)) (T, error) {
+	var out T
+	err := b.Do(func() error {
+		var err error
+		out, err = fn()
+		return err
+	})
+	return out, err
+}
+
+// IsFailure reports whether err means the database is unreachable or
+// misbehaving, as opposed to a normal outcome (no rows, conflicts, a
+// constraint the server reported) or the caller giving up.
+func IsFailure(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, pgx.ErrNoRows) {
+		return false
+	}
+	for _, domain := range []error{errs.ErrNotFound, errs.ErrVersionConflict, errs.ErrUnauthorized, errs.ErrRateLimited, errs.ErrAlreadyExists, errs.ErrQuotaExceeded, errs.ErrSnapshotGone} {
+		if errors.Is(err, domain) {
+			return false
+		}
+	}
+	var pg *pgconn.PgError
+	if errors.As(err, &pg) {
+		// class 08: connection exception, 53: insufficient resources,
+		// 57: operator intervention (shutdown, statement timeout), 58: system error
+		switch pg.Code[:2] {
+		case "08", "53", "57", "58":
+			return true
+		}
+		return false
+	}
+	return true
+}
+
+type stateVar State
+
+func (s stateVar) String() string { return `"` + State(s).String() + `"` }
@@ -0,0 +1,129 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/and161185/goph-keeper/internal/errs"
+)
+
+var errDown = errors.New("dial tcp: connection refused")
+
+func TestBreaker_TripProbeAndRecover(t *testing.T) {
+	t.Parallel()
+	var (
+		mu          sync.Mutex
+		transitions []string
+	)
+	done := make(chan struct{}, 8)
+	b := New(3, time.Minute, func(from, to State) {
+		mu.Lock()
+		transitions = append(transitions, from.String()+">"+to.String())
+		mu.Unlock()
+		done <- struct{}{}
+	})
+	now := time.Unix(1000, 0)
+	b.now = func() time.Time { return now }
+
+	calls := 0
+	fail := func() error { calls++; return errDown }
+	for range 3 {
+		if err := b.Do(fail); !errors.Is(err, errDown) {
+			t.Fatalf("closed breaker must pass the error through: %v", err)
+		}
+	}
+	if b.State() != Open {
+		t.Fatalf("state %s after threshold", b.State())
+	}
+
+	err := b.Do(fail)
+	var oe *OpenError
+	if !errors.As(err, &oe) || !errors.Is(err, errs.ErrUnavailable) || calls != 3 {
+		t.Fatalf("open breaker must fail fast: %v (calls %d)", err, calls)
+	}
+	if oe.RetryAfter != time.Minute {
+		t.Fatalf("retry after %s", oe.RetryAfter)
+	}
+
+	// after the cooldown a failing probe reopens immediately
+	now = now.Add(time.Minute)
+	if err := b.Do(fail); !errors.Is(err, errDown) || b.State() != Open {
+		t.Fatalf("failed probe: %v, state %s", err, b.State())
+	}
+
+	// a successful probe closes the circuit
+	now = now.Add(time.Minute)
+	if err := b.Do(func() error { return nil }); err != nil || b.State() != Closed {
+		t.Fatalf("probe: %v, state %s", err, b.State())
+	}
+
+	for range 5 {
+		<-done
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(transitions) != 5 || transitions[0] != "closed>open" || transitions[4] != "half-open>closed" {
+		t.Fatalf("transitions %v", transitions)
+	}
+}
+
+func TestBreaker_SingleProbe(t *testing.T) {
+	t.Parallel()
+	b := New(1, time.Second, nil)
+	now := time.Unix(0, 0)
+	b.now = func() time.Time { return now }
+	_ = b.Do(func() error { return errDown })
+	now = now.Add(time.Second)
+
+	entered, release := make(chan struct{}), make(chan struct{})
+	go func() {
+		_ = b.Do(func() error { close(entered); <-release; return nil })
+	}()
+	<-entered
+	if err := b.Do(func() error { return nil }); !errors.Is(err, errs.ErrUnavailable) {
+		t.Fatalf("second call during the probe: %v", err)
+	}
+	close(release)
+}
+
+func TestBreaker_NormalOutcomesDoNotCount(t *testing.T) {
+	t.Parallel()
+	b := New(1, time.Minute, nil)
+	for _, err := range []error{
+		pgx.ErrNoRows,
+		errs.ErrNotFound,
+		fmt.Errorf("upsert: %w", errs.ErrVersionConflict),
+		context.Canceled,
+		&pgconn.PgError{Code: "23505"}, // unique_violation
+	} {
+		if got := b.Do(func() error { return err }); !errors.Is(got, err) {
+			t.Fatalf("%v: got %v", err, got)
+		}
+	}
+	if b.State() != Closed {
+		t.Fatalf("state %s", b.State())
+	}
+	_ = b.Do(func() error { return &pgconn.PgError{Code: "57P01"} }) // admin_shutdown
+	if b.State() != Open {
+		t.Fatalf("admin_shutdown must trip, state %s", b.State())
+	}
+}
+
+func TestCall(t *testing.T) {
+	t.Parallel()
+	b := New(1, time.Minute, nil)
+	if v, err := Call(b, func() (int, error) { return 7, nil }); v != 7 || err != nil {
+		t.Fatalf("got %d, %v", v, err)
+	}
+	_, _ = Call(b, func() (int, error) { return 0, errDown })
+	if v, err := Call(b, func() (int, error) { return 7, nil }); v != 0 || !errors.Is(err, errs.ErrUnavailable) {
+		t.Fatalf("open: %d, %v", v, err)
+	}
+}
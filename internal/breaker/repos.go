@@ -0,0 +1,252 @@
+package breaker
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+
+	"github.com/and161185/goph-keeper/internal/limiter"
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/and161185/goph-keeper/internal/repository"
+)
+
+// ItemRepo guards a repository.ItemRepository with a breaker.
+type ItemRepo struct {
+	b    *Breaker
+	next repository.ItemRepository
+}
+
+// NewItemRepo wraps next.
+func NewItemRepo(b *Breaker, next repository.ItemRepository) *ItemRepo {
+	return &ItemRepo{b: b, next: next}
+}
+
+// UpsertBatch implements repository.ItemRepository.
+func (r *ItemRepo) UpsertBatch(ctx context.Context, userID uuid.UUID, items []model.UpsertItem) ([]model.ItemVersion, error) {
+	return Call(r.b, func() ([]model.ItemVersion, error) { return r.next.UpsertBatch(ctx, userID, items) })
+}
+
+// UpsertEach implements repository.ItemRepository.
+func (r *ItemRepo) UpsertEach(ctx context.Context, userID uuid.UUID, ups []model.UpsertItem) ([]model.ItemResult, error) {
+	return Call(r.b, func() ([]model.ItemResult, error) { return r.next.UpsertEach(ctx, userID, ups) })
+}
+
+// Delete implements repository.ItemRepository.
+func (r *ItemRepo) Delete(ctx context.Context, userID, itemID uuid.UUID, baseVer int64) (model.ItemVersion, error) {
+	return Call(r.b, func() (model.ItemVersion, error) { return r.next.Delete(ctx, userID, itemID, baseVer) })
+}
+
+// DeleteBatch implements repository.ItemRepository.
+func (r *ItemRepo) DeleteBatch(ctx context.Context, userID uuid.UUID, refs []model.ItemRef) ([]model.ItemResult, error) {
+	return Call(r.b, func() ([]model.ItemResult, error) { return r.next.DeleteBatch(ctx, userID, refs) })
+}
+
+// ApplyChangeSet implements repository.ItemRepository.
+func (r *ItemRepo) ApplyChangeSet(ctx context.Context, userID uuid.UUID, cs model.ChangeSet) (model.ChangeSetResult, error) {
+	return Call(r.b, func() (model.ChangeSetResult, error) { return r.next.ApplyChangeSet(ctx, userID, cs) })
+}
+
+// GetChangesSince implements repository.ItemRepository.
+func (r *ItemRepo) GetChangesSince(ctx context.Context, userID uuid.UUID, after model.ChangeCursor, limit int) ([]model.Change, error) {
+	return Call(r.b, func() ([]model.Change, error) { return r.next.GetChangesSince(ctx, userID, after, limit) })
+}
+
+// GetJournalSince implements repository.ItemRepository.
+func (r *ItemRepo) GetJournalSince(ctx context.Context, userID uuid.UUID, afterSeq int64, limit int) ([]model.Change, error) {
+	return Call(r.b, func() ([]model.Change, error) { return r.next.GetJournalSince(ctx, userID, afterSeq, limit) })
+}
+
+// JournalHead implements repository.ItemRepository.
+func (r *ItemRepo) JournalHead(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return Call(r.b, func() (int64, error) { return r.next.JournalHead(ctx, userID) })
+}
+
+// GetSnapshot implements repository.ItemRepository.
+func (r *ItemRepo) GetSnapshot(ctx context.Context, userID uuid.UUID, atSeq int64, afterID uuid.UUID, limit int) ([]model.Change, error) {
+	return Call(r.b, func() ([]model.Change, error) { return r.next.GetSnapshot(ctx, userID, atSeq, afterID, limit) })
+}
+
+// GetItem implements repository.ItemRepository.
+func (r *ItemRepo) GetItem(ctx context.Context, userID, itemID uuid.UUID) (*model.Item, error) {
+	return Call(r.b, func() (*model.Item, error) { return r.next.GetItem(ctx, userID, itemID) })
+}
+
+// GetItemVersion implements repository.ItemRepository.
+func (r *ItemRepo) GetItemVersion(ctx context.Context, userID, itemID uuid.UUID, ver int64) (*model.Item, error) {
+	return Call(r.b, func() (*model.Item, error) { return r.next.GetItemVersion(ctx, userID, itemID, ver) })
+}
+
+// Stats implements repository.ItemRepository.
+func (r *ItemRepo) Stats(ctx context.Context, userID uuid.UUID) (model.ItemStats, error) {
+	return Call(r.b, func() (model.ItemStats, error) { return r.next.Stats(ctx, userID) })
+}
+
+// ExportItems implements repository.ItemRepository. An error of fn, such
+// as the client going away, is not the database failing.
+func (r *ItemRepo) ExportItems(ctx context.Context, userID uuid.UUID, fn func(model.Item) error) error {
+	var fnErr error
+	err := r.b.Do(func() error {
+		err := r.next.ExportItems(ctx, userID, func(it model.Item) error {
+			fnErr = fn(it)
+			return fnErr
+		})
+		if fnErr != nil {
+			return nil
+		}
+		return err
+	})
+	if fnErr != nil {
+		return fnErr
+	}
+	return err
+}
+
+// Digests implements repository.ItemRepository.
+func (r *ItemRepo) Digests(ctx context.Context, userID uuid.UUID) ([]model.ItemDigest, error) {
+	return Call(r.b, func() ([]model.ItemDigest, error) { return r.next.Digests(ctx, userID) })
+}
+
+// GetMaxVersion implements repository.ItemRepository.
+func (r *ItemRepo) GetMaxVersion(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return Call(r.b, func() (int64, error) { return r.next.GetMaxVersion(ctx, userID) })
+}
+
+// UserRepo guards a repository.UserRepository with a breaker.
+type UserRepo struct {
+	b    *Breaker
+	next repository.UserRepository
+}
+
+// NewUserRepo wraps next.
+func NewUserRepo(b *Breaker, next repository.UserRepository) *UserRepo {
+	return &UserRepo{b: b, next: next}
+}
+
+// Create implements repository.UserRepository.
+func (r *UserRepo) Create(ctx context.Context, u *model.User) error {
+	return r.b.Do(func() error { return r.next.Create(ctx, u) })
+}
+
+// GetByID implements repository.UserRepository.
+func (r *UserRepo) GetByID(ctx context.Context, id uuid.UUID) (*model.User, error) {
+	return Call(r.b, func() (*model.User, error) { return r.next.GetByID(ctx, id) })
+}
+
+// GetByUsername implements repository.UserRepository.
+func (r *UserRepo) GetByUsername(ctx context.Context, username string) (*model.User, error) {
+	return Call(r.b, func() (*model.User, error) { return r.next.GetByUsername(ctx, username) })
+}
+
+// SetWrappedDEKIfEmpty implements repository.UserRepository.
+func (r *UserRepo) SetWrappedDEKIfEmpty(ctx context.Context, id uuid.UUID, wrapped []byte) error {
+	return r.b.Do(func() error { return r.next.SetWrappedDEKIfEmpty(ctx, id, wrapped) })
+}
+
+// ReplaceWrappedDEK implements repository.UserRepository.
+func (r *UserRepo) ReplaceWrappedDEK(ctx context.Context, id uuid.UUID, prev, wrapped []byte) error {
+	return r.b.Do(func() error { return r.next.ReplaceWrappedDEK(ctx, id, prev, wrapped) })
+}
+
+// RecordLogin implements repository.UserRepository.
+func (r *UserRepo) RecordLogin(ctx context.Context, id uuid.UUID, a model.LoginAttempt, keep int) error {
+	return r.b.Do(func() error { return r.next.RecordLogin(ctx, id, a, keep) })
+}
+
+// LoginHistory implements repository.UserRepository.
+func (r *UserRepo) LoginHistory(ctx context.Context, id uuid.UUID, limit int) ([]model.LoginAttempt, error) {
+	return Call(r.b, func() ([]model.LoginAttempt, error) { return r.next.LoginHistory(ctx, id, limit) })
+}
+
+// CreateDuress implements repository.UserRepository.
+func (r *UserRepo) CreateDuress(ctx context.Context, id uuid.UUID, vault *model.User) error {
+	return r.b.Do(func() error { return r.next.CreateDuress(ctx, id, vault) })
+}
+
+// GetDuress implements repository.UserRepository.
+func (r *UserRepo) GetDuress(ctx context.Context, id uuid.UUID) (*model.User, error) {
+	return Call(r.b, func() (*model.User, error) { return r.next.GetDuress(ctx, id) })
+}
+
+// DeleteDuress implements repository.UserRepository.
+func (r *UserRepo) DeleteDuress(ctx context.Context, id uuid.UUID) error {
+	return r.b.Do(func() error { return r.next.DeleteDuress(ctx, id) })
+}
+
+// Freeze implements repository.UserRepository.
+func (r *UserRepo) Freeze(ctx context.Context, id uuid.UUID, at time.Time) (time.Time, error) {
+	return Call(r.b, func() (time.Time, error) { return r.next.Freeze(ctx, id, at) })
+}
+
+// Unfreeze implements repository.UserRepository.
+func (r *UserRepo) Unfreeze(ctx context.Context, id uuid.UUID) error {
+	return r.b.Do(func() error { return r.next.Unfreeze(ctx, id) })
+}
+
+// FrozenAt implements repository.UserRepository.
+func (r *UserRepo) FrozenAt(ctx context.Context, id uuid.UUID) (time.Time, error) {
+	return Call(r.b, func() (time.Time, error) { return r.next.FrozenAt(ctx, id) })
+}
+
+// SetDisabled implements repository.UserRepository.
+func (r *UserRepo) SetDisabled(ctx context.Context, id uuid.UUID, disabled bool) error {
+	return r.b.Do(func() error { return r.next.SetDisabled(ctx, id, disabled) })
+}
+
+// Disabled implements repository.UserRepository.
+func (r *UserRepo) Disabled(ctx context.Context, id uuid.UUID) (bool, error) {
+	return Call(r.b, func() (bool, error) { return r.next.Disabled(ctx, id) })
+}
+
+// Limiter guards the database-backed login limiter, which is the first
+// query of every login.
+type Limiter struct {
+	b    *Breaker
+	next limiter.Limiter
+}
+
+// NewLimiter wraps next.
+func NewLimiter(b *Breaker, next limiter.Limiter) *Limiter {
+	return &Limiter{b: b, next: next}
+}
+
+// Allow implements limiter.Limiter.
+func (l *Limiter) Allow(ctx context.Context, username string, ipHash []byte) (bool, time.Duration, error) {
+	var ok bool
+	var wait time.Duration
+	err := l.b.Do(func() error {
+		var err error
+		ok, wait, err = l.next.Allow(ctx, username, ipHash)
+		return err
+	})
+	return ok, wait, err
+}
+
+// Success implements limiter.Limiter.
+func (l *Limiter) Success(ctx context.Context, username string, ipHash []byte) (int, error) {
+	var n int
+	err := l.b.Do(func() error {
+		var err error
+		n, err = l.next.Success(ctx, username, ipHash)
+		return err
+	})
+	return n, err
+}
+
+// Failure implements limiter.Limiter.
+func (l *Limiter) Failure(ctx context.Context, username string, ipHash []byte) (bool, time.Duration, error) {
+	var blocked bool
+	var wait time.Duration
+	err := l.b.Do(func() error {
+		var err error
+		blocked, wait, err = l.next.Failure(ctx, username, ipHash)
+		return err
+	})
+	return blocked, wait, err
+}
+
+var (
+	_ repository.ItemRepository = (*ItemRepo)(nil)
+	_ repository.UserRepository = (*UserRepo)(nil)
+	_ limiter.Limiter           = (*Limiter)(nil)
+)
@@ -0,0 +1,84 @@
+package challenge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Siteverify endpoints of the supported CAPTCHA providers.
+const (
+	HCaptchaVerifyURL  = "https://api.hcaptcha.com/siteverify"
+	TurnstileVerifyURL = "https://challenges.cloudflare.com/turnstile/v0/siteverify"
+)
+
+// Captcha checks CAPTCHA responses with the provider's siteverify API,
+// which hCaptcha and Turnstile share. Clients solve the CAPTCHA in a
+// browser with SiteKey and send back the response token.
+type Captcha struct {
+	Kind     string // KindHCaptcha or KindTurnstile
+	SiteKey  string
+	Secret   string
+	Endpoint string
+	HTTP     *http.Client
+}
+
+// NewHCaptcha checks hCaptcha responses.
+func NewHCaptcha(siteKey, secret string, cli *http.Client) *Captcha {
+	return &Captcha{Kind: KindHCaptcha, SiteKey: siteKey, Secret: secret, Endpoint: HCaptchaVerifyURL, HTTP: cli}
+}
+
+// NewTurnstile checks Cloudflare Turnstile responses.
+func NewTurnstile(siteKey, secret string, cli *http.Client) *Captcha {
+	return &Captcha{Kind: KindTurnstile, SiteKey: siteKey, Secret: secret, Endpoint: TurnstileVerifyURL, HTTP: cli}
+}
+
+// Issue names the CAPTCHA to solve; the provider makes the puzzle.
+func (c *Captcha) Issue() (Challenge, error) {
+	return Challenge{Kind: c.Kind, SiteKey: c.SiteKey}, nil
+}
+
+// Verify asks the provider whether answer is a valid response token.
+func (c *Captcha) Verify(ctx context.Context, answer, remoteIP string) error {
+	form := url.Values{"secret": {c.Secret}, "response": {answer}, "sitekey": {c.SiteKey}}
+	if host, _, err := net.SplitHostPort(remoteIP); err == nil {
+		remoteIP = host
+	}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.Endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s siteverify: %w", c.Kind, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s siteverify: %s", c.Kind, resp.Status)
+	}
+	var out struct {
+		Success bool     `json:"success"`
+		Codes   []string `json:"error-codes"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&out); err != nil {
+		return fmt.Errorf("%s siteverify: %w", c.Kind, err)
+	}
+	if !out.Success {
+		for _, code := range out.Codes {
+			if strings.HasSuffix(code, "-secret") { // the server's fault, not the client's
+				return fmt.Errorf("%s siteverify: %s", c.Kind, code)
+			}
+		}
+		return fmt.Errorf("%w: %s", ErrFailed, strings.Join(out.Codes, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+// Package challenge makes clients prove they are not a script before the
+// server does expensive or abusable work for them: a CAPTCHA solved in a
+// browser (hCaptcha, Cloudflare Turnstile) or a proof of work the client
+// computes itself.
+package challenge
+
+import (
+	"context"
+	"errors"
+)
+
+// Kinds of challenge, as sent to clients.
+const (
+	KindPoW       = "pow"
+	KindHCaptcha  = "hcaptcha"
+	KindTurnstile = "turnstile"
+)
+
+// ErrFailed means an answer is wrong, expired or already used.
+var ErrFailed = errors.New("challenge failed")
+
+// Challenge is what a client must answer. Token and Difficulty are set for
+// proof of work, SiteKey for CAPTCHAs.
+type Challenge struct {
+	Kind       string
+	Token      string
+	Difficulty int // leading zero bits
+	SiteKey    string
+}
+
+// Verifier issues challenges and checks answers. Verify returns ErrFailed
+// (possibly wrapped) for a bad answer and any other error when it could not
+// tell, such as a CAPTCHA provider being down.
+type Verifier interface {
+	Issue() (Challenge, error)
+	Verify(ctx context.Context, answer, remoteIP string) error
+}
@@ -0,0 +1,117 @@
+package challenge
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPoW(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	p, err := NewPoW(8, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := p.Issue()
+	if err != nil || c.Kind != KindPoW || c.Difficulty != 8 || c.Token == "" {
+		t.Fatalf("issue: %+v %v", c, err)
+	}
+	answer := Solve(c.Token, c.Difficulty)
+	if err := p.Verify(ctx, answer, ""); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if err := p.Verify(ctx, answer, ""); !errors.Is(err, ErrFailed) {
+		t.Fatalf("replayed answer: %v", err)
+	}
+
+	c, _ = p.Issue()
+	for _, bad := range []string{"", c.Token, c.Token + ":", "AAAA:0", strings.ToUpper(Solve(c.Token, 8))} {
+		if err := p.Verify(ctx, bad, ""); !errors.Is(err, ErrFailed) {
+			t.Fatalf("%q: %v", bad, err)
+		}
+	}
+
+	// a token from another server (another key) is refused
+	other, _ := NewPoW(8, time.Minute)
+	oc, _ := other.Issue()
+	if err := p.Verify(ctx, Solve(oc.Token, 8), ""); !errors.Is(err, ErrFailed) {
+		t.Fatalf("foreign token: %v", err)
+	}
+
+	c, _ = p.Issue()
+	answer = Solve(c.Token, 8)
+	p.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	if err := p.Verify(ctx, answer, ""); !errors.Is(err, ErrFailed) {
+		t.Fatalf("expired token: %v", err)
+	}
+}
+
+func TestPoW_NotEnoughWork(t *testing.T) {
+	t.Parallel()
+	p, _ := NewPoW(20, time.Minute)
+	c, _ := p.Issue()
+	// an answer with 8 zero bits is very unlikely to have 20
+	answer := Solve(c.Token, 8)
+	if leadingZeros(sha256.Sum256([]byte(answer))) >= 20 {
+		t.Skip("lucky answer")
+	}
+	if err := p.Verify(context.Background(), answer, ""); !errors.Is(err, ErrFailed) {
+		t.Fatalf("weak answer: %v", err)
+	}
+	if _, err := NewPoW(0, time.Minute); err == nil {
+		t.Fatal("difficulty 0 accepted")
+	}
+}
+
+func TestCaptcha(t *testing.T) {
+	t.Parallel()
+	var got map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		got = map[string]string{}
+		for k := range r.PostForm {
+			got[k] = r.PostForm.Get(k)
+		}
+		switch r.PostForm.Get("response") {
+		case "good":
+			_, _ = w.Write([]byte(`{"success":true}`))
+		case "misconfigured":
+			_, _ = w.Write([]byte(`{"success":false,"error-codes":["invalid-input-secret"]}`))
+		case "down":
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			_, _ = w.Write([]byte(`{"success":false,"error-codes":["invalid-input-response"]}`))
+		}
+	}))
+	defer srv.Close()
+
+	c := NewTurnstile("site", "shh", srv.Client())
+	c.Endpoint = srv.URL
+	if ch, _ := c.Issue(); ch.Kind != KindTurnstile || ch.SiteKey != "site" {
+		t.Fatalf("issue: %+v", ch)
+	}
+	ctx := context.Background()
+	if err := c.Verify(ctx, "good", "203.0.113.7:5000"); err != nil {
+		t.Fatalf("good: %v", err)
+	}
+	if got["secret"] != "shh" || got["remoteip"] != "203.0.113.7" {
+		t.Fatalf("form: %v", got)
+	}
+	if err := c.Verify(ctx, "bad", ""); !errors.Is(err, ErrFailed) {
+		t.Fatalf("bad: %v", err)
+	}
+	if _, ok := got["remoteip"]; ok {
+		t.Fatal("sent an empty remoteip")
+	}
+	for _, answer := range []string{"misconfigured", "down"} {
+		if err := c.Verify(ctx, answer, ""); err == nil || errors.Is(err, ErrFailed) {
+			t.Fatalf("%s: %v", answer, err)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package challenge
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A proof-of-work token is base64url(expiry || nonce || mac): the expiry in
+// Unix seconds, 16 random bytes and the first 16 bytes of HMAC-SHA256 over
+// both. The server keeps no state until a token is redeemed.
+const (
+	powNonceLen = 16
+	powMACLen   = 16
+	powTokenLen = 8 + powNonceLen + powMACLen
+)
+
+// PoW is a hashcash-style challenge: the answer is token + ":" + suffix
+// such that SHA-256 of it starts with Difficulty zero bits. Each token is
+// accepted once.
+type PoW struct {
+	key  []byte
+	bits int
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu   sync.Mutex
+	used map[string]time.Time // redeemed tokens until they expire
+}
+
+// NewPoW issues tokens valid for ttl that need bits zero bits; each 1 more
+// doubles the client's work (20 takes about a second). Tokens are signed
+// with a key made at startup, so a restart invalidates outstanding ones.
+func NewPoW(bits int, ttl time.Duration) (*PoW, error) {
+	if bits < 1 || bits > 32 {
+		return nil, fmt.Errorf("challenge: proof-of-work difficulty %d outside 1..32", bits)
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return &PoW{key: key, bits: bits, ttl: ttl, now: time.Now, used: make(map[string]time.Time)}, nil
+}
+
+// Issue returns a fresh token.
+func (p *PoW) Issue() (Challenge, error) {
+	buf := make([]byte, powTokenLen)
+	binary.BigEndian.PutUint64(buf, uint64(p.now().Add(p.ttl).Unix()))
+	if _, err := rand.Read(buf[8 : 8+powNonceLen]); err != nil {
+		return Challenge{}, err
+	}
+	copy(buf[8+powNonceLen:], p.mac(buf[:8+powNonceLen]))
+	return Challenge{Kind: KindPoW, Token: base64.RawURLEncoding.EncodeToString(buf), Difficulty: p.bits}, nil
+}
+
+// Verify checks the token's signature and expiry, the work, and that the
+// token was not redeemed before.
+func (p *PoW) Verify(_ context.Context, answer, _ string) error {
+	token, _, ok := strings.Cut(answer, ":")
+	if !ok {
+		return fmt.Errorf("%w: malformed answer", ErrFailed)
+	}
+	buf, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(buf) != powTokenLen || !hmac.Equal(buf[8+powNonceLen:], p.mac(buf[:8+powNonceLen])) {
+		return fmt.Errorf("%w: bad token", ErrFailed)
+	}
+	now := p.now()
+	expires := time.Unix(int64(binary.BigEndian.Uint64(buf)), 0)
+	if !now.Before(expires) {
+		return fmt.Errorf("%w: token expired", ErrFailed)
+	}
+	if leadingZeros(sha256.Sum256([]byte(answer))) < p.bits {
+		return fmt.Errorf("%w: not enough work", ErrFailed)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, dup := p.used[token]; dup {
+		return fmt.Errorf("%w: token already used", ErrFailed)
+	}
+	for t, exp := range p.used {
+		if !now.Before(exp) {
+			delete(p.used, t)
+		}
+	}
+	p.used[token] = expires
+	return nil
+}
+
+func (p *PoW) mac(b []byte) []byte {
+	m := hmac.New(sha256.New, p.key)
+	m.Write(b)
+	return m.Sum(nil)[:powMACLen]
+}
+
+// Solve finds an answer to a proof-of-work challenge. It takes about
+// 2^difficulty hashes.
+func Solve(token string, difficulty int) string {
+	for n := uint64(0); ; n++ {
+		answer := token + ":" + strconv.FormatUint(n, 36)
+		if leadingZeros(sha256.Sum256([]byte(answer))) >= difficulty {
+			return answer
+		}
+	}
+}
+
+func leadingZeros(sum [sha256.Size]byte) int {
+	n := 0
+	for _, b := range sum {
+		if b != 0 {
+			return n + bits.LeadingZeros8(b)
+		}
+		n += 8
+	}
+	return n
+}
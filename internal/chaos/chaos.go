@@ -0,0 +1,150 @@
+// Package chaos wraps repositories to inject faults: added latency,
+// serialization failures and dropped connections. It exists so tests can
+// push retry, circuit-breaker and conflict handling through the failures a
+// loaded database produces. The server only offers it in builds with the
+// chaos tag (see -chaos in cmd/server).
+package chaos
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrDropped is what a call gets when its connection is dropped. It is no
+// PgError, so the breaker counts it as an infrastructure failure.
+var ErrDropped = errors.New("chaos: connection dropped")
+
+// serializationFailure builds the error PostgreSQL returns when a
+// serializable transaction loses to a concurrent one.
+func serializationFailure() error {
+	return &pgconn.PgError{Severity: "ERROR", Code: "40001", Message: "could not serialize access due to concurrent update (injected)"}
+}
+
+// metrics are published under the "chaos" expvar: the faults injected so far.
+var metrics = expvar.NewMap("chaos")
+
+// Faults says what to inject. The zero value injects nothing.
+type Faults struct {
+	// Latency delays every call by a random time up to it.
+	Latency time.Duration
+	// Serialization is the share of writes failing with SQLSTATE 40001.
+	Serialization float64
+	// Drop is the share of calls whose connection drops. Half of them drop
+	// after the call ran, so a write may be stored although it failed.
+	Drop float64
+	// Seed makes the faults repeatable; 0 picks a random one.
+	Seed uint64
+}
+
+// Parse reads "latency=50ms,serialization=0.05,drop=0.01,seed=1"; keys left
+// out are zero.
+func Parse(s string) (Faults, error) {
+	var f Faults
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(part, "=")
+		if !ok {
+			return f, fmt.Errorf("chaos: %q is not key=value", part)
+		}
+		var err error
+		switch k {
+		case "latency":
+			f.Latency, err = time.ParseDuration(v)
+		case "serialization":
+			f.Serialization, err = parseShare(v)
+		case "drop":
+			f.Drop, err = parseShare(v)
+		case "seed":
+			f.Seed, err = strconv.ParseUint(v, 10, 64)
+		default:
+			return f, fmt.Errorf("chaos: unknown key %q", k)
+		}
+		if err != nil {
+			return f, fmt.Errorf("chaos: %s: %w", k, err)
+		}
+	}
+	return f, nil
+}
+
+func parseShare(v string) (float64, error) {
+	p, err := strconv.ParseFloat(v, 64)
+	if err == nil && (p < 0 || p > 1) {
+		err = errors.New("not between 0 and 1")
+	}
+	return p, err
+}
+
+// Injector decides which calls fail. It is safe for concurrent use.
+type Injector struct {
+	f   Faults
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+// New returns an injector for f.
+func New(f Faults) *Injector {
+	seed := f.Seed
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+	return &Injector{f: f, rnd: rand.New(rand.NewPCG(seed, seed))}
+}
+
+func (in *Injector) float() float64 {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	return in.rnd.Float64()
+}
+
+// do runs fn with the faults applied; write marks calls that change data,
+// the only ones that can fail to serialize.
+func (in *Injector) do(ctx context.Context, write bool, fn func() error) error {
+	if in.f.Latency > 0 {
+		t := time.NewTimer(time.Duration(in.float() * float64(in.f.Latency)))
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		}
+	}
+	if write && in.float() < in.f.Serialization {
+		metrics.Add("serialization", 1)
+		return serializationFailure()
+	}
+	if in.float() < in.f.Drop {
+		metrics.Add("drop", 1)
+		if in.float() < 0.5 {
+			_ = fn()
+		}
+		return ErrDropped
+	}
+	return fn()
+}
+
+// call is do for calls returning a value.
+func call[T any](ctx context.Context, in *Injector, write bool, fn func() (T, error)) (T, error) {
+	var out T
+	err := in.do(ctx, write, func() error {
+		var err error
+		out, err = fn()
+		return err
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return out, nil
+}
@@ -0,0 +1,115 @@
+package chaos
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/and161185/goph-keeper/internal/breaker"
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/and161185/goph-keeper/internal/repository/memory"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+	f, err := Parse("latency=20ms, serialization=0.5,drop=0.1,seed=7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Faults{Latency: 20 * time.Millisecond, Serialization: 0.5, Drop: 0.1, Seed: 7}); f != want {
+		t.Fatalf("got %+v, want %+v", f, want)
+	}
+	if f, err := Parse(""); err != nil || f != (Faults{}) {
+		t.Fatalf("empty: %+v %v", f, err)
+	}
+	for _, bad := range []string{"latency", "latency=fast", "drop=2", "serialization=-0.1", "jitter=1ms"} {
+		if _, err := Parse(bad); err == nil {
+			t.Errorf("%q accepted", bad)
+		}
+	}
+}
+
+func upsertOne(id uuid.UUID, baseVer int64) []model.UpsertItem {
+	return []model.UpsertItem{{ID: id, BaseVer: baseVer, BlobEnc: []byte("x")}}
+}
+
+func TestItemRepo_Serialization(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := NewItemRepo(New(Faults{Serialization: 1}), memory.NewItemRepo())
+	user := uuid.Must(uuid.NewV4())
+
+	_, err := repo.UpsertBatch(ctx, user, upsertOne(uuid.Must(uuid.NewV4()), 0))
+	var pg *pgconn.PgError
+	if !errors.As(err, &pg) || pg.Code != "40001" {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := repo.GetMaxVersion(ctx, user); err != nil {
+		t.Fatalf("reads never fail to serialize: %v", err)
+	}
+}
+
+func TestItemRepo_DropMayApply(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := memory.NewItemRepo()
+	repo := NewItemRepo(New(Faults{Drop: 1, Seed: 1}), store)
+	user := uuid.Must(uuid.NewV4())
+
+	const n = 40
+	for range n {
+		if _, err := repo.UpsertBatch(ctx, user, upsertOne(uuid.Must(uuid.NewV4()), 0)); !errors.Is(err, ErrDropped) {
+			t.Fatalf("dropped call: %v", err)
+		}
+	}
+	// some writes landed although their callers saw a failure
+	st, err := store.Stats(ctx, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Items == 0 || st.Items == n {
+		t.Fatalf("%d of %d dropped writes stored", st.Items, n)
+	}
+}
+
+func TestInjector_LatencyHonoursContext(t *testing.T) {
+	t.Parallel()
+	in := New(Faults{Latency: time.Hour, Seed: 1})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	ran := false
+	if err := in.do(ctx, false, func() error { ran = true; return nil }); !errors.Is(err, context.DeadlineExceeded) || ran {
+		t.Fatalf("err %v, ran %v", err, ran)
+	}
+}
+
+func TestBreaker_SeesInjectedFaults(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	user := uuid.Must(uuid.NewV4())
+
+	// dropped connections open the breaker
+	brk := breaker.New(3, time.Minute, nil)
+	repo := breaker.NewItemRepo(brk, NewItemRepo(New(Faults{Drop: 1}), memory.NewItemRepo()))
+	for range 3 {
+		_, _ = repo.GetMaxVersion(ctx, user)
+	}
+	if _, err := repo.GetMaxVersion(ctx, user); !errors.Is(err, errs.ErrUnavailable) {
+		t.Fatalf("after 3 drops: %v", err)
+	}
+
+	// serialization failures are the database working, not failing
+	brk = breaker.New(3, time.Minute, nil)
+	repo = breaker.NewItemRepo(brk, NewItemRepo(New(Faults{Serialization: 1}), memory.NewItemRepo()))
+	for range 5 {
+		_, _ = repo.UpsertBatch(ctx, user, upsertOne(uuid.Must(uuid.NewV4()), 0))
+	}
+	if brk.State() != breaker.Closed {
+		t.Fatalf("breaker %s after serialization failures", brk.State())
+	}
+}
@@ -0,0 +1,190 @@
+package chaos
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/and161185/goph-keeper/internal/repository"
+)
+
+// ItemRepo injects faults into the calls of a repository.ItemRepository.
+type ItemRepo struct {
+	in   *Injector
+	next repository.ItemRepository
+}
+
+// NewItemRepo wraps next.
+func NewItemRepo(in *Injector, next repository.ItemRepository) *ItemRepo {
+	return &ItemRepo{in: in, next: next}
+}
+
+// UpsertBatch implements repository.ItemRepository.
+func (r *ItemRepo) UpsertBatch(ctx context.Context, userID uuid.UUID, items []model.UpsertItem) ([]model.ItemVersion, error) {
+	return call(ctx, r.in, true, func() ([]model.ItemVersion, error) { return r.next.UpsertBatch(ctx, userID, items) })
+}
+
+// UpsertEach implements repository.ItemRepository.
+func (r *ItemRepo) UpsertEach(ctx context.Context, userID uuid.UUID, ups []model.UpsertItem) ([]model.ItemResult, error) {
+	return call(ctx, r.in, true, func() ([]model.ItemResult, error) { return r.next.UpsertEach(ctx, userID, ups) })
+}
+
+// Delete implements repository.ItemRepository.
+func (r *ItemRepo) Delete(ctx context.Context, userID, itemID uuid.UUID, baseVer int64) (model.ItemVersion, error) {
+	return call(ctx, r.in, true, func() (model.ItemVersion, error) { return r.next.Delete(ctx, userID, itemID, baseVer) })
+}
+
+// DeleteBatch implements repository.ItemRepository.
+func (r *ItemRepo) DeleteBatch(ctx context.Context, userID uuid.UUID, refs []model.ItemRef) ([]model.ItemResult, error) {
+	return call(ctx, r.in, true, func() ([]model.ItemResult, error) { return r.next.DeleteBatch(ctx, userID, refs) })
+}
+
+// ApplyChangeSet implements repository.ItemRepository.
+func (r *ItemRepo) ApplyChangeSet(ctx context.Context, userID uuid.UUID, cs model.ChangeSet) (model.ChangeSetResult, error) {
+	return call(ctx, r.in, true, func() (model.ChangeSetResult, error) { return r.next.ApplyChangeSet(ctx, userID, cs) })
+}
+
+// GetChangesSince implements repository.ItemRepository.
+func (r *ItemRepo) GetChangesSince(ctx context.Context, userID uuid.UUID, after model.ChangeCursor, limit int) ([]model.Change, error) {
+	return call(ctx, r.in, false, func() ([]model.Change, error) { return r.next.GetChangesSince(ctx, userID, after, limit) })
+}
+
+// GetJournalSince implements repository.ItemRepository.
+func (r *ItemRepo) GetJournalSince(ctx context.Context, userID uuid.UUID, afterSeq int64, limit int) ([]model.Change, error) {
+	return call(ctx, r.in, false, func() ([]model.Change, error) { return r.next.GetJournalSince(ctx, userID, afterSeq, limit) })
+}
+
+// JournalHead implements repository.ItemRepository.
+func (r *ItemRepo) JournalHead(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return call(ctx, r.in, false, func() (int64, error) { return r.next.JournalHead(ctx, userID) })
+}
+
+// GetSnapshot implements repository.ItemRepository.
+func (r *ItemRepo) GetSnapshot(ctx context.Context, userID uuid.UUID, atSeq int64, afterID uuid.UUID, limit int) ([]model.Change, error) {
+	return call(ctx, r.in, false, func() ([]model.Change, error) {
+		return r.next.GetSnapshot(ctx, userID, atSeq, afterID, limit)
+	})
+}
+
+// GetItem implements repository.ItemRepository.
+func (r *ItemRepo) GetItem(ctx context.Context, userID, itemID uuid.UUID) (*model.Item, error) {
+	return call(ctx, r.in, false, func() (*model.Item, error) { return r.next.GetItem(ctx, userID, itemID) })
+}
+
+// GetItemVersion implements repository.ItemRepository.
+func (r *ItemRepo) GetItemVersion(ctx context.Context, userID, itemID uuid.UUID, ver int64) (*model.Item, error) {
+	return call(ctx, r.in, false, func() (*model.Item, error) { return r.next.GetItemVersion(ctx, userID, itemID, ver) })
+}
+
+// Stats implements repository.ItemRepository.
+func (r *ItemRepo) Stats(ctx context.Context, userID uuid.UUID) (model.ItemStats, error) {
+	return call(ctx, r.in, false, func() (model.ItemStats, error) { return r.next.Stats(ctx, userID) })
+}
+
+// ExportItems implements repository.ItemRepository.
+func (r *ItemRepo) ExportItems(ctx context.Context, userID uuid.UUID, fn func(model.Item) error) error {
+	return r.in.do(ctx, false, func() error { return r.next.ExportItems(ctx, userID, fn) })
+}
+
+// Digests implements repository.ItemRepository.
+func (r *ItemRepo) Digests(ctx context.Context, userID uuid.UUID) ([]model.ItemDigest, error) {
+	return call(ctx, r.in, false, func() ([]model.ItemDigest, error) { return r.next.Digests(ctx, userID) })
+}
+
+// GetMaxVersion implements repository.ItemRepository.
+func (r *ItemRepo) GetMaxVersion(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return call(ctx, r.in, false, func() (int64, error) { return r.next.GetMaxVersion(ctx, userID) })
+}
+
+// UserRepo injects faults into the calls of a repository.UserRepository.
+type UserRepo struct {
+	in   *Injector
+	next repository.UserRepository
+}
+
+// NewUserRepo wraps next.
+func NewUserRepo(in *Injector, next repository.UserRepository) *UserRepo {
+	return &UserRepo{in: in, next: next}
+}
+
+// Create implements repository.UserRepository.
+func (r *UserRepo) Create(ctx context.Context, u *model.User) error {
+	return r.in.do(ctx, true, func() error { return r.next.Create(ctx, u) })
+}
+
+// GetByID implements repository.UserRepository.
+func (r *UserRepo) GetByID(ctx context.Context, id uuid.UUID) (*model.User, error) {
+	return call(ctx, r.in, false, func() (*model.User, error) { return r.next.GetByID(ctx, id) })
+}
+
+// GetByUsername implements repository.UserRepository.
+func (r *UserRepo) GetByUsername(ctx context.Context, username string) (*model.User, error) {
+	return call(ctx, r.in, false, func() (*model.User, error) { return r.next.GetByUsername(ctx, username) })
+}
+
+// SetWrappedDEKIfEmpty implements repository.UserRepository.
+func (r *UserRepo) SetWrappedDEKIfEmpty(ctx context.Context, id uuid.UUID, wrapped []byte) error {
+	return r.in.do(ctx, true, func() error { return r.next.SetWrappedDEKIfEmpty(ctx, id, wrapped) })
+}
+
+// ReplaceWrappedDEK implements repository.UserRepository.
+func (r *UserRepo) ReplaceWrappedDEK(ctx context.Context, id uuid.UUID, prev, wrapped []byte) error {
+	return r.in.do(ctx, true, func() error { return r.next.ReplaceWrappedDEK(ctx, id, prev, wrapped) })
+}
+
+// RecordLogin implements repository.UserRepository.
+func (r *UserRepo) RecordLogin(ctx context.Context, id uuid.UUID, a model.LoginAttempt, keep int) error {
+	return r.in.do(ctx, true, func() error { return r.next.RecordLogin(ctx, id, a, keep) })
+}
+
+// LoginHistory implements repository.UserRepository.
+func (r *UserRepo) LoginHistory(ctx context.Context, id uuid.UUID, limit int) ([]model.LoginAttempt, error) {
+	return call(ctx, r.in, false, func() ([]model.LoginAttempt, error) { return r.next.LoginHistory(ctx, id, limit) })
+}
+
+// CreateDuress implements repository.UserRepository.
+func (r *UserRepo) CreateDuress(ctx context.Context, id uuid.UUID, vault *model.User) error {
+	return r.in.do(ctx, true, func() error { return r.next.CreateDuress(ctx, id, vault) })
+}
+
+// GetDuress implements repository.UserRepository.
+func (r *UserRepo) GetDuress(ctx context.Context, id uuid.UUID) (*model.User, error) {
+	return call(ctx, r.in, false, func() (*model.User, error) { return r.next.GetDuress(ctx, id) })
+}
+
+// DeleteDuress implements repository.UserRepository.
+func (r *UserRepo) DeleteDuress(ctx context.Context, id uuid.UUID) error {
+	return r.in.do(ctx, true, func() error { return r.next.DeleteDuress(ctx, id) })
+}
+
+// Freeze implements repository.UserRepository.
+func (r *UserRepo) Freeze(ctx context.Context, id uuid.UUID, at time.Time) (time.Time, error) {
+	return call(ctx, r.in, true, func() (time.Time, error) { return r.next.Freeze(ctx, id, at) })
+}
+
+// Unfreeze implements repository.UserRepository.
+func (r *UserRepo) Unfreeze(ctx context.Context, id uuid.UUID) error {
+	return r.in.do(ctx, true, func() error { return r.next.Unfreeze(ctx, id) })
+}
+
+// FrozenAt implements repository.UserRepository.
+func (r *UserRepo) FrozenAt(ctx context.Context, id uuid.UUID) (time.Time, error) {
+	return call(ctx, r.in, false, func() (time.Time, error) { return r.next.FrozenAt(ctx, id) })
+}
+
+// SetDisabled implements repository.UserRepository.
+func (r *UserRepo) SetDisabled(ctx context.Context, id uuid.UUID, disabled bool) error {
+	return r.in.do(ctx, true, func() error { return r.next.SetDisabled(ctx, id, disabled) })
+}
+
+// Disabled implements repository.UserRepository.
+func (r *UserRepo) Disabled(ctx context.Context, id uuid.UUID) (bool, error) {
+	return call(ctx, r.in, false, func() (bool, error) { return r.next.Disabled(ctx, id) })
+}
+
+var (
+	_ repository.ItemRepository = (*ItemRepo)(nil)
+	_ repository.UserRepository = (*UserRepo)(nil)
+)
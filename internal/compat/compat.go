@@ -0,0 +1,122 @@
+// Package compat is the capability exchange between clients and server.
+// Clients name their version and the protocol features they rely on in
+// request metadata; the server refuses clients older than its minimum and
+// clients relying on a feature it no longer offers, with an error that
+// says so instead of answering in a way the client misreads.
+package compat
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Request metadata keys.
+const (
+	VersionKey  = "x-gk-client-version"
+	FeaturesKey = "x-gk-client-features" // comma-separated
+)
+
+// Protocol features. Core ones are part of every server this build talks
+// to; the others depend on the server's configuration.
+const (
+	FeatureBestEffort  = "best_effort"    // UpsertItems.best_effort and item_results
+	FeaturePageToken   = "page_token"     // paged GetChanges
+	FeatureDeleteItems = "delete_items"   // DeleteItems batches
+	FeatureChangeSet   = "change_set"     // ApplyChangeSet
+	FeatureJournal     = "change_journal" // GetChanges.since_seq
+	FeatureItemStream  = "item_stream"    // GetItemStream
+	FeatureLongPoll    = "long_poll"      // GetChangesLongPoll
+	FeatureSessions    = "sessions"       // refresh tokens, ListSessions, RevokeSession
+	FeatureWarnings    = "warnings"       // UpsertItemsResponse.warnings
+	FeatureDuress      = "duress"         // SetDuressPassword, RemoveDuressPassword
+	FeatureFreeze      = "freeze"         // FreezeVault, UnfreezeVault
+	FeatureExport      = "export_items"   // ExportItems
+	FeatureSyncSession = "sync_session"   // BeginSync, GetChanges.sync_session
+
+	FeatureWebhooks  = "webhooks"
+	FeaturePush      = "push"
+	FeatureChallenge = "challenge"
+	FeatureAccessLog = "access_log"
+)
+
+// Core lists the features this build of the server always offers.
+var Core = []string{
+	FeatureBestEffort, FeaturePageToken, FeatureDeleteItems, FeatureItemStream,
+	FeatureLongPoll, FeatureSessions, FeatureWarnings, FeatureChangeSet,
+	FeatureJournal, FeatureDuress, FeatureFreeze, FeatureExport,
+	FeatureSyncSession,
+}
+
+// ParseFeatures splits a FeaturesKey value, dropping empty entries.
+func ParseFeatures(s string) []string {
+	var fs []string
+	for f := range strings.SplitSeq(s, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			fs = append(fs, f)
+		}
+	}
+	return fs
+}
+
+// Missing returns the entries of want that are not in have, in order.
+func Missing(want, have []string) []string {
+	var out []string
+	for _, f := range want {
+		if !slices.Contains(have, f) {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// Older reports whether version v is before min. Versions look like
+// v1.2.3 or 1.2.3, optionally with a -suffix, which sorts before the
+// release. A version that does not parse, such as "dev", is never older:
+// development builds are let through.
+func Older(v, min string) bool {
+	a, ok := parse(v)
+	if !ok {
+		return false
+	}
+	b, ok := parse(min)
+	if !ok {
+		return false
+	}
+	for i := range 3 {
+		if a.num[i] != b.num[i] {
+			return a.num[i] < b.num[i]
+		}
+	}
+	// 1.2.3-rc1 < 1.2.3
+	return a.pre != "" && (b.pre == "" || a.pre < b.pre)
+}
+
+// Valid reports whether v is a version Older understands.
+func Valid(v string) bool {
+	_, ok := parse(v)
+	return ok
+}
+
+type version struct {
+	num [3]int
+	pre string
+}
+
+func parse(s string) (version, bool) {
+	var v version
+	s = strings.TrimPrefix(s, "v")
+	s, v.pre, _ = strings.Cut(s, "-")
+	parts := strings.Split(s, ".")
+	if len(parts) == 0 || len(parts) > 3 {
+		return v, false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return v, false
+		}
+		v.num[i] = n
+	}
+	return v, true
+}
@@ -0,0 +1,40 @@
+package compat
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestOlder(t *testing.T) {
+	for _, c := range []struct {
+		v, min string
+		want   bool
+	}{
+		{"v1.2.3", "v1.2.3", false},
+		{"v1.2.2", "v1.2.3", true},
+		{"1.10.0", "v1.9.0", false},
+		{"v2", "v1.9.9", false},
+		{"v1.3.0-rc1", "v1.3.0", true},
+		{"v1.3.0", "v1.3.0-rc1", false},
+		{"v1.3.0-rc1", "v1.3.0-rc2", true},
+		{"dev", "v1.0.0", false},
+		{"v0.9.0", "", false},
+	} {
+		if got := Older(c.v, c.min); got != c.want {
+			t.Errorf("Older(%q, %q) = %v", c.v, c.min, got)
+		}
+	}
+	if Valid("v1.x") || !Valid("1.2") {
+		t.Fatal("Valid")
+	}
+}
+
+func TestFeatures(t *testing.T) {
+	got := ParseFeatures(" best_effort,,warnings ,")
+	if !slices.Equal(got, []string{FeatureBestEffort, FeatureWarnings}) {
+		t.Fatalf("parse: %q", got)
+	}
+	if m := Missing([]string{"a", FeatureWarnings, "b"}, Core); !slices.Equal(m, []string{"a", "b"}) {
+		t.Fatalf("missing: %q", m)
+	}
+}
@@ -0,0 +1,157 @@
+// Package consistency validates the invariants sync relies on in one
+// user's stored items, and repairs what can be repaired safely.
+//
+// Every write moves an item to the next version and records it twice: in
+// item_history and in the item_changes journal. A client syncing from the
+// journal or from versions trusts that those records agree with the items
+// table. Tombstones keep their last blob in storage but are served without
+// it, so "blob present iff not deleted" reduces to live items having one.
+package consistency
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/and161185/goph-keeper/internal/repository"
+	"github.com/gofrs/uuid/v5"
+)
+
+// The invariants; they name the Check of a model.Violation.
+const (
+	// CheckBlob: a live item has a blob.
+	CheckBlob = "blob"
+	// CheckHistoryHead: the item's current version is in its history.
+	// Repairable.
+	CheckHistoryHead = "history_head"
+	// CheckHistoryMismatch: the history has the current version with the
+	// other deleted flag.
+	CheckHistoryMismatch = "history_mismatch"
+	// CheckHistoryAhead: the history has versions above the current one.
+	CheckHistoryAhead = "history_ahead"
+	// CheckHistoryGap: the history skips versions between its first and
+	// the current one.
+	CheckHistoryGap = "history_gap"
+	// CheckJournalHead: the item's newest journal entry has its current
+	// version and deleted state. Repairable.
+	CheckJournalHead = "journal_head"
+	// CheckJournalOrder: each journal entry of an item has a higher version
+	// than the one before, so no version is journaled twice and nothing
+	// follows a tombstone at or below its version. A rollback resets items
+	// to older versions, so only entries since the last one are compared.
+	CheckJournalOrder = "journal_order"
+)
+
+// Check returns the violations in t, ordered by item id.
+func Check(t model.VaultTrace) []model.Violation {
+	items := slices.Clone(t.Items)
+	slices.SortFunc(items, func(a, b model.ItemTrace) int { return cmp.Compare(a.ID.String(), b.ID.String()) })
+	var out []model.Violation
+	for _, it := range items {
+		add := func(check, format string, args ...any) {
+			out = append(out, model.Violation{ItemID: it.ID, Check: check, Detail: fmt.Sprintf(format, args...)})
+		}
+		if !it.Deleted && it.BlobLen == 0 {
+			add(CheckBlob, "live item at version %d has no blob", it.Ver)
+		}
+		checkHistory(it, add)
+		checkJournal(it, t, add)
+	}
+	return out
+}
+
+func checkHistory(it model.ItemTrace, add func(check, format string, args ...any)) {
+	have := map[int64]bool{}
+	first := it.Ver
+	var ahead []int64
+	for _, h := range it.History {
+		have[h.Ver] = true
+		first = min(first, h.Ver)
+		switch {
+		case h.Ver > it.Ver:
+			ahead = append(ahead, h.Ver)
+		case h.Ver == it.Ver && h.Deleted != it.Deleted:
+			add(CheckHistoryMismatch, "history has version %d with deleted=%t, the item deleted=%t", h.Ver, h.Deleted, it.Deleted)
+		}
+	}
+	if !have[it.Ver] {
+		add(CheckHistoryHead, "version %d is not in the history", it.Ver)
+	}
+	if len(ahead) > 0 {
+		add(CheckHistoryAhead, "history has versions %v above the current %d", ahead, it.Ver)
+	}
+	var missing []int64
+	for v := first + 1; v < it.Ver; v++ {
+		if !have[v] {
+			missing = append(missing, v)
+		}
+	}
+	if len(missing) > 0 {
+		add(CheckHistoryGap, "history lacks versions %v", missing)
+	}
+}
+
+func checkJournal(it model.ItemTrace, t model.VaultTrace, add func(check, format string, args ...any)) {
+	entries := slices.Clone(it.Journal)
+	slices.SortFunc(entries, func(a, b model.TraceEntry) int { return cmp.Compare(a.Seq, b.Seq) })
+
+	want := "upsert"
+	if it.Deleted {
+		want = "delete"
+	}
+	if len(entries) == 0 {
+		add(CheckJournalHead, "not journaled")
+	} else if last := entries[len(entries)-1]; last.Ver != it.Ver || last.Op != want {
+		add(CheckJournalHead, "newest entry (seq %d) is %s of version %d, the item is at version %d, deleted=%t", last.Seq, last.Op, last.Ver, it.Ver, it.Deleted)
+	}
+
+	var prev *model.TraceEntry
+	for i := range entries {
+		e := &entries[i]
+		if e.At.Before(t.LastRollback) {
+			continue
+		}
+		// a removal journals the version it removed, and a removed item
+		// starts again at version 1 if its id is reused
+		if prev != nil && prev.Op != "remove" && (e.Ver < prev.Ver || e.Ver == prev.Ver && e.Op != "remove") {
+			what := "an entry"
+			if prev.Op == "delete" {
+				what = "a tombstone"
+			}
+			add(CheckJournalOrder, "seq %d journals version %d after %s of version %d (seq %d)", e.Seq, e.Ver, what, prev.Ver, prev.Seq)
+			return
+		}
+		prev = e
+	}
+}
+
+// Run checks the user's items and, with repair, fixes the violations that
+// can be: it records missing history and journal heads from the items
+// table, which is what clients are served. Others are only reported.
+func Run(ctx context.Context, repo repository.ConsistencyRepository, userID uuid.UUID, repair bool) (checked int, vs []model.Violation, err error) {
+	t, err := repo.Trace(ctx, userID)
+	if err != nil {
+		return 0, nil, err
+	}
+	vs = Check(t)
+	if !repair {
+		return len(t.Items), vs, nil
+	}
+	for i := range vs {
+		switch vs[i].Check {
+		case CheckHistoryHead:
+			err = repo.RecordHistory(ctx, userID, vs[i].ItemID)
+		case CheckJournalHead:
+			err = repo.RecordJournal(ctx, userID, vs[i].ItemID)
+		default:
+			continue
+		}
+		if err != nil {
+			return len(t.Items), vs, err
+		}
+		vs[i].Repaired = true
+	}
+	return len(t.Items), vs, nil
+}
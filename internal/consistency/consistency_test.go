@@ -0,0 +1,340 @@
+package consistency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/and161185/goph-keeper/internal/repository/memory"
+)
+
+func checks(vs []model.Violation) []string {
+	out := make([]string, 0, len(vs))
+	for _, v := range vs {
+		out = append(out, v.Check)
+	}
+	return out
+}
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+	id := uuid.Must(uuid.NewV4())
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	hist := func(vers ...int64) []model.TraceVersion {
+		out := make([]model.TraceVersion, 0, len(vers))
+		for _, v := range vers {
+			out = append(out, model.TraceVersion{Ver: v})
+		}
+		return out
+	}
+	entry := func(seq int64, op string, ver int64) model.TraceEntry {
+		return model.TraceEntry{Seq: seq, Op: op, Ver: ver, At: t0.Add(time.Duration(seq) * time.Minute)}
+	}
+	healthy := model.ItemTrace{ID: id, Ver: 3, BlobLen: 10, History: hist(1, 2, 3),
+		Journal: []model.TraceEntry{entry(1, "upsert", 1), entry(4, "upsert", 2), entry(9, "upsert", 3)}}
+
+	tests := []struct {
+		name string
+		edit func(it *model.ItemTrace, tr *model.VaultTrace)
+		want []string
+	}{
+		{"healthy", func(*model.ItemTrace, *model.VaultTrace) {}, nil},
+		{"no blob", func(it *model.ItemTrace, _ *model.VaultTrace) { it.BlobLen = 0 }, []string{CheckBlob}},
+		{"tombstone without blob", func(it *model.ItemTrace, _ *model.VaultTrace) {
+			it.BlobLen, it.Ver, it.Deleted = 0, 4, true
+			it.History = append(it.History, model.TraceVersion{Ver: 4, Deleted: true})
+			it.Journal = append(it.Journal, entry(12, "delete", 4))
+		}, nil},
+		{"history head", func(it *model.ItemTrace, _ *model.VaultTrace) { it.History = hist(1, 2) }, []string{CheckHistoryHead}},
+		{"history mismatch", func(it *model.ItemTrace, _ *model.VaultTrace) {
+			it.History[2].Deleted = true
+		}, []string{CheckHistoryMismatch}},
+		{"history ahead", func(it *model.ItemTrace, _ *model.VaultTrace) { it.History = hist(1, 2, 3, 4) }, []string{CheckHistoryAhead}},
+		{"history gap", func(it *model.ItemTrace, _ *model.VaultTrace) { it.History = hist(1, 3) }, []string{CheckHistoryGap}},
+		{"history from before tracking", func(it *model.ItemTrace, _ *model.VaultTrace) { it.History = hist(2, 3) }, nil},
+		{"not journaled", func(it *model.ItemTrace, _ *model.VaultTrace) { it.Journal = nil }, []string{CheckJournalHead}},
+		{"journal behind", func(it *model.ItemTrace, _ *model.VaultTrace) { it.Journal = it.Journal[:2] }, []string{CheckJournalHead}},
+		{"journal says deleted", func(it *model.ItemTrace, _ *model.VaultTrace) { it.Journal[2].Op = "delete" }, []string{CheckJournalHead}},
+		{"duplicate version", func(it *model.ItemTrace, _ *model.VaultTrace) {
+			it.Journal = []model.TraceEntry{entry(1, "upsert", 1), entry(4, "upsert", 3), entry(9, "upsert", 3)}
+		}, []string{CheckJournalOrder}},
+		{"write under a tombstone", func(it *model.ItemTrace, _ *model.VaultTrace) {
+			it.Journal = []model.TraceEntry{entry(1, "upsert", 1), entry(4, "delete", 3), entry(9, "upsert", 3)}
+		}, []string{CheckJournalOrder}},
+		{"rolled back", func(it *model.ItemTrace, tr *model.VaultTrace) {
+			// versions 4 and 5 were written, then rolled back to 3
+			it.Journal = []model.TraceEntry{entry(1, "upsert", 3), entry(2, "upsert", 4), entry(3, "delete", 5), entry(9, "upsert", 3)}
+			tr.LastRollback = it.Journal[3].At
+		}, nil},
+		{"recreated after removal", func(it *model.ItemTrace, _ *model.VaultTrace) {
+			it.Ver, it.History = 1, hist(1)
+			it.Journal = []model.TraceEntry{entry(1, "upsert", 1), entry(2, "upsert", 2), entry(3, "remove", 2), entry(9, "upsert", 1)}
+		}, nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			it := healthy
+			it.History = slices.Clone(healthy.History)
+			it.Journal = slices.Clone(healthy.Journal)
+			tr := model.VaultTrace{}
+			tc.edit(&it, &tr)
+			tr.Items = []model.ItemTrace{it}
+			if got := checks(Check(tr)); !slices.Equal(got, tc.want) {
+				t.Fatalf("got %v, want %v: %+v", got, tc.want, Check(tr))
+			}
+		})
+	}
+}
+
+// vaultModel is the conflict model the repositories implement: a write
+// applies when its base version is the item's current one (0 for a new
+// item) and moves it to the next; a batch applies whole or not at all.
+type vaultModel map[uuid.UUID]*model.ItemTrace
+
+func (m vaultModel) ver(id uuid.UUID) int64 {
+	if it := m[id]; it != nil {
+		return it.Ver
+	}
+	return 0
+}
+
+// client is one device: it writes from the versions it last saw, which go
+// stale when another client writes.
+type client map[uuid.UUID]int64
+
+// Test_conflictModel drives random writes from several clients into the
+// memory repository and checks each outcome against vaultModel, then the
+// invariants over everything stored.
+func Test_conflictModel(t *testing.T) {
+	t.Parallel()
+	for seed := range uint64(200) {
+		t.Run(fmt.Sprint(seed), func(t *testing.T) {
+			t.Parallel()
+			runModel(t, rand.New(rand.NewPCG(seed, 1)))
+		})
+	}
+}
+
+func runModel(t *testing.T, r *rand.Rand) {
+	ctx := context.Background()
+	repo := memory.NewItemRepo()
+	user := uuid.Must(uuid.NewV4())
+	ids := make([]uuid.UUID, 5)
+	for i := range ids {
+		ids[i] = uuid.Must(uuid.NewV4())
+	}
+	m := vaultModel{}
+	clients := []client{{}, {}, {}}
+
+	// pick returns up to n distinct ids and a client's base for each
+	pick := func(c client, n int) ([]uuid.UUID, []int64) {
+		chosen := slices.Clone(ids)
+		r.Shuffle(len(chosen), func(i, j int) { chosen[i], chosen[j] = chosen[j], chosen[i] })
+		chosen = chosen[:1+r.IntN(n)]
+		bases := make([]int64, len(chosen))
+		for i, id := range chosen {
+			bases[i] = c[id]
+		}
+		return chosen, bases
+	}
+	apply := func(id uuid.UUID, deleted bool) {
+		it := m[id]
+		if it == nil {
+			it = &model.ItemTrace{ID: id}
+			m[id] = it
+		}
+		it.Ver++
+		it.Deleted = deleted
+	}
+	blob := []byte("sealed")
+
+	for step := range 60 {
+		c := clients[r.IntN(len(clients))]
+		switch op := r.IntN(4); op {
+		case 0: // UpsertBatch: all or nothing
+			chosen, bases := pick(c, 3)
+			ups := make([]model.UpsertItem, len(chosen))
+			stale := -1
+			for i, id := range chosen {
+				ups[i] = model.UpsertItem{ID: id, BaseVer: bases[i], BlobEnc: blob}
+				if stale < 0 && bases[i] != m.ver(id) {
+					stale = i
+				}
+			}
+			res, err := repo.UpsertBatch(ctx, user, ups)
+			var ce *errs.ConflictError
+			switch {
+			case stale >= 0:
+				if !errors.As(err, &ce) || ce.Index != stale || ce.Ver != m.ver(chosen[stale]) {
+					t.Fatalf("step %d: stale batch: %v", step, err)
+				}
+				c[chosen[stale]] = ce.Ver
+			case err != nil:
+				t.Fatalf("step %d: batch: %v", step, err)
+			default:
+				for i, id := range chosen {
+					apply(id, false)
+					if res[i].NewVer != m.ver(id) {
+						t.Fatalf("step %d: %s at %d, model %d", step, id, res[i].NewVer, m.ver(id))
+					}
+					c[id] = res[i].NewVer
+				}
+			}
+		case 1: // UpsertEach: item by item
+			chosen, bases := pick(c, 3)
+			ups := make([]model.UpsertItem, len(chosen))
+			for i, id := range chosen {
+				ups[i] = model.UpsertItem{ID: id, BaseVer: bases[i], BlobEnc: blob}
+			}
+			res, err := repo.UpsertEach(ctx, user, ups)
+			if err != nil {
+				t.Fatalf("step %d: each: %v", step, err)
+			}
+			for i, id := range chosen {
+				if bases[i] == m.ver(id) {
+					apply(id, false)
+					if res[i].Status != model.StatusOK {
+						t.Fatalf("step %d: %s refused: %+v", step, id, res[i])
+					}
+				} else if res[i].Status != model.StatusVersionConflict {
+					t.Fatalf("step %d: stale %s: %+v", step, id, res[i])
+				}
+				if res[i].Ver != m.ver(id) {
+					t.Fatalf("step %d: %s reported at %d, model %d", step, id, res[i].Ver, m.ver(id))
+				}
+				c[id] = res[i].Ver
+			}
+		case 2: // DeleteBatch: all or nothing, unknown items are not found
+			chosen, bases := pick(c, 2)
+			refs := make([]model.ItemRef, len(chosen))
+			ok := true
+			for i, id := range chosen {
+				refs[i] = model.ItemRef{ID: id, BaseVer: bases[i]}
+				ok = ok && m[id] != nil && bases[i] == m.ver(id)
+			}
+			res, err := repo.DeleteBatch(ctx, user, refs)
+			if err != nil {
+				t.Fatalf("step %d: delete: %v", step, err)
+			}
+			if model.AllOK(res) != ok {
+				t.Fatalf("step %d: delete applied=%v, model %v: %+v", step, model.AllOK(res), ok, res)
+			}
+			for i, id := range chosen {
+				switch {
+				case ok:
+					apply(id, true)
+				case m[id] == nil && res[i].Status != model.StatusNotFound:
+					t.Fatalf("step %d: unknown %s: %+v", step, id, res[i])
+				case m[id] != nil && bases[i] != m.ver(id) && res[i].Status != model.StatusVersionConflict:
+					t.Fatalf("step %d: stale delete of %s: %+v", step, id, res[i])
+				}
+				if res[i].Status == model.StatusOK || res[i].Status == model.StatusVersionConflict {
+					c[id] = res[i].Ver
+				}
+			}
+		case 3: // ApplyChangeSet: an upsert and a delete, together or not at all
+			chosen, bases := pick(c, 2)
+			cs := model.ChangeSet{Upserts: []model.UpsertItem{{ID: chosen[0], BaseVer: bases[0], BlobEnc: blob}}}
+			ok := bases[0] == m.ver(chosen[0])
+			if len(chosen) > 1 {
+				cs.Deletes = []model.ItemRef{{ID: chosen[1], BaseVer: bases[1]}}
+				ok = ok && m[chosen[1]] != nil && bases[1] == m.ver(chosen[1])
+			}
+			res, err := repo.ApplyChangeSet(ctx, user, cs)
+			if err != nil {
+				t.Fatalf("step %d: change set: %v", step, err)
+			}
+			if res.Applied() != ok {
+				t.Fatalf("step %d: change set applied=%v, model %v: %+v", step, res.Applied(), ok, res)
+			}
+			if ok {
+				apply(chosen[0], false)
+				c[chosen[0]] = res.Upserts[0].Ver
+				if len(chosen) > 1 {
+					apply(chosen[1], true)
+					c[chosen[1]] = res.Deletes[0].Ver
+				}
+			}
+		}
+	}
+
+	tr, err := repo.Trace(ctx, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vs := Check(tr); len(vs) > 0 {
+		t.Fatalf("invariants broken: %+v", vs)
+	}
+	if len(tr.Items) != len(m) {
+		t.Fatalf("%d items stored, model has %d", len(tr.Items), len(m))
+	}
+	for _, it := range tr.Items {
+		if want := m[it.ID]; want == nil || it.Ver != want.Ver || it.Deleted != want.Deleted {
+			t.Fatalf("%s stored at %d deleted=%v, model %+v", it.ID, it.Ver, it.Deleted, want)
+		}
+	}
+}
+
+func TestRun_Repair(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	user := uuid.Must(uuid.NewV4())
+	repo := &brokenRepo{ItemRepo: memory.NewItemRepo()}
+	id := uuid.Must(uuid.NewV4())
+	if _, err := repo.UpsertBatch(ctx, user, []model.UpsertItem{{ID: id, BlobEnc: []byte("x")}}); err != nil {
+		t.Fatal(err)
+	}
+	repo.noHistory = map[uuid.UUID]bool{id: true}
+	repo.noJournal = map[uuid.UUID]bool{id: true}
+
+	n, vs, err := Run(ctx, repo, user, false)
+	if err != nil || n != 1 || !slices.Equal(checks(vs), []string{CheckHistoryHead, CheckJournalHead}) || vs[0].Repaired {
+		t.Fatalf("check: %d %+v %v", n, vs, err)
+	}
+	if _, vs, err = Run(ctx, repo, user, true); err != nil || !vs[0].Repaired || !vs[1].Repaired {
+		t.Fatalf("repair: %+v %v", vs, err)
+	}
+	if _, vs, err = Run(ctx, repo, user, false); err != nil || len(vs) != 0 {
+		t.Fatalf("after repair: %+v %v", vs, err)
+	}
+}
+
+// brokenRepo hides the newest history and journal records of every item,
+// as if the triggers had missed the last write, until they are recorded
+// again.
+type brokenRepo struct {
+	*memory.ItemRepo
+	noHistory, noJournal map[uuid.UUID]bool
+}
+
+func (b *brokenRepo) Trace(ctx context.Context, userID uuid.UUID) (model.VaultTrace, error) {
+	t, err := b.ItemRepo.Trace(ctx, userID)
+	for i := range t.Items {
+		it := &t.Items[i]
+		if b.noHistory[it.ID] {
+			it.History = it.History[:len(it.History)-1]
+		}
+		if b.noJournal[it.ID] {
+			it.Journal = it.Journal[:len(it.Journal)-1]
+		}
+	}
+	return t, err
+}
+
+func (b *brokenRepo) RecordHistory(ctx context.Context, userID, itemID uuid.UUID) error {
+	delete(b.noHistory, itemID)
+	return b.ItemRepo.RecordHistory(ctx, userID, itemID)
+}
+
+func (b *brokenRepo) RecordJournal(ctx context.Context, userID, itemID uuid.UUID) error {
+	delete(b.noJournal, itemID)
+	return b.ItemRepo.RecordJournal(ctx, userID, itemID)
+}
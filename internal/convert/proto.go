@@ -2,6 +2,7 @@ package convert
 
 import (
 	"fmt"
+	"math"
 	"time"
 
 	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
@@ -41,6 +42,29 @@ func FromProtoEncryptedBlob(b *pb.EncryptedBlob) model.EncryptedBlob {
 	return model.EncryptedBlob(ctxt)
 }
 
+// --- ItemHints ---
+
+// ToProtoItemHints converts hints for the wire; zero hints become nil.
+func ToProtoItemHints(h model.ItemHints) *pb.ItemHints {
+	if h == (model.ItemHints{}) {
+		return nil
+	}
+	out := &pb.ItemHints{}
+	out.SetSizeClass(pb.SizeClass(h.SizeClass))
+	out.SetSchemaVersion(h.SchemaVersion)
+	return out
+}
+
+// FromProtoItemHints converts wire hints; nil yields zero hints. Unknown size
+// classes are kept for the service to reject.
+func FromProtoItemHints(h *pb.ItemHints) model.ItemHints {
+	c := h.GetSizeClass()
+	if c < 0 || c > math.MaxInt8 {
+		c = -1 // would wrap into a valid class
+	}
+	return model.ItemHints{SizeClass: model.SizeClass(c), SchemaVersion: h.GetSchemaVersion()}
+}
+
 // --- Upsert (client -> server) ---
 
 // FromProtoUpsertItem converts protobuf UpsertItem to domain struct.
@@ -56,6 +80,7 @@ func FromProtoUpsertItem(in *pb.UpsertItem) (model.UpsertItem, error) {
 		ID:      id,
 		BaseVer: in.GetBaseVer(),
 		BlobEnc: FromProtoEncryptedBlob(in.GetBlobEnc()),
+		Hints:   FromProtoItemHints(in.GetHints()),
 	}, nil
 }
 
@@ -72,6 +97,19 @@ func FromProtoUpsertItems(in []*pb.UpsertItem) ([]model.UpsertItem, error) {
 	return out, nil
 }
 
+// FromProtoItemRefs converts protobuf item refs to domain refs.
+func FromProtoItemRefs(in []*pb.ItemRef) ([]model.ItemRef, error) {
+	out := make([]model.ItemRef, 0, len(in))
+	for i, r := range in {
+		var id u.UUID
+		if err := id.UnmarshalText([]byte(r.GetId())); err != nil {
+			return nil, fmt.Errorf("item[%d]: invalid id: %w", i, err)
+		}
+		out = append(out, model.ItemRef{ID: id, BaseVer: r.GetBaseVer()})
+	}
+	return out, nil
+}
+
 // --- Versions / Changes (server -> client) ---
 
 // ToProtoItemVersion converts domain ItemVersion to protobuf result.
@@ -92,6 +130,20 @@ func ToProtoItemVersions(vs []model.ItemVersion) []*pb.ItemVersion {
 	return out
 }
 
+// ToProtoItemResults converts batch results to protobuf.
+func ToProtoItemResults(rs []model.ItemResult) []*pb.ItemResult {
+	out := make([]*pb.ItemResult, 0, len(rs))
+	for _, r := range rs {
+		ir := &pb.ItemResult{}
+		ir.SetId(r.ID.String())
+		ir.SetStatus(pb.ItemStatus(r.Status))
+		ir.SetVer(r.Ver)
+		ir.SetUpdatedAt(ts(r.UpdatedAt))
+		out = append(out, ir)
+	}
+	return out
+}
+
 // ToProtoChange converts domain.Change to pb.Change.
 func ToProtoChange(c model.Change) *pb.Change {
 	var blob *pb.EncryptedBlob
@@ -105,6 +157,10 @@ func ToProtoChange(c model.Change) *pb.Change {
 	change.SetDeleted(c.Deleted)
 	change.SetUpdatedAt(ts(c.UpdatedAt))
 	change.SetBlobEnc(blob)
+	change.SetHints(ToProtoItemHints(c.Hints))
+	if c.Seq > 0 {
+		change.SetSeq(c.Seq)
+	}
 
 	return change
 
@@ -130,6 +186,7 @@ func ToProtoGetItemResponse(it model.Item) *pb.GetItemResponse {
 	iresp.SetDeleted(it.Deleted)
 	iresp.SetUpdatedAt(ts(it.UpdatedAt))
 	iresp.SetBlobEnc(ToProtoEncryptedBlob(it.BlobEnc))
+	iresp.SetHints(ToProtoItemHints(it.Hints))
 
 	return iresp
 }
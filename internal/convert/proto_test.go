@@ -73,6 +73,31 @@ func TestFromProtoUpsertItem_OK(t *testing.T) {
 	}
 }
 
+func TestItemHints(t *testing.T) {
+	t.Parallel()
+
+	if ToProtoItemHints(model.ItemHints{}) != nil {
+		t.Fatalf("zero hints must give nil pb")
+	}
+	if FromProtoItemHints(nil) != (model.ItemHints{}) {
+		t.Fatalf("nil pb must give zero hints")
+	}
+	h := model.ItemHints{SizeClass: model.SizeLarge, SchemaVersion: 4}
+	p := ToProtoItemHints(h)
+	if p.GetSizeClass() != pb.SizeClass_SIZE_CLASS_LARGE || p.GetSchemaVersion() != 4 {
+		t.Fatalf("pb mismatch: %v", p)
+	}
+	if FromProtoItemHints(p) != h {
+		t.Fatalf("roundtrip mismatch")
+	}
+
+	// a class past int8 must not wrap into a valid one
+	p.SetSizeClass(pb.SizeClass(257))
+	if FromProtoItemHints(p).SizeClass.Valid() {
+		t.Fatalf("out-of-range class accepted")
+	}
+}
+
 func TestFromProtoUpsertItem_InvalidUUID(t *testing.T) {
 	t.Parallel()
 
@@ -4,10 +4,8 @@ package clientcrypto
 import (
 	"crypto/rand"
 	"crypto/sha256"
-	"encoding/binary"
 	"errors"
 
-	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/chacha20poly1305"
 	"golang.org/x/crypto/hkdf"
 )
@@ -28,9 +26,9 @@ func Rand(n int) ([]byte, error) {
 	return b, err
 }
 
-// DeriveKEK derives a KEK from password and kekSalt using Argon2id.
+// DeriveKEK derives a KEK from password and kekSalt using Argon2id with DefaultKDF.
 func DeriveKEK(password, kekSalt []byte) []byte {
-	return argon2.IDKey(password, kekSalt, argonTime, argonMemory, argonThreads, KeKLen)
+	return DefaultKDF.DeriveKEK(password, kekSalt)
 }
 
 // WrapDEK encrypts DEK with KEK using XChaCha20-Poly1305 and random nonce.
@@ -49,8 +47,12 @@ func WrapDEK(kek, dek []byte) ([]byte, error) {
 	return out, nil
 }
 
-// UnwrapDEK decrypts wrapped DEK using KEK.
+// UnwrapDEK decrypts wrapped DEK using KEK. Both the original format and
+// WrapDEKWithParams output are accepted.
 func UnwrapDEK(kek, wrapped []byte) ([]byte, error) {
+	if isVersioned(wrapped) {
+		return unwrapVersioned(kek, wrapped)
+	}
 	if len(wrapped) < chacha20poly1305.NonceSizeX {
 		return nil, errors.New("wrapped too short")
 	}
@@ -81,20 +83,22 @@ func EncryptBlob(key, userID, itemID []byte, ver int64, plaintext []byte) ([]byt
 	if err != nil {
 		return nil, err
 	}
-	aad := make([]byte, 0, len(userID)+len(itemID)+8)
-	aad = append(aad, userID...)
-	aad = append(aad, itemID...)
-	var v [8]byte
-	binary.BigEndian.PutUint64(v[:], uint64(ver))
-	aad = append(aad, v[:]...)
 	out := make([]byte, 0, len(nonce)+len(plaintext)+aead.Overhead())
 	out = append(out, nonce...)
-	out = append(out, aead.Seal(nil, nonce, plaintext, aad)...)
+	out = append(out, aead.Seal(nil, nonce, plaintext, blobAAD(nil, userID, itemID, ver))...)
 	return out, nil
 }
 
-// DecryptBlob decrypts a blob using the same AAD as during encryption.
+// DecryptBlob decrypts a blob using the same AAD as during encryption. Blobs
+// from EncryptBlobWith are recognised by their header.
 func DecryptBlob(key, userID, itemID []byte, ver int64, blob []byte) ([]byte, error) {
+	if BlobCipher(blob) != XChaCha20Poly1305 {
+		pt, err := decryptVersioned(key, userID, itemID, ver, blob)
+		if err == nil {
+			return pt, nil
+		}
+		// a headerless blob whose random nonce starts with the magic
+	}
 	if len(blob) < chacha20poly1305.NonceSizeX {
 		return nil, errors.New("blob too short")
 	}
@@ -104,11 +108,5 @@ func DecryptBlob(key, userID, itemID []byte, ver int64, blob []byte) ([]byte, er
 	}
 	nonce := blob[:chacha20poly1305.NonceSizeX]
 	ct := blob[chacha20poly1305.NonceSizeX:]
-	aad := make([]byte, 0, len(userID)+len(itemID)+8)
-	aad = append(aad, userID...)
-	aad = append(aad, itemID...)
-	var v [8]byte
-	binary.BigEndian.PutUint64(v[:], uint64(ver))
-	aad = append(aad, v[:]...)
-	return aead.Open(nil, nonce, ct, aad)
+	return aead.Open(nil, nonce, ct, blobAAD(nil, userID, itemID, ver))
 }
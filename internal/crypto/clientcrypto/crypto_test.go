@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"crypto/subtle"
 	"testing"
+
+	"golang.org/x/crypto/chacha20poly1305"
 )
 
 func TestRand_LengthUniq(t *testing.T) {
@@ -239,3 +241,133 @@ func TestEncryptDecryptBlob_Roundtrip_And_AAD(t *testing.T) {
 		t.Fatalf("wrong key should error")
 	}
 }
+
+func TestWrapDEKWithParams_RoundTripAndHeader(t *testing.T) {
+	t.Parallel()
+	p := KDFParams{Time: 1, MemoryKiB: 64, Threads: 1}
+	kek := p.DeriveKEK([]byte("pw"), []byte("salt"))
+	dek, _ := Rand(DEKLen)
+
+	w, err := WrapDEKWithParams(kek, dek, p)
+	if err != nil {
+		t.Fatalf("wrap: %v", err)
+	}
+	got, err := WrappedKDF(w)
+	if err != nil || got != p {
+		t.Fatalf("WrappedKDF = %v, %v; want %v", got, err, p)
+	}
+	out, err := UnwrapDEK(kek, w)
+	if err != nil || !bytes.Equal(out, dek) {
+		t.Fatalf("unwrap: %v", err)
+	}
+
+	// the header is authenticated: editing the parameters breaks the unwrap
+	bad := bytes.Clone(w)
+	bad[7]++
+	if _, err := UnwrapDEK(kek, bad); err == nil {
+		t.Fatalf("tampered header must not unwrap")
+	}
+
+	legacy, _ := WrapDEK(kek, dek)
+	if got, err := WrappedKDF(legacy); err != nil || got != DefaultKDF {
+		t.Fatalf("legacy WrappedKDF = %v, %v", got, err)
+	}
+	copy(legacy, "GKK1") // a legacy nonce may start with the magic by chance
+	if got, _ := WrappedKDF(legacy); got != DefaultKDF {
+		t.Fatalf("legacy blob with magic prefix parsed as %v", got)
+	}
+}
+
+func TestWrappedKDF_RejectsOutOfBounds(t *testing.T) {
+	t.Parallel()
+	hdr := []byte("GKK1\x00\x00\x00\x01\xff\xff\xff\xff\x01")
+	w := append(hdr, make([]byte, 72)...)
+	if _, err := WrappedKDF(w); err == nil {
+		t.Fatalf("want error for 4 TiB memory")
+	}
+	if _, err := WrapDEKWithParams(make([]byte, KeKLen), make([]byte, DEKLen), KDFParams{}); err == nil {
+		t.Fatalf("want validation error for zero params")
+	}
+}
+
+func TestEncryptBlobWith_CiphersAndHeader(t *testing.T) {
+	t.Parallel()
+	key, _ := Rand(DEKLen)
+	u, it := []byte("user"), []byte("item")
+	pt := []byte("secret payload")
+
+	for _, c := range []Cipher{XChaCha20Poly1305, AES256GCM} {
+		blob, err := EncryptBlobWith(c, key, u, it, 3, pt)
+		if err != nil {
+			t.Fatalf("%s: encrypt: %v", c, err)
+		}
+		if BlobCipher(blob) != c {
+			t.Fatalf("%s: BlobCipher = %s", c, BlobCipher(blob))
+		}
+		got, err := DecryptBlob(key, u, it, 3, blob)
+		if err != nil || !bytes.Equal(got, pt) {
+			t.Fatalf("%s: decrypt: %v", c, err)
+		}
+		if _, err := DecryptBlob(key, u, it, 4, blob); err == nil {
+			t.Fatalf("%s: wrong version must fail", c)
+		}
+	}
+
+	// switching the cipher byte must not produce a readable blob
+	blob, _ := EncryptBlobWith(AES256GCM, key, u, it, 1, pt)
+	blob[4] = byte(XChaCha20Poly1305)
+	if _, err := DecryptBlob(key, u, it, 1, blob); err == nil {
+		t.Fatalf("tampered header must fail")
+	}
+
+	// a legacy blob whose nonce happens to start with the header still opens
+	aead, _ := chacha20poly1305.NewX(key)
+	nonce := append([]byte("GKB1\x02"), make([]byte, chacha20poly1305.NonceSizeX-5)...)
+	legacy := append(nonce, aead.Seal(nil, nonce, pt, blobAAD(nil, u, it, 1))...)
+	if got, err := DecryptBlob(key, u, it, 1, legacy); err != nil || !bytes.Equal(got, pt) {
+		t.Fatalf("legacy blob with magic-like nonce: %v", err)
+	}
+
+	if c, err := ParseCipher("aes-256-gcm"); err != nil || c != AES256GCM {
+		t.Fatalf("ParseCipher: %v %v", c, err)
+	}
+	if _, err := ParseCipher("des"); err == nil {
+		t.Fatalf("want error for unknown cipher")
+	}
+}
+
+func TestHybridWrap_RoundTrip(t *testing.T) {
+	t.Parallel()
+	k, err := GenerateHybridKey()
+	if err != nil {
+		t.Fatalf("keygen: %v", err)
+	}
+	dek, _ := Rand(DEKLen)
+	w, err := WrapDEKForRecipient(k.Public(), dek)
+	if err != nil {
+		t.Fatalf("wrap: %v", err)
+	}
+
+	k2, err := ParseHybridKey(k.Bytes())
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	got, err := k2.UnwrapDEK(w)
+	if err != nil || !bytes.Equal(got, dek) {
+		t.Fatalf("unwrap: %v", err)
+	}
+
+	other, _ := GenerateHybridKey()
+	if _, err := other.UnwrapDEK(w); err == nil {
+		t.Fatalf("another recipient must not unwrap")
+	}
+	// flipping a byte of the ML-KEM ciphertext changes the derived key
+	bad := bytes.Clone(w)
+	bad[4+32+10] ^= 1
+	if _, err := k.UnwrapDEK(bad); err == nil {
+		t.Fatalf("tampered KEM ciphertext must fail")
+	}
+	if _, err := WrapDEKForRecipient(k.Public()[:40], dek); err == nil {
+		t.Fatalf("want error for short public key")
+	}
+}
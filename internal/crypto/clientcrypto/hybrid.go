@@ -0,0 +1,158 @@
+package clientcrypto
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/mlkem"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/hkdf"
+)
+
+// Hybrid recipient keys combine X25519 with ML-KEM-768: a DEK wrapped for a
+// recipient stays safe as long as either one holds, so ciphertext recorded
+// today is not exposed by a future quantum computer breaking X25519.
+const (
+	x25519Len        = 32
+	hybridPublicLen  = x25519Len + mlkem.EncapsulationKeySize768
+	hybridPrivateLen = x25519Len + mlkem.SeedSize
+	hybridHeaderLen  = 4 + x25519Len + mlkem.CiphertextSize768
+)
+
+var hybridMagic = []byte("GKH1")
+
+// HybridKey is a recipient's private key.
+type HybridKey struct {
+	x  *ecdh.PrivateKey
+	kd *mlkem.DecapsulationKey768
+}
+
+// GenerateHybridKey creates a new recipient key pair.
+func GenerateHybridKey() (*HybridKey, error) {
+	x, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	kd, err := mlkem.GenerateKey768()
+	if err != nil {
+		return nil, err
+	}
+	return &HybridKey{x: x, kd: kd}, nil
+}
+
+// ParseHybridKey restores a key from Bytes.
+func ParseHybridKey(b []byte) (*HybridKey, error) {
+	if len(b) != hybridPrivateLen {
+		return nil, errors.New("hybrid key: bad length")
+	}
+	x, err := ecdh.X25519().NewPrivateKey(b[:x25519Len])
+	if err != nil {
+		return nil, err
+	}
+	kd, err := mlkem.NewDecapsulationKey768(b[x25519Len:])
+	if err != nil {
+		return nil, err
+	}
+	return &HybridKey{x: x, kd: kd}, nil
+}
+
+// Bytes is the X25519 scalar followed by the ML-KEM seed.
+func (k *HybridKey) Bytes() []byte {
+	return append(k.x.Bytes(), k.kd.Bytes()...)
+}
+
+// Public is the X25519 public key followed by the ML-KEM encapsulation key.
+func (k *HybridKey) Public() []byte {
+	return append(k.x.PublicKey().Bytes(), k.kd.EncapsulationKey().Bytes()...)
+}
+
+// WrapDEKForRecipient seals dek to a hybrid public key. The result is
+// magic || ephemeral X25519 key || ML-KEM ciphertext || nonce || ciphertext.
+func WrapDEKForRecipient(pub, dek []byte) ([]byte, error) {
+	if len(pub) != hybridPublicLen {
+		return nil, errors.New("hybrid public key: bad length")
+	}
+	rx, err := ecdh.X25519().NewPublicKey(pub[:x25519Len])
+	if err != nil {
+		return nil, err
+	}
+	ek, err := mlkem.NewEncapsulationKey768(pub[x25519Len:])
+	if err != nil {
+		return nil, err
+	}
+	eph, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	xss, err := eph.ECDH(rx)
+	if err != nil {
+		return nil, err
+	}
+	kss, kct := ek.Encapsulate()
+
+	hdr := make([]byte, 0, hybridHeaderLen)
+	hdr = append(hdr, hybridMagic...)
+	hdr = append(hdr, eph.PublicKey().Bytes()...)
+	hdr = append(hdr, kct...)
+	kek, err := hybridKEK(kss, xss, hdr, rx.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	aead, err := chacha20poly1305.NewX(kek)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := Rand(chacha20poly1305.NonceSizeX)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, len(hdr)+len(nonce)+len(dek)+aead.Overhead())
+	out = append(out, hdr...)
+	out = append(out, nonce...)
+	return append(out, aead.Seal(nil, nonce, dek, hdr)...), nil
+}
+
+// UnwrapDEK opens a WrapDEKForRecipient result.
+func (k *HybridKey) UnwrapDEK(wrapped []byte) ([]byte, error) {
+	if len(wrapped) < hybridHeaderLen+chacha20poly1305.NonceSizeX || !bytes.HasPrefix(wrapped, hybridMagic) {
+		return nil, errors.New("not a hybrid-wrapped DEK")
+	}
+	hdr := wrapped[:hybridHeaderLen]
+	eph, err := ecdh.X25519().NewPublicKey(hdr[4 : 4+x25519Len])
+	if err != nil {
+		return nil, err
+	}
+	xss, err := k.x.ECDH(eph)
+	if err != nil {
+		return nil, err
+	}
+	kss, err := k.kd.Decapsulate(hdr[4+x25519Len:])
+	if err != nil {
+		return nil, err
+	}
+	kek, err := hybridKEK(kss, xss, hdr, k.x.PublicKey().Bytes())
+	if err != nil {
+		return nil, err
+	}
+	aead, err := chacha20poly1305.NewX(kek)
+	if err != nil {
+		return nil, err
+	}
+	rest := wrapped[hybridHeaderLen:]
+	return aead.Open(nil, rest[:chacha20poly1305.NonceSizeX], rest[chacha20poly1305.NonceSizeX:], hdr)
+}
+
+// hybridKEK combines both shared secrets, binding the transcript (header and
+// recipient X25519 key) into the derivation.
+func hybridKEK(kemSecret, ecdhSecret, hdr, recipientX []byte) ([]byte, error) {
+	ikm := append(bytes.Clone(kemSecret), ecdhSecret...)
+	info := append([]byte("goph-keeper hybrid dek v1"), hdr...)
+	info = append(info, recipientX...)
+	kek := make([]byte, KeKLen)
+	_, err := io.ReadFull(hkdf.New(sha256.New, ikm, nil, info), kek)
+	return kek, err
+}
@@ -0,0 +1,123 @@
+package clientcrypto
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// KDFParams are the Argon2id costs used to derive a KEK from the password.
+type KDFParams struct {
+	Time      uint32 // passes over memory
+	MemoryKiB uint32
+	Threads   uint8
+}
+
+// DefaultKDF are the parameters of wrapped DEKs that carry none.
+var DefaultKDF = KDFParams{Time: argonTime, MemoryKiB: argonMemory, Threads: argonThreads}
+
+// Bounds on parameters read from a wrapped DEK. The server stores the
+// header, so it must not be able to make a client burn unbounded memory.
+const (
+	maxKDFTime      = 64
+	maxKDFMemoryKiB = 4 << 20 // 4 GiB
+)
+
+// Validate checks the parameters against Argon2's minimums and our bounds.
+func (p KDFParams) Validate() error {
+	switch {
+	case p.Time < 1 || p.Time > maxKDFTime:
+		return fmt.Errorf("kdf time %d out of range 1..%d", p.Time, maxKDFTime)
+	case p.Threads < 1:
+		return errors.New("kdf threads must be at least 1")
+	case p.MemoryKiB < 8*uint32(p.Threads) || p.MemoryKiB > maxKDFMemoryKiB:
+		return fmt.Errorf("kdf memory %d KiB out of range %d..%d", p.MemoryKiB, 8*uint32(p.Threads), maxKDFMemoryKiB)
+	}
+	return nil
+}
+
+// Cost is a rough work measure (passes × memory) for comparing parameter sets.
+func (p KDFParams) Cost() uint64 { return uint64(p.Time) * uint64(p.MemoryKiB) }
+
+func (p KDFParams) String() string {
+	return fmt.Sprintf("t=%d m=%dMiB p=%d", p.Time, p.MemoryKiB/1024, p.Threads)
+}
+
+// DeriveKEK derives a KEK with these parameters.
+func (p KDFParams) DeriveKEK(password, kekSalt []byte) []byte {
+	return argon2.IDKey(password, kekSalt, p.Time, p.MemoryKiB, p.Threads, KeKLen)
+}
+
+// Versioned wrapped DEK: magic || time u32 || memory u32 || threads u8 ||
+// nonce || ciphertext, with the header bound as AAD. Blobs without the magic
+// are the original format (nonce || ciphertext) under DefaultKDF.
+var wrapMagic = []byte("GKK1")
+
+const (
+	wrapHeaderLen = 4 + 4 + 4 + 1
+	// legacyWrappedLen is the size of an unversioned wrapped DEK; a legacy
+	// nonce that happens to start with the magic is recognised by it.
+	legacyWrappedLen = chacha20poly1305.NonceSizeX + DEKLen + chacha20poly1305.Overhead
+)
+
+func isVersioned(wrapped []byte) bool {
+	return len(wrapped) != legacyWrappedLen && len(wrapped) >= wrapHeaderLen && bytes.HasPrefix(wrapped, wrapMagic)
+}
+
+// WrappedKDF returns the parameters a wrapped DEK was made with.
+func WrappedKDF(wrapped []byte) (KDFParams, error) {
+	if !isVersioned(wrapped) {
+		return DefaultKDF, nil
+	}
+	p := KDFParams{
+		Time:      binary.BigEndian.Uint32(wrapped[4:8]),
+		MemoryKiB: binary.BigEndian.Uint32(wrapped[8:12]),
+		Threads:   wrapped[12],
+	}
+	return p, p.Validate()
+}
+
+// WrapDEKWithParams wraps dek like WrapDEK and records p in the result, so a
+// later login knows how to derive the KEK.
+func WrapDEKWithParams(kek, dek []byte, p KDFParams) ([]byte, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	aead, err := chacha20poly1305.NewX(kek)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := Rand(chacha20poly1305.NonceSizeX)
+	if err != nil {
+		return nil, err
+	}
+	hdr := make([]byte, 0, wrapHeaderLen)
+	hdr = append(hdr, wrapMagic...)
+	hdr = binary.BigEndian.AppendUint32(hdr, p.Time)
+	hdr = binary.BigEndian.AppendUint32(hdr, p.MemoryKiB)
+	hdr = append(hdr, p.Threads)
+
+	out := make([]byte, 0, len(hdr)+len(nonce)+len(dek)+aead.Overhead())
+	out = append(out, hdr...)
+	out = append(out, nonce...)
+	out = append(out, aead.Seal(nil, nonce, dek, hdr)...)
+	return out, nil
+}
+
+// unwrapVersioned opens a WrapDEKWithParams blob.
+func unwrapVersioned(kek, wrapped []byte) ([]byte, error) {
+	if len(wrapped) < wrapHeaderLen+chacha20poly1305.NonceSizeX {
+		return nil, errors.New("wrapped too short")
+	}
+	aead, err := chacha20poly1305.NewX(kek)
+	if err != nil {
+		return nil, err
+	}
+	hdr := wrapped[:wrapHeaderLen]
+	nonce := wrapped[wrapHeaderLen : wrapHeaderLen+chacha20poly1305.NonceSizeX]
+	return aead.Open(nil, nonce, wrapped[wrapHeaderLen+chacha20poly1305.NonceSizeX:], hdr)
+}
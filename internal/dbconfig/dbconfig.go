@@ -0,0 +1,147 @@
+// Package dbconfig turns first-class database flags (pool sizing, lifetimes,
+// TLS) into pgx configuration, so they need not be hidden in the DSN.
+package dbconfig
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// sslModes are the libpq sslmode values pgx understands.
+var sslModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+// Options are database settings layered over the DSN. Zero values keep
+// whatever the DSN (or pgx's default) says.
+type Options struct {
+	MaxConns          int32
+	MinConns          int32
+	MaxConnLifetime   time.Duration
+	MaxConnIdleTime   time.Duration
+	HealthCheckPeriod time.Duration
+	// StatementTimeout is set as the server-side statement_timeout, a
+	// backstop for queries whose client context cannot cancel them.
+	StatementTimeout time.Duration
+	// Warmup bounds opening MinConns connections at startup; zero leaves
+	// them to pgx's background fill.
+	Warmup time.Duration
+	// PingPeriod is how often idle connections are pinged and broken ones
+	// replaced; zero never. Neither is a pgx setting.
+	PingPeriod time.Duration
+
+	SSLMode     string
+	SSLRootCert string // CA bundle for verify-ca / verify-full
+	SSLCert     string // client certificate
+	SSLKey      string // client key
+}
+
+// Validate checks ranges, the sslmode name and that referenced files exist.
+func (o Options) Validate() error {
+	var errs []error
+	if o.MaxConns < 0 || o.MinConns < 0 {
+		errs = append(errs, errors.New("connection counts must not be negative"))
+	}
+	if o.MaxConns > 0 && o.MinConns > o.MaxConns {
+		errs = append(errs, fmt.Errorf("min conns %d exceeds max conns %d", o.MinConns, o.MaxConns))
+	}
+	if o.MaxConnLifetime < 0 || o.MaxConnIdleTime < 0 || o.HealthCheckPeriod < 0 || o.StatementTimeout < 0 ||
+		o.Warmup < 0 || o.PingPeriod < 0 {
+		errs = append(errs, errors.New("durations must not be negative"))
+	}
+	if o.SSLMode != "" && !slices.Contains(sslModes, o.SSLMode) {
+		errs = append(errs, fmt.Errorf("sslmode %q: want one of %s", o.SSLMode, strings.Join(sslModes, ", ")))
+	}
+	if o.SSLMode == "disable" && (o.SSLRootCert != "" || o.SSLCert != "" || o.SSLKey != "") {
+		errs = append(errs, errors.New("TLS files given but sslmode is disable"))
+	}
+	if (o.SSLCert == "") != (o.SSLKey == "") {
+		errs = append(errs, errors.New("client certificate and key must be given together"))
+	}
+	for _, f := range []string{o.SSLRootCert, o.SSLCert, o.SSLKey} {
+		if f == "" {
+			continue
+		}
+		if _, err := os.Stat(f); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ApplyDSN returns dsn with the TLS options set, overriding any values already
+// in it. Both URL ("postgres://...") and keyword/value DSNs are accepted.
+func (o Options) ApplyDSN(dsn string) (string, error) {
+	params := [][2]string{
+		{"sslmode", o.SSLMode},
+		{"sslrootcert", o.SSLRootCert},
+		{"sslcert", o.SSLCert},
+		{"sslkey", o.SSLKey},
+	}
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			return "", errors.New("parse dsn: invalid URL") // url errors quote the URL, password included
+		}
+		q := u.Query()
+		for _, p := range params {
+			if p[1] != "" {
+				q.Set(p[0], p[1])
+			}
+		}
+		u.RawQuery = q.Encode()
+		return u.String(), nil
+	}
+	// keyword/value form: later keywords win
+	var b strings.Builder
+	b.WriteString(dsn)
+	for _, p := range params {
+		if p[1] != "" {
+			fmt.Fprintf(&b, " %s='%s'", p[0], strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(p[1]))
+		}
+	}
+	return b.String(), nil
+}
+
+// PoolConfig parses dsn with the options applied.
+func (o Options) PoolConfig(dsn string) (*pgxpool.Config, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+	full, err := o.ApplyDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := pgxpool.ParseConfig(full)
+	if err != nil {
+		return nil, errors.New("parse dsn: invalid connection string") // pgx errors may echo the password
+	}
+	if o.MaxConns > 0 {
+		cfg.MaxConns = o.MaxConns
+	}
+	if o.MinConns > 0 {
+		cfg.MinConns = o.MinConns
+	}
+	if cfg.MinConns > cfg.MaxConns {
+		return nil, fmt.Errorf("min conns %d exceeds max conns %d", cfg.MinConns, cfg.MaxConns)
+	}
+	if o.MaxConnLifetime > 0 {
+		cfg.MaxConnLifetime = o.MaxConnLifetime
+	}
+	if o.MaxConnIdleTime > 0 {
+		cfg.MaxConnIdleTime = o.MaxConnIdleTime
+	}
+	if o.HealthCheckPeriod > 0 {
+		cfg.HealthCheckPeriod = o.HealthCheckPeriod
+	}
+	if o.StatementTimeout > 0 {
+		cfg.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(o.StatementTimeout.Milliseconds(), 10)
+	}
+	return cfg, nil
+}
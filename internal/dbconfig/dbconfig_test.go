@@ -0,0 +1,97 @@
+package dbconfig
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOptions_Validate(t *testing.T) {
+	t.Parallel()
+	ca := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(ca, []byte("x"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ok := []Options{
+		{},
+		{MaxConns: 10, MinConns: 2, MaxConnLifetime: time.Hour},
+		{SSLMode: "verify-full", SSLRootCert: ca},
+	}
+	for _, o := range ok {
+		if err := o.Validate(); err != nil {
+			t.Fatalf("%+v: %v", o, err)
+		}
+	}
+	bad := []Options{
+		{MaxConns: -1},
+		{MaxConns: 2, MinConns: 5},
+		{HealthCheckPeriod: -time.Second},
+		{PingPeriod: -time.Second},
+		{SSLMode: "on"},
+		{SSLMode: "disable", SSLRootCert: ca},
+		{SSLCert: ca},
+		{SSLMode: "verify-ca", SSLRootCert: filepath.Join(t.TempDir(), "missing.pem")},
+	}
+	for _, o := range bad {
+		if err := o.Validate(); err == nil {
+			t.Fatalf("%+v: want error", o)
+		}
+	}
+}
+
+func TestOptions_ApplyDSN(t *testing.T) {
+	t.Parallel()
+	o := Options{SSLMode: "verify-full", SSLRootCert: "/etc/ssl/ca.pem"}
+
+	got, err := o.ApplyDSN("postgres://u:p@db:5432/gk?sslmode=disable")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, "sslmode=verify-full") || strings.Contains(got, "sslmode=disable") ||
+		!strings.Contains(got, "sslrootcert=%2Fetc%2Fssl%2Fca.pem") {
+		t.Fatalf("url dsn: %s", got)
+	}
+
+	got, err = o.ApplyDSN("host=db user=u dbname=gk sslmode=disable")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(got, " sslmode='verify-full' sslrootcert='/etc/ssl/ca.pem'") {
+		t.Fatalf("keyword dsn: %s", got)
+	}
+
+	if got, _ := (Options{}).ApplyDSN("postgres://db/gk"); got != "postgres://db/gk" {
+		t.Fatalf("no options must keep dsn: %s", got)
+	}
+	if _, err := o.ApplyDSN("postgres://u:secretpw@db:bad port/gk"); err == nil || strings.Contains(err.Error(), "secretpw") {
+		t.Fatalf("bad url must fail without leaking the password: %v", err)
+	}
+}
+
+func TestOptions_PoolConfig(t *testing.T) {
+	t.Parallel()
+	o := Options{MaxConns: 20, MinConns: 4, MaxConnLifetime: 30 * time.Minute, MaxConnIdleTime: 5 * time.Minute, HealthCheckPeriod: 15 * time.Second, StatementTimeout: 3 * time.Second, SSLMode: "require"}
+	cfg, err := o.PoolConfig("postgres://u:p@db:5432/gk?pool_max_conns=3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.MaxConns != 20 || cfg.MinConns != 4 || cfg.MaxConnLifetime != 30*time.Minute ||
+		cfg.MaxConnIdleTime != 5*time.Minute || cfg.HealthCheckPeriod != 15*time.Second {
+		t.Fatalf("pool settings: %+v", cfg)
+	}
+	if cfg.ConnConfig.RuntimeParams["statement_timeout"] != "3000" {
+		t.Fatalf("statement_timeout: %q", cfg.ConnConfig.RuntimeParams["statement_timeout"])
+	}
+	if cfg.ConnConfig.TLSConfig == nil {
+		t.Fatal("sslmode=require must enable TLS")
+	}
+
+	if _, err := (Options{MinConns: 10}).PoolConfig("postgres://db/gk?pool_max_conns=4"); err == nil {
+		t.Fatal("min above DSN max must fail")
+	}
+	if _, err := (Options{}).PoolConfig("host=db port=notaport password=secretpw"); err == nil || strings.Contains(err.Error(), "secretpw") {
+		t.Fatalf("bad dsn must fail without leaking the password: %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+// Package diag exposes runtime diagnostics (pprof, expvar, GC stats) over HTTP.
+package diag
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"runtime/debug"
+	"sync/atomic"
+	"time"
+)
+
+// Handler serves diagnostics endpoints; it answers 404 while disabled.
+type Handler struct {
+	enabled atomic.Bool
+	mux     *http.ServeMux
+}
+
+// New constructs a diagnostics handler with the given initial state.
+func New(enabled bool) *Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+	mux.HandleFunc("/debug/gc", gcStats)
+
+	h := &Handler{mux: mux}
+	h.enabled.Store(enabled)
+	return h
+}
+
+// SetEnabled switches diagnostics exposure on or off.
+func (h *Handler) SetEnabled(v bool) { h.enabled.Store(v) }
+
+// Enabled reports whether diagnostics are currently exposed.
+func (h *Handler) Enabled() bool { return h.enabled.Load() }
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.Enabled() {
+		http.NotFound(w, r)
+		return
+	}
+	h.mux.ServeHTTP(w, r)
+}
+
+// GCStats is a compact snapshot of garbage collector and heap state.
+type GCStats struct {
+	NumGC        int64         `json:"num_gc"`
+	LastGC       time.Time     `json:"last_gc"`
+	PauseTotal   time.Duration `json:"pause_total_ns"`
+	HeapAlloc    uint64        `json:"heap_alloc"`
+	HeapInuse    uint64        `json:"heap_inuse"`
+	HeapObjects  uint64        `json:"heap_objects"`
+	Sys          uint64        `json:"sys"`
+	NumGoroutine int           `json:"num_goroutine"`
+}
+
+// ReadGCStats collects the current GC/heap snapshot.
+func ReadGCStats() GCStats {
+	var gs debug.GCStats
+	debug.ReadGCStats(&gs)
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	return GCStats{
+		NumGC:        gs.NumGC,
+		LastGC:       gs.LastGC,
+		PauseTotal:   gs.PauseTotal,
+		HeapAlloc:    ms.HeapAlloc,
+		HeapInuse:    ms.HeapInuse,
+		HeapObjects:  ms.HeapObjects,
+		Sys:          ms.Sys,
+		NumGoroutine: runtime.NumGoroutine(),
+	}
+}
+
+func gcStats(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(ReadGCStats())
+}
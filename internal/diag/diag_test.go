@@ -0,0 +1,60 @@
+package diag
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func get(t *testing.T, h http.Handler, path string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	return rec
+}
+
+func TestHandler_DisabledReturns404(t *testing.T) {
+	t.Parallel()
+	h := New(false)
+	for _, p := range []string{"/debug/pprof/", "/debug/vars", "/debug/gc"} {
+		if rec := get(t, h, p); rec.Code != http.StatusNotFound {
+			t.Fatalf("%s: want 404 when disabled, got %d", p, rec.Code)
+		}
+	}
+}
+
+func TestHandler_ToggleAtRuntime(t *testing.T) {
+	t.Parallel()
+	h := New(false)
+	h.SetEnabled(true)
+	if !h.Enabled() {
+		t.Fatalf("want enabled")
+	}
+	if rec := get(t, h, "/debug/pprof/"); rec.Code != http.StatusOK {
+		t.Fatalf("pprof index: %d", rec.Code)
+	}
+	if rec := get(t, h, "/debug/vars"); rec.Code != http.StatusOK {
+		t.Fatalf("expvar: %d", rec.Code)
+	}
+	h.SetEnabled(false)
+	if rec := get(t, h, "/debug/vars"); rec.Code != http.StatusNotFound {
+		t.Fatalf("want 404 after disable, got %d", rec.Code)
+	}
+}
+
+func TestHandler_GCStatsJSON(t *testing.T) {
+	t.Parallel()
+	h := New(true)
+	rec := get(t, h, "/debug/gc")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("gc: %d", rec.Code)
+	}
+	var st GCStats
+	if err := json.Unmarshal(rec.Body.Bytes(), &st); err != nil {
+		t.Fatalf("bad json: %v", err)
+	}
+	if st.NumGoroutine <= 0 || st.Sys == 0 {
+		t.Fatalf("implausible stats: %+v", st)
+	}
+}
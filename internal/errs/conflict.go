@@ -0,0 +1,24 @@
+package errs
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+// ConflictError is ErrVersionConflict with the item's state on the server,
+// so a client can rebase without reading the item first.
+type ConflictError struct {
+	Index     int // position of the item in its batch
+	ID        uuid.UUID
+	Ver       int64     // current version, 0 if there is no such item
+	UpdatedAt time.Time // of the current version; zero if there is no such item
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("item[%d]: version conflict (current ver %d)", e.Index, e.Ver)
+}
+
+// Unwrap makes errors.Is(err, ErrVersionConflict) hold.
+func (e *ConflictError) Unwrap() error { return ErrVersionConflict }
@@ -0,0 +1,48 @@
+package errs
+
+// Domain is the google.rpc.ErrorInfo domain of errors returned by the server.
+const Domain = "gophkeeper.v1"
+
+// Reason codes carried in google.rpc.ErrorInfo. They are part of the API:
+// clients branch on them instead of parsing messages, so never rename one.
+const (
+	ReasonInternal           = "INTERNAL"
+	ReasonUnavailable        = "UNAVAILABLE"
+	ReasonInvalidArgument    = "INVALID_ARGUMENT"
+	ReasonUnauthenticated    = "UNAUTHENTICATED"
+	ReasonBadCredentials     = "BAD_CREDENTIALS"
+	ReasonRateLimited        = "RATE_LIMITED"
+	ReasonPermissionDenied   = "PERMISSION_DENIED"
+	ReasonUnknownTenant      = "UNKNOWN_TENANT"
+	ReasonTenantMismatch     = "TENANT_MISMATCH"
+	ReasonUserQuota          = "USER_QUOTA_EXCEEDED"
+	ReasonStorageQuota       = "STORAGE_QUOTA_EXCEEDED"
+	ReasonVersionConflict    = "VERSION_CONFLICT"
+	ReasonNotFound           = "NOT_FOUND"
+	ReasonAlreadyExists      = "ALREADY_EXISTS"
+	ReasonAlreadyInitialized = "DEK_ALREADY_SET"
+	ReasonDEKChanged         = "DEK_CHANGED"
+	ReasonNotConfigured      = "NOT_CONFIGURED"
+	ReasonWebhookLimit       = "WEBHOOK_LIMIT_EXCEEDED"
+	ReasonDeviceLimit        = "DEVICE_LIMIT_EXCEEDED"
+	ReasonThrottled          = "THROTTLED"
+	ReasonItemLimit          = "ITEM_LIMIT_EXCEEDED"
+	ReasonAddressDenied      = "ADDRESS_DENIED"
+	ReasonIPRuleLimit        = "IP_RULE_LIMIT_EXCEEDED"
+	ReasonChallengeRequired  = "CHALLENGE_REQUIRED"
+	ReasonClientTooOld       = "CLIENT_TOO_OLD"
+	ReasonUnsupportedFeature = "UNSUPPORTED_FEATURE"
+	ReasonInsufficientScope  = "INSUFFICIENT_SCOPE"
+	ReasonVaultFrozen        = "VAULT_FROZEN"
+	ReasonAccountDisabled    = "ACCOUNT_DISABLED"
+	ReasonSyncExpired        = "SYNC_SESSION_EXPIRED"
+	ReasonLegalHold          = "LEGAL_HOLD"
+)
+
+// Warning codes carried in gophkeeper.v1.Warning. Like reasons they are
+// part of the API.
+const (
+	WarnItemLimitNear = "ITEM_LIMIT_NEARLY_REACHED"
+	WarnItemLarge     = "ITEM_LARGE"
+	WarnDeprecatedID  = "DEPRECATED_ID"
+)
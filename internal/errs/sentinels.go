@@ -19,4 +19,31 @@ var (
 
 	// ErrAlreadyExists indicates a unique constraint violation (e.g., username taken).
 	ErrAlreadyExists = errors.New("already exists")
+
+	// ErrUnavailable indicates the storage backend is down and calls are failing fast.
+	ErrUnavailable = errors.New("unavailable")
+
+	// ErrInvalidArgument indicates a request that failed validation; the text after it is safe to show clients.
+	ErrInvalidArgument = errors.New("invalid argument")
+
+	// ErrQuotaExceeded indicates a tenant storage or user quota would be exceeded.
+	ErrQuotaExceeded = errors.New("quota exceeded")
+
+	// ErrItemLimit indicates a user would hold more live items than the per-user cap allows.
+	ErrItemLimit = errors.New("item limit exceeded")
+
+	// ErrAddressDenied indicates the client's address is outside an allow list or inside a deny list.
+	ErrAddressDenied = errors.New("address not allowed")
+
+	// ErrNotConfigured indicates a feature this server has turned off.
+	ErrNotConfigured = errors.New("not configured")
+
+	// ErrAccountDisabled indicates an admin has suspended the account.
+	ErrAccountDisabled = errors.New("account disabled")
+
+	// ErrSnapshotGone indicates a past state of the vault can no longer be read.
+	ErrSnapshotGone = errors.New("snapshot gone")
+
+	// ErrLegalHold indicates the operation would remove item history or tombstones the legal hold keeps.
+	ErrLegalHold = errors.New("legal hold")
 )
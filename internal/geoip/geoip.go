@@ -0,0 +1,84 @@
+// Package geoip locates client addresses with a MaxMind DB file, such as
+// GeoLite2-Country or GeoLite2-City, read without MaxMind's libraries. Only
+// the country and, from City databases, the coordinates are used.
+package geoip
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/netip"
+	"os"
+)
+
+// Location is where an address is registered or, roughly, used.
+type Location struct {
+	Country   string // ISO 3166-1 alpha-2, empty if unknown
+	Lat, Lon  float64
+	HasCoords bool // Lat and Lon are set; City databases only
+}
+
+// DB is an opened database. It is read into memory and safe for concurrent use.
+type DB struct{ r *reader }
+
+// Open reads the database at path.
+func Open(path string) (*DB, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	r, err := newReader(buf)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &DB{r: r}, nil
+}
+
+// Lookup returns ip's location; an address the database lacks has a zero one.
+func (db *DB) Lookup(ip netip.Addr) (Location, error) {
+	v, err := db.r.lookup(ip)
+	if err != nil {
+		return Location{}, err
+	}
+	rec, _ := v.(map[string]any)
+	var loc Location
+	loc.Country = isoCode(rec["country"])
+	if loc.Country == "" {
+		loc.Country = isoCode(rec["registered_country"])
+	}
+	if l, ok := rec["location"].(map[string]any); ok {
+		lat, okLat := l["latitude"].(float64)
+		lon, okLon := l["longitude"].(float64)
+		loc.Lat, loc.Lon, loc.HasCoords = lat, lon, okLat && okLon
+	}
+	return loc, nil
+}
+
+// Locate is Lookup for a peer address as gRPC reports it, "ip:port" or a
+// bare ip. Unparsable addresses and lookup errors give a zero Location.
+func (db *DB) Locate(addr string) Location {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	ip, err := netip.ParseAddr(addr)
+	if err != nil {
+		return Location{}
+	}
+	loc, _ := db.Lookup(ip)
+	return loc
+}
+
+func isoCode(v any) string {
+	m, _ := v.(map[string]any)
+	s, _ := m["iso_code"].(string)
+	return s
+}
+
+// DistanceKm is the great-circle distance between two locations with coordinates.
+func DistanceKm(a, b Location) float64 {
+	const earthRadiusKm = 6371
+	rad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat, dLon := rad(b.Lat-a.Lat), rad(b.Lon-a.Lon)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(rad(a.Lat))*math.Cos(rad(b.Lat))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(min(h, 1)))
+}
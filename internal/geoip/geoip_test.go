@@ -0,0 +1,214 @@
+package geoip
+
+import (
+	"encoding/binary"
+	"maps"
+	"math"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"testing"
+)
+
+// enc encodes values in the MaxMind DB data format.
+type enc struct{ buf []byte }
+
+func (e *enc) ctrl(typ, size int) {
+	if typ > 7 {
+		e.buf = append(e.buf, byte(size), byte(typ-7))
+		return
+	}
+	e.buf = append(e.buf, byte(typ<<5|size))
+}
+
+func (e *enc) value(v any) {
+	switch v := v.(type) {
+	case string:
+		if len(v) >= 29 {
+			e.buf = append(e.buf, byte(typeString<<5|29), byte(len(v)-29))
+		} else {
+			e.ctrl(typeString, len(v))
+		}
+		e.buf = append(e.buf, v...)
+	case float64:
+		e.ctrl(typeDouble, 8)
+		e.buf = binary.BigEndian.AppendUint64(e.buf, math.Float64bits(v))
+	case uint32:
+		e.ctrl(typeUint32, 4)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, v)
+	case uint16:
+		e.ctrl(typeUint16, 2)
+		e.buf = binary.BigEndian.AppendUint16(e.buf, v)
+	case uint64:
+		e.ctrl(typeUint64, 8)
+		e.buf = binary.BigEndian.AppendUint64(e.buf, v)
+	case pointer:
+		e.buf = append(e.buf, byte(typePointer<<5|int(v)>>8&7), byte(v))
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		e.ctrl(typeMap, len(v))
+		for _, k := range keys {
+			e.value(k)
+			e.value(v[k])
+		}
+	}
+}
+
+// pointer is a data-section offset below 2048, written in the 1-byte form.
+type pointer int
+
+// buildDB writes a database with 24-bit records mapping each prefix to its
+// record. IPv4 prefixes go under ::/96 when ipVersion is 6.
+func buildDB(t *testing.T, ipVersion int, recs map[string]map[string]any) string {
+	t.Helper()
+	var data enc
+	// a shared value reached through a pointer, as real databases do
+	shared := len(data.buf)
+	data.value(map[string]any{"iso_code": "EU"})
+
+	type child struct {
+		node int // index into nodes, or -1
+		data int // offset+1 into data, or 0
+	}
+	nodes := [][2]child{{{-1, 0}, {-1, 0}}}
+	for cidr, rec := range recs {
+		p := netip.MustParsePrefix(cidr)
+		addr, bits := p.Addr().AsSlice(), p.Bits()
+		if ipVersion == 6 && p.Addr().Is4() {
+			addr, bits = append(make([]byte, 12), addr...), bits+96
+		}
+		off := len(data.buf)
+		rec = maps.Clone(rec)
+		rec["registered_country"] = pointer(shared)
+		data.value(rec)
+		n := 0
+		for i := range bits {
+			bit := addr[i/8] >> (7 - i%8) & 1
+			if i == bits-1 {
+				nodes[n][bit] = child{-1, off + 1}
+				break
+			}
+			if nodes[n][bit].node < 0 {
+				nodes = append(nodes, [2]child{{-1, 0}, {-1, 0}})
+				nodes[n][bit] = child{len(nodes) - 1, 0}
+			}
+			n = nodes[n][bit].node
+		}
+	}
+
+	count := len(nodes)
+	var file []byte
+	for _, nd := range nodes {
+		for _, c := range nd {
+			v := count // empty
+			switch {
+			case c.node >= 0:
+				v = c.node
+			case c.data > 0:
+				v = count + 16 + c.data - 1
+			}
+			file = append(file, byte(v>>16), byte(v>>8), byte(v))
+		}
+	}
+	file = append(file, make([]byte, 16)...)
+	file = append(file, data.buf...)
+	file = append(file, metaMarker...)
+	var meta enc
+	meta.value(map[string]any{
+		"node_count":    uint32(count),
+		"record_size":   uint16(24),
+		"ip_version":    uint16(ipVersion),
+		"database_type": "GeoLite2-City-Test-With-A-Long-Name",
+		"build_epoch":   uint64(1767225600),
+	})
+	file = append(file, meta.buf...)
+
+	path := filepath.Join(t.TempDir(), "test.mmdb")
+	if err := os.WriteFile(path, file, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func testRecords() map[string]map[string]any {
+	return map[string]map[string]any{
+		"81.2.69.0/24": {
+			"country":  map[string]any{"iso_code": "GB"},
+			"location": map[string]any{"latitude": 51.5, "longitude": -0.13},
+		},
+		"175.16.199.0/24": {"country": map[string]any{"iso_code": "CN"}},
+		"2001:db8::/32":   {"country": map[string]any{"iso_code": "SE"}},
+	}
+}
+
+func TestLookup(t *testing.T) {
+	t.Parallel()
+	for _, v := range []int{4, 6} {
+		db, err := Open(buildDB(t, v, testRecords()))
+		if err != nil {
+			t.Fatalf("ipv%d: %v", v, err)
+		}
+		if loc := db.Locate("81.2.69.160:50123"); loc.Country != "GB" || !loc.HasCoords || loc.Lat != 51.5 {
+			t.Fatalf("ipv%d GB: %+v", v, loc)
+		}
+		if loc := db.Locate("::ffff:175.16.199.7"); loc.Country != "CN" || loc.HasCoords {
+			t.Fatalf("ipv%d CN: %+v", v, loc)
+		}
+		if loc := db.Locate("8.8.8.8"); loc != (Location{}) {
+			t.Fatalf("ipv%d unknown address: %+v", v, loc)
+		}
+		if loc := db.Locate("not an address"); loc != (Location{}) {
+			t.Fatalf("ipv%d junk: %+v", v, loc)
+		}
+		want := ""
+		if v == 6 {
+			want = "SE"
+		}
+		if loc := db.Locate("[2001:db8::1]:443"); loc.Country != want {
+			t.Fatalf("ipv%d v6 address: %+v", v, loc)
+		}
+	}
+}
+
+func TestLookup_RegisteredCountryFallback(t *testing.T) {
+	t.Parallel()
+	db, err := Open(buildDB(t, 6, map[string]map[string]any{"10.0.0.0/8": {}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loc := db.Locate("10.1.2.3"); loc.Country != "EU" {
+		t.Fatalf("registered country through a pointer: %+v", loc)
+	}
+}
+
+func TestOpen_Malformed(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "bad.mmdb")
+	_ = os.WriteFile(path, []byte("not a database"), 0o600)
+	if _, err := Open(path); err == nil {
+		t.Fatal("opened a file without metadata")
+	}
+	var meta enc
+	meta.value(map[string]any{"node_count": uint32(1000), "record_size": uint16(24), "ip_version": uint16(4)})
+	if _, err := newReader(append(slices.Clone(metaMarker), meta.buf...)); err == nil {
+		t.Fatal("opened a file without its search tree")
+	}
+}
+
+func TestDistanceKm(t *testing.T) {
+	t.Parallel()
+	london := Location{Lat: 51.5, Lon: -0.13, HasCoords: true}
+	paris := Location{Lat: 48.86, Lon: 2.35, HasCoords: true}
+	if d := DistanceKm(london, paris); d < 330 || d > 360 {
+		t.Fatalf("London-Paris %.0f km", d)
+	}
+	if d := DistanceKm(paris, paris); d != 0 {
+		t.Fatalf("same place %.3f km", d)
+	}
+}
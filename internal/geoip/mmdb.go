@@ -0,0 +1,264 @@
+package geoip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net/netip"
+)
+
+// A MaxMind DB file is a binary search tree over address bits, a 16-byte
+// separator, a data section and, after metaMarker, a metadata map. See
+// https://maxmind.github.io/MaxMind-DB/ for the format.
+
+var metaMarker = []byte("\xab\xcd\xefMaxMind.com")
+
+// maxDepth bounds nested maps, arrays and pointers in a malformed file.
+const maxDepth = 32
+
+var errFormat = errors.New("geoip: malformed database")
+
+// reader looks up records in a MaxMind DB held in memory.
+type reader struct {
+	tree       []byte
+	data       []byte
+	nodeCount  uint32
+	recordSize int
+	ipVersion  int
+	ipv4Start  uint32 // node reached after the 96 zero bits of ::/96
+}
+
+func newReader(buf []byte) (*reader, error) {
+	i := bytes.LastIndex(buf, metaMarker)
+	if i < 0 {
+		return nil, fmt.Errorf("%w: no metadata", errFormat)
+	}
+	v, _, err := decoder(buf[i+len(metaMarker):]).decode(0, 0)
+	if err != nil {
+		return nil, err
+	}
+	meta, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: metadata is not a map", errFormat)
+	}
+	r := &reader{
+		nodeCount:  uint32(asUint(meta["node_count"])),
+		recordSize: int(asUint(meta["record_size"])),
+		ipVersion:  int(asUint(meta["ip_version"])),
+	}
+	switch {
+	case r.recordSize != 24 && r.recordSize != 28 && r.recordSize != 32:
+		return nil, fmt.Errorf("%w: record size %d", errFormat, r.recordSize)
+	case r.ipVersion != 4 && r.ipVersion != 6:
+		return nil, fmt.Errorf("%w: ip version %d", errFormat, r.ipVersion)
+	}
+	treeSize := int(r.nodeCount) * r.recordSize / 4
+	if treeSize+16 > i {
+		return nil, fmt.Errorf("%w: search tree overruns the file", errFormat)
+	}
+	r.tree, r.data = buf[:treeSize], buf[treeSize+16:i]
+	if r.ipVersion == 6 {
+		for n := 0; n < 96 && r.ipv4Start < r.nodeCount; n++ {
+			r.ipv4Start = r.record(r.ipv4Start, 0)
+		}
+	}
+	return r, nil
+}
+
+// lookup returns the record for ip, nil if the database has none.
+func (r *reader) lookup(ip netip.Addr) (any, error) {
+	ip = ip.Unmap()
+	node, bits := uint32(0), 128
+	switch {
+	case ip.Is4() && r.ipVersion == 6:
+		node, bits = r.ipv4Start, 32
+	case ip.Is4():
+		bits = 32
+	case r.ipVersion == 4:
+		return nil, nil
+	}
+	addr := ip.AsSlice()
+	for i := 0; i < bits && node < r.nodeCount; i++ {
+		bit := addr[i/8] >> (7 - i%8) & 1
+		node = r.record(node, int(bit))
+	}
+	if node <= r.nodeCount {
+		return nil, nil // no record, or the tree ran out of bits
+	}
+	off := int(node-r.nodeCount) - 16
+	if off < 0 || off >= len(r.data) {
+		return nil, fmt.Errorf("%w: record points outside the data section", errFormat)
+	}
+	v, _, err := decoder(r.data).decode(off, 0)
+	return v, err
+}
+
+// record reads the left (0) or right (1) record of node.
+func (r *reader) record(node uint32, side int) uint32 {
+	size := r.recordSize / 4 // bytes per node
+	b := r.tree[int(node)*size : int(node+1)*size]
+	switch r.recordSize {
+	case 24:
+		b = b[side*3:]
+		return uint32(b[0])<<16 | uint32(b[1])<<8 | uint32(b[2])
+	case 28:
+		if side == 0 {
+			return uint32(b[3]&0xf0)<<20 | uint32(b[0])<<16 | uint32(b[1])<<8 | uint32(b[2])
+		}
+		return uint32(b[3]&0x0f)<<24 | uint32(b[4])<<16 | uint32(b[5])<<8 | uint32(b[6])
+	default:
+		return binary.BigEndian.Uint32(b[side*4:])
+	}
+}
+
+// Data section types.
+const (
+	typePointer = 1
+	typeString  = 2
+	typeDouble  = 3
+	typeBytes   = 4
+	typeUint16  = 5
+	typeUint32  = 6
+	typeMap     = 7
+	typeInt32   = 8
+	typeUint64  = 9
+	typeUint128 = 10
+	typeArray   = 11
+	typeBool    = 14
+	typeFloat   = 15
+)
+
+// decoder reads values from a data section; pointers are offsets into it.
+type decoder []byte
+
+// decode returns the value at off and the offset just past it.
+func (d decoder) decode(off, depth int) (any, int, error) {
+	if depth > maxDepth {
+		return nil, 0, fmt.Errorf("%w: nested too deep", errFormat)
+	}
+	if off >= len(d) {
+		return nil, 0, fmt.Errorf("%w: offset %d past the data", errFormat, off)
+	}
+	ctrl := d[off]
+	off++
+	typ := int(ctrl >> 5)
+	if typ == typePointer {
+		return d.pointer(ctrl, off, depth)
+	}
+	if typ == 0 { // extended type in the next byte
+		if off >= len(d) {
+			return nil, 0, errFormat
+		}
+		typ = 7 + int(d[off])
+		off++
+	}
+	size := int(ctrl & 0x1f)
+	if size >= 29 {
+		n := size - 28 // 1, 2 or 3 more bytes
+		if off+n > len(d) {
+			return nil, 0, errFormat
+		}
+		ext := 0
+		for _, b := range d[off : off+n] {
+			ext = ext<<8 | int(b)
+		}
+		off += n
+		size = []int{0, 29, 285, 65821}[n] + ext
+	}
+
+	switch typ {
+	case typeMap:
+		m := make(map[string]any, size)
+		for range size {
+			k, next, err := d.decode(off, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			key, ok := k.(string)
+			if !ok {
+				return nil, 0, fmt.Errorf("%w: map key is not a string", errFormat)
+			}
+			v, next, err := d.decode(next, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			m[key], off = v, next
+		}
+		return m, off, nil
+	case typeArray:
+		a := make([]any, 0, size)
+		for range size {
+			v, next, err := d.decode(off, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			a, off = append(a, v), next
+		}
+		return a, off, nil
+	case typeBool:
+		return size != 0, off, nil
+	}
+
+	if off+size > len(d) {
+		return nil, 0, fmt.Errorf("%w: value overruns the data", errFormat)
+	}
+	b := d[off : off+size]
+	off += size
+	switch typ {
+	case typeString:
+		return string(b), off, nil
+	case typeBytes:
+		return bytes.Clone(b), off, nil
+	case typeDouble:
+		if size != 8 {
+			return nil, 0, errFormat
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), off, nil
+	case typeFloat:
+		if size != 4 {
+			return nil, 0, errFormat
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), off, nil
+	case typeUint16, typeUint32, typeUint64, typeInt32:
+		if size > 8 {
+			return nil, 0, errFormat
+		}
+		var u uint64
+		for _, c := range b {
+			u = u<<8 | uint64(c)
+		}
+		if typ == typeInt32 {
+			return int64(int32(u)), off, nil
+		}
+		return u, off, nil
+	case typeUint128:
+		return bytes.Clone(b), off, nil // nothing here needs its value
+	}
+	return nil, 0, fmt.Errorf("%w: type %d", errFormat, typ)
+}
+
+// pointer follows the pointer whose control byte is ctrl; the returned
+// offset is past the pointer itself, not past what it points to.
+func (d decoder) pointer(ctrl byte, off, depth int) (any, int, error) {
+	n := int(ctrl>>3&3) + 1
+	if off+n > len(d) {
+		return nil, 0, errFormat
+	}
+	p := 0
+	if n < 4 {
+		p = int(ctrl & 7)
+	}
+	for _, b := range d[off : off+n] {
+		p = p<<8 | int(b)
+	}
+	p += []int{0, 0, 2048, 526336, 0}[n]
+	v, _, err := d.decode(p, depth+1)
+	return v, off + n, err
+}
+
+func asUint(v any) uint64 {
+	u, _ := v.(uint64)
+	return u
+}
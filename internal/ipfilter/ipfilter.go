@@ -0,0 +1,172 @@
+// Package ipfilter decides which client addresses may call the server, from
+// CIDR allow and deny lists: the server's own and each user's.
+package ipfilter
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/gofrs/uuid/v5"
+)
+
+// metrics are published under the "ipfilter" expvar (see /debug/vars).
+var metrics = expvar.NewMap("ipfilter")
+
+// List is a set of address rules. A deny entry always wins; a non-empty
+// allow list admits only the addresses it covers.
+type List struct {
+	Allow []netip.Prefix
+	Deny  []netip.Prefix
+}
+
+// Parse reads comma-separated CIDRs; a bare address is a single-host prefix.
+func Parse(allow, deny string) (List, error) {
+	var l List
+	var err error
+	if l.Allow, err = parsePrefixes(allow); err != nil {
+		return List{}, err
+	}
+	if l.Deny, err = parsePrefixes(deny); err != nil {
+		return List{}, err
+	}
+	return l, nil
+}
+
+func parsePrefixes(s string) ([]netip.Prefix, error) {
+	var out []netip.Prefix
+	for _, f := range strings.Split(s, ",") {
+		if f = strings.TrimSpace(f); f == "" {
+			continue
+		}
+		p, err := ParsePrefix(f)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// ParsePrefix reads one CIDR or address, masked to its network.
+func ParsePrefix(s string) (netip.Prefix, error) {
+	if !strings.Contains(s, "/") {
+		a, err := netip.ParseAddr(s)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("address rule %q: %w", s, err)
+		}
+		a = a.Unmap()
+		return netip.PrefixFrom(a, a.BitLen()), nil
+	}
+	p, err := netip.ParsePrefix(s)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("address rule %q: %w", s, err)
+	}
+	if p.Addr().Is4In6() && p.Bits() >= 96 {
+		p = netip.PrefixFrom(p.Addr().Unmap(), p.Bits()-96)
+	}
+	return p.Masked(), nil
+}
+
+// FromRules builds a List from a user's stored rules.
+func FromRules(rules []model.IPRule) List {
+	var l List
+	for _, r := range rules {
+		if r.Allow {
+			l.Allow = append(l.Allow, r.CIDR)
+		} else {
+			l.Deny = append(l.Deny, r.CIDR)
+		}
+	}
+	return l
+}
+
+// Empty reports whether l admits every address.
+func (l List) Empty() bool { return len(l.Allow) == 0 && len(l.Deny) == 0 }
+
+// Permits reports whether l admits addr. An invalid address, such as a
+// peer that is not on IP, is admitted only by an empty list.
+func (l List) Permits(addr netip.Addr) bool {
+	if !addr.IsValid() {
+		return l.Empty()
+	}
+	addr = addr.Unmap()
+	for _, p := range l.Deny {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+	if len(l.Allow) == 0 {
+		return true
+	}
+	for _, p := range l.Allow {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// PeerAddr parses a peer address as gRPC reports it, "ip:port" or a bare
+// ip; anything else gives the invalid Addr.
+func PeerAddr(peer string) netip.Addr {
+	if host, _, err := net.SplitHostPort(peer); err == nil {
+		peer = host
+	}
+	a, _ := netip.ParseAddr(peer)
+	return a
+}
+
+// RuleSource looks up users' own rules; *postgres.IPRuleRepo implements it.
+type RuleSource interface {
+	Rules(ctx context.Context, userID uuid.UUID) ([]model.IPRule, error)
+	RulesByUsername(ctx context.Context, username string) ([]model.IPRule, error)
+}
+
+// Filter applies the server's list to every call and, when Users is set,
+// the calling user's list too. A user's rules can narrow the server's but
+// not widen them.
+type Filter struct {
+	Global List
+	Users  RuleSource
+}
+
+// Check admits or refuses a call from addr. The user is userID when the
+// call carries a token, otherwise the username it names (Login), otherwise
+// unknown and only the server's list applies. A refusal is
+// errs.ErrAddressDenied; a failed rule lookup is returned as is, so a user
+// locked to a network is never let in by an outage.
+func (f *Filter) Check(ctx context.Context, addr netip.Addr, userID uuid.UUID, username string) error {
+	if !f.Global.Permits(addr) {
+		metrics.Add("denied_global", 1)
+		return errs.ErrAddressDenied
+	}
+	if f.Users == nil {
+		return nil
+	}
+	var (
+		rules []model.IPRule
+		err   error
+	)
+	switch {
+	case userID != uuid.Nil:
+		rules, err = f.Users.Rules(ctx, userID)
+	case username != "":
+		rules, err = f.Users.RulesByUsername(ctx, username)
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !FromRules(rules).Permits(addr) {
+		metrics.Add("denied_user", 1)
+		return errs.ErrAddressDenied
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package ipfilter
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"slices"
+	"testing"
+
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/gofrs/uuid/v5"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+	l, err := Parse(" 10.0.0.0/8, 192.168.1.7 ,::ffff:172.16.0.0/108", "10.66.0.0/16,2001:db8::1/32")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := List{
+		Allow: []netip.Prefix{
+			netip.MustParsePrefix("10.0.0.0/8"),
+			netip.MustParsePrefix("192.168.1.7/32"),
+			netip.MustParsePrefix("172.16.0.0/12"),
+		},
+		Deny: []netip.Prefix{
+			netip.MustParsePrefix("10.66.0.0/16"),
+			netip.MustParsePrefix("2001:db8::/32"),
+		},
+	}
+	if !slices.Equal(l.Allow, want.Allow) || !slices.Equal(l.Deny, want.Deny) {
+		t.Fatalf("parsed %+v, want %+v", l, want)
+	}
+	if _, err := Parse("10.0.0.0/33", ""); err == nil {
+		t.Fatal("accepted a bad prefix")
+	}
+	if l, err := Parse("", " , "); err != nil || !l.Empty() {
+		t.Fatalf("empty lists: %+v %v", l, err)
+	}
+}
+
+func TestList_Permits(t *testing.T) {
+	t.Parallel()
+	l, _ := Parse("10.0.0.0/8", "10.66.0.0/16")
+	for addr, want := range map[string]bool{
+		"10.1.2.3":        true,
+		"::ffff:10.1.2.3": true,
+		"10.66.0.1":       false,
+		"192.0.2.1":       false,
+	} {
+		if got := l.Permits(netip.MustParseAddr(addr)); got != want {
+			t.Errorf("%s: got %v", addr, got)
+		}
+	}
+	if l.Permits(netip.Addr{}) || !(List{}).Permits(netip.Addr{}) {
+		t.Fatal("an invalid address passes only an empty list")
+	}
+	denyOnly, _ := Parse("", "192.0.2.0/24")
+	if !denyOnly.Permits(netip.MustParseAddr("198.51.100.1")) || denyOnly.Permits(netip.MustParseAddr("192.0.2.9")) {
+		t.Fatal("deny-only list")
+	}
+}
+
+func TestPeerAddr(t *testing.T) {
+	t.Parallel()
+	if a := PeerAddr("203.0.113.7:51000"); a != netip.MustParseAddr("203.0.113.7") {
+		t.Fatalf("ip:port: %v", a)
+	}
+	if a := PeerAddr("[2001:db8::1]:443"); a != netip.MustParseAddr("2001:db8::1") {
+		t.Fatalf("[ip6]:port: %v", a)
+	}
+	if a := PeerAddr("bufconn"); a.IsValid() {
+		t.Fatalf("not an address: %v", a)
+	}
+}
+
+type fakeRules struct {
+	byID   map[uuid.UUID][]model.IPRule
+	byName map[string][]model.IPRule
+	err    error
+}
+
+func (f fakeRules) Rules(_ context.Context, id uuid.UUID) ([]model.IPRule, error) {
+	return f.byID[id], f.err
+}
+func (f fakeRules) RulesByUsername(_ context.Context, name string) ([]model.IPRule, error) {
+	return f.byName[name], f.err
+}
+
+func TestFilter_Check(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	alice := uuid.Must(uuid.NewV4())
+	vpn := []model.IPRule{{CIDR: netip.MustParsePrefix("10.8.0.0/16"), Allow: true}}
+	global, _ := Parse("", "198.51.100.0/24")
+	f := &Filter{Global: global, Users: fakeRules{
+		byID:   map[uuid.UUID][]model.IPRule{alice: vpn},
+		byName: map[string][]model.IPRule{"alice": vpn},
+	}}
+	home, office := netip.MustParseAddr("192.0.2.1"), netip.MustParseAddr("10.8.1.1")
+
+	if err := f.Check(ctx, netip.MustParseAddr("198.51.100.5"), uuid.Nil, ""); !errors.Is(err, errs.ErrAddressDenied) {
+		t.Fatalf("globally denied: %v", err)
+	}
+	if err := f.Check(ctx, home, uuid.Nil, ""); err != nil {
+		t.Fatalf("anonymous call: %v", err)
+	}
+	if err := f.Check(ctx, home, alice, ""); !errors.Is(err, errs.ErrAddressDenied) {
+		t.Fatalf("token outside the user's network: %v", err)
+	}
+	if err := f.Check(ctx, home, uuid.Nil, "alice"); !errors.Is(err, errs.ErrAddressDenied) {
+		t.Fatalf("login outside the user's network: %v", err)
+	}
+	if err := f.Check(ctx, office, alice, ""); err != nil {
+		t.Fatalf("inside the user's network: %v", err)
+	}
+	if err := f.Check(ctx, home, uuid.Nil, "bob"); err != nil {
+		t.Fatalf("user without rules: %v", err)
+	}
+
+	down := errors.New("db down")
+	f.Users = fakeRules{err: down}
+	if err := f.Check(ctx, office, alice, ""); !errors.Is(err, down) {
+		t.Fatalf("lookup failure must not admit: %v", err)
+	}
+}
@@ -10,8 +10,20 @@ import (
 type Limiter interface {
 	// Allow reports whether login is currently allowed and optional retry-after.
 	Allow(ctx context.Context, username string, ipHash []byte) (bool, time.Duration, error)
-	// Success resets counters after a successful login.
-	Success(ctx context.Context, username string, ipHash []byte) error
+	// Success resets counters after a successful login and reports the
+	// failed attempts on username, from any ip, since its previous success.
+	Success(ctx context.Context, username string, ipHash []byte) (int, error)
 	// Failure records a failed attempt; may place a temporary block.
 	Failure(ctx context.Context, username string, ipHash []byte) (bool, time.Duration, error)
 }
+
+// Lock is a (username, ip) pair barred from logging in.
+type Lock struct {
+	Username     string // as passed to Failure
+	IPHash       []byte
+	Fails        int
+	BlockedUntil time.Time
+}
+
+// maxLocks caps a Locks listing.
+const maxLocks = 1000
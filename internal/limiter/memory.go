@@ -0,0 +1,137 @@
+package limiter
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+type memKey struct {
+	username string
+	ipHash   string
+}
+
+type memEntry struct {
+	fails        int
+	unseen       int // failures not yet reported by Success
+	blockedUntil time.Time
+	updated      time.Time
+}
+
+// Memory is an in-process limiter with the same rules as PG. Its state is
+// per process and lost on restart.
+type Memory struct {
+	window   time.Duration
+	maxFails int
+	blockFor time.Duration
+
+	mu      sync.Mutex
+	entries map[memKey]*memEntry
+}
+
+// NewMemory constructs an in-memory limiter.
+func NewMemory(window time.Duration, maxFails int, blockFor time.Duration) *Memory {
+	return &Memory{window: window, maxFails: maxFails, blockFor: blockFor, entries: make(map[memKey]*memEntry)}
+}
+
+// Allow reports whether login is currently allowed and a retry-after duration.
+func (l *Memory) Allow(_ context.Context, username string, ipHash []byte) (bool, time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e, ok := l.entries[memKey{username, string(ipHash)}]
+	if ok {
+		if wait := time.Until(e.blockedUntil); wait > 0 {
+			return false, wait, nil
+		}
+	}
+	return true, 0, nil
+}
+
+// Success forgets the failures of (username, ip) and counts those of
+// username since the last success.
+func (l *Memory) Success(_ context.Context, username string, ipHash []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	n := 0
+	for k, e := range l.entries {
+		if k.username == username {
+			n += e.unseen
+			e.unseen = 0
+		}
+	}
+	delete(l.entries, memKey{username, string(ipHash)})
+	return n, nil
+}
+
+// Unseen counts the failures of username since its last success.
+func (l *Memory) Unseen(_ context.Context, username string) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	n := 0
+	for k, e := range l.entries {
+		if k.username == username {
+			n += e.unseen
+		}
+	}
+	return n, nil
+}
+
+// Failure records a failed attempt; the count restarts after a quiet window.
+func (l *Memory) Failure(_ context.Context, username string, ipHash []byte) (bool, time.Duration, error) {
+	now := time.Now()
+	k := memKey{username, string(ipHash)}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e, ok := l.entries[k]
+	if !ok {
+		e = &memEntry{}
+		l.entries[k] = e
+	}
+	if now.Sub(e.updated) > l.window {
+		e.fails = 0
+	}
+	e.fails++
+	e.unseen++
+	e.updated = now
+	if e.fails >= l.maxFails {
+		e.blockedUntil = now.Add(l.blockFor)
+		return true, l.blockFor, nil
+	}
+	return false, 0, nil
+}
+
+// Locks lists the pairs blocked now, latest to expire first.
+func (l *Memory) Locks(context.Context) ([]Lock, error) {
+	now := time.Now()
+	l.mu.Lock()
+	var locks []Lock
+	for k, e := range l.entries {
+		if e.blockedUntil.After(now) {
+			locks = append(locks, Lock{Username: k.username, IPHash: []byte(k.ipHash), Fails: e.fails, BlockedUntil: e.blockedUntil})
+		}
+	}
+	l.mu.Unlock()
+	slices.SortFunc(locks, func(a, b Lock) int { return b.BlockedUntil.Compare(a.BlockedUntil) })
+	if len(locks) > maxLocks {
+		locks = locks[:maxLocks]
+	}
+	return locks, nil
+}
+
+// Clear lifts the blocks on username from ipHash, or from every ip if
+// ipHash is empty, and reports how many it lifted.
+func (l *Memory) Clear(_ context.Context, username string, ipHash []byte) (int, error) {
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	n := 0
+	for k, e := range l.entries {
+		if k.username != username || len(ipHash) > 0 && k.ipHash != string(ipHash) || !e.blockedUntil.After(now) {
+			continue
+		}
+		e.fails, e.blockedUntil = 0, time.Time{}
+		n++
+	}
+	return n, nil
+}
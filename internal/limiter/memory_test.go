@@ -0,0 +1,89 @@
+package limiter
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemory_LockoutAndReset(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	l := NewMemory(time.Minute, 3, time.Hour)
+	ip := HashIP("10.0.0.1")
+
+	for i := 1; i < 3; i++ {
+		if blocked, _, _ := l.Failure(ctx, "bob", ip); blocked {
+			t.Fatalf("blocked after %d failures", i)
+		}
+	}
+	blocked, d, err := l.Failure(ctx, "bob", ip)
+	if !blocked || d != time.Hour || err != nil {
+		t.Fatalf("third failure: %v %s %v", blocked, d, err)
+	}
+	if ok, wait, _ := l.Allow(ctx, "bob", ip); ok || wait <= 0 {
+		t.Fatalf("allowed while blocked: %v %s", ok, wait)
+	}
+	if ok, _, _ := l.Allow(ctx, "bob", HashIP("10.0.0.2")); !ok {
+		t.Fatal("another address must not be blocked")
+	}
+
+	_, _, _ = l.Failure(ctx, "bob", HashIP("10.0.0.2"))
+	if n, _ := l.Unseen(ctx, "bob"); n != 4 {
+		t.Fatalf("unseen %d, want 4", n)
+	}
+	if n, _ := l.Success(ctx, "bob", ip); n != 4 {
+		t.Fatalf("success reported %d failures, want 4 from both addresses", n)
+	}
+	if ok, _, _ := l.Allow(ctx, "bob", ip); !ok {
+		t.Fatal("still blocked after success")
+	}
+	if n, _ := l.Success(ctx, "bob", ip); n != 0 {
+		t.Fatalf("failures reported twice: %d", n)
+	}
+	if n, _ := l.Unseen(ctx, "bob"); n != 0 {
+		t.Fatalf("unseen %d after success", n)
+	}
+}
+
+func TestMemory_LocksAndClear(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	l := NewMemory(time.Minute, 1, time.Hour)
+	a, b := HashIP("10.0.0.1"), HashIP("10.0.0.2")
+	_, _, _ = l.Failure(ctx, "bob", a)
+	_, _, _ = l.Failure(ctx, "bob", b)
+	_, _, _ = l.Failure(ctx, "eve", a)
+
+	locks, _ := l.Locks(ctx)
+	if len(locks) != 3 || locks[0].Fails != 1 || locks[0].BlockedUntil.Before(locks[2].BlockedUntil) {
+		t.Fatalf("locks: %+v", locks)
+	}
+	if n, _ := l.Clear(ctx, "bob", a); n != 1 {
+		t.Fatalf("cleared %d, want 1", n)
+	}
+	if ok, _, _ := l.Allow(ctx, "bob", a); !ok {
+		t.Fatal("still blocked after clear")
+	}
+	if n, _ := l.Clear(ctx, "bob", nil); n != 1 {
+		t.Fatalf("cleared %d, want the other address", n)
+	}
+	if locks, _ := l.Locks(ctx); len(locks) != 1 || locks[0].Username != "eve" {
+		t.Fatalf("locks after clear: %+v", locks)
+	}
+	if n, _ := l.Success(ctx, "bob", a); n != 2 {
+		t.Fatalf("clear must keep the count for the next login, got %d", n)
+	}
+}
+
+func TestMemory_WindowRestartsCount(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	l := NewMemory(time.Minute, 2, time.Hour)
+	ip := HashIP("10.0.0.1")
+	_, _, _ = l.Failure(ctx, "bob", ip)
+	l.entries[memKey{"bob", string(ip)}].updated = time.Now().Add(-2 * time.Minute)
+	if blocked, _, _ := l.Failure(ctx, "bob", ip); blocked {
+		t.Fatal("a failure outside the window must start a new count")
+	}
+}
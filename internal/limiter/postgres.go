@@ -21,6 +21,7 @@ type PG struct {
 type pgxQuerier interface {
 	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
 	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
 }
 
 // NewPG constructs a PostgreSQL-backed limiter.
@@ -60,15 +61,36 @@ func (l *PG) Allow(ctx context.Context, username string, ipHash []byte) (bool, t
 	}
 }
 
-// Success resets counters for (username, ip).
-func (l *PG) Success(ctx context.Context, username string, ipHash []byte) error {
+// Success resets counters for (username, ip) and counts the failures of
+// username since the last success.
+func (l *PG) Success(ctx context.Context, username string, ipHash []byte) (int, error) {
 	const q = `
 INSERT INTO auth_limiter (username, ip_hash, fail_count, blocked_until, updated_at)
 VALUES ($1,$2,0,'epoch',now())
 ON CONFLICT (username, ip_hash)
 DO UPDATE SET fail_count=0, blocked_until='epoch', updated_at=now()`
-	_, err := l.pool.Exec(ctx, q, username, ipHash)
-	return err
+	if _, err := l.pool.Exec(ctx, q, username, ipHash); err != nil {
+		return 0, err
+	}
+	const seen = `
+WITH seen AS (
+  UPDATE auth_limiter a SET unseen_fails=0
+  FROM (SELECT ip_hash, unseen_fails FROM auth_limiter WHERE username=$1 AND unseen_fails>0 FOR UPDATE) o
+  WHERE a.username=$1 AND a.ip_hash=o.ip_hash
+  RETURNING o.unseen_fails
+)
+SELECT COALESCE(sum(unseen_fails), 0)::int FROM seen`
+	var n int
+	err := l.pool.QueryRow(ctx, seen, username).Scan(&n)
+	return n, err
+}
+
+// Unseen counts the failures of username since its last success.
+func (l *PG) Unseen(ctx context.Context, username string) (int, error) {
+	const q = `SELECT COALESCE(sum(unseen_fails), 0)::int FROM auth_limiter WHERE username=$1`
+	var n int
+	err := l.pool.QueryRow(ctx, q, username).Scan(&n)
+	return n, err
 }
 
 // Failure records a failed attempt; may set a block until a future time.
@@ -76,11 +98,12 @@ func (l *PG) Failure(ctx context.Context, username string, ipHash []byte) (bool,
 	now := time.Now()
 
 	const q = `
-INSERT INTO auth_limiter (username, ip_hash, fail_count, blocked_until, updated_at)
-VALUES ($1,$2,1,'epoch',now())
+INSERT INTO auth_limiter (username, ip_hash, fail_count, unseen_fails, blocked_until, updated_at)
+VALUES ($1,$2,1,1,'epoch',now())
 ON CONFLICT (username, ip_hash) DO UPDATE
 SET
   fail_count = CASE WHEN EXCLUDED.updated_at - auth_limiter.updated_at > $3::interval THEN 1 ELSE auth_limiter.fail_count + 1 END,
+  unseen_fails = auth_limiter.unseen_fails + 1,
   updated_at = now()
 RETURNING fail_count`
 	var fails int
@@ -97,3 +120,37 @@ RETURNING fail_count`
 	}
 	return false, 0, nil
 }
+
+// Locks lists the pairs blocked now, latest to expire first.
+func (l *PG) Locks(ctx context.Context) ([]Lock, error) {
+	const q = `
+SELECT username, ip_hash, fail_count, blocked_until FROM auth_limiter
+WHERE blocked_until > now() ORDER BY blocked_until DESC LIMIT $1`
+	rows, err := l.pool.Query(ctx, q, maxLocks)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var locks []Lock
+	for rows.Next() {
+		var lk Lock
+		if err := rows.Scan(&lk.Username, &lk.IPHash, &lk.Fails, &lk.BlockedUntil); err != nil {
+			return nil, err
+		}
+		locks = append(locks, lk)
+	}
+	return locks, rows.Err()
+}
+
+// Clear lifts the blocks on username from ipHash, or from every ip if
+// ipHash is empty, and reports how many it lifted.
+func (l *PG) Clear(ctx context.Context, username string, ipHash []byte) (int, error) {
+	const q = `
+UPDATE auth_limiter SET fail_count=0, blocked_until='epoch'
+WHERE username=$1 AND ($2::bytea IS NULL OR ip_hash=$2) AND blocked_until > now()`
+	if len(ipHash) == 0 {
+		ipHash = nil
+	}
+	tag, err := l.pool.Exec(ctx, q, username, ipHash)
+	return int(tag.RowsAffected()), err
+}
@@ -20,14 +20,20 @@ type fakePool struct {
 	qrBlockedTill *time.Time
 	qrUpdatedAt   time.Time
 	qrFailsRet    int
+	qrUnseen      int
 
-	lastExecSQL string
-	execErr     error
+	lastExecSQL  string
+	lastExecArgs []any
+	execErr      error
 }
 
 func (f *fakePool) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
-	f.lastExecSQL = sql
-	return pgconn.CommandTag{}, f.execErr
+	f.lastExecSQL, f.lastExecArgs = sql, args
+	return pgconn.NewCommandTag("UPDATE 2"), f.execErr
+}
+
+func (f *fakePool) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return nil, errors.New("unexpected query")
 }
 
 func (f *fakePool) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
@@ -48,6 +54,21 @@ func (f *fakePool) QueryRow(ctx context.Context, sql string, args ...any) pgx.Ro
 			return nil
 		}}
 
+	case contains(sql, "unseen_fails=0"):
+		return fakeRow{scan: func(dest ...any) error {
+			*(dest[0].(*int)) = f.qrUnseen
+			return nil
+		}}
+
+	case contains(sql, "sum(unseen_fails)"):
+		return fakeRow{scan: func(dest ...any) error {
+			if f.qrErr != nil {
+				return f.qrErr
+			}
+			*(dest[0].(*int)) = f.qrUnseen
+			return nil
+		}}
+
 	case contains(sql, "RETURNING fail_count"):
 		return fakeRow{scan: func(dest ...any) error {
 			if f.qrErr != nil {
@@ -119,23 +140,35 @@ func TestSuccess_ExecError_Propagates(t *testing.T) {
 	fp := &fakePool{execErr: errors.New("exec fail")}
 	l := NewPGWithQuerier(fp, 15*time.Minute, 5, 15*time.Minute)
 
-	if err := l.Success(context.Background(), "u", []byte("h")); err == nil {
+	if _, err := l.Success(context.Background(), "u", []byte("h")); err == nil {
 		t.Fatalf("want exec error")
 	}
 }
 
 func TestSuccess_OK(t *testing.T) {
-	fp := &fakePool{}
+	fp := &fakePool{qrUnseen: 3}
 	l := NewPGWithQuerier(fp, 15*time.Minute, 5, 15*time.Minute)
 
-	if err := l.Success(context.Background(), "u", []byte("h")); err != nil {
-		t.Fatalf("success err: %v", err)
+	if n, err := l.Success(context.Background(), "u", []byte("h")); err != nil || n != 3 {
+		t.Fatalf("success: n=%d err=%v", n, err)
 	}
 	if !contains(fp.lastExecSQL, "INSERT INTO auth_limiter") {
 		t.Fatalf("unexpected exec: %s", fp.lastExecSQL)
 	}
 }
 
+func TestUnseen(t *testing.T) {
+	fp := &fakePool{qrUnseen: 2}
+	l := NewPGWithQuerier(fp, 15*time.Minute, 5, 15*time.Minute)
+	if n, err := l.Unseen(context.Background(), "u"); err != nil || n != 2 {
+		t.Fatalf("unseen: n=%d err=%v", n, err)
+	}
+	fp.qrErr = errors.New("db down")
+	if _, err := l.Unseen(context.Background(), "u"); err == nil {
+		t.Fatal("error swallowed")
+	}
+}
+
 func TestFailure_Increments_NoBlock(t *testing.T) {
 	fp := &fakePool{qrFailsRet: 2}
 	l := NewPGWithQuerier(fp, 5*time.Minute, 5, 15*time.Minute)
@@ -168,6 +201,19 @@ func TestFailure_DBErrorOnReturning(t *testing.T) {
 	}
 }
 
+func TestClear_AllAddresses(t *testing.T) {
+	fp := &fakePool{}
+	l := NewPGWithQuerier(fp, 5*time.Minute, 5, 10*time.Minute)
+
+	n, err := l.Clear(context.Background(), "u", []byte{})
+	if err != nil || n != 2 {
+		t.Fatalf("Clear: n=%d err=%v", n, err)
+	}
+	if fp.lastExecArgs[1].([]byte) != nil {
+		t.Fatalf("an empty hash must match every address, got %v", fp.lastExecArgs[1])
+	}
+}
+
 func TestHashIP_Determinism(t *testing.T) {
 	a := HashIP("1.2.3.4:123")
 	b := HashIP("1.2.3.4:123")
@@ -0,0 +1,93 @@
+// Package longpoll wakes long-polling change requests when the vault they
+// wait on changes.
+//
+// Wake-ups only cross goroutines of one server process. Behind a load
+// balancer a write may land on another instance, so waiters also recheck
+// the database now and then instead of relying on the hub alone.
+package longpoll
+
+import (
+	"context"
+	"expvar"
+	"sync"
+
+	"github.com/gofrs/uuid/v5"
+
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/and161185/goph-keeper/internal/tenant"
+)
+
+// metrics are published under the "longpoll" expvar (see /debug/vars).
+var metrics = expvar.NewMap("longpoll")
+
+type key struct {
+	tenant string
+	user   uuid.UUID
+}
+
+// Hub tracks the waiting calls of every user.
+type Hub struct {
+	mu      sync.Mutex
+	waiters map[key]map[chan struct{}]struct{}
+	done    chan struct{}
+	closed  bool
+}
+
+// NewHub returns an empty hub.
+func NewHub() *Hub {
+	return &Hub{waiters: make(map[key]map[chan struct{}]struct{}), done: make(chan struct{})}
+}
+
+// Subscribe returns a channel that is closed at the user's next change, and
+// a function the caller must call once it stops waiting.
+func (h *Hub) Subscribe(tid string, user uuid.UUID) (<-chan struct{}, func()) {
+	k := key{tid, user}
+	ch := make(chan struct{})
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.waiters[k] == nil {
+		h.waiters[k] = make(map[chan struct{}]struct{})
+	}
+	h.waiters[k][ch] = struct{}{}
+	metrics.Add("waiting", 1)
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := h.waiters[k][ch]; !ok {
+			return // already woken
+		}
+		delete(h.waiters[k], ch)
+		if len(h.waiters[k]) == 0 {
+			delete(h.waiters, k)
+		}
+		metrics.Add("waiting", -1)
+	}
+}
+
+// ItemsChanged implements service.ChangeNotifier: it wakes the user's
+// waiting calls.
+func (h *Hub) ItemsChanged(ctx context.Context, userID uuid.UUID, _ []model.Change) {
+	k := key{tenant.FromContext(ctx), userID}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.waiters[k] {
+		close(ch)
+		metrics.Add("waiting", -1)
+		metrics.Add("woken", 1)
+	}
+	delete(h.waiters, k)
+}
+
+// Done is closed by Close.
+func (h *Hub) Done() <-chan struct{} { return h.done }
+
+// Close tells waiting calls that the server is shutting down, so they
+// answer now rather than hold up a graceful stop.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.closed {
+		h.closed = true
+		close(h.done)
+	}
+}
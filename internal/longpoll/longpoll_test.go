@@ -0,0 +1,63 @@
+package longpoll
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gofrs/uuid/v5"
+
+	"github.com/and161185/goph-keeper/internal/tenant"
+)
+
+func closed(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestHub_WakesOnlyTheUser(t *testing.T) {
+	h := NewHub()
+	alice, bob := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	a1, rel1 := h.Subscribe(tenant.Default, alice)
+	defer rel1()
+	a2, rel2 := h.Subscribe(tenant.Default, alice)
+	defer rel2()
+	b, relB := h.Subscribe(tenant.Default, bob)
+	defer relB()
+	other, relOther := h.Subscribe("acme", alice)
+	defer relOther()
+
+	h.ItemsChanged(context.Background(), alice, nil)
+	if !closed(a1) || !closed(a2) {
+		t.Fatal("alice's waiters not woken")
+	}
+	if closed(b) || closed(other) {
+		t.Fatal("woke another user or tenant")
+	}
+
+	h.ItemsChanged(tenant.WithID(context.Background(), "acme"), alice, nil)
+	if !closed(other) {
+		t.Fatal("tenant waiter not woken")
+	}
+}
+
+func TestHub_ReleaseAndClose(t *testing.T) {
+	h := NewHub()
+	u := uuid.Must(uuid.NewV4())
+	ch, release := h.Subscribe(tenant.Default, u)
+	release()
+	release() // twice is harmless
+	h.ItemsChanged(context.Background(), u, nil)
+	if closed(ch) || len(h.waiters) != 0 {
+		t.Fatal("released waiter still registered")
+	}
+
+	h.Close()
+	h.Close()
+	if !closed(h.Done()) {
+		t.Fatal("Done not closed")
+	}
+}
@@ -0,0 +1,86 @@
+// Package merkle defines the vault checksum that server and client must
+// compute identically: a SHA-256 Merkle tree with one leaf per item, in item
+// id order. Leaf and node hashes use distinct prefixes so a node can never
+// be passed off as a leaf.
+package merkle
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"slices"
+	"strings"
+)
+
+// Size is the length of every hash in the tree.
+const Size = sha256.Size
+
+// Leaf is one item's contribution. BlobHash is the SHA-256 of the encrypted
+// blob, or all zeros for a tombstone, whose blob clients never receive.
+type Leaf struct {
+	ID       string
+	Ver      int64
+	Deleted  bool
+	BlobHash [Size]byte
+}
+
+// BlobHash returns the hash of an encrypted blob as used in a Leaf.
+func BlobHash(blob []byte) [Size]byte { return sha256.Sum256(blob) }
+
+// NewLeaf builds the leaf of an item from its wire fields.
+func NewLeaf(id string, ver int64, deleted bool, blob []byte) Leaf {
+	l := Leaf{ID: id, Ver: ver, Deleted: deleted}
+	if !deleted {
+		l.BlobHash = BlobHash(blob)
+	}
+	return l
+}
+
+// Hash is H(0x00 || len(id) || id || ver || deleted || blob hash), integers big-endian.
+func (l Leaf) Hash() [Size]byte {
+	b := make([]byte, 0, 1+2+len(l.ID)+8+1+Size)
+	b = append(b, 0x00)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(l.ID)))
+	b = append(b, l.ID...)
+	b = binary.BigEndian.AppendUint64(b, uint64(l.Ver))
+	if l.Deleted {
+		b = append(b, 1)
+	} else {
+		b = append(b, 0)
+	}
+	b = append(b, l.BlobHash[:]...)
+	return sha256.Sum256(b)
+}
+
+// Sort orders leaves by id, the order Root hashes them in.
+func Sort(leaves []Leaf) {
+	slices.SortFunc(leaves, func(a, b Leaf) int { return strings.Compare(a.ID, b.ID) })
+}
+
+// Root returns the Merkle root of leaves, which must be sorted (see Sort).
+// Pairs are hashed as H(0x01 || left || right); an odd last node moves up
+// unchanged. An empty vault's root is H(0x01).
+func Root(leaves []Leaf) [Size]byte {
+	if len(leaves) == 0 {
+		return sha256.Sum256([]byte{0x01})
+	}
+	level := make([][Size]byte, len(leaves))
+	for i, l := range leaves {
+		level[i] = l.Hash()
+	}
+	var buf [1 + 2*Size]byte
+	buf[0] = 0x01
+	for len(level) > 1 {
+		next := level[:0]
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+				continue
+			}
+			copy(buf[1:], level[i][:])
+			copy(buf[1+Size:], level[i+1][:])
+			next = append(next, sha256.Sum256(buf[:]))
+		}
+		level = next
+	}
+	return level[0]
+}
@@ -0,0 +1,56 @@
+package merkle
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+)
+
+func TestRoot(t *testing.T) {
+	t.Parallel()
+	a := NewLeaf("a", 1, false, []byte("x"))
+	b := NewLeaf("b", 2, true, []byte("ignored for tombstones"))
+	c := NewLeaf("c", 3, false, nil)
+
+	if b.BlobHash != [Size]byte{} {
+		t.Fatal("tombstone blob hash must be zero")
+	}
+	if Root(nil) != sha256.Sum256([]byte{0x01}) {
+		t.Fatal("empty root")
+	}
+	if Root([]Leaf{a}) != a.Hash() {
+		t.Fatal("single leaf is the root")
+	}
+
+	node := func(l, r [Size]byte) [Size]byte {
+		return sha256.Sum256(append(append([]byte{0x01}, l[:]...), r[:]...))
+	}
+	want := node(node(a.Hash(), b.Hash()), c.Hash())
+	leaves := []Leaf{c, a, b}
+	Sort(leaves)
+	if got := Root(leaves); got != want {
+		t.Fatalf("root %x, want %x", got, want)
+	}
+
+	// any field change moves the root
+	for _, l := range []Leaf{
+		NewLeaf("a", 2, false, []byte("x")),
+		NewLeaf("a", 1, false, []byte("y")),
+		NewLeaf("a", 1, true, []byte("x")),
+	} {
+		if Root([]Leaf{l, b, c}) == want {
+			t.Fatalf("%+v did not change the root", l)
+		}
+	}
+}
+
+// TestLeafHash pins the encoding; changing it breaks verification against
+// older clients and servers.
+func TestLeafHash(t *testing.T) {
+	t.Parallel()
+	h := NewLeaf("00000000-0000-0000-0000-000000000001", 7, false, []byte("blob")).Hash()
+	const want = "da44a13a543a04f9d9186dcbe72e844786c66737efdb9b68acbdf5589343fdd0"
+	if got := hex.EncodeToString(h[:]); got != want {
+		t.Fatalf("leaf hash %s", got)
+	}
+}
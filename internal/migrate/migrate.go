@@ -4,6 +4,9 @@ package migrate
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/pressly/goose/v3"
@@ -26,3 +29,54 @@ func Up(ctx context.Context, dsn string) error {
 
 	return goose.UpContext(ctx, db, ".")
 }
+
+// Latest returns the highest migration version embedded in the binary.
+func Latest() (int64, error) {
+	names, err := fs.Glob(migrations.FS, "*.sql")
+	if err != nil {
+		return 0, err
+	}
+	var latest int64
+	for _, n := range names {
+		v, err := goose.NumericComponent(n)
+		if err != nil {
+			return 0, err
+		}
+		latest = max(latest, v)
+	}
+	return latest, nil
+}
+
+// versionQuery picks the highest version whose most recent goose record is
+// "applied", mirroring how goose itself resolves rollbacks.
+const versionQuery = `
+SELECT version_id FROM goose_db_version t
+WHERE is_applied AND id = (SELECT max(id) FROM goose_db_version WHERE version_id = t.version_id)
+ORDER BY version_id DESC LIMIT 1`
+
+// Version returns the schema version currently applied to db. It only reads
+// the goose table, so it is safe to call concurrently with live traffic.
+func Version(ctx context.Context, db *sql.DB) (int64, error) {
+	var v int64
+	err := db.QueryRowContext(ctx, versionQuery).Scan(&v)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return v, err
+}
+
+// Check reports an error unless db is reachable and migrated to Latest.
+func Check(ctx context.Context, db *sql.DB) error {
+	want, err := Latest()
+	if err != nil {
+		return err
+	}
+	got, err := Version(ctx, db)
+	if err != nil {
+		return err
+	}
+	if got < want {
+		return fmt.Errorf("schema at version %d, want %d", got, want)
+	}
+	return nil
+}
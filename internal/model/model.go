@@ -2,8 +2,10 @@
 package model
 
 import (
+	"net/netip"
 	"time"
 
+	"github.com/and161185/goph-keeper/internal/geoip"
 	"github.com/gofrs/uuid/v5"
 )
 
@@ -12,11 +14,57 @@ type Tokens struct {
 	AccessToken  string
 	RefreshToken string
 	ExpiresAt    time.Time // access token expiry (for diagnostics)
+	FailedLogins int       // wrong passwords since the previous login, to warn the user
+	// Anomalies flags a login from an unusual place, see LoginNewCountry.
+	Anomalies []string
+	Scopes    []string // what the access token may do; empty means everything
 }
 
+// Access token scopes. Each authenticated RPC needs one of them; a token
+// without any, like those issued before scopes existed, has them all.
+const (
+	ScopeItemsRead     = "items:read"     // the change feed, items and stats
+	ScopeItemsWrite    = "items:write"    // upserts and deletes
+	ScopeAccountManage = "account:manage" // DEK, sessions, webhooks, devices, admin calls
+)
+
+// Scopes lists every scope.
+var Scopes = []string{ScopeItemsRead, ScopeItemsWrite, ScopeAccountManage}
+
 // EncryptedBlob is an opaque ciphertext produced on the client side.
 type EncryptedBlob []byte
 
+// SizeClass is the ceiling a client declares for an item's ciphertext.
+type SizeClass int8
+
+// Size classes; the values match the wire enum.
+const (
+	SizeUnspecified SizeClass = iota
+	SizeSmall
+	SizeLarge
+)
+
+// MaxBytes is the longest ciphertext the class allows, or 0 when the class
+// sets no limit of its own.
+func (c SizeClass) MaxBytes() int {
+	switch c {
+	case SizeSmall:
+		return 64 << 10
+	case SizeLarge:
+		return 1 << 20
+	}
+	return 0
+}
+
+// Valid reports whether c is a known class.
+func (c SizeClass) Valid() bool { return c >= SizeUnspecified && c <= SizeLarge }
+
+// ItemHints are opaque client declarations stored next to the blob.
+type ItemHints struct {
+	SizeClass     SizeClass
+	SchemaVersion int32 // client payload format, 0 when unknown
+}
+
 // Item is a single stored record, including encrypted payload and versioning metadata.
 type Item struct {
 	ID        uuid.UUID     // client-generated PK
@@ -25,6 +73,7 @@ type Item struct {
 	Ver       int64         // monotonically increasing version (>= 0)
 	Deleted   bool          // tombstone flag
 	UpdatedAt time.Time     // maintained by DB triggers or repo
+	Hints     ItemHints
 }
 
 // UpsertItem is a client change intent with optimistic concurrency base version.
@@ -32,6 +81,7 @@ type UpsertItem struct {
 	ID      uuid.UUID
 	BaseVer int64
 	BlobEnc EncryptedBlob
+	Hints   ItemHints
 }
 
 // ItemVersion reports the new version after a successful change.
@@ -41,6 +91,70 @@ type ItemVersion struct {
 	UpdatedAt time.Time
 }
 
+// ItemRef names an item at the version a client last saw.
+type ItemRef struct {
+	ID      uuid.UUID
+	BaseVer int64
+}
+
+// ItemStatus is the outcome of one item of a batch; the values match the
+// wire enum.
+type ItemStatus int8
+
+const (
+	StatusOK ItemStatus = iota + 1
+	StatusVersionConflict
+	StatusNotFound
+	// StatusAborted marks an item that was fine in a batch rolled back
+	// because of another one.
+	StatusAborted
+)
+
+// ItemResult reports one item of a batch. Ver is the new version for
+// StatusOK and the current one for StatusVersionConflict.
+type ItemResult struct {
+	ID        uuid.UUID
+	Status    ItemStatus
+	Ver       int64
+	UpdatedAt time.Time
+}
+
+// AllOK reports whether every result is StatusOK, i.e. the batch applied.
+func AllOK(rs []ItemResult) bool {
+	for _, r := range rs {
+		if r.Status != StatusOK {
+			return false
+		}
+	}
+	return true
+}
+
+// AbortOK turns the StatusOK results of a failed batch into StatusAborted.
+func AbortOK(rs []ItemResult) {
+	for i := range rs {
+		if rs[i].Status == StatusOK {
+			rs[i] = ItemResult{ID: rs[i].ID, Status: StatusAborted}
+		}
+	}
+}
+
+// ChangeSet is a batch of upserts and deletes applied in one transaction,
+// all of them or none.
+type ChangeSet struct {
+	Upserts []UpsertItem
+	Deletes []ItemRef
+}
+
+// ChangeSetResult has a result per upsert and per delete of a ChangeSet, in
+// order.
+type ChangeSetResult struct {
+	Upserts []ItemResult
+	Deletes []ItemResult
+}
+
+// Applied reports whether the change set was committed.
+func (r ChangeSetResult) Applied() bool { return AllOK(r.Upserts) && AllOK(r.Deletes) }
+
 // Change describes a single item mutation for delta sync.
 type Change struct {
 	ID        uuid.UUID
@@ -48,6 +162,175 @@ type Change struct {
 	Deleted   bool
 	UpdatedAt time.Time
 	BlobEnc   EncryptedBlob // nil if Deleted==true (server MAY omit)
+	Hints     ItemHints
+	Seq       int64 // position in the change journal; journal reads only
+}
+
+// ChangeCursor is a position in a user's change feed, which is ordered by
+// (Ver, ID): versions are per item, so many items share one.
+type ChangeCursor struct {
+	Ver int64
+	ID  uuid.UUID
+}
+
+// AfterVer is the cursor that starts the feed just above ver.
+func AfterVer(ver int64) ChangeCursor { return ChangeCursor{Ver: ver, ID: uuid.Max} }
+
+// ItemStats aggregates a user's stored items.
+type ItemStats struct {
+	Items       int64
+	Deleted     int64
+	MaxVer      int64
+	TotalBytes  int64     // encrypted size of live items
+	LastUpdated time.Time // zero when the user has no items
+	MaxItems    int64     // per-user cap on live items, 0 when unlimited
+}
+
+// ItemDigest is an item's checksum input: version, tombstone flag and the
+// SHA-256 of its encrypted blob (nil for tombstones).
+type ItemDigest struct {
+	ID       uuid.UUID
+	Ver      int64
+	Deleted  bool
+	BlobHash []byte
+}
+
+// RollbackResult reports a point-in-time rollback of one user's items.
+type RollbackResult struct {
+	ID        int64 // user_rollbacks row; 0 for a dry run
+	Restored  int64 // items reset to their version at the target time
+	Removed   int64 // items created after it
+	Discarded int64 // history versions moved to item_history_discarded
+	Skipped   int64 // items with no recorded version at or before it
+}
+
+// Webhook is a URL notified of changes to its owner's items.
+type Webhook struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	URL       string
+	Secret    []byte // HMAC key for delivery signatures
+	CreatedAt time.Time
+}
+
+// Device is a client woken by push notifications on vault changes.
+type Device struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Platform  string // fcm, apns or ntfy
+	Token     string // provider address of the device
+	Name      string
+	CreatedAt time.Time
+}
+
+// LoginAttempt is one password check against a user's account.
+type LoginAttempt struct {
+	At      time.Time
+	IPHash  []byte // limiter.HashIP of the client address, port stripped
+	Success bool
+	// Where is the client's location; zero without a GeoIP database.
+	Where geoip.Location
+}
+
+// Login anomalies, found by comparing a login's location with earlier ones.
+const (
+	LoginNewCountry       = "new_country"       // no earlier login from the country
+	LoginImpossibleTravel = "impossible_travel" // too far from the last login, too soon
+)
+
+// IPRule admits (Allow) or refuses one network for a user's calls.
+type IPRule struct {
+	CIDR      netip.Prefix
+	Allow     bool
+	CreatedAt time.Time
+}
+
+// Session is a device signed in with a refresh token. The server stores
+// only the token's hash, so a session can be listed and revoked but its
+// token never shown again.
+type Session struct {
+	ID         uuid.UUID
+	UserID     uuid.UUID
+	Label      string   // device name the client gave at login, e.g. "laptop"
+	Scopes     []string // granted at login and kept by every refresh; empty means all
+	CreatedAt  time.Time
+	LastUsedAt time.Time // last login or refresh
+	ExpiresAt  time.Time
+}
+
+// ItemAccess is one session's reads of an item.
+type ItemAccess struct {
+	SessionID uuid.UUID // uuid.Nil: tokens issued without a session
+	Label     string    // the session's device label when it first read the item
+	FirstAt   time.Time
+	LastAt    time.Time
+	Reads     int64
+}
+
+// VaultTrace is what the consistency checker reads of one user's items:
+// each item's current row, its history and its journal entries.
+type VaultTrace struct {
+	Items        []ItemTrace
+	LastRollback time.Time // zero if the user was never rolled back
+}
+
+// ItemTrace is one item as stored, with what was recorded of its writes.
+type ItemTrace struct {
+	ID      uuid.UUID
+	Ver     int64
+	Deleted bool
+	BlobLen int
+	History []TraceVersion // item_history rows, by version
+	Journal []TraceEntry   // item_changes entries, by seq
+}
+
+// TraceVersion is one recorded version of an item.
+type TraceVersion struct {
+	Ver     int64
+	Deleted bool
+}
+
+// TraceEntry is one journal entry of an item.
+type TraceEntry struct {
+	Seq int64
+	Op  string // "upsert", "delete" or "remove"
+	Ver int64
+	At  time.Time
+}
+
+// Violation is a broken invariant found in a user's items.
+type Violation struct {
+	ItemID   uuid.UUID
+	Check    string // which invariant, e.g. "journal_head"
+	Detail   string
+	Repaired bool
+}
+
+// UserUsage is one user's storage as of a usage rollup.
+type UserUsage struct {
+	UserID       uuid.UUID
+	Tenant       string
+	Items        int64 // live items
+	Tombstones   int64
+	Bytes        int64     // ciphertext in items, tombstones included
+	HistoryBytes int64     // ciphertext of kept versions
+	LastWrite    time.Time // zero if the user never stored an item
+	LastLogin    time.Time // zero if no login is on record
+	History      []UsageSample
+}
+
+// UsageSample is a user's storage as of the last rollup of a day.
+type UsageSample struct {
+	Day          time.Time
+	Items        int64
+	Bytes        int64
+	HistoryBytes int64
+}
+
+// UsageReport is the latest usage rollup; AsOf is zero if there was none.
+type UsageReport struct {
+	AsOf  time.Time
+	Users []UserUsage
 }
 
 // User represents an account stored on the server. Sensitive keys are never stored in plaintext.
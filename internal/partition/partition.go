@@ -0,0 +1,168 @@
+// Package partition converts the items table into hash partitions by
+// user_id, for deployments whose single items table and its indexes have
+// grown unwieldy. Every item query names the user, so the planner prunes
+// it to one partition and each partition's indexes stay small.
+//
+// The conversion is opt-in and one-way, so migrations that touch items
+// must work on either shape; CREATE INDEX CONCURRENTLY, for one, does not
+// on a partitioned table. A partitioned table's primary key must include
+// user_id, so global uniqueness of item ids moves to an item_ids table
+// that triggers keep in step: inserting an id another user holds still
+// fails with a unique violation, as the repositories expect.
+package partition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// MaxParts bounds the partition count; beyond it planning costs more than
+// the smaller indexes save.
+const MaxParts = 1024
+
+// minServer is the first PostgreSQL version with row triggers on
+// partitioned tables.
+const minServer = 130000
+
+// ErrPartitioned is returned when items is already partitioned.
+var ErrPartitioned = errors.New("items is already partitioned")
+
+// DB is what the conversion needs of a connection pool.
+type DB interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
+}
+
+// countParts counts the partitions of items; 0 means a plain table.
+const countParts = `SELECT count(*) FROM pg_inherits WHERE inhparent = 'items'::regclass`
+
+// Parts returns the number of partitions of items, 0 if it is a plain table.
+func Parts(ctx context.Context, db DB) (int, error) {
+	var n int
+	if err := db.QueryRow(ctx, countParts).Scan(&n); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// Items rebuilds items as parts hash partitions by user_id in one
+// transaction. It holds an exclusive lock on items throughout, so the
+// server should be stopped or drained first; on any error nothing changes.
+func Items(ctx context.Context, db DB, parts int) (err error) {
+	if parts < 2 || parts > MaxParts {
+		return fmt.Errorf("partitions: want 2 to %d, got %d", MaxParts, parts)
+	}
+	tx, err := db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+			return
+		}
+		if e := tx.Commit(ctx); e != nil {
+			err = e
+		}
+	}()
+
+	var server int
+	if err = tx.QueryRow(ctx, `SELECT current_setting('server_version_num')::int`).Scan(&server); err != nil {
+		return err
+	}
+	if server < minServer {
+		return fmt.Errorf("partitioning needs PostgreSQL 13 or later, server is %d", server)
+	}
+	if _, err = tx.Exec(ctx, `LOCK TABLE items IN ACCESS EXCLUSIVE MODE`); err != nil {
+		return err
+	}
+	var n int
+	if err = tx.QueryRow(ctx, countParts).Scan(&n); err != nil {
+		return err
+	}
+	if n > 0 {
+		return fmt.Errorf("%w into %d", ErrPartitioned, n)
+	}
+	_, err = tx.Exec(ctx, script(parts))
+	return err
+}
+
+// script returns the conversion, run after items is locked. It copies
+// items into a partitioned twin, drops the original with the view and the
+// foreign key depending on it, and recreates those, the indexes and the
+// triggers on the new table.
+func script(parts int) string {
+	var b strings.Builder
+	b.WriteString(`
+CREATE TABLE item_ids (
+  id       uuid PRIMARY KEY,
+  user_id  uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE
+);
+INSERT INTO item_ids (id, user_id) SELECT id, user_id FROM items;
+
+CREATE TABLE items_partitioned (LIKE items INCLUDING DEFAULTS INCLUDING CONSTRAINTS)
+  PARTITION BY HASH (user_id);
+ALTER TABLE items_partitioned ADD CONSTRAINT items_partitioned_pkey PRIMARY KEY (user_id, id);
+`)
+	for i := range parts {
+		fmt.Fprintf(&b, "CREATE TABLE items_p%03d PARTITION OF items_partitioned FOR VALUES WITH (MODULUS %d, REMAINDER %d);\n", i, parts, i)
+	}
+	b.WriteString(`INSERT INTO items_partitioned SELECT * FROM items;
+
+DROP VIEW IF EXISTS v_item_stats;
+ALTER TABLE item_accesses DROP CONSTRAINT IF EXISTS item_accesses_item_id_fkey;
+DROP TABLE items;
+ALTER TABLE items_partitioned RENAME TO items;
+ALTER TABLE items RENAME CONSTRAINT items_partitioned_pkey TO items_pkey;
+
+-- the primary key leads with user_id, so idx_items_user is not recreated
+CREATE INDEX idx_items_changes ON items (user_id, tenant_id, ver, id);
+CREATE INDEX idx_items_tenant_live ON items (tenant_id) WHERE NOT deleted;
+ALTER TABLE items ADD CONSTRAINT items_user_tenant_fk
+  FOREIGN KEY (user_id, tenant_id) REFERENCES users(id, tenant_id) ON DELETE CASCADE;
+ALTER TABLE item_accesses ADD CONSTRAINT item_accesses_item_fkey
+  FOREIGN KEY (user_id, item_id) REFERENCES items(user_id, id) ON DELETE CASCADE;
+
+CREATE OR REPLACE FUNCTION claim_item_id()
+RETURNS trigger LANGUAGE plpgsql AS $$
+BEGIN
+  -- an id another user holds fails here, as it did on the old primary key
+  INSERT INTO item_ids (id, user_id) VALUES (NEW.id, NEW.user_id);
+  RETURN NEW;
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION release_item_id()
+RETURNS trigger LANGUAGE plpgsql AS $$
+BEGIN
+  DELETE FROM item_ids WHERE id = OLD.id AND user_id = OLD.user_id;
+  RETURN OLD;
+END;
+$$;
+
+CREATE TRIGGER trg_items_claim_id BEFORE INSERT ON items
+FOR EACH ROW EXECUTE FUNCTION claim_item_id();
+CREATE TRIGGER trg_items_release_id AFTER DELETE ON items
+FOR EACH ROW EXECUTE FUNCTION release_item_id();
+CREATE TRIGGER trg_items_updated_at BEFORE UPDATE ON items
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+CREATE TRIGGER trg_items_history AFTER INSERT OR UPDATE ON items
+FOR EACH ROW EXECUTE FUNCTION record_item_history();
+CREATE TRIGGER trg_items_changes AFTER INSERT OR UPDATE OR DELETE ON items
+FOR EACH ROW EXECUTE FUNCTION record_item_change();
+
+CREATE VIEW v_item_stats AS
+SELECT
+  user_id,
+  count(*) AS total,
+  count(*) FILTER (WHERE deleted) AS deleted,
+  max(ver) AS max_ver
+FROM items
+GROUP BY user_id;
+`)
+	return b.String()
+}
@@ -0,0 +1,60 @@
+package partition
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	pgxmock "github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_script(t *testing.T) {
+	s := script(4)
+	for i, want := range []string{
+		"items_p000 PARTITION OF items_partitioned FOR VALUES WITH (MODULUS 4, REMAINDER 0)",
+		"items_p003 PARTITION OF items_partitioned FOR VALUES WITH (MODULUS 4, REMAINDER 3)",
+	} {
+		require.Contains(t, s, want, i)
+	}
+	require.Equal(t, 4, strings.Count(s, "PARTITION OF"))
+	// everything that depended on the old table is back
+	for _, want := range []string{"trg_items_updated_at", "trg_items_history", "trg_items_changes", "v_item_stats", "item_accesses_item_fkey", "items_user_tenant_fk"} {
+		require.Contains(t, s, want)
+	}
+}
+
+func TestItems(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+	ctx := context.Background()
+
+	require.Error(t, Items(ctx, mock, 1))
+	require.Error(t, Items(ctx, mock, MaxParts+1))
+
+	expect := func(server, parts int) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`server_version_num`).WillReturnRows(pgxmock.NewRows([]string{"v"}).AddRow(server))
+		if server < minServer {
+			return
+		}
+		mock.ExpectExec(`LOCK TABLE items IN ACCESS EXCLUSIVE MODE`).WillReturnResult(pgxmock.NewResult("LOCK TABLE", 0))
+		mock.ExpectQuery(`FROM pg_inherits`).WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(parts))
+	}
+
+	expect(160004, 0)
+	mock.ExpectExec(`CREATE TABLE item_ids`).WillReturnResult(pgxmock.NewResult("CREATE VIEW", 0))
+	mock.ExpectCommit()
+	require.NoError(t, Items(ctx, mock, 16))
+
+	expect(160004, 16)
+	mock.ExpectRollback()
+	require.True(t, errors.Is(Items(ctx, mock, 16), ErrPartitioned))
+
+	expect(120010, 0)
+	mock.ExpectRollback()
+	require.ErrorContains(t, Items(ctx, mock, 16), "PostgreSQL 13")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,92 @@
+// Package probe serves plain-HTTP liveness and readiness endpoints for load
+// balancers and orchestrators that cannot speak the gRPC health protocol.
+package probe
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Check is one readiness dependency, e.g. a database ping.
+type Check struct {
+	Name string
+	Fn   func(ctx context.Context) error
+}
+
+// Handler answers /livez and /readyz.
+type Handler struct {
+	checks   []Check
+	timeout  time.Duration
+	draining atomic.Bool
+	mux      *http.ServeMux
+}
+
+// New constructs a probe handler; every readiness check runs with the given timeout.
+func New(timeout time.Duration, checks ...Check) *Handler {
+	h := &Handler{checks: checks, timeout: timeout, mux: http.NewServeMux()}
+	h.mux.HandleFunc("/livez", h.livez)
+	h.mux.HandleFunc("/readyz", h.readyz)
+	return h
+}
+
+// Drain makes /readyz fail so traffic moves away before shutdown; /livez keeps passing.
+func (h *Handler) Drain() { h.draining.Store(true) }
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) { h.mux.ServeHTTP(w, r) }
+
+// Report is the /readyz response body.
+type Report struct {
+	Status string            `json:"status"` // "ok" or "fail"
+	Checks map[string]string `json:"checks"` // check name -> "ok" or error text
+}
+
+// Ready runs all checks concurrently and reports the outcome.
+func (h *Handler) Ready(ctx context.Context) Report {
+	rep := Report{Status: "ok", Checks: make(map[string]string, len(h.checks)+1)}
+	if h.draining.Load() {
+		rep.Status = "fail"
+		rep.Checks["shutdown"] = "draining"
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, c := range h.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cctx, cancel := context.WithTimeout(ctx, h.timeout)
+			defer cancel()
+			res := "ok"
+			if err := c.Fn(cctx); err != nil {
+				res = err.Error()
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			rep.Checks[c.Name] = res
+			if res != "ok" {
+				rep.Status = "fail"
+			}
+		}()
+	}
+	wg.Wait()
+	return rep
+}
+
+func (h *Handler) livez(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = w.Write([]byte("ok\n"))
+}
+
+func (h *Handler) readyz(w http.ResponseWriter, r *http.Request) {
+	rep := h.Ready(r.Context())
+	w.Header().Set("Content-Type", "application/json")
+	if rep.Status != "ok" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(rep)
+}
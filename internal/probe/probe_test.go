@@ -0,0 +1,92 @@
+package probe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func get(t *testing.T, h http.Handler, path string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	return rec
+}
+
+func readReport(t *testing.T, rec *httptest.ResponseRecorder) Report {
+	t.Helper()
+	var rep Report
+	if err := json.Unmarshal(rec.Body.Bytes(), &rep); err != nil {
+		t.Fatalf("bad json: %v", err)
+	}
+	return rep
+}
+
+func TestHandler_Livez(t *testing.T) {
+	t.Parallel()
+	h := New(time.Second, Check{"db", func(context.Context) error { return errors.New("down") }})
+	if rec := get(t, h, "/livez"); rec.Code != http.StatusOK {
+		t.Fatalf("livez must not depend on checks: %d", rec.Code)
+	}
+}
+
+func TestHandler_ReadyzOK(t *testing.T) {
+	t.Parallel()
+	ok := func(context.Context) error { return nil }
+	h := New(time.Second, Check{"db", ok}, Check{"migrations", ok})
+	rec := get(t, h, "/readyz")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("readyz: %d", rec.Code)
+	}
+	rep := readReport(t, rec)
+	if rep.Status != "ok" || rep.Checks["db"] != "ok" || rep.Checks["migrations"] != "ok" {
+		t.Fatalf("report: %+v", rep)
+	}
+}
+
+func TestHandler_ReadyzFailingCheck(t *testing.T) {
+	t.Parallel()
+	h := New(time.Second,
+		Check{"db", func(context.Context) error { return nil }},
+		Check{"migrations", func(context.Context) error { return errors.New("schema at version 2, want 3") }},
+	)
+	rec := get(t, h, "/readyz")
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("want 503, got %d", rec.Code)
+	}
+	rep := readReport(t, rec)
+	if rep.Status != "fail" || rep.Checks["db"] != "ok" || rep.Checks["migrations"] != "schema at version 2, want 3" {
+		t.Fatalf("report: %+v", rep)
+	}
+}
+
+func TestHandler_CheckTimeout(t *testing.T) {
+	t.Parallel()
+	slow := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	h := New(20*time.Millisecond, Check{"db", slow})
+	if rec := get(t, h, "/readyz"); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("want 503 on timeout, got %d", rec.Code)
+	}
+}
+
+func TestHandler_Drain(t *testing.T) {
+	t.Parallel()
+	h := New(time.Second)
+	if rec := get(t, h, "/readyz"); rec.Code != http.StatusOK {
+		t.Fatalf("before drain: %d", rec.Code)
+	}
+	h.Drain()
+	if rec := get(t, h, "/readyz"); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("after drain: %d", rec.Code)
+	}
+	if rec := get(t, h, "/livez"); rec.Code != http.StatusOK {
+		t.Fatalf("livez after drain: %d", rec.Code)
+	}
+}
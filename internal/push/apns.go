@@ -0,0 +1,135 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// APNs endpoints.
+const (
+	APNsProduction = "https://api.push.apple.com"
+	APNsSandbox    = "https://api.sandbox.push.apple.com"
+)
+
+// APNsProvider sends background notifications with token-based (.p8 key)
+// authentication.
+type APNsProvider struct {
+	KeyID    string
+	TeamID   string
+	Topic    string // app bundle id
+	Key      *ecdsa.PrivateKey
+	Endpoint string // default APNsProduction
+	HTTP     *http.Client
+	Now      func() time.Time
+
+	mu       sync.Mutex
+	jwt      string
+	issuedAt time.Time
+}
+
+// ParseAPNsKey reads the PKCS#8 PEM (.p8) signing key Apple issues.
+func ParseAPNsKey(pemBytes []byte) (*ecdsa.PrivateKey, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, errors.New("apns: key is not PEM")
+	}
+	k, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("apns: key: %w", err)
+	}
+	ek, ok := k.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("apns: key is not an EC key")
+	}
+	return ek, nil
+}
+
+// providerToken returns the signed JWT, renewed every 50 minutes: Apple
+// rejects tokens older than an hour and throttles ones renewed too often.
+func (a *APNsProvider) providerToken() (string, error) {
+	now := time.Now()
+	if a.Now != nil {
+		now = a.Now()
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.jwt != "" && now.Sub(a.issuedAt) < 50*time.Minute {
+		return a.jwt, nil
+	}
+	t := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{"iss": a.TeamID, "iat": now.Unix()})
+	t.Header["kid"] = a.KeyID
+	s, err := t.SignedString(a.Key)
+	if err != nil {
+		return "", err
+	}
+	a.jwt, a.issuedAt = s, now
+	return s, nil
+}
+
+// Send delivers m as a background (content-available) notification.
+func (a *APNsProvider) Send(ctx context.Context, token string, m Message) error {
+	body, err := json.Marshal(map[string]any{
+		"aps":  map[string]any{"content-available": 1},
+		"type": EventVaultChanged,
+		"ver":  m.MaxVer,
+	})
+	if err != nil {
+		return err
+	}
+	endpoint := a.Endpoint
+	if endpoint == "" {
+		endpoint = APNsProduction
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"/3/device/"+token, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	jwt, err := a.providerToken()
+	if err != nil {
+		return fmt.Errorf("apns: provider token: %w", err)
+	}
+	req.Header.Set("Authorization", "bearer "+jwt)
+	req.Header.Set("apns-topic", a.Topic)
+	req.Header.Set("apns-push-type", "background")
+	req.Header.Set("apns-priority", "5") // background pushes must not use 10
+	// one pending wake-up per device is enough
+	req.Header.Set("apns-collapse-id", EventVaultChanged)
+	cli := a.HTTP
+	if cli == nil {
+		cli = &http.Client{Timeout: 10 * time.Second} // the default transport speaks HTTP/2
+	}
+	resp, err := cli.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	var e struct {
+		Reason string `json:"reason"`
+	}
+	_ = json.Unmarshal(raw, &e)
+	if resp.StatusCode == http.StatusGone || e.Reason == "BadDeviceToken" || e.Reason == "Unregistered" {
+		return ErrUnregistered
+	}
+	if e.Reason == "ExpiredProviderToken" {
+		a.mu.Lock()
+		a.jwt = ""
+		a.mu.Unlock()
+	}
+	return fmt.Errorf("apns: %s: %s", resp.Status, e.Reason)
+}
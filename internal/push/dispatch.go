@@ -0,0 +1,157 @@
+package push
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"go.uber.org/zap"
+
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/and161185/goph-keeper/internal/tenant"
+)
+
+// metrics are published under the "push" expvar (see /debug/vars).
+var metrics = expvar.NewMap("push")
+
+// Devices looks up and prunes push targets; repository.DeviceRepository
+// implements it.
+type Devices interface {
+	List(ctx context.Context, userID uuid.UUID) ([]model.Device, error)
+	DeleteToken(ctx context.Context, platform, token string) error
+}
+
+type userKey struct {
+	tenant string
+	userID uuid.UUID
+}
+
+// Dispatcher pushes to a user's devices after their changes commit. It
+// implements service.ChangeNotifier. Changes arriving within Delay of each
+// other are coalesced into one push, so a bulk import does not wake every
+// device a hundred times.
+type Dispatcher struct {
+	devices   Devices
+	providers map[string]Provider
+	logger    *zap.Logger
+	delay     time.Duration
+
+	mu      sync.Mutex
+	pending map[userKey]int64
+	wake    chan struct{}
+}
+
+// NewDispatcher returns a dispatcher sending through providers, keyed by
+// platform.
+func NewDispatcher(devices Devices, providers map[string]Provider, logger *zap.Logger) *Dispatcher {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Dispatcher{
+		devices:   devices,
+		providers: providers,
+		logger:    logger,
+		delay:     time.Second,
+		pending:   make(map[userKey]int64),
+		wake:      make(chan struct{}, 1),
+	}
+}
+
+// Platforms lists the configured platforms, sorted.
+func (d *Dispatcher) Platforms() []string {
+	out := make([]string, 0, len(d.providers))
+	for p := range d.providers {
+		out = append(out, p)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// ItemsChanged marks userID's devices for a push.
+func (d *Dispatcher) ItemsChanged(ctx context.Context, userID uuid.UUID, changes []model.Change) {
+	var ver int64
+	for _, c := range changes {
+		ver = max(ver, c.Ver)
+	}
+	k := userKey{tenant: tenant.FromContext(ctx), userID: userID}
+	d.mu.Lock()
+	// bounded like the webhook queue; dropped users still sync on their own
+	if _, ok := d.pending[k]; !ok && len(d.pending) >= 10000 {
+		d.mu.Unlock()
+		metrics.Add("dropped", 1)
+		return
+	}
+	d.pending[k] = max(d.pending[k], ver)
+	d.mu.Unlock()
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run sends pending pushes until ctx is done.
+func (d *Dispatcher) Run(ctx context.Context) {
+	for {
+		select {
+		case <-d.wake:
+		case <-ctx.Done():
+			return
+		}
+		select {
+		case <-time.After(d.delay):
+		case <-ctx.Done():
+			return
+		}
+		d.mu.Lock()
+		batch := d.pending
+		d.pending = make(map[userKey]int64)
+		d.mu.Unlock()
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, 8)
+		for k, ver := range batch {
+			sem <- struct{}{}
+			wg.Add(1)
+			go func() {
+				defer func() { <-sem; wg.Done() }()
+				d.send(ctx, k, ver)
+			}()
+		}
+		wg.Wait()
+	}
+}
+
+// send pushes to every device of one user, forgetting tokens the provider
+// reports gone.
+func (d *Dispatcher) send(ctx context.Context, k userKey, ver int64) {
+	ctx = tenant.WithID(ctx, k.tenant)
+	devs, err := d.devices.List(ctx, k.userID)
+	if err != nil {
+		metrics.Add("failed", 1)
+		d.logger.Warn("push device lookup", zap.Stringer("user", k.userID), zap.Error(err))
+		return
+	}
+	m := Message{UserID: k.userID, MaxVer: ver}
+	for _, dev := range devs {
+		p, ok := d.providers[dev.Platform]
+		if !ok {
+			continue // platform disabled since the device registered
+		}
+		err := p.Send(ctx, dev.Token, m)
+		switch {
+		case err == nil:
+			metrics.Add("sent", 1)
+		case errors.Is(err, ErrUnregistered):
+			metrics.Add("pruned", 1)
+			if err := d.devices.DeleteToken(ctx, dev.Platform, dev.Token); err != nil {
+				d.logger.Warn("push prune", zap.Stringer("device", dev.ID), zap.Error(err))
+			}
+		default:
+			metrics.Add("failed", 1)
+			d.logger.Warn("push", zap.String("platform", dev.Platform), zap.Stringer("device", dev.ID), zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package push
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/and161185/goph-keeper/internal/tenant"
+)
+
+type fakeDevices struct {
+	mu      sync.Mutex
+	devs    []model.Device
+	tenant  string
+	deleted []string
+}
+
+func (f *fakeDevices) List(ctx context.Context, _ uuid.UUID) ([]model.Device, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.tenant = tenant.FromContext(ctx)
+	return f.devs, nil
+}
+
+func (f *fakeDevices) DeleteToken(_ context.Context, _, token string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.deleted = append(f.deleted, token)
+	return nil
+}
+
+// fakeProvider records sends and fails tokens listed in gone.
+type fakeProvider struct {
+	mu   sync.Mutex
+	sent map[string][]int64
+	gone map[string]bool
+}
+
+func (p *fakeProvider) Send(_ context.Context, token string, m Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.gone[token] {
+		return ErrUnregistered
+	}
+	if p.sent == nil {
+		p.sent = make(map[string][]int64)
+	}
+	p.sent[token] = append(p.sent[token], m.MaxVer)
+	return nil
+}
+
+func TestDispatcher_CoalescesAndPrunes(t *testing.T) {
+	t.Parallel()
+	user := uuid.Must(uuid.NewV4())
+	devs := &fakeDevices{devs: []model.Device{
+		{ID: uuid.Must(uuid.NewV4()), Platform: Ntfy, Token: "live"},
+		{ID: uuid.Must(uuid.NewV4()), Platform: Ntfy, Token: "stale"},
+		{ID: uuid.Must(uuid.NewV4()), Platform: APNs, Token: "not-configured"},
+	}}
+	p := &fakeProvider{gone: map[string]bool{"stale": true}}
+	d := NewDispatcher(devs, map[string]Provider{Ntfy: p}, nil)
+	d.delay = 20 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() { d.Run(ctx); close(done) }()
+	defer func() { cancel(); <-done }()
+
+	tctx := tenant.WithID(context.Background(), "acme")
+	d.ItemsChanged(tctx, user, []model.Change{{Ver: 3}})
+	d.ItemsChanged(tctx, user, []model.Change{{Ver: 5}, {Ver: 4}})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		p.mu.Lock()
+		n := len(p.sent["live"])
+		p.mu.Unlock()
+		devs.mu.Lock()
+		pruned := len(devs.deleted)
+		devs.mu.Unlock()
+		if n > 0 && pruned > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("no push")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if got := p.sent["live"]; len(got) != 1 || got[0] != 5 {
+		t.Fatalf("sent %v, want one push of version 5", got)
+	}
+	if devs.tenant != "acme" || devs.deleted[0] != "stale" {
+		t.Fatalf("tenant %q, deleted %v", devs.tenant, devs.deleted)
+	}
+}
+
+func TestDispatcher_Platforms(t *testing.T) {
+	t.Parallel()
+	d := NewDispatcher(nil, map[string]Provider{Ntfy: &fakeProvider{}, APNs: &fakeProvider{}}, nil)
+	if got := d.Platforms(); len(got) != 2 || got[0] != APNs || got[1] != Ntfy {
+		t.Fatalf("Platforms() = %v", got)
+	}
+}
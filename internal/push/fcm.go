@@ -0,0 +1,86 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FCMProvider sends data messages through the FCM HTTP v1 API.
+type FCMProvider struct {
+	Project  string // Firebase project id
+	Endpoint string // default https://fcm.googleapis.com
+	HTTP     *http.Client
+	// Token returns an OAuth2 access token with the firebase.messaging scope.
+	Token func(ctx context.Context) (string, error)
+}
+
+// Send delivers m as a high-priority data message, which wakes the app
+// without showing anything to the user.
+func (f *FCMProvider) Send(ctx context.Context, token string, m Message) error {
+	msg := map[string]any{"message": map[string]any{
+		"token": token,
+		"data": map[string]string{
+			"type": EventVaultChanged,
+			"ver":  strconv.FormatInt(m.MaxVer, 10),
+		},
+		"android": map[string]any{"priority": "HIGH", "ttl": "3600s"},
+	}}
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	endpoint := f.Endpoint
+	if endpoint == "" {
+		endpoint = "https://fcm.googleapis.com"
+	}
+	url := strings.TrimRight(endpoint, "/") + "/v1/projects/" + f.Project + "/messages:send"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	tok, err := f.Token(ctx)
+	if err != nil {
+		return fmt.Errorf("fcm: access token: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+tok)
+	req.Header.Set("Content-Type", "application/json")
+	cli := f.HTTP
+	if cli == nil {
+		cli = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := cli.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	var e struct {
+		Error struct {
+			Status  string `json:"status"`
+			Message string `json:"message"`
+			Details []struct {
+				ErrorCode string `json:"errorCode"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	_ = json.Unmarshal(raw, &e)
+	for _, d := range e.Error.Details {
+		if d.ErrorCode == "UNREGISTERED" {
+			return ErrUnregistered
+		}
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrUnregistered
+	}
+	return fmt.Errorf("fcm: %s: %s", resp.Status, e.Error.Message)
+}
@@ -0,0 +1,57 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// NtfyProvider publishes to topics on one ntfy server. Device tokens are
+// topic names, so users cannot make the server post elsewhere.
+type NtfyProvider struct {
+	BaseURL     string // e.g. https://ntfy.sh
+	AccessToken string // optional, for servers with access control
+	HTTP        *http.Client
+}
+
+// NtfyPayload is the message body published to a topic.
+type NtfyPayload struct {
+	Type string `json:"type"`
+	Ver  int64  `json:"ver"`
+}
+
+// Send publishes m to the topic token.
+func (n *NtfyProvider) Send(ctx context.Context, token string, m Message) error {
+	body, err := json.Marshal(NtfyPayload{Type: EventVaultChanged, Ver: m.MaxVer})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(n.BaseURL, "/")+"/"+token, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if n.AccessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+n.AccessToken)
+	}
+	req.Header.Set("Priority", "low")
+	req.Header.Set("Cache", "no") // a device that was offline syncs anyway
+	cli := n.HTTP
+	if cli == nil {
+		cli = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := cli.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4<<10))
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("ntfy: %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package push
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// capture records the last request a test server saw and answers with
+// code and body.
+type capture struct {
+	code int
+	body string
+	req  *http.Request
+	got  []byte
+}
+
+func (c *capture) server(t *testing.T) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.req = r
+		c.got, _ = io.ReadAll(r.Body)
+		w.WriteHeader(c.code)
+		_, _ = io.WriteString(w, c.body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestFCMProvider(t *testing.T) {
+	t.Parallel()
+	c := &capture{code: http.StatusOK}
+	srv := c.server(t)
+	f := &FCMProvider{Project: "vault-app", Endpoint: srv.URL, Token: func(context.Context) (string, error) { return "ya29.tok", nil }}
+
+	if err := f.Send(context.Background(), "reg-token", Message{MaxVer: 42}); err != nil {
+		t.Fatal(err)
+	}
+	if c.req.URL.Path != "/v1/projects/vault-app/messages:send" || c.req.Header.Get("Authorization") != "Bearer ya29.tok" {
+		t.Fatalf("request: %s %v", c.req.URL.Path, c.req.Header)
+	}
+	var msg struct {
+		Message struct {
+			Token string            `json:"token"`
+			Data  map[string]string `json:"data"`
+		} `json:"message"`
+	}
+	if err := json.Unmarshal(c.got, &msg); err != nil || msg.Message.Token != "reg-token" ||
+		msg.Message.Data["type"] != EventVaultChanged || msg.Message.Data["ver"] != "42" {
+		t.Fatalf("body %s: %v", c.got, err)
+	}
+
+	c.code, c.body = http.StatusNotFound, `{"error":{"status":"NOT_FOUND","details":[{"errorCode":"UNREGISTERED"}]}}`
+	if err := f.Send(context.Background(), "reg-token", Message{}); !errors.Is(err, ErrUnregistered) {
+		t.Fatalf("unregistered: %v", err)
+	}
+	c.code, c.body = http.StatusServiceUnavailable, `{"error":{"message":"try later"}}`
+	if err := f.Send(context.Background(), "reg-token", Message{}); err == nil || errors.Is(err, ErrUnregistered) {
+		t.Fatalf("unavailable: %v", err)
+	}
+}
+
+func TestAPNsProvider(t *testing.T) {
+	t.Parallel()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, _ := x509.MarshalPKCS8PrivateKey(key)
+	parsed, err := ParseAPNsKey(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	if err != nil || !parsed.Equal(key) {
+		t.Fatalf("ParseAPNsKey: %v", err)
+	}
+
+	c := &capture{code: http.StatusOK}
+	srv := c.server(t)
+	a := &APNsProvider{KeyID: "KEY123", TeamID: "TEAM45", Topic: "org.example.vault", Key: parsed, Endpoint: srv.URL}
+	device := strings.Repeat("ab", 32)
+	if err := a.Send(context.Background(), device, Message{MaxVer: 7}); err != nil {
+		t.Fatal(err)
+	}
+	h := c.req.Header
+	if c.req.URL.Path != "/3/device/"+device || h.Get("apns-topic") != "org.example.vault" ||
+		h.Get("apns-push-type") != "background" || h.Get("apns-priority") != "5" {
+		t.Fatalf("request: %s %v", c.req.URL.Path, h)
+	}
+	tok, err := jwt.Parse(strings.TrimPrefix(h.Get("Authorization"), "bearer "),
+		func(*jwt.Token) (any, error) { return &key.PublicKey, nil },
+		jwt.WithValidMethods([]string{"ES256"}))
+	if err != nil || tok.Header["kid"] != "KEY123" || tok.Claims.(jwt.MapClaims)["iss"] != "TEAM45" {
+		t.Fatalf("provider token: %v", err)
+	}
+	if !strings.Contains(string(c.got), `"content-available":1`) {
+		t.Fatalf("payload %s", c.got)
+	}
+
+	c.code, c.body = http.StatusGone, `{"reason":"Unregistered"}`
+	if err := a.Send(context.Background(), device, Message{}); !errors.Is(err, ErrUnregistered) {
+		t.Fatalf("gone: %v", err)
+	}
+	c.code, c.body = http.StatusBadRequest, `{"reason":"BadDeviceToken"}`
+	if err := a.Send(context.Background(), device, Message{}); !errors.Is(err, ErrUnregistered) {
+		t.Fatalf("bad token: %v", err)
+	}
+}
+
+func TestNtfyProvider(t *testing.T) {
+	t.Parallel()
+	c := &capture{code: http.StatusOK}
+	srv := c.server(t)
+	n := &NtfyProvider{BaseURL: srv.URL + "/", AccessToken: "tk_x"}
+	if err := n.Send(context.Background(), "gk-0123456789abcdef", Message{MaxVer: 3}); err != nil {
+		t.Fatal(err)
+	}
+	var p NtfyPayload
+	if c.req.URL.Path != "/gk-0123456789abcdef" || c.req.Header.Get("Authorization") != "Bearer tk_x" ||
+		json.Unmarshal(c.got, &p) != nil || p != (NtfyPayload{Type: EventVaultChanged, Ver: 3}) {
+		t.Fatalf("request %s %v %s", c.req.URL.Path, c.req.Header, c.got)
+	}
+	c.code = http.StatusForbidden
+	if err := n.Send(context.Background(), "gk-0123456789abcdef", Message{}); err == nil {
+		t.Fatal("403 accepted")
+	}
+}
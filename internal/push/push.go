@@ -0,0 +1,70 @@
+// Package push wakes users' devices when their vault changes, so clients
+// can sync at once instead of polling.
+//
+// A push says only that the vault changed and its newest version; clients
+// fetch the changes themselves over the authenticated API. Devices are
+// reached through Firebase Cloud Messaging, Apple Push Notification service
+// or an ntfy server, whichever the operator configures.
+package push
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+// Platforms.
+const (
+	FCM  = "fcm"
+	APNs = "apns"
+	Ntfy = "ntfy"
+)
+
+// Message is the content of a push.
+type Message struct {
+	UserID uuid.UUID
+	// MaxVer is the highest item version in the changes that triggered it;
+	// a client that has seen it can skip the sync.
+	MaxVer int64
+}
+
+// EventVaultChanged is the type field of every push payload.
+const EventVaultChanged = "vault.changed"
+
+// Provider delivers a message to one device token.
+type Provider interface {
+	Send(ctx context.Context, token string, m Message) error
+}
+
+// ErrUnregistered is returned by a Provider when the token is no longer
+// valid (app uninstalled, token rotated); the device should be forgotten.
+var ErrUnregistered = errors.New("push: device token no longer valid")
+
+var ntfyTopic = regexp.MustCompile(`^[A-Za-z0-9_-]{16,64}$`)
+
+// CheckToken validates a device token for platform.
+func CheckToken(platform, token string) error {
+	switch platform {
+	case FCM:
+		if token == "" || len(token) > 4096 || strings.ContainsAny(token, " \t\r\n/") {
+			return errors.New("bad fcm registration token")
+		}
+	case APNs:
+		if _, err := hex.DecodeString(token); err != nil || len(token) < 64 || len(token) > 200 {
+			return errors.New("apns device token must be 64-200 hex digits")
+		}
+	case Ntfy:
+		// anyone who knows the topic can read it: it must be hard to guess
+		if !ntfyTopic.MatchString(token) {
+			return errors.New("ntfy topic must be 16-64 letters, digits, '-' or '_'")
+		}
+	default:
+		return fmt.Errorf("unknown platform %q", platform)
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package push
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckToken(t *testing.T) {
+	t.Parallel()
+	apns := strings.Repeat("ab", 32)
+	cases := []struct {
+		platform, token string
+		ok              bool
+	}{
+		{FCM, "dGhpcyBpcyBhIHRva2Vu:APA91b", true},
+		{FCM, "", false},
+		{FCM, "a b", false},
+		{APNs, apns, true},
+		{APNs, apns[:62], false},
+		{APNs, strings.Repeat("zz", 32), false},
+		{Ntfy, "gk-0123456789abcdef", true},
+		{Ntfy, "short", false},
+		{Ntfy, "gk-0123456789abcdef/../x", false},
+		{"sms", "123", false},
+	}
+	for _, c := range cases {
+		if err := CheckToken(c.platform, c.token); (err == nil) != c.ok {
+			t.Errorf("CheckToken(%q, %q) = %v", c.platform, c.token, err)
+		}
+	}
+}
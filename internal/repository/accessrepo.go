@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/gofrs/uuid/v5"
+)
+
+// AccessLogRepository keeps, per item, which sessions read it and when.
+type AccessLogRepository interface {
+	// Record notes that the session read the user's item at at; sessionID
+	// is uuid.Nil for a token without a session.
+	Record(ctx context.Context, userID, itemID, sessionID uuid.UUID, at time.Time) error
+	// List returns the sessions that read the user's item, most recent
+	// reader first.
+	List(ctx context.Context, userID, itemID uuid.UUID) ([]model.ItemAccess, error)
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/gofrs/uuid/v5"
+)
+
+// ConsistencyRepository exposes what the consistency checker validates and
+// the repairs it may make. Repairs only add records of an item's current
+// state, so running one twice, or on a healthy item, does no harm.
+type ConsistencyRepository interface {
+	// Trace reads the user's items, their history and their journal
+	// entries as of one moment.
+	Trace(ctx context.Context, userID uuid.UUID) (model.VaultTrace, error)
+	// RecordHistory stores the item's current state in its history unless
+	// that version is there already.
+	RecordHistory(ctx context.Context, userID, itemID uuid.UUID) error
+	// RecordJournal journals the item's current state, so readers of the
+	// journal get it again.
+	RecordJournal(ctx context.Context, userID, itemID uuid.UUID) error
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/gofrs/uuid/v5"
+)
+
+// DeviceRepository stores users' push notification targets.
+type DeviceRepository interface {
+	// Register stores d, or renames the user's device with the same
+	// platform and token, setting d's ID and creation time. New devices are
+	// refused once the user has max.
+	Register(ctx context.Context, d *model.Device, max int) error
+	// List returns the user's devices, oldest first.
+	List(ctx context.Context, userID uuid.UUID) ([]model.Device, error)
+	// Delete removes one of the user's devices.
+	Delete(ctx context.Context, userID, id uuid.UUID) error
+	// DeleteToken removes every device with this platform token, once the
+	// provider reports it gone.
+	DeleteToken(ctx context.Context, platform, token string) error
+}
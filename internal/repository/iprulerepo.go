@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+	"net/netip"
+
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/gofrs/uuid/v5"
+)
+
+// IPRuleRepository stores users' client address rules. Admins name users
+// by username, within the tenant of the context.
+type IPRuleRepository interface {
+	// Rules returns the user's rules, oldest first.
+	Rules(ctx context.Context, userID uuid.UUID) ([]model.IPRule, error)
+	// RulesByUsername is Rules for the named user; none if there is no such user.
+	RulesByUsername(ctx context.Context, username string) ([]model.IPRule, error)
+	// Add stores r for the named user, replacing the rule for the same
+	// network, and sets r's creation time. ErrNotFound if there is no such
+	// user; new rules are refused once the user has max.
+	Add(ctx context.Context, username string, r *model.IPRule, max int) error
+	// Remove deletes the named user's rule for cidr; ErrNotFound if none.
+	Remove(ctx context.Context, username string, cidr netip.Prefix) error
+}
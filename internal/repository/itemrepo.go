@@ -12,15 +12,64 @@ type ItemRepository interface {
 	// UpsertBatch inserts or updates items using optimistic concurrency.
 	UpsertBatch(ctx context.Context, userID uuid.UUID, items []model.UpsertItem) ([]model.ItemVersion, error)
 
+	// UpsertEach is UpsertBatch where each item stands alone: a stale base
+	// version yields a StatusVersionConflict result instead of failing the
+	// batch. The error is for storage failures and the quota.
+	UpsertEach(ctx context.Context, userID uuid.UUID, items []model.UpsertItem) ([]model.ItemResult, error)
+
 	// Delete sets tombstone on item (ver++) with base version check.
 	Delete(ctx context.Context, userID, itemID uuid.UUID, baseVer int64) (model.ItemVersion, error)
 
-	// GetChangesSince returns all changes with version greater than sinceVer.
-	GetChangesSince(ctx context.Context, userID uuid.UUID, sinceVer int64) ([]model.Change, error)
+	// DeleteBatch tombstones the items in one transaction. It returns a result
+	// per ref and commits only if all of them are StatusOK; otherwise the OK
+	// ones come back as StatusAborted. The error is for storage failures.
+	DeleteBatch(ctx context.Context, userID uuid.UUID, refs []model.ItemRef) ([]model.ItemResult, error)
+
+	// ApplyChangeSet writes cs's upserts and tombstones its deletes in one
+	// transaction, checking every entry like DeleteBatch and committing only
+	// if all are StatusOK. An upsert of an id another user holds is a
+	// StatusVersionConflict. The error is for storage failures and the quota.
+	ApplyChangeSet(ctx context.Context, userID uuid.UUID, cs model.ChangeSet) (model.ChangeSetResult, error)
+
+	// GetChangesSince returns up to limit changes after the cursor, in
+	// (ver, id) order; limit <= 0 returns them all.
+	GetChangesSince(ctx context.Context, userID uuid.UUID, after model.ChangeCursor, limit int) ([]model.Change, error)
+
+	// GetJournalSince returns up to limit changes from the change journal
+	// after seq, in seq order: for each item changed since, its newest
+	// entry with the item as it is now. A removed item comes as a
+	// tombstone. limit <= 0 returns them all.
+	GetJournalSince(ctx context.Context, userID uuid.UUID, afterSeq int64, limit int) ([]model.Change, error)
+
+	// JournalHead returns the seq of the user's newest journal entry, 0
+	// when there is none.
+	JournalHead(ctx context.Context, userID uuid.UUID) (int64, error)
+
+	// GetSnapshot returns up to limit of the user's items after afterID,
+	// in id order, as they stood at journal position atSeq: each at the
+	// version of its last entry up to atSeq, read from history, with Seq
+	// set to that entry. Items first written after atSeq, or removed by
+	// then, are left out. It fails with errs.ErrSnapshotGone when such a
+	// version is no longer in history. limit <= 0 returns them all.
+	GetSnapshot(ctx context.Context, userID uuid.UUID, atSeq int64, afterID uuid.UUID, limit int) ([]model.Change, error)
 
 	// GetItem returns a single item by ID.
 	GetItem(ctx context.Context, userID, itemID uuid.UUID) (*model.Item, error)
 
+	// GetItemVersion returns an item as it was stored at version ver.
+	GetItemVersion(ctx context.Context, userID, itemID uuid.UUID, ver int64) (*model.Item, error)
+
+	// ExportItems calls fn with each of the user's items, tombstones
+	// included, in id order, all read from one snapshot. It stops at the
+	// first error fn returns and returns it.
+	ExportItems(ctx context.Context, userID uuid.UUID, fn func(model.Item) error) error
+
+	// Stats returns aggregate counters over a user's items.
+	Stats(ctx context.Context, userID uuid.UUID) (model.ItemStats, error)
+
+	// Digests returns every item of a user (tombstones included) with its blob hash.
+	Digests(ctx context.Context, userID uuid.UUID) ([]model.ItemDigest, error)
+
 	// GetMaxVersion returns the latest version for a user.
 	GetMaxVersion(ctx context.Context, userID uuid.UUID) (int64, error)
 }
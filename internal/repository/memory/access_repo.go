@@ -0,0 +1,70 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/and161185/goph-keeper/internal/tenant"
+	"github.com/gofrs/uuid/v5"
+)
+
+type accessRow struct {
+	tenant string
+	userID uuid.UUID
+	itemID uuid.UUID
+	access model.ItemAccess
+}
+
+// AccessLogRepo implements AccessLogRepository in memory.
+type AccessLogRepo struct {
+	sessions *SessionRepo
+
+	mu   sync.Mutex
+	rows []accessRow
+}
+
+// NewAccessLogRepo constructs an empty access log that takes device labels
+// from sessions, which may be nil.
+func NewAccessLogRepo(sessions *SessionRepo) *AccessLogRepo {
+	return &AccessLogRepo{sessions: sessions}
+}
+
+// Record counts a read by the session, adding its row on the first one.
+func (r *AccessLogRepo) Record(ctx context.Context, userID, itemID, sessionID uuid.UUID, at time.Time) error {
+	tid := tenant.FromContext(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, row := range r.rows {
+		if row.tenant == tid && row.itemID == itemID && row.access.SessionID == sessionID {
+			if at.After(row.access.LastAt) {
+				r.rows[i].access.LastAt = at
+			}
+			r.rows[i].access.Reads++
+			return nil
+		}
+	}
+	a := model.ItemAccess{SessionID: sessionID, FirstAt: at, LastAt: at, Reads: 1}
+	if r.sessions != nil {
+		a.Label = r.sessions.label(tid, sessionID)
+	}
+	r.rows = append(r.rows, accessRow{tenant: tid, userID: userID, itemID: itemID, access: a})
+	return nil
+}
+
+// List returns the item's rows, newest read first.
+func (r *AccessLogRepo) List(ctx context.Context, userID, itemID uuid.UUID) ([]model.ItemAccess, error) {
+	tid := tenant.FromContext(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []model.ItemAccess
+	for _, row := range r.rows {
+		if row.tenant == tid && row.userID == userID && row.itemID == itemID {
+			out = append(out, row.access)
+		}
+	}
+	slices.SortStableFunc(out, func(a, b model.ItemAccess) int { return b.LastAt.Compare(a.LastAt) })
+	return out, nil
+}
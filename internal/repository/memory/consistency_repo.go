@@ -0,0 +1,90 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/gofrs/uuid/v5"
+)
+
+// Trace implements repository.ConsistencyRepository. Items are never
+// rolled back here.
+func (r *ItemRepo) Trace(_ context.Context, userID uuid.UUID) (model.VaultTrace, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var t model.VaultTrace
+	index := map[uuid.UUID]int{}
+	for id, row := range r.items {
+		it := row.item
+		if it.UserID != userID {
+			continue
+		}
+		tr := model.ItemTrace{ID: id, Ver: it.Ver, Deleted: it.Deleted, BlobLen: len(it.BlobEnc)}
+		for _, h := range r.history[id] {
+			tr.History = append(tr.History, model.TraceVersion{Ver: h.Ver, Deleted: h.Deleted})
+		}
+		index[id] = len(t.Items)
+		t.Items = append(t.Items, tr)
+	}
+	for i, e := range r.journal {
+		j, ok := index[e.itemID]
+		if !ok || e.userID != userID {
+			continue
+		}
+		op := "upsert"
+		if e.deleted {
+			op = "delete"
+		}
+		t.Items[j].Journal = append(t.Items[j].Journal, model.TraceEntry{Seq: int64(i) + 1, Op: op, Ver: e.ver, At: e.at})
+	}
+	return t, nil
+}
+
+// item returns the user's item in any tenant; the caller holds r.mu.
+func (r *ItemRepo) item(userID, itemID uuid.UUID) (*itemRow, error) {
+	row, ok := r.items[itemID]
+	if !ok || row.item.UserID != userID {
+		return nil, errs.ErrNotFound
+	}
+	return row, nil
+}
+
+// RecordHistory implements repository.ConsistencyRepository.
+func (r *ItemRepo) RecordHistory(_ context.Context, userID, itemID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	row, err := r.item(userID, itemID)
+	if err != nil {
+		return err
+	}
+	for _, h := range r.history[itemID] {
+		if h.Ver == row.item.Ver {
+			return nil
+		}
+	}
+	r.history[itemID] = append(r.history[itemID], row.item)
+	return nil
+}
+
+// RecordJournal implements repository.ConsistencyRepository. It adds
+// nothing when the item's newest entry already records its current row.
+func (r *ItemRepo) RecordJournal(_ context.Context, userID, itemID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	row, err := r.item(userID, itemID)
+	if err != nil {
+		return err
+	}
+	it := row.item
+	for i := len(r.journal) - 1; i >= 0; i-- {
+		if e := r.journal[i]; e.itemID == itemID {
+			if e.ver == it.Ver && e.deleted == it.Deleted {
+				return nil
+			}
+			break
+		}
+	}
+	r.journal = append(r.journal, journalEntry{tenant: row.tenant, userID: userID, itemID: itemID, ver: it.Ver, deleted: it.Deleted, at: it.UpdatedAt})
+	return nil
+}
@@ -0,0 +1,93 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/and161185/goph-keeper/internal/tenant"
+	"github.com/gofrs/uuid/v5"
+)
+
+type deviceRow struct {
+	tenant string
+	device model.Device
+}
+
+// DeviceRepo implements DeviceRepository in memory.
+type DeviceRepo struct {
+	mu      sync.Mutex
+	devices []deviceRow // in creation order
+}
+
+// NewDeviceRepo constructs an empty device repository.
+func NewDeviceRepo() *DeviceRepo { return &DeviceRepo{} }
+
+// Register stores d, or renames the user's device with the same platform
+// and token, which never counts against max.
+func (r *DeviceRepo) Register(ctx context.Context, d *model.Device, max int) error {
+	tid := tenant.FromContext(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for i, row := range r.devices {
+		if row.device.UserID != d.UserID {
+			continue
+		}
+		if row.device.Platform == d.Platform && row.device.Token == d.Token {
+			r.devices[i].device.Name = d.Name
+			d.ID, d.CreatedAt = row.device.ID, row.device.CreatedAt
+			return nil
+		}
+		n++
+	}
+	if n >= max {
+		return fmt.Errorf("%w: at most %d devices per user", errs.ErrQuotaExceeded, max)
+	}
+	d.CreatedAt = time.Now().UTC()
+	r.devices = append(r.devices, deviceRow{tenant: tid, device: *d})
+	return nil
+}
+
+// List returns the user's devices, oldest first.
+func (r *DeviceRepo) List(ctx context.Context, userID uuid.UUID) ([]model.Device, error) {
+	tid := tenant.FromContext(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []model.Device
+	for _, row := range r.devices {
+		if row.tenant == tid && row.device.UserID == userID {
+			out = append(out, row.device)
+		}
+	}
+	return out, nil
+}
+
+// Delete removes one of the user's devices.
+func (r *DeviceRepo) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	tid := tenant.FromContext(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, row := range r.devices {
+		if row.tenant == tid && row.device.UserID == userID && row.device.ID == id {
+			r.devices = slices.Delete(r.devices, i, i+1)
+			return nil
+		}
+	}
+	return errs.ErrNotFound
+}
+
+// DeleteToken removes the tenant's registrations of a retired token.
+func (r *DeviceRepo) DeleteToken(ctx context.Context, platform, token string) error {
+	tid := tenant.FromContext(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.devices = slices.DeleteFunc(r.devices, func(row deviceRow) bool {
+		return row.tenant == tid && row.device.Platform == platform && row.device.Token == token
+	})
+	return nil
+}
@@ -0,0 +1,461 @@
+package memory
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/and161185/goph-keeper/internal/tenant"
+	"github.com/gofrs/uuid/v5"
+)
+
+type itemRow struct {
+	tenant string
+	item   model.Item
+}
+
+// ItemRepo implements ItemRepository in memory. Every stored version is
+// kept, as item_history does, and journaled, as item_changes does. Blobs
+// are copied on the way in and shared on the way out; callers must not
+// modify them.
+type ItemRepo struct {
+	mu      sync.Mutex
+	items   map[uuid.UUID]*itemRow
+	history map[uuid.UUID][]model.Item
+	journal []journalEntry
+}
+
+// journalEntry is one item write; its seq is its index plus one.
+type journalEntry struct {
+	tenant  string
+	userID  uuid.UUID
+	itemID  uuid.UUID
+	ver     int64
+	deleted bool
+	at      time.Time
+}
+
+// NewItemRepo constructs an empty item repository.
+func NewItemRepo() *ItemRepo {
+	return &ItemRepo{items: make(map[uuid.UUID]*itemRow), history: make(map[uuid.UUID][]model.Item)}
+}
+
+// owned returns the row of the user's item id; the caller holds r.mu.
+func (r *ItemRepo) owned(tid string, userID, id uuid.UUID) (*itemRow, bool) {
+	row, ok := r.items[id]
+	if !ok || row.tenant != tid || row.item.UserID != userID {
+		return nil, false
+	}
+	return row, true
+}
+
+// store writes it and records the version; the caller holds r.mu.
+func (r *ItemRepo) store(tid string, it model.Item) {
+	r.items[it.ID] = &itemRow{tenant: tid, item: it}
+	r.history[it.ID] = append(r.history[it.ID], it)
+	r.journal = append(r.journal, journalEntry{tenant: tid, userID: it.UserID, itemID: it.ID, ver: it.Ver, deleted: it.Deleted, at: it.UpdatedAt})
+}
+
+// UpsertBatch applies all of ups or none of them.
+func (r *ItemRepo) UpsertBatch(ctx context.Context, userID uuid.UUID, ups []model.UpsertItem) ([]model.ItemVersion, error) {
+	tid := tenant.FromContext(ctx)
+	now := time.Now().UTC()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// stage the batch so a failing item leaves the store untouched
+	staged := make(map[uuid.UUID]model.Item, len(ups))
+	order := make([]uuid.UUID, 0, len(ups))
+	results := make([]model.ItemVersion, 0, len(ups))
+	for i, up := range ups {
+		cur, ok := staged[up.ID]
+		if !ok {
+			if row, found := r.owned(tid, userID, up.ID); found {
+				cur, ok = row.item, true
+			} else if _, taken := r.items[up.ID]; taken {
+				return nil, fmt.Errorf("item[%d]: %w: id in use", i, errs.ErrAlreadyExists)
+			}
+		}
+		if (ok && cur.Ver != up.BaseVer) || (!ok && up.BaseVer != 0) {
+			return nil, &errs.ConflictError{Index: i, ID: up.ID, Ver: cur.Ver, UpdatedAt: cur.UpdatedAt}
+		}
+		it := model.Item{ID: up.ID, UserID: userID, BlobEnc: slices.Clone(up.BlobEnc), Ver: up.BaseVer + 1, UpdatedAt: now, Hints: up.Hints}
+		if _, seen := staged[up.ID]; !seen {
+			order = append(order, up.ID)
+		}
+		staged[up.ID] = it
+		results = append(results, model.ItemVersion{ID: up.ID, NewVer: it.Ver, UpdatedAt: now})
+	}
+	for _, id := range order {
+		r.store(tid, staged[id])
+	}
+	return results, nil
+}
+
+// UpsertEach stores every item whose base version matches and reports the
+// others as conflicts, as does an id held by another user.
+func (r *ItemRepo) UpsertEach(ctx context.Context, userID uuid.UUID, ups []model.UpsertItem) ([]model.ItemResult, error) {
+	tid := tenant.FromContext(ctx)
+	now := time.Now().UTC()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	results := make([]model.ItemResult, 0, len(ups))
+	for _, up := range ups {
+		var cur model.Item
+		if row, found := r.owned(tid, userID, up.ID); found {
+			cur = row.item
+		} else if _, taken := r.items[up.ID]; taken {
+			results = append(results, model.ItemResult{ID: up.ID, Status: model.StatusVersionConflict})
+			continue
+		}
+		if cur.Ver != up.BaseVer {
+			results = append(results, model.ItemResult{ID: up.ID, Status: model.StatusVersionConflict, Ver: cur.Ver, UpdatedAt: cur.UpdatedAt})
+			continue
+		}
+		r.store(tid, model.Item{ID: up.ID, UserID: userID, BlobEnc: slices.Clone(up.BlobEnc), Ver: cur.Ver + 1, UpdatedAt: now, Hints: up.Hints})
+		results = append(results, model.ItemResult{ID: up.ID, Status: model.StatusOK, Ver: cur.Ver + 1, UpdatedAt: now})
+	}
+	return results, nil
+}
+
+// Delete turns the user's item into a tombstone at the next version.
+func (r *ItemRepo) Delete(ctx context.Context, userID, itemID uuid.UUID, baseVer int64) (model.ItemVersion, error) {
+	tid := tenant.FromContext(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	row, ok := r.owned(tid, userID, itemID)
+	if !ok {
+		return model.ItemVersion{}, errs.ErrNotFound
+	}
+	if row.item.Ver != baseVer {
+		return model.ItemVersion{}, &errs.ConflictError{ID: itemID, Ver: row.item.Ver, UpdatedAt: row.item.UpdatedAt}
+	}
+	it := row.item
+	it.Ver++
+	it.Deleted = true
+	it.UpdatedAt = time.Now().UTC()
+	r.store(tid, it)
+	return model.ItemVersion{ID: itemID, NewVer: it.Ver, UpdatedAt: it.UpdatedAt}, nil
+}
+
+// DeleteBatch tombstones the user's items if every ref matches, staging
+// them like UpsertBatch.
+func (r *ItemRepo) DeleteBatch(ctx context.Context, userID uuid.UUID, refs []model.ItemRef) ([]model.ItemResult, error) {
+	tid := tenant.FromContext(ctx)
+	now := time.Now().UTC()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	staged := make(map[uuid.UUID]model.Item, len(refs))
+	order := make([]uuid.UUID, 0, len(refs))
+	results := make([]model.ItemResult, 0, len(refs))
+	for _, ref := range refs {
+		cur, ok := staged[ref.ID]
+		if !ok {
+			row, found := r.owned(tid, userID, ref.ID)
+			if !found {
+				results = append(results, model.ItemResult{ID: ref.ID, Status: model.StatusNotFound})
+				continue
+			}
+			cur = row.item
+			order = append(order, ref.ID)
+		}
+		if cur.Ver != ref.BaseVer {
+			results = append(results, model.ItemResult{ID: ref.ID, Status: model.StatusVersionConflict, Ver: cur.Ver, UpdatedAt: cur.UpdatedAt})
+			staged[ref.ID] = cur
+			continue
+		}
+		cur.Ver++
+		cur.Deleted = true
+		cur.UpdatedAt = now
+		staged[ref.ID] = cur
+		results = append(results, model.ItemResult{ID: ref.ID, Status: model.StatusOK, Ver: cur.Ver, UpdatedAt: now})
+	}
+	if !model.AllOK(results) {
+		model.AbortOK(results)
+		return results, nil
+	}
+	for _, id := range order {
+		r.store(tid, staged[id])
+	}
+	return results, nil
+}
+
+// ApplyChangeSet stages cs's upserts, then its deletes, and stores them
+// only if every one matches.
+func (r *ItemRepo) ApplyChangeSet(ctx context.Context, userID uuid.UUID, cs model.ChangeSet) (model.ChangeSetResult, error) {
+	tid := tenant.FromContext(ctx)
+	now := time.Now().UTC()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	staged := make(map[uuid.UUID]model.Item, len(cs.Upserts)+len(cs.Deletes))
+	order := make([]uuid.UUID, 0, len(staged))
+	current := func(id uuid.UUID) (model.Item, bool) {
+		if it, ok := staged[id]; ok {
+			return it, true
+		}
+		if row, ok := r.owned(tid, userID, id); ok {
+			return row.item, true
+		}
+		return model.Item{}, false
+	}
+	stage := func(it model.Item) {
+		if _, seen := staged[it.ID]; !seen {
+			order = append(order, it.ID)
+		}
+		staged[it.ID] = it
+	}
+
+	res := model.ChangeSetResult{
+		Upserts: make([]model.ItemResult, 0, len(cs.Upserts)),
+		Deletes: make([]model.ItemResult, 0, len(cs.Deletes)),
+	}
+	for _, up := range cs.Upserts {
+		cur, ok := current(up.ID)
+		if _, taken := r.items[up.ID]; !ok && taken {
+			res.Upserts = append(res.Upserts, model.ItemResult{ID: up.ID, Status: model.StatusVersionConflict})
+			continue
+		}
+		if cur.Ver != up.BaseVer {
+			res.Upserts = append(res.Upserts, model.ItemResult{ID: up.ID, Status: model.StatusVersionConflict, Ver: cur.Ver, UpdatedAt: cur.UpdatedAt})
+			continue
+		}
+		stage(model.Item{ID: up.ID, UserID: userID, BlobEnc: slices.Clone(up.BlobEnc), Ver: cur.Ver + 1, UpdatedAt: now, Hints: up.Hints})
+		res.Upserts = append(res.Upserts, model.ItemResult{ID: up.ID, Status: model.StatusOK, Ver: cur.Ver + 1, UpdatedAt: now})
+	}
+	for _, ref := range cs.Deletes {
+		cur, ok := current(ref.ID)
+		switch {
+		case !ok:
+			res.Deletes = append(res.Deletes, model.ItemResult{ID: ref.ID, Status: model.StatusNotFound})
+		case cur.Ver != ref.BaseVer:
+			res.Deletes = append(res.Deletes, model.ItemResult{ID: ref.ID, Status: model.StatusVersionConflict, Ver: cur.Ver, UpdatedAt: cur.UpdatedAt})
+		default:
+			cur.Ver++
+			cur.Deleted = true
+			cur.UpdatedAt = now
+			stage(cur)
+			res.Deletes = append(res.Deletes, model.ItemResult{ID: ref.ID, Status: model.StatusOK, Ver: cur.Ver, UpdatedAt: now})
+		}
+	}
+	if !res.Applied() {
+		model.AbortOK(res.Upserts)
+		model.AbortOK(res.Deletes)
+		return res, nil
+	}
+	for _, id := range order {
+		r.store(tid, staged[id])
+	}
+	return res, nil
+}
+
+// GetChangesSince returns a page of the user's items after the cursor.
+func (r *ItemRepo) GetChangesSince(ctx context.Context, userID uuid.UUID, after model.ChangeCursor, limit int) ([]model.Change, error) {
+	var out []model.Change
+	for _, it := range r.list(ctx, userID) {
+		if cmp.Or(cmp.Compare(it.Ver, after.Ver), bytes.Compare(it.ID[:], after.ID[:])) <= 0 {
+			continue
+		}
+		ch := model.Change{ID: it.ID, Ver: it.Ver, Deleted: it.Deleted, UpdatedAt: it.UpdatedAt, Hints: it.Hints}
+		if !it.Deleted {
+			ch.BlobEnc = it.BlobEnc
+		}
+		out = append(out, ch)
+	}
+	slices.SortFunc(out, func(a, b model.Change) int {
+		return cmp.Or(cmp.Compare(a.Ver, b.Ver), bytes.Compare(a.ID[:], b.ID[:]))
+	})
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+// GetJournalSince returns the user's items written after seq, each at the
+// seq of its last write.
+func (r *ItemRepo) GetJournalSince(ctx context.Context, userID uuid.UUID, afterSeq int64, limit int) ([]model.Change, error) {
+	tid := tenant.FromContext(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	newest := map[uuid.UUID]int64{}
+	for i, e := range r.journal {
+		if e.tenant == tid && e.userID == userID {
+			newest[e.itemID] = int64(i) + 1
+		}
+	}
+	var out []model.Change
+	for i := max(afterSeq, 0); i < int64(len(r.journal)); i++ {
+		e := r.journal[i]
+		if e.tenant != tid || e.userID != userID || newest[e.itemID] != i+1 {
+			continue
+		}
+		it := r.items[e.itemID].item
+		ch := model.Change{ID: it.ID, Ver: it.Ver, Deleted: it.Deleted, UpdatedAt: it.UpdatedAt, Hints: it.Hints, Seq: i + 1}
+		if !it.Deleted {
+			ch.BlobEnc = it.BlobEnc
+		}
+		out = append(out, ch)
+		if limit > 0 && len(out) == limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+// JournalHead returns the seq of the user's newest journal entry.
+func (r *ItemRepo) JournalHead(ctx context.Context, userID uuid.UUID) (int64, error) {
+	tid := tenant.FromContext(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := len(r.journal) - 1; i >= 0; i-- {
+		if e := r.journal[i]; e.tenant == tid && e.userID == userID {
+			return int64(i) + 1, nil
+		}
+	}
+	return 0, nil
+}
+
+// GetSnapshot returns a page of the user's items as of journal seq atSeq,
+// each at the version of its last entry by then.
+func (r *ItemRepo) GetSnapshot(ctx context.Context, userID uuid.UUID, atSeq int64, afterID uuid.UUID, limit int) ([]model.Change, error) {
+	tid := tenant.FromContext(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	last := map[uuid.UUID]int64{}
+	for i := range min(max(atSeq, 0), int64(len(r.journal))) {
+		if e := r.journal[i]; e.tenant == tid && e.userID == userID && bytes.Compare(e.itemID[:], afterID[:]) > 0 {
+			last[e.itemID] = i + 1
+		}
+	}
+	ids := make([]uuid.UUID, 0, len(last))
+	for id := range last {
+		ids = append(ids, id)
+	}
+	slices.SortFunc(ids, func(a, b uuid.UUID) int { return bytes.Compare(a[:], b[:]) })
+	if limit > 0 && len(ids) > limit {
+		ids = ids[:limit]
+	}
+	out := make([]model.Change, 0, len(ids))
+	for _, id := range ids {
+		seq := last[id]
+		ver := r.journal[seq-1].ver
+		i := slices.IndexFunc(r.history[id], func(it model.Item) bool { return it.Ver == ver })
+		if i < 0 {
+			return nil, errs.ErrSnapshotGone
+		}
+		it := r.history[id][i]
+		ch := model.Change{ID: it.ID, Ver: it.Ver, Deleted: it.Deleted, UpdatedAt: it.UpdatedAt, Hints: it.Hints, Seq: seq}
+		if !it.Deleted {
+			ch.BlobEnc = it.BlobEnc
+		}
+		out = append(out, ch)
+	}
+	return out, nil
+}
+
+// GetItem returns the user's item.
+func (r *ItemRepo) GetItem(ctx context.Context, userID, itemID uuid.UUID) (*model.Item, error) {
+	tid := tenant.FromContext(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	row, ok := r.owned(tid, userID, itemID)
+	if !ok {
+		return nil, errs.ErrNotFound
+	}
+	it := row.item
+	return &it, nil
+}
+
+// GetItemVersion returns the user's item as stored at version ver.
+func (r *ItemRepo) GetItemVersion(ctx context.Context, userID, itemID uuid.UUID, ver int64) (*model.Item, error) {
+	tid := tenant.FromContext(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.owned(tid, userID, itemID); !ok {
+		return nil, errs.ErrNotFound
+	}
+	for _, it := range r.history[itemID] {
+		if it.Ver == ver {
+			return &it, nil
+		}
+	}
+	return nil, errs.ErrNotFound
+}
+
+// Stats aggregates the user's items.
+func (r *ItemRepo) Stats(ctx context.Context, userID uuid.UUID) (model.ItemStats, error) {
+	var st model.ItemStats
+	for _, it := range r.list(ctx, userID) {
+		st.Items++
+		st.MaxVer = max(st.MaxVer, it.Ver)
+		if it.Deleted {
+			st.Deleted++
+		} else {
+			st.TotalBytes += int64(len(it.BlobEnc))
+		}
+		if it.UpdatedAt.After(st.LastUpdated) {
+			st.LastUpdated = it.UpdatedAt
+		}
+	}
+	return st, nil
+}
+
+// ExportItems calls fn with the user's items ordered by id, as they were
+// when it was called.
+func (r *ItemRepo) ExportItems(ctx context.Context, userID uuid.UUID, fn func(model.Item) error) error {
+	items := r.list(ctx, userID)
+	slices.SortFunc(items, func(a, b model.Item) int { return cmp.Compare(a.ID.String(), b.ID.String()) })
+	for _, it := range items {
+		if err := fn(it); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Digests returns the user's items with blob hashes, ordered by id.
+func (r *ItemRepo) Digests(ctx context.Context, userID uuid.UUID) ([]model.ItemDigest, error) {
+	items := r.list(ctx, userID)
+	out := make([]model.ItemDigest, 0, len(items))
+	for _, it := range items {
+		d := model.ItemDigest{ID: it.ID, Ver: it.Ver, Deleted: it.Deleted}
+		if !it.Deleted {
+			h := sha256.Sum256(it.BlobEnc)
+			d.BlobHash = h[:]
+		}
+		out = append(out, d)
+	}
+	slices.SortFunc(out, func(a, b model.ItemDigest) int { return cmp.Compare(a.ID.String(), b.ID.String()) })
+	return out, nil
+}
+
+// GetMaxVersion returns the highest version among the user's items.
+func (r *ItemRepo) GetMaxVersion(ctx context.Context, userID uuid.UUID) (int64, error) {
+	st, err := r.Stats(ctx, userID)
+	return st.MaxVer, err
+}
+
+// list copies the user's current items.
+func (r *ItemRepo) list(ctx context.Context, userID uuid.UUID) []model.Item {
+	tid := tenant.FromContext(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []model.Item
+	for _, row := range r.items {
+		if row.tenant == tid && row.item.UserID == userID {
+			out = append(out, row.item)
+		}
+	}
+	return out
+}
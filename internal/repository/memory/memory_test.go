@@ -0,0 +1,454 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/and161185/goph-keeper/internal/repository"
+	"github.com/and161185/goph-keeper/internal/tenant"
+	"github.com/gofrs/uuid/v5"
+)
+
+var (
+	_ repository.UserRepository        = (*UserRepo)(nil)
+	_ repository.ItemRepository        = (*ItemRepo)(nil)
+	_ repository.WebhookRepository     = (*WebhookRepo)(nil)
+	_ repository.DeviceRepository      = (*DeviceRepo)(nil)
+	_ repository.SessionRepository     = (*SessionRepo)(nil)
+	_ repository.AccessLogRepository   = (*AccessLogRepo)(nil)
+	_ repository.ConsistencyRepository = (*ItemRepo)(nil)
+)
+
+func TestItemRepo_Versions(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	r := NewItemRepo()
+	user := uuid.Must(uuid.NewV4())
+	a, b := uuid.Must(uuid.NewV7()), uuid.Must(uuid.NewV7())
+
+	res, err := r.UpsertBatch(ctx, user, []model.UpsertItem{{ID: a, BlobEnc: []byte("a1")}, {ID: b, BlobEnc: []byte("b1")}})
+	if err != nil || len(res) != 2 || res[0].NewVer != 1 {
+		t.Fatalf("insert: %v %v", res, err)
+	}
+	if _, err := r.UpsertBatch(ctx, user, []model.UpsertItem{{ID: a, BaseVer: 1, BlobEnc: []byte("a2")}, {ID: b, BaseVer: 7, BlobEnc: []byte("b2")}}); !errors.Is(err, errs.ErrVersionConflict) {
+		t.Fatalf("stale base: %v", err)
+	}
+	if it, _ := r.GetItem(ctx, user, a); it.Ver != 1 || string(it.BlobEnc) != "a1" {
+		t.Fatalf("a failed batch must change nothing: %+v", it)
+	}
+	hints := model.ItemHints{SizeClass: model.SizeSmall, SchemaVersion: 2}
+	if _, err := r.UpsertBatch(ctx, user, []model.UpsertItem{{ID: a, BaseVer: 1, BlobEnc: []byte("a2"), Hints: hints}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Delete(ctx, user, b, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	chs, _ := r.GetChangesSince(ctx, user, model.AfterVer(1), 0)
+	if len(chs) != 2 || chs[0].Ver != 2 || chs[1].Ver != 2 {
+		t.Fatalf("changes: %+v", chs)
+	}
+	// both sit at version 2: the id carries the second page on
+	page, _ := r.GetChangesSince(ctx, user, model.AfterVer(1), 1)
+	rest, _ := r.GetChangesSince(ctx, user, model.ChangeCursor{Ver: page[0].Ver, ID: page[0].ID}, 1)
+	if len(page) != 1 || len(rest) != 1 || page[0].ID != chs[0].ID || rest[0].ID != chs[1].ID {
+		t.Fatalf("pages: %+v %+v", page, rest)
+	}
+	for _, c := range chs {
+		if c.Deleted != (c.ID == b) || c.Deleted != (c.BlobEnc == nil) || (c.ID == a) != (c.Hints == hints) {
+			t.Fatalf("change %+v", c)
+		}
+	}
+	old, err := r.GetItemVersion(ctx, user, a, 1)
+	if err != nil || string(old.BlobEnc) != "a1" || old.Hints != (model.ItemHints{}) {
+		t.Fatalf("history: %+v %v", old, err)
+	}
+	st, _ := r.Stats(ctx, user)
+	if st.Items != 2 || st.Deleted != 1 || st.MaxVer != 2 || st.TotalBytes != 2 {
+		t.Fatalf("stats: %+v", st)
+	}
+	ds, _ := r.Digests(ctx, user)
+	if len(ds) != 2 || ds[0].ID.String() > ds[1].ID.String() {
+		t.Fatalf("digests: %+v", ds)
+	}
+}
+
+func TestItemRepo_Isolation(t *testing.T) {
+	t.Parallel()
+	r := NewItemRepo()
+	alice, bob := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	id := uuid.Must(uuid.NewV7())
+	acme := tenant.WithID(context.Background(), "acme")
+
+	if _, err := r.UpsertBatch(acme, alice, []model.UpsertItem{{ID: id, BlobEnc: []byte{1}}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.GetItem(context.Background(), alice, id); !errors.Is(err, errs.ErrNotFound) {
+		t.Fatalf("other tenant: %v", err)
+	}
+	if _, err := r.GetItem(acme, bob, id); !errors.Is(err, errs.ErrNotFound) {
+		t.Fatalf("other user: %v", err)
+	}
+	if _, err := r.UpsertBatch(acme, bob, []model.UpsertItem{{ID: id, BlobEnc: []byte{2}}}); err == nil {
+		t.Fatal("another user's id must not be taken over")
+	}
+}
+
+func TestItemRepo_UpsertEach(t *testing.T) {
+	t.Parallel()
+	r := NewItemRepo()
+	ctx := context.Background()
+	alice, bob := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	a, b, c := uuid.Must(uuid.NewV7()), uuid.Must(uuid.NewV7()), uuid.Must(uuid.NewV7())
+	if _, err := r.UpsertBatch(ctx, alice, []model.UpsertItem{{ID: a, BlobEnc: []byte{1}}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.UpsertBatch(ctx, bob, []model.UpsertItem{{ID: c, BlobEnc: []byte{1}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := r.UpsertEach(ctx, alice, []model.UpsertItem{
+		{ID: a, BaseVer: 3, BlobEnc: []byte{2}},
+		{ID: b, BlobEnc: []byte{2}},
+		{ID: c, BlobEnc: []byte{2}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []model.ItemStatus{model.StatusVersionConflict, model.StatusOK, model.StatusVersionConflict}
+	for i, got := range res {
+		if got.Status != want[i] {
+			t.Fatalf("item %d: %+v", i, got)
+		}
+	}
+	if res[0].Ver != 1 {
+		t.Fatalf("conflict should report version 1: %+v", res[0])
+	}
+	if it, err := r.GetItem(ctx, alice, b); err != nil || it.Ver != 1 {
+		t.Fatalf("fresh item not written: %+v %v", it, err)
+	}
+}
+
+func TestItemRepo_DeleteBatch(t *testing.T) {
+	t.Parallel()
+	r := NewItemRepo()
+	ctx := context.Background()
+	user := uuid.Must(uuid.NewV4())
+	a, b := uuid.Must(uuid.NewV7()), uuid.Must(uuid.NewV7())
+	if _, err := r.UpsertBatch(ctx, user, []model.UpsertItem{{ID: a, BlobEnc: []byte{1}}, {ID: b, BlobEnc: []byte{2}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// a repeated id sees the first delete and conflicts; nothing is applied
+	res, err := r.DeleteBatch(ctx, user, []model.ItemRef{{ID: a, BaseVer: 1}, {ID: a, BaseVer: 1}, {ID: uuid.Must(uuid.NewV7())}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res[0].Status != model.StatusAborted || res[1].Status != model.StatusVersionConflict || res[1].Ver != 2 || res[2].Status != model.StatusNotFound {
+		t.Fatalf("results %+v", res)
+	}
+	if it, _ := r.GetItem(ctx, user, a); it.Deleted || it.Ver != 1 {
+		t.Fatalf("failed batch applied: %+v", it)
+	}
+
+	res, err = r.DeleteBatch(ctx, user, []model.ItemRef{{ID: a, BaseVer: 1}, {ID: b, BaseVer: 1}})
+	if err != nil || !model.AllOK(res) || res[1].Ver != 2 {
+		t.Fatalf("DeleteBatch: %+v %v", res, err)
+	}
+	if it, _ := r.GetItem(ctx, user, b); !it.Deleted || it.Ver != 2 {
+		t.Fatalf("not deleted: %+v", it)
+	}
+}
+
+func TestItemRepo_ApplyChangeSet(t *testing.T) {
+	t.Parallel()
+	r := NewItemRepo()
+	ctx := context.Background()
+	alice, bob := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	a, b, theirs := uuid.Must(uuid.NewV7()), uuid.Must(uuid.NewV7()), uuid.Must(uuid.NewV7())
+	if _, err := r.UpsertBatch(ctx, alice, []model.UpsertItem{{ID: a, BlobEnc: []byte{1}}, {ID: b, BlobEnc: []byte{2}}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.UpsertBatch(ctx, bob, []model.UpsertItem{{ID: theirs, BlobEnc: []byte{3}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// another user's id and a stale delete: nothing is applied
+	res, err := r.ApplyChangeSet(ctx, alice, model.ChangeSet{
+		Upserts: []model.UpsertItem{{ID: a, BaseVer: 1, BlobEnc: []byte{9}}, {ID: theirs, BlobEnc: []byte{9}}},
+		Deletes: []model.ItemRef{{ID: b, BaseVer: 2}},
+	})
+	if err != nil || res.Applied() {
+		t.Fatalf("ApplyChangeSet: %+v %v", res, err)
+	}
+	if res.Upserts[0].Status != model.StatusAborted || res.Upserts[1].Status != model.StatusVersionConflict ||
+		res.Deletes[0].Status != model.StatusVersionConflict || res.Deletes[0].Ver != 1 {
+		t.Fatalf("results %+v", res)
+	}
+	if it, _ := r.GetItem(ctx, alice, a); it.Ver != 1 {
+		t.Fatalf("failed change set applied: %+v", it)
+	}
+
+	res, err = r.ApplyChangeSet(ctx, alice, model.ChangeSet{
+		Upserts: []model.UpsertItem{{ID: a, BaseVer: 1, BlobEnc: []byte{9}}},
+		Deletes: []model.ItemRef{{ID: b, BaseVer: 1}},
+	})
+	if err != nil || !res.Applied() || res.Upserts[0].Ver != 2 || res.Deletes[0].Ver != 2 {
+		t.Fatalf("ApplyChangeSet: %+v %v", res, err)
+	}
+	if it, _ := r.GetItem(ctx, alice, a); it.Ver != 2 || it.BlobEnc[0] != 9 {
+		t.Fatalf("not upserted: %+v", it)
+	}
+	if it, _ := r.GetItem(ctx, alice, b); !it.Deleted {
+		t.Fatalf("not deleted: %+v", it)
+	}
+}
+
+func TestItemRepo_GetJournalSince(t *testing.T) {
+	t.Parallel()
+	r := NewItemRepo()
+	ctx := context.Background()
+	alice, bob := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	a, b, c := uuid.Must(uuid.NewV7()), uuid.Must(uuid.NewV7()), uuid.Must(uuid.NewV7())
+	for _, up := range []struct {
+		user uuid.UUID
+		item model.UpsertItem
+	}{
+		{alice, model.UpsertItem{ID: a, BlobEnc: []byte{1}}},             // seq 1
+		{alice, model.UpsertItem{ID: b, BlobEnc: []byte{1}}},             // 2
+		{bob, model.UpsertItem{ID: c, BlobEnc: []byte{1}}},               // 3
+		{alice, model.UpsertItem{ID: b, BaseVer: 1, BlobEnc: []byte{2}}}, // 4
+		{alice, model.UpsertItem{ID: b, BaseVer: 2, BlobEnc: []byte{3}}}, // 5
+	} {
+		if _, err := r.UpsertBatch(ctx, up.user, []model.UpsertItem{up.item}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := r.Delete(ctx, alice, a, 1); err != nil { // seq 6
+		t.Fatal(err)
+	}
+
+	all, err := r.GetJournalSince(ctx, alice, 0, 0)
+	if err != nil || len(all) != 2 {
+		t.Fatalf("journal: %+v %v", all, err)
+	}
+	if all[0].ID != b || all[0].Seq != 5 || all[0].Ver != 3 || all[1].ID != a || all[1].Seq != 6 || !all[1].Deleted || all[1].BlobEnc != nil {
+		t.Fatalf("journal: %+v", all)
+	}
+	// b was written at version 3, above a's 2, but after seq 5 only a comes
+	if got, _ := r.GetJournalSince(ctx, alice, 5, 0); len(got) != 1 || got[0].ID != a {
+		t.Fatalf("after seq 5: %+v", got)
+	}
+	if got, _ := r.GetJournalSince(ctx, alice, 0, 1); len(got) != 1 || got[0].Seq != 5 {
+		t.Fatalf("limit 1: %+v", got)
+	}
+}
+
+func TestItemRepo_GetSnapshot(t *testing.T) {
+	t.Parallel()
+	r := NewItemRepo()
+	ctx := context.Background()
+	alice := uuid.Must(uuid.NewV4())
+	a, b := uuid.UUID{1}, uuid.UUID{2}
+	if _, err := r.UpsertBatch(ctx, alice, []model.UpsertItem{{ID: a, BlobEnc: []byte{1}}, {ID: b, BlobEnc: []byte{1}}}); err != nil {
+		t.Fatal(err)
+	}
+	at, err := r.JournalHead(ctx, alice)
+	if err != nil || at != 2 {
+		t.Fatalf("head: %d %v", at, err)
+	}
+	if _, err := r.UpsertBatch(ctx, alice, []model.UpsertItem{{ID: a, BaseVer: 1, BlobEnc: []byte{2}}, {ID: uuid.Must(uuid.NewV7()), BlobEnc: []byte{1}}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Delete(ctx, alice, b, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := r.GetSnapshot(ctx, alice, at, uuid.Nil, 0)
+	if err != nil || len(got) != 2 {
+		t.Fatalf("snapshot: %+v %v", got, err)
+	}
+	if got[0].ID != a || got[0].Ver != 1 || got[0].BlobEnc[0] != 1 || got[1].ID != b || got[1].Deleted {
+		t.Fatalf("snapshot moved with later writes: %+v", got)
+	}
+	if got, _ := r.GetSnapshot(ctx, alice, at, a, 0); len(got) != 1 || got[0].ID != b {
+		t.Fatalf("after a: %+v", got)
+	}
+	if got, _ := r.GetSnapshot(ctx, uuid.Must(uuid.NewV4()), at, uuid.Nil, 0); len(got) != 0 {
+		t.Fatalf("another user's snapshot: %+v", got)
+	}
+}
+
+func TestUserRepo(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	r := NewUserRepo()
+	u := &model.User{ID: uuid.Must(uuid.NewV4()), Username: "alice", WrappedDEK: []byte{}}
+	if err := r.Create(ctx, u); err != nil {
+		t.Fatal(err)
+	}
+	dup := &model.User{ID: uuid.Must(uuid.NewV4()), Username: "alice"}
+	if err := r.Create(ctx, dup); !errors.Is(err, errs.ErrAlreadyExists) {
+		t.Fatalf("duplicate username: %v", err)
+	}
+	if err := r.Create(tenant.WithID(ctx, "acme"), dup); err != nil {
+		t.Fatalf("same name in another tenant: %v", err)
+	}
+
+	if err := r.SetWrappedDEKIfEmpty(ctx, u.ID, []byte("w1")); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.SetWrappedDEKIfEmpty(ctx, u.ID, []byte("w2")); !errors.Is(err, errs.ErrVersionConflict) {
+		t.Fatalf("second set: %v", err)
+	}
+	if err := r.ReplaceWrappedDEK(ctx, u.ID, []byte("w0"), []byte("w2")); !errors.Is(err, errs.ErrVersionConflict) {
+		t.Fatalf("replace with stale prev: %v", err)
+	}
+	if err := r.ReplaceWrappedDEK(ctx, u.ID, []byte("w1"), []byte("w2")); err != nil {
+		t.Fatal(err)
+	}
+	got, err := r.GetByUsername(ctx, "alice")
+	if err != nil || got.ID != u.ID || string(got.WrappedDEK) != "w2" || got.CreatedAt.IsZero() {
+		t.Fatalf("get: %+v %v", got, err)
+	}
+}
+
+func TestUserRepo_LoginHistory(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	r := NewUserRepo()
+	u := &model.User{ID: uuid.Must(uuid.NewV4()), Username: "alice"}
+	if err := r.Create(ctx, u); err != nil {
+		t.Fatal(err)
+	}
+	for i := range 4 {
+		if err := r.RecordLogin(ctx, u.ID, model.LoginAttempt{At: time.Unix(int64(i), 0), Success: i == 3}, 3); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := r.LoginHistory(ctx, u.ID, 10)
+	if err != nil || len(got) != 3 || !got[0].Success || got[0].At.Unix() != 3 || got[2].At.Unix() != 1 {
+		t.Fatalf("history: %+v %v", got, err)
+	}
+	if got, _ := r.LoginHistory(ctx, u.ID, 1); len(got) != 1 {
+		t.Fatalf("limit: %+v", got)
+	}
+	if got, _ := r.LoginHistory(tenant.WithID(ctx, "acme"), u.ID, 10); len(got) != 0 {
+		t.Fatalf("another tenant saw %+v", got)
+	}
+}
+
+func TestDeviceRepo_Register(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	r := NewDeviceRepo()
+	user := uuid.Must(uuid.NewV4())
+	d := &model.Device{ID: uuid.Must(uuid.NewV4()), UserID: user, Platform: "ntfy", Token: "t1", Name: "a"}
+	if err := r.Register(ctx, d, 1); err != nil {
+		t.Fatal(err)
+	}
+	again := &model.Device{ID: uuid.Must(uuid.NewV4()), UserID: user, Platform: "ntfy", Token: "t1", Name: "b"}
+	if err := r.Register(ctx, again, 1); err != nil || again.ID != d.ID {
+		t.Fatalf("re-register: %v %v", again.ID, err)
+	}
+	other := &model.Device{ID: uuid.Must(uuid.NewV4()), UserID: user, Platform: "ntfy", Token: "t2"}
+	if err := r.Register(ctx, other, 1); !errors.Is(err, errs.ErrQuotaExceeded) {
+		t.Fatalf("over the limit: %v", err)
+	}
+	_ = r.DeleteToken(ctx, "ntfy", "t1")
+	if ds, _ := r.List(ctx, user); len(ds) != 0 {
+		t.Fatalf("after DeleteToken: %+v", ds)
+	}
+}
+
+func TestSessionRepo(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	r := NewSessionRepo()
+	user := uuid.Must(uuid.NewV4())
+	exp := time.Now().Add(time.Hour)
+	var ids []uuid.UUID
+	for i, h := range []string{"a", "b", "c"} {
+		s := &model.Session{ID: uuid.Must(uuid.NewV4()), UserID: user, Label: h}
+		if err := r.Create(ctx, s, []byte(h), exp, 2); err != nil || s.CreatedAt.IsZero() {
+			t.Fatalf("create %d: %v", i, err)
+		}
+		ids = append(ids, s.ID)
+		time.Sleep(time.Millisecond)
+	}
+	if ss, _ := r.List(ctx, user); len(ss) != 2 || ss[0].ID != ids[2] || ss[1].ID != ids[1] {
+		t.Fatalf("keep 2, newest first: %+v", ss)
+	}
+
+	s, err := r.Rotate(ctx, []byte("b"), []byte("b2"), exp)
+	if err != nil || s.ID != ids[1] {
+		t.Fatalf("rotate: %+v %v", s, err)
+	}
+	if _, err := r.Rotate(ctx, []byte("b"), []byte("b3"), exp); !errors.Is(err, errs.ErrNotFound) {
+		t.Fatalf("old token rotated twice: %v", err)
+	}
+	if _, err := r.Rotate(tenant.WithID(ctx, "acme"), []byte("b2"), []byte("b3"), exp); !errors.Is(err, errs.ErrNotFound) {
+		t.Fatalf("rotated in another tenant: %v", err)
+	}
+	if id, err := r.Owner(ctx, []byte("b2")); err != nil || id != user {
+		t.Fatalf("owner: %v %v", id, err)
+	}
+	if _, err := r.Owner(ctx, []byte("b")); !errors.Is(err, errs.ErrNotFound) {
+		t.Fatalf("owner of a rotated token: %v", err)
+	}
+	if ss, _ := r.List(ctx, user); ss[0].ID != ids[1] {
+		t.Fatalf("refreshed session not first: %+v", ss)
+	}
+
+	if err := r.Delete(ctx, user, ids[1]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Rotate(ctx, []byte("b2"), []byte("b3"), exp); !errors.Is(err, errs.ErrNotFound) {
+		t.Fatalf("revoked session rotated: %v", err)
+	}
+	if err := r.Delete(ctx, user, ids[1]); !errors.Is(err, errs.ErrNotFound) {
+		t.Fatalf("delete twice: %v", err)
+	}
+
+	expired := &model.Session{ID: uuid.Must(uuid.NewV4()), UserID: user}
+	_ = r.Create(ctx, expired, []byte("x"), time.Now().Add(-time.Second), 10)
+	if _, err := r.Rotate(ctx, []byte("x"), []byte("y"), exp); !errors.Is(err, errs.ErrNotFound) {
+		t.Fatalf("expired session rotated: %v", err)
+	}
+}
+
+func TestAccessLogRepo(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	sessions := NewSessionRepo()
+	r := NewAccessLogRepo(sessions)
+	user, item := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	laptop := &model.Session{ID: uuid.Must(uuid.NewV4()), UserID: user, Label: "laptop"}
+	if err := sessions.Create(ctx, laptop, []byte("a"), time.Now().Add(time.Hour), 10); err != nil {
+		t.Fatal(err)
+	}
+	t0 := time.Now().UTC()
+
+	_ = r.Record(ctx, user, item, laptop.ID, t0)
+	_ = r.Record(ctx, user, item, uuid.Nil, t0.Add(time.Second))
+	_ = r.Record(ctx, user, item, laptop.ID, t0.Add(2*time.Second))
+	_ = r.Record(tenant.WithID(ctx, "acme"), user, item, laptop.ID, t0)
+
+	got, err := r.List(ctx, user, item)
+	if err != nil || len(got) != 2 {
+		t.Fatalf("list: %+v %v", got, err)
+	}
+	if a := got[0]; a.SessionID != laptop.ID || a.Label != "laptop" || a.Reads != 2 || !a.FirstAt.Equal(t0) || !a.LastAt.Equal(t0.Add(2*time.Second)) {
+		t.Fatalf("laptop's reads: %+v", a)
+	}
+	if a := got[1]; a.SessionID != uuid.Nil || a.Label != "" || a.Reads != 1 {
+		t.Fatalf("sessionless read: %+v", a)
+	}
+	if got, _ := r.List(ctx, uuid.Must(uuid.NewV4()), item); len(got) != 0 {
+		t.Fatalf("another user's log: %+v", got)
+	}
+}
@@ -0,0 +1,134 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/and161185/goph-keeper/internal/tenant"
+	"github.com/gofrs/uuid/v5"
+)
+
+type sessionRow struct {
+	tenant  string
+	hash    []byte
+	session model.Session
+}
+
+// SessionRepo implements SessionRepository in memory.
+type SessionRepo struct {
+	mu   sync.Mutex
+	rows []sessionRow
+}
+
+// NewSessionRepo constructs an empty session repository.
+func NewSessionRepo() *SessionRepo { return &SessionRepo{} }
+
+// Create stores s after dropping the user's expired sessions and the least
+// recently used ones beyond keep.
+func (r *SessionRepo) Create(ctx context.Context, s *model.Session, tokenHash []byte, expires time.Time, keep int) error {
+	tid := tenant.FromContext(ctx)
+	now := time.Now().UTC()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rows = slices.DeleteFunc(r.rows, func(row sessionRow) bool {
+		return row.session.UserID == s.UserID && !now.Before(row.session.ExpiresAt)
+	})
+	r.sortLocked()
+	n := 0
+	r.rows = slices.DeleteFunc(r.rows, func(row sessionRow) bool {
+		if row.session.UserID != s.UserID {
+			return false
+		}
+		n++
+		return n >= keep
+	})
+	s.CreatedAt, s.LastUsedAt, s.ExpiresAt = now, now, expires
+	row := sessionRow{tenant: tid, hash: bytes.Clone(tokenHash), session: *s}
+	row.session.Scopes = slices.Clone(s.Scopes)
+	r.rows = append(r.rows, row)
+	return nil
+}
+
+// Rotate swaps the token hash of the live session holding oldHash.
+func (r *SessionRepo) Rotate(ctx context.Context, oldHash, newHash []byte, expires time.Time) (model.Session, error) {
+	tid := tenant.FromContext(ctx)
+	now := time.Now().UTC()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, row := range r.rows {
+		if row.tenant == tid && bytes.Equal(row.hash, oldHash) && now.Before(row.session.ExpiresAt) {
+			r.rows[i].hash = bytes.Clone(newHash)
+			r.rows[i].session.LastUsedAt, r.rows[i].session.ExpiresAt = now, expires
+			return r.rows[i].session, nil
+		}
+	}
+	return model.Session{}, errs.ErrNotFound
+}
+
+// Owner returns the user of the live session holding tokenHash.
+func (r *SessionRepo) Owner(ctx context.Context, tokenHash []byte) (uuid.UUID, error) {
+	tid := tenant.FromContext(ctx)
+	now := time.Now()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, row := range r.rows {
+		if row.tenant == tid && bytes.Equal(row.hash, tokenHash) && now.Before(row.session.ExpiresAt) {
+			return row.session.UserID, nil
+		}
+	}
+	return uuid.Nil, errs.ErrNotFound
+}
+
+// List returns the user's live sessions, most recently used first.
+func (r *SessionRepo) List(ctx context.Context, userID uuid.UUID) ([]model.Session, error) {
+	tid := tenant.FromContext(ctx)
+	now := time.Now()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sortLocked()
+	var out []model.Session
+	for _, row := range r.rows {
+		if row.tenant == tid && row.session.UserID == userID && now.Before(row.session.ExpiresAt) {
+			out = append(out, row.session)
+		}
+	}
+	return out, nil
+}
+
+// Delete removes one of the user's sessions.
+func (r *SessionRepo) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	tid := tenant.FromContext(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, row := range r.rows {
+		if row.tenant == tid && row.session.UserID == userID && row.session.ID == id {
+			r.rows = slices.Delete(r.rows, i, i+1)
+			return nil
+		}
+	}
+	return errs.ErrNotFound
+}
+
+// sortLocked orders sessions most recently used first.
+func (r *SessionRepo) sortLocked() {
+	slices.SortStableFunc(r.rows, func(a, b sessionRow) int {
+		return b.session.LastUsedAt.Compare(a.session.LastUsedAt)
+	})
+}
+
+// label is the device label of a session, "" when there is none.
+func (r *SessionRepo) label(tid string, id uuid.UUID) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, row := range r.rows {
+		if row.tenant == tid && row.session.ID == id {
+			return row.session.Label
+		}
+	}
+	return ""
+}
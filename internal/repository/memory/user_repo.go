@@ -0,0 +1,264 @@
+// Package memory contains in-memory implementations of repository
+// interfaces. They keep the semantics of the PostgreSQL ones (tenant
+// scoping, optimistic versions, item history) but not their quotas, and
+// lose everything on exit. They back tests and pkg/gktest.
+package memory
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/and161185/goph-keeper/internal/tenant"
+	"github.com/gofrs/uuid/v5"
+)
+
+type userRow struct {
+	tenant   string
+	user     model.User
+	logins   []model.LoginAttempt // oldest first
+	duress   uuid.UUID            // the duress vault's id, or uuid.Nil
+	frozen   time.Time            // zero unless the vault is frozen
+	disabled bool
+}
+
+// UserRepo implements UserRepository in memory.
+type UserRepo struct {
+	mu    sync.Mutex
+	users map[uuid.UUID]*userRow
+}
+
+// NewUserRepo constructs an empty user repository.
+func NewUserRepo() *UserRepo { return &UserRepo{users: make(map[uuid.UUID]*userRow)} }
+
+// Create stores a copy of u. Usernames are unique per tenant.
+func (r *UserRepo) Create(ctx context.Context, u *model.User) error {
+	tid := tenant.FromContext(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.users[u.ID]; ok {
+		return errs.ErrAlreadyExists
+	}
+	for _, row := range r.users {
+		if row.tenant == tid && row.user.Username == u.Username {
+			return errs.ErrAlreadyExists
+		}
+	}
+	c := cloneUser(*u)
+	c.CreatedAt = time.Now().UTC()
+	r.users[u.ID] = &userRow{tenant: tid, user: c}
+	return nil
+}
+
+// GetByID returns a copy of the tenant's user with this id.
+func (r *UserRepo) GetByID(ctx context.Context, id uuid.UUID) (*model.User, error) {
+	tid := tenant.FromContext(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	row, ok := r.users[id]
+	if !ok || row.tenant != tid {
+		return nil, errs.ErrNotFound
+	}
+	u := cloneUser(row.user)
+	return &u, nil
+}
+
+// GetByUsername returns a copy of the tenant's user with this name.
+func (r *UserRepo) GetByUsername(ctx context.Context, username string) (*model.User, error) {
+	tid := tenant.FromContext(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, row := range r.users {
+		if row.tenant == tid && row.user.Username == username {
+			u := cloneUser(row.user)
+			return &u, nil
+		}
+	}
+	return nil, errs.ErrNotFound
+}
+
+// SetWrappedDEKIfEmpty stores wrapped unless the user already has a DEK.
+func (r *UserRepo) SetWrappedDEKIfEmpty(ctx context.Context, id uuid.UUID, wrapped []byte) error {
+	return r.swapDEK(ctx, id, func(cur []byte) bool { return len(cur) == 0 }, wrapped)
+}
+
+// ReplaceWrappedDEK stores wrapped if the current DEK still equals prev.
+func (r *UserRepo) ReplaceWrappedDEK(ctx context.Context, id uuid.UUID, prev, wrapped []byte) error {
+	return r.swapDEK(ctx, id, func(cur []byte) bool { return slices.Equal(cur, prev) }, wrapped)
+}
+
+// swapDEK sets the wrapped DEK if ok accepts the current one; like the
+// SQL versions, a missing user and a failed check are both conflicts.
+func (r *UserRepo) swapDEK(ctx context.Context, id uuid.UUID, ok func(cur []byte) bool, wrapped []byte) error {
+	tid := tenant.FromContext(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	row, found := r.users[id]
+	if !found || row.tenant != tid || !ok(row.user.WrappedDEK) {
+		return errs.ErrVersionConflict
+	}
+	row.user.WrappedDEK = slices.Clone(wrapped)
+	return nil
+}
+
+// RecordLogin appends a to the user's history, keeping the newest keep.
+func (r *UserRepo) RecordLogin(ctx context.Context, id uuid.UUID, a model.LoginAttempt, keep int) error {
+	tid := tenant.FromContext(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	row, ok := r.users[id]
+	if !ok || row.tenant != tid {
+		return errs.ErrNotFound
+	}
+	a.IPHash = slices.Clone(a.IPHash)
+	row.logins = append(row.logins, a)
+	if n := len(row.logins) - keep; n > 0 {
+		row.logins = slices.Delete(row.logins, 0, n)
+	}
+	return nil
+}
+
+// LoginHistory returns up to limit of the user's attempts, newest first.
+func (r *UserRepo) LoginHistory(ctx context.Context, id uuid.UUID, limit int) ([]model.LoginAttempt, error) {
+	tid := tenant.FromContext(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	row, ok := r.users[id]
+	if !ok || row.tenant != tid {
+		return nil, nil
+	}
+	var out []model.LoginAttempt
+	for i := len(row.logins) - 1; i >= 0 && len(out) < limit; i-- {
+		a := row.logins[i]
+		a.IPHash = slices.Clone(a.IPHash)
+		out = append(out, a)
+	}
+	return out, nil
+}
+
+// CreateDuress stores a copy of vault and links it to the user.
+func (r *UserRepo) CreateDuress(ctx context.Context, id uuid.UUID, vault *model.User) error {
+	tid := tenant.FromContext(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	row, ok := r.users[id]
+	if !ok || row.tenant != tid {
+		return errs.ErrNotFound
+	}
+	if _, taken := r.users[vault.ID]; taken || row.duress != uuid.Nil {
+		return errs.ErrAlreadyExists
+	}
+	c := cloneUser(*vault)
+	c.CreatedAt = time.Now().UTC()
+	r.users[vault.ID] = &userRow{tenant: tid, user: c}
+	row.duress = vault.ID
+	return nil
+}
+
+// GetDuress returns a copy of the user's duress vault.
+func (r *UserRepo) GetDuress(ctx context.Context, id uuid.UUID) (*model.User, error) {
+	tid := tenant.FromContext(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	row, ok := r.users[id]
+	if !ok || row.tenant != tid || row.duress == uuid.Nil {
+		return nil, errs.ErrNotFound
+	}
+	u := cloneUser(r.users[row.duress].user)
+	return &u, nil
+}
+
+// DeleteDuress forgets the user's duress vault. Unlike the SQL version it
+// cannot reach the vault's items, which stay in the item repository.
+func (r *UserRepo) DeleteDuress(ctx context.Context, id uuid.UUID) error {
+	tid := tenant.FromContext(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	row, ok := r.users[id]
+	if !ok || row.tenant != tid || row.duress == uuid.Nil {
+		return errs.ErrNotFound
+	}
+	delete(r.users, row.duress)
+	row.duress = uuid.Nil
+	return nil
+}
+
+func cloneUser(u model.User) model.User {
+	u.PwdHash = slices.Clone(u.PwdHash)
+	u.SaltAuth = slices.Clone(u.SaltAuth)
+	u.KekSalt = slices.Clone(u.KekSalt)
+	u.WrappedDEK = slices.Clone(u.WrappedDEK)
+	return u
+}
+
+// Freeze records at as the time the vault was frozen unless it already is.
+func (r *UserRepo) Freeze(ctx context.Context, id uuid.UUID, at time.Time) (time.Time, error) {
+	tid := tenant.FromContext(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	row, ok := r.users[id]
+	if !ok || row.tenant != tid {
+		return time.Time{}, errs.ErrNotFound
+	}
+	if row.frozen.IsZero() {
+		row.frozen = at
+	}
+	return row.frozen, nil
+}
+
+// Unfreeze clears the time the vault was frozen.
+func (r *UserRepo) Unfreeze(ctx context.Context, id uuid.UUID) error {
+	tid := tenant.FromContext(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	row, ok := r.users[id]
+	if !ok || row.tenant != tid {
+		return errs.ErrNotFound
+	}
+	row.frozen = time.Time{}
+	return nil
+}
+
+// FrozenAt returns when the vault was frozen, zero if it is not.
+func (r *UserRepo) FrozenAt(ctx context.Context, id uuid.UUID) (time.Time, error) {
+	tid := tenant.FromContext(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	row, ok := r.users[id]
+	if !ok || row.tenant != tid {
+		return time.Time{}, errs.ErrNotFound
+	}
+	return row.frozen, nil
+}
+
+// SetDisabled suspends or reactivates the user and its duress vault in any
+// tenant. Sessions live in SessionRepo, so unlike the SQL version it does
+// not end them; Refresh refuses a suspended user's anyway.
+func (r *UserRepo) SetDisabled(_ context.Context, id uuid.UUID, disabled bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	row, ok := r.users[id]
+	if !ok {
+		return errs.ErrNotFound
+	}
+	row.disabled = disabled
+	if v, ok := r.users[row.duress]; ok {
+		v.disabled = disabled
+	}
+	return nil
+}
+
+// Disabled reports whether the tenant's user is suspended.
+func (r *UserRepo) Disabled(ctx context.Context, id uuid.UUID) (bool, error) {
+	tid := tenant.FromContext(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	row, ok := r.users[id]
+	if !ok || row.tenant != tid {
+		return false, errs.ErrNotFound
+	}
+	return row.disabled, nil
+}
@@ -0,0 +1,77 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/and161185/goph-keeper/internal/tenant"
+	"github.com/gofrs/uuid/v5"
+)
+
+type webhookRow struct {
+	tenant string
+	hook   model.Webhook
+}
+
+// WebhookRepo implements WebhookRepository in memory.
+type WebhookRepo struct {
+	mu    sync.Mutex
+	hooks []webhookRow // in creation order
+}
+
+// NewWebhookRepo constructs an empty webhook repository.
+func NewWebhookRepo() *WebhookRepo { return &WebhookRepo{} }
+
+// Create stores h unless its owner already has max webhooks.
+func (r *WebhookRepo) Create(ctx context.Context, h *model.Webhook, max int) error {
+	tid := tenant.FromContext(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for _, row := range r.hooks {
+		if row.hook.UserID == h.UserID {
+			n++
+		}
+	}
+	if n >= max {
+		return fmt.Errorf("%w: at most %d webhooks per user", errs.ErrQuotaExceeded, max)
+	}
+	h.CreatedAt = time.Now().UTC()
+	c := *h
+	c.Secret = slices.Clone(h.Secret)
+	r.hooks = append(r.hooks, webhookRow{tenant: tid, hook: c})
+	return nil
+}
+
+// List returns the user's webhooks, oldest first.
+func (r *WebhookRepo) List(ctx context.Context, userID uuid.UUID) ([]model.Webhook, error) {
+	tid := tenant.FromContext(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []model.Webhook
+	for _, row := range r.hooks {
+		if row.tenant == tid && row.hook.UserID == userID {
+			out = append(out, row.hook)
+		}
+	}
+	return out, nil
+}
+
+// Delete removes one of the user's webhooks.
+func (r *WebhookRepo) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	tid := tenant.FromContext(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, row := range r.hooks {
+		if row.tenant == tid && row.hook.UserID == userID && row.hook.ID == id {
+			r.hooks = slices.Delete(r.hooks, i, i+1)
+			return nil
+		}
+	}
+	return errs.ErrNotFound
+}
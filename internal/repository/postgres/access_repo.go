@@ -0,0 +1,56 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/and161185/goph-keeper/internal/tenant"
+	"github.com/gofrs/uuid/v5"
+)
+
+// AccessLogRepo implements AccessLogRepository using PostgreSQL.
+type AccessLogRepo struct{ db *DB }
+
+// NewAccessLogRepo constructs an access log repository.
+func NewAccessLogRepo(db *DB) *AccessLogRepo { return &AccessLogRepo{db: db} }
+
+// Record upserts the (item, session) row, taking the label from the
+// session the first time.
+func (r *AccessLogRepo) Record(ctx context.Context, userID, itemID, sessionID uuid.UUID, at time.Time) error {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
+	const q = `
+INSERT INTO item_accesses (item_id, session_id, user_id, tenant_id, label, first_at, last_at)
+VALUES ($1, $2, $3, $4, COALESCE((SELECT label FROM sessions WHERE id=$2), ''), $5, $5)
+ON CONFLICT (item_id, session_id) DO UPDATE
+SET last_at = GREATEST(item_accesses.last_at, EXCLUDED.last_at), reads = item_accesses.reads + 1`
+	_, err := r.db.Pool.Exec(ctx, q, itemID, sessionID, userID, tenant.FromContext(ctx), at)
+	return err
+}
+
+// List selects the item's rows, newest read first.
+func (r *AccessLogRepo) List(ctx context.Context, userID, itemID uuid.UUID) ([]model.ItemAccess, error) {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
+	const q = `
+SELECT session_id, label, first_at, last_at, reads FROM item_accesses
+WHERE item_id=$1 AND user_id=$2 AND tenant_id=$3
+ORDER BY last_at DESC, session_id`
+	rows, err := r.db.Pool.Query(ctx, q, itemID, userID, tenant.FromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []model.ItemAccess
+	for rows.Next() {
+		var a model.ItemAccess
+		if err := rows.Scan(&a.SessionID, &a.Label, &a.FirstAt, &a.LastAt, &a.Reads); err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
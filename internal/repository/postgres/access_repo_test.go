@@ -0,0 +1,40 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/and161185/goph-keeper/internal/tenant"
+	"github.com/gofrs/uuid/v5"
+	pgxmock "github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessLogRepo(t *testing.T) {
+	db, mock := newDB(t)
+	defer mock.Close()
+	r := NewAccessLogRepo(db)
+	ctx := tenant.WithID(context.Background(), "acme")
+	user, item, sess := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	now := time.Now().UTC()
+
+	mock.ExpectExec(`INSERT INTO item_accesses .* COALESCE\(\(SELECT label FROM sessions WHERE id=\$2\), ''\), \$5, \$5\)\s+ON CONFLICT \(item_id, session_id\) DO UPDATE`).
+		WithArgs(item, sess, user, "acme", now).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	require.NoError(t, r.Record(ctx, user, item, sess, now))
+
+	mock.ExpectQuery(`FROM item_accesses\s+WHERE item_id=\$1 AND user_id=\$2 AND tenant_id=\$3\s+ORDER BY last_at DESC`).
+		WithArgs(item, user, "acme").
+		WillReturnRows(pgxmock.NewRows([]string{"session_id", "label", "first_at", "last_at", "reads"}).
+			AddRow(sess, "laptop", now, now, int64(3)).
+			AddRow(uuid.Nil, "", now, now, int64(1)))
+	list, err := r.List(ctx, user, item)
+	require.NoError(t, err)
+	require.Equal(t, []model.ItemAccess{
+		{SessionID: sess, Label: "laptop", FirstAt: now, LastAt: now, Reads: 3},
+		{SessionID: uuid.Nil, FirstAt: now, LastAt: now, Reads: 1},
+	}, list)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,154 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/model"
+)
+
+// Trace reads the user's items, item_history and item_changes in one
+// repeatable-read transaction, so a concurrent write shows in all three or
+// in none. Like RollbackUser it goes by user id alone.
+func (r *ItemRepo) Trace(ctx context.Context, userID uuid.UUID) (t model.VaultTrace, err error) {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.db.Pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return t, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	index := map[uuid.UUID]int{}
+	rows, err := tx.Query(ctx, `SELECT id, ver, deleted, octet_length(blob_enc) FROM items WHERE user_id=$1 ORDER BY id`, userID)
+	if err != nil {
+		return t, err
+	}
+	for rows.Next() {
+		var it model.ItemTrace
+		if err = rows.Scan(&it.ID, &it.Ver, &it.Deleted, &it.BlobLen); err != nil {
+			rows.Close()
+			return t, err
+		}
+		index[it.ID] = len(t.Items)
+		t.Items = append(t.Items, it)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return t, err
+	}
+
+	rows, err = tx.Query(ctx, `SELECT item_id, ver, deleted FROM item_history WHERE user_id=$1 ORDER BY item_id, ver`, userID)
+	if err != nil {
+		return t, err
+	}
+	for rows.Next() {
+		var (
+			id uuid.UUID
+			h  model.TraceVersion
+		)
+		if err = rows.Scan(&id, &h.Ver, &h.Deleted); err != nil {
+			rows.Close()
+			return t, err
+		}
+		if i, ok := index[id]; ok {
+			t.Items[i].History = append(t.Items[i].History, h)
+		}
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return t, err
+	}
+
+	rows, err = tx.Query(ctx, `SELECT seq, item_id, op, ver, changed_at FROM item_changes WHERE user_id=$1 ORDER BY seq`, userID)
+	if err != nil {
+		return t, err
+	}
+	for rows.Next() {
+		var (
+			id uuid.UUID
+			e  model.TraceEntry
+		)
+		if err = rows.Scan(&e.Seq, &id, &e.Op, &e.Ver, &e.At); err != nil {
+			rows.Close()
+			return t, err
+		}
+		if i, ok := index[id]; ok {
+			t.Items[i].Journal = append(t.Items[i].Journal, e)
+		}
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return t, err
+	}
+
+	var last *time.Time
+	if err = tx.QueryRow(ctx, `SELECT max(performed_at) FROM user_rollbacks WHERE user_id=$1`, userID).Scan(&last); err != nil {
+		return t, err
+	}
+	if last != nil {
+		t.LastRollback = *last
+	}
+	return t, nil
+}
+
+// RecordHistory copies the item's current row into item_history, as the
+// history trigger would have.
+func (r *ItemRepo) RecordHistory(ctx context.Context, userID, itemID uuid.UUID) error {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
+	const q = `
+INSERT INTO item_history (item_id, user_id, tenant_id, ver, blob_enc, deleted, updated_at, size_class, schema_version)
+SELECT id, user_id, tenant_id, ver, blob_enc, deleted, updated_at, size_class, schema_version
+FROM items WHERE id=$1 AND user_id=$2
+ON CONFLICT (item_id, ver) DO NOTHING`
+	if _, err := r.db.Pool.Exec(ctx, q, itemID, userID); err != nil {
+		return err
+	}
+	return nil
+}
+
+// RecordJournal adds an item_changes entry for the item's current row,
+// under the user's journal lock like any write.
+func (r *ItemRepo) RecordJournal(ctx context.Context, userID, itemID uuid.UUID) (err error) {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.db.Pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+			return
+		}
+		if e := tx.Commit(ctx); e != nil {
+			err = e
+		}
+	}()
+
+	var tid string
+	if err = tx.QueryRow(ctx, `SELECT tenant_id FROM items WHERE id=$1 AND user_id=$2`, itemID, userID).Scan(&tid); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errs.ErrNotFound
+		}
+		return err
+	}
+	if err = lockChanges(ctx, tx, tid, userID); err != nil {
+		return err
+	}
+	const q = `
+INSERT INTO item_changes (tenant_id, user_id, item_id, op, ver)
+SELECT tenant_id, user_id, id, CASE WHEN deleted THEN 'delete' ELSE 'upsert' END, ver
+FROM items WHERE id=$1 AND user_id=$2`
+	_, err = tx.Exec(ctx, q, itemID, userID)
+	return err
+}
@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+	pgxmock "github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestItemRepo_Trace(t *testing.T) {
+	db, mock := newDB(t)
+	defer mock.Close()
+	r := NewItemRepo(db)
+
+	ctx := context.Background()
+	user, a, gone := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	at := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectBeginTx(pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	mock.ExpectQuery(`FROM items WHERE user_id=\$1`).WithArgs(user).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "ver", "deleted", "len"}).AddRow(a, int64(2), true, 40))
+	mock.ExpectQuery(`FROM item_history WHERE user_id=\$1`).WithArgs(user).
+		WillReturnRows(pgxmock.NewRows([]string{"item_id", "ver", "deleted"}).
+			AddRow(a, int64(1), false).AddRow(a, int64(2), true).AddRow(gone, int64(1), false))
+	mock.ExpectQuery(`FROM item_changes WHERE user_id=\$1`).WithArgs(user).
+		WillReturnRows(pgxmock.NewRows([]string{"seq", "item_id", "op", "ver", "changed_at"}).
+			AddRow(int64(3), a, "upsert", int64(1), at).AddRow(int64(5), gone, "remove", int64(1), at).AddRow(int64(8), a, "delete", int64(2), at))
+	mock.ExpectQuery(`SELECT max\(performed_at\) FROM user_rollbacks`).WithArgs(user).
+		WillReturnRows(pgxmock.NewRows([]string{"max"}).AddRow(&at))
+	mock.ExpectRollback()
+
+	tr, err := r.Trace(ctx, user)
+	require.NoError(t, err)
+	require.Equal(t, model.VaultTrace{
+		LastRollback: at,
+		Items: []model.ItemTrace{{
+			ID: a, Ver: 2, Deleted: true, BlobLen: 40,
+			History: []model.TraceVersion{{Ver: 1}, {Ver: 2, Deleted: true}},
+			Journal: []model.TraceEntry{{Seq: 3, Op: "upsert", Ver: 1, At: at}, {Seq: 8, Op: "delete", Ver: 2, At: at}},
+		}},
+	}, tr)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestItemRepo_RecordJournal(t *testing.T) {
+	db, mock := newDB(t)
+	defer mock.Close()
+	r := NewItemRepo(db)
+	ctx := context.Background()
+	user, item := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT tenant_id FROM items`).WithArgs(item, user).
+		WillReturnRows(pgxmock.NewRows([]string{"tenant_id"}).AddRow("default"))
+	mock.ExpectExec(`pg_advisory_xact_lock`).WithArgs("default/" + user.String()).
+		WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	mock.ExpectExec(`INSERT INTO item_changes`).WithArgs(item, user).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
+	require.NoError(t, r.RecordJournal(ctx, user, item))
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT tenant_id FROM items`).WithArgs(item, user).WillReturnError(pgx.ErrNoRows)
+	mock.ExpectRollback()
+	require.ErrorIs(t, r.RecordJournal(ctx, user, item), errs.ErrNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
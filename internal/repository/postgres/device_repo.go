@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/and161185/goph-keeper/internal/tenant"
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+)
+
+// DeviceRepo implements DeviceRepository using PostgreSQL.
+type DeviceRepo struct{ db *DB }
+
+// NewDeviceRepo constructs a device repository.
+func NewDeviceRepo(db *DB) *DeviceRepo { return &DeviceRepo{db: db} }
+
+// Register inserts d or renames the existing registration of its token.
+// Re-registering never counts against the limit.
+func (r *DeviceRepo) Register(ctx context.Context, d *model.Device, max int) error {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
+	const q = `
+INSERT INTO devices (id, user_id, tenant_id, platform, token, name)
+SELECT $1, $2, $3, $4, $5, $6
+WHERE (SELECT count(*) FROM devices WHERE user_id=$2) < $7
+   OR EXISTS (SELECT 1 FROM devices WHERE user_id=$2 AND platform=$4 AND token=$5)
+ON CONFLICT (user_id, platform, token) DO UPDATE SET name=EXCLUDED.name
+RETURNING id, created_at`
+	err := r.db.Pool.QueryRow(ctx, q, d.ID, d.UserID, tenant.FromContext(ctx), d.Platform, d.Token, d.Name, max).
+		Scan(&d.ID, &d.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%w: at most %d devices per user", errs.ErrQuotaExceeded, max)
+	}
+	return err
+}
+
+// List selects the user's devices, oldest first.
+func (r *DeviceRepo) List(ctx context.Context, userID uuid.UUID) ([]model.Device, error) {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
+	const q = `
+SELECT id, platform, token, name, created_at
+FROM devices WHERE user_id=$1 AND tenant_id=$2
+ORDER BY created_at, id`
+	rows, err := r.db.Pool.Query(ctx, q, userID, tenant.FromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []model.Device
+	for rows.Next() {
+		d := model.Device{UserID: userID}
+		if err := rows.Scan(&d.ID, &d.Platform, &d.Token, &d.Name, &d.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+// Delete removes one of the user's devices.
+func (r *DeviceRepo) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
+	tag, err := r.db.Pool.Exec(ctx, `DELETE FROM devices WHERE id=$1 AND user_id=$2 AND tenant_id=$3`,
+		id, userID, tenant.FromContext(ctx))
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errs.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteToken removes the registrations of a token the provider has retired.
+func (r *DeviceRepo) DeleteToken(ctx context.Context, platform, token string) error {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
+	_, err := r.db.Pool.Exec(ctx, `DELETE FROM devices WHERE platform=$1 AND token=$2 AND tenant_id=$3`,
+		platform, token, tenant.FromContext(ctx))
+	return err
+}
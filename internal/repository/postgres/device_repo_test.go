@@ -0,0 +1,55 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/and161185/goph-keeper/internal/tenant"
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+	pgxmock "github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeviceRepo(t *testing.T) {
+	db, mock := newDB(t)
+	defer mock.Close()
+	r := NewDeviceRepo(db)
+	ctx := context.Background()
+	user := uuid.Must(uuid.NewV4())
+	d := model.Device{ID: uuid.Must(uuid.NewV4()), UserID: user, Platform: "ntfy", Token: "gk-0123456789abcdef", Name: "laptop"}
+	existing := uuid.Must(uuid.NewV4())
+	now := time.Now().UTC()
+
+	// re-registering a token returns the device already stored
+	mock.ExpectQuery(`INSERT INTO devices .* < \$7\s+OR EXISTS .* ON CONFLICT \(user_id, platform, token\) DO UPDATE`).
+		WithArgs(d.ID, user, tenant.Default, d.Platform, d.Token, d.Name, 3).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at"}).AddRow(existing, now))
+	require.NoError(t, r.Register(ctx, &d, 3))
+	require.Equal(t, existing, d.ID)
+	require.Equal(t, now, d.CreatedAt)
+
+	mock.ExpectQuery(`INSERT INTO devices`).WithArgs(d.ID, user, tenant.Default, d.Platform, d.Token, d.Name, 3).
+		WillReturnError(pgx.ErrNoRows)
+	require.ErrorIs(t, r.Register(ctx, &d, 3), errs.ErrQuotaExceeded)
+
+	mock.ExpectQuery(`SELECT id, platform, token, name, created_at\s+FROM devices WHERE user_id=\$1 AND tenant_id=\$2`).
+		WithArgs(user, tenant.Default).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "platform", "token", "name", "created_at"}).
+			AddRow(d.ID, d.Platform, d.Token, d.Name, now))
+	got, err := r.List(ctx, user)
+	require.NoError(t, err)
+	require.Equal(t, []model.Device{d}, got)
+
+	mock.ExpectExec(`DELETE FROM devices WHERE id=\$1 AND user_id=\$2 AND tenant_id=\$3`).
+		WithArgs(d.ID, user, tenant.Default).WillReturnResult(pgxmock.NewResult("DELETE", 0))
+	require.ErrorIs(t, r.Delete(ctx, user, d.ID), errs.ErrNotFound)
+
+	mock.ExpectExec(`DELETE FROM devices WHERE platform=\$1 AND token=\$2 AND tenant_id=\$3`).
+		WithArgs(d.Platform, d.Token, tenant.Default).WillReturnResult(pgxmock.NewResult("DELETE", 2))
+	require.NoError(t, r.DeleteToken(ctx, d.Platform, d.Token))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/and161185/goph-keeper/internal/tenant"
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+)
+
+// IPRuleRepo implements IPRuleRepository using PostgreSQL.
+type IPRuleRepo struct{ db *DB }
+
+// NewIPRuleRepo constructs an address rule repository.
+func NewIPRuleRepo(db *DB) *IPRuleRepo { return &IPRuleRepo{db: db} }
+
+// Rules selects the user's rules.
+func (r *IPRuleRepo) Rules(ctx context.Context, userID uuid.UUID) ([]model.IPRule, error) {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
+	const q = `
+SELECT cidr, allow, created_at FROM user_ip_rules
+WHERE user_id=$1 AND tenant_id=$2 ORDER BY created_at, cidr`
+	return r.query(ctx, q, userID, tenant.FromContext(ctx))
+}
+
+// RulesByUsername selects the rules of the user with this name.
+func (r *IPRuleRepo) RulesByUsername(ctx context.Context, username string) ([]model.IPRule, error) {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
+	const q = `
+SELECT r.cidr, r.allow, r.created_at FROM user_ip_rules r JOIN users u ON u.id = r.user_id
+WHERE u.tenant_id=$1 AND u.username=$2 ORDER BY r.created_at, r.cidr`
+	return r.query(ctx, q, tenant.FromContext(ctx), username)
+}
+
+func (r *IPRuleRepo) query(ctx context.Context, q string, args ...any) ([]model.IPRule, error) {
+	rows, err := r.db.Pool.Query(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []model.IPRule
+	for rows.Next() {
+		var rule model.IPRule
+		if err := rows.Scan(&rule.CIDR, &rule.Allow, &rule.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, rule)
+	}
+	return out, rows.Err()
+}
+
+// userID resolves a username in the context's tenant.
+func (r *IPRuleRepo) userID(ctx context.Context, username string) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := r.db.Pool.QueryRow(ctx, `SELECT id FROM users WHERE tenant_id=$1 AND username=$2`,
+		tenant.FromContext(ctx), username).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return uuid.Nil, errs.ErrNotFound
+	}
+	return id, err
+}
+
+// Add upserts a rule. Replacing a rule never counts against the limit.
+func (r *IPRuleRepo) Add(ctx context.Context, username string, rule *model.IPRule, max int) error {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
+	id, err := r.userID(ctx, username)
+	if err != nil {
+		return err
+	}
+	const q = `
+INSERT INTO user_ip_rules (user_id, tenant_id, cidr, allow)
+SELECT $1, $2, $3, $4
+WHERE (SELECT count(*) FROM user_ip_rules WHERE user_id=$1) < $5
+   OR EXISTS (SELECT 1 FROM user_ip_rules WHERE user_id=$1 AND cidr=$3)
+ON CONFLICT (user_id, cidr) DO UPDATE SET allow=EXCLUDED.allow
+RETURNING created_at`
+	err = r.db.Pool.QueryRow(ctx, q, id, tenant.FromContext(ctx), rule.CIDR, rule.Allow, max).Scan(&rule.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%w: at most %d address rules per user", errs.ErrQuotaExceeded, max)
+	}
+	return err
+}
+
+// Remove deletes one rule of the named user.
+func (r *IPRuleRepo) Remove(ctx context.Context, username string, cidr netip.Prefix) error {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
+	const q = `
+DELETE FROM user_ip_rules r USING users u
+WHERE u.id = r.user_id AND u.tenant_id=$1 AND u.username=$2 AND r.cidr=$3`
+	tag, err := r.db.Pool.Exec(ctx, q, tenant.FromContext(ctx), username, cidr)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errs.ErrNotFound
+	}
+	return nil
+}
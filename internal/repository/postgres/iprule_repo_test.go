@@ -0,0 +1,61 @@
+package postgres
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/and161185/goph-keeper/internal/tenant"
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+	pgxmock "github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIPRuleRepo(t *testing.T) {
+	db, mock := newDB(t)
+	defer mock.Close()
+	r := NewIPRuleRepo(db)
+	ctx := tenant.WithID(context.Background(), "acme")
+	user := uuid.Must(uuid.NewV4())
+	vpn := netip.MustParsePrefix("10.8.0.0/16")
+	now := time.Now().UTC()
+
+	mock.ExpectQuery(`SELECT id FROM users WHERE tenant_id=\$1 AND username=\$2`).WithArgs("acme", "alice").
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(user))
+	mock.ExpectQuery(`INSERT INTO user_ip_rules .* < \$5\s+OR EXISTS .* ON CONFLICT \(user_id, cidr\) DO UPDATE`).
+		WithArgs(user, "acme", vpn, true, 50).
+		WillReturnRows(pgxmock.NewRows([]string{"created_at"}).AddRow(now))
+	rule := model.IPRule{CIDR: vpn, Allow: true}
+	require.NoError(t, r.Add(ctx, "alice", &rule, 50))
+	require.Equal(t, now, rule.CreatedAt)
+
+	mock.ExpectQuery(`SELECT id FROM users`).WithArgs("acme", "alice").
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(user))
+	mock.ExpectQuery(`INSERT INTO user_ip_rules`).WithArgs(user, "acme", vpn, true, 50).WillReturnError(pgx.ErrNoRows)
+	require.ErrorIs(t, r.Add(ctx, "alice", &rule, 50), errs.ErrQuotaExceeded)
+
+	mock.ExpectQuery(`SELECT id FROM users`).WithArgs("acme", "nobody").WillReturnError(pgx.ErrNoRows)
+	require.ErrorIs(t, r.Add(ctx, "nobody", &rule, 50), errs.ErrNotFound)
+
+	cols := []string{"cidr", "allow", "created_at"}
+	mock.ExpectQuery(`SELECT cidr, allow, created_at FROM user_ip_rules WHERE user_id=\$1 AND tenant_id=\$2`).
+		WithArgs(user, "acme").WillReturnRows(pgxmock.NewRows(cols).AddRow(vpn, true, now))
+	got, err := r.Rules(ctx, user)
+	require.NoError(t, err)
+	require.Equal(t, []model.IPRule{rule}, got)
+
+	mock.ExpectQuery(`FROM user_ip_rules r JOIN users u ON u.id = r.user_id WHERE u.tenant_id=\$1 AND u.username=\$2`).
+		WithArgs("acme", "alice").WillReturnRows(pgxmock.NewRows(cols).AddRow(vpn, true, now))
+	got, err = r.RulesByUsername(ctx, "alice")
+	require.NoError(t, err)
+	require.Equal(t, []model.IPRule{rule}, got)
+
+	mock.ExpectExec(`DELETE FROM user_ip_rules r USING users u`).WithArgs("acme", "alice", vpn).
+		WillReturnResult(pgxmock.NewResult("DELETE", 0))
+	require.ErrorIs(t, r.Remove(ctx, "alice", vpn), errs.ErrNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
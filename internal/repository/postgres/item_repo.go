@@ -8,20 +8,35 @@ import (
 
 	"github.com/and161185/goph-keeper/internal/errs"
 	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/and161185/goph-keeper/internal/tenant"
 	"github.com/gofrs/uuid/v5"
 	"github.com/jackc/pgx/v5"
 )
 
 // ItemRepo implements ItemRepository using PostgreSQL.
-type ItemRepo struct{ db *DB }
+type ItemRepo struct {
+	db *DB
+	// hold is the legal hold window; see SetLegalHold.
+	hold time.Duration
+}
 
 // NewItemRepo constructs an item repository.
 func NewItemRepo(db *DB) *ItemRepo { return &ItemRepo{db: db} }
 
+// SetLegalHold keeps item history and tombstones younger than d: rollbacks
+// that would move such history out of item_history fail with
+// errs.ErrLegalHold. 0 turns the hold off.
+func (r *ItemRepo) SetLegalHold(d time.Duration) { r.hold = d }
+
 // UpsertBatch inserts/updates items with optimistic concurrency and returns new versions.
+// Distinct ids cost a fixed number of round trips: one to lock and read
+// them all, then one each for the updates and the inserts.
 func (r *ItemRepo) UpsertBatch(
 	ctx context.Context, userID uuid.UUID, ups []model.UpsertItem,
 ) (results []model.ItemVersion, err error) {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
 	tx, err := r.db.Pool.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
 		return nil, err
@@ -36,44 +51,260 @@ func (r *ItemRepo) UpsertBatch(
 		}
 	}()
 
-	results = make([]model.ItemVersion, 0, len(ups))
-	const sel = `SELECT ver FROM items WHERE id=$1 AND user_id=$2 FOR UPDATE`
-	const ins = `INSERT INTO items (id, user_id, blob_enc, ver, deleted) VALUES ($1,$2,$3,$4,false)`
-	const upd = `UPDATE items SET blob_enc=$3, ver=$4, deleted=false WHERE id=$1 AND user_id=$2`
-
+	tid := tenant.FromContext(ctx)
+	if err = lockChanges(ctx, tx, tid, userID); err != nil {
+		return nil, err
+	}
+	ids := make([]uuid.UUID, len(ups))
 	for i, up := range ups {
-		var curVer int64
-		row := tx.QueryRow(ctx, sel, up.ID, userID)
-		scanErr := row.Scan(&curVer)
-		switch {
-		case scanErr == nil:
-			if curVer != up.BaseVer {
-				return nil, fmt.Errorf("item[%d]: %w", i, errs.ErrVersionConflict)
-			}
-			newVer := curVer + 1
-			if _, err = tx.Exec(ctx, upd, up.ID, userID, []byte(up.BlobEnc), newVer); err != nil {
+		ids[i] = up.ID
+	}
+	results = make([]model.ItemVersion, 0, len(ups))
+	if repeats(ids) {
+		// a repeated id must see the write before it
+		for i, up := range ups {
+			res, err := upsertItem(ctx, tx, tid, userID, up)
+			if err != nil {
 				return nil, err
 			}
-			results = append(results, model.ItemVersion{ID: up.ID, NewVer: newVer})
-		case errors.Is(scanErr, pgx.ErrNoRows):
-			if up.BaseVer != 0 {
-				return nil, fmt.Errorf("item[%d]: %w", i, errs.ErrVersionConflict)
+			if res.Status != model.StatusOK {
+				return nil, &errs.ConflictError{Index: i, ID: up.ID, Ver: res.Ver, UpdatedAt: res.UpdatedAt}
 			}
-			if _, err = tx.Exec(ctx, ins, up.ID, userID, []byte(up.BlobEnc), int64(1)); err != nil {
-				return nil, err
+			results = append(results, model.ItemVersion{ID: up.ID, NewVer: res.Ver})
+		}
+	} else {
+		cur, err := lockItems(ctx, tx, tid, userID, ids)
+		if err != nil {
+			return nil, err
+		}
+		for i, up := range ups {
+			c := cur[up.ID]
+			if c.ver != up.BaseVer {
+				return nil, &errs.ConflictError{Index: i, ID: up.ID, Ver: c.ver, UpdatedAt: c.at}
 			}
-			results = append(results, model.ItemVersion{ID: up.ID, NewVer: 1})
+			results = append(results, model.ItemVersion{ID: up.ID, NewVer: c.ver + 1})
+		}
+		if err = writeItems(ctx, tx, tid, userID, ups, cur); err != nil {
+			return nil, err
+		}
+	}
+	if err = r.checkQuota(ctx, tx, tid); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// UpsertEach writes each item under its own savepoint, so a stale base_ver
+// or an id taken by another user fails just that item. The quota is checked
+// once, over the whole batch.
+func (r *ItemRepo) UpsertEach(
+	ctx context.Context, userID uuid.UUID, ups []model.UpsertItem,
+) (results []model.ItemResult, err error) {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.db.Pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+			return
+		}
+		if e := tx.Commit(ctx); e != nil {
+			results, err = nil, e
+		}
+	}()
+
+	tid := tenant.FromContext(ctx)
+	if err = lockChanges(ctx, tx, tid, userID); err != nil {
+		return nil, err
+	}
+	results = make([]model.ItemResult, 0, len(ups))
+	for _, up := range ups {
+		if _, err = tx.Exec(ctx, `SAVEPOINT item`); err != nil {
+			return nil, err
+		}
+		res, ierr := upsertItem(ctx, tx, tid, userID, up)
+		switch {
+		case ierr == nil && res.Status == model.StatusOK:
+			_, err = tx.Exec(ctx, `RELEASE SAVEPOINT item`)
+		case ierr == nil:
+			_, err = tx.Exec(ctx, `ROLLBACK TO SAVEPOINT item`)
+		case isUniqueViolation(ierr):
+			res = model.ItemResult{ID: up.ID, Status: model.StatusVersionConflict}
+			_, err = tx.Exec(ctx, `ROLLBACK TO SAVEPOINT item`)
 		default:
-			return nil, scanErr
+			err = ierr
 		}
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, res)
+	}
+	if err = r.checkQuota(ctx, tx, tid); err != nil {
+		return nil, err
 	}
 	return results, nil
 }
 
+// upsertItem writes one item of a batch in tx. A base_ver that does not
+// match is a StatusVersionConflict result carrying the current version and
+// its updated_at (zero for a missing item), not an error.
+func upsertItem(ctx context.Context, tx pgx.Tx, tid string, userID uuid.UUID, up model.UpsertItem) (model.ItemResult, error) {
+	const sel = `SELECT ver, updated_at FROM items WHERE id=$1 AND user_id=$2 AND tenant_id=$3 FOR UPDATE`
+	const ins = `INSERT INTO items (id, user_id, blob_enc, ver, deleted, tenant_id, size_class, schema_version) VALUES ($1,$2,$3,$4,false,$5,$6,$7)`
+	const upd = `UPDATE items SET blob_enc=$3, ver=$4, deleted=false, size_class=$6, schema_version=$7 WHERE id=$1 AND user_id=$2 AND tenant_id=$5`
+
+	var (
+		curVer int64
+		curAt  time.Time
+	)
+	scanErr := tx.QueryRow(ctx, sel, up.ID, userID, tid).Scan(&curVer, &curAt)
+	switch {
+	case errors.Is(scanErr, pgx.ErrNoRows):
+		curVer, curAt = 0, time.Time{}
+	case scanErr != nil:
+		return model.ItemResult{}, scanErr
+	}
+	if curVer != up.BaseVer {
+		return model.ItemResult{ID: up.ID, Status: model.StatusVersionConflict, Ver: curVer, UpdatedAt: curAt}, nil
+	}
+	q := upd
+	if scanErr != nil {
+		q = ins
+	}
+	if _, err := tx.Exec(ctx, q, up.ID, userID, []byte(up.BlobEnc), curVer+1, tid, int16(up.Hints.SizeClass), up.Hints.SchemaVersion); err != nil {
+		return model.ItemResult{}, err
+	}
+	return model.ItemResult{ID: up.ID, Status: model.StatusOK, Ver: curVer + 1}, nil
+}
+
+// lockedItem is the state of an item row locked by lockItems.
+type lockedItem struct {
+	ver int64
+	at  time.Time
+}
+
+// repeats reports whether an id occurs more than once in ids.
+func repeats(ids []uuid.UUID) bool {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			return true
+		}
+		seen[id] = true
+	}
+	return false
+}
+
+// lockItems locks the user's rows among ids FOR UPDATE in one round trip,
+// in id order, and returns their state; ids without a row are absent.
+// Taking the array as one parameter keeps the statement text fixed, so
+// pgx prepares it once per connection whatever the batch size.
+func lockItems(ctx context.Context, tx pgx.Tx, tid string, userID uuid.UUID, ids []uuid.UUID) (map[uuid.UUID]lockedItem, error) {
+	const q = `SELECT id, ver, updated_at FROM items WHERE user_id=$1 AND tenant_id=$2 AND id = ANY($3) ORDER BY id FOR UPDATE`
+	rows, err := tx.Query(ctx, q, userID, tid, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := make(map[uuid.UUID]lockedItem, len(ids))
+	for rows.Next() {
+		var (
+			id uuid.UUID
+			it lockedItem
+		)
+		if err = rows.Scan(&id, &it.ver, &it.at); err != nil {
+			return nil, err
+		}
+		out[id] = it
+	}
+	return out, rows.Err()
+}
+
+// writeItems stores ups, whose base versions have been checked against
+// cur, with at most one UPDATE and one INSERT. The ids must be distinct.
+// An id another user holds makes the INSERT fail with a unique violation.
+func writeItems(ctx context.Context, tx pgx.Tx, tid string, userID uuid.UUID, ups []model.UpsertItem, cur map[uuid.UUID]lockedItem) error {
+	type cols struct {
+		ids   []uuid.UUID
+		blobs [][]byte
+		cls   []int16
+		sv    []int32
+	}
+	var upd, ins cols
+	for _, up := range ups {
+		c := &ins
+		if _, ok := cur[up.ID]; ok {
+			c = &upd
+		}
+		c.ids = append(c.ids, up.ID)
+		c.blobs = append(c.blobs, []byte(up.BlobEnc))
+		c.cls = append(c.cls, int16(up.Hints.SizeClass))
+		c.sv = append(c.sv, up.Hints.SchemaVersion)
+	}
+	if len(upd.ids) > 0 {
+		const q = `
+UPDATE items i SET blob_enc=u.blob, ver=i.ver+1, deleted=false, size_class=u.cls, schema_version=u.sv
+FROM unnest($3::uuid[], $4::bytea[], $5::smallint[], $6::int[]) AS u(id, blob, cls, sv)
+WHERE i.id=u.id AND i.user_id=$1 AND i.tenant_id=$2`
+		if _, err := tx.Exec(ctx, q, userID, tid, upd.ids, upd.blobs, upd.cls, upd.sv); err != nil {
+			return err
+		}
+	}
+	if len(ins.ids) > 0 {
+		const q = `
+INSERT INTO items (id, user_id, blob_enc, ver, deleted, tenant_id, size_class, schema_version)
+SELECT u.id, $1, u.blob, 1, false, $2, u.cls, u.sv
+FROM unnest($3::uuid[], $4::bytea[], $5::smallint[], $6::int[]) AS u(id, blob, cls, sv)`
+		if _, err := tx.Exec(ctx, q, userID, tid, ins.ids, ins.blobs, ins.cls, ins.sv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lockChanges takes the user's journal lock for the rest of tx. Writers of
+// a user's items queue on it, so the journal entries their writes add are
+// numbered in commit order and a reader past seq n never misses a later
+// commit below n. Take it before any item row lock, or writers deadlock.
+func lockChanges(ctx context.Context, tx pgx.Tx, tid string, userID uuid.UUID) error {
+	_, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtextextended($1, 0))`, tid+"/"+userID.String())
+	return err
+}
+
+// checkQuota fails if the tenant's live items, as seen by tx after its
+// writes, exceed the quota; the caller then rolls the batch back.
+func (r *ItemRepo) checkQuota(ctx context.Context, tx pgx.Tx, tid string) error {
+	q := r.db.Tenants.Quota(tid)
+	if q.MaxItems <= 0 && q.MaxBytes <= 0 {
+		return nil
+	}
+	const usage = `
+SELECT count(*), COALESCE(SUM(octet_length(blob_enc)),0)
+FROM items WHERE tenant_id=$1 AND NOT deleted`
+	var items, size int64
+	if err := tx.QueryRow(ctx, usage, tid).Scan(&items, &size); err != nil {
+		return err
+	}
+	if q.MaxItems > 0 && items > q.MaxItems {
+		return fmt.Errorf("%w: tenant %s would hold %d items (max %d)", errs.ErrQuotaExceeded, tid, items, q.MaxItems)
+	}
+	if q.MaxBytes > 0 && size > q.MaxBytes {
+		return fmt.Errorf("%w: tenant %s would hold %d bytes (max %d)", errs.ErrQuotaExceeded, tid, size, q.MaxBytes)
+	}
+	return nil
+}
+
 // Delete marks an item as deleted (tombstone) with version increment.
 func (r *ItemRepo) Delete(
 	ctx context.Context, userID, itemID uuid.UUID, baseVer int64,
 ) (ver model.ItemVersion, err error) {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
 	tx, err := r.db.Pool.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
 		return model.ItemVersion{}, err
@@ -88,34 +319,210 @@ func (r *ItemRepo) Delete(
 		}
 	}()
 
-	const sel = `SELECT ver FROM items WHERE id=$1 AND user_id=$2 FOR UPDATE`
-	const upd = `UPDATE items SET deleted=true, ver=$3 WHERE id=$1 AND user_id=$2`
+	tid := tenant.FromContext(ctx)
+	if err = lockChanges(ctx, tx, tid, userID); err != nil {
+		return model.ItemVersion{}, err
+	}
+	const sel = `SELECT ver, updated_at FROM items WHERE id=$1 AND user_id=$2 AND tenant_id=$3 FOR UPDATE`
+	const upd = `UPDATE items SET deleted=true, ver=$3 WHERE id=$1 AND user_id=$2 AND tenant_id=$4`
 
-	var curVer int64
-	if err = tx.QueryRow(ctx, sel, itemID, userID).Scan(&curVer); err != nil {
+	var (
+		curVer int64
+		curAt  time.Time
+	)
+	if err = tx.QueryRow(ctx, sel, itemID, userID, tid).Scan(&curVer, &curAt); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return model.ItemVersion{}, errs.ErrNotFound
 		}
 		return model.ItemVersion{}, err
 	}
 	if curVer != baseVer {
-		return model.ItemVersion{}, errs.ErrVersionConflict
+		return model.ItemVersion{}, &errs.ConflictError{ID: itemID, Ver: curVer, UpdatedAt: curAt}
 	}
 	newVer := curVer + 1
-	if _, err = tx.Exec(ctx, upd, itemID, userID, newVer); err != nil {
+	if _, err = tx.Exec(ctx, upd, itemID, userID, newVer, tid); err != nil {
 		return model.ItemVersion{}, err
 	}
 	return model.ItemVersion{ID: itemID, NewVer: newVer}, nil
 }
 
-// GetChangesSince returns changes strictly after the provided version.
-func (r *ItemRepo) GetChangesSince(ctx context.Context, userID uuid.UUID, sinceVer int64) ([]model.Change, error) {
+// DeleteBatch tombstones the items in one transaction. Every ref is checked
+// even after a failure, so the caller learns all the items that stand in
+// the way; then the whole batch is rolled back. Distinct ids are locked and
+// tombstoned with one statement each, repeated ones item by item.
+func (r *ItemRepo) DeleteBatch(
+	ctx context.Context, userID uuid.UUID, refs []model.ItemRef,
+) (results []model.ItemResult, err error) {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.db.Pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil || !model.AllOK(results) {
+			_ = tx.Rollback(ctx)
+			return
+		}
+		if e := tx.Commit(ctx); e != nil {
+			results, err = nil, e
+		}
+	}()
+
+	tid := tenant.FromContext(ctx)
+	if err = lockChanges(ctx, tx, tid, userID); err != nil {
+		return nil, err
+	}
+	ids := make([]uuid.UUID, len(refs))
+	for i, ref := range refs {
+		ids[i] = ref.ID
+	}
+	results = make([]model.ItemResult, 0, len(refs))
+	if repeats(ids) {
+		for _, ref := range refs {
+			res, err := deleteItem(ctx, tx, tid, userID, ref)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, res)
+		}
+	} else {
+		cur, err := lockItems(ctx, tx, tid, userID, ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, ref := range refs {
+			c, ok := cur[ref.ID]
+			switch {
+			case !ok:
+				results = append(results, model.ItemResult{ID: ref.ID, Status: model.StatusNotFound})
+			case c.ver != ref.BaseVer:
+				results = append(results, model.ItemResult{ID: ref.ID, Status: model.StatusVersionConflict, Ver: c.ver, UpdatedAt: c.at})
+			default:
+				results = append(results, model.ItemResult{ID: ref.ID, Status: model.StatusOK, Ver: c.ver + 1})
+			}
+		}
+		if model.AllOK(results) {
+			const upd = `UPDATE items SET deleted=true, ver=ver+1 WHERE user_id=$1 AND tenant_id=$2 AND id = ANY($3)`
+			if _, err = tx.Exec(ctx, upd, userID, tid, ids); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if !model.AllOK(results) {
+		model.AbortOK(results)
+	}
+	return results, nil
+}
+
+// deleteItem tombstones one item of a batch in tx. A missing item or a
+// stale base_ver is a result, not an error. A matching item is deleted
+// even if the batch is already doomed, so a repeated id sees its first
+// delete.
+func deleteItem(ctx context.Context, tx pgx.Tx, tid string, userID uuid.UUID, ref model.ItemRef) (model.ItemResult, error) {
+	const sel = `SELECT ver, updated_at FROM items WHERE id=$1 AND user_id=$2 AND tenant_id=$3 FOR UPDATE`
+	const upd = `UPDATE items SET deleted=true, ver=$3 WHERE id=$1 AND user_id=$2 AND tenant_id=$4`
+
+	var (
+		curVer int64
+		curAt  time.Time
+	)
+	err := tx.QueryRow(ctx, sel, ref.ID, userID, tid).Scan(&curVer, &curAt)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return model.ItemResult{ID: ref.ID, Status: model.StatusNotFound}, nil
+	case err != nil:
+		return model.ItemResult{}, err
+	case curVer != ref.BaseVer:
+		return model.ItemResult{ID: ref.ID, Status: model.StatusVersionConflict, Ver: curVer, UpdatedAt: curAt}, nil
+	}
+	if _, err := tx.Exec(ctx, upd, ref.ID, userID, curVer+1, tid); err != nil {
+		return model.ItemResult{}, err
+	}
+	return model.ItemResult{ID: ref.ID, Status: model.StatusOK, Ver: curVer + 1}, nil
+}
+
+// ApplyChangeSet writes the upserts, each under a savepoint as in
+// UpsertEach, then deletes as in DeleteBatch, all in one transaction. The
+// transaction commits only if every entry succeeded and the quota holds.
+func (r *ItemRepo) ApplyChangeSet(
+	ctx context.Context, userID uuid.UUID, cs model.ChangeSet,
+) (res model.ChangeSetResult, err error) {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.db.Pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return model.ChangeSetResult{}, err
+	}
+	defer func() {
+		if err != nil || !res.Applied() {
+			_ = tx.Rollback(ctx)
+			return
+		}
+		if e := tx.Commit(ctx); e != nil {
+			res, err = model.ChangeSetResult{}, e
+		}
+	}()
+
+	tid := tenant.FromContext(ctx)
+	if err = lockChanges(ctx, tx, tid, userID); err != nil {
+		return model.ChangeSetResult{}, err
+	}
+	res.Upserts = make([]model.ItemResult, 0, len(cs.Upserts))
+	for _, up := range cs.Upserts {
+		if _, err = tx.Exec(ctx, `SAVEPOINT item`); err != nil {
+			return model.ChangeSetResult{}, err
+		}
+		ir, ierr := upsertItem(ctx, tx, tid, userID, up)
+		switch {
+		case ierr == nil:
+			_, err = tx.Exec(ctx, `RELEASE SAVEPOINT item`)
+		case isUniqueViolation(ierr):
+			ir = model.ItemResult{ID: up.ID, Status: model.StatusVersionConflict}
+			_, err = tx.Exec(ctx, `ROLLBACK TO SAVEPOINT item`)
+		default:
+			err = ierr
+		}
+		if err != nil {
+			return model.ChangeSetResult{}, err
+		}
+		res.Upserts = append(res.Upserts, ir)
+	}
+	res.Deletes = make([]model.ItemResult, 0, len(cs.Deletes))
+	for _, ref := range cs.Deletes {
+		ir, err := deleteItem(ctx, tx, tid, userID, ref)
+		if err != nil {
+			return model.ChangeSetResult{}, err
+		}
+		res.Deletes = append(res.Deletes, ir)
+	}
+	if !res.Applied() {
+		model.AbortOK(res.Upserts)
+		model.AbortOK(res.Deletes)
+		return res, nil
+	}
+	if err = r.checkQuota(ctx, tx, tid); err != nil {
+		return model.ChangeSetResult{}, err
+	}
+	return res, nil
+}
+
+// GetChangesSince returns a page of changes after the cursor. The row
+// comparison walks idx_items_changes, so a page costs O(limit) whatever the
+// vault's size.
+func (r *ItemRepo) GetChangesSince(ctx context.Context, userID uuid.UUID, after model.ChangeCursor, limit int) ([]model.Change, error) {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
 	const q = `
-SELECT id, ver, deleted, updated_at, blob_enc
+SELECT id, ver, deleted, updated_at, blob_enc, size_class, schema_version
 FROM items
-WHERE user_id=$1 AND ver>$2
-ORDER BY ver ASC`
-	rows, err := r.db.Pool.Query(ctx, q, userID, sinceVer)
+WHERE user_id=$1 AND tenant_id=$3 AND (ver, id) > ($2, $4)
+ORDER BY ver, id
+LIMIT NULLIF($5, 0)`
+	rows, err := r.db.Pool.Query(ctx, q, userID, after.Ver, tenant.FromContext(ctx), after.ID, max(limit, 0))
 	if err != nil {
 		return nil, err
 	}
@@ -129,11 +536,13 @@ ORDER BY ver ASC`
 			del  bool
 			ts   time.Time
 			blob []byte
+			cls  int16
+			sv   int32
 		)
-		if err = rows.Scan(&id, &ver, &del, &ts, &blob); err != nil {
+		if err = rows.Scan(&id, &ver, &del, &ts, &blob, &cls, &sv); err != nil {
 			return nil, err
 		}
-		ch := model.Change{ID: id, Ver: ver, Deleted: del, UpdatedAt: ts}
+		ch := model.Change{ID: id, Ver: ver, Deleted: del, UpdatedAt: ts, Hints: model.ItemHints{SizeClass: model.SizeClass(cls), SchemaVersion: sv}}
 		if !del {
 			ch.BlobEnc = model.EncryptedBlob(blob)
 		}
@@ -142,28 +551,272 @@ ORDER BY ver ASC`
 	return out, rows.Err()
 }
 
+// GetJournalSince reads item_changes after seq, keeping only each item's
+// newest entry, joined with the item as it is now.
+func (r *ItemRepo) GetJournalSince(ctx context.Context, userID uuid.UUID, afterSeq int64, limit int) ([]model.Change, error) {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
+	const q = `
+SELECT c.seq, c.item_id, COALESCE(i.ver, c.ver), COALESCE(i.deleted, true), COALESCE(i.updated_at, c.changed_at),
+       i.blob_enc, COALESCE(i.size_class, 0), COALESCE(i.schema_version, 0)
+FROM item_changes c
+LEFT JOIN items i ON i.id = c.item_id AND i.user_id = c.user_id AND i.tenant_id = c.tenant_id
+WHERE c.user_id=$1 AND c.tenant_id=$2 AND c.seq > $3
+  AND NOT EXISTS (SELECT 1 FROM item_changes l WHERE l.item_id = c.item_id AND l.seq > c.seq)
+ORDER BY c.seq
+LIMIT NULLIF($4, 0)`
+	rows, err := r.db.Pool.Query(ctx, q, userID, tenant.FromContext(ctx), afterSeq, max(limit, 0))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []model.Change
+	for rows.Next() {
+		var (
+			ch   model.Change
+			blob []byte
+			cls  int16
+		)
+		if err = rows.Scan(&ch.Seq, &ch.ID, &ch.Ver, &ch.Deleted, &ch.UpdatedAt, &blob, &cls, &ch.Hints.SchemaVersion); err != nil {
+			return nil, err
+		}
+		ch.Hints.SizeClass = model.SizeClass(cls)
+		if !ch.Deleted {
+			ch.BlobEnc = model.EncryptedBlob(blob)
+		}
+		out = append(out, ch)
+	}
+	return out, rows.Err()
+}
+
+// JournalHead returns the user's newest committed journal seq. Writers
+// commit under the user's journal lock, so every change up to it is in.
+func (r *ItemRepo) JournalHead(ctx context.Context, userID uuid.UUID) (int64, error) {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
+	const q = `SELECT COALESCE(max(seq), 0) FROM item_changes WHERE user_id=$1 AND tenant_id=$2`
+	var seq int64
+	err := r.db.Pool.QueryRow(ctx, q, userID, tenant.FromContext(ctx)).Scan(&seq)
+	return seq, err
+}
+
+// GetSnapshot finds each item's last journal entry up to atSeq and reads
+// that version from item_history. Only rollbacks take versions back: they
+// discard history above the version they restore, which later writes may
+// reuse, and journal the restore with a lower version (or a removal). An
+// entry like that after atSeq makes the snapshot's version unreliable even
+// when a row with its number exists.
+func (r *ItemRepo) GetSnapshot(ctx context.Context, userID uuid.UUID, atSeq int64, afterID uuid.UUID, limit int) ([]model.Change, error) {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
+	const q = `
+SELECT l.seq, l.item_id, l.ver,
+       h.item_id IS NOT NULL AND NOT EXISTS (
+         SELECT 1 FROM item_changes c
+         WHERE c.item_id = l.item_id AND c.seq > $3 AND (c.op = 'remove' OR c.ver < l.ver)),
+       COALESCE(h.deleted, false), COALESCE(h.updated_at, l.changed_at), h.blob_enc,
+       COALESCE(h.size_class, 0), COALESCE(h.schema_version, 0)
+FROM (
+  SELECT DISTINCT ON (item_id) seq, item_id, op, ver, changed_at
+  FROM item_changes
+  WHERE user_id=$1 AND tenant_id=$2 AND seq <= $3 AND item_id > $4
+  ORDER BY item_id, seq DESC
+) l
+LEFT JOIN item_history h ON h.item_id = l.item_id AND h.ver = l.ver
+WHERE l.op <> 'remove'
+ORDER BY l.item_id
+LIMIT NULLIF($5, 0)`
+	rows, err := r.db.Pool.Query(ctx, q, userID, tenant.FromContext(ctx), atSeq, afterID, max(limit, 0))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []model.Change
+	for rows.Next() {
+		var (
+			ch     model.Change
+			intact bool
+			blob   []byte
+			cls    int16
+		)
+		if err = rows.Scan(&ch.Seq, &ch.ID, &ch.Ver, &intact, &ch.Deleted, &ch.UpdatedAt, &blob, &cls, &ch.Hints.SchemaVersion); err != nil {
+			return nil, err
+		}
+		if !intact {
+			return nil, fmt.Errorf("%w: item %s ver %d", errs.ErrSnapshotGone, ch.ID, ch.Ver)
+		}
+		ch.Hints.SizeClass = model.SizeClass(cls)
+		if !ch.Deleted {
+			ch.BlobEnc = model.EncryptedBlob(blob)
+		}
+		out = append(out, ch)
+	}
+	return out, rows.Err()
+}
+
 // GetItem returns a single item by id.
 func (r *ItemRepo) GetItem(ctx context.Context, userID, itemID uuid.UUID) (*model.Item, error) {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
+	const q = `
+SELECT id, user_id, blob_enc, ver, deleted, updated_at, size_class, schema_version
+FROM items WHERE user_id=$1 AND id=$2 AND tenant_id=$3`
+	row := r.db.Pool.QueryRow(ctx, q, userID, itemID, tenant.FromContext(ctx))
+	return scanItem(row)
+}
+
+// GetItemVersion returns a historical item version recorded in item_history.
+func (r *ItemRepo) GetItemVersion(ctx context.Context, userID, itemID uuid.UUID, ver int64) (*model.Item, error) {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
 	const q = `
-SELECT id, user_id, blob_enc, ver, deleted, updated_at
-FROM items WHERE user_id=$1 AND id=$2`
-	row := r.db.Pool.QueryRow(ctx, q, userID, itemID)
-	var it model.Item
-	if err := row.Scan(&it.ID, &it.UserID, &it.BlobEnc, &it.Ver, &it.Deleted, &it.UpdatedAt); err != nil {
+SELECT item_id, user_id, blob_enc, ver, deleted, updated_at, size_class, schema_version
+FROM item_history WHERE user_id=$1 AND item_id=$2 AND ver=$3 AND tenant_id=$4`
+	row := r.db.Pool.QueryRow(ctx, q, userID, itemID, ver, tenant.FromContext(ctx))
+	return scanItem(row)
+}
+
+// scanItem reads a row of the item columns selected by GetItem and
+// GetItemVersion.
+func scanItem(row pgx.Row) (*model.Item, error) {
+	var (
+		it  model.Item
+		cls int16
+	)
+	if err := row.Scan(&it.ID, &it.UserID, &it.BlobEnc, &it.Ver, &it.Deleted, &it.UpdatedAt, &cls, &it.Hints.SchemaVersion); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, errs.ErrNotFound
 		}
 		return nil, err
 	}
+	it.Hints.SizeClass = model.SizeClass(cls)
 	return &it, nil
 }
 
+// exportPage is how many items ExportItems reads per query.
+const exportPage = 100
+
+// ExportItems pages through the items by id in one repeatable-read
+// transaction, so every page comes from the same snapshot. The transaction
+// stays open while fn runs, which is as long as the client takes; only
+// each page's query is bounded by the call timeout.
+func (r *ItemRepo) ExportItems(ctx context.Context, userID uuid.UUID, fn func(model.Item) error) error {
+	tx, err := r.db.Pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	const q = `
+SELECT id, user_id, blob_enc, ver, deleted, updated_at, size_class, schema_version
+FROM items WHERE user_id=$1 AND tenant_id=$2 AND id > $3
+ORDER BY id LIMIT $4`
+	tid := tenant.FromContext(ctx)
+	after := uuid.Nil
+	for {
+		page, err := r.exportPage(ctx, tx, q, userID, tid, after)
+		if err != nil {
+			return err
+		}
+		for _, it := range page {
+			if err := fn(it); err != nil {
+				return err
+			}
+		}
+		if len(page) < exportPage {
+			return nil
+		}
+		after = page[len(page)-1].ID
+	}
+}
+
+func (r *ItemRepo) exportPage(ctx context.Context, tx pgx.Tx, q string, userID uuid.UUID, tid string, after uuid.UUID) ([]model.Item, error) {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := tx.Query(ctx, q, userID, tid, after, exportPage)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []model.Item
+	for rows.Next() {
+		it, err := scanItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *it)
+	}
+	return out, rows.Err()
+}
+
+// Digests hashes blobs in the database, so only 32 bytes per item cross the wire.
+func (r *ItemRepo) Digests(ctx context.Context, userID uuid.UUID) ([]model.ItemDigest, error) {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
+	const q = `
+SELECT id, ver, deleted, CASE WHEN deleted THEN NULL ELSE sha256(blob_enc) END
+FROM items WHERE user_id=$1 AND tenant_id=$2
+ORDER BY id`
+	rows, err := r.db.Pool.Query(ctx, q, userID, tenant.FromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []model.ItemDigest
+	for rows.Next() {
+		var d model.ItemDigest
+		if err = rows.Scan(&d.ID, &d.Ver, &d.Deleted, &d.BlobHash); err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
 // GetMaxVersion returns the current maximum version for a user.
 func (r *ItemRepo) GetMaxVersion(ctx context.Context, userID uuid.UUID) (int64, error) {
-	const q = `SELECT COALESCE(MAX(ver),0) FROM items WHERE user_id=$1`
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
+	const q = `SELECT COALESCE(MAX(ver),0) FROM items WHERE user_id=$1 AND tenant_id=$2`
 	var v int64
-	if err := r.db.Pool.QueryRow(ctx, q, userID).Scan(&v); err != nil {
+	if err := r.db.Pool.QueryRow(ctx, q, userID, tenant.FromContext(ctx)).Scan(&v); err != nil {
 		return 0, err
 	}
 	return v, nil
 }
+
+// Stats aggregates item counters for a user in a single scan.
+func (r *ItemRepo) Stats(ctx context.Context, userID uuid.UUID) (model.ItemStats, error) {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
+	const q = `
+SELECT count(*),
+       count(*) FILTER (WHERE deleted),
+       COALESCE(MAX(ver),0),
+       COALESCE(SUM(octet_length(blob_enc)) FILTER (WHERE NOT deleted),0),
+       MAX(updated_at)
+FROM items WHERE user_id=$1 AND tenant_id=$2`
+	var (
+		st   model.ItemStats
+		last *time.Time
+	)
+	if err := r.db.Pool.QueryRow(ctx, q, userID, tenant.FromContext(ctx)).Scan(&st.Items, &st.Deleted, &st.MaxVer, &st.TotalBytes, &last); err != nil {
+		return model.ItemStats{}, err
+	}
+	if last != nil {
+		st.LastUpdated = *last
+	}
+	return st, nil
+}
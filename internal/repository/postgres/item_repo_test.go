@@ -8,8 +8,10 @@ import (
 
 	"github.com/and161185/goph-keeper/internal/errs"
 	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/and161185/goph-keeper/internal/tenant"
 	"github.com/gofrs/uuid/v5"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	pgxmock "github.com/pashagolub/pgxmock/v3"
 	"github.com/stretchr/testify/require"
 )
@@ -21,6 +23,33 @@ func newDB(t *testing.T) (*DB, pgxmock.PgxPoolIface) {
 	return &DB{Pool: mock}, mock
 }
 
+// rowTime is the updated_at of rows returned by mocked FOR UPDATE selects.
+var rowTime = time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// lockedRow is the result of the FOR UPDATE select for an item at ver.
+func lockedRow(ver int64) *pgxmock.Rows {
+	return pgxmock.NewRows([]string{"ver", "updated_at"}).AddRow(ver, rowTime)
+}
+
+// lockedRows is the result of lockItems for items at the given versions.
+func lockedRows(vers map[uuid.UUID]int64) *pgxmock.Rows {
+	rows := pgxmock.NewRows([]string{"id", "ver", "updated_at"})
+	for id, ver := range vers {
+		rows.AddRow(id, ver, rowTime)
+	}
+	return rows
+}
+
+const lockItemsQ = `SELECT id, ver, updated_at FROM items WHERE user_id=\$1 AND tenant_id=\$2 AND id = ANY\(\$3\) ORDER BY id FOR UPDATE`
+
+// expectBegin expects a write transaction to open and take the user's
+// change journal lock.
+func expectBegin(mock pgxmock.PgxPoolIface) {
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT pg_advisory_xact_lock\(hashtextextended\(\$1, 0\)\)`).WithArgs(pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("SELECT", 1))
+}
+
 func TestItemRepo_UpsertBatch_Update_OK(t *testing.T) {
 	db, mock := newDB(t)
 	defer mock.Close()
@@ -31,17 +60,17 @@ func TestItemRepo_UpsertBatch_Update_OK(t *testing.T) {
 	itemID := uuid.Must(uuid.NewV4())
 	base := int64(5)
 
-	mock.ExpectBegin()
-	mock.ExpectQuery(`SELECT ver FROM items WHERE id=\$1 AND user_id=\$2 FOR UPDATE`).
-		WithArgs(itemID, userID).
-		WillReturnRows(pgxmock.NewRows([]string{"ver"}).AddRow(base))
-	mock.ExpectExec(`UPDATE items SET blob_enc=\$3, ver=\$4, deleted=false WHERE id=\$1 AND user_id=\$2`).
-		WithArgs(itemID, userID, []byte("enc"), base+1).
+	expectBegin(mock)
+	mock.ExpectQuery(lockItemsQ).
+		WithArgs(userID, tenant.Default, []uuid.UUID{itemID}).
+		WillReturnRows(lockedRows(map[uuid.UUID]int64{itemID: base}))
+	mock.ExpectExec(`UPDATE items i SET blob_enc=u.blob, ver=i.ver\+1, deleted=false, size_class=u.cls, schema_version=u.sv\s+FROM unnest`).
+		WithArgs(userID, tenant.Default, []uuid.UUID{itemID}, [][]byte{[]byte("enc")}, []int16{int16(model.SizeSmall)}, []int32{2}).
 		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 	mock.ExpectCommit()
 
 	res, err := r.UpsertBatch(ctx, userID, []model.UpsertItem{
-		{ID: itemID, BaseVer: base, BlobEnc: model.EncryptedBlob("enc")},
+		{ID: itemID, BaseVer: base, BlobEnc: model.EncryptedBlob("enc"), Hints: model.ItemHints{SizeClass: model.SizeSmall, SchemaVersion: 2}},
 	})
 	require.NoError(t, err)
 	require.Equal(t, 1, len(res))
@@ -57,12 +86,12 @@ func TestItemRepo_UpsertBatch_Create_OK(t *testing.T) {
 	userID := uuid.Must(uuid.NewV4())
 	itemID := uuid.Must(uuid.NewV4())
 
-	mock.ExpectBegin()
-	mock.ExpectQuery(`SELECT ver FROM items WHERE id=\$1 AND user_id=\$2 FOR UPDATE`).
-		WithArgs(itemID, userID).
-		WillReturnError(pgx.ErrNoRows)
-	mock.ExpectExec(`INSERT INTO items \(id, user_id, blob_enc, ver, deleted\) VALUES \(\$1,\$2,\$3,\$4,false\)`).
-		WithArgs(itemID, userID, []byte("enc"), int64(1)).
+	expectBegin(mock)
+	mock.ExpectQuery(lockItemsQ).
+		WithArgs(userID, tenant.Default, []uuid.UUID{itemID}).
+		WillReturnRows(lockedRows(nil))
+	mock.ExpectExec(`INSERT INTO items \(id, user_id, blob_enc, ver, deleted, tenant_id, size_class, schema_version\)\s+SELECT u.id, \$1, u.blob, 1, false, \$2, u.cls, u.sv\s+FROM unnest`).
+		WithArgs(userID, tenant.Default, []uuid.UUID{itemID}, [][]byte{[]byte("enc")}, []int16{0}, []int32{0}).
 		WillReturnResult(pgxmock.NewResult("INSERT", 1))
 	mock.ExpectCommit()
 
@@ -82,16 +111,19 @@ func TestItemRepo_UpsertBatch_VersionConflict_OnUpdate(t *testing.T) {
 	userID := uuid.Must(uuid.NewV4())
 	itemID := uuid.Must(uuid.NewV4())
 
-	mock.ExpectBegin()
-	mock.ExpectQuery(`SELECT ver FROM items WHERE id=\$1 AND user_id=\$2 FOR UPDATE`).
-		WithArgs(itemID, userID).
-		WillReturnRows(pgxmock.NewRows([]string{"ver"}).AddRow(int64(2)))
+	expectBegin(mock)
+	mock.ExpectQuery(lockItemsQ).
+		WithArgs(userID, tenant.Default, []uuid.UUID{itemID}).
+		WillReturnRows(lockedRows(map[uuid.UUID]int64{itemID: 2}))
 	mock.ExpectRollback()
 
 	_, err := r.UpsertBatch(ctx, userID, []model.UpsertItem{
 		{ID: itemID, BaseVer: 1, BlobEnc: model.EncryptedBlob("x")},
 	})
 	require.ErrorIs(t, err, errs.ErrVersionConflict)
+	var ce *errs.ConflictError
+	require.ErrorAs(t, err, &ce)
+	require.Equal(t, errs.ConflictError{ID: itemID, Ver: 2, UpdatedAt: rowTime}, *ce)
 }
 
 func TestItemRepo_UpsertBatch_VersionConflict_OnCreate(t *testing.T) {
@@ -103,10 +135,10 @@ func TestItemRepo_UpsertBatch_VersionConflict_OnCreate(t *testing.T) {
 	userID := uuid.Must(uuid.NewV4())
 	itemID := uuid.Must(uuid.NewV4())
 
-	mock.ExpectBegin()
-	mock.ExpectQuery(`SELECT ver FROM items WHERE id=\$1 AND user_id=\$2 FOR UPDATE`).
-		WithArgs(itemID, userID).
-		WillReturnError(pgx.ErrNoRows)
+	expectBegin(mock)
+	mock.ExpectQuery(lockItemsQ).
+		WithArgs(userID, tenant.Default, []uuid.UUID{itemID}).
+		WillReturnRows(lockedRows(nil))
 	mock.ExpectRollback()
 
 	_, err := r.UpsertBatch(ctx, userID, []model.UpsertItem{
@@ -115,6 +147,52 @@ func TestItemRepo_UpsertBatch_VersionConflict_OnCreate(t *testing.T) {
 	require.ErrorIs(t, err, errs.ErrVersionConflict)
 }
 
+func TestItemRepo_UpsertEach(t *testing.T) {
+	db, mock := newDB(t)
+	defer mock.Close()
+	r := NewItemRepo(db)
+
+	userID := uuid.Must(uuid.NewV4())
+	a, b, c := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	const sel = `SELECT ver, updated_at FROM items WHERE id=\$1 AND user_id=\$2 AND tenant_id=\$3 FOR UPDATE`
+
+	expectBegin(mock)
+	// a: written
+	mock.ExpectExec(`SAVEPOINT item`).WillReturnResult(pgxmock.NewResult("SAVEPOINT", 0))
+	mock.ExpectQuery(sel).WithArgs(a, userID, tenant.Default).
+		WillReturnRows(lockedRow(int64(1)))
+	mock.ExpectExec(`UPDATE items SET blob_enc`).
+		WithArgs(a, userID, []byte("a"), int64(2), tenant.Default, int16(0), int32(0)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectExec(`RELEASE SAVEPOINT item`).WillReturnResult(pgxmock.NewResult("RELEASE", 0))
+	// b: stale
+	mock.ExpectExec(`SAVEPOINT item`).WillReturnResult(pgxmock.NewResult("SAVEPOINT", 0))
+	mock.ExpectQuery(sel).WithArgs(b, userID, tenant.Default).
+		WillReturnRows(lockedRow(int64(6)))
+	mock.ExpectExec(`ROLLBACK TO SAVEPOINT item`).WillReturnResult(pgxmock.NewResult("ROLLBACK", 0))
+	// c: id held by another user
+	mock.ExpectExec(`SAVEPOINT item`).WillReturnResult(pgxmock.NewResult("SAVEPOINT", 0))
+	mock.ExpectQuery(sel).WithArgs(c, userID, tenant.Default).WillReturnError(pgx.ErrNoRows)
+	mock.ExpectExec(`INSERT INTO items`).
+		WithArgs(c, userID, []byte("c"), int64(1), tenant.Default, int16(0), int32(0)).
+		WillReturnError(&pgconn.PgError{Code: "23505"})
+	mock.ExpectExec(`ROLLBACK TO SAVEPOINT item`).WillReturnResult(pgxmock.NewResult("ROLLBACK", 0))
+	mock.ExpectCommit()
+
+	res, err := r.UpsertEach(context.Background(), userID, []model.UpsertItem{
+		{ID: a, BaseVer: 1, BlobEnc: model.EncryptedBlob("a")},
+		{ID: b, BaseVer: 5, BlobEnc: model.EncryptedBlob("b")},
+		{ID: c, BlobEnc: model.EncryptedBlob("c")},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []model.ItemResult{
+		{ID: a, Status: model.StatusOK, Ver: 2},
+		{ID: b, Status: model.StatusVersionConflict, Ver: 6, UpdatedAt: rowTime},
+		{ID: c, Status: model.StatusVersionConflict},
+	}, res)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestItemRepo_Delete_OK(t *testing.T) {
 	db, mock := newDB(t)
 	defer mock.Close()
@@ -125,12 +203,12 @@ func TestItemRepo_Delete_OK(t *testing.T) {
 	itemID := uuid.Must(uuid.NewV4())
 	cur := int64(7)
 
-	mock.ExpectBegin()
-	mock.ExpectQuery(`SELECT ver FROM items WHERE id=\$1 AND user_id=\$2 FOR UPDATE`).
-		WithArgs(itemID, userID).
-		WillReturnRows(pgxmock.NewRows([]string{"ver"}).AddRow(cur))
-	mock.ExpectExec(`UPDATE items SET deleted=true, ver=\$3 WHERE id=\$1 AND user_id=\$2`).
-		WithArgs(itemID, userID, cur+1).
+	expectBegin(mock)
+	mock.ExpectQuery(`SELECT ver, updated_at FROM items WHERE id=\$1 AND user_id=\$2 AND tenant_id=\$3 FOR UPDATE`).
+		WithArgs(itemID, userID, tenant.Default).
+		WillReturnRows(lockedRow(cur))
+	mock.ExpectExec(`UPDATE items SET deleted=true, ver=\$3 WHERE id=\$1 AND user_id=\$2 AND tenant_id=\$4`).
+		WithArgs(itemID, userID, cur+1, tenant.Default).
 		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 	mock.ExpectCommit()
 
@@ -148,9 +226,9 @@ func TestItemRepo_Delete_NotFound(t *testing.T) {
 	userID := uuid.Must(uuid.NewV4())
 	itemID := uuid.Must(uuid.NewV4())
 
-	mock.ExpectBegin()
-	mock.ExpectQuery(`SELECT ver FROM items WHERE id=\$1 AND user_id=\$2 FOR UPDATE`).
-		WithArgs(itemID, userID).
+	expectBegin(mock)
+	mock.ExpectQuery(`SELECT ver, updated_at FROM items WHERE id=\$1 AND user_id=\$2 AND tenant_id=\$3 FOR UPDATE`).
+		WithArgs(itemID, userID, tenant.Default).
 		WillReturnError(pgx.ErrNoRows)
 	mock.ExpectRollback()
 
@@ -167,14 +245,100 @@ func TestItemRepo_Delete_VersionConflict(t *testing.T) {
 	userID := uuid.Must(uuid.NewV4())
 	itemID := uuid.Must(uuid.NewV4())
 
-	mock.ExpectBegin()
-	mock.ExpectQuery(`SELECT ver FROM items WHERE id=\$1 AND user_id=\$2 FOR UPDATE`).
-		WithArgs(itemID, userID).
-		WillReturnRows(pgxmock.NewRows([]string{"ver"}).AddRow(int64(3)))
+	expectBegin(mock)
+	mock.ExpectQuery(`SELECT ver, updated_at FROM items WHERE id=\$1 AND user_id=\$2 AND tenant_id=\$3 FOR UPDATE`).
+		WithArgs(itemID, userID, tenant.Default).
+		WillReturnRows(lockedRow(int64(3)))
 	mock.ExpectRollback()
 
 	_, err := r.Delete(ctx, userID, itemID, 1)
 	require.ErrorIs(t, err, errs.ErrVersionConflict)
+	var ce *errs.ConflictError
+	require.ErrorAs(t, err, &ce)
+	require.Equal(t, int64(3), ce.Ver)
+	require.Equal(t, rowTime, ce.UpdatedAt)
+}
+
+func TestItemRepo_DeleteBatch_OK(t *testing.T) {
+	db, mock := newDB(t)
+	defer mock.Close()
+	r := NewItemRepo(db)
+
+	userID := uuid.Must(uuid.NewV4())
+	a, b := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+
+	expectBegin(mock)
+	mock.ExpectQuery(lockItemsQ).WithArgs(userID, tenant.Default, []uuid.UUID{a, b}).
+		WillReturnRows(lockedRows(map[uuid.UUID]int64{a: 2, b: 5}))
+	mock.ExpectExec(`UPDATE items SET deleted=true, ver=ver\+1 WHERE user_id=\$1 AND tenant_id=\$2 AND id = ANY\(\$3\)`).
+		WithArgs(userID, tenant.Default, []uuid.UUID{a, b}).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 2))
+	mock.ExpectCommit()
+
+	res, err := r.DeleteBatch(context.Background(), userID, []model.ItemRef{{ID: a, BaseVer: 2}, {ID: b, BaseVer: 5}})
+	require.NoError(t, err)
+	require.Equal(t, []model.ItemResult{
+		{ID: a, Status: model.StatusOK, Ver: 3},
+		{ID: b, Status: model.StatusOK, Ver: 6},
+	}, res)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestItemRepo_DeleteBatch_RollsBack(t *testing.T) {
+	db, mock := newDB(t)
+	defer mock.Close()
+	r := NewItemRepo(db)
+
+	userID := uuid.Must(uuid.NewV4())
+	a, b, c := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	const sel = `SELECT ver, updated_at FROM items WHERE id=\$1 AND user_id=\$2 AND tenant_id=\$3 FOR UPDATE`
+
+	expectBegin(mock)
+	mock.ExpectQuery(lockItemsQ).WithArgs(userID, tenant.Default, []uuid.UUID{a, b, c}).
+		WillReturnRows(lockedRows(map[uuid.UUID]int64{a: 4, c: 1}))
+	mock.ExpectRollback()
+
+	res, err := r.DeleteBatch(context.Background(), userID, []model.ItemRef{{ID: a, BaseVer: 3}, {ID: b, BaseVer: 1}, {ID: c, BaseVer: 1}})
+	require.NoError(t, err)
+	require.Equal(t, []model.ItemResult{
+		{ID: a, Status: model.StatusVersionConflict, Ver: 4, UpdatedAt: rowTime},
+		{ID: b, Status: model.StatusNotFound},
+		{ID: c, Status: model.StatusAborted},
+	}, res)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestItemRepo_ApplyChangeSet_RollsBack(t *testing.T) {
+	db, mock := newDB(t)
+	defer mock.Close()
+	r := NewItemRepo(db)
+
+	userID := uuid.Must(uuid.NewV4())
+	a, b := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	const sel = `SELECT ver, updated_at FROM items WHERE id=\$1 AND user_id=\$2 AND tenant_id=\$3 FOR UPDATE`
+
+	expectBegin(mock)
+	mock.ExpectExec(`SAVEPOINT item`).WillReturnResult(pgxmock.NewResult("SAVEPOINT", 0))
+	mock.ExpectQuery(sel).WithArgs(a, userID, tenant.Default).
+		WillReturnRows(lockedRow(int64(2)))
+	mock.ExpectExec(`UPDATE items SET blob_enc`).
+		WithArgs(a, userID, []byte{1}, int64(3), tenant.Default, int16(0), int32(0)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectExec(`RELEASE SAVEPOINT item`).WillReturnResult(pgxmock.NewResult("RELEASE", 0))
+	mock.ExpectQuery(sel).WithArgs(b, userID, tenant.Default).
+		WillReturnRows(lockedRow(int64(6)))
+	mock.ExpectRollback()
+
+	res, err := r.ApplyChangeSet(context.Background(), userID, model.ChangeSet{
+		Upserts: []model.UpsertItem{{ID: a, BaseVer: 2, BlobEnc: []byte{1}}},
+		Deletes: []model.ItemRef{{ID: b, BaseVer: 5}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, model.ChangeSetResult{
+		Upserts: []model.ItemResult{{ID: a, Status: model.StatusAborted}},
+		Deletes: []model.ItemResult{{ID: b, Status: model.StatusVersionConflict, Ver: 6, UpdatedAt: rowTime}},
+	}, res)
+	require.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestItemRepo_GetChangesSince(t *testing.T) {
@@ -188,21 +352,92 @@ func TestItemRepo_GetChangesSince(t *testing.T) {
 	id1 := uuid.Must(uuid.NewV4())
 	id2 := uuid.Must(uuid.NewV4())
 
-	rows := pgxmock.NewRows([]string{"id", "ver", "deleted", "updated_at", "blob_enc"}).
-		AddRow(id1, int64(2), false, ts, []byte("enc1")).
-		AddRow(id2, int64(3), true, ts, []byte(nil))
+	rows := pgxmock.NewRows([]string{"id", "ver", "deleted", "updated_at", "blob_enc", "size_class", "schema_version"}).
+		AddRow(id1, int64(2), false, ts, []byte("enc1"), int16(model.SizeLarge), int32(1)).
+		AddRow(id2, int64(3), true, ts, []byte(nil), int16(0), int32(0))
 
-	mock.ExpectQuery(`SELECT id, ver, deleted, updated_at, blob_enc FROM items WHERE user_id=\$1 AND ver>\$2 ORDER BY ver ASC`).
-		WithArgs(userID, int64(1)).
+	mock.ExpectQuery(`SELECT id, ver, deleted, updated_at, blob_enc, size_class, schema_version FROM items WHERE user_id=\$1 AND tenant_id=\$3 AND \(ver, id\) > \(\$2, \$4\) ORDER BY ver, id LIMIT NULLIF\(\$5, 0\)`).
+		WithArgs(userID, int64(1), tenant.Default, uuid.Max, 500).
 		WillReturnRows(rows)
 
-	out, err := r.GetChangesSince(ctx, userID, 1)
+	out, err := r.GetChangesSince(ctx, userID, model.AfterVer(1), 500)
 	require.NoError(t, err)
 	require.Len(t, out, 2)
 	require.False(t, out[0].Deleted)
 	require.Equal(t, model.EncryptedBlob("enc1"), out[0].BlobEnc)
+	require.Equal(t, model.ItemHints{SizeClass: model.SizeLarge, SchemaVersion: 1}, out[0].Hints)
+	require.True(t, out[1].Deleted)
+	require.Nil(t, out[1].BlobEnc)
+}
+
+func TestItemRepo_GetJournalSince(t *testing.T) {
+	db, mock := newDB(t)
+	defer mock.Close()
+	r := NewItemRepo(db)
+
+	ctx := context.Background()
+	userID := uuid.Must(uuid.NewV4())
+	ts := time.Now().UTC()
+	id1, id2 := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+
+	rows := pgxmock.NewRows([]string{"seq", "id", "ver", "deleted", "changed_at", "blob_enc", "size_class", "schema_version"}).
+		AddRow(int64(11), id1, int64(2), false, ts, []byte("enc1"), int16(model.SizeSmall), int32(1)).
+		AddRow(int64(14), id2, int64(5), true, ts, []byte(nil), int16(0), int32(0))
+
+	mock.ExpectQuery(`FROM item_changes c\s+LEFT JOIN items i .*WHERE c.user_id=\$1 AND c.tenant_id=\$2 AND c.seq > \$3`).
+		WithArgs(userID, tenant.Default, int64(10), 100).
+		WillReturnRows(rows)
+
+	out, err := r.GetJournalSince(ctx, userID, 10, 100)
+	require.NoError(t, err)
+	require.Len(t, out, 2)
+	require.Equal(t, int64(11), out[0].Seq)
+	require.Equal(t, model.EncryptedBlob("enc1"), out[0].BlobEnc)
+	require.Equal(t, int64(14), out[1].Seq)
 	require.True(t, out[1].Deleted)
 	require.Nil(t, out[1].BlobEnc)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestItemRepo_GetSnapshot(t *testing.T) {
+	db, mock := newDB(t)
+	defer mock.Close()
+	r := NewItemRepo(db)
+
+	ctx := context.Background()
+	userID := uuid.Must(uuid.NewV4())
+	ts := time.Now().UTC()
+	id1, id2 := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	cols := []string{"seq", "item_id", "ver", "intact", "deleted", "updated_at", "blob_enc", "size_class", "schema_version"}
+
+	mock.ExpectQuery(`SELECT COALESCE\(max\(seq\), 0\) FROM item_changes WHERE user_id=\$1 AND tenant_id=\$2`).
+		WithArgs(userID, tenant.Default).
+		WillReturnRows(pgxmock.NewRows([]string{"max"}).AddRow(int64(40)))
+	head, err := r.JournalHead(ctx, userID)
+	require.NoError(t, err)
+	require.Equal(t, int64(40), head)
+
+	mock.ExpectQuery(`SELECT DISTINCT ON \(item_id\) .*seq <= \$3 AND item_id > \$4.*LEFT JOIN item_history h`).
+		WithArgs(userID, tenant.Default, int64(40), uuid.Nil, 100).
+		WillReturnRows(pgxmock.NewRows(cols).
+			AddRow(int64(11), id1, int64(2), true, false, ts, []byte("enc1"), int16(model.SizeSmall), int32(1)).
+			AddRow(int64(14), id2, int64(5), true, true, ts, []byte("gone"), int16(0), int32(0)))
+	out, err := r.GetSnapshot(ctx, userID, 40, uuid.Nil, 100)
+	require.NoError(t, err)
+	require.Len(t, out, 2)
+	require.Equal(t, model.EncryptedBlob("enc1"), out[0].BlobEnc)
+	require.Equal(t, int64(11), out[0].Seq)
+	require.True(t, out[1].Deleted)
+	require.Nil(t, out[1].BlobEnc)
+
+	// a rollback since took the version away
+	mock.ExpectQuery(`SELECT DISTINCT ON \(item_id\)`).
+		WithArgs(userID, tenant.Default, int64(40), id1, 100).
+		WillReturnRows(pgxmock.NewRows(cols).
+			AddRow(int64(14), id2, int64(5), false, false, ts, []byte(nil), int16(0), int32(0)))
+	_, err = r.GetSnapshot(ctx, userID, 40, id1, 100)
+	require.ErrorIs(t, err, errs.ErrSnapshotGone)
+	require.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestItemRepo_GetItem_OK_And_NotFound(t *testing.T) {
@@ -216,23 +451,51 @@ func TestItemRepo_GetItem_OK_And_NotFound(t *testing.T) {
 	ts := time.Now().UTC()
 
 	// OK
-	mock.ExpectQuery(`SELECT id, user_id, blob_enc, ver, deleted, updated_at FROM items WHERE user_id=\$1 AND id=\$2`).
-		WithArgs(userID, itemID).
-		WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "blob_enc", "ver", "deleted", "updated_at"}).
-			AddRow(itemID, userID, []byte("enc"), int64(10), false, ts))
+	mock.ExpectQuery(`SELECT id, user_id, blob_enc, ver, deleted, updated_at, size_class, schema_version FROM items WHERE user_id=\$1 AND id=\$2 AND tenant_id=\$3`).
+		WithArgs(userID, itemID, tenant.Default).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "blob_enc", "ver", "deleted", "updated_at", "size_class", "schema_version"}).
+			AddRow(itemID, userID, []byte("enc"), int64(10), false, ts, int16(model.SizeSmall), int32(3)))
 	it, err := r.GetItem(ctx, userID, itemID)
 	require.NoError(t, err)
 	require.Equal(t, itemID, it.ID)
 	require.Equal(t, int64(10), it.Ver)
+	require.Equal(t, model.ItemHints{SizeClass: model.SizeSmall, SchemaVersion: 3}, it.Hints)
 
 	// NotFound
-	mock.ExpectQuery(`SELECT id, user_id, blob_enc, ver, deleted, updated_at FROM items WHERE user_id=\$1 AND id=\$2`).
-		WithArgs(userID, itemID).
+	mock.ExpectQuery(`SELECT id, user_id, blob_enc, ver, deleted, updated_at, size_class, schema_version FROM items WHERE user_id=\$1 AND id=\$2 AND tenant_id=\$3`).
+		WithArgs(userID, itemID, tenant.Default).
 		WillReturnError(pgx.ErrNoRows)
 	_, err = r.GetItem(ctx, userID, itemID)
 	require.ErrorIs(t, err, errs.ErrNotFound)
 }
 
+func TestItemRepo_GetItemVersion_OK_And_NotFound(t *testing.T) {
+	db, mock := newDB(t)
+	defer mock.Close()
+	r := NewItemRepo(db)
+
+	ctx := context.Background()
+	userID := uuid.Must(uuid.NewV4())
+	itemID := uuid.Must(uuid.NewV4())
+	ts := time.Now().UTC()
+
+	mock.ExpectQuery(`SELECT item_id, user_id, blob_enc, ver, deleted, updated_at, size_class, schema_version FROM item_history WHERE user_id=\$1 AND item_id=\$2 AND ver=\$3 AND tenant_id=\$4`).
+		WithArgs(userID, itemID, int64(3), tenant.Default).
+		WillReturnRows(pgxmock.NewRows([]string{"item_id", "user_id", "blob_enc", "ver", "deleted", "updated_at", "size_class", "schema_version"}).
+			AddRow(itemID, userID, []byte("old"), int64(3), false, ts, int16(0), int32(0)))
+	it, err := r.GetItemVersion(ctx, userID, itemID, 3)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), it.Ver)
+	require.Equal(t, model.EncryptedBlob("old"), it.BlobEnc)
+
+	mock.ExpectQuery(`FROM item_history`).
+		WithArgs(userID, itemID, int64(99), tenant.Default).
+		WillReturnError(pgx.ErrNoRows)
+	_, err = r.GetItemVersion(ctx, userID, itemID, 99)
+	require.ErrorIs(t, err, errs.ErrNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestItemRepo_GetMaxVersion(t *testing.T) {
 	db, mock := newDB(t)
 	defer mock.Close()
@@ -241,8 +504,8 @@ func TestItemRepo_GetMaxVersion(t *testing.T) {
 	ctx := context.Background()
 	userID := uuid.Must(uuid.NewV4())
 
-	mock.ExpectQuery(`SELECT COALESCE\(MAX\(ver\),0\) FROM items WHERE user_id=\$1`).
-		WithArgs(userID).
+	mock.ExpectQuery(`SELECT COALESCE\(MAX\(ver\),0\) FROM items WHERE user_id=\$1 AND tenant_id=\$2`).
+		WithArgs(userID, tenant.Default).
 		WillReturnRows(pgxmock.NewRows([]string{"max"}).AddRow(int64(42)))
 
 	v, err := r.GetMaxVersion(ctx, userID)
@@ -250,6 +513,96 @@ func TestItemRepo_GetMaxVersion(t *testing.T) {
 	require.Equal(t, int64(42), v)
 }
 
+func TestItemRepo_Stats(t *testing.T) {
+	db, mock := newDB(t)
+	defer mock.Close()
+	r := NewItemRepo(db)
+
+	ctx := context.Background()
+	userID := uuid.Must(uuid.NewV4())
+	ts := time.Now().UTC()
+
+	mock.ExpectQuery(`SELECT count\(\*\),.*FROM items WHERE user_id=\$1 AND tenant_id=\$2`).
+		WithArgs(userID, tenant.Default).
+		WillReturnRows(pgxmock.NewRows([]string{"items", "deleted", "max", "bytes", "last"}).
+			AddRow(int64(5), int64(1), int64(9), int64(4096), &ts))
+	st, err := r.Stats(ctx, userID)
+	require.NoError(t, err)
+	require.Equal(t, model.ItemStats{Items: 5, Deleted: 1, MaxVer: 9, TotalBytes: 4096, LastUpdated: ts}, st)
+
+	// empty vault: MAX(updated_at) is NULL
+	mock.ExpectQuery(`FROM items WHERE user_id=\$1 AND tenant_id=\$2`).
+		WithArgs(userID, tenant.Default).
+		WillReturnRows(pgxmock.NewRows([]string{"items", "deleted", "max", "bytes", "last"}).
+			AddRow(int64(0), int64(0), int64(0), int64(0), (*time.Time)(nil)))
+	st, err = r.Stats(ctx, userID)
+	require.NoError(t, err)
+	require.True(t, st.LastUpdated.IsZero())
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestItemRepo_Digests(t *testing.T) {
+	db, mock := newDB(t)
+	defer mock.Close()
+	r := NewItemRepo(db)
+
+	userID := uuid.Must(uuid.NewV4())
+	a, b := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	h := make([]byte, 32)
+
+	mock.ExpectQuery(`SELECT id, ver, deleted, CASE WHEN deleted THEN NULL ELSE sha256\(blob_enc\) END\s+FROM items WHERE user_id=\$1 AND tenant_id=\$2`).
+		WithArgs(userID, tenant.Default).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "ver", "deleted", "hash"}).
+			AddRow(a, int64(2), false, h).
+			AddRow(b, int64(5), true, []byte(nil)))
+	got, err := r.Digests(context.Background(), userID)
+	require.NoError(t, err)
+	require.Equal(t, []model.ItemDigest{{ID: a, Ver: 2, BlobHash: h}, {ID: b, Ver: 5, Deleted: true}}, got)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestItemRepo_ExportItems(t *testing.T) {
+	db, mock := newDB(t)
+	defer mock.Close()
+	r := NewItemRepo(db)
+	ctx := context.Background()
+	userID := uuid.Must(uuid.NewV4())
+	cols := []string{"id", "user_id", "blob_enc", "ver", "deleted", "updated_at", "size_class", "schema_version"}
+	const q = `SELECT id, user_id, blob_enc, ver, deleted, updated_at, size_class, schema_version FROM items WHERE user_id=\$1 AND tenant_id=\$2 AND id > \$3 ORDER BY id LIMIT \$4`
+	ro := pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}
+
+	// a full page asks for the next one after its last id
+	full := pgxmock.NewRows(cols)
+	var last uuid.UUID
+	for i := range exportPage {
+		last = uuid.Must(uuid.FromBytes([]byte{0, 0, 0, 0, 0, 0, 0x40, 0, 0x80, 0, 0, 0, 0, 0, 0, byte(i + 1)}))
+		full.AddRow(last, userID, []byte("enc"), int64(1), false, rowTime, int16(0), int32(0))
+	}
+	tail := uuid.Must(uuid.NewV4())
+	mock.ExpectBeginTx(ro)
+	mock.ExpectQuery(q).WithArgs(userID, tenant.Default, uuid.Nil, exportPage).WillReturnRows(full)
+	mock.ExpectQuery(q).WithArgs(userID, tenant.Default, last, exportPage).
+		WillReturnRows(pgxmock.NewRows(cols).AddRow(tail, userID, []byte(nil), int64(3), true, rowTime, int16(0), int32(0)))
+	mock.ExpectRollback()
+
+	var got []model.Item
+	require.NoError(t, r.ExportItems(ctx, userID, func(it model.Item) error {
+		got = append(got, it)
+		return nil
+	}))
+	require.Len(t, got, exportPage+1)
+	require.Equal(t, model.Item{ID: tail, UserID: userID, Ver: 3, Deleted: true, UpdatedAt: rowTime}, got[exportPage])
+
+	// fn's error ends the export
+	stop := errors.New("client gone")
+	mock.ExpectBeginTx(ro)
+	mock.ExpectQuery(q).WithArgs(userID, tenant.Default, uuid.Nil, exportPage).
+		WillReturnRows(pgxmock.NewRows(cols).AddRow(tail, userID, []byte("enc"), int64(1), false, rowTime, int16(0), int32(0)))
+	mock.ExpectRollback()
+	require.ErrorIs(t, r.ExportItems(ctx, userID, func(model.Item) error { return stop }), stop)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestItemRepo_UpsertBatch_TxBeginErr(t *testing.T) {
 	db, mock := newDB(t)
 	defer mock.Close()
@@ -269,11 +622,11 @@ func TestItemRepo_UpsertBatch_Update_ExecErr(t *testing.T) {
 	uid := uuid.Must(uuid.NewV4())
 	iid := uuid.Must(uuid.NewV4())
 
-	mock.ExpectBegin()
-	mock.ExpectQuery(`SELECT ver FROM items WHERE id=\$1 AND user_id=\$2 FOR UPDATE`).
-		WithArgs(iid, uid).WillReturnRows(pgxmock.NewRows([]string{"ver"}).AddRow(int64(1)))
-	mock.ExpectExec(`UPDATE items SET blob_enc=\$3, ver=\$4, deleted=false WHERE id=\$1 AND user_id=\$2`).
-		WithArgs(iid, uid, []byte("enc"), int64(2)).WillReturnError(errors.New("exec-fail"))
+	expectBegin(mock)
+	mock.ExpectQuery(`SELECT ver, updated_at FROM items WHERE id=\$1 AND user_id=\$2 AND tenant_id=\$3 FOR UPDATE`).
+		WithArgs(iid, uid, tenant.Default).WillReturnRows(lockedRow(int64(1)))
+	mock.ExpectExec(`UPDATE items SET blob_enc=\$3, ver=\$4, deleted=false, size_class=\$6, schema_version=\$7 WHERE id=\$1 AND user_id=\$2 AND tenant_id=\$5`).
+		WithArgs(iid, uid, []byte("enc"), int64(2), tenant.Default, int16(0), int32(0)).WillReturnError(errors.New("exec-fail"))
 	mock.ExpectRollback()
 
 	_, err := r.UpsertBatch(ctx, uid, []model.UpsertItem{{ID: iid, BaseVer: 1, BlobEnc: model.EncryptedBlob("enc")}})
@@ -288,11 +641,11 @@ func TestItemRepo_UpsertBatch_Insert_ExecErr(t *testing.T) {
 	uid := uuid.Must(uuid.NewV4())
 	iid := uuid.Must(uuid.NewV4())
 
-	mock.ExpectBegin()
-	mock.ExpectQuery(`SELECT ver FROM items WHERE id=\$1 AND user_id=\$2 FOR UPDATE`).
-		WithArgs(iid, uid).WillReturnError(pgx.ErrNoRows)
-	mock.ExpectExec(`INSERT INTO items \(id, user_id, blob_enc, ver, deleted\) VALUES`).
-		WithArgs(iid, uid, []byte("enc"), int64(1)).WillReturnError(errors.New("insert-fail"))
+	expectBegin(mock)
+	mock.ExpectQuery(`SELECT ver, updated_at FROM items WHERE id=\$1 AND user_id=\$2 AND tenant_id=\$3 FOR UPDATE`).
+		WithArgs(iid, uid, tenant.Default).WillReturnError(pgx.ErrNoRows)
+	mock.ExpectExec(`INSERT INTO items \(id, user_id, blob_enc, ver, deleted, tenant_id, size_class, schema_version\) VALUES`).
+		WithArgs(iid, uid, []byte("enc"), int64(1), tenant.Default, int16(0), int32(0)).WillReturnError(errors.New("insert-fail"))
 	mock.ExpectRollback()
 
 	_, err := r.UpsertBatch(ctx, uid, []model.UpsertItem{{ID: iid, BaseVer: 0, BlobEnc: model.EncryptedBlob("enc")}})
@@ -307,9 +660,9 @@ func TestItemRepo_UpsertBatch_ScanOtherErr(t *testing.T) {
 	uid := uuid.Must(uuid.NewV4())
 	iid := uuid.Must(uuid.NewV4())
 
-	mock.ExpectBegin()
-	mock.ExpectQuery(`SELECT ver FROM items WHERE id=\$1 AND user_id=\$2 FOR UPDATE`).
-		WithArgs(iid, uid).WillReturnError(errors.New("weird-scan"))
+	expectBegin(mock)
+	mock.ExpectQuery(`SELECT ver, updated_at FROM items WHERE id=\$1 AND user_id=\$2 AND tenant_id=\$3 FOR UPDATE`).
+		WithArgs(iid, uid, tenant.Default).WillReturnError(errors.New("weird-scan"))
 	mock.ExpectRollback()
 
 	_, err := r.UpsertBatch(ctx, uid, []model.UpsertItem{{ID: iid, BaseVer: 0, BlobEnc: model.EncryptedBlob("x")}})
@@ -324,22 +677,74 @@ func TestItemRepo_UpsertBatch_MultipleItems_StopOnFirstErr(t *testing.T) {
 	uid := uuid.Must(uuid.NewV4())
 	i1, i2 := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
 
-	mock.ExpectBegin()
-
-	mock.ExpectQuery(`SELECT ver FROM items WHERE id=\$1 AND user_id=\$2 FOR UPDATE`).
-		WithArgs(i1, uid).WillReturnRows(pgxmock.NewRows([]string{"ver"}).AddRow(int64(2)))
-	mock.ExpectExec(`UPDATE items SET blob_enc=\$3, ver=\$4, deleted=false WHERE id=\$1 AND user_id=\$2`).
-		WithArgs(i1, uid, []byte("a"), int64(3)).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
-
-	mock.ExpectQuery(`SELECT ver FROM items WHERE id=\$1 AND user_id=\$2 FOR UPDATE`).
-		WithArgs(i2, uid).WillReturnRows(pgxmock.NewRows([]string{"ver"}).AddRow(int64(5)))
+	expectBegin(mock)
+	mock.ExpectQuery(lockItemsQ).WithArgs(uid, tenant.Default, []uuid.UUID{i1, i2}).
+		WillReturnRows(lockedRows(map[uuid.UUID]int64{i1: 2, i2: 5}))
 	mock.ExpectRollback()
 
 	_, err := r.UpsertBatch(ctx, uid, []model.UpsertItem{
 		{ID: i1, BaseVer: 2, BlobEnc: model.EncryptedBlob("a")},
 		{ID: i2, BaseVer: 1, BlobEnc: model.EncryptedBlob("b")},
 	})
-	require.ErrorIs(t, err, errs.ErrVersionConflict)
+	var ce *errs.ConflictError
+	require.ErrorAs(t, err, &ce)
+	require.Equal(t, errs.ConflictError{Index: 1, ID: i2, Ver: 5, UpdatedAt: rowTime}, *ce)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestItemRepo_UpsertBatch_UpdatesAndInserts(t *testing.T) {
+	db, mock := newDB(t)
+	defer mock.Close()
+	r := NewItemRepo(db)
+	uid := uuid.Must(uuid.NewV4())
+	a, b, c := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+
+	// however many items, one lock, one UPDATE and one INSERT
+	expectBegin(mock)
+	mock.ExpectQuery(lockItemsQ).WithArgs(uid, tenant.Default, []uuid.UUID{a, b, c}).
+		WillReturnRows(lockedRows(map[uuid.UUID]int64{a: 4, c: 1}))
+	mock.ExpectExec(`UPDATE items i SET`).
+		WithArgs(uid, tenant.Default, []uuid.UUID{a, c}, [][]byte{[]byte("a"), []byte("c")}, []int16{0, 0}, []int32{0, 0}).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 2))
+	mock.ExpectExec(`INSERT INTO items`).
+		WithArgs(uid, tenant.Default, []uuid.UUID{b}, [][]byte{[]byte("b")}, []int16{0}, []int32{0}).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
+
+	res, err := r.UpsertBatch(context.Background(), uid, []model.UpsertItem{
+		{ID: a, BaseVer: 4, BlobEnc: model.EncryptedBlob("a")},
+		{ID: b, BaseVer: 0, BlobEnc: model.EncryptedBlob("b")},
+		{ID: c, BaseVer: 1, BlobEnc: model.EncryptedBlob("c")},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []model.ItemVersion{{ID: a, NewVer: 5}, {ID: b, NewVer: 1}, {ID: c, NewVer: 2}}, res)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestItemRepo_UpsertBatch_RepeatedID(t *testing.T) {
+	db, mock := newDB(t)
+	defer mock.Close()
+	r := NewItemRepo(db)
+	uid, id := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	const sel = `SELECT ver, updated_at FROM items WHERE id=\$1 AND user_id=\$2 AND tenant_id=\$3 FOR UPDATE`
+
+	// the second write sees the first, so each version is stored
+	expectBegin(mock)
+	mock.ExpectQuery(sel).WithArgs(id, uid, tenant.Default).WillReturnError(pgx.ErrNoRows)
+	mock.ExpectExec(`INSERT INTO items`).
+		WithArgs(id, uid, []byte("1"), int64(1), tenant.Default, int16(0), int32(0)).WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectQuery(sel).WithArgs(id, uid, tenant.Default).WillReturnRows(lockedRow(1))
+	mock.ExpectExec(`UPDATE items SET blob_enc`).
+		WithArgs(id, uid, []byte("2"), int64(2), tenant.Default, int16(0), int32(0)).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectCommit()
+
+	res, err := r.UpsertBatch(context.Background(), uid, []model.UpsertItem{
+		{ID: id, BaseVer: 0, BlobEnc: model.EncryptedBlob("1")},
+		{ID: id, BaseVer: 1, BlobEnc: model.EncryptedBlob("2")},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []model.ItemVersion{{ID: id, NewVer: 1}, {ID: id, NewVer: 2}}, res)
+	require.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestItemRepo_Delete_CommitErr(t *testing.T) {
@@ -350,11 +755,11 @@ func TestItemRepo_Delete_CommitErr(t *testing.T) {
 	uid := uuid.Must(uuid.NewV4())
 	iid := uuid.Must(uuid.NewV4())
 
-	mock.ExpectBegin()
-	mock.ExpectQuery(`SELECT ver FROM items WHERE id=\$1 AND user_id=\$2 FOR UPDATE`).
-		WithArgs(iid, uid).WillReturnRows(pgxmock.NewRows([]string{"ver"}).AddRow(int64(1)))
-	mock.ExpectExec(`UPDATE items SET deleted=true, ver=\$3 WHERE id=\$1 AND user_id=\$2`).
-		WithArgs(iid, uid, int64(2)).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	expectBegin(mock)
+	mock.ExpectQuery(`SELECT ver, updated_at FROM items WHERE id=\$1 AND user_id=\$2 AND tenant_id=\$3 FOR UPDATE`).
+		WithArgs(iid, uid, tenant.Default).WillReturnRows(lockedRow(int64(1)))
+	mock.ExpectExec(`UPDATE items SET deleted=true, ver=\$3 WHERE id=\$1 AND user_id=\$2 AND tenant_id=\$4`).
+		WithArgs(iid, uid, int64(2), tenant.Default).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 	mock.ExpectCommit().WillReturnError(errors.New("commit-fail"))
 
 	_, err := r.Delete(ctx, uid, iid, 1)
@@ -369,11 +774,11 @@ func TestItemRepo_Delete_ExecErr(t *testing.T) {
 	uid := uuid.Must(uuid.NewV4())
 	iid := uuid.Must(uuid.NewV4())
 
-	mock.ExpectBegin()
-	mock.ExpectQuery(`SELECT ver FROM items WHERE id=\$1 AND user_id=\$2 FOR UPDATE`).
-		WithArgs(iid, uid).WillReturnRows(pgxmock.NewRows([]string{"ver"}).AddRow(int64(1)))
-	mock.ExpectExec(`UPDATE items SET deleted=true, ver=\$3 WHERE id=\$1 AND user_id=\$2`).
-		WithArgs(iid, uid, int64(2)).WillReturnError(errors.New("upd-fail"))
+	expectBegin(mock)
+	mock.ExpectQuery(`SELECT ver, updated_at FROM items WHERE id=\$1 AND user_id=\$2 AND tenant_id=\$3 FOR UPDATE`).
+		WithArgs(iid, uid, tenant.Default).WillReturnRows(lockedRow(int64(1)))
+	mock.ExpectExec(`UPDATE items SET deleted=true, ver=\$3 WHERE id=\$1 AND user_id=\$2 AND tenant_id=\$4`).
+		WithArgs(iid, uid, int64(2), tenant.Default).WillReturnError(errors.New("upd-fail"))
 	mock.ExpectRollback()
 
 	_, err := r.Delete(ctx, uid, iid, 1)
@@ -387,10 +792,10 @@ func TestItemRepo_GetChangesSince_QueryErr(t *testing.T) {
 	ctx := context.Background()
 	uid := uuid.Must(uuid.NewV4())
 
-	mock.ExpectQuery(`SELECT id, ver, deleted, updated_at, blob_enc FROM items WHERE user_id=\$1 AND ver>\$2 ORDER BY ver ASC`).
-		WithArgs(uid, int64(0)).WillReturnError(errors.New("q-fail"))
+	mock.ExpectQuery(`SELECT id, ver, deleted, updated_at, blob_enc, size_class, schema_version FROM items WHERE user_id=\$1 AND tenant_id=\$3 AND \(ver, id\) > \(\$2, \$4\) ORDER BY ver, id LIMIT NULLIF\(\$5, 0\)`).
+		WithArgs(uid, int64(0), tenant.Default, uuid.Max, 0).WillReturnError(errors.New("q-fail"))
 
-	_, err := r.GetChangesSince(ctx, uid, 0)
+	_, err := r.GetChangesSince(ctx, uid, model.AfterVer(0), 0)
 	require.Error(t, err)
 }
 
@@ -403,10 +808,10 @@ func TestItemRepo_GetChangesSince_RowScanErrAndRowsErr(t *testing.T) {
 
 	rows := pgxmock.NewRows([]string{"id", "ver", "deleted", "updated_at", "blob_enc"}).
 		RowError(0, errors.New("row0"))
-	mock.ExpectQuery(`SELECT id, ver, deleted, updated_at, blob_enc FROM items WHERE user_id=\$1 AND ver>\$2 ORDER BY ver ASC`).
-		WithArgs(uid, int64(0)).WillReturnRows(rows)
+	mock.ExpectQuery(`SELECT id, ver, deleted, updated_at, blob_enc, size_class, schema_version FROM items WHERE user_id=\$1 AND tenant_id=\$3 AND \(ver, id\) > \(\$2, \$4\) ORDER BY ver, id LIMIT NULLIF\(\$5, 0\)`).
+		WithArgs(uid, int64(0), tenant.Default, uuid.Max, 0).WillReturnRows(rows)
 
-	_, err := r.GetChangesSince(ctx, uid, 0)
+	_, err := r.GetChangesSince(ctx, uid, model.AfterVer(0), 0)
 	require.Error(t, err)
 }
 
@@ -418,8 +823,43 @@ func TestItemRepo_GetItem_QueryOtherErr(t *testing.T) {
 	uid := uuid.Must(uuid.NewV4())
 	iid := uuid.Must(uuid.NewV4())
 
-	mock.ExpectQuery(`SELECT id, user_id, blob_enc, ver, deleted, updated_at FROM items WHERE user_id=\$1 AND id=\$2`).
-		WithArgs(uid, iid).WillReturnError(errors.New("weird"))
+	mock.ExpectQuery(`SELECT id, user_id, blob_enc, ver, deleted, updated_at, size_class, schema_version FROM items WHERE user_id=\$1 AND id=\$2 AND tenant_id=\$3`).
+		WithArgs(uid, iid, tenant.Default).WillReturnError(errors.New("weird"))
 	_, err := r.GetItem(ctx, uid, iid)
 	require.Error(t, err)
 }
+
+func TestItemRepo_UpsertBatch_TenantQuota(t *testing.T) {
+	db, mock := newDB(t)
+	defer mock.Close()
+	reg, err := tenant.New("", []tenant.Tenant{{ID: "acme", Quota: tenant.Quota{MaxItems: 10, MaxBytes: 100}}})
+	require.NoError(t, err)
+	db.Tenants = reg
+	r := NewItemRepo(db)
+	ctx := tenant.WithID(context.Background(), "acme")
+	uid := uuid.Must(uuid.NewV4())
+	iid := uuid.Must(uuid.NewV4())
+	ups := []model.UpsertItem{{ID: iid, BlobEnc: model.EncryptedBlob("enc")}}
+
+	expectInsert := func() {
+		expectBegin(mock)
+		mock.ExpectQuery(lockItemsQ).WithArgs(uid, "acme", []uuid.UUID{iid}).WillReturnRows(lockedRows(nil))
+		mock.ExpectExec(`INSERT INTO items`).
+			WithArgs(uid, "acme", []uuid.UUID{iid}, [][]byte{[]byte("enc")}, []int16{0}, []int32{0}).WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	}
+
+	expectInsert()
+	mock.ExpectQuery(`SELECT count\(\*\), COALESCE\(SUM\(octet_length\(blob_enc\)\),0\) FROM items WHERE tenant_id=\$1 AND NOT deleted`).
+		WithArgs("acme").WillReturnRows(pgxmock.NewRows([]string{"count", "sum"}).AddRow(int64(3), int64(101)))
+	mock.ExpectRollback()
+	_, err = r.UpsertBatch(ctx, uid, ups)
+	require.ErrorIs(t, err, errs.ErrQuotaExceeded)
+
+	expectInsert()
+	mock.ExpectQuery(`FROM items WHERE tenant_id=\$1 AND NOT deleted`).
+		WithArgs("acme").WillReturnRows(pgxmock.NewRows([]string{"count", "sum"}).AddRow(int64(10), int64(100)))
+	mock.ExpectCommit()
+	_, err = r.UpsertBatch(ctx, uid, ups)
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
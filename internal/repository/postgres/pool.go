@@ -4,10 +4,15 @@ package postgres
 import (
 	"context"
 	"errors"
+	"sync"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	"github.com/and161185/goph-keeper/internal/tenant"
 )
 
 // PgxPool is a minimal abstraction over a Postgres connection pool,
@@ -26,9 +31,27 @@ type PgxPool interface {
 }
 
 // DB wraps pgxpool.Pool to satisfy repository constructors and allow testing.
-type DB struct{ Pool PgxPool }
+type DB struct {
+	Pool PgxPool
+	// Timeout bounds each repository call, all statements of a transaction
+	// together; zero leaves only the caller's deadline.
+	Timeout time.Duration
+	// Tenants supplies per-tenant quotas; nil means no quotas.
+	Tenants *tenant.Registry
+}
+
+// withTimeout derives the context for one repository call.
+func (db *DB) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if db.Timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, db.Timeout)
+}
 
-// New creates a new connection pool for the given DSN.
+// New creates a new connection pool for the given DSN. Unless the DSN sets
+// default_query_exec_mode, pgx prepares each statement text once per
+// connection and caches it, so queries keep their text fixed and pass
+// variable-length input as array parameters.
 func New(ctx context.Context, dsn string) (*DB, error) {
 	pool, err := pgxpool.New(ctx, dsn)
 	if err != nil {
@@ -40,6 +63,91 @@ func New(ctx context.Context, dsn string) (*DB, error) {
 // Close closes the underlying pool.
 func (db *DB) Close() { db.Pool.Close() }
 
+// Warm opens connections until pool holds at least n, all at once, and
+// returns them to the pool idle. pgxpool only fills MinConns in the
+// background, so without it the first requests after startup dial.
+func Warm(ctx context.Context, pool *pgxpool.Pool, n int32) error {
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		conns []*pgxpool.Conn
+		fails []error
+	)
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c, err := pool.Acquire(ctx)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				fails = append(fails, err)
+				return
+			}
+			conns = append(conns, c)
+		}()
+	}
+	wg.Wait()
+	for _, c := range conns {
+		c.Release()
+	}
+	return errors.Join(fails...)
+}
+
+// KeepAlive pings the pool's idle connections every period until ctx is
+// done, closing those that fail, and then tops the pool back up to
+// MinConns. A connection the database or a middlebox dropped while idle is
+// thereby replaced before a request draws it. Each connection is returned
+// as soon as its ping answers.
+func KeepAlive(ctx context.Context, pool *pgxpool.Pool, period time.Duration, logger *zap.Logger) {
+	t := time.NewTicker(period)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+		broken := pingIdle(ctx, pool, period)
+		if broken == 0 || ctx.Err() != nil {
+			continue
+		}
+		logger.Warn("closed broken database connections", zap.Int("count", broken))
+		wctx, cancel := context.WithTimeout(ctx, period)
+		if err := Warm(wctx, pool, pool.Config().MinConns); err != nil {
+			logger.Warn("replace database connections", zap.Error(err))
+		}
+		cancel()
+	}
+}
+
+// pingIdle pings every idle connection of pool, each bounded by timeout,
+// and closes those that fail; it returns how many did.
+func pingIdle(ctx context.Context, pool *pgxpool.Pool, timeout time.Duration) int {
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		broken int
+	)
+	for _, c := range pool.AcquireAllIdle(ctx) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer c.Release() // a closed connection is destroyed, not pooled
+			pctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			if err := c.Ping(pctx); err != nil && ctx.Err() == nil {
+				_ = c.Conn().Close(ctx)
+				mu.Lock()
+				broken++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return broken
+}
+
 // isUniqueViolation reports whether the error is a unique constraint violation.
 func isUniqueViolation(err error) bool {
 	var pg *pgconn.PgError
@@ -0,0 +1,61 @@
+package postgres
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// deadPool is a pool whose server accepts connections and drops them.
+func deadPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = l.Close() })
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			_ = c.Close()
+		}
+	}()
+	cfg, err := pgxpool.ParseConfig("postgres://gk@" + l.Addr().String() + "/gk?sslmode=disable")
+	require.NoError(t, err)
+	pool, err := pgxpool.NewWithConfig(context.Background(), cfg)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+func TestWarm_ReportsFailure(t *testing.T) {
+	pool := deadPool(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.Error(t, Warm(ctx, pool, 3))
+	require.Zero(t, pool.Stat().TotalConns())
+	require.NoError(t, Warm(ctx, pool, 0))
+}
+
+func TestKeepAlive_StopsWithContext(t *testing.T) {
+	pool := deadPool(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		KeepAlive(ctx, pool, 5*time.Millisecond, zap.NewNop())
+		close(done)
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("KeepAlive did not return")
+	}
+}
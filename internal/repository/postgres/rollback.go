@@ -0,0 +1,164 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/and161185/goph-keeper/internal/tenant"
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+)
+
+// rollbackPlan is one item's state now and in history.
+type rollbackPlan struct {
+	id    uuid.UUID
+	ver   int64
+	at    *int64 // newest version at or before the target time
+	first *int64 // oldest recorded version
+}
+
+// RollbackUser resets every item of userID to its state at `to`, using
+// item_history. Items return to the version they had then (blobs are bound
+// to their version and cannot be re-sealed here); items created later are
+// removed; the superseded history rows move to item_history_discarded under
+// a user_rollbacks record. Items whose history starts after `to` with a
+// version above 1 predate history tracking and are left alone. A dry run
+// computes the same counts and rolls everything back. Under a legal hold,
+// a rollback that would discard history inside the hold window fails with
+// errs.ErrLegalHold.
+func (r *ItemRepo) RollbackUser(
+	ctx context.Context, userID uuid.UUID, to time.Time, by uuid.UUID, dryRun bool,
+) (res model.RollbackResult, err error) {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.db.Pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return res, err
+	}
+	defer func() {
+		if err != nil || dryRun {
+			_ = tx.Rollback(ctx)
+			return
+		}
+		if e := tx.Commit(ctx); e != nil {
+			err = e
+		}
+	}()
+
+	// before the user row lock: writers hold the journal lock while
+	// inserting items, which needs a share lock on the user row
+	if err = lockChanges(ctx, tx, tenant.FromContext(ctx), userID); err != nil {
+		return res, err
+	}
+	// the user row lock serialises rollbacks of the same user
+	var one int
+	if err = tx.QueryRow(ctx, `SELECT 1 FROM users WHERE id=$1 FOR UPDATE`, userID).Scan(&one); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return res, errs.ErrNotFound
+		}
+		return res, err
+	}
+	if r.hold > 0 {
+		// every history row after `to` is discarded; none may be in the window
+		const held = `
+SELECT EXISTS (SELECT 1 FROM item_history
+  WHERE user_id=$1 AND updated_at>$2 AND updated_at>now()-make_interval(secs => $3))`
+		var found bool
+		if err = tx.QueryRow(ctx, held, userID, to, r.hold.Seconds()).Scan(&found); err != nil {
+			return res, err
+		}
+		if found {
+			return res, fmt.Errorf("%w: history newer than %s would be discarded", errs.ErrLegalHold, r.hold)
+		}
+	}
+
+	const plan = `
+SELECT i.id, i.ver,
+       (SELECT max(h.ver) FROM item_history h WHERE h.item_id=i.id AND h.updated_at<=$2),
+       (SELECT min(h.ver) FROM item_history h WHERE h.item_id=i.id)
+FROM items i WHERE i.user_id=$1
+ORDER BY i.id
+FOR UPDATE OF i`
+	rows, err := tx.Query(ctx, plan, userID, to)
+	if err != nil {
+		return res, err
+	}
+	var items []rollbackPlan
+	for rows.Next() {
+		var p rollbackPlan
+		if err = rows.Scan(&p.id, &p.ver, &p.at, &p.first); err != nil {
+			rows.Close()
+			return res, err
+		}
+		items = append(items, p)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return res, err
+	}
+
+	const audit = `
+INSERT INTO user_rollbacks (user_id, to_time, performed_by, restored, removed, discarded, skipped)
+VALUES ($1,$2,$3,0,0,0,0) RETURNING id`
+	var id int64
+	if err = tx.QueryRow(ctx, audit, userID, to, by).Scan(&id); err != nil {
+		return res, err
+	}
+
+	const discard = `
+WITH moved AS (
+  DELETE FROM item_history WHERE item_id=$2 AND ver>$3
+  RETURNING item_id, user_id, tenant_id, ver, blob_enc, deleted, updated_at, size_class, schema_version
+)
+INSERT INTO item_history_discarded (rollback_id, item_id, user_id, tenant_id, ver, blob_enc, deleted, updated_at, size_class, schema_version)
+SELECT $1, item_id, user_id, tenant_id, ver, blob_enc, deleted, updated_at, size_class, schema_version FROM moved`
+	const restore = `
+UPDATE items SET (ver, blob_enc, deleted, size_class, schema_version) =
+  (SELECT ver, blob_enc, deleted, size_class, schema_version FROM item_history WHERE item_id=$1 AND ver=$2)
+WHERE id=$1 AND user_id=$3`
+	const remove = `DELETE FROM items WHERE id=$1 AND user_id=$2`
+
+	for _, p := range items {
+		var keep int64
+		switch {
+		case p.at != nil && *p.at == p.ver:
+			continue
+		case p.at != nil:
+			keep = *p.at
+		case p.first != nil && *p.first == 1:
+			keep = 0
+		default:
+			res.Skipped++
+			continue
+		}
+		tag, e := tx.Exec(ctx, discard, id, p.id, keep)
+		if err = e; err != nil {
+			return res, err
+		}
+		res.Discarded += tag.RowsAffected()
+		if keep > 0 {
+			_, err = tx.Exec(ctx, restore, p.id, keep, userID)
+			res.Restored++
+		} else {
+			_, err = tx.Exec(ctx, remove, p.id, userID)
+			res.Removed++
+		}
+		if err != nil {
+			return res, err
+		}
+	}
+
+	const counts = `UPDATE user_rollbacks SET restored=$2, removed=$3, discarded=$4, skipped=$5 WHERE id=$1`
+	if _, err = tx.Exec(ctx, counts, id, res.Restored, res.Removed, res.Discarded, res.Skipped); err != nil {
+		return res, err
+	}
+	if !dryRun {
+		res.ID = id
+	}
+	return res, nil
+}
@@ -0,0 +1,128 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+	pgxmock "github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func i64(v int64) *int64 { return &v }
+
+func TestItemRepo_RollbackUser(t *testing.T) {
+	db, mock := newDB(t)
+	defer mock.Close()
+	r := NewItemRepo(db)
+
+	ctx := context.Background()
+	user, admin := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	edited, created, same, legacy := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	to := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	expectBegin(mock)
+	mock.ExpectQuery(`SELECT 1 FROM users WHERE id=\$1 FOR UPDATE`).WithArgs(user).
+		WillReturnRows(pgxmock.NewRows([]string{"one"}).AddRow(1))
+	mock.ExpectQuery(`FROM items i WHERE i.user_id=\$1`).WithArgs(user, to).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "ver", "at", "first"}).
+			AddRow(edited, int64(7), i64(3), i64(1)). // corrupted after `to`
+			AddRow(created, int64(2), nil, i64(1)).   // did not exist yet
+			AddRow(same, int64(4), i64(4), i64(1)).   // untouched since
+			AddRow(legacy, int64(9), nil, i64(8)))    // history starts after `to`
+	mock.ExpectQuery(`INSERT INTO user_rollbacks`).WithArgs(user, to, admin).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(int64(42)))
+	mock.ExpectExec(`DELETE FROM item_history WHERE item_id=\$2 AND ver>\$3`).WithArgs(int64(42), edited, int64(3)).
+		WillReturnResult(pgxmock.NewResult("INSERT", 4))
+	mock.ExpectExec(`UPDATE items SET \(ver, blob_enc, deleted, size_class, schema_version\)`).WithArgs(edited, int64(3), user).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectExec(`DELETE FROM item_history WHERE item_id=\$2 AND ver>\$3`).WithArgs(int64(42), created, int64(0)).
+		WillReturnResult(pgxmock.NewResult("INSERT", 2))
+	mock.ExpectExec(`DELETE FROM items WHERE id=\$1 AND user_id=\$2`).WithArgs(created, user).
+		WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	mock.ExpectExec(`UPDATE user_rollbacks SET`).WithArgs(int64(42), int64(1), int64(1), int64(6), int64(1)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectCommit()
+
+	res, err := r.RollbackUser(ctx, user, to, admin, false)
+	require.NoError(t, err)
+	require.Equal(t, int64(42), res.ID)
+	require.Equal(t, int64(1), res.Restored)
+	require.Equal(t, int64(1), res.Removed)
+	require.Equal(t, int64(6), res.Discarded)
+	require.Equal(t, int64(1), res.Skipped)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestItemRepo_RollbackUser_DryRunAndUnknownUser(t *testing.T) {
+	db, mock := newDB(t)
+	defer mock.Close()
+	r := NewItemRepo(db)
+	ctx := context.Background()
+	user := uuid.Must(uuid.NewV4())
+	to := time.Now()
+
+	expectBegin(mock)
+	mock.ExpectQuery(`SELECT 1 FROM users`).WithArgs(user).WillReturnError(pgx.ErrNoRows)
+	mock.ExpectRollback()
+	_, err := r.RollbackUser(ctx, user, to, uuid.Nil, false)
+	require.ErrorIs(t, err, errs.ErrNotFound)
+
+	expectBegin(mock)
+	mock.ExpectQuery(`SELECT 1 FROM users`).WithArgs(user).
+		WillReturnRows(pgxmock.NewRows([]string{"one"}).AddRow(1))
+	mock.ExpectQuery(`FROM items i`).WithArgs(user, to).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "ver", "at", "first"}).AddRow(uuid.Must(uuid.NewV4()), int64(2), i64(1), i64(1)))
+	mock.ExpectQuery(`INSERT INTO user_rollbacks`).WithArgs(user, to, uuid.Nil).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(int64(1)))
+	mock.ExpectExec(`DELETE FROM item_history`).WithArgs(int64(1), pgxmock.AnyArg(), int64(1)).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectExec(`UPDATE items`).WithArgs(pgxmock.AnyArg(), int64(1), pgxmock.AnyArg()).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectExec(`UPDATE user_rollbacks`).WithArgs(int64(1), int64(1), int64(0), int64(1), int64(0)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectRollback()
+	res, err := r.RollbackUser(ctx, user, to, uuid.Nil, true)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), res.ID)
+	require.Equal(t, int64(1), res.Restored)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestItemRepo_RollbackUser_LegalHold(t *testing.T) {
+	db, mock := newDB(t)
+	defer mock.Close()
+	r := NewItemRepo(db)
+	r.SetLegalHold(90 * 24 * time.Hour)
+	ctx := context.Background()
+	user := uuid.Must(uuid.NewV4())
+	to := time.Now().Add(-time.Hour)
+
+	expectBegin(mock)
+	mock.ExpectQuery(`SELECT 1 FROM users`).WithArgs(user).
+		WillReturnRows(pgxmock.NewRows([]string{"one"}).AddRow(1))
+	mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM item_history`).WithArgs(user, to, float64(90*24*3600)).
+		WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectRollback()
+	_, err := r.RollbackUser(ctx, user, to, uuid.Nil, false)
+	require.ErrorIs(t, err, errs.ErrLegalHold)
+
+	// nothing after `to` is inside the window: the rollback goes ahead
+	expectBegin(mock)
+	mock.ExpectQuery(`SELECT 1 FROM users`).WithArgs(user).
+		WillReturnRows(pgxmock.NewRows([]string{"one"}).AddRow(1))
+	mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM item_history`).WithArgs(user, to, float64(90*24*3600)).
+		WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectQuery(`FROM items i`).WithArgs(user, to).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "ver", "at", "first"}))
+	mock.ExpectQuery(`INSERT INTO user_rollbacks`).WithArgs(user, to, uuid.Nil).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(int64(1)))
+	mock.ExpectExec(`UPDATE user_rollbacks`).WithArgs(int64(1), int64(0), int64(0), int64(0), int64(0)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectCommit()
+	_, err = r.RollbackUser(ctx, user, to, uuid.Nil, false)
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/and161185/goph-keeper/internal/tenant"
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+)
+
+// SessionRepo implements SessionRepository using PostgreSQL.
+type SessionRepo struct{ db *DB }
+
+// NewSessionRepo constructs a session repository.
+func NewSessionRepo(db *DB) *SessionRepo { return &SessionRepo{db: db} }
+
+// Create inserts s, pruning the user's expired and surplus sessions in the
+// same statement.
+func (r *SessionRepo) Create(ctx context.Context, s *model.Session, tokenHash []byte, expires time.Time, keep int) error {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
+	const q = `
+WITH pruned AS (
+  DELETE FROM sessions WHERE user_id=$2 AND (expires_at <= now() OR id IN (
+    SELECT id FROM sessions WHERE user_id=$2 AND expires_at > now()
+    ORDER BY last_used_at DESC, id OFFSET $7))
+)
+INSERT INTO sessions (id, user_id, tenant_id, token_hash, label, expires_at, scopes)
+VALUES ($1, $2, $3, $4, $5, $6, COALESCE($8::text[], '{}'))
+RETURNING created_at, last_used_at`
+	err := r.db.Pool.QueryRow(ctx, q, s.ID, s.UserID, tenant.FromContext(ctx), tokenHash, s.Label, expires, max(keep-1, 0), s.Scopes).
+		Scan(&s.CreatedAt, &s.LastUsedAt)
+	if err != nil {
+		return err
+	}
+	s.ExpiresAt = expires
+	return nil
+}
+
+// Rotate swaps the token hash of a live session in one statement, so two
+// refreshes with the same token cannot both succeed.
+func (r *SessionRepo) Rotate(ctx context.Context, oldHash, newHash []byte, expires time.Time) (model.Session, error) {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
+	const q = `
+UPDATE sessions SET token_hash=$2, last_used_at=now(), expires_at=$3
+WHERE token_hash=$1 AND tenant_id=$4 AND expires_at > now()
+RETURNING id, user_id, label, created_at, last_used_at, expires_at, scopes`
+	var s model.Session
+	err := r.db.Pool.QueryRow(ctx, q, oldHash, newHash, expires, tenant.FromContext(ctx)).
+		Scan(&s.ID, &s.UserID, &s.Label, &s.CreatedAt, &s.LastUsedAt, &s.ExpiresAt, &s.Scopes)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return model.Session{}, errs.ErrNotFound
+	}
+	return s, err
+}
+
+// Owner selects the user of the live session holding tokenHash.
+func (r *SessionRepo) Owner(ctx context.Context, tokenHash []byte) (uuid.UUID, error) {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
+	const q = `SELECT user_id FROM sessions WHERE token_hash=$1 AND tenant_id=$2 AND expires_at > now()`
+	var id uuid.UUID
+	err := r.db.Pool.QueryRow(ctx, q, tokenHash, tenant.FromContext(ctx)).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return uuid.Nil, errs.ErrNotFound
+	}
+	return id, err
+}
+
+// List selects the user's live sessions, most recently used first.
+func (r *SessionRepo) List(ctx context.Context, userID uuid.UUID) ([]model.Session, error) {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
+	const q = `
+SELECT id, label, created_at, last_used_at, expires_at, scopes
+FROM sessions WHERE user_id=$1 AND tenant_id=$2 AND expires_at > now()
+ORDER BY last_used_at DESC, id`
+	rows, err := r.db.Pool.Query(ctx, q, userID, tenant.FromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []model.Session
+	for rows.Next() {
+		s := model.Session{UserID: userID}
+		if err := rows.Scan(&s.ID, &s.Label, &s.CreatedAt, &s.LastUsedAt, &s.ExpiresAt, &s.Scopes); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+// Delete removes one of the user's sessions.
+func (r *SessionRepo) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
+	tag, err := r.db.Pool.Exec(ctx, `DELETE FROM sessions WHERE id=$1 AND user_id=$2 AND tenant_id=$3`,
+		id, userID, tenant.FromContext(ctx))
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errs.ErrNotFound
+	}
+	return nil
+}
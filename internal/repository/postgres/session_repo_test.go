@@ -0,0 +1,59 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/and161185/goph-keeper/internal/tenant"
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+	pgxmock "github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionRepo(t *testing.T) {
+	db, mock := newDB(t)
+	defer mock.Close()
+	r := NewSessionRepo(db)
+	ctx := tenant.WithID(context.Background(), "acme")
+	user, id := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	now := time.Now().UTC()
+	exp := now.Add(24 * time.Hour)
+
+	mock.ExpectQuery(`WITH pruned AS \(\s+DELETE FROM sessions .* OFFSET \$7\)\)\s+\)\s+INSERT INTO sessions`).
+		WithArgs(id, user, "acme", []byte("h1"), "laptop", exp, 9, []string{"items:read"}).
+		WillReturnRows(pgxmock.NewRows([]string{"created_at", "last_used_at"}).AddRow(now, now))
+	s := model.Session{ID: id, UserID: user, Label: "laptop", Scopes: []string{"items:read"}}
+	require.NoError(t, r.Create(ctx, &s, []byte("h1"), exp, 10))
+	require.Equal(t, model.Session{ID: id, UserID: user, Label: "laptop", Scopes: []string{"items:read"}, CreatedAt: now, LastUsedAt: now, ExpiresAt: exp}, s)
+
+	cols := []string{"id", "user_id", "label", "created_at", "last_used_at", "expires_at", "scopes"}
+	mock.ExpectQuery(`UPDATE sessions SET token_hash=\$2, last_used_at=now\(\), expires_at=\$3\s+WHERE token_hash=\$1 AND tenant_id=\$4 AND expires_at > now\(\)`).
+		WithArgs([]byte("h1"), []byte("h2"), exp, "acme").
+		WillReturnRows(pgxmock.NewRows(cols).AddRow(id, user, "laptop", now, now, exp, []string{"items:read"}))
+	got, err := r.Rotate(ctx, []byte("h1"), []byte("h2"), exp)
+	require.NoError(t, err)
+	require.Equal(t, s, got)
+
+	mock.ExpectQuery(`UPDATE sessions`).WithArgs([]byte("h1"), []byte("h3"), exp, "acme").WillReturnError(pgx.ErrNoRows)
+	_, err = r.Rotate(ctx, []byte("h1"), []byte("h3"), exp)
+	require.ErrorIs(t, err, errs.ErrNotFound)
+
+	mock.ExpectQuery(`FROM sessions WHERE user_id=\$1 AND tenant_id=\$2 AND expires_at > now\(\)\s+ORDER BY last_used_at DESC`).
+		WithArgs(user, "acme").
+		WillReturnRows(pgxmock.NewRows([]string{"id", "label", "created_at", "last_used_at", "expires_at", "scopes"}).
+			AddRow(id, "laptop", now, now, exp, []string{"items:read"}))
+	list, err := r.List(ctx, user)
+	require.NoError(t, err)
+	require.Equal(t, []model.Session{s}, list)
+
+	mock.ExpectExec(`DELETE FROM sessions WHERE id=\$1 AND user_id=\$2 AND tenant_id=\$3`).WithArgs(id, user, "acme").
+		WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	require.NoError(t, r.Delete(ctx, user, id))
+	mock.ExpectExec(`DELETE FROM sessions`).WithArgs(id, user, "acme").WillReturnResult(pgxmock.NewResult("DELETE", 0))
+	require.ErrorIs(t, r.Delete(ctx, user, id), errs.ErrNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// SlowQueryTracer is a pgx.QueryTracer that logs statements running at least
+// Threshold. Arguments are redacted to their type and size: they carry
+// usernames, password hashes and encrypted blobs.
+type SlowQueryTracer struct {
+	Logger    *zap.Logger
+	Threshold time.Duration
+}
+
+type traceKey struct{}
+
+type traceStart struct {
+	at   time.Time
+	sql  string
+	args []any
+}
+
+// TraceQueryStart implements pgx.QueryTracer.
+func (t *SlowQueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, traceKey{}, traceStart{at: time.Now(), sql: data.SQL, args: data.Args})
+}
+
+// TraceQueryEnd implements pgx.QueryTracer.
+func (t *SlowQueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	st, ok := ctx.Value(traceKey{}).(traceStart)
+	if !ok {
+		return
+	}
+	elapsed := time.Since(st.at)
+	if elapsed < t.Threshold {
+		return
+	}
+	fields := []zap.Field{
+		zap.Duration("elapsed", elapsed),
+		zap.String("sql", compactSQL(st.sql)),
+		zap.Strings("args", RedactArgs(st.args)),
+		zap.String("tag", data.CommandTag.String()),
+	}
+	if data.Err != nil {
+		fields = append(fields, zap.Error(data.Err))
+	}
+	t.Logger.Warn("slow query", fields...)
+}
+
+// RedactArgs describes query arguments without their values.
+func RedactArgs(args []any) []string {
+	out := make([]string, len(args))
+	for i, a := range args {
+		switch v := a.(type) {
+		case nil:
+			out[i] = "nil"
+		case string:
+			out[i] = fmt.Sprintf("string(%d)", len(v))
+		case []byte:
+			out[i] = fmt.Sprintf("[]byte(%d)", len(v))
+		default:
+			out[i] = fmt.Sprintf("%T", a)
+		}
+	}
+	return out
+}
+
+// compactSQL collapses whitespace so multi-line queries log on one line.
+func compactSQL(sql string) string {
+	return strings.Join(strings.Fields(sql), " ")
+}
@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	pgxmock "github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/and161185/goph-keeper/internal/tenant"
+)
+
+func TestSlowQueryTracer_LogsOnlySlowQueriesRedacted(t *testing.T) {
+	core, logs := observer.New(zapcore.WarnLevel)
+	tr := &SlowQueryTracer{Logger: zap.New(core), Threshold: 20 * time.Millisecond}
+
+	run := func(d time.Duration) {
+		ctx := tr.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{
+			SQL:  "SELECT *\n  FROM users\n WHERE username=$1",
+			Args: []any{"alice", []byte("pwdhash"), int64(7)},
+		})
+		time.Sleep(d)
+		tr.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("SELECT 1")})
+	}
+	run(0)
+	require.Equal(t, 0, logs.Len(), "fast query must not be logged")
+
+	run(30 * time.Millisecond)
+	require.Equal(t, 1, logs.Len())
+	fields := logs.All()[0].ContextMap()
+	require.Equal(t, "SELECT * FROM users WHERE username=$1", fields["sql"])
+	require.Equal(t, []any{"string(5)", "[]byte(7)", "int64"}, fields["args"])
+	require.NotContains(t, strings.Join([]string{logs.All()[0].Message, fields["sql"].(string)}, " "), "alice")
+}
+
+func TestDB_TimeoutBoundsRepositoryCalls(t *testing.T) {
+	db, mock := newDB(t)
+	defer mock.Close()
+	db.Timeout = 20 * time.Millisecond
+	r := NewItemRepo(db)
+
+	uid := uuid.Must(uuid.NewV4())
+	mock.ExpectQuery(`SELECT COALESCE\(MAX\(ver\),0\)`).
+		WithArgs(uid, tenant.Default).
+		WillReturnRows(pgxmock.NewRows([]string{"max"}).AddRow(int64(1))).
+		WillDelayFor(time.Second)
+
+	start := time.Now()
+	_, err := r.GetMaxVersion(context.Background(), uid)
+	require.Error(t, err)
+	require.True(t, errors.Is(err, context.DeadlineExceeded) || strings.Contains(err.Error(), "cancel"), err)
+	require.Less(t, time.Since(start), 500*time.Millisecond)
+}
@@ -0,0 +1,144 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/and161185/goph-keeper/internal/model"
+)
+
+// UsageRepo implements UsageRepository using PostgreSQL.
+type UsageRepo struct{ db *DB }
+
+// NewUsageRepo constructs a usage rollup repository.
+func NewUsageRepo(db *DB) *UsageRepo { return &UsageRepo{db: db} }
+
+// RollupUsage aggregates items, item_history and login_history per user.
+// It reads every row of them, so it runs without the per-call timeout and
+// lifts statement_timeout for its transaction.
+func (r *UsageRepo) RollupUsage(ctx context.Context, day time.Time, keep int) (n int64, err error) {
+	tx, err := r.db.Pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+			return
+		}
+		if e := tx.Commit(ctx); e != nil {
+			err = e
+		}
+	}()
+
+	if _, err = tx.Exec(ctx, `SET LOCAL statement_timeout = 0`); err != nil {
+		return 0, err
+	}
+	const q = `
+INSERT INTO user_usage (user_id, day, tenant_id, items, tombstones, bytes, history_bytes, last_write, last_login, rolled_up_at)
+SELECT u.id, $1::date, u.tenant_id,
+       COALESCE(i.items, 0), COALESCE(i.tombstones, 0), COALESCE(i.bytes, 0), COALESCE(h.bytes, 0),
+       i.last_write, l.last_login, now()
+FROM users u
+LEFT JOIN (SELECT user_id, count(*) FILTER (WHERE NOT deleted) AS items, count(*) FILTER (WHERE deleted) AS tombstones,
+                  sum(octet_length(blob_enc)) AS bytes, max(updated_at) AS last_write
+           FROM items GROUP BY user_id) i ON i.user_id = u.id
+LEFT JOIN (SELECT user_id, sum(octet_length(blob_enc)) AS bytes FROM item_history GROUP BY user_id) h ON h.user_id = u.id
+LEFT JOIN (SELECT user_id, max(at) AS last_login FROM login_history WHERE success GROUP BY user_id) l ON l.user_id = u.id
+ON CONFLICT (user_id, day) DO UPDATE SET
+  tenant_id=EXCLUDED.tenant_id, items=EXCLUDED.items, tombstones=EXCLUDED.tombstones, bytes=EXCLUDED.bytes,
+  history_bytes=EXCLUDED.history_bytes, last_write=EXCLUDED.last_write, last_login=EXCLUDED.last_login,
+  rolled_up_at=EXCLUDED.rolled_up_at`
+	tag, err := tx.Exec(ctx, q, day)
+	if err != nil {
+		return 0, err
+	}
+	if _, err = tx.Exec(ctx, `DELETE FROM user_usage WHERE day < $1::date - $2::int`, day, keep); err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// UsageReport reads the newest day and the users' earlier days in one
+// repeatable-read transaction, so a rollup committing meanwhile is not half
+// seen.
+func (r *UsageRepo) UsageReport(ctx context.Context, tenant string, limit, days int) (rep model.UsageReport, err error) {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.db.Pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return rep, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var day time.Time
+	err = tx.QueryRow(ctx, `SELECT day, max(rolled_up_at) FROM user_usage GROUP BY day ORDER BY day DESC LIMIT 1`).Scan(&day, &rep.AsOf)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return rep, nil
+	}
+	if err != nil {
+		return rep, err
+	}
+
+	const q = `
+SELECT user_id, tenant_id, items, tombstones, bytes, history_bytes, last_write, last_login FROM user_usage
+WHERE day=$1 AND ($2 = '' OR tenant_id=$2) ORDER BY bytes DESC, user_id LIMIT $3`
+	rows, err := tx.Query(ctx, q, day, tenant, limit)
+	if err != nil {
+		return rep, err
+	}
+	index := map[uuid.UUID]int{}
+	for rows.Next() {
+		var (
+			u                    model.UserUsage
+			lastWrite, lastLogin *time.Time
+		)
+		if err = rows.Scan(&u.UserID, &u.Tenant, &u.Items, &u.Tombstones, &u.Bytes, &u.HistoryBytes, &lastWrite, &lastLogin); err != nil {
+			rows.Close()
+			return rep, err
+		}
+		if lastWrite != nil {
+			u.LastWrite = *lastWrite
+		}
+		if lastLogin != nil {
+			u.LastLogin = *lastLogin
+		}
+		index[u.UserID] = len(rep.Users)
+		rep.Users = append(rep.Users, u)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil || days <= 0 || len(rep.Users) == 0 {
+		return rep, err
+	}
+
+	ids := make([]uuid.UUID, len(rep.Users))
+	for i, u := range rep.Users {
+		ids[i] = u.UserID
+	}
+	const hq = `
+SELECT user_id, day, items, bytes, history_bytes FROM user_usage
+WHERE user_id = ANY($1) AND day > $2::date - $3::int AND day <= $2 ORDER BY user_id, day`
+	rows, err = tx.Query(ctx, hq, ids, day, days)
+	if err != nil {
+		return rep, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			id uuid.UUID
+			s  model.UsageSample
+		)
+		if err = rows.Scan(&id, &s.Day, &s.Items, &s.Bytes, &s.HistoryBytes); err != nil {
+			return rep, err
+		}
+		if i, ok := index[id]; ok {
+			rep.Users[i].History = append(rep.Users[i].History, s)
+		}
+	}
+	return rep, rows.Err()
+}
@@ -0,0 +1,80 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+	pgxmock "github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageRepo_RollupUsage(t *testing.T) {
+	db, mock := newDB(t)
+	defer mock.Close()
+	r := NewUsageRepo(db)
+	day := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL statement_timeout = 0`).WillReturnResult(pgxmock.NewResult("SET", 0))
+	mock.ExpectExec(`INSERT INTO user_usage .* FROM users u LEFT JOIN .* ON CONFLICT \(user_id, day\) DO UPDATE`).
+		WithArgs(day).WillReturnResult(pgxmock.NewResult("INSERT", 3))
+	mock.ExpectExec(`DELETE FROM user_usage WHERE day < \$1::date - \$2::int`).WithArgs(day, 400).
+		WillReturnResult(pgxmock.NewResult("DELETE", 3))
+	mock.ExpectCommit()
+
+	n, err := r.RollupUsage(context.Background(), day, 400)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, n)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUsageRepo_UsageReport(t *testing.T) {
+	db, mock := newDB(t)
+	defer mock.Close()
+	r := NewUsageRepo(db)
+	ctx := context.Background()
+	big, idle := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	day := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	at := day.Add(3 * time.Hour)
+	ro := pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}
+
+	mock.ExpectBeginTx(ro)
+	mock.ExpectQuery(`SELECT day, max\(rolled_up_at\) FROM user_usage`).
+		WillReturnRows(pgxmock.NewRows([]string{"day", "max"}).AddRow(day, at))
+	mock.ExpectQuery(`FROM user_usage WHERE day=\$1 AND \(\$2 = '' OR tenant_id=\$2\) ORDER BY bytes DESC`).WithArgs(day, "acme", 10).
+		WillReturnRows(pgxmock.NewRows([]string{"user_id", "tenant_id", "items", "tombstones", "bytes", "history_bytes", "last_write", "last_login"}).
+			AddRow(big, "acme", int64(40), int64(2), int64(9000), int64(30000), &at, &at).
+			AddRow(idle, "acme", int64(0), int64(0), int64(0), int64(0), (*time.Time)(nil), (*time.Time)(nil)))
+	mock.ExpectQuery(`FROM user_usage WHERE user_id = ANY\(\$1\)`).WithArgs([]uuid.UUID{big, idle}, day, 7).
+		WillReturnRows(pgxmock.NewRows([]string{"user_id", "day", "items", "bytes", "history_bytes"}).
+			AddRow(big, day.AddDate(0, 0, -1), int64(30), int64(7000), int64(20000)).
+			AddRow(big, day, int64(40), int64(9000), int64(30000)).
+			AddRow(idle, day, int64(0), int64(0), int64(0)))
+	mock.ExpectRollback()
+
+	rep, err := r.UsageReport(ctx, "acme", 10, 7)
+	require.NoError(t, err)
+	require.Equal(t, model.UsageReport{AsOf: at, Users: []model.UserUsage{
+		{
+			UserID: big, Tenant: "acme", Items: 40, Tombstones: 2, Bytes: 9000, HistoryBytes: 30000, LastWrite: at, LastLogin: at,
+			History: []model.UsageSample{
+				{Day: day.AddDate(0, 0, -1), Items: 30, Bytes: 7000, HistoryBytes: 20000},
+				{Day: day, Items: 40, Bytes: 9000, HistoryBytes: 30000},
+			},
+		},
+		{UserID: idle, Tenant: "acme", History: []model.UsageSample{{Day: day}}},
+	}}, rep)
+
+	// nothing rolled up yet
+	mock.ExpectBeginTx(ro)
+	mock.ExpectQuery(`SELECT day, max\(rolled_up_at\) FROM user_usage`).WillReturnError(pgx.ErrNoRows)
+	mock.ExpectRollback()
+	rep, err = r.UsageReport(ctx, "", 10, 7)
+	require.NoError(t, err)
+	require.Equal(t, model.UsageReport{}, rep)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -3,10 +3,14 @@ package postgres
 import (
 	"context"
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/and161185/goph-keeper/internal/errs"
 	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/and161185/goph-keeper/internal/tenant"
 	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
 )
 
 // UserRepo implements UserRepository using PostgreSQL.
@@ -17,22 +21,40 @@ func NewUserRepo(db *DB) *UserRepo { return &UserRepo{db: db} }
 
 // Create inserts a new user row.
 func (r *UserRepo) Create(ctx context.Context, u *model.User) error {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
+	tid := tenant.FromContext(ctx)
+	if limit := r.db.Tenants.Quota(tid).MaxUsers; limit > 0 {
+		// soft limit: concurrent registrations may overshoot it slightly
+		var n int64
+		if err := r.db.Pool.QueryRow(ctx, `SELECT count(*) FROM users WHERE tenant_id=$1`, tid).Scan(&n); err != nil {
+			return err
+		}
+		if n >= limit {
+			return fmt.Errorf("%w: tenant %s has %d users", errs.ErrQuotaExceeded, tid, n)
+		}
+	}
+
 	const q = `
-INSERT INTO users (id, username, pwd_hash, salt_auth, kek_salt, wrapped_dek)
-VALUES ($1, $2, $3, $4, $5, $6)`
-	_, err := r.db.Pool.Exec(ctx, q, u.ID, u.Username, u.PwdHash, u.SaltAuth, u.KekSalt, u.WrappedDEK)
+INSERT INTO users (id, username, pwd_hash, salt_auth, kek_salt, wrapped_dek, tenant_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	_, err := r.db.Pool.Exec(ctx, q, u.ID, u.Username, u.PwdHash, u.SaltAuth, u.KekSalt, u.WrappedDEK, tid)
 	if isUniqueViolation(err) {
-		return errs.ErrVersionConflict // or define ErrAlreadyExists if нужно
+		return errs.ErrAlreadyExists
 	}
 	return err
 }
 
 // GetByID selects a user by ID.
 func (r *UserRepo) GetByID(ctx context.Context, id uuid.UUID) (*model.User, error) {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
 	const q = `
 SELECT id, username, pwd_hash, salt_auth, kek_salt, wrapped_dek, created_at
-FROM users WHERE id=$1`
-	row := r.db.Pool.QueryRow(ctx, q, id)
+FROM users WHERE id=$1 AND tenant_id=$2`
+	row := r.db.Pool.QueryRow(ctx, q, id, tenant.FromContext(ctx))
 	var u model.User
 	if err := row.Scan(&u.ID, &u.Username, &u.PwdHash, &u.SaltAuth, &u.KekSalt, &u.WrappedDEK, &u.CreatedAt); err != nil {
 		if errors.Is(err, context.Canceled) {
@@ -45,10 +67,13 @@ FROM users WHERE id=$1`
 
 // GetByUsername selects a user by username.
 func (r *UserRepo) GetByUsername(ctx context.Context, username string) (*model.User, error) {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
 	const q = `
 SELECT id, username, pwd_hash, salt_auth, kek_salt, wrapped_dek, created_at
-FROM users WHERE username=$1`
-	row := r.db.Pool.QueryRow(ctx, q, username)
+FROM users WHERE username=$1 AND tenant_id=$2`
+	row := r.db.Pool.QueryRow(ctx, q, username, tenant.FromContext(ctx))
 	var u model.User
 	if err := row.Scan(&u.ID, &u.Username, &u.PwdHash, &u.SaltAuth, &u.KekSalt, &u.WrappedDEK, &u.CreatedAt); err != nil {
 		if errors.Is(err, context.Canceled) {
@@ -61,11 +86,34 @@ FROM users WHERE username=$1`
 
 // SetWrappedDEKIfEmpty updates wrapped_dek only if currently empty.
 func (r *UserRepo) SetWrappedDEKIfEmpty(ctx context.Context, id uuid.UUID, wrapped []byte) error {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
 	const q = `
 UPDATE users
 SET wrapped_dek = $2
-WHERE id = $1 AND octet_length(wrapped_dek) = 0`
-	tag, err := r.db.Pool.Exec(ctx, q, id, wrapped)
+WHERE id = $1 AND tenant_id = $3 AND octet_length(wrapped_dek) = 0`
+	tag, err := r.db.Pool.Exec(ctx, q, id, wrapped, tenant.FromContext(ctx))
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errs.ErrVersionConflict
+	}
+	return nil
+}
+
+// ReplaceWrappedDEK updates wrapped_dek only if it still equals prev, so two
+// clients re-wrapping at once cannot silently overwrite each other.
+func (r *UserRepo) ReplaceWrappedDEK(ctx context.Context, id uuid.UUID, prev, wrapped []byte) error {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
+	const q = `
+UPDATE users
+SET wrapped_dek = $3
+WHERE id = $1 AND tenant_id = $4 AND wrapped_dek = $2`
+	tag, err := r.db.Pool.Exec(ctx, q, id, prev, wrapped, tenant.FromContext(ctx))
 	if err != nil {
 		return err
 	}
@@ -74,3 +122,226 @@ WHERE id = $1 AND octet_length(wrapped_dek) = 0`
 	}
 	return nil
 }
+
+// RecordLogin inserts a login attempt and trims the user's history to keep rows.
+func (r *UserRepo) RecordLogin(ctx context.Context, id uuid.UUID, a model.LoginAttempt, keep int) error {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
+	tid := tenant.FromContext(ctx)
+	const ins = `
+INSERT INTO login_history (user_id, tenant_id, at, ip_hash, success, country, lat, lon)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	var lat, lon *float64
+	if a.Where.HasCoords {
+		lat, lon = &a.Where.Lat, &a.Where.Lon
+	}
+	if _, err := r.db.Pool.Exec(ctx, ins, id, tid, a.At, a.IPHash, a.Success, a.Where.Country, lat, lon); err != nil {
+		return err
+	}
+	const trim = `
+DELETE FROM login_history
+WHERE user_id=$1 AND tenant_id=$2 AND id <= (
+  SELECT id FROM login_history WHERE user_id=$1 AND tenant_id=$2 ORDER BY id DESC OFFSET $3 LIMIT 1)`
+	_, err := r.db.Pool.Exec(ctx, trim, id, tid, keep)
+	return err
+}
+
+// LoginHistory selects the user's newest login attempts.
+func (r *UserRepo) LoginHistory(ctx context.Context, id uuid.UUID, limit int) ([]model.LoginAttempt, error) {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
+	const q = `
+SELECT at, ip_hash, success, country, lat, lon FROM login_history
+WHERE user_id=$1 AND tenant_id=$2 ORDER BY id DESC LIMIT $3`
+	rows, err := r.db.Pool.Query(ctx, q, id, tenant.FromContext(ctx), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []model.LoginAttempt
+	for rows.Next() {
+		var (
+			a        model.LoginAttempt
+			lat, lon *float64
+		)
+		if err := rows.Scan(&a.At, &a.IPHash, &a.Success, &a.Where.Country, &lat, &lon); err != nil {
+			return nil, err
+		}
+		if lat != nil && lon != nil {
+			a.Where.Lat, a.Where.Lon, a.Where.HasCoords = *lat, *lon, true
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// CreateDuress inserts the vault's users row and links it to the user in
+// one statement, unless the user has a vault already.
+func (r *UserRepo) CreateDuress(ctx context.Context, id uuid.UUID, vault *model.User) error {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
+	const q = `
+WITH vault AS (
+  INSERT INTO users (id, username, pwd_hash, salt_auth, kek_salt, wrapped_dek, tenant_id)
+  SELECT $2, $3, $4, $5, $6, $7, $8
+  WHERE NOT EXISTS (SELECT 1 FROM user_duress WHERE user_id=$1)
+  RETURNING id
+)
+INSERT INTO user_duress (user_id, tenant_id, vault_id) SELECT $1, $8, id FROM vault`
+	tag, err := r.db.Pool.Exec(ctx, q, id, vault.ID, vault.Username, vault.PwdHash, vault.SaltAuth, vault.KekSalt,
+		vault.WrappedDEK, tenant.FromContext(ctx))
+	if isUniqueViolation(err) {
+		return errs.ErrAlreadyExists
+	}
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errs.ErrAlreadyExists
+	}
+	return nil
+}
+
+// GetDuress selects the users row of the user's duress vault.
+func (r *UserRepo) GetDuress(ctx context.Context, id uuid.UUID) (*model.User, error) {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
+	const q = `
+SELECT u.id, u.username, u.pwd_hash, u.salt_auth, u.kek_salt, u.wrapped_dek, u.created_at
+FROM user_duress d JOIN users u ON u.id = d.vault_id
+WHERE d.user_id=$1 AND d.tenant_id=$2`
+	var u model.User
+	err := r.db.Pool.QueryRow(ctx, q, id, tenant.FromContext(ctx)).
+		Scan(&u.ID, &u.Username, &u.PwdHash, &u.SaltAuth, &u.KekSalt, &u.WrappedDEK, &u.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errs.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// DeleteDuress deletes the vault's users row; the cascade takes its items,
+// sessions and the link with it.
+func (r *UserRepo) DeleteDuress(ctx context.Context, id uuid.UUID) error {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
+	const q = `
+DELETE FROM users WHERE id = (SELECT vault_id FROM user_duress WHERE user_id=$1 AND tenant_id=$2)`
+	tag, err := r.db.Pool.Exec(ctx, q, id, tenant.FromContext(ctx))
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errs.ErrNotFound
+	}
+	return nil
+}
+
+// Freeze sets frozen_at unless it is set and returns the stored value.
+func (r *UserRepo) Freeze(ctx context.Context, id uuid.UUID, at time.Time) (time.Time, error) {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
+	const q = `
+UPDATE users SET frozen_at = COALESCE(frozen_at, $2)
+WHERE id=$1 AND tenant_id=$3
+RETURNING frozen_at`
+	var frozen time.Time
+	err := r.db.Pool.QueryRow(ctx, q, id, at, tenant.FromContext(ctx)).Scan(&frozen)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return time.Time{}, errs.ErrNotFound
+	}
+	return frozen, err
+}
+
+// Unfreeze clears frozen_at.
+func (r *UserRepo) Unfreeze(ctx context.Context, id uuid.UUID) error {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
+	const q = `UPDATE users SET frozen_at = NULL WHERE id=$1 AND tenant_id=$2`
+	tag, err := r.db.Pool.Exec(ctx, q, id, tenant.FromContext(ctx))
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errs.ErrNotFound
+	}
+	return nil
+}
+
+// FrozenAt reads frozen_at; NULL is the zero time.
+func (r *UserRepo) FrozenAt(ctx context.Context, id uuid.UUID) (time.Time, error) {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
+	const q = `SELECT frozen_at FROM users WHERE id=$1 AND tenant_id=$2`
+	var frozen *time.Time
+	err := r.db.Pool.QueryRow(ctx, q, id, tenant.FromContext(ctx)).Scan(&frozen)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return time.Time{}, errs.ErrNotFound
+	}
+	if err != nil || frozen == nil {
+		return time.Time{}, err
+	}
+	return *frozen, nil
+}
+
+// SetDisabled updates the user and its duress vault and, when suspending,
+// deletes their sessions in the same transaction.
+func (r *UserRepo) SetDisabled(ctx context.Context, id uuid.UUID, disabled bool) (err error) {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.db.Pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+			return
+		}
+		if e := tx.Commit(ctx); e != nil {
+			err = e
+		}
+	}()
+
+	const q = `
+UPDATE users SET is_disabled=$2
+WHERE id=$1 OR id = (SELECT vault_id FROM user_duress WHERE user_id=$1)`
+	tag, err := tx.Exec(ctx, q, id, disabled)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errs.ErrNotFound
+	}
+	if disabled {
+		const del = `
+DELETE FROM sessions
+WHERE user_id=$1 OR user_id = (SELECT vault_id FROM user_duress WHERE user_id=$1)`
+		_, err = tx.Exec(ctx, del, id)
+	}
+	return err
+}
+
+// Disabled reads is_disabled.
+func (r *UserRepo) Disabled(ctx context.Context, id uuid.UUID) (bool, error) {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
+	var disabled bool
+	err := r.db.Pool.QueryRow(ctx, `SELECT is_disabled FROM users WHERE id=$1 AND tenant_id=$2`, id, tenant.FromContext(ctx)).Scan(&disabled)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, errs.ErrNotFound
+	}
+	return disabled, err
+}
@@ -3,9 +3,12 @@ package postgres
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/geoip"
 	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/and161185/goph-keeper/internal/tenant"
 	"github.com/gofrs/uuid/v5"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -28,17 +31,17 @@ func TestUserRepo_Create_OK_and_UniqueViolation(t *testing.T) {
 	}
 
 	// OK
-	mock.ExpectExec(`INSERT INTO users \(id, username, pwd_hash, salt_auth, kek_salt, wrapped_dek\) VALUES \(\$1, \$2, \$3, \$4, \$5, \$6\)`).
-		WithArgs(u.ID, u.Username, u.PwdHash, u.SaltAuth, u.KekSalt, u.WrappedDEK).
+	mock.ExpectExec(`INSERT INTO users \(id, username, pwd_hash, salt_auth, kek_salt, wrapped_dek, tenant_id\) VALUES \(\$1, \$2, \$3, \$4, \$5, \$6, \$7\)`).
+		WithArgs(u.ID, u.Username, u.PwdHash, u.SaltAuth, u.KekSalt, u.WrappedDEK, tenant.Default).
 		WillReturnResult(pgxmock.NewResult("INSERT", 1))
 	require.NoError(t, r.Create(ctx, u))
 
 	// Unique violation
-	mock.ExpectExec(`INSERT INTO users \(id, username, pwd_hash, salt_auth, kek_salt, wrapped_dek\) VALUES \(\$1, \$2, \$3, \$4, \$5, \$6\)`).
-		WithArgs(u.ID, u.Username, u.PwdHash, u.SaltAuth, u.KekSalt, u.WrappedDEK).
+	mock.ExpectExec(`INSERT INTO users \(id, username, pwd_hash, salt_auth, kek_salt, wrapped_dek, tenant_id\) VALUES \(\$1, \$2, \$3, \$4, \$5, \$6, \$7\)`).
+		WithArgs(u.ID, u.Username, u.PwdHash, u.SaltAuth, u.KekSalt, u.WrappedDEK, tenant.Default).
 		WillReturnError(&pgconn.PgError{Code: "23505"})
 	err := r.Create(ctx, u)
-	require.ErrorIs(t, err, errs.ErrVersionConflict)
+	require.ErrorIs(t, err, errs.ErrAlreadyExists)
 }
 
 func TestUserRepo_GetByID(t *testing.T) {
@@ -48,16 +51,16 @@ func TestUserRepo_GetByID(t *testing.T) {
 	ctx := context.Background()
 	id := uuid.Must(uuid.NewV4())
 
-	mock.ExpectQuery(`SELECT id, username, pwd_hash, salt_auth, kek_salt, wrapped_dek, created_at FROM users WHERE id=\$1`).
-		WithArgs(id).
+	mock.ExpectQuery(`SELECT id, username, pwd_hash, salt_auth, kek_salt, wrapped_dek, created_at FROM users WHERE id=\$1 AND tenant_id=\$2`).
+		WithArgs(id, tenant.Default).
 		WillReturnRows(pgxmock.NewRows([]string{"id", "username", "pwd_hash", "salt_auth", "kek_salt", "wrapped_dek", "created_at"}).
 			AddRow(id, "u", []byte("h"), []byte("s"), []byte("k"), []byte("w"), pgxmock.AnyArg()))
 	u, err := r.GetByID(ctx, id)
 	require.NoError(t, err)
 	require.Equal(t, id, u.ID)
 
-	mock.ExpectQuery(`SELECT id, username, pwd_hash, salt_auth, kek_salt, wrapped_dek, created_at FROM users WHERE id=\$1`).
-		WithArgs(id).
+	mock.ExpectQuery(`SELECT id, username, pwd_hash, salt_auth, kek_salt, wrapped_dek, created_at FROM users WHERE id=\$1 AND tenant_id=\$2`).
+		WithArgs(id, tenant.Default).
 		WillReturnError(pgx.ErrNoRows)
 	_, err = r.GetByID(ctx, id)
 	require.ErrorIs(t, err, errs.ErrNotFound)
@@ -71,16 +74,16 @@ func TestUserRepo_GetByUsername(t *testing.T) {
 	name := "u2"
 	id := uuid.Must(uuid.NewV4())
 
-	mock.ExpectQuery(`SELECT id, username, pwd_hash, salt_auth, kek_salt, wrapped_dek, created_at FROM users WHERE username=\$1`).
-		WithArgs(name).
+	mock.ExpectQuery(`SELECT id, username, pwd_hash, salt_auth, kek_salt, wrapped_dek, created_at FROM users WHERE username=\$1 AND tenant_id=\$2`).
+		WithArgs(name, tenant.Default).
 		WillReturnRows(pgxmock.NewRows([]string{"id", "username", "pwd_hash", "salt_auth", "kek_salt", "wrapped_dek", "created_at"}).
 			AddRow(id, name, []byte("h"), []byte("s"), []byte("k"), []byte("w"), pgxmock.AnyArg()))
 	u, err := r.GetByUsername(ctx, name)
 	require.NoError(t, err)
 	require.Equal(t, name, u.Username)
 
-	mock.ExpectQuery(`SELECT id, username, pwd_hash, salt_auth, kek_salt, wrapped_dek, created_at FROM users WHERE username=\$1`).
-		WithArgs(name).
+	mock.ExpectQuery(`SELECT id, username, pwd_hash, salt_auth, kek_salt, wrapped_dek, created_at FROM users WHERE username=\$1 AND tenant_id=\$2`).
+		WithArgs(name, tenant.Default).
 		WillReturnError(pgx.ErrNoRows)
 	_, err = r.GetByUsername(ctx, name)
 	require.ErrorIs(t, err, errs.ErrNotFound)
@@ -94,14 +97,210 @@ func TestUserRepo_SetWrappedDEKIfEmpty(t *testing.T) {
 	id := uuid.Must(uuid.NewV4())
 	w := []byte("wrapped")
 
-	mock.ExpectExec(`UPDATE users SET wrapped_dek = \$2 WHERE id = \$1 AND octet_length\(wrapped_dek\) = 0`).
-		WithArgs(id, w).
+	mock.ExpectExec(`UPDATE users SET wrapped_dek = \$2 WHERE id = \$1 AND tenant_id = \$3 AND octet_length\(wrapped_dek\) = 0`).
+		WithArgs(id, w, tenant.Default).
 		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 	require.NoError(t, r.SetWrappedDEKIfEmpty(ctx, id, w))
 
-	mock.ExpectExec(`UPDATE users SET wrapped_dek = \$2 WHERE id = \$1 AND octet_length\(wrapped_dek\) = 0`).
-		WithArgs(id, w).
+	mock.ExpectExec(`UPDATE users SET wrapped_dek = \$2 WHERE id = \$1 AND tenant_id = \$3 AND octet_length\(wrapped_dek\) = 0`).
+		WithArgs(id, w, tenant.Default).
 		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
 	err := r.SetWrappedDEKIfEmpty(ctx, id, w)
 	require.ErrorIs(t, err, errs.ErrVersionConflict)
 }
+
+func TestUserRepo_ReplaceWrappedDEK(t *testing.T) {
+	db, mock := newDB(t)
+	defer mock.Close()
+	r := NewUserRepo(db)
+	ctx := context.Background()
+	id := uuid.Must(uuid.NewV4())
+	prev, next := []byte("old"), []byte("new")
+
+	mock.ExpectExec(`UPDATE users SET wrapped_dek = \$3 WHERE id = \$1 AND tenant_id = \$4 AND wrapped_dek = \$2`).
+		WithArgs(id, prev, next, tenant.Default).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	require.NoError(t, r.ReplaceWrappedDEK(ctx, id, prev, next))
+
+	mock.ExpectExec(`UPDATE users SET wrapped_dek = \$3`).
+		WithArgs(id, prev, next, tenant.Default).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+	require.ErrorIs(t, r.ReplaceWrappedDEK(ctx, id, prev, next), errs.ErrVersionConflict)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepo_TenantScopeAndQuota(t *testing.T) {
+	db, mock := newDB(t)
+	defer mock.Close()
+	reg, err := tenant.New("", []tenant.Tenant{{ID: "acme", Quota: tenant.Quota{MaxUsers: 2}}})
+	require.NoError(t, err)
+	db.Tenants = reg
+	r := NewUserRepo(db)
+	ctx := tenant.WithID(context.Background(), "acme")
+	u := &model.User{ID: uuid.Must(uuid.NewV4()), Username: "u"}
+
+	mock.ExpectQuery(`SELECT count\(\*\) FROM users WHERE tenant_id=\$1`).
+		WithArgs("acme").
+		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(int64(2)))
+	require.ErrorIs(t, r.Create(ctx, u), errs.ErrQuotaExceeded)
+
+	mock.ExpectQuery(`SELECT count\(\*\) FROM users WHERE tenant_id=\$1`).
+		WithArgs("acme").
+		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(int64(1)))
+	mock.ExpectExec(`INSERT INTO users`).
+		WithArgs(u.ID, u.Username, u.PwdHash, u.SaltAuth, u.KekSalt, u.WrappedDEK, "acme").
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	require.NoError(t, r.Create(ctx, u))
+
+	// a user of another tenant is simply not found
+	mock.ExpectQuery(`FROM users WHERE username=\$1 AND tenant_id=\$2`).
+		WithArgs("u", "acme").
+		WillReturnError(pgx.ErrNoRows)
+	_, err = r.GetByUsername(ctx, "u")
+	require.ErrorIs(t, err, errs.ErrNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepo_RecordLogin(t *testing.T) {
+	db, mock := newDB(t)
+	defer mock.Close()
+	r := NewUserRepo(db)
+	id := uuid.Must(uuid.NewV4())
+	a := model.LoginAttempt{At: time.Now(), IPHash: []byte("h"), Success: true,
+		Where: geoip.Location{Country: "GB", Lat: 51.5, Lon: -0.13, HasCoords: true}}
+
+	mock.ExpectExec(`INSERT INTO login_history \(user_id, tenant_id, at, ip_hash, success, country, lat, lon\) VALUES \(\$1, .*, \$8\)`).
+		WithArgs(id, tenant.Default, a.At, a.IPHash, true, "GB", &a.Where.Lat, &a.Where.Lon).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectExec(`DELETE FROM login_history WHERE user_id=\$1 AND tenant_id=\$2 AND id <= \( SELECT id FROM login_history .* OFFSET \$3 LIMIT 1\)`).
+		WithArgs(id, tenant.Default, 100).
+		WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	require.NoError(t, r.RecordLogin(context.Background(), id, a, 100))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepo_LoginHistory(t *testing.T) {
+	db, mock := newDB(t)
+	defer mock.Close()
+	r := NewUserRepo(db)
+	id := uuid.Must(uuid.NewV4())
+	at := time.Now()
+
+	lat, lon := 59.3, 18.1
+	mock.ExpectQuery(`SELECT at, ip_hash, success, country, lat, lon FROM login_history WHERE user_id=\$1 AND tenant_id=\$2 ORDER BY id DESC LIMIT \$3`).
+		WithArgs(id, tenant.Default, 20).
+		WillReturnRows(pgxmock.NewRows([]string{"at", "ip_hash", "success", "country", "lat", "lon"}).
+			AddRow(at, []byte("h"), false, "", (*float64)(nil), (*float64)(nil)).
+			AddRow(at.Add(-time.Minute), []byte("h"), true, "SE", &lat, &lon))
+	got, err := r.LoginHistory(context.Background(), id, 20)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	require.False(t, got[0].Success)
+	require.Equal(t, geoip.Location{}, got[0].Where)
+	require.True(t, got[1].Success)
+	require.Equal(t, geoip.Location{Country: "SE", Lat: 59.3, Lon: 18.1, HasCoords: true}, got[1].Where)
+}
+
+func TestUserRepo_Duress(t *testing.T) {
+	db, mock := newDB(t)
+	defer mock.Close()
+	r := NewUserRepo(db)
+	ctx := context.Background()
+	id := uuid.Must(uuid.NewV4())
+	v := &model.User{ID: uuid.Must(uuid.NewV4()), Username: "duress\x1f" + id.String(), PwdHash: []byte("h"), SaltAuth: []byte("s"), KekSalt: []byte("k"), WrappedDEK: []byte{}}
+
+	mock.ExpectExec(`WITH vault AS \(\s+INSERT INTO users .+WHERE NOT EXISTS \(SELECT 1 FROM user_duress WHERE user_id=\$1\).+INSERT INTO user_duress`).
+		WithArgs(id, v.ID, v.Username, v.PwdHash, v.SaltAuth, v.KekSalt, v.WrappedDEK, tenant.Default).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	require.NoError(t, r.CreateDuress(ctx, id, v))
+	mock.ExpectExec(`WITH vault AS`).
+		WithArgs(id, v.ID, v.Username, v.PwdHash, v.SaltAuth, v.KekSalt, v.WrappedDEK, tenant.Default).
+		WillReturnResult(pgxmock.NewResult("INSERT", 0))
+	require.ErrorIs(t, r.CreateDuress(ctx, id, v), errs.ErrAlreadyExists)
+
+	mock.ExpectQuery(`FROM user_duress d JOIN users u ON u.id = d.vault_id\s+WHERE d.user_id=\$1 AND d.tenant_id=\$2`).
+		WithArgs(id, tenant.Default).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "username", "pwd_hash", "salt_auth", "kek_salt", "wrapped_dek", "created_at"}).
+			AddRow(v.ID, v.Username, v.PwdHash, v.SaltAuth, v.KekSalt, v.WrappedDEK, time.Now()))
+	got, err := r.GetDuress(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, v.ID, got.ID)
+	mock.ExpectQuery(`FROM user_duress`).WithArgs(id, tenant.Default).WillReturnError(pgx.ErrNoRows)
+	_, err = r.GetDuress(ctx, id)
+	require.ErrorIs(t, err, errs.ErrNotFound)
+
+	mock.ExpectExec(`DELETE FROM users WHERE id = \(SELECT vault_id FROM user_duress WHERE user_id=\$1 AND tenant_id=\$2\)`).
+		WithArgs(id, tenant.Default).
+		WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	require.NoError(t, r.DeleteDuress(ctx, id))
+	mock.ExpectExec(`DELETE FROM users`).WithArgs(id, tenant.Default).WillReturnResult(pgxmock.NewResult("DELETE", 0))
+	require.ErrorIs(t, r.DeleteDuress(ctx, id), errs.ErrNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepo_Freeze(t *testing.T) {
+	db, mock := newDB(t)
+	defer mock.Close()
+	r := NewUserRepo(db)
+	ctx := context.Background()
+	id := uuid.Must(uuid.NewV4())
+	at := time.Now().UTC()
+
+	mock.ExpectQuery(`UPDATE users SET frozen_at = COALESCE\(frozen_at, \$2\)\s+WHERE id=\$1 AND tenant_id=\$3\s+RETURNING frozen_at`).
+		WithArgs(id, at, tenant.Default).
+		WillReturnRows(pgxmock.NewRows([]string{"frozen_at"}).AddRow(at.Add(-time.Hour)))
+	got, err := r.Freeze(ctx, id, at)
+	require.NoError(t, err)
+	require.Equal(t, at.Add(-time.Hour), got)
+
+	mock.ExpectQuery(`SELECT frozen_at FROM users WHERE id=\$1 AND tenant_id=\$2`).WithArgs(id, tenant.Default).
+		WillReturnRows(pgxmock.NewRows([]string{"frozen_at"}).AddRow(nil))
+	got, err = r.FrozenAt(ctx, id)
+	require.NoError(t, err)
+	require.True(t, got.IsZero())
+	mock.ExpectQuery(`SELECT frozen_at FROM users`).WithArgs(id, tenant.Default).WillReturnError(pgx.ErrNoRows)
+	_, err = r.FrozenAt(ctx, id)
+	require.ErrorIs(t, err, errs.ErrNotFound)
+
+	mock.ExpectExec(`UPDATE users SET frozen_at = NULL WHERE id=\$1 AND tenant_id=\$2`).WithArgs(id, tenant.Default).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+	require.ErrorIs(t, r.Unfreeze(ctx, id), errs.ErrNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepo_Disabled(t *testing.T) {
+	db, mock := newDB(t)
+	defer mock.Close()
+	r := NewUserRepo(db)
+	ctx := context.Background()
+	id := uuid.Must(uuid.NewV4())
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE users SET is_disabled=\$2\s+WHERE id=\$1 OR id = \(SELECT vault_id FROM user_duress WHERE user_id=\$1\)`).
+		WithArgs(id, true).WillReturnResult(pgxmock.NewResult("UPDATE", 2))
+	mock.ExpectExec(`DELETE FROM sessions\s+WHERE user_id=\$1 OR user_id = \(SELECT vault_id`).WithArgs(id).
+		WillReturnResult(pgxmock.NewResult("DELETE", 3))
+	mock.ExpectCommit()
+	require.NoError(t, r.SetDisabled(ctx, id, true))
+
+	// reactivating leaves sessions alone
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE users SET is_disabled=\$2`).WithArgs(id, false).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectCommit()
+	require.NoError(t, r.SetDisabled(ctx, id, false))
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE users SET is_disabled=\$2`).WithArgs(id, true).WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+	mock.ExpectRollback()
+	require.ErrorIs(t, r.SetDisabled(ctx, id, true), errs.ErrNotFound)
+
+	mock.ExpectQuery(`SELECT is_disabled FROM users WHERE id=\$1 AND tenant_id=\$2`).WithArgs(id, tenant.Default).
+		WillReturnRows(pgxmock.NewRows([]string{"is_disabled"}).AddRow(true))
+	disabled, err := r.Disabled(ctx, id)
+	require.NoError(t, err)
+	require.True(t, disabled)
+	mock.ExpectQuery(`SELECT is_disabled FROM users`).WithArgs(id, tenant.Default).WillReturnError(pgx.ErrNoRows)
+	_, err = r.Disabled(ctx, id)
+	require.ErrorIs(t, err, errs.ErrNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
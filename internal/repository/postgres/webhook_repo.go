@@ -0,0 +1,78 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/and161185/goph-keeper/internal/tenant"
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+)
+
+// WebhookRepo implements WebhookRepository using PostgreSQL.
+type WebhookRepo struct{ db *DB }
+
+// NewWebhookRepo constructs a webhook repository.
+func NewWebhookRepo(db *DB) *WebhookRepo { return &WebhookRepo{db: db} }
+
+// Create inserts h and fills in its creation time. The per-user limit is
+// checked in the same statement; concurrent creates may overshoot it by one.
+func (r *WebhookRepo) Create(ctx context.Context, h *model.Webhook, max int) error {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
+	const q = `
+INSERT INTO webhooks (id, user_id, tenant_id, url, secret)
+SELECT $1, $2, $3, $4, $5
+WHERE (SELECT count(*) FROM webhooks WHERE user_id=$2) < $6
+RETURNING created_at`
+	err := r.db.Pool.QueryRow(ctx, q, h.ID, h.UserID, tenant.FromContext(ctx), h.URL, h.Secret, max).Scan(&h.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%w: at most %d webhooks per user", errs.ErrQuotaExceeded, max)
+	}
+	return err
+}
+
+// List selects the user's webhooks, oldest first.
+func (r *WebhookRepo) List(ctx context.Context, userID uuid.UUID) ([]model.Webhook, error) {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
+	const q = `
+SELECT id, url, secret, created_at
+FROM webhooks WHERE user_id=$1 AND tenant_id=$2
+ORDER BY created_at, id`
+	rows, err := r.db.Pool.Query(ctx, q, userID, tenant.FromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []model.Webhook
+	for rows.Next() {
+		h := model.Webhook{UserID: userID}
+		if err := rows.Scan(&h.ID, &h.URL, &h.Secret, &h.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, h)
+	}
+	return out, rows.Err()
+}
+
+// Delete removes one of the user's webhooks.
+func (r *WebhookRepo) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	ctx, cancel := r.db.withTimeout(ctx)
+	defer cancel()
+
+	tag, err := r.db.Pool.Exec(ctx, `DELETE FROM webhooks WHERE id=$1 AND user_id=$2 AND tenant_id=$3`,
+		id, userID, tenant.FromContext(ctx))
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errs.ErrNotFound
+	}
+	return nil
+}
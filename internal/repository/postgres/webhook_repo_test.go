@@ -0,0 +1,50 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/and161185/goph-keeper/internal/tenant"
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+	pgxmock "github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookRepo(t *testing.T) {
+	db, mock := newDB(t)
+	defer mock.Close()
+	r := NewWebhookRepo(db)
+	ctx := context.Background()
+	user := uuid.Must(uuid.NewV4())
+	h := model.Webhook{ID: uuid.Must(uuid.NewV4()), UserID: user, URL: "https://ci.example/h", Secret: []byte("whsec_x")}
+	now := time.Now().UTC()
+
+	mock.ExpectQuery(`INSERT INTO webhooks .* WHERE \(SELECT count\(\*\) FROM webhooks WHERE user_id=\$2\) < \$6`).
+		WithArgs(h.ID, user, tenant.Default, h.URL, h.Secret, 2).
+		WillReturnRows(pgxmock.NewRows([]string{"created_at"}).AddRow(now))
+	require.NoError(t, r.Create(ctx, &h, 2))
+	require.Equal(t, now, h.CreatedAt)
+
+	mock.ExpectQuery(`INSERT INTO webhooks`).WithArgs(h.ID, user, tenant.Default, h.URL, h.Secret, 2).
+		WillReturnError(pgx.ErrNoRows)
+	require.ErrorIs(t, r.Create(ctx, &h, 2), errs.ErrQuotaExceeded)
+
+	mock.ExpectQuery(`SELECT id, url, secret, created_at FROM webhooks WHERE user_id=\$1 AND tenant_id=\$2`).
+		WithArgs(user, tenant.Default).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "url", "secret", "created_at"}).AddRow(h.ID, h.URL, h.Secret, now))
+	got, err := r.List(ctx, user)
+	require.NoError(t, err)
+	require.Equal(t, []model.Webhook{h}, got)
+
+	mock.ExpectExec(`DELETE FROM webhooks WHERE id=\$1 AND user_id=\$2 AND tenant_id=\$3`).
+		WithArgs(h.ID, user, tenant.Default).WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	require.NoError(t, r.Delete(ctx, user, h.ID))
+	mock.ExpectExec(`DELETE FROM webhooks`).
+		WithArgs(h.ID, user, tenant.Default).WillReturnResult(pgxmock.NewResult("DELETE", 0))
+	require.ErrorIs(t, r.Delete(ctx, user, h.ID), errs.ErrNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
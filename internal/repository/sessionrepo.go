@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/gofrs/uuid/v5"
+)
+
+// SessionRepository stores refresh-token sessions by the SHA-256 of their
+// token; the tokens themselves are never stored.
+type SessionRepository interface {
+	// Create stores s with tokenHash and sets its ID and times. The user's
+	// expired sessions are dropped, and so are the least recently used ones
+	// beyond keep.
+	Create(ctx context.Context, s *model.Session, tokenHash []byte, expires time.Time, keep int) error
+	// Rotate replaces the hash of an unexpired session's token, marks the
+	// session used and extends it to expires. ErrNotFound if no live
+	// session has oldHash, so each token works once.
+	Rotate(ctx context.Context, oldHash, newHash []byte, expires time.Time) (model.Session, error)
+	// Owner returns the user of the unexpired session holding tokenHash,
+	// without using it; ErrNotFound if none.
+	Owner(ctx context.Context, tokenHash []byte) (uuid.UUID, error)
+	// List returns the user's unexpired sessions, most recently used first.
+	List(ctx context.Context, userID uuid.UUID) ([]model.Session, error)
+	// Delete ends one of the user's sessions; ErrNotFound if none.
+	Delete(ctx context.Context, userID, id uuid.UUID) error
+}
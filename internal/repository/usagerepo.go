@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/and161185/goph-keeper/internal/model"
+)
+
+// UsageRepository keeps daily rollups of each user's storage for capacity
+// planning. It sees every tenant.
+type UsageRepository interface {
+	// RollupUsage records every user's current usage under day, replacing
+	// what that day had, and drops days more than keep days before it. It
+	// returns the number of users recorded.
+	RollupUsage(ctx context.Context, day time.Time, keep int) (int64, error)
+	// UsageReport returns the users of the newest rolled-up day, most bytes
+	// first, at most limit, only tenant's unless it is empty. Each user has
+	// the samples of the days days up to and including that day, oldest
+	// first.
+	UsageReport(ctx context.Context, tenant string, limit, days int) (model.UsageReport, error)
+}
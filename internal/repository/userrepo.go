@@ -3,6 +3,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/and161185/goph-keeper/internal/model"
 	"github.com/gofrs/uuid/v5"
@@ -10,7 +11,8 @@ import (
 
 // UserRepository provides CRUD access for users and bootstrap data.
 type UserRepository interface {
-	// Create inserts a new user.
+	// Create inserts a new user; a taken username (per tenant) is
+	// errs.ErrAlreadyExists.
 	Create(ctx context.Context, u *model.User) error
 	// GetByID loads a user by ID.
 	GetByID(ctx context.Context, id uuid.UUID) (*model.User, error)
@@ -18,4 +20,32 @@ type UserRepository interface {
 	GetByUsername(ctx context.Context, username string) (*model.User, error)
 	// SetWrappedDEKIfEmpty stores wrapped DEK only if it is currently empty.
 	SetWrappedDEKIfEmpty(ctx context.Context, id uuid.UUID, wrapped []byte) error
+	// ReplaceWrappedDEK swaps the wrapped DEK only if it still equals prev.
+	ReplaceWrappedDEK(ctx context.Context, id uuid.UUID, prev, wrapped []byte) error
+	// RecordLogin appends a to the user's login history and drops all but
+	// the newest keep attempts.
+	RecordLogin(ctx context.Context, id uuid.UUID, a model.LoginAttempt, keep int) error
+	// LoginHistory returns the user's newest login attempts, newest first.
+	LoginHistory(ctx context.Context, id uuid.UUID, limit int) ([]model.LoginAttempt, error)
+	// CreateDuress stores vault as the user's duress vault, a user of its
+	// own; a user that has one already is errs.ErrAlreadyExists.
+	CreateDuress(ctx context.Context, id uuid.UUID, vault *model.User) error
+	// GetDuress loads the user's duress vault, errs.ErrNotFound if none.
+	GetDuress(ctx context.Context, id uuid.UUID) (*model.User, error)
+	// DeleteDuress removes the user's duress vault with all its items;
+	// errs.ErrNotFound if there is none.
+	DeleteDuress(ctx context.Context, id uuid.UUID) error
+	// Freeze marks the user's vault read-only from at, unless it already
+	// is, and returns when it was frozen.
+	Freeze(ctx context.Context, id uuid.UUID, at time.Time) (time.Time, error)
+	// Unfreeze makes the user's vault writable again.
+	Unfreeze(ctx context.Context, id uuid.UUID) error
+	// FrozenAt returns when the user's vault was frozen; zero if it is not.
+	FrozenAt(ctx context.Context, id uuid.UUID) (time.Time, error)
+	// SetDisabled suspends or reactivates the user and its duress vault,
+	// errs.ErrNotFound if there is no such user. Suspending ends their
+	// sessions. Admins act across tenants, so it goes by id alone.
+	SetDisabled(ctx context.Context, id uuid.UUID, disabled bool) error
+	// Disabled reports whether the user is suspended.
+	Disabled(ctx context.Context, id uuid.UUID) (bool, error)
 }
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/gofrs/uuid/v5"
+)
+
+// WebhookRepository stores users' change notification endpoints.
+type WebhookRepository interface {
+	// Create stores h, setting its creation time, unless its owner
+	// already has max webhooks.
+	Create(ctx context.Context, h *model.Webhook, max int) error
+	// List returns the user's webhooks, secrets included, oldest first.
+	List(ctx context.Context, userID uuid.UUID) ([]model.Webhook, error)
+	// Delete removes one of the user's webhooks.
+	Delete(ctx context.Context, userID, id uuid.UUID) error
+}
@@ -0,0 +1,49 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/and161185/goph-keeper/internal/awsv4"
+)
+
+// AWS reads secrets from AWS Secrets Manager. Names look like
+// "secret-id" (whole SecretString) or "secret-id#key" (one key of a JSON secret).
+type AWS struct {
+	API *awsv4.Client
+}
+
+// AWSFromEnv configures AWS from the standard AWS_* environment variables.
+func AWSFromEnv() *AWS {
+	return &AWS{API: awsv4.FromEnvClient()}
+}
+
+// Get implements Provider.
+func (a *AWS) Get(ctx context.Context, name string) (string, error) {
+	id, key := splitField(name, "")
+	var out struct {
+		SecretString string `json:"SecretString"`
+	}
+	err := a.API.Do(ctx, "secretsmanager", "secretsmanager.GetSecretValue", map[string]string{"SecretId": id}, &out)
+	var apiErr *awsv4.APIError
+	if errors.As(err, &apiErr) && apiErr.Type == "ResourceNotFoundException" {
+		return "", fmt.Errorf("%s: %w", id, ErrNotFound)
+	}
+	if err != nil {
+		return "", fmt.Errorf("secrets manager %s: %w", id, err)
+	}
+	if key == "" {
+		return out.SecretString, nil
+	}
+	var kv map[string]any
+	if err := json.Unmarshal([]byte(out.SecretString), &kv); err != nil {
+		return "", fmt.Errorf("secrets manager %s: not a JSON secret, cannot pick %q", id, key)
+	}
+	s, ok := kv[key].(string)
+	if !ok {
+		return "", fmt.Errorf("%s#%s: %w", id, key, ErrNotFound)
+	}
+	return s, nil
+}
@@ -0,0 +1,119 @@
+// Package secrets resolves sensitive server configuration (JWT key, DSN)
+// from files and external secret managers, so it never has to appear in
+// process arguments or unit files.
+//
+// A reference has the form "scheme:rest":
+//
+//	file:/etc/gophkeeper/jwt.key
+//	env:GK_JWT_KEY
+//	vault:kv/gophkeeper#jwt_key      (HashiCorp Vault KV v2, VAULT_ADDR/VAULT_TOKEN)
+//	awssm:prod/gophkeeper#dsn        (AWS Secrets Manager, AWS_* environment)
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ErrNotFound is returned when a reference points at nothing.
+var ErrNotFound = errors.New("secret not found")
+
+// Provider fetches the secret named by the part of a reference after "scheme:".
+type Provider interface {
+	Get(ctx context.Context, name string) (string, error)
+}
+
+// ProviderFunc adapts a function to Provider.
+type ProviderFunc func(ctx context.Context, name string) (string, error)
+
+// Get implements Provider.
+func (f ProviderFunc) Get(ctx context.Context, name string) (string, error) { return f(ctx, name) }
+
+// Resolver dispatches references to providers by scheme.
+type Resolver struct {
+	providers map[string]Provider
+}
+
+// NewResolver returns a resolver with the file and env providers registered.
+func NewResolver() *Resolver {
+	r := &Resolver{providers: map[string]Provider{}}
+	r.Register("file", ProviderFunc(func(_ context.Context, path string) (string, error) { return ReadFile(path) }))
+	r.Register("env", ProviderFunc(func(_ context.Context, name string) (string, error) {
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("env %s: %w", name, ErrNotFound)
+		}
+		return v, nil
+	}))
+	return r
+}
+
+// Default returns a resolver with every built-in provider, configured from the environment.
+func Default() *Resolver {
+	r := NewResolver()
+	r.Register("vault", VaultFromEnv())
+	r.Register("awssm", AWSFromEnv())
+	return r
+}
+
+// Register adds or replaces the provider for scheme.
+func (r *Resolver) Register(scheme string, p Provider) { r.providers[scheme] = p }
+
+// Resolve fetches the secret for ref.
+func (r *Resolver) Resolve(ctx context.Context, ref string) (string, error) {
+	scheme, name, ok := strings.Cut(ref, ":")
+	if !ok || name == "" {
+		return "", fmt.Errorf("bad secret reference %q: want scheme:name", ref)
+	}
+	p, ok := r.providers[scheme]
+	if !ok {
+		return "", fmt.Errorf("bad secret reference %q: unknown scheme %q", ref, scheme)
+	}
+	v, err := p.Get(ctx, name)
+	if err != nil {
+		return "", fmt.Errorf("secret %s: %w", scheme, err)
+	}
+	return v, nil
+}
+
+// Pick returns the one configured source of a setting: a literal value, a
+// file path or a provider reference. Setting more than one is an error.
+func (r *Resolver) Pick(ctx context.Context, setting, literal, file, ref string) (string, error) {
+	set := 0
+	for _, s := range []string{literal, file, ref} {
+		if s != "" {
+			set++
+		}
+	}
+	switch {
+	case set > 1:
+		return "", fmt.Errorf("%s: use only one of the value, -file and -ref flags", setting)
+	case file != "":
+		return ReadFile(file)
+	case ref != "":
+		return r.Resolve(ctx, ref)
+	}
+	return literal, nil
+}
+
+// ReadFile reads a secret file, dropping one trailing newline as left by editors and echo.
+func ReadFile(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	s := strings.TrimSuffix(string(b), "\n")
+	return strings.TrimSuffix(s, "\r"), nil
+}
+
+// splitField splits "path#field" and applies the default field name.
+func splitField(name, def string) (string, string) {
+	path, field, ok := strings.Cut(name, "#")
+	if !ok || field == "" {
+		field = def
+	}
+	return path, field
+}
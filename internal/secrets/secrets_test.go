@@ -0,0 +1,134 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/and161185/goph-keeper/internal/awsv4"
+)
+
+func TestReadFile_TrimsOneNewline(t *testing.T) {
+	t.Parallel()
+	p := filepath.Join(t.TempDir(), "jwt.key")
+	if err := os.WriteFile(p, []byte("s3cret\r\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := ReadFile(p); err != nil || v != "s3cret" {
+		t.Fatalf("got %q %v", v, err)
+	}
+}
+
+func TestResolver_Pick(t *testing.T) {
+	t.Setenv("GK_TEST_SECRET", "from-env")
+	p := filepath.Join(t.TempDir(), "dsn")
+	if err := os.WriteFile(p, []byte("postgres://x\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	r := NewResolver()
+	ctx := context.Background()
+
+	cases := []struct {
+		literal, file, ref, want string
+	}{
+		{"lit", "", "", "lit"},
+		{"", p, "", "postgres://x"},
+		{"", "", "env:GK_TEST_SECRET", "from-env"},
+		{"", "", "file:" + p, "postgres://x"},
+	}
+	for _, c := range cases {
+		if v, err := r.Pick(ctx, "dsn", c.literal, c.file, c.ref); err != nil || v != c.want {
+			t.Fatalf("%+v: got %q %v", c, v, err)
+		}
+	}
+	if _, err := r.Pick(ctx, "dsn", "lit", p, ""); err == nil {
+		t.Fatal("want error for two sources")
+	}
+	if _, err := r.Resolve(ctx, "env:GK_TEST_MISSING_VAR"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("missing env: %v", err)
+	}
+	for _, ref := range []string{"noscheme", "nope:x", "env:"} {
+		if _, err := r.Resolve(ctx, ref); err == nil {
+			t.Fatalf("%q: want error", ref)
+		}
+	}
+}
+
+func TestVault_Get(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "tok" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.Path != "/v1/kv/data/gophkeeper" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = io.WriteString(w, `{"data":{"data":{"jwt_key":"k1","value":"v"}}}`)
+	}))
+	defer srv.Close()
+
+	v := &Vault{Addr: srv.URL, Token: "tok", Client: srv.Client()}
+	ctx := context.Background()
+	if got, err := v.Get(ctx, "kv/gophkeeper#jwt_key"); err != nil || got != "k1" {
+		t.Fatalf("field: %q %v", got, err)
+	}
+	if got, err := v.Get(ctx, "kv/gophkeeper"); err != nil || got != "v" {
+		t.Fatalf("default field: %q %v", got, err)
+	}
+	if _, err := v.Get(ctx, "kv/other"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("missing path: %v", err)
+	}
+	if _, err := v.Get(ctx, "kv/gophkeeper#nope"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("missing field: %v", err)
+	}
+	if _, err := (&Vault{Addr: srv.URL, Token: "bad", Client: srv.Client()}).Get(ctx, "kv/gophkeeper"); err == nil {
+		t.Fatal("want error for bad token")
+	}
+}
+
+func TestAWS_Get(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Amz-Target") != "secretsmanager.GetSecretValue" ||
+			!strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AK/") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var in struct{ SecretId string }
+		_ = json.NewDecoder(r.Body).Decode(&in)
+		switch in.SecretId {
+		case "plain":
+			_, _ = io.WriteString(w, `{"SecretString":"p"}`)
+		case "json":
+			_, _ = io.WriteString(w, `{"SecretString":"{\"dsn\":\"postgres://x\"}"}`)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = io.WriteString(w, `{"__type":"ResourceNotFoundException"}`)
+		}
+	}))
+	defer srv.Close()
+
+	a := &AWS{API: &awsv4.Client{Region: "eu-west-1", Creds: awsv4.Credentials{AccessKey: "AK", SecretKey: "SK"}, Endpoint: srv.URL, HTTP: srv.Client()}}
+	ctx := context.Background()
+	if got, err := a.Get(ctx, "plain"); err != nil || got != "p" {
+		t.Fatalf("plain: %q %v", got, err)
+	}
+	if got, err := a.Get(ctx, "json#dsn"); err != nil || got != "postgres://x" {
+		t.Fatalf("json key: %q %v", got, err)
+	}
+	if _, err := a.Get(ctx, "json#nope"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("missing key: %v", err)
+	}
+	if _, err := a.Get(ctx, "gone"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("missing secret: %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Vault reads secrets from a HashiCorp Vault KV version 2 engine.
+// Names look like "mount/path#field"; the field defaults to "value".
+type Vault struct {
+	Addr   string // e.g. https://vault.internal:8200
+	Token  string
+	Client *http.Client
+}
+
+// VaultFromEnv configures Vault from VAULT_ADDR and VAULT_TOKEN (or the file named by VAULT_TOKEN_FILE).
+func VaultFromEnv() *Vault {
+	v := &Vault{Addr: os.Getenv("VAULT_ADDR"), Token: os.Getenv("VAULT_TOKEN")}
+	if f := os.Getenv("VAULT_TOKEN_FILE"); v.Token == "" && f != "" {
+		v.Token, _ = ReadFile(f)
+	}
+	return v
+}
+
+// Get implements Provider.
+func (v *Vault) Get(ctx context.Context, name string) (string, error) {
+	if v.Addr == "" || v.Token == "" {
+		return "", errors.New("VAULT_ADDR and VAULT_TOKEN must be set")
+	}
+	path, field := splitField(name, "value")
+	mount, rest, ok := strings.Cut(strings.Trim(path, "/"), "/")
+	if !ok || rest == "" {
+		return "", fmt.Errorf("vault name %q: want mount/path#field", name)
+	}
+
+	url := strings.TrimRight(v.Addr, "/") + "/v1/" + mount + "/data/" + rest
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", v.Token)
+	cli := v.Client
+	if cli == nil {
+		cli = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := cli.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return "", fmt.Errorf("%s: %w", path, ErrNotFound)
+	default:
+		return "", fmt.Errorf("vault %s: %s", path, resp.Status)
+	}
+
+	var body struct {
+		Data struct {
+			Data map[string]any `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("vault %s: %w", path, err)
+	}
+	s, ok := body.Data.Data[field].(string)
+	if !ok {
+		return "", fmt.Errorf("%s#%s: %w", path, field, ErrNotFound)
+	}
+	return s, nil
+}
@@ -0,0 +1,80 @@
+package grpcserver
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/and161185/goph-keeper/internal/repository"
+)
+
+// SetAccessLog makes GetItem note which session read each item, for
+// GetItemAccessLog. Without it nothing is recorded and GetItemAccessLog
+// fails with FailedPrecondition.
+func (s *Server) SetAccessLog(repo repository.AccessLogRepository) { s.accesses = repo }
+
+// tokenSession is the session the call's token was issued for, uuid.Nil
+// for tokens from a login without refresh tokens.
+func (s *Server) tokenSession(ctx context.Context) uuid.UUID {
+	claims, err := bearerClaims(ctx, s.verifier)
+	if err != nil {
+		return uuid.Nil
+	}
+	id, _ := uuid.FromString(claims.Session)
+	return id
+}
+
+// recordAccess notes a read of the item. It is best effort: the item was
+// read either way, and failing the call would only hide it from the user.
+func (s *Server) recordAccess(ctx context.Context, userID, itemID uuid.UUID) {
+	if s.accesses == nil {
+		return
+	}
+	_ = s.accesses.Record(ctx, userID, itemID, s.tokenSession(ctx), time.Now().UTC())
+}
+
+func toProtoItemAccess(a model.ItemAccess, current bool) *pb.ItemAccess {
+	out := &pb.ItemAccess{}
+	if a.SessionID != uuid.Nil {
+		out.SetSessionId(a.SessionID.String())
+	}
+	out.SetLabel(a.Label)
+	out.SetFirstAt(timestamppb.New(a.FirstAt))
+	out.SetLastAt(timestamppb.New(a.LastAt))
+	out.SetReads(a.Reads)
+	out.SetCurrent(current)
+	return out
+}
+
+// GetItemAccessLog lists the sessions that read one of the caller's items.
+func (s *Server) GetItemAccessLog(ctx context.Context, req *pb.GetItemAccessLogRequest) (*pb.GetItemAccessLogResponse, error) {
+	userID, err := s.userIDFromCtx(ctx)
+	if err != nil {
+		return nil, statusError(codes.Unauthenticated, errs.ReasonUnauthenticated, "no auth")
+	}
+	if s.accesses == nil {
+		return nil, statusError(codes.FailedPrecondition, errs.ReasonNotConfigured, "the access log is disabled on this server")
+	}
+	itemID, err := uuid.FromString(req.GetId())
+	if err != nil {
+		return nil, statusError(codes.InvalidArgument, errs.ReasonInvalidArgument, "bad id")
+	}
+	list, err := s.accesses.List(ctx, userID, itemID)
+	if err != nil {
+		return nil, toStatus("item access log", err)
+	}
+	current := s.tokenSession(ctx)
+	out := make([]*pb.ItemAccess, 0, len(list))
+	for _, a := range list {
+		out = append(out, toProtoItemAccess(a, current != uuid.Nil && a.SessionID == current))
+	}
+	resp := &pb.GetItemAccessLogResponse{}
+	resp.SetAccesses(out)
+	return resp, nil
+}
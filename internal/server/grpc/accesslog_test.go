@@ -0,0 +1,83 @@
+package grpcserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/internal/limiter"
+	"github.com/and161185/goph-keeper/internal/repository/memory"
+	"github.com/and161185/goph-keeper/internal/service"
+	"github.com/and161185/goph-keeper/internal/tokensign"
+)
+
+func TestServer_ItemAccessLog(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	signer := tokensign.HMAC([]byte("k"))
+	auth := service.NewAuthService(memory.NewUserRepo(), signer, time.Minute, limiter.NewMemory(time.Minute, 5, time.Minute))
+	sessions := memory.NewSessionRepo()
+	auth.SetSessions(sessions, time.Hour)
+	s := New(auth, &fakeItems{}, signer)
+
+	rr := &pb.RegisterRequest{}
+	rr.SetUsername("alice")
+	rr.SetPassword("pw")
+	if _, err := s.Register(ctx, rr); err != nil {
+		t.Fatal(err)
+	}
+	login := func(label string) context.Context {
+		t.Helper()
+		lr := &pb.LoginRequest{}
+		lr.SetUsername("alice")
+		lr.SetPassword("pw")
+		lr.SetDeviceLabel(label)
+		resp, err := s.Login(ctx, lr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ctxAuth(resp.GetAccessToken())
+	}
+	laptop, kiosk := login("laptop"), login("library kiosk")
+	id := uuid.Must(uuid.NewV4()).String()
+	get := &pb.GetItemRequest{}
+	get.SetId(id)
+	logReq := &pb.GetItemAccessLogRequest{}
+	logReq.SetId(id)
+
+	if _, err := s.GetItemAccessLog(laptop, logReq); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("log off: %v", err)
+	}
+	s.SetAccessLog(memory.NewAccessLogRepo(sessions))
+
+	for _, c := range []context.Context{laptop, laptop, kiosk} {
+		if _, err := s.GetItem(c, get); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	resp, err := s.GetItemAccessLog(laptop, logReq)
+	if err != nil || len(resp.GetAccesses()) != 2 {
+		t.Fatalf("log: %v %v", resp, err)
+	}
+	last, first := resp.GetAccesses()[0], resp.GetAccesses()[1]
+	if last.GetLabel() != "library kiosk" || last.GetReads() != 1 || last.GetCurrent() {
+		t.Fatalf("kiosk read last: %v", last)
+	}
+	if first.GetLabel() != "laptop" || first.GetReads() != 2 || !first.GetCurrent() || first.GetSessionId() == "" {
+		t.Fatalf("laptop read twice and made the call: %v", first)
+	}
+
+	logReq.SetId(uuid.Must(uuid.NewV4()).String())
+	if resp, err := s.GetItemAccessLog(laptop, logReq); err != nil || len(resp.GetAccesses()) != 0 {
+		t.Fatalf("unread item: %v %v", resp, err)
+	}
+	if _, err := s.GetItemAccessLog(ctx, logReq); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("no token: %v", err)
+	}
+}
@@ -0,0 +1,395 @@
+package grpcserver
+
+import (
+	"context"
+	"time"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/internal/consistency"
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/ipfilter"
+	"github.com/and161185/goph-keeper/internal/limiter"
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/and161185/goph-keeper/internal/repository"
+	"github.com/and161185/goph-keeper/internal/tenant"
+	"github.com/and161185/goph-keeper/internal/tokensign"
+	"github.com/gofrs/uuid/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Diagnostics is a runtime-toggleable diagnostics endpoint.
+type Diagnostics interface {
+	SetEnabled(v bool)
+	Enabled() bool
+}
+
+// Rollbacker rolls one user's items back to a point in time.
+type Rollbacker interface {
+	RollbackUser(ctx context.Context, userID uuid.UUID, to time.Time, by uuid.UUID, dryRun bool) (model.RollbackResult, error)
+}
+
+// Lockouts lists and lifts login lockouts; usernames are tenant-scoped.
+type Lockouts interface {
+	Locks(ctx context.Context) ([]limiter.Lock, error)
+	Clear(ctx context.Context, username string, ipHash []byte) (int, error)
+}
+
+// Suspender suspends and reactivates accounts in any tenant;
+// *service.AuthServiceImpl implements it.
+type Suspender interface {
+	SetDisabled(ctx context.Context, userID uuid.UUID, disabled bool) error
+}
+
+// Admin implements operator-only RPCs; callers must be in the admin set.
+type Admin struct {
+	pb.UnimplementedAdminServiceServer
+	verifier tokensign.Verifier
+	admins   map[uuid.UUID]struct{}
+	diag     Diagnostics
+	rollback Rollbacker
+	locks    Lockouts
+	ipRules  repository.IPRuleRepository
+	maxRules int
+	checker  repository.ConsistencyRepository
+	usage    repository.UsageRepository
+	suspend  Suspender
+	now      func() time.Time
+}
+
+// NewAdmin constructs the admin service. diag may be nil when no
+// diagnostics listener is configured, rb when rollbacks are unavailable,
+// locks when the login limiter cannot be managed.
+func NewAdmin(verifier tokensign.Verifier, admins []uuid.UUID, diag Diagnostics, rb Rollbacker, locks Lockouts) *Admin {
+	set := make(map[uuid.UUID]struct{}, len(admins))
+	for _, id := range admins {
+		set[id] = struct{}{}
+	}
+	return &Admin{verifier: verifier, admins: set, diag: diag, rollback: rb, locks: locks, now: time.Now}
+}
+
+// SetIPRules enables the per-user address rule RPCs, with at most max
+// rules per user. Without it they fail with FailedPrecondition.
+func (a *Admin) SetIPRules(repo repository.IPRuleRepository, max int) {
+	a.ipRules, a.maxRules = repo, max
+}
+
+// SetConsistency enables CheckUserConsistency. Without it the call fails
+// with FailedPrecondition.
+func (a *Admin) SetConsistency(repo repository.ConsistencyRepository) {
+	a.checker = repo
+}
+
+// SetUsage enables GetUsageReport. Without it the call fails with
+// FailedPrecondition.
+func (a *Admin) SetUsage(repo repository.UsageRepository) {
+	a.usage = repo
+}
+
+// SetSuspender enables SuspendUser and ReactivateUser. Without it they
+// fail with FailedPrecondition.
+func (a *Admin) SetSuspender(s Suspender) {
+	a.suspend = s
+}
+
+// authorize verifies the bearer token and checks admin membership.
+func (a *Admin) authorize(ctx context.Context) (uuid.UUID, error) {
+	id, err := verifyBearer(ctx, a.verifier)
+	if err != nil {
+		return uuid.Nil, statusError(codes.Unauthenticated, errs.ReasonUnauthenticated, "no auth")
+	}
+	if _, ok := a.admins[id]; !ok {
+		return uuid.Nil, statusError(codes.PermissionDenied, errs.ReasonPermissionDenied, "admin only")
+	}
+	return id, nil
+}
+
+// SetDiagnostics enables or disables the diagnostics HTTP endpoint.
+func (a *Admin) SetDiagnostics(ctx context.Context, req *pb.SetDiagnosticsRequest) (*pb.SetDiagnosticsResponse, error) {
+	if _, err := a.authorize(ctx); err != nil {
+		return nil, err
+	}
+	if a.diag == nil {
+		return nil, statusError(codes.FailedPrecondition, errs.ReasonNotConfigured, "diagnostics listener not configured")
+	}
+	a.diag.SetEnabled(req.GetEnabled())
+
+	resp := &pb.SetDiagnosticsResponse{}
+	resp.SetEnabled(a.diag.Enabled())
+	return resp, nil
+}
+
+// RollbackUser rolls one user's vault back to a timestamp.
+func (a *Admin) RollbackUser(ctx context.Context, req *pb.RollbackUserRequest) (*pb.RollbackUserResponse, error) {
+	by, err := a.authorize(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if a.rollback == nil {
+		return nil, statusError(codes.FailedPrecondition, errs.ReasonNotConfigured, "rollback not available")
+	}
+	userID, err := uuid.FromString(req.GetUserId())
+	if err != nil {
+		return nil, statusError(codes.InvalidArgument, errs.ReasonInvalidArgument, "bad user_id")
+	}
+	to := req.GetToTime().AsTime()
+	if to.After(a.now()) {
+		return nil, statusError(codes.InvalidArgument, errs.ReasonInvalidArgument, "to_time is in the future")
+	}
+
+	res, err := a.rollback.RollbackUser(ctx, userID, to, by, req.GetDryRun())
+	if err != nil {
+		return nil, toStatus("RollbackUser", err, remap(errs.ErrNotFound, errs.ReasonNotFound, "user not found"))
+	}
+	resp := &pb.RollbackUserResponse{}
+	resp.SetRollbackId(res.ID)
+	resp.SetRestored(res.Restored)
+	resp.SetRemoved(res.Removed)
+	resp.SetDiscardedVersions(res.Discarded)
+	resp.SetSkipped(res.Skipped)
+	resp.SetDryRun(req.GetDryRun())
+	return resp, nil
+}
+
+// CheckUserConsistency checks one user's items for divergence and, if
+// asked, repairs what it safely can.
+func (a *Admin) CheckUserConsistency(ctx context.Context, req *pb.CheckUserConsistencyRequest) (*pb.CheckUserConsistencyResponse, error) {
+	if _, err := a.authorize(ctx); err != nil {
+		return nil, err
+	}
+	if a.checker == nil {
+		return nil, statusError(codes.FailedPrecondition, errs.ReasonNotConfigured, "consistency checks not available")
+	}
+	userID, err := uuid.FromString(req.GetUserId())
+	if err != nil {
+		return nil, statusError(codes.InvalidArgument, errs.ReasonInvalidArgument, "bad user_id")
+	}
+
+	n, vs, err := consistency.Run(ctx, a.checker, userID, req.GetRepair())
+	if err != nil {
+		return nil, toStatus("CheckUserConsistency", err)
+	}
+	out := make([]*pb.ConsistencyViolation, 0, len(vs))
+	for _, v := range vs {
+		pv := &pb.ConsistencyViolation{}
+		pv.SetItemId(v.ItemID.String())
+		pv.SetCheck(v.Check)
+		pv.SetDetail(v.Detail)
+		pv.SetRepaired(v.Repaired)
+		out = append(out, pv)
+	}
+	resp := &pb.CheckUserConsistencyResponse{}
+	resp.SetItemsChecked(int64(n))
+	resp.SetViolations(out)
+	return resp, nil
+}
+
+// SuspendUser disables an account until ReactivateUser.
+func (a *Admin) SuspendUser(ctx context.Context, req *pb.SuspendUserRequest) (*pb.SuspendUserResponse, error) {
+	if err := a.setDisabled(ctx, "SuspendUser", req.GetUserId(), true); err != nil {
+		return nil, err
+	}
+	return &pb.SuspendUserResponse{}, nil
+}
+
+// ReactivateUser lifts a suspension.
+func (a *Admin) ReactivateUser(ctx context.Context, req *pb.ReactivateUserRequest) (*pb.ReactivateUserResponse, error) {
+	if err := a.setDisabled(ctx, "ReactivateUser", req.GetUserId(), false); err != nil {
+		return nil, err
+	}
+	return &pb.ReactivateUserResponse{}, nil
+}
+
+func (a *Admin) setDisabled(ctx context.Context, op, user string, disabled bool) error {
+	if _, err := a.authorize(ctx); err != nil {
+		return err
+	}
+	if a.suspend == nil {
+		return statusError(codes.FailedPrecondition, errs.ReasonNotConfigured, "suspension not available")
+	}
+	userID, err := uuid.FromString(user)
+	if err != nil {
+		return statusError(codes.InvalidArgument, errs.ReasonInvalidArgument, "bad user_id")
+	}
+	if err := a.suspend.SetDisabled(ctx, userID, disabled); err != nil {
+		return toStatus(op, err, remap(errs.ErrNotFound, errs.ReasonNotFound, "user not found"))
+	}
+	return nil
+}
+
+// defaultUsageUsers is the GetUsageReport limit when the request has none.
+const defaultUsageUsers = 100
+
+// GetUsageReport returns the largest users of the latest usage rollup.
+func (a *Admin) GetUsageReport(ctx context.Context, req *pb.GetUsageReportRequest) (*pb.GetUsageReportResponse, error) {
+	if _, err := a.authorize(ctx); err != nil {
+		return nil, err
+	}
+	if a.usage == nil {
+		return nil, statusError(codes.FailedPrecondition, errs.ReasonNotConfigured, "usage reports not available")
+	}
+	limit := int(req.GetLimit())
+	if limit == 0 {
+		limit = defaultUsageUsers
+	}
+
+	rep, err := a.usage.UsageReport(ctx, req.GetTenant(), limit, int(req.GetDays()))
+	if err != nil {
+		return nil, toStatus("GetUsageReport", err)
+	}
+	out := make([]*pb.UserUsage, 0, len(rep.Users))
+	for _, u := range rep.Users {
+		pu := &pb.UserUsage{}
+		pu.SetUserId(u.UserID.String())
+		pu.SetTenant(u.Tenant)
+		pu.SetItems(u.Items)
+		pu.SetTombstones(u.Tombstones)
+		pu.SetBytes(u.Bytes)
+		pu.SetHistoryBytes(u.HistoryBytes)
+		if !u.LastWrite.IsZero() {
+			pu.SetLastWrite(timestamppb.New(u.LastWrite))
+		}
+		if !u.LastLogin.IsZero() {
+			pu.SetLastLogin(timestamppb.New(u.LastLogin))
+		}
+		samples := make([]*pb.UsageSample, 0, len(u.History))
+		for _, h := range u.History {
+			ps := &pb.UsageSample{}
+			ps.SetDay(timestamppb.New(h.Day))
+			ps.SetItems(h.Items)
+			ps.SetBytes(h.Bytes)
+			ps.SetHistoryBytes(h.HistoryBytes)
+			samples = append(samples, ps)
+		}
+		pu.SetHistory(samples)
+		out = append(out, pu)
+	}
+	resp := &pb.GetUsageReportResponse{}
+	if !rep.AsOf.IsZero() {
+		resp.SetAsOf(timestamppb.New(rep.AsOf))
+	}
+	resp.SetUsers(out)
+	return resp, nil
+}
+
+// ListLoginLocks lists the (username, ip) pairs locked out of Login.
+func (a *Admin) ListLoginLocks(ctx context.Context, _ *pb.ListLoginLocksRequest) (*pb.ListLoginLocksResponse, error) {
+	if _, err := a.authorize(ctx); err != nil {
+		return nil, err
+	}
+	if a.locks == nil {
+		return nil, statusError(codes.FailedPrecondition, errs.ReasonNotConfigured, "lockouts not manageable")
+	}
+	locks, err := a.locks.Locks(ctx)
+	if err != nil {
+		return nil, toStatus("ListLoginLocks", err)
+	}
+	out := make([]*pb.LoginLock, 0, len(locks))
+	for _, lk := range locks {
+		tid, name := tenant.Unscope(lk.Username)
+		l := &pb.LoginLock{}
+		l.SetTenant(tid)
+		l.SetUsername(name)
+		l.SetIpHash(lk.IPHash)
+		l.SetFailedAttempts(int32(lk.Fails))
+		l.SetBlockedUntil(timestamppb.New(lk.BlockedUntil))
+		out = append(out, l)
+	}
+	resp := &pb.ListLoginLocksResponse{}
+	resp.SetLocks(out)
+	return resp, nil
+}
+
+// ClearLoginLocks lifts one account's lockouts.
+func (a *Admin) ClearLoginLocks(ctx context.Context, req *pb.ClearLoginLocksRequest) (*pb.ClearLoginLocksResponse, error) {
+	if _, err := a.authorize(ctx); err != nil {
+		return nil, err
+	}
+	if a.locks == nil {
+		return nil, statusError(codes.FailedPrecondition, errs.ReasonNotConfigured, "lockouts not manageable")
+	}
+	key := tenant.Scope(tenant.WithID(ctx, req.GetTenant()), req.GetUsername())
+	n, err := a.locks.Clear(ctx, key, req.GetIpHash())
+	if err != nil {
+		return nil, toStatus("ClearLoginLocks", err)
+	}
+	resp := &pb.ClearLoginLocksResponse{}
+	resp.SetCleared(int32(n))
+	return resp, nil
+}
+
+func toProtoIPRule(r model.IPRule) *pb.IPRule {
+	out := &pb.IPRule{}
+	out.SetCidr(r.CIDR.String())
+	out.SetAllow(r.Allow)
+	out.SetCreatedAt(timestamppb.New(r.CreatedAt))
+	return out
+}
+
+// ipRulesCall checks what every address rule RPC needs and returns the
+// context of the user's tenant.
+func (a *Admin) ipRulesCall(ctx context.Context, tid string) (context.Context, error) {
+	if _, err := a.authorize(ctx); err != nil {
+		return nil, err
+	}
+	if a.ipRules == nil {
+		return nil, statusError(codes.FailedPrecondition, errs.ReasonNotConfigured, "per-user address rules are off")
+	}
+	return tenant.WithID(ctx, tid), nil
+}
+
+// ListUserIPRules lists one user's address rules.
+func (a *Admin) ListUserIPRules(ctx context.Context, req *pb.ListUserIPRulesRequest) (*pb.ListUserIPRulesResponse, error) {
+	ctx, err := a.ipRulesCall(ctx, req.GetTenant())
+	if err != nil {
+		return nil, err
+	}
+	rules, err := a.ipRules.RulesByUsername(ctx, req.GetUsername())
+	if err != nil {
+		return nil, toStatus("ListUserIPRules", err)
+	}
+	out := make([]*pb.IPRule, 0, len(rules))
+	for _, r := range rules {
+		out = append(out, toProtoIPRule(r))
+	}
+	resp := &pb.ListUserIPRulesResponse{}
+	resp.SetRules(out)
+	return resp, nil
+}
+
+// AddUserIPRule adds or changes one of a user's address rules.
+func (a *Admin) AddUserIPRule(ctx context.Context, req *pb.AddUserIPRuleRequest) (*pb.AddUserIPRuleResponse, error) {
+	ctx, err := a.ipRulesCall(ctx, req.GetTenant())
+	if err != nil {
+		return nil, err
+	}
+	cidr, err := ipfilter.ParsePrefix(req.GetCidr())
+	if err != nil {
+		return nil, statusError(codes.InvalidArgument, errs.ReasonInvalidArgument, "bad cidr")
+	}
+	r := model.IPRule{CIDR: cidr, Allow: req.GetAllow()}
+	if err := a.ipRules.Add(ctx, req.GetUsername(), &r, a.maxRules); err != nil {
+		return nil, toStatus("AddUserIPRule", err,
+			remap(errs.ErrNotFound, errs.ReasonNotFound, "user not found"),
+			remap(errs.ErrQuotaExceeded, errs.ReasonIPRuleLimit, "too many address rules"))
+	}
+	resp := &pb.AddUserIPRuleResponse{}
+	resp.SetRule(toProtoIPRule(r))
+	return resp, nil
+}
+
+// RemoveUserIPRule removes one of a user's address rules.
+func (a *Admin) RemoveUserIPRule(ctx context.Context, req *pb.RemoveUserIPRuleRequest) (*pb.RemoveUserIPRuleResponse, error) {
+	ctx, err := a.ipRulesCall(ctx, req.GetTenant())
+	if err != nil {
+		return nil, err
+	}
+	cidr, err := ipfilter.ParsePrefix(req.GetCidr())
+	if err != nil {
+		return nil, statusError(codes.InvalidArgument, errs.ReasonInvalidArgument, "bad cidr")
+	}
+	if err := a.ipRules.Remove(ctx, req.GetUsername(), cidr); err != nil {
+		return nil, toStatus("RemoveUserIPRule", err, remap(errs.ErrNotFound, errs.ReasonNotFound, "rule not found"))
+	}
+	return &pb.RemoveUserIPRuleResponse{}, nil
+}
@@ -0,0 +1,458 @@
+package grpcserver
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+	"time"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/limiter"
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/and161185/goph-keeper/internal/repository"
+	"github.com/and161185/goph-keeper/internal/tenant"
+	"github.com/and161185/goph-keeper/internal/tokensign"
+	"github.com/gofrs/uuid/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type fakeDiag struct{ on bool }
+
+func (f *fakeDiag) SetEnabled(v bool) { f.on = v }
+func (f *fakeDiag) Enabled() bool     { return f.on }
+
+func diagReq(on bool) *pb.SetDiagnosticsRequest {
+	r := &pb.SetDiagnosticsRequest{}
+	r.SetEnabled(on)
+	return r
+}
+
+func TestAdmin_SetDiagnostics_AuthAndToggle(t *testing.T) {
+	t.Parallel()
+	key := []byte("k")
+	admin := uuid.Must(uuid.NewV4())
+	d := &fakeDiag{}
+	a := NewAdmin(tokensign.HMAC(key), []uuid.UUID{admin}, d, nil, nil)
+
+	if _, err := a.SetDiagnostics(context.Background(), diagReq(true)); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("want Unauthenticated, got %v", err)
+	}
+
+	other := ctxAuth(jwtFor(t, uuid.Must(uuid.NewV4()).String(), key, time.Hour))
+	if _, err := a.SetDiagnostics(other, diagReq(true)); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("want PermissionDenied, got %v", err)
+	}
+
+	ctx := ctxAuth(jwtFor(t, admin.String(), key, time.Hour))
+	resp, err := a.SetDiagnostics(ctx, diagReq(true))
+	if err != nil || !resp.GetEnabled() || !d.on {
+		t.Fatalf("enable: resp=%v err=%v", resp, err)
+	}
+	resp, err = a.SetDiagnostics(ctx, diagReq(false))
+	if err != nil || resp.GetEnabled() || d.on {
+		t.Fatalf("disable: resp=%v err=%v", resp, err)
+	}
+}
+
+func TestAdmin_SetDiagnostics_NotConfigured(t *testing.T) {
+	t.Parallel()
+	key := []byte("k")
+	admin := uuid.Must(uuid.NewV4())
+	a := NewAdmin(tokensign.HMAC(key), []uuid.UUID{admin}, nil, nil, nil)
+
+	ctx := ctxAuth(jwtFor(t, admin.String(), key, time.Hour))
+	if _, err := a.SetDiagnostics(ctx, diagReq(true)); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("want FailedPrecondition, got %v", err)
+	}
+}
+
+type fakeRollback struct {
+	user, by uuid.UUID
+	to       time.Time
+	dry      bool
+	err      error
+}
+
+func (f *fakeRollback) RollbackUser(_ context.Context, userID uuid.UUID, to time.Time, by uuid.UUID, dryRun bool) (model.RollbackResult, error) {
+	f.user, f.to, f.by, f.dry = userID, to, by, dryRun
+	return model.RollbackResult{ID: 9, Restored: 2, Removed: 1, Discarded: 5, Skipped: 1}, f.err
+}
+
+func TestAdmin_RollbackUser(t *testing.T) {
+	t.Parallel()
+	key := []byte("k")
+	admin, victim := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	rb := &fakeRollback{}
+	a := NewAdmin(tokensign.HMAC(key), []uuid.UUID{admin}, nil, rb, nil)
+	ctx := ctxAuth(jwtFor(t, admin.String(), key, time.Hour))
+	req := func(user string, to time.Time) *pb.RollbackUserRequest {
+		r := &pb.RollbackUserRequest{}
+		r.SetUserId(user)
+		if !to.IsZero() {
+			r.SetToTime(timestamppb.New(to))
+		}
+		return r
+	}
+	to := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+
+	for _, r := range []*pb.RollbackUserRequest{
+		req("nope", to), req(victim.String(), time.Time{}), req(victim.String(), time.Now().Add(time.Hour)),
+	} {
+		if _, err := validated(a.RollbackUser)(ctx, r); status.Code(err) != codes.InvalidArgument {
+			t.Fatalf("want InvalidArgument, got %v", err)
+		}
+	}
+
+	resp, err := a.RollbackUser(ctx, req(victim.String(), to))
+	if err != nil || resp.GetRollbackId() != 9 || resp.GetDiscardedVersions() != 5 || resp.GetSkipped() != 1 {
+		t.Fatalf("resp=%v err=%v", resp, err)
+	}
+	if rb.user != victim || rb.by != admin || !rb.to.Equal(to) {
+		t.Fatalf("called with %+v", rb)
+	}
+
+	rb.err = errs.ErrNotFound
+	if _, err := a.RollbackUser(ctx, req(victim.String(), to)); status.Code(err) != codes.NotFound {
+		t.Fatalf("want NotFound, got %v", err)
+	}
+	other := ctxAuth(jwtFor(t, victim.String(), key, time.Hour))
+	if _, err := a.RollbackUser(other, req(victim.String(), to)); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("want PermissionDenied, got %v", err)
+	}
+	none := NewAdmin(tokensign.HMAC(key), []uuid.UUID{admin}, nil, nil, nil)
+	if _, err := none.RollbackUser(ctx, req(victim.String(), to)); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("want FailedPrecondition, got %v", err)
+	}
+}
+
+// fakeConsistency serves one item whose journal misses its latest write.
+type fakeConsistency struct {
+	item     uuid.UUID
+	recorded bool
+}
+
+var _ repository.ConsistencyRepository = (*fakeConsistency)(nil)
+
+func (f *fakeConsistency) Trace(context.Context, uuid.UUID) (model.VaultTrace, error) {
+	it := model.ItemTrace{ID: f.item, Ver: 2, BlobLen: 1, History: []model.TraceVersion{{Ver: 1}, {Ver: 2}},
+		Journal: []model.TraceEntry{{Seq: 1, Op: "upsert", Ver: 1}}}
+	if f.recorded {
+		it.Journal = append(it.Journal, model.TraceEntry{Seq: 2, Op: "upsert", Ver: 2})
+	}
+	return model.VaultTrace{Items: []model.ItemTrace{it}}, nil
+}
+
+func (f *fakeConsistency) RecordHistory(context.Context, uuid.UUID, uuid.UUID) error { return nil }
+
+func (f *fakeConsistency) RecordJournal(_ context.Context, _, item uuid.UUID) error {
+	f.recorded = item == f.item
+	return nil
+}
+
+func TestAdmin_CheckUserConsistency(t *testing.T) {
+	t.Parallel()
+	key := []byte("k")
+	admin, victim := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	a := NewAdmin(tokensign.HMAC(key), []uuid.UUID{admin}, nil, nil, nil)
+	ctx := ctxAuth(jwtFor(t, admin.String(), key, time.Hour))
+	req := func(user string, repair bool) *pb.CheckUserConsistencyRequest {
+		r := &pb.CheckUserConsistencyRequest{}
+		r.SetUserId(user)
+		r.SetRepair(repair)
+		return r
+	}
+
+	if _, err := a.CheckUserConsistency(ctx, req(victim.String(), false)); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("want FailedPrecondition, got %v", err)
+	}
+	f := &fakeConsistency{item: uuid.Must(uuid.NewV4())}
+	a.SetConsistency(f)
+	if _, err := validated(a.CheckUserConsistency)(ctx, req("nope", false)); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("want InvalidArgument, got %v", err)
+	}
+	other := ctxAuth(jwtFor(t, victim.String(), key, time.Hour))
+	if _, err := a.CheckUserConsistency(other, req(victim.String(), false)); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("want PermissionDenied, got %v", err)
+	}
+
+	resp, err := a.CheckUserConsistency(ctx, req(victim.String(), false))
+	if err != nil || resp.GetItemsChecked() != 1 || len(resp.GetViolations()) != 1 {
+		t.Fatalf("check: resp=%v err=%v", resp, err)
+	}
+	if v := resp.GetViolations()[0]; v.GetItemId() != f.item.String() || v.GetCheck() != "journal_head" || v.GetRepaired() || f.recorded {
+		t.Fatalf("violation %v, recorded %v", v, f.recorded)
+	}
+	resp, err = a.CheckUserConsistency(ctx, req(victim.String(), true))
+	if err != nil || !resp.GetViolations()[0].GetRepaired() || !f.recorded {
+		t.Fatalf("repair: resp=%v err=%v", resp, err)
+	}
+	if resp, err = a.CheckUserConsistency(ctx, req(victim.String(), false)); err != nil || len(resp.GetViolations()) != 0 {
+		t.Fatalf("after repair: resp=%v err=%v", resp, err)
+	}
+}
+
+// fakeSuspender records suspensions of known users.
+type fakeSuspender map[uuid.UUID]bool
+
+func (f fakeSuspender) SetDisabled(_ context.Context, id uuid.UUID, disabled bool) error {
+	if _, ok := f[id]; !ok {
+		return errs.ErrNotFound
+	}
+	f[id] = disabled
+	return nil
+}
+
+func TestAdmin_SuspendUser(t *testing.T) {
+	t.Parallel()
+	key := []byte("k")
+	admin, user := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	a := NewAdmin(tokensign.HMAC(key), []uuid.UUID{admin}, nil, nil, nil)
+	ctx := ctxAuth(jwtFor(t, admin.String(), key, time.Hour))
+	suspend := func(id string) *pb.SuspendUserRequest {
+		r := &pb.SuspendUserRequest{}
+		r.SetUserId(id)
+		return r
+	}
+	reactivate := &pb.ReactivateUserRequest{}
+	reactivate.SetUserId(user.String())
+
+	if _, err := a.SuspendUser(ctx, suspend(user.String())); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("want FailedPrecondition, got %v", err)
+	}
+	f := fakeSuspender{user: false}
+	a.SetSuspender(f)
+	if _, err := validated(a.SuspendUser)(ctx, suspend("nope")); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("want InvalidArgument, got %v", err)
+	}
+	other := ctxAuth(jwtFor(t, user.String(), key, time.Hour))
+	if _, err := a.SuspendUser(other, suspend(user.String())); status.Code(err) != codes.PermissionDenied || f[user] {
+		t.Fatalf("want PermissionDenied, got %v", err)
+	}
+
+	if _, err := a.SuspendUser(ctx, suspend(user.String())); err != nil || !f[user] {
+		t.Fatalf("suspend: %v", err)
+	}
+	if _, err := a.ReactivateUser(ctx, reactivate); err != nil || f[user] {
+		t.Fatalf("reactivate: %v", err)
+	}
+	if _, err := a.SuspendUser(ctx, suspend(admin.String())); status.Code(err) != codes.NotFound {
+		t.Fatalf("want NotFound, got %v", err)
+	}
+}
+
+// fakeUsage serves a fixed report and records the query.
+type fakeUsage struct {
+	rep         model.UsageReport
+	tenant      string
+	limit, days int
+}
+
+var _ repository.UsageRepository = (*fakeUsage)(nil)
+
+func (f *fakeUsage) RollupUsage(context.Context, time.Time, int) (int64, error) { return 0, nil }
+
+func (f *fakeUsage) UsageReport(_ context.Context, tenant string, limit, days int) (model.UsageReport, error) {
+	f.tenant, f.limit, f.days = tenant, limit, days
+	return f.rep, nil
+}
+
+func TestAdmin_GetUsageReport(t *testing.T) {
+	t.Parallel()
+	key := []byte("k")
+	admin, user := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	a := NewAdmin(tokensign.HMAC(key), []uuid.UUID{admin}, nil, nil, nil)
+	ctx := ctxAuth(jwtFor(t, admin.String(), key, time.Hour))
+	req := func(tenant string, limit, days int32) *pb.GetUsageReportRequest {
+		r := &pb.GetUsageReportRequest{}
+		r.SetTenant(tenant)
+		r.SetLimit(limit)
+		r.SetDays(days)
+		return r
+	}
+
+	if _, err := a.GetUsageReport(ctx, req("", 0, 0)); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("want FailedPrecondition, got %v", err)
+	}
+	day := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	f := &fakeUsage{rep: model.UsageReport{AsOf: day.Add(time.Hour), Users: []model.UserUsage{{
+		UserID: user, Tenant: "acme", Items: 3, Bytes: 300, HistoryBytes: 900, LastWrite: day,
+		History: []model.UsageSample{{Day: day, Items: 3, Bytes: 300, HistoryBytes: 900}},
+	}}}}
+	a.SetUsage(f)
+	for _, r := range []*pb.GetUsageReportRequest{req("", -1, 0), req("", 1001, 0), req("", 0, 367)} {
+		if _, err := validated(a.GetUsageReport)(ctx, r); status.Code(err) != codes.InvalidArgument {
+			t.Fatalf("%v: want InvalidArgument, got %v", r, err)
+		}
+	}
+	other := ctxAuth(jwtFor(t, user.String(), key, time.Hour))
+	if _, err := a.GetUsageReport(other, req("", 0, 0)); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("want PermissionDenied, got %v", err)
+	}
+
+	resp, err := a.GetUsageReport(ctx, req("acme", 0, 30))
+	if err != nil || f.tenant != "acme" || f.limit != defaultUsageUsers || f.days != 30 {
+		t.Fatalf("resp=%v err=%v query=%+v", resp, err, f)
+	}
+	if !resp.GetAsOf().AsTime().Equal(day.Add(time.Hour)) || len(resp.GetUsers()) != 1 {
+		t.Fatalf("resp %v", resp)
+	}
+	u := resp.GetUsers()[0]
+	if u.GetUserId() != user.String() || u.GetBytes() != 300 || u.GetHistoryBytes() != 900 || u.HasLastLogin() ||
+		len(u.GetHistory()) != 1 || !u.GetHistory()[0].GetDay().AsTime().Equal(day) {
+		t.Fatalf("user %v", u)
+	}
+
+	f.rep = model.UsageReport{}
+	if resp, err = a.GetUsageReport(ctx, req("", 5, 0)); err != nil || resp.HasAsOf() || f.limit != 5 {
+		t.Fatalf("before any rollup: resp=%v err=%v", resp, err)
+	}
+}
+
+func TestAdmin_LoginLocks(t *testing.T) {
+	t.Parallel()
+	key := []byte("k")
+	admin := uuid.Must(uuid.NewV4())
+	lim := limiter.NewMemory(time.Minute, 1, time.Hour)
+	ip := limiter.HashIP("10.0.0.1")
+	_, _, _ = lim.Failure(context.Background(), "bob", ip)
+	acme := tenant.WithID(context.Background(), "acme")
+	_, _, _ = lim.Failure(acme, tenant.Scope(acme, "bob"), ip)
+	a := NewAdmin(tokensign.HMAC(key), []uuid.UUID{admin}, nil, nil, lim)
+	ctx := ctxAuth(jwtFor(t, admin.String(), key, time.Hour))
+
+	resp, err := a.ListLoginLocks(ctx, &pb.ListLoginLocksRequest{})
+	if err != nil || len(resp.GetLocks()) != 2 {
+		t.Fatalf("list: %v %v", resp, err)
+	}
+	tenants := map[string]bool{}
+	for _, l := range resp.GetLocks() {
+		if l.GetUsername() != "bob" || l.GetFailedAttempts() != 1 || !l.GetBlockedUntil().AsTime().After(time.Now()) {
+			t.Fatalf("lock: %v", l)
+		}
+		tenants[l.GetTenant()] = true
+	}
+	if !tenants[tenant.Default] || !tenants["acme"] {
+		t.Fatalf("tenants: %v", tenants)
+	}
+
+	if _, err := validated(a.ClearLoginLocks)(ctx, &pb.ClearLoginLocksRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("want InvalidArgument, got %v", err)
+	}
+	req := &pb.ClearLoginLocksRequest{}
+	req.SetTenant("acme")
+	req.SetUsername("bob")
+	if out, err := a.ClearLoginLocks(ctx, req); err != nil || out.GetCleared() != 1 {
+		t.Fatalf("clear: %v %v", out, err)
+	}
+	if ok, _, _ := lim.Allow(ctx, "bob", ip); ok {
+		t.Fatal("clearing acme's bob unlocked the default tenant's")
+	}
+
+	other := ctxAuth(jwtFor(t, uuid.Must(uuid.NewV4()).String(), key, time.Hour))
+	if _, err := a.ListLoginLocks(other, &pb.ListLoginLocksRequest{}); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("want PermissionDenied, got %v", err)
+	}
+	none := NewAdmin(tokensign.HMAC(key), []uuid.UUID{admin}, nil, nil, nil)
+	if _, err := none.ListLoginLocks(ctx, &pb.ListLoginLocksRequest{}); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("want FailedPrecondition, got %v", err)
+	}
+}
+
+// fakeIPRules keeps rules by tenant and username.
+type fakeIPRules map[string][]model.IPRule
+
+var _ repository.IPRuleRepository = fakeIPRules{}
+
+func (f fakeIPRules) Rules(context.Context, uuid.UUID) ([]model.IPRule, error) { return nil, nil }
+func (f fakeIPRules) RulesByUsername(ctx context.Context, name string) ([]model.IPRule, error) {
+	return f[tenant.Scope(ctx, name)], nil
+}
+func (f fakeIPRules) Add(ctx context.Context, name string, r *model.IPRule, max int) error {
+	if name == "ghost" {
+		return errs.ErrNotFound
+	}
+	key := tenant.Scope(ctx, name)
+	if len(f[key]) >= max {
+		return errs.ErrQuotaExceeded
+	}
+	r.CreatedAt = time.Now()
+	f[key] = append(f[key], *r)
+	return nil
+}
+func (f fakeIPRules) Remove(ctx context.Context, name string, cidr netip.Prefix) error {
+	key := tenant.Scope(ctx, name)
+	for i, r := range f[key] {
+		if r.CIDR == cidr {
+			f[key] = append(f[key][:i], f[key][i+1:]...)
+			return nil
+		}
+	}
+	return errs.ErrNotFound
+}
+
+func TestAdmin_IPRules(t *testing.T) {
+	t.Parallel()
+	key := []byte("k")
+	admin := uuid.Must(uuid.NewV4())
+	rules := fakeIPRules{}
+	a := NewAdmin(tokensign.HMAC(key), []uuid.UUID{admin}, nil, nil, nil)
+	a.SetIPRules(rules, 1)
+	ctx := ctxAuth(jwtFor(t, admin.String(), key, time.Hour))
+	add := func(user, cidr string) *pb.AddUserIPRuleRequest {
+		r := &pb.AddUserIPRuleRequest{}
+		r.SetTenant("acme")
+		r.SetUsername(user)
+		r.SetCidr(cidr)
+		r.SetAllow(true)
+		return r
+	}
+
+	resp, err := a.AddUserIPRule(ctx, add("alice", "10.8.1.1/16"))
+	if err != nil || resp.GetRule().GetCidr() != "10.8.0.0/16" || !resp.GetRule().GetAllow() {
+		t.Fatalf("add: %v %v", resp, err)
+	}
+	for _, c := range []struct {
+		req  *pb.AddUserIPRuleRequest
+		code codes.Code
+	}{
+		{add("", "10.0.0.0/8"), codes.InvalidArgument},
+		{add("alice", "10.0.0.0/33"), codes.InvalidArgument},
+		{add("ghost", "10.0.0.0/8"), codes.NotFound},
+		{add("alice", "192.0.2.0/24"), codes.ResourceExhausted},
+	} {
+		if _, err := validated(a.AddUserIPRule)(ctx, c.req); status.Code(err) != c.code {
+			t.Fatalf("%v: want %v, got %v", c.req, c.code, err)
+		}
+	}
+
+	list := &pb.ListUserIPRulesRequest{}
+	list.SetTenant("acme")
+	list.SetUsername("alice")
+	got, err := a.ListUserIPRules(ctx, list)
+	if err != nil || len(got.GetRules()) != 1 {
+		t.Fatalf("list: %v %v", got, err)
+	}
+	list.SetTenant("")
+	if got, _ := a.ListUserIPRules(ctx, list); len(got.GetRules()) != 0 {
+		t.Fatalf("another tenant's alice: %v", got)
+	}
+
+	rm := &pb.RemoveUserIPRuleRequest{}
+	rm.SetTenant("acme")
+	rm.SetUsername("alice")
+	rm.SetCidr("10.8.0.0/16")
+	if _, err := a.RemoveUserIPRule(ctx, rm); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.RemoveUserIPRule(ctx, rm); status.Code(err) != codes.NotFound {
+		t.Fatalf("want NotFound, got %v", err)
+	}
+
+	none := NewAdmin(tokensign.HMAC(key), []uuid.UUID{admin}, nil, nil, nil)
+	if _, err := none.ListUserIPRules(ctx, list); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("want FailedPrecondition, got %v", err)
+	}
+}
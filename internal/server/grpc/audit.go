@@ -0,0 +1,150 @@
+package grpcserver
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/internal/auditlog"
+	"github.com/and161185/goph-keeper/internal/tenant"
+	"github.com/and161185/goph-keeper/internal/tokensign"
+)
+
+// Auditor records audit events; *auditlog.Log implements it.
+type Auditor interface {
+	Record(ctx context.Context, e auditlog.Event)
+}
+
+// auditedMethods maps the security-relevant RPCs to their audit action.
+// Reads of the change feed and stats are too frequent to be worth it.
+var auditedMethods = map[string]string{
+	pb.GophKeeper_Register_FullMethodName:               "register",
+	pb.GophKeeper_Login_FullMethodName:                  "login",
+	pb.GophKeeper_SetWrappedDEK_FullMethodName:          "dek.set",
+	pb.GophKeeper_UpsertItems_FullMethodName:            "item.upsert",
+	pb.GophKeeper_GetItem_FullMethodName:                "item.read",
+	pb.GophKeeper_GetItemStream_FullMethodName:          "item.read",
+	pb.GophKeeper_ExportItems_FullMethodName:            "item.export",
+	pb.GophKeeper_DeleteItem_FullMethodName:             "item.delete",
+	pb.GophKeeper_DeleteItems_FullMethodName:            "item.delete",
+	pb.GophKeeper_ApplyChangeSet_FullMethodName:         "item.change_set",
+	pb.GophKeeper_CreateWebhook_FullMethodName:          "webhook.create",
+	pb.GophKeeper_DeleteWebhook_FullMethodName:          "webhook.delete",
+	pb.GophKeeper_RegisterDevice_FullMethodName:         "device.register",
+	pb.GophKeeper_UnregisterDevice_FullMethodName:       "device.unregister",
+	pb.GophKeeper_RevokeSession_FullMethodName:          "session.revoke",
+	pb.GophKeeper_SetDuressPassword_FullMethodName:      "duress.set",
+	pb.GophKeeper_RemoveDuressPassword_FullMethodName:   "duress.remove",
+	pb.GophKeeper_FreezeVault_FullMethodName:            "vault.freeze",
+	pb.GophKeeper_UnfreezeVault_FullMethodName:          "vault.unfreeze",
+	pb.AdminService_SetDiagnostics_FullMethodName:       "admin.set_diagnostics",
+	pb.AdminService_RollbackUser_FullMethodName:         "admin.rollback_user",
+	pb.AdminService_CheckUserConsistency_FullMethodName: "admin.check_user",
+	pb.AdminService_SuspendUser_FullMethodName:          "admin.suspend_user",
+	pb.AdminService_ReactivateUser_FullMethodName:       "admin.reactivate_user",
+	pb.AdminService_ClearLoginLocks_FullMethodName:      "admin.clear_login_locks",
+	pb.AdminService_AddUserIPRule_FullMethodName:        "admin.add_ip_rule",
+	pb.AdminService_RemoveUserIPRule_FullMethodName:     "admin.remove_ip_rule",
+}
+
+// AuditUnary returns a unary server interceptor that records the outcome of
+// every audited RPC. It must run inside TenantUnary to see the tenant.
+func AuditUnary(a Auditor, v tokensign.Verifier) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (any, error) {
+		action, ok := auditedMethods[info.FullMethod]
+		if !ok {
+			return next(ctx, req)
+		}
+		resp, err := next(ctx, req)
+		a.Record(ctx, auditEvent(ctx, v, action, req, resp, err))
+		return resp, err
+	}
+}
+
+// AuditStream is AuditUnary for server-streaming RPCs, recording once the
+// stream ends. It must run inside TenantStream.
+func AuditStream(a Auditor, v tokensign.Verifier) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, next grpc.StreamHandler) error {
+		action, ok := auditedMethods[info.FullMethod]
+		if !ok {
+			return next(srv, ss)
+		}
+		rs := &recvStream{ServerStream: ss}
+		err := next(srv, rs)
+		a.Record(ss.Context(), auditEvent(ss.Context(), v, action, rs.req, nil, err))
+		return err
+	}
+}
+
+// recvStream remembers the request a server-streaming handler received.
+type recvStream struct {
+	grpc.ServerStream
+	req any
+}
+
+func (s *recvStream) RecvMsg(m any) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil && s.req == nil {
+		s.req = m
+	}
+	return err
+}
+
+func auditEvent(ctx context.Context, v tokensign.Verifier, action string, req, resp any, err error) auditlog.Event {
+	e := auditlog.Event{
+		Time:    time.Now().UTC(),
+		Tenant:  tenant.FromContext(ctx),
+		Action:  action,
+		Peer:    remoteIP(ctx),
+		Outcome: auditlog.Success,
+		Code:    status.Code(err).String(),
+	}
+	if err != nil {
+		e.Outcome = auditlog.Failure
+	}
+	if id, verr := verifyBearer(ctx, v); verr == nil {
+		e.UserID = id
+	} else if r, ok := resp.(interface{ GetUserId() string }); ok && err == nil {
+		// Register and Login name the user only in the response
+		e.UserID, _ = uuid.FromString(r.GetUserId())
+	}
+	if r, ok := resp.(*pb.LoginResponse); ok && err == nil {
+		e.Flags = r.GetAnomalies()
+	}
+	if r, ok := req.(interface{ GetUsername() string }); ok {
+		e.Subject = r.GetUsername()
+	}
+	switch r := req.(type) {
+	case *pb.GetItemRequest:
+		e.Target = r.GetId()
+	case *pb.DeleteItemRequest:
+		e.Target = r.GetId()
+	case *pb.CreateWebhookRequest:
+		e.Target = r.GetUrl()
+	case *pb.DeleteWebhookRequest:
+		e.Target = r.GetId()
+	case *pb.RegisterDeviceRequest:
+		e.Target = r.GetPlatform() // the token itself is a credential
+	case *pb.UnregisterDeviceRequest:
+		e.Target = r.GetId()
+	case *pb.RevokeSessionRequest:
+		e.Target = r.GetId()
+	case *pb.RollbackUserRequest:
+		e.Target = r.GetUserId()
+	case *pb.CheckUserConsistencyRequest:
+		e.Target = r.GetUserId()
+	case *pb.SuspendUserRequest:
+		e.Target = r.GetUserId()
+	case *pb.ReactivateUserRequest:
+		e.Target = r.GetUserId()
+	case *pb.AddUserIPRuleRequest:
+		e.Target = r.GetCidr()
+	case *pb.RemoveUserIPRuleRequest:
+		e.Target = r.GetCidr()
+	}
+	return e
+}
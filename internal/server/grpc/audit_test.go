@@ -0,0 +1,80 @@
+package grpcserver
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/internal/auditlog"
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/tenant"
+	"github.com/and161185/goph-keeper/internal/tokensign"
+)
+
+type recordedEvents []auditlog.Event
+
+func (r *recordedEvents) Record(_ context.Context, e auditlog.Event) { *r = append(*r, e) }
+
+func TestAuditUnary(t *testing.T) {
+	t.Parallel()
+	key := []byte("k")
+	ic := func(rec *recordedEvents) grpc.UnaryServerInterceptor { return AuditUnary(rec, tokensign.HMAC(key)) }
+	base := tenant.WithID(peer.NewContext(context.Background(), &peer.Peer{Addr: fakeAddr{}}), "acme")
+	info := func(m string) *grpc.UnaryServerInfo { return &grpc.UnaryServerInfo{FullMethod: m} }
+	uid := uuid.Must(uuid.NewV4())
+
+	var rec recordedEvents
+	// failed login: only the username is known
+	login := &pb.LoginRequest{}
+	login.SetUsername("eve")
+	_, _ = ic(&rec)(base, login, info(pb.GophKeeper_Login_FullMethodName), func(context.Context, any) (any, error) {
+		return nil, statusError(codes.Unauthenticated, errs.ReasonBadCredentials, "bad credentials")
+	})
+	// successful login: the user id comes from the response
+	_, _ = ic(&rec)(base, login, info(pb.GophKeeper_Login_FullMethodName), func(context.Context, any) (any, error) {
+		r := &pb.LoginResponse{}
+		r.SetUserId(uid.String())
+		r.SetAnomalies([]string{"new_country"})
+		return r, nil
+	})
+	// item read with a token
+	tok := makeJWT(t, uid.String(), key, jwt.SigningMethodHS256, time.Now(), time.Minute)
+	get := &pb.GetItemRequest{}
+	get.SetId("item-1")
+	ctx := tenant.WithID(peer.NewContext(ctxWithAuth(tok), &peer.Peer{Addr: fakeAddr{}}), "acme")
+	_, _ = ic(&rec)(ctx, get, info(pb.GophKeeper_GetItem_FullMethodName), func(context.Context, any) (any, error) {
+		return &pb.GetItemResponse{}, nil
+	})
+	// not audited
+	_, _ = ic(&rec)(ctx, &pb.GetChangesRequest{}, info(pb.GophKeeper_GetChanges_FullMethodName), func(context.Context, any) (any, error) {
+		return &pb.GetChangesResponse{}, nil
+	})
+
+	want := []auditlog.Event{
+		{Tenant: "acme", Action: "login", Subject: "eve", Peer: "127.0.0.1:12345", Outcome: auditlog.Failure, Code: "Unauthenticated"},
+		{Tenant: "acme", Action: "login", UserID: uid, Subject: "eve", Peer: "127.0.0.1:12345", Outcome: auditlog.Success, Code: "OK",
+			Flags: []string{"new_country"}},
+		{Tenant: "acme", Action: "item.read", UserID: uid, Target: "item-1", Peer: "127.0.0.1:12345", Outcome: auditlog.Success, Code: "OK"},
+	}
+	if len(rec) != len(want) {
+		t.Fatalf("recorded %d events, want %d: %+v", len(rec), len(want), rec)
+	}
+	for i := range want {
+		got := rec[i]
+		if got.Time.IsZero() {
+			t.Fatalf("event %d without time", i)
+		}
+		got.Time = time.Time{}
+		if !reflect.DeepEqual(got, want[i]) {
+			t.Fatalf("event %d:\n got %+v\nwant %+v", i, got, want[i])
+		}
+	}
+}
@@ -4,11 +4,17 @@ import (
 	"context"
 
 	"github.com/gofrs/uuid/v5"
+	"google.golang.org/grpc"
+
+	"github.com/and161185/goph-keeper/internal/tokensign"
 )
 
 type ctxKey string
 
-const userIDKey ctxKey = "gk.userID"
+const (
+	userIDKey ctxKey = "gk.userID"
+	claimsKey ctxKey = "gk.claims"
+)
 
 // WithUserID stores authenticated user ID in context.
 func WithUserID(ctx context.Context, id uuid.UUID) context.Context {
@@ -24,3 +30,43 @@ func UserIDFromCtx(ctx context.Context) (uuid.UUID, bool) {
 	id, ok := v.(uuid.UUID)
 	return id, ok
 }
+
+func claimsFromCtx(ctx context.Context) (*tokensign.Claims, bool) {
+	c, ok := ctx.Value(claimsKey).(*tokensign.Claims)
+	return c, ok
+}
+
+// AuthUnary returns a unary server interceptor that verifies the bearer JWT
+// once per call and stores its claims and user ID in the context, where the
+// handlers and the interceptors after it read them. Calls without a valid
+// token go on unchanged: Register and Login need none, and the other
+// handlers refuse them. It belongs ahead of every interceptor that looks
+// at the caller.
+func AuthUnary(v tokensign.Verifier) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, next grpc.UnaryHandler) (any, error) {
+		return next(authenticate(ctx, v), req)
+	}
+}
+
+// AuthStream is AuthUnary for streaming RPCs.
+func AuthStream(v tokensign.Verifier) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, next grpc.StreamHandler) error {
+		ctx := authenticate(ss.Context(), v)
+		if ctx == ss.Context() {
+			return next(srv, ss)
+		}
+		return next(srv, &ctxStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func authenticate(ctx context.Context, v tokensign.Verifier) context.Context {
+	claims, err := bearerClaims(ctx, v)
+	if err != nil {
+		return ctx
+	}
+	id, err := uuid.FromString(claims.Subject)
+	if err != nil {
+		return ctx
+	}
+	return WithUserID(context.WithValue(ctx, claimsKey, claims), id)
+}
@@ -3,8 +3,12 @@ package grpcserver
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/gofrs/uuid/v5"
+	"google.golang.org/grpc"
+
+	"github.com/and161185/goph-keeper/internal/tokensign"
 )
 
 func TestWithUserID_And_UserIDFromCtx(t *testing.T) {
@@ -32,3 +36,47 @@ func TestWithUserID_And_UserIDFromCtx(t *testing.T) {
 		t.Fatalf("expected miss on wrong typed value")
 	}
 }
+
+func TestAuthUnary(t *testing.T) {
+	t.Parallel()
+
+	key := []byte("k")
+	icpt := AuthUnary(tokensign.HMAC(key))
+	seen := func(ctx context.Context) (uuid.UUID, bool) {
+		t.Helper()
+		var (
+			id uuid.UUID
+			ok bool
+		)
+		_, err := icpt(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/m"}, func(ctx context.Context, _ any) (any, error) {
+			id, ok = UserIDFromCtx(ctx)
+			return nil, nil
+		})
+		if err != nil {
+			t.Fatalf("interceptor refused the call: %v", err)
+		}
+		return id, ok
+	}
+
+	want := uuid.Must(uuid.NewV4())
+	if id, ok := seen(ctxAuth(jwtFor(t, want.String(), key, time.Hour))); !ok || id != want {
+		t.Fatalf("valid token: got %s, %v", id, ok)
+	}
+	for name, ctx := range map[string]context.Context{
+		"no token":      context.Background(),
+		"wrong key":     ctxAuth(jwtFor(t, want.String(), []byte("other"), time.Hour)),
+		"expired":       ctxAuth(jwtFor(t, want.String(), key, -time.Hour)),
+		"bad subject":   ctxAuth(jwtFor(t, "not-a-uuid", key, time.Hour)),
+		"garbage token": ctxAuth("x.y.z"),
+	} {
+		if _, ok := seen(ctx); ok {
+			t.Fatalf("%s: user id stored", name)
+		}
+	}
+
+	// what AuthUnary stored is not verified again
+	ctx := WithUserID(context.Background(), want)
+	if id, err := verifyBearer(ctx, tokensign.HMAC(key)); err != nil || id != want {
+		t.Fatalf("verifyBearer: %s, %v", id, err)
+	}
+}
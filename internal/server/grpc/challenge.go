@@ -0,0 +1,65 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"google.golang.org/grpc/codes"
+
+	"github.com/and161185/goph-keeper/internal/challenge"
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/tenant"
+)
+
+// FailedLogins counts an account's wrong passwords since its last login;
+// *limiter.PG and *limiter.Memory implement it.
+type FailedLogins interface {
+	Unseen(ctx context.Context, username string) (int, error)
+}
+
+// SetChallenge makes Register always, and Login once an account has had
+// loginAfter wrong passwords since its last login, answer a challenge
+// first. With fails nil only Register is challenged.
+func (s *Server) SetChallenge(v challenge.Verifier, fails FailedLogins, loginAfter int) {
+	s.chal, s.chalFails, s.chalAfter = v, fails, loginAfter
+}
+
+// loginChallenged reports whether a login to username must answer a challenge.
+func (s *Server) loginChallenged(ctx context.Context, username string) (bool, error) {
+	if s.chal == nil || s.chalFails == nil {
+		return false, nil
+	}
+	n, err := s.chalFails.Unseen(ctx, tenant.Scope(ctx, username))
+	return n >= s.chalAfter, err
+}
+
+// checkChallenge returns nil if answer answers a challenge, otherwise the
+// CHALLENGE_REQUIRED error carrying a new one.
+func (s *Server) checkChallenge(ctx context.Context, answer string) error {
+	if answer != "" {
+		err := s.chal.Verify(ctx, answer, remoteIP(ctx))
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, challenge.ErrFailed) {
+			return toStatus("verify challenge", err)
+		}
+	}
+	c, err := s.chal.Issue()
+	if err != nil {
+		return toStatus("issue challenge", err)
+	}
+	msg := "challenge required"
+	if answer != "" {
+		msg = "challenge failed"
+	}
+	meta := []string{"kind", c.Kind}
+	if c.Token != "" {
+		meta = append(meta, "token", c.Token, "difficulty", strconv.Itoa(c.Difficulty))
+	}
+	if c.SiteKey != "" {
+		meta = append(meta, "site_key", c.SiteKey)
+	}
+	return statusError(codes.Unauthenticated, errs.ReasonChallengeRequired, msg, meta...)
+}
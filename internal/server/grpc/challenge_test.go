@@ -0,0 +1,119 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/internal/challenge"
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/tenant"
+	"github.com/and161185/goph-keeper/internal/tokensign"
+)
+
+type fakeFails struct {
+	n    map[string]int
+	err  error
+	seen string
+}
+
+func (f *fakeFails) Unseen(_ context.Context, username string) (int, error) {
+	f.seen = username
+	return f.n[username], f.err
+}
+
+// brokenVerifier cannot reach its CAPTCHA provider.
+type brokenVerifier struct{}
+
+func (brokenVerifier) Issue() (challenge.Challenge, error) {
+	return challenge.Challenge{Kind: challenge.KindTurnstile, SiteKey: "site"}, nil
+}
+func (brokenVerifier) Verify(context.Context, string, string) error {
+	return errors.New("siteverify: 502 Bad Gateway")
+}
+
+// challengeOf returns the challenge a CHALLENGE_REQUIRED error carries.
+func challengeOf(t *testing.T, err error) map[string]string {
+	t.Helper()
+	st, _ := status.FromError(err)
+	info := errInfo(st)
+	if st.Code() != codes.Unauthenticated || info == nil || info.GetReason() != errs.ReasonChallengeRequired {
+		t.Fatalf("want CHALLENGE_REQUIRED, got %v", err)
+	}
+	return info.GetMetadata()
+}
+
+func TestServer_Challenge(t *testing.T) {
+	t.Parallel()
+	pow, err := challenge.NewPoW(8, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	tctx := tenant.WithID(ctx, "acme")
+	fails := &fakeFails{n: map[string]int{tenant.Scope(tctx, "bob"): 3, "alice": 2}}
+	s := New(&fakeAuth{}, &fakeItems{}, tokensign.HMAC([]byte("k")))
+	s.SetChallenge(pow, fails, 3)
+
+	rr := &pb.RegisterRequest{}
+	rr.SetUsername("u")
+	rr.SetPassword("p")
+	_, err = s.Register(ctx, rr)
+	meta := challengeOf(t, err)
+	if meta["kind"] != challenge.KindPoW || meta["token"] == "" || meta["difficulty"] != "8" {
+		t.Fatalf("challenge: %v", meta)
+	}
+	bits, _ := strconv.Atoi(meta["difficulty"])
+	answer := challenge.Solve(meta["token"], bits)
+	rr.SetChallengeResponse(answer)
+	if _, err := s.Register(ctx, rr); err != nil {
+		t.Fatalf("answered: %v", err)
+	}
+	// each answer is good once; the refusal carries a fresh challenge
+	_, err = s.Register(ctx, rr)
+	if meta := challengeOf(t, err); meta["token"] == "" || status.Convert(err).Message() != "challenge failed" {
+		t.Fatalf("replay: %v", err)
+	}
+
+	lr := &pb.LoginRequest{}
+	lr.SetUsername("alice")
+	lr.SetPassword("p")
+	if _, err := s.Login(ctx, lr); err != nil {
+		t.Fatalf("login below the threshold: %v", err)
+	}
+	lr.SetUsername("bob")
+	_, err = s.Login(tctx, lr)
+	meta = challengeOf(t, err)
+	if fails.seen != tenant.Scope(tctx, "bob") {
+		t.Fatalf("failures looked up for %q", fails.seen)
+	}
+	lr.SetChallengeResponse(challenge.Solve(meta["token"], bits))
+	if _, err := s.Login(tctx, lr); err != nil {
+		t.Fatalf("login with an answer: %v", err)
+	}
+
+	fails.err = errors.New("db down")
+	if _, err := s.Login(ctx, lr); status.Code(err) != codes.Internal {
+		t.Fatalf("failure count unavailable: %v", err)
+	}
+
+	// a provider outage is the server's problem, not a wrong answer
+	s.SetChallenge(brokenVerifier{}, nil, 0)
+	rr.SetChallengeResponse("captcha-token")
+	if _, err := s.Register(ctx, rr); status.Code(err) != codes.Internal {
+		t.Fatalf("provider down: %v", err)
+	}
+	rr.SetChallengeResponse("")
+	if meta := challengeOf(t, func() error { _, err := s.Register(ctx, rr); return err }()); meta["site_key"] != "site" || meta["token"] != "" {
+		t.Fatalf("captcha challenge: %v", meta)
+	}
+	if _, err := s.Login(ctx, lr); err != nil {
+		t.Fatalf("login challenged without a failure count: %v", err)
+	}
+}
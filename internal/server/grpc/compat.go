@@ -0,0 +1,91 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/internal/compat"
+	"github.com/and161185/goph-keeper/internal/errs"
+)
+
+// SetServerInfo sets what GetServerInfo reports as the server's version and
+// the oldest client version it accepts.
+func (s *Server) SetServerInfo(version, minClient string) {
+	s.version, s.minClient = version, minClient
+}
+
+// GetServerInfo reports the server's version, features and minimum client
+// version. It needs no token.
+func (s *Server) GetServerInfo(context.Context, *pb.GetServerInfoRequest) (*pb.GetServerInfoResponse, error) {
+	features := append([]string(nil), compat.Core...)
+	if s.hooks != nil {
+		features = append(features, compat.FeatureWebhooks)
+	}
+	if s.devices != nil {
+		features = append(features, compat.FeaturePush)
+	}
+	if s.chal != nil {
+		features = append(features, compat.FeatureChallenge)
+	}
+	if s.accesses != nil {
+		features = append(features, compat.FeatureAccessLog)
+	}
+	out := &pb.GetServerInfoResponse{}
+	out.SetServerVersion(s.version)
+	out.SetFeatures(features)
+	out.SetMinClientVersion(s.minClient)
+	return out, nil
+}
+
+// CompatUnary returns a unary server interceptor refusing clients that name
+// a version older than minClient (empty = any) or rely on a feature outside
+// compat.Core. Clients that send neither are let through, as are calls to
+// GetServerInfo.
+func CompatUnary(minClient string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (any, error) {
+		if info.FullMethod != pb.GophKeeper_GetServerInfo_FullMethodName {
+			if err := checkClient(ctx, minClient); err != nil {
+				return nil, err
+			}
+		}
+		return next(ctx, req)
+	}
+}
+
+// CompatStream is CompatUnary for streaming RPCs.
+func CompatStream(minClient string) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, next grpc.StreamHandler) error {
+		if err := checkClient(ss.Context(), minClient); err != nil {
+			return err
+		}
+		return next(srv, ss)
+	}
+}
+
+func checkClient(ctx context.Context, minClient string) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if v := first(md.Get(compat.VersionKey)); minClient != "" && v != "" && compat.Older(v, minClient) {
+		return statusError(codes.FailedPrecondition, errs.ReasonClientTooOld,
+			fmt.Sprintf("client %s is older than the oldest this server accepts (%s); upgrade it", v, minClient),
+			"min_client_version", minClient)
+	}
+	if missing := compat.Missing(compat.ParseFeatures(first(md.Get(compat.FeaturesKey))), compat.Core); len(missing) > 0 {
+		list := strings.Join(missing, ",")
+		return statusError(codes.FailedPrecondition, errs.ReasonUnsupportedFeature,
+			"this server does not offer "+list, "missing", list)
+	}
+	return nil
+}
+
+func first(vs []string) string {
+	if len(vs) == 0 {
+		return ""
+	}
+	return vs[0]
+}
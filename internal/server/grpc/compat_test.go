@@ -0,0 +1,73 @@
+package grpcserver
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/internal/compat"
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/tokensign"
+)
+
+func TestCompatUnary(t *testing.T) {
+	t.Parallel()
+	icpt := CompatUnary("v1.4.0")
+	next := func(context.Context, any) (any, error) { return "ok", nil }
+	call := func(method string, kv ...string) (string, map[string]string) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(kv...))
+		_, err := icpt(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, next)
+		if err == nil {
+			return "", nil
+		}
+		info := errInfo(status.Convert(err))
+		return info.GetReason(), info.GetMetadata()
+	}
+	const items = pb.GophKeeper_UpsertItems_FullMethodName
+
+	for _, kv := range [][]string{
+		nil,
+		{compat.VersionKey, "v1.4.0"},
+		{compat.VersionKey, "v2.0.0-rc1"},
+		{compat.VersionKey, "dev"},
+		{compat.FeaturesKey, "best_effort,warnings"},
+	} {
+		if reason, _ := call(items, kv...); reason != "" {
+			t.Fatalf("%v refused: %s", kv, reason)
+		}
+	}
+	reason, meta := call(items, compat.VersionKey, "v1.3.9")
+	if reason != errs.ReasonClientTooOld || meta["min_client_version"] != "v1.4.0" {
+		t.Fatalf("old client: %s %v", reason, meta)
+	}
+	if reason, _ := call(pb.GophKeeper_GetServerInfo_FullMethodName, compat.VersionKey, "v1.3.9"); reason != "" {
+		t.Fatalf("GetServerInfo refused: %s", reason)
+	}
+	reason, meta = call(items, compat.FeaturesKey, "warnings,teleport,time_travel")
+	if reason != errs.ReasonUnsupportedFeature || meta["missing"] != "teleport,time_travel" {
+		t.Fatalf("unknown features: %s %v", reason, meta)
+	}
+}
+
+func TestServer_GetServerInfo(t *testing.T) {
+	t.Parallel()
+	s := New(&fakeAuth{}, &fakeItems{}, tokensign.HMAC([]byte("k")))
+	s.SetServerInfo("v1.5.0", "v1.4.0")
+	out, err := s.GetServerInfo(context.Background(), &pb.GetServerInfoRequest{})
+	if err != nil || out.GetServerVersion() != "v1.5.0" || out.GetMinClientVersion() != "v1.4.0" {
+		t.Fatalf("%v %v", out, err)
+	}
+	if !slices.Equal(out.GetFeatures(), compat.Core) {
+		t.Fatalf("features: %v", out.GetFeatures())
+	}
+	s.SetChallenge(brokenVerifier{}, nil, 0)
+	out, _ = s.GetServerInfo(context.Background(), &pb.GetServerInfoRequest{})
+	if !slices.Contains(out.GetFeatures(), compat.FeatureChallenge) {
+		t.Fatalf("challenge not listed: %v", out.GetFeatures())
+	}
+}
@@ -0,0 +1,106 @@
+package grpcserver
+
+import (
+	"context"
+	"slices"
+
+	"github.com/gofrs/uuid/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/and161185/goph-keeper/internal/push"
+	"github.com/and161185/goph-keeper/internal/repository"
+)
+
+// SetDevices enables the device RPCs for the given push platforms, with at
+// most max devices per user. Without it they fail with FailedPrecondition.
+func (s *Server) SetDevices(repo repository.DeviceRepository, platforms []string, max int) {
+	s.devices, s.pushPlatforms, s.maxDevices = repo, platforms, max
+}
+
+func toProtoDevice(d model.Device) *pb.Device {
+	out := &pb.Device{}
+	out.SetId(d.ID.String())
+	out.SetPlatform(d.Platform)
+	out.SetName(d.Name)
+	out.SetCreatedAt(timestamppb.New(d.CreatedAt))
+	return out
+}
+
+// deviceUser authenticates the caller of a device RPC.
+func (s *Server) deviceUser(ctx context.Context) (uuid.UUID, error) {
+	userID, err := s.userIDFromCtx(ctx)
+	if err != nil {
+		return uuid.Nil, statusError(codes.Unauthenticated, errs.ReasonUnauthenticated, "no auth")
+	}
+	if s.devices == nil {
+		return uuid.Nil, statusError(codes.FailedPrecondition, errs.ReasonNotConfigured, "push notifications are disabled on this server")
+	}
+	return userID, nil
+}
+
+// RegisterDevice stores a push target of the caller.
+func (s *Server) RegisterDevice(ctx context.Context, req *pb.RegisterDeviceRequest) (*pb.RegisterDeviceResponse, error) {
+	userID, err := s.deviceUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(s.pushPlatforms, req.GetPlatform()) {
+		return nil, statusError(codes.InvalidArgument, errs.ReasonInvalidArgument, "platform not available: "+req.GetPlatform())
+	}
+	if err := push.CheckToken(req.GetPlatform(), req.GetToken()); err != nil {
+		return nil, statusError(codes.InvalidArgument, errs.ReasonInvalidArgument, err.Error())
+	}
+	d := model.Device{
+		ID:       uuid.Must(uuid.NewV4()),
+		UserID:   userID,
+		Platform: req.GetPlatform(),
+		Token:    req.GetToken(),
+		Name:     req.GetName(),
+	}
+	if err := s.devices.Register(ctx, &d, s.maxDevices); err != nil {
+		return nil, toStatus("register device", err, remap(errs.ErrQuotaExceeded, errs.ReasonDeviceLimit, "too many devices"))
+	}
+	resp := &pb.RegisterDeviceResponse{}
+	resp.SetDevice(toProtoDevice(d))
+	return resp, nil
+}
+
+// ListDevices returns the caller's devices and the platforms on offer.
+func (s *Server) ListDevices(ctx context.Context, _ *pb.ListDevicesRequest) (*pb.ListDevicesResponse, error) {
+	userID, err := s.deviceUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	devs, err := s.devices.List(ctx, userID)
+	if err != nil {
+		return nil, toStatus("list devices", err)
+	}
+	out := make([]*pb.Device, 0, len(devs))
+	for _, d := range devs {
+		out = append(out, toProtoDevice(d))
+	}
+	resp := &pb.ListDevicesResponse{}
+	resp.SetDevices(out)
+	resp.SetPlatforms(s.pushPlatforms)
+	return resp, nil
+}
+
+// UnregisterDevice removes one of the caller's devices.
+func (s *Server) UnregisterDevice(ctx context.Context, req *pb.UnregisterDeviceRequest) (*pb.UnregisterDeviceResponse, error) {
+	userID, err := s.deviceUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	id, err := uuid.FromString(req.GetId())
+	if err != nil {
+		return nil, statusError(codes.InvalidArgument, errs.ReasonInvalidArgument, "bad id")
+	}
+	if err := s.devices.Delete(ctx, userID, id); err != nil {
+		return nil, toStatus("unregister device", err)
+	}
+	return &pb.UnregisterDeviceResponse{}, nil
+}
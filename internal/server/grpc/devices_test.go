@@ -0,0 +1,97 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/and161185/goph-keeper/internal/tokensign"
+)
+
+type fakeDevices struct{ devs []model.Device }
+
+func (f *fakeDevices) Register(_ context.Context, d *model.Device, max int) error {
+	if len(f.devs) >= max {
+		return fmt.Errorf("%w: limit", errs.ErrQuotaExceeded)
+	}
+	d.CreatedAt = time.Now()
+	f.devs = append(f.devs, *d)
+	return nil
+}
+
+func (f *fakeDevices) List(_ context.Context, userID uuid.UUID) ([]model.Device, error) {
+	var out []model.Device
+	for _, d := range f.devs {
+		if d.UserID == userID {
+			out = append(out, d)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeDevices) Delete(_ context.Context, userID, id uuid.UUID) error {
+	for i, d := range f.devs {
+		if d.ID == id && d.UserID == userID {
+			f.devs = append(f.devs[:i], f.devs[i+1:]...)
+			return nil
+		}
+	}
+	return errs.ErrNotFound
+}
+
+func (f *fakeDevices) DeleteToken(context.Context, string, string) error { return nil }
+
+func TestDeviceRPCs(t *testing.T) {
+	key := []byte("secret")
+	s := New(nil, &fakeItems{}, tokensign.HMAC(key))
+	ctx := ctxAuth(jwtFor(t, uuid.Must(uuid.NewV4()).String(), key, time.Hour))
+	register := func(platform, token string) (*pb.RegisterDeviceResponse, error) {
+		req := &pb.RegisterDeviceRequest{}
+		req.SetPlatform(platform)
+		req.SetToken(token)
+		req.SetName("laptop")
+		return s.RegisterDevice(ctx, req)
+	}
+
+	if _, err := register("ntfy", "gk-0123456789abcdef"); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("disabled: %v", err)
+	}
+	store := &fakeDevices{}
+	s.SetDevices(store, []string{"ntfy"}, 1)
+
+	if _, err := register("fcm", "reg-token"); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("unconfigured platform: %v", err)
+	}
+	if _, err := register("ntfy", "guessable"); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("short topic: %v", err)
+	}
+	resp, err := register("ntfy", "gk-0123456789abcdef")
+	if err != nil || resp.GetDevice().GetPlatform() != "ntfy" || resp.GetDevice().GetName() != "laptop" {
+		t.Fatalf("register: %v %v", resp, err)
+	}
+	if _, err := register("ntfy", "gk-fedcba9876543210"); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("over limit: %v", err)
+	}
+
+	list, err := s.ListDevices(ctx, &pb.ListDevicesRequest{})
+	if err != nil || len(list.GetDevices()) != 1 || len(list.GetPlatforms()) != 1 {
+		t.Fatalf("list: %v %v", list, err)
+	}
+	other := ctxAuth(jwtFor(t, uuid.Must(uuid.NewV4()).String(), key, time.Hour))
+	del := &pb.UnregisterDeviceRequest{}
+	del.SetId(resp.GetDevice().GetId())
+	if _, err := s.UnregisterDevice(other, del); status.Code(err) != codes.NotFound {
+		t.Fatalf("foreign delete: %v", err)
+	}
+	if _, err := s.UnregisterDevice(ctx, del); err != nil || len(store.devs) != 0 {
+		t.Fatalf("unregister: %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gofrs/uuid/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/tokensign"
+)
+
+// DisabledChecker tells whether an account is suspended;
+// *service.AuthServiceImpl implements it.
+type DisabledChecker interface {
+	Disabled(ctx context.Context, userID uuid.UUID) (bool, error)
+}
+
+// DisabledUnary returns a unary server interceptor refusing every call
+// whose token belongs to a suspended account, so tokens issued before the
+// suspension stop working at once rather than when they expire. Calls
+// without a valid token pass, for the handler to reject; each other call
+// costs one lookup. It must run inside TenantUnary.
+func DisabledUnary(c DisabledChecker, v tokensign.Verifier) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, next grpc.UnaryHandler) (any, error) {
+		if err := checkDisabled(ctx, c, v); err != nil {
+			return nil, err
+		}
+		return next(ctx, req)
+	}
+}
+
+// DisabledStream is DisabledUnary for streaming RPCs.
+func DisabledStream(c DisabledChecker, v tokensign.Verifier) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, next grpc.StreamHandler) error {
+		if err := checkDisabled(ss.Context(), c, v); err != nil {
+			return err
+		}
+		return next(srv, ss)
+	}
+}
+
+func checkDisabled(ctx context.Context, c DisabledChecker, v tokensign.Verifier) error {
+	userID, err := verifyBearer(ctx, v)
+	if err != nil {
+		return nil
+	}
+	disabled, err := c.Disabled(ctx, userID)
+	switch {
+	case errors.Is(err, errs.ErrNotFound):
+		// e.g. a removed duress vault: its tokens name no one
+		return statusError(codes.Unauthenticated, errs.ReasonUnauthenticated, "no auth")
+	case err != nil:
+		return toStatus("disabled check", err)
+	case disabled:
+		return toStatus("disabled check", errs.ErrAccountDisabled)
+	}
+	return nil
+}
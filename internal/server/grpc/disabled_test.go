@@ -0,0 +1,66 @@
+package grpcserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/limiter"
+	"github.com/and161185/goph-keeper/internal/repository/memory"
+	"github.com/and161185/goph-keeper/internal/service"
+	"github.com/and161185/goph-keeper/internal/tokensign"
+)
+
+func TestDisabledUnary(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	key := []byte("k")
+	auth := service.NewAuthService(memory.NewUserRepo(), tokensign.HMAC(key), time.Minute, limiter.NewMemory(time.Minute, 5, time.Minute))
+	u, err := auth.Register(ctx, "alice", "pw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tok, _, err := auth.LoginWithIP(ctx, "alice", "pw", "", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	called := false
+	handler := func(context.Context, any) (any, error) { called = true; return nil, nil }
+	call := func(ctx context.Context) error {
+		called = false
+		_, err := DisabledUnary(auth, tokensign.HMAC(key))(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/m"}, handler)
+		return err
+	}
+
+	if err := call(ctxAuth(tok.AccessToken)); err != nil || !called {
+		t.Fatalf("active account: %v", err)
+	}
+	if err := auth.SetDisabled(ctx, u.ID, true); err != nil {
+		t.Fatal(err)
+	}
+	err = call(ctxAuth(tok.AccessToken))
+	if st := status.Convert(err); called || st.Code() != codes.PermissionDenied || errInfo(st).GetReason() != errs.ReasonAccountDisabled {
+		t.Fatalf("token issued before the suspension: called=%v %v", called, err)
+	}
+	if err := call(ctx); err != nil || !called {
+		t.Fatalf("no token is the handler's to refuse: %v", err)
+	}
+	ghost := ctxAuth(jwtFor(t, uuid.Must(uuid.NewV4()).String(), key, time.Hour))
+	if err := call(ghost); status.Code(err) != codes.Unauthenticated || called {
+		t.Fatalf("token of no user: %v", err)
+	}
+
+	if err := auth.SetDisabled(ctx, u.ID, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := call(ctxAuth(tok.AccessToken)); err != nil || !called {
+		t.Fatalf("reactivated: %v", err)
+	}
+}
@@ -0,0 +1,36 @@
+package grpcserver
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/internal/errs"
+)
+
+// SetDuressPassword gives the caller's account a duress vault.
+func (s *Server) SetDuressPassword(ctx context.Context, req *pb.SetDuressPasswordRequest) (*pb.SetDuressPasswordResponse, error) {
+	userID, err := s.userIDFromCtx(ctx)
+	if err != nil {
+		return nil, statusError(codes.Unauthenticated, errs.ReasonUnauthenticated, "no auth")
+	}
+	if err := s.auth.SetDuress(ctx, userID, req.GetPassword()); err != nil {
+		return nil, toStatus("set duress", err,
+			remap(errs.ErrAlreadyExists, errs.ReasonAlreadyExists, "a duress password is already set"))
+	}
+	return &pb.SetDuressPasswordResponse{}, nil
+}
+
+// RemoveDuressPassword deletes the caller's duress vault.
+func (s *Server) RemoveDuressPassword(ctx context.Context, _ *pb.RemoveDuressPasswordRequest) (*pb.RemoveDuressPasswordResponse, error) {
+	userID, err := s.userIDFromCtx(ctx)
+	if err != nil {
+		return nil, statusError(codes.Unauthenticated, errs.ReasonUnauthenticated, "no auth")
+	}
+	if err := s.auth.RemoveDuress(ctx, userID); err != nil {
+		return nil, toStatus("remove duress", err,
+			remap(errs.ErrNotFound, errs.ReasonNotFound, "no duress password is set"))
+	}
+	return &pb.RemoveDuressPasswordResponse{}, nil
+}
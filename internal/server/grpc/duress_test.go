@@ -0,0 +1,73 @@
+package grpcserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/internal/limiter"
+	"github.com/and161185/goph-keeper/internal/repository/memory"
+	"github.com/and161185/goph-keeper/internal/service"
+	"github.com/and161185/goph-keeper/internal/tokensign"
+)
+
+func TestServer_Duress(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	signer := tokensign.HMAC([]byte("k"))
+	auth := service.NewAuthService(memory.NewUserRepo(), signer, time.Minute, limiter.NewMemory(time.Minute, 5, time.Minute))
+	s := New(auth, &fakeItems{}, signer)
+
+	rr := &pb.RegisterRequest{}
+	rr.SetUsername("alice")
+	rr.SetPassword("pw")
+	if _, err := s.Register(ctx, rr); err != nil {
+		t.Fatal(err)
+	}
+	login := func(password string) (*pb.LoginResponse, error) {
+		lr := &pb.LoginRequest{}
+		lr.SetUsername("alice")
+		lr.SetPassword(password)
+		return s.Login(ctx, lr)
+	}
+	real, err := login("pw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	authed := ctxWithAuth(real.GetAccessToken())
+
+	if _, err := s.SetDuressPassword(ctx, &pb.SetDuressPasswordRequest{}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("no token: %v", err)
+	}
+	set := &pb.SetDuressPasswordRequest{}
+	set.SetPassword("pw")
+	if _, err := s.SetDuressPassword(authed, set); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("account password: %v", err)
+	}
+	set.SetPassword("decoy")
+	if _, err := s.SetDuressPassword(authed, set); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.SetDuressPassword(authed, set); status.Code(err) != codes.AlreadyExists {
+		t.Fatalf("second duress password: %v", err)
+	}
+
+	decoy, err := login("decoy")
+	if err != nil || decoy.GetUserId() == real.GetUserId() || len(decoy.GetWrappedDek()) != 0 {
+		t.Fatalf("duress login: %v %v", decoy, err)
+	}
+
+	if _, err := s.RemoveDuressPassword(authed, &pb.RemoveDuressPasswordRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.RemoveDuressPassword(authed, &pb.RemoveDuressPasswordRequest{}); status.Code(err) != codes.NotFound {
+		t.Fatalf("remove twice: %v", err)
+	}
+	if _, err := login("decoy"); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("duress login after removal: %v", err)
+	}
+}
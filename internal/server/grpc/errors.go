@@ -0,0 +1,168 @@
+package grpcserver
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/and161185/goph-keeper/internal/breaker"
+	"github.com/and161185/goph-keeper/internal/errs"
+)
+
+// defaultRetryAfter is the hint sent when the outage error carries none.
+const defaultRetryAfter = 5 * time.Second
+
+// statusError builds a status with a fixed, client-safe message and a
+// google.rpc.ErrorInfo carrying reason. meta is key/value pairs.
+func statusError(c codes.Code, reason, msg string, meta ...string) error {
+	return withDetails(status.New(c, msg), errorInfo(reason, meta...)).Err()
+}
+
+func errorInfo(reason string, meta ...string) *errdetails.ErrorInfo {
+	info := &errdetails.ErrorInfo{Reason: reason, Domain: errs.Domain}
+	if len(meta) > 1 {
+		info.Metadata = make(map[string]string, len(meta)/2)
+		for i := 0; i+1 < len(meta); i += 2 {
+			info.Metadata[meta[i]] = meta[i+1]
+		}
+	}
+	return info
+}
+
+// withDetails attaches details, keeping st unchanged if they cannot be encoded.
+func withDetails(st *status.Status, details ...protoadapt.MessageV1) *status.Status {
+	if withInfo, err := st.WithDetails(details...); err == nil {
+		return withInfo
+	}
+	return st
+}
+
+// internalErr is an Internal status whose cause stays on the server: the
+// client sees only the incident id, LoggingUnary logs the cause with it.
+type internalErr struct {
+	st       *status.Status
+	op       string
+	incident string
+	cause    error
+}
+
+func (e *internalErr) Error() string              { return e.op + ": " + e.cause.Error() }
+func (e *internalErr) Unwrap() error              { return e.cause }
+func (e *internalErr) GRPCStatus() *status.Status { return e.st }
+
+// mapping is the status an errs sentinel becomes.
+type mapping struct {
+	err    error
+	code   codes.Code
+	reason string
+	msg    string // empty: the error's own text, which errs allows clients to see
+}
+
+// sentinelStatus is how every errs sentinel reaches clients unless a
+// handler remaps it. ErrUnavailable and ErrVersionConflict get extra
+// details; see toStatus.
+var sentinelStatus = []mapping{
+	{errs.ErrInvalidArgument, codes.InvalidArgument, errs.ReasonInvalidArgument, ""},
+	{errs.ErrVersionConflict, codes.FailedPrecondition, errs.ReasonVersionConflict, "version conflict"},
+	{errs.ErrNotFound, codes.NotFound, errs.ReasonNotFound, "not found"},
+	{errs.ErrAlreadyExists, codes.AlreadyExists, errs.ReasonAlreadyExists, "already exists"},
+	{errs.ErrUnauthorized, codes.Unauthenticated, errs.ReasonUnauthenticated, "unauthenticated"},
+	{errs.ErrRateLimited, codes.ResourceExhausted, errs.ReasonRateLimited, "rate limited"},
+	{errs.ErrItemLimit, codes.ResourceExhausted, errs.ReasonItemLimit, "item limit reached"},
+	{errs.ErrQuotaExceeded, codes.ResourceExhausted, errs.ReasonStorageQuota, "storage quota exceeded"},
+	{errs.ErrAddressDenied, codes.PermissionDenied, errs.ReasonAddressDenied, "address not allowed"},
+	{errs.ErrAccountDisabled, codes.PermissionDenied, errs.ReasonAccountDisabled, "account disabled; contact the server's administrator"},
+	{errs.ErrSnapshotGone, codes.FailedPrecondition, errs.ReasonSyncExpired, "sync session expired; call BeginSync again"},
+	{errs.ErrNotConfigured, codes.FailedPrecondition, errs.ReasonNotConfigured, "not configured"},
+	{errs.ErrLegalHold, codes.FailedPrecondition, errs.ReasonLegalHold, "refused: a legal hold keeps the history this would remove"},
+	{errs.ErrUnavailable, codes.Unavailable, errs.ReasonUnavailable, "storage unavailable, retry later"},
+}
+
+// remap gives sentinel another reason and message in one handler, keeping
+// its code.
+func remap(sentinel error, reason, msg string) mapping {
+	for _, m := range sentinelStatus {
+		if m.err == sentinel {
+			return mapping{sentinel, m.code, reason, msg}
+		}
+	}
+	panic("remap: not an errs sentinel")
+}
+
+func (m mapping) status(err error) error {
+	msg := m.msg
+	if msg == "" {
+		msg = err.Error()
+	}
+	return statusError(m.code, m.reason, msg)
+}
+
+// toStatus is the one place service errors become gRPC statuses; every
+// handler returns its errors through it. A sentinel in over maps as given
+// there, any other one as in sentinelStatus. A version conflict carries the
+// stale item (see conflictError) and a storage outage a RetryInfo detail,
+// so clients back off instead of reporting a server bug. Anything else is
+// Internal with a generic message, since driver errors can quote SQL and
+// data; op and the cause are logged with its incident id.
+func toStatus(op string, err error, over ...mapping) error {
+	for _, m := range over {
+		if errors.Is(err, m.err) {
+			return m.status(err)
+		}
+	}
+	switch {
+	case errors.Is(err, errs.ErrVersionConflict):
+		return conflictError(err)
+	case errors.Is(err, errs.ErrUnavailable):
+		wait := defaultRetryAfter
+		var oe *breaker.OpenError
+		if errors.As(err, &oe) && oe.RetryAfter > 0 {
+			wait = oe.RetryAfter
+		}
+		st := status.New(codes.Unavailable, "storage unavailable, retry later")
+		return withDetails(st, errorInfo(errs.ReasonUnavailable), &errdetails.RetryInfo{RetryDelay: durationpb.New(wait)}).Err()
+	}
+	for _, m := range sentinelStatus {
+		if errors.Is(err, m.err) {
+			return m.status(err)
+		}
+	}
+	return internalError(op, err)
+}
+
+// internalError is the Internal status for an unexpected error in op.
+func internalError(op string, err error) error {
+	incident := newIncidentID()
+	st := withDetails(status.New(codes.Internal, "internal error (incident "+incident+")"),
+		errorInfo(errs.ReasonInternal, "op", op, "incident", incident))
+	return &internalErr{st: st, op: op, incident: incident, cause: err}
+}
+
+// conflictError maps a version conflict. When err knows the item's state,
+// the ErrorInfo carries it: "item" (index in the batch), "id",
+// "current_ver" and, for an existing item, "updated_at" (RFC 3339).
+func conflictError(err error) error {
+	var ce *errs.ConflictError
+	if !errors.As(err, &ce) {
+		return statusError(codes.FailedPrecondition, errs.ReasonVersionConflict, "version conflict")
+	}
+	meta := []string{"item", strconv.Itoa(ce.Index), "id", ce.ID.String(), "current_ver", strconv.FormatInt(ce.Ver, 10)}
+	if !ce.UpdatedAt.IsZero() {
+		meta = append(meta, "updated_at", ce.UpdatedAt.UTC().Format(time.RFC3339Nano))
+	}
+	return statusError(codes.FailedPrecondition, errs.ReasonVersionConflict, "version conflict", meta...)
+}
+
+func newIncidentID() string {
+	var b [6]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
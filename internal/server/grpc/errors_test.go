@@ -0,0 +1,148 @@
+package grpcserver
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/and161185/goph-keeper/internal/breaker"
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/gofrs/uuid/v5"
+)
+
+func TestToStatus(t *testing.T) {
+	t.Parallel()
+	cause := errors.New(`ERROR: relation "items" does not exist (SQLSTATE 42P01)`)
+	err := toStatus("stats", cause)
+	st := status.Convert(err)
+	if st.Code() != codes.Internal || strings.Contains(st.Message(), "items") {
+		t.Fatalf("internal details leaked: %v", st)
+	}
+	info := errInfo(st)
+	if info.GetReason() != errs.ReasonInternal || info.GetDomain() != errs.Domain ||
+		info.GetMetadata()["op"] != "stats" || !strings.Contains(st.Message(), info.GetMetadata()["incident"]) {
+		t.Fatalf("error info: %v (message %q)", info, st.Message())
+	}
+	if !errors.Is(err, cause) {
+		t.Fatalf("the cause must stay reachable server-side")
+	}
+
+	st = status.Convert(toStatus("upsert", fmt.Errorf("%w: item[0] empty id", errs.ErrInvalidArgument)))
+	if st.Code() != codes.InvalidArgument || st.Message() != "invalid argument: item[0] empty id" || errInfo(st).GetReason() != errs.ReasonInvalidArgument {
+		t.Fatalf("validation: %v", st)
+	}
+
+	cases := map[error]time.Duration{
+		&breaker.OpenError{RetryAfter: 3 * time.Second}: 3 * time.Second,
+		fmt.Errorf("get: %w", errs.ErrUnavailable):      defaultRetryAfter,
+	}
+	for err, want := range cases {
+		st := status.Convert(toStatus("get", err))
+		if st.Code() != codes.Unavailable {
+			t.Fatalf("%v: code %s", err, st.Code())
+		}
+		var got time.Duration
+		for _, d := range st.Details() {
+			if ri, ok := d.(*errdetails.RetryInfo); ok {
+				got = ri.GetRetryDelay().AsDuration()
+			}
+		}
+		if got != want {
+			t.Fatalf("%v: retry delay %s, want %s", err, got, want)
+		}
+		if errInfo(st).GetReason() != errs.ReasonUnavailable {
+			t.Fatalf("%v: reason %v", err, errInfo(st))
+		}
+	}
+}
+
+func TestToStatus_Sentinels(t *testing.T) {
+	t.Parallel()
+	all := []error{
+		errs.ErrNotFound, errs.ErrVersionConflict, errs.ErrUnauthorized, errs.ErrRateLimited,
+		errs.ErrAlreadyExists, errs.ErrUnavailable, errs.ErrInvalidArgument, errs.ErrQuotaExceeded,
+		errs.ErrItemLimit, errs.ErrAddressDenied, errs.ErrNotConfigured, errs.ErrAccountDisabled,
+		errs.ErrSnapshotGone, errs.ErrLegalHold,
+	}
+	if len(sentinelStatus) != len(all) {
+		t.Fatalf("sentinelStatus has %d entries for %d sentinels", len(sentinelStatus), len(all))
+	}
+	for _, sentinel := range all {
+		var want *mapping
+		for i := range sentinelStatus {
+			if sentinelStatus[i].err == sentinel {
+				want = &sentinelStatus[i]
+			}
+		}
+		if want == nil {
+			t.Fatalf("%v: no mapping", sentinel)
+		}
+		st := status.Convert(toStatus("op", fmt.Errorf("op: %w", sentinel)))
+		if st.Code() != want.code || errInfo(st).GetReason() != want.reason {
+			t.Errorf("%v: got %s %s, want %s %s", sentinel, st.Code(), errInfo(st).GetReason(), want.code, want.reason)
+		}
+		if st.Code() == codes.Internal {
+			t.Errorf("%v: surfaces as Internal", sentinel)
+		}
+	}
+
+	st := status.Convert(toStatus("register", errs.ErrAlreadyExists))
+	if st.Code() != codes.AlreadyExists || errInfo(st).GetReason() != errs.ReasonAlreadyExists {
+		t.Fatalf("already exists: %v", st)
+	}
+
+	over := remap(errs.ErrQuotaExceeded, errs.ReasonDeviceLimit, "too many devices")
+	st = status.Convert(toStatus("register device", fmt.Errorf("x: %w", errs.ErrQuotaExceeded), over))
+	if st.Code() != codes.ResourceExhausted || st.Message() != "too many devices" || errInfo(st).GetReason() != errs.ReasonDeviceLimit {
+		t.Fatalf("remap: %v", st)
+	}
+	st = status.Convert(toStatus("register device", errs.ErrNotFound, over))
+	if st.Code() != codes.NotFound || errInfo(st).GetReason() != errs.ReasonNotFound {
+		t.Fatalf("remap of another sentinel applied: %v", st)
+	}
+}
+
+func TestStatusError_ErrorInfo(t *testing.T) {
+	t.Parallel()
+	st := status.Convert(statusError(codes.FailedPrecondition, errs.ReasonVersionConflict, "version conflict", "id", "x"))
+	info := errInfo(st)
+	if st.Code() != codes.FailedPrecondition || info.GetReason() != errs.ReasonVersionConflict || info.GetMetadata()["id"] != "x" {
+		t.Fatalf("got %v %v", st, info)
+	}
+}
+
+func TestConflictError(t *testing.T) {
+	t.Parallel()
+	id := uuid.Must(uuid.NewV7())
+	at := time.Date(2025, 3, 1, 12, 0, 0, 5, time.FixedZone("", 3600))
+	st := status.Convert(conflictError(fmt.Errorf("upsert: %w", &errs.ConflictError{Index: 2, ID: id, Ver: 7, UpdatedAt: at})))
+	want := map[string]string{"item": "2", "id": id.String(), "current_ver": "7", "updated_at": "2025-03-01T11:00:00.000000005Z"}
+	if info := errInfo(st); st.Code() != codes.FailedPrecondition || info.GetReason() != errs.ReasonVersionConflict || !maps.Equal(info.GetMetadata(), want) {
+		t.Fatalf("got %v %v", st, info)
+	}
+
+	// a missing item has no updated_at; a bare sentinel has no metadata
+	st = status.Convert(conflictError(&errs.ConflictError{ID: id}))
+	if _, ok := errInfo(st).GetMetadata()["updated_at"]; ok || errInfo(st).GetMetadata()["current_ver"] != "0" {
+		t.Fatalf("missing item: %v", errInfo(st))
+	}
+	if st = status.Convert(conflictError(errs.ErrVersionConflict)); st.Code() != codes.FailedPrecondition || len(errInfo(st).GetMetadata()) != 0 {
+		t.Fatalf("sentinel: %v", errInfo(st))
+	}
+}
+
+func errInfo(st *status.Status) *errdetails.ErrorInfo {
+	for _, d := range st.Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok {
+			return info
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package grpcserver
+
+import (
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/internal/convert"
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/model"
+)
+
+const (
+	// exportMessageBytes is the blob bytes ExportItems gathers before
+	// sending a message.
+	exportMessageBytes = 1 << 20
+	// exportMaxBlob is the largest blob ExportItems sends; bigger ones are
+	// left for GetItemStream.
+	exportMaxBlob = 3 << 20
+)
+
+// ExportItems streams the caller's items. Send blocks while the client's
+// flow-control window is full, which holds back the next read.
+func (s *Server) ExportItems(req *pb.ExportItemsRequest, stream grpc.ServerStreamingServer[pb.ExportItemsResponse]) error {
+	ctx := stream.Context()
+	userID, err := s.userIDFromCtx(ctx)
+	if err != nil {
+		return statusError(codes.Unauthenticated, errs.ReasonUnauthenticated, "no auth")
+	}
+
+	var (
+		batch   []*pb.Change
+		size    int
+		sendErr error
+	)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		out := &pb.ExportItemsResponse{}
+		out.SetItems(batch)
+		batch, size = nil, 0
+		sendErr = stream.Send(out)
+		return sendErr
+	}
+	err = s.items.Export(ctx, userID, req.GetIncludeDeleted(), func(it model.Item) error {
+		c := convert.ToProtoChange(model.Change{ID: it.ID, Ver: it.Ver, Deleted: it.Deleted, UpdatedAt: it.UpdatedAt, BlobEnc: it.BlobEnc, Hints: it.Hints})
+		n := len(it.BlobEnc)
+		if it.Deleted {
+			n = 0
+		}
+		if n > exportMaxBlob {
+			c.ClearBlobEnc()
+			n = 0
+		}
+		if size > 0 && size+n > exportMessageBytes {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		batch = append(batch, c)
+		size += n
+		return nil
+	})
+	if sendErr != nil {
+		return sendErr
+	}
+	if err != nil {
+		return toStatus("export items", err)
+	}
+	return flush()
+}
@@ -0,0 +1,106 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/and161185/goph-keeper/internal/repository/memory"
+	"github.com/and161185/goph-keeper/internal/service"
+	"github.com/and161185/goph-keeper/internal/tokensign"
+)
+
+func TestServer_ExportItems(t *testing.T) {
+	t.Parallel()
+	key := []byte("k")
+	repo := memory.NewItemRepo()
+	cc, stop := startBufGRPC(t, New(nil, service.NewItemService(repo, 100), tokensign.HMAC(key)))
+	defer stop()
+	cli := pb.NewGophKeeperClient(cc)
+
+	user := uuid.Must(uuid.NewV4())
+	ctx := context.Background()
+	var ups []model.UpsertItem
+	for range 5 {
+		ups = append(ups, model.UpsertItem{ID: uuid.Must(uuid.NewV4()), BlobEnc: make([]byte, 300<<10)})
+	}
+	huge := uuid.Must(uuid.NewV4())
+	ups = append(ups, model.UpsertItem{ID: huge, BlobEnc: make([]byte, exportMaxBlob+1)})
+	if _, err := repo.UpsertBatch(ctx, user, ups); err != nil {
+		t.Fatal(err)
+	}
+	gone := ups[0].ID.String()
+	if _, err := repo.Delete(ctx, user, ups[0].ID, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.UpsertBatch(ctx, uuid.Must(uuid.NewV4()), []model.UpsertItem{{ID: uuid.Must(uuid.NewV4()), BlobEnc: []byte{1}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	authed := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+jwtFor(t, user.String(), key, time.Hour))
+	export := func(includeDeleted bool) (msgs int, items []*pb.Change) {
+		t.Helper()
+		req := &pb.ExportItemsRequest{}
+		req.SetIncludeDeleted(includeDeleted)
+		stream, err := cli.ExportItems(authed, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for {
+			m, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return msgs, items
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			msgs++
+			items = append(items, m.GetItems()...)
+		}
+	}
+
+	msgs, items := export(false)
+	if len(items) != 5 {
+		t.Fatalf("got %d items, want the 5 live ones", len(items))
+	}
+	// 300 KiB each: three fill a message, and the huge one travels blobless
+	if msgs != 2 {
+		t.Fatalf("%d messages", msgs)
+	}
+	for i, c := range items {
+		if i > 0 && c.GetId() <= items[i-1].GetId() {
+			t.Fatalf("not in id order: %s after %s", c.GetId(), items[i-1].GetId())
+		}
+		if c.GetId() == gone {
+			t.Fatal("tombstone without include_deleted")
+		}
+		if c.GetId() == huge.String() {
+			if c.HasBlobEnc() || c.GetVer() != 1 {
+				t.Fatalf("item over the limit: ver %d, blob %v", c.GetVer(), c.HasBlobEnc())
+			}
+		} else if len(c.GetBlobEnc().GetCiphertext()) != 300<<10 {
+			t.Fatalf("item %s: %d bytes", c.GetId(), len(c.GetBlobEnc().GetCiphertext()))
+		}
+	}
+
+	if _, items = export(true); len(items) != 6 {
+		t.Fatalf("with tombstones: %d items", len(items))
+	}
+
+	stream, err := cli.ExportItems(ctx, &pb.ExportItemsRequest{})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("no token: %v", err)
+	}
+}
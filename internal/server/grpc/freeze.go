@@ -0,0 +1,95 @@
+package grpcserver
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/tokensign"
+)
+
+// frozenMethods are the RPCs a frozen vault refuses: every change to the
+// account except ending sessions and unfreezing, which are how a user
+// recovers from a lost device.
+var frozenMethods = map[string]bool{
+	pb.GophKeeper_UpsertItems_FullMethodName:          true,
+	pb.GophKeeper_DeleteItem_FullMethodName:           true,
+	pb.GophKeeper_DeleteItems_FullMethodName:          true,
+	pb.GophKeeper_ApplyChangeSet_FullMethodName:       true,
+	pb.GophKeeper_SetWrappedDEK_FullMethodName:        true,
+	pb.GophKeeper_CreateWebhook_FullMethodName:        true,
+	pb.GophKeeper_DeleteWebhook_FullMethodName:        true,
+	pb.GophKeeper_RegisterDevice_FullMethodName:       true,
+	pb.GophKeeper_UnregisterDevice_FullMethodName:     true,
+	pb.GophKeeper_SetDuressPassword_FullMethodName:    true,
+	pb.GophKeeper_RemoveDuressPassword_FullMethodName: true,
+}
+
+// FreezeChecker tells whether a user's vault is frozen;
+// *service.AuthServiceImpl implements it.
+type FreezeChecker interface {
+	FrozenAt(ctx context.Context, userID uuid.UUID) (time.Time, error)
+}
+
+// FreezeUnary returns a unary server interceptor refusing frozenMethods
+// for users whose vault is frozen. Other calls cost no lookup. Streaming
+// RPCs only read, so there is no stream variant.
+func FreezeUnary(c FreezeChecker, v tokensign.Verifier) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (any, error) {
+		if frozenMethods[info.FullMethod] {
+			if err := checkFrozen(ctx, c, v); err != nil {
+				return nil, err
+			}
+		}
+		return next(ctx, req)
+	}
+}
+
+func checkFrozen(ctx context.Context, c FreezeChecker, v tokensign.Verifier) error {
+	userID, err := verifyBearer(ctx, v)
+	if err != nil {
+		return nil // the handler refuses it
+	}
+	at, err := c.FrozenAt(ctx, userID)
+	if err != nil {
+		return toStatus("frozen check", err)
+	}
+	if at.IsZero() {
+		return nil
+	}
+	return statusError(codes.FailedPrecondition, errs.ReasonVaultFrozen,
+		"the vault is frozen; unfreeze it to make changes", "frozen_at", at.UTC().Format(time.RFC3339))
+}
+
+// FreezeVault makes the caller's vault read-only.
+func (s *Server) FreezeVault(ctx context.Context, req *pb.FreezeVaultRequest) (*pb.FreezeVaultResponse, error) {
+	userID, err := s.userIDFromCtx(ctx)
+	if err != nil {
+		return nil, statusError(codes.Unauthenticated, errs.ReasonUnauthenticated, "no auth")
+	}
+	at, err := s.auth.Freeze(ctx, userID, req.GetPassword(), remoteIP(ctx))
+	if err != nil {
+		return nil, toStatus("freeze", err, remap(errs.ErrUnauthorized, errs.ReasonBadCredentials, "wrong password"))
+	}
+	resp := &pb.FreezeVaultResponse{}
+	resp.SetFrozenAt(timestamppb.New(at))
+	return resp, nil
+}
+
+// UnfreezeVault makes the caller's vault writable again.
+func (s *Server) UnfreezeVault(ctx context.Context, req *pb.UnfreezeVaultRequest) (*pb.UnfreezeVaultResponse, error) {
+	userID, err := s.userIDFromCtx(ctx)
+	if err != nil {
+		return nil, statusError(codes.Unauthenticated, errs.ReasonUnauthenticated, "no auth")
+	}
+	if err := s.auth.Unfreeze(ctx, userID, req.GetPassword(), remoteIP(ctx)); err != nil {
+		return nil, toStatus("unfreeze", err, remap(errs.ErrUnauthorized, errs.ReasonBadCredentials, "wrong password"))
+	}
+	return &pb.UnfreezeVaultResponse{}, nil
+}
@@ -0,0 +1,91 @@
+package grpcserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/limiter"
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/and161185/goph-keeper/internal/repository/memory"
+	"github.com/and161185/goph-keeper/internal/service"
+	"github.com/and161185/goph-keeper/internal/tokensign"
+)
+
+func TestServer_Freeze(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	signer := tokensign.HMAC([]byte("k"))
+	auth := service.NewAuthService(memory.NewUserRepo(), signer, time.Minute, limiter.NewMemory(time.Minute, 5, time.Minute))
+	s := New(auth, &fakeItems{}, signer)
+
+	rr := &pb.RegisterRequest{}
+	rr.SetUsername("alice")
+	rr.SetPassword("pw")
+	if _, err := s.Register(ctx, rr); err != nil {
+		t.Fatal(err)
+	}
+	lr := &pb.LoginRequest{}
+	lr.SetUsername("alice")
+	lr.SetPassword("pw")
+	login, err := s.Login(ctx, lr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authed := ctxWithAuth(login.GetAccessToken())
+
+	called := false
+	handler := func(context.Context, any) (any, error) { called = true; return nil, nil }
+	call := func(method string) error {
+		called = false
+		_, err := FreezeUnary(auth, signer)(authed, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		return err
+	}
+
+	freeze := &pb.FreezeVaultRequest{}
+	freeze.SetPassword("guess")
+	if _, err := s.FreezeVault(authed, freeze); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("wrong password: %v", err)
+	}
+	freeze.SetPassword("pw")
+	resp, err := s.FreezeVault(authed, freeze)
+	if err != nil || resp.GetFrozenAt().AsTime().IsZero() {
+		t.Fatalf("freeze: %v %v", resp, err)
+	}
+
+	err = call(pb.GophKeeper_UpsertItems_FullMethodName)
+	st, _ := status.FromError(err)
+	if called || st.Code() != codes.FailedPrecondition || errInfo(st).GetReason() != errs.ReasonVaultFrozen ||
+		errInfo(st).GetMetadata()["frozen_at"] == "" {
+		t.Fatalf("upsert into a frozen vault: called=%v %v", called, err)
+	}
+	for _, m := range []string{pb.GophKeeper_GetItem_FullMethodName, pb.GophKeeper_RevokeSession_FullMethodName, pb.GophKeeper_UnfreezeVault_FullMethodName} {
+		if err := call(m); err != nil || !called {
+			t.Fatalf("%s while frozen: %v", m, err)
+		}
+	}
+
+	unfreeze := &pb.UnfreezeVaultRequest{}
+	unfreeze.SetPassword("pw")
+	if _, err := s.UnfreezeVault(authed, unfreeze); err != nil {
+		t.Fatal(err)
+	}
+	if err := call(pb.GophKeeper_UpsertItems_FullMethodName); err != nil || !called {
+		t.Fatalf("upsert after unfreezing: %v", err)
+	}
+}
+
+func Test_frozenMethods_CoverItemWrites(t *testing.T) {
+	t.Parallel()
+	for m, p := range policies {
+		if p.scope == model.ScopeItemsWrite && !frozenMethods[m] {
+			t.Errorf("%s changes items but works in a frozen vault", m)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package grpcserver
 
 import (
 	"context"
+	"errors"
 	"runtime/debug"
 	"time"
 
@@ -10,6 +11,8 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
+
+	"github.com/and161185/goph-keeper/internal/errs"
 )
 
 // LoggingUnary returns a unary server interceptor for structured logging.
@@ -17,24 +20,44 @@ func LoggingUnary(log *zap.Logger) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (any, error) {
 		start := time.Now()
 		resp, err := next(ctx, req)
-		code := status.Code(err)
-
-		var remote string
-		if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
-			remote = p.Addr.String()
-		}
-
-		// никаких пейлоадов — только метаданные
-		log.Info("grpc",
-			zap.String("method", info.FullMethod),
-			zap.String("code", code.String()),
-			zap.Duration("dur", time.Since(start)),
-			zap.String("peer", remote),
-		)
+		logCall(log, ctx, info.FullMethod, start, err)
 		return resp, err
 	}
 }
 
+// LoggingStream is LoggingUnary for streaming RPCs; it logs once the
+// stream ends.
+func LoggingStream(log *zap.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, next grpc.StreamHandler) error {
+		start := time.Now()
+		err := next(srv, ss)
+		logCall(log, ss.Context(), info.FullMethod, start, err)
+		return err
+	}
+}
+
+func logCall(log *zap.Logger, ctx context.Context, method string, start time.Time, err error) {
+	var remote string
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		remote = p.Addr.String()
+	}
+
+	// никаких пейлоадов — только метаданные
+	fields := []zap.Field{
+		zap.String("method", method),
+		zap.String("code", status.Code(err).String()),
+		zap.Duration("dur", time.Since(start)),
+		zap.String("peer", remote),
+	}
+	var ie *internalErr
+	if errors.As(err, &ie) {
+		// the client only got the incident id; the cause is logged here
+		log.Error("grpc", append(fields, zap.String("incident", ie.incident), zap.Error(ie))...)
+		return
+	}
+	log.Info("grpc", fields...)
+}
+
 // RecoverUnary returns a unary server interceptor that recovers from panics.
 func RecoverUnary(log *zap.Logger) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (resp any, err error) {
@@ -45,9 +68,35 @@ func RecoverUnary(log *zap.Logger) grpc.UnaryServerInterceptor {
 					zap.ByteString("stack", debug.Stack()),
 					zap.String("method", info.FullMethod),
 				)
-				err = status.Error(codes.Internal, "internal")
+				err = statusError(codes.Internal, errs.ReasonInternal, "internal")
 			}
 		}()
 		return next(ctx, req)
 	}
 }
+
+// RecoverStream is RecoverUnary for streaming RPCs.
+func RecoverStream(log *zap.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, next grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Error("panic",
+					zap.Any("reason", r),
+					zap.ByteString("stack", debug.Stack()),
+					zap.String("method", info.FullMethod),
+				)
+				err = statusError(codes.Internal, errs.ReasonInternal, "internal")
+			}
+		}()
+		return next(srv, ss)
+	}
+}
+
+// ctxStream is a ServerStream with a replaced context, for interceptors
+// that scope the handler's context.
+type ctxStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *ctxStream) Context() context.Context { return s.ctx }
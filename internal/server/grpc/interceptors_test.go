@@ -3,10 +3,13 @@ package grpcserver
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
+	"go.uber.org/zap"
 	"go.uber.org/zap/zaptest"
+	"go.uber.org/zap/zaptest/observer"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/peer"
@@ -47,6 +50,29 @@ func TestLoggingUnary_Passthrough(t *testing.T) {
 	}
 }
 
+func TestLoggingUnary_LogsInternalCause(t *testing.T) {
+	t.Parallel()
+
+	core, logs := observer.New(zap.InfoLevel)
+	ic := LoggingUnary(zap.New(core))
+	info := &grpc.UnaryServerInfo{FullMethod: "/gk.Service/Method"}
+	cause := errors.New("pq: password authentication failed")
+	h := func(context.Context, any) (any, error) { return nil, internalError("get", cause) }
+
+	_, err := ic(context.Background(), "req", info, h)
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("want Internal, got %v", err)
+	}
+	entries := logs.FilterMessage("grpc").All()
+	if len(entries) != 1 || entries[0].Level != zap.ErrorLevel {
+		t.Fatalf("want one error entry, got %+v", entries)
+	}
+	ctx := entries[0].ContextMap()
+	if ctx["incident"] == "" || !strings.Contains(ctx["error"].(string), "password authentication") {
+		t.Fatalf("cause not logged: %v", ctx)
+	}
+}
+
 func TestRecoverUnary_CatchesPanic(t *testing.T) {
 	t.Parallel()
 
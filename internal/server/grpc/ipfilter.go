@@ -0,0 +1,114 @@
+package grpcserver
+
+import (
+	"context"
+	"net/netip"
+
+	"github.com/gofrs/uuid/v5"
+	"google.golang.org/grpc"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/internal/ipfilter"
+	"github.com/and161185/goph-keeper/internal/tenant"
+	"github.com/and161185/goph-keeper/internal/tokensign"
+)
+
+// AddressChecker admits or refuses calls by client address;
+// *ipfilter.Filter implements it.
+type AddressChecker interface {
+	Check(ctx context.Context, addr netip.Addr, userID uuid.UUID, username string) error
+}
+
+// RefreshOwners finds the user a refresh token belongs to;
+// *service.AuthServiceImpl implements it.
+type RefreshOwners interface {
+	RefreshOwner(ctx context.Context, refreshToken string) (uuid.UUID, error)
+}
+
+// GlobalIPFilterUnary returns a unary server interceptor refusing calls
+// from addresses outside the server's own rules. It goes first in the chain,
+// ahead of authentication, throttling and the audit interceptor, so f is
+// asked about the address alone. Refused calls to audited methods are
+// recorded to a directly; a may be nil.
+func GlobalIPFilterUnary(f AddressChecker, a Auditor, reg *tenant.Registry, v tokensign.Verifier) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (any, error) {
+		if err := checkGlobal(ctx, f, a, reg, v, info.FullMethod, req); err != nil {
+			return nil, err
+		}
+		return next(ctx, req)
+	}
+}
+
+// GlobalIPFilterStream is GlobalIPFilterUnary for streaming RPCs. The
+// request is not read yet, so their audit records have no target.
+func GlobalIPFilterStream(f AddressChecker, a Auditor, reg *tenant.Registry, v tokensign.Verifier) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, next grpc.StreamHandler) error {
+		if err := checkGlobal(ss.Context(), f, a, reg, v, info.FullMethod, nil); err != nil {
+			return err
+		}
+		return next(srv, ss)
+	}
+}
+
+func checkGlobal(ctx context.Context, f AddressChecker, a Auditor, reg *tenant.Registry, v tokensign.Verifier, method string, req any) error {
+	err := f.Check(ctx, ipfilter.PeerAddr(remoteIP(ctx)), uuid.Nil, "")
+	if err == nil {
+		return nil
+	}
+	st := toStatus("address rules", err)
+	if action, ok := auditedMethods[method]; ok && a != nil {
+		// TenantUnary has not run yet; if the tenant cannot be resolved
+		// either, the record goes to the default one
+		if id, terr := resolveTenant(ctx, reg, v); terr == nil {
+			ctx = tenant.WithID(ctx, id)
+		}
+		a.Record(ctx, auditEvent(ctx, v, action, req, nil, st))
+	}
+	return st
+}
+
+// IPFilterUnary returns a unary server interceptor refusing calls from
+// addresses outside the caller's own rules, before the handler
+// authenticates anything. Login is matched to its user by username and
+// Refresh, through owners, by its refresh token; owners may be nil when
+// the server issues no refresh tokens. It must run inside TenantUnary.
+func IPFilterUnary(f AddressChecker, v tokensign.Verifier, owners RefreshOwners) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, next grpc.UnaryHandler) (any, error) {
+		if err := checkAddress(ctx, f, v, owners, req); err != nil {
+			return nil, err
+		}
+		return next(ctx, req)
+	}
+}
+
+// IPFilterStream is IPFilterUnary for streaming RPCs, which all carry a token.
+func IPFilterStream(f AddressChecker, v tokensign.Verifier) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, next grpc.StreamHandler) error {
+		if err := checkAddress(ss.Context(), f, v, nil, nil); err != nil {
+			return err
+		}
+		return next(srv, ss)
+	}
+}
+
+func checkAddress(ctx context.Context, f AddressChecker, v tokensign.Verifier, owners RefreshOwners, req any) error {
+	user, _ := verifyBearer(ctx, v)
+	var name string
+	if user == uuid.Nil {
+		switch r := req.(type) {
+		case *pb.RefreshRequest:
+			if owners != nil {
+				var err error
+				if user, err = owners.RefreshOwner(ctx, r.GetRefreshToken()); err != nil {
+					return toStatus("address rules", err)
+				}
+			}
+		case interface{ GetUsername() string }:
+			name = r.GetUsername()
+		}
+	}
+	if err := f.Check(ctx, ipfilter.PeerAddr(remoteIP(ctx)), user, name); err != nil {
+		return toStatus("address rules", err)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/ipfilter"
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/and161185/goph-keeper/internal/tokensign"
+)
+
+type fakeChecker struct {
+	err  error
+	addr netip.Addr
+	user uuid.UUID
+	name string
+}
+
+func (f *fakeChecker) Check(_ context.Context, addr netip.Addr, user uuid.UUID, name string) error {
+	f.addr, f.user, f.name = addr, user, name
+	return f.err
+}
+
+// fakeOwners maps refresh tokens to users.
+type fakeOwners map[string]uuid.UUID
+
+func (o fakeOwners) RefreshOwner(_ context.Context, tok string) (uuid.UUID, error) {
+	return o[tok], nil
+}
+
+// userRules is a RuleSource holding one user's rules.
+type userRules struct {
+	user  uuid.UUID
+	rules []model.IPRule
+}
+
+func (u userRules) Rules(_ context.Context, id uuid.UUID) ([]model.IPRule, error) {
+	if id == u.user {
+		return u.rules, nil
+	}
+	return nil, nil
+}
+
+func (u userRules) RulesByUsername(context.Context, string) ([]model.IPRule, error) { return nil, nil }
+
+func TestIPFilterUnary(t *testing.T) {
+	t.Parallel()
+	key := []byte("k")
+	uid := uuid.Must(uuid.NewV4())
+	base := peer.NewContext(context.Background(), &peer.Peer{Addr: fakeAddr{}})
+	called := false
+	handler := func(context.Context, any) (any, error) { called = true; return &pb.LoginResponse{}, nil }
+	info := &grpc.UnaryServerInfo{}
+	login := &pb.LoginRequest{}
+	login.SetUsername("alice")
+
+	f := &fakeChecker{}
+	ic := IPFilterUnary(f, tokensign.HMAC(key), fakeOwners{})
+	if _, err := ic(base, login, info, handler); err != nil || !called {
+		t.Fatalf("admitted login: %v", err)
+	}
+	if f.addr != netip.MustParseAddr("127.0.0.1") || f.user != uuid.Nil || f.name != "alice" {
+		t.Fatalf("login checked as %v %v %q", f.addr, f.user, f.name)
+	}
+
+	tok := makeJWT(t, uid.String(), key, jwt.SigningMethodHS256, time.Now(), time.Minute)
+	ctx := peer.NewContext(ctxWithAuth(tok), &peer.Peer{Addr: fakeAddr{}})
+	if _, err := ic(ctx, &pb.GetItemRequest{}, info, handler); err != nil || f.user != uid || f.name != "" {
+		t.Fatalf("token call checked as %v %q: %v", f.user, f.name, err)
+	}
+
+	called = false
+	f.err = errs.ErrAddressDenied
+	_, err := ic(base, login, info, handler)
+	st, _ := status.FromError(err)
+	if called || st.Code() != codes.PermissionDenied || errInfo(st).GetReason() != errs.ReasonAddressDenied {
+		t.Fatalf("denied: called=%v %v", called, err)
+	}
+
+	f.err = errors.New("db down")
+	if _, err := ic(base, login, info, handler); status.Code(err) != codes.Internal || called {
+		t.Fatalf("lookup failure: %v", err)
+	}
+}
+
+func TestIPFilterUnary_Refresh(t *testing.T) {
+	t.Parallel()
+	uid := uuid.Must(uuid.NewV4())
+	base := peer.NewContext(context.Background(), &peer.Peer{Addr: fakeAddr{}})
+	called := false
+	handler := func(context.Context, any) (any, error) { called = true; return &pb.RefreshResponse{}, nil }
+	refresh := func(tok string) *pb.RefreshRequest {
+		r := &pb.RefreshRequest{}
+		r.SetRefreshToken(tok)
+		return r
+	}
+
+	// the user has barred the caller's address; Refresh carries no bearer,
+	// only the refresh token names the user
+	f := &ipfilter.Filter{Users: userRules{user: uid, rules: []model.IPRule{{CIDR: netip.MustParsePrefix("127.0.0.0/8")}}}}
+	ic := IPFilterUnary(f, tokensign.HMAC([]byte("k")), fakeOwners{"rt": uid})
+	_, err := ic(base, refresh("rt"), &grpc.UnaryServerInfo{}, handler)
+	st, _ := status.FromError(err)
+	if called || st.Code() != codes.PermissionDenied || errInfo(st).GetReason() != errs.ReasonAddressDenied {
+		t.Fatalf("refresh from a blocked address: called=%v %v", called, err)
+	}
+
+	// an unknown token leaves only the server's rules; Refresh refuses it itself
+	if _, err := ic(base, refresh("other"), &grpc.UnaryServerInfo{}, handler); err != nil || !called {
+		t.Fatalf("unknown token: called=%v %v", called, err)
+	}
+}
+
+func TestGlobalIPFilterUnary(t *testing.T) {
+	t.Parallel()
+	base := peer.NewContext(context.Background(), &peer.Peer{Addr: fakeAddr{}})
+	called := false
+	handler := func(context.Context, any) (any, error) { called = true; return &pb.LoginResponse{}, nil }
+	login := &pb.LoginRequest{}
+	login.SetUsername("alice")
+
+	var rec recordedEvents
+	deny, err := ipfilter.Parse("", "127.0.0.0/8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ic := GlobalIPFilterUnary(&ipfilter.Filter{Global: deny}, &rec, nil, tokensign.HMAC([]byte("k")))
+	_, err = ic(base, login, &grpc.UnaryServerInfo{FullMethod: pb.GophKeeper_Login_FullMethodName}, handler)
+	if called || status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("denied: called=%v %v", called, err)
+	}
+	if len(rec) != 1 || rec[0].Action != "login" || rec[0].Subject != "alice" || rec[0].Code != codes.PermissionDenied.String() {
+		t.Fatalf("audit: %+v", rec)
+	}
+
+	// not audited: refused without a record
+	if _, err := ic(base, &pb.GetChangesRequest{}, &grpc.UnaryServerInfo{FullMethod: pb.GophKeeper_GetChanges_FullMethodName}, handler); status.Code(err) != codes.PermissionDenied || len(rec) != 1 {
+		t.Fatalf("unaudited: %v %+v", err, rec)
+	}
+
+	ic = GlobalIPFilterUnary(&ipfilter.Filter{}, nil, nil, tokensign.HMAC([]byte("k")))
+	if _, err := ic(base, login, &grpc.UnaryServerInfo{FullMethod: pb.GophKeeper_Login_FullMethodName}, handler); err != nil || !called {
+		t.Fatalf("admitted: called=%v %v", called, err)
+	}
+}
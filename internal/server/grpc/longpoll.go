@@ -0,0 +1,116 @@
+package grpcserver
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/tenant"
+)
+
+// Long-poll timing: the wait a client gets by default and at most, the
+// margin kept before the call's deadline, and how often a waiting call
+// rereads the database for writes made through other server instances.
+const (
+	longPollDefault = 30 * time.Second
+	longPollMax     = 60 * time.Second
+	longPollMargin  = time.Second
+	longPollRecheck = 5 * time.Second
+)
+
+// ChangeWaiter wakes long-polling calls; *longpoll.Hub implements it.
+type ChangeWaiter interface {
+	// Subscribe returns a channel closed at the user's next change and a
+	// function to call once done waiting.
+	Subscribe(tenant string, user uuid.UUID) (<-chan struct{}, func())
+	// Done is closed when the server is shutting down.
+	Done() <-chan struct{}
+}
+
+// SetChangeWaiter lets GetChangesLongPoll sleep until a change instead of
+// rereading the database every few seconds.
+func (s *Server) SetChangeWaiter(w ChangeWaiter) { s.waiter = w }
+
+// longPollWait is how long a call may be held.
+func longPollWait(ctx context.Context, seconds int32) time.Duration {
+	wait := longPollDefault
+	if seconds > 0 {
+		wait = min(time.Duration(seconds)*time.Second, longPollMax)
+	}
+	if dl, ok := ctx.Deadline(); ok {
+		wait = min(wait, time.Until(dl)-longPollMargin)
+	}
+	return max(wait, 0)
+}
+
+// GetChangesLongPoll answers like GetChanges once the vault changes or the
+// wait is over. Besides the hub's wake-ups, a waiting call compares the
+// vault's stats every few seconds, which also catches edits of old items
+// made through other instances.
+func (s *Server) GetChangesLongPoll(ctx context.Context, req *pb.GetChangesLongPollRequest) (*pb.GetChangesLongPollResponse, error) {
+	userID, err := s.userIDFromCtx(ctx)
+	if err != nil {
+		return nil, statusError(codes.Unauthenticated, errs.ReasonUnauthenticated, "no auth")
+	}
+	var woken, done <-chan struct{}
+	if s.waiter != nil {
+		// subscribe before the first read, so a write in between still wakes us
+		var release func()
+		woken, release = s.waiter.Subscribe(tenant.FromContext(ctx), userID)
+		defer release()
+		done = s.waiter.Done()
+	}
+	page := &pb.GetChangesRequest{}
+	page.SetSinceVer(req.GetSinceVer())
+	if req.HasSinceSeq() {
+		page.SetSinceSeq(req.GetSinceSeq())
+	}
+	page.SetMaxSchemaVersion(req.GetMaxSchemaVersion())
+	reply := func(changed bool) (*pb.GetChangesLongPollResponse, error) {
+		res, err := s.changesSince(ctx, userID, page)
+		if err != nil {
+			return nil, err
+		}
+		out := &pb.GetChangesLongPollResponse{}
+		out.SetResult(res)
+		out.SetChanged(changed || len(res.GetChanges()) > 0)
+		return out, nil
+	}
+
+	before, err := s.items.Stats(ctx, userID)
+	if err != nil {
+		return nil, toStatus("long poll", err)
+	}
+	if out, err := reply(false); err != nil || out.GetChanged() {
+		return out, err
+	}
+	timeout := time.NewTimer(longPollWait(ctx, req.GetWaitSeconds()))
+	defer timeout.Stop()
+	recheck := time.NewTicker(longPollRecheck)
+	defer recheck.Stop()
+	for {
+		select {
+		case <-woken:
+			return reply(true)
+		case <-recheck.C:
+			now, err := s.items.Stats(ctx, userID)
+			if err != nil {
+				return nil, toStatus("long poll", err)
+			}
+			if now.Items != before.Items || now.Deleted != before.Deleted || !now.LastUpdated.Equal(before.LastUpdated) {
+				return reply(true)
+			}
+		case <-timeout.C:
+			return reply(false)
+		case <-done:
+			return reply(false)
+		case <-ctx.Done():
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package grpcserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/and161185/goph-keeper/internal/tokensign"
+)
+
+// quietItems has nothing new above any version.
+type quietItems struct{ fakeItems }
+
+func (q *quietItems) GetChanges(context.Context, uuid.UUID, model.ChangeCursor, int) ([]model.Change, error) {
+	return nil, nil
+}
+
+type fakeWaiter struct {
+	wake       chan struct{}
+	done       chan struct{}
+	subscribed chan struct{}
+	released   bool
+}
+
+func newFakeWaiter() *fakeWaiter {
+	return &fakeWaiter{wake: make(chan struct{}), done: make(chan struct{}), subscribed: make(chan struct{}, 1)}
+}
+
+func (w *fakeWaiter) Subscribe(string, uuid.UUID) (<-chan struct{}, func()) {
+	select {
+	case w.subscribed <- struct{}{}:
+	default:
+	}
+	return w.wake, func() { w.released = true }
+}
+func (w *fakeWaiter) Done() <-chan struct{} { return w.done }
+
+func longPollReq(wait int32) *pb.GetChangesLongPollRequest {
+	req := &pb.GetChangesLongPollRequest{}
+	req.SetSinceVer(3)
+	req.SetWaitSeconds(wait)
+	return req
+}
+
+func Test_GetChangesLongPoll_Immediate(t *testing.T) {
+	key := []byte("secret")
+	s := New(nil, &fakeItems{}, tokensign.HMAC(key))
+	ctx := ctxAuth(jwtFor(t, uuid.Must(uuid.NewV4()).String(), key, time.Hour))
+
+	out, err := s.GetChangesLongPoll(ctx, longPollReq(30))
+	if err != nil || !out.GetChanged() || len(out.GetResult().GetChanges()) != 1 || out.GetResult().GetWatermark() != 4 {
+		t.Fatalf("want the pending change at once: %v %v", out, err)
+	}
+}
+
+func Test_GetChangesLongPoll_Woken(t *testing.T) {
+	key := []byte("secret")
+	s := New(nil, &quietItems{}, tokensign.HMAC(key))
+	w := newFakeWaiter()
+	s.SetChangeWaiter(w)
+	ctx := ctxAuth(jwtFor(t, uuid.Must(uuid.NewV4()).String(), key, time.Hour))
+
+	go func() {
+		<-w.subscribed
+		close(w.wake)
+	}()
+	out, err := s.GetChangesLongPoll(ctx, longPollReq(30))
+	if err != nil || !out.GetChanged() || out.GetResult().GetWatermark() != 3 {
+		t.Fatalf("want changed after wake-up: %v %v", out, err)
+	}
+	if !w.released {
+		t.Fatal("subscription not released")
+	}
+}
+
+func Test_GetChangesLongPoll_TimeoutAndShutdown(t *testing.T) {
+	key := []byte("secret")
+	s := New(nil, &quietItems{}, tokensign.HMAC(key))
+	w := newFakeWaiter()
+	s.SetChangeWaiter(w)
+	token := jwtFor(t, uuid.Must(uuid.NewV4()).String(), key, time.Hour)
+
+	// the call's deadline, less the margin, caps the wait
+	ctx, cancel := context.WithTimeout(ctxAuth(token), longPollMargin+200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	out, err := s.GetChangesLongPoll(ctx, longPollReq(30))
+	if err != nil || out.GetChanged() {
+		t.Fatalf("want an unchanged answer: %v %v", out, err)
+	}
+	if d := time.Since(start); d < 150*time.Millisecond || d > longPollMargin {
+		t.Fatalf("waited %s", d)
+	}
+
+	close(w.done)
+	start = time.Now()
+	out, err = s.GetChangesLongPoll(ctxAuth(token), longPollReq(30))
+	if err != nil || out.GetChanged() || time.Since(start) > time.Second {
+		t.Fatalf("want a prompt answer on shutdown: %v %v", out, err)
+	}
+}
+
+func Test_GetChangesLongPoll_Unauthenticated(t *testing.T) {
+	s := &Server{verifier: tokensign.HMAC([]byte("k"))}
+	_, err := s.GetChangesLongPoll(context.Background(), longPollReq(1))
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("want Unauthenticated, got %v", err)
+	}
+}
+
+func Test_longPollWait(t *testing.T) {
+	bg := context.Background()
+	if got := longPollWait(bg, 0); got != longPollDefault {
+		t.Errorf("default: %s", got)
+	}
+	if got := longPollWait(bg, 600); got != longPollMax {
+		t.Errorf("capped: %s", got)
+	}
+	ctx, cancel := context.WithTimeout(bg, longPollMargin/2)
+	defer cancel()
+	if got := longPollWait(ctx, 10); got != 0 {
+		t.Errorf("past the deadline: %s", got)
+	}
+}
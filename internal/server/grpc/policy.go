@@ -0,0 +1,144 @@
+package grpcserver
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/and161185/goph-keeper/internal/tokensign"
+)
+
+// access says who may call a method.
+type access int
+
+const (
+	// accessUser needs a valid token.
+	accessUser access = iota
+	// accessAnonymous needs none: the method authenticates the caller by
+	// other means, or serves anyone.
+	accessAnonymous
+	// accessAdmin needs a valid token of a server administrator. The token
+	// is checked here; AdminService checks the administrator list.
+	accessAdmin
+)
+
+// policy is what a method requires of its caller.
+type policy struct {
+	access access
+	// scope the token must carry; empty for anonymous methods.
+	scope string
+}
+
+var (
+	anonymous     = policy{access: accessAnonymous}
+	itemsRead     = policy{access: accessUser, scope: model.ScopeItemsRead}
+	itemsWrite    = policy{access: accessUser, scope: model.ScopeItemsWrite}
+	accountManage = policy{access: accessUser, scope: model.ScopeAccountManage}
+	// admins act through their own accounts' tokens
+	admin = policy{access: accessAdmin, scope: model.ScopeAccountManage}
+)
+
+// policies lists every RPC of the gophkeeper services with what it requires
+// of its caller. A method missing from it is refused, so a new RPC is closed
+// until it is added here.
+var policies = map[string]policy{
+	pb.GophKeeper_Register_FullMethodName:               anonymous,
+	pb.GophKeeper_Login_FullMethodName:                  anonymous,
+	pb.GophKeeper_Refresh_FullMethodName:                anonymous, // the refresh token is the credential
+	pb.GophKeeper_GetServerInfo_FullMethodName:          anonymous,
+	pb.GophKeeper_GetChanges_FullMethodName:             itemsRead,
+	pb.GophKeeper_GetChangesLongPoll_FullMethodName:     itemsRead,
+	pb.GophKeeper_GetItem_FullMethodName:                itemsRead,
+	pb.GophKeeper_GetItemStream_FullMethodName:          itemsRead,
+	pb.GophKeeper_ExportItems_FullMethodName:            itemsRead,
+	pb.GophKeeper_BeginSync_FullMethodName:              itemsRead,
+	pb.GophKeeper_GetStats_FullMethodName:               itemsRead,
+	pb.GophKeeper_VerifyVault_FullMethodName:            itemsRead,
+	pb.GophKeeper_GetItemAccessLog_FullMethodName:       itemsRead,
+	pb.GophKeeper_UpsertItems_FullMethodName:            itemsWrite,
+	pb.GophKeeper_DeleteItem_FullMethodName:             itemsWrite,
+	pb.GophKeeper_DeleteItems_FullMethodName:            itemsWrite,
+	pb.GophKeeper_ApplyChangeSet_FullMethodName:         itemsWrite,
+	pb.GophKeeper_SetWrappedDEK_FullMethodName:          accountManage,
+	pb.GophKeeper_CreateWebhook_FullMethodName:          accountManage,
+	pb.GophKeeper_ListWebhooks_FullMethodName:           accountManage,
+	pb.GophKeeper_DeleteWebhook_FullMethodName:          accountManage,
+	pb.GophKeeper_RegisterDevice_FullMethodName:         accountManage,
+	pb.GophKeeper_ListDevices_FullMethodName:            accountManage,
+	pb.GophKeeper_UnregisterDevice_FullMethodName:       accountManage,
+	pb.GophKeeper_GetLoginHistory_FullMethodName:        accountManage,
+	pb.GophKeeper_ListSessions_FullMethodName:           accountManage,
+	pb.GophKeeper_RevokeSession_FullMethodName:          accountManage,
+	pb.GophKeeper_SetDuressPassword_FullMethodName:      accountManage,
+	pb.GophKeeper_RemoveDuressPassword_FullMethodName:   accountManage,
+	pb.GophKeeper_FreezeVault_FullMethodName:            accountManage,
+	pb.GophKeeper_UnfreezeVault_FullMethodName:          accountManage,
+	pb.AdminService_SetDiagnostics_FullMethodName:       admin,
+	pb.AdminService_RollbackUser_FullMethodName:         admin,
+	pb.AdminService_CheckUserConsistency_FullMethodName: admin,
+	pb.AdminService_SuspendUser_FullMethodName:          admin,
+	pb.AdminService_ReactivateUser_FullMethodName:       admin,
+	pb.AdminService_GetUsageReport_FullMethodName:       admin,
+	pb.AdminService_ListLoginLocks_FullMethodName:       admin,
+	pb.AdminService_ClearLoginLocks_FullMethodName:      admin,
+	pb.AdminService_ListUserIPRules_FullMethodName:      admin,
+	pb.AdminService_AddUserIPRule_FullMethodName:        admin,
+	pb.AdminService_RemoveUserIPRule_FullMethodName:     admin,
+}
+
+// governed are the services policies covers; others, such as health
+// checks and reflection, are let through.
+var governed = []string{
+	"/" + pb.GophKeeper_ServiceDesc.ServiceName + "/",
+	"/" + pb.AdminService_ServiceDesc.ServiceName + "/",
+}
+
+// PolicyUnary returns a unary server interceptor enforcing policies: it
+// refuses calls without a valid token to methods that need one, and calls
+// whose token lacks the method's scope.
+func PolicyUnary(v tokensign.Verifier) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (any, error) {
+		if err := checkPolicy(ctx, v, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return next(ctx, req)
+	}
+}
+
+// PolicyStream is PolicyUnary for streaming RPCs.
+func PolicyStream(v tokensign.Verifier) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, next grpc.StreamHandler) error {
+		if err := checkPolicy(ss.Context(), v, info.FullMethod); err != nil {
+			return err
+		}
+		return next(srv, ss)
+	}
+}
+
+func checkPolicy(ctx context.Context, v tokensign.Verifier, method string) error {
+	p, ok := policies[method]
+	if !ok {
+		for _, prefix := range governed {
+			if strings.HasPrefix(method, prefix) {
+				return statusError(codes.PermissionDenied, errs.ReasonPermissionDenied, "method has no access policy")
+			}
+		}
+		return nil
+	}
+	if p.access == accessAnonymous {
+		return nil
+	}
+	claims, err := bearerClaims(ctx, v)
+	if err != nil {
+		return statusError(codes.Unauthenticated, errs.ReasonUnauthenticated, "no auth")
+	}
+	if !claims.HasScope(p.scope) {
+		return statusError(codes.PermissionDenied, errs.ReasonInsufficientScope, "token lacks scope "+p.scope, "scope", p.scope)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package grpcserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/tokensign"
+)
+
+func TestPolicyUnary(t *testing.T) {
+	t.Parallel()
+	signer := tokensign.HMAC([]byte("k"))
+	token := func(scope string) context.Context {
+		claims := tokensign.Claims{Scope: scope, RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   uuid.Must(uuid.NewV4()).String(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		}}
+		tok, err := tokensign.Issue(context.Background(), signer, claims)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ctxWithAuth(tok)
+	}
+	called := false
+	handler := func(context.Context, any) (any, error) { called = true; return nil, nil }
+	call := func(ctx context.Context, method string) error {
+		called = false
+		_, err := PolicyUnary(signer)(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		return err
+	}
+
+	readOnly := token("items:read")
+	if err := call(readOnly, pb.GophKeeper_GetChanges_FullMethodName); err != nil || !called {
+		t.Fatalf("read with items:read: %v", err)
+	}
+	err := call(readOnly, pb.GophKeeper_DeleteItems_FullMethodName)
+	st, _ := status.FromError(err)
+	if called || st.Code() != codes.PermissionDenied || errInfo(st).GetReason() != errs.ReasonInsufficientScope ||
+		errInfo(st).GetMetadata()["scope"] != "items:write" {
+		t.Fatalf("delete with items:read: called=%v %v", called, err)
+	}
+	if err := call(token(""), pb.AdminService_RollbackUser_FullMethodName); err != nil || !called {
+		t.Fatalf("unscoped token: %v", err)
+	}
+	err = call(context.Background(), pb.GophKeeper_UpsertItems_FullMethodName)
+	if st := status.Convert(err); called || st.Code() != codes.Unauthenticated || errInfo(st).GetReason() != errs.ReasonUnauthenticated {
+		t.Fatalf("no token: called=%v %v", called, err)
+	}
+	if err := call(context.Background(), pb.GophKeeper_Register_FullMethodName); err != nil || !called {
+		t.Fatalf("register needs no token: %v", err)
+	}
+	if err := call(readOnly, pb.GophKeeper_Login_FullMethodName); err != nil || !called {
+		t.Fatalf("login: %v", err)
+	}
+	err = call(readOnly, "/"+pb.GophKeeper_ServiceDesc.ServiceName+"/Unlisted")
+	if called || status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("method with no policy: called=%v %v", called, err)
+	}
+	if err := call(context.Background(), "/grpc.health.v1.Health/Check"); err != nil || !called {
+		t.Fatalf("health check: %v", err)
+	}
+}
+
+func Test_policies_CoverEveryRPC(t *testing.T) {
+	t.Parallel()
+	want := map[string]bool{}
+	for _, sd := range []grpc.ServiceDesc{pb.GophKeeper_ServiceDesc, pb.AdminService_ServiceDesc} {
+		var names []string
+		for _, m := range sd.Methods {
+			names = append(names, m.MethodName)
+		}
+		for _, s := range sd.Streams {
+			names = append(names, s.StreamName)
+		}
+		for _, n := range names {
+			full := "/" + sd.ServiceName + "/" + n
+			want[full] = true
+			p, ok := policies[full]
+			switch {
+			case !ok:
+				t.Errorf("%s has no policy", full)
+			case p.access == accessAnonymous && p.scope != "":
+				t.Errorf("%s is anonymous but needs scope %s", full, p.scope)
+			case p.access != accessAnonymous && p.scope == "":
+				t.Errorf("%s needs a token of no scope", full)
+			case (sd.ServiceName == pb.AdminService_ServiceDesc.ServiceName) != (p.access == accessAdmin):
+				t.Errorf("%s: admin access only and exactly for AdminService", full)
+			}
+		}
+	}
+	for m := range policies {
+		if !want[m] {
+			t.Errorf("policy for no such method %s", m)
+		}
+	}
+}
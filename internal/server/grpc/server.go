@@ -3,50 +3,99 @@ package grpcserver
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/binary"
 	"errors"
 	"strings"
 	"time"
 
 	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/internal/challenge"
 	"github.com/and161185/goph-keeper/internal/convert"
 	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/merkle"
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/and161185/goph-keeper/internal/repository"
 	"github.com/and161185/goph-keeper/internal/service"
+	"github.com/and161185/goph-keeper/internal/tokensign"
+	"github.com/and161185/goph-keeper/internal/webhook"
 	"github.com/gofrs/uuid/v5"
 	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
-	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // Server wires services into gRPC handlers.
 type Server struct {
 	pb.UnimplementedGophKeeperServer
-	auth    service.AuthService
-	items   service.ItemService
-	signKey []byte
+	auth     service.AuthService
+	items    service.ItemService
+	verifier tokensign.Verifier
+
+	hooks      repository.WebhookRepository
+	hookPolicy webhook.Policy
+
+	accesses repository.AccessLogRepository
+
+	devices       repository.DeviceRepository
+	pushPlatforms []string
+	maxDevices    int
+
+	waiter      ChangeWaiter
+	changesPage int
+
+	chal      challenge.Verifier
+	chalFails FailedLogins
+	chalAfter int
+
+	warnItemBytes int
+
+	version   string
+	minClient string
 }
 
+// DefaultChangesPage is the most changes GetChanges returns per call unless
+// SetMaxChangesPage says otherwise.
+const DefaultChangesPage = 1000
+
 // New constructs a gRPC server with injected services.
-func New(auth service.AuthService, items service.ItemService, signKey []byte) *Server {
-	return &Server{auth: auth, items: items, signKey: signKey}
+func New(auth service.AuthService, items service.ItemService, verifier tokensign.Verifier) *Server {
+	return &Server{auth: auth, items: items, verifier: verifier, changesPage: DefaultChangesPage, warnItemBytes: DefaultWarnItemBytes}
+}
+
+// SetMaxChangesPage caps the changes per GetChanges page; n <= 0 restores
+// the default.
+func (s *Server) SetMaxChangesPage(n int) {
+	if n <= 0 {
+		n = DefaultChangesPage
+	}
+	s.changesPage = n
 }
 
 // --- Auth ---
 
 // Register creates a new user account.
 func (s *Server) Register(ctx context.Context, req *pb.RegisterRequest) (*pb.RegisterResponse, error) {
-	if req.GetUsername() == "" || req.GetPassword() == "" {
-		return nil, status.Error(codes.InvalidArgument, "empty username/password")
+	if s.chal != nil {
+		if err := s.checkChallenge(ctx, req.GetChallengeResponse()); err != nil {
+			return nil, err
+		}
 	}
-	userID, err := s.auth.Register(ctx, req.GetUsername(), req.GetPassword())
+	u, err := s.auth.Register(ctx, req.GetUsername(), req.GetPassword())
 	if err != nil {
-		// map conflicts/validation as needed
-		return nil, status.Errorf(codes.Internal, "register: %v", err)
+		return nil, toStatus("register", err,
+			remap(errs.ErrQuotaExceeded, errs.ReasonUserQuota, "user quota exceeded"),
+			remap(errs.ErrAlreadyExists, errs.ReasonAlreadyExists, "username taken"))
 	}
 
 	rr := &pb.RegisterResponse{}
-	rr.SetUserId(userID)
+	rr.SetUserId(u.ID.String())
+	if req.GetReturnKekSalt() {
+		rr.SetKekSalt(u.KekSalt)
+	}
 	return rr, nil
 }
 
@@ -59,17 +108,20 @@ func remoteIP(ctx context.Context) string {
 
 // Login authenticates a user and returns tokens and bootstrap data.
 func (s *Server) Login(ctx context.Context, req *pb.LoginRequest) (*pb.LoginResponse, error) {
+	need, err := s.loginChallenged(ctx, req.GetUsername())
+	if err != nil {
+		return nil, toStatus("login", err)
+	}
+	if need {
+		if err := s.checkChallenge(ctx, req.GetChallengeResponse()); err != nil {
+			return nil, err
+		}
+	}
 
 	ip := remoteIP(ctx)
-	tok, u, err := s.auth.LoginWithIP(ctx, req.GetUsername(), req.GetPassword(), ip)
+	tok, u, err := s.auth.LoginWithIP(ctx, req.GetUsername(), req.GetPassword(), ip, req.GetDeviceLabel(), req.GetScopes())
 	if err != nil {
-		if errors.Is(err, errs.ErrUnauthorized) {
-			return nil, status.Error(codes.Unauthenticated, "bad credentials")
-		}
-		if errors.Is(err, errs.ErrRateLimited) {
-			return nil, status.Error(codes.ResourceExhausted, "rate limited")
-		}
-		return nil, status.Errorf(codes.Internal, "login: %v", err)
+		return nil, toStatus("login", err, remap(errs.ErrUnauthorized, errs.ReasonBadCredentials, "bad credentials"))
 	}
 
 	lg := &pb.LoginResponse{}
@@ -78,88 +130,248 @@ func (s *Server) Login(ctx context.Context, req *pb.LoginRequest) (*pb.LoginResp
 	lg.SetKekSalt(u.KekSalt)
 	lg.SetWrappedDek(u.WrappedDEK)
 	lg.SetUserId(u.ID.String())
+	lg.SetFailedAttempts(int32(tok.FailedLogins))
+	lg.SetAnomalies(tok.Anomalies)
+	lg.SetScopes(tok.Scopes)
 	return lg, nil
 }
 
 // --- Items ---
 // UpsertItems creates or updates items in batch with optimistic concurrency.
+// With best_effort each item is written or rejected on its own.
 func (s *Server) UpsertItems(ctx context.Context, req *pb.UpsertItemsRequest) (*pb.UpsertItemsResponse, error) {
 	userID, err := s.userIDFromCtx(ctx)
 	if err != nil {
-		return nil, status.Error(codes.Unauthenticated, "no auth")
+		return nil, statusError(codes.Unauthenticated, errs.ReasonUnauthenticated, "no auth")
 	}
 	ups, err := convert.FromProtoUpsertItems(req.GetItems())
 	if err != nil {
-		return nil, status.Errorf(codes.InvalidArgument, "bad items: %v", err)
+		return nil, statusError(codes.InvalidArgument, errs.ReasonInvalidArgument, "bad items: "+err.Error())
+	}
+	uir := &pb.UpsertItemsResponse{}
+	if req.GetBestEffort() {
+		res, err := s.items.UpsertEach(ctx, userID, ups)
+		if err != nil {
+			return nil, toStatus("upsert", err)
+		}
+		written := make([]model.ItemVersion, 0, len(res))
+		stored := make([]model.UpsertItem, 0, len(res))
+		for i, r := range res {
+			if r.Status == model.StatusOK {
+				written = append(written, model.ItemVersion{ID: r.ID, NewVer: r.Ver, UpdatedAt: r.UpdatedAt})
+				stored = append(stored, ups[i])
+			}
+		}
+		uir.SetResults(convert.ToProtoItemVersions(written))
+		uir.SetItemResults(convert.ToProtoItemResults(res))
+		uir.SetWarnings(s.upsertWarnings(ctx, userID, stored))
+		return uir, nil
 	}
 	res, err := s.items.Upsert(ctx, userID, ups)
 	if err != nil {
-		if errors.Is(err, errs.ErrVersionConflict) {
-			return nil, status.Error(codes.FailedPrecondition, "version conflict")
-		}
-		return nil, status.Errorf(codes.Internal, "upsert: %v", err)
+		return nil, toStatus("upsert", err)
 	}
-	uir := &pb.UpsertItemsResponse{}
 	uir.SetResults(convert.ToProtoItemVersions(res))
+	uir.SetWarnings(s.upsertWarnings(ctx, userID, ups))
 	return uir, nil
 }
 
-// GetChanges returns changes since a given version for delta synchronization.
+// GetChanges returns changes since a given version for delta synchronization,
+// one page at a time.
 func (s *Server) GetChanges(ctx context.Context, req *pb.GetChangesRequest) (*pb.GetChangesResponse, error) {
 	userID, err := s.userIDFromCtx(ctx)
 	if err != nil {
-		return nil, status.Error(codes.Unauthenticated, "no auth")
+		return nil, statusError(codes.Unauthenticated, errs.ReasonUnauthenticated, "no auth")
+	}
+	if req.GetSyncSession() != "" {
+		return s.snapshotChanges(ctx, userID, req)
+	}
+	return s.changesSince(ctx, userID, req)
+}
+
+// changesSince reads a page of the user's changes into a response: from the
+// change journal when the request has since_seq or a journal page token,
+// else by version. Blobs written with a schema above max_schema_version,
+// when it is set, are left out.
+func (s *Server) changesSince(ctx context.Context, userID uuid.UUID, req *pb.GetChangesRequest) (*pb.GetChangesResponse, error) {
+	after := model.AfterVer(req.GetSinceVer())
+	seq, journal := req.GetSinceSeq(), req.HasSinceSeq()
+	if tok := req.GetPageToken(); tok != "" {
+		var ok bool
+		if seq, ok = decodeSeqToken(tok); ok {
+			journal = true
+		} else if after, ok = decodePageToken(tok); ok {
+			journal = false
+		} else {
+			return nil, statusError(codes.InvalidArgument, errs.ReasonInvalidArgument, "bad page_token")
+		}
+	}
+	size := s.pageSize(req.GetPageSize())
+
+	// taken before the read: every change committed by then is in the response
+	now := time.Now()
+	// one extra row tells whether another page follows
+	var (
+		cs  []model.Change
+		err error
+	)
+	if journal {
+		cs, err = s.items.GetJournal(ctx, userID, seq, size+1)
+	} else {
+		cs, err = s.items.GetChanges(ctx, userID, after, size+1)
 	}
-	cs, err := s.items.GetChanges(ctx, userID, req.GetSinceVer())
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "get changes: %v", err)
+		return nil, toStatus("get changes", err)
+	}
+	var next string
+	if len(cs) > size {
+		cs = cs[:size]
+		last := cs[size-1]
+		if journal {
+			next = encodeSeqToken(last.Seq)
+		} else {
+			next = encodePageToken(model.ChangeCursor{Ver: last.Ver, ID: last.ID})
+		}
+	}
+	watermark := after.Ver
+	maxSchema := req.GetMaxSchemaVersion()
+	for i, c := range cs {
+		watermark = max(watermark, c.Ver)
+		seq = max(seq, c.Seq)
+		if maxSchema > 0 && c.Hints.SchemaVersion > maxSchema {
+			cs[i].BlobEnc = nil
+		}
 	}
 
 	gcr := &pb.GetChangesResponse{}
 	gcr.SetChanges(convert.ToProtoChanges(cs))
+	gcr.SetServerTime(timestamppb.New(now))
+	gcr.SetWatermark(watermark)
+	gcr.SetNextPageToken(next)
+	if journal {
+		gcr.SetSeqWatermark(seq)
+	}
 	return gcr, nil
 }
 
-// GetItem returns a single item by id.
+// pageSize is the page a request's page_size asks for, within the limit.
+func (s *Server) pageSize(n int32) int {
+	if n > 0 && int(n) < s.changesPage {
+		return int(n)
+	}
+	return s.changesPage
+}
+
+// encodePageToken packs a cursor as 8 bytes of version and 16 of item id.
+func encodePageToken(c model.ChangeCursor) string {
+	b := binary.BigEndian.AppendUint64(make([]byte, 0, 24), uint64(c.Ver))
+	return base64.RawURLEncoding.EncodeToString(append(b, c.ID[:]...))
+}
+
+func decodePageToken(tok string) (model.ChangeCursor, bool) {
+	b, err := base64.RawURLEncoding.DecodeString(tok)
+	if err != nil || len(b) != 24 {
+		return model.ChangeCursor{}, false
+	}
+	c := model.ChangeCursor{Ver: int64(binary.BigEndian.Uint64(b))}
+	copy(c.ID[:], b[8:])
+	return c, c.Ver >= 0
+}
+
+// encodeSeqToken packs a journal position as 8 bytes, which tells it from
+// a version cursor by length.
+func encodeSeqToken(seq int64) string {
+	return base64.RawURLEncoding.EncodeToString(binary.BigEndian.AppendUint64(nil, uint64(seq)))
+}
+
+func decodeSeqToken(tok string) (int64, bool) {
+	b, err := base64.RawURLEncoding.DecodeString(tok)
+	if err != nil || len(b) != 8 {
+		return 0, false
+	}
+	seq := int64(binary.BigEndian.Uint64(b))
+	return seq, seq >= 0
+}
+
+// GetItem returns a single item by id, or a past version when ver is set.
 func (s *Server) GetItem(ctx context.Context, req *pb.GetItemRequest) (*pb.GetItemResponse, error) {
+	it, err := s.loadItem(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return convert.ToProtoGetItemResponse(*it), nil
+}
+
+// streamChunk is the largest blob slice GetItemStream sends per message.
+const streamChunk = 256 << 10
+
+// GetItemStream is GetItem for blobs bigger than a client will accept in
+// one message: the ciphertext follows the metadata in streamChunk pieces.
+func (s *Server) GetItemStream(req *pb.GetItemRequest, stream grpc.ServerStreamingServer[pb.GetItemChunk]) error {
+	it, err := s.loadItem(stream.Context(), req)
+	if err != nil {
+		return err
+	}
+	blob := it.BlobEnc
+	first := &pb.GetItemChunk{}
+	first.SetId(it.ID.String())
+	first.SetVer(it.Ver)
+	first.SetDeleted(it.Deleted)
+	first.SetUpdatedAt(timestamppb.New(it.UpdatedAt))
+	first.SetTotalSize(int64(len(blob)))
+	first.SetHints(convert.ToProtoItemHints(it.Hints))
+	n := min(len(blob), streamChunk)
+	first.SetData(blob[:n])
+	if err := stream.Send(first); err != nil {
+		return err
+	}
+	for blob = blob[n:]; len(blob) > 0; blob = blob[n:] {
+		n = min(len(blob), streamChunk)
+		c := &pb.GetItemChunk{}
+		c.SetData(blob[:n])
+		if err := stream.Send(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadItem validates a GetItem request and reads the item it names.
+func (s *Server) loadItem(ctx context.Context, req *pb.GetItemRequest) (*model.Item, error) {
 	userID, err := s.userIDFromCtx(ctx)
 	if err != nil {
-		return nil, status.Error(codes.Unauthenticated, "no auth")
+		return nil, statusError(codes.Unauthenticated, errs.ReasonUnauthenticated, "no auth")
 	}
 	itemID, err := uuid.FromString(req.GetId())
 	if err != nil {
-		return nil, status.Error(codes.InvalidArgument, "bad id")
+		return nil, statusError(codes.InvalidArgument, errs.ReasonInvalidArgument, "bad id")
+	}
+	var it *model.Item
+	if req.GetVer() > 0 {
+		it, err = s.items.GetVersion(ctx, userID, itemID, req.GetVer())
+	} else {
+		it, err = s.items.GetOne(ctx, userID, itemID)
 	}
-	it, err := s.items.GetOne(ctx, userID, itemID)
 	if err != nil {
-		if errors.Is(err, errs.ErrNotFound) {
-			return nil, status.Error(codes.NotFound, "not found")
-		}
-		return nil, status.Errorf(codes.Internal, "get item: %v", err)
+		return nil, toStatus("get item", err)
 	}
-	return convert.ToProtoGetItemResponse(*it), nil
+	s.recordAccess(ctx, userID, itemID)
+	return it, nil
 }
 
 // DeleteItem marks an item as deleted (tombstone).
 func (s *Server) DeleteItem(ctx context.Context, req *pb.DeleteItemRequest) (*pb.DeleteItemResponse, error) {
 	userID, err := s.userIDFromCtx(ctx)
 	if err != nil {
-		return nil, status.Error(codes.Unauthenticated, "no auth")
+		return nil, statusError(codes.Unauthenticated, errs.ReasonUnauthenticated, "no auth")
 	}
 	itemID, err := uuid.FromString(req.GetId())
 	if err != nil {
-		return nil, status.Error(codes.InvalidArgument, "bad id")
+		return nil, statusError(codes.InvalidArgument, errs.ReasonInvalidArgument, "bad id")
 	}
 	ver, err := s.items.Delete(ctx, userID, itemID, req.GetBaseVer())
 	if err != nil {
-		switch {
-		case errors.Is(err, errs.ErrVersionConflict):
-			return nil, status.Error(codes.FailedPrecondition, "version conflict")
-		case errors.Is(err, errs.ErrNotFound):
-			return nil, status.Error(codes.NotFound, "not found")
-		default:
-			return nil, status.Errorf(codes.Internal, "delete: %v", err)
-		}
+		return nil, toStatus("delete", err)
 	}
 
 	dir := &pb.DeleteItemResponse{}
@@ -167,27 +379,71 @@ func (s *Server) DeleteItem(ctx context.Context, req *pb.DeleteItemRequest) (*pb
 	return dir, nil
 }
 
-// userIDFromCtx: extract "authorization: Bearer <JWT>", verify HS256, return sub as UUID.
-func (s *Server) userIDFromCtx(ctx context.Context) (uuid.UUID, error) {
-	tok, err := bearerTokenFromMD(ctx)
+// DeleteItems tombstones a batch of items, all or none. Conflicts are
+// reported per item in the response rather than as an error.
+func (s *Server) DeleteItems(ctx context.Context, req *pb.DeleteItemsRequest) (*pb.DeleteItemsResponse, error) {
+	userID, err := s.userIDFromCtx(ctx)
 	if err != nil {
-		return uuid.Nil, err
+		return nil, statusError(codes.Unauthenticated, errs.ReasonUnauthenticated, "no auth")
 	}
+	refs, err := convert.FromProtoItemRefs(req.GetItems())
+	if err != nil {
+		return nil, statusError(codes.InvalidArgument, errs.ReasonInvalidArgument, "bad items: "+err.Error())
+	}
+	res, err := s.items.DeleteBatch(ctx, userID, refs)
+	if err != nil {
+		return nil, toStatus("delete batch", err)
+	}
+	out := &pb.DeleteItemsResponse{}
+	out.SetResults(convert.ToProtoItemResults(res))
+	out.SetApplied(model.AllOK(res))
+	return out, nil
+}
 
-	var claims jwt.RegisteredClaims
-	parsed, err := jwt.ParseWithClaims(tok, &claims, func(t *jwt.Token) (any, error) {
-		if t.Method != jwt.SigningMethodHS256 {
-			return nil, errors.New("unexpected signing method")
-		}
-		return s.signKey, nil
-	})
-	if err != nil || !parsed.Valid {
-		return uuid.Nil, errors.New("invalid token")
+// ApplyChangeSet upserts and deletes items in one transaction, all or
+// none. Like DeleteItems, conflicts are reported per entry.
+func (s *Server) ApplyChangeSet(ctx context.Context, req *pb.ApplyChangeSetRequest) (*pb.ApplyChangeSetResponse, error) {
+	userID, err := s.userIDFromCtx(ctx)
+	if err != nil {
+		return nil, statusError(codes.Unauthenticated, errs.ReasonUnauthenticated, "no auth")
+	}
+	ups, err := convert.FromProtoUpsertItems(req.GetUpserts())
+	if err != nil {
+		return nil, statusError(codes.InvalidArgument, errs.ReasonInvalidArgument, "bad upserts: "+err.Error())
+	}
+	refs, err := convert.FromProtoItemRefs(req.GetDeletes())
+	if err != nil {
+		return nil, statusError(codes.InvalidArgument, errs.ReasonInvalidArgument, "bad deletes: "+err.Error())
 	}
+	res, err := s.items.ApplyChangeSet(ctx, userID, model.ChangeSet{Upserts: ups, Deletes: refs})
+	if err != nil {
+		return nil, toStatus("apply change set", err)
+	}
+	out := &pb.ApplyChangeSetResponse{}
+	out.SetUpsertResults(convert.ToProtoItemResults(res.Upserts))
+	out.SetDeleteResults(convert.ToProtoItemResults(res.Deletes))
+	out.SetApplied(res.Applied())
+	if res.Applied() {
+		out.SetWarnings(s.upsertWarnings(ctx, userID, ups))
+	}
+	return out, nil
+}
 
-	v := jwt.NewValidator(jwt.WithLeeway(30 * time.Second))
-	if err := v.Validate(&claims); err != nil {
-		return uuid.Nil, errors.New("token expired or not valid yet")
+// userIDFromCtx returns the caller's user ID: the one AuthUnary stored, or
+// else the subject of the bearer JWT, verified here.
+func (s *Server) userIDFromCtx(ctx context.Context) (uuid.UUID, error) {
+	return verifyBearer(ctx, s.verifier)
+}
+
+// verifyBearer validates the bearer JWT from incoming metadata against v,
+// unless AuthUnary already has.
+func verifyBearer(ctx context.Context, v tokensign.Verifier) (uuid.UUID, error) {
+	if id, ok := UserIDFromCtx(ctx); ok {
+		return id, nil
+	}
+	claims, err := bearerClaims(ctx, v)
+	if err != nil {
+		return uuid.Nil, err
 	}
 
 	id, err := uuid.FromString(claims.Subject)
@@ -197,6 +453,33 @@ func (s *Server) userIDFromCtx(ctx context.Context) (uuid.UUID, error) {
 	return id, nil
 }
 
+// bearerClaims returns the claims of the valid bearer JWT in incoming metadata.
+func bearerClaims(ctx context.Context, v tokensign.Verifier) (*tokensign.Claims, error) {
+	if c, ok := claimsFromCtx(ctx); ok {
+		return c, nil
+	}
+	tok, err := bearerTokenFromMD(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return parseToken(tok, v)
+}
+
+// parseToken checks the signature and validity window of tok.
+func parseToken(tok string, v tokensign.Verifier) (*tokensign.Claims, error) {
+	var claims tokensign.Claims
+	parsed, err := jwt.ParseWithClaims(tok, &claims, tokensign.Keyfunc(v))
+	if err != nil || !parsed.Valid {
+		return nil, errors.New("invalid token")
+	}
+
+	val := jwt.NewValidator(jwt.WithLeeway(30 * time.Second))
+	if err := val.Validate(&claims); err != nil {
+		return nil, errors.New("token expired or not valid yet")
+	}
+	return &claims, nil
+}
+
 func bearerTokenFromMD(ctx context.Context) (string, error) {
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
@@ -217,17 +500,94 @@ func bearerTokenFromMD(ctx context.Context) (string, error) {
 func (s *Server) SetWrappedDEK(ctx context.Context, r *pb.SetWrappedDEKRequest) (*pb.SetWrappedDEKResponse, error) {
 	userID, err := s.userIDFromCtx(ctx)
 	if err != nil {
-		return nil, status.Error(codes.Unauthenticated, "no auth")
+		return nil, statusError(codes.Unauthenticated, errs.ReasonUnauthenticated, "no auth")
 	}
-	if len(r.GetWrappedDek()) == 0 {
-		return nil, status.Error(codes.InvalidArgument, "empty wrapped_dek")
+	if r.HasPreviousWrappedDek() {
+		if err := s.auth.ReplaceWrappedDEK(ctx, userID, r.GetPreviousWrappedDek(), r.GetWrappedDek()); err != nil {
+			return nil, toStatus("replace wrapped dek", err,
+				remap(errs.ErrVersionConflict, errs.ReasonDEKChanged, "wrapped dek changed since read"))
+		}
+		return &pb.SetWrappedDEKResponse{}, nil
 	}
-
 	if err := s.auth.SetWrappedDEK(ctx, userID, r.GetWrappedDek()); err != nil {
-		if errors.Is(err, errs.ErrVersionConflict) {
-			return nil, status.Error(codes.FailedPrecondition, "already initialized")
-		}
-		return nil, status.Errorf(codes.Internal, "set wrapped dek: %v", err)
+		return nil, toStatus("set wrapped dek", err,
+			remap(errs.ErrVersionConflict, errs.ReasonAlreadyInitialized, "already initialized"))
 	}
 	return &pb.SetWrappedDEKResponse{}, nil
 }
+
+// GetStats returns aggregate item counters for the caller.
+func (s *Server) GetStats(ctx context.Context, _ *pb.GetStatsRequest) (*pb.GetStatsResponse, error) {
+	userID, err := s.userIDFromCtx(ctx)
+	if err != nil {
+		return nil, statusError(codes.Unauthenticated, errs.ReasonUnauthenticated, "no auth")
+	}
+	st, err := s.items.Stats(ctx, userID)
+	if err != nil {
+		return nil, toStatus("stats", err)
+	}
+	resp := &pb.GetStatsResponse{}
+	resp.SetItems(st.Items)
+	resp.SetDeleted(st.Deleted)
+	resp.SetMaxVer(st.MaxVer)
+	resp.SetTotalBytes(st.TotalBytes)
+	resp.SetMaxItems(st.MaxItems)
+	if !st.LastUpdated.IsZero() {
+		resp.SetLastUpdated(timestamppb.New(st.LastUpdated))
+	}
+	return resp, nil
+}
+
+// GetLoginHistory returns the caller's recent login attempts.
+func (s *Server) GetLoginHistory(ctx context.Context, req *pb.GetLoginHistoryRequest) (*pb.GetLoginHistoryResponse, error) {
+	userID, err := s.userIDFromCtx(ctx)
+	if err != nil {
+		return nil, statusError(codes.Unauthenticated, errs.ReasonUnauthenticated, "no auth")
+	}
+	hist, err := s.auth.LoginHistory(ctx, userID, int(req.GetLimit()))
+	if err != nil {
+		return nil, toStatus("login history", err)
+	}
+	out := make([]*pb.LoginAttempt, 0, len(hist))
+	for _, a := range hist {
+		la := &pb.LoginAttempt{}
+		la.SetAt(timestamppb.New(a.At))
+		la.SetIpHash(a.IPHash)
+		la.SetSuccess(a.Success)
+		la.SetCountry(a.Where.Country)
+		out = append(out, la)
+	}
+	resp := &pb.GetLoginHistoryResponse{}
+	resp.SetAttempts(out)
+	return resp, nil
+}
+
+// VerifyVault returns the Merkle root of the caller's vault.
+func (s *Server) VerifyVault(ctx context.Context, req *pb.VerifyVaultRequest) (*pb.VerifyVaultResponse, error) {
+	userID, err := s.userIDFromCtx(ctx)
+	if err != nil {
+		return nil, statusError(codes.Unauthenticated, errs.ReasonUnauthenticated, "no auth")
+	}
+	leaves, err := s.items.VaultLeaves(ctx, userID)
+	if err != nil {
+		return nil, toStatus("verify vault", err)
+	}
+	root := merkle.Root(leaves)
+	resp := &pb.VerifyVaultResponse{}
+	resp.SetRoot(root[:])
+	resp.SetItems(int64(len(leaves)))
+	if req.GetIncludeLeaves() {
+		out := make([]*pb.VaultLeaf, 0, len(leaves))
+		for _, l := range leaves {
+			h := l.Hash()
+			vl := &pb.VaultLeaf{}
+			vl.SetId(l.ID)
+			vl.SetVer(l.Ver)
+			vl.SetDeleted(l.Deleted)
+			vl.SetHash(h[:])
+			out = append(out, vl)
+		}
+		resp.SetLeaves(out)
+	}
+	return resp, nil
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/and161185/goph-keeper/internal/tokensign"
 	"github.com/gofrs/uuid/v5"
 	"github.com/golang-jwt/jwt/v5"
 	"google.golang.org/grpc/metadata"
@@ -60,9 +61,9 @@ func Test_bearerTokenFromMD_OkAndErrors(t *testing.T) {
 func Test_userIDFromCtx_Valid(t *testing.T) {
 	t.Parallel()
 
-	s := &Server{signKey: []byte("secret")}
+	s := &Server{verifier: tokensign.HMAC([]byte("secret"))}
 	sub := uuid.Must(uuid.NewV4()).String()
-	j := makeJWT(t, sub, s.signKey, jwt.SigningMethodHS256, time.Now().UTC().Add(-time.Minute), 10*time.Minute)
+	j := makeJWT(t, sub, []byte("secret"), jwt.SigningMethodHS256, time.Now().UTC().Add(-time.Minute), 10*time.Minute)
 	ctx := ctxWithAuth(j)
 
 	id, err := s.userIDFromCtx(ctx)
@@ -77,7 +78,7 @@ func Test_userIDFromCtx_Valid(t *testing.T) {
 func Test_userIDFromCtx_NoMetadata(t *testing.T) {
 	t.Parallel()
 
-	s := &Server{signKey: []byte("secret")}
+	s := &Server{verifier: tokensign.HMAC([]byte("secret"))}
 	if _, err := s.userIDFromCtx(context.Background()); err == nil {
 		t.Fatalf("want error on missing metadata")
 	}
@@ -86,10 +87,10 @@ func Test_userIDFromCtx_NoMetadata(t *testing.T) {
 func Test_userIDFromCtx_Expired(t *testing.T) {
 	t.Parallel()
 
-	s := &Server{signKey: []byte("secret")}
+	s := &Server{verifier: tokensign.HMAC([]byte("secret"))}
 	sub := uuid.Must(uuid.NewV4()).String()
 
-	j := makeJWT(t, sub, s.signKey, jwt.SigningMethodHS256, time.Now().UTC().Add(-2*time.Hour), -time.Hour)
+	j := makeJWT(t, sub, []byte("secret"), jwt.SigningMethodHS256, time.Now().UTC().Add(-2*time.Hour), -time.Hour)
 	ctx := ctxWithAuth(j)
 
 	if _, err := s.userIDFromCtx(ctx); err == nil {
@@ -100,8 +101,8 @@ func Test_userIDFromCtx_Expired(t *testing.T) {
 func Test_userIDFromCtx_BadSubject(t *testing.T) {
 	t.Parallel()
 
-	s := &Server{signKey: []byte("secret")}
-	j := makeJWT(t, "not-a-uuid", s.signKey, jwt.SigningMethodHS256, time.Now().UTC(), time.Hour)
+	s := &Server{verifier: tokensign.HMAC([]byte("secret"))}
+	j := makeJWT(t, "not-a-uuid", []byte("secret"), jwt.SigningMethodHS256, time.Now().UTC(), time.Hour)
 	ctx := ctxWithAuth(j)
 
 	if _, err := s.userIDFromCtx(ctx); err == nil {
@@ -112,10 +113,10 @@ func Test_userIDFromCtx_BadSubject(t *testing.T) {
 func Test_userIDFromCtx_WrongAlg(t *testing.T) {
 	t.Parallel()
 
-	s := &Server{signKey: []byte("secret")}
+	s := &Server{verifier: tokensign.HMAC([]byte("secret"))}
 	sub := uuid.Must(uuid.NewV4()).String()
 
-	j := makeJWT(t, sub, s.signKey, jwt.SigningMethodHS384, time.Now().UTC(), time.Hour)
+	j := makeJWT(t, sub, []byte("secret"), jwt.SigningMethodHS384, time.Now().UTC(), time.Hour)
 	ctx := ctxWithAuth(j)
 
 	if _, err := s.userIDFromCtx(ctx); err == nil {
@@ -126,7 +127,7 @@ func Test_userIDFromCtx_WrongAlg(t *testing.T) {
 func Test_userIDFromCtx_InvalidTokenString(t *testing.T) {
 	t.Parallel()
 
-	s := &Server{signKey: []byte("secret")}
+	s := &Server{verifier: tokensign.HMAC([]byte("secret"))}
 	ctx := ctxWithAuth("this-is-not-a-jwt")
 
 	if _, err := s.userIDFromCtx(ctx); err == nil {
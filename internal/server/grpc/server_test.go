@@ -1,13 +1,26 @@
 package grpcserver
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"io"
 	"net"
+	"strings"
 	"testing"
 	"time"
 
 	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/geoip"
+	"github.com/and161185/goph-keeper/internal/limiter"
+	"github.com/and161185/goph-keeper/internal/merkle"
 	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/and161185/goph-keeper/internal/repository/memory"
+	"github.com/and161185/goph-keeper/internal/service"
+	"github.com/and161185/goph-keeper/internal/tenant"
+	"github.com/and161185/goph-keeper/internal/tokensign"
 	"github.com/gofrs/uuid/v5"
 	"github.com/golang-jwt/jwt/v5"
 	"google.golang.org/grpc"
@@ -24,13 +37,13 @@ type fakeAuth struct {
 	id  uuid.UUID
 }
 
-func (f *fakeAuth) Register(context.Context, string, string) (string, error) {
+func (f *fakeAuth) Register(context.Context, string, string) (model.User, error) {
 	if f.id == uuid.Nil {
 		f.id = uuid.Must(uuid.NewV4())
 	}
-	return f.id.String(), nil
+	return model.User{ID: f.id, KekSalt: []byte("keksalt")}, nil
 }
-func (f *fakeAuth) LoginWithIP(context.Context, string, string, string) (model.Tokens, model.User, error) {
+func (f *fakeAuth) LoginWithIP(context.Context, string, string, string, string, []string) (model.Tokens, model.User, error) {
 	if f.id == uuid.Nil {
 		f.id = uuid.Must(uuid.NewV4())
 	}
@@ -39,29 +52,109 @@ func (f *fakeAuth) LoginWithIP(context.Context, string, string, string) (model.T
 	}, nil
 }
 func (f *fakeAuth) SetWrappedDEK(context.Context, uuid.UUID, []byte) error { return nil }
+func (f *fakeAuth) Refresh(context.Context, string) (model.Tokens, error) {
+	return model.Tokens{}, errs.ErrNotConfigured
+}
+func (f *fakeAuth) Sessions(context.Context, uuid.UUID) ([]model.Session, error) {
+	return nil, errs.ErrNotConfigured
+}
+func (f *fakeAuth) RevokeSession(context.Context, uuid.UUID, uuid.UUID) error {
+	return errs.ErrNotConfigured
+}
+func (f *fakeAuth) LoginHistory(context.Context, uuid.UUID, int) ([]model.LoginAttempt, error) {
+	return []model.LoginAttempt{{At: time.Now(), IPHash: []byte{1}, Success: true, Where: geoip.Location{Country: "SE"}}}, nil
+}
+func (f *fakeAuth) SetDuress(context.Context, uuid.UUID, string) error { return nil }
+func (f *fakeAuth) RemoveDuress(context.Context, uuid.UUID) error      { return nil }
+func (f *fakeAuth) Freeze(context.Context, uuid.UUID, string, string) (time.Time, error) {
+	return time.Now(), nil
+}
+func (f *fakeAuth) Unfreeze(context.Context, uuid.UUID, string, string) error { return nil }
+func (f *fakeAuth) FrozenAt(context.Context, uuid.UUID) (time.Time, error)    { return time.Time{}, nil }
+func (f *fakeAuth) ReplaceWrappedDEK(_ context.Context, _ uuid.UUID, prev, _ []byte) error {
+	if string(prev) != "current" {
+		return errs.ErrVersionConflict
+	}
+	return nil
+}
 
 type fakeItems struct{ lastSince int64 }
 
 func (f *fakeItems) Upsert(_ context.Context, _ uuid.UUID, ups []model.UpsertItem) ([]model.ItemVersion, error) {
 	return []model.ItemVersion{{ID: ups[0].ID, NewVer: ups[0].BaseVer + 1}}, nil
 }
+func (f *fakeItems) UpsertEach(_ context.Context, _ uuid.UUID, ups []model.UpsertItem) ([]model.ItemResult, error) {
+	res := make([]model.ItemResult, len(ups))
+	for i, up := range ups {
+		res[i] = model.ItemResult{ID: up.ID, Status: model.StatusOK, Ver: up.BaseVer + 1}
+	}
+	return res, nil
+}
 func (f *fakeItems) Delete(_ context.Context, _ uuid.UUID, id uuid.UUID, baseVer int64) (model.ItemVersion, error) {
 	return model.ItemVersion{ID: id, NewVer: baseVer + 1}, nil
 }
-func (f *fakeItems) GetChanges(_ context.Context, _ uuid.UUID, sinceVer int64) ([]model.Change, error) {
-	f.lastSince = sinceVer
-	return []model.Change{{ID: uuid.Must(uuid.NewV4()), Ver: sinceVer + 1}}, nil
+func (f *fakeItems) DeleteBatch(_ context.Context, _ uuid.UUID, refs []model.ItemRef) ([]model.ItemResult, error) {
+	res := make([]model.ItemResult, len(refs))
+	for i, r := range refs {
+		res[i] = model.ItemResult{ID: r.ID, Status: model.StatusOK, Ver: r.BaseVer + 1}
+		if r.BaseVer == 0 {
+			res[i] = model.ItemResult{ID: r.ID, Status: model.StatusNotFound}
+		}
+	}
+	if !model.AllOK(res) {
+		model.AbortOK(res)
+	}
+	return res, nil
+}
+func (f *fakeItems) ApplyChangeSet(ctx context.Context, u uuid.UUID, cs model.ChangeSet) (model.ChangeSetResult, error) {
+	res := model.ChangeSetResult{Upserts: make([]model.ItemResult, len(cs.Upserts))}
+	for i, up := range cs.Upserts {
+		res.Upserts[i] = model.ItemResult{ID: up.ID, Status: model.StatusOK, Ver: up.BaseVer + 1}
+	}
+	res.Deletes, _ = f.DeleteBatch(ctx, u, cs.Deletes)
+	if !res.Applied() {
+		model.AbortOK(res.Upserts)
+	}
+	return res, nil
+}
+func (f *fakeItems) GetChanges(_ context.Context, _ uuid.UUID, after model.ChangeCursor, _ int) ([]model.Change, error) {
+	f.lastSince = after.Ver
+	return []model.Change{{ID: uuid.Must(uuid.NewV4()), Ver: after.Ver + 1}}, nil
+}
+func (f *fakeItems) GetJournal(_ context.Context, _ uuid.UUID, afterSeq int64, _ int) ([]model.Change, error) {
+	return []model.Change{{ID: uuid.Must(uuid.NewV4()), Ver: 1, Seq: afterSeq + 1}}, nil
+}
+func (f *fakeItems) BeginSync(context.Context, uuid.UUID) (int64, error) {
+	return 0, nil
+}
+func (f *fakeItems) GetSnapshot(context.Context, uuid.UUID, int64, uuid.UUID, int) ([]model.Change, error) {
+	return nil, nil
 }
 func (f *fakeItems) GetOne(_ context.Context, _ uuid.UUID, id uuid.UUID) (*model.Item, error) {
 	return &model.Item{ID: id, Ver: 2, BlobEnc: []byte{1, 2, 3}}, nil
 }
+func (f *fakeItems) GetVersion(_ context.Context, _ uuid.UUID, id uuid.UUID, ver int64) (*model.Item, error) {
+	if ver > 2 {
+		return nil, errs.ErrNotFound
+	}
+	return &model.Item{ID: id, Ver: ver, BlobEnc: []byte{byte(ver)}}, nil
+}
+func (f *fakeItems) Stats(context.Context, uuid.UUID) (model.ItemStats, error) {
+	return model.ItemStats{Items: 4, Deleted: 1, MaxVer: 7, TotalBytes: 100}, nil
+}
+func (f *fakeItems) Export(context.Context, uuid.UUID, bool, func(model.Item) error) error {
+	return nil
+}
+func (f *fakeItems) VaultLeaves(context.Context, uuid.UUID) ([]merkle.Leaf, error) {
+	return []merkle.Leaf{merkle.NewLeaf("a", 1, false, []byte("x")), merkle.NewLeaf("b", 3, true, nil)}, nil
+}
 
 const bufSize = 1 << 20
 
-func startBufGRPC(t *testing.T, srv *Server) (*grpc.ClientConn, func()) {
+func startBufGRPC(t *testing.T, srv *Server, opts ...grpc.ServerOption) (*grpc.ClientConn, func()) {
 	t.Helper()
 	lis := bufconn.Listen(bufSize)
-	gs := grpc.NewServer()
+	gs := grpc.NewServer(opts...)
 	pb.RegisterGophKeeperServer(gs, srv)
 	go func() { _ = gs.Serve(lis) }()
 	dialer := func(context.Context, string) (net.Conn, error) { return lis.Dial() }
@@ -103,7 +196,7 @@ func TestServer_E2E_BasicFlow(t *testing.T) {
 	signKey := []byte("test-secret")
 	a := &fakeAuth{key: signKey, id: uuid.Must(uuid.NewV4())}
 	it := &fakeItems{}
-	srv := New(a, it, signKey)
+	srv := New(a, it, tokensign.HMAC(signKey))
 
 	cc, stop := startBufGRPC(t, srv)
 	defer stop()
@@ -165,12 +258,29 @@ func TestServer_E2E_BasicFlow(t *testing.T) {
 		t.Fatalf("get item: %v, resp=%+v", err, gi)
 	}
 
+	gir.SetVer(1)
+	gi, err = srv.GetItem(authIn, gir)
+	if err != nil || gi.GetVer() != 1 {
+		t.Fatalf("get item ver 1: %v, resp=%+v", err, gi)
+	}
+	gir.SetVer(5)
+	if _, err = srv.GetItem(authIn, gir); status.Code(err) != codes.NotFound {
+		t.Fatalf("want NotFound for unknown version, got %v", err)
+	}
+	gir.SetVer(-1)
+	if _, err = validated(srv.GetItem)(authIn, gir); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("want InvalidArgument for negative ver, got %v", err)
+	}
+
 	gcr := &pb.GetChangesRequest{}
 	gcr.SetSinceVer(0)
 	gc, err := srv.GetChanges(authIn, gcr)
 	if err != nil || len(gc.GetChanges()) != 1 || it.lastSince != 0 {
 		t.Fatalf("get changes: %v, resp=%+v lastSince=%d", err, gc, it.lastSince)
 	}
+	if gc.GetWatermark() != 1 || time.Since(gc.GetServerTime().AsTime()) > time.Minute {
+		t.Fatalf("sync metadata: watermark=%d server_time=%v", gc.GetWatermark(), gc.GetServerTime().AsTime())
+	}
 
 	dir := &pb.DeleteItemRequest{}
 	dir.SetId(itemID.String())
@@ -185,6 +295,14 @@ func TestServer_E2E_BasicFlow(t *testing.T) {
 	if _, err := srv.SetWrappedDEK(authIn, swDEKr); err != nil {
 		t.Fatalf("set wrapped: %v", err)
 	}
+	swDEKr.SetPreviousWrappedDek([]byte("stale"))
+	if _, err := srv.SetWrappedDEK(authIn, swDEKr); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("want FailedPrecondition on stale previous, got %v", err)
+	}
+	swDEKr.SetPreviousWrappedDek([]byte("current"))
+	if _, err := srv.SetWrappedDEK(authIn, swDEKr); err != nil {
+		t.Fatalf("replace wrapped: %v", err)
+	}
 }
 
 func Test_remoteIP_EmptyIsOk(t *testing.T) {
@@ -193,21 +311,54 @@ func Test_remoteIP_EmptyIsOk(t *testing.T) {
 	}
 }
 func Test_Register_EmptyFields(t *testing.T) {
-	s := &Server{signKey: []byte("k")}
-	_, err := s.Register(context.Background(), &pb.RegisterRequest{})
+	s := &Server{verifier: tokensign.HMAC([]byte("k"))}
+	_, err := validated(s.Register)(context.Background(), &pb.RegisterRequest{})
 	if st, ok := status.FromError(err); !ok || st.Code() != codes.InvalidArgument {
 		t.Fatalf("want InvalidArgument, got %v", err)
 	}
 }
+func TestServer_Register(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	signer := tokensign.HMAC([]byte("k"))
+	s := New(service.NewAuthService(memory.NewUserRepo(), signer, time.Minute, limiter.NewMemory(time.Minute, 5, time.Minute)), &fakeItems{}, signer)
+
+	rr := &pb.RegisterRequest{}
+	rr.SetUsername("alice")
+	rr.SetPassword("pw")
+	out, err := s.Register(ctx, rr)
+	if err != nil || out.GetUserId() == "" || out.GetKekSalt() != nil {
+		t.Fatalf("register: %v %v", out, err)
+	}
+	_, err = s.Register(ctx, rr)
+	if st := status.Convert(err); st.Code() != codes.AlreadyExists || errInfo(st).GetReason() != errs.ReasonAlreadyExists {
+		t.Fatalf("taken username: %v", err)
+	}
+
+	rr.SetUsername("bob")
+	rr.SetReturnKekSalt(true)
+	out, err = s.Register(ctx, rr)
+	if err != nil || len(out.GetKekSalt()) == 0 {
+		t.Fatalf("kek salt: %v %v", out, err)
+	}
+	lr := &pb.LoginRequest{}
+	lr.SetUsername("bob")
+	lr.SetPassword("pw")
+	login, err := s.Login(ctx, lr)
+	if err != nil || !bytes.Equal(login.GetKekSalt(), out.GetKekSalt()) {
+		t.Fatalf("login salt %x, register salt %x: %v", login.GetKekSalt(), out.GetKekSalt(), err)
+	}
+}
+
 func Test_UpsertItems_Unauthenticated(t *testing.T) {
-	s := &Server{signKey: []byte("k")}
+	s := &Server{verifier: tokensign.HMAC([]byte("k"))}
 	_, err := s.UpsertItems(context.Background(), &pb.UpsertItemsRequest{})
 	if st, ok := status.FromError(err); !ok || st.Code() != codes.Unauthenticated {
 		t.Fatalf("want Unauthenticated, got %v", err)
 	}
 }
 func Test_GetChanges_Unauthenticated(t *testing.T) {
-	s := &Server{signKey: []byte("k")}
+	s := &Server{verifier: tokensign.HMAC([]byte("k"))}
 
 	gcr := &pb.GetChangesRequest{}
 	gcr.SetSinceVer(0)
@@ -217,7 +368,7 @@ func Test_GetChanges_Unauthenticated(t *testing.T) {
 	}
 }
 func Test_GetItem_Unauthenticated(t *testing.T) {
-	s := &Server{signKey: []byte("k")}
+	s := &Server{verifier: tokensign.HMAC([]byte("k"))}
 	gir := &pb.GetItemRequest{}
 	gir.SetId("x")
 	_, err := s.GetItem(context.Background(), gir)
@@ -227,7 +378,7 @@ func Test_GetItem_Unauthenticated(t *testing.T) {
 }
 func Test_GetItem_BadID_WithAuth(t *testing.T) {
 	key := []byte("secret")
-	s := &Server{signKey: key}
+	s := &Server{verifier: tokensign.HMAC(key)}
 	sub := uuid.Must(uuid.NewV4()).String()
 	ctx := ctxAuth(jwtFor(t, sub, key, time.Hour))
 
@@ -238,9 +389,302 @@ func Test_GetItem_BadID_WithAuth(t *testing.T) {
 		t.Fatalf("want InvalidArgument, got %v", err)
 	}
 }
+func Test_GetStats(t *testing.T) {
+	key := []byte("secret")
+	s := New(nil, &fakeItems{}, tokensign.HMAC(key))
+	if _, err := s.GetStats(context.Background(), &pb.GetStatsRequest{}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("want Unauthenticated, got %v", err)
+	}
+	ctx := ctxAuth(jwtFor(t, uuid.Must(uuid.NewV4()).String(), key, time.Hour))
+	st, err := s.GetStats(ctx, &pb.GetStatsRequest{})
+	if err != nil || st.GetItems() != 4 || st.GetMaxVer() != 7 || st.GetTotalBytes() != 100 || st.HasLastUpdated() {
+		t.Fatalf("stats: %v %v", st, err)
+	}
+}
+
+func Test_GetLoginHistory(t *testing.T) {
+	key := []byte("secret")
+	s := New(&fakeAuth{}, &fakeItems{}, tokensign.HMAC(key))
+	if _, err := s.GetLoginHistory(context.Background(), &pb.GetLoginHistoryRequest{}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("want Unauthenticated, got %v", err)
+	}
+	ctx := ctxAuth(jwtFor(t, uuid.Must(uuid.NewV4()).String(), key, time.Hour))
+	resp, err := s.GetLoginHistory(ctx, &pb.GetLoginHistoryRequest{})
+	if err != nil || len(resp.GetAttempts()) != 1 || !resp.GetAttempts()[0].GetSuccess() || !resp.GetAttempts()[0].HasAt() ||
+		resp.GetAttempts()[0].GetCountry() != "SE" {
+		t.Fatalf("history: %v %v", resp, err)
+	}
+}
+
+// fullItems is a vault at its item cap.
+type fullItems struct{ fakeItems }
+
+func (fullItems) Upsert(context.Context, uuid.UUID, []model.UpsertItem) ([]model.ItemVersion, error) {
+	return nil, fmt.Errorf("%w: 10 live items + 1 new (max 10)", errs.ErrItemLimit)
+}
+func (fullItems) Stats(context.Context, uuid.UUID) (model.ItemStats, error) {
+	return model.ItemStats{Items: 12, Deleted: 2, MaxItems: 10}, nil
+}
+
+func Test_ItemLimit(t *testing.T) {
+	key := []byte("secret")
+	s := New(nil, &fullItems{}, tokensign.HMAC(key))
+	ctx := ctxAuth(jwtFor(t, uuid.Must(uuid.NewV4()).String(), key, time.Hour))
+
+	ui := &pb.UpsertItem{}
+	ui.SetId(uuid.Must(uuid.NewV7()).String())
+	eb := &pb.EncryptedBlob{}
+	eb.SetCiphertext([]byte{1})
+	ui.SetBlobEnc(eb)
+	uir := &pb.UpsertItemsRequest{}
+	uir.SetItems([]*pb.UpsertItem{ui})
+	_, err := s.UpsertItems(ctx, uir)
+	if st := status.Convert(err); st.Code() != codes.ResourceExhausted || errInfo(st).GetReason() != errs.ReasonItemLimit {
+		t.Fatalf("upsert at the cap: %v", err)
+	}
+
+	st, err := s.GetStats(ctx, &pb.GetStatsRequest{})
+	if err != nil || st.GetMaxItems() != 10 || st.GetItems()-st.GetDeleted() != 10 {
+		t.Fatalf("stats: %v %v", st, err)
+	}
+}
+
+// mixedSchemas holds items written in payload schemas 1 and 3.
+type mixedSchemas struct{ fakeItems }
+
+func (mixedSchemas) GetChanges(context.Context, uuid.UUID, model.ChangeCursor, int) ([]model.Change, error) {
+	return []model.Change{
+		{ID: uuid.Must(uuid.NewV4()), Ver: 1, BlobEnc: []byte("v1"), Hints: model.ItemHints{SizeClass: model.SizeSmall, SchemaVersion: 1}},
+		{ID: uuid.Must(uuid.NewV4()), Ver: 2, BlobEnc: []byte("v3"), Hints: model.ItemHints{SchemaVersion: 3}},
+	}, nil
+}
+
+func Test_GetChanges_MaxSchemaVersion(t *testing.T) {
+	key := []byte("secret")
+	s := New(nil, &mixedSchemas{}, tokensign.HMAC(key))
+	ctx := ctxAuth(jwtFor(t, uuid.Must(uuid.NewV4()).String(), key, time.Hour))
+
+	req := &pb.GetChangesRequest{}
+	out, err := s.GetChanges(ctx, req)
+	if err != nil || !out.GetChanges()[0].HasBlobEnc() || !out.GetChanges()[1].HasBlobEnc() {
+		t.Fatalf("unfiltered: %v %v", out, err)
+	}
+	req.SetMaxSchemaVersion(2)
+	out, err = s.GetChanges(ctx, req)
+	if err != nil || out.GetWatermark() != 2 {
+		t.Fatalf("filtered: %v %v", out, err)
+	}
+	old, newer := out.GetChanges()[0], out.GetChanges()[1]
+	if !old.HasBlobEnc() || old.GetHints().GetSizeClass() != pb.SizeClass_SIZE_CLASS_SMALL {
+		t.Fatalf("readable item: %v", old)
+	}
+	if newer.HasBlobEnc() || newer.GetHints().GetSchemaVersion() != 3 {
+		t.Fatalf("item of a newer schema: %v", newer)
+	}
+}
+
+func Test_GetChanges_Pages(t *testing.T) {
+	key := []byte("secret")
+	items := service.NewItemService(memory.NewItemRepo(), 100)
+	s := New(nil, items, tokensign.HMAC(key))
+	s.SetMaxChangesPage(3)
+	user := uuid.Must(uuid.NewV4())
+	ctx := ctxAuth(jwtFor(t, user.String(), key, time.Hour))
+
+	var ups []model.UpsertItem
+	for range 5 {
+		ups = append(ups, model.UpsertItem{ID: uuid.Must(uuid.NewV7()), BlobEnc: []byte{1}})
+	}
+	if _, err := items.Upsert(ctx, user, ups); err != nil {
+		t.Fatal(err)
+	}
+
+	// all five share version 1; pages of two walk them by id
+	req := &pb.GetChangesRequest{}
+	req.SetPageSize(2)
+	seen := map[string]bool{}
+	var sizes []int
+	for {
+		out, err := s.GetChanges(ctx, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sizes = append(sizes, len(out.GetChanges()))
+		for _, c := range out.GetChanges() {
+			seen[c.GetId()] = true
+		}
+		if out.GetNextPageToken() == "" {
+			if out.GetWatermark() != 1 {
+				t.Fatalf("watermark %d", out.GetWatermark())
+			}
+			break
+		}
+		req.SetPageToken(out.GetNextPageToken())
+	}
+	if fmt.Sprint(sizes) != "[2 2 1]" || len(seen) != 5 {
+		t.Fatalf("pages %v, %d distinct items", sizes, len(seen))
+	}
+
+	// the server's limit wins over a bigger page_size
+	req = &pb.GetChangesRequest{}
+	req.SetPageSize(50)
+	out, err := s.GetChanges(ctx, req)
+	if err != nil || len(out.GetChanges()) != 3 || out.GetNextPageToken() == "" {
+		t.Fatalf("capped page: %v %v", out, err)
+	}
+
+	req.SetPageToken("not a token")
+	if _, err := s.GetChanges(ctx, req); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("bad token: %v", err)
+	}
+}
+
+func Test_GetChanges_Journal(t *testing.T) {
+	key := []byte("secret")
+	items := service.NewItemService(memory.NewItemRepo(), 100)
+	s := New(nil, items, tokensign.HMAC(key))
+	user := uuid.Must(uuid.NewV4())
+	ctx := ctxAuth(jwtFor(t, user.String(), key, time.Hour))
+
+	hot, cold := uuid.Must(uuid.NewV7()), uuid.Must(uuid.NewV7())
+	if _, err := items.Upsert(ctx, user, []model.UpsertItem{{ID: cold, BlobEnc: []byte{1}}}); err != nil {
+		t.Fatal(err)
+	}
+	for base := range int64(3) {
+		if _, err := items.Upsert(ctx, user, []model.UpsertItem{{ID: hot, BaseVer: base, BlobEnc: []byte{1}}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	req := &pb.GetChangesRequest{}
+	req.SetSinceSeq(0)
+	out, err := s.GetChanges(ctx, req)
+	if err != nil || len(out.GetChanges()) != 2 || out.GetSeqWatermark() != 4 || out.GetWatermark() != 3 {
+		t.Fatalf("full read: %v %v", out, err)
+	}
+
+	// a version-1 edit after the version-3 one: since_ver would miss it
+	if _, err := items.Upsert(ctx, user, []model.UpsertItem{{ID: cold, BaseVer: 1, BlobEnc: []byte{2}}}); err != nil {
+		t.Fatal(err)
+	}
+	req.SetSinceSeq(out.GetSeqWatermark())
+	out, err = s.GetChanges(ctx, req)
+	if err != nil || len(out.GetChanges()) != 1 || out.GetChanges()[0].GetId() != cold.String() ||
+		out.GetChanges()[0].GetSeq() != 5 || out.GetSeqWatermark() != 5 {
+		t.Fatalf("since seq 4: %v %v", out, err)
+	}
+
+	// pages carry a seq token
+	req = &pb.GetChangesRequest{}
+	req.SetSinceSeq(0)
+	req.SetPageSize(1)
+	out, err = s.GetChanges(ctx, req)
+	if err != nil || len(out.GetChanges()) != 1 || out.GetChanges()[0].GetId() != hot.String() {
+		t.Fatalf("first page: %v %v", out, err)
+	}
+	req = &pb.GetChangesRequest{}
+	req.SetPageToken(out.GetNextPageToken())
+	out, err = s.GetChanges(ctx, req)
+	if err != nil || len(out.GetChanges()) != 1 || out.GetNextPageToken() != "" || !out.HasSeqWatermark() || out.GetSeqWatermark() != 5 {
+		t.Fatalf("second page: %v %v", out, err)
+	}
+
+	// version reads carry no seq watermark
+	out, err = s.GetChanges(ctx, &pb.GetChangesRequest{})
+	if err != nil || out.HasSeqWatermark() {
+		t.Fatalf("version read: %v %v", out, err)
+	}
+}
+
+func Test_UpsertItems_BestEffort(t *testing.T) {
+	key := []byte("secret")
+	items := service.NewItemService(memory.NewItemRepo(), 100)
+	s := New(nil, items, tokensign.HMAC(key))
+	user := uuid.Must(uuid.NewV4())
+	ctx := ctxAuth(jwtFor(t, user.String(), key, time.Hour))
+
+	old := uuid.Must(uuid.NewV7())
+	if _, err := items.Upsert(ctx, user, []model.UpsertItem{{ID: old, BlobEnc: []byte{1}}}); err != nil {
+		t.Fatal(err)
+	}
+	up := func(id uuid.UUID, base int64) *pb.UpsertItem {
+		eb := &pb.EncryptedBlob{}
+		eb.SetCiphertext([]byte{2})
+		u := &pb.UpsertItem{}
+		u.SetId(id.String())
+		u.SetBaseVer(base)
+		u.SetBlobEnc(eb)
+		return u
+	}
+	fresh := uuid.Must(uuid.NewV7())
+	req := &pb.UpsertItemsRequest{}
+	req.SetItems([]*pb.UpsertItem{up(old, 0), up(fresh, 0)}) // old is at version 1
+
+	if _, err := s.UpsertItems(ctx, req); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("atomic batch: want FailedPrecondition, got %v", err)
+	}
+	req.SetBestEffort(true)
+	out, err := s.UpsertItems(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs := out.GetItemResults()
+	if len(rs) != 2 || rs[0].GetStatus() != pb.ItemStatus_ITEM_STATUS_VERSION_CONFLICT || rs[0].GetVer() != 1 ||
+		rs[1].GetStatus() != pb.ItemStatus_ITEM_STATUS_OK || rs[1].GetVer() != 1 {
+		t.Fatalf("item results: %v", rs)
+	}
+	if len(out.GetResults()) != 1 || out.GetResults()[0].GetId() != fresh.String() {
+		t.Fatalf("results: %v", out.GetResults())
+	}
+	if it, err := items.GetOne(ctx, user, old); err != nil || it.Ver != 1 {
+		t.Fatalf("conflicting item changed: %+v %v", it, err)
+	}
+}
+
+func Test_pageToken(t *testing.T) {
+	c := model.ChangeCursor{Ver: 42, ID: uuid.Must(uuid.NewV7())}
+	if got, ok := decodePageToken(encodePageToken(c)); !ok || got != c {
+		t.Fatalf("round trip: %v %v", got, ok)
+	}
+	if _, ok := decodePageToken(encodePageToken(model.ChangeCursor{Ver: -1})); ok {
+		t.Fatal("negative version accepted")
+	}
+	if got, ok := decodeSeqToken(encodeSeqToken(7)); !ok || got != 7 {
+		t.Fatalf("seq round trip: %v %v", got, ok)
+	}
+	if _, ok := decodeSeqToken(encodePageToken(c)); ok {
+		t.Fatal("version token read as seq")
+	}
+	if _, ok := decodePageToken(encodeSeqToken(7)); ok {
+		t.Fatal("seq token read as version")
+	}
+}
+
+func Test_VerifyVault(t *testing.T) {
+	key := []byte("secret")
+	s := New(nil, &fakeItems{}, tokensign.HMAC(key))
+	if _, err := s.VerifyVault(context.Background(), &pb.VerifyVaultRequest{}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("want Unauthenticated, got %v", err)
+	}
+	ctx := ctxAuth(jwtFor(t, uuid.Must(uuid.NewV4()).String(), key, time.Hour))
+	leaves, _ := (&fakeItems{}).VaultLeaves(ctx, uuid.Nil)
+	want := merkle.Root(leaves)
+
+	vv, err := s.VerifyVault(ctx, &pb.VerifyVaultRequest{})
+	if err != nil || string(vv.GetRoot()) != string(want[:]) || vv.GetItems() != 2 || len(vv.GetLeaves()) != 0 {
+		t.Fatalf("verify: %v %v", vv, err)
+	}
+	req := &pb.VerifyVaultRequest{}
+	req.SetIncludeLeaves(true)
+	vv, err = s.VerifyVault(ctx, req)
+	h := leaves[1].Hash()
+	if err != nil || len(vv.GetLeaves()) != 2 || !vv.GetLeaves()[1].GetDeleted() || string(vv.GetLeaves()[1].GetHash()) != string(h[:]) {
+		t.Fatalf("verify with leaves: %v %v", vv, err)
+	}
+}
 func Test_DeleteItem_BadID_WithAuth(t *testing.T) {
 	key := []byte("secret")
-	s := &Server{signKey: key}
+	s := &Server{verifier: tokensign.HMAC(key)}
 	sub := uuid.Must(uuid.NewV4()).String()
 	ctx := ctxAuth(jwtFor(t, sub, key, time.Hour))
 
@@ -252,22 +696,111 @@ func Test_DeleteItem_BadID_WithAuth(t *testing.T) {
 		t.Fatalf("want InvalidArgument, got %v", err)
 	}
 }
+func Test_DeleteItems(t *testing.T) {
+	key := []byte("secret")
+	s := New(nil, &fakeItems{}, tokensign.HMAC(key))
+	ctx := ctxAuth(jwtFor(t, uuid.Must(uuid.NewV4()).String(), key, time.Hour))
+	ref := func(id string, base int64) *pb.ItemRef {
+		r := &pb.ItemRef{}
+		r.SetId(id)
+		r.SetBaseVer(base)
+		return r
+	}
+	a, b := uuid.Must(uuid.NewV4()).String(), uuid.Must(uuid.NewV4()).String()
+
+	req := &pb.DeleteItemsRequest{}
+	req.SetItems([]*pb.ItemRef{ref(a, 1), ref(b, 4)})
+	resp, err := s.DeleteItems(ctx, req)
+	if err != nil || !resp.GetApplied() || len(resp.GetResults()) != 2 {
+		t.Fatalf("DeleteItems: %v %v", resp, err)
+	}
+	if r := resp.GetResults()[1]; r.GetId() != b || r.GetStatus() != pb.ItemStatus_ITEM_STATUS_OK || r.GetVer() != 5 {
+		t.Fatalf("result: %v", r)
+	}
+
+	// the fake reports base 0 as missing
+	req.SetItems([]*pb.ItemRef{ref(a, 1), ref(b, 0)})
+	resp, err = s.DeleteItems(ctx, req)
+	if err != nil || resp.GetApplied() {
+		t.Fatalf("want a rolled-back batch, got %v %v", resp, err)
+	}
+	if got := []pb.ItemStatus{resp.GetResults()[0].GetStatus(), resp.GetResults()[1].GetStatus()}; got[0] != pb.ItemStatus_ITEM_STATUS_ABORTED || got[1] != pb.ItemStatus_ITEM_STATUS_NOT_FOUND {
+		t.Fatalf("statuses %v", got)
+	}
+
+	req.SetItems([]*pb.ItemRef{ref(a, 1), ref("nope", 1)})
+	if _, err := s.DeleteItems(ctx, req); status.Code(err) != codes.InvalidArgument || !strings.Contains(err.Error(), "item[1]") {
+		t.Fatalf("want InvalidArgument naming item[1], got %v", err)
+	}
+	if _, err := s.DeleteItems(context.Background(), req); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("want Unauthenticated, got %v", err)
+	}
+}
+
+func Test_ApplyChangeSet(t *testing.T) {
+	key := []byte("secret")
+	s := New(nil, &fakeItems{}, tokensign.HMAC(key))
+	ctx := ctxAuth(jwtFor(t, uuid.Must(uuid.NewV4()).String(), key, time.Hour))
+	ref := func(id string, base int64) *pb.ItemRef {
+		r := &pb.ItemRef{}
+		r.SetId(id)
+		r.SetBaseVer(base)
+		return r
+	}
+	a, b := uuid.Must(uuid.NewV7()).String(), uuid.Must(uuid.NewV4()).String()
+	up := &pb.UpsertItem{}
+	up.SetId(a)
+	up.SetBaseVer(2)
+	blob := &pb.EncryptedBlob{}
+	blob.SetCiphertext([]byte{1})
+	up.SetBlobEnc(blob)
+
+	req := &pb.ApplyChangeSetRequest{}
+	req.SetUpserts([]*pb.UpsertItem{up})
+	req.SetDeletes([]*pb.ItemRef{ref(b, 4)})
+	resp, err := s.ApplyChangeSet(ctx, req)
+	if err != nil || !resp.GetApplied() || len(resp.GetUpsertResults()) != 1 || len(resp.GetDeleteResults()) != 1 {
+		t.Fatalf("ApplyChangeSet: %v %v", resp, err)
+	}
+	if r := resp.GetUpsertResults()[0]; r.GetId() != a || r.GetVer() != 3 {
+		t.Fatalf("upsert result: %v", r)
+	}
+
+	// the fake reports base 0 as missing, which rolls back the upsert too
+	req.SetDeletes([]*pb.ItemRef{ref(b, 0)})
+	resp, err = s.ApplyChangeSet(ctx, req)
+	if err != nil || resp.GetApplied() || len(resp.GetWarnings()) != 0 {
+		t.Fatalf("want a rolled-back change set, got %v %v", resp, err)
+	}
+	if got := resp.GetUpsertResults()[0].GetStatus(); got != pb.ItemStatus_ITEM_STATUS_ABORTED {
+		t.Fatalf("upsert status %v", got)
+	}
+
+	req.SetDeletes([]*pb.ItemRef{ref("nope", 1)})
+	if _, err := s.ApplyChangeSet(ctx, req); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("want InvalidArgument, got %v", err)
+	}
+	if _, err := s.ApplyChangeSet(context.Background(), req); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("want Unauthenticated, got %v", err)
+	}
+}
+
 func Test_SetWrappedDEK_Empty_WithAuth(t *testing.T) {
 	key := []byte("secret")
-	s := &Server{signKey: key}
+	s := &Server{verifier: tokensign.HMAC(key)}
 	sub := uuid.Must(uuid.NewV4()).String()
 	ctx := ctxAuth(jwtFor(t, sub, key, time.Hour))
 
 	swDEKr := &pb.SetWrappedDEKRequest{}
 	swDEKr.SetWrappedDek(nil)
-	_, err := s.SetWrappedDEK(ctx, swDEKr)
+	_, err := validated(s.SetWrappedDEK)(ctx, swDEKr)
 	if st, ok := status.FromError(err); !ok || st.Code() != codes.InvalidArgument {
 		t.Fatalf("want InvalidArgument, got %v", err)
 	}
 }
 func Test_UpsertItems_BadItems_WithAuth(t *testing.T) {
 	key := []byte("secret")
-	s := &Server{signKey: key}
+	s := &Server{verifier: tokensign.HMAC(key)}
 	sub := uuid.Must(uuid.NewV4()).String()
 	ctx := ctxAuth(jwtFor(t, sub, key, time.Hour))
 
@@ -296,7 +829,7 @@ func Test_bearerTokenFromMD_MultipleHeaders_CaseInsensitive_Spaces(t *testing.T)
 func Test_userIDFromCtx_NotBeforeInFuture(t *testing.T) {
 	t.Parallel()
 	key := []byte("k")
-	s := &Server{signKey: key}
+	s := &Server{verifier: tokensign.HMAC(key)}
 	sub := uuid.Must(uuid.NewV4()).String()
 	nbf := time.Now().UTC().Add(10 * time.Minute)
 	claims := jwt.RegisteredClaims{
@@ -324,7 +857,7 @@ func Test_userIDFromCtx_WrongKeySignature(t *testing.T) {
 		ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
 	}
 	tok, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(signerKey)
-	s := &Server{signKey: verifyKey}
+	s := &Server{verifier: tokensign.HMAC(verifyKey)}
 	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+tok))
 	if _, err := s.userIDFromCtx(ctx); err == nil {
 		t.Fatalf("expected invalid signature error")
@@ -344,7 +877,7 @@ func Test_remoteIP_WithPeer(t *testing.T) {
 }
 func Test_SetWrappedDEK_Unauthenticated(t *testing.T) {
 	t.Parallel()
-	s := &Server{signKey: []byte("k")}
+	s := &Server{verifier: tokensign.HMAC([]byte("k"))}
 	_, err := s.SetWrappedDEK(context.Background(), nil)
 	if st, ok := status.FromError(err); !ok || st.Code() != codes.Unauthenticated {
 		t.Fatalf("want Unauthenticated, got %v", err)
@@ -363,7 +896,7 @@ func Test_bearerTokenFromMD_NoBearerAmongMany(t *testing.T) {
 func Test_userIDFromCtx_LeewayAllowsSmallClockSkew(t *testing.T) {
 	t.Parallel()
 	key := []byte("k")
-	s := &Server{signKey: key}
+	s := &Server{verifier: tokensign.HMAC(key)}
 	sub := uuid.Must(uuid.NewV4()).String()
 	now := time.Now().UTC()
 	claims := jwt.RegisteredClaims{
@@ -378,3 +911,84 @@ func Test_userIDFromCtx_LeewayAllowsSmallClockSkew(t *testing.T) {
 		t.Fatalf("unexpected leeway validation error: %v", err)
 	}
 }
+
+// bigItems serves every item with blob, noting the tenant it was asked in.
+type bigItems struct {
+	fakeItems
+	blob   []byte
+	tenant string
+}
+
+func (f *bigItems) GetOne(ctx context.Context, _ uuid.UUID, id uuid.UUID) (*model.Item, error) {
+	f.tenant = tenant.FromContext(ctx)
+	return &model.Item{ID: id, Ver: 4, BlobEnc: f.blob}, nil
+}
+
+func TestServer_GetItemStream(t *testing.T) {
+	t.Parallel()
+	blob := make([]byte, 2*streamChunk+1000)
+	for i := range blob {
+		blob[i] = byte(i % 251)
+	}
+	items := &bigItems{blob: blob}
+	signer := tokensign.HMAC([]byte("k"))
+	reg, err := tenant.New("", []tenant.Tenant{{ID: "acme"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rec recordedEvents
+	cc, stop := startBufGRPC(t, New(nil, items, signer),
+		grpc.ChainStreamInterceptor(TenantStream(reg, signer), AuditStream(&rec, signer)))
+	defer stop()
+
+	tok, err := tokensign.Issue(context.Background(), signer, tokensign.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{Subject: uuid.Must(uuid.NewV4()).String(), ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))},
+		Tenant:           "acme",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+tok)
+	id := uuid.Must(uuid.NewV4()).String()
+	req := &pb.GetItemRequest{}
+	req.SetId(id)
+	stream, err := pb.NewGophKeeperClient(cc).GetItemStream(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []byte
+	var sizes []int
+	for {
+		c, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(sizes) == 0 && (c.GetId() != id || c.GetVer() != 4 || c.GetTotalSize() != int64(len(blob))) {
+			t.Fatalf("first chunk: %v", c)
+		}
+		sizes = append(sizes, len(c.GetData()))
+		got = append(got, c.GetData()...)
+	}
+	if !bytes.Equal(got, blob) || len(sizes) != 3 || sizes[0] != streamChunk || sizes[2] != 1000 {
+		t.Fatalf("chunks %v, reassembled %d bytes", sizes, len(got))
+	}
+	if items.tenant != "acme" {
+		t.Fatalf("handler ran in tenant %q", items.tenant)
+	}
+	if len(rec) != 1 || rec[0].Action != "item.read" || rec[0].Target != id || rec[0].Tenant != "acme" || rec[0].Code != "OK" {
+		t.Fatalf("audit %+v", rec)
+	}
+
+	bad := &pb.GetItemRequest{}
+	bad.SetId("nope")
+	stream, err = pb.NewGophKeeperClient(cc).GetItemStream(ctx, bad)
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("bad id: %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+package grpcserver
+
+import (
+	"context"
+
+	"github.com/gofrs/uuid/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/model"
+)
+
+// maxDeviceLabel bounds the device label a client gives at login, in bytes.
+const maxDeviceLabel = 100
+
+// sessionsOff is how the session RPCs report a server without refresh
+// tokens.
+var sessionsOff = remap(errs.ErrNotConfigured, errs.ReasonNotConfigured, "sessions are disabled on this server")
+
+func toProtoSession(s model.Session, current bool) *pb.Session {
+	out := &pb.Session{}
+	out.SetId(s.ID.String())
+	out.SetLabel(s.Label)
+	out.SetCreatedAt(timestamppb.New(s.CreatedAt))
+	out.SetLastUsedAt(timestamppb.New(s.LastUsedAt))
+	out.SetExpiresAt(timestamppb.New(s.ExpiresAt))
+	out.SetCurrent(current)
+	out.SetScopes(s.Scopes)
+	return out
+}
+
+// Refresh issues new tokens for a refresh token.
+func (s *Server) Refresh(ctx context.Context, req *pb.RefreshRequest) (*pb.RefreshResponse, error) {
+	tok, err := s.auth.Refresh(ctx, req.GetRefreshToken())
+	if err != nil {
+		return nil, toStatus("refresh", err, sessionsOff,
+			remap(errs.ErrInvalidArgument, errs.ReasonInvalidArgument, "empty refresh token"),
+			remap(errs.ErrUnauthorized, errs.ReasonUnauthenticated, "invalid refresh token"))
+	}
+	resp := &pb.RefreshResponse{}
+	resp.SetAccessToken(tok.AccessToken)
+	resp.SetRefreshToken(tok.RefreshToken)
+	return resp, nil
+}
+
+// ListSessions returns the caller's sessions, marking the one the call's
+// token belongs to.
+func (s *Server) ListSessions(ctx context.Context, _ *pb.ListSessionsRequest) (*pb.ListSessionsResponse, error) {
+	claims, err := bearerClaims(ctx, s.verifier)
+	if err != nil {
+		return nil, statusError(codes.Unauthenticated, errs.ReasonUnauthenticated, "no auth")
+	}
+	userID, err := uuid.FromString(claims.Subject)
+	if err != nil {
+		return nil, statusError(codes.Unauthenticated, errs.ReasonUnauthenticated, "no auth")
+	}
+	sessions, err := s.auth.Sessions(ctx, userID)
+	if err != nil {
+		return nil, toStatus("list sessions", err, sessionsOff)
+	}
+	out := make([]*pb.Session, 0, len(sessions))
+	for _, sess := range sessions {
+		out = append(out, toProtoSession(sess, sess.ID.String() == claims.Session))
+	}
+	resp := &pb.ListSessionsResponse{}
+	resp.SetSessions(out)
+	return resp, nil
+}
+
+// RevokeSession ends one of the caller's sessions.
+func (s *Server) RevokeSession(ctx context.Context, req *pb.RevokeSessionRequest) (*pb.RevokeSessionResponse, error) {
+	userID, err := s.userIDFromCtx(ctx)
+	if err != nil {
+		return nil, statusError(codes.Unauthenticated, errs.ReasonUnauthenticated, "no auth")
+	}
+	id, err := uuid.FromString(req.GetId())
+	if err != nil {
+		return nil, statusError(codes.InvalidArgument, errs.ReasonInvalidArgument, "bad session id")
+	}
+	if err := s.auth.RevokeSession(ctx, userID, id); err != nil {
+		return nil, toStatus("revoke session", err, sessionsOff,
+			remap(errs.ErrNotFound, errs.ReasonNotFound, "no such session"))
+	}
+	return &pb.RevokeSessionResponse{}, nil
+}
@@ -0,0 +1,93 @@
+package grpcserver
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/internal/limiter"
+	"github.com/and161185/goph-keeper/internal/repository/memory"
+	"github.com/and161185/goph-keeper/internal/service"
+	"github.com/and161185/goph-keeper/internal/tokensign"
+)
+
+func TestServer_Sessions(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	signer := tokensign.HMAC([]byte("k"))
+	auth := service.NewAuthService(memory.NewUserRepo(), signer, time.Minute, limiter.NewMemory(time.Minute, 5, time.Minute))
+	s := New(auth, &fakeItems{}, signer)
+
+	if _, err := s.Refresh(ctx, &pb.RefreshRequest{}); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("sessions off: %v", err)
+	}
+	auth.SetSessions(memory.NewSessionRepo(), time.Hour)
+
+	rr := &pb.RegisterRequest{}
+	rr.SetUsername("alice")
+	rr.SetPassword("pw")
+	if _, err := s.Register(ctx, rr); err != nil {
+		t.Fatal(err)
+	}
+	login := func(label string) *pb.LoginResponse {
+		t.Helper()
+		lr := &pb.LoginRequest{}
+		lr.SetUsername("alice")
+		lr.SetPassword("pw")
+		lr.SetDeviceLabel(label)
+		resp, err := s.Login(ctx, lr)
+		if err != nil || resp.GetRefreshToken() == "" {
+			t.Fatalf("login %q: %v %v", label, resp, err)
+		}
+		return resp
+	}
+	laptop, phone := login("laptop"), login("phone")
+
+	rf := &pb.RefreshRequest{}
+	rf.SetRefreshToken(laptop.GetRefreshToken())
+	fresh, err := s.Refresh(ctx, rf)
+	if err != nil || fresh.GetAccessToken() == "" || fresh.GetRefreshToken() == laptop.GetRefreshToken() {
+		t.Fatalf("refresh: %v %v", fresh, err)
+	}
+	if _, err := s.Refresh(ctx, rf); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("used refresh token: %v", err)
+	}
+
+	list, err := s.ListSessions(ctxAuth(fresh.GetAccessToken()), &pb.ListSessionsRequest{})
+	if err != nil || len(list.GetSessions()) != 2 {
+		t.Fatalf("list: %v %v", list, err)
+	}
+	first, second := list.GetSessions()[0], list.GetSessions()[1]
+	if first.GetLabel() != "laptop" || !first.GetCurrent() || second.GetLabel() != "phone" || second.GetCurrent() {
+		t.Fatalf("laptop was used last and made the call: %v", list)
+	}
+
+	rv := &pb.RevokeSessionRequest{}
+	rv.SetId(second.GetId())
+	if _, err := s.RevokeSession(ctxAuth(fresh.GetAccessToken()), rv); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	rf.SetRefreshToken(phone.GetRefreshToken())
+	if _, err := s.Refresh(ctx, rf); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("revoked session refreshed: %v", err)
+	}
+	if _, err := s.RevokeSession(ctxAuth(fresh.GetAccessToken()), rv); status.Code(err) != codes.NotFound {
+		t.Fatalf("revoke twice: %v", err)
+	}
+	if _, err := s.RevokeSession(ctx, rv); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("revoke without a token: %v", err)
+	}
+
+	lr := &pb.LoginRequest{}
+	lr.SetUsername("alice")
+	lr.SetPassword("pw")
+	lr.SetDeviceLabel(strings.Repeat("x", maxDeviceLabel+1))
+	if _, err := validated(s.Login)(ctx, lr); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("long label: %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+package grpcserver
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/internal/convert"
+	"github.com/and161185/goph-keeper/internal/errs"
+)
+
+// BeginSync pins the caller's vault at its newest journal entry. The
+// session is only that position: item history keeps every version it
+// needs, so nothing is held open on the server between pages.
+func (s *Server) BeginSync(ctx context.Context, _ *pb.BeginSyncRequest) (*pb.BeginSyncResponse, error) {
+	userID, err := s.userIDFromCtx(ctx)
+	if err != nil {
+		return nil, statusError(codes.Unauthenticated, errs.ReasonUnauthenticated, "no auth")
+	}
+	now := time.Now()
+	seq, err := s.items.BeginSync(ctx, userID)
+	if err != nil {
+		return nil, toStatus("begin sync", err)
+	}
+	out := &pb.BeginSyncResponse{}
+	out.SetSyncSession(encodeSyncSession(userID, seq))
+	out.SetSeqWatermark(seq)
+	out.SetServerTime(timestamppb.New(now))
+	return out, nil
+}
+
+// snapshotChanges is GetChanges for a sync session: a page of the vault as
+// it stood when the session began, by item id.
+func (s *Server) snapshotChanges(ctx context.Context, userID uuid.UUID, req *pb.GetChangesRequest) (*pb.GetChangesResponse, error) {
+	seq, ok := decodeSyncSession(req.GetSyncSession(), userID)
+	if !ok {
+		return nil, statusError(codes.InvalidArgument, errs.ReasonInvalidArgument, "bad sync_session")
+	}
+	var after uuid.UUID
+	if tok := req.GetPageToken(); tok != "" {
+		if after, ok = decodeIDToken(tok); !ok {
+			return nil, statusError(codes.InvalidArgument, errs.ReasonInvalidArgument, "bad page_token")
+		}
+	}
+	size := s.pageSize(req.GetPageSize())
+
+	now := time.Now()
+	cs, err := s.items.GetSnapshot(ctx, userID, seq, after, size+1)
+	if err != nil {
+		return nil, toStatus("get snapshot", err)
+	}
+	var next string
+	if len(cs) > size {
+		cs = cs[:size]
+		next = encodeIDToken(cs[size-1].ID)
+	}
+	var watermark int64
+	maxSchema := req.GetMaxSchemaVersion()
+	for i, c := range cs {
+		watermark = max(watermark, c.Ver)
+		if maxSchema > 0 && c.Hints.SchemaVersion > maxSchema {
+			cs[i].BlobEnc = nil
+		}
+	}
+
+	gcr := &pb.GetChangesResponse{}
+	gcr.SetChanges(convert.ToProtoChanges(cs))
+	gcr.SetServerTime(timestamppb.New(now))
+	gcr.SetWatermark(watermark)
+	gcr.SetNextPageToken(next)
+	gcr.SetSeqWatermark(seq)
+	return gcr, nil
+}
+
+// encodeSyncSession packs the session's owner and journal position. It is
+// not secret: a caller can only read its own vault, at any position.
+func encodeSyncSession(userID uuid.UUID, seq int64) string {
+	b := binary.BigEndian.AppendUint64(append(make([]byte, 0, 24), userID[:]...), uint64(seq))
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodeSyncSession refuses sessions of other users, which a client mixing
+// up accounts would otherwise read as an empty vault.
+func decodeSyncSession(tok string, userID uuid.UUID) (int64, bool) {
+	b, err := base64.RawURLEncoding.DecodeString(tok)
+	if err != nil || len(b) != 24 || uuid.UUID(b[:16]) != userID {
+		return 0, false
+	}
+	seq := int64(binary.BigEndian.Uint64(b[16:]))
+	return seq, seq >= 0
+}
+
+// encodeIDToken packs a snapshot page position: the last item id sent.
+func encodeIDToken(id uuid.UUID) string {
+	return base64.RawURLEncoding.EncodeToString(id[:])
+}
+
+func decodeIDToken(tok string) (uuid.UUID, bool) {
+	b, err := base64.RawURLEncoding.DecodeString(tok)
+	if err != nil || len(b) != 16 {
+		return uuid.Nil, false
+	}
+	return uuid.UUID(b), true
+}
@@ -0,0 +1,75 @@
+package grpcserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/and161185/goph-keeper/internal/repository/memory"
+	"github.com/and161185/goph-keeper/internal/service"
+	"github.com/and161185/goph-keeper/internal/tokensign"
+)
+
+func TestServer_BeginSync(t *testing.T) {
+	t.Parallel()
+	key := []byte("k")
+	repo := memory.NewItemRepo()
+	cc, stop := startBufGRPC(t, New(nil, service.NewItemService(repo, 100), tokensign.HMAC(key)))
+	defer stop()
+	cli := pb.NewGophKeeperClient(cc)
+
+	user := uuid.Must(uuid.NewV4())
+	ctx := context.Background()
+	ids := []uuid.UUID{{1}, {2}, {3}}
+	for _, id := range ids {
+		if _, err := repo.UpsertBatch(ctx, user, []model.UpsertItem{{ID: id, BlobEnc: []byte{1}}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	authed := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+jwtFor(t, user.String(), key, time.Hour))
+
+	begun, err := cli.BeginSync(authed, &pb.BeginSyncRequest{})
+	if err != nil || begun.GetSeqWatermark() != 3 {
+		t.Fatalf("BeginSync: %v %v", begun, err)
+	}
+	req := &pb.GetChangesRequest{}
+	req.SetSyncSession(begun.GetSyncSession())
+	req.SetPageSize(2)
+	first, err := cli.GetChanges(authed, req)
+	if err != nil || len(first.GetChanges()) != 2 || first.GetNextPageToken() == "" {
+		t.Fatalf("first page: %v %v", first, err)
+	}
+
+	// another device writes between the pages
+	if _, err := repo.UpsertBatch(ctx, user, []model.UpsertItem{{ID: ids[2], BaseVer: 1, BlobEnc: []byte{2}}, {ID: uuid.UUID{4}, BlobEnc: []byte{1}}}); err != nil {
+		t.Fatal(err)
+	}
+	req.SetPageToken(first.GetNextPageToken())
+	last, err := cli.GetChanges(authed, req)
+	if err != nil || last.GetNextPageToken() != "" || last.GetSeqWatermark() != 3 {
+		t.Fatalf("last page: %v %v", last, err)
+	}
+	if got := last.GetChanges(); len(got) != 1 || got[0].GetId() != ids[2].String() || got[0].GetVer() != 1 || got[0].GetBlobEnc().GetCiphertext()[0] != 1 {
+		t.Fatalf("page read after a write must keep the session's state: %v", got)
+	}
+
+	// the writes follow through the journal
+	since := &pb.GetChangesRequest{}
+	since.SetSinceSeq(begun.GetSeqWatermark())
+	if out, err := cli.GetChanges(authed, since); err != nil || len(out.GetChanges()) != 2 {
+		t.Fatalf("after the session: %v %v", out, err)
+	}
+
+	other := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+jwtFor(t, uuid.Must(uuid.NewV4()).String(), key, time.Hour))
+	req.SetPageToken("")
+	if _, err := cli.GetChanges(other, req); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("another user's session: %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+package grpcserver
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/tenant"
+	"github.com/and161185/goph-keeper/internal/tokensign"
+)
+
+// TenantUnary returns a unary server interceptor that scopes the request
+// context to a tenant. Requests with a valid token belong to the token's
+// tenant; the others (Register, Login) to the tenant selected by the TLS
+// server name, or tenant.Default. A token presented on another tenant's host
+// name is refused, so a stolen token cannot be replayed across tenants.
+func TenantUnary(reg *tenant.Registry, v tokensign.Verifier) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, next grpc.UnaryHandler) (any, error) {
+		id, err := resolveTenant(ctx, reg, v)
+		if err != nil {
+			return nil, err
+		}
+		return next(tenant.WithID(ctx, id), req)
+	}
+}
+
+// TenantStream is TenantUnary for streaming RPCs.
+func TenantStream(reg *tenant.Registry, v tokensign.Verifier) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, next grpc.StreamHandler) error {
+		id, err := resolveTenant(ss.Context(), reg, v)
+		if err != nil {
+			return err
+		}
+		return next(srv, &ctxStream{ServerStream: ss, ctx: tenant.WithID(ss.Context(), id)})
+	}
+}
+
+func resolveTenant(ctx context.Context, reg *tenant.Registry, v tokensign.Verifier) (string, error) {
+	byHost, hostKnown := reg.FromHost(serverName(ctx))
+	if _, err := bearerTokenFromMD(ctx); err != nil {
+		if hostKnown {
+			return byHost, nil
+		}
+		return tenant.Default, nil
+	}
+	claims, err := bearerClaims(ctx, v)
+	if err != nil {
+		return tenant.Default, nil // the handler rejects the token itself
+	}
+	id := claims.Tenant
+	if id == "" {
+		id = tenant.Default
+	}
+	if !reg.Known(id) {
+		return "", statusError(codes.Unauthenticated, errs.ReasonUnknownTenant, "unknown tenant")
+	}
+	if hostKnown && byHost != id {
+		return "", statusError(codes.PermissionDenied, errs.ReasonTenantMismatch, "token belongs to another tenant")
+	}
+	return id, nil
+}
+
+// serverName returns the SNI host name the client connected with.
+func serverName(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	if ti, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+		return ti.State.ServerName
+	}
+	return ""
+}
@@ -0,0 +1,76 @@
+package grpcserver
+
+import (
+	"context"
+	"crypto/tls"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/and161185/goph-keeper/internal/tenant"
+	"github.com/and161185/goph-keeper/internal/tokensign"
+)
+
+func TestTenantUnary(t *testing.T) {
+	t.Parallel()
+	reg, err := tenant.New("gk.example", []tenant.Tenant{
+		{ID: "acme", Hosts: []string{"vault.acme.test"}},
+		{ID: "globex"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := tokensign.HMAC([]byte("k"))
+	token := func(tid string) string {
+		s, err := tokensign.Issue(context.Background(), signer, tokensign.Claims{
+			RegisteredClaims: jwt.RegisteredClaims{Subject: "u", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))},
+			Tenant:           tid,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	call := func(sni, tok string) (string, error) {
+		ctx := context.Background()
+		if sni != "" {
+			ctx = peer.NewContext(ctx, &peer.Peer{AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{ServerName: sni}}})
+		}
+		if tok != "" {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer "+tok))
+		}
+		var got string
+		_, err := TenantUnary(reg, signer)(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, _ any) (any, error) {
+			got = tenant.FromContext(ctx)
+			return nil, nil
+		})
+		return got, err
+	}
+
+	for _, tc := range []struct {
+		name, sni, tok, want string
+		code                 codes.Code
+	}{
+		{name: "no sni", want: tenant.Default},
+		{name: "unknown host", sni: "other.test", want: tenant.Default},
+		{name: "listed host", sni: "vault.acme.test", want: "acme"},
+		{name: "domain subhost", sni: "GLOBEX.gk.example", want: "globex"},
+		{name: "token wins without host", tok: token("acme"), want: "acme"},
+		{name: "legacy token", sni: "other.test", tok: token(""), want: tenant.Default},
+		{name: "matching host", sni: "acme.gk.example", tok: token("acme"), want: "acme"},
+		{name: "cross-tenant replay", sni: "globex.gk.example", tok: token("acme"), code: codes.PermissionDenied},
+		{name: "removed tenant", tok: token("initech"), code: codes.Unauthenticated},
+	} {
+		got, err := call(tc.sni, tc.tok)
+		if status.Code(err) != tc.code || got != tc.want {
+			t.Errorf("%s: got %q, %v", tc.name, got, err)
+		}
+	}
+}
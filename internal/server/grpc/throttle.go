@@ -0,0 +1,92 @@
+package grpcserver
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/tenant"
+	"github.com/and161185/goph-keeper/internal/tokensign"
+)
+
+// Throttler meters users' calls; *throttle.Throttle implements it.
+type Throttler interface {
+	Allow(tenant string, user uuid.UUID) time.Duration
+	Charge(tenant string, user uuid.UUID, n int)
+}
+
+// throttledError tells the client to come back after wait.
+func throttledError(wait time.Duration) error {
+	st := status.New(codes.ResourceExhausted, "too many requests, retry later")
+	return withDetails(st, errorInfo(errs.ReasonThrottled), &errdetails.RetryInfo{RetryDelay: durationpb.New(wait)}).Err()
+}
+
+// msgSize is the encoded size of a request or response message.
+func msgSize(m any) int {
+	if pm, ok := m.(proto.Message); ok {
+		return proto.Size(pm)
+	}
+	return 0
+}
+
+// ThrottleUnary returns a unary server interceptor enforcing per-user
+// limits on authenticated calls; Register and Login are left to the login
+// limiter. It must run inside TenantUnary.
+func ThrottleUnary(t Throttler, v tokensign.Verifier) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, next grpc.UnaryHandler) (any, error) {
+		user, err := verifyBearer(ctx, v)
+		if err != nil {
+			return next(ctx, req)
+		}
+		tid := tenant.FromContext(ctx)
+		if wait := t.Allow(tid, user); wait > 0 {
+			return nil, throttledError(wait)
+		}
+		resp, err := next(ctx, req)
+		t.Charge(tid, user, msgSize(req)+msgSize(resp))
+		return resp, err
+	}
+}
+
+// ThrottleStream is ThrottleUnary for streaming RPCs. A stream is admitted
+// as one request and charged for every message; it is not cut off midway.
+func ThrottleStream(t Throttler, v tokensign.Verifier) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, next grpc.StreamHandler) error {
+		user, err := verifyBearer(ss.Context(), v)
+		if err != nil {
+			return next(srv, ss)
+		}
+		tid := tenant.FromContext(ss.Context())
+		if wait := t.Allow(tid, user); wait > 0 {
+			return throttledError(wait)
+		}
+		return next(srv, &meteredStream{ServerStream: ss, charge: func(n int) { t.Charge(tid, user, n) }})
+	}
+}
+
+// meteredStream charges every message it carries.
+type meteredStream struct {
+	grpc.ServerStream
+	charge func(n int)
+}
+
+func (s *meteredStream) SendMsg(m any) error {
+	s.charge(msgSize(m))
+	return s.ServerStream.SendMsg(m)
+}
+
+func (s *meteredStream) RecvMsg(m any) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.charge(msgSize(m))
+	}
+	return err
+}
@@ -0,0 +1,88 @@
+package grpcserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/tenant"
+	"github.com/and161185/goph-keeper/internal/tokensign"
+)
+
+type fakeThrottler struct {
+	wait    time.Duration
+	allowed []uuid.UUID
+	charged int
+	tenant  string
+}
+
+func (f *fakeThrottler) Allow(tid string, user uuid.UUID) time.Duration {
+	f.tenant = tid
+	if f.wait == 0 {
+		f.allowed = append(f.allowed, user)
+	}
+	return f.wait
+}
+
+func (f *fakeThrottler) Charge(_ string, _ uuid.UUID, n int) { f.charged += n }
+
+func TestThrottleUnary(t *testing.T) {
+	t.Parallel()
+	key := []byte("k")
+	uid := uuid.Must(uuid.NewV4())
+	tok := makeJWT(t, uid.String(), key, jwt.SigningMethodHS256, time.Now(), time.Minute)
+	ctx := tenant.WithID(ctxWithAuth(tok), "acme")
+	req := &pb.GetItemRequest{}
+	req.SetId("item-1")
+	resp := &pb.GetItemResponse{}
+	resp.SetId("item-1")
+	handler := func(context.Context, any) (any, error) { return resp, nil }
+	info := &grpc.UnaryServerInfo{}
+
+	f := &fakeThrottler{}
+	ic := ThrottleUnary(f, tokensign.HMAC(key))
+	if _, err := ic(ctx, req, info, handler); err != nil {
+		t.Fatal(err)
+	}
+	if len(f.allowed) != 1 || f.allowed[0] != uid || f.tenant != "acme" || f.charged != proto.Size(req)+proto.Size(resp) {
+		t.Fatalf("allowed %v in %q, charged %d", f.allowed, f.tenant, f.charged)
+	}
+
+	// calls without a valid token are not metered
+	if _, err := ic(context.Background(), &pb.LoginRequest{}, info, handler); err != nil || len(f.allowed) != 1 {
+		t.Fatalf("anonymous: %v", err)
+	}
+
+	f.wait = 1500 * time.Millisecond
+	_, err := ic(ctx, req, info, func(context.Context, any) (any, error) {
+		t.Fatal("throttled call reached the handler")
+		return nil, nil
+	})
+	st := status.Convert(err)
+	if st.Code() != codes.ResourceExhausted {
+		t.Fatalf("throttled: %v", err)
+	}
+	var reason string
+	var retry time.Duration
+	for _, d := range st.Details() {
+		switch d := d.(type) {
+		case *errdetails.ErrorInfo:
+			reason = d.GetReason()
+		case *errdetails.RetryInfo:
+			retry = d.GetRetryDelay().AsDuration()
+		}
+	}
+	if reason != errs.ReasonThrottled || retry != f.wait {
+		t.Fatalf("details: reason %q, retry %s", reason, retry)
+	}
+}
@@ -0,0 +1,278 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gofrs/uuid/v5"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/ipfilter"
+	"github.com/and161185/goph-keeper/internal/model"
+)
+
+// Limits bounds request payloads; zero fields take the defaults below.
+type Limits struct {
+	MaxBatch int // items per UpsertItems, DeleteItems or ApplyChangeSet call
+	MaxBlob  int // bytes of one item's ciphertext
+}
+
+const (
+	defaultMaxBatch = 1000
+	defaultMaxBlob  = 1 << 20
+	maxDeviceName   = 100
+	maxUsageUsers   = 1000
+	maxUsageDays    = 366
+)
+
+// ValidateUnary returns a unary server interceptor that checks every
+// request's shape before its handler runs: ids are UUIDs, counts and sizes
+// within lim, versions and limits not negative, required fields set. A bad
+// request fails with InvalidArgument, reason INVALID_ARGUMENT and a
+// google.rpc.BadRequest listing each offending field.
+func ValidateUnary(lim Limits) grpc.UnaryServerInterceptor {
+	lim = lim.withDefaults()
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, next grpc.UnaryHandler) (any, error) {
+		if err := validate(req, lim); err != nil {
+			return nil, err
+		}
+		return next(ctx, req)
+	}
+}
+
+// ValidateStream is ValidateUnary for streaming RPCs: it checks each
+// message the client sends.
+func ValidateStream(lim Limits) grpc.StreamServerInterceptor {
+	lim = lim.withDefaults()
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, next grpc.StreamHandler) error {
+		return next(srv, &validStream{ServerStream: ss, lim: lim})
+	}
+}
+
+type validStream struct {
+	grpc.ServerStream
+	lim Limits
+}
+
+func (s *validStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return validate(m, s.lim)
+}
+
+func (l Limits) withDefaults() Limits {
+	if l.MaxBatch <= 0 {
+		l.MaxBatch = defaultMaxBatch
+	}
+	if l.MaxBlob <= 0 {
+		l.MaxBlob = defaultMaxBlob
+	}
+	return l
+}
+
+// violations collects the fields a request gets wrong.
+type violations []*errdetails.BadRequest_FieldViolation
+
+func (v *violations) add(field, format string, args ...any) {
+	*v = append(*v, &errdetails.BadRequest_FieldViolation{Field: field, Description: fmt.Sprintf(format, args...)})
+}
+
+func (v *violations) required(field, value string) {
+	if value == "" {
+		v.add(field, "is required")
+	}
+}
+
+func (v *violations) uuid(field, value string) {
+	if id, err := uuid.FromString(value); err != nil || id == uuid.Nil {
+		v.add(field, "must be a non-nil UUID")
+	}
+}
+
+func (v *violations) nonNegative(field string, n int64) {
+	if n < 0 {
+		v.add(field, "must not be negative")
+	}
+}
+
+func (v *violations) atMost(field string, n, max int64) {
+	if n < 0 || n > max {
+		v.add(field, "must be between 0 and %d", max)
+	}
+}
+
+func (v *violations) maxLen(field, value string, n int) {
+	if len(value) > n {
+		v.add(field, "must be at most %d bytes", n)
+	}
+}
+
+func (v *violations) batch(field string, n int, lim Limits) {
+	if n > lim.MaxBatch {
+		v.add(field, "must hold at most %d entries", lim.MaxBatch)
+	}
+}
+
+func (v *violations) cidr(field, value string) {
+	if _, err := ipfilter.ParsePrefix(value); err != nil {
+		v.add(field, "must be an address or CIDR")
+	}
+}
+
+// err is the InvalidArgument status for v, nil if v is empty. Its message
+// names the first violation; the BadRequest detail has them all.
+func (v violations) err() error {
+	if len(v) == 0 {
+		return nil
+	}
+	msg := v[0].GetField() + ": " + v[0].GetDescription()
+	if len(v) > 1 {
+		msg += fmt.Sprintf(" (and %d more)", len(v)-1)
+	}
+	st := status.New(codes.InvalidArgument, msg)
+	return withDetails(st, errorInfo(errs.ReasonInvalidArgument), &errdetails.BadRequest{FieldViolations: v}).Err()
+}
+
+// validate checks req against the rules of its type; types without rules
+// pass.
+func validate(req any, lim Limits) error {
+	var v violations
+	switch r := req.(type) {
+	case *pb.RegisterRequest:
+		v.required("username", r.GetUsername())
+		v.required("password", r.GetPassword())
+	case *pb.LoginRequest:
+		v.maxLen("device_label", r.GetDeviceLabel(), maxDeviceLabel)
+		for i, sc := range r.GetScopes() {
+			if !slices.Contains(model.Scopes, sc) {
+				v.add(fmt.Sprintf("scopes[%d]", i), "must be one of %s", strings.Join(model.Scopes, ", "))
+			}
+		}
+	case *pb.RefreshRequest:
+		v.required("refresh_token", r.GetRefreshToken())
+	case *pb.RevokeSessionRequest:
+		v.uuid("id", r.GetId())
+	case *pb.SetDuressPasswordRequest:
+		v.required("password", r.GetPassword())
+	case *pb.FreezeVaultRequest:
+		v.required("password", r.GetPassword())
+	case *pb.UnfreezeVaultRequest:
+		v.required("password", r.GetPassword())
+	case *pb.UpsertItemsRequest:
+		v.batch("items", len(r.GetItems()), lim)
+		for i, it := range r.GetItems() {
+			validateUpsert(&v, fmt.Sprintf("items[%d].", i), it, lim)
+		}
+	case *pb.GetChangesRequest:
+		v.nonNegative("since_ver", r.GetSinceVer())
+		v.nonNegative("max_schema_version", int64(r.GetMaxSchemaVersion()))
+		v.nonNegative("page_size", int64(r.GetPageSize()))
+		v.nonNegative("since_seq", r.GetSinceSeq())
+		if tok := r.GetPageToken(); tok != "" && r.GetSyncSession() != "" {
+			if _, ok := decodeIDToken(tok); !ok {
+				v.add("page_token", "is not a token this server issued")
+			}
+		} else if tok != "" {
+			_, ok := decodePageToken(tok)
+			if _, seq := decodeSeqToken(tok); !ok && !seq {
+				v.add("page_token", "is not a token this server issued")
+			}
+		}
+	case *pb.GetChangesLongPollRequest:
+		v.nonNegative("since_ver", r.GetSinceVer())
+		v.nonNegative("since_seq", r.GetSinceSeq())
+		v.nonNegative("max_schema_version", int64(r.GetMaxSchemaVersion()))
+	case *pb.GetItemRequest:
+		v.uuid("id", r.GetId())
+		v.nonNegative("ver", r.GetVer())
+	case *pb.GetItemAccessLogRequest:
+		v.uuid("id", r.GetId())
+	case *pb.DeleteItemRequest:
+		v.uuid("id", r.GetId())
+		v.nonNegative("base_ver", r.GetBaseVer())
+	case *pb.DeleteItemsRequest:
+		v.batch("items", len(r.GetItems()), lim)
+		for i, ref := range r.GetItems() {
+			v.uuid(fmt.Sprintf("items[%d].id", i), ref.GetId())
+			v.nonNegative(fmt.Sprintf("items[%d].base_ver", i), ref.GetBaseVer())
+		}
+	case *pb.ApplyChangeSetRequest:
+		v.batch("upserts", len(r.GetUpserts())+len(r.GetDeletes()), lim)
+		for i, it := range r.GetUpserts() {
+			validateUpsert(&v, fmt.Sprintf("upserts[%d].", i), it, lim)
+		}
+		for i, ref := range r.GetDeletes() {
+			v.uuid(fmt.Sprintf("deletes[%d].id", i), ref.GetId())
+			v.nonNegative(fmt.Sprintf("deletes[%d].base_ver", i), ref.GetBaseVer())
+		}
+	case *pb.SetWrappedDEKRequest:
+		if len(r.GetWrappedDek()) == 0 {
+			v.add("wrapped_dek", "is required")
+		}
+	case *pb.GetLoginHistoryRequest:
+		v.nonNegative("limit", int64(r.GetLimit()))
+	case *pb.CreateWebhookRequest:
+		v.required("url", r.GetUrl())
+	case *pb.DeleteWebhookRequest:
+		v.uuid("id", r.GetId())
+	case *pb.RegisterDeviceRequest:
+		v.required("platform", r.GetPlatform())
+		v.required("token", r.GetToken())
+		v.maxLen("name", r.GetName(), maxDeviceName)
+	case *pb.UnregisterDeviceRequest:
+		v.uuid("id", r.GetId())
+	case *pb.RollbackUserRequest:
+		v.uuid("user_id", r.GetUserId())
+		if !r.HasToTime() {
+			v.add("to_time", "is required")
+		}
+	case *pb.CheckUserConsistencyRequest:
+		v.uuid("user_id", r.GetUserId())
+	case *pb.SuspendUserRequest:
+		v.uuid("user_id", r.GetUserId())
+	case *pb.ReactivateUserRequest:
+		v.uuid("user_id", r.GetUserId())
+	case *pb.GetUsageReportRequest:
+		v.atMost("limit", int64(r.GetLimit()), maxUsageUsers)
+		v.atMost("days", int64(r.GetDays()), maxUsageDays)
+	case *pb.ClearLoginLocksRequest:
+		v.required("username", r.GetUsername())
+	case *pb.ListUserIPRulesRequest:
+		v.required("username", r.GetUsername())
+	case *pb.AddUserIPRuleRequest:
+		v.required("username", r.GetUsername())
+		v.cidr("cidr", r.GetCidr())
+	case *pb.RemoveUserIPRuleRequest:
+		v.required("username", r.GetUsername())
+		v.cidr("cidr", r.GetCidr())
+	}
+	return v.err()
+}
+
+func validateUpsert(v *violations, prefix string, it *pb.UpsertItem, lim Limits) {
+	v.uuid(prefix+"id", it.GetId())
+	v.nonNegative(prefix+"base_ver", it.GetBaseVer())
+	n := len(it.GetBlobEnc().GetCiphertext())
+	switch {
+	case n == 0:
+		v.add(prefix+"blob_enc.ciphertext", "is required")
+	case n > lim.MaxBlob:
+		v.add(prefix+"blob_enc.ciphertext", "must be at most %d bytes", lim.MaxBlob)
+	}
+	h := it.GetHints()
+	class := model.SizeClass(h.GetSizeClass())
+	if !class.Valid() {
+		v.add(prefix+"hints.size_class", "unknown value %d", h.GetSizeClass())
+	} else if m := class.MaxBytes(); m > 0 && n > m {
+		v.add(prefix+"blob_enc.ciphertext", "must be at most %d bytes for size class %s", m, strings.TrimPrefix(h.GetSizeClass().String(), "SIZE_CLASS_"))
+	}
+	v.nonNegative(prefix+"hints.schema_version", int64(h.GetSchemaVersion()))
+}
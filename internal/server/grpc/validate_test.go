@@ -0,0 +1,112 @@
+package grpcserver
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/gofrs/uuid/v5"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/internal/errs"
+)
+
+// validated runs call behind ValidateUnary, as the server does.
+func validated[Req, Resp any](call func(context.Context, Req) (Resp, error)) func(context.Context, Req) (Resp, error) {
+	icpt := ValidateUnary(Limits{})
+	return func(ctx context.Context, req Req) (Resp, error) {
+		var zero Resp
+		resp, err := icpt(ctx, req, &grpc.UnaryServerInfo{}, func(ctx context.Context, r any) (any, error) {
+			return call(ctx, r.(Req))
+		})
+		if err != nil {
+			return zero, err
+		}
+		return resp.(Resp), nil
+	}
+}
+
+func fieldViolations(t *testing.T, err error) map[string]string {
+	t.Helper()
+	st := status.Convert(err)
+	if st.Code() != codes.InvalidArgument || errInfo(st).GetReason() != errs.ReasonInvalidArgument {
+		t.Fatalf("want INVALID_ARGUMENT, got %v", err)
+	}
+	out := map[string]string{}
+	for _, d := range st.Details() {
+		if br, ok := d.(*errdetails.BadRequest); ok {
+			for _, fv := range br.GetFieldViolations() {
+				out[fv.GetField()] = fv.GetDescription()
+			}
+		}
+	}
+	return out
+}
+
+func Test_validate(t *testing.T) {
+	lim := Limits{MaxBatch: 2, MaxBlob: 100 << 10}.withDefaults()
+	up := func(id string, base int64, size int, class pb.SizeClass) *pb.UpsertItem {
+		eb := &pb.EncryptedBlob{}
+		eb.SetCiphertext(make([]byte, size))
+		h := &pb.ItemHints{}
+		h.SetSizeClass(class)
+		u := &pb.UpsertItem{}
+		u.SetId(id)
+		u.SetBaseVer(base)
+		u.SetBlobEnc(eb)
+		u.SetHints(h)
+		return u
+	}
+	id := uuid.Must(uuid.NewV7()).String()
+
+	ok := &pb.UpsertItemsRequest{}
+	ok.SetItems([]*pb.UpsertItem{up(id, 0, 10, pb.SizeClass_SIZE_CLASS_SMALL), up(id, 3, 70<<10, pb.SizeClass_SIZE_CLASS_LARGE)})
+	if err := validate(ok, lim); err != nil {
+		t.Fatalf("good batch: %v", err)
+	}
+	bad := &pb.UpsertItemsRequest{}
+	bad.SetItems([]*pb.UpsertItem{
+		up("nope", -1, 0, 0),
+		up(uuid.Nil.String(), 0, 70<<10, pb.SizeClass_SIZE_CLASS_SMALL),
+		up(id, 0, 101<<10, 7),
+	})
+	err := validate(bad, lim)
+	got := fieldViolations(t, err)
+	for _, f := range []string{
+		"items", "items[0].id", "items[0].base_ver", "items[0].blob_enc.ciphertext",
+		"items[1].id", "items[1].blob_enc.ciphertext", "items[2].blob_enc.ciphertext", "items[2].hints.size_class",
+	} {
+		if got[f] == "" {
+			t.Errorf("no violation for %s in %v", f, got)
+		}
+	}
+	if msg := status.Convert(err).Message(); !strings.HasPrefix(msg, "items: must hold at most 2 entries (and ") {
+		t.Fatalf("message %q", msg)
+	}
+
+	gc := &pb.GetChangesRequest{}
+	gc.SetSinceVer(-1)
+	gc.SetSinceSeq(-1)
+	gc.SetPageToken("garbage")
+	if got := fieldViolations(t, validate(gc, lim)); len(got) != 3 || got["since_ver"] == "" || got["since_seq"] == "" || got["page_token"] == "" {
+		t.Fatalf("GetChanges: %v", got)
+	}
+	login := &pb.LoginRequest{}
+	login.SetScopes([]string{"items:read", "items:admin"})
+	if got := fieldViolations(t, validate(login, lim)); len(got) != 1 || got["scopes[1]"] == "" {
+		t.Fatalf("Login: %v", got)
+	}
+	rule := &pb.AddUserIPRuleRequest{}
+	rule.SetCidr("10.0.0.0/33")
+	if got := fieldViolations(t, validate(rule, lim)); len(got) != 2 || got["username"] == "" || got["cidr"] == "" {
+		t.Fatalf("AddUserIPRule: %v", got)
+	}
+	// messages without rules pass
+	if err := validate(&pb.GetStatsRequest{}, lim); err != nil {
+		t.Fatal(err)
+	}
+}
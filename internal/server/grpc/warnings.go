@@ -0,0 +1,64 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gofrs/uuid/v5"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/model"
+)
+
+// DefaultWarnItemBytes is the blob size above which UpsertItems warns that
+// an item is unusually large unless SetWarnItemBytes says otherwise.
+const DefaultWarnItemBytes = 256 << 10
+
+// itemLimitNear is the share of the item cap, in percent, from which
+// UpsertItems warns that it is nearly reached.
+const itemLimitNear = 90
+
+// SetWarnItemBytes sets the blob size above which UpsertItems warns that
+// an item is unusually large; n <= 0 turns the warning off.
+func (s *Server) SetWarnItemBytes(n int) { s.warnItemBytes = n }
+
+// upsertWarnings returns the advisory warnings for written, the items an
+// UpsertItems call stored. They never fail the call: if the item counts
+// cannot be read the cap warning is left out.
+func (s *Server) upsertWarnings(ctx context.Context, userID uuid.UUID, written []model.UpsertItem) []*pb.Warning {
+	var ws []*pb.Warning
+	added := false
+	for _, up := range written {
+		if s.warnItemBytes > 0 && len(up.BlobEnc) > s.warnItemBytes {
+			ws = append(ws, newWarning(errs.WarnItemLarge, up.ID,
+				fmt.Sprintf("item is %d bytes; items over %d bytes slow every sync", len(up.BlobEnc), s.warnItemBytes)))
+		}
+		if up.BaseVer == 0 {
+			added = true
+			if up.ID.Version() == uuid.V4 {
+				ws = append(ws, newWarning(errs.WarnDeprecatedID, up.ID,
+					"new items should have a UUIDv7 id; UUIDv4 ids will be refused in a future version"))
+			}
+		}
+	}
+	if !added {
+		return ws
+	}
+	st, err := s.items.Stats(ctx, userID)
+	if err == nil && st.MaxItems > 0 && st.Items*100 >= st.MaxItems*itemLimitNear {
+		ws = append(ws, newWarning(errs.WarnItemLimitNear, uuid.Nil,
+			fmt.Sprintf("%d of %d items used", st.Items, st.MaxItems)))
+	}
+	return ws
+}
+
+func newWarning(code string, id uuid.UUID, msg string) *pb.Warning {
+	w := &pb.Warning{}
+	w.SetCode(code)
+	w.SetMessage(msg)
+	if id != uuid.Nil {
+		w.SetItemId(id.String())
+	}
+	return w
+}
@@ -0,0 +1,65 @@
+package grpcserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/repository/memory"
+	"github.com/and161185/goph-keeper/internal/service"
+	"github.com/and161185/goph-keeper/internal/tokensign"
+)
+
+func TestServer_UpsertWarnings(t *testing.T) {
+	key := []byte("secret")
+	items := service.NewItemService(memory.NewItemRepo(), 100)
+	items.SetMaxItems(10)
+	s := New(nil, items, tokensign.HMAC(key))
+	s.SetWarnItemBytes(8)
+	user := uuid.Must(uuid.NewV4())
+	ctx := ctxAuth(jwtFor(t, user.String(), key, time.Hour))
+
+	up := func(id uuid.UUID, size int) *pb.UpsertItem {
+		eb := &pb.EncryptedBlob{}
+		eb.SetCiphertext(make([]byte, size))
+		u := &pb.UpsertItem{}
+		u.SetId(id.String())
+		u.SetBlobEnc(eb)
+		return u
+	}
+	upsert := func(ups ...*pb.UpsertItem) map[string]string {
+		t.Helper()
+		req := &pb.UpsertItemsRequest{}
+		req.SetItems(ups)
+		out, err := s.UpsertItems(ctx, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := map[string]string{}
+		for _, w := range out.GetWarnings() {
+			got[w.GetCode()] = w.GetItemId()
+		}
+		return got
+	}
+
+	if got := upsert(up(uuid.Must(uuid.NewV7()), 8)); len(got) != 0 {
+		t.Fatalf("plain write: %v", got)
+	}
+	big, v4 := uuid.Must(uuid.NewV7()), uuid.Must(uuid.NewV4())
+	got := upsert(up(big, 9), up(v4, 1))
+	if len(got) != 2 || got[errs.WarnItemLarge] != big.String() || got[errs.WarnDeprecatedID] != v4.String() {
+		t.Fatalf("large and v4: %v", got)
+	}
+
+	var batch []*pb.UpsertItem
+	for range 6 {
+		batch = append(batch, up(uuid.Must(uuid.NewV7()), 1))
+	}
+	got = upsert(batch...)
+	if id, ok := got[errs.WarnItemLimitNear]; len(got) != 1 || !ok || id != "" {
+		t.Fatalf("9 of 10 items: %v", got)
+	}
+}
@@ -0,0 +1,99 @@
+package grpcserver
+
+import (
+	"context"
+
+	"github.com/gofrs/uuid/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/and161185/goph-keeper/internal/repository"
+	"github.com/and161185/goph-keeper/internal/webhook"
+)
+
+// SetWebhooks enables the webhook RPCs, storing hooks in repo under policy.
+// Without it they fail with FailedPrecondition.
+func (s *Server) SetWebhooks(repo repository.WebhookRepository, policy webhook.Policy) {
+	s.hooks, s.hookPolicy = repo, policy
+}
+
+func toProtoWebhook(h model.Webhook) *pb.Webhook {
+	w := &pb.Webhook{}
+	w.SetId(h.ID.String())
+	w.SetUrl(h.URL)
+	w.SetCreatedAt(timestamppb.New(h.CreatedAt))
+	return w
+}
+
+// webhookUser authenticates the caller of a webhook RPC.
+func (s *Server) webhookUser(ctx context.Context) (uuid.UUID, error) {
+	userID, err := s.userIDFromCtx(ctx)
+	if err != nil {
+		return uuid.Nil, statusError(codes.Unauthenticated, errs.ReasonUnauthenticated, "no auth")
+	}
+	if s.hooks == nil {
+		return uuid.Nil, statusError(codes.FailedPrecondition, errs.ReasonNotConfigured, "webhooks are disabled on this server")
+	}
+	return userID, nil
+}
+
+// CreateWebhook registers a change notification URL and returns its secret.
+func (s *Server) CreateWebhook(ctx context.Context, req *pb.CreateWebhookRequest) (*pb.CreateWebhookResponse, error) {
+	userID, err := s.webhookUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.hookPolicy.CheckURL(req.GetUrl()); err != nil {
+		return nil, statusError(codes.InvalidArgument, errs.ReasonInvalidArgument, "bad url: "+err.Error())
+	}
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		return nil, toStatus("webhook secret", err)
+	}
+	h := model.Webhook{ID: uuid.Must(uuid.NewV4()), UserID: userID, URL: req.GetUrl(), Secret: []byte(secret)}
+	if err := s.hooks.Create(ctx, &h, s.hookPolicy.MaxPerUser); err != nil {
+		return nil, toStatus("create webhook", err, remap(errs.ErrQuotaExceeded, errs.ReasonWebhookLimit, "too many webhooks"))
+	}
+	resp := &pb.CreateWebhookResponse{}
+	resp.SetWebhook(toProtoWebhook(h))
+	resp.SetSecret(secret)
+	return resp, nil
+}
+
+// ListWebhooks returns the caller's webhooks without their secrets.
+func (s *Server) ListWebhooks(ctx context.Context, _ *pb.ListWebhooksRequest) (*pb.ListWebhooksResponse, error) {
+	userID, err := s.webhookUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	hooks, err := s.hooks.List(ctx, userID)
+	if err != nil {
+		return nil, toStatus("list webhooks", err)
+	}
+	out := make([]*pb.Webhook, 0, len(hooks))
+	for _, h := range hooks {
+		out = append(out, toProtoWebhook(h))
+	}
+	resp := &pb.ListWebhooksResponse{}
+	resp.SetWebhooks(out)
+	return resp, nil
+}
+
+// DeleteWebhook removes one of the caller's webhooks.
+func (s *Server) DeleteWebhook(ctx context.Context, req *pb.DeleteWebhookRequest) (*pb.DeleteWebhookResponse, error) {
+	userID, err := s.webhookUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	id, err := uuid.FromString(req.GetId())
+	if err != nil {
+		return nil, statusError(codes.InvalidArgument, errs.ReasonInvalidArgument, "bad id")
+	}
+	if err := s.hooks.Delete(ctx, userID, id); err != nil {
+		return nil, toStatus("delete webhook", err)
+	}
+	return &pb.DeleteWebhookResponse{}, nil
+}
@@ -0,0 +1,91 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/and161185/goph-keeper/internal/tokensign"
+	"github.com/and161185/goph-keeper/internal/webhook"
+)
+
+type fakeWebhooks struct{ hooks []model.Webhook }
+
+func (f *fakeWebhooks) Create(_ context.Context, h *model.Webhook, max int) error {
+	if len(f.hooks) >= max {
+		return fmt.Errorf("%w: limit", errs.ErrQuotaExceeded)
+	}
+	h.CreatedAt = time.Now()
+	f.hooks = append(f.hooks, *h)
+	return nil
+}
+
+func (f *fakeWebhooks) List(_ context.Context, userID uuid.UUID) ([]model.Webhook, error) {
+	var out []model.Webhook
+	for _, h := range f.hooks {
+		if h.UserID == userID {
+			out = append(out, h)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeWebhooks) Delete(_ context.Context, userID, id uuid.UUID) error {
+	for i, h := range f.hooks {
+		if h.ID == id && h.UserID == userID {
+			f.hooks = append(f.hooks[:i], f.hooks[i+1:]...)
+			return nil
+		}
+	}
+	return errs.ErrNotFound
+}
+
+func TestWebhookRPCs(t *testing.T) {
+	key := []byte("secret")
+	s := New(nil, &fakeItems{}, tokensign.HMAC(key))
+	ctx := ctxAuth(jwtFor(t, uuid.Must(uuid.NewV4()).String(), key, time.Hour))
+	create := func(url string) (*pb.CreateWebhookResponse, error) {
+		req := &pb.CreateWebhookRequest{}
+		req.SetUrl(url)
+		return s.CreateWebhook(ctx, req)
+	}
+
+	if _, err := create("https://ci.example/h"); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("disabled: %v", err)
+	}
+	store := &fakeWebhooks{}
+	s.SetWebhooks(store, webhook.Policy{MaxPerUser: 1})
+
+	if _, err := create("http://ci.example/h"); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("http url: %v", err)
+	}
+	resp, err := create("https://ci.example/h")
+	if err != nil || resp.GetSecret() == "" || string(store.hooks[0].Secret) != resp.GetSecret() {
+		t.Fatalf("create: %v %v", resp, err)
+	}
+	if _, err := create("https://ci.example/2"); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("over limit: %v", err)
+	}
+
+	list, err := s.ListWebhooks(ctx, &pb.ListWebhooksRequest{})
+	if err != nil || len(list.GetWebhooks()) != 1 || list.GetWebhooks()[0].GetUrl() != "https://ci.example/h" {
+		t.Fatalf("list: %v %v", list, err)
+	}
+	other := ctxAuth(jwtFor(t, uuid.Must(uuid.NewV4()).String(), key, time.Hour))
+	del := &pb.DeleteWebhookRequest{}
+	del.SetId(resp.GetWebhook().GetId())
+	if _, err := s.DeleteWebhook(other, del); status.Code(err) != codes.NotFound {
+		t.Fatalf("foreign delete: %v", err)
+	}
+	if _, err := s.DeleteWebhook(ctx, del); err != nil || len(store.hooks) != 0 {
+		t.Fatalf("delete: %v", err)
+	}
+}
@@ -3,79 +3,257 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
 	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
 	"time"
+	"unicode"
 
 	pkgcrypto "github.com/and161185/goph-keeper/internal/crypto"
 	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/geoip"
 	"github.com/and161185/goph-keeper/internal/limiter"
 	"github.com/and161185/goph-keeper/internal/model"
 	"github.com/and161185/goph-keeper/internal/repository"
+	"github.com/and161185/goph-keeper/internal/tenant"
+	"github.com/and161185/goph-keeper/internal/tokensign"
 	"github.com/gofrs/uuid/v5"
 	"github.com/golang-jwt/jwt/v5"
 )
 
 // AuthService defines authentication and bootstrap operations.
 type AuthService interface {
-	// Register creates a new user with secure password hashing.
-	Register(ctx context.Context, username, password string) (userID string, err error)
-	// LoginWithIP applies rate-limiting and authenticates the user.
-	LoginWithIP(ctx context.Context, username, password string, ip string) (tokens model.Tokens, user model.User, err error)
+	// Register creates a new user with secure password hashing. A taken
+	// username fails with errs.ErrAlreadyExists.
+	Register(ctx context.Context, username, password string) (model.User, error)
+	// LoginWithIP applies rate-limiting and authenticates the user. With
+	// sessions on, it also starts a session for the named device. The
+	// tokens are limited to scopes, model.Scopes entries; none means all.
+	LoginWithIP(ctx context.Context, username, password, ip, device string, scopes []string) (tokens model.Tokens, user model.User, err error)
+	// Refresh trades a refresh token for new access and refresh tokens.
+	Refresh(ctx context.Context, refreshToken string) (model.Tokens, error)
+	// Sessions returns the user's live sessions, most recently used first.
+	Sessions(ctx context.Context, userID uuid.UUID) ([]model.Session, error)
+	// RevokeSession ends one of the user's sessions.
+	RevokeSession(ctx context.Context, userID, id uuid.UUID) error
 	// SetWrappedDEK stores client's wrapped DEK if none is set.
 	SetWrappedDEK(ctx context.Context, userID uuid.UUID, wrapped []byte) error
+	// ReplaceWrappedDEK re-wraps the DEK if the stored value still equals prev.
+	ReplaceWrappedDEK(ctx context.Context, userID uuid.UUID, prev, wrapped []byte) error
+	// LoginHistory returns the user's recent login attempts, newest first.
+	LoginHistory(ctx context.Context, userID uuid.UUID, limit int) ([]model.LoginAttempt, error)
+	// SetDuress gives the user a duress password: logging in with it opens
+	// a separate, empty vault. A user has at most one.
+	SetDuress(ctx context.Context, userID uuid.UUID, password string) error
+	// RemoveDuress deletes the user's duress vault and everything in it.
+	RemoveDuress(ctx context.Context, userID uuid.UUID) error
+	// Freeze makes the user's vault read-only once password, the user's
+	// own, is confirmed, and returns since when it is frozen. Wrong
+	// passwords count towards the login lockout from ip.
+	Freeze(ctx context.Context, userID uuid.UUID, password, ip string) (time.Time, error)
+	// Unfreeze makes the user's vault writable again, confirmed like Freeze.
+	Unfreeze(ctx context.Context, userID uuid.UUID, password, ip string) error
+	// FrozenAt returns when the user's vault was frozen; zero if it is not.
+	FrozenAt(ctx context.Context, userID uuid.UUID) (time.Time, error)
 }
 
+// Login history per user: the newest loginHistoryKeep attempts are kept,
+// and LoginHistory returns loginHistoryDefault unless asked for more.
+const (
+	loginHistoryKeep    = 100
+	loginHistoryDefault = 20
+)
+
+// A login is impossible travel when it is more than travelMinKm from the
+// previous one and getting there would have meant going faster than
+// travelMaxKmh. The floor absorbs GeoIP placing nearby cities far apart.
+const (
+	travelMinKm  = 500
+	travelMaxKmh = 1000
+)
+
+// GeoLocator places a client address; *geoip.DB implements it.
+type GeoLocator interface {
+	Locate(addr string) geoip.Location
+}
+
+// sessionsKeep is the most sessions a user has; a login beyond it ends the
+// least recently used one.
+const sessionsKeep = 20
+
 type AuthServiceImpl struct {
-	users     repository.UserRepository
-	signKey   []byte
-	accessTTL time.Duration
-	lim       limiter.Limiter
+	users      repository.UserRepository
+	signer     tokensign.Signer
+	accessTTL  time.Duration
+	lim        limiter.Limiter
+	geo        GeoLocator                   // nil: logins are not located
+	sessions   repository.SessionRepository // nil: no refresh tokens
+	refreshTTL time.Duration
 }
 
 // NewAuthService constructs AuthService with required dependencies.
-func NewAuthService(users repository.UserRepository, signKey []byte, accessTTL time.Duration, lim limiter.Limiter) *AuthServiceImpl {
-	return &AuthServiceImpl{users: users, signKey: signKey, accessTTL: accessTTL, lim: lim}
+func NewAuthService(users repository.UserRepository, signer tokensign.Signer, accessTTL time.Duration, lim limiter.Limiter) *AuthServiceImpl {
+	return &AuthServiceImpl{users: users, signer: signer, accessTTL: accessTTL, lim: lim}
+}
+
+// SetGeo makes logins record where they came from and report anomalies.
+func (s *AuthServiceImpl) SetGeo(g GeoLocator) { s.geo = g }
+
+// SetSessions makes logins return a refresh token as well. A session lasts
+// ttl from its last use.
+func (s *AuthServiceImpl) SetSessions(repo repository.SessionRepository, ttl time.Duration) {
+	s.sessions, s.refreshTTL = repo, ttl
 }
 
-// Register creates a new user record with per-user salts.
-func (s *AuthServiceImpl) Register(ctx context.Context, username, password string) (string, error) {
+// Register creates a new user record with per-user salts and returns it.
+func (s *AuthServiceImpl) Register(ctx context.Context, username, password string) (model.User, error) {
 	if username == "" || password == "" {
-		return "", errors.New("empty username/password")
+		return model.User{}, fmt.Errorf("%w: empty username/password", errs.ErrInvalidArgument)
+	}
+	if strings.ContainsFunc(username, unicode.IsControl) {
+		// keeps duress vault names out of reach
+		return model.User{}, fmt.Errorf("%w: control character in username", errs.ErrInvalidArgument)
 	}
+	u, err := newUser(username, password)
+	if err != nil {
+		return model.User{}, err
+	}
+	if err := s.users.Create(ctx, u); err != nil {
+		return model.User{}, err
+	}
+	return *u, nil
+}
+
+// newUser builds a user with fresh salts and no DEK yet.
+func newUser(username, password string) (*model.User, error) {
 	uid, err := uuid.NewV4()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	saltAuth, err := pkgcrypto.RandBytes(16)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	kekSalt, err := pkgcrypto.RandBytes(16)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	pwdHash := pkgcrypto.HashPassword([]byte(password), saltAuth)
-
-	u := &model.User{
+	return &model.User{
 		ID:         uid,
 		Username:   username,
-		PwdHash:    pwdHash,
+		PwdHash:    pkgcrypto.HashPassword([]byte(password), saltAuth),
 		SaltAuth:   saltAuth,
 		KekSalt:    kekSalt,
-		WrappedDEK: []byte{}, // empty for now (MVP)
+		WrappedDEK: []byte{}, // the client sets it after the first login
+	}, nil
+}
+
+// duressPrefix starts a duress vault's username. Register refuses control
+// characters, so no one can sign up under it or log in to it by name.
+const duressPrefix = "duress\x1f"
+
+// SetDuress creates the user's duress vault. Its password must differ from
+// the user's own, which would otherwise always win.
+func (s *AuthServiceImpl) SetDuress(ctx context.Context, userID uuid.UUID, password string) error {
+	if userID == uuid.Nil || password == "" {
+		return fmt.Errorf("%w: userID/password", errs.ErrInvalidArgument)
 	}
-	if err := s.users.Create(ctx, u); err != nil {
-		return "", err
+	u, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if pkgcrypto.VerifyPassword([]byte(password), u.SaltAuth, u.PwdHash) {
+		return fmt.Errorf("%w: the duress password is the account password", errs.ErrInvalidArgument)
+	}
+	vault, err := newUser(duressPrefix+userID.String(), password)
+	if err != nil {
+		return err
+	}
+	return s.users.CreateDuress(ctx, userID, vault)
+}
+
+// RemoveDuress deletes the user's duress vault.
+func (s *AuthServiceImpl) RemoveDuress(ctx context.Context, userID uuid.UUID) error {
+	if userID == uuid.Nil {
+		return fmt.Errorf("%w: userID", errs.ErrInvalidArgument)
+	}
+	return s.users.DeleteDuress(ctx, userID)
+}
+
+// duressVault returns the user's duress vault if password opens it.
+func (s *AuthServiceImpl) duressVault(ctx context.Context, userID uuid.UUID, password string) (*model.User, bool) {
+	v, err := s.users.GetDuress(ctx, userID)
+	if err != nil || !pkgcrypto.VerifyPassword([]byte(password), v.SaltAuth, v.PwdHash) {
+		return nil, false
+	}
+	return v, true
+}
+
+// Freeze confirms the password and freezes the vault.
+func (s *AuthServiceImpl) Freeze(ctx context.Context, userID uuid.UUID, password, ip string) (time.Time, error) {
+	if err := s.confirmPassword(ctx, userID, password, ip); err != nil {
+		return time.Time{}, err
+	}
+	return s.users.Freeze(ctx, userID, time.Now().UTC())
+}
+
+// Unfreeze confirms the password and unfreezes the vault.
+func (s *AuthServiceImpl) Unfreeze(ctx context.Context, userID uuid.UUID, password, ip string) error {
+	if err := s.confirmPassword(ctx, userID, password, ip); err != nil {
+		return err
+	}
+	return s.users.Unfreeze(ctx, userID)
+}
+
+// FrozenAt reports whether, and since when, the vault is frozen.
+func (s *AuthServiceImpl) FrozenAt(ctx context.Context, userID uuid.UUID) (time.Time, error) {
+	return s.users.FrozenAt(ctx, userID)
+}
+
+// confirmPassword checks that password is the user's own. It shares the
+// login limiter's counters, so a stolen token is no way round the lockout
+// for guessing the password.
+func (s *AuthServiceImpl) confirmPassword(ctx context.Context, userID uuid.UUID, password, ip string) error {
+	if userID == uuid.Nil || password == "" {
+		return fmt.Errorf("%w: userID/password", errs.ErrInvalidArgument)
+	}
+	u, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return err
 	}
-	return uid.String(), nil
+	key, ipHash := tenant.Scope(ctx, u.Username), limiter.HashIP(ip)
+	allowed, _, err := s.lim.Allow(ctx, key, ipHash)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return errs.ErrRateLimited
+	}
+	if !pkgcrypto.VerifyPassword([]byte(password), u.SaltAuth, u.PwdHash) {
+		if blocked, _, ferr := s.lim.Failure(ctx, key, ipHash); ferr == nil && blocked {
+			return errs.ErrRateLimited
+		}
+		return errs.ErrUnauthorized
+	}
+	// no Success: it would clear the failures the next login reports
+	return nil
 }
 
 // LoginWithIP authenticates with rate limiting by (username, ip).
-func (s *AuthServiceImpl) LoginWithIP(ctx context.Context, username, password, ip string) (model.Tokens, model.User, error) {
+func (s *AuthServiceImpl) LoginWithIP(ctx context.Context, username, password, ip, device string, scopes []string) (model.Tokens, model.User, error) {
+	scopes, err := knownScopes(scopes)
+	if err != nil {
+		return model.Tokens{}, model.User{}, err
+	}
 	ipHash := limiter.HashIP(ip)
+	key := tenant.Scope(ctx, username) // usernames repeat across tenants; lockouts must not
 
 	// Check if requests are currently allowed for this (user, ip).
-	allowed, _, err := s.lim.Allow(ctx, username, ipHash)
+	allowed, _, err := s.lim.Allow(ctx, key, ipHash)
 	if err != nil {
 		return model.Tokens{}, model.User{}, err
 	}
@@ -83,13 +261,30 @@ func (s *AuthServiceImpl) LoginWithIP(ctx context.Context, username, password, i
 		return model.Tokens{}, model.User{}, errs.ErrRateLimited
 	}
 
-	u, err := s.users.GetByUsername(ctx, username)
-	if err != nil || !pkgcrypto.VerifyPassword([]byte(password), u.SaltAuth, u.PwdHash) {
+	u, err := (*model.User)(nil), errs.ErrNotFound
+	if !strings.HasPrefix(username, duressPrefix) { // a vault opens through its owner only
+		u, err = s.users.GetByUsername(ctx, username)
+	}
+	if errors.Is(err, errs.ErrUnavailable) {
+		// an outage is not a failed attempt and must not look like bad credentials
+		return model.Tokens{}, model.User{}, err
+	}
+	if err == nil && !pkgcrypto.VerifyPassword([]byte(password), u.SaltAuth, u.PwdHash) {
+		// The duress password logs in to the vault as if it were the
+		// account: same counters, same response, only another user id.
+		if v, ok := s.duressVault(ctx, u.ID, password); ok {
+			u = v
+		} else {
+			err = errs.ErrUnauthorized
+		}
+	}
+	if err != nil {
 		// Record failure; if threshold reached — return rate-limited.
-		if blocked, _, ferr := s.lim.Failure(ctx, username, ipHash); ferr == nil && blocked {
+		if blocked, _, ferr := s.lim.Failure(ctx, key, ipHash); ferr == nil && blocked {
 			return model.Tokens{}, model.User{}, errs.ErrRateLimited
 		}
-		if err == nil {
+		if u != nil {
+			s.recordLogin(ctx, u.ID, s.attempt(ip, false))
 			// hide existence of the user on wrong password
 			return model.Tokens{}, model.User{}, errs.ErrUnauthorized
 		}
@@ -97,34 +292,278 @@ func (s *AuthServiceImpl) LoginWithIP(ctx context.Context, username, password, i
 		return model.Tokens{}, model.User{}, errs.ErrUnauthorized
 	}
 
-	// Success: reset counters (best-effort).
-	_ = s.lim.Success(ctx, username, ipHash)
+	// Only the right password learns that the account is suspended.
+	if err := s.refuseDisabled(ctx, u.ID); err != nil {
+		return model.Tokens{}, model.User{}, err
+	}
 
-	access, exp, err := s.issueAccessToken(u.ID)
-	if err != nil {
+	// Success: reset counters (best-effort; a lost count only skips the warning).
+	failed, _ := s.lim.Success(ctx, key, ipHash)
+	a := s.attempt(ip, true)
+	anomalies := s.anomalies(ctx, u.ID, a)
+	s.recordLogin(ctx, u.ID, a)
+
+	tok := model.Tokens{FailedLogins: failed, Anomalies: anomalies, Scopes: scopes}
+	var sid uuid.UUID
+	if s.sessions != nil {
+		if tok.RefreshToken, sid, err = s.startSession(ctx, u.ID, device, scopes); err != nil {
+			return model.Tokens{}, model.User{}, err
+		}
+	}
+	if tok.AccessToken, tok.ExpiresAt, err = s.issueAccessToken(ctx, u.ID, sid, scopes); err != nil {
 		return model.Tokens{}, model.User{}, err
 	}
-	return model.Tokens{AccessToken: access, ExpiresAt: exp}, *u, nil
+	return tok, *u, nil
+}
+
+// knownScopes checks requested scopes and returns them deduplicated, in
+// model.Scopes order.
+func knownScopes(scopes []string) ([]string, error) {
+	var out []string
+	for _, sc := range scopes {
+		if !slices.Contains(model.Scopes, sc) {
+			return nil, fmt.Errorf("%w: unknown scope %q", errs.ErrInvalidArgument, sc)
+		}
+	}
+	for _, sc := range model.Scopes {
+		if slices.Contains(scopes, sc) {
+			out = append(out, sc)
+		}
+	}
+	return out, nil
 }
 
-// issueAccessToken creates a signed HS256 JWT for the given subject.
-func (s *AuthServiceImpl) issueAccessToken(userID uuid.UUID) (string, time.Time, error) {
+// startSession stores a new session for the device and returns its refresh token.
+func (s *AuthServiceImpl) startSession(ctx context.Context, userID uuid.UUID, device string, scopes []string) (string, uuid.UUID, error) {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return "", uuid.Nil, err
+	}
+	refresh, hash, err := newRefreshToken()
+	if err != nil {
+		return "", uuid.Nil, err
+	}
+	sess := model.Session{ID: id, UserID: userID, Label: device, Scopes: scopes}
+	if err := s.sessions.Create(ctx, &sess, hash, time.Now().Add(s.refreshTTL), sessionsKeep); err != nil {
+		return "", uuid.Nil, err
+	}
+	return refresh, id, nil
+}
+
+// newRefreshToken returns a random token and the hash it is stored under.
+// The token has 256 bits of entropy, so an unsalted hash is enough.
+func newRefreshToken() (string, []byte, error) {
+	b, err := pkgcrypto.RandBytes(32)
+	if err != nil {
+		return "", nil, err
+	}
+	tok := base64.RawURLEncoding.EncodeToString(b)
+	return tok, hashRefreshToken(tok), nil
+}
+
+func hashRefreshToken(tok string) []byte {
+	sum := sha256.Sum256([]byte(tok))
+	return sum[:]
+}
+
+// Refresh rotates a session's refresh token: the one given stops working
+// and the returned one replaces it. The new access token has the scopes
+// the session was started with. An unknown, used or expired token is
+// ErrUnauthorized.
+func (s *AuthServiceImpl) Refresh(ctx context.Context, refreshToken string) (model.Tokens, error) {
+	if s.sessions == nil {
+		return model.Tokens{}, errs.ErrNotConfigured
+	}
+	if refreshToken == "" {
+		return model.Tokens{}, fmt.Errorf("%w: empty refresh token", errs.ErrInvalidArgument)
+	}
+	next, hash, err := newRefreshToken()
+	if err != nil {
+		return model.Tokens{}, err
+	}
+	sess, err := s.sessions.Rotate(ctx, hashRefreshToken(refreshToken), hash, time.Now().Add(s.refreshTTL))
+	if errors.Is(err, errs.ErrNotFound) {
+		return model.Tokens{}, errs.ErrUnauthorized
+	}
+	if err != nil {
+		return model.Tokens{}, err
+	}
+	if err := s.refuseDisabled(ctx, sess.UserID); err != nil {
+		return model.Tokens{}, err
+	}
+	access, exp, err := s.issueAccessToken(ctx, sess.UserID, sess.ID, sess.Scopes)
+	if err != nil {
+		return model.Tokens{}, err
+	}
+	return model.Tokens{AccessToken: access, RefreshToken: next, ExpiresAt: exp, Scopes: sess.Scopes}, nil
+}
+
+// RefreshOwner returns the user a refresh token belongs to without using
+// the token, so address rules can be applied before Refresh. It is
+// uuid.Nil for an unknown or expired token, which Refresh refuses anyway.
+func (s *AuthServiceImpl) RefreshOwner(ctx context.Context, refreshToken string) (uuid.UUID, error) {
+	if s.sessions == nil || refreshToken == "" {
+		return uuid.Nil, nil
+	}
+	id, err := s.sessions.Owner(ctx, hashRefreshToken(refreshToken))
+	if errors.Is(err, errs.ErrNotFound) {
+		return uuid.Nil, nil
+	}
+	return id, err
+}
+
+// SetDisabled suspends or reactivates an account. A suspended one cannot
+// log in or refresh, and its sessions end.
+func (s *AuthServiceImpl) SetDisabled(ctx context.Context, userID uuid.UUID, disabled bool) error {
+	if userID == uuid.Nil {
+		return fmt.Errorf("%w: userID", errs.ErrInvalidArgument)
+	}
+	return s.users.SetDisabled(ctx, userID, disabled)
+}
+
+// Disabled reports whether the account is suspended.
+func (s *AuthServiceImpl) Disabled(ctx context.Context, userID uuid.UUID) (bool, error) {
+	return s.users.Disabled(ctx, userID)
+}
+
+// refuseDisabled is ErrAccountDisabled if the account is suspended.
+func (s *AuthServiceImpl) refuseDisabled(ctx context.Context, userID uuid.UUID) error {
+	disabled, err := s.users.Disabled(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if disabled {
+		return errs.ErrAccountDisabled
+	}
+	return nil
+}
+
+// Sessions lists the user's live sessions.
+func (s *AuthServiceImpl) Sessions(ctx context.Context, userID uuid.UUID) ([]model.Session, error) {
+	if s.sessions == nil {
+		return nil, errs.ErrNotConfigured
+	}
+	return s.sessions.List(ctx, userID)
+}
+
+// RevokeSession ends a session: its refresh token stops working. Access
+// tokens already issued from it stay valid until they expire.
+func (s *AuthServiceImpl) RevokeSession(ctx context.Context, userID, id uuid.UUID) error {
+	if s.sessions == nil {
+		return errs.ErrNotConfigured
+	}
+	return s.sessions.Delete(ctx, userID, id)
+}
+
+// attempt describes a password check from ip happening now.
+func (s *AuthServiceImpl) attempt(ip string, ok bool) model.LoginAttempt {
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host // one entry per address, not per connection
+	}
+	a := model.LoginAttempt{At: time.Now().UTC(), IPHash: limiter.HashIP(ip), Success: ok}
+	if s.geo != nil {
+		a.Where = s.geo.Locate(ip)
+	}
+	return a
+}
+
+// recordLogin adds an attempt to the user's login history. It is
+// best-effort: a lost entry must not fail the login.
+func (s *AuthServiceImpl) recordLogin(ctx context.Context, userID uuid.UUID, a model.LoginAttempt) {
+	_ = s.users.RecordLogin(ctx, userID, a, loginHistoryKeep)
+}
+
+// anomalies compares a successful login with the user's history. Like the
+// history itself it is best-effort: without one nothing is flagged.
+func (s *AuthServiceImpl) anomalies(ctx context.Context, userID uuid.UUID, a model.LoginAttempt) []string {
+	if a.Where.Country == "" {
+		return nil
+	}
+	hist, err := s.users.LoginHistory(ctx, userID, loginHistoryKeep)
+	if err != nil {
+		return nil
+	}
+	return loginAnomalies(hist, a)
+}
+
+// loginAnomalies flags a from the located successful logins in hist,
+// newest first. A user's first located login is not a new country.
+func loginAnomalies(hist []model.LoginAttempt, a model.LoginAttempt) []string {
+	var (
+		out         []string
+		prev        *model.LoginAttempt // newest one with coordinates
+		located     bool
+		seenCountry bool
+	)
+	for i := range hist {
+		h := &hist[i]
+		if !h.Success || h.Where.Country == "" {
+			continue
+		}
+		located = true
+		seenCountry = seenCountry || h.Where.Country == a.Where.Country
+		if prev == nil && h.Where.HasCoords {
+			prev = h
+		}
+	}
+	if located && !seenCountry {
+		out = append(out, model.LoginNewCountry)
+	}
+	if prev != nil && a.Where.HasCoords {
+		km := geoip.DistanceKm(prev.Where, a.Where)
+		hours := a.At.Sub(prev.At).Hours()
+		if km > travelMinKm && (hours <= 0 || km/hours > travelMaxKmh) {
+			out = append(out, model.LoginImpossibleTravel)
+		}
+	}
+	return out
+}
+
+// LoginHistory returns up to limit recent attempts; 0 means the default.
+func (s *AuthServiceImpl) LoginHistory(ctx context.Context, userID uuid.UUID, limit int) ([]model.LoginAttempt, error) {
+	if userID == uuid.Nil || limit < 0 {
+		return nil, fmt.Errorf("%w: userID/limit", errs.ErrInvalidArgument)
+	}
+	if limit == 0 {
+		limit = loginHistoryDefault
+	}
+	return s.users.LoginHistory(ctx, userID, min(limit, loginHistoryKeep))
+}
+
+// issueAccessToken creates a JWT for the given subject, signed by the
+// configured signer; sid is its session, uuid.Nil for none, and scopes
+// limit it, none for an unlimited token.
+func (s *AuthServiceImpl) issueAccessToken(ctx context.Context, userID, sid uuid.UUID, scopes []string) (string, time.Time, error) {
 	now := time.Now()
 	exp := now.Add(s.accessTTL)
-	claims := jwt.RegisteredClaims{
+	claims := tokensign.Claims{RegisteredClaims: jwt.RegisteredClaims{
 		Subject:   userID.String(),
 		IssuedAt:  jwt.NewNumericDate(now),
 		ExpiresAt: jwt.NewNumericDate(exp),
+	}}
+	if tid := tenant.FromContext(ctx); tid != tenant.Default {
+		claims.Tenant = tid
 	}
-	tok := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signed, err := tok.SignedString(s.signKey)
+	if sid != uuid.Nil {
+		claims.Session = sid.String()
+	}
+	claims.Scope = strings.Join(scopes, " ")
+	signed, err := tokensign.Issue(ctx, s.signer, claims)
 	return signed, exp, err
 }
 
 // SetWrappedDEK persists wrapped DEK if not yet initialized.
 func (s *AuthServiceImpl) SetWrappedDEK(ctx context.Context, userID uuid.UUID, wrapped []byte) error {
 	if userID == uuid.Nil || len(wrapped) == 0 {
-		return errors.New("validation: userID/wrapped_dek")
+		return fmt.Errorf("%w: userID/wrapped_dek", errs.ErrInvalidArgument)
 	}
 	return s.users.SetWrappedDEKIfEmpty(ctx, userID, wrapped)
 }
+
+// ReplaceWrappedDEK swaps a wrapped DEK the client has re-wrapped.
+func (s *AuthServiceImpl) ReplaceWrappedDEK(ctx context.Context, userID uuid.UUID, prev, wrapped []byte) error {
+	if userID == uuid.Nil || len(prev) == 0 || len(wrapped) == 0 {
+		return fmt.Errorf("%w: userID/previous/wrapped_dek", errs.ErrInvalidArgument)
+	}
+	return s.users.ReplaceWrappedDEK(ctx, userID, prev, wrapped)
+}
@@ -1,17 +1,24 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"slices"
 	"testing"
 	"time"
 
 	pkgcrypto "github.com/and161185/goph-keeper/internal/crypto"
 	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/geoip"
 	"github.com/and161185/goph-keeper/internal/limiter"
 	"github.com/and161185/goph-keeper/internal/model"
 	"github.com/and161185/goph-keeper/internal/repository"
+	"github.com/and161185/goph-keeper/internal/repository/memory"
+	"github.com/and161185/goph-keeper/internal/tokensign"
 	"github.com/gofrs/uuid/v5"
+	"github.com/golang-jwt/jwt/v5"
 )
 
 type fakeUsers struct {
@@ -21,6 +28,13 @@ type fakeUsers struct {
 	getErr    error
 
 	setWrappedErr error
+
+	logins    []model.LoginAttempt
+	lastLimit int
+
+	duress   map[uuid.UUID]*model.User
+	frozen   map[uuid.UUID]time.Time
+	disabled map[uuid.UUID]bool
 }
 
 var _ repository.UserRepository = (*fakeUsers)(nil)
@@ -74,6 +88,78 @@ func (f *fakeUsers) SetWrappedDEKIfEmpty(_ context.Context, id uuid.UUID, wrappe
 	}
 	return errs.ErrNotFound
 }
+func (f *fakeUsers) RecordLogin(_ context.Context, _ uuid.UUID, a model.LoginAttempt, _ int) error {
+	f.logins = append(f.logins, a)
+	return nil
+}
+func (f *fakeUsers) LoginHistory(_ context.Context, _ uuid.UUID, limit int) ([]model.LoginAttempt, error) {
+	f.lastLimit = limit
+	return f.logins, nil
+}
+func (f *fakeUsers) ReplaceWrappedDEK(_ context.Context, id uuid.UUID, prev, wrapped []byte) error {
+	for _, u := range f.byName {
+		if u.ID == id {
+			if !bytes.Equal(u.WrappedDEK, prev) {
+				return errs.ErrVersionConflict
+			}
+			u.WrappedDEK = append([]byte(nil), wrapped...)
+			return nil
+		}
+	}
+	return errs.ErrNotFound
+}
+func (f *fakeUsers) CreateDuress(_ context.Context, id uuid.UUID, vault *model.User) error {
+	if f.duress[id] != nil {
+		return errs.ErrAlreadyExists
+	}
+	if f.duress == nil {
+		f.duress = map[uuid.UUID]*model.User{}
+	}
+	cpy := *vault
+	f.duress[id] = &cpy
+	return nil
+}
+func (f *fakeUsers) GetDuress(_ context.Context, id uuid.UUID) (*model.User, error) {
+	v, ok := f.duress[id]
+	if !ok {
+		return nil, errs.ErrNotFound
+	}
+	c := *v
+	return &c, nil
+}
+func (f *fakeUsers) DeleteDuress(_ context.Context, id uuid.UUID) error {
+	if _, ok := f.duress[id]; !ok {
+		return errs.ErrNotFound
+	}
+	delete(f.duress, id)
+	return nil
+}
+func (f *fakeUsers) Freeze(_ context.Context, id uuid.UUID, at time.Time) (time.Time, error) {
+	if f.frozen == nil {
+		f.frozen = map[uuid.UUID]time.Time{}
+	}
+	if _, ok := f.frozen[id]; !ok {
+		f.frozen[id] = at
+	}
+	return f.frozen[id], nil
+}
+func (f *fakeUsers) Unfreeze(_ context.Context, id uuid.UUID) error {
+	delete(f.frozen, id)
+	return nil
+}
+func (f *fakeUsers) FrozenAt(_ context.Context, id uuid.UUID) (time.Time, error) {
+	return f.frozen[id], nil
+}
+func (f *fakeUsers) SetDisabled(_ context.Context, id uuid.UUID, disabled bool) error {
+	if f.disabled == nil {
+		f.disabled = map[uuid.UUID]bool{}
+	}
+	f.disabled[id] = disabled
+	return nil
+}
+func (f *fakeUsers) Disabled(_ context.Context, id uuid.UUID) (bool, error) {
+	return f.disabled[id], nil
+}
 
 type fakeLimiter struct {
 	allowOK  bool
@@ -82,7 +168,8 @@ type fakeLimiter struct {
 	failBlocked bool
 	failErr     error
 
-	successErr error
+	successFails int
+	successErr   error
 
 	allowCalls   int
 	failureCalls int
@@ -95,9 +182,9 @@ func (l *fakeLimiter) Allow(context.Context, string, []byte) (bool, time.Duratio
 	l.allowCalls++
 	return l.allowOK, 0, l.allowErr
 }
-func (l *fakeLimiter) Success(context.Context, string, []byte) error {
+func (l *fakeLimiter) Success(context.Context, string, []byte) (int, error) {
 	l.successCalls++
-	return l.successErr
+	return l.successFails, l.successErr
 }
 func (l *fakeLimiter) Failure(context.Context, string, []byte) (bool, time.Duration, error) {
 	l.failureCalls++
@@ -107,22 +194,22 @@ func (l *fakeLimiter) Failure(context.Context, string, []byte) (bool, time.Durat
 func TestAuth_Register_Basics(t *testing.T) {
 	t.Parallel()
 	users := &fakeUsers{byName: map[string]*model.User{}}
-	s := NewAuthService(users, []byte("k"), time.Minute, &fakeLimiter{})
+	s := NewAuthService(users, tokensign.HMAC([]byte("k")), time.Minute, &fakeLimiter{})
 
 	if _, err := s.Register(context.Background(), "", ""); err == nil {
 		t.Fatalf("want validation error on empty username/password")
 	}
 
-	id, err := s.Register(context.Background(), "alice", "pwd")
+	u, err := s.Register(context.Background(), "alice", "pwd")
 	if err != nil {
 		t.Fatalf("Register: %v", err)
 	}
-	if id == "" {
-		t.Fatalf("empty user id")
+	if u.ID == uuid.Nil || len(u.KekSalt) != 16 {
+		t.Fatalf("user: %+v", u)
 	}
 
-	if _, err := s.Register(context.Background(), "alice", "pwd2"); err == nil {
-		t.Fatalf("want repo error on duplicate username")
+	if _, err := s.Register(context.Background(), "alice", "pwd2"); !errors.Is(err, errs.ErrAlreadyExists) {
+		t.Fatalf("duplicate username: %v", err)
 	}
 
 	users.createErr = errors.New("boom")
@@ -147,41 +234,50 @@ func TestAuth_LoginWithIP_RateLimiterAndCreds(t *testing.T) {
 
 	users := &fakeUsers{byName: map[string]*model.User{"alice": u}}
 	lim := &fakeLimiter{allowOK: true}
-	s := NewAuthService(users, []byte("secret"), 2*time.Minute, lim)
+	s := NewAuthService(users, tokensign.HMAC([]byte("secret")), 2*time.Minute, lim)
 
 	lim.allowErr = errors.New("lim-err")
-	if _, _, err := s.LoginWithIP(context.Background(), "alice", "correct", "1.2.3.4"); err == nil {
+	if _, _, err := s.LoginWithIP(context.Background(), "alice", "correct", "1.2.3.4", "", nil); err == nil {
 		t.Fatalf("want limiter error propagate")
 	}
 	lim.allowErr = nil
 
 	lim.allowOK = false
-	if _, _, err := s.LoginWithIP(context.Background(), "alice", "correct", "1.2.3.4"); !errors.Is(err, errs.ErrRateLimited) {
+	if _, _, err := s.LoginWithIP(context.Background(), "alice", "correct", "1.2.3.4", "", nil); !errors.Is(err, errs.ErrRateLimited) {
 		t.Fatalf("want ErrRateLimited, got %v", err)
 	}
 	lim.allowOK = true
 
 	users.getErr = errs.ErrNotFound
-	if _, _, err := s.LoginWithIP(context.Background(), "nope", "x", ""); !errors.Is(err, errs.ErrUnauthorized) {
+	if _, _, err := s.LoginWithIP(context.Background(), "nope", "x", "", "", nil); !errors.Is(err, errs.ErrUnauthorized) {
 		t.Fatalf("want ErrUnauthorized on missing user, got %v", err)
 	}
+	users.getErr = fmt.Errorf("get user: %w", errs.ErrUnavailable)
+	calls := lim.failureCalls
+	if _, _, err := s.LoginWithIP(context.Background(), "alice", "correct", "", "", nil); !errors.Is(err, errs.ErrUnavailable) {
+		t.Fatalf("want ErrUnavailable during an outage, got %v", err)
+	}
+	if lim.failureCalls != calls {
+		t.Fatalf("an outage must not count as a failed attempt")
+	}
 	users.getErr = nil
 
 	lim.failBlocked = true
-	if _, _, err := s.LoginWithIP(context.Background(), "alice", "wrong", ""); !errors.Is(err, errs.ErrRateLimited) {
+	if _, _, err := s.LoginWithIP(context.Background(), "alice", "wrong", "", "", nil); !errors.Is(err, errs.ErrRateLimited) {
 		t.Fatalf("want ErrRateLimited on blocked after failure, got %v", err)
 	}
 
 	lim.failBlocked = false
-	if _, _, err := s.LoginWithIP(context.Background(), "alice", "wrong", ""); !errors.Is(err, errs.ErrUnauthorized) {
+	if _, _, err := s.LoginWithIP(context.Background(), "alice", "wrong", "", "", nil); !errors.Is(err, errs.ErrUnauthorized) {
 		t.Fatalf("want ErrUnauthorized on wrong password, got %v", err)
 	}
 
-	tok, gotUser, err := s.LoginWithIP(context.Background(), "alice", "correct", "127.0.0.1:123")
+	lim.successFails = 2
+	tok, gotUser, err := s.LoginWithIP(context.Background(), "alice", "correct", "127.0.0.1:123", "", nil)
 	if err != nil {
 		t.Fatalf("LoginWithIP success: %v", err)
 	}
-	if tok.AccessToken == "" || tok.ExpiresAt.Before(time.Now()) {
+	if tok.AccessToken == "" || tok.ExpiresAt.Before(time.Now()) || tok.FailedLogins != 2 {
 		t.Fatalf("bad token: %+v", tok)
 	}
 	if gotUser.ID != u.ID || len(gotUser.KekSalt) == 0 {
@@ -192,12 +288,256 @@ func TestAuth_LoginWithIP_RateLimiterAndCreds(t *testing.T) {
 	}
 }
 
+func TestAuth_LoginHistory(t *testing.T) {
+	t.Parallel()
+	salt, _ := pkgcrypto.RandBytes(16)
+	u := &model.User{ID: uuid.Must(uuid.NewV4()), Username: "bob", SaltAuth: salt, PwdHash: pkgcrypto.HashPassword([]byte("p"), salt)}
+	users := &fakeUsers{byName: map[string]*model.User{"bob": u}}
+	s := NewAuthService(users, tokensign.HMAC([]byte("k")), time.Minute, &fakeLimiter{allowOK: true})
+	ctx := context.Background()
+
+	_, _, _ = s.LoginWithIP(ctx, "bob", "wrong", "10.0.0.1:5000", "", nil)
+	_, _, _ = s.LoginWithIP(ctx, "nobody", "p", "10.0.0.1:5000", "", nil)
+	if _, _, err := s.LoginWithIP(ctx, "bob", "p", "10.0.0.1:5001", "", nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(users.logins) != 2 || users.logins[0].Success || !users.logins[1].Success {
+		t.Fatalf("recorded %+v", users.logins)
+	}
+	if !bytes.Equal(users.logins[0].IPHash, limiter.HashIP("10.0.0.1")) || !bytes.Equal(users.logins[0].IPHash, users.logins[1].IPHash) {
+		t.Fatal("the port must not be part of the address hash")
+	}
+
+	if _, err := s.LoginHistory(ctx, u.ID, 0); err != nil || users.lastLimit != loginHistoryDefault {
+		t.Fatalf("default limit: %d %v", users.lastLimit, err)
+	}
+	if _, _ = s.LoginHistory(ctx, u.ID, 1000); users.lastLimit != loginHistoryKeep {
+		t.Fatalf("limit not capped: %d", users.lastLimit)
+	}
+	if _, err := s.LoginHistory(ctx, u.ID, -1); !errors.Is(err, errs.ErrInvalidArgument) {
+		t.Fatalf("negative limit: %v", err)
+	}
+}
+
+// fakeGeo places addresses by a fixed table.
+type fakeGeo map[string]geoip.Location
+
+func (g fakeGeo) Locate(addr string) geoip.Location { return g[addr] }
+
+func TestLoginAnomalies(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+	london := geoip.Location{Country: "GB", Lat: 51.5, Lon: -0.13, HasCoords: true}
+	paris := geoip.Location{Country: "FR", Lat: 48.86, Lon: 2.35, HasCoords: true}
+	tokyo := geoip.Location{Country: "JP", Lat: 35.68, Lon: 139.69, HasCoords: true}
+	at := func(ago time.Duration, where geoip.Location, ok bool) model.LoginAttempt {
+		return model.LoginAttempt{At: now.Add(-ago), Where: where, Success: ok}
+	}
+	cases := []struct {
+		name string
+		hist []model.LoginAttempt
+		cur  geoip.Location
+		want []string
+	}{
+		{"first located login", []model.LoginAttempt{at(time.Hour, geoip.Location{}, true)}, london, nil},
+		{"same country", []model.LoginAttempt{at(time.Minute, london, true)}, london, nil},
+		{"short hop", []model.LoginAttempt{at(time.Hour, london, true)}, paris, []string{model.LoginNewCountry}},
+		{"failures do not count", []model.LoginAttempt{at(time.Hour, london, true), at(2*time.Hour, tokyo, false)}, tokyo,
+			[]string{model.LoginNewCountry, model.LoginImpossibleTravel}},
+		{"known country, too fast", []model.LoginAttempt{at(time.Hour, tokyo, true), at(48*time.Hour, london, true)}, london,
+			[]string{model.LoginImpossibleTravel}},
+		{"long enough", []model.LoginAttempt{at(24*time.Hour, tokyo, true), at(48*time.Hour, london, true)}, london, nil},
+		{"no coordinates", []model.LoginAttempt{at(time.Hour, geoip.Location{Country: "JP"}, true)}, geoip.Location{Country: "GB"},
+			[]string{model.LoginNewCountry}},
+	}
+	for _, c := range cases {
+		got := loginAnomalies(c.hist, model.LoginAttempt{At: now, Where: c.cur, Success: true})
+		if !slices.Equal(got, c.want) {
+			t.Errorf("%s: got %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestAuth_LoginWithIP_Geo(t *testing.T) {
+	t.Parallel()
+	salt, _ := pkgcrypto.RandBytes(16)
+	u := &model.User{ID: uuid.Must(uuid.NewV4()), Username: "carol", SaltAuth: salt, PwdHash: pkgcrypto.HashPassword([]byte("p"), salt)}
+	users := &fakeUsers{byName: map[string]*model.User{"carol": u}}
+	s := NewAuthService(users, tokensign.HMAC([]byte("k")), time.Minute, &fakeLimiter{allowOK: true})
+	s.SetGeo(fakeGeo{
+		"10.0.0.1": {Country: "GB", Lat: 51.5, Lon: -0.13, HasCoords: true},
+		"10.0.0.2": {Country: "JP", Lat: 35.68, Lon: 139.69, HasCoords: true},
+	})
+	ctx := context.Background()
+
+	tok, _, err := s.LoginWithIP(ctx, "carol", "p", "10.0.0.1:5000", "", nil)
+	if err != nil || tok.Anomalies != nil {
+		t.Fatalf("first login: %v %v", tok.Anomalies, err)
+	}
+	if users.logins[0].Where.Country != "GB" {
+		t.Fatalf("login not located: %+v", users.logins[0])
+	}
+	tok, _, err = s.LoginWithIP(ctx, "carol", "p", "10.0.0.2:5000", "", nil)
+	if err != nil || !slices.Equal(tok.Anomalies, []string{model.LoginNewCountry, model.LoginImpossibleTravel}) {
+		t.Fatalf("jump to another continent: %v %v", tok.Anomalies, err)
+	}
+}
+
+// failingSessions cannot store sessions.
+type failingSessions struct{ repository.SessionRepository }
+
+func (failingSessions) Create(context.Context, *model.Session, []byte, time.Time, int) error {
+	return errors.New("db down")
+}
+
+func TestAuth_Sessions(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	salt, _ := pkgcrypto.RandBytes(16)
+	u := &model.User{ID: uuid.Must(uuid.NewV4()), Username: "dave", SaltAuth: salt, PwdHash: pkgcrypto.HashPassword([]byte("p"), salt)}
+	signer := tokensign.HMAC([]byte("k"))
+	s := NewAuthService(&fakeUsers{byName: map[string]*model.User{"dave": u}}, signer, time.Minute, &fakeLimiter{allowOK: true})
+
+	tok, _, err := s.LoginWithIP(ctx, "dave", "p", "", "laptop", nil)
+	if err != nil || tok.RefreshToken != "" {
+		t.Fatalf("sessions off: %q %v", tok.RefreshToken, err)
+	}
+	if _, err := s.Refresh(ctx, "x"); !errors.Is(err, errs.ErrNotConfigured) {
+		t.Fatalf("refresh with sessions off: %v", err)
+	}
+
+	repo := memory.NewSessionRepo()
+	s.SetSessions(repo, time.Hour)
+	tok, _, err = s.LoginWithIP(ctx, "dave", "p", "", "laptop", nil)
+	if err != nil || tok.RefreshToken == "" {
+		t.Fatalf("login: %v", err)
+	}
+	sessions, _ := s.Sessions(ctx, u.ID)
+	if len(sessions) != 1 || sessions[0].Label != "laptop" || time.Until(sessions[0].ExpiresAt) < 59*time.Minute {
+		t.Fatalf("sessions: %+v", sessions)
+	}
+	sid := func(access string) string {
+		var c tokensign.Claims
+		if _, err := jwt.ParseWithClaims(access, &c, tokensign.Keyfunc(signer)); err != nil {
+			t.Fatal(err)
+		}
+		return c.Session
+	}
+	if sid(tok.AccessToken) != sessions[0].ID.String() {
+		t.Fatal("login access token without its session")
+	}
+
+	next, err := s.Refresh(ctx, tok.RefreshToken)
+	if err != nil || next.RefreshToken == tok.RefreshToken || sid(next.AccessToken) != sessions[0].ID.String() {
+		t.Fatalf("refresh: %+v %v", next, err)
+	}
+	if _, err := s.Refresh(ctx, tok.RefreshToken); !errors.Is(err, errs.ErrUnauthorized) {
+		t.Fatalf("reused refresh token: %v", err)
+	}
+	if _, err := s.Refresh(ctx, ""); !errors.Is(err, errs.ErrInvalidArgument) {
+		t.Fatalf("empty refresh token: %v", err)
+	}
+	if err := s.RevokeSession(ctx, u.ID, sessions[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Refresh(ctx, next.RefreshToken); !errors.Is(err, errs.ErrUnauthorized) {
+		t.Fatalf("revoked session: %v", err)
+	}
+
+	s.SetSessions(failingSessions{}, time.Hour)
+	if _, _, err := s.LoginWithIP(ctx, "dave", "p", "", "", nil); err == nil {
+		t.Fatal("login without a stored session")
+	}
+}
+
+func TestAuth_Scopes(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	salt, _ := pkgcrypto.RandBytes(16)
+	u := &model.User{ID: uuid.Must(uuid.NewV4()), Username: "erin", SaltAuth: salt, PwdHash: pkgcrypto.HashPassword([]byte("p"), salt)}
+	signer := tokensign.HMAC([]byte("k"))
+	s := NewAuthService(&fakeUsers{byName: map[string]*model.User{"erin": u}}, signer, time.Minute, &fakeLimiter{allowOK: true})
+	s.SetSessions(memory.NewSessionRepo(), time.Hour)
+	scope := func(access string) string {
+		var c tokensign.Claims
+		if _, err := jwt.ParseWithClaims(access, &c, tokensign.Keyfunc(signer)); err != nil {
+			t.Fatal(err)
+		}
+		return c.Scope
+	}
+
+	if _, _, err := s.LoginWithIP(ctx, "erin", "p", "", "", []string{"items:delete"}); !errors.Is(err, errs.ErrInvalidArgument) {
+		t.Fatalf("unknown scope: %v", err)
+	}
+	tok, _, err := s.LoginWithIP(ctx, "erin", "p", "", "", nil)
+	if err != nil || scope(tok.AccessToken) != "" || tok.Scopes != nil {
+		t.Fatalf("unscoped login: %+v %v", tok, err)
+	}
+	tok, _, err = s.LoginWithIP(ctx, "erin", "p", "", "sync", []string{model.ScopeItemsWrite, model.ScopeItemsRead, model.ScopeItemsRead})
+	if err != nil || scope(tok.AccessToken) != "items:read items:write" || len(tok.Scopes) != 2 {
+		t.Fatalf("scoped login: %+v %v", tok, err)
+	}
+	next, err := s.Refresh(ctx, tok.RefreshToken)
+	if err != nil || scope(next.AccessToken) != "items:read items:write" {
+		t.Fatalf("refresh widened the scopes: %+v %v", next, err)
+	}
+}
+
+func TestAuth_Duress(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	lim := &fakeLimiter{allowOK: true}
+	s := NewAuthService(memory.NewUserRepo(), tokensign.HMAC([]byte("k")), time.Minute, lim)
+
+	u, err := s.Register(ctx, "frank", "real")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetDuress(ctx, u.ID, "real"); !errors.Is(err, errs.ErrInvalidArgument) {
+		t.Fatalf("duress password equal to the real one: %v", err)
+	}
+	if err := s.RemoveDuress(ctx, u.ID); !errors.Is(err, errs.ErrNotFound) {
+		t.Fatalf("remove without one: %v", err)
+	}
+	if err := s.SetDuress(ctx, u.ID, "decoy"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetDuress(ctx, u.ID, "other"); !errors.Is(err, errs.ErrAlreadyExists) {
+		t.Fatalf("second duress password: %v", err)
+	}
+
+	_, real, err := s.LoginWithIP(ctx, "frank", "real", "", "", nil)
+	if err != nil || real.ID != u.ID {
+		t.Fatalf("real login: %v %v", real.ID, err)
+	}
+	_, vault, err := s.LoginWithIP(ctx, "frank", "decoy", "", "", nil)
+	if err != nil || vault.ID == u.ID || bytes.Equal(vault.KekSalt, u.KekSalt) {
+		t.Fatalf("duress login: %v %v", vault.ID, err)
+	}
+	if lim.successCalls != 2 {
+		t.Fatalf("success calls %d", lim.successCalls)
+	}
+	if _, err := s.Register(ctx, vault.Username, "x"); !errors.Is(err, errs.ErrInvalidArgument) {
+		t.Fatalf("registering a vault name: %v", err)
+	}
+	if _, _, err := s.LoginWithIP(ctx, vault.Username, "decoy", "", "", nil); !errors.Is(err, errs.ErrUnauthorized) {
+		t.Fatalf("vault logged in by name: %v", err)
+	}
+
+	if err := s.RemoveDuress(ctx, u.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.LoginWithIP(ctx, "frank", "decoy", "", "", nil); !errors.Is(err, errs.ErrUnauthorized) {
+		t.Fatalf("login after removal: %v", err)
+	}
+}
+
 func TestAuth_issueAccessToken_UsedViaLoginTTL(t *testing.T) {
 	t.Parallel()
 
 	users := &fakeUsers{byName: map[string]*model.User{}}
 	lim := &fakeLimiter{allowOK: true}
-	s := NewAuthService(users, []byte("k"), 1*time.Second, lim)
+	s := NewAuthService(users, tokensign.HMAC([]byte("k")), 1*time.Second, lim)
 
 	salt, _ := pkgcrypto.RandBytes(16)
 	u := &model.User{
@@ -209,7 +549,7 @@ func TestAuth_issueAccessToken_UsedViaLoginTTL(t *testing.T) {
 	}
 	_ = users.Create(context.Background(), u)
 
-	tk, _, err := s.LoginWithIP(context.Background(), "bob", "p", "")
+	tk, _, err := s.LoginWithIP(context.Background(), "bob", "p", "", "", nil)
 	if err != nil {
 		t.Fatalf("login: %v", err)
 	}
@@ -226,7 +566,7 @@ func TestAuth_SetWrappedDEK(t *testing.T) {
 	t.Parallel()
 
 	users := &fakeUsers{byName: map[string]*model.User{}}
-	s := NewAuthService(users, []byte("k"), time.Minute, &fakeLimiter{allowOK: true})
+	s := NewAuthService(users, tokensign.HMAC([]byte("k")), time.Minute, &fakeLimiter{allowOK: true})
 
 	uid := uuid.Must(uuid.NewV4())
 	users.byName["u"] = &model.User{ID: uid, Username: "u", WrappedDEK: []byte{}}
@@ -250,4 +590,111 @@ func TestAuth_SetWrappedDEK(t *testing.T) {
 	if err := s.SetWrappedDEK(context.Background(), uid, []byte{9}); err == nil {
 		t.Fatalf("want propagated repo error")
 	}
+
+	if err := s.ReplaceWrappedDEK(context.Background(), uid, nil, []byte{8}); err == nil {
+		t.Fatalf("want validation error (empty previous)")
+	}
+	if err := s.ReplaceWrappedDEK(context.Background(), uid, []byte{1}, []byte{8}); !errors.Is(err, errs.ErrVersionConflict) {
+		t.Fatalf("want ErrVersionConflict on stale previous, got %v", err)
+	}
+	if err := s.ReplaceWrappedDEK(context.Background(), uid, []byte{7, 7}, []byte{8}); err != nil {
+		t.Fatalf("ReplaceWrappedDEK: %v", err)
+	}
+	if !bytes.Equal(users.byName["u"].WrappedDEK, []byte{8}) {
+		t.Fatalf("wrapped DEK not replaced: %v", users.byName["u"].WrappedDEK)
+	}
+}
+
+func TestAuth_Freeze(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	lim := &fakeLimiter{allowOK: true}
+	s := NewAuthService(memory.NewUserRepo(), tokensign.HMAC([]byte("k")), time.Minute, lim)
+	u, err := s.Register(ctx, "gina", "pw")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Freeze(ctx, u.ID, "guess", "10.0.0.1"); !errors.Is(err, errs.ErrUnauthorized) || lim.failureCalls != 1 {
+		t.Fatalf("wrong password: %v, %d failures", err, lim.failureCalls)
+	}
+	if at, _ := s.FrozenAt(ctx, u.ID); !at.IsZero() {
+		t.Fatalf("frozen by a wrong password at %v", at)
+	}
+	at, err := s.Freeze(ctx, u.ID, "pw", "10.0.0.1")
+	if err != nil || at.IsZero() {
+		t.Fatalf("freeze: %v %v", at, err)
+	}
+	if again, err := s.Freeze(ctx, u.ID, "pw", "10.0.0.1"); err != nil || !again.Equal(at) {
+		t.Fatalf("freezing twice moved the time: %v %v", again, err)
+	}
+	if got, _ := s.FrozenAt(ctx, u.ID); !got.Equal(at) {
+		t.Fatalf("frozen at %v, want %v", got, at)
+	}
+	if lim.successCalls != 0 {
+		t.Fatal("a confirmed password reset the login failures")
+	}
+
+	lim.allowOK = false
+	if err := s.Unfreeze(ctx, u.ID, "pw", "10.0.0.1"); !errors.Is(err, errs.ErrRateLimited) {
+		t.Fatalf("locked out: %v", err)
+	}
+	lim.allowOK = true
+	if err := s.Unfreeze(ctx, u.ID, "pw", "10.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s.FrozenAt(ctx, u.ID); !got.IsZero() {
+		t.Fatalf("still frozen at %v", got)
+	}
+}
+
+func TestAuth_Disabled(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	lim := &fakeLimiter{allowOK: true}
+	s := NewAuthService(memory.NewUserRepo(), tokensign.HMAC([]byte("k")), time.Minute, lim)
+	s.SetSessions(memory.NewSessionRepo(), time.Hour)
+	u, err := s.Register(ctx, "hank", "pw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetDuress(ctx, u.ID, "decoy"); err != nil {
+		t.Fatal(err)
+	}
+	tok, _, err := s.LoginWithIP(ctx, "hank", "pw", "", "laptop", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.SetDisabled(ctx, u.ID, true); err != nil {
+		t.Fatal(err)
+	}
+	if d, err := s.Disabled(ctx, u.ID); err != nil || !d {
+		t.Fatalf("disabled %v %v", d, err)
+	}
+	if _, _, err := s.LoginWithIP(ctx, "hank", "pw", "", "", nil); !errors.Is(err, errs.ErrAccountDisabled) {
+		t.Fatalf("login: %v", err)
+	}
+	if _, _, err := s.LoginWithIP(ctx, "hank", "decoy", "", "", nil); !errors.Is(err, errs.ErrAccountDisabled) {
+		t.Fatalf("duress login: %v", err)
+	}
+	if _, _, err := s.LoginWithIP(ctx, "hank", "guess", "", "", nil); !errors.Is(err, errs.ErrUnauthorized) {
+		t.Fatalf("a wrong password learnt more: %v", err)
+	}
+	if _, err := s.Refresh(ctx, tok.RefreshToken); !errors.Is(err, errs.ErrAccountDisabled) {
+		t.Fatalf("refresh: %v", err)
+	}
+	if lim.successCalls != 1 {
+		t.Fatalf("refused logins reset the failures: %d success calls", lim.successCalls)
+	}
+
+	if err := s.SetDisabled(ctx, u.ID, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.LoginWithIP(ctx, "hank", "pw", "", "", nil); err != nil {
+		t.Fatalf("after reactivation: %v", err)
+	}
+	if err := s.SetDisabled(ctx, uuid.Must(uuid.NewV4()), true); !errors.Is(err, errs.ErrNotFound) {
+		t.Fatalf("unknown user: %v", err)
+	}
 }
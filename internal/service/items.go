@@ -6,9 +6,9 @@ import (
 	"fmt"
 
 	"github.com/gofrs/uuid/v5"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/merkle"
 	"github.com/and161185/goph-keeper/internal/model"
 	"github.com/and161185/goph-keeper/internal/repository"
 )
@@ -17,17 +17,60 @@ import (
 type ItemService interface {
 	// Upsert creates or updates items atomically and returns new versions.
 	Upsert(ctx context.Context, userID uuid.UUID, ups []model.UpsertItem) ([]model.ItemVersion, error)
+	// UpsertEach writes each item on its own, with a result per item.
+	UpsertEach(ctx context.Context, userID uuid.UUID, ups []model.UpsertItem) ([]model.ItemResult, error)
 	// Delete sets tombstone on an item and returns new version.
 	Delete(ctx context.Context, userID, id uuid.UUID, baseVer int64) (model.ItemVersion, error)
-	// GetChanges returns changes since provided version for delta sync.
-	GetChanges(ctx context.Context, userID uuid.UUID, sinceVer int64) ([]model.Change, error)
+	// DeleteBatch deletes all of the items or none, with a result per item.
+	DeleteBatch(ctx context.Context, userID uuid.UUID, refs []model.ItemRef) ([]model.ItemResult, error)
+	// ApplyChangeSet upserts and deletes items all or none, with a result
+	// per entry.
+	ApplyChangeSet(ctx context.Context, userID uuid.UUID, cs model.ChangeSet) (model.ChangeSetResult, error)
+	// GetChanges returns up to limit changes after the cursor for delta sync.
+	GetChanges(ctx context.Context, userID uuid.UUID, after model.ChangeCursor, limit int) ([]model.Change, error)
+	// GetJournal returns up to limit changes from the change journal after
+	// seq, in commit order.
+	GetJournal(ctx context.Context, userID uuid.UUID, afterSeq int64, limit int) ([]model.Change, error)
+	// BeginSync returns the journal position a sync session reads the vault
+	// at: the user's newest change.
+	BeginSync(ctx context.Context, userID uuid.UUID) (int64, error)
+	// GetSnapshot returns up to limit items after afterID, in id order, as
+	// they stood at journal position atSeq.
+	GetSnapshot(ctx context.Context, userID uuid.UUID, atSeq int64, afterID uuid.UUID, limit int) ([]model.Change, error)
 	// GetOne returns a single item by ID.
 	GetOne(ctx context.Context, userID, id uuid.UUID) (*model.Item, error)
+	// GetVersion returns a single item at a past version.
+	GetVersion(ctx context.Context, userID, id uuid.UUID, ver int64) (*model.Item, error)
+	// Export calls fn with each of the user's items, as of one snapshot and
+	// in id order; with includeDeleted, tombstones too.
+	Export(ctx context.Context, userID uuid.UUID, includeDeleted bool, fn func(model.Item) error) error
+	// Stats returns aggregate counters over the user's items.
+	Stats(ctx context.Context, userID uuid.UUID) (model.ItemStats, error)
+	// VaultLeaves returns the user's Merkle leaves, sorted for merkle.Root.
+	VaultLeaves(ctx context.Context, userID uuid.UUID) ([]merkle.Leaf, error)
+}
+
+// ChangeNotifier is told about item changes after they are committed. It
+// must not block: it runs on the request path.
+type ChangeNotifier interface {
+	ItemsChanged(ctx context.Context, userID uuid.UUID, changes []model.Change)
+}
+
+// Notifiers passes each notification to all of its members in turn.
+type Notifiers []ChangeNotifier
+
+// ItemsChanged implements ChangeNotifier.
+func (ns Notifiers) ItemsChanged(ctx context.Context, userID uuid.UUID, changes []model.Change) {
+	for _, n := range ns {
+		n.ItemsChanged(ctx, userID, changes)
+	}
 }
 
 type ItemServiceImpl struct {
 	repo     repository.ItemRepository
 	maxBatch int
+	maxItems int64
+	notify   ChangeNotifier
 }
 
 // NewItemService constructs ItemService with batch limits.
@@ -38,68 +81,384 @@ func NewItemService(repo repository.ItemRepository, maxBatch int) *ItemServiceIm
 	return &ItemServiceImpl{repo: repo, maxBatch: maxBatch}
 }
 
+// SetMaxItems caps the live items each user may hold; 0 lifts the cap.
+func (s *ItemServiceImpl) SetMaxItems(n int64) { s.maxItems = n }
+
+// SetNotifier makes s report committed upserts and deletes to n.
+func (s *ItemServiceImpl) SetNotifier(n ChangeNotifier) { s.notify = n }
+
+// changed reports committed versions to the notifier, if any. Notifications
+// carry no blobs.
+func (s *ItemServiceImpl) changed(ctx context.Context, userID uuid.UUID, vs []model.ItemVersion, deleted bool) {
+	if s.notify == nil || len(vs) == 0 {
+		return
+	}
+	cs := make([]model.Change, len(vs))
+	for i, v := range vs {
+		cs[i] = model.Change{ID: v.ID, Ver: v.NewVer, Deleted: deleted, UpdatedAt: v.UpdatedAt}
+	}
+	s.notify.ItemsChanged(ctx, userID, cs)
+}
+
+// newItemID reports whether id may name a new item. Clients generate
+// time-ordered UUIDv7s, which keep inserts at the right edge of the items
+// index; v4 is still accepted from older clients. Items that already exist
+// keep whatever id they have.
+func newItemID(id uuid.UUID) bool {
+	return id.Variant() == uuid.VariantRFC9562 && (id.Version() == uuid.V7 || id.Version() == uuid.V4)
+}
+
 // Upsert validates input and delegates atomic batch upsert to repository.
 // Validation rules:
 // - len(ups) > 0
 // - each ID != uuid.Nil
 // - BaseVer >= 0
+// - new items (BaseVer == 0) have a UUIDv7 or v4 ID
 // - BlobEnc not empty
+// - hints name a known size class, the blob fits it, and schema_version >= 0
+// - new items do not take the user past the item cap, if one is set
 func (s *ItemServiceImpl) Upsert(ctx context.Context, userID uuid.UUID, ups []model.UpsertItem) ([]model.ItemVersion, error) {
 	if userID == uuid.Nil {
-		return nil, errors.New("validation: empty userID")
+		return nil, fmt.Errorf("%w: empty userID", errs.ErrInvalidArgument)
 	}
 	if len(ups) == 0 {
 		return []model.ItemVersion{}, nil
 	}
+	if err := s.checkUpserts(ctx, userID, ups); err != nil {
+		return nil, err
+	}
+	res, err := s.repo.UpsertBatch(ctx, userID, ups)
+	if err != nil {
+		return nil, err
+	}
+	s.changed(ctx, userID, res, false)
+	return res, nil
+}
+
+// UpsertEach is Upsert in best-effort mode: the batch is validated as a
+// whole, then each item is written or reported as a conflict on its own.
+// Only the items written are notified.
+func (s *ItemServiceImpl) UpsertEach(ctx context.Context, userID uuid.UUID, ups []model.UpsertItem) ([]model.ItemResult, error) {
+	if userID == uuid.Nil {
+		return nil, fmt.Errorf("%w: empty userID", errs.ErrInvalidArgument)
+	}
+	if len(ups) == 0 {
+		return []model.ItemResult{}, nil
+	}
+	if err := s.checkUpserts(ctx, userID, ups); err != nil {
+		return nil, err
+	}
+	res, err := s.repo.UpsertEach(ctx, userID, ups)
+	if err != nil {
+		return nil, err
+	}
+	vs := make([]model.ItemVersion, 0, len(res))
+	for _, r := range res {
+		if r.Status == model.StatusOK {
+			vs = append(vs, model.ItemVersion{ID: r.ID, NewVer: r.Ver, UpdatedAt: r.UpdatedAt})
+		}
+	}
+	s.changed(ctx, userID, vs, false)
+	return res, nil
+}
+
+// checkUpserts applies Upsert's rules to a non-empty batch.
+func (s *ItemServiceImpl) checkUpserts(ctx context.Context, userID uuid.UUID, ups []model.UpsertItem) error {
 	if s.maxBatch > 0 && len(ups) > s.maxBatch {
-		return nil, fmt.Errorf("validation: batch too large (%d > %d)", len(ups), s.maxBatch)
+		return fmt.Errorf("%w: batch too large (%d > %d)", errs.ErrInvalidArgument, len(ups), s.maxBatch)
 	}
 
 	const maxBlob = 1 << 20
 	for i := range ups {
 		if ups[i].ID == uuid.Nil {
-			return nil, fmt.Errorf("validation: item[%d] empty id", i)
+			return fmt.Errorf("%w: item[%d] empty id", errs.ErrInvalidArgument, i)
 		}
 		if ups[i].BaseVer < 0 {
-			return nil, fmt.Errorf("validation: item[%d] negative base_ver", i)
+			return fmt.Errorf("%w: item[%d] negative base_ver", errs.ErrInvalidArgument, i)
+		}
+		if ups[i].BaseVer == 0 && !newItemID(ups[i].ID) {
+			return fmt.Errorf("%w: item[%d] id must be a UUIDv7 or UUIDv4", errs.ErrInvalidArgument, i)
 		}
 		if len(ups[i].BlobEnc) == 0 {
-			return nil, fmt.Errorf("validation: item[%d] empty blob", i)
+			return fmt.Errorf("%w: item[%d] empty blob", errs.ErrInvalidArgument, i)
 		}
 		if len(ups[i].BlobEnc) > maxBlob {
-			return nil, status.Error(codes.InvalidArgument, "blob too large (>1MiB)")
+			return fmt.Errorf("%w: item[%d] blob too large (>1MiB)", errs.ErrInvalidArgument, i)
 		}
+		if err := checkHints(ups[i].Hints, len(ups[i].BlobEnc)); err != nil {
+			return fmt.Errorf("%w: item[%d] %v", errs.ErrInvalidArgument, i, err)
+		}
+	}
+
+	return s.checkItemLimit(ctx, userID, ups)
+}
+
+// checkHints validates h for a blob of n bytes.
+func checkHints(h model.ItemHints, n int) error {
+	if !h.SizeClass.Valid() {
+		return fmt.Errorf("unknown size class %d", h.SizeClass)
+	}
+	if maxBytes := h.SizeClass.MaxBytes(); maxBytes > 0 && n > maxBytes {
+		return fmt.Errorf("blob of %d bytes exceeds its size class (max %d)", n, maxBytes)
 	}
+	if h.SchemaVersion < 0 {
+		return errors.New("negative schema_version")
+	}
+	return nil
+}
 
-	return s.repo.UpsertBatch(ctx, userID, ups)
+// checkItemLimit fails with errs.ErrItemLimit if the batch's new items
+// (BaseVer == 0) would take the user past maxItems. Updates and restores of
+// existing rows are not counted, so a user at the cap can still edit. The
+// check runs outside the upsert transaction: concurrent batches may overshoot
+// by their size, which still bounds storage.
+func (s *ItemServiceImpl) checkItemLimit(ctx context.Context, userID uuid.UUID, ups []model.UpsertItem) error {
+	if s.maxItems <= 0 {
+		return nil
+	}
+	var added int64
+	for i := range ups {
+		if ups[i].BaseVer == 0 {
+			added++
+		}
+	}
+	if added == 0 {
+		return nil
+	}
+	st, err := s.repo.Stats(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if live := st.Items - st.Deleted; live+added > s.maxItems {
+		return fmt.Errorf("%w: %d live items + %d new (max %d)", errs.ErrItemLimit, live, added, s.maxItems)
+	}
+	return nil
 }
 
 // Delete applies tombstone with optimistic concurrency (ver++).
 func (s *ItemServiceImpl) Delete(ctx context.Context, userID, id uuid.UUID, baseVer int64) (model.ItemVersion, error) {
 	if userID == uuid.Nil || id == uuid.Nil {
-		return model.ItemVersion{}, errors.New("validation: empty userID/id")
+		return model.ItemVersion{}, fmt.Errorf("%w: empty userID/id", errs.ErrInvalidArgument)
 	}
 	if baseVer < 0 {
-		return model.ItemVersion{}, errors.New("validation: negative base_ver")
+		return model.ItemVersion{}, fmt.Errorf("%w: negative base_ver", errs.ErrInvalidArgument)
+	}
+	v, err := s.repo.Delete(ctx, userID, id, baseVer)
+	if err != nil {
+		return v, err
+	}
+	s.changed(ctx, userID, []model.ItemVersion{v}, true)
+	return v, nil
+}
+
+// DeleteBatch validates the refs like Delete, plus the batch limit, and
+// delegates to the repository. Only an applied batch is notified.
+func (s *ItemServiceImpl) DeleteBatch(ctx context.Context, userID uuid.UUID, refs []model.ItemRef) ([]model.ItemResult, error) {
+	if userID == uuid.Nil {
+		return nil, fmt.Errorf("%w: empty userID", errs.ErrInvalidArgument)
+	}
+	if len(refs) == 0 {
+		return []model.ItemResult{}, nil
+	}
+	if s.maxBatch > 0 && len(refs) > s.maxBatch {
+		return nil, fmt.Errorf("%w: batch too large (%d > %d)", errs.ErrInvalidArgument, len(refs), s.maxBatch)
+	}
+	if err := checkRefs(refs); err != nil {
+		return nil, err
+	}
+	res, err := s.repo.DeleteBatch(ctx, userID, refs)
+	if err != nil {
+		return nil, err
+	}
+	if model.AllOK(res) {
+		s.changed(ctx, userID, resultVersions(res), true)
+	}
+	return res, nil
+}
+
+// checkRefs applies Delete's rules to each ref of a batch.
+func checkRefs(refs []model.ItemRef) error {
+	for i, ref := range refs {
+		if ref.ID == uuid.Nil {
+			return fmt.Errorf("%w: item[%d] empty id", errs.ErrInvalidArgument, i)
+		}
+		if ref.BaseVer < 0 {
+			return fmt.Errorf("%w: item[%d] negative base_ver", errs.ErrInvalidArgument, i)
+		}
+	}
+	return nil
+}
+
+// resultVersions returns the versions of a committed batch's results.
+func resultVersions(rs []model.ItemResult) []model.ItemVersion {
+	vs := make([]model.ItemVersion, len(rs))
+	for i, r := range rs {
+		vs[i] = model.ItemVersion{ID: r.ID, NewVer: r.Ver, UpdatedAt: r.UpdatedAt}
+	}
+	return vs
+}
+
+// ApplyChangeSet checks the upserts as Upsert does and the deletes as
+// DeleteBatch does, with the batch limit over both, and refuses an id that
+// is both upserted and deleted. Only an applied change set is notified.
+func (s *ItemServiceImpl) ApplyChangeSet(ctx context.Context, userID uuid.UUID, cs model.ChangeSet) (model.ChangeSetResult, error) {
+	if userID == uuid.Nil {
+		return model.ChangeSetResult{}, fmt.Errorf("%w: empty userID", errs.ErrInvalidArgument)
+	}
+	n := len(cs.Upserts) + len(cs.Deletes)
+	if n == 0 {
+		return model.ChangeSetResult{Upserts: []model.ItemResult{}, Deletes: []model.ItemResult{}}, nil
+	}
+	if s.maxBatch > 0 && n > s.maxBatch {
+		return model.ChangeSetResult{}, fmt.Errorf("%w: batch too large (%d > %d)", errs.ErrInvalidArgument, n, s.maxBatch)
+	}
+	if len(cs.Upserts) > 0 {
+		if err := s.checkUpserts(ctx, userID, cs.Upserts); err != nil {
+			return model.ChangeSetResult{}, err
+		}
+	}
+	if err := checkRefs(cs.Deletes); err != nil {
+		return model.ChangeSetResult{}, err
+	}
+	upserted := make(map[uuid.UUID]bool, len(cs.Upserts))
+	for _, up := range cs.Upserts {
+		upserted[up.ID] = true
+	}
+	for i, ref := range cs.Deletes {
+		if upserted[ref.ID] {
+			return model.ChangeSetResult{}, fmt.Errorf("%w: delete[%d] %s is also upserted", errs.ErrInvalidArgument, i, ref.ID)
+		}
+	}
+
+	res, err := s.repo.ApplyChangeSet(ctx, userID, cs)
+	if err != nil {
+		return model.ChangeSetResult{}, err
+	}
+	if res.Applied() {
+		s.changed(ctx, userID, resultVersions(res.Upserts), false)
+		s.changed(ctx, userID, resultVersions(res.Deletes), true)
+	}
+	return res, nil
+}
+
+// GetChanges returns up to limit changes after the cursor, ordered by
+// (ver, id); limit 0 returns them all.
+func (s *ItemServiceImpl) GetChanges(ctx context.Context, userID uuid.UUID, after model.ChangeCursor, limit int) ([]model.Change, error) {
+	if userID == uuid.Nil {
+		return nil, fmt.Errorf("%w: empty userID", errs.ErrInvalidArgument)
+	}
+	if after.Ver < 0 {
+		return nil, fmt.Errorf("%w: negative since_ver", errs.ErrInvalidArgument)
+	}
+	if limit < 0 {
+		return nil, fmt.Errorf("%w: negative page size", errs.ErrInvalidArgument)
+	}
+	return s.repo.GetChangesSince(ctx, userID, after, limit)
+}
+
+// GetJournal returns up to limit journal changes after seq; limit 0
+// returns them all.
+func (s *ItemServiceImpl) GetJournal(ctx context.Context, userID uuid.UUID, afterSeq int64, limit int) ([]model.Change, error) {
+	if userID == uuid.Nil {
+		return nil, fmt.Errorf("%w: empty userID", errs.ErrInvalidArgument)
 	}
-	return s.repo.Delete(ctx, userID, id, baseVer)
+	if afterSeq < 0 {
+		return nil, fmt.Errorf("%w: negative since_seq", errs.ErrInvalidArgument)
+	}
+	if limit < 0 {
+		return nil, fmt.Errorf("%w: negative page size", errs.ErrInvalidArgument)
+	}
+	return s.repo.GetJournalSince(ctx, userID, afterSeq, limit)
+}
+
+// BeginSync pins the user's newest journal position.
+func (s *ItemServiceImpl) BeginSync(ctx context.Context, userID uuid.UUID) (int64, error) {
+	if userID == uuid.Nil {
+		return 0, fmt.Errorf("%w: empty userID", errs.ErrInvalidArgument)
+	}
+	return s.repo.JournalHead(ctx, userID)
 }
 
-// GetChanges returns all changes with ver > sinceVer ordered by ver ASC.
-func (s *ItemServiceImpl) GetChanges(ctx context.Context, userID uuid.UUID, sinceVer int64) ([]model.Change, error) {
+// GetSnapshot returns a page of the vault as of atSeq; limit 0 returns it
+// all.
+func (s *ItemServiceImpl) GetSnapshot(ctx context.Context, userID uuid.UUID, atSeq int64, afterID uuid.UUID, limit int) ([]model.Change, error) {
 	if userID == uuid.Nil {
-		return nil, errors.New("validation: empty userID")
+		return nil, fmt.Errorf("%w: empty userID", errs.ErrInvalidArgument)
 	}
-	if sinceVer < 0 {
-		return nil, errors.New("validation: negative since_ver")
+	if atSeq < 0 {
+		return nil, fmt.Errorf("%w: negative snapshot position", errs.ErrInvalidArgument)
 	}
-	return s.repo.GetChangesSince(ctx, userID, sinceVer)
+	if limit < 0 {
+		return nil, fmt.Errorf("%w: negative page size", errs.ErrInvalidArgument)
+	}
+	return s.repo.GetSnapshot(ctx, userID, atSeq, afterID, limit)
 }
 
 // GetOne fetches single item by id.
 func (s *ItemServiceImpl) GetOne(ctx context.Context, userID, id uuid.UUID) (*model.Item, error) {
 	if userID == uuid.Nil || id == uuid.Nil {
-		return nil, errors.New("validation: empty userID/id")
+		return nil, fmt.Errorf("%w: empty userID/id", errs.ErrInvalidArgument)
 	}
 	return s.repo.GetItem(ctx, userID, id)
 }
+
+// GetVersion fetches an item as stored at version ver (ver >= 1).
+func (s *ItemServiceImpl) GetVersion(ctx context.Context, userID, id uuid.UUID, ver int64) (*model.Item, error) {
+	if userID == uuid.Nil || id == uuid.Nil {
+		return nil, fmt.Errorf("%w: empty userID/id", errs.ErrInvalidArgument)
+	}
+	if ver < 1 {
+		return nil, fmt.Errorf("%w: ver must be >= 1", errs.ErrInvalidArgument)
+	}
+	return s.repo.GetItemVersion(ctx, userID, id, ver)
+}
+
+// Export streams the user's items to fn.
+func (s *ItemServiceImpl) Export(ctx context.Context, userID uuid.UUID, includeDeleted bool, fn func(model.Item) error) error {
+	if userID == uuid.Nil {
+		return fmt.Errorf("%w: empty userID", errs.ErrInvalidArgument)
+	}
+	return s.repo.ExportItems(ctx, userID, func(it model.Item) error {
+		if it.Deleted && !includeDeleted {
+			return nil
+		}
+		return fn(it)
+	})
+}
+
+// Stats returns item counters for a user.
+func (s *ItemServiceImpl) Stats(ctx context.Context, userID uuid.UUID) (model.ItemStats, error) {
+	if userID == uuid.Nil {
+		return model.ItemStats{}, fmt.Errorf("%w: empty userID", errs.ErrInvalidArgument)
+	}
+	st, err := s.repo.Stats(ctx, userID)
+	if err != nil {
+		return model.ItemStats{}, err
+	}
+	st.MaxItems = s.maxItems
+	return st, nil
+}
+
+// VaultLeaves converts item digests into checksum leaves.
+func (s *ItemServiceImpl) VaultLeaves(ctx context.Context, userID uuid.UUID) ([]merkle.Leaf, error) {
+	if userID == uuid.Nil {
+		return nil, fmt.Errorf("%w: empty userID", errs.ErrInvalidArgument)
+	}
+	ds, err := s.repo.Digests(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	leaves := make([]merkle.Leaf, 0, len(ds))
+	for _, d := range ds {
+		l := merkle.Leaf{ID: d.ID.String(), Ver: d.Ver, Deleted: d.Deleted}
+		if !d.Deleted {
+			if len(d.BlobHash) != merkle.Size {
+				return nil, fmt.Errorf("item %s: blob hash of %d bytes", d.ID, len(d.BlobHash))
+			}
+			l.BlobHash = [merkle.Size]byte(d.BlobHash)
+		}
+		leaves = append(leaves, l)
+	}
+	merkle.Sort(leaves)
+	return leaves, nil
+}
@@ -3,10 +3,13 @@ package service
 import (
 	"context"
 	"errors"
+	"reflect"
 	"testing"
 
 	"github.com/gofrs/uuid/v5"
 
+	"github.com/and161185/goph-keeper/internal/errs"
+	"github.com/and161185/goph-keeper/internal/merkle"
 	"github.com/and161185/goph-keeper/internal/model"
 	"github.com/and161185/goph-keeper/internal/repository"
 )
@@ -16,6 +19,7 @@ type fakeItemRepo struct {
 	upsertInUps  []model.UpsertItem
 	upsertOut    []model.ItemVersion
 	upsertErr    error
+	eachOut      []model.ItemResult
 
 	delInUser uuid.UUID
 	delInID   uuid.UUID
@@ -23,15 +27,31 @@ type fakeItemRepo struct {
 	delOut    model.ItemVersion
 	delErr    error
 
+	delBatchIn  []model.ItemRef
+	delBatchOut []model.ItemResult
+
+	csIn  *model.ChangeSet
+	csOut model.ChangeSetResult
+
 	chInUser  uuid.UUID
-	chInSince int64
+	chInAfter model.ChangeCursor
+	chInLimit int
+	chInSeq   int64
+	chInID    uuid.UUID
 	chOut     []model.Change
+	headOut   int64
 	chErr     error
 
 	getInUser uuid.UUID
 	getInID   uuid.UUID
 	getOut    *model.Item
 	getErr    error
+
+	verInVer int64
+
+	statsOut   model.ItemStats
+	digestsOut []model.ItemDigest
+	exportOut  []model.Item
 }
 
 var _ repository.ItemRepository = (*fakeItemRepo)(nil)
@@ -40,12 +60,36 @@ func (f *fakeItemRepo) UpsertBatch(_ context.Context, userID uuid.UUID, ups []mo
 	f.upsertInUser, f.upsertInUps = userID, append([]model.UpsertItem(nil), ups...)
 	return append([]model.ItemVersion(nil), f.upsertOut...), f.upsertErr
 }
+func (f *fakeItemRepo) UpsertEach(_ context.Context, userID uuid.UUID, ups []model.UpsertItem) ([]model.ItemResult, error) {
+	f.upsertInUser, f.upsertInUps = userID, append([]model.UpsertItem(nil), ups...)
+	return append([]model.ItemResult(nil), f.eachOut...), f.upsertErr
+}
 func (f *fakeItemRepo) Delete(_ context.Context, userID, id uuid.UUID, baseVer int64) (model.ItemVersion, error) {
 	f.delInUser, f.delInID, f.delInBase = userID, id, baseVer
 	return f.delOut, f.delErr
 }
-func (f *fakeItemRepo) GetChangesSince(_ context.Context, userID uuid.UUID, sinceVer int64) ([]model.Change, error) {
-	f.chInUser, f.chInSince = userID, sinceVer
+func (f *fakeItemRepo) DeleteBatch(_ context.Context, userID uuid.UUID, refs []model.ItemRef) ([]model.ItemResult, error) {
+	f.delInUser, f.delBatchIn = userID, append([]model.ItemRef(nil), refs...)
+	return append([]model.ItemResult(nil), f.delBatchOut...), nil
+}
+func (f *fakeItemRepo) ApplyChangeSet(_ context.Context, userID uuid.UUID, cs model.ChangeSet) (model.ChangeSetResult, error) {
+	f.upsertInUser, f.csIn = userID, &cs
+	return f.csOut, nil
+}
+func (f *fakeItemRepo) GetChangesSince(_ context.Context, userID uuid.UUID, after model.ChangeCursor, limit int) ([]model.Change, error) {
+	f.chInUser, f.chInAfter, f.chInLimit = userID, after, limit
+	return append([]model.Change(nil), f.chOut...), f.chErr
+}
+func (f *fakeItemRepo) GetJournalSince(_ context.Context, userID uuid.UUID, afterSeq int64, limit int) ([]model.Change, error) {
+	f.chInUser, f.chInSeq, f.chInLimit = userID, afterSeq, limit
+	return append([]model.Change(nil), f.chOut...), f.chErr
+}
+func (f *fakeItemRepo) JournalHead(_ context.Context, userID uuid.UUID) (int64, error) {
+	f.chInUser = userID
+	return f.headOut, f.chErr
+}
+func (f *fakeItemRepo) GetSnapshot(_ context.Context, userID uuid.UUID, atSeq int64, afterID uuid.UUID, limit int) ([]model.Change, error) {
+	f.chInUser, f.chInSeq, f.chInID, f.chInLimit = userID, atSeq, afterID, limit
 	return append([]model.Change(nil), f.chOut...), f.chErr
 }
 func (f *fakeItemRepo) GetItem(_ context.Context, userID, id uuid.UUID) (*model.Item, error) {
@@ -53,6 +97,31 @@ func (f *fakeItemRepo) GetItem(_ context.Context, userID, id uuid.UUID) (*model.
 	return f.getOut, f.getErr
 }
 
+func (f *fakeItemRepo) GetItemVersion(_ context.Context, userID, id uuid.UUID, ver int64) (*model.Item, error) {
+	f.getInUser, f.getInID, f.verInVer = userID, id, ver
+	return f.getOut, f.getErr
+}
+
+func (f *fakeItemRepo) Stats(_ context.Context, userID uuid.UUID) (model.ItemStats, error) {
+	f.getInUser = userID
+	return f.statsOut, nil
+}
+
+func (f *fakeItemRepo) ExportItems(_ context.Context, userID uuid.UUID, fn func(model.Item) error) error {
+	f.getInUser = userID
+	for _, it := range f.exportOut {
+		if err := fn(it); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *fakeItemRepo) Digests(_ context.Context, userID uuid.UUID) ([]model.ItemDigest, error) {
+	f.getInUser = userID
+	return f.digestsOut, nil
+}
+
 func (f *fakeItemRepo) GetMaxVersion(_ context.Context, userID uuid.UUID) (int64, error) {
 	return 0, nil
 }
@@ -107,6 +176,90 @@ func TestItemService_Upsert_Validation(t *testing.T) {
 	}
 }
 
+func TestItemService_Upsert_IDVersions(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	s := NewItemService(&fakeItemRepo{}, 10)
+	user := uuid.Must(uuid.NewV4())
+	v1 := uuid.Must(uuid.NewV1())
+
+	for _, id := range []uuid.UUID{uuid.Must(uuid.NewV7()), uuid.Must(uuid.NewV4())} {
+		if _, err := s.Upsert(ctx, user, []model.UpsertItem{{ID: id, BlobEnc: []byte{1}}}); err != nil {
+			t.Fatalf("new v%d item: %v", id.Version(), err)
+		}
+	}
+	if _, err := s.Upsert(ctx, user, []model.UpsertItem{{ID: v1, BlobEnc: []byte{1}}}); !errors.Is(err, errs.ErrInvalidArgument) {
+		t.Fatalf("new v1 item: %v", err)
+	}
+	// an item that already exists keeps its id
+	if _, err := s.Upsert(ctx, user, []model.UpsertItem{{ID: v1, BaseVer: 3, BlobEnc: []byte{1}}}); err != nil {
+		t.Fatalf("update of v1 item: %v", err)
+	}
+}
+
+func TestItemService_Upsert_ItemLimit(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := &fakeItemRepo{statsOut: model.ItemStats{Items: 5, Deleted: 2}}
+	s := NewItemService(repo, 10)
+	s.SetMaxItems(4)
+	user := uuid.Must(uuid.NewV4())
+	item := func(baseVer int64) model.UpsertItem {
+		return model.UpsertItem{ID: uuid.Must(uuid.NewV7()), BaseVer: baseVer, BlobEnc: []byte{1}}
+	}
+
+	// 3 live: one more fits, two do not
+	if _, err := s.Upsert(ctx, user, []model.UpsertItem{item(0)}); err != nil {
+		t.Fatalf("under the cap: %v", err)
+	}
+	repo.upsertInUps = nil
+	if _, err := s.Upsert(ctx, user, []model.UpsertItem{item(0), item(0)}); !errors.Is(err, errs.ErrItemLimit) {
+		t.Fatalf("over the cap: %v", err)
+	}
+	if repo.upsertInUps != nil {
+		t.Fatal("a batch over the cap reached the repository")
+	}
+	// edits are not new items
+	repo.statsOut.Deleted = 1
+	if _, err := s.Upsert(ctx, user, []model.UpsertItem{item(3), item(1)}); err != nil {
+		t.Fatalf("edits at the cap: %v", err)
+	}
+
+	st, err := s.Stats(ctx, user)
+	if err != nil || st.MaxItems != 4 {
+		t.Fatalf("stats: %+v %v", st, err)
+	}
+}
+
+func TestItemService_Upsert_Hints(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	s := NewItemService(&fakeItemRepo{}, 10)
+	user := uuid.Must(uuid.NewV4())
+	item := func(size int, h model.ItemHints) []model.UpsertItem {
+		return []model.UpsertItem{{ID: uuid.Must(uuid.NewV7()), BlobEnc: make([]byte, size), Hints: h}}
+	}
+
+	cases := []struct {
+		name string
+		ups  []model.UpsertItem
+		ok   bool
+	}{
+		{"no hints", item(100<<10, model.ItemHints{}), true},
+		{"small fits", item(64<<10, model.ItemHints{SizeClass: model.SizeSmall, SchemaVersion: 2}), true},
+		{"small overflows", item(64<<10+1, model.ItemHints{SizeClass: model.SizeSmall}), false},
+		{"large", item(1<<20, model.ItemHints{SizeClass: model.SizeLarge}), true},
+		{"unknown class", item(10, model.ItemHints{SizeClass: 7}), false},
+		{"negative schema", item(10, model.ItemHints{SchemaVersion: -1}), false},
+	}
+	for _, tc := range cases {
+		_, err := s.Upsert(ctx, user, tc.ups)
+		if tc.ok != (err == nil) || (err != nil && !errors.Is(err, errs.ErrInvalidArgument)) {
+			t.Errorf("%s: %v", tc.name, err)
+		}
+	}
+}
+
 func TestItemService_Upsert_DelegatesToRepo(t *testing.T) {
 	t.Parallel()
 	ctx := context.Background()
@@ -160,6 +313,96 @@ func TestItemService_Delete_ValidationAndDelegate(t *testing.T) {
 	}
 }
 
+func TestItemService_DeleteBatch(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	u, a, b := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	repo := &fakeItemRepo{delBatchOut: []model.ItemResult{
+		{ID: a, Status: model.StatusOK, Ver: 3},
+		{ID: b, Status: model.StatusOK, Ver: 5},
+	}}
+	s := NewItemService(repo, 2)
+	n := &recordingNotifier{}
+	s.SetNotifier(n)
+
+	bad := [][]model.ItemRef{
+		{{ID: uuid.Nil, BaseVer: 1}},
+		{{ID: a, BaseVer: -1}},
+		{{ID: a, BaseVer: 1}, {ID: b, BaseVer: 1}, {ID: a, BaseVer: 2}}, // over maxBatch
+	}
+	for i, refs := range bad {
+		if _, err := s.DeleteBatch(ctx, u, refs); !errors.Is(err, errs.ErrInvalidArgument) {
+			t.Fatalf("case %d: want ErrInvalidArgument, got %v", i, err)
+		}
+	}
+	if res, err := s.DeleteBatch(ctx, u, nil); err != nil || len(res) != 0 || repo.delBatchIn != nil {
+		t.Fatalf("empty batch: %v %v %v", res, err, repo.delBatchIn)
+	}
+
+	refs := []model.ItemRef{{ID: a, BaseVer: 2}, {ID: b, BaseVer: 4}}
+	res, err := s.DeleteBatch(ctx, u, refs)
+	if err != nil || len(res) != 2 || repo.delInUser != u || !reflect.DeepEqual(repo.delBatchIn, refs) {
+		t.Fatalf("delegate: %+v %v %+v", res, err, repo.delBatchIn)
+	}
+	want := []model.Change{{ID: a, Ver: 3, Deleted: true}, {ID: b, Ver: 5, Deleted: true}}
+	if !reflect.DeepEqual(n.changes, want) {
+		t.Fatalf("notified %+v", n.changes)
+	}
+
+	// a rolled-back batch is not notified
+	n.changes = nil
+	repo.delBatchOut = []model.ItemResult{{ID: a, Status: model.StatusAborted}, {ID: b, Status: model.StatusVersionConflict, Ver: 7}}
+	if res, err := s.DeleteBatch(ctx, u, refs); err != nil || model.AllOK(res) || n.changes != nil {
+		t.Fatalf("failed batch: %+v %v notified %+v", res, err, n.changes)
+	}
+}
+
+func TestItemService_ApplyChangeSet(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	u, a, b := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV7()), uuid.Must(uuid.NewV4())
+	repo := &fakeItemRepo{csOut: model.ChangeSetResult{
+		Upserts: []model.ItemResult{{ID: a, Status: model.StatusOK, Ver: 4}},
+		Deletes: []model.ItemResult{{ID: b, Status: model.StatusOK, Ver: 2}},
+	}}
+	s := NewItemService(repo, 2)
+	n := &recordingNotifier{}
+	s.SetNotifier(n)
+
+	up := model.UpsertItem{ID: a, BaseVer: 3, BlobEnc: []byte{1}}
+	bad := []model.ChangeSet{
+		{Upserts: []model.UpsertItem{{ID: a, BaseVer: 3}}},                                                    // empty blob
+		{Deletes: []model.ItemRef{{ID: uuid.Nil, BaseVer: 1}}},                                                // empty id
+		{Upserts: []model.UpsertItem{up}, Deletes: []model.ItemRef{{ID: a, BaseVer: 3}}},                      // upserted and deleted
+		{Upserts: []model.UpsertItem{up}, Deletes: []model.ItemRef{{ID: b, BaseVer: 1}, {ID: u, BaseVer: 1}}}, // over maxBatch
+	}
+	for i, cs := range bad {
+		if _, err := s.ApplyChangeSet(ctx, u, cs); !errors.Is(err, errs.ErrInvalidArgument) {
+			t.Fatalf("case %d: want ErrInvalidArgument, got %v", i, err)
+		}
+	}
+	if res, err := s.ApplyChangeSet(ctx, u, model.ChangeSet{}); err != nil || !res.Applied() || repo.csIn != nil {
+		t.Fatalf("empty change set: %+v %v %+v", res, err, repo.csIn)
+	}
+
+	cs := model.ChangeSet{Upserts: []model.UpsertItem{up}, Deletes: []model.ItemRef{{ID: b, BaseVer: 1}}}
+	res, err := s.ApplyChangeSet(ctx, u, cs)
+	if err != nil || !res.Applied() || repo.upsertInUser != u || !reflect.DeepEqual(*repo.csIn, cs) {
+		t.Fatalf("delegate: %+v %v %+v", res, err, repo.csIn)
+	}
+	want := []model.Change{{ID: a, Ver: 4}, {ID: b, Ver: 2, Deleted: true}}
+	if !reflect.DeepEqual(n.changes, want) {
+		t.Fatalf("notified %+v", n.changes)
+	}
+
+	n.changes = nil
+	repo.csOut.Upserts = []model.ItemResult{{ID: a, Status: model.StatusVersionConflict, Ver: 5}}
+	repo.csOut.Deletes = []model.ItemResult{{ID: b, Status: model.StatusAborted}}
+	if res, err := s.ApplyChangeSet(ctx, u, cs); err != nil || res.Applied() || n.changes != nil {
+		t.Fatalf("failed change set: %+v %v notified %+v", res, err, n.changes)
+	}
+}
+
 func TestItemService_GetChanges_ValidationAndDelegate(t *testing.T) {
 	t.Parallel()
 	ctx := context.Background()
@@ -168,22 +411,74 @@ func TestItemService_GetChanges_ValidationAndDelegate(t *testing.T) {
 
 	u := uuid.Must(uuid.NewV4())
 
-	if _, err := s.GetChanges(ctx, uuid.Nil, 0); err == nil {
+	if _, err := s.GetChanges(ctx, uuid.Nil, model.AfterVer(0), 0); err == nil {
 		t.Fatalf("want validation error on empty userID")
 	}
 
-	if _, err := s.GetChanges(ctx, u, -1); err == nil {
+	if _, err := s.GetChanges(ctx, u, model.AfterVer(-1), 0); err == nil {
 		t.Fatalf("want validation error on negative since")
 	}
-	out, err := s.GetChanges(ctx, u, 4)
+	if _, err := s.GetChanges(ctx, u, model.AfterVer(0), -1); err == nil {
+		t.Fatalf("want validation error on negative limit")
+	}
+	out, err := s.GetChanges(ctx, u, model.AfterVer(4), 50)
 	if err != nil {
 		t.Fatalf("GetChanges: %v", err)
 	}
-	if len(out) != 2 || out[0].Ver != 5 || repo.chInUser != u || repo.chInSince != 4 {
+	if len(out) != 2 || out[0].Ver != 5 || repo.chInUser != u || repo.chInAfter != model.AfterVer(4) || repo.chInLimit != 50 {
 		t.Fatalf("delegate mismatch: out=%+v repo=%+v", out, repo)
 	}
 }
 
+func TestItemService_GetJournal(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := &fakeItemRepo{chOut: []model.Change{{Ver: 1, Seq: 8}}}
+	s := NewItemService(repo, 10)
+	u := uuid.Must(uuid.NewV4())
+
+	for _, c := range []struct {
+		user       uuid.UUID
+		seq, limit int64
+	}{{uuid.Nil, 0, 0}, {u, -1, 0}, {u, 0, -1}} {
+		if _, err := s.GetJournal(ctx, c.user, c.seq, int(c.limit)); !errors.Is(err, errs.ErrInvalidArgument) {
+			t.Fatalf("%+v: want ErrInvalidArgument, got %v", c, err)
+		}
+	}
+	out, err := s.GetJournal(ctx, u, 7, 50)
+	if err != nil || len(out) != 1 || repo.chInUser != u || repo.chInSeq != 7 || repo.chInLimit != 50 {
+		t.Fatalf("delegate mismatch: out=%+v err=%v repo=%+v", out, err, repo)
+	}
+}
+
+func TestItemService_Snapshot(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := &fakeItemRepo{chOut: []model.Change{{Ver: 1, Seq: 8}}, headOut: 12}
+	s := NewItemService(repo, 10)
+	u := uuid.Must(uuid.NewV4())
+
+	if _, err := s.BeginSync(ctx, uuid.Nil); !errors.Is(err, errs.ErrInvalidArgument) {
+		t.Fatalf("BeginSync without user: %v", err)
+	}
+	if seq, err := s.BeginSync(ctx, u); err != nil || seq != 12 || repo.chInUser != u {
+		t.Fatalf("BeginSync: %d, %v", seq, err)
+	}
+	for _, c := range []struct {
+		user       uuid.UUID
+		seq, limit int64
+	}{{uuid.Nil, 0, 0}, {u, -1, 0}, {u, 0, -1}} {
+		if _, err := s.GetSnapshot(ctx, c.user, c.seq, uuid.Nil, int(c.limit)); !errors.Is(err, errs.ErrInvalidArgument) {
+			t.Fatalf("%+v: want ErrInvalidArgument, got %v", c, err)
+		}
+	}
+	after := uuid.Must(uuid.NewV4())
+	out, err := s.GetSnapshot(ctx, u, 12, after, 50)
+	if err != nil || len(out) != 1 || repo.chInSeq != 12 || repo.chInID != after || repo.chInLimit != 50 {
+		t.Fatalf("delegate mismatch: out=%+v err=%v repo=%+v", out, err, repo)
+	}
+}
+
 func TestItemService_GetOne_ValidationAndDelegate(t *testing.T) {
 	t.Parallel()
 	ctx := context.Background()
@@ -227,10 +522,172 @@ func TestItemService_RepoErrorsPropagate(t *testing.T) {
 	if _, err := s.Delete(ctx, u, id, 0); err == nil {
 		t.Fatalf("want repo error propagate (delete)")
 	}
-	if _, err := s.GetChanges(ctx, u, 0); err == nil {
+	if _, err := s.GetChanges(ctx, u, model.AfterVer(0), 0); err == nil {
 		t.Fatalf("want repo error propagate (changes)")
 	}
 	if _, err := s.GetOne(ctx, u, id); err == nil {
 		t.Fatalf("want repo error propagate (get)")
 	}
 }
+
+func TestItemService_GetVersion_Validation(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	itID := uuid.Must(uuid.NewV4())
+	u := uuid.Must(uuid.NewV4())
+	repo := &fakeItemRepo{getOut: &model.Item{ID: itID, Ver: 2}}
+	s := NewItemService(repo, 10)
+
+	if _, err := s.GetVersion(ctx, u, itID, 0); err == nil {
+		t.Fatalf("want validation error on ver 0")
+	}
+	if _, err := s.GetVersion(ctx, uuid.Nil, itID, 1); err == nil {
+		t.Fatalf("want validation error on empty userID")
+	}
+	got, err := s.GetVersion(ctx, u, itID, 2)
+	if err != nil || got.Ver != 2 || repo.verInVer != 2 || repo.getInID != itID {
+		t.Fatalf("GetVersion: %+v %v (repo saw ver=%d)", got, err, repo.verInVer)
+	}
+}
+
+func TestItemService_Stats(t *testing.T) {
+	t.Parallel()
+	repo := &fakeItemRepo{statsOut: model.ItemStats{Items: 3}}
+	s := NewItemService(repo, 10)
+	if _, err := s.Stats(context.Background(), uuid.Nil); err == nil {
+		t.Fatalf("want validation error on empty userID")
+	}
+	u := uuid.Must(uuid.NewV4())
+	st, err := s.Stats(context.Background(), u)
+	if err != nil || st.Items != 3 || repo.getInUser != u {
+		t.Fatalf("Stats: %+v %v", st, err)
+	}
+}
+
+func TestItemService_Export(t *testing.T) {
+	t.Parallel()
+	live := model.Item{ID: uuid.Must(uuid.NewV4()), Ver: 2}
+	gone := model.Item{ID: uuid.Must(uuid.NewV4()), Ver: 3, Deleted: true}
+	repo := &fakeItemRepo{exportOut: []model.Item{live, gone}}
+	s := NewItemService(repo, 10)
+	collect := func(includeDeleted bool) []model.Item {
+		t.Helper()
+		var got []model.Item
+		err := s.Export(context.Background(), uuid.Must(uuid.NewV4()), includeDeleted, func(it model.Item) error {
+			got = append(got, it)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+	if got := collect(false); len(got) != 1 || got[0].ID != live.ID {
+		t.Fatalf("without tombstones: %+v", got)
+	}
+	if got := collect(true); len(got) != 2 {
+		t.Fatalf("with tombstones: %+v", got)
+	}
+
+	stop := errors.New("client gone")
+	if err := s.Export(context.Background(), uuid.Must(uuid.NewV4()), true, func(model.Item) error { return stop }); !errors.Is(err, stop) {
+		t.Fatalf("fn error: %v", err)
+	}
+	if err := s.Export(context.Background(), uuid.Nil, false, func(model.Item) error { return nil }); !errors.Is(err, errs.ErrInvalidArgument) {
+		t.Fatalf("empty userID: %v", err)
+	}
+}
+
+func TestItemService_VaultLeaves(t *testing.T) {
+	t.Parallel()
+	a := uuid.FromStringOrNil("aaaaaaaa-0000-0000-0000-000000000000")
+	b := uuid.FromStringOrNil("bbbbbbbb-0000-0000-0000-000000000000")
+	sum := merkle.BlobHash([]byte("x"))
+	repo := &fakeItemRepo{digestsOut: []model.ItemDigest{
+		{ID: b, Ver: 2, Deleted: true},
+		{ID: a, Ver: 1, BlobHash: sum[:]},
+	}}
+	s := NewItemService(repo, 10)
+	leaves, err := s.VaultLeaves(context.Background(), uuid.Must(uuid.NewV4()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []merkle.Leaf{merkle.NewLeaf(a.String(), 1, false, []byte("x")), merkle.NewLeaf(b.String(), 2, true, nil)}
+	if len(leaves) != 2 || leaves[0] != want[0] || leaves[1] != want[1] {
+		t.Fatalf("leaves %+v", leaves)
+	}
+
+	repo.digestsOut = []model.ItemDigest{{ID: a, Ver: 1, BlobHash: []byte{1}}}
+	if _, err := s.VaultLeaves(context.Background(), uuid.Must(uuid.NewV4())); err == nil {
+		t.Fatal("want error on a malformed blob hash")
+	}
+}
+
+type recordingNotifier struct {
+	user    uuid.UUID
+	changes []model.Change
+}
+
+func (r *recordingNotifier) ItemsChanged(_ context.Context, userID uuid.UUID, cs []model.Change) {
+	r.user, r.changes = userID, append(r.changes, cs...)
+}
+
+func TestItemService_NotifiesCommittedChanges(t *testing.T) {
+	user, id := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	repo := &fakeItemRepo{
+		upsertOut: []model.ItemVersion{{ID: id, NewVer: 1}},
+		delOut:    model.ItemVersion{ID: id, NewVer: 2},
+	}
+	s := NewItemService(repo, 10)
+	n := &recordingNotifier{}
+	s.SetNotifier(n)
+
+	if _, err := s.Upsert(context.Background(), user, []model.UpsertItem{{ID: id, BlobEnc: []byte("x")}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Delete(context.Background(), user, id, 1); err != nil {
+		t.Fatal(err)
+	}
+	repo.delErr = errors.New("conflict")
+	_, _ = s.Delete(context.Background(), user, id, 1)
+
+	want := []model.Change{{ID: id, Ver: 1}, {ID: id, Ver: 2, Deleted: true}}
+	if n.user != user || !reflect.DeepEqual(n.changes, want) {
+		t.Fatalf("notified %v %+v", n.user, n.changes)
+	}
+}
+
+func TestItemService_UpsertEach(t *testing.T) {
+	user, a, b := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV7()), uuid.Must(uuid.NewV7())
+	repo := &fakeItemRepo{eachOut: []model.ItemResult{
+		{ID: a, Status: model.StatusVersionConflict, Ver: 4},
+		{ID: b, Status: model.StatusOK, Ver: 1},
+	}}
+	s := NewItemService(repo, 10)
+	n := &recordingNotifier{}
+	s.SetNotifier(n)
+
+	// the batch is still validated as a whole
+	if _, err := s.UpsertEach(context.Background(), user, []model.UpsertItem{{ID: a, BaseVer: 2}, {ID: b, BlobEnc: []byte("x")}}); !errors.Is(err, errs.ErrInvalidArgument) || repo.upsertInUps != nil {
+		t.Fatalf("want ErrInvalidArgument before the repo, got %v", err)
+	}
+
+	ups := []model.UpsertItem{{ID: a, BaseVer: 2, BlobEnc: []byte("x")}, {ID: b, BlobEnc: []byte("y")}}
+	res, err := s.UpsertEach(context.Background(), user, ups)
+	if err != nil || len(res) != 2 || len(repo.upsertInUps) != 2 {
+		t.Fatalf("UpsertEach: %+v %v", res, err)
+	}
+	if want := []model.Change{{ID: b, Ver: 1}}; !reflect.DeepEqual(n.changes, want) {
+		t.Fatalf("notified %+v", n.changes)
+	}
+}
+
+func TestNotifiers_FanOut(t *testing.T) {
+	a, b := &recordingNotifier{}, &recordingNotifier{}
+	user := uuid.Must(uuid.NewV4())
+	cs := []model.Change{{ID: uuid.Must(uuid.NewV4()), Ver: 3}}
+	Notifiers{a, b}.ItemsChanged(context.Background(), user, cs)
+	if a.user != user || b.user != user || !reflect.DeepEqual(a.changes, cs) || !reflect.DeepEqual(b.changes, cs) {
+		t.Fatalf("fan-out: %+v %+v", a, b)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package systemd
+
+import "net"
+
+// Listeners always returns nil: socket activation exists only on unix.
+func Listeners() ([]net.Listener, error) { return nil, nil }
@@ -0,0 +1,41 @@
+//go:build unix
+
+package systemd
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"syscall"
+)
+
+// Listeners returns the sockets passed by systemd socket activation, in the
+// order of the .socket unit's Listen* lines; nil when not socket-activated.
+// The LISTEN_* variables are cleared so child processes do not inherit them.
+func Listeners() ([]net.Listener, error) {
+	n, err := listenFDs()
+	defer func() {
+		_ = os.Unsetenv("LISTEN_PID")
+		_ = os.Unsetenv("LISTEN_FDS")
+		_ = os.Unsetenv("LISTEN_FDNAMES")
+	}()
+	if err != nil || n == 0 {
+		return nil, err
+	}
+	out := make([]net.Listener, 0, n)
+	for fd := listenFDsStart; fd < listenFDsStart+n; fd++ {
+		syscall.CloseOnExec(fd)
+		f := os.NewFile(uintptr(fd), "LISTEN_FD_"+strconv.Itoa(fd))
+		l, err := net.FileListener(f)
+		_ = f.Close() // FileListener dups the descriptor
+		if err != nil {
+			for _, o := range out {
+				_ = o.Close()
+			}
+			return nil, fmt.Errorf("systemd: fd %d: %w", fd, err)
+		}
+		out = append(out, l)
+	}
+	return out, nil
+}
@@ -0,0 +1,93 @@
+// Package systemd implements the small parts of the systemd service protocol
+// gk-server needs: socket activation (LISTEN_FDS), readiness and status
+// notifications (sd_notify) and watchdog keep-alives. Everything is a no-op
+// when the process is not started by systemd.
+package systemd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+// listenFDsStart is the first file descriptor passed by systemd (SD_LISTEN_FDS_START).
+const listenFDsStart = 3
+
+// listenFDs returns how many descriptors systemd passed to this process.
+// Descriptors addressed to another PID (e.g. inherited by a child) are ignored.
+func listenFDs() (int, error) {
+	pid := os.Getenv("LISTEN_PID")
+	if pid == "" {
+		return 0, nil
+	}
+	if p, err := strconv.Atoi(pid); err != nil || p != os.Getpid() {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("systemd: bad LISTEN_FDS %q", os.Getenv("LISTEN_FDS"))
+	}
+	return n, nil
+}
+
+// Notify sends a state string such as "READY=1" to the service manager.
+// It reports false without error when NOTIFY_SOCKET is unset.
+func Notify(state string) (bool, error) {
+	path := os.Getenv("NOTIFY_SOCKET")
+	if path == "" {
+		return false, nil
+	}
+	if path[0] == '@' {
+		path = "\x00" + path[1:] // abstract namespace
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		return false, fmt.Errorf("systemd notify: %w", err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return false, fmt.Errorf("systemd notify: %w", err)
+	}
+	return true, nil
+}
+
+// WatchdogInterval returns the WatchdogSec= configured for this service, or
+// zero when the watchdog is off or meant for another process.
+func WatchdogInterval() time.Duration {
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" {
+		if p, err := strconv.Atoi(pid); err != nil || p != os.Getpid() {
+			return 0
+		}
+	}
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+	return time.Duration(usec) * time.Microsecond
+}
+
+// Watchdog sends WATCHDOG=1 at half the configured interval until ctx ends.
+// check, if non-nil, must pass for a ping to be sent, so a wedged dependency
+// lets systemd restart the service. It returns at once when the watchdog is off.
+func Watchdog(ctx context.Context, check func(context.Context) error) {
+	interval := WatchdogInterval()
+	if interval == 0 {
+		return
+	}
+	t := time.NewTicker(interval / 2)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if check != nil && check(ctx) != nil {
+				continue
+			}
+			_, _ = Notify("WATCHDOG=1")
+		}
+	}
+}
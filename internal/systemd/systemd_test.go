@@ -0,0 +1,142 @@
+package systemd
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// notifySocket listens where Notify will send and returns received datagrams.
+func notifySocket(t *testing.T) <-chan string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	t.Setenv("NOTIFY_SOCKET", path)
+
+	ch := make(chan string, 16)
+	go func() {
+		buf := make([]byte, 256)
+		for {
+			n, err := conn.Read(buf)
+			if err != nil {
+				return
+			}
+			ch <- string(buf[:n])
+		}
+	}()
+	return ch
+}
+
+func TestNotify_Unset(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+	if sent, err := Notify("READY=1"); sent || err != nil {
+		t.Fatalf("want no-op, got %v %v", sent, err)
+	}
+}
+
+func TestNotify_Sends(t *testing.T) {
+	ch := notifySocket(t)
+	if sent, err := Notify("READY=1"); !sent || err != nil {
+		t.Fatalf("notify: %v %v", sent, err)
+	}
+	select {
+	case got := <-ch:
+		if got != "READY=1" {
+			t.Fatalf("got %q", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no datagram")
+	}
+}
+
+func TestListenFDs(t *testing.T) {
+	t.Setenv("LISTEN_PID", "")
+	if n, err := listenFDs(); n != 0 || err != nil {
+		t.Fatalf("unset: %d %v", n, err)
+	}
+
+	t.Setenv("LISTEN_PID", "1")
+	t.Setenv("LISTEN_FDS", "2")
+	if n, _ := listenFDs(); n != 0 {
+		t.Fatalf("other pid must be ignored, got %d", n)
+	}
+
+	t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+	if n, err := listenFDs(); n != 2 || err != nil {
+		t.Fatalf("own pid: %d %v", n, err)
+	}
+	t.Setenv("LISTEN_FDS", "x")
+	if _, err := listenFDs(); err == nil {
+		t.Fatal("want error for bad LISTEN_FDS")
+	}
+}
+
+func TestListeners_NotActivated(t *testing.T) {
+	t.Setenv("LISTEN_PID", "")
+	ls, err := Listeners()
+	if ls != nil || err != nil {
+		t.Fatalf("want nil, got %v %v", ls, err)
+	}
+}
+
+func TestWatchdogInterval(t *testing.T) {
+	t.Setenv("WATCHDOG_PID", "")
+	t.Setenv("WATCHDOG_USEC", "")
+	if d := WatchdogInterval(); d != 0 {
+		t.Fatalf("unset: %v", d)
+	}
+	t.Setenv("WATCHDOG_USEC", "30000000")
+	if d := WatchdogInterval(); d != 30*time.Second {
+		t.Fatalf("got %v", d)
+	}
+	t.Setenv("WATCHDOG_PID", "1")
+	if d := WatchdogInterval(); d != 0 {
+		t.Fatalf("other pid: %v", d)
+	}
+}
+
+func TestWatchdog_Pings(t *testing.T) {
+	ch := notifySocket(t)
+	t.Setenv("WATCHDOG_PID", "")
+	t.Setenv("WATCHDOG_USEC", "20000") // ping every 10ms
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		Watchdog(ctx, nil)
+	}()
+	select {
+	case got := <-ch:
+		if got != "WATCHDOG=1" {
+			t.Fatalf("got %q", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no watchdog ping")
+	}
+	cancel()
+	<-done
+}
+
+func TestWatchdog_FailingCheckSuppressesPing(t *testing.T) {
+	ch := notifySocket(t)
+	t.Setenv("WATCHDOG_PID", "")
+	t.Setenv("WATCHDOG_USEC", "20000")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	Watchdog(ctx, func(context.Context) error { return context.DeadlineExceeded })
+	select {
+	case got := <-ch:
+		t.Fatalf("unexpected %q", got)
+	default:
+	}
+}
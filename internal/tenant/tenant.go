@@ -0,0 +1,176 @@
+// Package tenant carries the tenant dimension: which customer organisation a
+// request belongs to, how that is resolved and what the tenant may store.
+//
+// A deployment without a tenants file serves a single tenant, Default, and
+// behaves exactly as before tenants existed.
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Default is the tenant of pre-existing data, tokens without a tenant claim
+// and connections whose TLS server name maps to no tenant.
+const Default = "default"
+
+var validID = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+// Quota limits a tenant's storage and its users' throughput; zero fields
+// are unlimited, or for the throughput fields the server-wide default.
+type Quota struct {
+	MaxUsers int64 `yaml:"max_users"`
+	MaxItems int64 `yaml:"max_items"` // live (non-deleted) items
+	MaxBytes int64 `yaml:"max_bytes"` // encrypted size of live items
+
+	UserRequestsPerMin int64 `yaml:"user_requests_per_min"`
+	UserBytesPerSec    int64 `yaml:"user_bytes_per_sec"` // request plus response size
+}
+
+// Tenant is one entry of the tenants file.
+type Tenant struct {
+	ID    string   `yaml:"id"`
+	Name  string   `yaml:"name"`
+	Hosts []string `yaml:"hosts"` // TLS server names (SNI) that select the tenant
+	Quota `yaml:",inline"`
+}
+
+// Registry knows the configured tenants. A nil *Registry is valid and
+// knows only Default, without quotas.
+type Registry struct {
+	domain string
+	byID   map[string]Tenant
+	byHost map[string]string
+}
+
+// New validates tenants. Besides the listed hosts, "<id>.<domain>" selects a
+// tenant when domain is set.
+func New(domain string, tenants []Tenant) (*Registry, error) {
+	r := &Registry{
+		domain: strings.ToLower(strings.Trim(domain, ".")),
+		byID:   make(map[string]Tenant, len(tenants)),
+		byHost: make(map[string]string),
+	}
+	for _, t := range tenants {
+		if !validID.MatchString(t.ID) {
+			return nil, fmt.Errorf("tenant id %q: want lowercase letters, digits and dashes", t.ID)
+		}
+		if _, dup := r.byID[t.ID]; dup {
+			return nil, fmt.Errorf("tenant %q listed twice", t.ID)
+		}
+		if t.MaxUsers < 0 || t.MaxItems < 0 || t.MaxBytes < 0 {
+			return nil, fmt.Errorf("tenant %q: quotas must not be negative", t.ID)
+		}
+		r.byID[t.ID] = t
+		for _, h := range t.Hosts {
+			h = strings.ToLower(h)
+			if other, dup := r.byHost[h]; dup {
+				return nil, fmt.Errorf("host %q belongs to both %q and %q", h, other, t.ID)
+			}
+			r.byHost[h] = t.ID
+		}
+	}
+	return r, nil
+}
+
+// Load reads a YAML tenants file:
+//
+//	tenants:
+//	  - id: acme
+//	    name: Acme Corp
+//	    hosts: [vault.acme.example]
+//	    max_users: 50
+//	    max_items: 100000
+//	    max_bytes: 1073741824
+func Load(path, domain string) (*Registry, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f struct {
+		Tenants []Tenant `yaml:"tenants"`
+	}
+	if err := yaml.Unmarshal(raw, &f); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(f.Tenants) == 0 {
+		return nil, errors.New(path + ": no tenants")
+	}
+	return New(domain, f.Tenants)
+}
+
+// Known reports whether id may be served.
+func (r *Registry) Known(id string) bool {
+	if id == Default {
+		return true
+	}
+	if r == nil {
+		return false
+	}
+	_, ok := r.byID[id]
+	return ok
+}
+
+// FromHost maps a TLS server name to a configured tenant.
+func (r *Registry) FromHost(host string) (string, bool) {
+	if r == nil || host == "" {
+		return "", false
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if id, ok := r.byHost[host]; ok {
+		return id, true
+	}
+	if r.domain != "" {
+		if sub, ok := strings.CutSuffix(host, "."+r.domain); ok && !strings.Contains(sub, ".") && r.Known(sub) {
+			return sub, true
+		}
+	}
+	return "", false
+}
+
+// Quota returns id's limits.
+func (r *Registry) Quota(id string) Quota {
+	if r == nil {
+		return Quota{}
+	}
+	return r.byID[id].Quota
+}
+
+type ctxKey struct{}
+
+// WithID returns ctx scoped to tenant id.
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+// FromContext returns the request's tenant, Default if none was set.
+func FromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(ctxKey{}).(string); ok && id != "" {
+		return id
+	}
+	return Default
+}
+
+// Scope qualifies a per-tenant name (such as a username used as a rate
+// limiter key) so equal names in different tenants stay apart. Names in the
+// Default tenant are returned unchanged.
+func Scope(ctx context.Context, name string) string {
+	if id := FromContext(ctx); id != Default {
+		return id + "\x1f" + name // unit separator: cannot appear in a tenant id
+	}
+	return name
+}
+
+// Unscope splits a name made by Scope back into its tenant and name.
+func Unscope(scoped string) (id, name string) {
+	if id, name, ok := strings.Cut(scoped, "\x1f"); ok {
+		return id, name
+	}
+	return Default, scoped
+}
@@ -0,0 +1,88 @@
+package tenant
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "tenants.yaml")
+	const doc = `
+tenants:
+  - id: acme
+    name: Acme Corp
+    hosts: [Vault.Acme.test]
+    max_users: 5
+    max_bytes: 1024
+  - id: globex
+`
+	if err := os.WriteFile(path, []byte(doc), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	r, err := Load(path, "gk.example.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q := r.Quota("acme"); q.MaxUsers != 5 || q.MaxBytes != 1024 || q.MaxItems != 0 {
+		t.Fatalf("quota %+v", q)
+	}
+	for host, want := range map[string]string{
+		"vault.acme.test.":    "acme",
+		"globex.gk.example":   "globex",
+		"initech.gk.example":  "",
+		"a.globex.gk.example": "",
+		"gk.example":          "",
+		"":                    "",
+	} {
+		if got, _ := r.FromHost(host); got != want {
+			t.Errorf("FromHost(%q) = %q, want %q", host, got, want)
+		}
+	}
+	if !r.Known(Default) || !r.Known("globex") || r.Known("initech") {
+		t.Fatal("Known")
+	}
+}
+
+func TestNew_Validation(t *testing.T) {
+	t.Parallel()
+	for want, tenants := range map[string][]Tenant{
+		"lowercase":  {{ID: "Acme"}},
+		"twice":      {{ID: "a"}, {ID: "a"}},
+		"negative":   {{ID: "a", Quota: Quota{MaxItems: -1}}},
+		"belongs to": {{ID: "a", Hosts: []string{"h"}}, {ID: "b", Hosts: []string{"H"}}},
+	} {
+		if _, err := New("", tenants); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: %v", want, err)
+		}
+	}
+}
+
+func TestNilRegistryAndContext(t *testing.T) {
+	t.Parallel()
+	var r *Registry
+	if !r.Known(Default) || r.Known("acme") || r.Quota(Default) != (Quota{}) {
+		t.Fatal("nil registry")
+	}
+	if _, ok := r.FromHost("acme.example"); ok {
+		t.Fatal("nil registry resolved a host")
+	}
+
+	ctx := context.Background()
+	if FromContext(ctx) != Default || Scope(ctx, "bob") != "bob" {
+		t.Fatal("default scope")
+	}
+	ctx = WithID(ctx, "acme")
+	if FromContext(ctx) != "acme" || Scope(ctx, "bob") == "bob" {
+		t.Fatal("acme scope")
+	}
+	if id, name := Unscope(Scope(ctx, "bob")); id != "acme" || name != "bob" {
+		t.Fatalf("unscope: %q %q", id, name)
+	}
+	if id, name := Unscope("bob"); id != Default || name != "bob" {
+		t.Fatalf("unscope default: %q %q", id, name)
+	}
+}
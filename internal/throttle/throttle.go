@@ -0,0 +1,153 @@
+// Package throttle limits how much of the server each user may take:
+// requests per minute and bytes per second, each as a token bucket kept in
+// memory.
+//
+// Bytes are charged after a call, when its size is known, and the bucket
+// may go into debt: a response bigger than the bucket still goes out, and
+// the user's next calls wait until the debt is paid off. Over any stretch
+// of time a user therefore gets the configured rate on average.
+package throttle
+
+import (
+	"context"
+	"expvar"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+// metrics are published under the "throttle" expvar (see /debug/vars).
+var metrics = expvar.NewMap("throttle")
+
+// byteBurst is how many seconds of byte allowance an idle user accumulates.
+const byteBurst = 10
+
+// idleAfter is how long an untouched bucket is kept; by then it is full and
+// indistinguishable from a new one.
+const idleAfter = 10 * time.Minute
+
+// Limits is one user's allowance; zero fields are unlimited.
+type Limits struct {
+	RequestsPerMin int64
+	BytesPerSec    int64
+}
+
+type key struct {
+	tenant string
+	user   uuid.UUID
+}
+
+type bucket struct {
+	reqs    float64
+	bytes   float64 // negative while in debt
+	updated time.Time
+}
+
+// refill adds the allowance earned since the last update.
+func (b *bucket) refill(now time.Time, l Limits) {
+	dt := now.Sub(b.updated).Seconds()
+	if dt > 0 {
+		b.reqs = math.Min(b.reqs+dt*float64(l.RequestsPerMin)/60, float64(l.RequestsPerMin))
+		b.bytes = math.Min(b.bytes+dt*float64(l.BytesPerSec), float64(l.BytesPerSec*byteBurst))
+		b.updated = now
+	}
+}
+
+// Throttle tracks every active user's buckets.
+type Throttle struct {
+	limits func(tenant string) Limits
+	now    func() time.Time
+
+	mu    sync.Mutex
+	users map[key]*bucket
+}
+
+// New returns a throttle applying limits(tenant) to each user of a tenant.
+func New(limits func(tenant string) Limits) *Throttle {
+	return &Throttle{limits: limits, now: time.Now, users: make(map[key]*bucket)}
+}
+
+// bucket returns the user's refilled bucket; the caller holds t.mu.
+func (t *Throttle) bucket(k key, l Limits) *bucket {
+	now := t.now()
+	b, ok := t.users[k]
+	if !ok {
+		b = &bucket{reqs: float64(l.RequestsPerMin), bytes: float64(l.BytesPerSec * byteBurst), updated: now}
+		t.users[k] = b
+	}
+	b.refill(now, l)
+	return b
+}
+
+// Allow takes one request from the user's allowance. When none is left, or
+// the byte bucket is in debt, it takes nothing and returns how long to wait.
+func (t *Throttle) Allow(tenant string, user uuid.UUID) time.Duration {
+	l := t.limits(tenant)
+	if l.RequestsPerMin <= 0 && l.BytesPerSec <= 0 {
+		return 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	b := t.bucket(key{tenant, user}, l)
+	var wait float64
+	if l.RequestsPerMin > 0 && b.reqs < 1 {
+		wait = (1 - b.reqs) * 60 / float64(l.RequestsPerMin)
+	}
+	if l.BytesPerSec > 0 && b.bytes < 0 {
+		wait = math.Max(wait, -b.bytes/float64(l.BytesPerSec))
+	}
+	if wait > 0 {
+		metrics.Add("rejected", 1)
+		return time.Duration(math.Ceil(wait*1000)) * time.Millisecond
+	}
+	if l.RequestsPerMin > 0 {
+		b.reqs--
+	}
+	return 0
+}
+
+// Charge counts n bytes moved for the user.
+func (t *Throttle) Charge(tenant string, user uuid.UUID, n int) {
+	l := t.limits(tenant)
+	if l.BytesPerSec <= 0 || n <= 0 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.bucket(key{tenant, user}, l).bytes -= float64(n)
+}
+
+// prune forgets users idle for idleAfter.
+func (t *Throttle) prune() {
+	now := t.now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for k, b := range t.users {
+		if now.Sub(b.updated) > idleAfter {
+			delete(t.users, k)
+		}
+	}
+	metrics.Set("users", intVar(len(t.users)))
+}
+
+// Run prunes idle users every minute until ctx is done.
+func (t *Throttle) Run(ctx context.Context) {
+	tick := time.NewTicker(time.Minute)
+	defer tick.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+			t.prune()
+		}
+	}
+}
+
+func intVar(n int) *expvar.Int {
+	v := new(expvar.Int)
+	v.Set(int64(n))
+	return v
+}
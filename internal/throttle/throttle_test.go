@@ -0,0 +1,78 @@
+package throttle
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+func testThrottle(l Limits) (*Throttle, *time.Time) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	t := New(func(string) Limits { return l })
+	t.now = func() time.Time { return now }
+	return t, &now
+}
+
+func TestThrottle_Requests(t *testing.T) {
+	t.Parallel()
+	th, now := testThrottle(Limits{RequestsPerMin: 3})
+	u := uuid.Must(uuid.NewV4())
+	for i := range 3 {
+		if w := th.Allow("acme", u); w != 0 {
+			t.Fatalf("request %d refused for %s", i, w)
+		}
+	}
+	if w := th.Allow("acme", u); w != 20*time.Second {
+		t.Fatalf("4th request: wait %s, want 20s", w)
+	}
+	// other users and tenants have their own buckets
+	if th.Allow("acme", uuid.Must(uuid.NewV4())) != 0 || th.Allow("globex", u) != 0 {
+		t.Fatal("bucket shared")
+	}
+	*now = now.Add(20 * time.Second)
+	if w := th.Allow("acme", u); w != 0 {
+		t.Fatalf("after refill: wait %s", w)
+	}
+}
+
+func TestThrottle_BytesDebt(t *testing.T) {
+	t.Parallel()
+	th, now := testThrottle(Limits{BytesPerSec: 100})
+	u := uuid.Must(uuid.NewV4())
+	if th.Allow("", u) != 0 {
+		t.Fatal("first call refused")
+	}
+	// a 3000-byte response exceeds the 1000-byte burst; it goes out anyway
+	th.Charge("", u, 3000)
+	if w := th.Allow("", u); w != 20*time.Second {
+		t.Fatalf("in debt: wait %s, want 20s", w)
+	}
+	*now = now.Add(20 * time.Second)
+	if w := th.Allow("", u); w != 0 {
+		t.Fatalf("debt paid: wait %s", w)
+	}
+}
+
+func TestThrottle_UnlimitedAndPrune(t *testing.T) {
+	t.Parallel()
+	th, now := testThrottle(Limits{})
+	u := uuid.Must(uuid.NewV4())
+	for range 1000 {
+		th.Charge("", u, 1<<20)
+		if th.Allow("", u) != 0 {
+			t.Fatal("unlimited user refused")
+		}
+	}
+	if len(th.users) != 0 {
+		t.Fatal("unlimited users tracked")
+	}
+
+	th.limits = func(string) Limits { return Limits{RequestsPerMin: 1} }
+	th.Allow("", u)
+	*now = now.Add(idleAfter + time.Second)
+	th.prune()
+	if len(th.users) != 0 {
+		t.Fatal("idle user kept")
+	}
+}
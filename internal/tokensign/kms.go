@@ -0,0 +1,216 @@
+package tokensign
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/and161185/goph-keeper/internal/awsv4"
+)
+
+// NewAWSKMS returns a signer backed by an asymmetric AWS KMS key (SIGN_VERIFY
+// usage, ECC_NIST_P256 or RSA). The public key is fetched once at startup;
+// every token costs one kms:Sign call. api nil means the AWS_* environment.
+func NewAWSKMS(ctx context.Context, api *awsv4.Client, keyID string) (Signer, error) {
+	if keyID == "" {
+		return nil, errors.New("awskms: empty key id")
+	}
+	if api == nil {
+		api = awsv4.FromEnvClient()
+	}
+	var pk struct {
+		PublicKey []byte `json:"PublicKey"`
+		KeyUsage  string `json:"KeyUsage"`
+	}
+	if err := api.Do(ctx, "kms", "TrentService.GetPublicKey", map[string]string{"KeyId": keyID}, &pk); err != nil {
+		return nil, fmt.Errorf("awskms: get public key: %w", err)
+	}
+	if pk.KeyUsage != "" && pk.KeyUsage != "SIGN_VERIFY" {
+		return nil, fmt.Errorf("awskms: key usage %s, want SIGN_VERIFY", pk.KeyUsage)
+	}
+	pub, err := x509.ParsePKIXPublicKey(pk.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("awskms: public key: %w", err)
+	}
+	m, err := methodFor(pub)
+	if err != nil {
+		return nil, fmt.Errorf("awskms: %w", err)
+	}
+	alg := map[jwt.SigningMethod]string{
+		jwt.SigningMethodES256: "ECDSA_SHA_256",
+		jwt.SigningMethodRS256: "RSASSA_PKCS1_V1_5_SHA_256",
+	}[m]
+	if alg == "" {
+		return nil, fmt.Errorf("awskms: %s keys are not supported", m.Alg())
+	}
+	return &digestSigner{method: m, pub: pub, sign: func(ctx context.Context, digest []byte) ([]byte, error) {
+		in := map[string]any{"KeyId": keyID, "Message": digest, "MessageType": "DIGEST", "SigningAlgorithm": alg}
+		var out struct {
+			Signature []byte `json:"Signature"`
+		}
+		if err := api.Do(ctx, "kms", "TrentService.Sign", in, &out); err != nil {
+			return nil, fmt.Errorf("awskms: sign: %w", err)
+		}
+		return out.Signature, nil
+	}}, nil
+}
+
+// GCP talks to the Cloud KMS REST API.
+type GCP struct {
+	Endpoint string // default https://cloudkms.googleapis.com
+	HTTP     *http.Client
+	// Token returns an OAuth2 access token; default: GOOGLE_OAUTH_ACCESS_TOKEN,
+	// then the GCE/GKE metadata server.
+	Token func(ctx context.Context) (string, error)
+}
+
+// NewGCPKMS returns a signer backed by a Cloud KMS asymmetric key version
+// (projects/P/locations/L/keyRings/R/cryptoKeys/K/cryptoKeyVersions/N).
+func NewGCPKMS(ctx context.Context, g *GCP, version string) (Signer, error) {
+	if !strings.Contains(version, "/cryptoKeyVersions/") {
+		return nil, fmt.Errorf("gcpkms: %q is not a crypto key version name", version)
+	}
+	if g == nil {
+		g = &GCP{}
+	}
+	if g.Endpoint == "" {
+		g.Endpoint = "https://cloudkms.googleapis.com"
+	}
+	if g.HTTP == nil {
+		g.HTTP = &http.Client{Timeout: 10 * time.Second}
+	}
+	if g.Token == nil {
+		g.Token = GCPDefaultToken(g.HTTP)
+	}
+
+	var pk struct {
+		PEM string `json:"pem"`
+	}
+	if err := g.call(ctx, http.MethodGet, version+"/publicKey", nil, &pk); err != nil {
+		return nil, fmt.Errorf("gcpkms: get public key: %w", err)
+	}
+	block, _ := pem.Decode([]byte(pk.PEM))
+	if block == nil {
+		return nil, errors.New("gcpkms: public key is not PEM")
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("gcpkms: public key: %w", err)
+	}
+	m, err := methodFor(pub)
+	if err != nil {
+		return nil, fmt.Errorf("gcpkms: %w", err)
+	}
+	return &digestSigner{method: m, pub: pub, sign: func(ctx context.Context, msg []byte) ([]byte, error) {
+		in := map[string]any{"digest": map[string][]byte{"sha256": msg}}
+		if m == jwt.SigningMethodEdDSA {
+			in = map[string]any{"data": msg}
+		}
+		var out struct {
+			Signature []byte `json:"signature"`
+		}
+		if err := g.call(ctx, http.MethodPost, version+":asymmetricSign", in, &out); err != nil {
+			return nil, fmt.Errorf("gcpkms: sign: %w", err)
+		}
+		return out.Signature, nil
+	}}, nil
+}
+
+// call performs an authenticated Cloud KMS v1 request.
+func (g *GCP) call(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(g.Endpoint, "/")+"/v1/"+path, body)
+	if err != nil {
+		return err
+	}
+	tok, err := g.Token(ctx)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+tok)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := g.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		_ = json.Unmarshal(raw, &e)
+		return fmt.Errorf("%s: %s", resp.Status, e.Error.Message)
+	}
+	return json.Unmarshal(raw, out)
+}
+
+// GCPDefaultToken returns a cached token source reading GOOGLE_OAUTH_ACCESS_TOKEN
+// or, failing that, the metadata server's default service account.
+func GCPDefaultToken(cli *http.Client) func(ctx context.Context) (string, error) {
+	var (
+		mu     sync.Mutex
+		token  string
+		expiry time.Time
+	)
+	return func(ctx context.Context) (string, error) {
+		if t := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"); t != "" {
+			return t, nil
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if token != "" && time.Now().Before(expiry) {
+			return token, nil
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+			"http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token", nil)
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("Metadata-Flavor", "Google")
+		resp, err := cli.Do(req)
+		if err != nil {
+			return "", fmt.Errorf("gcp metadata token: %w", err)
+		}
+		defer resp.Body.Close()
+		var out struct {
+			AccessToken string `json:"access_token"`
+			ExpiresIn   int    `json:"expires_in"`
+		}
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("gcp metadata token: %s", resp.Status)
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+			return "", fmt.Errorf("gcp metadata token: %w", err)
+		}
+		token = out.AccessToken
+		expiry = time.Now().Add(time.Duration(out.ExpiresIn)*time.Second - time.Minute)
+		return token, nil
+	}
+}
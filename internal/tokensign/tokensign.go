@@ -0,0 +1,185 @@
+// Package tokensign signs and verifies access tokens. The signing key may be
+// an in-memory HMAC secret or live in a KMS/HSM, in which case only the
+// public key is ever held by the server.
+package tokensign
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Verifier is what token validation needs: the expected algorithm and key.
+type Verifier interface {
+	Method() jwt.SigningMethod
+	VerifyKey() any
+}
+
+// Signer produces JWT signatures for the algorithm reported by Method.
+type Signer interface {
+	Verifier
+	// Sign returns the JWS signature of the "header.payload" signing string.
+	Sign(ctx context.Context, signingString string) ([]byte, error)
+}
+
+// Claims are the access token claims: the user is the subject, Tenant the
+// organisation the token is valid for (absent in pre-tenant tokens),
+// Session the refresh-token session it belongs to, if any, and Scope the
+// space-separated scopes it is limited to (absent: unlimited).
+type Claims struct {
+	jwt.RegisteredClaims
+	Tenant  string `json:"tid,omitempty"`
+	Session string `json:"sid,omitempty"`
+	Scope   string `json:"scope,omitempty"`
+}
+
+// HasScope reports whether the token may be used for scope.
+func (c *Claims) HasScope(scope string) bool {
+	return c.Scope == "" || slices.Contains(strings.Fields(c.Scope), scope)
+}
+
+// Issue builds and signs a token carrying claims.
+func Issue(ctx context.Context, s Signer, claims jwt.Claims) (string, error) {
+	tok := jwt.NewWithClaims(s.Method(), claims)
+	ss, err := tok.SigningString()
+	if err != nil {
+		return "", err
+	}
+	sig, err := s.Sign(ctx, ss)
+	if err != nil {
+		return "", fmt.Errorf("sign token: %w", err)
+	}
+	return ss + "." + tok.EncodeSegment(sig), nil
+}
+
+// Keyfunc returns a jwt.Keyfunc accepting only v's algorithm.
+func Keyfunc(v Verifier) jwt.Keyfunc {
+	want := v.Method().Alg()
+	return func(t *jwt.Token) (any, error) {
+		if t.Method.Alg() != want {
+			return nil, errors.New("unexpected signing method")
+		}
+		return v.VerifyKey(), nil
+	}
+}
+
+// hmacSigner signs HS256 with an in-memory secret.
+type hmacSigner []byte
+
+// HMAC returns an HS256 signer for key.
+func HMAC(key []byte) Signer { return hmacSigner(key) }
+
+func (h hmacSigner) Method() jwt.SigningMethod { return jwt.SigningMethodHS256 }
+func (h hmacSigner) VerifyKey() any            { return []byte(h) }
+func (h hmacSigner) Sign(_ context.Context, ss string) ([]byte, error) {
+	return jwt.SigningMethodHS256.Sign(ss, []byte(h))
+}
+
+// digestSigner signs a SHA-256 digest remotely (KMS) or through crypto.Signer (PKCS#11).
+type digestSigner struct {
+	method jwt.SigningMethod
+	pub    crypto.PublicKey
+	// sign returns a signature over digest; ECDSA signatures come back ASN.1 DER.
+	sign func(ctx context.Context, digest []byte) ([]byte, error)
+}
+
+func (d *digestSigner) Method() jwt.SigningMethod { return d.method }
+func (d *digestSigner) VerifyKey() any            { return d.pub }
+
+func (d *digestSigner) Sign(ctx context.Context, ss string) ([]byte, error) {
+	var msg []byte
+	if d.method == jwt.SigningMethodEdDSA {
+		msg = []byte(ss) // Ed25519 signs the message itself
+	} else {
+		sum := sha256.Sum256([]byte(ss))
+		msg = sum[:]
+	}
+	sig, err := d.sign(ctx, msg)
+	if err != nil {
+		return nil, err
+	}
+	if d.method == jwt.SigningMethodES256 {
+		return derToJOSE(sig, 32)
+	}
+	return sig, nil
+}
+
+// methodFor picks the JWT algorithm for a public key.
+func methodFor(pub crypto.PublicKey) (jwt.SigningMethod, error) {
+	switch k := pub.(type) {
+	case *ecdsa.PublicKey:
+		if k.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("unsupported curve %s: only P-256 (ES256)", k.Curve.Params().Name)
+		}
+		return jwt.SigningMethodES256, nil
+	case *rsa.PublicKey:
+		return jwt.SigningMethodRS256, nil
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, nil
+	}
+	return nil, fmt.Errorf("unsupported public key type %T", pub)
+}
+
+// FromCryptoSigner adapts any crypto.Signer, e.g. a PKCS#11 key from an HSM
+// library, choosing ES256, RS256 or EdDSA from its public key.
+func FromCryptoSigner(cs crypto.Signer) (Signer, error) {
+	m, err := methodFor(cs.Public())
+	if err != nil {
+		return nil, err
+	}
+	opts := crypto.SignerOpts(crypto.SHA256)
+	if m == jwt.SigningMethodEdDSA {
+		opts = crypto.Hash(0)
+	}
+	return &digestSigner{method: m, pub: cs.Public(), sign: func(_ context.Context, digest []byte) ([]byte, error) {
+		return cs.Sign(rand.Reader, digest, opts)
+	}}, nil
+}
+
+// derToJOSE converts an ASN.1 ECDSA signature to the fixed-size r||s form JWS uses.
+func derToJOSE(der []byte, size int) ([]byte, error) {
+	var sig struct{ R, S *big.Int }
+	if _, err := asn1.Unmarshal(der, &sig); err != nil {
+		return nil, fmt.Errorf("ecdsa signature: %w", err)
+	}
+	out := make([]byte, 2*size)
+	sig.R.FillBytes(out[:size])
+	sig.S.FillBytes(out[size:])
+	return out, nil
+}
+
+// Open builds a signer from a -jwt-signer spec:
+//
+//	hmac                         in-memory key (hmacKey)
+//	awskms:<key id or ARN>       AWS KMS asymmetric key (AWS_* environment)
+//	gcpkms:projects/.../cryptoKeyVersions/N   Google Cloud KMS key version
+//
+// PKCS#11 tokens are supported through FromCryptoSigner.
+func Open(ctx context.Context, spec string, hmacKey []byte) (Signer, error) {
+	kind, arg, _ := strings.Cut(spec, ":")
+	switch kind {
+	case "", "hmac":
+		if len(hmacKey) == 0 {
+			return nil, errors.New("hmac signer needs a jwt key")
+		}
+		return HMAC(hmacKey), nil
+	case "awskms":
+		return NewAWSKMS(ctx, nil, arg)
+	case "gcpkms":
+		return NewGCPKMS(ctx, nil, arg)
+	}
+	return nil, fmt.Errorf("unknown jwt signer %q (want hmac, awskms:<key> or gcpkms:<key version>)", kind)
+}
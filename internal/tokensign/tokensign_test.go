@@ -0,0 +1,183 @@
+package tokensign
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/and161185/goph-keeper/internal/awsv4"
+)
+
+// roundTrip issues a token with s and verifies it with s's public half.
+func roundTrip(t *testing.T, s Signer) {
+	t.Helper()
+	claims := jwt.RegisteredClaims{Subject: "u1", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))}
+	tok, err := Issue(context.Background(), s, claims)
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+	var got jwt.RegisteredClaims
+	parsed, err := jwt.ParseWithClaims(tok, &got, Keyfunc(s))
+	if err != nil || !parsed.Valid || got.Subject != "u1" {
+		t.Fatalf("%s verify: %v", s.Method().Alg(), err)
+	}
+}
+
+func TestHMAC_RoundTripAndCompat(t *testing.T) {
+	t.Parallel()
+	key := []byte("k")
+	roundTrip(t, HMAC(key))
+
+	// tokens from jwt's own HS256 signer must verify too
+	legacy, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{Subject: "x"}).SignedString(key)
+	if _, err := jwt.Parse(legacy, Keyfunc(HMAC(key))); err != nil {
+		t.Fatalf("legacy token: %v", err)
+	}
+}
+
+func TestClaims_HasScope(t *testing.T) {
+	t.Parallel()
+	if c := (Claims{}); !c.HasScope("items:write") {
+		t.Fatal("unscoped token refused")
+	}
+	c := Claims{Scope: "items:read account:manage"}
+	if !c.HasScope("items:read") || !c.HasScope("account:manage") || c.HasScope("items:write") || c.HasScope("items") {
+		t.Fatalf("scope %q", c.Scope)
+	}
+}
+
+func TestFromCryptoSigner(t *testing.T) {
+	t.Parallel()
+	ec, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	rs, _ := rsa.GenerateKey(rand.Reader, 2048)
+	_, ed, _ := ed25519.GenerateKey(rand.Reader)
+	for _, k := range []crypto.Signer{ec, rs, ed} {
+		s, err := FromCryptoSigner(k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		roundTrip(t, s)
+	}
+
+	p384, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if _, err := FromCryptoSigner(p384); err == nil {
+		t.Fatal("P-384 must be rejected")
+	}
+}
+
+func TestKeyfunc_RejectsOtherAlgorithms(t *testing.T) {
+	t.Parallel()
+	ec, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	es, _ := FromCryptoSigner(ec)
+	der, _ := x509.MarshalPKIXPublicKey(&ec.PublicKey)
+
+	// HS256 "signed" with the public key bytes: the classic algorithm-confusion attack
+	forged, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{Subject: "x"}).SignedString(der)
+	if _, err := jwt.Parse(forged, Keyfunc(es)); err == nil {
+		t.Fatal("HS256 token accepted by ES256 verifier")
+	}
+}
+
+func TestAWSKMS(t *testing.T) {
+	t.Parallel()
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	spki, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var in struct {
+			KeyId, MessageType, SigningAlgorithm string
+			Message                              []byte
+		}
+		_ = json.NewDecoder(r.Body).Decode(&in)
+		switch r.Header.Get("X-Amz-Target") {
+		case "TrentService.GetPublicKey":
+			_ = json.NewEncoder(w).Encode(map[string]any{"PublicKey": spki, "KeyUsage": "SIGN_VERIFY"})
+		case "TrentService.Sign":
+			if in.KeyId != "alias/gk" || in.MessageType != "DIGEST" || in.SigningAlgorithm != "ECDSA_SHA_256" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			sig, _ := ecdsa.SignASN1(rand.Reader, key, in.Message)
+			_ = json.NewEncoder(w).Encode(map[string]any{"Signature": sig})
+		}
+	}))
+	defer srv.Close()
+
+	api := &awsv4.Client{Region: "eu-west-1", Creds: awsv4.Credentials{AccessKey: "AK", SecretKey: "SK"}, Endpoint: srv.URL, HTTP: srv.Client()}
+	s, err := NewAWSKMS(context.Background(), api, "alias/gk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Method() != jwt.SigningMethodES256 {
+		t.Fatalf("method %s", s.Method().Alg())
+	}
+	roundTrip(t, s)
+}
+
+func TestGCPKMS(t *testing.T) {
+	t.Parallel()
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	spki, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	pemKey := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: spki}))
+	const name = "projects/p/locations/global/keyRings/r/cryptoKeys/jwt/cryptoKeyVersions/1"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/" + name + "/publicKey":
+			_ = json.NewEncoder(w).Encode(map[string]string{"pem": pemKey, "algorithm": "RSA_SIGN_PKCS1_2048_SHA256"})
+		case "/v1/" + name + ":asymmetricSign":
+			var in struct {
+				Digest struct{ Sha256 []byte } `json:"digest"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&in)
+			sig, _ := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, in.Digest.Sha256)
+			_ = json.NewEncoder(w).Encode(map[string]any{"signature": sig})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	g := &GCP{Endpoint: srv.URL, HTTP: srv.Client(), Token: func(context.Context) (string, error) { return "tok", nil }}
+	s, err := NewGCPKMS(context.Background(), g, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Method() != jwt.SigningMethodRS256 {
+		t.Fatalf("method %s", s.Method().Alg())
+	}
+	roundTrip(t, s)
+
+	if _, err := NewGCPKMS(context.Background(), g, "projects/p/keyRings/r"); err == nil || !strings.Contains(err.Error(), "crypto key version") {
+		t.Fatalf("bad name: %v", err)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	t.Parallel()
+	if s, err := Open(context.Background(), "hmac", []byte("k")); err != nil || s.Method() != jwt.SigningMethodHS256 {
+		t.Fatalf("hmac: %v", err)
+	}
+	if _, err := Open(context.Background(), "", nil); err == nil {
+		t.Fatal("hmac without key must fail")
+	}
+	if _, err := Open(context.Background(), "pkcs11:slot", nil); err == nil {
+		t.Fatal("unknown kind must fail")
+	}
+}
@@ -0,0 +1,86 @@
+package totp
+
+import (
+	"testing"
+	"time"
+)
+
+// RFC 6238 Appendix B test vectors.
+func TestCode_RFC6238Vectors(t *testing.T) {
+	t.Parallel()
+	seeds := map[string][]byte{
+		"SHA1":   []byte("12345678901234567890"),
+		"SHA256": []byte("12345678901234567890123456789012"),
+		"SHA512": []byte("1234567890123456789012345678901234567890123456789012345678901234"),
+	}
+	cases := []struct {
+		unix int64
+		algo string
+		want string
+	}{
+		{59, "SHA1", "94287082"},
+		{59, "SHA256", "46119246"},
+		{59, "SHA512", "90693936"},
+		{1111111109, "SHA1", "07081804"},
+		{1234567890, "SHA256", "91819424"},
+		{20000000000, "SHA512", "47863826"},
+	}
+	for _, c := range cases {
+		got, err := Code(Params{Secret: seeds[c.algo], Digits: 8, Period: 30, Algo: c.algo}, time.Unix(c.unix, 0))
+		if err != nil {
+			t.Fatalf("%s@%d: %v", c.algo, c.unix, err)
+		}
+		if got != c.want {
+			t.Fatalf("%s@%d: got %s want %s", c.algo, c.unix, got, c.want)
+		}
+	}
+}
+
+func TestCode_SixDigitsAndErrors(t *testing.T) {
+	t.Parallel()
+	p := Params{Secret: []byte("12345678901234567890"), Digits: 6, Period: 30}
+	got, err := Code(p, time.Unix(59, 0))
+	if err != nil || got != "287082" {
+		t.Fatalf("6 digits: %q %v", got, err)
+	}
+	if _, err := Code(Params{Secret: p.Secret, Digits: 7, Period: 30}, time.Now()); err == nil {
+		t.Fatalf("want error on digits=7")
+	}
+	if _, err := Code(Params{Secret: p.Secret, Digits: 6, Period: 0}, time.Now()); err == nil {
+		t.Fatalf("want error on period=0")
+	}
+	if _, err := Code(Params{Secret: p.Secret, Digits: 6, Period: 30, Algo: "MD5"}, time.Now()); err == nil {
+		t.Fatalf("want error on unknown algo")
+	}
+}
+
+func TestDecodeSecret(t *testing.T) {
+	t.Parallel()
+	a, err := DecodeSecret("JBSWY3DPEHPK3PXP")
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	b, err := DecodeSecret("jbsw y3dp ehpk 3pxp")
+	if err != nil || string(a) != string(b) {
+		t.Fatalf("lenient decode mismatch: %v", err)
+	}
+	if _, err := DecodeSecret(""); err == nil {
+		t.Fatalf("want error on empty")
+	}
+	if _, err := DecodeSecret("abc!"); err == nil {
+		t.Fatalf("want error on bad alphabet")
+	}
+}
+
+func TestRemaining(t *testing.T) {
+	t.Parallel()
+	if got := Remaining(30, time.Unix(59, 0)); got != time.Second {
+		t.Fatalf("got %v", got)
+	}
+	if got := Remaining(30, time.Unix(60, 0)); got != 30*time.Second {
+		t.Fatalf("got %v", got)
+	}
+	if Remaining(0, time.Now()) != 0 {
+		t.Fatalf("zero period")
+	}
+}
@@ -0,0 +1,59 @@
+// Package usage rolls up each user's storage for the admin usage report.
+// A rollup reads every item, so it runs on a timer rather than on writes;
+// the report is as old as the last rollup.
+package usage
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/and161185/goph-keeper/internal/repository"
+)
+
+// Keep is how many days of rollups are kept.
+const Keep = 400
+
+// Roller records every user's usage once per Interval, under the UTC day.
+// Rolling up again on the same day replaces that day's figures, so several
+// servers may run one.
+type Roller struct {
+	Repo     repository.UsageRepository
+	Interval time.Duration
+	Logger   *zap.Logger
+	Now      func() time.Time
+}
+
+func (r *Roller) now() time.Time {
+	if r.Now != nil {
+		return r.Now()
+	}
+	return time.Now()
+}
+
+// RunOnce rolls up usage under today.
+func (r *Roller) RunOnce(ctx context.Context) error {
+	day := r.now().UTC().Truncate(24 * time.Hour)
+	n, err := r.Repo.RollupUsage(ctx, day, Keep)
+	if err != nil {
+		r.Logger.Error("usage rollup", zap.Error(err))
+		return err
+	}
+	r.Logger.Info("usage rolled up", zap.Time("day", day), zap.Int64("users", n))
+	return nil
+}
+
+// Run rolls up at once, then every Interval until ctx is done.
+func (r *Roller) Run(ctx context.Context) {
+	tick := time.NewTicker(r.Interval)
+	defer tick.Stop()
+	for {
+		_ = r.RunOnce(ctx) // logged
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package usage
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/and161185/goph-keeper/internal/model"
+)
+
+type fakeRepo struct {
+	mu   sync.Mutex
+	days []time.Time
+	keep int
+	err  error
+}
+
+func (f *fakeRepo) RollupUsage(_ context.Context, day time.Time, keep int) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.keep = keep
+	f.days = append(f.days, day)
+	return 2, f.err
+}
+
+func (f *fakeRepo) UsageReport(context.Context, string, int, int) (model.UsageReport, error) {
+	return model.UsageReport{}, nil
+}
+
+func (f *fakeRepo) calls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.days)
+}
+
+func TestRoller_RunOnce(t *testing.T) {
+	t.Parallel()
+	repo := &fakeRepo{}
+	// late evening west of UTC is already the next UTC day
+	at := time.Date(2026, 10, 16, 23, 30, 0, 0, time.FixedZone("", -3*3600))
+	r := &Roller{Repo: repo, Logger: zap.NewNop(), Now: func() time.Time { return at }}
+	if err := r.RunOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC); !repo.days[0].Equal(want) || repo.keep != Keep {
+		t.Fatalf("day %v keep %d, want %v keep %d", repo.days[0], repo.keep, want, Keep)
+	}
+
+	repo.err = errors.New("down")
+	if err := r.RunOnce(context.Background()); !errors.Is(err, repo.err) {
+		t.Fatalf("err %v", err)
+	}
+}
+
+func TestRoller_Run(t *testing.T) {
+	t.Parallel()
+	repo := &fakeRepo{}
+	r := &Roller{Repo: repo, Interval: time.Millisecond, Logger: zap.NewNop()}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() { r.Run(ctx); close(done) }()
+	for repo.calls() < 3 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not stop")
+	}
+}
@@ -0,0 +1,216 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"go.uber.org/zap"
+
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/and161185/goph-keeper/internal/tenant"
+)
+
+// metrics are published under the "webhooks" expvar (see /debug/vars).
+var metrics = expvar.NewMap("webhooks")
+
+// Lister returns a user's webhooks; repository.WebhookRepository implements it.
+type Lister interface {
+	List(ctx context.Context, userID uuid.UUID) ([]model.Webhook, error)
+}
+
+// job is one batch of committed changes of one user.
+type job struct {
+	tenant  string
+	userID  uuid.UUID
+	changes []Change
+	at      time.Time
+}
+
+// Dispatcher delivers change notifications in the background. It
+// implements service.ChangeNotifier; notifications that do not fit in the
+// queue are dropped and counted, clients still see the changes on sync.
+type Dispatcher struct {
+	hooks    Lister
+	client   *http.Client
+	queue    chan job
+	logger   *zap.Logger
+	attempts int
+	backoff  time.Duration
+	now      func() time.Time
+}
+
+// NewDispatcher returns a dispatcher reading webhooks from hooks. Unless
+// policy allows insecure hooks, connections to private, loopback and
+// link-local addresses are refused, so a webhook cannot probe the server's
+// own network.
+func NewDispatcher(hooks Lister, policy Policy, logger *zap.Logger) *Dispatcher {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	if !policy.AllowInsecure {
+		dialer.Control = refusePrivate
+	}
+	tr := &http.Transport{
+		Proxy:                 nil, // a proxy would hide the destination from refusePrivate
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ResponseHeaderTimeout: 10 * time.Second,
+		MaxIdleConnsPerHost:   2,
+		IdleConnTimeout:       time.Minute,
+	}
+	return &Dispatcher{
+		hooks: hooks,
+		client: &http.Client{
+			Transport: tr,
+			Timeout:   15 * time.Second,
+			// a redirect could lead anywhere; receivers must give the final URL
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+		queue:    make(chan job, 1000),
+		logger:   logger,
+		attempts: 3,
+		backoff:  2 * time.Second,
+		now:      time.Now,
+	}
+}
+
+// refusePrivate is a net.Dialer Control hook rejecting non-public addresses.
+func refusePrivate(_, address string, _ syscall.RawConn) error {
+	ap, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	ip := ap.Addr().Unmap()
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() {
+		return fmt.Errorf("webhook: destination %s is not a public address", ip)
+	}
+	return nil
+}
+
+// ItemsChanged queues a notification of changes to userID's webhooks.
+func (d *Dispatcher) ItemsChanged(ctx context.Context, userID uuid.UUID, changes []model.Change) {
+	j := job{tenant: tenant.FromContext(ctx), userID: userID, at: d.now().UTC()}
+	for _, c := range changes {
+		j.changes = append(j.changes, Change{ItemID: c.ID, Ver: c.Ver, Deleted: c.Deleted})
+	}
+	select {
+	case d.queue <- j:
+	default:
+		metrics.Add("dropped", 1)
+	}
+}
+
+// Run delivers queued notifications with workers goroutines until ctx is
+// done. Deliveries in flight are abandoned at shutdown.
+func (d *Dispatcher) Run(ctx context.Context, workers int) {
+	var wg sync.WaitGroup
+	for range max(workers, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case j := <-d.queue:
+					d.handle(ctx, j)
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// handle sends j to every webhook of its user.
+func (d *Dispatcher) handle(ctx context.Context, j job) {
+	ctx = tenant.WithID(ctx, j.tenant)
+	hooks, err := d.hooks.List(ctx, j.userID)
+	if err != nil {
+		metrics.Add("failed", 1)
+		d.logger.Warn("webhook lookup", zap.Stringer("user", j.userID), zap.Error(err))
+		return
+	}
+	if len(hooks) == 0 {
+		return
+	}
+	p := Payload{
+		ID:        uuid.Must(uuid.NewV4()),
+		Event:     EventVaultChanged,
+		UserID:    j.userID,
+		CreatedAt: j.at,
+		Changes:   j.changes,
+	}
+	body, err := json.Marshal(p)
+	if err != nil {
+		return
+	}
+	for _, h := range hooks {
+		if err := d.deliver(ctx, h, p.ID, body); err != nil {
+			metrics.Add("failed", 1)
+			d.logger.Warn("webhook delivery", zap.Stringer("webhook", h.ID), zap.Stringer("user", j.userID), zap.Error(err))
+			continue
+		}
+		metrics.Add("delivered", 1)
+	}
+}
+
+// errPermanent marks a response that retrying will not change.
+var errPermanent = errors.New("permanent")
+
+// deliver posts body to h, retrying network errors, 429 and 5xx.
+func (d *Dispatcher) deliver(ctx context.Context, h model.Webhook, id uuid.UUID, body []byte) error {
+	var err error
+	for attempt := 0; attempt < d.attempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(d.backoff << (attempt - 1)):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		if err = d.post(ctx, h, id, body); err == nil || errors.Is(err, errPermanent) {
+			return err
+		}
+	}
+	return err
+}
+
+func (d *Dispatcher) post(ctx context.Context, h model.Webhook, id uuid.UUID, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%w: %v", errPermanent, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "GophKeeper-Webhook/1")
+	req.Header.Set(HeaderEvent, EventVaultChanged)
+	req.Header.Set(HeaderDelivery, id.String())
+	// signed per attempt so a retry is not rejected as stale
+	req.Header.Set(HeaderSignature, Sign(h.Secret, d.now(), body))
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4<<10))
+	switch {
+	case resp.StatusCode/100 == 2:
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return errors.New(resp.Status)
+	default:
+		return fmt.Errorf("%w: %s", errPermanent, resp.Status)
+	}
+}
@@ -0,0 +1,121 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+
+	"github.com/and161185/goph-keeper/internal/model"
+	"github.com/and161185/goph-keeper/internal/tenant"
+)
+
+type fakeHooks struct {
+	hooks  []model.Webhook
+	tenant string
+}
+
+func (f *fakeHooks) List(ctx context.Context, _ uuid.UUID) ([]model.Webhook, error) {
+	f.tenant = tenant.FromContext(ctx)
+	return f.hooks, nil
+}
+
+// receiver records deliveries, failing the first fail ones with code.
+type receiver struct {
+	mu     sync.Mutex
+	fail   int
+	code   int
+	calls  int
+	bodies [][]byte
+	hdr    http.Header
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls++
+	if r.calls <= r.fail {
+		w.WriteHeader(r.code)
+		return
+	}
+	b, _ := io.ReadAll(req.Body)
+	r.bodies = append(r.bodies, b)
+	r.hdr = req.Header.Clone()
+}
+
+func testDispatcher(hooks Lister) *Dispatcher {
+	d := NewDispatcher(hooks, Policy{AllowInsecure: true}, nil)
+	d.backoff = time.Millisecond
+	return d
+}
+
+func TestDispatcher_DeliversSignedChanges(t *testing.T) {
+	t.Parallel()
+	rcv := &receiver{fail: 1, code: http.StatusBadGateway}
+	srv := httptest.NewServer(rcv)
+	defer srv.Close()
+	user := uuid.Must(uuid.NewV4())
+	item := uuid.Must(uuid.NewV4())
+	hooks := &fakeHooks{hooks: []model.Webhook{{ID: uuid.Must(uuid.NewV4()), URL: srv.URL + "/h", Secret: []byte("whsec_k")}}}
+	d := testDispatcher(hooks)
+
+	ctx := tenant.WithID(context.Background(), "acme")
+	d.ItemsChanged(ctx, user, []model.Change{{ID: item, Ver: 4, Deleted: true, BlobEnc: []byte("never sent")}})
+	d.handle(context.Background(), <-d.queue)
+
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+	if rcv.calls != 2 || len(rcv.bodies) != 1 {
+		t.Fatalf("calls=%d delivered=%d, want a retry after 502", rcv.calls, len(rcv.bodies))
+	}
+	if hooks.tenant != "acme" {
+		t.Fatalf("hooks listed in tenant %q", hooks.tenant)
+	}
+	body := rcv.bodies[0]
+	if err := Verify([]byte("whsec_k"), rcv.hdr.Get(HeaderSignature), body, time.Now(), time.Minute); err != nil {
+		t.Fatalf("signature: %v", err)
+	}
+	var p Payload
+	if err := json.Unmarshal(body, &p); err != nil {
+		t.Fatal(err)
+	}
+	want := []Change{{ItemID: item, Ver: 4, Deleted: true}}
+	if p.Event != EventVaultChanged || p.UserID != user || len(p.Changes) != 1 || p.Changes[0] != want[0] ||
+		rcv.hdr.Get(HeaderDelivery) != p.ID.String() {
+		t.Fatalf("payload %+v headers %v", p, rcv.hdr)
+	}
+}
+
+func TestDispatcher_NoRetryOnClientError(t *testing.T) {
+	t.Parallel()
+	rcv := &receiver{fail: 5, code: http.StatusGone}
+	srv := httptest.NewServer(rcv)
+	defer srv.Close()
+	d := testDispatcher(nil)
+	err := d.deliver(context.Background(), model.Webhook{URL: srv.URL}, uuid.Must(uuid.NewV4()), []byte("{}"))
+	if !errors.Is(err, errPermanent) || rcv.calls != 1 {
+		t.Fatalf("err=%v calls=%d", err, rcv.calls)
+	}
+}
+
+func TestDispatcher_RefusesPrivateDestinations(t *testing.T) {
+	t.Parallel()
+	rcv := &receiver{}
+	srv := httptest.NewServer(rcv)
+	defer srv.Close()
+	d := NewDispatcher(nil, Policy{}, nil)
+	d.attempts = 1
+	if err := d.deliver(context.Background(), model.Webhook{URL: srv.URL}, uuid.Must(uuid.NewV4()), []byte("{}")); err == nil {
+		t.Fatal("loopback destination reached")
+	}
+	if rcv.calls != 0 {
+		t.Fatal("request sent")
+	}
+}
@@ -0,0 +1,141 @@
+// Package webhook notifies users' endpoints of changes to their vault.
+//
+// A delivery is a JSON POST naming the changed items by id, version and
+// deleted flag; it never carries item data, encrypted or not. Each request
+// is signed with the webhook's secret so receivers can reject forgeries and
+// replays (see Sign and Verify).
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+// Headers set on every delivery.
+const (
+	HeaderSignature = "X-GophKeeper-Signature"
+	HeaderEvent     = "X-GophKeeper-Event"
+	HeaderDelivery  = "X-GophKeeper-Delivery"
+)
+
+// EventVaultChanged is the only event type so far.
+const EventVaultChanged = "vault.changed"
+
+// Payload is the body of a delivery.
+type Payload struct {
+	ID        uuid.UUID `json:"id"` // delivery id, the same across retries
+	Event     string    `json:"event"`
+	UserID    uuid.UUID `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+	Changes   []Change  `json:"changes"`
+}
+
+// Change is one changed item.
+type Change struct {
+	ItemID  uuid.UUID `json:"item_id"`
+	Ver     int64     `json:"ver"`
+	Deleted bool      `json:"deleted"`
+}
+
+// NewSecret returns a fresh signing secret. The string itself, not a
+// decoding of it, is the HMAC key.
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Sign returns the signature header value for body sent at t:
+// "t=<unix seconds>,v1=<hex HMAC-SHA256(secret, "<t>.<body>")>".
+func Sign(secret []byte, t time.Time, body []byte) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	return "t=" + ts + ",v1=" + hex.EncodeToString(mac(secret, ts, body))
+}
+
+func mac(secret []byte, ts string, body []byte) []byte {
+	m := hmac.New(sha256.New, secret)
+	m.Write([]byte(ts))
+	m.Write([]byte{'.'})
+	m.Write(body)
+	return m.Sum(nil)
+}
+
+// ErrSignature is returned by Verify for a missing, malformed, wrong or
+// stale signature.
+var ErrSignature = errors.New("webhook: bad signature")
+
+// Verify checks a delivery's signature header against body. Signatures more
+// than tolerance away from now are rejected, so a captured request cannot
+// be replayed later.
+func Verify(secret []byte, header string, body []byte, now time.Time, tolerance time.Duration) error {
+	var ts string
+	var sigs [][]byte
+	for _, part := range strings.Split(header, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch k {
+		case "t":
+			ts = v
+		case "v1":
+			if b, err := hex.DecodeString(v); err == nil {
+				sigs = append(sigs, b)
+			}
+		}
+	}
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || len(sigs) == 0 {
+		return ErrSignature
+	}
+	if d := now.Sub(time.Unix(sec, 0)); d > tolerance || d < -tolerance {
+		return fmt.Errorf("%w: timestamp outside tolerance", ErrSignature)
+	}
+	want := mac(secret, ts, body)
+	for _, s := range sigs {
+		if hmac.Equal(s, want) {
+			return nil
+		}
+	}
+	return ErrSignature
+}
+
+// Policy limits what users may register.
+type Policy struct {
+	// MaxPerUser caps the webhooks of one user.
+	MaxPerUser int
+	// AllowInsecure permits http:// URLs and private, loopback and
+	// link-local destinations. For development only.
+	AllowInsecure bool
+}
+
+// CheckURL reports whether raw may be registered as a webhook.
+func (p Policy) CheckURL(raw string) error {
+	if len(raw) > 2048 {
+		return errors.New("url too long")
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return errors.New("malformed url")
+	}
+	if u.Scheme != "https" && !(p.AllowInsecure && u.Scheme == "http") {
+		return errors.New("url must be https://")
+	}
+	if u.Hostname() == "" {
+		return errors.New("url has no host")
+	}
+	if u.User != nil {
+		return errors.New("url must not carry credentials")
+	}
+	return nil
+}
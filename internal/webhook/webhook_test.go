@@ -0,0 +1,69 @@
+package webhook
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignVerify(t *testing.T) {
+	t.Parallel()
+	secret, err := NewSecret()
+	if err != nil || !strings.HasPrefix(secret, "whsec_") {
+		t.Fatalf("secret %q: %v", secret, err)
+	}
+	key := []byte(secret)
+	body := []byte(`{"event":"vault.changed"}`)
+	at := time.Unix(1_700_000_000, 0)
+	sig := Sign(key, at, body)
+	if !strings.HasPrefix(sig, "t=1700000000,v1=") {
+		t.Fatalf("header %q", sig)
+	}
+
+	if err := Verify(key, sig, body, at.Add(time.Minute), 5*time.Minute); err != nil {
+		t.Fatalf("valid signature rejected: %v", err)
+	}
+	// a receiver rotating keys may see several v1 values
+	if err := Verify(key, "t=1700000000,v1=00, v1="+sig[len("t=1700000000,v1="):], body, at, time.Minute); err != nil {
+		t.Fatalf("second v1 ignored: %v", err)
+	}
+	for name, c := range map[string]struct {
+		key, body []byte
+		header    string
+		now       time.Time
+	}{
+		"tampered body": {key, []byte(`{}`), sig, at},
+		"wrong key":     {[]byte("whsec_other"), body, sig, at},
+		"replayed":      {key, body, sig, at.Add(6 * time.Minute)},
+		"no timestamp":  {key, body, sig[strings.Index(sig, ",")+1:], at},
+		"garbage":       {key, body, "v1=zz", at},
+	} {
+		if err := Verify(c.key, c.header, c.body, c.now, 5*time.Minute); !errors.Is(err, ErrSignature) {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+func TestPolicy_CheckURL(t *testing.T) {
+	t.Parallel()
+	strict, dev := Policy{}, Policy{AllowInsecure: true}
+	for _, c := range []struct {
+		url       string
+		strict, d bool
+	}{
+		{"https://ci.example.com/hooks/gk", true, true},
+		{"http://ci.example.com/hook", false, true},
+		{"https://user:pw@ci.example.com/", false, false},
+		{"https:///path", false, false},
+		{"ftp://ci.example.com/", false, false},
+		{"https://ci.example.com/" + strings.Repeat("a", 2048), false, false},
+	} {
+		if got := strict.CheckURL(c.url) == nil; got != c.strict {
+			t.Errorf("strict %q: allowed=%v", c.url, got)
+		}
+		if got := dev.CheckURL(c.url) == nil; got != c.d {
+			t.Errorf("dev %q: allowed=%v", c.url, got)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+-- +goose Up
+-- Every stored item version (including tombstones), written by trigger so that
+-- all mutation paths are covered. Blobs stay opaque: AAD binds them to their ver.
+CREATE TABLE IF NOT EXISTS item_history (
+  item_id     uuid NOT NULL,
+  user_id     uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  ver         bigint NOT NULL,
+  blob_enc    bytea NOT NULL,
+  deleted     boolean NOT NULL,
+  updated_at  timestamptz NOT NULL,
+  PRIMARY KEY (item_id, ver)
+);
+
+CREATE INDEX IF NOT EXISTS idx_item_history_user_ts ON item_history(user_id, updated_at);
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION record_item_history()
+RETURNS trigger LANGUAGE plpgsql AS $$
+BEGIN
+  INSERT INTO item_history (item_id, user_id, ver, blob_enc, deleted, updated_at)
+  VALUES (NEW.id, NEW.user_id, NEW.ver, NEW.blob_enc, NEW.deleted, NEW.updated_at)
+  ON CONFLICT (item_id, ver) DO NOTHING;
+  RETURN NEW;
+END;
+$$;
+-- +goose StatementEnd
+
+DROP TRIGGER IF EXISTS trg_items_history ON items;
+CREATE TRIGGER trg_items_history
+AFTER INSERT OR UPDATE ON items
+FOR EACH ROW EXECUTE FUNCTION record_item_history();
+
+-- Seed history with the current state of existing items.
+INSERT INTO item_history (item_id, user_id, ver, blob_enc, deleted, updated_at)
+SELECT id, user_id, ver, blob_enc, deleted, updated_at FROM items
+ON CONFLICT (item_id, ver) DO NOTHING;
+
+-- +goose Down
+DROP TRIGGER IF EXISTS trg_items_history ON items;
+DROP FUNCTION IF EXISTS record_item_history();
+DROP TABLE IF EXISTS item_history;
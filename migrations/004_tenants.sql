@@ -0,0 +1,50 @@
+-- +goose Up
+-- Tenant dimension. Existing rows belong to the 'default' tenant; usernames
+-- become unique per tenant. Items carry tenant_id too, and the composite key
+-- keeps an item from ever pointing at a user of another tenant.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tenant_id text NOT NULL DEFAULT 'default';
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_username_key;
+CREATE UNIQUE INDEX IF NOT EXISTS users_tenant_username ON users(tenant_id, username);
+ALTER TABLE users ADD CONSTRAINT users_id_tenant UNIQUE (id, tenant_id);
+
+ALTER TABLE items ADD COLUMN IF NOT EXISTS tenant_id text NOT NULL DEFAULT 'default';
+ALTER TABLE items ADD CONSTRAINT items_user_tenant_fk
+  FOREIGN KEY (user_id, tenant_id) REFERENCES users(id, tenant_id) ON DELETE CASCADE;
+CREATE INDEX IF NOT EXISTS idx_items_tenant_live ON items(tenant_id) WHERE NOT deleted;
+
+ALTER TABLE item_history ADD COLUMN IF NOT EXISTS tenant_id text NOT NULL DEFAULT 'default';
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION record_item_history()
+RETURNS trigger LANGUAGE plpgsql AS $$
+BEGIN
+  INSERT INTO item_history (item_id, user_id, tenant_id, ver, blob_enc, deleted, updated_at)
+  VALUES (NEW.id, NEW.user_id, NEW.tenant_id, NEW.ver, NEW.blob_enc, NEW.deleted, NEW.updated_at)
+  ON CONFLICT (item_id, ver) DO NOTHING;
+  RETURN NEW;
+END;
+$$;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION record_item_history()
+RETURNS trigger LANGUAGE plpgsql AS $$
+BEGIN
+  INSERT INTO item_history (item_id, user_id, ver, blob_enc, deleted, updated_at)
+  VALUES (NEW.id, NEW.user_id, NEW.ver, NEW.blob_enc, NEW.deleted, NEW.updated_at)
+  ON CONFLICT (item_id, ver) DO NOTHING;
+  RETURN NEW;
+END;
+$$;
+-- +goose StatementEnd
+
+ALTER TABLE item_history DROP COLUMN IF EXISTS tenant_id;
+DROP INDEX IF EXISTS idx_items_tenant_live;
+ALTER TABLE items DROP CONSTRAINT IF EXISTS items_user_tenant_fk;
+ALTER TABLE items DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_id_tenant;
+DROP INDEX IF EXISTS users_tenant_username;
+-- fails if tenants reused a username; merge or rename those accounts first
+ALTER TABLE users ADD CONSTRAINT users_username_key UNIQUE (username);
+ALTER TABLE users DROP COLUMN IF EXISTS tenant_id;
@@ -0,0 +1,31 @@
+-- +goose Up
+-- Point-in-time rollbacks of one user's vault. Blobs are bound to their
+-- version, so a rollback resets items to older versions; the history rows it
+-- supersedes move to item_history_discarded instead of being lost.
+CREATE TABLE IF NOT EXISTS user_rollbacks (
+  id           bigserial PRIMARY KEY,
+  user_id      uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  to_time      timestamptz NOT NULL,
+  performed_by uuid NOT NULL,
+  performed_at timestamptz NOT NULL DEFAULT now(),
+  restored     bigint NOT NULL,
+  removed      bigint NOT NULL,
+  discarded    bigint NOT NULL,
+  skipped      bigint NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS item_history_discarded (
+  rollback_id bigint NOT NULL REFERENCES user_rollbacks(id) ON DELETE CASCADE,
+  item_id     uuid NOT NULL,
+  user_id     uuid NOT NULL,
+  tenant_id   text NOT NULL,
+  ver         bigint NOT NULL,
+  blob_enc    bytea NOT NULL,
+  deleted     boolean NOT NULL,
+  updated_at  timestamptz NOT NULL,
+  PRIMARY KEY (rollback_id, item_id, ver)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS item_history_discarded;
+DROP TABLE IF EXISTS user_rollbacks;
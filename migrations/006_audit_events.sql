@@ -0,0 +1,20 @@
+-- +goose Up
+-- Security-relevant calls (sign-ups, logins, key and item changes, admin
+-- operations), kept for export to a SIEM. No payloads are recorded.
+CREATE TABLE IF NOT EXISTS audit_events (
+  id        bigserial PRIMARY KEY,
+  at        timestamptz NOT NULL,
+  tenant_id text NOT NULL,
+  action    text NOT NULL,
+  user_id   uuid,
+  subject   text NOT NULL DEFAULT '',
+  target    text NOT NULL DEFAULT '',
+  peer      text NOT NULL DEFAULT '',
+  outcome   text NOT NULL,
+  code      text NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS audit_events_at_idx ON audit_events (at);
+
+-- +goose Down
+DROP TABLE IF EXISTS audit_events;
@@ -0,0 +1,16 @@
+-- +goose Up
+-- Per-user change notification endpoints. The secret signs deliveries and
+-- must be readable by the server, so it is stored as issued.
+CREATE TABLE IF NOT EXISTS webhooks (
+  id         uuid PRIMARY KEY,
+  user_id    uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  tenant_id  text NOT NULL,
+  url        text NOT NULL,
+  secret     bytea NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS webhooks_user_idx ON webhooks (user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS webhooks;
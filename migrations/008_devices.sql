@@ -0,0 +1,15 @@
+-- +goose Up
+-- Devices woken by push notifications when their owner's vault changes.
+CREATE TABLE IF NOT EXISTS devices (
+  id         uuid PRIMARY KEY,
+  user_id    uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  tenant_id  text NOT NULL,
+  platform   text NOT NULL,
+  token      text NOT NULL,
+  name       text NOT NULL DEFAULT '',
+  created_at timestamptz NOT NULL DEFAULT now(),
+  UNIQUE (user_id, platform, token)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS devices;
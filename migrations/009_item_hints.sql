@@ -0,0 +1,43 @@
+-- +goose Up
+-- Client-declared hints: the size class the blob was checked against and
+-- the client's payload schema version. Both are opaque to the server and
+-- recorded in history with the blob they describe, and kept with it when a
+-- rollback moves that history to item_history_discarded.
+ALTER TABLE items ADD COLUMN IF NOT EXISTS size_class smallint NOT NULL DEFAULT 0;
+ALTER TABLE items ADD COLUMN IF NOT EXISTS schema_version integer NOT NULL DEFAULT 0;
+ALTER TABLE item_history ADD COLUMN IF NOT EXISTS size_class smallint NOT NULL DEFAULT 0;
+ALTER TABLE item_history ADD COLUMN IF NOT EXISTS schema_version integer NOT NULL DEFAULT 0;
+ALTER TABLE item_history_discarded ADD COLUMN IF NOT EXISTS size_class smallint NOT NULL DEFAULT 0;
+ALTER TABLE item_history_discarded ADD COLUMN IF NOT EXISTS schema_version integer NOT NULL DEFAULT 0;
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION record_item_history()
+RETURNS trigger LANGUAGE plpgsql AS $$
+BEGIN
+  INSERT INTO item_history (item_id, user_id, tenant_id, ver, blob_enc, deleted, updated_at, size_class, schema_version)
+  VALUES (NEW.id, NEW.user_id, NEW.tenant_id, NEW.ver, NEW.blob_enc, NEW.deleted, NEW.updated_at, NEW.size_class, NEW.schema_version)
+  ON CONFLICT (item_id, ver) DO NOTHING;
+  RETURN NEW;
+END;
+$$;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION record_item_history()
+RETURNS trigger LANGUAGE plpgsql AS $$
+BEGIN
+  INSERT INTO item_history (item_id, user_id, tenant_id, ver, blob_enc, deleted, updated_at)
+  VALUES (NEW.id, NEW.user_id, NEW.tenant_id, NEW.ver, NEW.blob_enc, NEW.deleted, NEW.updated_at)
+  ON CONFLICT (item_id, ver) DO NOTHING;
+  RETURN NEW;
+END;
+$$;
+-- +goose StatementEnd
+
+ALTER TABLE item_history_discarded DROP COLUMN IF EXISTS schema_version;
+ALTER TABLE item_history_discarded DROP COLUMN IF EXISTS size_class;
+ALTER TABLE item_history DROP COLUMN IF EXISTS schema_version;
+ALTER TABLE item_history DROP COLUMN IF EXISTS size_class;
+ALTER TABLE items DROP COLUMN IF EXISTS schema_version;
+ALTER TABLE items DROP COLUMN IF EXISTS size_class;
@@ -0,0 +1,13 @@
+-- +goose NO TRANSACTION
+-- +goose Up
+-- GetChanges pages through a user's items by (ver, id). This index covers
+-- the whole keyset condition and the order, so a page reads O(page) index
+-- entries and rows instead of sorting the vault. It replaces
+-- idx_items_user_ver, which lacked tenant_id and id. Built concurrently so
+-- writes go on meanwhile.
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_items_changes ON items (user_id, tenant_id, ver, id);
+DROP INDEX CONCURRENTLY IF EXISTS idx_items_user_ver;
+
+-- +goose Down
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_items_user_ver ON items (user_id, ver);
+DROP INDEX CONCURRENTLY IF EXISTS idx_items_changes;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Wrong passwords not yet reported to the account owner. Unlike fail_count
+-- it survives the window and lockouts, and is zeroed for every address of
+-- a username when that username next logs in.
+ALTER TABLE auth_limiter ADD COLUMN IF NOT EXISTS unseen_fails INT NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE auth_limiter DROP COLUMN IF EXISTS unseen_fails;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- Recent password checks per user, shown to the user to spot logins that
+-- were not theirs. The service trims each user to the newest rows.
+CREATE TABLE IF NOT EXISTS login_history (
+  id        bigserial PRIMARY KEY,
+  user_id   uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  tenant_id text NOT NULL,
+  at        timestamptz NOT NULL,
+  ip_hash   bytea NOT NULL,
+  success   boolean NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_login_history_user ON login_history (user_id, id);
+
+-- +goose Down
+DROP TABLE IF EXISTS login_history;
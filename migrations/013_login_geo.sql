@@ -0,0 +1,16 @@
+-- +goose Up
+-- Where logins came from, when the server has a GeoIP database, and the
+-- anomalies found in them. Coordinates are NULL unless a City database
+-- placed the address.
+ALTER TABLE login_history
+  ADD COLUMN IF NOT EXISTS country text NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS lat double precision,
+  ADD COLUMN IF NOT EXISTS lon double precision;
+
+ALTER TABLE audit_events
+  ADD COLUMN IF NOT EXISTS country text NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS flags text[] NOT NULL DEFAULT '{}';
+
+-- +goose Down
+ALTER TABLE audit_events DROP COLUMN IF EXISTS flags, DROP COLUMN IF EXISTS country;
+ALTER TABLE login_history DROP COLUMN IF EXISTS lon, DROP COLUMN IF EXISTS lat, DROP COLUMN IF EXISTS country;
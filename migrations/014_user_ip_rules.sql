@@ -0,0 +1,14 @@
+-- +goose Up
+-- Per-user client address rules, e.g. to keep a vault reachable only from a
+-- corporate VPN. Set by admins; checked on every call the user makes.
+CREATE TABLE IF NOT EXISTS user_ip_rules (
+  user_id    uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  tenant_id  text NOT NULL,
+  cidr       cidr NOT NULL,
+  allow      boolean NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY (user_id, cidr)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS user_ip_rules;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- Refresh-token sessions. Only a SHA-256 of each token is kept, and it
+-- changes every time the token is used.
+CREATE TABLE IF NOT EXISTS sessions (
+  id           uuid PRIMARY KEY,
+  user_id      uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  tenant_id    text NOT NULL,
+  token_hash   bytea NOT NULL UNIQUE,
+  label        text NOT NULL DEFAULT '',
+  created_at   timestamptz NOT NULL DEFAULT now(),
+  last_used_at timestamptz NOT NULL DEFAULT now(),
+  expires_at   timestamptz NOT NULL
+);
+CREATE INDEX IF NOT EXISTS sessions_user_idx ON sessions (user_id, last_used_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS sessions;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- Scopes a session was started with; every refresh issues access tokens
+-- limited to them. Empty means unlimited, as for sessions from before.
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS scopes text[] NOT NULL DEFAULT '{}';
+
+-- +goose Down
+ALTER TABLE sessions DROP COLUMN IF EXISTS scopes;
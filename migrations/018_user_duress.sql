@@ -0,0 +1,15 @@
+-- +goose Up
+-- Duress vaults. A vault is a users row of its own (password hash, KEK
+-- salt, wrapped DEK, items) whose username no one can register; logging in
+-- to the owner's name with the vault's password opens it instead of the
+-- owner's vault. Removing the vault's row removes its items and this link.
+CREATE TABLE IF NOT EXISTS user_duress (
+  user_id    uuid PRIMARY KEY,
+  tenant_id  text NOT NULL,
+  vault_id   uuid NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  FOREIGN KEY (user_id, tenant_id) REFERENCES users(id, tenant_id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS user_duress;
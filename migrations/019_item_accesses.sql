@@ -0,0 +1,18 @@
+-- +goose Up
+-- Who read which item: one row per item and session, updated on each
+-- GetItem. The label is copied from the session, so a revoked device stays
+-- recognisable.
+CREATE TABLE IF NOT EXISTS item_accesses (
+  item_id     uuid NOT NULL REFERENCES items(id) ON DELETE CASCADE,
+  session_id  uuid NOT NULL, -- the nil uuid for tokens without a session
+  user_id     uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  tenant_id   text NOT NULL,
+  label       text NOT NULL DEFAULT '',
+  first_at    timestamptz NOT NULL,
+  last_at     timestamptz NOT NULL,
+  reads       bigint NOT NULL DEFAULT 1,
+  PRIMARY KEY (item_id, session_id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS item_accesses;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- Set while the user has frozen their vault: the server refuses every
+-- change to it until the user unfreezes it.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS frozen_at timestamptz;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN IF EXISTS frozen_at;
@@ -0,0 +1,21 @@
+-- +goose Up
+-- Each user's storage as of one day, written by the server's usage rollup
+-- (-usage-rollup) and read by AdminService.GetUsageReport. A rollup
+-- replaces the day's row, so the last one of a day stands for it.
+CREATE TABLE IF NOT EXISTS user_usage (
+  user_id       uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  day           date NOT NULL,
+  tenant_id     text NOT NULL,
+  items         bigint NOT NULL, -- live items
+  tombstones    bigint NOT NULL,
+  bytes         bigint NOT NULL, -- ciphertext in items, tombstones included
+  history_bytes bigint NOT NULL, -- ciphertext of kept versions
+  last_write    timestamptz,
+  last_login    timestamptz,
+  rolled_up_at  timestamptz NOT NULL,
+  PRIMARY KEY (user_id, day)
+);
+CREATE INDEX IF NOT EXISTS idx_user_usage_day ON user_usage (day, bytes DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS user_usage;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Set by an admin to suspend an account: the server refuses its logins,
+-- refreshes and every call with a token it already holds. A duress vault
+-- follows its owner.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS is_disabled boolean NOT NULL DEFAULT false;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN IF EXISTS is_disabled;
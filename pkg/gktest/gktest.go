@@ -0,0 +1,307 @@
+// Package gktest runs a complete GophKeeper server in-process, for
+// end-to-end tests of clients.
+//
+// The server is the production one: the same handlers, services and
+// interceptors, backed by in-memory repositories and login limiter instead
+// of PostgreSQL. It listens on an in-memory connection with a throwaway TLS
+// certificate, so clients dial it with DialOptions and nothing touches the
+// network or the disk:
+//
+//	srv := gktest.Start(t)
+//	cli := srv.Client(t)
+//	userID, token := srv.NewUser(t, "alice", "secret")
+//	ctx := gktest.WithToken(context.Background(), token)
+//	_, err := cli.UpsertItems(ctx, req)
+//
+// Webhooks are delivered (any URL is allowed, so httptest servers work).
+// Push notifications, the audit trail, the admin service and tenant
+// quotas are not available.
+package gktest
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/internal/breaker"
+	"github.com/and161185/goph-keeper/internal/chaos"
+	"github.com/and161185/goph-keeper/internal/limiter"
+	"github.com/and161185/goph-keeper/internal/longpoll"
+	"github.com/and161185/goph-keeper/internal/repository"
+	"github.com/and161185/goph-keeper/internal/repository/memory"
+	grpcserver "github.com/and161185/goph-keeper/internal/server/grpc"
+	"github.com/and161185/goph-keeper/internal/service"
+	"github.com/and161185/goph-keeper/internal/tokensign"
+	"github.com/and161185/goph-keeper/internal/webhook"
+)
+
+// ServerName is the name in the server's certificate.
+const ServerName = "gktest"
+
+const bufSize = 4 << 20
+
+type config struct {
+	maxBatch   int
+	maxItems   int64
+	maxPage    int
+	accessTTL  time.Duration
+	loginFails int
+	loginBlock time.Duration
+	logger     *zap.Logger
+	minClient  string
+	faults     *chaos.Faults
+	brkFails   int
+	brkCool    time.Duration
+}
+
+// Option changes the server's configuration.
+type Option func(*config)
+
+// WithMaxBatch sets the upsert batch limit (default 1000).
+func WithMaxBatch(n int) Option { return func(c *config) { c.maxBatch = n } }
+
+// WithMaxItems caps each user's live items (default: no cap).
+func WithMaxItems(n int64) Option { return func(c *config) { c.maxItems = n } }
+
+// WithMaxChangesPage sets how many changes a GetChanges page holds at most
+// (default 1000), so clients can test paging with a few items.
+func WithMaxChangesPage(n int) Option { return func(c *config) { c.maxPage = n } }
+
+// WithAccessTTL sets the lifetime of access tokens (default 15 minutes).
+func WithAccessTTL(d time.Duration) Option { return func(c *config) { c.accessTTL = d } }
+
+// WithLoginLimit locks a username out for block after fails failed logins
+// from one address (default 5 and 15 minutes).
+func WithLoginLimit(fails int, block time.Duration) Option {
+	return func(c *config) { c.loginFails, c.loginBlock = fails, block }
+}
+
+// WithLogger makes the server log calls to l (default: nothing is logged).
+func WithLogger(l *zap.Logger) Option { return func(c *config) { c.logger = l } }
+
+// WithMinClientVersion refuses calls from clients reporting an older
+// version, as the server's -min-client-version does (default: any).
+func WithMinClientVersion(v string) Option { return func(c *config) { c.minClient = v } }
+
+// WithFaults injects f into the user and item repositories (see package
+// chaos), so tests can watch clients cope with a struggling database.
+func WithFaults(f chaos.Faults) Option { return func(c *config) { c.faults = &f } }
+
+// WithBreaker puts a circuit breaker in front of the repositories, as the
+// server's -db-breaker-threshold does (default: none). With WithFaults the
+// injected failures go through it.
+func WithBreaker(threshold int, cooldown time.Duration) Option {
+	return func(c *config) { c.brkFails, c.brkCool = threshold, cooldown }
+}
+
+// Server is a running in-process GophKeeper server.
+type Server struct {
+	lis    *bufconn.Listener
+	gs     *grpc.Server
+	roots  *x509.CertPool
+	cancel context.CancelFunc
+}
+
+// New starts a server. Close stops it.
+func New(opts ...Option) (*Server, error) {
+	c := config{
+		maxBatch:   1000,
+		accessTTL:  15 * time.Minute,
+		loginFails: 5,
+		loginBlock: 15 * time.Minute,
+		logger:     zap.NewNop(),
+	}
+	for _, o := range opts {
+		o(&c)
+	}
+	cert, roots, err := selfSigned()
+	if err != nil {
+		return nil, err
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	signer := tokensign.HMAC(key)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	hooks := memory.NewWebhookRepo()
+	hookPolicy := webhook.Policy{MaxPerUser: 5, AllowInsecure: true}
+	hookDisp := webhook.NewDispatcher(hooks, hookPolicy, c.logger)
+	go hookDisp.Run(ctx, 1)
+
+	var (
+		users repository.UserRepository = memory.NewUserRepo()
+		items repository.ItemRepository = memory.NewItemRepo()
+	)
+	if c.faults != nil {
+		in := chaos.New(*c.faults)
+		users, items = chaos.NewUserRepo(in, users), chaos.NewItemRepo(in, items)
+	}
+	if c.brkFails > 0 {
+		brk := breaker.New(c.brkFails, c.brkCool, nil)
+		users, items = breaker.NewUserRepo(brk, users), breaker.NewItemRepo(brk, items)
+	}
+
+	lim := limiter.NewMemory(c.loginBlock, c.loginFails, c.loginBlock)
+	authSvc := service.NewAuthService(users, signer, c.accessTTL, lim)
+	sessions := memory.NewSessionRepo()
+	authSvc.SetSessions(sessions, 30*24*time.Hour)
+	itemSvc := service.NewItemService(items, c.maxBatch)
+	itemSvc.SetMaxItems(c.maxItems)
+	polls := longpoll.NewHub()
+	itemSvc.SetNotifier(service.Notifiers{hookDisp, polls})
+
+	gs := grpc.NewServer(
+		grpc.MaxRecvMsgSize(1<<20),
+		grpc.Creds(credentials.NewServerTLSFromCert(&cert)),
+		grpc.ChainUnaryInterceptor(
+			grpcserver.RecoverUnary(c.logger),
+			grpcserver.LoggingUnary(c.logger),
+			grpcserver.AuthUnary(signer),
+			grpcserver.TenantUnary(nil, signer),
+			grpcserver.CompatUnary(c.minClient),
+			grpcserver.ValidateUnary(grpcserver.Limits{MaxBatch: c.maxBatch}),
+			grpcserver.PolicyUnary(signer),
+			grpcserver.DisabledUnary(authSvc, signer),
+			grpcserver.FreezeUnary(authSvc, signer),
+		),
+		grpc.ChainStreamInterceptor(
+			grpcserver.RecoverStream(c.logger),
+			grpcserver.LoggingStream(c.logger),
+			grpcserver.AuthStream(signer),
+			grpcserver.TenantStream(nil, signer),
+			grpcserver.CompatStream(c.minClient),
+			grpcserver.ValidateStream(grpcserver.Limits{MaxBatch: c.maxBatch}),
+			grpcserver.PolicyStream(signer),
+			grpcserver.DisabledStream(authSvc, signer),
+		),
+	)
+	app := grpcserver.New(authSvc, itemSvc, signer)
+	app.SetWebhooks(hooks, hookPolicy)
+	app.SetChangeWaiter(polls)
+	app.SetMaxChangesPage(c.maxPage)
+	app.SetServerInfo("gktest", c.minClient)
+	app.SetAccessLog(memory.NewAccessLogRepo(sessions))
+	pb.RegisterGophKeeperServer(gs, app)
+
+	s := &Server{lis: bufconn.Listen(bufSize), gs: gs, roots: roots, cancel: cancel}
+	go func() { _ = gs.Serve(s.lis) }()
+	return s, nil
+}
+
+// Start starts a server that is closed when tb's test ends.
+func Start(tb testing.TB, opts ...Option) *Server {
+	tb.Helper()
+	s, err := New(opts...)
+	if err != nil {
+		tb.Fatalf("gktest: %v", err)
+	}
+	tb.Cleanup(s.Close)
+	return s
+}
+
+// Close stops the server and drops its data.
+func (s *Server) Close() {
+	s.gs.Stop()
+	s.cancel()
+	_ = s.lis.Close()
+}
+
+// DialOptions route a connection to the server whatever address is
+// dialed, and trust its certificate.
+func (s *Server) DialOptions() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return s.lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{RootCAs: s.roots, ServerName: ServerName})),
+	}
+}
+
+// Dial connects to the server.
+func (s *Server) Dial(ctx context.Context, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	//nolint:staticcheck // DialContext is supported through 1.x; migrate when grpc.NewClient is stable
+	return grpc.DialContext(ctx, ServerName, append(s.DialOptions(), opts...)...)
+}
+
+// Client returns a client of the server whose connection is closed when
+// tb's test ends.
+func (s *Server) Client(tb testing.TB) pb.GophKeeperClient {
+	tb.Helper()
+	cc, err := s.Dial(context.Background())
+	if err != nil {
+		tb.Fatalf("gktest: dial: %v", err)
+	}
+	tb.Cleanup(func() { _ = cc.Close() })
+	return pb.NewGophKeeperClient(cc)
+}
+
+// NewUser registers a user and logs in, returning the user id and an access
+// token. The user has no wrapped DEK yet.
+func (s *Server) NewUser(tb testing.TB, username, password string) (userID, token string) {
+	tb.Helper()
+	cli := s.Client(tb)
+	ctx := context.Background()
+	rr := &pb.RegisterRequest{}
+	rr.SetUsername(username)
+	rr.SetPassword(password)
+	if _, err := cli.Register(ctx, rr); err != nil {
+		tb.Fatalf("gktest: register %s: %v", username, err)
+	}
+	lr := &pb.LoginRequest{}
+	lr.SetUsername(username)
+	lr.SetPassword(password)
+	resp, err := cli.Login(ctx, lr)
+	if err != nil {
+		tb.Fatalf("gktest: login %s: %v", username, err)
+	}
+	return resp.GetUserId(), resp.GetAccessToken()
+}
+
+// WithToken returns a context whose calls carry token as the bearer.
+func WithToken(ctx context.Context, token string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+}
+
+// selfSigned makes a certificate for ServerName and a pool trusting it.
+func selfSigned() (tls.Certificate, *x509.CertPool, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, nil, err
+	}
+	now := time.Now()
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: ServerName},
+		DNSNames:     []string{ServerName},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, nil, err
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return tls.Certificate{}, nil, err
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(leaf)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, roots, nil
+}
@@ -0,0 +1,191 @@
+package gktest
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/and161185/goph-keeper/gen/go/gophkeeper/v1"
+	"github.com/and161185/goph-keeper/internal/chaos"
+)
+
+func upsert(id string, baseVer int64, blob string) *pb.UpsertItemsRequest {
+	eb := &pb.EncryptedBlob{}
+	eb.SetCiphertext([]byte(blob))
+	ui := &pb.UpsertItem{}
+	ui.SetId(id)
+	ui.SetBaseVer(baseVer)
+	ui.SetBlobEnc(eb)
+	req := &pb.UpsertItemsRequest{}
+	req.SetItems([]*pb.UpsertItem{ui})
+	return req
+}
+
+func TestServer_ItemRoundTrip(t *testing.T) {
+	t.Parallel()
+	srv := Start(t)
+	cli := srv.Client(t)
+	_, token := srv.NewUser(t, "alice", "secret")
+	ctx := WithToken(context.Background(), token)
+
+	swr := &pb.SetWrappedDEKRequest{}
+	swr.SetWrappedDek([]byte("wrapped"))
+	if _, err := cli.SetWrappedDEK(ctx, swr); err != nil {
+		t.Fatalf("set DEK: %v", err)
+	}
+	id := uuid.Must(uuid.NewV7()).String()
+	if _, err := cli.UpsertItems(ctx, upsert(id, 0, "v1")); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := cli.UpsertItems(ctx, upsert(id, 0, "again")); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("stale create: %v", err)
+	}
+	if _, err := cli.UpsertItems(ctx, upsert(id, 1, "v2")); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	gir := &pb.GetItemRequest{}
+	gir.SetId(id)
+	got, err := cli.GetItem(ctx, gir)
+	if err != nil || got.GetVer() != 2 || string(got.GetBlobEnc().GetCiphertext()) != "v2" {
+		t.Fatalf("get: %v %v", got, err)
+	}
+	gcr := &pb.GetChangesRequest{}
+	gcr.SetSinceVer(0)
+	chs, err := cli.GetChanges(ctx, gcr)
+	if err != nil || len(chs.GetChanges()) != 1 {
+		t.Fatalf("changes: %v %v", chs, err)
+	}
+
+	lr := &pb.LoginRequest{}
+	lr.SetUsername("alice")
+	lr.SetPassword("secret")
+	login, err := cli.Login(context.Background(), lr)
+	if err != nil || string(login.GetWrappedDek()) != "wrapped" {
+		t.Fatalf("login after DEK: %v %v", login, err)
+	}
+
+	// another user sees nothing
+	_, other := srv.NewUser(t, "bob", "secret")
+	if _, err := cli.GetItem(WithToken(context.Background(), other), gir); status.Code(err) != codes.NotFound {
+		t.Fatalf("bob reads alice's item: %v", err)
+	}
+	if _, err := cli.GetItem(context.Background(), gir); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("no token: %v", err)
+	}
+}
+
+func TestServer_Options(t *testing.T) {
+	t.Parallel()
+	srv := Start(t, WithMaxItems(1), WithLoginLimit(2, time.Hour))
+	cli := srv.Client(t)
+	_, token := srv.NewUser(t, "alice", "secret")
+	ctx := WithToken(context.Background(), token)
+
+	if _, err := cli.UpsertItems(ctx, upsert(uuid.Must(uuid.NewV7()).String(), 0, "a")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cli.UpsertItems(ctx, upsert(uuid.Must(uuid.NewV7()).String(), 0, "b")); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("over the item cap: %v", err)
+	}
+
+	lr := &pb.LoginRequest{}
+	lr.SetUsername("alice")
+	lr.SetPassword("wrong")
+	for range 2 {
+		_, _ = cli.Login(context.Background(), lr)
+	}
+	lr.SetPassword("secret")
+	if _, err := cli.Login(context.Background(), lr); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("locked out: %v", err)
+	}
+}
+
+func TestServer_Faults(t *testing.T) {
+	t.Parallel()
+	srv := Start(t, WithFaults(chaos.Faults{Drop: 1}), WithBreaker(2, time.Minute))
+	cli := srv.Client(t)
+	rr := &pb.RegisterRequest{}
+	rr.SetUsername("alice")
+	rr.SetPassword("secret")
+	for range 2 {
+		if _, err := cli.Register(context.Background(), rr); status.Code(err) != codes.Internal {
+			t.Fatalf("dropped connection: %v", err)
+		}
+	}
+	if _, err := cli.Register(context.Background(), rr); status.Code(err) != codes.Unavailable {
+		t.Fatalf("breaker open: %v", err)
+	}
+}
+
+func TestServer_Webhook(t *testing.T) {
+	t.Parallel()
+	got := make(chan string, 1)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		got <- string(b)
+	}))
+	defer hook.Close()
+
+	srv := Start(t)
+	cli := srv.Client(t)
+	_, token := srv.NewUser(t, "alice", "secret")
+	ctx := WithToken(context.Background(), token)
+	cwr := &pb.CreateWebhookRequest{}
+	cwr.SetUrl(hook.URL)
+	if _, err := cli.CreateWebhook(ctx, cwr); err != nil {
+		t.Fatalf("create webhook: %v", err)
+	}
+	id := uuid.Must(uuid.NewV7()).String()
+	if _, err := cli.UpsertItems(ctx, upsert(id, 0, "a")); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case body := <-got:
+		if !strings.Contains(body, id) {
+			t.Fatalf("delivery %s lacks the item id", body)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no webhook delivery")
+	}
+}
+
+func TestServer_LongPoll(t *testing.T) {
+	t.Parallel()
+	srv := Start(t)
+	cli := srv.Client(t)
+	_, token := srv.NewUser(t, "alice", "secret")
+	ctx := WithToken(context.Background(), token)
+
+	type result struct {
+		resp *pb.GetChangesLongPollResponse
+		err  error
+	}
+	got := make(chan result, 1)
+	go func() {
+		req := &pb.GetChangesLongPollRequest{}
+		req.SetWaitSeconds(10)
+		resp, err := cli.GetChangesLongPoll(ctx, req)
+		got <- result{resp, err}
+	}()
+	time.Sleep(100 * time.Millisecond) // let the call start waiting
+	if _, err := cli.UpsertItems(ctx, upsert(uuid.Must(uuid.NewV7()).String(), 0, "a")); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case r := <-got:
+		if r.err != nil || !r.resp.GetChanged() || len(r.resp.GetResult().GetChanges()) != 1 {
+			t.Fatalf("long poll: %v %v", r.resp, r.err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("long poll not woken")
+	}
+}